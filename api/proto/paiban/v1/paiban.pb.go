@@ -0,0 +1,2582 @@
+// 排班与派单 gRPC 接口定义
+//
+// 与 HTTP API 一一对应：
+//   ScheduleService.Generate       POST /api/v1/schedule/generate
+//   ScheduleService.GenerateStream POST /api/v1/schedule/generate（流式返回求解进度，最后一条消息为结果）
+//   ScheduleService.Validate       POST /api/v1/schedule/validate
+//   DispatchService.Single         POST /api/v1/dispatch/single
+//   DispatchService.Batch          POST /api/v1/dispatch/batch
+//   DispatchService.Reassign       POST /api/v1/dispatch/reassign
+//   DispatchService.Route          POST /api/v1/dispatch/route
+//   DispatchService.ReportStatus   POST /api/v1/dispatch/status
+//   DispatchService.ListStatus     GET  /api/v1/dispatch/status
+//
+// 字段名与 JSON 请求体保持一致（snake_case）。结构较深或随业务频繁扩展的部分
+// （员工偏好、合同、约束配置、服务订单等）使用 google.protobuf.Struct 透传，
+// 内容与 HTTP 请求中对应字段的 JSON 相同。
+//
+// 服务端（internal/grpcapi）将请求转为 JSON 交给同一套 HTTP 处理器执行，认证、组织校验和限流与 HTTP 接口相同：
+// 元数据 x-api-key / authorization 对应同名请求头，错误按 HTTP 状态码映射为 gRPC 状态码。
+// Go 代码由 protoc-gen-go 和 protoc-gen-go-grpc 生成（make proto）。
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: paiban/v1/paiban.proto
+
+package paibanv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// GenerateRequest 排班生成请求
+type GenerateRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	OrgId           string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	StartDate       string                 `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"` // YYYY-MM-DD
+	EndDate         string                 `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`       // YYYY-MM-DD
+	Scenario        string                 `protobuf:"bytes,4,opt,name=scenario,proto3" json:"scenario,omitempty"`                    // restaurant/factory/housekeeping/nursing
+	Employees       []*Employee            `protobuf:"bytes,5,rep,name=employees,proto3" json:"employees,omitempty"`
+	Shifts          []*Shift               `protobuf:"bytes,6,rep,name=shifts,proto3" json:"shifts,omitempty"`
+	Requirements    []*Requirement         `protobuf:"bytes,7,rep,name=requirements,proto3" json:"requirements,omitempty"`
+	RequirementSpec string                 `protobuf:"bytes,8,opt,name=requirement_spec,json=requirementSpec,proto3" json:"requirement_spec,omitempty"` // 需求简写，requirements 为空时按简写生成需求
+	Constraints     *structpb.Struct       `protobuf:"bytes,9,opt,name=constraints,proto3" json:"constraints,omitempty"`                                // 约束配置，同 constraints
+	Options         *GenerateOptions       `protobuf:"bytes,10,opt,name=options,proto3" json:"options,omitempty"`
+	ExternalWorkers []*structpb.Struct     `protobuf:"bytes,11,rep,name=external_workers,json=externalWorkers,proto3" json:"external_workers,omitempty"` // 外部人员池，同 external_workers
+	Stores          []*structpb.Struct     `protobuf:"bytes,12,rep,name=stores,proto3" json:"stores,omitempty"`                                          // 门店营业时间，同 stores
+	Jurisdiction    string                 `protobuf:"bytes,13,opt,name=jurisdiction,proto3" json:"jurisdiction,omitempty"`                              // 劳动法规辖区
+	Teams           []*structpb.Struct     `protobuf:"bytes,14,rep,name=teams,proto3" json:"teams,omitempty"`                                            // 班组，同 teams
+	ScheduleId      string                 `protobuf:"bytes,15,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`                // 重新生成的排班草稿ID
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GenerateRequest) Reset() {
+	*x = GenerateRequest{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateRequest) ProtoMessage() {}
+
+func (x *GenerateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateRequest.ProtoReflect.Descriptor instead.
+func (*GenerateRequest) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GenerateRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetScenario() string {
+	if x != nil {
+		return x.Scenario
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetEmployees() []*Employee {
+	if x != nil {
+		return x.Employees
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetShifts() []*Shift {
+	if x != nil {
+		return x.Shifts
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetRequirements() []*Requirement {
+	if x != nil {
+		return x.Requirements
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetRequirementSpec() string {
+	if x != nil {
+		return x.RequirementSpec
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetConstraints() *structpb.Struct {
+	if x != nil {
+		return x.Constraints
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetOptions() *GenerateOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetExternalWorkers() []*structpb.Struct {
+	if x != nil {
+		return x.ExternalWorkers
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetStores() []*structpb.Struct {
+	if x != nil {
+		return x.Stores
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetJurisdiction() string {
+	if x != nil {
+		return x.Jurisdiction
+	}
+	return ""
+}
+
+func (x *GenerateRequest) GetTeams() []*structpb.Struct {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+func (x *GenerateRequest) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+// Employee 员工
+type Employee struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Id                     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                   string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Position               string                 `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	Skills                 []string               `protobuf:"bytes,4,rep,name=skills,proto3" json:"skills,omitempty"`
+	Certifications         []string               `protobuf:"bytes,5,rep,name=certifications,proto3" json:"certifications,omitempty"`
+	Status                 string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	BirthDate              string                 `protobuf:"bytes,7,opt,name=birth_date,json=birthDate,proto3" json:"birth_date,omitempty"`
+	HourlyRate             float64                `protobuf:"fixed64,8,opt,name=hourly_rate,json=hourlyRate,proto3" json:"hourly_rate,omitempty"`
+	StoreId                string                 `protobuf:"bytes,9,opt,name=store_id,json=storeId,proto3" json:"store_id,omitempty"`
+	AllowedStores          []string               `protobuf:"bytes,10,rep,name=allowed_stores,json=allowedStores,proto3" json:"allowed_stores,omitempty"`
+	MonthlyShiftsCounts    map[string]int32       `protobuf:"bytes,11,rep,name=monthly_shifts_counts,json=monthlyShiftsCounts,proto3" json:"monthly_shifts_counts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // key: YYYY-MM
+	Preferences            *structpb.Struct       `protobuf:"bytes,12,opt,name=preferences,proto3" json:"preferences,omitempty"`                                                                                                                         // 员工偏好
+	Contract               *structpb.Struct       `protobuf:"bytes,13,opt,name=contract,proto3" json:"contract,omitempty"`                                                                                                                               // 合同约束
+	AvailabilityWindows    []*structpb.Struct     `protobuf:"bytes,14,rep,name=availability_windows,json=availabilityWindows,proto3" json:"availability_windows,omitempty"`
+	Availability           []*structpb.Struct     `protobuf:"bytes,15,rep,name=availability,proto3" json:"availability,omitempty"`
+	UnavailableWindows     []*structpb.Struct     `protobuf:"bytes,16,rep,name=unavailable_windows,json=unavailableWindows,proto3" json:"unavailable_windows,omitempty"`
+	Leaves                 []*structpb.Struct     `protobuf:"bytes,17,rep,name=leaves,proto3" json:"leaves,omitempty"`
+	VerifiedCertifications []*structpb.Struct     `protobuf:"bytes,18,rep,name=verified_certifications,json=verifiedCertifications,proto3" json:"verified_certifications,omitempty"`
+	HomeStore              string                 `protobuf:"bytes,19,opt,name=home_store,json=homeStore,proto3" json:"home_store,omitempty"` // store_id 的别名
+	CertificationRecords   []*structpb.Struct     `protobuf:"bytes,20,rep,name=certification_records,json=certificationRecords,proto3" json:"certification_records,omitempty"`
+	SkillLevels            []*structpb.Struct     `protobuf:"bytes,21,rep,name=skill_levels,json=skillLevels,proto3" json:"skill_levels,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Employee) Reset() {
+	*x = Employee{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Employee) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Employee) ProtoMessage() {}
+
+func (x *Employee) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Employee.ProtoReflect.Descriptor instead.
+func (*Employee) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Employee) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Employee) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Employee) GetPosition() string {
+	if x != nil {
+		return x.Position
+	}
+	return ""
+}
+
+func (x *Employee) GetSkills() []string {
+	if x != nil {
+		return x.Skills
+	}
+	return nil
+}
+
+func (x *Employee) GetCertifications() []string {
+	if x != nil {
+		return x.Certifications
+	}
+	return nil
+}
+
+func (x *Employee) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Employee) GetBirthDate() string {
+	if x != nil {
+		return x.BirthDate
+	}
+	return ""
+}
+
+func (x *Employee) GetHourlyRate() float64 {
+	if x != nil {
+		return x.HourlyRate
+	}
+	return 0
+}
+
+func (x *Employee) GetStoreId() string {
+	if x != nil {
+		return x.StoreId
+	}
+	return ""
+}
+
+func (x *Employee) GetAllowedStores() []string {
+	if x != nil {
+		return x.AllowedStores
+	}
+	return nil
+}
+
+func (x *Employee) GetMonthlyShiftsCounts() map[string]int32 {
+	if x != nil {
+		return x.MonthlyShiftsCounts
+	}
+	return nil
+}
+
+func (x *Employee) GetPreferences() *structpb.Struct {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+func (x *Employee) GetContract() *structpb.Struct {
+	if x != nil {
+		return x.Contract
+	}
+	return nil
+}
+
+func (x *Employee) GetAvailabilityWindows() []*structpb.Struct {
+	if x != nil {
+		return x.AvailabilityWindows
+	}
+	return nil
+}
+
+func (x *Employee) GetAvailability() []*structpb.Struct {
+	if x != nil {
+		return x.Availability
+	}
+	return nil
+}
+
+func (x *Employee) GetUnavailableWindows() []*structpb.Struct {
+	if x != nil {
+		return x.UnavailableWindows
+	}
+	return nil
+}
+
+func (x *Employee) GetLeaves() []*structpb.Struct {
+	if x != nil {
+		return x.Leaves
+	}
+	return nil
+}
+
+func (x *Employee) GetVerifiedCertifications() []*structpb.Struct {
+	if x != nil {
+		return x.VerifiedCertifications
+	}
+	return nil
+}
+
+func (x *Employee) GetHomeStore() string {
+	if x != nil {
+		return x.HomeStore
+	}
+	return ""
+}
+
+func (x *Employee) GetCertificationRecords() []*structpb.Struct {
+	if x != nil {
+		return x.CertificationRecords
+	}
+	return nil
+}
+
+func (x *Employee) GetSkillLevels() []*structpb.Struct {
+	if x != nil {
+		return x.SkillLevels
+	}
+	return nil
+}
+
+// Shift 班次
+type Shift struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Code          string                 `protobuf:"bytes,3,opt,name=code,proto3" json:"code,omitempty"`
+	StartTime     string                 `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // HH:MM
+	EndTime       string                 `protobuf:"bytes,5,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // HH:MM
+	Duration      int32                  `protobuf:"varint,6,opt,name=duration,proto3" json:"duration,omitempty"`                   // 分钟
+	Type          string                 `protobuf:"bytes,7,opt,name=type,proto3" json:"type,omitempty"`
+	StoreId       string                 `protobuf:"bytes,8,opt,name=store_id,json=storeId,proto3" json:"store_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Shift) Reset() {
+	*x = Shift{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Shift) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Shift) ProtoMessage() {}
+
+func (x *Shift) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Shift.ProtoReflect.Descriptor instead.
+func (*Shift) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Shift) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Shift) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Shift) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Shift) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *Shift) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *Shift) GetDuration() int32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *Shift) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Shift) GetStoreId() string {
+	if x != nil {
+		return x.StoreId
+	}
+	return ""
+}
+
+// Requirement 人员需求
+type Requirement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ShiftId       string                 `protobuf:"bytes,1,opt,name=shift_id,json=shiftId,proto3" json:"shift_id,omitempty"`
+	Date          string                 `protobuf:"bytes,2,opt,name=date,proto3" json:"date,omitempty"`
+	Position      string                 `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	MinEmployees  int32                  `protobuf:"varint,4,opt,name=min_employees,json=minEmployees,proto3" json:"min_employees,omitempty"`
+	MaxEmployees  int32                  `protobuf:"varint,5,opt,name=max_employees,json=maxEmployees,proto3" json:"max_employees,omitempty"`
+	OptEmployees  int32                  `protobuf:"varint,6,opt,name=opt_employees,json=optEmployees,proto3" json:"opt_employees,omitempty"`
+	Skills        []string               `protobuf:"bytes,7,rep,name=skills,proto3" json:"skills,omitempty"`
+	Priority      int32                  `protobuf:"varint,8,opt,name=priority,proto3" json:"priority,omitempty"`
+	StoreId       string                 `protobuf:"bytes,9,opt,name=store_id,json=storeId,proto3" json:"store_id,omitempty"`
+	SkillGroups   []*structpb.Struct     `protobuf:"bytes,10,rep,name=skill_groups,json=skillGroups,proto3" json:"skill_groups,omitempty"`
+	Id            string                 `protobuf:"bytes,11,opt,name=id,proto3" json:"id,omitempty"`                                // 已保存需求的ID
+	ShiftCode     string                 `protobuf:"bytes,12,opt,name=shift_code,json=shiftCode,proto3" json:"shift_code,omitempty"` // 未给出 shift_id 时按班次编码匹配
+	SkillLevels   []*structpb.Struct     `protobuf:"bytes,13,rep,name=skill_levels,json=skillLevels,proto3" json:"skill_levels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Requirement) Reset() {
+	*x = Requirement{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Requirement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Requirement) ProtoMessage() {}
+
+func (x *Requirement) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Requirement.ProtoReflect.Descriptor instead.
+func (*Requirement) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Requirement) GetShiftId() string {
+	if x != nil {
+		return x.ShiftId
+	}
+	return ""
+}
+
+func (x *Requirement) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *Requirement) GetPosition() string {
+	if x != nil {
+		return x.Position
+	}
+	return ""
+}
+
+func (x *Requirement) GetMinEmployees() int32 {
+	if x != nil {
+		return x.MinEmployees
+	}
+	return 0
+}
+
+func (x *Requirement) GetMaxEmployees() int32 {
+	if x != nil {
+		return x.MaxEmployees
+	}
+	return 0
+}
+
+func (x *Requirement) GetOptEmployees() int32 {
+	if x != nil {
+		return x.OptEmployees
+	}
+	return 0
+}
+
+func (x *Requirement) GetSkills() []string {
+	if x != nil {
+		return x.Skills
+	}
+	return nil
+}
+
+func (x *Requirement) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Requirement) GetStoreId() string {
+	if x != nil {
+		return x.StoreId
+	}
+	return ""
+}
+
+func (x *Requirement) GetSkillGroups() []*structpb.Struct {
+	if x != nil {
+		return x.SkillGroups
+	}
+	return nil
+}
+
+func (x *Requirement) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Requirement) GetShiftCode() string {
+	if x != nil {
+		return x.ShiftCode
+	}
+	return ""
+}
+
+func (x *Requirement) GetSkillLevels() []*structpb.Struct {
+	if x != nil {
+		return x.SkillLevels
+	}
+	return nil
+}
+
+// GenerateOptions 生成选项
+type GenerateOptions struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	TimeoutSeconds     int32                  `protobuf:"varint,1,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"`
+	OptimizationLevel  int32                  `protobuf:"varint,2,opt,name=optimization_level,json=optimizationLevel,proto3" json:"optimization_level,omitempty"` // 1=快速, 2=平衡（贪心 + 优化）, 3=最优（模拟退火）
+	RespectPreferences bool                   `protobuf:"varint,3,opt,name=respect_preferences,json=respectPreferences,proto3" json:"respect_preferences,omitempty"`
+	HistoryDays        int32                  `protobuf:"varint,4,opt,name=history_days,json=historyDays,proto3" json:"history_days,omitempty"`
+	Optimizer          string                 `protobuf:"bytes,5,opt,name=optimizer,proto3" json:"optimizer,omitempty"` // local_search/genetic
+	Seed               int64                  `protobuf:"varint,6,opt,name=seed,proto3" json:"seed,omitempty"`          // 非 0 时启用确定性模式
+	Workers            int32                  `protobuf:"varint,7,opt,name=workers,proto3" json:"workers,omitempty"`    // 并行评估候选人的工作协程数（最多 32）
+	ExternalHoursCap   float64                `protobuf:"fixed64,8,opt,name=external_hours_cap,json=externalHoursCap,proto3" json:"external_hours_cap,omitempty"`
+	NoExternal         bool                   `protobuf:"varint,9,opt,name=no_external,json=noExternal,proto3" json:"no_external,omitempty"`
+	DryRun             bool                   `protobuf:"varint,10,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	StoreName          string                 `protobuf:"bytes,11,opt,name=store_name,json=storeName,proto3" json:"store_name,omitempty"`
+	ScoringProfile     map[string]float64     `protobuf:"bytes,12,rep,name=scoring_profile,json=scoringProfile,proto3" json:"scoring_profile,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // 多目标评分权重，仅 optimization_level=2 时可用
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *GenerateOptions) Reset() {
+	*x = GenerateOptions{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateOptions) ProtoMessage() {}
+
+func (x *GenerateOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateOptions.ProtoReflect.Descriptor instead.
+func (*GenerateOptions) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GenerateOptions) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *GenerateOptions) GetOptimizationLevel() int32 {
+	if x != nil {
+		return x.OptimizationLevel
+	}
+	return 0
+}
+
+func (x *GenerateOptions) GetRespectPreferences() bool {
+	if x != nil {
+		return x.RespectPreferences
+	}
+	return false
+}
+
+func (x *GenerateOptions) GetHistoryDays() int32 {
+	if x != nil {
+		return x.HistoryDays
+	}
+	return 0
+}
+
+func (x *GenerateOptions) GetOptimizer() string {
+	if x != nil {
+		return x.Optimizer
+	}
+	return ""
+}
+
+func (x *GenerateOptions) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *GenerateOptions) GetWorkers() int32 {
+	if x != nil {
+		return x.Workers
+	}
+	return 0
+}
+
+func (x *GenerateOptions) GetExternalHoursCap() float64 {
+	if x != nil {
+		return x.ExternalHoursCap
+	}
+	return 0
+}
+
+func (x *GenerateOptions) GetNoExternal() bool {
+	if x != nil {
+		return x.NoExternal
+	}
+	return false
+}
+
+func (x *GenerateOptions) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *GenerateOptions) GetStoreName() string {
+	if x != nil {
+		return x.StoreName
+	}
+	return ""
+}
+
+func (x *GenerateOptions) GetScoringProfile() map[string]float64 {
+	if x != nil {
+		return x.ScoringProfile
+	}
+	return nil
+}
+
+// Assignment 排班分配
+type Assignment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	EmployeeId    string                 `protobuf:"bytes,2,opt,name=employee_id,json=employeeId,proto3" json:"employee_id,omitempty"`
+	EmployeeName  string                 `protobuf:"bytes,3,opt,name=employee_name,json=employeeName,proto3" json:"employee_name,omitempty"`
+	ShiftId       string                 `protobuf:"bytes,4,opt,name=shift_id,json=shiftId,proto3" json:"shift_id,omitempty"`
+	ShiftName     string                 `protobuf:"bytes,5,opt,name=shift_name,json=shiftName,proto3" json:"shift_name,omitempty"`
+	Date          string                 `protobuf:"bytes,6,opt,name=date,proto3" json:"date,omitempty"`
+	StartTime     string                 `protobuf:"bytes,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       string                 `protobuf:"bytes,8,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Position      string                 `protobuf:"bytes,9,opt,name=position,proto3" json:"position,omitempty"`
+	StoreId       string                 `protobuf:"bytes,10,opt,name=store_id,json=storeId,proto3" json:"store_id,omitempty"`
+	StoreName     string                 `protobuf:"bytes,11,opt,name=store_name,json=storeName,proto3" json:"store_name,omitempty"`
+	Hours         float64                `protobuf:"fixed64,12,opt,name=hours,proto3" json:"hours,omitempty"`
+	Score         float64                `protobuf:"fixed64,13,opt,name=score,proto3" json:"score,omitempty"`
+	External      bool                   `protobuf:"varint,14,opt,name=external,proto3" json:"external,omitempty"`
+	Agency        string                 `protobuf:"bytes,15,opt,name=agency,proto3" json:"agency,omitempty"`
+	Cost          float64                `protobuf:"fixed64,16,opt,name=cost,proto3" json:"cost,omitempty"`
+	ScoreDetail   *structpb.Struct       `protobuf:"bytes,17,opt,name=score_detail,json=scoreDetail,proto3" json:"score_detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Assignment) Reset() {
+	*x = Assignment{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Assignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Assignment) ProtoMessage() {}
+
+func (x *Assignment) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Assignment.ProtoReflect.Descriptor instead.
+func (*Assignment) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Assignment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Assignment) GetEmployeeId() string {
+	if x != nil {
+		return x.EmployeeId
+	}
+	return ""
+}
+
+func (x *Assignment) GetEmployeeName() string {
+	if x != nil {
+		return x.EmployeeName
+	}
+	return ""
+}
+
+func (x *Assignment) GetShiftId() string {
+	if x != nil {
+		return x.ShiftId
+	}
+	return ""
+}
+
+func (x *Assignment) GetShiftName() string {
+	if x != nil {
+		return x.ShiftName
+	}
+	return ""
+}
+
+func (x *Assignment) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *Assignment) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *Assignment) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *Assignment) GetPosition() string {
+	if x != nil {
+		return x.Position
+	}
+	return ""
+}
+
+func (x *Assignment) GetStoreId() string {
+	if x != nil {
+		return x.StoreId
+	}
+	return ""
+}
+
+func (x *Assignment) GetStoreName() string {
+	if x != nil {
+		return x.StoreName
+	}
+	return ""
+}
+
+func (x *Assignment) GetHours() float64 {
+	if x != nil {
+		return x.Hours
+	}
+	return 0
+}
+
+func (x *Assignment) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Assignment) GetExternal() bool {
+	if x != nil {
+		return x.External
+	}
+	return false
+}
+
+func (x *Assignment) GetAgency() string {
+	if x != nil {
+		return x.Agency
+	}
+	return ""
+}
+
+func (x *Assignment) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *Assignment) GetScoreDetail() *structpb.Struct {
+	if x != nil {
+		return x.ScoreDetail
+	}
+	return nil
+}
+
+// GenerateResponse 排班生成结果
+type GenerateResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Success     bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Partial     bool                   `protobuf:"varint,2,opt,name=partial,proto3" json:"partial,omitempty"`
+	Message     string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	ScheduleId  string                 `protobuf:"bytes,4,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	Assignments []*Assignment          `protobuf:"bytes,5,rep,name=assignments,proto3" json:"assignments,omitempty"`
+	Duration    string                 `protobuf:"bytes,6,opt,name=duration,proto3" json:"duration,omitempty"`
+	Seed        int64                  `protobuf:"varint,7,opt,name=seed,proto3" json:"seed,omitempty"`
+	// 以下内容与 HTTP 响应中同名字段的 JSON 相同
+	Statistics             *structpb.Struct   `protobuf:"bytes,8,opt,name=statistics,proto3" json:"statistics,omitempty"`
+	ConstraintResult       *structpb.Struct   `protobuf:"bytes,9,opt,name=constraint_result,json=constraintResult,proto3" json:"constraint_result,omitempty"`
+	Unfilled               []*structpb.Struct `protobuf:"bytes,10,rep,name=unfilled,proto3" json:"unfilled,omitempty"`
+	Suggestions            []*structpb.Struct `protobuf:"bytes,11,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	Compliance             *structpb.Struct   `protobuf:"bytes,12,opt,name=compliance,proto3" json:"compliance,omitempty"`
+	CostSummary            *structpb.Struct   `protobuf:"bytes,13,opt,name=cost_summary,json=costSummary,proto3" json:"cost_summary,omitempty"`
+	OnCall                 []*Assignment      `protobuf:"bytes,14,rep,name=on_call,json=onCall,proto3" json:"on_call,omitempty"`
+	Anomalies              []*structpb.Struct `protobuf:"bytes,15,rep,name=anomalies,proto3" json:"anomalies,omitempty"`
+	UnmappedLabels         []*structpb.Struct `protobuf:"bytes,16,rep,name=unmapped_labels,json=unmappedLabels,proto3" json:"unmapped_labels,omitempty"`
+	BlackoutPeriods        []string           `protobuf:"bytes,17,rep,name=blackout_periods,json=blackoutPeriods,proto3" json:"blackout_periods,omitempty"`
+	OpeningHoursConflicts  []string           `protobuf:"bytes,18,rep,name=opening_hours_conflicts,json=openingHoursConflicts,proto3" json:"opening_hours_conflicts,omitempty"`
+	ExternalLabor          *structpb.Struct   `protobuf:"bytes,19,opt,name=external_labor,json=externalLabor,proto3" json:"external_labor,omitempty"`
+	PreviousScheduleId     string             `protobuf:"bytes,20,opt,name=previous_schedule_id,json=previousScheduleId,proto3" json:"previous_schedule_id,omitempty"`
+	HistoryAssignments     int32              `protobuf:"varint,21,opt,name=history_assignments,json=historyAssignments,proto3" json:"history_assignments,omitempty"`
+	Stores                 []*structpb.Struct `protobuf:"bytes,22,rep,name=stores,proto3" json:"stores,omitempty"`
+	ExpiringCertifications []*structpb.Struct `protobuf:"bytes,23,rep,name=expiring_certifications,json=expiringCertifications,proto3" json:"expiring_certifications,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *GenerateResponse) Reset() {
+	*x = GenerateResponse{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateResponse) ProtoMessage() {}
+
+func (x *GenerateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateResponse.ProtoReflect.Descriptor instead.
+func (*GenerateResponse) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GenerateResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GenerateResponse) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *GenerateResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GenerateResponse) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+func (x *GenerateResponse) GetAssignments() []*Assignment {
+	if x != nil {
+		return x.Assignments
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetDuration() string {
+	if x != nil {
+		return x.Duration
+	}
+	return ""
+}
+
+func (x *GenerateResponse) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *GenerateResponse) GetStatistics() *structpb.Struct {
+	if x != nil {
+		return x.Statistics
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetConstraintResult() *structpb.Struct {
+	if x != nil {
+		return x.ConstraintResult
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetUnfilled() []*structpb.Struct {
+	if x != nil {
+		return x.Unfilled
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetSuggestions() []*structpb.Struct {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetCompliance() *structpb.Struct {
+	if x != nil {
+		return x.Compliance
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetCostSummary() *structpb.Struct {
+	if x != nil {
+		return x.CostSummary
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetOnCall() []*Assignment {
+	if x != nil {
+		return x.OnCall
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetAnomalies() []*structpb.Struct {
+	if x != nil {
+		return x.Anomalies
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetUnmappedLabels() []*structpb.Struct {
+	if x != nil {
+		return x.UnmappedLabels
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetBlackoutPeriods() []string {
+	if x != nil {
+		return x.BlackoutPeriods
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetOpeningHoursConflicts() []string {
+	if x != nil {
+		return x.OpeningHoursConflicts
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetExternalLabor() *structpb.Struct {
+	if x != nil {
+		return x.ExternalLabor
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetPreviousScheduleId() string {
+	if x != nil {
+		return x.PreviousScheduleId
+	}
+	return ""
+}
+
+func (x *GenerateResponse) GetHistoryAssignments() int32 {
+	if x != nil {
+		return x.HistoryAssignments
+	}
+	return 0
+}
+
+func (x *GenerateResponse) GetStores() []*structpb.Struct {
+	if x != nil {
+		return x.Stores
+	}
+	return nil
+}
+
+func (x *GenerateResponse) GetExpiringCertifications() []*structpb.Struct {
+	if x != nil {
+		return x.ExpiringCertifications
+	}
+	return nil
+}
+
+// Progress 求解进度，同异步生成作业的 progress
+type Progress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phase         string                 `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"`
+	Fraction      float64                `protobuf:"fixed64,2,opt,name=fraction,proto3" json:"fraction,omitempty"` // 整体完成比例（0-1）
+	Round         int32                  `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`        // 贪心阶段为分配轮次，退火阶段为迭代次数
+	Assignments   int32                  `protobuf:"varint,4,opt,name=assignments,proto3" json:"assignments,omitempty"`
+	Score         float64                `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"` // 满足最少人数的需求占比（0-100）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Progress) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *Progress) GetFraction() float64 {
+	if x != nil {
+		return x.Fraction
+	}
+	return 0
+}
+
+func (x *Progress) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Progress) GetAssignments() int32 {
+	if x != nil {
+		return x.Assignments
+	}
+	return 0
+}
+
+func (x *Progress) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+// GenerateEvent 流式生成消息：求解中为进度，结束时为结果
+type GenerateEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*GenerateEvent_Progress
+	//	*GenerateEvent_Result
+	Event         isGenerateEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GenerateEvent) Reset() {
+	*x = GenerateEvent{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateEvent) ProtoMessage() {}
+
+func (x *GenerateEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateEvent.ProtoReflect.Descriptor instead.
+func (*GenerateEvent) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GenerateEvent) GetEvent() isGenerateEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *GenerateEvent) GetProgress() *Progress {
+	if x != nil {
+		if x, ok := x.Event.(*GenerateEvent_Progress); ok {
+			return x.Progress
+		}
+	}
+	return nil
+}
+
+func (x *GenerateEvent) GetResult() *GenerateResponse {
+	if x != nil {
+		if x, ok := x.Event.(*GenerateEvent_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isGenerateEvent_Event interface {
+	isGenerateEvent_Event()
+}
+
+type GenerateEvent_Progress struct {
+	Progress *Progress `protobuf:"bytes,1,opt,name=progress,proto3,oneof"`
+}
+
+type GenerateEvent_Result struct {
+	Result *GenerateResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*GenerateEvent_Progress) isGenerateEvent_Event() {}
+
+func (*GenerateEvent_Result) isGenerateEvent_Event() {}
+
+// ValidateRequest 排班校验请求
+type ValidateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Assignments   []*ValidateAssignment  `protobuf:"bytes,2,rep,name=assignments,proto3" json:"assignments,omitempty"`
+	Employees     []*Employee            `protobuf:"bytes,3,rep,name=employees,proto3" json:"employees,omitempty"`
+	Constraints   *structpb.Struct       `protobuf:"bytes,4,opt,name=constraints,proto3" json:"constraints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateRequest) Reset() {
+	*x = ValidateRequest{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRequest) ProtoMessage() {}
+
+func (x *ValidateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRequest.ProtoReflect.Descriptor instead.
+func (*ValidateRequest) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ValidateRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *ValidateRequest) GetAssignments() []*ValidateAssignment {
+	if x != nil {
+		return x.Assignments
+	}
+	return nil
+}
+
+func (x *ValidateRequest) GetEmployees() []*Employee {
+	if x != nil {
+		return x.Employees
+	}
+	return nil
+}
+
+func (x *ValidateRequest) GetConstraints() *structpb.Struct {
+	if x != nil {
+		return x.Constraints
+	}
+	return nil
+}
+
+// ValidateAssignment 待校验的分配
+type ValidateAssignment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EmployeeId    string                 `protobuf:"bytes,1,opt,name=employee_id,json=employeeId,proto3" json:"employee_id,omitempty"`
+	ShiftId       string                 `protobuf:"bytes,2,opt,name=shift_id,json=shiftId,proto3" json:"shift_id,omitempty"`
+	Date          string                 `protobuf:"bytes,3,opt,name=date,proto3" json:"date,omitempty"`
+	StartTime     string                 `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       string                 `protobuf:"bytes,5,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Position      string                 `protobuf:"bytes,6,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateAssignment) Reset() {
+	*x = ValidateAssignment{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateAssignment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateAssignment) ProtoMessage() {}
+
+func (x *ValidateAssignment) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateAssignment.ProtoReflect.Descriptor instead.
+func (*ValidateAssignment) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ValidateAssignment) GetEmployeeId() string {
+	if x != nil {
+		return x.EmployeeId
+	}
+	return ""
+}
+
+func (x *ValidateAssignment) GetShiftId() string {
+	if x != nil {
+		return x.ShiftId
+	}
+	return ""
+}
+
+func (x *ValidateAssignment) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *ValidateAssignment) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *ValidateAssignment) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *ValidateAssignment) GetPosition() string {
+	if x != nil {
+		return x.Position
+	}
+	return ""
+}
+
+// ValidateResponse 排班校验结果，result 为 HTTP 响应的 JSON
+type ValidateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *structpb.Struct       `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateResponse) Reset() {
+	*x = ValidateResponse{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateResponse) ProtoMessage() {}
+
+func (x *ValidateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateResponse.ProtoReflect.Descriptor instead.
+func (*ValidateResponse) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ValidateResponse) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// DispatchRequest 单个订单派单请求，订单、候选人等与 HTTP 请求的 JSON 相同
+type DispatchRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Order            *structpb.Struct       `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	Candidates       []*structpb.Struct     `protobuf:"bytes,2,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	Customer         *structpb.Struct       `protobuf:"bytes,3,opt,name=customer,proto3" json:"customer,omitempty"`
+	TodayOrders      []*structpb.Struct     `protobuf:"bytes,4,rep,name=today_orders,json=todayOrders,proto3" json:"today_orders,omitempty"`
+	History          []*structpb.Struct     `protobuf:"bytes,5,rep,name=history,proto3" json:"history,omitempty"`
+	KeepApart        []*structpb.Struct     `protobuf:"bytes,6,rep,name=keep_apart,json=keepApart,proto3" json:"keep_apart,omitempty"`
+	MaxResults       int32                  `protobuf:"varint,7,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+	WindowCaregivers []string               `protobuf:"bytes,8,rep,name=window_caregivers,json=windowCaregivers,proto3" json:"window_caregivers,omitempty"`
+	Constraints      *structpb.Struct       `protobuf:"bytes,9,opt,name=constraints,proto3" json:"constraints,omitempty"` // 派单约束配置，覆盖组织保存的配置中的同名约束
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DispatchRequest) Reset() {
+	*x = DispatchRequest{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DispatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DispatchRequest) ProtoMessage() {}
+
+func (x *DispatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DispatchRequest.ProtoReflect.Descriptor instead.
+func (*DispatchRequest) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DispatchRequest) GetOrder() *structpb.Struct {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+func (x *DispatchRequest) GetCandidates() []*structpb.Struct {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+func (x *DispatchRequest) GetCustomer() *structpb.Struct {
+	if x != nil {
+		return x.Customer
+	}
+	return nil
+}
+
+func (x *DispatchRequest) GetTodayOrders() []*structpb.Struct {
+	if x != nil {
+		return x.TodayOrders
+	}
+	return nil
+}
+
+func (x *DispatchRequest) GetHistory() []*structpb.Struct {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *DispatchRequest) GetKeepApart() []*structpb.Struct {
+	if x != nil {
+		return x.KeepApart
+	}
+	return nil
+}
+
+func (x *DispatchRequest) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+func (x *DispatchRequest) GetWindowCaregivers() []string {
+	if x != nil {
+		return x.WindowCaregivers
+	}
+	return nil
+}
+
+func (x *DispatchRequest) GetConstraints() *structpb.Struct {
+	if x != nil {
+		return x.Constraints
+	}
+	return nil
+}
+
+// BatchDispatchRequest 批量派单请求
+type BatchDispatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*structpb.Struct     `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	Candidates    []*structpb.Struct     `protobuf:"bytes,2,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	Customer      *structpb.Struct       `protobuf:"bytes,3,opt,name=customer,proto3" json:"customer,omitempty"`
+	KeepApart     []*structpb.Struct     `protobuf:"bytes,4,rep,name=keep_apart,json=keepApart,proto3" json:"keep_apart,omitempty"`
+	Optimization  string                 `protobuf:"bytes,5,opt,name=optimization,proto3" json:"optimization,omitempty"` // greedy（默认）/global
+	Constraints   *structpb.Struct       `protobuf:"bytes,6,opt,name=constraints,proto3" json:"constraints,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchDispatchRequest) Reset() {
+	*x = BatchDispatchRequest{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDispatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDispatchRequest) ProtoMessage() {}
+
+func (x *BatchDispatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDispatchRequest.ProtoReflect.Descriptor instead.
+func (*BatchDispatchRequest) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *BatchDispatchRequest) GetOrders() []*structpb.Struct {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *BatchDispatchRequest) GetCandidates() []*structpb.Struct {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+func (x *BatchDispatchRequest) GetCustomer() *structpb.Struct {
+	if x != nil {
+		return x.Customer
+	}
+	return nil
+}
+
+func (x *BatchDispatchRequest) GetKeepApart() []*structpb.Struct {
+	if x != nil {
+		return x.KeepApart
+	}
+	return nil
+}
+
+func (x *BatchDispatchRequest) GetOptimization() string {
+	if x != nil {
+		return x.Optimization
+	}
+	return ""
+}
+
+func (x *BatchDispatchRequest) GetConstraints() *structpb.Struct {
+	if x != nil {
+		return x.Constraints
+	}
+	return nil
+}
+
+// ReassignRequest 改派请求，订单、候选人等与 HTTP 请求的 JSON 相同
+type ReassignRequest struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Order                 *structpb.Struct       `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	UnavailableEmployeeId string                 `protobuf:"bytes,2,opt,name=unavailable_employee_id,json=unavailableEmployeeId,proto3" json:"unavailable_employee_id,omitempty"` // 默认为订单的带队人
+	Candidates            []*structpb.Struct     `protobuf:"bytes,3,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	Customer              *structpb.Struct       `protobuf:"bytes,4,opt,name=customer,proto3" json:"customer,omitempty"`
+	Customers             []*structpb.Struct     `protobuf:"bytes,5,rep,name=customers,proto3" json:"customers,omitempty"`
+	TodayOrders           []*structpb.Struct     `protobuf:"bytes,6,rep,name=today_orders,json=todayOrders,proto3" json:"today_orders,omitempty"`
+	History               []*structpb.Struct     `protobuf:"bytes,7,rep,name=history,proto3" json:"history,omitempty"`
+	MaxResults            int32                  `protobuf:"varint,8,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+	Constraints           *structpb.Struct       `protobuf:"bytes,9,opt,name=constraints,proto3" json:"constraints,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ReassignRequest) Reset() {
+	*x = ReassignRequest{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReassignRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReassignRequest) ProtoMessage() {}
+
+func (x *ReassignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReassignRequest.ProtoReflect.Descriptor instead.
+func (*ReassignRequest) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReassignRequest) GetOrder() *structpb.Struct {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+func (x *ReassignRequest) GetUnavailableEmployeeId() string {
+	if x != nil {
+		return x.UnavailableEmployeeId
+	}
+	return ""
+}
+
+func (x *ReassignRequest) GetCandidates() []*structpb.Struct {
+	if x != nil {
+		return x.Candidates
+	}
+	return nil
+}
+
+func (x *ReassignRequest) GetCustomer() *structpb.Struct {
+	if x != nil {
+		return x.Customer
+	}
+	return nil
+}
+
+func (x *ReassignRequest) GetCustomers() []*structpb.Struct {
+	if x != nil {
+		return x.Customers
+	}
+	return nil
+}
+
+func (x *ReassignRequest) GetTodayOrders() []*structpb.Struct {
+	if x != nil {
+		return x.TodayOrders
+	}
+	return nil
+}
+
+func (x *ReassignRequest) GetHistory() []*structpb.Struct {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+func (x *ReassignRequest) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+func (x *ReassignRequest) GetConstraints() *structpb.Struct {
+	if x != nil {
+		return x.Constraints
+	}
+	return nil
+}
+
+// DispatchResponse 派单结果，result 为 HTTP 响应的 JSON
+type DispatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Result        *structpb.Struct       `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DispatchResponse) Reset() {
+	*x = DispatchResponse{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DispatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DispatchResponse) ProtoMessage() {}
+
+func (x *DispatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DispatchResponse.ProtoReflect.Descriptor instead.
+func (*DispatchResponse) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DispatchResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DispatchResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DispatchResponse) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// RouteRequest 最优路线请求
+type RouteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*structpb.Struct     `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	StartLocation *structpb.Struct       `protobuf:"bytes,2,opt,name=start_location,json=startLocation,proto3" json:"start_location,omitempty"`
+	StartTime     string                 `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // HH:MM
+	SpeedKmh      float64                `protobuf:"fixed64,4,opt,name=speed_kmh,json=speedKmh,proto3" json:"speed_kmh,omitempty"`
+	ReturnToStart bool                   `protobuf:"varint,5,opt,name=return_to_start,json=returnToStart,proto3" json:"return_to_start,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteRequest) Reset() {
+	*x = RouteRequest{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteRequest) ProtoMessage() {}
+
+func (x *RouteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteRequest.ProtoReflect.Descriptor instead.
+func (*RouteRequest) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RouteRequest) GetOrders() []*structpb.Struct {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetStartLocation() *structpb.Struct {
+	if x != nil {
+		return x.StartLocation
+	}
+	return nil
+}
+
+func (x *RouteRequest) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *RouteRequest) GetSpeedKmh() float64 {
+	if x != nil {
+		return x.SpeedKmh
+	}
+	return 0
+}
+
+func (x *RouteRequest) GetReturnToStart() bool {
+	if x != nil {
+		return x.ReturnToStart
+	}
+	return false
+}
+
+// RouteResponse 最优路线结果，result 为 HTTP 响应的 JSON
+type RouteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Result        *structpb.Struct       `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RouteResponse) Reset() {
+	*x = RouteResponse{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RouteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RouteResponse) ProtoMessage() {}
+
+func (x *RouteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RouteResponse.ProtoReflect.Descriptor instead.
+func (*RouteResponse) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RouteResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RouteResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RouteResponse) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// ReportStatusRequest 服务人员状态上报，与 HTTP 请求的 JSON 相同
+type ReportStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *structpb.Struct       `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportStatusRequest) Reset() {
+	*x = ReportStatusRequest{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportStatusRequest) ProtoMessage() {}
+
+func (x *ReportStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportStatusRequest.ProtoReflect.Descriptor instead.
+func (*ReportStatusRequest) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReportStatusRequest) GetStatus() *structpb.Struct {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+// ListStatusRequest 服务人员状态查询
+type ListStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EmployeeId    string                 `protobuf:"bytes,1,opt,name=employee_id,json=employeeId,proto3" json:"employee_id,omitempty"` // 为空时返回全部
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListStatusRequest) Reset() {
+	*x = ListStatusRequest{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListStatusRequest) ProtoMessage() {}
+
+func (x *ListStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListStatusRequest.ProtoReflect.Descriptor instead.
+func (*ListStatusRequest) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListStatusRequest) GetEmployeeId() string {
+	if x != nil {
+		return x.EmployeeId
+	}
+	return ""
+}
+
+// StatusResponse 服务人员状态，result 为 HTTP 响应的 JSON
+type StatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *structpb.Struct       `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_paiban_v1_paiban_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_paiban_v1_paiban_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_paiban_v1_paiban_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *StatusResponse) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+var File_paiban_v1_paiban_proto protoreflect.FileDescriptor
+
+const file_paiban_v1_paiban_proto_rawDesc = "" +
+	"\n" +
+	"\x16paiban/v1/paiban.proto\x12\tpaiban.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x9c\x05\n" +
+	"\x0fGenerateRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x02 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x03 \x01(\tR\aendDate\x12\x1a\n" +
+	"\bscenario\x18\x04 \x01(\tR\bscenario\x121\n" +
+	"\temployees\x18\x05 \x03(\v2\x13.paiban.v1.EmployeeR\temployees\x12(\n" +
+	"\x06shifts\x18\x06 \x03(\v2\x10.paiban.v1.ShiftR\x06shifts\x12:\n" +
+	"\frequirements\x18\a \x03(\v2\x16.paiban.v1.RequirementR\frequirements\x12)\n" +
+	"\x10requirement_spec\x18\b \x01(\tR\x0frequirementSpec\x129\n" +
+	"\vconstraints\x18\t \x01(\v2\x17.google.protobuf.StructR\vconstraints\x124\n" +
+	"\aoptions\x18\n" +
+	" \x01(\v2\x1a.paiban.v1.GenerateOptionsR\aoptions\x12B\n" +
+	"\x10external_workers\x18\v \x03(\v2\x17.google.protobuf.StructR\x0fexternalWorkers\x12/\n" +
+	"\x06stores\x18\f \x03(\v2\x17.google.protobuf.StructR\x06stores\x12\"\n" +
+	"\fjurisdiction\x18\r \x01(\tR\fjurisdiction\x12-\n" +
+	"\x05teams\x18\x0e \x03(\v2\x17.google.protobuf.StructR\x05teams\x12\x1f\n" +
+	"\vschedule_id\x18\x0f \x01(\tR\n" +
+	"scheduleId\"\xbd\b\n" +
+	"\bEmployee\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\bposition\x18\x03 \x01(\tR\bposition\x12\x16\n" +
+	"\x06skills\x18\x04 \x03(\tR\x06skills\x12&\n" +
+	"\x0ecertifications\x18\x05 \x03(\tR\x0ecertifications\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"birth_date\x18\a \x01(\tR\tbirthDate\x12\x1f\n" +
+	"\vhourly_rate\x18\b \x01(\x01R\n" +
+	"hourlyRate\x12\x19\n" +
+	"\bstore_id\x18\t \x01(\tR\astoreId\x12%\n" +
+	"\x0eallowed_stores\x18\n" +
+	" \x03(\tR\rallowedStores\x12`\n" +
+	"\x15monthly_shifts_counts\x18\v \x03(\v2,.paiban.v1.Employee.MonthlyShiftsCountsEntryR\x13monthlyShiftsCounts\x129\n" +
+	"\vpreferences\x18\f \x01(\v2\x17.google.protobuf.StructR\vpreferences\x123\n" +
+	"\bcontract\x18\r \x01(\v2\x17.google.protobuf.StructR\bcontract\x12J\n" +
+	"\x14availability_windows\x18\x0e \x03(\v2\x17.google.protobuf.StructR\x13availabilityWindows\x12;\n" +
+	"\favailability\x18\x0f \x03(\v2\x17.google.protobuf.StructR\favailability\x12H\n" +
+	"\x13unavailable_windows\x18\x10 \x03(\v2\x17.google.protobuf.StructR\x12unavailableWindows\x12/\n" +
+	"\x06leaves\x18\x11 \x03(\v2\x17.google.protobuf.StructR\x06leaves\x12P\n" +
+	"\x17verified_certifications\x18\x12 \x03(\v2\x17.google.protobuf.StructR\x16verifiedCertifications\x12\x1d\n" +
+	"\n" +
+	"home_store\x18\x13 \x01(\tR\thomeStore\x12L\n" +
+	"\x15certification_records\x18\x14 \x03(\v2\x17.google.protobuf.StructR\x14certificationRecords\x12:\n" +
+	"\fskill_levels\x18\x15 \x03(\v2\x17.google.protobuf.StructR\vskillLevels\x1aF\n" +
+	"\x18MonthlyShiftsCountsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xc4\x01\n" +
+	"\x05Shift\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\tR\x04code\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x04 \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x05 \x01(\tR\aendTime\x12\x1a\n" +
+	"\bduration\x18\x06 \x01(\x05R\bduration\x12\x12\n" +
+	"\x04type\x18\a \x01(\tR\x04type\x12\x19\n" +
+	"\bstore_id\x18\b \x01(\tR\astoreId\"\xbd\x03\n" +
+	"\vRequirement\x12\x19\n" +
+	"\bshift_id\x18\x01 \x01(\tR\ashiftId\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\tR\x04date\x12\x1a\n" +
+	"\bposition\x18\x03 \x01(\tR\bposition\x12#\n" +
+	"\rmin_employees\x18\x04 \x01(\x05R\fminEmployees\x12#\n" +
+	"\rmax_employees\x18\x05 \x01(\x05R\fmaxEmployees\x12#\n" +
+	"\ropt_employees\x18\x06 \x01(\x05R\foptEmployees\x12\x16\n" +
+	"\x06skills\x18\a \x03(\tR\x06skills\x12\x1a\n" +
+	"\bpriority\x18\b \x01(\x05R\bpriority\x12\x19\n" +
+	"\bstore_id\x18\t \x01(\tR\astoreId\x12:\n" +
+	"\fskill_groups\x18\n" +
+	" \x03(\v2\x17.google.protobuf.StructR\vskillGroups\x12\x0e\n" +
+	"\x02id\x18\v \x01(\tR\x02id\x12\x1d\n" +
+	"\n" +
+	"shift_code\x18\f \x01(\tR\tshiftCode\x12:\n" +
+	"\fskill_levels\x18\r \x03(\v2\x17.google.protobuf.StructR\vskillLevels\"\xac\x04\n" +
+	"\x0fGenerateOptions\x12'\n" +
+	"\x0ftimeout_seconds\x18\x01 \x01(\x05R\x0etimeoutSeconds\x12-\n" +
+	"\x12optimization_level\x18\x02 \x01(\x05R\x11optimizationLevel\x12/\n" +
+	"\x13respect_preferences\x18\x03 \x01(\bR\x12respectPreferences\x12!\n" +
+	"\fhistory_days\x18\x04 \x01(\x05R\vhistoryDays\x12\x1c\n" +
+	"\toptimizer\x18\x05 \x01(\tR\toptimizer\x12\x12\n" +
+	"\x04seed\x18\x06 \x01(\x03R\x04seed\x12\x18\n" +
+	"\aworkers\x18\a \x01(\x05R\aworkers\x12,\n" +
+	"\x12external_hours_cap\x18\b \x01(\x01R\x10externalHoursCap\x12\x1f\n" +
+	"\vno_external\x18\t \x01(\bR\n" +
+	"noExternal\x12\x17\n" +
+	"\adry_run\x18\n" +
+	" \x01(\bR\x06dryRun\x12\x1d\n" +
+	"\n" +
+	"store_name\x18\v \x01(\tR\tstoreName\x12W\n" +
+	"\x0fscoring_profile\x18\f \x03(\v2..paiban.v1.GenerateOptions.ScoringProfileEntryR\x0escoringProfile\x1aA\n" +
+	"\x13ScoringProfileEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xf0\x03\n" +
+	"\n" +
+	"Assignment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vemployee_id\x18\x02 \x01(\tR\n" +
+	"employeeId\x12#\n" +
+	"\remployee_name\x18\x03 \x01(\tR\femployeeName\x12\x19\n" +
+	"\bshift_id\x18\x04 \x01(\tR\ashiftId\x12\x1d\n" +
+	"\n" +
+	"shift_name\x18\x05 \x01(\tR\tshiftName\x12\x12\n" +
+	"\x04date\x18\x06 \x01(\tR\x04date\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\a \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\b \x01(\tR\aendTime\x12\x1a\n" +
+	"\bposition\x18\t \x01(\tR\bposition\x12\x19\n" +
+	"\bstore_id\x18\n" +
+	" \x01(\tR\astoreId\x12\x1d\n" +
+	"\n" +
+	"store_name\x18\v \x01(\tR\tstoreName\x12\x14\n" +
+	"\x05hours\x18\f \x01(\x01R\x05hours\x12\x14\n" +
+	"\x05score\x18\r \x01(\x01R\x05score\x12\x1a\n" +
+	"\bexternal\x18\x0e \x01(\bR\bexternal\x12\x16\n" +
+	"\x06agency\x18\x0f \x01(\tR\x06agency\x12\x12\n" +
+	"\x04cost\x18\x10 \x01(\x01R\x04cost\x12:\n" +
+	"\fscore_detail\x18\x11 \x01(\v2\x17.google.protobuf.StructR\vscoreDetail\"\x80\t\n" +
+	"\x10GenerateResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\apartial\x18\x02 \x01(\bR\apartial\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1f\n" +
+	"\vschedule_id\x18\x04 \x01(\tR\n" +
+	"scheduleId\x127\n" +
+	"\vassignments\x18\x05 \x03(\v2\x15.paiban.v1.AssignmentR\vassignments\x12\x1a\n" +
+	"\bduration\x18\x06 \x01(\tR\bduration\x12\x12\n" +
+	"\x04seed\x18\a \x01(\x03R\x04seed\x127\n" +
+	"\n" +
+	"statistics\x18\b \x01(\v2\x17.google.protobuf.StructR\n" +
+	"statistics\x12D\n" +
+	"\x11constraint_result\x18\t \x01(\v2\x17.google.protobuf.StructR\x10constraintResult\x123\n" +
+	"\bunfilled\x18\n" +
+	" \x03(\v2\x17.google.protobuf.StructR\bunfilled\x129\n" +
+	"\vsuggestions\x18\v \x03(\v2\x17.google.protobuf.StructR\vsuggestions\x127\n" +
+	"\n" +
+	"compliance\x18\f \x01(\v2\x17.google.protobuf.StructR\n" +
+	"compliance\x12:\n" +
+	"\fcost_summary\x18\r \x01(\v2\x17.google.protobuf.StructR\vcostSummary\x12.\n" +
+	"\aon_call\x18\x0e \x03(\v2\x15.paiban.v1.AssignmentR\x06onCall\x125\n" +
+	"\tanomalies\x18\x0f \x03(\v2\x17.google.protobuf.StructR\tanomalies\x12@\n" +
+	"\x0funmapped_labels\x18\x10 \x03(\v2\x17.google.protobuf.StructR\x0eunmappedLabels\x12)\n" +
+	"\x10blackout_periods\x18\x11 \x03(\tR\x0fblackoutPeriods\x126\n" +
+	"\x17opening_hours_conflicts\x18\x12 \x03(\tR\x15openingHoursConflicts\x12>\n" +
+	"\x0eexternal_labor\x18\x13 \x01(\v2\x17.google.protobuf.StructR\rexternalLabor\x120\n" +
+	"\x14previous_schedule_id\x18\x14 \x01(\tR\x12previousScheduleId\x12/\n" +
+	"\x13history_assignments\x18\x15 \x01(\x05R\x12historyAssignments\x12/\n" +
+	"\x06stores\x18\x16 \x03(\v2\x17.google.protobuf.StructR\x06stores\x12P\n" +
+	"\x17expiring_certifications\x18\x17 \x03(\v2\x17.google.protobuf.StructR\x16expiringCertifications\"\x8a\x01\n" +
+	"\bProgress\x12\x14\n" +
+	"\x05phase\x18\x01 \x01(\tR\x05phase\x12\x1a\n" +
+	"\bfraction\x18\x02 \x01(\x01R\bfraction\x12\x14\n" +
+	"\x05round\x18\x03 \x01(\x05R\x05round\x12 \n" +
+	"\vassignments\x18\x04 \x01(\x05R\vassignments\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x01R\x05score\"\x82\x01\n" +
+	"\rGenerateEvent\x121\n" +
+	"\bprogress\x18\x01 \x01(\v2\x13.paiban.v1.ProgressH\x00R\bprogress\x125\n" +
+	"\x06result\x18\x02 \x01(\v2\x1b.paiban.v1.GenerateResponseH\x00R\x06resultB\a\n" +
+	"\x05event\"\xd7\x01\n" +
+	"\x0fValidateRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12?\n" +
+	"\vassignments\x18\x02 \x03(\v2\x1d.paiban.v1.ValidateAssignmentR\vassignments\x121\n" +
+	"\temployees\x18\x03 \x03(\v2\x13.paiban.v1.EmployeeR\temployees\x129\n" +
+	"\vconstraints\x18\x04 \x01(\v2\x17.google.protobuf.StructR\vconstraints\"\xba\x01\n" +
+	"\x12ValidateAssignment\x12\x1f\n" +
+	"\vemployee_id\x18\x01 \x01(\tR\n" +
+	"employeeId\x12\x19\n" +
+	"\bshift_id\x18\x02 \x01(\tR\ashiftId\x12\x12\n" +
+	"\x04date\x18\x03 \x01(\tR\x04date\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x04 \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x05 \x01(\tR\aendTime\x12\x1a\n" +
+	"\bposition\x18\x06 \x01(\tR\bposition\"C\n" +
+	"\x10ValidateResponse\x12/\n" +
+	"\x06result\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06result\"\xde\x03\n" +
+	"\x0fDispatchRequest\x12-\n" +
+	"\x05order\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x05order\x127\n" +
+	"\n" +
+	"candidates\x18\x02 \x03(\v2\x17.google.protobuf.StructR\n" +
+	"candidates\x123\n" +
+	"\bcustomer\x18\x03 \x01(\v2\x17.google.protobuf.StructR\bcustomer\x12:\n" +
+	"\ftoday_orders\x18\x04 \x03(\v2\x17.google.protobuf.StructR\vtodayOrders\x121\n" +
+	"\ahistory\x18\x05 \x03(\v2\x17.google.protobuf.StructR\ahistory\x126\n" +
+	"\n" +
+	"keep_apart\x18\x06 \x03(\v2\x17.google.protobuf.StructR\tkeepApart\x12\x1f\n" +
+	"\vmax_results\x18\a \x01(\x05R\n" +
+	"maxResults\x12+\n" +
+	"\x11window_caregivers\x18\b \x03(\tR\x10windowCaregivers\x129\n" +
+	"\vconstraints\x18\t \x01(\v2\x17.google.protobuf.StructR\vconstraints\"\xcc\x02\n" +
+	"\x14BatchDispatchRequest\x12/\n" +
+	"\x06orders\x18\x01 \x03(\v2\x17.google.protobuf.StructR\x06orders\x127\n" +
+	"\n" +
+	"candidates\x18\x02 \x03(\v2\x17.google.protobuf.StructR\n" +
+	"candidates\x123\n" +
+	"\bcustomer\x18\x03 \x01(\v2\x17.google.protobuf.StructR\bcustomer\x126\n" +
+	"\n" +
+	"keep_apart\x18\x04 \x03(\v2\x17.google.protobuf.StructR\tkeepApart\x12\"\n" +
+	"\foptimization\x18\x05 \x01(\tR\foptimization\x129\n" +
+	"\vconstraints\x18\x06 \x01(\v2\x17.google.protobuf.StructR\vconstraints\"\xe8\x03\n" +
+	"\x0fReassignRequest\x12-\n" +
+	"\x05order\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x05order\x126\n" +
+	"\x17unavailable_employee_id\x18\x02 \x01(\tR\x15unavailableEmployeeId\x127\n" +
+	"\n" +
+	"candidates\x18\x03 \x03(\v2\x17.google.protobuf.StructR\n" +
+	"candidates\x123\n" +
+	"\bcustomer\x18\x04 \x01(\v2\x17.google.protobuf.StructR\bcustomer\x125\n" +
+	"\tcustomers\x18\x05 \x03(\v2\x17.google.protobuf.StructR\tcustomers\x12:\n" +
+	"\ftoday_orders\x18\x06 \x03(\v2\x17.google.protobuf.StructR\vtodayOrders\x121\n" +
+	"\ahistory\x18\a \x03(\v2\x17.google.protobuf.StructR\ahistory\x12\x1f\n" +
+	"\vmax_results\x18\b \x01(\x05R\n" +
+	"maxResults\x129\n" +
+	"\vconstraints\x18\t \x01(\v2\x17.google.protobuf.StructR\vconstraints\"s\n" +
+	"\x10DispatchResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12/\n" +
+	"\x06result\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x06result\"\xe3\x01\n" +
+	"\fRouteRequest\x12/\n" +
+	"\x06orders\x18\x01 \x03(\v2\x17.google.protobuf.StructR\x06orders\x12>\n" +
+	"\x0estart_location\x18\x02 \x01(\v2\x17.google.protobuf.StructR\rstartLocation\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\tR\tstartTime\x12\x1b\n" +
+	"\tspeed_kmh\x18\x04 \x01(\x01R\bspeedKmh\x12&\n" +
+	"\x0freturn_to_start\x18\x05 \x01(\bR\rreturnToStart\"p\n" +
+	"\rRouteResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12/\n" +
+	"\x06result\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x06result\"F\n" +
+	"\x13ReportStatusRequest\x12/\n" +
+	"\x06status\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06status\"4\n" +
+	"\x11ListStatusRequest\x12\x1f\n" +
+	"\vemployee_id\x18\x01 \x01(\tR\n" +
+	"employeeId\"A\n" +
+	"\x0eStatusResponse\x12/\n" +
+	"\x06result\x18\x01 \x01(\v2\x17.google.protobuf.StructR\x06result2\xe5\x01\n" +
+	"\x0fScheduleService\x12C\n" +
+	"\bGenerate\x12\x1a.paiban.v1.GenerateRequest\x1a\x1b.paiban.v1.GenerateResponse\x12H\n" +
+	"\x0eGenerateStream\x12\x1a.paiban.v1.GenerateRequest\x1a\x18.paiban.v1.GenerateEvent0\x01\x12C\n" +
+	"\bValidate\x12\x1a.paiban.v1.ValidateRequest\x1a\x1b.paiban.v1.ValidateResponse2\xae\x03\n" +
+	"\x0fDispatchService\x12A\n" +
+	"\x06Single\x12\x1a.paiban.v1.DispatchRequest\x1a\x1b.paiban.v1.DispatchResponse\x12E\n" +
+	"\x05Batch\x12\x1f.paiban.v1.BatchDispatchRequest\x1a\x1b.paiban.v1.DispatchResponse\x12C\n" +
+	"\bReassign\x12\x1a.paiban.v1.ReassignRequest\x1a\x1b.paiban.v1.DispatchResponse\x12:\n" +
+	"\x05Route\x12\x17.paiban.v1.RouteRequest\x1a\x18.paiban.v1.RouteResponse\x12I\n" +
+	"\fReportStatus\x12\x1e.paiban.v1.ReportStatusRequest\x1a\x19.paiban.v1.StatusResponse\x12E\n" +
+	"\n" +
+	"ListStatus\x12\x1c.paiban.v1.ListStatusRequest\x1a\x19.paiban.v1.StatusResponseBL\n" +
+	"\x11com.paiban.api.v1P\x01Z5github.com/paiban/paiban/api/proto/paiban/v1;paibanv1b\x06proto3"
+
+var (
+	file_paiban_v1_paiban_proto_rawDescOnce sync.Once
+	file_paiban_v1_paiban_proto_rawDescData []byte
+)
+
+func file_paiban_v1_paiban_proto_rawDescGZIP() []byte {
+	file_paiban_v1_paiban_proto_rawDescOnce.Do(func() {
+		file_paiban_v1_paiban_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_paiban_v1_paiban_proto_rawDesc), len(file_paiban_v1_paiban_proto_rawDesc)))
+	})
+	return file_paiban_v1_paiban_proto_rawDescData
+}
+
+var file_paiban_v1_paiban_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_paiban_v1_paiban_proto_goTypes = []any{
+	(*GenerateRequest)(nil),      // 0: paiban.v1.GenerateRequest
+	(*Employee)(nil),             // 1: paiban.v1.Employee
+	(*Shift)(nil),                // 2: paiban.v1.Shift
+	(*Requirement)(nil),          // 3: paiban.v1.Requirement
+	(*GenerateOptions)(nil),      // 4: paiban.v1.GenerateOptions
+	(*Assignment)(nil),           // 5: paiban.v1.Assignment
+	(*GenerateResponse)(nil),     // 6: paiban.v1.GenerateResponse
+	(*Progress)(nil),             // 7: paiban.v1.Progress
+	(*GenerateEvent)(nil),        // 8: paiban.v1.GenerateEvent
+	(*ValidateRequest)(nil),      // 9: paiban.v1.ValidateRequest
+	(*ValidateAssignment)(nil),   // 10: paiban.v1.ValidateAssignment
+	(*ValidateResponse)(nil),     // 11: paiban.v1.ValidateResponse
+	(*DispatchRequest)(nil),      // 12: paiban.v1.DispatchRequest
+	(*BatchDispatchRequest)(nil), // 13: paiban.v1.BatchDispatchRequest
+	(*ReassignRequest)(nil),      // 14: paiban.v1.ReassignRequest
+	(*DispatchResponse)(nil),     // 15: paiban.v1.DispatchResponse
+	(*RouteRequest)(nil),         // 16: paiban.v1.RouteRequest
+	(*RouteResponse)(nil),        // 17: paiban.v1.RouteResponse
+	(*ReportStatusRequest)(nil),  // 18: paiban.v1.ReportStatusRequest
+	(*ListStatusRequest)(nil),    // 19: paiban.v1.ListStatusRequest
+	(*StatusResponse)(nil),       // 20: paiban.v1.StatusResponse
+	nil,                          // 21: paiban.v1.Employee.MonthlyShiftsCountsEntry
+	nil,                          // 22: paiban.v1.GenerateOptions.ScoringProfileEntry
+	(*structpb.Struct)(nil),      // 23: google.protobuf.Struct
+}
+var file_paiban_v1_paiban_proto_depIdxs = []int32{
+	1,  // 0: paiban.v1.GenerateRequest.employees:type_name -> paiban.v1.Employee
+	2,  // 1: paiban.v1.GenerateRequest.shifts:type_name -> paiban.v1.Shift
+	3,  // 2: paiban.v1.GenerateRequest.requirements:type_name -> paiban.v1.Requirement
+	23, // 3: paiban.v1.GenerateRequest.constraints:type_name -> google.protobuf.Struct
+	4,  // 4: paiban.v1.GenerateRequest.options:type_name -> paiban.v1.GenerateOptions
+	23, // 5: paiban.v1.GenerateRequest.external_workers:type_name -> google.protobuf.Struct
+	23, // 6: paiban.v1.GenerateRequest.stores:type_name -> google.protobuf.Struct
+	23, // 7: paiban.v1.GenerateRequest.teams:type_name -> google.protobuf.Struct
+	21, // 8: paiban.v1.Employee.monthly_shifts_counts:type_name -> paiban.v1.Employee.MonthlyShiftsCountsEntry
+	23, // 9: paiban.v1.Employee.preferences:type_name -> google.protobuf.Struct
+	23, // 10: paiban.v1.Employee.contract:type_name -> google.protobuf.Struct
+	23, // 11: paiban.v1.Employee.availability_windows:type_name -> google.protobuf.Struct
+	23, // 12: paiban.v1.Employee.availability:type_name -> google.protobuf.Struct
+	23, // 13: paiban.v1.Employee.unavailable_windows:type_name -> google.protobuf.Struct
+	23, // 14: paiban.v1.Employee.leaves:type_name -> google.protobuf.Struct
+	23, // 15: paiban.v1.Employee.verified_certifications:type_name -> google.protobuf.Struct
+	23, // 16: paiban.v1.Employee.certification_records:type_name -> google.protobuf.Struct
+	23, // 17: paiban.v1.Employee.skill_levels:type_name -> google.protobuf.Struct
+	23, // 18: paiban.v1.Requirement.skill_groups:type_name -> google.protobuf.Struct
+	23, // 19: paiban.v1.Requirement.skill_levels:type_name -> google.protobuf.Struct
+	22, // 20: paiban.v1.GenerateOptions.scoring_profile:type_name -> paiban.v1.GenerateOptions.ScoringProfileEntry
+	23, // 21: paiban.v1.Assignment.score_detail:type_name -> google.protobuf.Struct
+	5,  // 22: paiban.v1.GenerateResponse.assignments:type_name -> paiban.v1.Assignment
+	23, // 23: paiban.v1.GenerateResponse.statistics:type_name -> google.protobuf.Struct
+	23, // 24: paiban.v1.GenerateResponse.constraint_result:type_name -> google.protobuf.Struct
+	23, // 25: paiban.v1.GenerateResponse.unfilled:type_name -> google.protobuf.Struct
+	23, // 26: paiban.v1.GenerateResponse.suggestions:type_name -> google.protobuf.Struct
+	23, // 27: paiban.v1.GenerateResponse.compliance:type_name -> google.protobuf.Struct
+	23, // 28: paiban.v1.GenerateResponse.cost_summary:type_name -> google.protobuf.Struct
+	5,  // 29: paiban.v1.GenerateResponse.on_call:type_name -> paiban.v1.Assignment
+	23, // 30: paiban.v1.GenerateResponse.anomalies:type_name -> google.protobuf.Struct
+	23, // 31: paiban.v1.GenerateResponse.unmapped_labels:type_name -> google.protobuf.Struct
+	23, // 32: paiban.v1.GenerateResponse.external_labor:type_name -> google.protobuf.Struct
+	23, // 33: paiban.v1.GenerateResponse.stores:type_name -> google.protobuf.Struct
+	23, // 34: paiban.v1.GenerateResponse.expiring_certifications:type_name -> google.protobuf.Struct
+	7,  // 35: paiban.v1.GenerateEvent.progress:type_name -> paiban.v1.Progress
+	6,  // 36: paiban.v1.GenerateEvent.result:type_name -> paiban.v1.GenerateResponse
+	10, // 37: paiban.v1.ValidateRequest.assignments:type_name -> paiban.v1.ValidateAssignment
+	1,  // 38: paiban.v1.ValidateRequest.employees:type_name -> paiban.v1.Employee
+	23, // 39: paiban.v1.ValidateRequest.constraints:type_name -> google.protobuf.Struct
+	23, // 40: paiban.v1.ValidateResponse.result:type_name -> google.protobuf.Struct
+	23, // 41: paiban.v1.DispatchRequest.order:type_name -> google.protobuf.Struct
+	23, // 42: paiban.v1.DispatchRequest.candidates:type_name -> google.protobuf.Struct
+	23, // 43: paiban.v1.DispatchRequest.customer:type_name -> google.protobuf.Struct
+	23, // 44: paiban.v1.DispatchRequest.today_orders:type_name -> google.protobuf.Struct
+	23, // 45: paiban.v1.DispatchRequest.history:type_name -> google.protobuf.Struct
+	23, // 46: paiban.v1.DispatchRequest.keep_apart:type_name -> google.protobuf.Struct
+	23, // 47: paiban.v1.DispatchRequest.constraints:type_name -> google.protobuf.Struct
+	23, // 48: paiban.v1.BatchDispatchRequest.orders:type_name -> google.protobuf.Struct
+	23, // 49: paiban.v1.BatchDispatchRequest.candidates:type_name -> google.protobuf.Struct
+	23, // 50: paiban.v1.BatchDispatchRequest.customer:type_name -> google.protobuf.Struct
+	23, // 51: paiban.v1.BatchDispatchRequest.keep_apart:type_name -> google.protobuf.Struct
+	23, // 52: paiban.v1.BatchDispatchRequest.constraints:type_name -> google.protobuf.Struct
+	23, // 53: paiban.v1.ReassignRequest.order:type_name -> google.protobuf.Struct
+	23, // 54: paiban.v1.ReassignRequest.candidates:type_name -> google.protobuf.Struct
+	23, // 55: paiban.v1.ReassignRequest.customer:type_name -> google.protobuf.Struct
+	23, // 56: paiban.v1.ReassignRequest.customers:type_name -> google.protobuf.Struct
+	23, // 57: paiban.v1.ReassignRequest.today_orders:type_name -> google.protobuf.Struct
+	23, // 58: paiban.v1.ReassignRequest.history:type_name -> google.protobuf.Struct
+	23, // 59: paiban.v1.ReassignRequest.constraints:type_name -> google.protobuf.Struct
+	23, // 60: paiban.v1.DispatchResponse.result:type_name -> google.protobuf.Struct
+	23, // 61: paiban.v1.RouteRequest.orders:type_name -> google.protobuf.Struct
+	23, // 62: paiban.v1.RouteRequest.start_location:type_name -> google.protobuf.Struct
+	23, // 63: paiban.v1.RouteResponse.result:type_name -> google.protobuf.Struct
+	23, // 64: paiban.v1.ReportStatusRequest.status:type_name -> google.protobuf.Struct
+	23, // 65: paiban.v1.StatusResponse.result:type_name -> google.protobuf.Struct
+	0,  // 66: paiban.v1.ScheduleService.Generate:input_type -> paiban.v1.GenerateRequest
+	0,  // 67: paiban.v1.ScheduleService.GenerateStream:input_type -> paiban.v1.GenerateRequest
+	9,  // 68: paiban.v1.ScheduleService.Validate:input_type -> paiban.v1.ValidateRequest
+	12, // 69: paiban.v1.DispatchService.Single:input_type -> paiban.v1.DispatchRequest
+	13, // 70: paiban.v1.DispatchService.Batch:input_type -> paiban.v1.BatchDispatchRequest
+	14, // 71: paiban.v1.DispatchService.Reassign:input_type -> paiban.v1.ReassignRequest
+	16, // 72: paiban.v1.DispatchService.Route:input_type -> paiban.v1.RouteRequest
+	18, // 73: paiban.v1.DispatchService.ReportStatus:input_type -> paiban.v1.ReportStatusRequest
+	19, // 74: paiban.v1.DispatchService.ListStatus:input_type -> paiban.v1.ListStatusRequest
+	6,  // 75: paiban.v1.ScheduleService.Generate:output_type -> paiban.v1.GenerateResponse
+	8,  // 76: paiban.v1.ScheduleService.GenerateStream:output_type -> paiban.v1.GenerateEvent
+	11, // 77: paiban.v1.ScheduleService.Validate:output_type -> paiban.v1.ValidateResponse
+	15, // 78: paiban.v1.DispatchService.Single:output_type -> paiban.v1.DispatchResponse
+	15, // 79: paiban.v1.DispatchService.Batch:output_type -> paiban.v1.DispatchResponse
+	15, // 80: paiban.v1.DispatchService.Reassign:output_type -> paiban.v1.DispatchResponse
+	17, // 81: paiban.v1.DispatchService.Route:output_type -> paiban.v1.RouteResponse
+	20, // 82: paiban.v1.DispatchService.ReportStatus:output_type -> paiban.v1.StatusResponse
+	20, // 83: paiban.v1.DispatchService.ListStatus:output_type -> paiban.v1.StatusResponse
+	75, // [75:84] is the sub-list for method output_type
+	66, // [66:75] is the sub-list for method input_type
+	66, // [66:66] is the sub-list for extension type_name
+	66, // [66:66] is the sub-list for extension extendee
+	0,  // [0:66] is the sub-list for field type_name
+}
+
+func init() { file_paiban_v1_paiban_proto_init() }
+func file_paiban_v1_paiban_proto_init() {
+	if File_paiban_v1_paiban_proto != nil {
+		return
+	}
+	file_paiban_v1_paiban_proto_msgTypes[8].OneofWrappers = []any{
+		(*GenerateEvent_Progress)(nil),
+		(*GenerateEvent_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_paiban_v1_paiban_proto_rawDesc), len(file_paiban_v1_paiban_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   23,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_paiban_v1_paiban_proto_goTypes,
+		DependencyIndexes: file_paiban_v1_paiban_proto_depIdxs,
+		MessageInfos:      file_paiban_v1_paiban_proto_msgTypes,
+	}.Build()
+	File_paiban_v1_paiban_proto = out.File
+	file_paiban_v1_paiban_proto_goTypes = nil
+	file_paiban_v1_paiban_proto_depIdxs = nil
+}
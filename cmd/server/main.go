@@ -5,20 +5,47 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"sync"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/approval"
+	"github.com/paiban/paiban/internal/backfill"
+	"github.com/paiban/paiban/internal/bulk"
+	"github.com/paiban/paiban/internal/certification"
+	"github.com/paiban/paiban/internal/compliance"
+	"github.com/paiban/paiban/internal/config"
 	"github.com/paiban/paiban/internal/constraints"
+	"github.com/paiban/paiban/internal/database"
+	"github.com/paiban/paiban/internal/docalert"
+	"github.com/paiban/paiban/internal/genjob"
+	"github.com/paiban/paiban/internal/grpcapi"
 	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/hrsync"
+	"github.com/paiban/paiban/internal/memstore"
 	"github.com/paiban/paiban/internal/metrics"
+	"github.com/paiban/paiban/internal/middleware"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/internal/order"
+	"github.com/paiban/paiban/internal/payroll"
+	"github.com/paiban/paiban/internal/posimport"
+	"github.com/paiban/paiban/internal/publication"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/internal/share"
+	"github.com/paiban/paiban/internal/summary"
+	"github.com/paiban/paiban/internal/timebank"
+	"github.com/paiban/paiban/migrations"
+	"github.com/paiban/paiban/pkg/chaos"
+	"github.com/paiban/paiban/pkg/dispatcher/travel"
 	"github.com/paiban/paiban/pkg/logger"
+	"google.golang.org/grpc"
 )
 
 // 构建信息（通过 ldflags 注入）
@@ -28,10 +55,27 @@ var (
 	GitCommit = "unknown"
 )
 
+// v1Sunset API v1 计划下线时间
+var v1Sunset = time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+
 func main() {
+	// 加载配置：-config（默认取 CONFIG_PATH）指定的 YAML 配置文件，环境变量覆盖同名配置
+	configPath := flag.String("config", os.Getenv("CONFIG_PATH"), "YAML 配置文件路径")
+	migrateOnly := flag.Bool("migrate", false, "执行数据库迁移后退出")
+	grpcPort := flag.Int("grpc-port", -1, "gRPC 服务端口，覆盖配置 server.grpc_port（0 表示不启动）")
+	flag.Parse()
+	cfg, err := config.LoadFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载配置失败: %v\n", err)
+		os.Exit(1)
+	}
+	if *grpcPort >= 0 {
+		cfg.Server.GRPCPort = *grpcPort
+	}
+
 	// 初始化日志
 	logger.Init(logger.Config{
-		Level:  os.Getenv("APP_LOG_LEVEL"),
+		Level:  cfg.App.LogLevel,
 		Format: "console",
 	})
 
@@ -40,15 +84,333 @@ func main() {
 	fmt.Printf("Build: %s (%s)\n", BuildTime, GitCommit)
 	fmt.Println()
 
-	// 获取端口配置
-	port := os.Getenv("APP_PORT")
-	if port == "" {
-		port = "7012"
-	}
+	port := strconv.Itoa(cfg.App.Port)
 
-	// 创建处理器（无数据库模式，适用于测试和简单场景）
-	// 如需持久化，请使用 handler.NewScheduleHandler(scheduleRepo, employeeRepo, shiftRepo)
+	// 创建处理器：配置数据库地址（database.host / DB_HOST）时连接数据库，生成的排班及其分配通过 ScheduleRepository 保存；
+	// 否则为无数据库模式（适用于测试和简单场景）
 	scheduleHandler := handler.NewScheduleHandlerWithoutDB()
+	var scheduleRepo *repository.ScheduleRepository
+	var constraintRepo *repository.ConstraintRepository
+	var scenarioTemplateRepo *repository.ScenarioTemplateRepository
+	var employeeRepo *repository.EmployeeRepository
+	var shiftRepo *repository.ShiftRepository
+	var requirementRepo *repository.RequirementRepository
+	var teamRepo *repository.TeamRepository
+	if *migrateOnly && !cfg.Database.Enabled() {
+		logger.Error().Msg("未配置数据库地址（database.host / DB_HOST），无法执行迁移")
+		os.Exit(1)
+	}
+	if cfg.Database.Enabled() {
+		db, err := database.New(&cfg.Database)
+		if err != nil {
+			logger.Error().Err(err).Msg("连接数据库失败")
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		// 数据库迁移：执行 migrations 目录中内嵌的未执行迁移（database.auto_migrate 或 -migrate）
+		if cfg.Database.AutoMigrate || *migrateOnly {
+			applied, err := db.Migrate(context.Background(), migrations.FS)
+			if err != nil {
+				db.Close()
+				logger.Error().Err(err).Msg("数据库迁移失败")
+				os.Exit(1)
+			}
+			logger.Info().Int("applied", len(applied)).Msg("数据库迁移完成")
+			if *migrateOnly {
+				return
+			}
+		}
+
+		// 连接池状态写入 paiban_db_connections 指标
+		dbCtx, stopDBStats := context.WithCancel(context.Background())
+		defer stopDBStats()
+		go db.ReportStats(dbCtx, 15*time.Second)
+
+		scheduleRepo = repository.NewScheduleRepository(db)
+		employeeRepo = repository.NewEmployeeRepository(db)
+		shiftRepo = repository.NewShiftRepository(db)
+		scheduleHandler = handler.NewScheduleHandler(scheduleRepo, employeeRepo, shiftRepo)
+		constraintRepo = repository.NewConstraintRepository(db)
+		scheduleHandler.SetConstraintRepository(constraintRepo)
+		scenarioTemplateRepo = repository.NewScenarioTemplateRepository(db)
+		scheduleHandler.SetScenarioTemplateRepository(scenarioTemplateRepo)
+		requirementRepo = repository.NewRequirementRepository(db)
+		scheduleHandler.SetRequirementRepository(requirementRepo)
+		teamRepo = repository.NewTeamRepository(db)
+		scheduleHandler.SetTeamRepository(teamRepo)
+		handler.SetAuditRepository(repository.NewAuditRepository(db))
+	}
+	// 生成默认值：请求未指定超时、优化级别、并行协程数时使用 scheduler 配置，默认约束参数优先级最低
+	scheduleHandler.SetDefaults(handler.GenerateDefaults{
+		Timeout:           cfg.Scheduler.DefaultTimeout,
+		OptimizationLevel: cfg.Scheduler.OptimizationLevel,
+		Workers:           cfg.Scheduler.Workers,
+		Constraints:       cfg.Scheduler.Constraints,
+	})
+
+	// 内存状态存储（无数据库模式下可选启用快照持久化）
+	// store.snapshot_path 为空时不启用；store.snapshot_interval 控制快照间隔（默认 1m）
+	storeCtx, stopStore := context.WithCancel(context.Background())
+	storeDone := make(chan struct{})
+	publicationHandler := handler.NewPublicationHandler(nil, nil)
+	draftHandler := handler.NewDraftHandler(nil)
+	scheduleRecordHandler := handler.NewScheduleRecordHandler(scheduleRepo, nil, draftHandler)
+	swapHandler := handler.NewSwapHandler(nil, scheduleHandler, draftHandler)
+	gridHandler := handler.NewGridHandler(nil)
+	exportHandler := handler.NewExportHandler(nil, scheduleRepo)
+	analyticsHandler := handler.NewAnalyticsHandler(nil)
+	summaryHandler := handler.NewSummaryHandler(nil, nil)
+	hrSyncHandler := handler.NewHRSyncHandler(nil, nil)
+	aliasHandler := handler.NewAliasHandler(nil)
+	budgetHandler := handler.NewBudgetHandler(nil)
+	blackoutHandler := handler.NewBlackoutHandler(nil)
+	openingHoursHandler := handler.NewOpeningHoursHandler(nil)
+	approvalHandler := handler.NewApprovalHandler(nil, nil)
+	constraintConfigHandler := handler.NewConstraintConfigHandler(nil)
+	backfillHandler := handler.NewBackfillHandler(nil)
+	bulkHandler := handler.NewBulkHandler(nil, nil)
+	payrollHandler := handler.NewPayrollHandler(nil)
+	externalPoolHandler := handler.NewExternalPoolHandler(nil)
+	certificationHandler := handler.NewCertificationHandler(nil, nil)
+	timeBankHandler := handler.NewTimeBankHandler(nil, nil)
+	orderHandler := handler.NewOrderHandler(nil, nil)
+	carePlanHandler := handler.NewCarePlanHandler(nil, nil)
+	complianceHandler := handler.NewComplianceHandler(nil, nil)
+	documentAlertHandler := handler.NewDocumentAlertHandler(nil, nil)
+	requirementImportHandler := handler.NewRequirementImportHandler(nil, nil)
+	shareHandler := handler.NewShareHandler(nil, nil)
+	generateJobHandler := handler.NewGenerateJobHandler(nil, nil)
+	patternHandler := handler.NewPatternHandler(nil)
+	holidayHandler := handler.NewHolidayHandler(nil)
+	webhookHandler := handler.NewWebhookHandler(nil)
+
+	// 约束目录：内置约束库与场景模板，配置 scheduler.catalog_path 时合并该 JSON 文件中的定义，
+	// 并可通过管理接口在运行时重新加载
+	var catalogSource constraints.Source
+	if path := cfg.Scheduler.CatalogPath; path != "" {
+		catalogSource = constraints.FileSource{Path: path}
+	}
+	catalog := constraints.NewCatalog(catalogSource)
+	if catalogSource != nil {
+		status, err := catalog.Reload(false)
+		if err != nil {
+			logger.Error().Err(err).Str("source", catalogSource.Name()).Msg("加载约束目录失败")
+			os.Exit(1)
+		}
+		logger.Info().Interface("status", status).Msg("约束目录已加载")
+	}
+	catalogHandler := handler.NewCatalogHandler(catalog)
+	orgConstraintHandler := handler.NewOrgConstraintHandler(constraintRepo, nil, catalog)
+	scenarioTemplateHandler := handler.NewScenarioTemplateHandler(scenarioTemplateRepo, nil, catalog)
+	employeeHandler := handler.NewEmployeeHandler(employeeRepo, nil)
+	shiftHandler := handler.NewShiftHandler(shiftRepo, nil)
+	teamHandler := handler.NewTeamHandler(teamRepo, nil)
+	requirementHandler := handler.NewRequirementHandler(requirementRepo, shiftRepo, nil, scheduleHandler)
+
+	// 限流：按客户端（API 密钥、令牌或 IP）和接口分别计数，api.rate_limit_endpoints 配置接口规则
+	rateLimiter := middleware.NewRateLimiter(cfg.API.RateLimits())
+	rateLimitHandler := handler.NewRateLimitHandler(rateLimiter)
+
+	chaosHandler := handler.NewChaosHandler()
+	if chaos.Enabled {
+		logger.Warn().Msg("故障注入已启用（-tags chaos），请勿在生产环境使用")
+	}
+
+	// 路程估算：配置 dispatcher.travel.provider（osrm/amap/google）时派单和路线规划按路网距离估算，
+	// 服务不可用时退回直线距离；未配置时按直线距离
+	if t := cfg.Dispatcher.Travel; t.Provider != "" {
+		provider, err := travel.New(travel.Config{
+			Provider: t.Provider,
+			URL:      t.URL,
+			Key:      t.Key,
+			SpeedKmh: t.SpeedKmh,
+			CacheTTL: t.CacheTTL,
+		})
+		if err != nil {
+			logger.Error().Err(err).Str("provider", t.Provider).Msg("配置路程服务失败")
+			os.Exit(1)
+		}
+		handler.SetTravelProvider(provider)
+		logger.Info().Str("provider", provider.Name()).Msg("路程服务已配置")
+	}
+
+	// 通知投递：配置 notify.webhook_url 时以 Webhook 投递，否则写入日志
+	var notifier notify.Notifier = notify.LogNotifier{}
+	if url := cfg.Notify.WebhookURL; url != "" {
+		notifier = notify.NewWebhookNotifier(url)
+	}
+	if snapshotPath := cfg.Store.SnapshotPath; snapshotPath != "" {
+		store := memstore.New(snapshotPath)
+		if err := store.Load(); err != nil {
+			logger.Error().Err(err).Str("path", snapshotPath).Msg("加载内存快照失败")
+			os.Exit(1)
+		}
+		interval := cfg.Store.SnapshotInterval
+
+		// 通知订阅：组织订阅的事件异步投递到 Webhook（HMAC 签名）或邮件（notify.smtp），
+		// 失败按指数退避重试（notify.webhook_max_attempts、notify.webhook_backoff）
+		dispatcher := notify.NewDispatcher(notifier, store)
+		dispatcher.MaxAttempts, dispatcher.Backoff = cfg.Notify.WebhookMaxAttempts, cfg.Notify.WebhookBackoff
+		if smtp := cfg.Notify.SMTP; smtp.Host != "" {
+			dispatcher.SetMailer(notify.NewSMTPMailer(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.From))
+		}
+		notifier = dispatcher
+		webhookHandler = handler.NewWebhookHandler(store)
+		go func() {
+			<-storeCtx.Done()
+			dispatcher.Close()
+		}()
+
+		scheduleHandler.SetStore(store)
+		scheduleHandler.SetNotifier(notifier)
+		draftHandler = handler.NewDraftHandler(store)
+		draftHandler.SetNotifier(notifier)
+		draftHandler.SetChecker(scheduleHandler.HardViolations)
+		scheduleRecordHandler = handler.NewScheduleRecordHandler(scheduleRepo, store, draftHandler)
+		swapHandler = handler.NewSwapHandler(store, scheduleHandler, draftHandler)
+		swapHandler.SetScheduleRepository(scheduleRepo)
+		swapHandler.SetNotifier(notifier)
+		gridHandler = handler.NewGridHandler(store)
+		exportHandler = handler.NewExportHandler(store, scheduleRepo)
+		analyticsHandler = handler.NewAnalyticsHandler(store)
+
+		// 排班发布：按组织发布规则到点自动公布（jobs.publication_check_interval，默认 1m）
+		// 发布前按生成时的约束配置复核硬约束；发布、归档记录审计并通知下游系统
+		publisher := publication.NewPublisher(store, notifier)
+		publisher.SetChecker(scheduleHandler.HardViolations)
+		publicationHandler = handler.NewPublicationHandler(store, publisher)
+		go publisher.Run(storeCtx, cfg.Jobs.PublicationCheckInterval)
+
+		// 员工月度汇总：月末后自动推送上月汇总（jobs.summary_check_interval，默认 1h）
+		summaryService := summary.NewService(store, notifier)
+		summaryHandler = handler.NewSummaryHandler(store, summaryService)
+		go summaryService.Run(storeCtx, cfg.Jobs.SummaryCheckInterval)
+
+		// 审批：审批人外出时按委托转交，超时催办并升级（jobs.approval_check_interval，默认 15m）
+		approvalService := approval.NewService(store, notifier)
+		approvalHandler = handler.NewApprovalHandler(store, approvalService)
+
+		// 调休账户：加班审批通过后按组织调休策略拆分为加班费和调休
+		timeBankService := timebank.NewService(store)
+		approvalService.OnApproved(timeBankService.OnApproved)
+		timeBankHandler = handler.NewTimeBankHandler(store, timeBankService)
+		go approvalService.Run(storeCtx, cfg.Jobs.ApprovalCheckInterval)
+
+		// HR 系统同步：事件进入有界队列（jobs.hrsync_queue_size），按 jobs.hrsync_rate 个/秒匀速处理
+		syncer := hrsync.NewSyncer(store, cfg.Jobs.HRSyncQueueSize)
+		hrSyncHandler = handler.NewHRSyncHandler(store, syncer)
+		go syncer.Run(storeCtx, cfg.Jobs.HRSyncRate)
+
+		// 标签别名：排班、派单和 HR 同步按组织别名归一化技能/岗位/资质
+		aliasHandler = handler.NewAliasHandler(store)
+		handler.SetDispatchStore(store)
+
+		// 审计日志：未配置数据库时记录在排班存储中
+		handler.SetAuditStore(store)
+
+		// 门店工时预算：排班生成/验证按预算约束，工作量统计输出预算对比
+		budgetHandler = handler.NewBudgetHandler(store)
+		handler.SetWorkloadStore(store)
+
+		// 请假管控期：超额请假待审核，期间排班需求提升优先级
+		blackoutHandler = handler.NewBlackoutHandler(store)
+
+		// 门店营业时间：排班生成/验证按营业时间约束班次
+		openingHoursHandler = handler.NewOpeningHoursHandler(store)
+
+		// 组织约束配置版本：跨组织、跨版本对比规则设置
+		constraintConfigHandler = handler.NewConstraintConfigHandler(store)
+
+		// 组织级约束配置：未配置数据库时保存在内存存储，排班请求未提供 constraints 时作为默认约束
+		orgConstraintHandler = handler.NewOrgConstraintHandler(constraintRepo, store, catalog)
+
+		// 自定义场景模板：排班请求的 scenario 为自定义场景标识时以模板约束为基础
+		scenarioTemplateHandler = handler.NewScenarioTemplateHandler(scenarioTemplateRepo, store, catalog)
+
+		// 员工档案：排班请求未提供员工列表时使用组织的在职员工
+		employeeHandler = handler.NewEmployeeHandler(employeeRepo, store)
+
+		// 班次定义：排班请求可按编码引用，未提供班次列表时使用组织的启用班次
+		shiftHandler = handler.NewShiftHandler(shiftRepo, store)
+
+		// 班组：排班请求未提供 teams 时使用组织的班组，求解器尽量整组安排到同一班次
+		teamHandler = handler.NewTeamHandler(teamRepo, store)
+
+		// 排班需求：可按周模式批量生成，排班请求未提供需求时使用组织在排班周期内的需求
+		requirementHandler = handler.NewRequirementHandler(requirementRepo, shiftRepo, store, scheduleHandler)
+
+		// 循环排班模板：保存按周重复的轮班表，展开时检测冲突
+		patternHandler = handler.NewPatternHandler(store)
+
+		// 节假日日历：组织自定义节假日，排班时与内置法定节假日合并
+		holidayHandler = handler.NewHolidayHandler(store)
+
+		// 历史排班回填：从仅含分配的历史数据推断班次定义
+		backfillService := backfill.NewService(store)
+		backfillHandler = handler.NewBackfillHandler(backfillService)
+
+		// 批量作业：导入/派单/验证拆分为批次后台执行，每个组织每秒最多启动 jobs.bulk_batch_rate 个批次，
+		// jobs.bulk_workers 个作业并发执行
+		bulkRunner := bulk.NewRunner(store, cfg.Jobs.BulkBatchRate, cfg.Jobs.BulkWorkers)
+		handler.RegisterBulkProcessors(bulkRunner, scheduleHandler, backfillService)
+		bulkHandler = handler.NewBulkHandler(store, bulkRunner)
+		go bulkRunner.Run(storeCtx)
+
+		// 工资结账：锁定已结账期间的分配，修改需走调整流程并单独记录工时差额
+		payrollHandler = handler.NewPayrollHandler(payroll.NewService(store))
+
+		// 外部人员池：派遣/零工人员在内部员工排满后补位
+		externalPoolHandler = handler.NewExternalPoolHandler(store)
+
+		// 证书材料：提交、核验、驳回，资质约束可要求证书已核验
+		certificationHandler = handler.NewCertificationHandler(store, certification.NewService(store))
+
+		// 服务订单：状态流转、完成凭证（客户签名、照片）和结算导出；护理计划按服务项目生成待派单订单
+		orderService := order.NewService(store)
+		orderHandler = handler.NewOrderHandler(store, orderService)
+		carePlanHandler = handler.NewCarePlanHandler(store, orderService)
+
+		// 工时合规证明：已结账期间按工时类硬性规则核查并签发，配置 security.compliance_signing_key 时附 HMAC 签名
+		complianceService := compliance.NewService(store)
+		if key := cfg.Security.ComplianceSigningKey; key != "" {
+			complianceService.SetSigningKey(key)
+		}
+		complianceHandler = handler.NewComplianceHandler(store, complianceService)
+
+		// 证件到期提醒：证书、签证/工作许可和合同到期前按类型提前提醒，每日简报推送给管理者（jobs.document_alert_check_interval，默认 1h）
+		documentAlertService := docalert.NewService(store, notifier)
+		documentAlertHandler = handler.NewDocumentAlertHandler(store, documentAlertService)
+		go documentAlertService.Run(storeCtx, cfg.Jobs.DocumentAlertCheckInterval)
+
+		// POS 需求导入：小时业务量按人效换算为班次草稿需求，审核采用后写入组织需求
+		requirementImportHandler = handler.NewRequirementImportHandler(store, posimport.NewService(store))
+
+		// 排班分享链接：security.share_link_secret 为签名密钥，未配置时使用随机密钥（重启后已发出的链接失效）
+		shareHandler = handler.NewShareHandler(store, share.NewService(store, cfg.Security.ShareLinkSecret))
+
+		// 异步排班生成：大规模排班保存为作业后台求解，jobs.generate_job_workers 个作业同时求解
+		generateRunner := genjob.NewRunner(store, cfg.Jobs.GenerateJobWorkers, handler.GenerateJobProcessor(scheduleHandler))
+		scheduleHandler.SetJobRunner(generateRunner)
+		generateJobHandler = handler.NewGenerateJobHandler(store, generateRunner)
+		go generateRunner.Run(storeCtx)
+
+		go func() {
+			defer close(storeDone)
+			store.Run(storeCtx, interval)
+		}()
+		logger.Info().
+			Str("path", snapshotPath).
+			Dur("interval", interval).
+			Interface("records", store.Stats()).
+			Msg("内存状态存储已启用")
+	} else {
+		close(storeDone)
+	}
+	// 配置数据库时发布、归档同步数据库中的排班状态；未启用内存存储时直接在数据库中发布、归档
+	if scheduleRepo != nil {
+		publicationHandler.SetScheduleRepository(scheduleRepo)
+	}
 
 	// 创建 HTTP 服务器
 	mux := http.NewServeMux()
@@ -83,21 +445,152 @@ func main() {
 			"message": "PaiBan 排班引擎 API v1",
 			"endpoints": {
 				"schedule": {
-					"generate": "POST /api/v1/schedule/generate",
-					"validate": "POST /api/v1/schedule/validate"
+					"generate": "POST /api/v1/schedule/generate[?async=true]",
+					"jobs": "GET /api/v1/schedule/jobs",
+					"job": "GET /api/v1/schedule/jobs/{id}",
+					"job_result": "GET /api/v1/schedule/jobs/{id}/result",
+					"job_cancel": "POST /api/v1/schedule/jobs/{id}/cancel",
+					"job_events": "GET /api/v1/schedule/jobs/{id}/events",
+					"validate": "POST /api/v1/schedule/validate",
+					"compare": "POST /api/v1/schedule/compare",
+					"requirements": "GET|POST /api/v1/requirements",
+					"requirement": "GET|PUT|DELETE /api/v1/requirements/{id}",
+					"requirements_bulk": "POST|PATCH /api/v1/requirements/bulk",
+					"requirements_parse": "POST /api/v1/requirements/parse",
+					"requirements_forecast": "POST /api/v1/requirements/forecast",
+					"schedules": "GET /api/v1/schedules",
+					"schedule": "GET|DELETE /api/v1/schedules/{id}",
+					"assignments": "GET|PATCH|POST /api/v1/schedules/{id}/assignments",
+					"assignment": "PATCH|DELETE /api/v1/schedules/{id}/assignments/{assignment_id}",
+					"publish": "POST /api/v1/schedules/{id}/publish",
+					"archive": "POST /api/v1/schedules/{id}/archive",
+					"history": "GET /api/v1/schedules/{id}/history",
+					"versions": "GET /api/v1/schedules/{id}/versions",
+					"diff": "GET /api/v1/schedules/{id}/diff?from=&to=",
+					"rollback": "POST /api/v1/schedules/{id}/rollback/{version}",
+					"swap_evaluate": "POST /api/v1/swap/evaluate",
+					"swap_apply": "POST /api/v1/swap/apply",
+					"swap_candidates": "POST /api/v1/swap/candidates",
+					"grid": "GET /api/v1/schedules/{id}/grid",
+					"export": "POST /api/v1/schedules/export",
+					"publication_rule": "GET|PUT /api/v1/orgs/{org_id}/publication-rule",
+					"aliases": "GET|PUT /api/v1/orgs/{org_id}/aliases",
+					"store_budgets": "GET|PUT /api/v1/orgs/{org_id}/store-budgets",
+					"opening_hours": "GET|PUT /api/v1/orgs/{org_id}/opening-hours",
+					"blackout_periods": "GET|PUT /api/v1/orgs/{org_id}/blackout-periods",
+					"blackout_report": "GET /api/v1/orgs/{org_id}/blackout-periods/report",
+					"leave_review": "POST /api/v1/employees/{employee_id}/availability/{date}/review",
+					"backfill": "POST /api/v1/orgs/{org_id}/backfill",
+					"payroll_closes": "GET|POST /api/v1/orgs/{org_id}/payroll/closes",
+					"payroll_adjustments": "GET|POST /api/v1/orgs/{org_id}/payroll/adjustments",
+					"payroll_report": "GET /api/v1/orgs/{org_id}/payroll/report?month=YYYY-MM",
+					"time_bank": "GET /api/v1/orgs/{org_id}/time-bank",
+					"time_bank_adjustments": "POST /api/v1/orgs/{org_id}/time-bank/adjustments",
+					"time_bank_policy": "GET|PUT /api/v1/orgs/{org_id}/time-bank/policy",
+					"external_workers": "GET|PUT /api/v1/orgs/{org_id}/external-workers",
+					"certification_documents": "GET|POST /api/v1/orgs/{org_id}/certification-documents",
+					"certification_verify": "POST /api/v1/orgs/{org_id}/certification-documents/{id}/verify",
+					"certification_reject": "POST /api/v1/orgs/{org_id}/certification-documents/{id}/reject",
+					"orders": "GET|POST /api/v1/orgs/{org_id}/orders",
+					"order_status": "POST /api/v1/orgs/{org_id}/orders/{id}/status",
+					"order_completion_proof": "GET|PUT /api/v1/orgs/{org_id}/orders/{id}/completion-proof",
+					"order_billing_export": "GET /api/v1/orgs/{org_id}/orders/billing-export",
+					"customer_caregivers": "GET /api/v1/orgs/{org_id}/customer-caregivers",
+					"completion_policy": "GET|PUT /api/v1/orgs/{org_id}/completion-policy",
+					"holidays": "GET /api/v1/holidays?year=YYYY[&org_id=]",
+					"org_holidays": "GET|POST /api/v1/orgs/{org_id}/holidays",
+					"org_holiday": "GET|PUT|DELETE /api/v1/orgs/{org_id}/holidays/{id}",
+					"webhooks": "GET|POST /api/v1/webhooks",
+					"webhook": "GET|PUT|DELETE /api/v1/webhooks/{id}",
+					"webhook_deliveries": "GET /api/v1/webhooks/{id}/deliveries",
+					"audit": "GET /api/v1/audit?org_id=&from=&to=",
+					"jurisdictions": "GET /api/v1/jurisdictions",
+					"care_plans": "GET|POST /api/v1/orgs/{org_id}/care-plans",
+					"care_plan": "GET /api/v1/orgs/{org_id}/care-plans/{id}",
+					"care_plan_preview_orders": "POST /api/v1/orgs/{org_id}/care-plans/{id}/preview-orders",
+					"care_plan_generate_orders": "POST /api/v1/orgs/{org_id}/care-plans/{id}/generate-orders",
+					"compliance_certificates": "GET|POST /api/v1/orgs/{org_id}/compliance-certificates",
+					"compliance_certificate": "GET /api/v1/orgs/{org_id}/compliance-certificates/{id}?format=json|pdf",
+					"compliance_verify": "POST /api/v1/compliance-certificates/verify",
+					"document_alerts": "GET /api/v1/orgs/{org_id}/document-alerts?date=YYYY-MM-DD",
+					"document_alert_policy": "GET|PUT /api/v1/orgs/{org_id}/document-alert-policy",
+					"requirement_imports": "GET|POST /api/v1/orgs/{org_id}/requirement-imports",
+					"requirement_import": "GET /api/v1/orgs/{org_id}/requirement-imports/{id}",
+					"requirement_import_review": "POST /api/v1/orgs/{org_id}/requirement-imports/{id}/review",
+					"share_links": "GET|POST /api/v1/schedules/{id}/share-links",
+					"share_link_revoke": "POST /api/v1/schedules/{id}/share-links/{link_id}/revoke",
+					"share_link_accesses": "GET /api/v1/schedules/{id}/share-links/{link_id}/accesses",
+					"share_view": "GET /share/{token}?format=html|json"
+				},
+				"bulk": {
+					"jobs": "GET|POST /api/v1/bulk/jobs",
+					"job": "GET /api/v1/bulk/jobs/{id}",
+					"results": "GET /api/v1/bulk/jobs/{id}/results",
+					"resume": "POST /api/v1/bulk/jobs/{id}/resume",
+					"cancel": "POST /api/v1/bulk/jobs/{id}/cancel"
+				},
+				"approvals": {
+					"approvals": "GET|POST /api/v1/orgs/{org_id}/approvals",
+					"approval": "GET /api/v1/approvals/{id}",
+					"decision": "POST /api/v1/approvals/{id}/decision",
+					"delegations": "GET|POST /api/v1/orgs/{org_id}/delegations",
+					"delete_delegation": "DELETE /api/v1/orgs/{org_id}/delegations/{id}",
+					"policy": "GET|PUT /api/v1/orgs/{org_id}/approval-policy"
+				},
+				"shifts": {
+					"list": "GET|POST /api/v1/shifts",
+					"shift": "GET|PUT|DELETE /api/v1/shifts/{id}"
+				},
+				"employees": {
+					"list": "GET|POST /api/v1/employees",
+					"employee": "GET|PUT|DELETE /api/v1/employees/{employee_id}",
+					"import": "POST /api/v1/employees/import",
+					"schedule": "GET /api/v1/employees/{employee_id}/schedule",
+					"fatigue": "GET /api/v1/employees/{employee_id}/fatigue",
+					"teams": "GET|PUT /api/v1/orgs/{org_id}/teams"
 				},
 				"constraints": {
-					"templates": "GET /api/v1/constraints/templates"
+					"templates": "GET /api/v1/constraints/templates",
+					"library": "GET /api/v1/constraints/library",
+					"catalog": "GET /api/v1/admin/constraints/catalog",
+					"org_config": "GET|PUT /api/v1/orgs/{org_id}/constraint-config",
+					"org_config_versions": "GET /api/v1/orgs/{org_id}/constraint-config/versions",
+					"schedule_cycle": "GET|PUT /api/v1/orgs/{org_id}/schedule-cycle",
+					"diff": "POST /api/v1/constraints/diff",
+					"org_constraints": "GET|POST /api/v1/constraints",
+					"org_constraint": "GET|PUT|DELETE /api/v1/constraints/{id}",
+					"scenario_templates": "GET|POST /api/v1/scenario-templates",
+					"scenario_template": "GET|PUT|DELETE /api/v1/scenario-templates/{id}",
+					"clone_scenario_template": "POST /api/v1/scenario-templates/{id}/clone",
+					"reload": "POST /api/v1/admin/constraints/reload"
 				},
 				"stats": {
 					"fairness": "POST /api/v1/stats/fairness",
 					"coverage": "POST /api/v1/stats/coverage",
-					"workload": "POST /api/v1/stats/workload"
+					"workload": "POST /api/v1/stats/workload",
+					"cost": "POST /api/v1/stats/cost",
+					"preference_satisfaction": "GET /api/v1/stats/preference-satisfaction",
+					"fatigue": "GET /api/v1/orgs/{org_id}/fatigue",
+					"attendance_variance": "POST /api/v1/stats/attendance-variance"
 				},
 				"dispatch": {
 					"single": "POST /api/v1/dispatch/single",
 					"batch": "POST /api/v1/dispatch/batch",
-					"route": "POST /api/v1/dispatch/route"
+					"reassign": "POST /api/v1/dispatch/reassign",
+					"constraints_library": "GET /api/v1/dispatch/constraints/library",
+					"org_constraints": "GET|PUT /api/v1/orgs/{org_id}/dispatch-constraints",
+					"route": "POST /api/v1/dispatch/route",
+					"status": "POST|GET /api/v1/dispatch/status"
+				},
+				"careplan": {
+					"create": "POST /api/v1/careplan/create",
+					"generate_orders": "POST /api/v1/careplan/generate-orders",
+					"recommend_carers": "POST /api/v1/careplan/recommend-carers"
+				},
+				"admin": {
+					"faults": "GET|POST|DELETE /api/v1/admin/faults",
+					"rate_limits": "GET /api/v1/admin/rate-limits",
+					"config": "GET /api/v1/admin/config"
 				}
 			}
 		}`))
@@ -105,15 +598,208 @@ func main() {
 
 	// 排班生成 API
 	mux.HandleFunc("/api/v1/schedule/generate", scheduleHandler.Generate)
+	mux.HandleFunc("/api/v1/schedule/jobs", generateJobHandler.Jobs)
+	mux.HandleFunc("/api/v1/schedule/jobs/{id}", generateJobHandler.Job)
+	mux.HandleFunc("/api/v1/schedule/jobs/{id}/result", generateJobHandler.Result)
+	mux.HandleFunc("/api/v1/schedule/jobs/{id}/cancel", generateJobHandler.Cancel)
+	mux.HandleFunc("/api/v1/schedule/jobs/{id}/events", generateJobHandler.Events)
+
+	// 循环排班模板：按周重复的轮班表展开为未来若干周的排班
+	mux.HandleFunc("/api/v1/schedule/patterns", patternHandler.Collection)
+	mux.HandleFunc("/api/v1/schedule/patterns/{id}", patternHandler.Item)
+	mux.HandleFunc("/api/v1/schedule/patterns/{id}/expand", patternHandler.Expand)
+
+	// 节假日日历：内置中国法定节假日，组织可自定义节假日
+	mux.HandleFunc("/api/v1/holidays", holidayHandler.Calendar)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/holidays", holidayHandler.Collection)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/holidays/{id}", holidayHandler.Item)
+
+	// 通知订阅：排班发布、分配修改、换班通过、缺员等事件投递到 Webhook 或邮件
+	mux.HandleFunc("/api/v1/webhooks", webhookHandler.Collection)
+	mux.HandleFunc("/api/v1/webhooks/{id}", webhookHandler.Item)
+	mux.HandleFunc("/api/v1/webhooks/{id}/deliveries", webhookHandler.Deliveries)
+
+	// 审计日志：排班生成/发布/归档、分配修改、换班和约束配置变更
+	mux.HandleFunc("/api/v1/audit", handler.AuditHandler)
+
+	// 劳动法合规规则包 API（排班请求的 jurisdiction 可选值）
+	mux.HandleFunc("/api/v1/jurisdictions", handler.ListJurisdictionsHandler)
 
 	// 排班验证 API
 	mux.HandleFunc("/api/v1/schedule/validate", scheduleHandler.Validate)
 
+	// 排班方案对比 API（多组约束权重试算或已有方案的并排对比）
+	mux.HandleFunc("/api/v1/schedule/compare", scheduleHandler.Compare)
+
+	// 排班需求 API（增删改查；bulk 的 POST 按周模式批量生成，PATCH 批量修改并预览试算结果，可选提交）
+	mux.HandleFunc("/api/v1/requirements", requirementHandler.Collection)
+	mux.HandleFunc("/api/v1/requirements/{id}", requirementHandler.Item)
+	mux.HandleFunc("/api/v1/requirements/bulk", requirementHandler.Bulk)
+	mux.HandleFunc("/api/v1/requirements/parse", scheduleHandler.ParseRequirementSpec)
+	mux.HandleFunc("/api/v1/requirements/forecast", scheduleHandler.ForecastRequirements)
+
+	// 排班发布 API（按组织发布规则公布，公布前员工不可见）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/publication-rule", publicationHandler.PublicationRule)
+	mux.HandleFunc("/api/v1/schedules/{id}/publish", publicationHandler.Publish)
+	mux.HandleFunc("/api/v1/schedules/{id}/archive", publicationHandler.Archive)
+	mux.HandleFunc("/api/v1/schedules/{id}/history", publicationHandler.History)
+
+	// 换班 API（评估两名员工之间的替班/互换，可行时应用到已保存的草稿排班；推荐替班候选）
+	mux.HandleFunc("/api/v1/swap/evaluate", swapHandler.Evaluate)
+	mux.HandleFunc("/api/v1/swap/apply", swapHandler.Apply)
+	mux.HandleFunc("/api/v1/swap/candidates", swapHandler.Candidates)
+
+	// 已保存排班 API（配置数据库时读写数据库，否则读写内存存储）
+	// 排班草稿编辑（PATCH assignments）使用 ETag/If-Match 乐观并发控制，旧版本修改可合并
+	mux.HandleFunc("/api/v1/schedules", scheduleRecordHandler.List)
+	mux.HandleFunc("/api/v1/schedules/{id}", scheduleRecordHandler.Schedule)
+	mux.HandleFunc("/api/v1/schedules/{id}/assignments", scheduleRecordHandler.Assignments)
+	mux.HandleFunc("/api/v1/schedules/{id}/assignments/{assignment_id}", draftHandler.Assignment)
+	mux.HandleFunc("/api/v1/schedules/{id}/merge", draftHandler.Merge)
+
+	// 排班版本 API（生成、重新生成、修改和发布时保存版本快照，可对比任意两个版本、回滚到之前的版本）
+	mux.HandleFunc("/api/v1/schedules/{id}/versions", draftHandler.Versions)
+	mux.HandleFunc("/api/v1/schedules/{id}/diff", draftHandler.Diff)
+	mux.HandleFunc("/api/v1/schedules/{id}/rollback/{version}", draftHandler.Rollback)
+
+	// 排班网格视图 API（行=员工，列=日期，可按岗位/门店分组并附带合计）
+	mux.HandleFunc("/api/v1/schedules/{id}/grid", gridHandler.Grid)
+	mux.HandleFunc("/api/v1/schedules/export", exportHandler.Export)
+
+	// 员工排班查询 API（仅返回已公布的排班）
+	mux.HandleFunc("/api/v1/shifts", shiftHandler.Collection)
+	mux.HandleFunc("/api/v1/shifts/{id}", shiftHandler.Item)
+	mux.HandleFunc("/api/v1/employees", employeeHandler.Collection)
+	mux.HandleFunc("/api/v1/employees/import", employeeHandler.Import)
+	mux.HandleFunc("/api/v1/employees/{employee_id}", employeeHandler.Item)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/teams", teamHandler.Teams)
+	mux.HandleFunc("/api/v1/employees/{employee_id}/schedule", publicationHandler.EmployeeSchedule)
+
+	// 员工月度汇总 API（员工查看并提出争议，管理者审核）
+	mux.HandleFunc("/api/v1/employees/{employee_id}/summaries/{month}", summaryHandler.EmployeeSummary)
+	mux.HandleFunc("/api/v1/employees/{employee_id}/summaries/{month}/disputes", summaryHandler.CreateDispute)
+	mux.HandleFunc("/api/v1/summary-disputes", summaryHandler.ListDisputes)
+	mux.HandleFunc("/api/v1/summary-disputes/{id}/review", summaryHandler.ReviewDispute)
+	mux.HandleFunc("/api/v1/summaries/{month}/deliver", summaryHandler.Deliver)
+
+	// 审批 API（委托转交、超时催办与升级）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/approvals", approvalHandler.Approvals)
+	mux.HandleFunc("/api/v1/approvals/{id}", approvalHandler.Approval)
+	mux.HandleFunc("/api/v1/approvals/{id}/decision", approvalHandler.Decide)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/delegations", approvalHandler.Delegations)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/delegations/{id}", approvalHandler.DeleteDelegation)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/approval-policy", approvalHandler.ApprovalPolicy)
+
+	// HR 系统同步 API（Webhook 接收员工主数据变更）
+	mux.HandleFunc("/api/v1/hrsync/sources/{source}/mapping", hrSyncHandler.Mapping)
+	mux.HandleFunc("/api/v1/hrsync/sources/{source}/events", hrSyncHandler.Events)
+	mux.HandleFunc("/api/v1/hrsync/conflicts", hrSyncHandler.Conflicts)
+	mux.HandleFunc("/api/v1/hrsync/conflicts/{id}", hrSyncHandler.Conflicts)
+	mux.HandleFunc("/api/v1/hrsync/repairs", hrSyncHandler.Repairs)
+
+	// 标签别名 API（技能/岗位/资质多语言别名映射，未映射标签报告）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/aliases", aliasHandler.Aliases)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/aliases/unmapped", aliasHandler.Unmapped)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/aliases/resolve", aliasHandler.Resolve)
+
+	// 门店工时预算 API
+	mux.HandleFunc("/api/v1/orgs/{org_id}/store-budgets", budgetHandler.StoreBudgets)
+
+	// 门店营业时间 API
+	mux.HandleFunc("/api/v1/orgs/{org_id}/opening-hours", openingHoursHandler.OpeningHours)
+
+	// 请假管控期 API
+	mux.HandleFunc("/api/v1/orgs/{org_id}/blackout-periods", blackoutHandler.Periods)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/blackout-periods/report", blackoutHandler.Report)
+
+	// 员工可用性 API（管控期内超额请假由管理者审核）
+	mux.HandleFunc("/api/v1/employees/{employee_id}/availability", analyticsHandler.Availability)
+	mux.HandleFunc("/api/v1/employees/{employee_id}/availability/{date}/review", blackoutHandler.ReviewLeave)
+
+	// 疲劳指数 API（基于内存存储中的已发布排班）
+	mux.HandleFunc("/api/v1/employees/{employee_id}/fatigue", analyticsHandler.EmployeeFatigue)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/fatigue", analyticsHandler.OrgFatigue)
+
+	// 分析报表 API（技能供需缺口）
+	mux.HandleFunc("/api/v1/analytics/skill-gap", analyticsHandler.SkillGap)
+
 	// 约束模板 API
-	mux.HandleFunc("/api/v1/constraints/templates", handleConstraintTemplates)
+	mux.HandleFunc("/api/v1/constraints/templates", catalogHandler.Templates)
 
 	// 约束库 API - 返回后端支持的所有约束及参数定义
-	mux.HandleFunc("/api/v1/constraints/library", handleConstraintLibrary)
+	mux.HandleFunc("/api/v1/constraints/library", catalogHandler.Library)
+
+	// 约束目录管理 API（管理员查看加载状态、运行时重新加载约束库和模板）
+	mux.HandleFunc("/api/v1/admin/constraints/catalog", catalogHandler.Status)
+	mux.HandleFunc("/api/v1/admin/constraints/reload", catalogHandler.Reload)
+
+	// 故障注入 API（仅 -tags chaos 构建的测试环境可用，管理员布置存储延迟、约束评估 panic、作业崩溃）
+	mux.HandleFunc("/api/v1/admin/faults", chaosHandler.Faults)
+	mux.HandleFunc("/api/v1/admin/rate-limits", rateLimitHandler.Status)
+	mux.HandleFunc("/api/v1/admin/config", handler.NewConfigHandler(cfg).Status)
+
+	// 组织约束配置版本及差异对比 API（组织对组织、同一组织的两个版本）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/constraint-config", constraintConfigHandler.OrgConfig)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/constraint-config/versions", constraintConfigHandler.OrgConfigVersions)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/schedule-cycle", constraintConfigHandler.ScheduleCycle)
+	mux.HandleFunc("/api/v1/constraints/diff", constraintConfigHandler.Diff)
+	mux.HandleFunc("/api/v1/constraints", orgConstraintHandler.Collection)
+	mux.HandleFunc("/api/v1/constraints/{id}", orgConstraintHandler.Item)
+	mux.HandleFunc("/api/v1/scenario-templates", scenarioTemplateHandler.Collection)
+	mux.HandleFunc("/api/v1/scenario-templates/{id}", scenarioTemplateHandler.Item)
+	mux.HandleFunc("/api/v1/scenario-templates/{id}/clone", scenarioTemplateHandler.Clone)
+
+	// 历史排班回填 API（推断班次定义并按月生成历史排班）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/backfill", backfillHandler.Backfill)
+
+	// 工资结账 API（结账锁定历史分配，结账后的修改通过调整记录工时差额）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/payroll/closes", payrollHandler.Closes)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/payroll/adjustments", payrollHandler.Adjustments)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/payroll/report", payrollHandler.Report)
+
+	// 调休账户 API（加班计入调休、请假使用调休、余额查询与调整）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/time-bank", timeBankHandler.TimeBank)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/time-bank/adjustments", timeBankHandler.Adjust)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/time-bank/policy", timeBankHandler.Policy)
+
+	// 外部人员池 API（派遣/零工人员，内部员工排满后才参与排班）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/external-workers", externalPoolHandler.ExternalWorkers)
+
+	// 证书材料 API（健康证、无犯罪证明等材料的提交与核验）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/certification-documents", certificationHandler.Documents)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/certification-documents/{id}/verify", certificationHandler.Verify)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/certification-documents/{id}/reject", certificationHandler.Reject)
+
+	// 服务订单 API（订单状态流转、完成凭证，完工策略要求时凭证不全不能完成；结算清单含完成凭证）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/orders", orderHandler.Orders)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/orders/{id}/status", orderHandler.Status)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/orders/{id}/completion-proof", orderHandler.CompletionProof)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/orders/billing-export", orderHandler.BillingExport)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/customer-caregivers", orderHandler.CustomerCaregivers)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/completion-policy", orderHandler.Policy)
+
+	// 工时合规证明 API（签发需管理者，可导出 PDF，校验摘要和签名）
+	mux.HandleFunc("/api/v1/orgs/{org_id}/compliance-certificates", complianceHandler.Certificates)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/compliance-certificates/{id}", complianceHandler.Certificate)
+	mux.HandleFunc("/api/v1/compliance-certificates/verify", complianceHandler.Verify)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/document-alerts", documentAlertHandler.Alerts)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/document-alert-policy", documentAlertHandler.Policy)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/requirement-imports", requirementImportHandler.Imports)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/requirement-imports/{id}", requirementImportHandler.Import)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/requirement-imports/{id}/review", requirementImportHandler.Review)
+
+	// 排班分享链接 API（管理者创建、撤销和查看访问记录，员工凭链接免登录查看只读排班）
+	mux.HandleFunc("/api/v1/schedules/{id}/share-links", shareHandler.Links)
+	mux.HandleFunc("/api/v1/schedules/{id}/share-links/{link_id}/revoke", shareHandler.Revoke)
+	mux.HandleFunc("/api/v1/schedules/{id}/share-links/{link_id}/accesses", shareHandler.Accesses)
+	mux.HandleFunc("/share/{token}", shareHandler.View)
+
+	// 批量作业 API（导入、批量派单、批量验证拆分为批次后台执行，可查询进度、恢复和取消）
+	mux.HandleFunc("/api/v1/bulk/jobs", bulkHandler.Jobs)
+	mux.HandleFunc("/api/v1/bulk/jobs/{id}", bulkHandler.Job)
+	mux.HandleFunc("/api/v1/bulk/jobs/{id}/results", bulkHandler.Results)
+	mux.HandleFunc("/api/v1/bulk/jobs/{id}/resume", bulkHandler.Resume)
+	mux.HandleFunc("/api/v1/bulk/jobs/{id}/cancel", bulkHandler.Cancel)
 
 	// ========================================
 	// 统计分析 API
@@ -128,6 +814,15 @@ func main() {
 	// 工作量统计 API
 	mux.HandleFunc("/api/v1/stats/workload", handler.GetWorkloadHandler)
 
+	// 人工成本计算 API（正常/加班/节假日工时及费用）
+	mux.HandleFunc("/api/v1/stats/cost", handler.GetCostHandler)
+
+	// 偏好满足度报告 API（基于内存存储中的排班与员工偏好）
+	mux.HandleFunc("/api/v1/stats/preference-satisfaction", analyticsHandler.PreferenceSatisfaction)
+
+	// 打卡差异报告 API（签到位置核验与员工合规率）
+	mux.HandleFunc("/api/v1/stats/attendance-variance", handler.GetAttendanceVarianceHandler)
+
 	// ========================================
 	// 派出服务 API
 	// ========================================
@@ -138,30 +833,107 @@ func main() {
 	// 批量派单 API
 	mux.HandleFunc("/api/v1/dispatch/batch", handler.BatchDispatchHandler)
 
+	// 改派 API（服务人员临时不可用时为订单及其当日后续订单寻找替补）
+	mux.HandleFunc("/api/v1/dispatch/reassign", handler.ReassignHandler)
+
+	// 派单约束 API（约束库，以及组织保存的约束参数、权重和启用状态；派单请求的 constraints 可覆盖）
+	mux.HandleFunc("/api/v1/dispatch/constraints/library", handler.DispatchConstraintLibraryHandler)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/dispatch-constraints", handler.DispatchConstraintsHandler)
+
 	// 最优路线 API
 	mux.HandleFunc("/api/v1/dispatch/route", handler.OptimalRouteHandler)
 
+	// 员工实时状态 API（移动端上报在岗/休息/下班/途中状态及位置）
+	mux.HandleFunc("/api/v1/dispatch/status", handler.EmployeeStatusHandler)
+
+	// 护理计划 API（按计划的服务项目和频率展开上门服务订单）
+	mux.HandleFunc("/api/v1/careplan/create", carePlanHandler.Create)
+	mux.HandleFunc("/api/v1/careplan/generate-orders", carePlanHandler.GenerateOrders)
+	mux.HandleFunc("/api/v1/careplan/recommend-carers", carePlanHandler.RecommendCarers)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/care-plans", carePlanHandler.Plans)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/care-plans/{id}", carePlanHandler.Plan)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/care-plans/{id}/preview-orders", carePlanHandler.PreviewOrders)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/care-plans/{id}/generate-orders", carePlanHandler.SaveOrders)
+
+	// ========================================
+	// API v2 端点（稳定的版本化资源）
+	// ========================================
+
+	// API v2 根路由
+	mux.HandleFunc("/api/v2/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("API-Version", handler.APIVersionV2)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{
+			"message": "PaiBan 排班引擎 API v2",
+			"endpoints": {
+				"schedules": {
+					"generate": "POST /api/v2/schedules",
+					"validate": "POST /api/v2/schedules/validate",
+					"get": "GET /api/v2/schedules/{id}"
+				}
+			}
+		}`))
+	})
+
+	// 排班资源
+	mux.HandleFunc("/api/v2/schedules", scheduleHandler.GenerateV2)
+	mux.HandleFunc("/api/v2/schedules/validate", scheduleHandler.ValidateV2)
+	mux.HandleFunc("/api/v2/schedules/{id}", scheduleHandler.GetScheduleV2)
+
 	// ========================================
 	// 监控端点
 	// ========================================
 
 	// Prometheus 指标端点
-	mux.Handle("/metrics", metrics.Handler())
+	if cfg.Metrics.Enabled {
+		mux.Handle(cfg.Metrics.Path, metrics.Handler())
+	}
 
 	// ========================================
 	// 中间件
 	// ========================================
 
 	// 创建带中间件的处理器
-	// 中间件执行顺序：requestID -> rateLimit -> cors -> logging -> handler
-	handler := requestIDMiddleware(rateLimitMiddleware(corsMiddleware(loggingMiddleware(mux))))
+	// 中间件执行顺序：requestID -> cors -> logging -> auth -> rateLimit -> recovery -> deprecation -> handler
+	deprecation := middleware.DeprecationMiddleware(&middleware.DeprecationConfig{
+		PathPrefix: "/api/v1/",
+		Sunset:     v1Sunset,
+		Successors: map[string]string{
+			"/api/v1/":                  "/api/v2/",
+			"/api/v1/schedule/generate": "/api/v2/schedules",
+			"/api/v1/schedule/validate": "/api/v2/schedules/validate",
+		},
+	})
+	// 请求处理 panic 时返回带 request_id 的 500；配置 notify.incident_webhook_url 时投递事故报告
+	recoveryConfig := &middleware.RecoveryConfig{}
+	if url := cfg.Notify.IncidentWebhookURL; url != "" {
+		recoveryConfig.Notifier = notify.NewWebhookNotifier(url)
+	}
+	recovery := middleware.Recovery(recoveryConfig)
+	// API 认证：AUTH_ENABLED=true（或 AUTH_CONFIG_PATH 配置文件中 enabled 为 true）时，/api/ 下的接口须携带
+	// 静态 API 密钥或 JWT，且请求的 org_id 须与凭证所属组织一致
+	authConfig := &cfg.Auth
+	auth, err := middleware.APIAuth(authConfig)
+	if err != nil {
+		logger.Error().Err(err).Msg("配置API认证失败")
+		os.Exit(1)
+	}
+	if authConfig.Enabled {
+		logger.Info().Int("api_keys", len(authConfig.APIKeys)).
+			Bool("jwt", authConfig.JWT.HS256Secret != "" || authConfig.JWT.PublicKeyFile != "").
+			Msg("API认证已启用")
+	} else {
+		logger.Warn().Msg("API认证未启用（AUTH_ENABLED），请勿在生产环境使用")
+	}
+	handler := requestIDMiddleware(corsMiddleware(&cfg.API.CORS)(loggingMiddleware(auth(rateLimiter.Middleware(recovery(deprecation(mux)))))))
 
 	server := &http.Server{
 		Addr:         ":" + port,
 		Handler:      handler,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 60 * time.Second,
-		IdleTimeout:  120 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	// 启动服务器（非阻塞）
@@ -178,6 +950,25 @@ func main() {
 		}
 	}()
 
+	// gRPC 服务：与 HTTP 接口共用处理器和中间件（认证、限流、日志），见 api/proto/paiban/v1/paiban.proto
+	var grpcServer *grpc.Server
+	if cfg.Server.GRPCPort > 0 {
+		lis, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.Server.GRPCPort))
+		if err != nil {
+			logger.Error().Err(err).Msg("gRPC 服务监听失败")
+			os.Exit(1)
+		}
+		grpcServer = grpc.NewServer()
+		grpcapi.NewServer(handler).Register(grpcServer)
+		go func() {
+			logger.Info().Int("port", cfg.Server.GRPCPort).Msg("gRPC 服务启动")
+			if err := grpcServer.Serve(lis); err != nil {
+				logger.Error().Err(err).Msg("gRPC 服务启动失败")
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// 优雅关闭
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -185,13 +976,32 @@ func main() {
 
 	logger.Info().Msg("正在关闭服务器...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
+	// gRPC 服务与 HTTP 服务同时关闭：等待进行中的 RPC 结束，超过关闭等待时间时强制断开
+	grpcStopped := make(chan struct{})
+	if grpcServer != nil {
+		go func() {
+			grpcServer.GracefulStop()
+			close(grpcStopped)
+		}()
+	}
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Error().Err(err).Msg("服务器关闭失败")
 		os.Exit(1)
 	}
+	if grpcServer != nil {
+		select {
+		case <-grpcStopped:
+		case <-ctx.Done():
+			grpcServer.Stop()
+		}
+	}
+
+	// 保存最后一次内存快照
+	stopStore()
+	<-storeDone
 
 	logger.Info().Msg("服务器已关闭")
 }
@@ -252,101 +1062,42 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-// RateLimiter 简单的令牌桶限流器
-type RateLimiter struct {
-	tokens     float64
-	maxTokens  float64
-	refillRate float64 // 每秒添加的令牌数
-	lastRefill time.Time
-	mu         sync.Mutex
-}
-
-// NewRateLimiter 创建限流器
-func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
-	return &RateLimiter{
-		tokens:     requestsPerSecond,
-		maxTokens:  requestsPerSecond * 2, // 允许突发流量
-		refillRate: requestsPerSecond,
-		lastRefill: time.Now(),
+// Flush 支持流式响应（如进度事件流）
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
 	}
 }
 
-// Allow 检查是否允许请求
-func (rl *RateLimiter) Allow() bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	elapsed := now.Sub(rl.lastRefill).Seconds()
-	rl.tokens += elapsed * rl.refillRate
-	if rl.tokens > rl.maxTokens {
-		rl.tokens = rl.maxTokens
-	}
-	rl.lastRefill = now
-
-	if rl.tokens >= 1 {
-		rl.tokens--
-		return true
-	}
-	return false
+// Unwrap 供 http.ResponseController 获取底层 ResponseWriter
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
 }
 
-var globalRateLimiter = NewRateLimiter(100) // 默认 100 QPS
-
-// rateLimitMiddleware 限流中间件
-func rateLimitMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !globalRateLimiter.Allow() {
-			w.Header().Set("Content-Type", "application/json")
-			w.Header().Set("Retry-After", "1")
-			w.WriteHeader(http.StatusTooManyRequests)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error":   true,
-				"code":    "RATE_LIMITED",
-				"message": "请求过于频繁，请稍后重试",
-			})
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
-}
-
-// corsMiddleware CORS中间件
-func corsMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
-
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
-}
-
-// ConstraintRule 约束规则
-type ConstraintRule struct {
-	Name        string `json:"name"`
-	Type        string `json:"type"`        // hard/soft
-	Category    string `json:"category"`    // 约束类别
-	Description string `json:"description"` // 约束描述
-	Default     string `json:"default"`     // 默认值
-}
+// corsMiddleware CORS中间件：只对 api.cors.origins 中的来源返回跨域响应头，未启用时不返回
+func corsMiddleware(cfg *config.CORSConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.Enabled {
+				origin := cfg.AllowOrigin(r.Header.Get("Origin"))
+				if origin != "" {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+					w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+				}
+				if origin != "*" {
+					w.Header().Add("Vary", "Origin") // 按来源应答时缓存须区分来源
+				}
+			}
 
-// ConstraintTemplate 约束模板
-type ConstraintTemplate struct {
-	Scenario    string           `json:"scenario"`
-	Name        string           `json:"name"`
-	Description string           `json:"description"`
-	Constraints []ConstraintRule `json:"constraints"` // 约束规则列表
-}
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
 
-// ConstraintTemplatesResponse 约束模板响应
-type ConstraintTemplatesResponse struct {
-	Templates []ConstraintTemplate `json:"templates"`
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // ConstraintParam 约束参数定义
@@ -375,94 +1126,6 @@ type ConstraintLibraryResponse struct {
 	Library []ConstraintDefinition `json:"library"`
 }
 
-// handleConstraintTemplates 处理约束模板请求
-func handleConstraintTemplates(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
-	// 通用硬约束
-	commonHardConstraints := []ConstraintRule{
-		{Name: "max_hours_per_day", Type: "hard", Category: "工时限制", Description: "每日最大工时", Default: "10小时"},
-		{Name: "max_hours_per_week", Type: "hard", Category: "工时限制", Description: "每周最大工时", Default: "44小时"},
-		{Name: "min_rest_between_shifts", Type: "hard", Category: "休息保障", Description: "班次间最小休息时间", Default: "11小时"},
-		{Name: "max_consecutive_days", Type: "hard", Category: "休息保障", Description: "最大连续工作天数", Default: "6天"},
-		{Name: "skill_required", Type: "hard", Category: "资质要求", Description: "技能与岗位匹配", Default: "必须满足"},
-	}
-
-	// 通用软约束
-	commonSoftConstraints := []ConstraintRule{
-		{Name: "workload_balance", Type: "soft", Category: "公平性", Description: "工作量均衡", Default: "权重60"},
-		{Name: "employee_preference", Type: "soft", Category: "偏好", Description: "员工偏好考虑", Default: "权重50"},
-		{Name: "minimize_overtime", Type: "soft", Category: "成本优化", Description: "减少加班", Default: "权重70"},
-	}
-
-	templates := []ConstraintTemplate{
-		{
-			Scenario:    "restaurant",
-			Name:        "餐饮门店标准模板",
-			Description: "适用于餐饮门店的标准约束配置，包含高峰期人员配置、工时限制等",
-			Constraints: append(append(commonHardConstraints,
-				ConstraintRule{Name: "industry_certification", Type: "hard", Category: "资质要求", Description: "健康证等行业资质", Default: "必须持有"},
-				ConstraintRule{Name: "peak_hours_coverage", Type: "soft", Category: "服务保障", Description: "高峰期人员覆盖", Default: "11:00-13:00, 17:00-20:00 最少3人"},
-				ConstraintRule{Name: "split_shift", Type: "soft", Category: "排班模式", Description: "两头班支持", Default: "每周最多2次"},
-			), commonSoftConstraints...),
-		},
-		{
-			Scenario:    "factory",
-			Name:        "工厂三班倒模板",
-			Description: "适用于工厂三班倒的约束配置，包含倒班规则、产线覆盖等",
-			Constraints: append(append(commonHardConstraints,
-				ConstraintRule{Name: "shift_rotation", Type: "hard", Category: "排班模式", Description: "倒班轮换规则", Default: "早-中-晚轮换"},
-				ConstraintRule{Name: "production_line_coverage", Type: "hard", Category: "服务保障", Description: "产线24小时覆盖", Default: "必须满足"},
-				ConstraintRule{Name: "handover_overlap", Type: "soft", Category: "交接", Description: "交接班重叠时间", Default: "15分钟"},
-			), commonSoftConstraints...),
-		},
-		{
-			Scenario:    "housekeeping",
-			Name:        "家政服务模板",
-			Description: "适用于家政服务的约束配置，包含服务区域、路程时间等",
-			Constraints: append(append(commonHardConstraints,
-				ConstraintRule{Name: "service_area", Type: "hard", Category: "区域限制", Description: "服务区域匹配", Default: "必须在服务范围内"},
-				ConstraintRule{Name: "travel_time", Type: "soft", Category: "效率优化", Description: "路程时间考虑", Default: "尽量减少"},
-				ConstraintRule{Name: "time_window", Type: "hard", Category: "服务保障", Description: "服务时间窗口", Default: "必须在客户指定时段"},
-			), commonSoftConstraints...),
-		},
-		{
-			Scenario:    "nursing",
-			Name:        "长护险服务模板",
-			Description: "适用于长期护理保险服务的约束配置，包含护理计划、资质等级等",
-			Constraints: append(append(commonHardConstraints,
-				ConstraintRule{Name: "nursing_qualification", Type: "hard", Category: "资质要求", Description: "护理资质等级", Default: "必须持有护理证"},
-				ConstraintRule{Name: "service_continuity", Type: "soft", Category: "服务质量", Description: "服务连续性", Default: "优先安排熟悉的护理员"},
-				ConstraintRule{Name: "max_patients_per_day", Type: "hard", Category: "服务质量", Description: "每日最大服务患者数", Default: "4人"},
-			), commonSoftConstraints...),
-		},
-	}
-
-	response := ConstraintTemplatesResponse{Templates: templates}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
-
-// handleConstraintLibrary 处理约束库请求 - 返回后端支持的所有约束定义
-func handleConstraintLibrary(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-
-	// 使用独立的约束库模块
-	library := constraints.GetLibrary()
-
-	response := constraints.LibraryResponse{Library: library}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
-
 // handleConstraintLibrary_OLD 保留旧的约束定义以便参考（未使用）
 func handleConstraintLibrary_OLD(w http.ResponseWriter, r *http.Request) {
 	library := []ConstraintDefinition{
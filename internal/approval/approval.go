@@ -0,0 +1,343 @@
+// Package approval 提供审批流转
+// 换班、加班、排班等审批单提交给指定审批人；审批人外出时按委托规则自动转交受托人，
+// 超过组织审批时限未处理则催办，多次催办仍未处理时升级；审批结果记录实际审批人和代审批关系
+package approval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+var (
+	// ErrInvalidRequest 审批单缺少必要信息
+	ErrInvalidRequest = errors.New("审批单无效")
+	// ErrDecided 审批单已处理
+	ErrDecided = errors.New("审批单已处理")
+	// ErrNotAssigned 当前用户不是该审批单的审批人
+	ErrNotAssigned = errors.New("当前用户无权处理该审批单")
+)
+
+// Service 审批服务
+type Service struct {
+	store      *memstore.Store
+	notifier   notify.Notifier
+	now        func() time.Time
+	onApproved []func(*model.ApprovalRequest)
+}
+
+// NewService 创建审批服务
+func NewService(store *memstore.Store, notifier notify.Notifier) *Service {
+	if notifier == nil {
+		notifier = notify.LogNotifier{}
+	}
+	return &Service{
+		store:    store,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+// Submit 提交审批单，按委托规则确定当前处理人并通知
+func (s *Service) Submit(ctx context.Context, a *model.ApprovalRequest) (*model.ApprovalRequest, error) {
+	if !model.IsValidApprovalKind(a.Kind) {
+		return nil, fmt.Errorf("%w: 不支持的审批类型 %q", ErrInvalidRequest, a.Kind)
+	}
+	if a.Approver == "" || a.Title == "" {
+		return nil, fmt.Errorf("%w: 审批人和标题不能为空", ErrInvalidRequest)
+	}
+	if a.Hours < 0 {
+		return nil, fmt.Errorf("%w: 加班时长不能为负数", ErrInvalidRequest)
+	}
+	if a.Hours > 0 {
+		if a.Kind != model.ApprovalKindOvertime || a.EmployeeID == nil {
+			return nil, fmt.Errorf("%w: 加班时长只用于加班审批，且须指定加班员工", ErrInvalidRequest)
+		}
+		if _, err := time.Parse("2006-01-02", a.Date); err != nil {
+			return nil, fmt.Errorf("%w: 加班日期格式应为 YYYY-MM-DD", ErrInvalidRequest)
+		}
+	}
+
+	now := s.now()
+	a.BaseModel = model.BaseModel{ID: uuid.New(), CreatedAt: now, UpdatedAt: now}
+	a.Status = model.ApprovalPending
+	a.AssignedTo = a.Approver
+	a.Routes = []model.ApprovalRoute{{At: now, To: a.Approver, Reason: model.RouteAssigned}}
+	a.Reminders, a.RemindedAt, a.EscalatedAt = 0, nil, nil
+	a.DecidedBy, a.OnBehalfOf, a.Note, a.DecidedAt = "", "", "", nil
+	s.route(a, s.store.ListDelegations(a.OrgID), now)
+	if policy := s.policy(a.OrgID); policy != nil {
+		due := now.Add(policy.SLA())
+		a.DueAt = &due
+	}
+
+	if err := s.store.PutApproval(a); err != nil {
+		return nil, err
+	}
+	s.notify(ctx, a, notify.TypeApprovalAssigned, fmt.Sprintf("待审批：%s", a.Title))
+	return a, nil
+}
+
+// Decide 审批。当前处理人、原审批人以及流转过程中接手过的审批人均可处理；
+// 实际审批人与原审批人不同时记录代审批关系
+func (s *Service) Decide(ctx context.Context, id uuid.UUID, actor string, approve bool, note string) (*model.ApprovalRequest, error) {
+	a, err := s.store.GetApproval(id)
+	if err != nil {
+		return nil, err
+	}
+	if !a.IsPending() {
+		return a, ErrDecided
+	}
+	if actor == "" || !canDecide(a, actor) {
+		return nil, ErrNotAssigned
+	}
+
+	now := s.now()
+	a.Status = model.ApprovalRejected
+	if approve {
+		a.Status = model.ApprovalApproved
+	}
+	a.DecidedBy = actor
+	if actor != a.Approver {
+		a.OnBehalfOf = a.Approver
+	}
+	a.Note = note
+	a.DecidedAt = &now
+	a.UpdatedAt = now
+	if err := s.store.PutApproval(a); err != nil {
+		return nil, err
+	}
+	if approve {
+		for _, fn := range s.onApproved {
+			fn(a)
+		}
+	}
+
+	if a.RequestedBy != "" {
+		result := "已驳回"
+		if approve {
+			result = "已通过"
+		}
+		n := notify.New(notify.TypeApprovalDecided, a.OrgID, fmt.Sprintf("%s %s", a.Title, result), note, a).ToUser(a.RequestedBy)
+		if err := s.notifier.Notify(ctx, n); err != nil {
+			logger.Error().Err(err).Str("approval_id", a.ID.String()).Msg("通知审批结果失败")
+		}
+	}
+	return a, nil
+}
+
+// OnApproved 注册审批通过后的处理（如加班计入调休账户），需在服务启动前注册
+func (s *Service) OnApproved(fn func(*model.ApprovalRequest)) {
+	s.onApproved = append(s.onApproved, fn)
+}
+
+// Inbox 列出当前由某用户处理的待审批单
+func (s *Service) Inbox(orgID uuid.UUID, user string) []*model.ApprovalRequest {
+	result := make([]*model.ApprovalRequest, 0)
+	for _, a := range s.store.ListApprovals(orgID, model.ApprovalPending) {
+		if a.AssignedTo == user {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// Reroute 按当前生效的委托重新分派组织的待审批单（委托新增、撤销或到期后调用），返回转交数量
+func (s *Service) Reroute(ctx context.Context, orgID uuid.UUID) int {
+	now := s.now()
+	delegations := s.store.ListDelegations(orgID)
+	count := 0
+	for _, a := range s.store.ListApprovals(orgID, model.ApprovalPending) {
+		before := a.AssignedTo
+		if !s.route(a, delegations, now) {
+			continue
+		}
+		a.UpdatedAt = now
+		if err := s.store.PutApproval(a); err != nil {
+			logger.Error().Err(err).Str("approval_id", a.ID.String()).Msg("转交审批单失败")
+			continue
+		}
+		logger.Info().
+			Str("approval_id", a.ID.String()).
+			Str("from", before).
+			Str("to", a.AssignedTo).
+			Msg("审批单已转交")
+		s.notify(ctx, a, notify.TypeApprovalAssigned, fmt.Sprintf("待审批（转交）：%s", a.Title))
+		count++
+	}
+	return count
+}
+
+// Sweep 处理超过审批时限的待审批单：催办当前处理人，催办次数达到策略要求后升级，
+// 返回催办和升级数量。未配置审批策略的组织不催办
+func (s *Service) Sweep(ctx context.Context) (reminded, escalated int) {
+	now := s.now()
+
+	// 委托按日期生效，先按当天委托重新分派各组织的待审批单
+	rerouted := make(map[uuid.UUID]bool)
+	for _, a := range s.store.ListApprovals(uuid.Nil, model.ApprovalPending) {
+		if !rerouted[a.OrgID] {
+			rerouted[a.OrgID] = true
+			s.Reroute(ctx, a.OrgID)
+		}
+	}
+
+	policies := make(map[uuid.UUID]*model.ApprovalPolicy)
+	for _, a := range s.store.ListApprovals(uuid.Nil, model.ApprovalPending) {
+		policy, ok := policies[a.OrgID]
+		if !ok {
+			policy = s.policy(a.OrgID)
+			policies[a.OrgID] = policy
+		}
+		if policy == nil {
+			continue
+		}
+		if a.DueAt == nil {
+			due := a.CreatedAt.Add(policy.SLA())
+			a.DueAt = &due
+		}
+		if now.Before(*a.DueAt) {
+			continue
+		}
+
+		escalateAfter := policy.EscalateAfter
+		if escalateAfter == 0 {
+			escalateAfter = 1
+		}
+		due := now.Add(policy.SLA())
+		a.DueAt = &due
+		a.UpdatedAt = now
+		if policy.EscalateTo != "" && a.EscalatedAt == nil && a.Reminders >= escalateAfter && policy.EscalateTo != a.AssignedTo {
+			a.EscalatedAt = &now
+			a.Routes = append(a.Routes, model.ApprovalRoute{At: now, From: a.AssignedTo, To: policy.EscalateTo, Reason: model.RouteEscalated})
+			a.AssignedTo = policy.EscalateTo
+			s.route(a, s.store.ListDelegations(a.OrgID), now)
+			if err := s.store.PutApproval(a); err != nil {
+				logger.Error().Err(err).Str("approval_id", a.ID.String()).Msg("升级审批单失败")
+				continue
+			}
+			s.notify(ctx, a, notify.TypeApprovalEscalated, fmt.Sprintf("审批超时升级：%s", a.Title))
+			escalated++
+			continue
+		}
+
+		a.Reminders++
+		a.RemindedAt = &now
+		if err := s.store.PutApproval(a); err != nil {
+			logger.Error().Err(err).Str("approval_id", a.ID.String()).Msg("催办审批单失败")
+			continue
+		}
+		s.notify(ctx, a, notify.TypeApprovalReminder, fmt.Sprintf("审批催办（第 %d 次）：%s", a.Reminders, a.Title))
+		reminded++
+	}
+	return reminded, escalated
+}
+
+// Run 定期催办和升级超时审批单，直到 ctx 取消
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if reminded, escalated := s.Sweep(ctx); reminded+escalated > 0 {
+				logger.Info().Int("reminded", reminded).Int("escalated", escalated).Msg("审批催办完成")
+			}
+		}
+	}
+}
+
+// route 按委托确定当前处理人：从最近一次指定/升级的审批人出发沿委托链转交，
+// 委托链成环时停在环上最后一个未重复的人。处理人变化时追加流转记录并返回 true
+func (s *Service) route(a *model.ApprovalRequest, delegations []*model.ApprovalDelegation, now time.Time) bool {
+	owner := a.Approver
+	for _, r := range a.Routes {
+		if r.Reason == model.RouteAssigned || r.Reason == model.RouteEscalated {
+			owner = r.To
+		}
+	}
+
+	date := now.Format("2006-01-02")
+	target := owner
+	var via *uuid.UUID
+	visited := map[string]bool{owner: true}
+	for {
+		d := activeDelegation(delegations, target, date, a.Kind)
+		if d == nil || visited[d.Delegate] {
+			break
+		}
+		visited[d.Delegate] = true
+		target = d.Delegate
+		id := d.ID
+		via = &id
+	}
+
+	if target == a.AssignedTo {
+		return false
+	}
+	reason := model.RouteDelegated
+	if via == nil {
+		reason = model.RouteReturned
+	}
+	a.Routes = append(a.Routes, model.ApprovalRoute{At: now, From: a.AssignedTo, To: target, Reason: reason, DelegationID: via})
+	a.AssignedTo = target
+	return true
+}
+
+// policy 返回组织审批策略，未配置时返回 nil
+func (s *Service) policy(orgID uuid.UUID) *model.ApprovalPolicy {
+	org, err := s.store.GetOrganization(orgID)
+	if err != nil {
+		return nil
+	}
+	return org.ApprovalPolicy
+}
+
+// notify 通知当前处理人
+func (s *Service) notify(ctx context.Context, a *model.ApprovalRequest, notifType, title string) {
+	n := notify.New(notifType, a.OrgID, title, a.Detail, a).ToUser(a.AssignedTo)
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		logger.Error().Err(err).Str("approval_id", a.ID.String()).Msg("通知审批人失败")
+	}
+}
+
+// activeDelegation 查找某用户在某日对某类审批生效的委托（多条时取最近创建的）
+func activeDelegation(delegations []*model.ApprovalDelegation, user, date, kind string) *model.ApprovalDelegation {
+	var found *model.ApprovalDelegation
+	for _, d := range delegations {
+		if d.Delegator != user || !d.Covers(date, kind) {
+			continue
+		}
+		if found == nil || d.CreatedAt.After(found.CreatedAt) {
+			found = d
+		}
+	}
+	return found
+}
+
+// canDecide 检查用户是否可处理审批单
+func canDecide(a *model.ApprovalRequest, actor string) bool {
+	if actor == a.AssignedTo || actor == a.Approver {
+		return true
+	}
+	for _, r := range a.Routes {
+		if r.To == actor {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+package approval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+type captureNotifier struct {
+	sent []*notify.Notification
+}
+
+func (c *captureNotifier) Notify(ctx context.Context, n *notify.Notification) error {
+	c.sent = append(c.sent, n)
+	return nil
+}
+
+func newTestService(t *testing.T, policy *model.ApprovalPolicy) (*Service, *captureNotifier, *time.Time, uuid.UUID) {
+	t.Helper()
+	store := memstore.New("")
+	orgID := uuid.New()
+	org := &model.Organization{BaseModel: model.NewBaseModel(), ApprovalPolicy: policy}
+	org.ID = orgID
+	store.PutOrganization(org)
+
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	notifier := &captureNotifier{}
+	s := NewService(store, notifier)
+	s.now = func() time.Time { return now }
+	return s, notifier, &now, orgID
+}
+
+func addDelegation(t *testing.T, s *Service, orgID uuid.UUID, from, to, start, end string) {
+	t.Helper()
+	d := &model.ApprovalDelegation{ID: uuid.New(), OrgID: orgID, Delegator: from, Delegate: to, StartDate: start, EndDate: end, CreatedAt: s.now()}
+	if err := s.store.PutDelegation(d); err != nil {
+		t.Fatalf("PutDelegation() error = %v", err)
+	}
+}
+
+func TestService_DelegatedApproval(t *testing.T) {
+	s, notifier, _, orgID := newTestService(t, nil)
+	addDelegation(t, s, orgID, "alice", "bob", "2026-03-01", "2026-03-07")
+
+	a, err := s.Submit(context.Background(), &model.ApprovalRequest{
+		OrgID: orgID, Kind: model.ApprovalKindSwap, Title: "张三与李四换班", Approver: "alice", RequestedBy: "zhangsan",
+	})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if a.AssignedTo != "bob" || len(a.Routes) != 2 || a.Routes[1].Reason != model.RouteDelegated {
+		t.Fatalf("审批单应转交受托人: %+v", a)
+	}
+	if got := notifier.sent[0].RecipientUser; got != "bob" {
+		t.Errorf("应通知受托人, got %q", got)
+	}
+
+	if _, err := s.Decide(context.Background(), a.ID, "carol", true, ""); err != ErrNotAssigned {
+		t.Errorf("无关用户审批应被拒绝, got %v", err)
+	}
+	decided, err := s.Decide(context.Background(), a.ID, "bob", true, "同意")
+	if err != nil {
+		t.Fatalf("Decide() error = %v", err)
+	}
+	if decided.DecidedBy != "bob" || decided.OnBehalfOf != "alice" || decided.Status != model.ApprovalApproved {
+		t.Errorf("应记录代审批: %+v", decided)
+	}
+	if _, err := s.Decide(context.Background(), a.ID, "alice", false, ""); err != ErrDecided {
+		t.Errorf("已处理的审批单不能再次审批, got %v", err)
+	}
+}
+
+func TestService_RerouteWhenDelegationEnds(t *testing.T) {
+	s, _, now, orgID := newTestService(t, nil)
+	a, _ := s.Submit(context.Background(), &model.ApprovalRequest{
+		OrgID: orgID, Kind: model.ApprovalKindOvertime, Title: "周末加班", Approver: "alice",
+	})
+
+	// 提交后才登记外出：待审批单转交受托人
+	addDelegation(t, s, orgID, "alice", "bob", "2026-03-01", "2026-03-03")
+	if n := s.Reroute(context.Background(), orgID); n != 1 {
+		t.Fatalf("Reroute() = %d, want 1", n)
+	}
+	if got := s.Inbox(orgID, "bob"); len(got) != 1 || got[0].ID != a.ID {
+		t.Fatalf("受托人应收到审批单: %+v", got)
+	}
+
+	// 委托到期后退回原审批人
+	*now = now.AddDate(0, 0, 3)
+	s.Sweep(context.Background())
+	got, _ := s.store.GetApproval(a.ID)
+	if got.AssignedTo != "alice" || got.Routes[len(got.Routes)-1].Reason != model.RouteReturned {
+		t.Errorf("委托到期应退回原审批人: %+v", got.Routes)
+	}
+}
+
+func TestService_SweepRemindsThenEscalates(t *testing.T) {
+	s, notifier, now, orgID := newTestService(t, &model.ApprovalPolicy{SLAHours: 24, EscalateAfter: 1, EscalateTo: "director"})
+	a, _ := s.Submit(context.Background(), &model.ApprovalRequest{
+		OrgID: orgID, Kind: model.ApprovalKindSchedule, Title: "发布下周排班", Approver: "alice",
+	})
+
+	if reminded, escalated := s.Sweep(context.Background()); reminded+escalated != 0 {
+		t.Fatalf("未超时不应催办: %d %d", reminded, escalated)
+	}
+
+	*now = now.Add(25 * time.Hour)
+	if reminded, _ := s.Sweep(context.Background()); reminded != 1 {
+		t.Fatalf("超时应催办一次, got %d", reminded)
+	}
+	if last := notifier.sent[len(notifier.sent)-1]; last.Type != notify.TypeApprovalReminder || last.RecipientUser != "alice" {
+		t.Errorf("应催办当前处理人: %+v", last)
+	}
+
+	*now = now.Add(25 * time.Hour)
+	if _, escalated := s.Sweep(context.Background()); escalated != 1 {
+		t.Fatalf("催办后仍超时应升级, got %d", escalated)
+	}
+	got, _ := s.store.GetApproval(a.ID)
+	if got.AssignedTo != "director" || got.EscalatedAt == nil {
+		t.Errorf("应升级给 director: %+v", got)
+	}
+
+	// 原审批人仍可处理，结果记录实际审批人
+	decided, err := s.Decide(context.Background(), a.ID, "alice", true, "")
+	if err != nil || decided.OnBehalfOf != "" {
+		t.Errorf("原审批人处理不应记录代审批: %+v, %v", decided, err)
+	}
+}
+
+func TestService_OvertimeOnApproved(t *testing.T) {
+	s, _, _, orgID := newTestService(t, nil)
+	var approved []*model.ApprovalRequest
+	s.OnApproved(func(a *model.ApprovalRequest) { approved = append(approved, a) })
+
+	empID := uuid.New()
+	if _, err := s.Submit(context.Background(), &model.ApprovalRequest{
+		OrgID: orgID, Kind: model.ApprovalKindOvertime, Title: "加班", Approver: "alice", Hours: 4,
+	}); err == nil {
+		t.Error("填写加班时长时须指定加班员工和日期")
+	}
+	a, err := s.Submit(context.Background(), &model.ApprovalRequest{
+		OrgID: orgID, Kind: model.ApprovalKindOvertime, Title: "加班", Approver: "alice",
+		EmployeeID: &empID, Date: "2026-03-07", Hours: 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Decide(context.Background(), a.ID, "alice", true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(approved) != 1 || approved[0].Hours != 4 || approved[0].Status != model.ApprovalApproved {
+		t.Errorf("审批通过后应回调: %+v", approved)
+	}
+}
@@ -0,0 +1,466 @@
+// Package backfill 提供历史排班回填
+// 组织只导入了历年的排班分配（员工、日期、上下班时间）而没有班次定义时，
+// 按上下班时间聚类推断班次定义，将分配映射到班次，写入班次库并按月生成已发布的历史排班，
+// 使历史统计分析和月度汇总可以直接使用
+package backfill
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// DefaultTolerance 默认聚类容差（分钟）：上班时间和时长均在容差内的分配归为同一班次
+const DefaultTolerance = 30
+
+// Record 导入的历史分配
+type Record struct {
+	EmployeeID   string `json:"employee_id,omitempty"`
+	EmployeeCode string `json:"employee_code,omitempty"` // 未给出员工ID时按工号匹配
+	Date         string `json:"date"`                    // YYYY-MM-DD
+	StartTime    string `json:"start_time"`              // HH:MM
+	EndTime      string `json:"end_time"`                // HH:MM，不晚于上班时间表示跨天
+	Position     string `json:"position,omitempty"`
+}
+
+// Options 回填选项
+type Options struct {
+	Tolerance int            // 聚类容差（分钟），0 表示默认值
+	Location  *time.Location // 上下班时间所在时区，nil 表示服务器时区
+	DryRun    bool           // 只推断不写入
+}
+
+// InferredShift 推断出的班次
+type InferredShift struct {
+	Shift       *model.Shift `json:"shift"`
+	Reused      bool         `json:"reused"`      // 是否复用了班次库中已有的班次
+	Assignments int          `json:"assignments"` // 映射到该班次的分配数
+	Patterns    []string     `json:"patterns"`    // 归入该班次的上下班时间（按出现次数降序）
+}
+
+// MonthResult 按月生成的历史排班
+type MonthResult struct {
+	Month       string    `json:"month"`
+	ScheduleID  uuid.UUID `json:"schedule_id"`
+	Assignments int       `json:"assignments"`
+}
+
+// Issue 未能导入的记录
+type Issue struct {
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+}
+
+// Result 回填结果
+type Result struct {
+	DryRun     bool             `json:"dry_run"`
+	Shifts     []*InferredShift `json:"shifts"`
+	Months     []MonthResult    `json:"months"`
+	Mapped     int              `json:"mapped"`
+	Duplicates int              `json:"duplicates"` // 历史排班中已存在的分配（重复导入）
+	Skipped    []Issue          `json:"skipped"`
+}
+
+// Pattern 一种上下班时间（分钟）
+type Pattern struct {
+	Start    int // 上班时间，距 0 点的分钟数
+	Duration int // 时长（分钟）
+}
+
+// String 返回 HH:MM-HH:MM
+func (p Pattern) String() string {
+	return formatMinutes(p.Start) + "-" + formatMinutes(p.Start+p.Duration)
+}
+
+// Cluster 聚类结果：Center 为类中出现次数最多的上下班时间
+type Cluster struct {
+	Center   Pattern
+	Patterns []Pattern
+	Count    int
+}
+
+// Infer 按上下班时间聚类。出现次数多的时间优先成为类中心，
+// 其余时间归入上班时间和时长都在容差内的最近类，否则自成一类；结果按上班时间排序
+func Infer(patterns []Pattern, tolerance int) []*Cluster {
+	counts := make(map[Pattern]int)
+	for _, p := range patterns {
+		counts[p]++
+	}
+	distinct := make([]Pattern, 0, len(counts))
+	for p := range counts {
+		distinct = append(distinct, p)
+	}
+	sort.Slice(distinct, func(i, j int) bool {
+		a, b := distinct[i], distinct[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		return a.Duration < b.Duration
+	})
+
+	var clusters []*Cluster
+	for _, p := range distinct {
+		var best *Cluster
+		bestDist := 0
+		for _, c := range clusters {
+			ds, dd := startDistance(p.Start, c.Center.Start), abs(p.Duration-c.Center.Duration)
+			if ds > tolerance || dd > tolerance {
+				continue
+			}
+			if dist := ds + dd; best == nil || dist < bestDist {
+				best, bestDist = c, dist
+			}
+		}
+		if best == nil {
+			best = &Cluster{Center: p}
+			clusters = append(clusters, best)
+		}
+		best.Patterns = append(best.Patterns, p)
+		best.Count += counts[p]
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Center.Start != clusters[j].Center.Start {
+			return clusters[i].Center.Start < clusters[j].Center.Start
+		}
+		return clusters[i].Center.Duration < clusters[j].Center.Duration
+	})
+	return clusters
+}
+
+// Service 历史回填服务
+type Service struct {
+	store *memstore.Store
+	now   func() time.Time
+}
+
+// NewService 创建历史回填服务
+func NewService(store *memstore.Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// parsed 校验通过的记录
+type parsed struct {
+	employee uuid.UUID
+	record   Record
+	pattern  Pattern
+}
+
+// Import 推断班次并回填历史排班
+// 班次库中已有上下班时间在容差内的班次时直接复用；每月的分配写入该月的历史排班（已有则追加），
+// 同一员工同一天同一上班时间的分配已存在时跳过，重复导入不会产生重复数据；
+// 已结账工资期间内的记录不会写入，需通过工资调整补录
+func (s *Service) Import(orgID uuid.UUID, records []Record, opts Options) (*Result, error) {
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = DefaultTolerance
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	result := &Result{
+		DryRun:  opts.DryRun,
+		Shifts:  make([]*InferredShift, 0),
+		Months:  make([]MonthResult, 0),
+		Skipped: make([]Issue, 0),
+	}
+
+	employees := make(map[uuid.UUID]bool)
+	byCode := make(map[string]uuid.UUID)
+	for _, emp := range s.store.ListEmployees(orgID) {
+		employees[emp.ID] = true
+		if emp.Code != "" {
+			byCode[emp.Code] = emp.ID
+		}
+	}
+
+	lockedBefore := s.store.PayrollLockedBefore(orgID)
+	var rows []parsed
+	for i, rec := range records {
+		empID, reason := resolveEmployee(rec, employees, byCode)
+		if reason == "" {
+			reason = validateRecord(rec)
+		}
+		if reason == "" && model.IsPayrollLocked(rec.Date, lockedBefore) {
+			reason = "工资期间已结账"
+		}
+		if reason != "" {
+			result.Skipped = append(result.Skipped, Issue{Index: i, Reason: reason})
+			continue
+		}
+		start, _ := parseMinutes(rec.StartTime)
+		end, _ := parseMinutes(rec.EndTime)
+		if end <= start {
+			end += 24 * 60
+		}
+		rows = append(rows, parsed{employee: empID, record: rec, pattern: Pattern{Start: start, Duration: end - start}})
+	}
+
+	patterns := make([]Pattern, len(rows))
+	for i, row := range rows {
+		patterns[i] = row.pattern
+	}
+	clusters := Infer(patterns, opts.Tolerance)
+
+	// 类中心 -> 班次（复用已有班次或新建）
+	existing := s.store.ListShifts(orgID)
+	shiftOf := make(map[Pattern]*InferredShift)
+	for _, c := range clusters {
+		inferred := &InferredShift{Assignments: c.Count, Patterns: make([]string, 0, len(c.Patterns))}
+		for _, p := range c.Patterns {
+			inferred.Patterns = append(inferred.Patterns, p.String())
+			shiftOf[p] = inferred
+		}
+		if shift := matchShift(existing, c.Center, opts.Tolerance); shift != nil {
+			inferred.Shift, inferred.Reused = shift, true
+		} else {
+			inferred.Shift = newShift(orgID, c, s.now())
+		}
+		result.Shifts = append(result.Shifts, inferred)
+	}
+
+	// 按月归集分配
+	months := make(map[string]*model.Schedule)
+	seen := make(map[string]bool)
+	for _, schedule := range s.store.ListSchedules(orgID) {
+		for _, a := range schedule.Assignments {
+			seen[assignmentKey(a.EmployeeID, a.Date, a.StartTime)] = true
+		}
+	}
+	added := make(map[string]int)
+	for _, row := range rows {
+		day, _ := time.ParseInLocation("2006-01-02", row.record.Date, opts.Location)
+		startAt := day.Add(time.Duration(row.pattern.Start) * time.Minute)
+		endAt := startAt.Add(time.Duration(row.pattern.Duration) * time.Minute)
+		key := assignmentKey(row.employee, row.record.Date, startAt)
+		if seen[key] {
+			result.Duplicates++
+			continue
+		}
+		seen[key] = true
+
+		month := row.record.Date[:7]
+		schedule, ok := months[month]
+		if !ok {
+			schedule = s.monthSchedule(orgID, month)
+			months[month] = schedule
+		}
+		a := model.Assignment{
+			BaseModel:  model.NewBaseModel(),
+			OrgID:      orgID,
+			ScheduleID: schedule.ID,
+			EmployeeID: row.employee,
+			ShiftID:    shiftOf[row.pattern].Shift.ID,
+			Date:       row.record.Date,
+			StartTime:  startAt,
+			EndTime:    endAt,
+			Position:   row.record.Position,
+			Status:     "completed",
+		}
+		schedule.Assignments = append(schedule.Assignments, a)
+		added[month]++
+		result.Mapped++
+	}
+
+	monthKeys := make([]string, 0, len(months))
+	for month := range months {
+		monthKeys = append(monthKeys, month)
+	}
+	sort.Strings(monthKeys)
+	for _, month := range monthKeys {
+		result.Months = append(result.Months, MonthResult{Month: month, ScheduleID: months[month].ID, Assignments: added[month]})
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	for _, inferred := range result.Shifts {
+		if inferred.Reused {
+			continue
+		}
+		if err := s.store.PutShift(inferred.Shift); err != nil {
+			return nil, fmt.Errorf("保存班次失败: %w", err)
+		}
+	}
+	for _, month := range monthKeys {
+		schedule := months[month]
+		sort.SliceStable(schedule.Assignments, func(i, j int) bool {
+			return schedule.Assignments[i].StartTime.Before(schedule.Assignments[j].StartTime)
+		})
+		schedule.UpdatedAt = s.now()
+		if err := s.store.PutSchedule(schedule); err != nil {
+			return nil, fmt.Errorf("保存历史排班失败: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// monthSchedule 返回某月的历史排班，不存在时新建（已发布，供历史统计使用）
+func (s *Service) monthSchedule(orgID uuid.UUID, month string) *model.Schedule {
+	name := ScheduleName(month)
+	for _, schedule := range s.store.ListSchedules(orgID) {
+		if schedule.Name == name {
+			return schedule
+		}
+	}
+	first, _ := time.Parse("2006-01", month)
+	now := s.now()
+	schedule := &model.Schedule{
+		BaseModel:   model.NewBaseModel(),
+		OrgID:       orgID,
+		Name:        name,
+		StartDate:   first.Format("2006-01-02"),
+		EndDate:     first.AddDate(0, 1, -1).Format("2006-01-02"),
+		Status:      "published",
+		Version:     1,
+		PublishedAt: &now,
+	}
+	schedule.CreatedAt, schedule.UpdatedAt = now, now
+	return schedule
+}
+
+// ScheduleName 返回某月历史排班的名称
+func ScheduleName(month string) string {
+	return "历史导入 " + month
+}
+
+// newShift 按类中心新建班次
+func newShift(orgID uuid.UUID, c *Cluster, now time.Time) *model.Shift {
+	shiftType := classifyShiftType(c.Center.Start)
+	shift := &model.Shift{
+		BaseModel:   model.NewBaseModel(),
+		OrgID:       orgID,
+		Name:        fmt.Sprintf("%s %s", shiftTypeNames[shiftType], c.Center),
+		Code:        "HIST-" + strings.ReplaceAll(c.Center.String(), ":", ""),
+		Description: fmt.Sprintf("由 %d 条历史分配推断", c.Count),
+		StartTime:   formatMinutes(c.Center.Start),
+		EndTime:     formatMinutes(c.Center.Start + c.Center.Duration),
+		Duration:    c.Center.Duration,
+		ShiftType:   shiftType,
+		IsActive:    true,
+	}
+	shift.CreatedAt, shift.UpdatedAt = now, now
+	return shift
+}
+
+// matchShift 查找上下班时间在容差内且最接近的已有班次
+func matchShift(shifts []*model.Shift, p Pattern, tolerance int) *model.Shift {
+	var best *model.Shift
+	bestDist := 0
+	for _, shift := range shifts {
+		start, err1 := parseMinutes(shift.StartTime)
+		end, err2 := parseMinutes(shift.EndTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if end <= start {
+			end += 24 * 60
+		}
+		ds, dd := startDistance(start, p.Start), abs(end-start-p.Duration)
+		if ds > tolerance || dd > tolerance {
+			continue
+		}
+		if dist := ds + dd; best == nil || dist < bestDist {
+			best, bestDist = shift, dist
+		}
+	}
+	return best
+}
+
+// resolveEmployee 按员工ID或工号匹配员工，失败时返回原因
+func resolveEmployee(rec Record, employees map[uuid.UUID]bool, byCode map[string]uuid.UUID) (uuid.UUID, string) {
+	if rec.EmployeeID != "" {
+		id, err := uuid.Parse(rec.EmployeeID)
+		if err != nil {
+			return uuid.Nil, "无效的员工ID格式"
+		}
+		if !employees[id] {
+			return uuid.Nil, "员工不存在"
+		}
+		return id, ""
+	}
+	if rec.EmployeeCode == "" {
+		return uuid.Nil, "缺少员工ID或工号"
+	}
+	id, ok := byCode[rec.EmployeeCode]
+	if !ok {
+		return uuid.Nil, fmt.Sprintf("工号 %s 不存在", rec.EmployeeCode)
+	}
+	return id, ""
+}
+
+// validateRecord 校验日期和上下班时间
+func validateRecord(rec Record) string {
+	if _, err := time.Parse("2006-01-02", rec.Date); err != nil {
+		return "日期格式应为 YYYY-MM-DD"
+	}
+	if _, err := parseMinutes(rec.StartTime); err != nil {
+		return "上班时间格式应为 HH:MM"
+	}
+	if _, err := parseMinutes(rec.EndTime); err != nil {
+		return "下班时间格式应为 HH:MM"
+	}
+	return ""
+}
+
+// assignmentKey 分配去重键：员工、日期、上班时间
+func assignmentKey(employeeID uuid.UUID, date string, start time.Time) string {
+	return employeeID.String() + "/" + date + "/" + start.Format("15:04")
+}
+
+// shiftTypeNames 班次类型名称
+var shiftTypeNames = map[string]string{
+	"morning":   "早班",
+	"afternoon": "中班",
+	"night":     "夜班",
+}
+
+// classifyShiftType 按上班时间分类班次类型（与统计模块一致）
+func classifyShiftType(start int) string {
+	hour := start / 60
+	if hour >= 6 && hour < 14 {
+		return "morning"
+	} else if hour >= 14 && hour < 22 {
+		return "afternoon"
+	}
+	return "night"
+}
+
+// parseMinutes 解析 HH:MM 为分钟数
+func parseMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatMinutes 分钟数格式化为 HH:MM（超过 24 小时取当天时刻）
+func formatMinutes(m int) string {
+	m = ((m % (24 * 60)) + 24*60) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+// startDistance 两个上班时间的距离（分钟，跨零点取较短一侧）
+func startDistance(a, b int) int {
+	d := abs(a - b)
+	if d > 12*60 {
+		d = 24*60 - d
+	}
+	return d
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
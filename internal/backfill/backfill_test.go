@@ -0,0 +1,112 @@
+package backfill
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestInfer_ClustersNearbyTimes(t *testing.T) {
+	patterns := []Pattern{
+		{Start: 8 * 60, Duration: 480}, {Start: 8 * 60, Duration: 480}, {Start: 8 * 60, Duration: 480},
+		{Start: 8*60 + 10, Duration: 470}, // 08:10-16:00 归入早班
+		{Start: 22 * 60, Duration: 480}, {Start: 22 * 60, Duration: 480},
+		{Start: 23*60 + 50, Duration: 490}, // 23:50 与 22:00 相差超过容差，自成一类
+		{Start: 14 * 60, Duration: 480},
+	}
+	clusters := Infer(patterns, 30)
+	if len(clusters) != 4 {
+		t.Fatalf("len(clusters) = %d, want 4", len(clusters))
+	}
+	early := clusters[0]
+	if early.Center != (Pattern{Start: 480, Duration: 480}) || early.Count != 4 || len(early.Patterns) != 2 {
+		t.Errorf("早班聚类错误: %+v", early)
+	}
+	if clusters[2].Center.String() != "22:00-06:00" || clusters[2].Count != 2 {
+		t.Errorf("跨天班次聚类错误: %+v", clusters[2])
+	}
+}
+
+func TestInfer_StartDistanceWrapsMidnight(t *testing.T) {
+	clusters := Infer([]Pattern{{Start: 23*60 + 50, Duration: 480}, {Start: 23*60 + 50, Duration: 480}, {Start: 10, Duration: 480}}, 30)
+	if len(clusters) != 1 || clusters[0].Count != 3 {
+		t.Errorf("23:50 与 00:10 应归为同一班次: %+v", clusters)
+	}
+}
+
+func TestService_Import(t *testing.T) {
+	store := memstore.New("")
+	orgID := uuid.New()
+	emp := &model.Employee{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "张三", Code: "E001"}
+	store.PutEmployee(emp)
+	existing := &model.Shift{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "晚班", StartTime: "14:00", EndTime: "22:00", Duration: 480}
+	store.PutShift(existing)
+
+	s := NewService(store)
+	s.now = func() time.Time { return time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC) }
+	records := []Record{
+		{EmployeeCode: "E001", Date: "2024-01-30", StartTime: "08:00", EndTime: "16:00"},
+		{EmployeeID: emp.ID.String(), Date: "2024-01-31", StartTime: "08:05", EndTime: "16:00"},
+		{EmployeeCode: "E001", Date: "2024-02-01", StartTime: "14:10", EndTime: "22:00"},
+		{EmployeeCode: "E404", Date: "2024-02-02", StartTime: "08:00", EndTime: "16:00"},
+		{EmployeeCode: "E001", Date: "2024-02-03", StartTime: "8点", EndTime: "16:00"},
+	}
+	opts := Options{Location: time.UTC}
+
+	result, err := s.Import(orgID, records, opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.Mapped != 3 || len(result.Skipped) != 2 || len(result.Months) != 2 {
+		t.Fatalf("回填结果错误: %+v", result)
+	}
+	if len(result.Shifts) != 2 || result.Shifts[0].Reused || !result.Shifts[1].Reused || result.Shifts[1].Shift.ID != existing.ID {
+		t.Fatalf("应新建早班并复用已有晚班: %+v %+v", result.Shifts[0], result.Shifts[1])
+	}
+	if len(store.ListShifts(orgID)) != 2 {
+		t.Errorf("班次库应有 2 个班次, got %d", len(store.ListShifts(orgID)))
+	}
+
+	jan, err := store.GetSchedule(result.Months[0].ScheduleID)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if jan.Status != "published" || len(jan.Assignments) != 2 || jan.Assignments[1].ShiftID != result.Shifts[0].Shift.ID {
+		t.Errorf("一月历史排班错误: %+v", jan)
+	}
+	// 保留实际上下班时间
+	if got := jan.Assignments[1].StartTime.Format("15:04"); got != "08:05" {
+		t.Errorf("StartTime = %s, want 08:05", got)
+	}
+
+	// 重复导入不产生重复分配
+	again, err := s.Import(orgID, records, opts)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if again.Mapped != 0 || again.Duplicates != 3 || len(store.ListSchedules(orgID)) != 2 {
+		t.Errorf("重复导入应全部跳过: %+v", again)
+	}
+}
+
+func TestService_ImportDryRun(t *testing.T) {
+	store := memstore.New("")
+	orgID := uuid.New()
+	store.PutEmployee(&model.Employee{BaseModel: model.NewBaseModel(), OrgID: orgID, Code: "E001"})
+
+	result, err := NewService(store).Import(orgID, []Record{
+		{EmployeeCode: "E001", Date: "2024-01-30", StartTime: "22:00", EndTime: "06:00"},
+	}, Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.Shifts) != 1 || result.Shifts[0].Shift.ShiftType != "night" || result.Shifts[0].Shift.Duration != 480 {
+		t.Errorf("应推断出夜班: %+v", result.Shifts)
+	}
+	if len(store.ListShifts(orgID)) != 0 || len(store.ListSchedules(orgID)) != 0 {
+		t.Error("试运行不应写入")
+	}
+}
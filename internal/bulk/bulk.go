@@ -0,0 +1,398 @@
+// Package bulk 提供批量作业
+// 导入、批量派单、批量验证等大批量请求不再作为单个同步请求处理，而是拆分为批次在后台执行：
+// 每批记录进度和行级错误，整批失败时自动重试，多次重试仍失败的批次可通过恢复重新执行；
+// 作业与原始数据保存在内存存储中，服务重启后从未完成的批次继续。
+// 批次按组织限速执行，避免批量作业挤占同一组织的在线请求
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/security"
+	"github.com/paiban/paiban/pkg/chaos"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+const (
+	// DefaultBatchSize 默认批次大小
+	DefaultBatchSize = 500
+	// MaxBatchSize 最大批次大小
+	MaxBatchSize = 5000
+	// MaxAttempts 单个批次的最大尝试次数
+	MaxAttempts = 3
+)
+
+var (
+	// ErrInvalidJob 作业参数无效
+	ErrInvalidJob = errors.New("批量作业无效")
+	// ErrJobFinished 作业已结束
+	ErrJobFinished = errors.New("批量作业已结束")
+	// ErrNothingToResume 作业没有可恢复的批次
+	ErrNothingToResume = errors.New("批量作业没有可恢复的批次")
+)
+
+// Processor 批次处理函数
+// rows 为本批原始数据，offset 为本批首行在作业中的行号；返回与 rows 一一对应的结果（失败行为 nil）
+// 和行级错误（行号为作业中的行号）。返回 error 表示整批失败，将重试
+type Processor func(ctx context.Context, job *model.BulkJob, offset int, rows []json.RawMessage) ([]json.RawMessage, []model.BulkRowError, error)
+
+// Runner 批量作业执行器
+type Runner struct {
+	store      *memstore.Store
+	processors map[string]Processor
+	limiter    *security.RateLimiter
+	queue      chan uuid.UUID
+	workers    int
+
+	mu      sync.Mutex // 串行化作业的读-改-写
+	queued  map[uuid.UUID]bool
+	running sync.Map // 作业ID -> *sync.Mutex，同一作业同时只在一个工作协程中执行
+	backoff time.Duration
+	now     func() time.Time
+}
+
+// NewRunner 创建批量作业执行器
+// batchesPerSecond 为每个组织每秒最多启动的批次数，workers 为并发执行的作业数
+func NewRunner(store *memstore.Store, batchesPerSecond, workers int) *Runner {
+	if batchesPerSecond <= 0 {
+		batchesPerSecond = 5
+	}
+	if workers <= 0 {
+		workers = 2
+	}
+	return &Runner{
+		store:      store,
+		processors: make(map[string]Processor),
+		limiter:    security.NewRateLimiter(batchesPerSecond, time.Second),
+		queue:      make(chan uuid.UUID, 1024),
+		workers:    workers,
+		queued:     make(map[uuid.UUID]bool),
+		backoff:    time.Second,
+		now:        time.Now,
+	}
+}
+
+// Register 注册作业类型的批次处理函数
+func (r *Runner) Register(kind string, p Processor) {
+	r.processors[kind] = p
+}
+
+// Submit 拆分批次、保存并排队执行作业
+func (r *Runner) Submit(job *model.BulkJob) (*model.BulkJob, error) {
+	if _, ok := r.processors[job.Kind]; !ok {
+		return nil, fmt.Errorf("%w: 不支持的作业类型 %q", ErrInvalidJob, job.Kind)
+	}
+	if len(job.Rows) == 0 {
+		return nil, fmt.Errorf("%w: 没有数据", ErrInvalidJob)
+	}
+	if job.BatchSize <= 0 {
+		job.BatchSize = DefaultBatchSize
+	}
+	if job.BatchSize > MaxBatchSize {
+		return nil, fmt.Errorf("%w: batch_size 不能超过 %d", ErrInvalidJob, MaxBatchSize)
+	}
+
+	now := r.now()
+	job.ID = uuid.New()
+	job.Status = model.BulkQueued
+	job.Total = len(job.Rows)
+	job.Processed, job.Succeeded, job.Failed = 0, 0, 0
+	job.Results = make([]json.RawMessage, len(job.Rows))
+	job.Errors = make([]model.BulkRowError, 0)
+	job.Batches = make([]model.BulkBatch, 0, (len(job.Rows)+job.BatchSize-1)/job.BatchSize)
+	for start := 0; start < len(job.Rows); start += job.BatchSize {
+		end := min(start+job.BatchSize, len(job.Rows))
+		job.Batches = append(job.Batches, model.BulkBatch{Index: len(job.Batches), Start: start, End: end, Status: model.BatchPending})
+	}
+	job.CreatedAt, job.UpdatedAt, job.FinishedAt = now, now, nil
+
+	if err := r.store.PutBulkJob(job); err != nil {
+		return nil, err
+	}
+	r.enqueue(job.ID)
+	return job.Summary(), nil
+}
+
+// Resume 重新执行失败或取消的批次
+func (r *Runner) Resume(id uuid.UUID) (*model.BulkJob, error) {
+	job, err := r.update(id, func(job *model.BulkJob) error {
+		if !job.IsFinished() {
+			return ErrNothingToResume
+		}
+		resumed := 0
+		for i := range job.Batches {
+			b := &job.Batches[i]
+			if b.Status == model.BatchFailed || b.Status == model.BatchCancelled || b.Status == model.BatchPending {
+				b.Status, b.Attempts, b.Error = model.BatchPending, 0, ""
+				resumed++
+			}
+		}
+		if resumed == 0 {
+			return ErrNothingToResume
+		}
+		job.Status, job.FinishedAt = model.BulkQueued, nil
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.enqueue(id)
+	return job.Summary(), nil
+}
+
+// Cancel 取消作业，正在执行的批次完成后停止
+func (r *Runner) Cancel(id uuid.UUID) (*model.BulkJob, error) {
+	job, err := r.update(id, func(job *model.BulkJob) error {
+		if job.IsFinished() {
+			return ErrJobFinished
+		}
+		for i := range job.Batches {
+			if job.Batches[i].Status == model.BatchPending {
+				job.Batches[i].Status = model.BatchCancelled
+			}
+		}
+		now := r.now()
+		job.Status, job.FinishedAt = model.BulkCancelled, &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return job.Summary(), nil
+}
+
+// Run 启动工作协程执行排队的作业，直到 ctx 取消；启动时恢复上次未完成的作业
+func (r *Runner) Run(ctx context.Context) {
+	for _, job := range r.store.ListBulkJobs(uuid.Nil) {
+		if job.IsFinished() {
+			continue
+		}
+		// 重启前正在执行的批次重新执行
+		r.update(job.ID, func(job *model.BulkJob) error {
+			for i := range job.Batches {
+				if job.Batches[i].Status == model.BatchRunning {
+					job.Batches[i].Status = model.BatchPending
+				}
+			}
+			return nil
+		})
+		logger.Info().Str("job_id", job.ID.String()).Str("kind", job.Kind).Msg("恢复未完成的批量作业")
+		r.enqueue(job.ID)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-r.queue:
+					r.dequeue(id)
+					r.execute(ctx, id)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// execute 依次执行作业的待执行批次（同一作业的批次按顺序执行，后续批次可依赖前面批次的结果）
+func (r *Runner) execute(ctx context.Context, id uuid.UUID) {
+	lock, _ := r.running.LoadOrStore(id, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := r.store.GetBulkJob(id)
+		if err != nil || job.Status == model.BulkCancelled {
+			return
+		}
+		index := nextBatch(job)
+		if index < 0 {
+			r.finish(id)
+			return
+		}
+		if !r.wait(ctx, job.OrgID) {
+			return
+		}
+
+		// 标记批次开始
+		job, err = r.update(id, func(job *model.BulkJob) error {
+			if job.Status == model.BulkCancelled {
+				return ErrJobFinished
+			}
+			now := r.now()
+			b := &job.Batches[index]
+			b.Status, b.StartedAt = model.BatchRunning, &now
+			b.Attempts++
+			job.Status = model.BulkRunning
+			return nil
+		})
+		if err != nil {
+			return
+		}
+
+		b := job.Batches[index]
+		results, rowErrors, procErr := r.process(ctx, job, b)
+		if ctx.Err() != nil {
+			// 服务退出：批次保持 running，重启后重新执行
+			return
+		}
+		r.update(id, func(job *model.BulkJob) error {
+			now := r.now()
+			b := &job.Batches[index]
+			b.EndedAt = &now
+			if procErr != nil {
+				b.Error = procErr.Error()
+				b.Status = model.BatchPending
+				if b.Attempts >= MaxAttempts {
+					b.Status = model.BatchFailed
+				}
+				if job.Status == model.BulkCancelled && b.Status == model.BatchPending {
+					b.Status = model.BatchCancelled
+				}
+				return nil
+			}
+			b.Status, b.Error = model.BatchCompleted, ""
+			failedRows := make(map[int]bool, len(rowErrors))
+			for _, e := range rowErrors {
+				failedRows[e.Row] = true
+			}
+			b.Failed = len(failedRows)
+			b.Succeeded = b.End - b.Start - b.Failed
+			copy(job.Results[b.Start:b.End], results)
+			job.Errors = append(job.Errors, rowErrors...)
+			job.Processed += b.End - b.Start
+			job.Succeeded += b.Succeeded
+			job.Failed += b.Failed
+			return nil
+		})
+		if procErr != nil {
+			logger.Warn().Err(procErr).
+				Str("job_id", id.String()).
+				Int("batch", index).
+				Int("attempt", b.Attempts).
+				Msg("批量作业批次执行失败")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(r.backoff * time.Duration(b.Attempts)):
+			}
+		}
+	}
+}
+
+// process 执行单个批次，处理函数 panic 时视为整批失败
+func (r *Runner) process(ctx context.Context, job *model.BulkJob, b model.BulkBatch) (results []json.RawMessage, rowErrors []model.BulkRowError, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("批次处理异常: %v", p)
+		}
+	}()
+	chaos.Panic(chaos.JobCrash, job.Kind)
+	results, rowErrors, err = r.processors[job.Kind](ctx, job, b.Start, job.Rows[b.Start:b.End])
+	if err == nil && len(results) != b.End-b.Start {
+		err = fmt.Errorf("批次结果数量不符: %d/%d", len(results), b.End-b.Start)
+	}
+	return results, rowErrors, err
+}
+
+// finish 所有批次执行完毕后结束作业
+func (r *Runner) finish(id uuid.UUID) {
+	job, err := r.update(id, func(job *model.BulkJob) error {
+		if job.IsFinished() {
+			return ErrJobFinished
+		}
+		now := r.now()
+		job.Status, job.FinishedAt = model.BulkCompleted, &now
+		for _, b := range job.Batches {
+			if b.Status != model.BatchCompleted {
+				job.Status = model.BulkPartial
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	logger.Info().
+		Str("job_id", id.String()).
+		Str("kind", job.Kind).
+		Str("status", job.Status).
+		Int("succeeded", job.Succeeded).
+		Int("failed", job.Failed).
+		Msg("批量作业结束")
+}
+
+// wait 按组织限速，等待可以启动下一个批次
+func (r *Runner) wait(ctx context.Context, orgID uuid.UUID) bool {
+	for !r.limiter.Allow(orgID.String()) {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return true
+}
+
+// update 读取最新的作业、修改并保存
+func (r *Runner) update(id uuid.UUID, fn func(job *model.BulkJob) error) (*model.BulkJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, err := r.store.GetBulkJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(job); err != nil {
+		return nil, err
+	}
+	job.UpdatedAt = r.now()
+	if err := r.store.PutBulkJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// enqueue 作业排队（已在队列中的作业不重复排队）
+func (r *Runner) enqueue(id uuid.UUID) {
+	r.mu.Lock()
+	if r.queued[id] {
+		r.mu.Unlock()
+		return
+	}
+	r.queued[id] = true
+	r.mu.Unlock()
+	go func() { r.queue <- id }()
+}
+
+// dequeue 作业出队
+func (r *Runner) dequeue(id uuid.UUID) {
+	r.mu.Lock()
+	delete(r.queued, id)
+	r.mu.Unlock()
+}
+
+// nextBatch 返回第一个待执行批次的下标，没有时返回 -1
+func nextBatch(job *model.BulkJob) int {
+	for i, b := range job.Batches {
+		if b.Status == model.BatchPending {
+			return i
+		}
+	}
+	return -1
+}
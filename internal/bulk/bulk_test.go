@@ -0,0 +1,167 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// doubler 结果为行值的两倍，负数为行级错误
+func doubler(ctx context.Context, job *model.BulkJob, offset int, rows []json.RawMessage) ([]json.RawMessage, []model.BulkRowError, error) {
+	results := make([]json.RawMessage, len(rows))
+	var rowErrors []model.BulkRowError
+	for i, row := range rows {
+		var n int
+		if err := json.Unmarshal(row, &n); err != nil || n < 0 {
+			rowErrors = append(rowErrors, model.BulkRowError{Row: offset + i, Message: "无效的数值"})
+			continue
+		}
+		results[i] = json.RawMessage(fmt.Sprint(n * 2))
+	}
+	return results, rowErrors, nil
+}
+
+func newTestRunner(t *testing.T, store *memstore.Store) (*Runner, context.CancelFunc) {
+	t.Helper()
+	r := NewRunner(store, 1000, 2)
+	r.backoff = time.Millisecond
+	r.Register(model.BulkKindValidate, doubler)
+	ctx, cancel := context.WithCancel(context.Background())
+	go r.Run(ctx)
+	t.Cleanup(cancel)
+	return r, cancel
+}
+
+func rows(values ...string) []json.RawMessage {
+	result := make([]json.RawMessage, len(values))
+	for i, v := range values {
+		result[i] = json.RawMessage(v)
+	}
+	return result
+}
+
+func waitFinished(t *testing.T, store *memstore.Store, id uuid.UUID) *model.BulkJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := store.GetBulkJob(id)
+		if err == nil && job.IsFinished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("作业 %s 未结束", id)
+	return nil
+}
+
+func TestRunner_BatchesWithRowErrors(t *testing.T) {
+	store := memstore.New("")
+	r, _ := newTestRunner(t, store)
+
+	job, err := r.Submit(&model.BulkJob{OrgID: uuid.New(), Kind: model.BulkKindValidate, BatchSize: 2, Rows: rows("1", "2", "-3", "4", "x")})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if len(job.Batches) != 3 || job.Rows != nil {
+		t.Fatalf("应拆分为 3 个批次且摘要不含原始数据: %+v", job)
+	}
+
+	done := waitFinished(t, store, job.ID)
+	if done.Status != model.BulkCompleted || done.Processed != 5 || done.Succeeded != 3 || done.Failed != 2 {
+		t.Errorf("作业统计错误: %+v", done)
+	}
+	if len(done.Errors) != 2 || done.Errors[0].Row != 2 || done.Errors[1].Row != 4 {
+		t.Errorf("行级错误应使用作业行号: %+v", done.Errors)
+	}
+	if string(done.Results[3]) != "8" || done.Results[2] != nil {
+		t.Errorf("结果应与原始数据对应: %s", done.Results)
+	}
+}
+
+func TestRunner_FailedBatchResume(t *testing.T) {
+	store := memstore.New("")
+	r := NewRunner(store, 1000, 1)
+	r.backoff = time.Millisecond
+	var broken atomic.Bool
+	broken.Store(true)
+	r.Register(model.BulkKindDispatch, func(ctx context.Context, job *model.BulkJob, offset int, rows []json.RawMessage) ([]json.RawMessage, []model.BulkRowError, error) {
+		if offset == 2 && broken.Load() {
+			return nil, nil, errors.New("下游不可用")
+		}
+		return doubler(ctx, job, offset, rows)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	job, _ := r.Submit(&model.BulkJob{OrgID: uuid.New(), Kind: model.BulkKindDispatch, BatchSize: 2, Rows: rows("1", "2", "3", "4", "5")})
+	done := waitFinished(t, store, job.ID)
+	if done.Status != model.BulkPartial || done.Batches[1].Status != model.BatchFailed || done.Batches[1].Attempts != MaxAttempts {
+		t.Fatalf("失败批次应重试后标记失败: %+v", done.Batches)
+	}
+	if done.Batches[2].Status != model.BatchCompleted || done.Processed != 3 {
+		t.Errorf("其余批次应继续执行: %+v", done)
+	}
+
+	broken.Store(false)
+	if _, err := r.Resume(job.ID); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	done = waitFinished(t, store, job.ID)
+	if done.Status != model.BulkCompleted || done.Processed != 5 || string(done.Results[2]) != "6" {
+		t.Errorf("恢复后应完成全部批次: %+v", done)
+	}
+	if _, err := r.Resume(job.ID); err != ErrNothingToResume {
+		t.Errorf("已完成的作业不可恢复, got %v", err)
+	}
+}
+
+func TestRunner_ResumesAfterRestart(t *testing.T) {
+	store := memstore.New("")
+	orgID := uuid.New()
+	// 模拟服务退出时正在执行的作业
+	job := &model.BulkJob{
+		ID: uuid.New(), OrgID: orgID, Kind: model.BulkKindValidate, Status: model.BulkRunning, BatchSize: 1, Total: 2,
+		Rows: rows("1", "2"), Results: make([]json.RawMessage, 2), Errors: []model.BulkRowError{},
+		Batches: []model.BulkBatch{
+			{Index: 0, Start: 0, End: 1, Status: model.BatchCompleted, Succeeded: 1},
+			{Index: 1, Start: 1, End: 2, Status: model.BatchRunning, Attempts: 1},
+		},
+		Processed: 1, Succeeded: 1,
+	}
+	job.Results[0] = json.RawMessage("2")
+	store.PutBulkJob(job)
+
+	newTestRunner(t, store)
+	done := waitFinished(t, store, job.ID)
+	if done.Status != model.BulkCompleted || done.Processed != 2 || string(done.Results[1]) != "4" {
+		t.Errorf("重启后应继续执行未完成的批次: %+v", done)
+	}
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	store := memstore.New("")
+	r := NewRunner(store, 1000, 1)
+	r.Register(model.BulkKindValidate, doubler)
+
+	// 未启动工作协程，作业停留在排队状态
+	job, _ := r.Submit(&model.BulkJob{OrgID: uuid.New(), Kind: model.BulkKindValidate, BatchSize: 1, Rows: rows("1", "2")})
+	cancelled, err := r.Cancel(job.ID)
+	if err != nil || cancelled.Status != model.BulkCancelled || cancelled.Batches[1].Status != model.BatchCancelled {
+		t.Fatalf("Cancel() = %+v, %v", cancelled, err)
+	}
+	if _, err := r.Cancel(job.ID); err != ErrJobFinished {
+		t.Errorf("重复取消应返回 ErrJobFinished, got %v", err)
+	}
+	if _, err := r.Submit(&model.BulkJob{OrgID: uuid.New(), Kind: "unknown", Rows: rows("1")}); !errors.Is(err, ErrInvalidJob) {
+		t.Errorf("未注册的作业类型应被拒绝, got %v", err)
+	}
+}
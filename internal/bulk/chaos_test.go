@@ -0,0 +1,52 @@
+//go:build chaos
+
+package bulk
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/chaos"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestRunner_RecoversFromWorkerCrash(t *testing.T) {
+	t.Cleanup(chaos.Reset)
+	store := memstore.New("")
+	r, _ := newTestRunner(t, store)
+
+	// 前两次执行崩溃，第三次重试成功
+	if _, err := chaos.Arm(chaos.Fault{Point: chaos.JobCrash, Target: model.BulkKindValidate, Count: MaxAttempts - 1}); err != nil {
+		t.Fatalf("Arm() error = %v", err)
+	}
+	job, err := r.Submit(&model.BulkJob{OrgID: uuid.New(), Kind: model.BulkKindValidate, Rows: rows("1", "2")})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	done := waitFinished(t, store, job.ID)
+	if done.Status != model.BulkCompleted || done.Batches[0].Attempts != MaxAttempts || done.Succeeded != 2 {
+		t.Errorf("崩溃后应重试成功: %+v", done)
+	}
+
+	// 持续崩溃：批次失败，解除故障后恢复执行
+	if _, err := chaos.Arm(chaos.Fault{Point: chaos.JobCrash}); err != nil {
+		t.Fatalf("Arm() error = %v", err)
+	}
+	job, err = r.Submit(&model.BulkJob{OrgID: uuid.New(), Kind: model.BulkKindValidate, Rows: rows("3")})
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	done = waitFinished(t, store, job.ID)
+	if done.Status == model.BulkCompleted || done.Batches[0].Status != model.BatchFailed {
+		t.Fatalf("持续崩溃时批次应失败: %+v", done)
+	}
+	chaos.Disarm(chaos.JobCrash)
+	if _, err := r.Resume(job.ID); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	done = waitFinished(t, store, job.ID)
+	if done.Status != model.BulkCompleted || done.Succeeded != 1 {
+		t.Errorf("解除故障后恢复执行应完成: %+v", done)
+	}
+}
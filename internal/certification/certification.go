@@ -0,0 +1,108 @@
+// Package certification 提供证书材料提交与核验
+// 员工提交健康证、无犯罪证明等证书材料（文件引用、发证机构、有效期），管理者审核通过或驳回；
+// 审核通过且在有效期内的证书视为已核验，资质约束可要求证书已核验而不只是登记了证书名称
+package certification
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+var (
+	// ErrInvalidDocument 证书材料缺少必要信息
+	ErrInvalidDocument = errors.New("证书材料无效")
+	// ErrReviewed 证书材料已审核，不能再次核验
+	ErrReviewed = errors.New("证书材料已审核")
+)
+
+// Service 证书核验服务
+type Service struct {
+	store *memstore.Store
+	now   func() time.Time
+}
+
+// NewService 创建证书核验服务
+func NewService(store *memstore.Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// Submit 提交证书材料，状态为待审核
+func (s *Service) Submit(d *model.CertificationDocument) (*model.CertificationDocument, error) {
+	if err := d.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	d.ID = uuid.New()
+	d.Status = model.CertificationPending
+	d.SubmittedAt = s.now()
+	d.ReviewNote, d.ReviewedBy, d.ReviewedAt = "", "", nil
+	if err := s.store.PutCertificationDocument(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Verify 核验通过，只能核验待审核的材料
+func (s *Service) Verify(orgID, id uuid.UUID, reviewer, note string) (*model.CertificationDocument, error) {
+	d, err := s.get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !d.IsPending() {
+		return d, ErrReviewed
+	}
+	return s.review(d, model.CertificationVerified, reviewer, note)
+}
+
+// Reject 驳回，必须填写原因；已核验的材料也可驳回（撤销核验，如发现材料造假）
+func (s *Service) Reject(orgID, id uuid.UUID, reviewer, note string) (*model.CertificationDocument, error) {
+	if note == "" {
+		return nil, fmt.Errorf("%w: 驳回原因不能为空", ErrInvalidDocument)
+	}
+	d, err := s.get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == model.CertificationRejected {
+		return d, ErrReviewed
+	}
+	return s.review(d, model.CertificationRejected, reviewer, note)
+}
+
+// Verified 返回组织内员工已核验的证书（员工ID -> 证书列表）
+func (s *Service) Verified(orgID uuid.UUID) map[uuid.UUID][]model.VerifiedCertification {
+	result := make(map[uuid.UUID][]model.VerifiedCertification)
+	for _, d := range s.store.ListCertificationDocuments(orgID, uuid.Nil, model.CertificationVerified) {
+		result[d.EmployeeID] = append(result[d.EmployeeID], model.VerifiedCertification{
+			Name:      d.Certification,
+			ExpiresAt: d.ExpiresAt,
+		})
+	}
+	return result
+}
+
+// get 获取组织下的证书材料
+func (s *Service) get(orgID, id uuid.UUID) (*model.CertificationDocument, error) {
+	d, err := s.store.GetCertificationDocument(id)
+	if err != nil || d.OrgID != orgID {
+		return nil, memstore.ErrNotFound
+	}
+	return d, nil
+}
+
+// review 记录审核结果
+func (s *Service) review(d *model.CertificationDocument, status, reviewer, note string) (*model.CertificationDocument, error) {
+	now := s.now()
+	d.Status = status
+	d.ReviewNote = note
+	d.ReviewedBy = reviewer
+	d.ReviewedAt = &now
+	if err := s.store.PutCertificationDocument(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,54 @@
+package certification
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestService_ReviewWorkflow(t *testing.T) {
+	s := NewService(memstore.New(""))
+	orgID, empID := uuid.New(), uuid.New()
+
+	if _, err := s.Submit(&model.CertificationDocument{OrgID: orgID, EmployeeID: empID, Certification: "健康证"}); !errors.Is(err, ErrInvalidDocument) {
+		t.Fatalf("缺少文件引用应被拒绝: %v", err)
+	}
+	d, err := s.Submit(&model.CertificationDocument{
+		OrgID: orgID, EmployeeID: empID, Certification: "健康证",
+		FileRef: "docs/health.pdf", Issuer: "区疾控中心", ExpiresAt: "2026-12-31",
+	})
+	if err != nil || d.Status != model.CertificationPending {
+		t.Fatalf("提交失败: %v %+v", err, d)
+	}
+	if len(s.Verified(orgID)) != 0 {
+		t.Error("待审核材料不应视为已核验")
+	}
+
+	if _, err := s.Verify(uuid.New(), d.ID, "manager", ""); err != memstore.ErrNotFound {
+		t.Errorf("其他组织不能核验: %v", err)
+	}
+	if _, err := s.Verify(orgID, d.ID, "manager", ""); err != nil {
+		t.Fatalf("核验失败: %v", err)
+	}
+	if _, err := s.Verify(orgID, d.ID, "manager", ""); !errors.Is(err, ErrReviewed) {
+		t.Errorf("已核验材料不能重复核验: %v", err)
+	}
+	verified := s.Verified(orgID)[empID]
+	if len(verified) != 1 || verified[0].Name != "健康证" || verified[0].ExpiresAt != "2026-12-31" {
+		t.Errorf("已核验证书 = %+v", verified)
+	}
+
+	// 已核验的材料可驳回以撤销核验，驳回必须填写原因
+	if _, err := s.Reject(orgID, d.ID, "manager", ""); !errors.Is(err, ErrInvalidDocument) {
+		t.Errorf("驳回原因为空应被拒绝: %v", err)
+	}
+	if d, err = s.Reject(orgID, d.ID, "manager", "证书编号与发证机构不符"); err != nil || d.Status != model.CertificationRejected {
+		t.Fatalf("驳回失败: %v %+v", err, d)
+	}
+	if len(s.Verified(orgID)) != 0 {
+		t.Error("驳回后不应再视为已核验")
+	}
+}
@@ -0,0 +1,237 @@
+// Package compliance 提供工时合规证明
+// 为已结账期间（结束日期早于组织工资结账日期）签发合规证明：按组织约束配置的工时类硬性规则
+// （每日/每周最长工时、班次间最短休息、最多连续工作天数）逐个员工核查已发布排班，
+// 列出例外及对应的加班审批；证明内容计算 SHA-256 摘要，配置签名密钥时附 HMAC 签名，可导出 JSON 或 PDF
+package compliance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+)
+
+// historyDays 期间开始前加载的已发布分配天数，用于跨期检查休息时间和连续工作天数
+const historyDays = 14
+
+var (
+	// ErrInvalidPeriod 期间格式错误
+	ErrInvalidPeriod = errors.New("期间无效")
+	// ErrPeriodOpen 期间尚未结账
+	ErrPeriodOpen = errors.New("期间尚未结账")
+	// ErrDigestMismatch 证明内容与摘要不符
+	ErrDigestMismatch = errors.New("证明内容与摘要不符")
+	// ErrSignatureMismatch 签名无效
+	ErrSignatureMismatch = errors.New("证明签名无效")
+)
+
+// Service 合规证明服务
+type Service struct {
+	store *memstore.Store
+	key   []byte
+	now   func() time.Time
+}
+
+// NewService 创建合规证明服务
+func NewService(store *memstore.Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// SetSigningKey 设置签名密钥，设置后签发的证明附 HMAC-SHA256 签名，校验时要求签名有效
+func (s *Service) SetSigningKey(key string) {
+	s.key = []byte(key)
+}
+
+// Issue 签发组织在 [startDate, endDate] 期间的合规证明，期间必须已结账
+func (s *Service) Issue(orgID uuid.UUID, startDate, endDate, issuedBy string) (*model.ComplianceCertificate, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start_date 格式应为 YYYY-MM-DD", ErrInvalidPeriod)
+	}
+	if _, err := time.Parse("2006-01-02", endDate); err != nil {
+		return nil, fmt.Errorf("%w: end_date 格式应为 YYYY-MM-DD", ErrInvalidPeriod)
+	}
+	if endDate < startDate {
+		return nil, fmt.Errorf("%w: 结束日期早于开始日期", ErrInvalidPeriod)
+	}
+	locked := s.store.PayrollLockedBefore(orgID)
+	if locked == "" {
+		return nil, fmt.Errorf("%w: 组织尚未进行工资结账", ErrPeriodOpen)
+	}
+	if endDate >= locked {
+		return nil, fmt.Errorf("%w: 结账日期为 %s，只能为此前的期间签发证明", ErrPeriodOpen, locked)
+	}
+
+	c := &model.ComplianceCertificate{
+		ID:        uuid.New(),
+		OrgID:     orgID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Employees: make([]model.EmployeeCompliance, 0),
+		IssuedBy:  issuedBy,
+		IssuedAt:  s.now().UTC().Truncate(time.Second),
+	}
+	var config map[string]interface{}
+	if v, err := s.store.GetConstraintConfig(orgID, 0); err == nil {
+		config, c.ConfigVersion = v.Config, v.Version
+	}
+	if org, err := s.store.GetOrganization(orgID); err == nil {
+		c.OrgName = org.Name
+		config = builtin.WithCycle(config, org.ScheduleCycle)
+	}
+
+	ctx := s.context(orgID, start.AddDate(0, 0, -historyDays).Format("2006-01-02"), startDate, endDate)
+	approvals := s.overtimeApprovals(orgID)
+	exceptions := make(map[uuid.UUID][]model.ComplianceException)
+	for _, rule := range builtin.WorkingTimeRules(config) {
+		cons := rule.Constraint
+		c.Rules = append(c.Rules, model.ComplianceRule{Type: string(cons.Type()), Name: cons.Name(), Limit: rule.Limit})
+		_, _, violations := cons.Evaluate(ctx)
+		for _, v := range violations {
+			e := model.ComplianceException{Rule: string(v.ConstraintType), Date: v.Date, Message: v.Message}
+			if a, ok := approvals[v.EmployeeID.String()+"/"+v.Date]; ok {
+				id := a.ID
+				e.Approved, e.ApprovalID = true, &id
+				e.Reason = a.Title
+				if a.Detail != "" {
+					e.Reason += "：" + a.Detail
+				}
+			}
+			exceptions[v.EmployeeID] = append(exceptions[v.EmployeeID], e)
+		}
+	}
+
+	c.Compliant = true
+	for _, emp := range ctx.Employees {
+		ec := model.EmployeeCompliance{EmployeeID: emp.ID, EmployeeName: emp.Name, Compliant: true}
+		for _, a := range ctx.GetEmployeeAssignments(emp.ID) {
+			ec.Shifts++
+			ec.Hours += a.WorkingHours()
+		}
+		ec.Hours = math.Round(ec.Hours*10) / 10
+		ec.Exceptions = exceptions[emp.ID]
+		sort.SliceStable(ec.Exceptions, func(i, j int) bool {
+			if ec.Exceptions[i].Date != ec.Exceptions[j].Date {
+				return ec.Exceptions[i].Date < ec.Exceptions[j].Date
+			}
+			return ec.Exceptions[i].Rule < ec.Exceptions[j].Rule
+		})
+		for _, e := range ec.Exceptions {
+			c.Exceptions++
+			if e.Approved {
+				c.Approved++
+			} else {
+				ec.Compliant = false
+			}
+		}
+		c.Compliant = c.Compliant && ec.Compliant
+		c.Employees = append(c.Employees, ec)
+	}
+	sort.Slice(c.Employees, func(i, j int) bool {
+		if c.Employees[i].EmployeeName != c.Employees[j].EmployeeName {
+			return c.Employees[i].EmployeeName < c.Employees[j].EmployeeName
+		}
+		return c.Employees[i].EmployeeID.String() < c.Employees[j].EmployeeID.String()
+	})
+
+	c.Digest = Digest(c)
+	c.Signature = s.sign(c.Digest)
+	if err := s.store.AddComplianceCertificate(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Verify 校验证明的摘要和签名（配置了签名密钥时）
+func (s *Service) Verify(c *model.ComplianceCertificate) error {
+	if !hmac.Equal([]byte(Digest(c)), []byte(c.Digest)) {
+		return ErrDigestMismatch
+	}
+	if len(s.key) > 0 && !hmac.Equal([]byte(s.sign(c.Digest)), []byte(c.Signature)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// Digest 计算证明内容（不含 Digest、Signature）的 SHA-256 摘要（十六进制）
+func Digest(c *model.ComplianceCertificate) string {
+	body := *c
+	body.Digest, body.Signature = "", ""
+	data, _ := json.Marshal(&body)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sign 用签名密钥计算摘要的 HMAC-SHA256，未配置密钥时返回空
+func (s *Service) sign(digest string) string {
+	if len(s.key) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(digest))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// context 构建核查上下文：期间内已发布排班的分配为本期分配，historyStart 起至期间开始前的为固定历史
+// 员工为期间内有分配的员工
+func (s *Service) context(orgID uuid.UUID, historyStart, startDate, endDate string) *constraint.Context {
+	ctx := constraint.NewContext(orgID, startDate, endDate)
+	shifts := s.store.ListShifts(orgID)
+	ctx.SetShifts(shifts)
+
+	seen := make(map[uuid.UUID]bool)
+	listed := make(map[uuid.UUID]bool)
+	var current, history []*model.Assignment
+	employees := make([]*model.Employee, 0)
+	for _, schedule := range s.store.ListSchedules(orgID) {
+		if schedule.Status != "published" {
+			continue
+		}
+		for i := range schedule.Assignments {
+			a := schedule.Assignments[i]
+			if seen[a.ID] || a.Status == "cancelled" || a.Date < historyStart || a.Date > endDate {
+				continue
+			}
+			seen[a.ID] = true
+			if a.Date < startDate {
+				history = append(history, &a)
+				continue
+			}
+			current = append(current, &a)
+			if !listed[a.EmployeeID] {
+				listed[a.EmployeeID] = true
+				emp := &model.Employee{BaseModel: model.BaseModel{ID: a.EmployeeID}, Name: a.EmployeeID.String()}
+				if stored, err := s.store.GetEmployee(a.EmployeeID); err == nil {
+					emp = stored
+				}
+				employees = append(employees, emp)
+			}
+		}
+	}
+	ctx.SetEmployees(employees)
+	ctx.SetAssignments(current)
+	ctx.SetHistory(history, shifts)
+	return ctx
+}
+
+// overtimeApprovals 返回组织审批通过的加班审批（员工ID/日期 -> 审批）
+func (s *Service) overtimeApprovals(orgID uuid.UUID) map[string]*model.ApprovalRequest {
+	result := make(map[string]*model.ApprovalRequest)
+	for _, a := range s.store.ListApprovals(orgID, model.ApprovalApproved) {
+		if a.Kind == model.ApprovalKindOvertime && a.EmployeeID != nil && a.Date != "" {
+			result[a.EmployeeID.String()+"/"+a.Date] = a
+		}
+	}
+	return result
+}
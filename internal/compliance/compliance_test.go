@@ -0,0 +1,117 @@
+package compliance
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// newTestStore 两名员工各有一天 12 小时的班次（超过默认每日 10 小时），其中张三当天有加班审批
+func newTestStore(t *testing.T) (*memstore.Store, uuid.UUID, *model.Employee, *model.Employee) {
+	t.Helper()
+	store := memstore.New("")
+	orgID := uuid.New()
+	zhang := &model.Employee{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "张三"}
+	li := &model.Employee{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "李四"}
+	store.PutEmployee(zhang)
+	store.PutEmployee(li)
+
+	schedule := &model.Schedule{BaseModel: model.NewBaseModel(), OrgID: orgID, StartDate: "2026-03-01", EndDate: "2026-03-31", Status: "published", Version: 1}
+	for _, item := range []struct {
+		emp   *model.Employee
+		date  string
+		hours int
+	}{{zhang, "2026-03-10", 12}, {zhang, "2026-03-12", 8}, {li, "2026-03-11", 12}} {
+		start, _ := time.Parse("2006-01-02 15:04", item.date+" 08:00")
+		schedule.Assignments = append(schedule.Assignments, model.Assignment{
+			BaseModel:  model.NewBaseModel(),
+			EmployeeID: item.emp.ID,
+			ShiftID:    uuid.New(),
+			Date:       item.date,
+			StartTime:  start,
+			EndTime:    start.Add(time.Duration(item.hours) * time.Hour),
+		})
+	}
+	store.PutSchedule(schedule)
+
+	employeeID := zhang.ID
+	store.PutApproval(&model.ApprovalRequest{
+		BaseModel:  model.NewBaseModel(),
+		OrgID:      orgID,
+		Kind:       model.ApprovalKindOvertime,
+		Title:      "盘点加班",
+		EmployeeID: &employeeID,
+		Date:       "2026-03-10",
+		Hours:      2,
+		Status:     model.ApprovalApproved,
+	})
+	return store, orgID, zhang, li
+}
+
+func TestService_IssueRequiresClosedPeriod(t *testing.T) {
+	store, orgID, _, _ := newTestStore(t)
+	s := NewService(store)
+
+	if _, err := s.Issue(orgID, "2026-03-01", "2026-03-31", "hr"); !errors.Is(err, ErrPeriodOpen) {
+		t.Fatalf("未结账期间应被拒绝, got %v", err)
+	}
+	store.AddPayrollClose(&model.PayrollClose{ID: uuid.New(), OrgID: orgID, LockedBefore: "2026-03-16"})
+	if _, err := s.Issue(orgID, "2026-03-01", "2026-03-31", "hr"); !errors.Is(err, ErrPeriodOpen) {
+		t.Errorf("期间结束日期不早于结账日期应被拒绝, got %v", err)
+	}
+	if _, err := s.Issue(orgID, "2026-03-15", "2026-03-01", "hr"); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("结束早于开始应被拒绝, got %v", err)
+	}
+}
+
+func TestService_IssueListsExceptionsWithApprovals(t *testing.T) {
+	store, orgID, zhang, li := newTestStore(t)
+	store.AddPayrollClose(&model.PayrollClose{ID: uuid.New(), OrgID: orgID, LockedBefore: "2026-04-01"})
+	s := NewService(store)
+	s.SetSigningKey("secret")
+
+	c, err := s.Issue(orgID, "2026-03-01", "2026-03-31", "hr")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if c.Compliant || c.Exceptions != 2 || c.Approved != 1 || len(c.Rules) != 4 {
+		t.Fatalf("certificate = %+v", c)
+	}
+	byID := make(map[uuid.UUID]model.EmployeeCompliance)
+	for _, e := range c.Employees {
+		byID[e.EmployeeID] = e
+	}
+	if e := byID[zhang.ID]; !e.Compliant || e.Shifts != 2 || e.Hours != 20 || !e.Exceptions[0].Approved || e.Exceptions[0].Reason != "盘点加班" {
+		t.Errorf("张三 = %+v", e)
+	}
+	if e := byID[li.ID]; e.Compliant || len(e.Exceptions) != 1 || e.Exceptions[0].Approved {
+		t.Errorf("李四 = %+v", e)
+	}
+
+	if c.Digest == "" || c.Signature == "" {
+		t.Fatal("证明应包含摘要和签名")
+	}
+	if err := s.Verify(c); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	tampered := *c
+	tampered.Compliant = true
+	if err := s.Verify(&tampered); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("篡改内容应校验失败, got %v", err)
+	}
+	tampered = *c
+	tampered.Signature = ""
+	if err := s.Verify(&tampered); !errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("缺少签名应校验失败, got %v", err)
+	}
+
+	pdf := RenderPDF(c)
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) || !bytes.Contains(pdf, []byte(c.Digest)) {
+		t.Error("PDF 格式不正确或缺少摘要")
+	}
+}
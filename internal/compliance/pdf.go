@@ -0,0 +1,166 @@
+package compliance
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// PDF 版式：A4 纵向，10 号字，使用阅读器内置的 Adobe 中文字体 STSong-Light（不嵌入字体）
+const (
+	pdfLinesPerPage = 60
+	pdfLineWidth    = 96 // 每行最大宽度（半角字符数，全角字符计 2）
+)
+
+// RenderPDF 将合规证明渲染为 PDF，每页页脚附证明编号和摘要
+func RenderPDF(c *model.ComplianceCertificate) []byte {
+	conclusion := "合规：所有员工均无未审批的例外"
+	if !c.Compliant {
+		conclusion = "不合规：存在未审批的例外"
+	}
+	config := "默认配置"
+	if c.ConfigVersion > 0 {
+		config = fmt.Sprintf("第 %d 版", c.ConfigVersion)
+	}
+
+	lines := []string{
+		"工时合规证明",
+		"",
+		"证明编号：" + c.ID.String(),
+		fmt.Sprintf("组织：%s（%s）", c.OrgName, c.OrgID),
+		fmt.Sprintf("期间：%s 至 %s", c.StartDate, c.EndDate),
+		"约束配置：" + config,
+		"核查规则：",
+	}
+	for _, r := range c.Rules {
+		lines = append(lines, fmt.Sprintf("  - %s（%s）：%d", r.Name, r.Type, r.Limit))
+	}
+	lines = append(lines,
+		"结论："+conclusion,
+		fmt.Sprintf("员工 %d 人，例外 %d 项（其中有审批依据 %d 项）", len(c.Employees), c.Exceptions, c.Approved),
+		"",
+	)
+	for _, e := range c.Employees {
+		status := "合规"
+		if !e.Compliant {
+			status = "不合规"
+		}
+		lines = append(lines, fmt.Sprintf("%s  班次 %d  工时 %.1f  %s", e.EmployeeName, e.Shifts, e.Hours, status))
+		for _, ex := range e.Exceptions {
+			note := "未审批"
+			if ex.Approved {
+				note = "已审批：" + ex.Reason
+			}
+			lines = append(lines, fmt.Sprintf("    [%s] %s（%s）", ex.Date, ex.Message, note))
+		}
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("签发人：%s  签发时间：%s", c.IssuedBy, c.IssuedAt.Format("2006-01-02 15:04:05 MST")),
+		"SHA-256："+c.Digest,
+	)
+	if c.Signature != "" {
+		lines = append(lines, "HMAC-SHA256："+c.Signature)
+	}
+
+	wrapped := make([]string, 0, len(lines))
+	for _, l := range lines {
+		wrapped = append(wrapped, wrapLine(l, pdfLineWidth)...)
+	}
+	return writePDF(wrapped, fmt.Sprintf("%s  SHA-256 %s", c.ID, c.Digest), "sha256:"+c.Digest)
+}
+
+// writePDF 生成分页的纯文本 PDF，footer 印在每页底部，subject（ASCII）写入文档信息便于检索摘要
+func writePDF(lines []string, footer, subject string) []byte {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	// 对象编号：1 目录，2 页面树，3-5 字体，之后每页依次为页面和内容流，最后为文档信息
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // 页面树，页面编号确定后填充
+		"<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>",
+		"<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>",
+		"<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+			"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>",
+	}
+	kids := make([]string, 0, len(pages))
+	for i, page := range pages {
+		pageObj, contentObj := len(objects)+1, len(objects)+2
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+
+		var content bytes.Buffer
+		content.WriteString("BT\n/F1 10 Tf\n14 TL\n50 800 Td\n")
+		for _, l := range page {
+			fmt.Fprintf(&content, "<%s> Tj T*\n", pdfText(l))
+		}
+		content.WriteString("ET\n")
+		fmt.Fprintf(&content, "BT\n/F1 8 Tf\n50 30 Td\n<%s> Tj\nET\n", pdfText(fmt.Sprintf("%s  第 %d/%d 页", footer, i+1, len(pages))))
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", contentObj),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+	objects = append(objects, fmt.Sprintf("<< /Producer (paiban) /Subject (%s) >>", subject))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, len(objects), xref)
+	return buf.Bytes()
+}
+
+// pdfText 将文本编码为 UCS-2 大端十六进制串，BMP 以外的字符替换为 ?
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// wrapLine 按显示宽度折行（ASCII 计 1，其他字符计 2）
+func wrapLine(s string, width int) []string {
+	var lines []string
+	for {
+		w, cut := 0, len(s)
+		for i, r := range s {
+			rw := 2
+			if r < utf8.RuneSelf {
+				rw = 1
+			}
+			if w+rw > width {
+				cut = i
+				break
+			}
+			w += rw
+		}
+		lines = append(lines, s[:cut])
+		if cut == len(s) {
+			return lines
+		}
+		s = "    " + s[cut:]
+	}
+}
@@ -2,42 +2,69 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Config 应用配置
 type Config struct {
-	App        AppConfig        `yaml:"app"`
-	Database   DatabaseConfig   `yaml:"database"`
-	Redis      RedisConfig      `yaml:"redis"`
-	API        APIConfig        `yaml:"api"`
-	Scheduler  SchedulerConfig  `yaml:"scheduler"`
-	Dispatcher DispatcherConfig `yaml:"dispatcher"`
-	Metrics    MetricsConfig    `yaml:"metrics"`
+	App        AppConfig        `yaml:"app" json:"app"`
+	Server     ServerConfig     `yaml:"server" json:"server"`
+	Database   DatabaseConfig   `yaml:"database" json:"database"`
+	Redis      RedisConfig      `yaml:"redis" json:"redis"`
+	API        APIConfig        `yaml:"api" json:"api"`
+	Auth       AuthConfig       `yaml:"auth" json:"auth"`
+	Scheduler  SchedulerConfig  `yaml:"scheduler" json:"scheduler"`
+	Dispatcher DispatcherConfig `yaml:"dispatcher" json:"dispatcher"`
+	Store      StoreConfig      `yaml:"store" json:"store"`
+	Jobs       JobsConfig       `yaml:"jobs" json:"jobs"`
+	Notify     NotifyConfig     `yaml:"notify" json:"notify"`
+	Security   SecurityConfig   `yaml:"security" json:"security"`
+	Metrics    MetricsConfig    `yaml:"metrics" json:"metrics"`
 }
 
 // AppConfig 应用基础配置
 type AppConfig struct {
-	Name     string `yaml:"name"`
-	Env      string `yaml:"env"`
-	Port     int    `yaml:"port"`
-	LogLevel string `yaml:"log_level"`
+	Name     string `yaml:"name" json:"name"`
+	Env      string `yaml:"env" json:"env"` // development/test/production
+	Port     int    `yaml:"port" json:"port"`
+	LogLevel string `yaml:"log_level" json:"log_level"` // debug/info/warn/error
+}
+
+// ServerConfig HTTP 服务配置
+type ServerConfig struct {
+	ReadTimeout     time.Duration `yaml:"read_timeout" json:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" json:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" json:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" json:"shutdown_timeout"` // 优雅关闭等待进行中请求的时间
+
+	// gRPC 服务端口（排班、校验和派单接口，见 api/proto），0 表示不启动
+	GRPCPort int `yaml:"grpc_port" json:"grpc_port"`
 }
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
-	Host            string        `yaml:"host"`
-	Port            int           `yaml:"port"`
-	Name            string        `yaml:"name"`
-	User            string        `yaml:"user"`
-	Password        string        `yaml:"password"`
-	SSLMode         string        `yaml:"ssl_mode"`
-	MaxOpenConns    int           `yaml:"max_open_conns"`
-	MaxIdleConns    int           `yaml:"max_idle_conns"`
-	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	Host            string        `yaml:"host" json:"host"` // 为空时不连接数据库（无数据库模式）
+	Port            int           `yaml:"port" json:"port"`
+	Name            string        `yaml:"name" json:"name"`
+	User            string        `yaml:"user" json:"user"`
+	Password        string        `yaml:"password" json:"password"`
+	SSLMode         string        `yaml:"ssl_mode" json:"ssl_mode"`
+	MaxOpenConns    int           `yaml:"max_open_conns" json:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" json:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" json:"conn_max_lifetime"`
+	AutoMigrate     bool          `yaml:"auto_migrate" json:"auto_migrate"` // 启动时执行未执行的数据库迁移
 }
 
 // DSN 返回数据库连接字符串
@@ -48,13 +75,18 @@ func (c *DatabaseConfig) DSN() string {
 	)
 }
 
+// Enabled 是否配置了数据库
+func (c *DatabaseConfig) Enabled() bool {
+	return c.Host != ""
+}
+
 // RedisConfig Redis配置
 type RedisConfig struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	Password string `yaml:"password"`
-	DB       int    `yaml:"db"`
-	PoolSize int    `yaml:"pool_size"`
+	Host     string `yaml:"host" json:"host"`
+	Port     int    `yaml:"port" json:"port"`
+	Password string `yaml:"password" json:"password"`
+	DB       int    `yaml:"db" json:"db"`
+	PoolSize int    `yaml:"pool_size" json:"pool_size"`
 }
 
 // Addr 返回Redis地址
@@ -64,91 +96,653 @@ func (c *RedisConfig) Addr() string {
 
 // APIConfig API配置
 type APIConfig struct {
-	RateLimit int           `yaml:"rate_limit"`
-	Timeout   time.Duration `yaml:"timeout"`
-	CORS      CORSConfig    `yaml:"cors"`
+	RateLimit          float64            `yaml:"rate_limit" json:"rate_limit"`                     // 每个客户端每秒请求数，0 表示不限制
+	RateBurst          int                `yaml:"rate_burst" json:"rate_burst"`                     // 突发请求数，0 时取速率的 2 倍
+	RateLimitEndpoints []EndpointRateRule `yaml:"rate_limit_endpoints" json:"rate_limit_endpoints"` // 按路径前缀覆盖默认规则
+	TrustProxy         bool               `yaml:"trust_proxy" json:"trust_proxy"`                   // 按 X-Forwarded-For 识别客户端 IP
+	Timeout            time.Duration      `yaml:"timeout" json:"timeout"`
+	CORS               CORSConfig         `yaml:"cors" json:"cors"`
+}
+
+// RateLimits 返回限流配置
+func (c *APIConfig) RateLimits() *RateLimitConfig {
+	return &RateLimitConfig{
+		Default:    RateRule{QPS: c.RateLimit, Burst: c.RateBurst},
+		Endpoints:  c.RateLimitEndpoints,
+		TrustProxy: c.TrustProxy,
+	}
+}
+
+// RateLimitConfig 请求限流配置：按客户端（API 密钥、令牌或 IP）分别计数的令牌桶
+type RateLimitConfig struct {
+	Default    RateRule           `json:"default"`
+	Endpoints  []EndpointRateRule `json:"endpoints,omitempty"`   // 按路径前缀覆盖默认规则，取最长匹配
+	TrustProxy bool               `json:"trust_proxy,omitempty"` // 按 X-Forwarded-For 的首个地址识别客户端 IP
+}
+
+// RateRule 令牌桶规则
+type RateRule struct {
+	QPS   float64 `yaml:"qps" json:"qps"`     // 每秒补充的令牌数，0 表示不限制
+	Burst int     `yaml:"burst" json:"burst"` // 桶容量（允许的突发请求数），为 0 时取 QPS 的 2 倍
+}
+
+// EndpointRateRule 接口限流规则
+type EndpointRateRule struct {
+	Path     string `yaml:"path" json:"path"` // 路径前缀，如 /api/v1/schedule/generate
+	RateRule `yaml:",inline"`
 }
 
 // CORSConfig 跨域配置
 type CORSConfig struct {
-	Enabled bool     `yaml:"enabled"`
-	Origins []string `yaml:"origins"`
+	Enabled bool     `yaml:"enabled" json:"enabled"`
+	Origins []string `yaml:"origins" json:"origins"` // 允许的来源，"*" 表示全部
+}
+
+// AllowOrigin 返回对请求来源应答的 Access-Control-Allow-Origin，不允许时返回空串
+func (c *CORSConfig) AllowOrigin(origin string) string {
+	for _, o := range c.Origins {
+		if o == "*" {
+			return "*"
+		}
+		if origin != "" && strings.EqualFold(o, origin) {
+			return origin
+		}
+	}
+	return ""
 }
 
 // SchedulerConfig 排班引擎配置
 type SchedulerConfig struct {
-	DefaultTimeout    time.Duration `yaml:"default_timeout"`
-	MaxIterations     int           `yaml:"max_iterations"`
-	OptimizationLevel int           `yaml:"optimization_level"` // 1=快速, 2=平衡, 3=最优
+	DefaultTimeout    time.Duration `yaml:"default_timeout" json:"default_timeout"`       // 请求未指定 timeout_seconds 时的求解超时
+	MaxIterations     int           `yaml:"max_iterations" json:"max_iterations"`         // 优化阶段的最大迭代次数
+	OptimizationLevel int           `yaml:"optimization_level" json:"optimization_level"` // 请求未指定时的优化级别：1=快速, 2=平衡, 3=最优
+	Workers           int           `yaml:"workers" json:"workers"`                       // 请求未指定时并行评估候选人的协程数，0 表示逐个评估
+
+	// 默认约束参数（如 workload_balance_weight、preference_weight 等软约束权重），
+	// 优先级低于组织默认约束、场景模板和请求中的 constraints
+	Constraints map[string]interface{} `yaml:"constraints" json:"constraints,omitempty"`
+	CatalogPath string                 `yaml:"catalog_path" json:"catalog_path,omitempty"` // 约束目录 JSON 文件
 }
 
 // DispatcherConfig 派单引擎配置
 type DispatcherConfig struct {
-	DefaultTimeout time.Duration `yaml:"default_timeout"`
-	OptimizeRoute  bool          `yaml:"optimize_route"`
-	MaxDistanceKm  float64       `yaml:"max_distance_km"`
+	DefaultTimeout time.Duration `yaml:"default_timeout" json:"default_timeout"`
+	OptimizeRoute  bool          `yaml:"optimize_route" json:"optimize_route"`
+	MaxDistanceKm  float64       `yaml:"max_distance_km" json:"max_distance_km"`
+	Travel         TravelConfig  `yaml:"travel" json:"travel"`
+}
+
+// TravelConfig 路程估算服务配置
+type TravelConfig struct {
+	Provider string        `yaml:"provider" json:"provider"` // osrm/amap/google，为空时按直线距离估算
+	URL      string        `yaml:"url" json:"url"`
+	Key      string        `yaml:"key" json:"key"`
+	SpeedKmh float64       `yaml:"speed_kmh" json:"speed_kmh"` // 退回直线估算时的平均速度，0 表示默认
+	CacheTTL time.Duration `yaml:"cache_ttl" json:"cache_ttl"` // 0 表示默认
+}
+
+// StoreConfig 内存状态存储配置
+type StoreConfig struct {
+	SnapshotPath     string        `yaml:"snapshot_path" json:"snapshot_path"` // 为空时不启用内存存储
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" json:"snapshot_interval"`
+}
+
+// JobsConfig 后台任务配置（需启用内存存储）
+type JobsConfig struct {
+	PublicationCheckInterval   time.Duration `yaml:"publication_check_interval" json:"publication_check_interval"`
+	SummaryCheckInterval       time.Duration `yaml:"summary_check_interval" json:"summary_check_interval"`
+	ApprovalCheckInterval      time.Duration `yaml:"approval_check_interval" json:"approval_check_interval"`
+	DocumentAlertCheckInterval time.Duration `yaml:"document_alert_check_interval" json:"document_alert_check_interval"`
+	HRSyncQueueSize            int           `yaml:"hrsync_queue_size" json:"hrsync_queue_size"`
+	HRSyncRate                 int           `yaml:"hrsync_rate" json:"hrsync_rate"`         // HR 同步事件每秒处理数量
+	BulkBatchRate              int           `yaml:"bulk_batch_rate" json:"bulk_batch_rate"` // 批量作业每个组织每秒最多启动的批次数
+	BulkWorkers                int           `yaml:"bulk_workers" json:"bulk_workers"`
+	GenerateJobWorkers         int           `yaml:"generate_job_workers" json:"generate_job_workers"`
+}
+
+// NotifyConfig 通知配置
+type NotifyConfig struct {
+	WebhookURL         string `yaml:"webhook_url" json:"webhook_url"`                   // 为空时通知仅写入日志
+	IncidentWebhookURL string `yaml:"incident_webhook_url" json:"incident_webhook_url"` // 请求处理 panic 时投递事故报告
+
+	// 组织通知订阅（/api/v1/webhooks）的投递重试
+	WebhookMaxAttempts int           `yaml:"webhook_max_attempts" json:"webhook_max_attempts"` // 最多投递次数（含首次）
+	WebhookBackoff     time.Duration `yaml:"webhook_backoff" json:"webhook_backoff"`           // 首次重试间隔，之后每次加倍
+
+	SMTP SMTPConfig `yaml:"smtp" json:"smtp"` // 邮件订阅的发件服务，host 为空时不发送邮件
+}
+
+// SMTPConfig SMTP 发件服务配置
+type SMTPConfig struct {
+	Host     string `yaml:"host" json:"host"`
+	Port     int    `yaml:"port" json:"port"`
+	Username string `yaml:"username" json:"username"` // 为空时不认证
+	Password string `yaml:"password" json:"password"`
+	From     string `yaml:"from" json:"from"`
+}
+
+// SecurityConfig 签名密钥配置
+type SecurityConfig struct {
+	ComplianceSigningKey string `yaml:"compliance_signing_key" json:"compliance_signing_key"`
+	ShareLinkSecret      string `yaml:"share_link_secret" json:"share_link_secret"` // 为空时使用随机密钥
 }
 
 // MetricsConfig 监控配置
 type MetricsConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	Path    string `yaml:"path"`
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Path    string `yaml:"path" json:"path"`
 }
 
-// Load 从环境变量加载配置
-func Load() (*Config, error) {
-	cfg := &Config{
-		App: AppConfig{
-			Name:     getEnv("APP_NAME", "paiban"),
-			Env:      getEnv("APP_ENV", "development"),
-			Port:     getEnvInt("APP_PORT", 7012),
-			LogLevel: getEnv("APP_LOG_LEVEL", "info"),
+// AuthConfig API 认证配置
+type AuthConfig struct {
+	Enabled   bool           `yaml:"enabled" json:"enabled"`
+	APIKeys   []APIKeyConfig `yaml:"api_keys" json:"api_keys"`
+	JWT       JWTConfig      `yaml:"jwt" json:"jwt"`
+	RateLimit int            `yaml:"rate_limit" json:"rate_limit"` // 每个凭证默认的每秒请求数，覆盖限流默认规则的速率，0 表示沿用默认规则
+}
+
+// APIKeyConfig 静态 API 密钥
+type APIKeyConfig struct {
+	Name      string `yaml:"name" json:"name"`
+	Key       string `yaml:"key" json:"key"`
+	OrgID     string `yaml:"org_id" json:"org_id"`                             // 密钥所属组织，"*" 表示可访问全部组织
+	RateLimit int    `yaml:"rate_limit,omitempty" json:"rate_limit,omitempty"` // 覆盖凭证默认的每秒请求数
+
+	// 密钥的角色（如 admin、manager），作为 X-User-Role 传给处理器，为空时不带角色
+	Role string `yaml:"role,omitempty" json:"role,omitempty"`
+}
+
+// JWTConfig JWT 认证配置
+type JWTConfig struct {
+	HS256Secret   string `yaml:"hs256_secret,omitempty" json:"hs256_secret,omitempty"`
+	PublicKeyFile string `yaml:"rs256_public_key_file,omitempty" json:"rs256_public_key_file,omitempty"` // RS256 公钥（PEM）文件
+	Issuer        string `yaml:"issuer,omitempty" json:"issuer,omitempty"`
+	Audience      string `yaml:"audience,omitempty" json:"audience,omitempty"`
+}
+
+// Default 返回默认配置
+func Default() *Config {
+	return &Config{
+		App: AppConfig{Name: "paiban", Env: "development", Port: 7012, LogLevel: "info"},
+		Server: ServerConfig{
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    60 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
 		},
 		Database: DatabaseConfig{
-			Host:            getEnv("DB_HOST", "localhost"),
-			Port:            getEnvInt("DB_PORT", 5432),
-			Name:            getEnv("DB_NAME", "paiban"),
-			User:            getEnv("DB_USER", "paiban"),
-			Password:        getEnv("DB_PASSWORD", "paiban123"),
-			SSLMode:         getEnv("DB_SSL_MODE", "disable"),
-			MaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 5),
-			ConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
-		},
-		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnvInt("REDIS_PORT", 6379),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnvInt("REDIS_DB", 0),
-			PoolSize: getEnvInt("REDIS_POOL_SIZE", 10),
+			Port:            5432,
+			Name:            "paiban",
+			User:            "paiban",
+			Password:        "paiban123",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 5 * time.Minute,
+			AutoMigrate:     true,
 		},
+		Redis: RedisConfig{Host: "localhost", Port: 6379, PoolSize: 10},
 		API: APIConfig{
-			RateLimit: getEnvInt("API_RATE_LIMIT", 100),
-			Timeout:   getEnvDuration("API_TIMEOUT", 30*time.Second),
-			CORS: CORSConfig{
-				Enabled: getEnvBool("API_CORS_ENABLED", true),
-				Origins: []string{"*"},
-			},
+			RateLimit: 100,
+			Timeout:   30 * time.Second,
+			CORS:      CORSConfig{Enabled: true, Origins: []string{"*"}},
 		},
 		Scheduler: SchedulerConfig{
-			DefaultTimeout:    getEnvDuration("SCHEDULER_TIMEOUT", 30*time.Second),
-			MaxIterations:     getEnvInt("SCHEDULER_MAX_ITERATIONS", 1000),
-			OptimizationLevel: getEnvInt("SCHEDULER_OPTIMIZATION_LEVEL", 2),
+			DefaultTimeout:    30 * time.Second,
+			MaxIterations:     1000,
+			OptimizationLevel: 1,
 		},
 		Dispatcher: DispatcherConfig{
-			DefaultTimeout: getEnvDuration("DISPATCHER_TIMEOUT", 5*time.Second),
-			OptimizeRoute:  getEnvBool("DISPATCHER_OPTIMIZE_ROUTE", true),
-			MaxDistanceKm:  getEnvFloat("DISPATCHER_MAX_DISTANCE", 15.0),
+			DefaultTimeout: 5 * time.Second,
+			OptimizeRoute:  true,
+			MaxDistanceKm:  15.0,
+		},
+		Store: StoreConfig{SnapshotInterval: time.Minute},
+		Jobs: JobsConfig{
+			PublicationCheckInterval:   time.Minute,
+			SummaryCheckInterval:       time.Hour,
+			ApprovalCheckInterval:      15 * time.Minute,
+			DocumentAlertCheckInterval: time.Hour,
+			HRSyncQueueSize:            1000,
+			HRSyncRate:                 20,
+			BulkBatchRate:              5,
+			BulkWorkers:                2,
+			GenerateJobWorkers:         1,
 		},
-		Metrics: MetricsConfig{
-			Enabled: getEnvBool("METRICS_ENABLED", true),
-			Path:    getEnv("METRICS_PATH", "/metrics"),
+		Notify: NotifyConfig{
+			WebhookMaxAttempts: 5,
+			WebhookBackoff:     2 * time.Second,
+			SMTP:               SMTPConfig{Port: 587},
 		},
+		Metrics: MetricsConfig{Enabled: true, Path: "/metrics"},
+	}
+}
+
+// Load 加载配置：CONFIG_PATH 指向的 YAML 配置文件（可选）覆盖默认值，环境变量再覆盖同名配置
+func Load() (*Config, error) {
+	return LoadFile(os.Getenv("CONFIG_PATH"))
+}
+
+// LoadFile 加载配置，优先级：环境变量 > AUTH_CONFIG_PATH 认证配置（JSON）> 配置文件 > 默认值。
+// 配置文件中可用 ${NAME:默认值} 引用环境变量；未知的配置项、格式错误和不合法的取值均返回错误
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		if err := decodeYAML([]byte(expandEnv(string(data))), cfg); err != nil {
+			return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+		}
+	}
+	if path := os.Getenv("AUTH_CONFIG_PATH"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取认证配置失败: %w", err)
+		}
+		if err := json.Unmarshal(data, &cfg.Auth); err != nil {
+			return nil, fmt.Errorf("解析认证配置失败: %w", err)
+		}
 	}
 
+	env := &envReader{}
+	cfg.applyEnv(env)
+	if len(env.errs) > 0 {
+		return nil, fmt.Errorf("环境变量配置错误: %s", strings.Join(env.errs, "; "))
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
+// envPattern 配置文件中的环境变量引用：${NAME} 或 ${NAME:默认值}
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::([^}]*))?\}`)
+
+// expandEnv 替换配置文件中的环境变量引用，未设置的变量取默认值
+func expandEnv(data string) string {
+	return envPattern.ReplaceAllStringFunc(data, func(ref string) string {
+		m := envPattern.FindStringSubmatch(ref)
+		if value, ok := os.LookupEnv(m[1]); ok {
+			return value
+		}
+		return m[2]
+	})
+}
+
+// decodeYAML 将 YAML 配置文件写入配置，文件中未出现的配置项保留原值；
+// 未知的配置项、重复的配置项和类型不符的取值返回带配置路径的错误
+func decodeYAML(data []byte, cfg *Config) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return err
+		}
+		paths := make(map[int]string)
+		collectPaths(root.Content[0], "", paths)
+		msgs := make([]string, len(typeErr.Errors))
+		for i, msg := range typeErr.Errors {
+			var line int
+			if _, err := fmt.Sscanf(msg, "line %d:", &line); err == nil && paths[line] != "" {
+				msg = paths[line] + ": " + msg
+			}
+			msgs[i] = msg
+		}
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	cfg.Scheduler.Constraints = jsonValue(cfg.Scheduler.Constraints).(map[string]interface{})
+	return nil
+}
+
+// collectPaths 记录每行对应的配置项路径（如 api.rate_limit_endpoints[0].qps），用于在解码错误中指出配置项；
+// 一行有多个配置项（行内映射）时取最后一个
+func collectPaths(n *yaml.Node, path string, paths map[int]string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			p := joinPath(path, key.Value)
+			if key.Value == "<<" {
+				p = path // 合并键的内容属于当前映射
+			}
+			paths[key.Line] = p
+			collectPaths(value, p, paths)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			paths[item.Line] = p
+			collectPaths(item, p, paths)
+		}
+	}
+}
+
+// jsonValue 将 YAML 解码的整数转换为 float64，与 JSON 解码一致，用于约束参数等自由格式的配置
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = jsonValue(item)
+		}
+		return items
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[k] = jsonValue(item)
+		}
+		return m
+	}
+	return value
+}
+
+// applyEnv 以环境变量覆盖配置
+func (c *Config) applyEnv(env *envReader) {
+	c.App.Name = env.str("APP_NAME", c.App.Name)
+	c.App.Env = env.str("APP_ENV", c.App.Env)
+	c.App.Port = env.int("APP_PORT", c.App.Port)
+	c.App.LogLevel = env.str("APP_LOG_LEVEL", c.App.LogLevel)
+
+	c.Server.ReadTimeout = env.duration("SERVER_READ_TIMEOUT", c.Server.ReadTimeout)
+	c.Server.WriteTimeout = env.duration("SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout)
+	c.Server.IdleTimeout = env.duration("SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout)
+	c.Server.ShutdownTimeout = env.duration("SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout)
+	c.Server.GRPCPort = env.int("GRPC_PORT", c.Server.GRPCPort)
+
+	c.Database.Host = env.str("DB_HOST", c.Database.Host)
+	c.Database.Port = env.int("DB_PORT", c.Database.Port)
+	c.Database.Name = env.str("DB_NAME", c.Database.Name)
+	c.Database.User = env.str("DB_USER", c.Database.User)
+	c.Database.Password = env.str("DB_PASSWORD", c.Database.Password)
+	c.Database.SSLMode = env.str("DB_SSL_MODE", c.Database.SSLMode)
+	c.Database.MaxOpenConns = env.int("DB_MAX_OPEN_CONNS", c.Database.MaxOpenConns)
+	c.Database.MaxIdleConns = env.int("DB_MAX_IDLE_CONNS", c.Database.MaxIdleConns)
+	c.Database.ConnMaxLifetime = env.duration("DB_CONN_MAX_LIFETIME", c.Database.ConnMaxLifetime)
+	c.Database.AutoMigrate = env.bool("DB_AUTO_MIGRATE", c.Database.AutoMigrate)
+
+	c.Redis.Host = env.str("REDIS_HOST", c.Redis.Host)
+	c.Redis.Port = env.int("REDIS_PORT", c.Redis.Port)
+	c.Redis.Password = env.str("REDIS_PASSWORD", c.Redis.Password)
+	c.Redis.DB = env.int("REDIS_DB", c.Redis.DB)
+	c.Redis.PoolSize = env.int("REDIS_POOL_SIZE", c.Redis.PoolSize)
+
+	c.API.RateLimit = env.float("API_RATE_LIMIT", c.API.RateLimit)
+	c.API.RateBurst = env.int("API_RATE_BURST", c.API.RateBurst)
+	c.API.RateLimitEndpoints = env.endpointRules("API_RATE_LIMIT_ENDPOINTS", c.API.RateLimitEndpoints)
+	c.API.TrustProxy = env.bool("API_RATE_LIMIT_TRUST_PROXY", c.API.TrustProxy)
+	c.API.Timeout = env.duration("API_TIMEOUT", c.API.Timeout)
+	c.API.CORS.Enabled = env.bool("API_CORS_ENABLED", c.API.CORS.Enabled)
+	c.API.CORS.Origins = env.list("API_CORS_ORIGINS", c.API.CORS.Origins)
+
+	c.Auth.Enabled = env.bool("AUTH_ENABLED", c.Auth.Enabled)
+	c.Auth.RateLimit = env.int("AUTH_RATE_LIMIT", c.Auth.RateLimit)
+	c.Auth.JWT.HS256Secret = env.str("AUTH_JWT_HS256_SECRET", c.Auth.JWT.HS256Secret)
+	c.Auth.JWT.PublicKeyFile = env.str("AUTH_JWT_RS256_PUBLIC_KEY_FILE", c.Auth.JWT.PublicKeyFile)
+	c.Auth.JWT.Issuer = env.str("AUTH_JWT_ISSUER", c.Auth.JWT.Issuer)
+	c.Auth.JWT.Audience = env.str("AUTH_JWT_AUDIENCE", c.Auth.JWT.Audience)
+	c.Auth.APIKeys = append(c.Auth.APIKeys, env.apiKeys("AUTH_API_KEYS")...)
+
+	c.Scheduler.DefaultTimeout = env.duration("SCHEDULER_TIMEOUT", c.Scheduler.DefaultTimeout)
+	c.Scheduler.MaxIterations = env.int("SCHEDULER_MAX_ITERATIONS", c.Scheduler.MaxIterations)
+	c.Scheduler.OptimizationLevel = env.int("SCHEDULER_OPTIMIZATION_LEVEL", c.Scheduler.OptimizationLevel)
+	c.Scheduler.Workers = env.int("SCHEDULER_WORKERS", c.Scheduler.Workers)
+	c.Scheduler.CatalogPath = env.str("CONSTRAINT_CATALOG_PATH", c.Scheduler.CatalogPath)
+
+	c.Dispatcher.DefaultTimeout = env.duration("DISPATCHER_TIMEOUT", c.Dispatcher.DefaultTimeout)
+	c.Dispatcher.OptimizeRoute = env.bool("DISPATCHER_OPTIMIZE_ROUTE", c.Dispatcher.OptimizeRoute)
+	c.Dispatcher.MaxDistanceKm = env.float("DISPATCHER_MAX_DISTANCE", c.Dispatcher.MaxDistanceKm)
+	c.Dispatcher.Travel.Provider = env.str("TRAVEL_PROVIDER", c.Dispatcher.Travel.Provider)
+	c.Dispatcher.Travel.URL = env.str("TRAVEL_URL", c.Dispatcher.Travel.URL)
+	c.Dispatcher.Travel.Key = env.str("TRAVEL_API_KEY", c.Dispatcher.Travel.Key)
+	c.Dispatcher.Travel.SpeedKmh = env.float("TRAVEL_SPEED_KMH", c.Dispatcher.Travel.SpeedKmh)
+	c.Dispatcher.Travel.CacheTTL = env.duration("TRAVEL_CACHE_TTL", c.Dispatcher.Travel.CacheTTL)
+
+	c.Store.SnapshotPath = env.str("STORE_SNAPSHOT_PATH", c.Store.SnapshotPath)
+	c.Store.SnapshotInterval = env.duration("STORE_SNAPSHOT_INTERVAL", c.Store.SnapshotInterval)
+
+	c.Jobs.PublicationCheckInterval = env.duration("PUBLICATION_CHECK_INTERVAL", c.Jobs.PublicationCheckInterval)
+	c.Jobs.SummaryCheckInterval = env.duration("SUMMARY_CHECK_INTERVAL", c.Jobs.SummaryCheckInterval)
+	c.Jobs.ApprovalCheckInterval = env.duration("APPROVAL_CHECK_INTERVAL", c.Jobs.ApprovalCheckInterval)
+	c.Jobs.DocumentAlertCheckInterval = env.duration("DOCUMENT_ALERT_CHECK_INTERVAL", c.Jobs.DocumentAlertCheckInterval)
+	c.Jobs.HRSyncQueueSize = env.int("HRSYNC_QUEUE_SIZE", c.Jobs.HRSyncQueueSize)
+	c.Jobs.HRSyncRate = env.int("HRSYNC_RATE", c.Jobs.HRSyncRate)
+	c.Jobs.BulkBatchRate = env.int("BULK_BATCH_RATE", c.Jobs.BulkBatchRate)
+	c.Jobs.BulkWorkers = env.int("BULK_WORKERS", c.Jobs.BulkWorkers)
+	c.Jobs.GenerateJobWorkers = env.int("GENERATE_JOB_WORKERS", c.Jobs.GenerateJobWorkers)
+
+	c.Notify.WebhookURL = env.str("NOTIFY_WEBHOOK_URL", c.Notify.WebhookURL)
+	c.Notify.IncidentWebhookURL = env.str("INCIDENT_WEBHOOK_URL", c.Notify.IncidentWebhookURL)
+	c.Notify.WebhookMaxAttempts = env.int("WEBHOOK_MAX_ATTEMPTS", c.Notify.WebhookMaxAttempts)
+	c.Notify.WebhookBackoff = env.duration("WEBHOOK_BACKOFF", c.Notify.WebhookBackoff)
+	c.Notify.SMTP.Host = env.str("SMTP_HOST", c.Notify.SMTP.Host)
+	c.Notify.SMTP.Port = env.int("SMTP_PORT", c.Notify.SMTP.Port)
+	c.Notify.SMTP.Username = env.str("SMTP_USERNAME", c.Notify.SMTP.Username)
+	c.Notify.SMTP.Password = env.str("SMTP_PASSWORD", c.Notify.SMTP.Password)
+	c.Notify.SMTP.From = env.str("SMTP_FROM", c.Notify.SMTP.From)
+
+	c.Security.ComplianceSigningKey = env.str("COMPLIANCE_SIGNING_KEY", c.Security.ComplianceSigningKey)
+	c.Security.ShareLinkSecret = env.str("SHARE_LINK_SECRET", c.Security.ShareLinkSecret)
+
+	c.Metrics.Enabled = env.bool("METRICS_ENABLED", c.Metrics.Enabled)
+	c.Metrics.Path = env.str("METRICS_PATH", c.Metrics.Path)
+}
+
+// maxSchedulerWorkers 与生成接口 workers 选项的上限一致
+const maxSchedulerWorkers = 32
+
+// Validate 校验配置取值，返回全部不合法的配置项
+func (c *Config) Validate() error {
+	var errs []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.App.Env == "development" || c.App.Env == "test" || c.App.Env == "production",
+		"app.env 须为 development/test/production: %q", c.App.Env)
+	check(c.App.Port > 0 && c.App.Port <= 65535, "app.port 超出范围: %d", c.App.Port)
+	check(c.Server.GRPCPort >= 0 && c.Server.GRPCPort <= 65535 && c.Server.GRPCPort != c.App.Port,
+		"server.grpc_port 须为 0-65535 且不同于 app.port: %d", c.Server.GRPCPort)
+	switch strings.ToLower(c.App.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Sprintf("app.log_level 须为 debug/info/warn/error: %q", c.App.LogLevel))
+	}
+
+	check(c.Server.ReadTimeout > 0 && c.Server.WriteTimeout > 0 && c.Server.IdleTimeout > 0 && c.Server.ShutdownTimeout > 0,
+		"server 的超时须大于 0")
+
+	if c.Database.Enabled() {
+		check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port 超出范围: %d", c.Database.Port)
+		check(c.Database.Name != "" && c.Database.User != "", "database.name 和 database.user 不能为空")
+		check(c.Database.MaxOpenConns >= 0 && c.Database.MaxIdleConns >= 0, "database 连接数不能为负数")
+	}
+
+	check(c.API.RateLimit >= 0 && c.API.RateBurst >= 0, "api.rate_limit 和 api.rate_burst 不能为负数")
+	for _, e := range c.API.RateLimitEndpoints {
+		check(strings.HasPrefix(e.Path, "/"), "api.rate_limit_endpoints 的 path 须以 / 开头: %q", e.Path)
+		check(e.QPS >= 0 && e.Burst >= 0, "api.rate_limit_endpoints %s 的 qps 和 burst 不能为负数", e.Path)
+	}
+	check(!c.API.CORS.Enabled || len(c.API.CORS.Origins) > 0, "api.cors 启用时 origins 不能为空")
+
+	if c.Auth.Enabled {
+		check(len(c.Auth.APIKeys) > 0 || c.Auth.JWT.HS256Secret != "" || c.Auth.JWT.PublicKeyFile != "",
+			"auth 启用时须配置 API 密钥或 JWT")
+	}
+	for _, key := range c.Auth.APIKeys {
+		check(key.Key != "" && key.OrgID != "", "API 密钥 %q 缺少 key 或 org_id", key.Name)
+		check(key.RateLimit >= 0, "API 密钥 %q 的 rate_limit 不能为负数", key.Name)
+	}
+	check(c.Auth.RateLimit >= 0, "auth.rate_limit 不能为负数")
+
+	check(c.Scheduler.DefaultTimeout > 0, "scheduler.default_timeout 须大于 0")
+	check(c.Scheduler.OptimizationLevel >= 1 && c.Scheduler.OptimizationLevel <= 3,
+		"scheduler.optimization_level 须为 1-3: %d", c.Scheduler.OptimizationLevel)
+	check(c.Scheduler.Workers >= 0 && c.Scheduler.Workers <= maxSchedulerWorkers,
+		"scheduler.workers 须为 0-%d: %d", maxSchedulerWorkers, c.Scheduler.Workers)
+
+	switch strings.ToLower(c.Dispatcher.Travel.Provider) {
+	case "", "straight", "osrm", "amap", "google":
+	default:
+		errs = append(errs, fmt.Sprintf("dispatcher.travel.provider 须为 osrm/amap/google: %q", c.Dispatcher.Travel.Provider))
+	}
+	check(c.Dispatcher.Travel.SpeedKmh >= 0 && c.Dispatcher.Travel.CacheTTL >= 0, "dispatcher.travel 的速度和缓存时间不能为负数")
+
+	check(c.Store.SnapshotInterval > 0, "store.snapshot_interval 须大于 0")
+	j := c.Jobs
+	check(j.PublicationCheckInterval > 0 && j.SummaryCheckInterval > 0 && j.ApprovalCheckInterval > 0 && j.DocumentAlertCheckInterval > 0,
+		"jobs 的检查间隔须大于 0")
+	check(j.HRSyncQueueSize > 0 && j.HRSyncRate > 0 && j.BulkBatchRate > 0 && j.BulkWorkers > 0 && j.GenerateJobWorkers > 0,
+		"jobs 的队列容量、速率和并发数须大于 0")
+
+	check(c.Notify.WebhookMaxAttempts > 0 && c.Notify.WebhookBackoff > 0, "notify 的 webhook_max_attempts 和 webhook_backoff 须大于 0")
+	if c.Notify.SMTP.Host != "" {
+		check(c.Notify.SMTP.Port > 0 && c.Notify.SMTP.Port <= 65535, "notify.smtp.port 超出范围: %d", c.Notify.SMTP.Port)
+		check(c.Notify.SMTP.From != "", "notify.smtp 启用时 from 不能为空")
+	}
+
+	check(strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path 须以 / 开头: %q", c.Metrics.Path)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("配置不合法: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// redacted 脱敏后的密钥、密码
+const redacted = "******"
+
+// Redacted 返回隐去密码、密钥和 Webhook 地址路径的配置副本，用于日志和管理接口
+func (c *Config) Redacted() *Config {
+	r := *c
+	mask := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return redacted
+	}
+	r.Database.Password = mask(c.Database.Password)
+	r.Redis.Password = mask(c.Redis.Password)
+	r.Auth.JWT.HS256Secret = mask(c.Auth.JWT.HS256Secret)
+	r.Auth.APIKeys = make([]APIKeyConfig, len(c.Auth.APIKeys))
+	for i, key := range c.Auth.APIKeys {
+		key.Key = mask(key.Key)
+		r.Auth.APIKeys[i] = key
+	}
+	r.Dispatcher.Travel.Key = mask(c.Dispatcher.Travel.Key)
+	r.Security.ComplianceSigningKey = mask(c.Security.ComplianceSigningKey)
+	r.Security.ShareLinkSecret = mask(c.Security.ShareLinkSecret)
+	r.Notify.WebhookURL = redactURL(c.Notify.WebhookURL)
+	r.Notify.IncidentWebhookURL = redactURL(c.Notify.IncidentWebhookURL)
+	r.Notify.SMTP.Password = mask(c.Notify.SMTP.Password)
+	return &r
+}
+
+// Values 返回以配置文件中的配置项名为键的配置，时长为 30s 形式的字符串
+func (c *Config) Values() map[string]interface{} {
+	return encode(reflect.ValueOf(c).Elem()).(map[string]interface{})
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// encode 将配置结构转换为以配置项名为键的映射（与配置文件对应），时长输出为 30s 形式的字符串
+func encode(v reflect.Value) interface{} {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return encode(v.Elem())
+	case reflect.Struct:
+		fields := make(map[string]reflect.Value)
+		collectFields(v, fields)
+		m := make(map[string]interface{}, len(fields))
+		for name, field := range fields {
+			m[name] = encode(field)
+		}
+		return m
+	case reflect.Slice:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = encode(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = encode(iter.Value())
+		}
+		return m
+	}
+	return v.Interface()
+}
+
+// collectFields 按 yaml 标签收集结构字段，匿名嵌入的结构展开到同一层
+func collectFields(v reflect.Value, fields map[string]reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			collectFields(v.Field(i), fields)
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = v.Field(i)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// redactURL 只保留协议和主机（Webhook 地址的路径和参数常含令牌）
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return redacted
+	}
+	if u.Path == "" && u.RawQuery == "" && u.User == nil {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host + "/" + redacted
+}
+
 // IsDevelopment 检查是否为开发环境
 func (c *Config) IsDevelopment() bool {
 	return c.App.Env == "development"
@@ -164,46 +758,143 @@ func (c *Config) IsTest() bool {
 	return c.App.Env == "test"
 }
 
-// 辅助函数
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+// envReader 读取环境变量，格式错误的取值记录为错误
+type envReader struct {
+	errs []string
+}
+
+func (e *envReader) lookup(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	return value, ok && value != ""
+}
+
+func (e *envReader) fail(key, value string) {
+	e.errs = append(e.errs, fmt.Sprintf("%s=%q 格式错误", key, value))
+}
+
+func (e *envReader) str(key, defaultValue string) string {
+	if value, ok := e.lookup(key); ok {
 		return value
 	}
 	return defaultValue
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if i, err := strconv.Atoi(value); err == nil {
-			return i
-		}
+func (e *envReader) int(key string, defaultValue int) int {
+	value, ok := e.lookup(key)
+	if !ok {
+		return defaultValue
 	}
-	return defaultValue
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		e.fail(key, value)
+		return defaultValue
+	}
+	return i
+}
+
+func (e *envReader) bool(key string, defaultValue bool) bool {
+	value, ok := e.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.fail(key, value)
+		return defaultValue
+	}
+	return b
+}
+
+func (e *envReader) float(key string, defaultValue float64) float64 {
+	value, ok := e.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		e.fail(key, value)
+		return defaultValue
+	}
+	return f
+}
+
+func (e *envReader) duration(key string, defaultValue time.Duration) time.Duration {
+	value, ok := e.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.fail(key, value)
+		return defaultValue
+	}
+	return d
 }
 
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if b, err := strconv.ParseBool(value); err == nil {
-			return b
+// list 逗号分隔的列表
+func (e *envReader) list(key string, defaultValue []string) []string {
+	value, ok := e.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
-	return defaultValue
+	return items
 }
 
-func getEnvFloat(key string, defaultValue float64) float64 {
-	if value := os.Getenv(key); value != "" {
-		if f, err := strconv.ParseFloat(value, 64); err == nil {
-			return f
+// endpointRules 逗号分隔的 路径前缀=每秒请求数[:突发数]，替换配置文件中的接口规则
+func (e *envReader) endpointRules(key string, defaultValue []EndpointRateRule) []EndpointRateRule {
+	value, ok := e.lookup(key)
+	if !ok {
+		return defaultValue
+	}
+	var rules []EndpointRateRule
+	for _, entry := range e.list(key, nil) {
+		path, spec, ok := strings.Cut(entry, "=")
+		qps, burst, _ := strings.Cut(spec, ":")
+		rule := EndpointRateRule{Path: path}
+		var err error
+		if rule.QPS, err = strconv.ParseFloat(qps, 64); !ok || err != nil {
+			e.fail(key, value)
+			return defaultValue
 		}
+		if burst != "" {
+			if rule.Burst, err = strconv.Atoi(burst); err != nil {
+				e.fail(key, value)
+				return defaultValue
+			}
+		}
+		rules = append(rules, rule)
 	}
-	return defaultValue
+	return rules
 }
 
-func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if d, err := time.ParseDuration(value); err == nil {
-			return d
+// apiKeys 逗号分隔的 名称:密钥:org_id[:每秒请求数[:角色]]，每秒请求数为空表示沿用默认值
+func (e *envReader) apiKeys(key string) []APIKeyConfig {
+	var keys []APIKeyConfig
+	for _, entry := range e.list(key, nil) {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 3 || len(parts) > 5 {
+			e.errs = append(e.errs, fmt.Sprintf("%s 中的密钥 %s 格式错误", key, parts[0]))
+			continue
+		}
+		k := APIKeyConfig{Name: parts[0], Key: parts[1], OrgID: parts[2]}
+		if len(parts) == 5 {
+			k.Role = parts[4]
 		}
+		if len(parts) >= 4 && parts[3] != "" {
+			limit, err := strconv.Atoi(parts[3])
+			if err != nil {
+				e.errs = append(e.errs, fmt.Sprintf("%s 中的密钥 %s 速率限制格式错误", key, parts[0]))
+				continue
+			}
+			k.RateLimit = limit
+		}
+		keys = append(keys, k)
 	}
-	return defaultValue
+	return keys
 }
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv 清除会覆盖配置文件的环境变量（CI 中配置了数据库等环境变量）
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"APP_ENV", "APP_PORT", "APP_LOG_LEVEL", "DB_HOST", "DB_PORT", "DB_NAME", "DB_USER",
+		"DB_PASSWORD", "REDIS_HOST", "REDIS_PORT", "API_RATE_LIMIT", "API_CORS_ORIGINS", "SCHEDULER_WORKERS",
+		"SCHEDULER_OPTIMIZATION_LEVEL", "GRPC_PORT", "AUTH_CONFIG_PATH", "AUTH_ENABLED", "AUTH_API_KEYS", "NOTIFY_WEBHOOK_URL"} {
+		t.Setenv(key, "")
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("TEST_DB_PASSWORD", "s3cret")
+	path := writeConfig(t, `
+# 注释
+app:
+  env: production
+  port: 8080
+server:
+  shutdown_timeout: 30s
+database:
+  host: db.internal
+  password: ${TEST_DB_PASSWORD}
+  user: ${TEST_DB_USER:paiban_app}
+api:
+  rate_limit: 20.5
+  rate_limit_endpoints:
+    - path: /api/v1/schedule/generate
+      qps: 2
+      burst: 4
+  cors:
+    origins: [https://a.example.com, "https://b.example.com"]
+scheduler:
+  optimization_level: 2
+  workers: 4
+  constraints:
+    workload_balance_weight: 80
+    respect_preferences: true
+    positions: [cashier, cook]
+`)
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	if cfg.App.Env != "production" || cfg.App.Port != 8080 || cfg.App.Name != "paiban" {
+		t.Errorf("app = %+v", cfg.App)
+	}
+	if cfg.Server.ShutdownTimeout != 30*time.Second || cfg.Server.ReadTimeout != 30*time.Second {
+		t.Errorf("server = %+v", cfg.Server)
+	}
+	if cfg.Database.Password != "s3cret" || cfg.Database.User != "paiban_app" || !cfg.Database.Enabled() {
+		t.Errorf("database = %+v", cfg.Database)
+	}
+	rl := cfg.API.RateLimits()
+	if rl.Default.QPS != 20.5 || len(rl.Endpoints) != 1 || rl.Endpoints[0].QPS != 2 || rl.Endpoints[0].Burst != 4 {
+		t.Errorf("rate limits = %+v", rl)
+	}
+	if !reflect.DeepEqual(cfg.API.CORS.Origins, []string{"https://a.example.com", "https://b.example.com"}) {
+		t.Errorf("cors origins = %v", cfg.API.CORS.Origins)
+	}
+	if cfg.Scheduler.OptimizationLevel != 2 || cfg.Scheduler.Workers != 4 {
+		t.Errorf("scheduler = %+v", cfg.Scheduler)
+	}
+	want := map[string]interface{}{
+		"workload_balance_weight": float64(80),
+		"respect_preferences":     true,
+		"positions":               []interface{}{"cashier", "cook"},
+	}
+	if !reflect.DeepEqual(cfg.Scheduler.Constraints, want) {
+		t.Errorf("constraints = %#v", cfg.Scheduler.Constraints)
+	}
+}
+
+func TestLoadFileEnvOverride(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, "app:\n  port: 8080\nscheduler:\n  workers: 4\n")
+	t.Setenv("APP_PORT", "9090")
+	t.Setenv("API_CORS_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("AUTH_API_KEYS", "pos:k1:org-1:5")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cfg.App.Port != 9090 {
+		t.Errorf("环境变量应覆盖配置文件, port = %d", cfg.App.Port)
+	}
+	if cfg.Scheduler.Workers != 4 {
+		t.Errorf("workers = %d", cfg.Scheduler.Workers)
+	}
+	if len(cfg.API.CORS.Origins) != 2 || cfg.API.CORS.Origins[1] != "https://b.example.com" {
+		t.Errorf("cors origins = %v", cfg.API.CORS.Origins)
+	}
+	if len(cfg.Auth.APIKeys) != 1 || cfg.Auth.APIKeys[0].RateLimit != 5 {
+		t.Errorf("api keys = %+v", cfg.Auth.APIKeys)
+	}
+
+	t.Setenv("APP_PORT", "abc")
+	if _, err := LoadFile(path); err == nil || !strings.Contains(err.Error(), "APP_PORT") {
+		t.Errorf("格式错误的环境变量应报错, err = %v", err)
+	}
+}
+
+// TestLoadFileYAMLFeatures 配置文件可使用锚点与合并键、行内映射和多行字符串
+func TestLoadFileYAMLFeatures(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `
+api:
+  rate_limit_endpoints:
+    - &generate {path: /api/v1/schedule/generate, qps: 2, burst: 4}
+    - <<: *generate
+      path: /api/v1/dispatch
+dispatcher:
+  travel: {provider: osrm, url: "http://osrm:5000", speed_kmh: 30}
+auth:
+  enabled: true
+  api_keys:
+    - {name: pos, key: k1, org_id: "*", role: admin}
+  jwt:
+    hs256_secret: >-
+      part-one
+      part-two
+security:
+  compliance_signing_key: |
+    line-1
+    line-2
+`)
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	want := []EndpointRateRule{
+		{Path: "/api/v1/schedule/generate", RateRule: RateRule{QPS: 2, Burst: 4}},
+		{Path: "/api/v1/dispatch", RateRule: RateRule{QPS: 2, Burst: 4}},
+	}
+	if !reflect.DeepEqual(cfg.API.RateLimitEndpoints, want) {
+		t.Errorf("rate_limit_endpoints = %+v", cfg.API.RateLimitEndpoints)
+	}
+	if tr := cfg.Dispatcher.Travel; tr.Provider != "osrm" || tr.URL != "http://osrm:5000" || tr.SpeedKmh != 30 {
+		t.Errorf("travel = %+v", tr)
+	}
+	if len(cfg.Auth.APIKeys) != 1 || cfg.Auth.APIKeys[0].OrgID != "*" || cfg.Auth.APIKeys[0].Role != "admin" {
+		t.Errorf("api keys = %+v", cfg.Auth.APIKeys)
+	}
+	if cfg.Auth.JWT.HS256Secret != "part-one part-two" {
+		t.Errorf("折叠字符串 = %q", cfg.Auth.JWT.HS256Secret)
+	}
+	if cfg.Security.ComplianceSigningKey != "line-1\nline-2\n" {
+		t.Errorf("字面量字符串 = %q", cfg.Security.ComplianceSigningKey)
+	}
+
+	// 行内映射中的错误同样报告配置项路径
+	_, err = LoadFile(writeConfig(t, "dispatcher:\n  travel: {speed_kmh: fast}\n"))
+	if err == nil || !strings.Contains(err.Error(), "dispatcher.travel.speed_kmh") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	clearEnv(t)
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"未知配置项", "app:\n  prot: 8080\n", "app.prot"},
+		{"类型错误", "app:\n  port: abc\n", "app.port"},
+		{"时长格式错误", "server:\n  read_timeout: 30\n", "server.read_timeout"},
+		{"缩进错误", "app:\n\tport: 8080\n", "line 2"},
+		{"重复配置项", "app:\n  port: 1\n  port: 2\n", "port"},
+		{"端口超出范围", "app:\n  port: 70000\n", "app.port"},
+		{"gRPC 端口与 HTTP 端口相同", "server:\n  grpc_port: 7012\n", "server.grpc_port"},
+		{"优化级别", "scheduler:\n  optimization_level: 5\n", "scheduler.optimization_level"},
+		{"并行协程数", "scheduler:\n  workers: 64\n", "scheduler.workers"},
+		{"日志级别", "app:\n  log_level: verbose\n", "app.log_level"},
+		{"路程服务", "dispatcher:\n  travel:\n    provider: baidu\n", "dispatcher.travel.provider"},
+		{"接口限流路径", "api:\n  rate_limit_endpoints:\n    - path: api/v1\n      qps: 1\n", "rate_limit_endpoints"},
+		{"CORS 来源", "api:\n  cors:\n    origins: []\n", "api.cors"},
+		{"认证未配置凭证", "auth:\n  enabled: true\n", "auth"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadFile(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(strings.ToLower(err.Error()), strings.ToLower(tt.want)) {
+				t.Errorf("err = %v, 应包含 %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("配置文件不存在时应报错")
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+	cfg, err := LoadFile("")
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("未指定配置文件时应为默认配置: %+v", cfg)
+	}
+	if cfg.Database.Enabled() {
+		t.Error("默认不连接数据库")
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "db-pass"
+	cfg.Auth.APIKeys = []APIKeyConfig{{Name: "pos", Key: "k1", OrgID: "*"}}
+	cfg.Auth.JWT.HS256Secret = "jwt-secret"
+	cfg.Security.ShareLinkSecret = "share"
+	cfg.Notify.WebhookURL = "https://hooks.example.com/services/T000/B000/XXXX"
+
+	r := cfg.Redacted()
+	if r.Database.Password != redacted || r.Auth.APIKeys[0].Key != redacted || r.Auth.JWT.HS256Secret != redacted ||
+		r.Security.ShareLinkSecret != redacted || r.Security.ComplianceSigningKey != "" {
+		t.Errorf("密钥应脱敏: %+v", r)
+	}
+	if r.Notify.WebhookURL != "https://hooks.example.com/"+redacted {
+		t.Errorf("webhook = %s", r.Notify.WebhookURL)
+	}
+	if cfg.Database.Password != "db-pass" || cfg.Auth.APIKeys[0].Key != "k1" {
+		t.Error("脱敏不应修改原配置")
+	}
+
+	values := r.Values()
+	server := values["server"].(map[string]interface{})
+	if server["read_timeout"] != "30s" {
+		t.Errorf("时长应输出为字符串, read_timeout = %v", server["read_timeout"])
+	}
+	if values["database"].(map[string]interface{})["password"] != redacted {
+		t.Errorf("database = %v", values["database"])
+	}
+}
+
+func TestCORSAllowOrigin(t *testing.T) {
+	c := CORSConfig{Enabled: true, Origins: []string{"https://a.example.com"}}
+	if got := c.AllowOrigin("https://A.example.com"); got != "https://A.example.com" {
+		t.Errorf("AllowOrigin = %q", got)
+	}
+	if got := c.AllowOrigin("https://evil.example.com"); got != "" {
+		t.Errorf("未允许的来源应返回空, got %q", got)
+	}
+	c.Origins = []string{"*"}
+	if got := c.AllowOrigin(""); got != "*" {
+		t.Errorf("AllowOrigin = %q", got)
+	}
+}
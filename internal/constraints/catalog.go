@@ -0,0 +1,290 @@
+package constraints
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// CatalogFile 约束目录定义（来自配置文件或数据库）
+// 与内置定义按 name（约束）/ scenario（模板）合并：同名覆盖，新名称追加
+type CatalogFile struct {
+	Library   []ConstraintDefinition `json:"library,omitempty"`
+	Templates []Template             `json:"templates,omitempty"`
+}
+
+// Source 约束目录来源
+type Source interface {
+	Name() string
+	Load() (*CatalogFile, error)
+}
+
+// FileSource 从 JSON 文件加载约束目录，文件不存在时视为没有自定义定义
+type FileSource struct {
+	Path string
+}
+
+// Name 返回来源描述
+func (s FileSource) Name() string {
+	return "file:" + s.Path
+}
+
+// Load 读取并解析约束目录文件
+func (s FileSource) Load() (*CatalogFile, error) {
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return &CatalogFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file CatalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析约束目录文件失败: %w", err)
+	}
+	return &file, nil
+}
+
+// CatalogStatus 约束目录加载状态
+type CatalogStatus struct {
+	Version           int       `json:"version"` // 每次成功加载递增，内置定义为 0
+	Source            string    `json:"source,omitempty"`
+	LoadedAt          time.Time `json:"loaded_at"`
+	LibraryCount      int       `json:"library_count"`
+	TemplateCount     int       `json:"template_count"`
+	CustomDefinitions int       `json:"custom_definitions"` // 来源中的约束定义数（覆盖或新增）
+	CustomTemplates   int       `json:"custom_templates"`   // 来源中的模板数（覆盖或新增）
+}
+
+// Catalog 运行时约束目录（约束库 + 场景模板）
+// 启动时由内置定义与来源合并得到，可在运行时重新加载；加载失败时保留当前目录
+type Catalog struct {
+	mu        sync.RWMutex
+	source    Source
+	library   []ConstraintDefinition
+	templates []Template
+	status    CatalogStatus
+}
+
+// NewCatalog 创建约束目录，初始仅包含内置定义；source 为空时不支持重新加载
+func NewCatalog(source Source) *Catalog {
+	c := &Catalog{source: source}
+	c.apply(&CatalogFile{}, false)
+	return c
+}
+
+// Library 返回当前约束库
+func (c *Catalog) Library() []ConstraintDefinition {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.library
+}
+
+// Templates 返回当前场景模板
+func (c *Catalog) Templates() []Template {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.templates
+}
+
+// Status 返回加载状态
+func (c *Catalog) Status() CatalogStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// Reload 从来源重新加载并替换约束目录；dryRun 为 true 时只校验不替换
+// 校验失败返回 *errors.ValidationErrors
+func (c *Catalog) Reload(dryRun bool) (CatalogStatus, error) {
+	if c.source == nil {
+		return c.Status(), errors.New(errors.CodeNotFound, "未配置约束目录来源")
+	}
+	file, err := c.source.Load()
+	if err != nil {
+		return c.Status(), err
+	}
+	if ve := Validate(file); ve.HasErrors() {
+		return c.Status(), ve
+	}
+	if dryRun {
+		status := c.Status()
+		library, templates := merge(file)
+		status.LibraryCount, status.TemplateCount = len(library), len(templates)
+		status.CustomDefinitions, status.CustomTemplates = len(file.Library), len(file.Templates)
+		return status, nil
+	}
+
+	c.apply(file, true)
+	return c.Status(), nil
+}
+
+// apply 合并内置定义与自定义定义并替换当前目录，bump 为 true 时版本号递增
+func (c *Catalog) apply(file *CatalogFile, bump bool) {
+	library, templates := merge(file)
+	status := CatalogStatus{
+		LoadedAt:          time.Now(),
+		LibraryCount:      len(library),
+		TemplateCount:     len(templates),
+		CustomDefinitions: len(file.Library),
+		CustomTemplates:   len(file.Templates),
+	}
+	if c.source != nil {
+		status.Source = c.source.Name()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status.Version = c.status.Version
+	if bump {
+		status.Version++
+	}
+	c.library = library
+	c.templates = templates
+	c.status = status
+}
+
+// merge 以内置定义为基础合并自定义定义：同名覆盖，新名称按出现顺序追加
+func merge(file *CatalogFile) ([]ConstraintDefinition, []Template) {
+	library := GetLibrary()
+	index := make(map[string]int, len(library))
+	for i, d := range library {
+		index[d.Name] = i
+	}
+	for _, d := range file.Library {
+		if i, ok := index[d.Name]; ok {
+			library[i] = d
+		} else {
+			index[d.Name] = len(library)
+			library = append(library, d)
+		}
+	}
+
+	templates := GetTemplates()
+	scenarios := make(map[string]int, len(templates))
+	for i, t := range templates {
+		scenarios[t.Scenario] = i
+	}
+	for _, t := range file.Templates {
+		if i, ok := scenarios[t.Scenario]; ok {
+			templates[i] = t
+		} else {
+			scenarios[t.Scenario] = len(templates)
+			templates = append(templates, t)
+		}
+	}
+	return library, templates
+}
+
+// Validate 校验自定义约束目录
+func Validate(file *CatalogFile) *errors.ValidationErrors {
+	ve := &errors.ValidationErrors{}
+
+	names := make(map[string]bool)
+	for i, d := range file.Library {
+		field := fmt.Sprintf("library[%d]", i)
+		if d.Name == "" {
+			ve.Add(field+".name", "不能为空")
+		} else if names[d.Name] {
+			ve.Add(field+".name", "重复的约束: "+d.Name)
+		}
+		names[d.Name] = true
+		if d.DisplayName == "" {
+			ve.Add(field+".display_name", "不能为空")
+		}
+		if d.Type != "hard" && d.Type != "soft" {
+			ve.Add(field+".type", "必须为 hard 或 soft")
+		}
+
+		params := make(map[string]bool)
+		for j, p := range d.Params {
+			pfield := fmt.Sprintf("%s.params[%d]", field, j)
+			if p.Name == "" {
+				ve.Add(pfield+".name", "不能为空")
+			} else if params[p.Name] {
+				ve.Add(pfield+".name", "重复的参数: "+p.Name)
+			}
+			params[p.Name] = true
+			validateParam(ve, pfield, p)
+		}
+	}
+
+	scenarios := make(map[string]bool)
+	for i, t := range file.Templates {
+		field := fmt.Sprintf("templates[%d]", i)
+		if t.Scenario == "" {
+			ve.Add(field+".scenario", "不能为空")
+		} else if scenarios[t.Scenario] {
+			ve.Add(field+".scenario", "重复的场景: "+t.Scenario)
+		}
+		scenarios[t.Scenario] = true
+		if t.Name == "" {
+			ve.Add(field+".name", "不能为空")
+		}
+		if len(t.Constraints) == 0 {
+			ve.Add(field+".constraints", "至少包含一条约束")
+		}
+		for j, r := range t.Constraints {
+			rfield := fmt.Sprintf("%s.constraints[%d]", field, j)
+			if r.Name == "" {
+				ve.Add(rfield+".name", "不能为空")
+			}
+			if r.Type != "hard" && r.Type != "soft" {
+				ve.Add(rfield+".type", "必须为 hard 或 soft")
+			}
+		}
+	}
+	return ve
+}
+
+// validateParam 校验参数类型与取值范围（数值参数要求 min <= default <= max）
+func validateParam(ve *errors.ValidationErrors, field string, p ConstraintParam) {
+	switch p.Type {
+	case "int", "float":
+	case "string", "bool", "array":
+		return
+	default:
+		ve.Add(field+".type", "必须为 int/float/string/bool/array")
+		return
+	}
+
+	parse := func(name, v string) (float64, bool) {
+		if v == "" {
+			return 0, false
+		}
+		var (
+			n   float64
+			err error
+		)
+		if p.Type == "int" {
+			var i int
+			i, err = strconv.Atoi(v)
+			n = float64(i)
+		} else {
+			n, err = strconv.ParseFloat(v, 64)
+		}
+		if err != nil {
+			ve.Add(field+"."+name, fmt.Sprintf("不是有效的 %s: %s", p.Type, v))
+			return 0, false
+		}
+		return n, true
+	}
+
+	def, hasDef := parse("default", p.Default)
+	min, hasMin := parse("min", p.Min)
+	max, hasMax := parse("max", p.Max)
+	if hasMin && hasMax && min > max {
+		ve.Add(field+".min", "不能大于 max")
+	}
+	if hasDef && hasMin && def < min {
+		ve.Add(field+".default", "不能小于 min")
+	}
+	if hasDef && hasMax && def > max {
+		ve.Add(field+".default", "不能大于 max")
+	}
+}
@@ -0,0 +1,109 @@
+package constraints
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCatalog(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCatalogReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	catalog := NewCatalog(FileSource{Path: path})
+
+	builtin := len(catalog.Library())
+	if builtin == 0 || len(catalog.Templates()) != 4 {
+		t.Fatalf("内置目录不完整: library=%d, templates=%d", builtin, len(catalog.Templates()))
+	}
+
+	// 文件不存在时仅使用内置定义
+	if _, err := catalog.Reload(false); err != nil {
+		t.Fatalf("文件不存在时应加载成功: %v", err)
+	}
+
+	writeCatalog(t, path, `{
+		"library": [{"name": "max_hours_per_day", "display_name": "每日最大工时", "type": "hard",
+			"params": [{"name": "max_hours", "type": "int", "default": "10", "min": "6", "max": "16"}]}],
+		"templates": [{"scenario": "retail", "name": "零售模板",
+			"constraints": [{"name": "max_hours_per_day", "type": "hard"}]}]
+	}`)
+	status, err := catalog.Reload(false)
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if status.Version != 2 || status.LibraryCount != builtin || status.TemplateCount != 5 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	for _, d := range catalog.Library() {
+		if d.Name == "max_hours_per_day" && d.Params[0].Max != "16" {
+			t.Errorf("同名定义应被覆盖, got max=%s", d.Params[0].Max)
+		}
+	}
+
+	// 校验失败时保留当前目录
+	writeCatalog(t, path, `{"library": [{"name": "max_hours_per_day", "display_name": "x", "type": "hard",
+		"params": [{"name": "max_hours", "type": "int", "default": "20", "min": "6", "max": "16"}]}]}`)
+	if _, err := catalog.Reload(false); err == nil {
+		t.Fatal("默认值超出范围应校验失败")
+	}
+	if catalog.Status().Version != 2 || len(catalog.Templates()) != 5 {
+		t.Error("校验失败不应替换当前目录")
+	}
+}
+
+func TestCatalogReloadDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	writeCatalog(t, path, `{"templates": [{"scenario": "retail", "name": "零售模板",
+		"constraints": [{"name": "max_hours_per_day", "type": "hard"}]}]}`)
+	catalog := NewCatalog(FileSource{Path: path})
+
+	status, err := catalog.Reload(true)
+	if err != nil {
+		t.Fatalf("Reload(dryRun) error = %v", err)
+	}
+	if status.TemplateCount != 5 {
+		t.Errorf("dry run 应报告合并后的模板数, got %d", status.TemplateCount)
+	}
+	if len(catalog.Templates()) != 4 || catalog.Status().Version != 0 {
+		t.Error("dry run 不应替换当前目录")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	file := &CatalogFile{
+		Library: []ConstraintDefinition{
+			{Name: "a", DisplayName: "A", Type: "hard"},
+			{Name: "a", DisplayName: "A", Type: "medium", Params: []ConstraintParam{
+				{Name: "n", Type: "int", Min: "5", Max: "3"},
+				{Name: "f", Type: "float", Default: "abc"},
+				{Name: "x", Type: "date"},
+			}},
+		},
+		Templates: []Template{{Scenario: "retail"}},
+	}
+
+	ve := Validate(file)
+	want := map[string]bool{
+		"library[1].name":              true,
+		"library[1].type":              true,
+		"library[1].params[0].min":     true,
+		"library[1].params[1].default": true,
+		"library[1].params[2].type":    true,
+		"templates[0].name":            true,
+		"templates[0].constraints":     true,
+	}
+	if len(ve.Errors) != len(want) {
+		t.Errorf("got %d errors, want %d: %+v", len(ve.Errors), len(want), ve.Errors)
+	}
+	for _, e := range ve.Errors {
+		if !want[e.Field] {
+			t.Errorf("unexpected error field %s: %s", e.Field, e.Message)
+		}
+	}
+}
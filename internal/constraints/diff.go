@@ -0,0 +1,212 @@
+package constraints
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// 约束/参数变更类型
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// paramOwners 约束配置键所属的约束（与约束库名称一致），未列出的键自成一项
+var paramOwners = map[string]string{
+	"max_hours_per_day":            "max_hours_per_day",
+	"max_hours_per_week":           "max_hours_per_week",
+	"max_hours_per_period":         "max_hours_per_week",
+	"hours_mode":                   "max_hours_per_week",
+	"max_hours_per_cycle":          "max_hours_per_week",
+	"cycle_weeks":                  "schedule_cycle",
+	"cycle_anchor_date":            "schedule_cycle",
+	"fairness_weight":              "workload_fairness",
+	"max_shifts_per_month":         "max_shifts_per_month",
+	"monthly_max_shifts":           "max_shifts_per_month",
+	"min_rest_between_shifts":      "min_rest_between_shifts",
+	"max_consecutive_days":         "max_consecutive_days",
+	"workload_balance_weight":      "workload_balance",
+	"workload_tolerance_percent":   "workload_balance",
+	"preference_weight":            "employee_preference",
+	"minimize_overtime_weight":     "minimize_overtime",
+	"standard_hours_per_week":      "minimize_overtime",
+	"store_hours_budgets":          "store_hours_budget",
+	"store_hours_budget_mode":      "store_hours_budget",
+	"store_hours_budget_weight":    "store_hours_budget",
+	"store_opening_hours":          "store_opening_hours",
+	"hourly_staffing":              "hourly_coverage",
+	"max_on_call_per_week":         "on_call",
+	"fatigue_weight":               "fatigue",
+	"fatigue_threshold":            "fatigue",
+	"standard_hours_per_day":       "overtime_cap",
+	"max_overtime_hours_per_day":   "overtime_cap",
+	"max_overtime_hours_per_month": "overtime_cap",
+	"minor_max_hours_per_day":      "minor_protection",
+	"minor_max_hours_per_week":     "minor_protection",
+	"minor_night_window":           "minor_protection",
+	"minor_min_rest_hours":         "minor_protection",
+	"max_labor_cost_per_week":      "max_labor_cost",
+	"max_labor_cost_per_month":     "max_labor_cost",
+	"max_labor_cost_mode":          "max_labor_cost",
+	"max_labor_cost_weight":        "max_labor_cost",
+	"overtime_pay_rate":            "max_labor_cost",
+	"holiday_handling_weight":      "holiday_handling",
+	"holiday_bonus_rate":           "holiday_handling",
+	"holiday_calendar":             "holiday_handling",
+	"holidays":                     "holiday_handling",
+	"min_peak_staff":               "peak_hours_coverage",
+	"peak_hours":                   "peak_hours_coverage",
+	"max_split_shifts_per_week":    "split_shift",
+	"allow_split_shift":            "split_shift",
+	"clopening_late_end":           "clopening",
+	"clopening_early_start":        "clopening",
+	"max_clopenings_per_week":      "clopening",
+	"shift_rotation_pattern":       "shift_rotation",
+	"rotation_days":                "shift_rotation",
+	"max_consecutive_nights":       "max_consecutive_nights",
+	"post_night_block_nights":      "post_night_rest",
+	"post_night_rest_days":         "post_night_rest",
+	"travel_buffer_minutes":        "travel_time",
+	"customer_preference_weight":   "customer_preference",
+	"caregiver_continuity_weight":  "caregiver_continuity",
+	"service_regularity_weight":    "service_regularity",
+	"max_patients_per_day":         "max_patients_per_day",
+}
+
+// ParamDiff 参数差异
+type ParamDiff struct {
+	Param  string      `json:"param"`
+	Change string      `json:"change"` // added/removed/changed
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// ConstraintDiff 约束差异
+// 约束的全部参数只在一侧出现时为 added/removed，否则为 changed
+type ConstraintDiff struct {
+	Constraint string      `json:"constraint"`
+	Change     string      `json:"change"`
+	Params     []ParamDiff `json:"params"`
+}
+
+// ConfigDiff 两份约束配置的差异
+type ConfigDiff struct {
+	Added     []ConstraintDiff `json:"added"`
+	Removed   []ConstraintDiff `json:"removed"`
+	Changed   []ConstraintDiff `json:"changed"`
+	Unchanged []string         `json:"unchanged"` // 两侧配置相同的约束
+	Identical bool             `json:"identical"`
+}
+
+// Owner 返回约束配置键所属的约束名称
+func Owner(param string) string {
+	if owner, ok := paramOwners[param]; ok {
+		return owner
+	}
+	return param
+}
+
+// Diff 比较两份约束配置（格式同排班请求的 constraints），按约束分组输出参数级差异
+// 数值按 JSON 语义比较（44 与 44.0 视为相同），结果按约束名称排序
+func Diff(base, target map[string]interface{}) ConfigDiff {
+	type sides struct{ base, target map[string]interface{} }
+	groups := make(map[string]*sides)
+	group := func(param string) *sides {
+		owner := Owner(param)
+		if groups[owner] == nil {
+			groups[owner] = &sides{base: map[string]interface{}{}, target: map[string]interface{}{}}
+		}
+		return groups[owner]
+	}
+	for k, v := range base {
+		group(k).base[k] = v
+	}
+	for k, v := range target {
+		group(k).target[k] = v
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := ConfigDiff{
+		Added:     make([]ConstraintDiff, 0),
+		Removed:   make([]ConstraintDiff, 0),
+		Changed:   make([]ConstraintDiff, 0),
+		Unchanged: make([]string, 0),
+	}
+	for _, name := range names {
+		g := groups[name]
+		params := diffParams(g.base, g.target)
+		switch {
+		case len(params) == 0:
+			result.Unchanged = append(result.Unchanged, name)
+		case len(g.base) == 0:
+			result.Added = append(result.Added, ConstraintDiff{Constraint: name, Change: ChangeAdded, Params: params})
+		case len(g.target) == 0:
+			result.Removed = append(result.Removed, ConstraintDiff{Constraint: name, Change: ChangeRemoved, Params: params})
+		default:
+			result.Changed = append(result.Changed, ConstraintDiff{Constraint: name, Change: ChangeChanged, Params: params})
+		}
+	}
+	result.Identical = len(result.Added)+len(result.Removed)+len(result.Changed) == 0
+	return result
+}
+
+// diffParams 比较同一约束两侧的参数
+func diffParams(base, target map[string]interface{}) []ParamDiff {
+	keys := make(map[string]bool, len(base)+len(target))
+	for k := range base {
+		keys[k] = true
+	}
+	for k := range target {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var params []ParamDiff
+	for _, k := range sorted {
+		before, inBase := base[k]
+		after, inTarget := target[k]
+		switch {
+		case !inBase:
+			params = append(params, ParamDiff{Param: k, Change: ChangeAdded, After: after})
+		case !inTarget:
+			params = append(params, ParamDiff{Param: k, Change: ChangeRemoved, Before: before})
+		case !sameValue(before, after):
+			params = append(params, ParamDiff{Param: k, Change: ChangeChanged, Before: before, After: after})
+		}
+	}
+	return params
+}
+
+// sameValue 按 JSON 序列化结果比较两个配置值（map 键有序，数值类型差异不影响结果）
+func sameValue(a, b interface{}) bool {
+	ja, errA := canonicalJSON(a)
+	jb, errB := canonicalJSON(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
+
+// canonicalJSON 序列化后重新解析再序列化，消除 int/float64 及具体类型的差异
+func canonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
+}
@@ -0,0 +1,45 @@
+package constraints
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	base := map[string]interface{}{
+		"max_hours_per_week":      44,
+		"min_rest_between_shifts": 11,
+		"fatigue_weight":          40,
+		"fatigue_threshold":       60.0,
+		"store_hours_budgets":     map[string]interface{}{"store-a": 320},
+	}
+	target := map[string]interface{}{
+		"max_hours_per_week":      44.0, // 数值类型不同但值相同
+		"min_rest_between_shifts": 12,
+		"fatigue_weight":          40,
+		"max_clopenings_per_week": 1,
+		"clopening_late_end":      "22:00",
+		"store_hours_budgets":     map[string]interface{}{"store-a": 320.0},
+	}
+
+	d := Diff(base, target)
+	if d.Identical {
+		t.Fatal("配置不同，不应相同")
+	}
+	if len(d.Added) != 1 || d.Added[0].Constraint != "clopening" || len(d.Added[0].Params) != 2 {
+		t.Errorf("Added = %+v", d.Added)
+	}
+	if len(d.Removed) != 0 {
+		t.Errorf("Removed = %+v", d.Removed)
+	}
+	if len(d.Changed) != 2 || d.Changed[0].Constraint != "fatigue" || d.Changed[1].Constraint != "min_rest_between_shifts" {
+		t.Fatalf("Changed = %+v", d.Changed)
+	}
+	if p := d.Changed[0].Params; len(p) != 1 || p[0].Param != "fatigue_threshold" || p[0].Change != ChangeRemoved {
+		t.Errorf("fatigue params = %+v", p)
+	}
+	if len(d.Unchanged) != 2 {
+		t.Errorf("Unchanged = %v", d.Unchanged)
+	}
+
+	if same := Diff(base, base); !same.Identical {
+		t.Errorf("相同配置应无差异: %+v", same)
+	}
+}
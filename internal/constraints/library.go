@@ -49,10 +49,12 @@ func GetLibrary() []ConstraintDefinition {
 			DisplayName: "每周最大工时",
 			Type:        "hard",
 			Category:    "工时限制",
-			Description: "限制员工每周的累计工作时长，确保符合劳动法规定。",
+			Description: "限制员工每周的累计工作时长，确保符合劳动法规定。两周/四周轮班时按整个周期累计，周期内各周可不均衡。",
 			Scenarios:   []string{"restaurant", "factory", "housekeeping", "nursing"},
 			Params: []ConstraintParam{
 				{Name: "max_hours", Type: "int", Description: "最大工时(小时)", Default: "44", Min: "36", Max: "60"},
+				{Name: "cycle_weeks", Type: "int", Description: "排班周期(周)，支持 1/2/4", Default: "1", Min: "1", Max: "4"},
+				{Name: "max_hours_per_cycle", Type: "int", Description: "每周期最大工时(小时)，默认为周上限乘以周数"},
 			},
 		},
 		{
@@ -107,6 +109,8 @@ func GetLibrary() []ConstraintDefinition {
 			Scenarios:   []string{"restaurant", "housekeeping", "nursing"},
 			Params: []ConstraintParam{
 				{Name: "required_certs", Type: "array", Description: "必需证书列表", Default: "健康证"},
+				{Name: "certification_scenario", Type: "string", Description: "按场景预设证书要求启用（restaurant/factory/housekeeping/nursing）"},
+				{Name: "require_verified_certifications", Type: "bool", Description: "要求证书材料已核验且在有效期内", Default: "false"},
 			},
 		},
 		{
@@ -114,19 +118,57 @@ func GetLibrary() []ConstraintDefinition {
 			DisplayName: "员工不可用时间",
 			Type:        "hard",
 			Category:    "时间限制",
-			Description: "在员工标记为不可用的时间段内不进行排班（如请假、个人事务）。",
+			Description: "在员工请假区间、每周重复的不可用时段及按日期登记的不可用时段内不进行排班（如请假、个人事务）。",
 			Scenarios:   []string{"restaurant", "factory", "housekeeping", "nursing"},
 			Params:      []ConstraintParam{},
 		},
+		{
+			Name:        "cross_store_travel",
+			DisplayName: "跨店通勤",
+			Type:        "hard",
+			Category:    "时间限制",
+			Description: "多门店排班时员工只排到所属门店或可支援门店，相邻班次在不同门店时须留足门店间的通勤时间。",
+			Scenarios:   []string{"restaurant"},
+			Params: []ConstraintParam{
+				{Name: "cross_store_travel_minutes", Type: "int", Description: "门店间未配置通勤时间时的默认通勤时间（分钟）", Default: "60", Min: "0", Max: "240"},
+			},
+		},
 		{
 			Name:        "fixed_shift",
 			DisplayName: "固定班次约束",
 			Type:        "hard",
 			Category:    "排班模式",
-			Description: "部分员工有固定的班次安排（如只上早班或只上夜班）。",
+			Description: "部分员工有固定的班次安排（如只上早班或只上夜班）：按员工偏好中的 fixed_shift_codes（班次编码或班次类型）只排固定班次，fixed_days_off 中的星期不排班。",
 			Scenarios:   []string{"restaurant", "factory"},
 			Params:      []ConstraintParam{},
 		},
+		{
+			Name:        "overtime_cap",
+			DisplayName: "加班上限",
+			Type:        "hard",
+			Category:    "劳动法合规",
+			Description: "每天超过标准日工时、每周超过标准周工时的部分计为加班，限制每日和每月的加班小时数（如《劳动法》每日不超过 3 小时、每月不超过 36 小时）。配置了加班上限或指定合规辖区时启用。",
+			Scenarios:   []string{"restaurant", "factory", "housekeeping", "nursing"},
+			Params: []ConstraintParam{
+				{Name: "max_overtime_hours_per_day", Type: "float", Description: "每日加班上限（小时），0 表示不限制", Default: "0", Min: "0", Max: "12"},
+				{Name: "max_overtime_hours_per_month", Type: "float", Description: "每月加班上限（小时），0 表示不限制", Default: "0", Min: "0", Max: "200"},
+				{Name: "standard_hours_per_day", Type: "float", Description: "标准日工时（小时）", Default: "8", Min: "1", Max: "12"},
+			},
+		},
+		{
+			Name:        "minor_protection",
+			DisplayName: "未成年工保护",
+			Type:        "hard",
+			Category:    "劳动法合规",
+			Description: "对排班日未满 18 周岁的员工（按出生日期）限制每日/每周工时、不安排夜班，并要求更长的班次间休息。配置了 minor_* 参数或指定合规辖区时启用。",
+			Scenarios:   []string{"restaurant", "factory", "housekeeping", "nursing"},
+			Params: []ConstraintParam{
+				{Name: "minor_max_hours_per_day", Type: "float", Description: "每日工时上限（小时）", Default: "0", Min: "0", Max: "12"},
+				{Name: "minor_max_hours_per_week", Type: "float", Description: "每周工时上限（小时）", Default: "0", Min: "0", Max: "60"},
+				{Name: "minor_night_window", Type: "string", Description: "禁止排班的夜间时段（如 22:00-06:00）"},
+				{Name: "minor_min_rest_hours", Type: "float", Description: "班次间最短休息（小时）", Default: "0", Min: "0", Max: "24"},
+			},
+		},
 
 		// =====================================================
 		// 通用软约束
@@ -166,6 +208,58 @@ func GetLibrary() []ConstraintDefinition {
 				{Name: "standard_hours", Type: "int", Description: "标准工时(周)", Default: "40"},
 			},
 		},
+		{
+			Name:        "store_hours_budget",
+			DisplayName: "门店每周工时预算",
+			Type:        "soft",
+			Category:    "成本优化",
+			Description: "按员工所属门店汇总每周排班工时，不超过财务下达的工时预算。可配置为硬约束，超预算的分配直接拒绝。",
+			Scenarios:   []string{"restaurant", "factory", "housekeeping", "nursing"},
+			Params: []ConstraintParam{
+				{Name: "budgets", Type: "object", Description: "门店每周预算工时（门店ID -> 小时），也可通过预算管理接口维护", Default: ""},
+				{Name: "mode", Type: "string", Description: "约束模式 soft/hard", Default: "soft"},
+				{Name: "weight", Type: "int", Description: "优化权重（软约束）", Default: "80", Min: "0", Max: "100"},
+			},
+		},
+		{
+			Name:        "max_labor_cost",
+			DisplayName: "人工成本预算",
+			Type:        "soft",
+			Category:    "成本优化",
+			Description: "按员工时薪及加班、节假日倍率估算每周和每月的人工成本，不超过预算；成本接近预算时优先安排成本低的员工。可配置为硬约束，超预算的分配直接拒绝。",
+			Scenarios:   []string{"restaurant", "factory", "housekeeping", "nursing"},
+			Params: []ConstraintParam{
+				{Name: "per_week", Type: "float", Description: "每周人工成本预算，0 表示不限制", Default: "0", Min: "0"},
+				{Name: "per_month", Type: "float", Description: "每月人工成本预算，0 表示不限制", Default: "0", Min: "0"},
+				{Name: "overtime_pay_rate", Type: "float", Description: "加班工资倍率", Default: "1.5", Min: "1", Max: "5"},
+				{Name: "mode", Type: "string", Description: "约束模式 soft/hard", Default: "soft"},
+				{Name: "weight", Type: "int", Description: "优化权重（软约束，每超出 100 计一次）", Default: "80", Min: "0", Max: "100"},
+			},
+		},
+		{
+			Name:        "fatigue",
+			DisplayName: "疲劳指数",
+			Type:        "soft",
+			Category:    "休息保障",
+			Description: "按近期工时、夜班、长班次和休息不足计算滚动疲劳指数（越早的班次影响越小），达到阈值时扣分，并优先安排疲劳较低的员工。",
+			Scenarios:   []string{"restaurant", "factory", "housekeeping", "nursing"},
+			Params: []ConstraintParam{
+				{Name: "weight", Type: "int", Description: "优化权重，0 表示不启用", Default: "40", Min: "0", Max: "100"},
+				{Name: "threshold", Type: "float", Description: "扣分阈值（疲劳指数 0-100）", Default: "60", Min: "1", Max: "100"},
+			},
+		},
+		{
+			Name:        "schedule_stability",
+			DisplayName: "周间排班稳定",
+			Type:        "soft",
+			Category:    "员工体验",
+			Description: "与员工前几周同一星期几的班次比较，班次类型一致的优先安排、不一致的扣分，并在统计中给出稳定性得分；权重越高越倾向稳定而不是其他优化目标。",
+			Scenarios:   []string{"restaurant", "factory", "housekeeping", "nursing"},
+			Params: []ConstraintParam{
+				{Name: "weight", Type: "int", Description: "优化权重，0 表示不启用", Default: "0", Min: "0", Max: "100"},
+				{Name: "weeks", Type: "int", Description: "比较的周数", Default: "4", Min: "1", Max: "4"},
+			},
+		},
 		{
 			Name:        "senior_junior_pair",
 			DisplayName: "新老搭配",
@@ -183,11 +277,12 @@ func GetLibrary() []ConstraintDefinition {
 			DisplayName: "法定假日处理",
 			Type:        "soft",
 			Category:    "休息保障",
-			Description: "在法定假日优先安排愿意加班的员工，并提供加班补贴。",
+			Description: "内置中国法定节假日及调休安排，可叠加组织自定义节假日。节假日值班按人轮流（含上期历史），节假日加班按加班倍率加权扣分，启用公平性约束时统计节假日工作公平性。",
 			Scenarios:   []string{"restaurant", "factory", "housekeeping", "nursing"},
 			Params: []ConstraintParam{
-				{Name: "weight", Type: "int", Description: "优化权重", Default: "80", Min: "0", Max: "100"},
-				{Name: "bonus_rate", Type: "float", Description: "假日加班倍率", Default: "2.0", Min: "1.0", Max: "3.0"},
+				{Name: "weight", Type: "int", Description: "节假日值班轮换权重，0 表示不启用", Default: "80", Min: "0", Max: "100"},
+				{Name: "bonus_rate", Type: "float", Description: "统一的假日加班倍率，0 表示按类型（法定节假日 3、调休休息日 2）", Default: "0", Min: "0", Max: "5"},
+				{Name: "calendar", Type: "string", Description: "内置节假日日历：cn 或 none", Default: "cn"},
 			},
 		},
 
@@ -220,6 +315,52 @@ func GetLibrary() []ConstraintDefinition {
 				{Name: "allow", Type: "bool", Description: "是否允许两头班", Default: "true"},
 			},
 		},
+		{
+			Name:        "clopening",
+			DisplayName: "晚关早开限制",
+			Type:        "hard",
+			Category:    "休息保障",
+			Description: "限制员工上完晚班（关店）后次日又上早班（开店），即使班次间休息时长已满足也会计数，保护员工睡眠。",
+			Scenarios:   []string{"restaurant"},
+			Params: []ConstraintParam{
+				{Name: "late_end", Type: "string", Description: "晚班下班时间不早于（跨午夜也计入）", Default: "22:00"},
+				{Name: "early_start", Type: "string", Description: "早班上班时间早于", Default: "10:00"},
+				{Name: "max_per_week", Type: "int", Description: "每周最多晚关早开次数", Default: "0", Min: "0", Max: "7"},
+			},
+		},
+		{
+			Name:        "store_opening_hours",
+			DisplayName: "门店营业时间",
+			Type:        "hard",
+			Category:    "时间限制",
+			Description: "员工所属门店配置了营业时间时，班次必须落在营业时间内（含开店准备和闭店收尾时间），节假日按特殊营业时间判断，闭店日不可排班。",
+			Scenarios:   []string{"restaurant"},
+			Params: []ConstraintParam{
+				{Name: "opening_hours", Type: "array", Description: "门店营业时间列表，也可通过营业时间接口维护", Default: ""},
+			},
+		},
+		{
+			Name:        "hourly_coverage",
+			DisplayName: "每小时最低在岗人数",
+			Type:        "hard",
+			Category:    "服务保障",
+			Description: "按分配的实际起止时间统计每个整点小时的在岗人数，指定时段内每小时不得少于最低人数（如 10:00-22:00 至少 2 人）。",
+			Scenarios:   []string{"restaurant", "factory", "nursing"},
+			Params: []ConstraintParam{
+				{Name: "hourly_staffing", Type: "array", Description: "时段及最少在岗人数列表，如 [{\"start\": \"10:00\", \"end\": \"22:00\", \"min_staff\": 2}]", Default: ""},
+			},
+		},
+		{
+			Name:        "on_call",
+			DisplayName: "待命安排",
+			Type:        "hard",
+			Category:    "时间限制",
+			Description: "班次类型为 on_call 的待命班次在常规班次排完后单独安排，待命次数少的员工优先轮流承担；每人每周待命次数有上限，夜班次日不待命，待命时段不与常规班次重叠。待命不计入工时和满足率。",
+			Scenarios:   []string{"restaurant", "factory", "nursing"},
+			Params: []ConstraintParam{
+				{Name: "max_on_call_per_week", Type: "int", Description: "每人每周最多待命次数，0 表示不限", Default: "2", Min: "0", Max: "7"},
+			},
+		},
 		{
 			Name:        "position_coverage",
 			DisplayName: "岗位覆盖",
@@ -244,7 +385,7 @@ func GetLibrary() []ConstraintDefinition {
 			Scenarios:   []string{"factory"},
 			Params: []ConstraintParam{
 				{Name: "pattern", Type: "string", Description: "轮换模式", Default: "三班倒"},
-				{Name: "rotation_days", Type: "int", Description: "轮换周期(天)", Default: "7", Min: "3", Max: "14"},
+				{Name: "rotation_days", Type: "int", Description: "轮换周期(天)，配置排班周期时默认为周期天数", Default: "7", Min: "3", Max: "28"},
 			},
 		},
 		{
@@ -258,6 +399,18 @@ func GetLibrary() []ConstraintDefinition {
 				{Name: "max_nights", Type: "int", Description: "最大连续夜班天数", Default: "4", Min: "2", Max: "7"},
 			},
 		},
+		{
+			Name:        "post_night_rest",
+			DisplayName: "夜班后休息",
+			Type:        "hard",
+			Category:    "休息保障",
+			Description: "员工连续上夜班达到指定天数后，夜班结束后的若干天内不安排任何班次。",
+			Scenarios:   []string{"factory"},
+			Params: []ConstraintParam{
+				{Name: "block_nights", Type: "int", Description: "触发休息的连续夜班天数", Default: "3", Min: "1", Max: "7"},
+				{Name: "rest_days", Type: "int", Description: "夜班后休息天数，0 表示关闭", Default: "2", Min: "0", Max: "7"},
+			},
+		},
 		{
 			Name:        "production_line_coverage",
 			DisplayName: "产线24小时覆盖",
@@ -280,6 +433,17 @@ func GetLibrary() []ConstraintDefinition {
 				{Name: "weight", Type: "int", Description: "优化权重", Default: "70", Min: "0", Max: "100"},
 			},
 		},
+		{
+			Name:        "team_cohesion",
+			DisplayName: "班组同班次",
+			Type:        "hard",
+			Category:    "协作",
+			Description: "cohesion 为 required 的班组，同一天上班的成员必须安排在同一班次。班组通过排班请求的 teams 或组织班组接口配置。",
+			Scenarios:   []string{"factory"},
+			Params: []ConstraintParam{
+				{Name: "weight", Type: "int", Description: "优化权重", Default: "100", Min: "0", Max: "100"},
+			},
+		},
 
 		// =====================================================
 		// 家政服务特有约束
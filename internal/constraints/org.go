@@ -0,0 +1,139 @@
+package constraints
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// WeightParam 返回约束权重对应的配置键（如 workload_balance → workload_balance_weight），没有时返回 false
+func WeightParam(name string) (string, bool) {
+	for param, owner := range paramOwners {
+		if owner == name && strings.HasSuffix(param, "_weight") {
+			return param, true
+		}
+	}
+	return "", false
+}
+
+// ValidateOrgConstraint 校验组织级约束配置，并补充类别和权重的默认值
+// 约束须为约束库中的约束、约束配置键所属的约束或已注册的插件约束；内置约束的参数须属于该约束，
+// 插件约束的参数交由插件工厂校验。类别默认取约束库定义（插件取约束实例），权重默认取约束库的 weight 参数默认值
+func ValidateOrgConstraint(c *model.OrgConstraint, library []ConstraintDefinition) error {
+	if c.Name == "" {
+		return fmt.Errorf("约束名称不能为空")
+	}
+
+	var def *ConstraintDefinition
+	for i := range library {
+		if library[i].Name == c.Name {
+			def = &library[i]
+			break
+		}
+	}
+
+	if factory, ok := constraint.LookupFactory(c.Name); ok {
+		instance, err := factory(pluginParams(c))
+		if err != nil {
+			return fmt.Errorf("约束 %s 的参数无效: %w", c.Name, err)
+		}
+		if c.Category == "" && instance != nil {
+			c.Category = string(instance.Category())
+		}
+	} else {
+		if def == nil && !isOwner(c.Name) {
+			return fmt.Errorf("未知约束: %s", c.Name)
+		}
+		for param := range c.Params {
+			if Owner(param) != c.Name {
+				return fmt.Errorf("参数 %s 不属于约束 %s", param, c.Name)
+			}
+		}
+	}
+
+	if c.Category == "" {
+		c.Category = string(constraint.CategorySoft)
+		if def != nil && def.Type != "" {
+			c.Category = def.Type
+		}
+	}
+	if c.Category != string(constraint.CategoryHard) && c.Category != string(constraint.CategorySoft) {
+		return fmt.Errorf("约束类别应为 hard 或 soft: %s", c.Category)
+	}
+
+	if c.Weight == 0 {
+		c.Weight = defaultWeight(c.Category, def)
+	}
+	if c.Weight < 1 || c.Weight > 100 {
+		return fmt.Errorf("约束权重应在 1-100 之间: %d", c.Weight)
+	}
+	return nil
+}
+
+// OrgConfig 将组织已启用的约束配置合并为排班请求的 constraints 格式
+// 内置约束的参数按键合并，权重写入约束的权重配置键（参数中已给出时以参数为准）；
+// 插件约束以 {参数..., "weight": 权重} 作为插件名称键的值
+func OrgConfig(list []*model.OrgConstraint) map[string]interface{} {
+	config := make(map[string]interface{})
+	for _, c := range list {
+		if !c.Enabled {
+			continue
+		}
+		if _, ok := constraint.LookupFactory(c.Name); ok {
+			config[c.Name] = pluginParams(c)
+			continue
+		}
+		for k, v := range c.Params {
+			config[k] = v
+		}
+		if key, ok := WeightParam(c.Name); ok {
+			if _, set := c.Params[key]; !set {
+				config[key] = c.Weight
+			}
+		}
+	}
+	return config
+}
+
+// pluginParams 插件约束的参数（含权重）
+func pluginParams(c *model.OrgConstraint) map[string]interface{} {
+	params := make(map[string]interface{}, len(c.Params)+1)
+	for k, v := range c.Params {
+		params[k] = v
+	}
+	if _, ok := params["weight"]; !ok && c.Weight > 0 {
+		params["weight"] = c.Weight
+	}
+	return params
+}
+
+// isOwner 名称是否为某个约束配置键所属的约束
+func isOwner(name string) bool {
+	for _, owner := range paramOwners {
+		if owner == name {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultWeight 约束的默认权重：约束库 weight 参数的默认值，没有时硬约束为 100、软约束为 50
+func defaultWeight(category string, def *ConstraintDefinition) int {
+	if def != nil {
+		for _, p := range def.Params {
+			if p.Name != "weight" {
+				continue
+			}
+			if w, err := strconv.Atoi(p.Default); err == nil && w > 0 {
+				return w
+			}
+		}
+	}
+	if category == string(constraint.CategoryHard) {
+		return 100
+	}
+	return 50
+}
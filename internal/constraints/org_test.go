@@ -0,0 +1,47 @@
+package constraints
+
+import (
+	"testing"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestOrgConfig(t *testing.T) {
+	balance := &model.OrgConstraint{Name: "workload_balance", Params: model.JSONMap{}, Enabled: true}
+	if err := ValidateOrgConstraint(balance, GetLibrary()); err != nil {
+		t.Fatalf("ValidateOrgConstraint: %v", err)
+	}
+	if balance.Category != "soft" || balance.Weight == 0 {
+		t.Errorf("应补充默认类别和权重: %+v", balance)
+	}
+	balance.Weight = 70
+
+	hours := &model.OrgConstraint{Name: "max_hours_per_week", Params: model.JSONMap{"max_hours_per_week": 40}, Enabled: true}
+	disabled := &model.OrgConstraint{Name: "max_hours_per_day", Params: model.JSONMap{"max_hours_per_day": 8}}
+	for _, c := range []*model.OrgConstraint{hours, disabled} {
+		if err := ValidateOrgConstraint(c, GetLibrary()); err != nil {
+			t.Fatalf("ValidateOrgConstraint(%s): %v", c.Name, err)
+		}
+	}
+
+	config := OrgConfig([]*model.OrgConstraint{balance, hours, disabled})
+	if config["workload_balance_weight"] != 70 || config["max_hours_per_week"] != 40 {
+		t.Errorf("config = %v", config)
+	}
+	if _, ok := config["max_hours_per_day"]; ok {
+		t.Errorf("停用的约束不应合并: %v", config)
+	}
+
+	invalid := []*model.OrgConstraint{
+		{Name: ""},
+		{Name: "no_such_rule"},
+		{Name: "max_hours_per_week", Params: model.JSONMap{"max_hours_per_day": 8}},
+		{Name: "workload_balance", Weight: 101},
+		{Name: "workload_balance", Category: "medium"},
+	}
+	for _, c := range invalid {
+		if err := ValidateOrgConstraint(c, GetLibrary()); err == nil {
+			t.Errorf("应校验失败: %+v", c)
+		}
+	}
+}
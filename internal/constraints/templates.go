@@ -0,0 +1,97 @@
+package constraints
+
+// TemplateRule 模板中的约束规则
+type TemplateRule struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`        // hard/soft
+	Category    string `json:"category"`    // 约束类别
+	Description string `json:"description"` // 约束描述
+	Default     string `json:"default"`     // 默认值
+}
+
+// Template 场景约束模板
+type Template struct {
+	Scenario    string         `json:"scenario"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Constraints []TemplateRule `json:"constraints"` // 约束规则列表
+}
+
+// TemplatesResponse 约束模板响应
+type TemplatesResponse struct {
+	Templates []Template `json:"templates"`
+}
+
+// GetTemplates 获取内置的场景约束模板
+func GetTemplates() []Template {
+	// 通用硬约束
+	commonHard := []TemplateRule{
+		{Name: "max_hours_per_day", Type: "hard", Category: "工时限制", Description: "每日最大工时", Default: "10小时"},
+		{Name: "max_hours_per_week", Type: "hard", Category: "工时限制", Description: "每周最大工时", Default: "44小时"},
+		{Name: "min_rest_between_shifts", Type: "hard", Category: "休息保障", Description: "班次间最小休息时间", Default: "11小时"},
+		{Name: "max_consecutive_days", Type: "hard", Category: "休息保障", Description: "最大连续工作天数", Default: "6天"},
+		{Name: "skill_required", Type: "hard", Category: "资质要求", Description: "技能与岗位匹配", Default: "必须满足"},
+	}
+
+	// 通用软约束
+	commonSoft := []TemplateRule{
+		{Name: "workload_balance", Type: "soft", Category: "公平性", Description: "工作量均衡", Default: "权重60"},
+		{Name: "employee_preference", Type: "soft", Category: "偏好", Description: "员工偏好考虑", Default: "权重50"},
+		{Name: "minimize_overtime", Type: "soft", Category: "成本优化", Description: "减少加班", Default: "权重70"},
+		{Name: "fatigue", Type: "soft", Category: "休息保障", Description: "疲劳指数", Default: "权重40，阈值60"},
+	}
+
+	// rules 拼接通用硬约束、场景约束和通用软约束
+	rules := func(scenario ...TemplateRule) []TemplateRule {
+		result := make([]TemplateRule, 0, len(commonHard)+len(scenario)+len(commonSoft))
+		result = append(result, commonHard...)
+		result = append(result, scenario...)
+		return append(result, commonSoft...)
+	}
+
+	return []Template{
+		{
+			Scenario:    "restaurant",
+			Name:        "餐饮门店标准模板",
+			Description: "适用于餐饮门店的标准约束配置，包含高峰期人员配置、工时限制等",
+			Constraints: rules(
+				TemplateRule{Name: "industry_certification", Type: "hard", Category: "资质要求", Description: "健康证等行业资质", Default: "必须持有"},
+				TemplateRule{Name: "peak_hours_coverage", Type: "soft", Category: "服务保障", Description: "高峰期人员覆盖", Default: "11:00-13:00, 17:00-20:00 最少3人"},
+				TemplateRule{Name: "split_shift", Type: "soft", Category: "排班模式", Description: "两头班支持", Default: "每周最多2次"},
+				TemplateRule{Name: "clopening", Type: "hard", Category: "休息保障", Description: "晚关早开限制", Default: "22:00后下班次日10:00前不上班"},
+				TemplateRule{Name: "store_opening_hours", Type: "hard", Category: "时间限制", Description: "班次落在门店营业时间内", Default: "配置营业时间后生效"},
+			),
+		},
+		{
+			Scenario:    "factory",
+			Name:        "工厂三班倒模板",
+			Description: "适用于工厂三班倒的约束配置，包含倒班规则、产线覆盖等",
+			Constraints: rules(
+				TemplateRule{Name: "shift_rotation", Type: "hard", Category: "排班模式", Description: "倒班轮换规则", Default: "早-中-晚轮换"},
+				TemplateRule{Name: "production_line_coverage", Type: "hard", Category: "服务保障", Description: "产线24小时覆盖", Default: "必须满足"},
+				TemplateRule{Name: "handover_overlap", Type: "soft", Category: "交接", Description: "交接班重叠时间", Default: "15分钟"},
+				TemplateRule{Name: "post_night_rest", Type: "hard", Category: "休息保障", Description: "连续夜班后休息", Default: "连续3个夜班后休息2天"},
+			),
+		},
+		{
+			Scenario:    "housekeeping",
+			Name:        "家政服务模板",
+			Description: "适用于家政服务的约束配置，包含服务区域、路程时间等",
+			Constraints: rules(
+				TemplateRule{Name: "service_area", Type: "hard", Category: "区域限制", Description: "服务区域匹配", Default: "必须在服务范围内"},
+				TemplateRule{Name: "travel_time", Type: "soft", Category: "效率优化", Description: "路程时间考虑", Default: "尽量减少"},
+				TemplateRule{Name: "time_window", Type: "hard", Category: "服务保障", Description: "服务时间窗口", Default: "必须在客户指定时段"},
+			),
+		},
+		{
+			Scenario:    "nursing",
+			Name:        "长护险服务模板",
+			Description: "适用于长期护理保险服务的约束配置，包含护理计划、资质等级等",
+			Constraints: rules(
+				TemplateRule{Name: "nursing_qualification", Type: "hard", Category: "资质要求", Description: "护理资质等级", Default: "必须持有护理证"},
+				TemplateRule{Name: "service_continuity", Type: "soft", Category: "服务质量", Description: "服务连续性", Default: "优先安排熟悉的护理员"},
+				TemplateRule{Name: "max_patients_per_day", Type: "hard", Category: "服务质量", Description: "每日最大服务患者数", Default: "4人"},
+			),
+		},
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/paiban/paiban/internal/config"
+	"github.com/paiban/paiban/internal/metrics"
+	"github.com/paiban/paiban/pkg/chaos"
 	"github.com/paiban/paiban/pkg/logger"
 
 	_ "github.com/lib/pq" // PostgreSQL 驱动
@@ -65,6 +67,8 @@ func (db *DB) Health(ctx context.Context) error {
 
 // Transaction 执行事务
 func (db *DB) Transaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	chaos.Delay(chaos.StoreLatency, "transaction")
+
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("开始事务失败: %w", err)
@@ -96,6 +100,21 @@ func (db *DB) Stats() sql.DBStats {
 	return db.DB.Stats()
 }
 
+// ReportStats 定期将连接池的连接数写入 paiban_db_connections 指标，直到 ctx 取消
+func (db *DB) ReportStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		stats := db.Stats()
+		metrics.SetDBConnections(stats.OpenConnections, stats.InUse, stats.Idle)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // Exec 执行SQL语句
 func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	start := time.Now()
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paiban/paiban/pkg/logger"
+)
+
+// migrationLockID 执行迁移时持有的会话级 advisory lock，避免多个实例同时迁移
+const migrationLockID = 70120001
+
+// Migration 数据库迁移
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+}
+
+// LoadMigrations 读取目录中的 {版本}_{名称}.up.sql 迁移脚本，按版本排序
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]Migration, 0, len(files))
+	seen := make(map[int]string, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".up.sql")
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("迁移文件名缺少版本号: %s", file)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("迁移版本 %d 重复: %s, %s", version, other, name)
+		}
+		seen[version] = name
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移文件失败: %w", err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, Up: string(data)})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Pending 返回未执行的迁移
+func Pending(migrations []Migration, applied map[int]bool) []Migration {
+	var pending []Migration
+	for _, m := range migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// Migrate 执行未执行的迁移，每个迁移在单独的事务中执行并记录到 schema_migrations，返回本次执行的迁移
+func (db *DB) Migrate(ctx context.Context, fsys fs.FS) ([]Migration, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	// advisory lock 属于会话，加锁、迁移和解锁须在同一连接上
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库连接失败: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return nil, fmt.Errorf("获取迁移锁失败: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(200) NOT NULL,
+		applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+	)`); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := Pending(migrations, applied)
+	for _, m := range pending {
+		start := time.Now()
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return nil, fmt.Errorf("执行迁移 %s 失败: %w", m.Name, err)
+		}
+		logger.Info().Str("migration", m.Name).Dur("duration", time.Since(start)).Msg("数据库迁移已执行")
+	}
+	return pending, nil
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("查询迁移记录失败: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+func applyMigration(ctx context.Context, conn *sql.Conn, m Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
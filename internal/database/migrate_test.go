@@ -0,0 +1,78 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/paiban/paiban/migrations"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_later.up.sql":    {Data: []byte("SELECT 10;")},
+		"002_second.up.sql":   {Data: []byte("SELECT 2;")},
+		"002_second.down.sql": {Data: []byte("SELECT -2;")},
+		"001_init.up.sql":     {Data: []byte("SELECT 1;")},
+		"README.md":           {Data: []byte("说明")},
+	}
+	got, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	var names []string
+	for _, m := range got {
+		names = append(names, m.Name)
+	}
+	if strings.Join(names, ",") != "001_init,002_second,010_later" {
+		t.Errorf("迁移应按版本排序且只包含 up 脚本: %v", names)
+	}
+	if got[1].Version != 2 || got[1].Up != "SELECT 2;" {
+		t.Errorf("migration = %+v", got[1])
+	}
+
+	pending := Pending(got, map[int]bool{1: true, 10: true})
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Errorf("pending = %+v", pending)
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"缺少版本号": {"init.up.sql": {Data: []byte("SELECT 1;")}},
+		"版本重复": {
+			"001_a.up.sql": {Data: []byte("SELECT 1;")},
+			"1_b.up.sql":   {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range tests {
+		if _, err := LoadMigrations(fsys); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
+
+// TestEmbeddedMigrations 内嵌的迁移脚本包含仓储使用的表
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(got) == 0 || got[0].Version != 1 {
+		t.Fatalf("migrations = %d", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Version != got[i-1].Version+1 {
+			t.Errorf("迁移版本不连续: %s 之后为 %s", got[i-1].Name, got[i].Name)
+		}
+	}
+	var all strings.Builder
+	for _, m := range got {
+		all.WriteString(m.Up)
+	}
+	for _, table := range []string{"schedules", "shifts", "employees", "assignments", "schedule_assignments", "constraints", "scenario_templates"} {
+		if !strings.Contains(all.String(), "CREATE TABLE IF NOT EXISTS "+table+" (") {
+			t.Errorf("迁移脚本缺少表 %s", table)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package docalert 提供员工证件到期提醒
+// 汇总证书材料（健康证、签证、工作许可等）的有效期和劳动合同到期日，按组织为各类证件配置的提前天数
+// 生成到期提醒，并标出被排在证件到期之后上班的员工；每日简报将当天的提醒推送给管理者，留出续期时间
+package docalert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ErrInvalidDate 日期格式错误
+var ErrInvalidDate = errors.New("日期格式应为 YYYY-MM-DD")
+
+// Service 证件到期提醒服务
+type Service struct {
+	store    *memstore.Store
+	notifier notify.Notifier
+	now      func() time.Time
+
+	mu        sync.Mutex
+	delivered map[uuid.UUID]string // 组织ID -> 最近推送简报的日期
+}
+
+// NewService 创建证件到期提醒服务
+func NewService(store *memstore.Store, notifier notify.Notifier) *Service {
+	if notifier == nil {
+		notifier = notify.LogNotifier{}
+	}
+	return &Service{
+		store:     store,
+		notifier:  notifier,
+		now:       time.Now,
+		delivered: make(map[uuid.UUID]string),
+	}
+}
+
+// Alerts 返回组织在 date 当天的证件到期提醒（按到期日期、员工排序）
+// 在职员工每类证件取有效期最晚的一份已核验证书材料，合同取 valid_to；
+// 证件在提前提醒期内、已过期，或 date 起有排在到期之后的分配时生成提醒
+func (s *Service) Alerts(orgID uuid.UUID, date string) ([]*model.DocumentAlert, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+
+	var policy *model.DocumentAlertPolicy
+	if org, err := s.store.GetOrganization(orgID); err == nil {
+		policy = org.DocumentAlertPolicy
+	}
+	scheduled := s.scheduledDates(orgID, date)
+
+	// 员工ID/证件类型 -> 有效期最晚的证书材料
+	latest := make(map[string]*model.CertificationDocument)
+	for _, d := range s.store.ListCertificationDocuments(orgID, uuid.Nil, model.CertificationVerified) {
+		key := d.EmployeeID.String() + "/" + d.Certification
+		if cur, ok := latest[key]; !ok || later(d.ExpiresAt, cur.ExpiresAt) {
+			latest[key] = d
+		}
+	}
+
+	alerts := make([]*model.DocumentAlert, 0)
+	for _, emp := range s.store.ListEmployees(orgID) {
+		if emp.Status != "" && !emp.IsActive() {
+			continue
+		}
+		var docs []*model.DocumentAlert
+		for _, d := range latest {
+			if d.EmployeeID != emp.ID || d.ExpiresAt == "" {
+				continue
+			}
+			id := d.ID
+			docs = append(docs, &model.DocumentAlert{DocumentType: d.Certification, DocumentID: &id, ExpiresAt: d.ExpiresAt})
+		}
+		if emp.Contract != nil && emp.Contract.ValidTo != "" {
+			docs = append(docs, &model.DocumentAlert{DocumentType: model.DocumentTypeContract, ExpiresAt: emp.Contract.ValidTo})
+		}
+
+		for _, a := range docs {
+			expires, err := time.Parse("2006-01-02", a.ExpiresAt)
+			if err != nil {
+				continue
+			}
+			a.EmployeeID, a.EmployeeName = emp.ID, emp.Name
+			a.DaysLeft = int(expires.Sub(day).Hours() / 24)
+			a.LeadDays = policy.Lead(a.DocumentType)
+			for _, d := range scheduled[emp.ID] {
+				if d > a.ExpiresAt {
+					if a.ScheduledBeyond == 0 {
+						a.FirstScheduledDate = d
+					}
+					a.ScheduledBeyond++
+				}
+			}
+			if a.DaysLeft > a.LeadDays && a.ScheduledBeyond == 0 {
+				continue
+			}
+			a.Level = model.DocumentAlertExpiring
+			if a.DaysLeft < 0 {
+				a.Level = model.DocumentAlertExpired
+			}
+			alerts = append(alerts, a)
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].ExpiresAt != alerts[j].ExpiresAt {
+			return alerts[i].ExpiresAt < alerts[j].ExpiresAt
+		}
+		if alerts[i].EmployeeName != alerts[j].EmployeeName {
+			return alerts[i].EmployeeName < alerts[j].EmployeeName
+		}
+		return alerts[i].DocumentType < alerts[j].DocumentType
+	})
+	return alerts, nil
+}
+
+// Deliver 向有证件到期提醒的组织推送 date 当天的简报，同一组织同一天只推送一次，返回推送数量
+func (s *Service) Deliver(ctx context.Context, date string) (int, error) {
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return 0, ErrInvalidDate
+	}
+
+	orgs := make(map[uuid.UUID]bool)
+	for _, emp := range s.store.ListEmployees(uuid.Nil) {
+		orgs[emp.OrgID] = true
+	}
+
+	count := 0
+	for orgID := range orgs {
+		s.mu.Lock()
+		done := s.delivered[orgID] == date
+		s.mu.Unlock()
+		if done {
+			continue
+		}
+
+		alerts, err := s.Alerts(orgID, date)
+		if err != nil {
+			return count, err
+		}
+		if len(alerts) > 0 {
+			expired, beyond := 0, 0
+			for _, a := range alerts {
+				if a.Level == model.DocumentAlertExpired {
+					expired++
+				}
+				if a.ScheduledBeyond > 0 {
+					beyond++
+				}
+			}
+			n := notify.New(notify.TypeDocumentExpiry, orgID,
+				fmt.Sprintf("%s 证件到期提醒", date),
+				fmt.Sprintf("%d 项证件即将到期或已过期（已过期 %d 项），其中 %d 项在到期后仍有排班", len(alerts), expired, beyond),
+				alerts)
+			if err := s.notifier.Notify(ctx, n); err != nil {
+				logger.Error().Err(err).Str("org_id", orgID.String()).Str("date", date).Msg("推送证件到期提醒失败")
+				continue
+			}
+			count++
+		}
+
+		s.mu.Lock()
+		s.delivered[orgID] = date
+		s.mu.Unlock()
+	}
+	return count, nil
+}
+
+// Run 定期推送当天的证件到期简报，直到 ctx 取消
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			date := s.now().Format("2006-01-02")
+			if n, err := s.Deliver(ctx, date); err != nil {
+				logger.Error().Err(err).Str("date", date).Msg("推送证件到期提醒失败")
+			} else if n > 0 {
+				logger.Info().Int("count", n).Str("date", date).Msg("证件到期提醒已推送")
+			}
+		}
+	}
+}
+
+// scheduledDates 返回组织 from 起（含）草稿和已发布排班中各员工的分配日期（升序，不含已取消的分配）
+func (s *Service) scheduledDates(orgID uuid.UUID, from string) map[uuid.UUID][]string {
+	seen := make(map[uuid.UUID]bool)
+	result := make(map[uuid.UUID][]string)
+	for _, schedule := range s.store.ListSchedules(orgID) {
+		if schedule.Status == "archived" {
+			continue
+		}
+		for _, a := range schedule.Assignments {
+			if seen[a.ID] || a.Status == "cancelled" || a.Date < from {
+				continue
+			}
+			seen[a.ID] = true
+			result[a.EmployeeID] = append(result[a.EmployeeID], a.Date)
+		}
+	}
+	for _, dates := range result {
+		sort.Strings(dates)
+	}
+	return result
+}
+
+// later 比较有效期，空（长期有效）视为最晚
+func later(a, b string) bool {
+	if a == "" || b == "" {
+		return a == "" && b != ""
+	}
+	return a > b
+}
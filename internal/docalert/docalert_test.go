@@ -0,0 +1,111 @@
+package docalert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+type captureNotifier struct {
+	sent []*notify.Notification
+}
+
+func (c *captureNotifier) Notify(ctx context.Context, n *notify.Notification) error {
+	c.sent = append(c.sent, n)
+	return nil
+}
+
+// newTestStore 张三的健康证 3 月 20 日到期（有续期前的旧证和待审核的新证），3 月 25 日仍有排班；
+// 李四的签证 4 月 30 日到期、合同 3 月 10 日已到期
+func newTestStore(t *testing.T) (*memstore.Store, uuid.UUID, *model.Employee, *model.Employee) {
+	t.Helper()
+	store := memstore.New("")
+	orgID := uuid.New()
+	zhang := &model.Employee{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "张三", Status: "active"}
+	li := &model.Employee{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "李四", Status: "active",
+		Contract: &model.EmployeeContract{ValidTo: "2026-03-10"}}
+	store.PutEmployee(zhang)
+	store.PutEmployee(li)
+
+	for _, d := range []*model.CertificationDocument{
+		{EmployeeID: zhang.ID, Certification: "健康证", ExpiresAt: "2025-03-20", Status: model.CertificationVerified},
+		{EmployeeID: zhang.ID, Certification: "健康证", ExpiresAt: "2026-03-20", Status: model.CertificationVerified},
+		{EmployeeID: zhang.ID, Certification: "健康证", ExpiresAt: "2027-03-20", Status: model.CertificationPending},
+		{EmployeeID: li.ID, Certification: "签证", ExpiresAt: "2026-04-30", Status: model.CertificationVerified},
+	} {
+		d.ID, d.OrgID, d.FileRef = uuid.New(), orgID, "oss://doc"
+		store.PutCertificationDocument(d)
+	}
+
+	schedule := &model.Schedule{BaseModel: model.NewBaseModel(), OrgID: orgID, Status: "draft"}
+	for _, date := range []string{"2026-03-18", "2026-03-25", "2026-03-26"} {
+		schedule.Assignments = append(schedule.Assignments, model.Assignment{
+			BaseModel:  model.NewBaseModel(),
+			EmployeeID: zhang.ID,
+			Date:       date,
+		})
+	}
+	store.PutSchedule(schedule)
+	return store, orgID, zhang, li
+}
+
+func TestService_Alerts(t *testing.T) {
+	store, orgID, zhang, li := newTestStore(t)
+	s := NewService(store, nil)
+
+	alerts, err := s.Alerts(orgID, "2026-03-15")
+	if err != nil {
+		t.Fatalf("Alerts() error = %v", err)
+	}
+	// 签证 46 天后到期，超过默认 30 天不提醒
+	if len(alerts) != 2 {
+		t.Fatalf("len(alerts) = %d, want 2: %+v", len(alerts), alerts)
+	}
+	if a := alerts[0]; a.EmployeeID != li.ID || a.DocumentType != model.DocumentTypeContract || a.Level != model.DocumentAlertExpired || a.DaysLeft != -5 {
+		t.Errorf("合同提醒 = %+v", a)
+	}
+	if a := alerts[1]; a.EmployeeID != zhang.ID || a.DaysLeft != 5 || a.Level != model.DocumentAlertExpiring ||
+		a.ScheduledBeyond != 2 || a.FirstScheduledDate != "2026-03-25" {
+		t.Errorf("健康证提醒 = %+v", a)
+	}
+
+	// 签证提前 60 天提醒，健康证提前 3 天（仍因到期后有排班而提醒）
+	store.PutOrganization(&model.Organization{
+		BaseModel:           model.BaseModel{ID: orgID},
+		DocumentAlertPolicy: &model.DocumentAlertPolicy{LeadDays: map[string]int{"签证": 60, "健康证": 3}},
+	})
+	alerts, _ = s.Alerts(orgID, "2026-03-15")
+	if len(alerts) != 3 || alerts[2].DocumentType != "签证" || alerts[2].LeadDays != 60 || alerts[1].LeadDays != 3 {
+		t.Errorf("alerts = %+v", alerts)
+	}
+
+	if _, err := s.Alerts(orgID, "2026/03/15"); err != ErrInvalidDate {
+		t.Errorf("无效日期应报错, got %v", err)
+	}
+}
+
+func TestService_DeliverOncePerDay(t *testing.T) {
+	store, _, _, _ := newTestStore(t)
+	notifier := &captureNotifier{}
+	s := NewService(store, notifier)
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Deliver(context.Background(), "2026-03-15"); err != nil {
+			t.Fatalf("Deliver() error = %v", err)
+		}
+	}
+	if len(notifier.sent) != 1 {
+		t.Fatalf("同一天应只推送一次, sent = %d", len(notifier.sent))
+	}
+	n := notifier.sent[0]
+	if n.Type != notify.TypeDocumentExpiry || n.RecipientRole != notify.RecipientManager {
+		t.Errorf("notification = %+v", n)
+	}
+	if alerts, ok := n.Data.([]*model.DocumentAlert); !ok || len(alerts) != 2 {
+		t.Errorf("notification data = %+v", n.Data)
+	}
+}
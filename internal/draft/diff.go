@@ -0,0 +1,151 @@
+package draft
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ChangedAssignment 两个版本间被修改的分配
+type ChangedAssignment struct {
+	Before model.Assignment `json:"before"`
+	After  model.Assignment `json:"after"`
+	Fields []string         `json:"fields"` // 变化的字段：employee_id/shift_id/date/start_time/end_time/position/store_id/status/notes
+}
+
+// AssignmentDiff 两个版本分配的差异
+type AssignmentDiff struct {
+	Added     []model.Assignment  `json:"added"`
+	Removed   []model.Assignment  `json:"removed"`
+	Changed   []ChangedAssignment `json:"changed"`
+	Unchanged int                 `json:"unchanged"`
+}
+
+// DiffAssignments 对比两个版本的分配
+// 先按分配ID对应；重新生成后分配ID会变化，其余分配内容完全相同的视为未变，
+// 同一班次、日期、岗位和门店的视为修改（如更换员工），剩下的为新增或删除
+func DiffAssignments(from, to []model.Assignment) AssignmentDiff {
+	diff := AssignmentDiff{
+		Added:   make([]model.Assignment, 0),
+		Removed: make([]model.Assignment, 0),
+		Changed: make([]ChangedAssignment, 0),
+	}
+	compare := func(before, after model.Assignment) {
+		if fields := changedFields(before, after); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, ChangedAssignment{Before: before, After: after, Fields: fields})
+		} else {
+			diff.Unchanged++
+		}
+	}
+
+	toByID := make(map[uuid.UUID]int, len(to))
+	for i, a := range to {
+		toByID[a.ID] = i
+	}
+	matched := make([]bool, len(to))
+	var restFrom []model.Assignment
+	for _, a := range from {
+		if i, ok := toByID[a.ID]; ok && !matched[i] {
+			matched[i] = true
+			compare(a, to[i])
+			continue
+		}
+		restFrom = append(restFrom, a)
+	}
+
+	// 按内容和按班次位置依次对应未按ID对应上的分配
+	for _, key := range []func(model.Assignment) string{contentKey, slotKey} {
+		pending := make(map[string][]int)
+		for i, a := range to {
+			if !matched[i] {
+				pending[key(a)] = append(pending[key(a)], i)
+			}
+		}
+		var unmatched []model.Assignment
+		for _, a := range restFrom {
+			k := key(a)
+			if len(pending[k]) == 0 {
+				unmatched = append(unmatched, a)
+				continue
+			}
+			i := pending[k][0]
+			pending[k] = pending[k][1:]
+			matched[i] = true
+			compare(a, to[i])
+		}
+		restFrom = unmatched
+	}
+
+	diff.Removed = append(diff.Removed, restFrom...)
+	for i, a := range to {
+		if !matched[i] {
+			diff.Added = append(diff.Added, a)
+		}
+	}
+	sortAssignments(diff.Added)
+	sortAssignments(diff.Removed)
+	sort.SliceStable(diff.Changed, func(i, j int) bool {
+		return assignmentLess(diff.Changed[i].After, diff.Changed[j].After)
+	})
+	return diff
+}
+
+// changedFields 返回两个分配内容不同的字段（不比较ID和时间戳）
+func changedFields(a, b model.Assignment) []string {
+	var fields []string
+	if a.EmployeeID != b.EmployeeID {
+		fields = append(fields, "employee_id")
+	}
+	if a.ShiftID != b.ShiftID {
+		fields = append(fields, "shift_id")
+	}
+	if a.Date != b.Date {
+		fields = append(fields, "date")
+	}
+	if !a.StartTime.Equal(b.StartTime) {
+		fields = append(fields, "start_time")
+	}
+	if !a.EndTime.Equal(b.EndTime) {
+		fields = append(fields, "end_time")
+	}
+	if a.Position != b.Position {
+		fields = append(fields, "position")
+	}
+	if a.StoreID != b.StoreID {
+		fields = append(fields, "store_id")
+	}
+	if a.Status != b.Status {
+		fields = append(fields, "status")
+	}
+	if a.Notes != b.Notes {
+		fields = append(fields, "notes")
+	}
+	return fields
+}
+
+// contentKey 分配内容（员工、班次、日期、时间、岗位、门店）
+func contentKey(a model.Assignment) string {
+	return a.EmployeeID.String() + "|" + slotKey(a) + "|" + a.StartTime.Format("2006-01-02T15:04") + "|" + a.EndTime.Format("2006-01-02T15:04")
+}
+
+// slotKey 分配所在的班次位置（班次、日期、岗位、门店）
+func slotKey(a model.Assignment) string {
+	return a.ShiftID.String() + "|" + a.Date + "|" + a.Position + "|" + a.StoreID
+}
+
+// sortAssignments 按日期、开始时间、员工排序
+func sortAssignments(list []model.Assignment) {
+	sort.SliceStable(list, func(i, j int) bool { return assignmentLess(list[i], list[j]) })
+}
+
+// assignmentLess 分配的排序：日期、开始时间、员工
+func assignmentLess(a, b model.Assignment) bool {
+	if a.Date != b.Date {
+		return a.Date < b.Date
+	}
+	if !a.StartTime.Equal(b.StartTime) {
+		return a.StartTime.Before(b.StartTime)
+	}
+	return a.EmployeeID.String() < b.EmployeeID.String()
+}
@@ -0,0 +1,52 @@
+package draft
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestDiffAssignments(t *testing.T) {
+	shift := uuid.New()
+	zhang, li, wang := uuid.New(), uuid.New(), uuid.New()
+	assignment := func(employee uuid.UUID, date string) model.Assignment {
+		return model.Assignment{BaseModel: model.NewBaseModel(), EmployeeID: employee, ShiftID: shift, Date: date, Status: "scheduled"}
+	}
+	kept := assignment(zhang, "2026-01-19")
+	edited := assignment(li, "2026-01-19")
+	removed := assignment(wang, "2026-01-21")
+	from := []model.Assignment{kept, edited, removed, assignment(zhang, "2026-01-20"), assignment(li, "2026-01-20")}
+
+	editedAfter := edited
+	editedAfter.Notes = "提前到岗"
+	// 重新生成：ID 全部变化，张三 20 日不变，20 日另一个班次位置改由王五上班，22 日新增
+	regenerated := assignment(zhang, "2026-01-20")
+	reassigned := assignment(wang, "2026-01-20")
+	added := assignment(li, "2026-01-22")
+	to := []model.Assignment{added, kept, editedAfter, reassigned, regenerated}
+
+	diff := DiffAssignments(from, to)
+	if diff.Unchanged != 2 {
+		t.Errorf("unchanged = %d, expected 2", diff.Unchanged)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].ID != added.ID {
+		t.Errorf("added = %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].ID != removed.ID {
+		t.Errorf("removed = %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 2 {
+		t.Fatalf("changed = %+v", diff.Changed)
+	}
+	if c := diff.Changed[0]; c.After.ID != editedAfter.ID || len(c.Fields) != 1 || c.Fields[0] != "notes" {
+		t.Errorf("changed[0] = %+v", c)
+	}
+	if c := diff.Changed[1]; c.Before.EmployeeID != li || c.After.EmployeeID != wang || c.Fields[0] != "employee_id" {
+		t.Errorf("changed[1] = %+v", c)
+	}
+
+	if diff := DiffAssignments(from, from); diff.Unchanged != len(from) || len(diff.Added)+len(diff.Removed)+len(diff.Changed) != 0 {
+		t.Errorf("相同版本 diff = %+v", diff)
+	}
+}
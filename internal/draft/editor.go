@@ -0,0 +1,462 @@
+// Package draft 提供排班草稿的并发编辑
+// 使用排班版本号做乐观并发控制：修改必须基于最新版本，否则返回版本冲突；
+// 基于旧版本的修改可通过合并应用不冲突的部分，并列出需要人工处理的冲突
+package draft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 合并冲突原因
+const (
+	ConflictModified      = "modified"       // 同一分配已被他人修改
+	ConflictRemoved       = "removed"        // 同一分配已被他人删除
+	ConflictDoubleBooking = "double_booking" // 员工当天已有分配
+	ConflictNoHistory     = "no_history"     // 缺少基准版本之后的修订记录，无法判断
+	ConflictInvalid       = "invalid"        // 变更本身无效
+	ConflictLocked        = "locked"         // 分配日期所在工资期间已结账
+)
+
+// maxMergeRetries 合并时保存遇到并发写入的最大重试次数
+const maxMergeRetries = 3
+
+var (
+	// ErrNotDraft 仅草稿状态的排班可编辑
+	ErrNotDraft = errors.New("仅草稿状态的排班可编辑")
+	// ErrInvalidChange 无效的分配变更
+	ErrInvalidChange = errors.New("无效的分配变更")
+	// ErrPeriodClosed 分配日期所在工资期间已结账，只能通过调整流程修改
+	ErrPeriodClosed = errors.New("工资期间已结账，请通过工资调整修改")
+	// ErrNotRestorable 仅草稿和已发布的排班可回滚
+	ErrNotRestorable = errors.New("仅草稿和已发布的排班可回滚")
+	// ErrVersionNotFound 排班没有该版本的快照
+	ErrVersionNotFound = errors.New("排班版本不存在")
+)
+
+// MergeConflict 合并冲突
+type MergeConflict struct {
+	Change model.AssignmentChange  `json:"change"`           // 本次提交的变更
+	Reason string                  `json:"reason"`           // 冲突原因
+	Theirs *model.AssignmentChange `json:"theirs,omitempty"` // 他人在基准版本之后的变更
+	Detail string                  `json:"detail"`
+}
+
+// MergeResult 合并结果
+type MergeResult struct {
+	Schedule  *model.Schedule          `json:"schedule"`
+	Applied   []model.AssignmentChange `json:"applied"`
+	Conflicts []MergeConflict          `json:"conflicts"`
+}
+
+// Editor 排班草稿编辑器
+type Editor struct {
+	store    *memstore.Store
+	notifier notify.Notifier // 分配修改通知（可选）
+	now      func() time.Time
+}
+
+// NewEditor 创建排班草稿编辑器
+func NewEditor(store *memstore.Store) *Editor {
+	return &Editor{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// SetNotifier 设置通知发送器，设置后每次保存分配修改发送 assignment_changed 通知
+func (e *Editor) SetNotifier(notifier notify.Notifier) {
+	e.notifier = notifier
+}
+
+// Edit 基于 baseVersion 修改排班分配
+// 排班当前版本与 baseVersion 不一致时返回 memstore.ErrVersionConflict
+func (e *Editor) Edit(scheduleID uuid.UUID, baseVersion int, author string, changes []model.AssignmentChange) (*model.Schedule, error) {
+	schedule, err := e.store.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != "draft" {
+		return nil, ErrNotDraft
+	}
+	if schedule.Version != baseVersion {
+		return nil, memstore.ErrVersionConflict
+	}
+
+	lockedBefore := e.store.PayrollLockedBefore(schedule.OrgID)
+	for _, c := range changes {
+		if date, locked := LockedDate(schedule, c, lockedBefore); locked {
+			return nil, fmt.Errorf("%w: %s 早于结账日期 %s", ErrPeriodClosed, date, lockedBefore)
+		}
+	}
+
+	applied, err := ApplyChanges(schedule, changes)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.save(schedule, baseVersion, author, applied); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Merge 将基于 baseVersion 的修改合并到排班最新版本
+// 与基准版本之后他人修改不冲突的变更会被应用，冲突的变更列在结果中等待人工处理
+func (e *Editor) Merge(scheduleID uuid.UUID, baseVersion int, author string, changes []model.AssignmentChange) (*MergeResult, error) {
+	for attempt := 0; ; attempt++ {
+		result, current, err := e.merge(scheduleID, baseVersion, changes)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Applied) == 0 {
+			return result, nil
+		}
+		err = e.save(result.Schedule, current, author, result.Applied)
+		if err == nil {
+			return result, nil
+		}
+		if err != memstore.ErrVersionConflict || attempt >= maxMergeRetries {
+			return nil, err
+		}
+	}
+}
+
+// merge 计算合并结果，返回合并前的排班版本
+func (e *Editor) merge(scheduleID uuid.UUID, baseVersion int, changes []model.AssignmentChange) (*MergeResult, int, error) {
+	schedule, err := e.store.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if schedule.Status != "draft" {
+		return nil, 0, ErrNotDraft
+	}
+	if baseVersion > schedule.Version {
+		return nil, 0, fmt.Errorf("%w: 基准版本 %d 晚于当前版本 %d", ErrInvalidChange, baseVersion, schedule.Version)
+	}
+	current := schedule.Version
+	lockedBefore := e.store.PayrollLockedBefore(schedule.OrgID)
+
+	// 基准版本之后他人修改过的分配
+	revisions := e.store.ListScheduleRevisions(scheduleID, baseVersion)
+	historyComplete := len(revisions) == current-baseVersion
+	theirs := make(map[uuid.UUID]model.AssignmentChange)
+	for _, rev := range revisions {
+		for _, c := range rev.Changes {
+			theirs[changeTarget(c)] = c
+		}
+	}
+
+	result := &MergeResult{
+		Schedule:  schedule,
+		Applied:   make([]model.AssignmentChange, 0, len(changes)),
+		Conflicts: make([]MergeConflict, 0),
+	}
+	conflict := func(c model.AssignmentChange, reason, detail string, their *model.AssignmentChange) {
+		result.Conflicts = append(result.Conflicts, MergeConflict{Change: c, Reason: reason, Theirs: their, Detail: detail})
+	}
+
+	for _, c := range changes {
+		if date, locked := LockedDate(schedule, c, lockedBefore); locked {
+			conflict(c, ConflictLocked, fmt.Sprintf("%s 早于结账日期 %s", date, lockedBefore), nil)
+			continue
+		}
+		if c.Op != model.ChangeAdd {
+			if their, ok := theirs[c.AssignmentID]; ok {
+				if sameChange(c, their) {
+					continue // 他人已做了相同修改
+				}
+				reason := ConflictModified
+				if their.Op == model.ChangeRemove {
+					reason = ConflictRemoved
+				}
+				conflict(c, reason, "该分配在基准版本之后已被他人修改", &their)
+				continue
+			}
+			if !historyComplete && current != baseVersion {
+				conflict(c, ConflictNoHistory, "缺少基准版本之后的修订记录", nil)
+				continue
+			}
+		}
+
+		if c.Assignment != nil && c.Op != model.ChangeRemove {
+			if other := findBooking(schedule, c.Assignment, c.AssignmentID); other != nil {
+				conflict(c, ConflictDoubleBooking,
+					fmt.Sprintf("员工 %s 在 %s 已有分配 %s", other.EmployeeID, other.Date, other.ID), nil)
+				continue
+			}
+		}
+
+		applied, err := ApplyChanges(schedule, []model.AssignmentChange{c})
+		if err != nil {
+			conflict(c, ConflictInvalid, err.Error(), nil)
+			continue
+		}
+		result.Applied = append(result.Applied, applied...)
+	}
+	return result, current, nil
+}
+
+// PreviewRollback 试算将排班恢复到 version 版本的分配，不保存
+// 返回恢复后的排班和相对当前版本的分配变更
+func (e *Editor) PreviewRollback(scheduleID uuid.UUID, version int) (*model.Schedule, []model.AssignmentChange, error) {
+	schedule, err := e.store.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes, err := e.restore(schedule, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schedule, changes, nil
+}
+
+// Rollback 基于 baseVersion 将排班的分配恢复到 version 版本的快照，作为新版本保存
+// 草稿和已发布的排班都可回滚，状态不变；排班当前版本与 baseVersion 不一致时返回 memstore.ErrVersionConflict
+func (e *Editor) Rollback(scheduleID uuid.UUID, version, baseVersion int, author string) (*model.Schedule, []model.AssignmentChange, error) {
+	schedule, err := e.store.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if schedule.Version != baseVersion {
+		return nil, nil, memstore.ErrVersionConflict
+	}
+	changes, err := e.restore(schedule, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	revision := e.revision(schedule, baseVersion, author, changes)
+	revision.RestoredFrom = version
+	if err := e.commit(schedule, baseVersion, revision); err != nil {
+		return nil, nil, err
+	}
+	return schedule, changes, nil
+}
+
+// restore 将排班的分配替换为 version 版本的快照，返回按分配ID对应的变更
+// 变更涉及已结账期间时返回 ErrPeriodClosed
+func (e *Editor) restore(schedule *model.Schedule, version int) ([]model.AssignmentChange, error) {
+	if schedule.Status != "draft" && schedule.Status != "published" {
+		return nil, ErrNotRestorable
+	}
+	if version == schedule.Version {
+		return nil, fmt.Errorf("%w: 已是当前版本 %d", ErrInvalidChange, version)
+	}
+	snapshot, err := e.store.GetScheduleVersion(schedule.ID, version)
+	if err != nil {
+		return nil, ErrVersionNotFound
+	}
+
+	target := make(map[uuid.UUID]bool, len(snapshot.Assignments))
+	for _, a := range snapshot.Assignments {
+		target[a.ID] = true
+	}
+	changes := make([]model.AssignmentChange, 0)
+	for _, a := range schedule.Assignments {
+		if !target[a.ID] {
+			changes = append(changes, model.AssignmentChange{Op: model.ChangeRemove, AssignmentID: a.ID})
+		}
+	}
+	for i := range snapshot.Assignments {
+		snapshot.Assignments[i].ScheduleID = schedule.ID
+		a := snapshot.Assignments[i]
+		switch j := indexOf(schedule, a.ID); {
+		case j < 0:
+			changes = append(changes, model.AssignmentChange{Op: model.ChangeAdd, AssignmentID: a.ID, Assignment: &a})
+		case len(changedFields(schedule.Assignments[j], a)) > 0:
+			changes = append(changes, model.AssignmentChange{Op: model.ChangeUpdate, AssignmentID: a.ID, Assignment: &a})
+		}
+	}
+
+	lockedBefore := e.store.PayrollLockedBefore(schedule.OrgID)
+	for _, c := range changes {
+		if date, locked := LockedDate(schedule, c, lockedBefore); locked {
+			return nil, fmt.Errorf("%w: %s 早于结账日期 %s", ErrPeriodClosed, date, lockedBefore)
+		}
+	}
+
+	schedule.Assignments = snapshot.Assignments
+	if snapshot.Statistics != nil {
+		schedule.Statistics = snapshot.Statistics
+	}
+	return changes, nil
+}
+
+// save 递增版本并以 CAS 方式保存排班和修订记录
+func (e *Editor) save(schedule *model.Schedule, expectedVersion int, author string, changes []model.AssignmentChange) error {
+	return e.commit(schedule, expectedVersion, e.revision(schedule, expectedVersion, author, changes))
+}
+
+// revision 创建排班下一版本的修订记录
+func (e *Editor) revision(schedule *model.Schedule, expectedVersion int, author string, changes []model.AssignmentChange) *model.ScheduleRevision {
+	return &model.ScheduleRevision{
+		ScheduleID: schedule.ID,
+		Version:    expectedVersion + 1,
+		Changes:    changes,
+		Author:     author,
+		CreatedAt:  e.now(),
+	}
+}
+
+// commit 按修订记录递增版本，以 CAS 方式保存排班和修订记录并发送通知
+func (e *Editor) commit(schedule *model.Schedule, expectedVersion int, revision *model.ScheduleRevision) error {
+	schedule.Version = revision.Version
+	schedule.UpdatedAt = revision.CreatedAt
+	if err := e.store.CompareAndSwapSchedule(schedule, expectedVersion, revision); err != nil {
+		return err
+	}
+	e.notifyChanged(schedule, revision)
+	return nil
+}
+
+// notifyChanged 发送分配修改通知，失败只记录日志
+func (e *Editor) notifyChanged(schedule *model.Schedule, revision *model.ScheduleRevision) {
+	if e.notifier == nil {
+		return
+	}
+	n := notify.New(notify.TypeAssignmentChanged, schedule.OrgID, "排班分配已修改",
+		fmt.Sprintf("%s 至 %s 的排班修改了 %d 个分配（版本 %d）", schedule.StartDate, schedule.EndDate, len(revision.Changes), revision.Version),
+		map[string]interface{}{
+			"schedule_id": schedule.ID,
+			"status":      schedule.Status,
+			"version":     revision.Version,
+			"author":      revision.Author,
+			"changes":     revision.Changes,
+		})
+	if err := e.notifier.Notify(context.Background(), n); err != nil {
+		logger.Error().Err(err).Str("type", n.Type).Msg("发送分配修改通知失败")
+	}
+}
+
+// ApplyChanges 将分配变更应用到排班，返回实际应用的变更（新增分配会补全ID）
+// 任一变更无效时返回错误，排班保持部分修改状态，调用方应丢弃该排班
+func ApplyChanges(schedule *model.Schedule, changes []model.AssignmentChange) ([]model.AssignmentChange, error) {
+	applied := make([]model.AssignmentChange, 0, len(changes))
+	for _, c := range changes {
+		switch c.Op {
+		case model.ChangeAdd:
+			if c.Assignment == nil {
+				return nil, fmt.Errorf("%w: add 缺少 assignment", ErrInvalidChange)
+			}
+			a := *c.Assignment
+			if a.ID == uuid.Nil {
+				a.ID = uuid.New()
+			}
+			if indexOf(schedule, a.ID) >= 0 {
+				return nil, fmt.Errorf("%w: 分配 %s 已存在", ErrInvalidChange, a.ID)
+			}
+			a.ScheduleID = schedule.ID
+			a.OrgID = schedule.OrgID
+			if a.Status == "" {
+				a.Status = "scheduled"
+			}
+			schedule.Assignments = append(schedule.Assignments, a)
+			c.AssignmentID = a.ID
+			c.Assignment = &a
+
+		case model.ChangeUpdate:
+			if c.Assignment == nil {
+				return nil, fmt.Errorf("%w: update 缺少 assignment", ErrInvalidChange)
+			}
+			idx := indexOf(schedule, c.AssignmentID)
+			if idx < 0 {
+				return nil, fmt.Errorf("%w: 分配 %s 不存在", ErrInvalidChange, c.AssignmentID)
+			}
+			a := *c.Assignment
+			a.ID = c.AssignmentID
+			a.ScheduleID = schedule.ID
+			a.OrgID = schedule.OrgID
+			a.CreatedAt = schedule.Assignments[idx].CreatedAt
+			if a.Status == "" {
+				a.Status = schedule.Assignments[idx].Status
+			}
+			schedule.Assignments[idx] = a
+			c.Assignment = &a
+
+		case model.ChangeRemove:
+			idx := indexOf(schedule, c.AssignmentID)
+			if idx < 0 {
+				return nil, fmt.Errorf("%w: 分配 %s 不存在", ErrInvalidChange, c.AssignmentID)
+			}
+			schedule.Assignments = append(schedule.Assignments[:idx:idx], schedule.Assignments[idx+1:]...)
+			c.Assignment = nil
+
+		default:
+			return nil, fmt.Errorf("%w: 未知操作 %q", ErrInvalidChange, c.Op)
+		}
+		applied = append(applied, c)
+	}
+	return applied, nil
+}
+
+// LockedDate 检查变更是否涉及已结账期间的分配
+// 修改和删除检查原分配日期，新增和修改检查新分配日期，返回第一个被锁定的日期
+func LockedDate(schedule *model.Schedule, c model.AssignmentChange, lockedBefore string) (string, bool) {
+	if lockedBefore == "" {
+		return "", false
+	}
+	if c.Op != model.ChangeAdd {
+		if idx := indexOf(schedule, c.AssignmentID); idx >= 0 && model.IsPayrollLocked(schedule.Assignments[idx].Date, lockedBefore) {
+			return schedule.Assignments[idx].Date, true
+		}
+	}
+	if c.Op != model.ChangeRemove && c.Assignment != nil && model.IsPayrollLocked(c.Assignment.Date, lockedBefore) {
+		return c.Assignment.Date, true
+	}
+	return "", false
+}
+
+// indexOf 查找分配在排班中的位置
+func indexOf(schedule *model.Schedule, id uuid.UUID) int {
+	for i, a := range schedule.Assignments {
+		if a.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// findBooking 查找同一员工同一天的其他分配
+func findBooking(schedule *model.Schedule, a *model.Assignment, exclude uuid.UUID) *model.Assignment {
+	for i := range schedule.Assignments {
+		existing := &schedule.Assignments[i]
+		if existing.ID == exclude || existing.ID == a.ID {
+			continue
+		}
+		if existing.EmployeeID == a.EmployeeID && existing.Date == a.Date {
+			return existing
+		}
+	}
+	return nil
+}
+
+// changeTarget 返回变更作用的分配ID
+func changeTarget(c model.AssignmentChange) uuid.UUID {
+	if c.AssignmentID == uuid.Nil && c.Assignment != nil {
+		return c.Assignment.ID
+	}
+	return c.AssignmentID
+}
+
+// sameChange 判断两次变更效果是否相同
+func sameChange(a, b model.AssignmentChange) bool {
+	if a.Op != b.Op {
+		return false
+	}
+	if a.Op == model.ChangeRemove {
+		return true
+	}
+	if a.Assignment == nil || b.Assignment == nil {
+		return false
+	}
+	x, y := a.Assignment, b.Assignment
+	return x.EmployeeID == y.EmployeeID && x.ShiftID == y.ShiftID && x.Date == y.Date &&
+		x.StartTime.Equal(y.StartTime) && x.EndTime.Equal(y.EndTime) && x.Position == y.Position
+}
@@ -0,0 +1,187 @@
+package draft
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func newTestDraft(t *testing.T) (*Editor, *model.Schedule) {
+	t.Helper()
+	store := memstore.New("")
+	schedule := &model.Schedule{
+		BaseModel: model.NewBaseModel(),
+		OrgID:     uuid.New(),
+		StartDate: "2026-01-19",
+		EndDate:   "2026-01-25",
+		Status:    "draft",
+		Version:   1,
+	}
+	for _, date := range []string{"2026-01-19", "2026-01-20"} {
+		schedule.Assignments = append(schedule.Assignments, model.Assignment{
+			BaseModel:  model.NewBaseModel(),
+			EmployeeID: uuid.New(),
+			ShiftID:    uuid.New(),
+			Date:       date,
+			Status:     "scheduled",
+		})
+	}
+	store.PutSchedule(schedule)
+	return NewEditor(store), schedule
+}
+
+func reassign(a model.Assignment, employeeID uuid.UUID) model.AssignmentChange {
+	a.EmployeeID = employeeID
+	return model.AssignmentChange{Op: model.ChangeUpdate, AssignmentID: a.ID, Assignment: &a}
+}
+
+func TestEditor_StaleVersionRejected(t *testing.T) {
+	e, schedule := newTestDraft(t)
+	first := schedule.Assignments[0]
+
+	updated, err := e.Edit(schedule.ID, 1, "a", []model.AssignmentChange{reassign(first, uuid.New())})
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("version = %d, expected 2", updated.Version)
+	}
+
+	// 第二位编辑者仍基于版本 1
+	if _, err := e.Edit(schedule.ID, 1, "b", []model.AssignmentChange{reassign(first, uuid.New())}); err != memstore.ErrVersionConflict {
+		t.Fatalf("Edit() error = %v, expected ErrVersionConflict", err)
+	}
+}
+
+func TestEditor_MergeNonConflicting(t *testing.T) {
+	e, schedule := newTestDraft(t)
+	first, second := schedule.Assignments[0], schedule.Assignments[1]
+
+	// A 修改第一个分配
+	if _, err := e.Edit(schedule.ID, 1, "a", []model.AssignmentChange{reassign(first, uuid.New())}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+
+	// B 基于版本 1 修改第二个分配并同时改第一个分配
+	bEmp := uuid.New()
+	result, err := e.Merge(schedule.ID, 1, "b", []model.AssignmentChange{
+		reassign(second, bEmp),
+		reassign(first, uuid.New()),
+	})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(result.Applied) != 1 || result.Applied[0].AssignmentID != second.ID {
+		t.Errorf("应仅应用第二个分配的修改, got %+v", result.Applied)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Reason != ConflictModified || result.Conflicts[0].Theirs == nil {
+		t.Fatalf("应报告第一个分配的冲突, got %+v", result.Conflicts)
+	}
+	if result.Schedule.Version != 3 {
+		t.Errorf("version = %d, expected 3", result.Schedule.Version)
+	}
+
+	saved, _ := e.store.GetSchedule(schedule.ID)
+	if saved.Assignments[1].EmployeeID != bEmp {
+		t.Error("合并后的修改应已保存")
+	}
+	if revs := e.store.ListScheduleRevisions(schedule.ID, 0); len(revs) != 2 {
+		t.Errorf("expected 2 revisions, got %d", len(revs))
+	}
+}
+
+func TestEditor_MergeDoubleBooking(t *testing.T) {
+	e, schedule := newTestDraft(t)
+	emp := uuid.New()
+	add := func() model.AssignmentChange {
+		return model.AssignmentChange{Op: model.ChangeAdd, Assignment: &model.Assignment{
+			EmployeeID: emp, ShiftID: uuid.New(), Date: "2026-01-21",
+		}}
+	}
+
+	if _, err := e.Edit(schedule.ID, 1, "a", []model.AssignmentChange{add()}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	result, err := e.Merge(schedule.ID, 1, "b", []model.AssignmentChange{add()})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(result.Applied) != 0 || len(result.Conflicts) != 1 || result.Conflicts[0].Reason != ConflictDoubleBooking {
+		t.Errorf("同一员工同一天应冲突, got applied=%d conflicts=%+v", len(result.Applied), result.Conflicts)
+	}
+}
+
+func TestEditor_PayrollLocked(t *testing.T) {
+	e, schedule := newTestDraft(t)
+	first, second := schedule.Assignments[0], schedule.Assignments[1]
+	if _, err := e.store.AddPayrollClose(&model.PayrollClose{ID: uuid.New(), OrgID: schedule.OrgID, LockedBefore: "2026-01-20"}); err != nil {
+		t.Fatalf("AddPayrollClose() error = %v", err)
+	}
+
+	if _, err := e.Edit(schedule.ID, 1, "a", []model.AssignmentChange{reassign(first, uuid.New())}); !errors.Is(err, ErrPeriodClosed) {
+		t.Fatalf("已结账日期的分配不可修改, got %v", err)
+	}
+	// 不可把未结账的分配移入已结账日期
+	moved := second
+	moved.Date = "2026-01-19"
+	if _, err := e.Edit(schedule.ID, 1, "a", []model.AssignmentChange{{Op: model.ChangeUpdate, AssignmentID: second.ID, Assignment: &moved}}); !errors.Is(err, ErrPeriodClosed) {
+		t.Fatalf("不可移入已结账日期, got %v", err)
+	}
+
+	result, err := e.Merge(schedule.ID, 1, "a", []model.AssignmentChange{
+		{Op: model.ChangeRemove, AssignmentID: first.ID},
+		reassign(second, uuid.New()),
+	})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(result.Applied) != 1 || len(result.Conflicts) != 1 || result.Conflicts[0].Reason != ConflictLocked {
+		t.Errorf("已结账的变更应列为冲突: %+v", result)
+	}
+}
+
+func TestEditor_Rollback(t *testing.T) {
+	e, schedule := newTestDraft(t)
+	e.store.AddScheduleVersion(schedule, model.ScheduleVersionGenerate, "")
+	first, second := schedule.Assignments[0], schedule.Assignments[1]
+
+	// 版本 2：改派第一个分配、删除第二个分配
+	if _, err := e.Edit(schedule.ID, 1, "a", []model.AssignmentChange{
+		reassign(first, uuid.New()),
+		{Op: model.ChangeRemove, AssignmentID: second.ID},
+	}); err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+
+	if _, _, err := e.Rollback(schedule.ID, 1, 1, "b"); err != memstore.ErrVersionConflict {
+		t.Errorf("Rollback() stale base error = %v, expected ErrVersionConflict", err)
+	}
+	if _, _, err := e.Rollback(schedule.ID, 9, 2, "b"); err != ErrVersionNotFound {
+		t.Errorf("Rollback() unknown version error = %v, expected ErrVersionNotFound", err)
+	}
+
+	restored, changes, err := e.Rollback(schedule.ID, 1, 2, "b")
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if restored.Version != 3 || len(restored.Assignments) != 2 || restored.Assignments[0].EmployeeID != first.EmployeeID || restored.Assignments[1].ID != second.ID {
+		t.Errorf("restored = %+v", restored)
+	}
+	if len(changes) != 2 || changes[0].Op != model.ChangeUpdate || changes[1].Op != model.ChangeAdd {
+		t.Errorf("changes = %+v", changes)
+	}
+
+	versions := e.store.ListScheduleVersions(schedule.ID)
+	if len(versions) != 3 || versions[2].Reason != model.ScheduleVersionRollback || versions[2].Author != "b" {
+		t.Errorf("versions = %+v", versions)
+	}
+	if revisions := e.store.ListScheduleRevisions(schedule.ID, 2); len(revisions) != 1 || revisions[0].RestoredFrom != 1 {
+		t.Errorf("revisions = %+v", revisions)
+	}
+	if _, _, err := e.Rollback(schedule.ID, 3, 3, "b"); !errors.Is(err, ErrInvalidChange) {
+		t.Errorf("Rollback() current version error = %v, expected ErrInvalidChange", err)
+	}
+}
@@ -0,0 +1,214 @@
+// Package exporter 将排班导出为排班表网格（行=员工，列=日期，单元格=班次代码）的 CSV/Excel 文件
+package exporter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 导出格式
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// MaxDays 单次导出的最大天数
+const MaxDays = 62
+
+// Entry 导出的单个分配
+type Entry struct {
+	EmployeeID   string
+	EmployeeName string
+	Position     string
+	Date         string
+	Start        string  // 上班时间（HH:MM），同一天多个班次按上班时间排列
+	Code         string  // 班次代码（单元格内容）
+	Hours        float64 // 工时
+}
+
+// Gap 未满足的需求
+type Gap struct {
+	Date     string
+	Shortage int // 缺口人次
+}
+
+// Roster 排班表网格
+type Roster struct {
+	Title         string
+	Dates         []string
+	Rows          []Row
+	DayHours      []float64 // 每天合计工时，与 Dates 一一对应
+	DayUnfilled   []int     // 每天未满足人次，与 Dates 一一对应
+	TotalHours    float64
+	TotalUnfilled int
+}
+
+// Row 员工行
+type Row struct {
+	EmployeeName string
+	Position     string
+	Cells        []string // 班次代码，同一天多个班次以 / 分隔
+	Hours        float64
+	Shifts       int
+}
+
+// Cell 表格单元格：Numeric 为 true 时为数值
+type Cell struct {
+	Text    string
+	Value   float64
+	Numeric bool
+}
+
+// BuildRoster 按日期范围汇总分配和未满足需求，范围外的分配和缺口不计入
+// 员工行按姓名排序，单元格内同一天的多个班次按上班时间排列
+func BuildRoster(title, startDate, endDate string, entries []Entry, gaps []Gap) (*Roster, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("开始日期格式无效: %s", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("结束日期格式无效: %s", endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("结束日期不能早于开始日期")
+	}
+	days := int(end.Sub(start).Hours()/24) + 1
+	if days > MaxDays {
+		return nil, fmt.Errorf("导出范围不能超过 %d 天", MaxDays)
+	}
+
+	r := &Roster{
+		Title:       title,
+		Dates:       make([]string, days),
+		DayHours:    make([]float64, days),
+		DayUnfilled: make([]int, days),
+	}
+	column := make(map[string]int, days)
+	for i := 0; i < days; i++ {
+		r.Dates[i] = start.AddDate(0, 0, i).Format("2006-01-02")
+		column[r.Dates[i]] = i
+	}
+
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	rows := make(map[string]*Row)
+	var keys []string
+	for _, e := range sorted {
+		col, ok := column[e.Date]
+		if !ok {
+			continue
+		}
+		key := e.EmployeeID
+		if key == "" {
+			key = e.EmployeeName
+		}
+		row, ok := rows[key]
+		if !ok {
+			row = &Row{EmployeeName: e.EmployeeName, Position: e.Position, Cells: make([]string, days)}
+			rows[key] = row
+			keys = append(keys, key)
+		}
+		if row.Cells[col] != "" {
+			row.Cells[col] += "/"
+		}
+		row.Cells[col] += e.Code
+		row.Hours += e.Hours
+		row.Shifts++
+		r.DayHours[col] += e.Hours
+		r.TotalHours += e.Hours
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := rows[keys[i]], rows[keys[j]]
+		if a.EmployeeName != b.EmployeeName {
+			return a.EmployeeName < b.EmployeeName
+		}
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		row := rows[key]
+		row.Hours = round(row.Hours)
+		r.Rows = append(r.Rows, *row)
+	}
+	for i := range r.DayHours {
+		r.DayHours[i] = round(r.DayHours[i])
+	}
+	r.TotalHours = round(r.TotalHours)
+
+	for _, g := range gaps {
+		if col, ok := column[g.Date]; ok && g.Shortage > 0 {
+			r.DayUnfilled[col] += g.Shortage
+			r.TotalUnfilled += g.Shortage
+		}
+	}
+	return r, nil
+}
+
+// Table 返回排班表的表格内容：表头、员工行，以及合计工时和未满足人次两行汇总
+func (r *Roster) Table() [][]Cell {
+	header := []Cell{text("员工"), text("岗位")}
+	for _, d := range r.Dates {
+		header = append(header, text(dateLabel(d)))
+	}
+	header = append(header, text("工时"), text("班次数"))
+	table := [][]Cell{header}
+
+	for _, row := range r.Rows {
+		cells := []Cell{text(row.EmployeeName), text(row.Position)}
+		for _, c := range row.Cells {
+			cells = append(cells, text(c))
+		}
+		cells = append(cells, number(row.Hours), number(float64(row.Shifts)))
+		table = append(table, cells)
+	}
+
+	hours := []Cell{text("合计工时"), text("")}
+	for _, h := range r.DayHours {
+		hours = append(hours, number(h))
+	}
+	hours = append(hours, number(r.TotalHours), text(""))
+	unfilled := []Cell{text("未满足人次"), text("")}
+	for _, n := range r.DayUnfilled {
+		unfilled = append(unfilled, number(float64(n)))
+	}
+	unfilled = append(unfilled, number(float64(r.TotalUnfilled)), text(""))
+	return append(table, hours, unfilled)
+}
+
+// ContentType 返回导出格式对应的 MIME 类型
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// String 单元格的文本形式（数值按最短小数表示）
+func (c Cell) String() string {
+	if !c.Numeric {
+		return c.Text
+	}
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", c.Value), "0"), ".")
+}
+
+// dateLabel 表头日期：月-日 星期
+func dateLabel(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	weekdays := [...]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+	return t.Format("01-02") + " " + weekdays[t.Weekday()]
+}
+
+func text(s string) Cell { return Cell{Text: s} }
+
+func number(v float64) Cell { return Cell{Value: v, Numeric: true} }
+
+// round 保留两位小数
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
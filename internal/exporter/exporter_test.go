@@ -0,0 +1,106 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testRoster(t *testing.T) *Roster {
+	t.Helper()
+	entries := []Entry{
+		{EmployeeID: "2", EmployeeName: "李四", Date: "2026-01-12", Start: "14:00", Code: "N", Hours: 8},
+		{EmployeeID: "1", EmployeeName: "张三", Position: "服务员", Date: "2026-01-12", Start: "17:00", Code: "E", Hours: 4},
+		{EmployeeID: "1", EmployeeName: "张三", Position: "服务员", Date: "2026-01-12", Start: "09:00", Code: "D", Hours: 4},
+		{EmployeeID: "1", EmployeeName: "张三", Date: "2026-01-14", Start: "09:00", Code: "D", Hours: 8},
+		{EmployeeID: "1", EmployeeName: "张三", Date: "2026-01-20", Start: "09:00", Code: "D", Hours: 8}, // 范围外
+	}
+	gaps := []Gap{{Date: "2026-01-13", Shortage: 2}, {Date: "2026-01-14", Shortage: 1}}
+	r, err := BuildRoster("排班表", "2026-01-12", "2026-01-14", entries, gaps)
+	if err != nil {
+		t.Fatalf("BuildRoster: %v", err)
+	}
+	return r
+}
+
+func TestBuildRoster(t *testing.T) {
+	r := testRoster(t)
+	if len(r.Dates) != 3 || len(r.Rows) != 2 {
+		t.Fatalf("dates=%v rows=%+v", r.Dates, r.Rows)
+	}
+	zhang := r.Rows[0]
+	if zhang.EmployeeName != "张三" || zhang.Cells[0] != "D/E" || zhang.Cells[2] != "D" || zhang.Hours != 16 || zhang.Shifts != 3 {
+		t.Errorf("张三 = %+v", zhang)
+	}
+	if r.DayHours[0] != 16 || r.TotalHours != 24 {
+		t.Errorf("hours = %v total=%v", r.DayHours, r.TotalHours)
+	}
+	if r.DayUnfilled[1] != 2 || r.TotalUnfilled != 3 {
+		t.Errorf("unfilled = %v total=%d", r.DayUnfilled, r.TotalUnfilled)
+	}
+
+	if _, err := BuildRoster("", "2026-01-14", "2026-01-12", nil, nil); err == nil {
+		t.Error("结束日期早于开始日期应返回错误")
+	}
+	if _, err := BuildRoster("", "2026-01-01", "2026-04-01", nil, nil); err == nil {
+		t.Error("超过最大天数应返回错误")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testRoster(t)); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\uFEFF"))).ReadAll()
+	if err != nil {
+		t.Fatalf("解析 CSV 失败: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("records = %v", records)
+	}
+	if got := strings.Join(records[0], ","); got != "员工,岗位,01-12 周一,01-13 周二,01-14 周三,工时,班次数" {
+		t.Errorf("header = %s", got)
+	}
+	if got := strings.Join(records[3], ","); got != "合计工时,,16,0,8,24," {
+		t.Errorf("hours row = %s", got)
+	}
+	if got := strings.Join(records[4], ","); got != "未满足人次,,0,2,1,3," {
+		t.Errorf("unfilled row = %s", got)
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, testRoster(t)); err != nil {
+		t.Fatalf("WriteXLSX: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("xlsx 不是有效的 zip: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(data)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/styles.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("缺少部件 %s", name)
+		}
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{`<c r="C2" t="inlineStr"><is><t>D/E</t></is></c>`, `<c r="F4" s="1"><v>24</v></c>`, `<c r="A5" s="1" t="inlineStr"><is><t>未满足人次</t></is></c>`} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("工作表缺少 %s", want)
+		}
+	}
+	if columnName(0) != "A" || columnName(25) != "Z" || columnName(26) != "AA" || columnName(27) != "AB" {
+		t.Errorf("columnName: %s %s %s", columnName(0), columnName(25), columnName(26))
+	}
+}
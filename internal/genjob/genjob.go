@@ -0,0 +1,321 @@
+// Package genjob 提供异步排班生成作业
+// 大规模排班（数百名员工、整月）同步生成会超过请求超时，异步模式下请求保存为作业立即返回，
+// 由后台工作协程排队求解；求解过程中记录阶段和进度，完成后保存生成响应供客户端获取。
+// 作业可随时取消（正在求解的作业中断求解），作业状态保存在内存存储中，服务重启后未完成的作业重新执行。
+// 客户端可订阅正在求解的作业，实时接收求解进度（轮次、分配数、当前得分）
+package genjob
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// progressInterval 进度写入存储的最小间隔（阶段变化时立即写入）
+const progressInterval = 500 * time.Millisecond
+
+// subscriberBuffer 订阅通道的缓冲大小，订阅方来不及接收时丢弃进度
+const subscriberBuffer = 16
+
+var (
+	// ErrInvalidJob 作业参数无效
+	ErrInvalidJob = stderrors.New("排班生成作业无效")
+	// ErrJobFinished 作业已结束
+	ErrJobFinished = stderrors.New("排班生成作业已结束")
+)
+
+// Processor 执行排班生成，progress 用于报告求解进度；
+// 返回生成响应和排班ID（未保存时为空）
+type Processor func(ctx context.Context, job *model.GenerateJob, progress solver.ProgressHook) (json.RawMessage, string, error)
+
+// Runner 排班生成作业执行器
+type Runner struct {
+	store   *memstore.Store
+	process Processor
+	queue   chan uuid.UUID
+	workers int
+
+	mu      sync.Mutex // 串行化作业的读-改-写
+	queued  map[uuid.UUID]bool
+	cancels map[uuid.UUID]context.CancelFunc // 正在求解的作业
+	subs    map[uuid.UUID]map[chan solver.Progress]struct{}
+	now     func() time.Time
+}
+
+// NewRunner 创建排班生成作业执行器，workers 为同时求解的作业数
+func NewRunner(store *memstore.Store, workers int, process Processor) *Runner {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Runner{
+		store:   store,
+		process: process,
+		queue:   make(chan uuid.UUID, 1024),
+		workers: workers,
+		queued:  make(map[uuid.UUID]bool),
+		cancels: make(map[uuid.UUID]context.CancelFunc),
+		subs:    make(map[uuid.UUID]map[chan solver.Progress]struct{}),
+		now:     time.Now,
+	}
+}
+
+// Submit 保存并排队执行作业
+func (r *Runner) Submit(orgID uuid.UUID, request json.RawMessage, createdBy string) (*model.GenerateJob, error) {
+	if orgID == uuid.Nil || len(request) == 0 {
+		return nil, fmt.Errorf("%w: 缺少组织或请求", ErrInvalidJob)
+	}
+	now := r.now()
+	job := &model.GenerateJob{
+		ID:        uuid.New(),
+		OrgID:     orgID,
+		Status:    model.GenerateJobQueued,
+		Request:   request,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := r.store.PutGenerateJob(job); err != nil {
+		return nil, err
+	}
+	r.enqueue(job.ID)
+	return job.Summary(), nil
+}
+
+// Cancel 取消作业：排队中的作业不再执行，正在求解的作业中断求解
+func (r *Runner) Cancel(id uuid.UUID) (*model.GenerateJob, error) {
+	job, err := r.update(id, func(job *model.GenerateJob) error {
+		if job.IsFinished() {
+			return ErrJobFinished
+		}
+		now := r.now()
+		job.Status, job.FinishedAt = model.GenerateJobCancelled, &now
+		if cancel := r.cancels[id]; cancel != nil {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.closeSubscribers(id)
+	return job.Summary(), nil
+}
+
+// Subscribe 订阅作业的求解进度，作业结束（完成、失败或取消）时通道关闭；
+// 已结束的作业返回已关闭的通道。订阅方不再接收时须调用返回的取消函数
+func (r *Runner) Subscribe(id uuid.UUID) (<-chan solver.Progress, func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, err := r.store.GetGenerateJob(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	ch := make(chan solver.Progress, subscriberBuffer)
+	if job.IsFinished() {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	if r.subs[id] == nil {
+		r.subs[id] = make(map[chan solver.Progress]struct{})
+	}
+	r.subs[id][ch] = struct{}{}
+	unsubscribe := func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if _, ok := r.subs[id][ch]; ok {
+			delete(r.subs[id], ch)
+			close(ch)
+			if len(r.subs[id]) == 0 {
+				delete(r.subs, id)
+			}
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// Run 启动工作协程执行排队的作业，直到 ctx 取消；启动时重新执行上次未完成的作业
+func (r *Runner) Run(ctx context.Context) {
+	for _, job := range r.store.ListGenerateJobs(uuid.Nil) {
+		if job.IsFinished() {
+			continue
+		}
+		r.update(job.ID, func(job *model.GenerateJob) error {
+			job.Status, job.Phase, job.Progress = model.GenerateJobQueued, "", 0
+			return nil
+		})
+		logger.Info().Str("job_id", job.ID.String()).Msg("恢复未完成的排班生成作业")
+		r.enqueue(job.ID)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-r.queue:
+					r.dequeue(id)
+					r.execute(ctx, id)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// execute 求解单个作业
+func (r *Runner) execute(ctx context.Context, id uuid.UUID) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	job, err := r.update(id, func(job *model.GenerateJob) error {
+		if job.Status != model.GenerateJobQueued {
+			return ErrJobFinished
+		}
+		now := r.now()
+		job.Status, job.StartedAt = model.GenerateJobRunning, &now
+		job.Attempts++
+		r.cancels[id] = cancel
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	defer func() {
+		r.mu.Lock()
+		delete(r.cancels, id)
+		r.mu.Unlock()
+	}()
+
+	var lastPhase string
+	var lastWrite time.Time
+	progress := func(p solver.Progress) {
+		r.publish(id, p)
+		if p.Phase == lastPhase && r.now().Sub(lastWrite) < progressInterval {
+			return
+		}
+		lastPhase, lastWrite = p.Phase, r.now()
+		r.update(id, func(job *model.GenerateJob) error {
+			if job.Status != model.GenerateJobRunning {
+				return ErrJobFinished
+			}
+			job.Phase, job.Progress = p.Phase, float64(int(p.Fraction*1000))/10
+			return nil
+		})
+	}
+
+	result, scheduleID, procErr := r.run(jobCtx, job, progress)
+	if ctx.Err() != nil {
+		// 服务退出：作业保持 running，重启后重新执行
+		return
+	}
+	job, err = r.update(id, func(job *model.GenerateJob) error {
+		if job.Status != model.GenerateJobRunning {
+			return ErrJobFinished
+		}
+		now := r.now()
+		job.FinishedAt = &now
+		if procErr != nil {
+			job.Status, job.Error = model.GenerateJobFailed, procErr.Error()
+			var appErr *errors.AppError
+			if stderrors.As(procErr, &appErr) {
+				job.Error, job.ErrorCode = appErr.Message, string(appErr.Code)
+			}
+			return nil
+		}
+		job.Status, job.Progress = model.GenerateJobCompleted, 100
+		job.Result, job.ScheduleID = result, scheduleID
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	r.closeSubscribers(id)
+	logger.Info().
+		Str("job_id", id.String()).
+		Str("status", job.Status).
+		Str("schedule_id", job.ScheduleID).
+		Msg("排班生成作业结束")
+}
+
+// run 执行处理函数，处理函数 panic 时视为失败
+func (r *Runner) run(ctx context.Context, job *model.GenerateJob, progress solver.ProgressHook) (result json.RawMessage, scheduleID string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("排班生成异常: %v", p)
+		}
+	}()
+	return r.process(ctx, job, progress)
+}
+
+// publish 向作业的订阅方发送进度，订阅方通道已满时丢弃
+func (r *Runner) publish(id uuid.UUID, p solver.Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ch := range r.subs[id] {
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+}
+
+// closeSubscribers 作业结束，关闭所有订阅通道
+func (r *Runner) closeSubscribers(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ch := range r.subs[id] {
+		close(ch)
+	}
+	delete(r.subs, id)
+}
+
+// update 读取最新的作业、修改并保存
+func (r *Runner) update(id uuid.UUID, fn func(job *model.GenerateJob) error) (*model.GenerateJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, err := r.store.GetGenerateJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(job); err != nil {
+		return nil, err
+	}
+	job.UpdatedAt = r.now()
+	if err := r.store.PutGenerateJob(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// enqueue 作业排队（已在队列中的作业不重复排队）
+func (r *Runner) enqueue(id uuid.UUID) {
+	r.mu.Lock()
+	if r.queued[id] {
+		r.mu.Unlock()
+		return
+	}
+	r.queued[id] = true
+	r.mu.Unlock()
+	go func() { r.queue <- id }()
+}
+
+// dequeue 作业出队
+func (r *Runner) dequeue(id uuid.UUID) {
+	r.mu.Lock()
+	delete(r.queued, id)
+	r.mu.Unlock()
+}
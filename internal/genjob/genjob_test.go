@@ -0,0 +1,180 @@
+package genjob
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+func startRunner(t *testing.T, store *memstore.Store, process Processor) *Runner {
+	t.Helper()
+	r := NewRunner(store, 1, process)
+	ctx, cancel := context.WithCancel(context.Background())
+	go r.Run(ctx)
+	t.Cleanup(cancel)
+	return r
+}
+
+func waitStatus(t *testing.T, store *memstore.Store, id uuid.UUID, status string) *model.GenerateJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := store.GetGenerateJob(id)
+		if err == nil && job.Status == status {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("作业 %s 未达到状态 %s", id, status)
+	return nil
+}
+
+func TestRunner_CompletesWithResult(t *testing.T) {
+	store := memstore.New("")
+	r := startRunner(t, store, func(ctx context.Context, job *model.GenerateJob, progress solver.ProgressHook) (json.RawMessage, string, error) {
+		progress(solver.Progress{Phase: "greedy", Fraction: 0.5})
+		return json.RawMessage(`{"success":true}`), "schedule-1", nil
+	})
+
+	job, err := r.Submit(uuid.New(), json.RawMessage(`{}`), "u1")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if job.Request != nil {
+		t.Error("提交返回的摘要不应包含请求")
+	}
+
+	done := waitStatus(t, store, job.ID, model.GenerateJobCompleted)
+	if done.Progress != 100 || done.Phase != "greedy" || done.ScheduleID != "schedule-1" || done.Attempts != 1 {
+		t.Errorf("作业状态错误: %+v", done.Summary())
+	}
+	if string(done.Result) != `{"success":true}` || done.FinishedAt == nil {
+		t.Errorf("result = %s", done.Result)
+	}
+	if _, err := r.Cancel(job.ID); err != ErrJobFinished {
+		t.Errorf("Cancel() 已结束作业 error = %v", err)
+	}
+	if _, err := r.Submit(uuid.Nil, json.RawMessage(`{}`), ""); err == nil {
+		t.Error("缺少组织应返回错误")
+	}
+}
+
+func TestRunner_Failed(t *testing.T) {
+	store := memstore.New("")
+	r := startRunner(t, store, func(ctx context.Context, job *model.GenerateJob, progress solver.ProgressHook) (json.RawMessage, string, error) {
+		return nil, "", errors.New(errors.CodeValidationFail, "排班求解失败")
+	})
+
+	job, _ := r.Submit(uuid.New(), json.RawMessage(`{}`), "")
+	done := waitStatus(t, store, job.ID, model.GenerateJobFailed)
+	if done.Error != "排班求解失败" || done.ErrorCode != string(errors.CodeValidationFail) || done.Result != nil {
+		t.Errorf("失败作业: %+v", done.Summary())
+	}
+}
+
+func TestRunner_CancelRunning(t *testing.T) {
+	store := memstore.New("")
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	r := startRunner(t, store, func(ctx context.Context, job *model.GenerateJob, progress solver.ProgressHook) (json.RawMessage, string, error) {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return nil, "", ctx.Err()
+	})
+
+	job, _ := r.Submit(uuid.New(), json.RawMessage(`{}`), "")
+	<-started
+	if _, err := r.Cancel(job.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("取消后求解未中断")
+	}
+
+	// 求解中断后作业保持已取消，不记为失败
+	time.Sleep(20 * time.Millisecond)
+	done, _ := store.GetGenerateJob(job.ID)
+	if done.Status != model.GenerateJobCancelled || done.Error != "" {
+		t.Errorf("取消作业: %+v", done.Summary())
+	}
+}
+
+func TestRunner_Subscribe(t *testing.T) {
+	store := memstore.New("")
+	subscribed := make(chan struct{})
+	r := startRunner(t, store, func(ctx context.Context, job *model.GenerateJob, progress solver.ProgressHook) (json.RawMessage, string, error) {
+		<-subscribed
+		progress(solver.Progress{Phase: "greedy", Fraction: 0.5, Round: 1, Assignments: 3, Score: 50})
+		progress(solver.Progress{Phase: "greedy", Fraction: 1, Round: 2, Assignments: 6, Score: 100})
+		return json.RawMessage(`{}`), "", nil
+	})
+
+	job, _ := r.Submit(uuid.New(), json.RawMessage(`{}`), "")
+	events, unsubscribe, err := r.Subscribe(job.ID)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer unsubscribe()
+	close(subscribed)
+
+	var got []solver.Progress
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case p, ok := <-events:
+			if !ok {
+				done = true
+				break
+			}
+			got = append(got, p)
+		case <-timeout:
+			t.Fatal("作业结束后订阅通道未关闭")
+		}
+	}
+	if len(got) != 2 || got[1].Round != 2 || got[1].Assignments != 6 || got[1].Score != 100 {
+		t.Errorf("进度 = %+v", got)
+	}
+
+	// 已结束的作业返回已关闭的通道
+	events, _, err = r.Subscribe(job.ID)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if _, ok := <-events; ok {
+		t.Error("已结束作业的订阅通道应已关闭")
+	}
+	if _, _, err := r.Subscribe(uuid.New()); err != memstore.ErrNotFound {
+		t.Errorf("Subscribe() 不存在的作业 error = %v", err)
+	}
+}
+
+func TestRunner_ResumesUnfinished(t *testing.T) {
+	store := memstore.New("")
+	now := time.Now()
+	// 服务退出前正在求解的作业
+	job := &model.GenerateJob{
+		ID: uuid.New(), OrgID: uuid.New(), Status: model.GenerateJobRunning,
+		Phase: "annealing", Progress: 60, Attempts: 1,
+		Request: json.RawMessage(`{}`), CreatedAt: now, UpdatedAt: now, StartedAt: &now,
+	}
+	store.PutGenerateJob(job)
+
+	startRunner(t, store, func(ctx context.Context, job *model.GenerateJob, progress solver.ProgressHook) (json.RawMessage, string, error) {
+		return json.RawMessage(`{}`), "", nil
+	})
+
+	done := waitStatus(t, store, job.ID, model.GenerateJobCompleted)
+	if done.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", done.Attempts)
+	}
+}
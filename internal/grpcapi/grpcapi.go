@@ -0,0 +1,330 @@
+// Package grpcapi 提供排班和派单接口的 gRPC 服务（api/proto/paiban/v1/paiban.proto）
+// 每个 RPC 将请求转为与 HTTP 接口相同的 JSON，交给同一套 HTTP 处理器（含认证、限流等中间件）在进程内执行，
+// 再把 JSON 响应转回 protobuf 消息，因此两种接口的校验规则、错误和结果完全一致
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	paibanv1 "github.com/paiban/paiban/api/proto/paiban/v1"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// forwardedMetadata 转为同名请求头的 gRPC 元数据（认证凭证和请求ID）
+var forwardedMetadata = []string{"authorization", "x-api-key", "x-request-id"}
+
+// progressBuffer 流式生成时缓存的进度消息数，客户端接收较慢时丢弃中间进度
+const progressBuffer = 64
+
+// Server 排班和派单 gRPC 服务
+type Server struct {
+	paibanv1.UnimplementedScheduleServiceServer
+	paibanv1.UnimplementedDispatchServiceServer
+
+	api http.Handler
+}
+
+// NewServer 创建 gRPC 服务，api 为带中间件的 HTTP 接口处理器
+func NewServer(api http.Handler) *Server {
+	return &Server{api: api}
+}
+
+// Register 在 gRPC 服务器上注册排班和派单服务
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	paibanv1.RegisterScheduleServiceServer(registrar, s)
+	paibanv1.RegisterDispatchServiceServer(registrar, s)
+}
+
+// Generate 生成排班
+func (s *Server) Generate(ctx context.Context, req *paibanv1.GenerateRequest) (*paibanv1.GenerateResponse, error) {
+	resp := &paibanv1.GenerateResponse{}
+	if err := s.call(ctx, http.MethodPost, "/api/v1/schedule/generate", nil, requestBody(req), resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GenerateStream 生成排班，求解过程中推送进度，最后一条消息为结果
+func (s *Server) GenerateStream(req *paibanv1.GenerateRequest, stream grpc.ServerStreamingServer[paibanv1.GenerateEvent]) error {
+	progress := make(chan solver.Progress, progressBuffer)
+	hook := func(p solver.Progress) {
+		select {
+		case progress <- p:
+		default:
+		}
+	}
+	resp := &paibanv1.GenerateResponse{}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.call(solver.WithProgress(stream.Context(), hook), http.MethodPost, "/api/v1/schedule/generate", nil, requestBody(req), resp)
+		// 求解器在处理器返回前同步调用进度回调，此后不再有进度
+		close(progress)
+	}()
+
+	for p := range progress {
+		err := stream.Send(&paibanv1.GenerateEvent{Event: &paibanv1.GenerateEvent_Progress{Progress: &paibanv1.Progress{
+			Phase:       p.Phase,
+			Fraction:    p.Fraction,
+			Round:       int32(p.Round),
+			Assignments: int32(p.Assignments),
+			Score:       p.Score,
+		}}})
+		if err != nil {
+			// 客户端已断开：流的上下文随之取消，求解结束后丢弃结果
+			for range progress {
+			}
+			<-done
+			return err
+		}
+	}
+	if err := <-done; err != nil {
+		return err
+	}
+	return stream.Send(&paibanv1.GenerateEvent{Event: &paibanv1.GenerateEvent_Result{Result: resp}})
+}
+
+// Validate 校验排班
+func (s *Server) Validate(ctx context.Context, req *paibanv1.ValidateRequest) (*paibanv1.ValidateResponse, error) {
+	result, err := s.callStruct(ctx, http.MethodPost, "/api/v1/schedule/validate", nil, requestBody(req))
+	if err != nil {
+		return nil, err
+	}
+	return &paibanv1.ValidateResponse{Result: result}, nil
+}
+
+// Single 单个订单派单
+func (s *Server) Single(ctx context.Context, req *paibanv1.DispatchRequest) (*paibanv1.DispatchResponse, error) {
+	return s.dispatch(ctx, "/api/v1/dispatch/single", req)
+}
+
+// Batch 批量派单
+func (s *Server) Batch(ctx context.Context, req *paibanv1.BatchDispatchRequest) (*paibanv1.DispatchResponse, error) {
+	return s.dispatch(ctx, "/api/v1/dispatch/batch", req)
+}
+
+// Reassign 服务人员临时不可用时改派订单
+func (s *Server) Reassign(ctx context.Context, req *paibanv1.ReassignRequest) (*paibanv1.DispatchResponse, error) {
+	return s.dispatch(ctx, "/api/v1/dispatch/reassign", req)
+}
+
+func (s *Server) dispatch(ctx context.Context, path string, req proto.Message) (*paibanv1.DispatchResponse, error) {
+	result, err := s.callStruct(ctx, http.MethodPost, path, nil, requestBody(req))
+	if err != nil {
+		return nil, err
+	}
+	return &paibanv1.DispatchResponse{
+		Success: result.GetFields()["success"].GetBoolValue(),
+		Error:   result.GetFields()["error"].GetStringValue(),
+		Result:  result,
+	}, nil
+}
+
+// Route 多订单最优路线
+func (s *Server) Route(ctx context.Context, req *paibanv1.RouteRequest) (*paibanv1.RouteResponse, error) {
+	result, err := s.callStruct(ctx, http.MethodPost, "/api/v1/dispatch/route", nil, requestBody(req))
+	if err != nil {
+		return nil, err
+	}
+	return &paibanv1.RouteResponse{
+		Success: result.GetFields()["success"].GetBoolValue(),
+		Error:   result.GetFields()["error"].GetStringValue(),
+		Result:  result,
+	}, nil
+}
+
+// ReportStatus 上报服务人员状态，请求体为状态本身
+func (s *Server) ReportStatus(ctx context.Context, req *paibanv1.ReportStatusRequest) (*paibanv1.StatusResponse, error) {
+	result, err := s.callStruct(ctx, http.MethodPost, "/api/v1/dispatch/status", nil, req.GetStatus().AsMap())
+	if err != nil {
+		return nil, err
+	}
+	return &paibanv1.StatusResponse{Result: result}, nil
+}
+
+// ListStatus 查询服务人员状态
+func (s *Server) ListStatus(ctx context.Context, req *paibanv1.ListStatusRequest) (*paibanv1.StatusResponse, error) {
+	query := url.Values{}
+	if req.GetEmployeeId() != "" {
+		query.Set("employee_id", req.GetEmployeeId())
+	}
+	result, err := s.callStruct(ctx, http.MethodGet, "/api/v1/dispatch/status", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &paibanv1.StatusResponse{Result: result}, nil
+}
+
+// callStruct 执行 HTTP 接口，响应 JSON 原样作为 Struct 返回
+func (s *Server) callStruct(ctx context.Context, method, path string, query url.Values, body interface{}) (*structpb.Struct, error) {
+	result := &structpb.Struct{}
+	if err := s.call(ctx, method, path, query, body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// call 在进程内执行 HTTP 接口：请求体编码为 JSON，gRPC 元数据转为请求头，
+// 成功时将响应 JSON 解码到 resp（忽略 protobuf 消息中没有的字段），失败时返回对应的 gRPC 状态
+func (s *Server) call(ctx context.Context, method, path string, query url.Values, body interface{}, resp proto.Message) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return status.Errorf(codes.InvalidArgument, "编码请求失败: %v", err)
+		}
+	}
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	r, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(data))
+	if err != nil {
+		return status.Errorf(codes.Internal, "构造请求失败: %v", err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range forwardedMetadata {
+			if values := md.Get(key); len(values) > 0 {
+				r.Header.Set(key, values[0])
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+
+	w := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+	s.api.ServeHTTP(w, r)
+	if w.status >= http.StatusBadRequest {
+		return status.Error(statusCode(w.status), errorMessage(w.status, w.body.Bytes()))
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(w.body.Bytes(), resp); err != nil {
+		return status.Errorf(codes.Internal, "解析响应失败: %v", err)
+	}
+	return nil
+}
+
+// responseRecorder 在内存中记录 HTTP 处理器的响应
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *responseRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+}
+
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(p)
+}
+
+// statusCode HTTP 状态码转 gRPC 状态码
+func statusCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
+// errorMessage 取错误响应中的说明：排班接口为 message，派单接口为 error，其余返回响应文本
+func errorMessage(httpStatus int, body []byte) string {
+	var resp struct {
+		Message string      `json:"message"`
+		Error   interface{} `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) == nil {
+		if resp.Message != "" {
+			return resp.Message
+		}
+		if msg, ok := resp.Error.(string); ok && msg != "" {
+			return msg
+		}
+	}
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return msg
+	}
+	return http.StatusText(httpStatus)
+}
+
+// requestBody 将请求消息转为与 HTTP 请求体相同的 JSON 值：字段名为 proto 字段名，
+// 未设置的字段省略，int64 输出为数值（protojson 输出为字符串，HTTP 处理器无法解析），Struct 原样展开
+func requestBody(m proto.Message) map[string]interface{} {
+	return messageValue(m.ProtoReflect())
+}
+
+func messageValue(m protoreflect.Message) map[string]interface{} {
+	if s, ok := m.Interface().(*structpb.Struct); ok {
+		return s.AsMap()
+	}
+	out := make(map[string]interface{})
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		switch {
+		case fd.IsList():
+			list := v.List()
+			items := make([]interface{}, list.Len())
+			for i := range items {
+				items[i] = fieldValue(fd, list.Get(i))
+			}
+			out[name] = items
+		case fd.IsMap():
+			entries := make(map[string]interface{})
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				entries[k.String()] = fieldValue(fd.MapValue(), v)
+				return true
+			})
+			out[name] = entries
+		default:
+			out[name] = fieldValue(fd, v)
+		}
+		return true
+	})
+	return out
+}
+
+func fieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	if fd.Kind() == protoreflect.MessageKind {
+		return messageValue(v.Message())
+	}
+	return v.Interface()
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/alias"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// AliasHandler 技能/岗位/资质标签别名处理器
+type AliasHandler struct {
+	store *memstore.Store
+}
+
+// NewAliasHandler 创建标签别名处理器
+func NewAliasHandler(store *memstore.Store) *AliasHandler {
+	return &AliasHandler{store: store}
+}
+
+// ResolveRequest 标签归一化预览请求
+type ResolveRequest struct {
+	Kind   string   `json:"kind"`
+	Labels []string `json:"labels"`
+}
+
+// ResolvedLabel 标签归一化结果
+type ResolvedLabel struct {
+	Label     string `json:"label"`
+	Canonical string `json:"canonical"`
+	Mapped    bool   `json:"mapped"`
+}
+
+// Aliases 查询/替换组织的标签别名映射（替换需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/aliases
+// PUT 请求体为完整的别名列表，会覆盖该组织已有映射
+func (h *AliasHandler) Aliases(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, h.store.ListAliases(orgID))
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var aliases []*model.LabelAlias
+		if err := json.NewDecoder(r.Body).Decode(&aliases); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := alias.Validate(aliases); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+		now := time.Now()
+		for _, a := range aliases {
+			a.UpdatedAt = now
+		}
+		if err := h.store.ReplaceAliases(orgID, aliases); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "保存别名失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, h.store.ListAliases(orgID))
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// Unmapped 查询排班、派单和 HR 同步中遇到的未映射标签
+// 路由: GET /api/v1/orgs/{org_id}/aliases/unmapped
+func (h *AliasHandler) Unmapped(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, h.store.ListUnmapped(orgID))
+}
+
+// Resolve 预览标签按当前映射归一化的结果
+// 路由: POST /api/v1/orgs/{org_id}/aliases/resolve
+func (h *AliasHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	switch req.Kind {
+	case model.AliasKindSkill, model.AliasKindPosition, model.AliasKindCertification:
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "kind 应为 skill/position/certification"))
+		return
+	}
+
+	norm := alias.NewNormalizer(orgID, h.store.ListAliases(orgID))
+	result := make([]ResolvedLabel, 0, len(req.Labels))
+	for _, label := range req.Labels {
+		canonical, mapped := norm.Normalize(req.Kind, label)
+		result = append(result, ResolvedLabel{Label: label, Canonical: canonical, Mapped: mapped})
+	}
+	respondJSON(w, http.StatusOK, result)
+}
+
+// orgID 检查存储并解析路径中的组织ID
+func (h *AliasHandler) orgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/timebank"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/stats"
+)
+
+// defaultWeeklyCapacity 员工未设置期望最大周工时时的默认周可用工时
+const defaultWeeklyCapacity = 40
+
+// AnalyticsHandler 分析报表处理器（基于内存存储中的员工、班次和需求）
+type AnalyticsHandler struct {
+	store *memstore.Store
+}
+
+// NewAnalyticsHandler 创建分析报表处理器
+func NewAnalyticsHandler(store *memstore.Store) *AnalyticsHandler {
+	return &AnalyticsHandler{store: store}
+}
+
+// SkillGap 技能供需缺口报告
+// 路由: GET /api/v1/analytics/skill-gap?org_id=&start_date=&end_date=（组织受限的调用方可省略 org_id）
+// 需求工时来自排班需求（班次工时 × 最少人数），供给工时来自在职员工及其可用性
+func (h *AnalyticsHandler) SkillGap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+
+	query := r.URL.Query()
+	orgID, ok := listOrgID(w, r)
+	if !ok {
+		return
+	}
+	if orgID == uuid.Nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	startDate, endDate := query.Get("start_date"), query.Get("end_date")
+	start, err1 := time.Parse("2006-01-02", startDate)
+	end, err2 := time.Parse("2006-01-02", endDate)
+	if err1 != nil || err2 != nil || end.Before(start) {
+		respondError(w, errors.New(errors.CodeInvalidTimeRange, "start_date/end_date 格式应为 YYYY-MM-DD 且结束不早于开始"))
+		return
+	}
+
+	shiftHours := make(map[uuid.UUID]float64)
+	for _, shift := range h.store.ListShifts(orgID) {
+		shiftHours[shift.ID] = shift.DurationHours()
+	}
+
+	requirements := h.store.ListRequirements(orgID, startDate, endDate)
+	var groups []model.SkillGroup
+	demands := make([]*stats.DemandInfo, 0, len(requirements))
+	for _, req := range requirements {
+		skills := append([]string{}, req.Skills...)
+		for _, g := range req.SkillGroups {
+			skills = append(skills, g.String())
+			groups = append(groups, g)
+		}
+		demands = append(demands, &stats.DemandInfo{
+			Date:      req.Date,
+			Position:  req.Position,
+			Skills:    skills,
+			Hours:     shiftHours[req.ShiftID],
+			Headcount: req.MinEmployees,
+		})
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	supplies := make([]*stats.SupplyInfo, 0)
+	for _, emp := range h.store.ListEmployees(orgID) {
+		if emp.Status != "" && !emp.IsActive() {
+			continue
+		}
+		skills := append([]string{}, emp.Skills...)
+		for _, g := range groups {
+			if emp.MeetsSkillGroup(g) {
+				skills = append(skills, g.String())
+			}
+		}
+		supplies = append(supplies, &stats.SupplyInfo{
+			EmployeeID:     emp.ID.String(),
+			Position:       emp.Position,
+			Skills:         uniqueStrings(skills),
+			AvailableHours: h.availableHours(emp, startDate, endDate, days),
+		})
+	}
+
+	report := stats.NewSkillGapAnalyzer().Analyze(startDate, endDate, demands, supplies)
+	respondJSON(w, http.StatusOK, report)
+}
+
+// PreferenceSatisfaction 员工偏好满足度报告
+// 路由: GET /api/v1/stats/preference-satisfaction?org_id=&start_date=&end_date=&include_drafts=false（组织受限的调用方可省略 org_id）
+// 默认统计已发布排班，include_drafts=true 时同时统计草稿（用于发布前评估及按组织调优）；
+// 休息申请取自员工登记的整天不可用记录
+func (h *AnalyticsHandler) PreferenceSatisfaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+
+	query := r.URL.Query()
+	orgID, ok := listOrgID(w, r)
+	if !ok {
+		return
+	}
+	if orgID == uuid.Nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	startDate, endDate := query.Get("start_date"), query.Get("end_date")
+	start, err1 := time.Parse("2006-01-02", startDate)
+	end, err2 := time.Parse("2006-01-02", endDate)
+	if err1 != nil || err2 != nil || end.Before(start) {
+		respondError(w, errors.New(errors.CodeInvalidTimeRange, "start_date/end_date 格式应为 YYYY-MM-DD 且结束不早于开始"))
+		return
+	}
+	includeDrafts := query.Get("include_drafts") == "true"
+
+	shifts := make(map[uuid.UUID]*model.Shift)
+	for _, shift := range h.store.ListShifts(orgID) {
+		shifts[shift.ID] = shift
+	}
+
+	assignments := make([]*stats.PreferenceAssignment, 0)
+	seen := make(map[uuid.UUID]bool)
+	for _, schedule := range h.store.ListSchedules(orgID) {
+		if schedule.Status != "published" && !(includeDrafts && schedule.Status == "draft") {
+			continue
+		}
+		for _, a := range schedule.Assignments {
+			if a.Date < startDate || a.Date > endDate || a.Status == "cancelled" || seen[a.ID] {
+				continue
+			}
+			seen[a.ID] = true
+			pa := &stats.PreferenceAssignment{EmployeeID: a.EmployeeID.String(), Date: a.Date}
+			if shift, ok := shifts[a.ShiftID]; ok {
+				pa.ShiftCode = shift.Code
+				pa.ShiftType = shift.ShiftType
+			}
+			assignments = append(assignments, pa)
+		}
+	}
+
+	profiles := make([]*stats.PreferenceProfile, 0)
+	for _, emp := range h.store.ListEmployees(orgID) {
+		if emp.Status != "" && !emp.IsActive() {
+			continue
+		}
+		profile := &stats.PreferenceProfile{EmployeeID: emp.ID.String(), EmployeeName: emp.Name}
+		if prefs := emp.Preferences; prefs != nil {
+			profile.PreferredShifts = append([]string{}, prefs.PreferredShifts...)
+			for _, ranking := range prefs.ShiftRankings {
+				profile.PreferredShifts = append(profile.PreferredShifts, ranking.Shift)
+			}
+			profile.PreferredShifts = uniqueStrings(profile.PreferredShifts)
+			profile.AvoidShifts = prefs.AvoidShifts
+			profile.PreferredDays = prefs.PreferredDays
+			profile.AvoidDays = prefs.AvoidDays
+		}
+		for _, av := range h.store.ListAvailability(emp.ID, startDate, endDate) {
+			if av.Type == "unavailable" && len(av.TimeRanges) == 0 {
+				profile.DaysOffRequested = append(profile.DaysOffRequested, av.Date)
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+
+	report := stats.NewPreferenceAnalyzer().Analyze(startDate, endDate, profiles, assignments)
+	respondJSON(w, http.StatusOK, report)
+}
+
+// Availability 员工可用性登记/查询
+// 路由: PUT|GET /api/v1/employees/{employee_id}/availability
+// PUT 请求体为可用性列表，同一天的记录会被覆盖；请假管控期内超出名额的全天请假标记为待审核
+func (h *AnalyticsHandler) Availability(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	employeeID, err := uuid.Parse(r.PathValue("employee_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式"))
+		return
+	}
+	if !authorizeEmployee(w, r, h.store, employeeID) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		respondJSON(w, http.StatusOK, h.store.ListAvailability(employeeID, query.Get("start_date"), query.Get("end_date")))
+
+	case http.MethodPut:
+		var items []*model.EmployeeAvailability
+		if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		for _, av := range items {
+			if _, err := time.Parse("2006-01-02", av.Date); err != nil {
+				respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的日期格式: "+av.Date))
+				return
+			}
+			switch av.Type {
+			case "available", "unavailable", "preferred":
+			default:
+				respondError(w, errors.New(errors.CodeInvalidInput, "type 应为 available/unavailable/preferred"))
+				return
+			}
+		}
+		// 请假使用调休时校验余额（待审核的请假也占用余额）
+		if err := timebank.NewService(h.store).CheckSpend(employeeID, items); err != nil {
+			respondTimeBankError(w, err)
+			return
+		}
+		applyLeaveQuota(h.store, employeeID, items)
+		for _, av := range items {
+			av.EmployeeID = employeeID
+			h.store.PutAvailability(av)
+		}
+		respondJSON(w, http.StatusOK, h.store.ListAvailability(employeeID, "", ""))
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// availableHours 计算员工在周期内的可用工时
+// 每日默认可用 周工时上限/7 小时；不可用日计 0，登记了时间段的日期按时间段累计
+func (h *AnalyticsHandler) availableHours(emp *model.Employee, startDate, endDate string, days int) float64 {
+	weekly := float64(defaultWeeklyCapacity)
+	if emp.Preferences != nil && emp.Preferences.MaxHoursPerWeek > 0 {
+		weekly = float64(emp.Preferences.MaxHoursPerWeek)
+	}
+	daily := weekly / 7
+	total := daily * float64(days)
+
+	for _, av := range h.store.ListAvailability(emp.ID, startDate, endDate) {
+		switch {
+		case av.Type == "unavailable" && len(av.TimeRanges) == 0:
+			total -= daily
+		case len(av.TimeRanges) > 0:
+			hours := 0.0
+			for _, tr := range av.TimeRanges {
+				hours += tr.End.Sub(tr.Start).Hours()
+			}
+			if av.Type == "unavailable" {
+				hours = daily - hours
+			}
+			if hours < 0 {
+				hours = 0
+			}
+			if hours > daily {
+				hours = daily
+			}
+			total += hours - daily
+		}
+	}
+	if total < 0 {
+		total = 0
+	}
+	return total
+}
+
+// uniqueStrings 去重并保持顺序
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if !seen[item] {
+			seen[item] = true
+			result = append(result, item)
+		}
+	}
+	return result
+}
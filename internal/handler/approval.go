@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/approval"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ApprovalHandler 审批处理器
+type ApprovalHandler struct {
+	store   *memstore.Store
+	service *approval.Service
+}
+
+// NewApprovalHandler 创建审批处理器
+func NewApprovalHandler(store *memstore.Store, service *approval.Service) *ApprovalHandler {
+	return &ApprovalHandler{
+		store:   store,
+		service: service,
+	}
+}
+
+// SubmitApprovalRequest 提交审批请求
+type SubmitApprovalRequest struct {
+	Kind      string     `json:"kind"` // swap/overtime/schedule
+	SubjectID *uuid.UUID `json:"subject_id,omitempty"`
+	Title     string     `json:"title"`
+	Detail    string     `json:"detail,omitempty"`
+	Approver  string     `json:"approver"`
+
+	// 加班审批：加班员工、日期和时长，审批通过后按组织调休策略结算
+	EmployeeID *uuid.UUID `json:"employee_id,omitempty"`
+	Date       string     `json:"date,omitempty"`
+	Hours      float64    `json:"hours,omitempty"`
+}
+
+// ApprovalDecisionRequest 审批决定请求
+type ApprovalDecisionRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note,omitempty"`
+}
+
+// Approvals 查询/提交组织的审批单
+// 路由: GET|POST /api/v1/orgs/{org_id}/approvals
+// GET 支持 status、assigned_to 过滤；assigned_to=me 表示当前用户（X-User-ID）待处理的审批单。
+// POST 提交审批单，提交人取 X-User-ID，审批人外出时自动转交受托人
+func (h *ApprovalHandler) Approvals(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		assignedTo := query.Get("assigned_to")
+		if assignedTo == "me" {
+			assignedTo = r.Header.Get(AuthorHeader)
+		}
+		result := make([]*model.ApprovalRequest, 0)
+		for _, a := range h.store.ListApprovals(orgID, query.Get("status")) {
+			if assignedTo == "" || a.AssignedTo == assignedTo {
+				result = append(result, a)
+			}
+		}
+		respondJSON(w, http.StatusOK, result)
+
+	case http.MethodPost:
+		var req SubmitApprovalRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		a, err := h.service.Submit(r.Context(), &model.ApprovalRequest{
+			OrgID:       orgID,
+			Kind:        req.Kind,
+			SubjectID:   req.SubjectID,
+			Title:       req.Title,
+			Detail:      req.Detail,
+			Approver:    req.Approver,
+			EmployeeID:  req.EmployeeID,
+			Date:        req.Date,
+			Hours:       req.Hours,
+			RequestedBy: r.Header.Get(AuthorHeader),
+		})
+		if err != nil {
+			respondApprovalError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, a)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Approval 查询审批单（含流转记录和实际审批人）
+// 路由: GET /api/v1/approvals/{id}
+func (h *ApprovalHandler) Approval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	a, ok := h.approval(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, a)
+}
+
+// Decide 审批
+// 路由: POST /api/v1/approvals/{id}/decision
+// 审批人取 X-User-ID，须为当前处理人、原审批人或流转中接手过的审批人
+func (h *ApprovalHandler) Decide(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	pending, ok := h.approval(w, r)
+	if !ok {
+		return
+	}
+
+	var req ApprovalDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	a, err := h.service.Decide(r.Context(), pending.ID, r.Header.Get(AuthorHeader), req.Approve, req.Note)
+	if err != nil {
+		respondApprovalError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, a)
+}
+
+// Delegations 查询/新增组织的审批委托（新增需管理者）
+// 路由: GET|POST /api/v1/orgs/{org_id}/delegations
+// 委托人为空时取 X-User-ID；新增后立即按委托转交委托人名下的待审批单
+func (h *ApprovalHandler) Delegations(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, h.store.ListDelegations(orgID))
+
+	case http.MethodPost:
+		if !requireManager(w, r) {
+			return
+		}
+		var d model.ApprovalDelegation
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if d.Delegator == "" {
+			d.Delegator = r.Header.Get(AuthorHeader)
+		}
+		if err := d.Validate(); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+		d.ID = uuid.New()
+		d.OrgID = orgID
+		d.CreatedBy = r.Header.Get(AuthorHeader)
+		d.CreatedAt = time.Now()
+		if err := h.store.PutDelegation(&d); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "保存委托失败"))
+			return
+		}
+		h.service.Reroute(r.Context(), orgID)
+		respondJSON(w, http.StatusCreated, d)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// DeleteDelegation 撤销审批委托（需管理者），受托人名下的待审批单退回原审批人
+// 路由: DELETE /api/v1/orgs/{org_id}/delegations/{id}
+func (h *ApprovalHandler) DeleteDelegation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持DELETE方法"))
+		return
+	}
+	if !h.ready(w) || !requireManager(w, r) {
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的委托ID格式"))
+		return
+	}
+	if err := h.store.DeleteDelegation(orgID, id); err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "委托不存在"))
+		return
+	}
+	rerouted := h.service.Reroute(r.Context(), orgID)
+	respondJSON(w, http.StatusOK, map[string]int{"rerouted": rerouted})
+}
+
+// ApprovalPolicy 查询/设置组织审批策略（设置需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/approval-policy
+func (h *ApprovalHandler) ApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil || org.ApprovalPolicy == nil {
+			respondError(w, errors.New(errors.CodeNotFound, "组织未配置审批策略"))
+			return
+		}
+		respondJSON(w, http.StatusOK, org.ApprovalPolicy)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var policy model.ApprovalPolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := policy.Validate(); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil {
+			org = &model.Organization{BaseModel: model.NewBaseModel()}
+			org.ID = orgID
+		}
+		org.ApprovalPolicy = &policy
+		org.UpdatedAt = time.Now()
+		h.store.PutOrganization(org)
+		respondJSON(w, http.StatusOK, org.ApprovalPolicy)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// approval 解析路径中的审批单ID并获取审批单，检查调用方可访问审批单所属的组织
+func (h *ApprovalHandler) approval(w http.ResponseWriter, r *http.Request) (*model.ApprovalRequest, bool) {
+	if !h.ready(w) {
+		return nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的审批单ID格式"))
+		return nil, false
+	}
+	a, err := h.store.GetApproval(id)
+	if err != nil {
+		respondApprovalError(w, err)
+		return nil, false
+	}
+	if !authorizeOrg(w, r, a.OrgID) {
+		return nil, false
+	}
+	return a, true
+}
+
+// ready 检查是否启用了排班存储
+func (h *ApprovalHandler) ready(w http.ResponseWriter) bool {
+	if h.store == nil || h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// respondApprovalError 将审批服务错误转换为响应
+func respondApprovalError(w http.ResponseWriter, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "审批单不存在"))
+	case stderrors.Is(err, approval.ErrInvalidRequest):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	case stderrors.Is(err, approval.ErrNotAssigned):
+		respondError(w, errors.New(errors.CodeForbidden, err.Error()))
+	case stderrors.Is(err, approval.ErrDecided):
+		respondError(w, errors.New(errors.CodeAlreadyExists, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "处理审批失败"))
+	}
+}
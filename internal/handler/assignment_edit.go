@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/draft"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/stats"
+	"github.com/paiban/paiban/pkg/validator"
+)
+
+// AssignmentEditInput 单个分配的新增和修改请求，修改时只更新给出的字段
+// 基准版本优先取 If-Match 请求头，其次取 base_version，均未给出时以当前版本为准
+type AssignmentEditInput struct {
+	BaseVersion *int    `json:"base_version,omitempty"`
+	EmployeeID  *string `json:"employee_id,omitempty"`
+	ShiftID     *string `json:"shift_id,omitempty"`
+	Date        *string `json:"date,omitempty"`       // YYYY-MM-DD
+	StartTime   *string `json:"start_time,omitempty"` // HH:MM，未给出时取班次时间
+	EndTime     *string `json:"end_time,omitempty"`   // HH:MM，不晚于开始时间时视为跨天
+	Position    *string `json:"position,omitempty"`
+	Notes       *string `json:"notes,omitempty"`
+}
+
+// AssignmentEditMetrics 排班的覆盖和公平性指标
+// 覆盖按组织保存的排班期间需求计算
+type AssignmentEditMetrics struct {
+	Requirements  int     `json:"requirements"`
+	Filled        int     `json:"filled"`
+	Shortage      int     `json:"shortage"`  // 缺口人数
+	FillRate      float64 `json:"fill_rate"` // 需求满足率（百分比）
+	Assignments   int     `json:"assignment_count"`
+	TotalHours    float64 `json:"total_hours"`
+	WorkloadGini  float64 `json:"workload_gini"`
+	NightGini     float64 `json:"night_shift_gini"`
+	WeekendGini   float64 `json:"weekend_shift_gini"`
+	FairnessScore float64 `json:"fairness_score"`
+}
+
+// AssignmentEditResponse 单个分配修改的结果
+// conflicts 为修改后涉及员工的冲突检测结果（不阻止保存，供编辑界面提示），delta 为 after - before
+type AssignmentEditResponse struct {
+	Schedule  *model.Schedule        `json:"schedule"`
+	Change    model.AssignmentChange `json:"change"`
+	Conflicts []validator.Conflict   `json:"conflicts"`
+	Before    AssignmentEditMetrics  `json:"before"`
+	After     AssignmentEditMetrics  `json:"after"`
+	Delta     AssignmentEditMetrics  `json:"delta"`
+	DryRun    bool                   `json:"dry_run,omitempty"`
+}
+
+// AddAssignment 新增分配
+// 路由: POST /api/v1/schedules/{id}/assignments[?dry_run=true]
+// employee_id、shift_id、date 必填；dry_run 只返回冲突和指标变化，不保存
+func (h *DraftHandler) AddAssignment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+	var input AssignmentEditInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if input.EmployeeID == nil || input.ShiftID == nil || input.Date == nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "employee_id、shift_id、date 不能为空"))
+		return
+	}
+
+	a := &model.Assignment{}
+	if appErr := h.applyAssignmentInput(&input, a); appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	h.editAssignment(w, r, id, input.BaseVersion, model.AssignmentChange{Op: model.ChangeAdd, Assignment: a}, http.StatusCreated)
+}
+
+// Assignment 修改或删除单个分配（更换员工、班次或时间）
+// 路由: PATCH|DELETE /api/v1/schedules/{id}/assignments/{assignment_id}[?dry_run=true]
+func (h *DraftHandler) Assignment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch && r.Method != http.MethodDelete {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持PATCH/DELETE方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+	assignmentID, err := uuid.Parse(r.PathValue("assignment_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的分配ID格式"))
+		return
+	}
+	var input AssignmentEditInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+	}
+
+	if r.Method == http.MethodDelete {
+		h.editAssignment(w, r, id, input.BaseVersion, model.AssignmentChange{Op: model.ChangeRemove, AssignmentID: assignmentID}, http.StatusOK)
+		return
+	}
+
+	schedule, err := h.store.GetSchedule(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+	var a *model.Assignment
+	for i := range schedule.Assignments {
+		if schedule.Assignments[i].ID == assignmentID {
+			a = &schedule.Assignments[i]
+			break
+		}
+	}
+	if a == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "分配不存在"))
+		return
+	}
+	if appErr := h.applyAssignmentInput(&input, a); appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	h.editAssignment(w, r, id, input.BaseVersion, model.AssignmentChange{Op: model.ChangeUpdate, AssignmentID: assignmentID, Assignment: a}, http.StatusOK)
+}
+
+// editAssignment 试算变更的冲突和指标变化，非 dry_run 时以乐观并发方式保存
+func (h *DraftHandler) editAssignment(w http.ResponseWriter, r *http.Request, id uuid.UUID, baseVersion *int, change model.AssignmentChange, status int) {
+	current, err := h.store.GetSchedule(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+	if current.Status != "draft" {
+		respondError(w, errors.New(errors.CodeScheduleConflict, draft.ErrNotDraft.Error()))
+		return
+	}
+	version := current.Version
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if version, err = parseVersionETag(ifMatch); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的 If-Match"))
+			return
+		}
+	} else if baseVersion != nil {
+		version = *baseVersion
+	}
+
+	// 在排班副本上试算，得到修改后的分配和补全ID的变更
+	preview, err := h.store.GetSchedule(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+	applied, err := draft.ApplyChanges(preview, []model.AssignmentChange{change})
+	if err != nil {
+		h.respondEditError(w, id, err)
+		return
+	}
+	change = applied[0]
+
+	employees := h.store.ListEmployees(current.OrgID)
+	resp := &AssignmentEditResponse{
+		Schedule:  preview,
+		Change:    change,
+		Conflicts: h.assignmentConflicts(current, preview, change, employees),
+		Before:    h.assignmentMetrics(current, employees),
+		After:     h.assignmentMetrics(preview, employees),
+		DryRun:    r.URL.Query().Get("dry_run") == "true",
+	}
+	resp.Delta = resp.After.sub(resp.Before)
+
+	if !resp.DryRun {
+		schedule, err := h.editor.Edit(id, version, r.Header.Get(AuthorHeader), []model.AssignmentChange{change})
+		if err != nil {
+			h.respondEditError(w, id, err)
+			return
+		}
+		resp.Schedule = schedule
+		recordAudit(w, r, schedule.OrgID, model.AuditAssignmentEdit, "schedule", id.String(), map[string]interface{}{
+			"base_version": version,
+			"version":      schedule.Version,
+			"changes":      []model.AssignmentChange{change},
+			"conflicts":    len(resp.Conflicts),
+		})
+		w.Header().Set("ETag", versionETag(schedule.Version))
+	}
+	respondJSON(w, status, resp)
+}
+
+// applyAssignmentInput 将请求中给出的字段写入分配
+// 更换班次或日期而未给出时间时，按班次的上下班时间重新计算起止时间
+func (h *DraftHandler) applyAssignmentInput(in *AssignmentEditInput, a *model.Assignment) *errors.AppError {
+	if in.EmployeeID != nil {
+		id, err := uuid.Parse(*in.EmployeeID)
+		if err != nil {
+			return errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式")
+		}
+		if _, err := h.store.GetEmployee(id); err != nil {
+			return errors.New(errors.CodeInvalidInput, "员工不存在: "+*in.EmployeeID)
+		}
+		a.EmployeeID = id
+	}
+	if in.ShiftID != nil {
+		id, err := uuid.Parse(*in.ShiftID)
+		if err != nil {
+			return errors.Wrap(err, errors.CodeInvalidInput, "无效的班次ID格式")
+		}
+		a.ShiftID = id
+	}
+	if in.Date != nil {
+		if _, err := time.Parse("2006-01-02", *in.Date); err != nil {
+			return errors.New(errors.CodeInvalidInput, "无效的日期格式，应为 YYYY-MM-DD")
+		}
+		a.Date = *in.Date
+	}
+	if in.Position != nil {
+		a.Position = strings.TrimSpace(*in.Position)
+	}
+	if in.Notes != nil {
+		a.Notes = *in.Notes
+	}
+
+	start, end := a.StartTime.Format("15:04"), a.EndTime.Format("15:04")
+	if in.ShiftID != nil || in.Date != nil || a.StartTime.IsZero() {
+		if shift, err := h.store.GetShift(a.ShiftID); err == nil {
+			start, end = shift.StartTime, shift.EndTime
+		} else if in.StartTime == nil || in.EndTime == nil {
+			return errors.New(errors.CodeInvalidInput, "班次不存在，需给出 start_time 和 end_time")
+		}
+	}
+	if in.StartTime != nil {
+		start = *in.StartTime
+	}
+	if in.EndTime != nil {
+		end = *in.EndTime
+	}
+	_, err1 := time.Parse("15:04", start)
+	_, err2 := time.Parse("15:04", end)
+	if err1 != nil || err2 != nil {
+		return errors.New(errors.CodeInvalidInput, "无效的时间格式，应为 HH:MM")
+	}
+	a.StartTime, a.EndTime = assignmentClock(a.Date, start, end)
+	return nil
+}
+
+// assignmentConflicts 检测修改后涉及员工（新员工和原员工）的冲突
+func (h *DraftHandler) assignmentConflicts(before, after *model.Schedule, change model.AssignmentChange, employees []*model.Employee) []validator.Conflict {
+	affected := make(map[uuid.UUID]bool)
+	if change.Assignment != nil {
+		affected[change.Assignment.EmployeeID] = true
+	}
+	for _, a := range before.Assignments {
+		if a.ID == change.AssignmentID {
+			affected[a.EmployeeID] = true
+		}
+	}
+
+	empMap := make(map[uuid.UUID]*model.Employee)
+	for _, e := range employees {
+		if affected[e.ID] {
+			empMap[e.ID] = e
+		}
+	}
+	list := make([]*model.Assignment, 0)
+	for i := range after.Assignments {
+		a := &after.Assignments[i]
+		if !affected[a.EmployeeID] {
+			continue
+		}
+		if empMap[a.EmployeeID] == nil {
+			empMap[a.EmployeeID] = &model.Employee{BaseModel: model.BaseModel{ID: a.EmployeeID}}
+		}
+		list = append(list, a)
+	}
+	conflicts := validator.NewConflictDetector(nil).DetectAll(list, empMap)
+	if conflicts == nil {
+		conflicts = make([]validator.Conflict, 0)
+	}
+	return conflicts
+}
+
+// assignmentMetrics 计算排班的覆盖和公平性指标
+func (h *DraftHandler) assignmentMetrics(schedule *model.Schedule, employees []*model.Employee) AssignmentEditMetrics {
+	m := AssignmentEditMetrics{Assignments: len(schedule.Assignments)}
+	assignments := make([]*model.Assignment, len(schedule.Assignments))
+	list := make([]*stats.AssignmentInfo, len(schedule.Assignments))
+	for i := range schedule.Assignments {
+		a := &schedule.Assignments[i]
+		assignments[i] = a
+		m.TotalHours += a.WorkingHours()
+		list[i] = &stats.AssignmentInfo{ShiftID: a.ShiftID.String(), EmployeeID: a.EmployeeID.String(), Date: a.Date, StartTime: a.StartTime, EndTime: a.EndTime}
+	}
+	m.TotalHours = math.Round(m.TotalHours*100) / 100
+
+	requirements := h.store.ListRequirements(schedule.OrgID, schedule.StartDate, schedule.EndDate)
+	unfilled := calculateUnfilledRequirements(requirements, assignments, nil, nil)
+	m.Requirements, m.Filled = len(requirements), len(requirements)-len(unfilled)
+	for _, u := range unfilled {
+		m.Shortage += u.Shortage
+	}
+	if m.Requirements > 0 {
+		m.FillRate = math.Round(float64(m.Filled)/float64(m.Requirements)*10000) / 100
+	}
+
+	infos := make([]*stats.EmployeeInfo, len(employees))
+	for i, e := range employees {
+		infos[i] = &stats.EmployeeInfo{ID: e.ID.String(), Name: e.Name}
+	}
+	f := stats.NewFairnessAnalyzer().Analyze(list, infos)
+	m.WorkloadGini, m.NightGini, m.WeekendGini = f.WorkloadGini, f.NightShiftGini, f.WeekendShiftGini
+	m.FairnessScore = math.Round(f.OverallFairnessScore*100) / 100
+	return m
+}
+
+// sub 指标变化（m - base）
+func (m AssignmentEditMetrics) sub(base AssignmentEditMetrics) AssignmentEditMetrics {
+	round := func(v float64) float64 { return math.Round(v*10000) / 10000 }
+	return AssignmentEditMetrics{
+		Requirements:  m.Requirements - base.Requirements,
+		Filled:        m.Filled - base.Filled,
+		Shortage:      m.Shortage - base.Shortage,
+		FillRate:      round(m.FillRate - base.FillRate),
+		Assignments:   m.Assignments - base.Assignments,
+		TotalHours:    round(m.TotalHours - base.TotalHours),
+		WorkloadGini:  round(m.WorkloadGini - base.WorkloadGini),
+		NightGini:     round(m.NightGini - base.NightGini),
+		WeekendGini:   round(m.WeekendGini - base.WeekendGini),
+		FairnessScore: round(m.FairnessScore - base.FairnessScore),
+	}
+}
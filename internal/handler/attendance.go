@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/stats"
+)
+
+// AttendanceVarianceRequest 打卡差异报告请求
+// 计划来源：派出服务订单（客户地址）和/或门店排班（员工所属门店地址）；
+// 打卡来源：服务记录的签到时间与位置，和/或考勤系统导出的打卡记录
+type AttendanceVarianceRequest struct {
+	OrgID                string  `json:"org_id"`
+	RadiusMeters         float64 `json:"radius_meters,omitempty"`          // 允许的打卡半径，默认 200 米
+	LateToleranceMinutes *int    `json:"late_tolerance_minutes,omitempty"` // 迟到容忍时间，默认 5 分钟
+	Timezone             string  `json:"timezone,omitempty"`               // 计划时间所在时区（IANA），默认服务器时区
+
+	Orders         []*model.ServiceOrder     `json:"orders,omitempty"`
+	Assignments    []*model.Assignment       `json:"assignments,omitempty"`
+	Employees      []*model.Employee         `json:"employees,omitempty"`
+	StoreLocations map[string]model.Location `json:"store_locations,omitempty"` // 门店ID -> 门店位置
+
+	ServiceRecords []model.ServiceRecord `json:"service_records,omitempty"`
+	ClockIns       []*stats.ClockIn      `json:"clock_ins,omitempty"`
+}
+
+// AttendanceVarianceResponse 打卡差异报告响应
+type AttendanceVarianceResponse struct {
+	Success bool                 `json:"success"`
+	Data    *stats.CheckInReport `json:"data,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+// GetAttendanceVarianceHandler 打卡差异报告API
+// 核验签到位置是否在计划地点的允许半径内，标记位置不符、未打卡和迟到，并给出每名员工的合规率
+func GetAttendanceVarianceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AttendanceVarianceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSONError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.RadiusMeters < 0 {
+		sendJSONError(w, "radius_meters 不能为负数", http.StatusBadRequest)
+		return
+	}
+
+	loc := time.Local
+	if req.Timezone != "" {
+		tz, err := time.LoadLocation(req.Timezone)
+		if err != nil {
+			sendJSONError(w, "无效的时区: "+req.Timezone, http.StatusBadRequest)
+			return
+		}
+		loc = tz
+	}
+	tolerance := 5
+	if req.LateToleranceMinutes != nil {
+		tolerance = *req.LateToleranceMinutes
+	}
+
+	visits := scheduledVisits(&req, loc)
+	clockIns := make([]*stats.ClockIn, 0, len(req.ClockIns)+len(req.ServiceRecords))
+	for _, c := range req.ClockIns {
+		c.Time = c.Time.In(loc)
+		clockIns = append(clockIns, c)
+	}
+	for _, rec := range req.ServiceRecords {
+		if rec.CheckInTime == nil {
+			continue
+		}
+		clockIns = append(clockIns, &stats.ClockIn{
+			VisitID:    rec.OrderID.String(),
+			EmployeeID: rec.EmployeeID.String(),
+			Time:       rec.CheckInTime.In(loc),
+			Location:   toGeoPoint(rec.CheckInLoc),
+		})
+	}
+
+	log.Printf("接收打卡差异分析请求: org_id=%s, visits=%d, clock_ins=%d",
+		req.OrgID, len(visits), len(clockIns))
+
+	report := stats.NewCheckInAnalyzer(req.RadiusMeters, tolerance).Analyze(visits, clockIns)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AttendanceVarianceResponse{Success: true, Data: report})
+}
+
+// scheduledVisits 将订单和排班转换为待核验的计划工作
+// 团队订单为每名成员各生成一条；排班使用员工所属门店的位置，门店无位置时仅核验是否打卡
+func scheduledVisits(req *AttendanceVarianceRequest, loc *time.Location) []*stats.ScheduledVisit {
+	names := make(map[uuid.UUID]string, len(req.Employees))
+	stores := make(map[uuid.UUID]string, len(req.Employees))
+	for _, e := range req.Employees {
+		names[e.ID] = e.Name
+		stores[e.ID] = e.StoreID
+	}
+
+	visits := make([]*stats.ScheduledVisit, 0, len(req.Orders)+len(req.Assignments))
+	for _, o := range req.Orders {
+		if o.Status == "cancelled" {
+			continue
+		}
+		start, _ := time.ParseInLocation("2006-01-02 15:04", o.ServiceDate+" "+o.StartTime, loc)
+		crew := o.EmployeeIDs
+		if o.EmployeeID != nil && !containsUUID(crew, *o.EmployeeID) {
+			crew = append([]uuid.UUID{*o.EmployeeID}, crew...)
+		}
+		for _, id := range crew {
+			visits = append(visits, &stats.ScheduledVisit{
+				ID:           o.ID.String(),
+				EmployeeID:   id.String(),
+				EmployeeName: names[id],
+				Date:         o.ServiceDate,
+				StartTime:    start,
+				Location:     toGeoPoint(o.Location),
+			})
+		}
+	}
+
+	for _, a := range req.Assignments {
+		visit := &stats.ScheduledVisit{
+			ID:           a.ID.String(),
+			EmployeeID:   a.EmployeeID.String(),
+			EmployeeName: names[a.EmployeeID],
+			Date:         a.Date,
+			StartTime:    a.StartTime,
+		}
+		if store, ok := req.StoreLocations[stores[a.EmployeeID]]; ok {
+			visit.Location = toGeoPoint(&store)
+		}
+		visits = append(visits, visit)
+	}
+	return visits
+}
+
+// toGeoPoint 转换位置为stats包类型
+func toGeoPoint(l *model.Location) *stats.GeoPoint {
+	if l == nil {
+		return nil
+	}
+	return &stats.GeoPoint{Latitude: l.Latitude, Longitude: l.Longitude}
+}
+
+// containsUUID 检查ID列表是否包含指定ID
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 审计日志存储：配置数据库时写入 audit_logs 表，否则写入内存存储；均未配置时不记录
+var (
+	auditRepo  repository.AuditRepositoryInterface
+	auditStore *memstore.Store
+)
+
+// SetAuditRepository 设置审计日志仓储，设置后审计日志写入数据库
+func SetAuditRepository(repo *repository.AuditRepository) {
+	if repo != nil {
+		auditRepo = repo
+	}
+}
+
+// SetAuditStore 设置审计日志的内存存储（未配置数据库时使用）
+func SetAuditStore(store *memstore.Store) {
+	auditStore = store
+}
+
+// AuditListResponse 审计日志查询响应
+type AuditListResponse struct {
+	Logs   []*model.AuditLog `json:"logs"`
+	Total  int               `json:"total"`
+	Offset int               `json:"offset"`
+	Limit  int               `json:"limit"`
+}
+
+// AuditHandler 查询审计日志（管理者），按时间倒序
+// from/to 为 RFC3339 时间或 YYYY-MM-DD 日期（to 为日期时包含当天）
+// 路由: GET /api/v1/audit?org_id=&from=&to=[&action=][&actor=][&request_id=][&offset=][&limit=]
+func AuditHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if auditRepo == nil && auditStore == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用审计日志存储"))
+		return
+	}
+	if !requireManager(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	orgID, err := uuid.Parse(query.Get("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	filter := model.AuditFilter{
+		OrgID:     orgID,
+		Action:    query.Get("action"),
+		Actor:     query.Get("actor"),
+		RequestID: query.Get("request_id"),
+		Limit:     100,
+	}
+	if filter.From, err = parseAuditTime(query.Get("from"), false); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的 from，应为 RFC3339 时间或 YYYY-MM-DD"))
+		return
+	}
+	if filter.To, err = parseAuditTime(query.Get("to"), true); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的 to，应为 RFC3339 时间或 YYYY-MM-DD"))
+		return
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		filter.Offset = offset
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= 1000 {
+		filter.Limit = limit
+	}
+
+	var logs []*model.AuditLog
+	var total int
+	if auditRepo != nil {
+		logs, total, err = auditRepo.List(r.Context(), filter)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询审计日志失败"))
+			return
+		}
+	} else {
+		logs, total = auditStore.ListAuditLogs(filter)
+	}
+	respondJSON(w, http.StatusOK, AuditListResponse{Logs: logs, Total: total, Offset: filter.Offset, Limit: filter.Limit})
+}
+
+// parseAuditTime 解析查询时间，日期作为结束时间时取次日零点
+func parseAuditTime(v string, end bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// recordAudit 记录审计日志：操作人和角色取 X-User-ID、X-User-Role 请求头，
+// 请求ID取请求ID中间件设置的 X-Request-ID 响应头，其次为请求头；写入失败只记录日志，不影响操作结果
+func recordAudit(w http.ResponseWriter, r *http.Request, orgID uuid.UUID, action, resourceType, resourceID string, detail map[string]interface{}) {
+	if auditRepo == nil && auditStore == nil {
+		return
+	}
+	requestID := w.Header().Get("X-Request-ID")
+	if requestID == "" {
+		requestID = r.Header.Get("X-Request-ID")
+	}
+	entry := &model.AuditLog{
+		ID:           uuid.New(),
+		OrgID:        orgID,
+		Actor:        r.Header.Get(AuthorHeader),
+		Role:         r.Header.Get(RoleHeader),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		RequestID:    requestID,
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		Detail:       detail,
+		CreatedAt:    time.Now(),
+	}
+
+	var err error
+	if auditRepo != nil {
+		// 请求已结束或取消时仍写入审计日志
+		err = auditRepo.Create(context.WithoutCancel(r.Context()), entry)
+	} else {
+		err = auditStore.AddAuditLog(entry)
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("action", action).Str("request_id", requestID).Msg("记录审计日志失败")
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/backfill"
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// maxBackfillRecords 单次回填的最大记录数
+const maxBackfillRecords = 200000
+
+// BackfillHandler 历史排班回填处理器
+type BackfillHandler struct {
+	service *backfill.Service
+}
+
+// NewBackfillHandler 创建历史排班回填处理器
+func NewBackfillHandler(service *backfill.Service) *BackfillHandler {
+	return &BackfillHandler{service: service}
+}
+
+// BackfillRequest 历史排班回填请求
+type BackfillRequest struct {
+	Records          []backfill.Record `json:"records"`
+	ToleranceMinutes int               `json:"tolerance_minutes,omitempty"` // 聚类容差，默认 30 分钟
+	Timezone         string            `json:"timezone,omitempty"`          // 上下班时间所在时区（IANA），默认服务器时区
+	DryRun           bool              `json:"dry_run,omitempty"`           // 只返回推断结果，不写入
+}
+
+// Backfill 从仅含分配的历史数据推断班次定义并回填历史排班（需管理者）
+// 路由: POST /api/v1/orgs/{org_id}/backfill
+func (h *BackfillHandler) Backfill(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	if !requireManager(w, r) {
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	var req BackfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if len(req.Records) == 0 {
+		respondError(w, errors.New(errors.CodeInvalidInput, "records 不能为空"))
+		return
+	}
+	if len(req.Records) > maxBackfillRecords {
+		respondError(w, errors.New(errors.CodeInvalidInput, "单次回填记录过多，请按年份分批导入"))
+		return
+	}
+	if req.ToleranceMinutes < 0 || req.ToleranceMinutes > 120 {
+		respondError(w, errors.New(errors.CodeInvalidInput, "tolerance_minutes 应在 0-120 之间"))
+		return
+	}
+
+	opts := backfill.Options{Tolerance: req.ToleranceMinutes, DryRun: req.DryRun}
+	if req.Timezone != "" {
+		loc, err := time.LoadLocation(req.Timezone)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的时区: "+req.Timezone))
+			return
+		}
+		opts.Location = loc
+	}
+
+	result, err := h.service.Import(orgID, req.Records, opts)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "回填历史排班失败"))
+		return
+	}
+	respondJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// BlackoutHandler 请假管控期处理器
+type BlackoutHandler struct {
+	store *memstore.Store
+}
+
+// NewBlackoutHandler 创建请假管控期处理器
+func NewBlackoutHandler(store *memstore.Store) *BlackoutHandler {
+	return &BlackoutHandler{store: store}
+}
+
+// LeaveReviewRequest 请假审核请求
+type LeaveReviewRequest struct {
+	Approved bool   `json:"approved"`
+	Note     string `json:"note,omitempty"`
+}
+
+// BlackoutDayUsage 管控期某天的请假情况
+type BlackoutDayUsage struct {
+	Date          string `json:"date"`
+	Allowed       int    `json:"allowed"`        // 每日请假名额
+	Requested     int    `json:"requested"`      // 申请请假人数（含待审核和已驳回）
+	Approved      int    `json:"approved"`       // 已批准人数
+	PendingReview int    `json:"pending_review"` // 待审核人数
+	Rejected      int    `json:"rejected"`       // 已驳回人数
+	OverQuota     bool   `json:"over_quota"`     // 已批准人数超出名额（管理者审核后放行）
+}
+
+// PendingLeave 待审核的请假
+type PendingLeave struct {
+	EmployeeID   string `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	Date         string `json:"date"`
+	Reason       string `json:"reason,omitempty"`
+	ReviewNote   string `json:"review_note,omitempty"`
+}
+
+// BlackoutReport 管控期请假报告
+type BlackoutReport struct {
+	Period        *model.BlackoutPeriod `json:"period"`
+	AllowedDays   int                   `json:"allowed_days"`   // 名额合计（人·天）
+	RequestedDays int                   `json:"requested_days"` // 申请合计（人·天）
+	ApprovedDays  int                   `json:"approved_days"`
+	PendingReview int                   `json:"pending_review"`
+	Rejected      int                   `json:"rejected"`
+	Days          []BlackoutDayUsage    `json:"days"`
+	Pending       []PendingLeave        `json:"pending"`
+}
+
+// Periods 查询/替换组织的请假管控期（替换需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/blackout-periods
+// PUT 请求体为完整的管控期列表，会覆盖该组织已有管控期；已提交的请假不会重新审核
+func (h *BlackoutHandler) Periods(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, h.store.ListBlackoutPeriods(orgID))
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var periods []*model.BlackoutPeriod
+		if err := json.NewDecoder(r.Body).Decode(&periods); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		now := time.Now()
+		for _, p := range periods {
+			if p == nil {
+				respondError(w, errors.New(errors.CodeInvalidInput, "管控期不能为空"))
+				return
+			}
+			if err := p.Validate(); err != nil {
+				respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+				return
+			}
+			p.UpdatedAt = now
+		}
+		if err := h.store.ReplaceBlackoutPeriods(orgID, periods); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "保存管控期失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, h.store.ListBlackoutPeriods(orgID))
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// Report 管控期请假申请与名额对比报告
+// 路由: GET /api/v1/orgs/{org_id}/blackout-periods/report?start_date=&end_date=
+// 日期范围为空时报告全部管控期，否则只报告与范围有交集的管控期
+func (h *BlackoutHandler) Report(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	query := r.URL.Query()
+	startDate, endDate := query.Get("start_date"), query.Get("end_date")
+
+	employees := h.store.ListEmployees(orgID)
+	reports := make([]*BlackoutReport, 0)
+	for _, p := range h.store.ListBlackoutPeriods(orgID) {
+		if (startDate != "" && p.EndDate < startDate) || (endDate != "" && p.StartDate > endDate) {
+			continue
+		}
+		reports = append(reports, h.report(p, employees))
+	}
+	respondJSON(w, http.StatusOK, reports)
+}
+
+// report 统计单个管控期的请假情况
+func (h *BlackoutHandler) report(p *model.BlackoutPeriod, employees []*model.Employee) *BlackoutReport {
+	report := &BlackoutReport{
+		Period:  p,
+		Days:    make([]BlackoutDayUsage, 0),
+		Pending: make([]PendingLeave, 0),
+	}
+	days := make(map[string]*BlackoutDayUsage)
+	start, _ := time.Parse("2006-01-02", p.StartDate)
+	end, _ := time.Parse("2006-01-02", p.EndDate)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		report.Days = append(report.Days, BlackoutDayUsage{Date: date, Allowed: p.MaxLeavePerDay})
+		report.AllowedDays += p.MaxLeavePerDay
+	}
+	for i := range report.Days {
+		days[report.Days[i].Date] = &report.Days[i]
+	}
+
+	for _, emp := range employees {
+		if !p.AppliesTo(emp.StoreID) {
+			continue
+		}
+		for _, av := range h.store.ListAvailability(emp.ID, p.StartDate, p.EndDate) {
+			day := days[av.Date]
+			if day == nil || !av.IsLeave() {
+				continue
+			}
+			day.Requested++
+			report.RequestedDays++
+			switch av.ReviewStatus {
+			case model.LeavePendingReview:
+				day.PendingReview++
+				report.PendingReview++
+				report.Pending = append(report.Pending, PendingLeave{
+					EmployeeID:   emp.ID.String(),
+					EmployeeName: emp.Name,
+					Date:         av.Date,
+					Reason:       av.Reason,
+					ReviewNote:   av.ReviewNote,
+				})
+			case model.LeaveRejected:
+				day.Rejected++
+				report.Rejected++
+			default:
+				day.Approved++
+				report.ApprovedDays++
+			}
+		}
+	}
+	for i := range report.Days {
+		report.Days[i].OverQuota = report.Days[i].Approved > report.Days[i].Allowed
+	}
+	sort.Slice(report.Pending, func(i, j int) bool {
+		if report.Pending[i].Date != report.Pending[j].Date {
+			return report.Pending[i].Date < report.Pending[j].Date
+		}
+		return report.Pending[i].EmployeeName < report.Pending[j].EmployeeName
+	})
+	return report
+}
+
+// ReviewLeave 管理者审核请假
+// 路由: POST /api/v1/employees/{employee_id}/availability/{date}/review
+func (h *BlackoutHandler) ReviewLeave(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	if !requireManager(w, r) {
+		return
+	}
+	employeeID, err := uuid.Parse(r.PathValue("employee_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式"))
+		return
+	}
+	date := r.PathValue("date")
+	var req LeaveReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+
+	items := h.store.ListAvailability(employeeID, date, date)
+	if len(items) == 0 || !items[0].IsLeave() {
+		respondError(w, errors.New(errors.CodeNotFound, "该员工当日没有请假记录"))
+		return
+	}
+	av := items[0]
+	av.ReviewStatus = model.LeaveRejected
+	if req.Approved {
+		av.ReviewStatus = model.LeaveApproved
+	}
+	av.ReviewNote = req.Note
+	if err := h.store.PutAvailability(av); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "保存审核结果失败"))
+		return
+	}
+	respondJSON(w, http.StatusOK, av)
+}
+
+// applyLeaveQuota 按请假管控期为员工提交的可用性设置审核状态
+// 管控期内某天已批准的请假人数达到名额时，新的全天请假标记为待审核；非请假记录清除审核状态
+// 员工不在存储中时无法确定所属组织，不做处理
+func applyLeaveQuota(store *memstore.Store, employeeID uuid.UUID, items []*model.EmployeeAvailability) {
+	for _, av := range items {
+		av.ReviewStatus, av.ReviewNote = "", ""
+	}
+	emp, err := store.GetEmployee(employeeID)
+	if err != nil {
+		return
+	}
+	periods := store.ListBlackoutPeriods(emp.OrgID)
+	if len(periods) == 0 {
+		return
+	}
+
+	var colleagues []*model.Employee
+	for _, av := range items {
+		if !av.IsLeave() {
+			continue
+		}
+		for _, p := range periods {
+			if !p.Contains(av.Date) || !p.AppliesTo(emp.StoreID) {
+				continue
+			}
+			if colleagues == nil {
+				colleagues = store.ListEmployees(emp.OrgID)
+			}
+			approved := 0
+			for _, c := range colleagues {
+				if c.ID == employeeID || !p.AppliesTo(c.StoreID) {
+					continue
+				}
+				for _, other := range store.ListAvailability(c.ID, av.Date, av.Date) {
+					if other.IsApprovedLeave() {
+						approved++
+					}
+				}
+			}
+			if approved >= p.MaxLeavePerDay {
+				av.ReviewStatus = model.LeavePendingReview
+				av.ReviewNote = fmt.Sprintf("管控期「%s」每日请假名额 %d 人已满", p.Name, p.MaxLeavePerDay)
+				break
+			}
+			av.ReviewStatus = model.LeaveApproved
+		}
+	}
+}
+
+// boostBlackoutRequirements 提升管控期内排班需求的优先级，返回生效的管控期名称
+// 门店管控期仅在请求中包含该门店员工时生效；同一需求命中多个管控期时取最大提升值
+func boostBlackoutRequirements(periods []*model.BlackoutPeriod, employees []*model.Employee, requirements []*model.ShiftRequirement) []string {
+	stores := make(map[string]bool)
+	for _, e := range employees {
+		stores[e.StoreID] = true
+	}
+
+	applied := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, req := range requirements {
+		boost := 0
+		for _, p := range periods {
+			if !p.Contains(req.Date) || (p.StoreID != "" && !stores[p.StoreID]) {
+				continue
+			}
+			if p.Boost() > boost {
+				boost = p.Boost()
+			}
+			if !seen[p.Name] {
+				seen[p.Name] = true
+				applied = append(applied, p.Name)
+			}
+		}
+		if boost > 0 {
+			req.Priority += boost
+			if req.Priority > 10 {
+				req.Priority = 10
+			}
+		}
+	}
+	return applied
+}
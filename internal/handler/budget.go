@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// BudgetHandler 门店工时预算处理器
+type BudgetHandler struct {
+	store *memstore.Store
+}
+
+// NewBudgetHandler 创建门店工时预算处理器
+func NewBudgetHandler(store *memstore.Store) *BudgetHandler {
+	return &BudgetHandler{store: store}
+}
+
+// StoreBudgets 查询/替换组织的门店每周工时预算（替换需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/store-budgets
+// PUT 请求体为完整的预算列表，会覆盖该组织已有预算；week_start 会规整为所在周的周日
+func (h *BudgetHandler) StoreBudgets(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, h.store.ListStoreBudgets(orgID))
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var budgets []*model.StoreHoursBudget
+		if err := json.NewDecoder(r.Body).Decode(&budgets); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := normalizeStoreBudgets(budgets); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+		now := time.Now()
+		for _, b := range budgets {
+			b.UpdatedAt = now
+		}
+		if err := h.store.ReplaceStoreBudgets(orgID, budgets); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "保存预算失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, h.store.ListStoreBudgets(orgID))
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// normalizeStoreBudgets 校验预算并将 week_start 规整为周日，同一门店同一周只能有一条预算
+func normalizeStoreBudgets(budgets []*model.StoreHoursBudget) error {
+	seen := make(map[string]bool, len(budgets))
+	for _, b := range budgets {
+		if b == nil || b.StoreID == "" {
+			return fmt.Errorf("门店ID不能为空")
+		}
+		if b.Hours <= 0 {
+			return fmt.Errorf("门店 %s 的预算工时必须大于0", b.StoreID)
+		}
+		if b.WeekStart != "" {
+			if _, err := time.Parse("2006-01-02", b.WeekStart); err != nil {
+				return fmt.Errorf("无效的周起始日: %s", b.WeekStart)
+			}
+			b.WeekStart = model.BudgetWeekStart(b.WeekStart)
+		}
+		key := b.StoreID + "|" + b.WeekStart
+		if seen[key] {
+			return fmt.Errorf("门店 %s 在周 %q 有重复预算", b.StoreID, b.WeekStart)
+		}
+		seen[key] = true
+	}
+	return nil
+}
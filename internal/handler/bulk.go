@@ -0,0 +1,484 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/backfill"
+	"github.com/paiban/paiban/internal/bulk"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/dispatcher"
+	"github.com/paiban/paiban/pkg/dispatcher/constraint"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// maxBulkRows 单个批量作业的最大行数
+const maxBulkRows = 1000000
+
+// BulkHandler 批量作业处理器
+type BulkHandler struct {
+	store  *memstore.Store
+	runner *bulk.Runner
+}
+
+// NewBulkHandler 创建批量作业处理器
+func NewBulkHandler(store *memstore.Store, runner *bulk.Runner) *BulkHandler {
+	return &BulkHandler{
+		store:  store,
+		runner: runner,
+	}
+}
+
+// SubmitBulkJobRequest 提交批量作业请求
+// params 为各批次共用的参数：
+//   - import：rows 为历史分配记录，params 为 {"tolerance_minutes", "timezone"}
+//   - dispatch：rows 为服务订单，params 为 {"candidates", "customer", "keep_apart"}
+//   - validate：rows 为排班验证请求（org_id 为空时取作业的组织）
+type SubmitBulkJobRequest struct {
+	Kind      string            `json:"kind"`
+	OrgID     string            `json:"org_id"`
+	BatchSize int               `json:"batch_size,omitempty"` // 默认 500
+	Params    json.RawMessage   `json:"params,omitempty"`
+	Rows      []json.RawMessage `json:"rows"`
+}
+
+// BulkImportParams 导入作业参数
+type BulkImportParams struct {
+	ToleranceMinutes int    `json:"tolerance_minutes,omitempty"`
+	Timezone         string `json:"timezone,omitempty"`
+}
+
+// BulkDispatchParams 批量派单作业参数
+type BulkDispatchParams struct {
+	Candidates []*model.Employee     `json:"candidates"`
+	Customer   *model.Customer       `json:"customer,omitempty"`
+	KeepApart  []model.KeepApartRule `json:"keep_apart,omitempty"`
+
+	Constraints model.DispatchConstraintConfig `json:"constraints,omitempty"` // 派单约束配置，覆盖组织保存的配置
+}
+
+// BulkResultItem 作业结果行
+type BulkResultItem struct {
+	Row    int             `json:"row"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Errors []string        `json:"errors,omitempty"`
+}
+
+// BulkResultsResponse 作业结果分页响应
+type BulkResultsResponse struct {
+	JobID  uuid.UUID        `json:"job_id"`
+	Status string           `json:"status"`
+	Total  int              `json:"total"`
+	Offset int              `json:"offset"`
+	Items  []BulkResultItem `json:"items"`
+}
+
+// RegisterBulkProcessors 注册各类批量作业的批次处理函数
+func RegisterBulkProcessors(runner *bulk.Runner, schedule *ScheduleHandler, importer *backfill.Service) {
+	runner.Register(model.BulkKindImport, bulkImport(importer))
+	runner.Register(model.BulkKindDispatch, bulkDispatch)
+	runner.Register(model.BulkKindValidate, bulkValidate(schedule))
+}
+
+// Jobs 提交/查询批量作业
+// 路由: GET|POST /api/v1/bulk/jobs?org_id=
+// GET 未指定 org_id 时组织受限的调用方只列出所属组织的作业；
+// POST 立即返回 202 和作业进度，作业拆分为批次在后台执行；导入作业需管理者
+func (h *BulkHandler) Jobs(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		orgID, ok := listOrgID(w, r)
+		if !ok {
+			return
+		}
+		respondJSON(w, http.StatusOK, h.store.ListBulkJobs(orgID))
+
+	case http.MethodPost:
+		var req SubmitBulkJobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if !model.IsValidBulkKind(req.Kind) {
+			respondError(w, errors.New(errors.CodeInvalidInput, fmt.Sprintf("不支持的作业类型: %s", req.Kind)))
+			return
+		}
+		if req.Kind == model.BulkKindImport && !requireManager(w, r) {
+			return
+		}
+		orgID, err := uuid.Parse(req.OrgID)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		if len(req.Rows) > maxBulkRows {
+			respondError(w, errors.New(errors.CodeInvalidInput, fmt.Sprintf("单个作业最多 %d 行", maxBulkRows)))
+			return
+		}
+		if err := checkBulkParams(req.Kind, req.Params); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+
+		job, err := h.runner.Submit(&model.BulkJob{
+			OrgID:     orgID,
+			Kind:      req.Kind,
+			BatchSize: req.BatchSize,
+			Params:    req.Params,
+			Rows:      req.Rows,
+			CreatedBy: r.Header.Get(AuthorHeader),
+		})
+		if err != nil {
+			respondBulkError(w, err)
+			return
+		}
+		w.Header().Set("Location", "/api/v1/bulk/jobs/"+job.ID.String())
+		w.Header().Set("Retry-After", "1")
+		respondJSON(w, http.StatusAccepted, job)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Job 查询批量作业进度（各批次状态和行级错误）
+// 路由: GET /api/v1/bulk/jobs/{id}
+func (h *BulkHandler) Job(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+	if !job.IsFinished() {
+		w.Header().Set("Retry-After", "1")
+	}
+	respondJSON(w, http.StatusOK, job.Summary())
+}
+
+// Results 分页查询批量作业的逐行结果
+// 路由: GET /api/v1/bulk/jobs/{id}/results?offset=&limit=&errors_only=
+func (h *BulkHandler) Results(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	errorsOnly := query.Get("errors_only") == "true"
+
+	rowErrors := make(map[int][]string)
+	for _, e := range job.Errors {
+		rowErrors[e.Row] = append(rowErrors[e.Row], e.Message)
+	}
+	resp := BulkResultsResponse{JobID: job.ID, Status: job.Status, Total: job.Total, Offset: offset, Items: make([]BulkResultItem, 0)}
+	for row := offset; row < job.Total && len(resp.Items) < limit; row++ {
+		if errorsOnly && len(rowErrors[row]) == 0 {
+			continue
+		}
+		resp.Items = append(resp.Items, BulkResultItem{Row: row, Result: job.Results[row], Errors: rowErrors[row]})
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// Resume 恢复批量作业，重新执行失败或已取消的批次
+// 路由: POST /api/v1/bulk/jobs/{id}/resume
+func (h *BulkHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.control(w, r, h.runner.Resume)
+}
+
+// Cancel 取消批量作业，正在执行的批次完成后停止
+// 路由: POST /api/v1/bulk/jobs/{id}/cancel
+func (h *BulkHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.control(w, r, h.runner.Cancel)
+}
+
+// control 执行恢复/取消
+func (h *BulkHandler) control(w http.ResponseWriter, r *http.Request, fn func(uuid.UUID) (*model.BulkJob, error)) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+	if job.Kind == model.BulkKindImport && !requireManager(w, r) {
+		return
+	}
+	updated, err := fn(job.ID)
+	if err != nil {
+		respondBulkError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// job 按路径中的作业ID读取作业，检查调用方可访问作业所属的组织
+func (h *BulkHandler) job(w http.ResponseWriter, r *http.Request) (*model.BulkJob, bool) {
+	if !h.ready(w) {
+		return nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的作业ID格式"))
+		return nil, false
+	}
+	job, err := h.store.GetBulkJob(id)
+	if err != nil {
+		respondBulkError(w, err)
+		return nil, false
+	}
+	if !authorizeOrg(w, r, job.OrgID) {
+		return nil, false
+	}
+	return job, true
+}
+
+// ready 检查是否启用了排班存储
+func (h *BulkHandler) ready(w http.ResponseWriter) bool {
+	if h.store == nil || h.runner == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// respondBulkError 将批量作业错误转换为响应
+func respondBulkError(w http.ResponseWriter, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "批量作业不存在"))
+	case stderrors.Is(err, bulk.ErrInvalidJob):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	case stderrors.Is(err, bulk.ErrJobFinished), stderrors.Is(err, bulk.ErrNothingToResume):
+		respondError(w, errors.New(errors.CodeAlreadyExists, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "处理批量作业失败"))
+	}
+}
+
+// checkBulkParams 提交时校验作业参数，避免所有批次因参数错误失败
+func checkBulkParams(kind string, raw json.RawMessage) error {
+	switch kind {
+	case model.BulkKindImport:
+		_, err := importOptions(raw)
+		return err
+	case model.BulkKindDispatch:
+		var params BulkDispatchParams
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return fmt.Errorf("解析派单参数失败: %w", err)
+			}
+		}
+		if len(params.Candidates) == 0 {
+			return fmt.Errorf("params.candidates 不能为空")
+		}
+		if err := constraint.Validate(params.Constraints); err != nil {
+			return fmt.Errorf("params.constraints 无效: %w", err)
+		}
+	}
+	return nil
+}
+
+// importOptions 解析导入作业参数
+func importOptions(raw json.RawMessage) (backfill.Options, error) {
+	var params BulkImportParams
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return backfill.Options{}, fmt.Errorf("解析导入参数失败: %w", err)
+		}
+	}
+	if params.ToleranceMinutes < 0 || params.ToleranceMinutes > 120 {
+		return backfill.Options{}, fmt.Errorf("tolerance_minutes 应在 0-120 之间")
+	}
+	opts := backfill.Options{Tolerance: params.ToleranceMinutes}
+	if params.Timezone != "" {
+		loc, err := time.LoadLocation(params.Timezone)
+		if err != nil {
+			return backfill.Options{}, fmt.Errorf("无效的时区: %s", params.Timezone)
+		}
+		opts.Location = loc
+	}
+	return opts, nil
+}
+
+// bulkImport 导入批次：回填历史分配，员工不存在或格式错误的记录为行级错误
+// 同一作业的批次依次执行，后续批次复用前面批次推断出的班次
+func bulkImport(importer *backfill.Service) bulk.Processor {
+	return func(ctx context.Context, job *model.BulkJob, offset int, rows []json.RawMessage) ([]json.RawMessage, []model.BulkRowError, error) {
+		opts, err := importOptions(job.Params)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var rowErrors []model.BulkRowError
+		records := make([]backfill.Record, 0, len(rows))
+		positions := make([]int, 0, len(rows)) // records 下标 -> 批次内行号
+		for i, raw := range rows {
+			var rec backfill.Record
+			if err := json.Unmarshal(raw, &rec); err != nil {
+				rowErrors = append(rowErrors, model.BulkRowError{Row: offset + i, Message: "解析记录失败: " + err.Error()})
+				continue
+			}
+			records = append(records, rec)
+			positions = append(positions, i)
+		}
+
+		result, err := importer.Import(job.OrgID, records, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		failed := make(map[int]bool, len(result.Skipped))
+		for _, issue := range result.Skipped {
+			failed[positions[issue.Index]] = true
+			rowErrors = append(rowErrors, model.BulkRowError{Row: offset + positions[issue.Index], Message: issue.Reason})
+		}
+
+		results := make([]json.RawMessage, len(rows))
+		for _, i := range positions {
+			if !failed[i] {
+				results[i] = json.RawMessage(`{"imported":true}`)
+			}
+		}
+		return results, rowErrors, nil
+	}
+}
+
+// bulkDispatch 派单批次：按订单顺序逐单派单，未能派出的订单为行级错误。
+// 前面批次已派出的订单计入员工当天订单，避免跨批次的时间冲突
+func bulkDispatch(ctx context.Context, job *model.BulkJob, offset int, rows []json.RawMessage) ([]json.RawMessage, []model.BulkRowError, error) {
+	var params BulkDispatchParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, nil, fmt.Errorf("解析派单参数失败: %w", err)
+	}
+	engine, err := dispatchEngineFor(job.OrgID, params.Constraints)
+	if err != nil {
+		return nil, nil, fmt.Errorf("派单约束配置无效: %w", err)
+	}
+
+	assigned := make([]*model.ServiceOrder, 0)
+	for i := 0; i < offset; i++ {
+		if order := assignedOrder(job.Rows[i], job.Results[i]); order != nil {
+			assigned = append(assigned, order)
+		}
+	}
+
+	var rowErrors []model.BulkRowError
+	orders := make([]*model.ServiceOrder, len(rows))
+	valid := make([]*model.ServiceOrder, 0, len(rows))
+	for i, raw := range rows {
+		var order model.ServiceOrder
+		if err := json.Unmarshal(raw, &order); err != nil {
+			rowErrors = append(rowErrors, model.BulkRowError{Row: offset + i, Message: "解析订单失败: " + err.Error()})
+			continue
+		}
+		orders[i] = &order
+		valid = append(valid, &order)
+	}
+	normalizeDispatch(valid, params.Candidates)
+
+	results := make([]json.RawMessage, len(rows))
+	for i, order := range orders {
+		if order == nil {
+			continue
+		}
+		resp := engine.Dispatch(&dispatcher.DispatchRequest{
+			Order:       order,
+			Candidates:  params.Candidates,
+			Customer:    params.Customer,
+			TodayOrders: assigned,
+			KeepApart:   params.KeepApart,
+			MaxResults:  3,
+		})
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !resp.Success {
+			rowErrors = append(rowErrors, model.BulkRowError{Row: offset + i, Message: "派单失败: " + resp.Reason})
+			continue
+		}
+		results[i] = data
+		if resp.BestMatch != nil {
+			orderCopy := *order
+			orderCopy.EmployeeID = &resp.BestMatch.Employee.ID
+			orderCopy.EmployeeIDs = resp.CrewIDs()
+			orderCopy.Status = "assigned"
+			assigned = append(assigned, &orderCopy)
+		}
+	}
+	return results, rowErrors, nil
+}
+
+// assignedOrder 由前面批次的派单结果还原已派出的订单
+func assignedOrder(raw, result json.RawMessage) *model.ServiceOrder {
+	if len(result) == 0 {
+		return nil
+	}
+	var resp dispatcher.DispatchResponse
+	var order model.ServiceOrder
+	if json.Unmarshal(result, &resp) != nil || json.Unmarshal(raw, &order) != nil {
+		return nil
+	}
+	if !resp.Success || resp.BestMatch == nil || resp.BestMatch.Employee == nil {
+		return nil
+	}
+	order.EmployeeID = &resp.BestMatch.Employee.ID
+	order.EmployeeIDs = resp.CrewIDs()
+	order.Status = "assigned"
+	return &order
+}
+
+// bulkValidate 验证批次：每行为一份排班验证请求，请求无效为行级错误，验证结果（含违规）作为行结果
+func bulkValidate(schedule *ScheduleHandler) bulk.Processor {
+	return func(ctx context.Context, job *model.BulkJob, offset int, rows []json.RawMessage) ([]json.RawMessage, []model.BulkRowError, error) {
+		var rowErrors []model.BulkRowError
+		results := make([]json.RawMessage, len(rows))
+		for i, raw := range rows {
+			var req ValidateRequest
+			if err := json.Unmarshal(raw, &req); err != nil {
+				rowErrors = append(rowErrors, model.BulkRowError{Row: offset + i, Message: "解析验证请求失败: " + err.Error()})
+				continue
+			}
+			if req.OrgID == "" {
+				req.OrgID = job.OrgID.String()
+			}
+			resp, appErr := schedule.validate(&req)
+			if appErr != nil {
+				rowErrors = append(rowErrors, model.BulkRowError{Row: offset + i, Message: appErr.Message})
+				continue
+			}
+			data, err := json.Marshal(resp)
+			if err != nil {
+				return nil, nil, err
+			}
+			results[i] = data
+		}
+		return results, rowErrors, nil
+	}
+}
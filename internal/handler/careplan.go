@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/order"
+	"github.com/paiban/paiban/pkg/careplan"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// CarePlanHandler 护理计划处理器（创建计划、按计划预览和生成服务订单、推荐护理员）
+type CarePlanHandler struct {
+	store   *memstore.Store
+	orders  *order.Service
+	manager *careplan.PlanManager
+}
+
+// NewCarePlanHandler 创建护理计划处理器，store 为 nil 时只提供不保存的计划接口
+func NewCarePlanHandler(store *memstore.Store, orders *order.Service) *CarePlanHandler {
+	return &CarePlanHandler{
+		store:   store,
+		orders:  orders,
+		manager: careplan.NewPlanManager(),
+	}
+}
+
+// CarePlanRequest 创建护理计划请求
+// 只填写 level 和 start_date 时按护理等级生成默认服务项目；care_plan 中未填写的字段按护理等级补全
+type CarePlanRequest struct {
+	CustomerID uuid.UUID       `json:"customer_id"`
+	Level      int             `json:"level,omitempty"`
+	StartDate  string          `json:"start_date,omitempty"`
+	CarePlan   *model.CarePlan `json:"care_plan,omitempty"`
+}
+
+// CarePlanOrdersRequest 按护理计划生成服务订单请求
+type CarePlanOrdersRequest struct {
+	CarePlan    *model.CarePlan `json:"care_plan,omitempty"` // 不保存计划的接口使用
+	Customer    *model.Customer `json:"customer,omitempty"`  // 计划未填写地址时使用客户地址
+	PeriodStart string          `json:"period_start"`
+	PeriodEnd   string          `json:"period_end"`
+	StartTime   string          `json:"start_time,omitempty"` // 上门时间（HH:MM），默认取计划的 visit_start_time
+}
+
+// RecommendCarersRequest 推荐护理员请求
+type RecommendCarersRequest struct {
+	CarePlan *model.CarePlan   `json:"care_plan"`
+	Carers   []*model.Employee `json:"carers"`
+}
+
+// CarePlanGenerateResult 生成并保存服务订单的结果
+type CarePlanGenerateResult struct {
+	*careplan.Expansion
+	Created []*model.ServiceOrder `json:"created"` // 新保存的订单
+	Skipped []string              `json:"skipped"` // 订单号已存在而跳过的订单
+}
+
+// Create 创建护理计划（不保存）
+// 路由: POST /api/v1/careplan/create
+func (h *CarePlanHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	plan, ok := h.decodePlan(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusCreated, map[string]interface{}{"success": true, "data": plan})
+}
+
+// GenerateOrders 按请求中的护理计划展开服务订单（不保存）
+// 路由: POST /api/v1/careplan/generate-orders
+func (h *CarePlanHandler) GenerateOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	var req CarePlanOrdersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if req.CarePlan == nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "缺少护理计划"))
+		return
+	}
+	if req.CarePlan.CustomerID == uuid.Nil && req.Customer != nil {
+		req.CarePlan.CustomerID = req.Customer.ID
+	}
+	if err := h.manager.Complete(req.CarePlan); err != nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+		return
+	}
+	expansion, ok := h.expand(w, req.CarePlan, &req)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{"success": true, "data": expansion})
+}
+
+// RecommendCarers 按护理等级所需技能推荐护理员
+// 路由: POST /api/v1/careplan/recommend-carers
+func (h *CarePlanHandler) RecommendCarers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	var req RecommendCarersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if req.CarePlan == nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "缺少护理计划"))
+		return
+	}
+	recommendations := h.manager.GetRecommendedCarers(req.CarePlan, req.Carers)
+	if recommendations == nil {
+		recommendations = []*careplan.CarerRecommendation{}
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{"success": true, "data": recommendations})
+}
+
+// Plans 查询/创建组织的护理计划
+// 路由: GET|POST /api/v1/orgs/{org_id}/care-plans
+// GET 支持 customer_id 过滤；POST 请求体同 /api/v1/careplan/create，补全后保存
+func (h *CarePlanHandler) Plans(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		customerID := uuid.Nil
+		if v := r.URL.Query().Get("customer_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的客户ID格式"))
+				return
+			}
+			customerID = id
+		}
+		respondJSON(w, http.StatusOK, h.store.ListCarePlans(orgID, customerID))
+
+	case http.MethodPost:
+		plan, ok := h.decodePlan(w, r)
+		if !ok {
+			return
+		}
+		plan.BaseModel = model.NewBaseModel()
+		plan.OrgID = orgID
+		if err := h.store.PutCarePlan(plan); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "保存护理计划失败"))
+			return
+		}
+		respondJSON(w, http.StatusCreated, plan)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Plan 查询护理计划
+// 路由: GET /api/v1/orgs/{org_id}/care-plans/{id}
+func (h *CarePlanHandler) Plan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	plan, ok := h.plan(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, plan)
+}
+
+// PreviewOrders 预览护理计划在一段时间内展开的服务订单（不保存）
+// 路由: POST /api/v1/orgs/{org_id}/care-plans/{id}/preview-orders
+func (h *CarePlanHandler) PreviewOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	plan, req, ok := h.planOrdersRequest(w, r)
+	if !ok {
+		return
+	}
+	expansion, ok := h.expand(w, plan, req)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, expansion)
+}
+
+// SaveOrders 按护理计划生成服务订单并保存为待派单订单
+// 路由: POST /api/v1/orgs/{org_id}/care-plans/{id}/generate-orders
+// 订单号为"计划编号-日期"，重复生成同一时段时已有订单跳过
+func (h *CarePlanHandler) SaveOrders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	plan, req, ok := h.planOrdersRequest(w, r)
+	if !ok {
+		return
+	}
+	expansion, ok := h.expand(w, plan, req)
+	if !ok {
+		return
+	}
+	created, skipped, err := h.orders.CreateMissing(plan.OrgID, expansion.Orders)
+	if err != nil {
+		respondOrderError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, CarePlanGenerateResult{Expansion: expansion, Created: created, Skipped: skipped})
+}
+
+// decodePlan 解析并补全护理计划
+func (h *CarePlanHandler) decodePlan(w http.ResponseWriter, r *http.Request) (*model.CarePlan, bool) {
+	var req CarePlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return nil, false
+	}
+	plan := req.CarePlan
+	if plan == nil {
+		plan = &model.CarePlan{Level: req.Level, StartDate: req.StartDate}
+	}
+	if req.CustomerID != uuid.Nil {
+		plan.CustomerID = req.CustomerID
+	}
+	if err := h.manager.Complete(plan); err != nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+		return nil, false
+	}
+	return plan, true
+}
+
+// planOrdersRequest 解析保存的护理计划和生成订单请求
+func (h *CarePlanHandler) planOrdersRequest(w http.ResponseWriter, r *http.Request) (*model.CarePlan, *CarePlanOrdersRequest, bool) {
+	plan, ok := h.plan(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	var req CarePlanOrdersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return nil, nil, false
+	}
+	return plan, &req, true
+}
+
+// expand 展开护理计划，计划未生效或日期无效时返回 400
+func (h *CarePlanHandler) expand(w http.ResponseWriter, plan *model.CarePlan, req *CarePlanOrdersRequest) (*careplan.Expansion, bool) {
+	if req.PeriodStart == "" || req.PeriodEnd == "" {
+		respondError(w, errors.New(errors.CodeInvalidInput, "period_start 和 period_end 不能为空"))
+		return nil, false
+	}
+	expansion, err := h.manager.Expand(plan, req.Customer, req.PeriodStart, req.PeriodEnd, careplan.ExpandOptions{StartTime: req.StartTime})
+	if err != nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+		return nil, false
+	}
+	return expansion, true
+}
+
+// orgID 检查存储是否启用并解析组织ID
+func (h *CarePlanHandler) orgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil || h.orders == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// plan 获取组织的护理计划
+func (h *CarePlanHandler) plan(w http.ResponseWriter, r *http.Request) (*model.CarePlan, bool) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的护理计划ID格式"))
+		return nil, false
+	}
+	plan, err := h.store.GetCarePlan(id)
+	if err != nil || plan.OrgID != orgID {
+		respondError(w, errors.New(errors.CodeNotFound, "护理计划不存在"))
+		return nil, false
+	}
+	return plan, true
+}
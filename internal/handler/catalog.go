@@ -0,0 +1,98 @@
+package handler
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"github.com/paiban/paiban/internal/constraints"
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// CatalogHandler 约束目录处理器（约束库、场景模板及运行时重新加载）
+type CatalogHandler struct {
+	catalog *constraints.Catalog
+}
+
+// NewCatalogHandler 创建约束目录处理器
+func NewCatalogHandler(catalog *constraints.Catalog) *CatalogHandler {
+	return &CatalogHandler{catalog: catalog}
+}
+
+// CatalogReloadResponse 约束目录重新加载响应
+type CatalogReloadResponse struct {
+	Success bool                      `json:"success"`
+	DryRun  bool                      `json:"dry_run,omitempty"`
+	Status  constraints.CatalogStatus `json:"status"`
+	Errors  []errors.ValidationError  `json:"errors,omitempty"` // 校验失败的字段，此时目录保持不变
+}
+
+// Library 返回约束库（后端支持的所有约束及参数定义）
+// 路由: GET /api/v1/constraints/library
+func (h *CatalogHandler) Library(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	respondJSON(w, http.StatusOK, constraints.LibraryResponse{Library: h.catalog.Library()})
+}
+
+// Templates 返回场景约束模板
+// 路由: GET /api/v1/constraints/templates
+func (h *CatalogHandler) Templates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	respondJSON(w, http.StatusOK, constraints.TemplatesResponse{Templates: h.catalog.Templates()})
+}
+
+// Status 查询约束目录加载状态（仅管理员）
+// 路由: GET /api/v1/admin/constraints/catalog
+func (h *CatalogHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	respondJSON(w, http.StatusOK, h.catalog.Status())
+}
+
+// Reload 从配置来源重新加载约束库和模板（仅管理员）
+// 路由: POST /api/v1/admin/constraints/reload[?dry_run=true]
+// 定义校验通过后整体替换，失败时返回各字段错误且不影响当前目录；dry_run 只校验不替换
+func (h *CatalogHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	status, err := h.catalog.Reload(dryRun)
+	if err != nil {
+		var ve *errors.ValidationErrors
+		var appErr *errors.AppError
+		switch {
+		case stderrors.As(err, &ve):
+			respondJSON(w, http.StatusBadRequest, CatalogReloadResponse{
+				DryRun: dryRun,
+				Status: status,
+				Errors: ve.Errors,
+			})
+		case stderrors.As(err, &appErr):
+			respondError(w, appErr)
+		default:
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "加载约束目录失败").WithDetails(err.Error()))
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, CatalogReloadResponse{
+		Success: true,
+		DryRun:  dryRun,
+		Status:  status,
+	})
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/certification"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// CertificationHandler 证书材料处理器
+type CertificationHandler struct {
+	store   *memstore.Store
+	service *certification.Service
+}
+
+// NewCertificationHandler 创建证书材料处理器
+func NewCertificationHandler(store *memstore.Store, service *certification.Service) *CertificationHandler {
+	return &CertificationHandler{
+		store:   store,
+		service: service,
+	}
+}
+
+// CertificationReviewRequest 证书材料审核请求
+type CertificationReviewRequest struct {
+	Note string `json:"note,omitempty"` // 审核意见，驳回时必填
+}
+
+// Documents 查询/提交组织的证书材料
+// 路由: GET|POST /api/v1/orgs/{org_id}/certification-documents
+// GET 支持 employee_id、status 过滤；POST 提交证书材料（文件引用、发证机构、有效期等），提交人取 X-User-ID
+func (h *CertificationHandler) Documents(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		employeeID := uuid.Nil
+		if v := query.Get("employee_id"); v != "" {
+			if employeeID, err = uuid.Parse(v); err != nil {
+				respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式"))
+				return
+			}
+		}
+		respondJSON(w, http.StatusOK, h.store.ListCertificationDocuments(orgID, employeeID, query.Get("status")))
+
+	case http.MethodPost:
+		var d model.CertificationDocument
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		d.OrgID = orgID
+		d.SubmittedBy = r.Header.Get(AuthorHeader)
+		doc, err := h.service.Submit(&d)
+		if err != nil {
+			respondCertificationError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, doc)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Verify 核验通过证书材料（需管理者）
+// 路由: POST /api/v1/orgs/{org_id}/certification-documents/{id}/verify
+func (h *CertificationHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.Verify)
+}
+
+// Reject 驳回证书材料（需管理者），已核验的材料也可驳回以撤销核验
+// 路由: POST /api/v1/orgs/{org_id}/certification-documents/{id}/reject
+func (h *CertificationHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.Reject)
+}
+
+// review 处理核验/驳回请求，审核人取 X-User-ID
+func (h *CertificationHandler) review(w http.ResponseWriter, r *http.Request, decide func(orgID, id uuid.UUID, reviewer, note string) (*model.CertificationDocument, error)) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if !h.ready(w) || !requireManager(w, r) {
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的证书材料ID格式"))
+		return
+	}
+
+	var req CertificationReviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+	}
+	doc, err := decide(orgID, id, r.Header.Get(AuthorHeader), req.Note)
+	if err != nil {
+		respondCertificationError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, doc)
+}
+
+// ready 检查是否启用了排班存储
+func (h *CertificationHandler) ready(w http.ResponseWriter) bool {
+	if h.store == nil || h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// respondCertificationError 将证书核验服务错误转换为响应
+func respondCertificationError(w http.ResponseWriter, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "证书材料不存在"))
+	case stderrors.Is(err, certification.ErrInvalidDocument):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	case stderrors.Is(err, certification.ErrReviewed):
+		respondError(w, errors.New(errors.CodeAlreadyExists, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "处理证书材料失败"))
+	}
+}
+
+// verifiedCertifications 返回存储中组织员工审核通过的证书，未启用存储时返回 nil
+// 请求中未携带已核验证书的员工使用这里的结果
+func (h *ScheduleHandler) verifiedCertifications(orgID uuid.UUID) map[uuid.UUID][]model.VerifiedCertification {
+	if h.store == nil {
+		return nil
+	}
+	return certification.NewService(h.store).Verified(orgID)
+}
+
+// CertificationExpiryWarning 排班期间到期的证书
+type CertificationExpiryWarning struct {
+	EmployeeID       string `json:"employee_id"`
+	EmployeeName     string `json:"employee_name,omitempty"`
+	Certification    string `json:"certification"`
+	ExpiresAt        string `json:"expires_at"`        // 有效期最后一天
+	AssignmentsAfter int    `json:"assignments_after"` // 到期后仍排有的班次数（岗位不要求该证书时仍可排班）
+	Message          string `json:"message"`
+}
+
+// expiringCertifications 列出员工在排班期间 [start, end] 内到期的证书，提醒在到期前续证
+func expiringCertifications(employees []*model.Employee, assignments []*model.Assignment, start, end string) []CertificationExpiryWarning {
+	var warnings []CertificationExpiryWarning
+	for _, emp := range employees {
+		for _, cert := range emp.ExpiringCertifications(start, end) {
+			after := 0
+			for _, a := range assignments {
+				if a.EmployeeID == emp.ID && a.Date > cert.ExpiresAt {
+					after++
+				}
+			}
+			warnings = append(warnings, CertificationExpiryWarning{
+				EmployeeID:       emp.ID.String(),
+				EmployeeName:     emp.Name,
+				Certification:    cert.Name,
+				ExpiresAt:        cert.ExpiresAt,
+				AssignmentsAfter: after,
+				Message:          fmt.Sprintf("员工 %s 的%s将于 %s 到期，到期后排有 %d 个班次", emp.Name, cert.Name, cert.ExpiresAt, after),
+			})
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/paiban/paiban/pkg/chaos"
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// ChaosHandler 故障注入处理器（仅 -tags chaos 构建的测试环境可用）
+type ChaosHandler struct{}
+
+// NewChaosHandler 创建故障注入处理器
+func NewChaosHandler() *ChaosHandler {
+	return &ChaosHandler{}
+}
+
+// FaultsResponse 故障注入状态
+type FaultsResponse struct {
+	Enabled bool          `json:"enabled"`
+	Points  []string      `json:"points"`
+	Faults  []chaos.Fault `json:"faults"`
+}
+
+// Faults 查询/布置/解除故障（仅管理员）
+// 路由: GET|POST|DELETE /api/v1/admin/faults
+// POST 布置故障（同一注入点替换原有故障）；DELETE 带 point 参数时解除该注入点，否则解除全部
+func (h *ChaosHandler) Faults(w http.ResponseWriter, r *http.Request) {
+	if !requireAdmin(w, r) {
+		return
+	}
+	if !chaos.Enabled {
+		respondError(w, errors.New(errors.CodeNotFound, chaos.ErrDisabled.Error()))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, FaultsResponse{Enabled: chaos.Enabled, Points: chaos.Points(), Faults: chaos.List()})
+
+	case http.MethodPost:
+		var f chaos.Fault
+		if err := json.NewDecoder(r.Body).Decode(&f); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		armed, err := chaos.Arm(f)
+		if err != nil {
+			if stderrors.Is(err, chaos.ErrInvalidFault) {
+				respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+				return
+			}
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "布置故障失败"))
+			return
+		}
+		respondJSON(w, http.StatusCreated, armed)
+
+	case http.MethodDelete:
+		if point := r.URL.Query().Get("point"); point != "" {
+			if !chaos.Disarm(point) {
+				respondError(w, errors.New(errors.CodeNotFound, "注入点未布置故障"))
+				return
+			}
+		} else {
+			chaos.Reset()
+		}
+		respondJSON(w, http.StatusOK, FaultsResponse{Enabled: chaos.Enabled, Points: chaos.Points(), Faults: chaos.List()})
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST/DELETE方法"))
+	}
+}
@@ -0,0 +1,314 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/stats"
+)
+
+const (
+	minCompareScenarios = 2
+	maxCompareScenarios = 3
+)
+
+// CompareRequest 排班方案对比请求
+// profiles 与 schedules 二选一：profiles 为 2-3 组约束权重配置，分别以 base 试算（不保存）；
+// schedules 为 2-3 组已有的分配方案，按 base 的员工、需求和约束评估
+type CompareRequest struct {
+	Base      GenerateRequest   `json:"base"`
+	Profiles  []CompareProfile  `json:"profiles,omitempty"`
+	Schedules []CompareSchedule `json:"schedules,omitempty"`
+}
+
+// CompareProfile 约束权重配置，与 base.constraints 合并（同名参数以此为准）
+type CompareProfile struct {
+	Name        string                 `json:"name"`
+	Constraints map[string]interface{} `json:"constraints"`
+}
+
+// CompareSchedule 已有的分配方案
+type CompareSchedule struct {
+	Name        string            `json:"name"`
+	Assignments []AssignmentInput `json:"assignments"`
+}
+
+// CompareResponse 排班方案对比结果
+type CompareResponse struct {
+	Scenarios []ScenarioMetrics `json:"scenarios"`
+	// 各指标最优的方案名称：fill_rate（满足率最高）、fairness（公平性评分最高）、cost（成本最低）、violations（硬约束违规最少）
+	Best map[string]string `json:"best"`
+}
+
+// ScenarioMetrics 单个方案的对比指标
+type ScenarioMetrics struct {
+	Name         string  `json:"name"`
+	Requirements int     `json:"requirements"`
+	Filled       int     `json:"filled"`
+	Shortage     int     `json:"shortage"`  // 缺口人数
+	FillRate     float64 `json:"fill_rate"` // 需求满足率（百分比）
+	Assignments  int     `json:"assignment_count"`
+	TotalHours   float64 `json:"total_hours"`
+
+	WorkloadGini  float64 `json:"workload_gini"`
+	NightGini     float64 `json:"night_shift_gini"`
+	WeekendGini   float64 `json:"weekend_shift_gini"`
+	FairnessScore float64 `json:"fairness_score"`
+
+	Cost          float64 `json:"cost"`
+	OvertimeHours float64 `json:"overtime_hours"`
+
+	HardViolations int     `json:"hard_violations"`
+	SoftViolations int     `json:"soft_violations"`
+	Score          float64 `json:"score"` // 约束评分
+
+	// 与第一个方案相比的变化（第一个方案为空）
+	Delta *ScenarioDelta `json:"delta,omitempty"`
+	// 试算生成的分配（profiles 模式），可直接采用
+	Schedule []AssignmentOutput `json:"assignments,omitempty"`
+}
+
+// ScenarioDelta 与基准方案相比的指标变化（本方案 - 基准方案）
+type ScenarioDelta struct {
+	FillRate       float64            `json:"fill_rate"`
+	Shortage       int                `json:"shortage"`
+	Cost           float64            `json:"cost"`
+	HardViolations int                `json:"hard_violations"`
+	SoftViolations int                `json:"soft_violations"`
+	Fairness       map[string]float64 `json:"fairness"` // 公平性对比（基尼系数和综合评分的变化）
+}
+
+// Compare 用不同约束权重试算或评估已有方案，并排对比满足率、公平性、成本和违规
+// 路由: POST /api/v1/schedule/compare
+func (h *ScheduleHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+
+	var req CompareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if err := validateGenerateRequest(&req.Base); err != nil {
+		respondError(w, err)
+		return
+	}
+	count := len(req.Profiles) + len(req.Schedules)
+	if len(req.Profiles) > 0 && len(req.Schedules) > 0 || count < minCompareScenarios || count > maxCompareScenarios {
+		respondError(w, errors.New(errors.CodeInvalidInput,
+			fmt.Sprintf("profiles 和 schedules 须且只能提供一项，包含 %d-%d 个方案", minCompareScenarios, maxCompareScenarios)))
+		return
+	}
+	if len(req.Base.Requirements) == 0 {
+		requirements, appErr := expandRequirementSpec(req.Base.RequirementSpec, req.Base.Shifts, req.Base.StartDate, req.Base.EndDate)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		req.Base.Requirements = requirements
+	}
+
+	employees := make([]*stats.EmployeeInfo, len(req.Base.Employees))
+	for i, e := range req.Base.Employees {
+		employees[i] = &stats.EmployeeInfo{ID: e.ID, Name: e.Name}
+	}
+	resp := &CompareResponse{Scenarios: make([]ScenarioMetrics, 0, count)}
+	var infos [][]*stats.AssignmentInfo
+	for i, p := range req.Profiles {
+		m, list, appErr := h.compareProfile(r, &req.Base, p, i, employees)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		resp.Scenarios = append(resp.Scenarios, *m)
+		infos = append(infos, list)
+	}
+	for i, s := range req.Schedules {
+		m, list, appErr := h.compareSchedule(&req.Base, s, i, employees)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		resp.Scenarios = append(resp.Scenarios, *m)
+		infos = append(infos, list)
+	}
+
+	analyzer := stats.NewFairnessAnalyzer()
+	first := &resp.Scenarios[0]
+	for i := 1; i < len(resp.Scenarios); i++ {
+		m := &resp.Scenarios[i]
+		m.Delta = &ScenarioDelta{
+			FillRate:       math.Round((m.FillRate-first.FillRate)*100) / 100,
+			Shortage:       m.Shortage - first.Shortage,
+			Cost:           roundCost(m.Cost - first.Cost),
+			HardViolations: m.HardViolations - first.HardViolations,
+			SoftViolations: m.SoftViolations - first.SoftViolations,
+			Fairness:       analyzer.CompareSchedules(infos[0], infos[i], employees),
+		}
+	}
+	resp.Best = bestScenarios(resp.Scenarios)
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// compareProfile 按约束权重配置试算一次（不保存）
+func (h *ScheduleHandler) compareProfile(r *http.Request, base *GenerateRequest, p CompareProfile, i int, employees []*stats.EmployeeInfo) (*ScenarioMetrics, []*stats.AssignmentInfo, *errors.AppError) {
+	trial := *base
+	trial.Employees = append([]EmployeeInput(nil), base.Employees...)
+	trial.Requirements = append([]RequirementInput(nil), base.Requirements...)
+	trial.Constraints = mergeConfig(base.Constraints, p.Constraints)
+	var options GenerateOptions
+	if base.Options != nil {
+		options = *base.Options
+	}
+	options.DryRun = true
+	trial.Options = &options
+
+	resp, appErr := h.generate(r.Context(), &trial)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	m := &ScenarioMetrics{Name: scenarioName(p.Name, i), Assignments: len(resp.Assignments), Schedule: resp.Assignments}
+	if st := resp.Statistics; st != nil {
+		m.Requirements, m.Filled, m.TotalHours = st.TotalRequirements, st.FilledRequirements, st.TotalHours
+		m.FillRate = math.Round(st.FillRate*100) / 100
+	}
+	for _, u := range resp.Unfilled {
+		m.Shortage += u.Shortage
+	}
+	if c := resp.Constraints; c != nil {
+		m.HardViolations, m.SoftViolations, m.Score = len(c.HardViolations), len(c.SoftViolations), c.Score
+	}
+	if cs := resp.CostSummary; cs != nil {
+		m.Cost, m.OvertimeHours = cs.Cost, cs.OvertimeHours
+	}
+
+	list := make([]*stats.AssignmentInfo, len(resp.Assignments))
+	for j, a := range resp.Assignments {
+		list[j] = &stats.AssignmentInfo{ShiftID: a.ShiftID, EmployeeID: a.EmployeeID, EmployeeName: a.EmployeeName, Date: a.Date}
+		list[j].StartTime, list[j].EndTime = assignmentClock(a.Date, a.StartTime, a.EndTime)
+	}
+	m.fairness(list, employees)
+	return m, list, nil
+}
+
+// compareSchedule 按 base 的员工、需求和约束评估已有的分配方案
+func (h *ScheduleHandler) compareSchedule(base *GenerateRequest, s CompareSchedule, i int, employees []*stats.EmployeeInfo) (*ScenarioMetrics, []*stats.AssignmentInfo, *errors.AppError) {
+	orgID, err := uuid.Parse(base.OrgID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式")
+	}
+	name := scenarioName(s.Name, i)
+
+	result, appErr := h.validate(&ValidateRequest{OrgID: base.OrgID, Assignments: s.Assignments, Employees: base.Employees, Constraints: base.Constraints})
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	m := &ScenarioMetrics{Name: name, Assignments: len(s.Assignments), Score: result.Score}
+	for _, v := range result.Violations {
+		if v.Severity == "error" {
+			m.HardViolations++
+		} else {
+			m.SoftViolations++
+		}
+	}
+
+	priced := make([]*model.Employee, 0, len(base.Employees))
+	for _, e := range base.Employees {
+		id, err := uuid.Parse(e.ID)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式: "+e.ID)
+		}
+		priced = append(priced, &model.Employee{BaseModel: model.BaseModel{ID: id}, Name: e.Name, Position: e.Position, HourlyRate: e.HourlyRate})
+	}
+	assignments := make([]*model.Assignment, 0, len(s.Assignments))
+	list := make([]*stats.AssignmentInfo, 0, len(s.Assignments))
+	for j, a := range s.Assignments {
+		empID, err1 := uuid.Parse(a.EmployeeID)
+		shiftID, err2 := uuid.Parse(a.ShiftID)
+		if err1 != nil || err2 != nil {
+			return nil, nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("方案 %s 第 %d 条分配的员工或班次ID无效", name, j+1))
+		}
+		start, end := assignmentClock(a.Date, a.StartTime, a.EndTime)
+		assignment := &model.Assignment{EmployeeID: empID, ShiftID: shiftID, Date: a.Date, StartTime: start, EndTime: end, Position: a.Position}
+		assignments = append(assignments, assignment)
+		m.TotalHours += assignment.WorkingHours()
+		list = append(list, &stats.AssignmentInfo{ShiftID: a.ShiftID, EmployeeID: a.EmployeeID, Date: a.Date, StartTime: start, EndTime: end})
+	}
+	m.TotalHours = math.Round(m.TotalHours*100) / 100
+
+	requirements := make([]*model.ShiftRequirement, 0, len(base.Requirements))
+	for _, in := range base.Requirements {
+		requirement, appErr := requirementFromInput(in)
+		if appErr != nil {
+			return nil, nil, appErr
+		}
+		requirements = append(requirements, requirement)
+	}
+	unfilled := calculateUnfilledRequirements(requirements, assignments, nil, nil)
+	m.Requirements, m.Filled = len(requirements), len(requirements)-len(unfilled)
+	for _, u := range unfilled {
+		m.Shortage += u.Shortage
+	}
+	if m.Requirements > 0 {
+		m.FillRate = math.Round(float64(m.Filled)/float64(m.Requirements)*10000) / 100
+	}
+
+	cost := builtin.ConfigCostCalculator(h.withHolidays(orgID, base.Constraints)).Calculate(priced, assignments)
+	m.Cost, m.OvertimeHours = cost.Cost, cost.OvertimeHours
+	m.fairness(list, employees)
+	return m, list, nil
+}
+
+// fairness 计算方案的公平性指标
+func (m *ScenarioMetrics) fairness(list []*stats.AssignmentInfo, employees []*stats.EmployeeInfo) {
+	f := stats.NewFairnessAnalyzer().Analyze(list, employees)
+	m.WorkloadGini, m.NightGini, m.WeekendGini = f.WorkloadGini, f.NightShiftGini, f.WeekendShiftGini
+	m.FairnessScore = math.Round(f.OverallFairnessScore*100) / 100
+}
+
+// bestScenarios 返回各指标最优的方案（相同时取靠前的方案）
+func bestScenarios(scenarios []ScenarioMetrics) map[string]string {
+	best := make(map[string]string)
+	pick := func(metric string, better func(a, b *ScenarioMetrics) bool) {
+		top := &scenarios[0]
+		for i := 1; i < len(scenarios); i++ {
+			if better(&scenarios[i], top) {
+				top = &scenarios[i]
+			}
+		}
+		best[metric] = top.Name
+	}
+	pick("fill_rate", func(a, b *ScenarioMetrics) bool { return a.FillRate > b.FillRate })
+	pick("fairness", func(a, b *ScenarioMetrics) bool { return a.FairnessScore > b.FairnessScore })
+	pick("cost", func(a, b *ScenarioMetrics) bool { return a.Cost < b.Cost })
+	pick("violations", func(a, b *ScenarioMetrics) bool { return a.HardViolations < b.HardViolations })
+	return best
+}
+
+// scenarioName 方案名称，未命名时按序号命名
+func scenarioName(name string, i int) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("方案%d", i+1)
+}
+
+// assignmentClock 解析分配的起止时间，结束时间不晚于开始时间时视为跨天
+func assignmentClock(date, start, end string) (time.Time, time.Time) {
+	startTime, _ := time.Parse("2006-01-02 15:04", date+" "+start)
+	endTime, _ := time.Parse("2006-01-02 15:04", date+" "+end)
+	if !endTime.After(startTime) {
+		endTime = endTime.Add(24 * time.Hour)
+	}
+	return startTime, endTime
+}
@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/stats"
+)
+
+// 兼容层：在引擎内部模型与各版本 API 资源之间转换。
+// 引擎内部结构（GenerateResponse、model.Schedule 等）可以自由演进，
+// 只需在此处维护到 v2 资源的映射，v2 对外字段保持不变。
+
+// scheduleStatusV2 根据生成结果推导 v2 排班状态
+func scheduleStatusV2(resp *GenerateResponse) string {
+	switch {
+	case len(resp.Assignments) == 0:
+		return ScheduleStatusFailed
+	case resp.Partial:
+		return ScheduleStatusPartial
+	default:
+		return ScheduleStatusComplete
+	}
+}
+
+// toScheduleV2 将生成响应转换为 v2 排班资源
+func toScheduleV2(req *GenerateRequest, resp *GenerateResponse) *ScheduleV2 {
+	schedule := &ScheduleV2{
+		APIVersion:  APIVersionV2,
+		Kind:        KindSchedule,
+		ID:          resp.ScheduleID,
+		OrgID:       req.OrgID,
+		Period:      PeriodV2{Start: req.StartDate, End: req.EndDate},
+		Status:      scheduleStatusV2(resp),
+		Message:     resp.Message,
+		Assignments: make([]AssignmentV2, 0, len(resp.Assignments)),
+		Unfilled:    make([]UnfilledV2, 0, len(resp.Unfilled)),
+		Violations:  make([]ViolationV2, 0),
+		Warnings:    make([]WarningV2, 0),
+	}
+
+	if d, err := time.ParseDuration(resp.Duration); err == nil {
+		schedule.DurationMs = d.Milliseconds()
+	}
+
+	for _, a := range resp.Assignments {
+		schedule.Assignments = append(schedule.Assignments, AssignmentV2{
+			ID:           a.ID,
+			EmployeeID:   a.EmployeeID,
+			EmployeeName: a.EmployeeName,
+			ShiftID:      a.ShiftID,
+			ShiftName:    a.ShiftName,
+			Date:         a.Date,
+			StartTime:    a.StartTime,
+			EndTime:      a.EndTime,
+			Position:     a.Position,
+			Hours:        a.Hours,
+			Score:        a.Score,
+		})
+	}
+
+	for _, u := range resp.Unfilled {
+		schedule.Unfilled = append(schedule.Unfilled, UnfilledV2{
+			ShiftID:  u.ShiftID,
+			Date:     u.Date,
+			Position: u.Position,
+			Required: u.Required,
+			Assigned: u.Assigned,
+			Reason:   u.Reason,
+		})
+	}
+
+	if resp.Statistics != nil {
+		schedule.Summary = SummaryV2{
+			TotalAssignments:   resp.Statistics.TotalAssignments,
+			FilledRequirements: resp.Statistics.FilledRequirements,
+			TotalRequirements:  resp.Statistics.TotalRequirements,
+			FillRate:           resp.Statistics.FillRate,
+			TotalHours:         resp.Statistics.TotalHours,
+		}
+	}
+
+	if resp.Constraints != nil {
+		schedule.Summary.Valid = resp.Constraints.IsValid
+		schedule.Summary.ConstraintScore = resp.Constraints.Score
+		schedule.Violations = append(schedule.Violations, toViolationsV2(resp.Constraints.HardViolations)...)
+		schedule.Violations = append(schedule.Violations, toViolationsV2(resp.Constraints.SoftViolations)...)
+	}
+
+	for _, s := range resp.Suggestions {
+		schedule.Warnings = append(schedule.Warnings, WarningV2{
+			Type:     "staffing_" + s.Type,
+			Severity: "info",
+			Message:  s.Reason,
+		})
+	}
+	schedule.Warnings = append(schedule.Warnings, toAnomalyWarningsV2(resp.Anomalies)...)
+
+	return schedule
+}
+
+// scheduleFromModelV2 将存储中的排班模型转换为 v2 排班资源
+func scheduleFromModelV2(s *model.Schedule) *ScheduleV2 {
+	schedule := &ScheduleV2{
+		APIVersion:  APIVersionV2,
+		Kind:        KindSchedule,
+		ID:          s.ID.String(),
+		OrgID:       s.OrgID.String(),
+		Period:      PeriodV2{Start: s.StartDate, End: s.EndDate},
+		Status:      ScheduleStatusComplete,
+		Lifecycle:   s.Status,
+		Assignments: make([]AssignmentV2, 0, len(s.Assignments)),
+		Unfilled:    make([]UnfilledV2, 0),
+		Violations:  make([]ViolationV2, 0),
+		Warnings:    make([]WarningV2, 0),
+	}
+
+	for _, a := range s.Assignments {
+		schedule.Assignments = append(schedule.Assignments, AssignmentV2{
+			ID:         a.ID.String(),
+			EmployeeID: a.EmployeeID.String(),
+			ShiftID:    a.ShiftID.String(),
+			Date:       a.Date,
+			StartTime:  a.StartTime.Format("15:04"),
+			EndTime:    a.EndTime.Format("15:04"),
+			Position:   a.Position,
+			Hours:      a.WorkingHours(),
+		})
+	}
+
+	if s.Statistics != nil {
+		if s.Statistics.UnfilledShifts > 0 {
+			schedule.Status = ScheduleStatusPartial
+		}
+		schedule.Summary = SummaryV2{
+			TotalAssignments: s.Statistics.TotalAssignments,
+			FillRate:         s.Statistics.FillRate,
+			TotalHours:       s.Statistics.TotalHours,
+			ConstraintScore:  s.Statistics.ConstraintScore,
+		}
+	}
+	if len(s.Assignments) == 0 {
+		schedule.Status = ScheduleStatusFailed
+	}
+
+	return schedule
+}
+
+// toValidationV2 将验证响应转换为 v2 验证资源
+func toValidationV2(resp *ValidateResponse) *ValidationV2 {
+	return &ValidationV2{
+		APIVersion: APIVersionV2,
+		Kind:       KindValidation,
+		Valid:      resp.IsValid,
+		Score:      resp.Score,
+		Violations: toViolationsV2(resp.Violations),
+	}
+}
+
+// toViolationsV2 转换约束违反详情
+func toViolationsV2(details []constraint.ViolationDetail) []ViolationV2 {
+	result := make([]ViolationV2, 0, len(details))
+	for _, d := range details {
+		v := ViolationV2{
+			Constraint: string(d.ConstraintType),
+			Name:       d.ConstraintName,
+			Level:      ViolationLevelSoft,
+			Date:       d.Date,
+			Message:    d.Message,
+			Penalty:    d.Penalty,
+		}
+		// 内部以 severity=error 标记硬约束违反
+		if strings.EqualFold(d.Severity, "error") {
+			v.Level = ViolationLevelHard
+		}
+		if d.EmployeeID != uuid.Nil {
+			v.EmployeeID = d.EmployeeID.String()
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// toAnomalyWarningsV2 将异常检测结果转换为 v2 警告
+func toAnomalyWarningsV2(anomalies []stats.Anomaly) []WarningV2 {
+	result := make([]WarningV2, 0, len(anomalies))
+	for _, a := range anomalies {
+		result = append(result, WarningV2{
+			Type:       "anomaly_" + a.Type,
+			Severity:   a.Severity,
+			EmployeeID: a.EmployeeID,
+			Message:    a.Message,
+		})
+	}
+	return result
+}
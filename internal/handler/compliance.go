@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/compliance"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ComplianceHandler 工时合规证明处理器
+type ComplianceHandler struct {
+	store   *memstore.Store
+	service *compliance.Service
+}
+
+// NewComplianceHandler 创建工时合规证明处理器
+func NewComplianceHandler(store *memstore.Store, service *compliance.Service) *ComplianceHandler {
+	return &ComplianceHandler{
+		store:   store,
+		service: service,
+	}
+}
+
+// IssueCertificateRequest 签发合规证明请求
+type IssueCertificateRequest struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// VerifyCertificateResponse 合规证明校验结果
+type VerifyCertificateResponse struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Certificates 查询/签发组织的合规证明
+// 路由: GET|POST /api/v1/orgs/{org_id}/compliance-certificates
+// POST 需管理者，为已结账期间签发证明，签发人取 X-User-ID
+func (h *ComplianceHandler) Certificates(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, h.store.ListComplianceCertificates(orgID))
+
+	case http.MethodPost:
+		if !requireManager(w, r) {
+			return
+		}
+		var req IssueCertificateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		c, err := h.service.Issue(orgID, req.StartDate, req.EndDate, r.Header.Get(AuthorHeader))
+		if err != nil {
+			respondComplianceError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, c)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Certificate 获取合规证明，format=pdf 时下载 PDF
+// 路由: GET /api/v1/orgs/{org_id}/compliance-certificates/{id}
+func (h *ComplianceHandler) Certificate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的证明ID格式"))
+		return
+	}
+	c, err := h.store.GetComplianceCertificate(id)
+	if err != nil || c.OrgID != orgID {
+		respondError(w, errors.New(errors.CodeNotFound, "合规证明不存在"))
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		respondJSON(w, http.StatusOK, c)
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=compliance-%s-%s.pdf", c.StartDate, c.EndDate))
+		w.WriteHeader(http.StatusOK)
+		w.Write(compliance.RenderPDF(c))
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "format 仅支持 json/pdf"))
+	}
+}
+
+// Verify 校验合规证明的摘要和签名，请求体为证明 JSON
+// 路由: POST /api/v1/compliance-certificates/verify
+func (h *ComplianceHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	var c model.ComplianceCertificate
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if err := h.service.Verify(&c); err != nil {
+		respondJSON(w, http.StatusOK, VerifyCertificateResponse{Reason: err.Error()})
+		return
+	}
+	respondJSON(w, http.StatusOK, VerifyCertificateResponse{Valid: true})
+}
+
+// orgID 检查是否启用了排班存储并解析组织ID
+func (h *ComplianceHandler) orgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil || h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// respondComplianceError 将合规证明服务错误转换为响应
+func respondComplianceError(w http.ResponseWriter, err error) {
+	switch {
+	case stderrors.Is(err, compliance.ErrInvalidPeriod):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	case stderrors.Is(err, compliance.ErrPeriodOpen):
+		respondError(w, errors.New(errors.CodeValidationFail, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "签发合规证明失败"))
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/paiban/paiban/internal/config"
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// ConfigHandler 运行配置处理器
+type ConfigHandler struct {
+	config *config.Config
+}
+
+// NewConfigHandler 创建运行配置处理器
+func NewConfigHandler(cfg *config.Config) *ConfigHandler {
+	return &ConfigHandler{config: cfg}
+}
+
+// Status 查询生效的配置（仅管理员），密码、密钥已脱敏，Webhook 地址只保留主机
+// 路由: GET /api/v1/admin/config
+// 配置项名与配置文件一致，时长为 30s 形式的字符串
+func (h *ConfigHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	respondJSON(w, http.StatusOK, h.config.Redacted().Values())
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/constraints"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ConstraintConfigHandler 组织约束配置版本与差异对比处理器
+type ConstraintConfigHandler struct {
+	store *memstore.Store
+}
+
+// NewConstraintConfigHandler 创建约束配置处理器
+func NewConstraintConfigHandler(store *memstore.Store) *ConstraintConfigHandler {
+	return &ConstraintConfigHandler{store: store}
+}
+
+// SaveConstraintConfigRequest 保存组织约束配置请求
+type SaveConstraintConfigRequest struct {
+	Config map[string]interface{} `json:"config"`
+	Note   string                 `json:"note,omitempty"`
+}
+
+// ConfigRef 参与对比的约束配置：组织配置版本（version 为 0 表示最新版本）或直接给出的配置
+type ConfigRef struct {
+	OrgID   string                 `json:"org_id,omitempty"`
+	Version int                    `json:"version,omitempty"`
+	Config  map[string]interface{} `json:"config,omitempty"`
+}
+
+// ConfigDiffRequest 约束配置对比请求
+type ConfigDiffRequest struct {
+	Base   ConfigRef `json:"base"`
+	Target ConfigRef `json:"target"`
+}
+
+// ConfigDiffResponse 约束配置对比响应（base/target 回显实际参与对比的版本）
+type ConfigDiffResponse struct {
+	Base   ConfigRef              `json:"base"`
+	Target ConfigRef              `json:"target"`
+	Diff   constraints.ConfigDiff `json:"diff"`
+}
+
+// OrgConfig 查询/保存组织约束配置（保存需管理者，每次保存生成新版本）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/constraint-config?version=
+func (h *ConstraintConfigHandler) OrgConfig(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		version := 0
+		if v := r.URL.Query().Get("version"); v != "" {
+			if version, err = strconv.Atoi(v); err != nil || version < 1 {
+				respondError(w, errors.New(errors.CodeInvalidInput, "version 必须为正整数"))
+				return
+			}
+		}
+		cfg, err := h.store.GetConstraintConfig(orgID, version)
+		if err != nil {
+			respondError(w, errors.New(errors.CodeNotFound, "组织约束配置版本不存在"))
+			return
+		}
+		respondJSON(w, http.StatusOK, cfg)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var req SaveConstraintConfigRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if req.Config == nil {
+			respondError(w, errors.New(errors.CodeInvalidInput, "config 不能为空"))
+			return
+		}
+		cfg, err := h.store.AddConstraintConfig(&model.ConstraintConfigVersion{
+			OrgID:     orgID,
+			Config:    req.Config,
+			Note:      req.Note,
+			CreatedBy: r.Header.Get(AuthorHeader),
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "保存约束配置失败"))
+			return
+		}
+		recordAudit(w, r, orgID, model.AuditConstraintChange, "constraint_config", fmt.Sprintf("%s@%d", orgID, cfg.Version), map[string]interface{}{
+			"version": cfg.Version,
+			"note":    cfg.Note,
+			"config":  cfg.Config,
+		})
+		respondJSON(w, http.StatusOK, cfg)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// OrgConfigVersions 列出组织约束配置的全部版本
+// 路由: GET /api/v1/orgs/{org_id}/constraint-config/versions
+func (h *ConstraintConfigHandler) OrgConfigVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	respondJSON(w, http.StatusOK, h.store.ListConstraintConfigs(orgID))
+}
+
+// ScheduleCycle 查询/设置组织的排班周期（设置需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/schedule-cycle
+// 两周/四周轮班的组织按周期计算工时上限、周末/夜班公平性和倒班轮换，排班请求的 cycle_weeks 可单独覆盖
+func (h *ConstraintConfigHandler) ScheduleCycle(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil || org.ScheduleCycle == nil {
+			respondError(w, errors.New(errors.CodeNotFound, "组织未配置排班周期"))
+			return
+		}
+		respondJSON(w, http.StatusOK, org.ScheduleCycle)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var cycle model.ScheduleCycle
+		if err := json.NewDecoder(r.Body).Decode(&cycle); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := cycle.Validate(); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil {
+			org = &model.Organization{BaseModel: model.NewBaseModel()}
+			org.ID = orgID
+		}
+		org.ScheduleCycle = &cycle
+		org.UpdatedAt = time.Now()
+		h.store.PutOrganization(org)
+		recordAudit(w, r, orgID, model.AuditConstraintChange, "schedule_cycle", orgID.String(), map[string]interface{}{
+			"schedule_cycle": org.ScheduleCycle,
+		})
+		respondJSON(w, http.StatusOK, org.ScheduleCycle)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// Diff 对比两份约束配置，输出新增/删除/变更的约束及参数级差异
+// 路由: POST /api/v1/constraints/diff
+// base/target 可为组织配置版本（组织对组织、同一组织的两个版本）或直接给出的配置
+func (h *ConstraintConfigHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	var req ConfigDiffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+
+	base, appErr := h.resolve("base", &req.Base)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	target, appErr := h.resolve("target", &req.Target)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+
+	resp := ConfigDiffResponse{
+		Base:   ConfigRef{OrgID: req.Base.OrgID, Version: req.Base.Version},
+		Target: ConfigRef{OrgID: req.Target.OrgID, Version: req.Target.Version},
+		Diff:   constraints.Diff(base, target),
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// resolve 取出参与对比的配置；引用组织版本时回填实际版本号
+func (h *ConstraintConfigHandler) resolve(side string, ref *ConfigRef) (map[string]interface{}, *errors.AppError) {
+	if ref.OrgID == "" {
+		if ref.Config == nil {
+			return nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("%s 需指定 org_id 或 config", side))
+		}
+		return ref.Config, nil
+	}
+	if ref.Config != nil {
+		return nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("%s 的 org_id 与 config 不能同时指定", side))
+	}
+	if h.store == nil {
+		return nil, errors.New(errors.CodeNotFound, "未启用排班存储")
+	}
+	orgID, err := uuid.Parse(ref.OrgID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidInput, fmt.Sprintf("%s 的组织ID格式无效", side))
+	}
+	cfg, err := h.store.GetConstraintConfig(orgID, ref.Version)
+	if err != nil {
+		return nil, errors.New(errors.CodeNotFound, fmt.Sprintf("%s 的组织约束配置版本不存在", side))
+	}
+	ref.Version = cfg.Version
+	return cfg.Config, nil
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/costing"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+)
+
+// CostResponse 人工成本响应
+type CostResponse struct {
+	Success bool             `json:"success"`
+	Data    *costing.Summary `json:"data,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// GetCostHandler 人工成本计算API：按员工时薪拆分正常、加班和节假日工时，按员工、岗位和日期汇总费用
+// 计算参数取自请求的 cost_config（键与排班约束配置一致），未指定 holidays 时使用存储中组织的自定义节假日
+func GetCostHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 加班按员工的日、周累计工时计算，需保留全部分配
+	var assignments []*model.Assignment
+	req, count, err := decodeStatsRequest(r, func(*StatsRequest) func(*model.Assignment) {
+		return func(a *model.Assignment) { assignments = append(assignments, a) }
+	})
+	if err != nil {
+		sendJSONError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("接收人工成本计算请求: org_id=%s, employees=%d, assignments=%d",
+		req.OrgID, len(req.Employees), count)
+
+	config := req.CostConfig
+	if _, ok := config["holidays"]; !ok && workloadStore != nil {
+		if orgID, err := uuid.Parse(req.OrgID); err == nil {
+			if stored := storedHolidays(workloadStore, orgID); len(stored) > 0 {
+				config = mergeConfig(config, map[string]interface{}{"holidays": stored})
+			}
+		}
+	}
+	summary := builtin.ConfigCostCalculator(config).Calculate(req.Employees, assignments)
+
+	resp := CostResponse{
+		Success: true,
+		Data:    summary,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
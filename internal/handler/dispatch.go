@@ -5,8 +5,14 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/order"
+	"github.com/paiban/paiban/pkg/alias"
 	"github.com/paiban/paiban/pkg/dispatcher"
+	"github.com/paiban/paiban/pkg/dispatcher/routing"
 	"github.com/paiban/paiban/pkg/model"
 )
 
@@ -17,7 +23,14 @@ type DispatchRequest struct {
 	Customer    *model.Customer                 `json:"customer,omitempty"`
 	TodayOrders []*model.ServiceOrder           `json:"today_orders,omitempty"`
 	History     []model.CustomerEmployeeHistory `json:"history,omitempty"`
+	KeepApart   []model.KeepApartRule           `json:"keep_apart,omitempty"` // 不可同组规则（团队订单）
 	MaxResults  int                             `json:"max_results,omitempty"`
+
+	// 客户滚动窗口内已有的服务人员，未提供时按已保存的订单记录统计
+	WindowCaregivers []uuid.UUID `json:"window_caregivers,omitempty"`
+
+	// 派单约束配置（参数、权重、启用状态），覆盖组织保存的配置中的同名约束
+	Constraints model.DispatchConstraintConfig `json:"constraints,omitempty"`
 }
 
 // BatchDispatchRequest 批量派单请求
@@ -25,6 +38,13 @@ type BatchDispatchRequest struct {
 	Orders     []*model.ServiceOrder `json:"orders"`
 	Candidates []*model.Employee     `json:"candidates"`
 	Customer   *model.Customer       `json:"customer,omitempty"`
+	KeepApart  []model.KeepApartRule `json:"keep_apart,omitempty"` // 不可同组规则（团队订单）
+
+	// Optimization 优化方式：greedy（默认，按订单顺序逐单派出）或 global（全部订单联合优化）
+	Optimization string `json:"optimization,omitempty"`
+
+	// 派单约束配置（参数、权重、启用状态），覆盖组织保存的配置中的同名约束
+	Constraints model.DispatchConstraintConfig `json:"constraints,omitempty"`
 }
 
 // DispatchAPIResponse 派单API响应
@@ -32,6 +52,8 @@ type DispatchAPIResponse struct {
 	Success bool                         `json:"success"`
 	Data    *dispatcher.DispatchResponse `json:"data,omitempty"`
 	Error   string                       `json:"error,omitempty"`
+
+	UnmappedLabels []model.UnmappedLabel `json:"unmapped_labels,omitempty"` // 未映射或无人具备的技能标签
 }
 
 // BatchDispatchAPIResponse 批量派单API响应
@@ -40,6 +62,8 @@ type BatchDispatchAPIResponse struct {
 	Data    []*dispatcher.DispatchResponse `json:"data,omitempty"`
 	Summary *BatchSummary                  `json:"summary,omitempty"`
 	Error   string                         `json:"error,omitempty"`
+
+	UnmappedLabels []model.UnmappedLabel `json:"unmapped_labels,omitempty"` // 未映射或无人具备的技能标签
 }
 
 // BatchSummary 批量派单汇总
@@ -48,12 +72,64 @@ type BatchSummary struct {
 	SuccessCount      int `json:"success_count"`
 	FailCount         int `json:"fail_count"`
 	AssignedEmployees int `json:"assigned_employees"`
+
+	Quality *dispatcher.BatchQuality `json:"quality,omitempty"` // 派单质量指标
 }
 
-var dispatchEngine *dispatcher.DispatchEngine
+var (
+	dispatchEngine *dispatcher.DispatchEngine
+	statusTracker  *dispatcher.StatusTracker
+	dispatchStore  *memstore.Store // 标签别名来源，为空时不做别名归一化
+)
 
 func init() {
+	statusTracker = dispatcher.NewStatusTracker(dispatcher.DefaultStatusTTL)
 	dispatchEngine = dispatcher.NewDispatchEngine()
+	dispatchEngine.SetStatusTracker(statusTracker)
+}
+
+// SetTravelProvider 设置派单和路线规划使用的路程估算服务
+func SetTravelProvider(provider dispatcher.TravelTimeProvider) {
+	dispatchEngine.SetTravelProvider(provider)
+}
+
+// SetDispatchStore 设置派单使用的内存存储（用于按组织归一化技能/资质标签）
+func SetDispatchStore(store *memstore.Store) {
+	dispatchStore = store
+}
+
+// normalizeDispatch 按订单所属组织的别名归一化订单和候选人标签，返回未映射的标签
+func normalizeDispatch(orders []*model.ServiceOrder, candidates []*model.Employee) []model.UnmappedLabel {
+	orgID := uuid.Nil
+	if len(orders) > 0 && orders[0] != nil {
+		orgID = orders[0].OrgID
+	}
+	var aliases []*model.LabelAlias
+	if dispatchStore != nil && orgID != uuid.Nil {
+		aliases = dispatchStore.ListAliases(orgID)
+	}
+	norm := alias.NewNormalizer(orgID, aliases)
+	for _, emp := range candidates {
+		norm.Employee(emp)
+	}
+	for _, order := range orders {
+		norm.Order(order)
+	}
+	unmapped := norm.Unmapped()
+	if dispatchStore != nil && orgID != uuid.Nil {
+		dispatchStore.RecordUnmapped(orgID, unmapped)
+	}
+	return unmapped
+}
+
+// windowCaregivers 按已保存的订单记录统计客户在滚动窗口内已有的服务人员（窗口截至订单服务日期）
+// 未启用存储或订单未填写组织/客户时返回 nil
+func windowCaregivers(o *model.ServiceOrder, customer *model.Customer) []uuid.UUID {
+	if dispatchStore == nil || o == nil || o.OrgID == uuid.Nil || o.CustomerID == uuid.Nil {
+		return nil
+	}
+	days, _ := customer.CaregiverWindow()
+	return order.NewService(dispatchStore).Caregivers(o.OrgID, o.CustomerID, o.ServiceDate, days)
 }
 
 // DispatchHandler 单个订单派单
@@ -79,7 +155,14 @@ func DispatchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	engine, err := dispatchEngineFor(req.Order.OrgID, req.Constraints)
+	if err != nil {
+		sendDispatchError(w, "Invalid constraints: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	log.Printf("接收派单请求: order=%s, candidates=%d", req.Order.OrderNo, len(req.Candidates))
+	unmapped := normalizeDispatch([]*model.ServiceOrder{req.Order}, req.Candidates)
 
 	// 构建派单请求
 	dispReq := &dispatcher.DispatchRequest{
@@ -88,16 +171,23 @@ func DispatchHandler(w http.ResponseWriter, r *http.Request) {
 		Customer:       req.Customer,
 		TodayOrders:    req.TodayOrders,
 		ServiceHistory: req.History,
+		KeepApart:      req.KeepApart,
 		MaxResults:     req.MaxResults,
+
+		WindowCaregivers: req.WindowCaregivers,
+	}
+	if len(dispReq.WindowCaregivers) == 0 {
+		dispReq.WindowCaregivers = windowCaregivers(req.Order, req.Customer)
 	}
 
 	// 执行派单
-	resp := dispatchEngine.Dispatch(dispReq)
+	resp := engine.Dispatch(dispReq)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(DispatchAPIResponse{
-		Success: resp.Success,
-		Data:    resp,
+		Success:        resp.Success,
+		Data:           resp,
+		UnmappedLabels: unmapped,
 	})
 }
 
@@ -124,10 +214,43 @@ func BatchDispatchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("接收批量派单请求: orders=%d, candidates=%d", len(req.Orders), len(req.Candidates))
+	switch req.Optimization {
+	case "":
+		req.Optimization = dispatcher.OptimizationGreedy
+	case dispatcher.OptimizationGreedy, dispatcher.OptimizationGlobal:
+	default:
+		sendDispatchError(w, "optimization must be one of greedy, global", http.StatusBadRequest)
+		return
+	}
+
+	var orgID uuid.UUID
+	if req.Orders[0] != nil {
+		orgID = req.Orders[0].OrgID
+	}
+	engine, err := dispatchEngineFor(orgID, req.Constraints)
+	if err != nil {
+		sendDispatchError(w, "Invalid constraints: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("接收批量派单请求: orders=%d, candidates=%d, optimization=%s", len(req.Orders), len(req.Candidates), req.Optimization)
+	unmapped := normalizeDispatch(req.Orders, req.Candidates)
+
+	// 各客户滚动窗口内已有的服务人员
+	caregivers := make(map[uuid.UUID][]uuid.UUID)
+	for _, o := range req.Orders {
+		if _, ok := caregivers[o.CustomerID]; !ok && o.CustomerID != uuid.Nil {
+			caregivers[o.CustomerID] = windowCaregivers(o, req.Customer)
+		}
+	}
 
 	// 执行批量派单
-	responses := dispatchEngine.BatchDispatch(req.Orders, req.Candidates, req.Customer)
+	var responses []*dispatcher.DispatchResponse
+	if req.Optimization == dispatcher.OptimizationGlobal {
+		responses = engine.BatchDispatchGlobal(req.Orders, req.Candidates, req.Customer, req.KeepApart, caregivers)
+	} else {
+		responses = engine.BatchDispatchWithCaregivers(req.Orders, req.Candidates, req.Customer, req.KeepApart, caregivers)
+	}
 
 	// 统计结果
 	summary := &BatchSummary{
@@ -141,35 +264,137 @@ func BatchDispatchHandler(w http.ResponseWriter, r *http.Request) {
 			if resp.BestMatch != nil {
 				assignedMap[resp.BestMatch.Employee.ID.String()] = true
 			}
+			for _, m := range resp.Crew {
+				assignedMap[m.Employee.ID.String()] = true
+			}
 		} else {
 			summary.FailCount++
 		}
 	}
 	summary.AssignedEmployees = len(assignedMap)
+	summary.Quality = dispatcher.EvaluateBatch(responses, req.Optimization)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(BatchDispatchAPIResponse{
-		Success: true,
-		Data:    responses,
-		Summary: summary,
+		Success:        true,
+		Data:           responses,
+		Summary:        summary,
+		UnmappedLabels: unmapped,
 	})
 }
 
-// OptimalRouteRequest 最优路线请求
+// ReassignRequest 改派请求：订单的服务人员临时不可用时寻找替补，并给出其当日后续订单的改派方案
+type ReassignRequest struct {
+	Order                 *model.ServiceOrder             `json:"order"`
+	UnavailableEmployeeID uuid.UUID                       `json:"unavailable_employee_id,omitempty"` // 默认为订单的带队人
+	Candidates            []*model.Employee               `json:"candidates"`
+	Customer              *model.Customer                 `json:"customer,omitempty"`
+	Customers             []*model.Customer               `json:"customers,omitempty"`    // 后续订单的客户
+	TodayOrders           []*model.ServiceOrder           `json:"today_orders,omitempty"` // 当日订单，含不可用人员的后续订单
+	History               []model.CustomerEmployeeHistory `json:"history,omitempty"`
+	MaxResults            int                             `json:"max_results,omitempty"`
+
+	// 派单约束配置（参数、权重、启用状态），覆盖组织保存的配置中的同名约束
+	Constraints model.DispatchConstraintConfig `json:"constraints,omitempty"`
+}
+
+// ReassignAPIResponse 改派API响应
+type ReassignAPIResponse struct {
+	Success bool                       `json:"success"`
+	Data    *dispatcher.ReassignResult `json:"data,omitempty"`
+	Error   string                     `json:"error,omitempty"`
+
+	UnmappedLabels []model.UnmappedLabel `json:"unmapped_labels,omitempty"` // 未映射或无人具备的技能标签
+}
+
+// ReassignHandler 服务人员临时不可用时改派订单
+// 路由: POST /api/v1/dispatch/reassign
+func ReassignHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ReassignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendReassignError(w, "Invalid request: "+err.Error())
+		return
+	}
+
+	if req.Order == nil {
+		sendReassignError(w, "Order is required")
+		return
+	}
+	if len(req.Candidates) == 0 {
+		sendReassignError(w, "At least one candidate is required")
+		return
+	}
+	unavailable := req.UnavailableEmployeeID
+	if unavailable == uuid.Nil && req.Order.EmployeeID != nil {
+		unavailable = *req.Order.EmployeeID
+	}
+	if unavailable == uuid.Nil || !req.Order.HasEmployee(unavailable) {
+		sendReassignError(w, "unavailable_employee_id must be assigned to the order")
+		return
+	}
+
+	engine, err := dispatchEngineFor(req.Order.OrgID, req.Constraints)
+	if err != nil {
+		sendReassignError(w, "Invalid constraints: "+err.Error())
+		return
+	}
+
+	log.Printf("接收改派请求: order=%s, unavailable=%s, candidates=%d", req.Order.OrderNo, unavailable, len(req.Candidates))
+	unmapped := normalizeDispatch(append([]*model.ServiceOrder{req.Order}, req.TodayOrders...), req.Candidates)
+
+	result := engine.Reassign(&dispatcher.ReassignRequest{
+		Order:                 req.Order,
+		UnavailableEmployeeID: unavailable,
+		Candidates:            req.Candidates,
+		Customer:              req.Customer,
+		Customers:             req.Customers,
+		TodayOrders:           req.TodayOrders,
+		ServiceHistory:        req.History,
+		MaxResults:            req.MaxResults,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ReassignAPIResponse{
+		Success:        result.Success,
+		Data:           result,
+		UnmappedLabels: unmapped,
+	})
+}
+
+// sendReassignError 发送改派请求错误
+func sendReassignError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ReassignAPIResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// OptimalRouteRequest 最优路线请求（单个服务人员一天内的订单）
 type OptimalRouteRequest struct {
 	Orders        []*model.ServiceOrder `json:"orders"`
 	StartLocation *model.Location       `json:"start_location"`
+	StartTime     string                `json:"start_time,omitempty"`      // 出发时间（HH:MM），默认按第一站时间窗倒推
+	SpeedKmh      float64               `json:"speed_kmh,omitempty"`       // 平均速度，默认 30km/h（配置路网路程服务时不使用）
+	ReturnToStart bool                  `json:"return_to_start,omitempty"` // 是否返回出发点
 }
 
 // OptimalRouteResponse 最优路线响应
 type OptimalRouteResponse struct {
 	Success       bool                  `json:"success"`
 	Orders        []*model.ServiceOrder `json:"orders,omitempty"`
+	Route         *routing.Route        `json:"route,omitempty"` // 逐站路线和预计到达时间
 	TotalDistance float64               `json:"total_distance_km,omitempty"`
 	Error         string                `json:"error,omitempty"`
 }
 
-// OptimalRouteHandler 计算最优路线
+// OptimalRouteHandler 计算最优路线：在遵守订单时间窗的前提下最小化路程，返回访问顺序和各站预计到达时间
 func OptimalRouteHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -178,12 +403,7 @@ func OptimalRouteHandler(w http.ResponseWriter, r *http.Request) {
 
 	var req OptimalRouteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(OptimalRouteResponse{
-			Success: false,
-			Error:   "Invalid request: " + err.Error(),
-		})
+		sendRouteError(w, "Invalid request: "+err.Error())
 		return
 	}
 
@@ -197,12 +417,114 @@ func OptimalRouteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 计算最优路线
-	optimizedOrders := dispatchEngine.OptimalRoute(req.Orders, req.StartLocation)
+	route, err := routing.Optimize(req.Orders, req.StartLocation, routing.Options{
+		StartTime:     req.StartTime,
+		SpeedKmh:      req.SpeedKmh,
+		ReturnToStart: req.ReturnToStart,
+		Travel:        dispatchEngine.TravelProvider(),
+	})
+	if err != nil {
+		sendRouteError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(OptimalRouteResponse{
+		Success:       true,
+		Orders:        route.Sequence(),
+		Route:         route,
+		TotalDistance: route.TotalDistanceKm,
+	})
+}
 
+// sendRouteError 发送路线请求错误
+func sendRouteError(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
 	json.NewEncoder(w).Encode(OptimalRouteResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// EmployeeStatusAPIResponse 员工实时状态API响应
+type EmployeeStatusAPIResponse struct {
+	Success bool                        `json:"success"`
+	Data    []*model.EmployeeLiveStatus `json:"data,omitempty"`
+	Error   string                      `json:"error,omitempty"`
+}
+
+// EmployeeStatusHandler 员工实时状态
+// POST 上报状态（移动端心跳），GET 查询未过期状态（可按 employee_id 过滤）
+func EmployeeStatusHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		reportEmployeeStatus(w, r)
+	case http.MethodGet:
+		listEmployeeStatus(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// reportEmployeeStatus 上报员工状态
+func reportEmployeeStatus(w http.ResponseWriter, r *http.Request) {
+	var status model.EmployeeLiveStatus
+	if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+		sendStatusError(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if status.EmployeeID == uuid.Nil {
+		sendStatusError(w, "employee_id is required", http.StatusBadRequest)
+		return
+	}
+	if !model.IsValidLiveStatus(status.Status) {
+		sendStatusError(w, "status must be one of on_duty, on_break, off_duty, en_route", http.StatusBadRequest)
+		return
+	}
+	// 以服务端时间为准，避免客户端时钟偏差导致状态提前过期
+	status.ReportedAt = time.Now()
+
+	statusTracker.Report(&status)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(EmployeeStatusAPIResponse{
 		Success: true,
-		Orders:  optimizedOrders,
+		Data:    []*model.EmployeeLiveStatus{&status},
+	})
+}
+
+// listEmployeeStatus 查询员工状态
+func listEmployeeStatus(w http.ResponseWriter, r *http.Request) {
+	var statuses []*model.EmployeeLiveStatus
+	if idStr := r.URL.Query().Get("employee_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			sendStatusError(w, "Invalid employee_id", http.StatusBadRequest)
+			return
+		}
+		if status := statusTracker.Get(id); status != nil {
+			statuses = append(statuses, status)
+		}
+	} else {
+		statuses = statusTracker.List()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(EmployeeStatusAPIResponse{
+		Success: true,
+		Data:    statuses,
+	})
+}
+
+// sendStatusError 发送状态接口错误
+func sendStatusError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(EmployeeStatusAPIResponse{
+		Success: false,
+		Error:   message,
 	})
 }
 
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/dispatcher"
+	"github.com/paiban/paiban/pkg/dispatcher/constraint"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// DispatchConstraintsResponse 组织派单约束配置响应
+type DispatchConstraintsResponse struct {
+	OrgID       string                         `json:"org_id"`
+	Constraints model.DispatchConstraintConfig `json:"constraints"`
+}
+
+// DispatchConstraintLibraryHandler 返回派单约束库（全部派单约束、默认权重及参数定义）
+// 路由: GET /api/v1/dispatch/constraints/library
+func DispatchConstraintLibraryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	respondJSON(w, http.StatusOK, constraint.LibraryResponse{Library: constraint.Library()})
+}
+
+// DispatchConstraintsHandler 查询或保存组织的派单约束配置，派单请求的 constraints 在其基础上覆盖
+// 路由: GET|PUT /api/v1/orgs/{org_id}/dispatch-constraints
+func DispatchConstraintsHandler(w http.ResponseWriter, r *http.Request) {
+	if dispatchStore == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		config := orgDispatchConstraints(orgID)
+		if config == nil {
+			config = model.DispatchConstraintConfig{}
+		}
+		respondJSON(w, http.StatusOK, DispatchConstraintsResponse{OrgID: orgID.String(), Constraints: config})
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var config model.DispatchConstraintConfig
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := constraint.Validate(config); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+
+		org, err := dispatchStore.GetOrganization(orgID)
+		if err != nil {
+			org = &model.Organization{BaseModel: model.NewBaseModel()}
+			org.ID = orgID
+		}
+		org.DispatchConstraints = config
+		org.UpdatedAt = time.Now()
+		dispatchStore.PutOrganization(org)
+		recordAudit(w, r, orgID, model.AuditConstraintChange, "dispatch_constraints", orgID.String(), map[string]interface{}{
+			"constraints": config,
+		})
+		respondJSON(w, http.StatusOK, DispatchConstraintsResponse{OrgID: orgID.String(), Constraints: config})
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// orgDispatchConstraints 获取组织保存的派单约束配置，未启用存储或未配置时返回 nil
+func orgDispatchConstraints(orgID uuid.UUID) model.DispatchConstraintConfig {
+	if dispatchStore == nil || orgID == uuid.Nil {
+		return nil
+	}
+	org, err := dispatchStore.GetOrganization(orgID)
+	if err != nil {
+		return nil
+	}
+	return org.DispatchConstraints
+}
+
+// dispatchEngineFor 按组织保存的派单约束配置和请求的 constraints（覆盖同名约束）返回派单引擎，
+// 均未配置时返回默认引擎；配置无效时返回错误
+func dispatchEngineFor(orgID uuid.UUID, override model.DispatchConstraintConfig) (*dispatcher.DispatchEngine, error) {
+	config := orgDispatchConstraints(orgID).Merge(override)
+	if len(config) == 0 {
+		return dispatchEngine, nil
+	}
+	constraints, err := constraint.Build(config)
+	if err != nil {
+		return nil, err
+	}
+	return dispatchEngine.WithConstraints(constraints), nil
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/docalert"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// DocumentAlertHandler 证件到期提醒处理器
+type DocumentAlertHandler struct {
+	store   *memstore.Store
+	service *docalert.Service
+}
+
+// NewDocumentAlertHandler 创建证件到期提醒处理器
+func NewDocumentAlertHandler(store *memstore.Store, service *docalert.Service) *DocumentAlertHandler {
+	return &DocumentAlertHandler{
+		store:   store,
+		service: service,
+	}
+}
+
+// Alerts 查询组织的证件到期提醒
+// 路由: GET /api/v1/orgs/{org_id}/document-alerts?date=YYYY-MM-DD（默认今天）
+func (h *DocumentAlertHandler) Alerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	alerts, err := h.service.Alerts(orgID, date)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+		return
+	}
+	respondJSON(w, http.StatusOK, alerts)
+}
+
+// Policy 查询/设置组织的证件到期提醒策略（设置需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/document-alert-policy
+func (h *DocumentAlertHandler) Policy(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil || org.DocumentAlertPolicy == nil {
+			respondError(w, errors.New(errors.CodeNotFound, "组织未配置证件到期提醒策略"))
+			return
+		}
+		respondJSON(w, http.StatusOK, org.DocumentAlertPolicy)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var policy model.DocumentAlertPolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := policy.Validate(); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil {
+			org = &model.Organization{BaseModel: model.NewBaseModel()}
+			org.ID = orgID
+		}
+		org.DocumentAlertPolicy = &policy
+		org.UpdatedAt = time.Now()
+		h.store.PutOrganization(org)
+		respondJSON(w, http.StatusOK, org.DocumentAlertPolicy)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// orgID 检查存储是否启用并解析组织ID
+func (h *DocumentAlertHandler) orgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil || h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
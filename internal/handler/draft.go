@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/draft"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/internal/publication"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// AuthorHeader 修改人请求头，记录在排班修订记录中
+const AuthorHeader = "X-User-ID"
+
+// DraftHandler 排班草稿编辑处理器
+type DraftHandler struct {
+	store  *memstore.Store
+	editor *draft.Editor
+	check  publication.Checker // 回滚后按当前约束复核硬约束（可选）
+}
+
+// NewDraftHandler 创建排班草稿编辑处理器
+func NewDraftHandler(store *memstore.Store) *DraftHandler {
+	h := &DraftHandler{store: store}
+	if store != nil {
+		h.editor = draft.NewEditor(store)
+	}
+	return h
+}
+
+// SetNotifier 设置通知发送器，设置后保存的分配修改（含换班）发送 assignment_changed 通知
+func (h *DraftHandler) SetNotifier(notifier notify.Notifier) {
+	if h.editor != nil {
+		h.editor.SetNotifier(notifier)
+	}
+}
+
+// SetChecker 设置硬约束复核，设置后回滚结果按组织当前约束复核并列出违反的分配
+func (h *DraftHandler) SetChecker(check publication.Checker) {
+	h.check = check
+}
+
+// EditAssignmentsRequest 分配修改请求
+// 基准版本优先取 If-Match 请求头，其次取 base_version
+type EditAssignmentsRequest struct {
+	BaseVersion *int                     `json:"base_version,omitempty"`
+	Changes     []model.AssignmentChange `json:"changes"`
+}
+
+// GetSchedule 获取排班，响应头 ETag 为排班版本
+// 路由: GET /api/v1/schedules/{id}
+func (h *DraftHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.store.GetSchedule(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+	w.Header().Set("ETag", versionETag(schedule.Version))
+	respondJSON(w, http.StatusOK, schedule)
+}
+
+// EditAssignments 修改排班分配（乐观并发控制）
+// 路由: PATCH /api/v1/schedules/{id}/assignments
+// 基准版本不是最新版本时返回 409，客户端应重新获取排班或调用合并接口
+func (h *DraftHandler) EditAssignments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持PATCH方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+	req, baseVersion, ok := decodeEditRequest(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := h.editor.Edit(id, baseVersion, r.Header.Get(AuthorHeader), req.Changes)
+	if err != nil {
+		h.respondEditError(w, id, err)
+		return
+	}
+	recordAudit(w, r, schedule.OrgID, model.AuditAssignmentEdit, "schedule", id.String(), map[string]interface{}{
+		"base_version": baseVersion,
+		"version":      schedule.Version,
+		"changes":      req.Changes,
+	})
+	w.Header().Set("ETag", versionETag(schedule.Version))
+	respondJSON(w, http.StatusOK, schedule)
+}
+
+// Merge 合并基于旧版本的分配修改
+// 路由: POST /api/v1/schedules/{id}/merge
+// 不冲突的修改会被应用；与他人修改冲突的变更在 conflicts 中返回，需人工处理
+func (h *DraftHandler) Merge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+	req, baseVersion, ok := decodeEditRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.editor.Merge(id, baseVersion, r.Header.Get(AuthorHeader), req.Changes)
+	if err != nil {
+		h.respondEditError(w, id, err)
+		return
+	}
+	recordAudit(w, r, result.Schedule.OrgID, model.AuditAssignmentMerge, "schedule", id.String(), map[string]interface{}{
+		"base_version": baseVersion,
+		"version":      result.Schedule.Version,
+		"applied":      result.Applied,
+		"conflicts":    len(result.Conflicts),
+	})
+	w.Header().Set("ETag", versionETag(result.Schedule.Version))
+	respondJSON(w, http.StatusOK, result)
+}
+
+// scheduleID 检查存储并解析排班ID，排班存在时检查调用方可访问其所属组织
+func (h *DraftHandler) scheduleID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式"))
+		return uuid.Nil, false
+	}
+	if schedule, err := h.store.GetSchedule(id); err == nil && !authorizeOrg(w, r, schedule.OrgID) {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondEditError 将编辑错误转换为响应
+func (h *DraftHandler) respondEditError(w http.ResponseWriter, id uuid.UUID, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+	case err == memstore.ErrVersionConflict:
+		appErr := errors.New(errors.CodeScheduleConflict, "排班已被他人修改")
+		if current, getErr := h.store.GetSchedule(id); getErr == nil {
+			w.Header().Set("ETag", versionETag(current.Version))
+			appErr = appErr.WithDetails(fmt.Sprintf("当前版本 %d，请重新获取排班或调用合并接口", current.Version))
+		}
+		respondError(w, appErr)
+	case err == draft.ErrNotDraft, err == draft.ErrNotRestorable:
+		respondError(w, errors.New(errors.CodeScheduleConflict, err.Error()))
+	case err == draft.ErrVersionNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, err.Error()))
+	case stderrors.Is(err, draft.ErrPeriodClosed):
+		respondError(w, errors.New(errors.CodeForbidden, "工资期间已结账").WithDetails(err.Error()))
+	case stderrors.Is(err, draft.ErrInvalidChange):
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "分配变更无效").WithDetails(err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "保存排班失败"))
+	}
+}
+
+// decodeEditRequest 解析修改请求和基准版本
+func decodeEditRequest(w http.ResponseWriter, r *http.Request) (*EditAssignmentsRequest, int, bool) {
+	var req EditAssignmentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return nil, 0, false
+	}
+	if len(req.Changes) == 0 {
+		respondError(w, errors.New(errors.CodeInvalidInput, "changes 不能为空"))
+		return nil, 0, false
+	}
+
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseVersionETag(ifMatch)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的 If-Match"))
+			return nil, 0, false
+		}
+		return &req, version, true
+	}
+	if req.BaseVersion == nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "需要 If-Match 请求头或 base_version"))
+		return nil, 0, false
+	}
+	return &req, *req.BaseVersion, true
+}
+
+// versionETag 版本号转 ETag
+func versionETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseVersionETag 解析 ETag 中的版本号，兼容弱校验前缀 W/
+func parseVersionETag(etag string) (int, error) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	return strconv.Atoi(strings.Trim(etag, `"`))
+}
@@ -0,0 +1,604 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// maxEmployeeImportRows 单次批量导入的最大员工数
+const maxEmployeeImportRows = 5000
+
+// EmployeeHandler 员工档案处理器
+// 配置数据库时读写 EmployeeRepository（删除为软删除），否则使用内存存储
+type EmployeeHandler struct {
+	repo  repository.EmployeeRepositoryInterface
+	store *memstore.Store
+}
+
+// NewEmployeeHandler 创建员工档案处理器，repo 为空时使用内存存储
+func NewEmployeeHandler(repo *repository.EmployeeRepository, store *memstore.Store) *EmployeeHandler {
+	h := &EmployeeHandler{store: store}
+	if repo != nil {
+		h.repo = repo
+	}
+	return h
+}
+
+// EmployeeRecordInput 员工档案的新增/更新请求，更新时只修改给出的字段
+type EmployeeRecordInput struct {
+	OrgID               string                     `json:"org_id,omitempty"` // 仅新增时使用
+	Name                *string                    `json:"name,omitempty"`
+	Code                *string                    `json:"code,omitempty"` // 工号，组织内唯一
+	Phone               *string                    `json:"phone,omitempty"`
+	Email               *string                    `json:"email,omitempty"`
+	Status              *string                    `json:"status,omitempty"`    // active/inactive/leave，默认 active
+	HireDate            *string                    `json:"hire_date,omitempty"` // YYYY-MM-DD，默认当天
+	Position            *string                    `json:"position,omitempty"`
+	Skills              []string                   `json:"skills,omitempty"`
+	Certifications      []string                   `json:"certifications,omitempty"`
+	HourlyRate          *float64                   `json:"hourly_rate,omitempty"`
+	StoreID             *string                    `json:"store_id,omitempty"`
+	Preferences         *model.EmployeePreferences `json:"preferences,omitempty"`
+	AvailabilityWindows []model.AvailabilityWindow `json:"availability_windows,omitempty"`
+
+	SkillLevels          []model.Skill         `json:"skill_levels,omitempty"`          // 技能等级
+	CertificationRecords []model.Certification `json:"certification_records,omitempty"` // 带有效期的证书
+}
+
+// EmployeeListResponse 员工列表响应
+type EmployeeListResponse struct {
+	Employees []*model.Employee `json:"employees"`
+	Total     int               `json:"total"`
+	Offset    int               `json:"offset"`
+	Limit     int               `json:"limit"`
+}
+
+// EmployeeImportRequest 批量导入员工请求
+type EmployeeImportRequest struct {
+	OrgID     string                `json:"org_id"`
+	Employees []EmployeeRecordInput `json:"employees"`
+}
+
+// EmployeeImportError 批量导入中失败的行
+type EmployeeImportError struct {
+	Row     int                    `json:"row"`
+	Code    string                 `json:"code,omitempty"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"` // 字段校验失败的原因
+}
+
+// EmployeeImportResponse 批量导入员工响应
+type EmployeeImportResponse struct {
+	Created int                   `json:"created"`
+	Updated int                   `json:"updated"`
+	Failed  int                   `json:"failed"`
+	Errors  []EmployeeImportError `json:"errors"`
+}
+
+// Collection 列出或新增员工
+// 未指定 org_id 时组织受限的调用方列出所属组织的员工
+// 路由: GET /api/v1/employees?org_id=&status=&position=&store_id=&skill=&q=&offset=&limit=
+// 路由: POST /api/v1/employees
+func (h *EmployeeHandler) Collection(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w, r)
+
+	case http.MethodPost:
+		var input EmployeeRecordInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		orgID, err := uuid.Parse(input.OrgID)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		emp, appErr := h.create(r.Context(), orgID, &input)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		respondJSON(w, http.StatusCreated, emp)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Item 获取、更新或删除员工
+// 路由: GET|PUT|DELETE /api/v1/employees/{employee_id}
+func (h *EmployeeHandler) Item(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("employee_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		emp, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, emp.OrgID) {
+			return
+		}
+		respondJSON(w, http.StatusOK, emp)
+
+	case http.MethodPut:
+		var input EmployeeRecordInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		emp, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, emp.OrgID) {
+			return
+		}
+		if appErr := h.update(r.Context(), emp, &input); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		respondJSON(w, http.StatusOK, emp)
+
+	case http.MethodDelete:
+		emp, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, emp.OrgID) {
+			return
+		}
+		// 数据库中为软删除，已保存排班中的员工关联不受影响；内存存储直接删除
+		if h.repo != nil {
+			err = h.repo.Delete(r.Context(), id)
+		} else {
+			err = h.store.DeleteEmployee(id)
+		}
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "删除员工失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"deleted": true,
+			"id":      id.String(),
+		})
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT/DELETE方法"))
+	}
+}
+
+// Import 批量导入员工，按工号新增或更新（已存在的工号只修改给出的字段）
+// 单行失败不影响其他行，失败原因在 errors 中按行号返回
+// 路由: POST /api/v1/employees/import
+func (h *EmployeeHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if !h.ready(w) {
+		return
+	}
+	var req EmployeeImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	orgID, err := uuid.Parse(req.OrgID)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	if len(req.Employees) == 0 {
+		respondError(w, errors.New(errors.CodeInvalidInput, "员工列表不能为空"))
+		return
+	}
+	if len(req.Employees) > maxEmployeeImportRows {
+		respondError(w, errors.New(errors.CodeInvalidInput, fmt.Sprintf("单次最多导入 %d 名员工", maxEmployeeImportRows)))
+		return
+	}
+
+	resp := EmployeeImportResponse{Errors: make([]EmployeeImportError, 0)}
+	for i := range req.Employees {
+		input := &req.Employees[i]
+		code := ""
+		if input.Code != nil {
+			code = strings.TrimSpace(*input.Code)
+		}
+		existing, appErr := h.findByCode(r.Context(), orgID, code)
+		if appErr == nil {
+			if existing != nil {
+				if appErr = h.update(r.Context(), existing, input); appErr == nil {
+					resp.Updated++
+				}
+			} else if _, appErr = h.create(r.Context(), orgID, input); appErr == nil {
+				resp.Created++
+			}
+		}
+		if appErr != nil {
+			resp.Failed++
+			resp.Errors = append(resp.Errors, EmployeeImportError{Row: i + 1, Code: code, Message: appErr.Message, Fields: appErr.Fields})
+		}
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// list 分页查询员工（按工号排序）
+func (h *EmployeeHandler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repository.DefaultListFilter()
+	filter.OrderBy, filter.OrderDir = "code", "asc"
+	orgID, ok := listOrgID(w, r)
+	if !ok {
+		return
+	}
+	if orgID == uuid.Nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	filter = filter.WithOrgID(orgID).WithStatus(query.Get("status"))
+	filter.Search = strings.TrimSpace(query.Get("q"))
+	filter.Extra = map[string]interface{}{
+		"position": query.Get("position"),
+		"store_id": query.Get("store_id"),
+		"skill":    query.Get("skill"),
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		filter = filter.WithOffset(offset)
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= 100 {
+		filter = filter.WithLimit(limit)
+	}
+
+	var employees []*model.Employee
+	var total int
+	var err error
+	if h.repo != nil {
+		employees, total, err = h.repo.List(r.Context(), filter)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询员工失败"))
+			return
+		}
+	} else {
+		employees, total = h.listStore(orgID, filter)
+	}
+	if employees == nil {
+		employees = make([]*model.Employee, 0)
+	}
+	respondJSON(w, http.StatusOK, EmployeeListResponse{Employees: employees, Total: total, Offset: filter.Offset, Limit: filter.Limit})
+}
+
+// listStore 按过滤条件分页查询内存存储中的员工
+func (h *EmployeeHandler) listStore(orgID uuid.UUID, filter repository.ListFilter) ([]*model.Employee, int) {
+	position, _ := filter.Extra["position"].(string)
+	storeID, _ := filter.Extra["store_id"].(string)
+	skill, _ := filter.Extra["skill"].(string)
+	search := strings.ToLower(filter.Search)
+
+	matched := make([]*model.Employee, 0)
+	for _, emp := range h.store.ListEmployees(orgID) {
+		if filter.Status != "" && emp.Status != filter.Status {
+			continue
+		}
+		if position != "" && emp.Position != position {
+			continue
+		}
+		if storeID != "" && emp.StoreID != storeID {
+			continue
+		}
+		if skill != "" && !emp.HasSkill(skill) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(emp.Name), search) &&
+			!strings.Contains(strings.ToLower(emp.Code), search) && !strings.Contains(emp.Phone, search) {
+			continue
+		}
+		matched = append(matched, emp)
+	}
+
+	total := len(matched)
+	if filter.Offset >= total {
+		return nil, total
+	}
+	end := filter.Offset + filter.Limit
+	if end > total {
+		end = total
+	}
+	return matched[filter.Offset:end], total
+}
+
+// get 获取员工，不存在时返回 404
+func (h *EmployeeHandler) get(ctx context.Context, id uuid.UUID) (*model.Employee, *errors.AppError) {
+	if h.repo != nil {
+		emp, err := h.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "查询员工失败")
+		}
+		if emp == nil {
+			return nil, errors.New(errors.CodeNotFound, "员工不存在")
+		}
+		return emp, nil
+	}
+	emp, err := h.store.GetEmployee(id)
+	if err != nil {
+		return nil, errors.New(errors.CodeNotFound, "员工不存在")
+	}
+	return emp, nil
+}
+
+// findByCode 按工号查找组织下的员工，不存在时返回 nil
+func (h *EmployeeHandler) findByCode(ctx context.Context, orgID uuid.UUID, code string) (*model.Employee, *errors.AppError) {
+	if code == "" {
+		return nil, nil
+	}
+	if h.repo != nil {
+		emp, err := h.repo.GetByCode(ctx, orgID, code)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "查询员工失败")
+		}
+		return emp, nil
+	}
+	for _, emp := range h.store.ListEmployees(orgID) {
+		if emp.Code == code {
+			return emp, nil
+		}
+	}
+	return nil, nil
+}
+
+// create 新增员工
+func (h *EmployeeHandler) create(ctx context.Context, orgID uuid.UUID, input *EmployeeRecordInput) (*model.Employee, *errors.AppError) {
+	emp := &model.Employee{
+		BaseModel: model.NewBaseModel(),
+		OrgID:     orgID,
+		Status:    "active",
+		HireDate:  time.Now().Format("2006-01-02"),
+	}
+	input.apply(emp)
+	if appErr := h.checkEmployee(ctx, emp); appErr != nil {
+		return nil, appErr
+	}
+	if h.repo != nil {
+		if err := h.repo.Create(ctx, emp); err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "保存员工失败")
+		}
+		return emp, nil
+	}
+	if err := h.store.PutEmployee(emp); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "保存员工失败")
+	}
+	return emp, nil
+}
+
+// update 将请求中给出的字段写入员工并保存（组织和ID不可修改）
+func (h *EmployeeHandler) update(ctx context.Context, emp *model.Employee, input *EmployeeRecordInput) *errors.AppError {
+	input.apply(emp)
+	if appErr := h.checkEmployee(ctx, emp); appErr != nil {
+		return appErr
+	}
+	emp.UpdatedAt = time.Now()
+	if h.repo != nil {
+		if err := h.repo.Update(ctx, emp); err != nil {
+			return errors.Wrap(err, errors.CodeInternal, "保存员工失败")
+		}
+		return nil
+	}
+	if err := h.store.PutEmployee(emp); err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "保存员工失败")
+	}
+	return nil
+}
+
+// checkEmployee 校验员工档案，工号在组织内须唯一
+func (h *EmployeeHandler) checkEmployee(ctx context.Context, emp *model.Employee) *errors.AppError {
+	if appErr := validateEmployeeRecord(emp); appErr != nil {
+		return appErr
+	}
+	other, appErr := h.findByCode(ctx, emp.OrgID, emp.Code)
+	if appErr != nil {
+		return appErr
+	}
+	if other != nil && other.ID != emp.ID {
+		return errors.New(errors.CodeAlreadyExists, "工号已存在: "+emp.Code)
+	}
+	return nil
+}
+
+// apply 将请求中给出的字段写入员工
+func (in *EmployeeRecordInput) apply(emp *model.Employee) {
+	if in.Name != nil {
+		emp.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Code != nil {
+		emp.Code = strings.TrimSpace(*in.Code)
+	}
+	if in.Phone != nil {
+		emp.Phone = *in.Phone
+	}
+	if in.Email != nil {
+		emp.Email = *in.Email
+	}
+	if in.Status != nil {
+		emp.Status = *in.Status
+	}
+	if in.HireDate != nil {
+		emp.HireDate = *in.HireDate
+	}
+	if in.Position != nil {
+		emp.Position = *in.Position
+	}
+	if in.Skills != nil {
+		emp.Skills = in.Skills
+	}
+	if in.Certifications != nil {
+		emp.Certifications = in.Certifications
+	}
+	if in.SkillLevels != nil {
+		emp.SkillLevels = in.SkillLevels
+	}
+	if in.CertificationRecords != nil {
+		emp.CertificationRecords = in.CertificationRecords
+	}
+	if in.HourlyRate != nil {
+		emp.HourlyRate = *in.HourlyRate
+	}
+	if in.StoreID != nil {
+		emp.StoreID = *in.StoreID
+	}
+	if in.Preferences != nil {
+		emp.Preferences = in.Preferences
+	}
+	if in.AvailabilityWindows != nil {
+		emp.AvailabilityWindows = in.AvailabilityWindows
+	}
+}
+
+// validateEmployeeRecord 校验员工档案字段
+func validateEmployeeRecord(emp *model.Employee) *errors.AppError {
+	ve := &errors.ValidationErrors{}
+	if emp.Name == "" {
+		ve.Add("name", "员工姓名不能为空")
+	}
+	if emp.Code == "" {
+		ve.Add("code", "工号不能为空")
+	}
+	switch emp.Status {
+	case "active", "inactive", "leave":
+	default:
+		ve.Add("status", "员工状态须为 active、inactive 或 leave")
+	}
+	if _, err := time.Parse("2006-01-02", emp.HireDate); err != nil {
+		ve.Add("hire_date", "入职日期格式须为 YYYY-MM-DD")
+	}
+	if emp.HourlyRate < 0 {
+		ve.Add("hourly_rate", "时薪不能为负数")
+	}
+	for _, w := range emp.AvailabilityWindows {
+		if !validClock(w.Start) || !validClock(w.End) {
+			ve.Add("availability_windows", fmt.Sprintf("可用时间窗口格式无效: %s-%s", w.Start, w.End))
+		}
+	}
+	for _, skill := range emp.SkillLevels {
+		if err := skill.Validate(); err != nil {
+			ve.Add("skill_levels", err.Error())
+		}
+	}
+	for _, cert := range emp.CertificationRecords {
+		if err := cert.Validate(); err != nil {
+			ve.Add("certification_records", err.Error())
+		}
+	}
+	if ve.HasErrors() {
+		return ve.ToAppError()
+	}
+	return nil
+}
+
+// ready 检查是否启用了存储
+func (h *EmployeeHandler) ready(w http.ResponseWriter) bool {
+	if h.repo == nil && h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// withStoredEmployees 排班请求未提供员工列表时，使用组织在职员工的档案
+// 配置数据库时读取 EmployeeRepository，否则读取内存存储
+func (h *ScheduleHandler) withStoredEmployees(ctx context.Context, req *GenerateRequest) *errors.AppError {
+	if len(req.Employees) > 0 {
+		return nil
+	}
+	orgID, err := uuid.Parse(req.OrgID)
+	if err != nil {
+		return nil // 由请求校验报告组织ID格式错误
+	}
+	var employees []*model.Employee
+	switch {
+	case h.employeeRepo != nil:
+		if employees, err = h.employeeRepo.ListActive(ctx, orgID); err != nil {
+			return errors.Wrap(err, errors.CodeInternal, "查询员工档案失败")
+		}
+	case h.store != nil:
+		for _, emp := range h.store.ListEmployees(orgID) {
+			if emp.Status == "" || emp.Status == "active" {
+				employees = append(employees, emp)
+			}
+		}
+	}
+	for _, emp := range employees {
+		req.Employees = append(req.Employees, toEmployeeInput(emp))
+	}
+	return nil
+}
+
+// toEmployeeInput 将员工档案转换为排班请求中的员工
+func toEmployeeInput(emp *model.Employee) EmployeeInput {
+	return EmployeeInput{
+		ID:                  emp.ID.String(),
+		Name:                emp.Name,
+		Position:            emp.Position,
+		Skills:              emp.Skills,
+		Certifications:      emp.Certifications,
+		SkillLevels:         emp.SkillLevels,
+		Status:              emp.Status,
+		BirthDate:           emp.BirthDate,
+		HourlyRate:          emp.HourlyRate,
+		StoreID:             emp.StoreID,
+		AllowedStores:       emp.AllowedStores,
+		MonthlyShiftsCounts: emp.MonthlyShiftsCounts,
+		Preferences:         emp.Preferences,
+		Contract:            emp.Contract,
+		AvailabilityWindows: emp.AvailabilityWindows,
+		UnavailableWindows:  emp.UnavailableWindows,
+		Leaves:              emp.Leaves,
+
+		CertificationRecords: emp.CertificationRecords,
+	}
+}
+
+// keepEmployeeRecord 保留员工档案中排班请求不包含的字段
+func keepEmployeeRecord(emp, record *model.Employee) {
+	if emp.Code == "" {
+		emp.Code = record.Code
+	}
+	if emp.Phone == "" {
+		emp.Phone = record.Phone
+	}
+	if emp.Email == "" {
+		emp.Email = record.Email
+	}
+	if emp.HireDate == "" {
+		emp.HireDate = record.HireDate
+	}
+	if emp.CreatedAt.IsZero() {
+		emp.CreatedAt = record.CreatedAt
+	}
+}
@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/exporter"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ExportHandler 排班表导出处理器（排班表网格：行=员工，列=日期，单元格=班次代码）
+// 按 schedule_id 导出时从内存存储读取排班，未找到且配置数据库时从 ScheduleRepository 读取
+type ExportHandler struct {
+	store *memstore.Store
+	repo  repository.ScheduleRepositoryInterface
+}
+
+// NewExportHandler 创建排班表导出处理器
+func NewExportHandler(store *memstore.Store, repo *repository.ScheduleRepository) *ExportHandler {
+	h := &ExportHandler{store: store}
+	if repo != nil {
+		h.repo = repo
+	}
+	return h
+}
+
+// ExportRequest 排班表导出请求：给出 schedule_id 导出已保存的排班，否则导出请求中的分配（如生成结果）
+type ExportRequest struct {
+	ScheduleID  string                `json:"schedule_id,omitempty"`
+	Format      string                `json:"format"`               // xlsx/csv，默认 xlsx
+	StartDate   string                `json:"start_date,omitempty"` // 默认为排班或分配的日期范围
+	EndDate     string                `json:"end_date,omitempty"`
+	Assignments []AssignmentOutput    `json:"assignments,omitempty"`
+	Unfilled    []UnfilledRequirement `json:"unfilled,omitempty"`
+	Shifts      []ShiftInput          `json:"shifts,omitempty"` // 单元格显示班次代码，未给出时显示班次名称
+}
+
+// Export 导出排班表
+// 路由: POST /api/v1/schedules/export
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	var req ExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	switch req.Format {
+	case "":
+		req.Format = exporter.FormatXLSX
+	case exporter.FormatXLSX, exporter.FormatCSV:
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "format 仅支持 xlsx/csv"))
+		return
+	}
+
+	var (
+		entries    []exporter.Entry
+		gaps       []exporter.Gap
+		start, end string
+		appErr     *errors.AppError
+	)
+	if req.ScheduleID != "" {
+		entries, gaps, start, end, appErr = h.loadSchedule(r.Context(), req.ScheduleID)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+	} else {
+		if len(req.Assignments) == 0 {
+			respondError(w, errors.New(errors.CodeInvalidInput, "schedule_id 和 assignments 不能同时为空"))
+			return
+		}
+		entries, gaps, start, end = exportEntries(req.Assignments, req.Unfilled, req.Shifts)
+	}
+	if req.StartDate != "" {
+		start = req.StartDate
+	}
+	if req.EndDate != "" {
+		end = req.EndDate
+	}
+
+	roster, err := exporter.BuildRoster(fmt.Sprintf("排班表 %s~%s", start, end), start, end, entries, gaps)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+		return
+	}
+	var buf bytes.Buffer
+	if req.Format == exporter.FormatCSV {
+		err = exporter.WriteCSV(&buf, roster)
+	} else {
+		err = exporter.WriteXLSX(&buf, roster)
+	}
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "生成导出文件失败"))
+		return
+	}
+
+	w.Header().Set("Content-Type", exporter.ContentType(req.Format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=schedule-%s-%s.%s", start, end, req.Format))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// loadSchedule 读取已保存排班的分配和未满足需求（数据库中的排班不含需求明细，不统计未满足人次）
+func (h *ExportHandler) loadSchedule(ctx context.Context, scheduleID string) ([]exporter.Entry, []exporter.Gap, string, string, *errors.AppError) {
+	id, err := uuid.Parse(scheduleID)
+	if err != nil {
+		return nil, nil, "", "", errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式")
+	}
+
+	if h.store != nil {
+		if schedule, err := h.store.GetSchedule(id); err == nil {
+			if appErr := orgAccessError(ctx, schedule.OrgID); appErr != nil {
+				return nil, nil, "", "", appErr
+			}
+			entries, gaps := h.storeEntries(schedule)
+			return entries, gaps, schedule.StartDate, schedule.EndDate, nil
+		}
+	}
+	if h.repo == nil {
+		return nil, nil, "", "", errors.New(errors.CodeNotFound, "排班不存在")
+	}
+
+	schedule, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, "", "", errors.Wrap(err, errors.CodeInternal, "查询排班失败")
+	}
+	if schedule == nil {
+		return nil, nil, "", "", errors.New(errors.CodeNotFound, "排班不存在")
+	}
+	if appErr := orgAccessError(ctx, schedule.OrgID); appErr != nil {
+		return nil, nil, "", "", appErr
+	}
+	assignments, err := h.repo.GetAssignments(ctx, id)
+	if err != nil {
+		return nil, nil, "", "", errors.Wrap(err, errors.CodeInternal, "查询排班分配失败")
+	}
+	var entries []exporter.Entry
+	for _, a := range assignments {
+		if a.Status == "cancelled" {
+			continue
+		}
+		entries = append(entries, exporter.Entry{
+			EmployeeID:   a.EmployeeID.String(),
+			EmployeeName: a.EmployeeName,
+			Position:     a.Position,
+			Date:         a.Date,
+			Start:        a.StartTime,
+			Code:         a.ShiftName,
+			Hours:        clockHours(a.StartTime, a.EndTime),
+		})
+	}
+	return entries, nil, schedule.StartDate, schedule.EndDate, nil
+}
+
+// storeEntries 内存存储中排班的分配，未满足需求按组织在排班期间的需求计算
+func (h *ExportHandler) storeEntries(schedule *model.Schedule) ([]exporter.Entry, []exporter.Gap) {
+	employees := make(map[uuid.UUID]*model.Employee)
+	for _, emp := range h.store.ListEmployees(schedule.OrgID) {
+		employees[emp.ID] = emp
+	}
+	shifts := make(map[uuid.UUID]*model.Shift)
+	for _, shift := range h.store.ListShifts(schedule.OrgID) {
+		shifts[shift.ID] = shift
+	}
+
+	var entries []exporter.Entry
+	assignments := make([]*model.Assignment, 0, len(schedule.Assignments))
+	for i := range schedule.Assignments {
+		a := &schedule.Assignments[i]
+		if a.Status == "cancelled" {
+			continue
+		}
+		assignments = append(assignments, a)
+		entry := exporter.Entry{
+			EmployeeID:   a.EmployeeID.String(),
+			EmployeeName: a.EmployeeID.String(),
+			Position:     a.Position,
+			Date:         a.Date,
+			Start:        a.StartTime.Format("15:04"),
+			Hours:        a.WorkingHours(),
+		}
+		if emp := employees[a.EmployeeID]; emp != nil {
+			entry.EmployeeName = emp.Name
+			if entry.Position == "" {
+				entry.Position = emp.Position
+			}
+		}
+		if shift := shifts[a.ShiftID]; shift != nil {
+			entry.Code = shiftLabel(shift.Code, shift.Name)
+		}
+		if entry.Code == "" {
+			entry.Code = entry.Start + "-" + a.EndTime.Format("15:04")
+		}
+		entries = append(entries, entry)
+	}
+
+	requirements := h.store.ListRequirements(schedule.OrgID, schedule.StartDate, schedule.EndDate)
+	var gaps []exporter.Gap
+	for _, u := range calculateUnfilledRequirements(requirements, assignments, nil, nil) {
+		gaps = append(gaps, exporter.Gap{Date: u.Date, Shortage: u.Shortage})
+	}
+	return entries, gaps
+}
+
+// exportEntries 请求中的分配和未满足需求，日期范围为分配和缺口的最早、最晚日期
+func exportEntries(assignments []AssignmentOutput, unfilled []UnfilledRequirement, shifts []ShiftInput) ([]exporter.Entry, []exporter.Gap, string, string) {
+	codes := make(map[string]string, len(shifts))
+	for _, s := range shifts {
+		codes[s.ID] = shiftLabel(s.Code, s.Name)
+	}
+
+	var start, end string
+	span := func(date string) {
+		if start == "" || date < start {
+			start = date
+		}
+		if date > end {
+			end = date
+		}
+	}
+	entries := make([]exporter.Entry, 0, len(assignments))
+	for _, a := range assignments {
+		code := codes[a.ShiftID]
+		if code == "" {
+			code = shiftLabel("", a.ShiftName)
+		}
+		if code == "" {
+			code = a.StartTime + "-" + a.EndTime
+		}
+		name := a.EmployeeName
+		if name == "" {
+			name = a.EmployeeID
+		}
+		hours := a.Hours
+		if hours == 0 {
+			hours = clockHours(a.StartTime, a.EndTime)
+		}
+		entries = append(entries, exporter.Entry{
+			EmployeeID:   a.EmployeeID,
+			EmployeeName: name,
+			Position:     a.Position,
+			Date:         a.Date,
+			Start:        a.StartTime,
+			Code:         code,
+			Hours:        hours,
+		})
+		span(a.Date)
+	}
+	gaps := make([]exporter.Gap, 0, len(unfilled))
+	for _, u := range unfilled {
+		gaps = append(gaps, exporter.Gap{Date: u.Date, Shortage: u.Shortage})
+		span(u.Date)
+	}
+	return entries, gaps, start, end
+}
+
+// shiftLabel 单元格中的班次标识：优先使用班次代码
+func shiftLabel(code, name string) string {
+	if code != "" {
+		return code
+	}
+	return name
+}
+
+// clockHours 按 HH:MM 上下班时间计算工时，跨午夜时加 24 小时，格式无效时返回 0
+func clockHours(start, end string) float64 {
+	s, err1 := time.Parse("15:04", start)
+	e, err2 := time.Parse("15:04", end)
+	if err1 != nil || err2 != nil {
+		return 0
+	}
+	if !e.After(s) {
+		e = e.Add(24 * time.Hour)
+	}
+	return e.Sub(s).Hours()
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ExternalPoolHandler 外部人员池处理器
+type ExternalPoolHandler struct {
+	store *memstore.Store
+}
+
+// NewExternalPoolHandler 创建外部人员池处理器
+func NewExternalPoolHandler(store *memstore.Store) *ExternalPoolHandler {
+	return &ExternalPoolHandler{store: store}
+}
+
+// ExternalWorkers 查询/替换组织的外部人员池（替换需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/external-workers
+// PUT 请求体为完整的外部人员池（workers、max_hours_per_period），会覆盖已有配置
+func (h *ExternalPoolHandler) ExternalWorkers(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		pool, err := h.store.GetExternalPool(orgID)
+		if err != nil {
+			pool = &model.ExternalPool{OrgID: orgID, Workers: []*model.ExternalWorker{}}
+		}
+		respondJSON(w, http.StatusOK, pool)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var pool model.ExternalPool
+		if err := json.NewDecoder(r.Body).Decode(&pool); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := pool.Validate(); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+		for _, worker := range pool.Workers {
+			for _, win := range worker.AvailabilityWindows {
+				if !validClock(win.Start) || !validClock(win.End) {
+					respondError(w, errors.New(errors.CodeInvalidInput, "外部人员 "+worker.Name+" 的可用时间窗口格式无效"))
+					return
+				}
+			}
+		}
+		if pool.Workers == nil {
+			pool.Workers = []*model.ExternalWorker{}
+		}
+		pool.OrgID = orgID
+		pool.UpdatedAt = time.Now()
+		if err := h.store.PutExternalPool(&pool); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "保存外部人员池失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, &pool)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// ExternalLaborSummary 外部人员用工汇总
+type ExternalLaborSummary struct {
+	Assignments int                     `json:"assignments"`
+	Hours       float64                 `json:"hours"`
+	Cost        float64                 `json:"cost"`
+	HoursCap    float64                 `json:"hours_cap,omitempty"` // 本期外部人员总工时上限
+	ByAgency    []ExternalAgencySummary `json:"by_agency"`
+}
+
+// ExternalAgencySummary 按派遣机构汇总的外部用工
+type ExternalAgencySummary struct {
+	Agency      string  `json:"agency"`
+	Assignments int     `json:"assignments"`
+	Hours       float64 `json:"hours"`
+	Cost        float64 `json:"cost"`
+}
+
+// externalWorkers 返回本次排班可用的外部人员（已转换为员工）及本期外部工时上限
+// 请求中指定了外部人员时使用请求中的名单，否则使用组织的外部人员池；options.no_external 为 true 时不使用外部人员。
+// 与内部员工ID重复的外部人员被忽略
+func (h *ScheduleHandler) externalWorkers(orgID uuid.UUID, req *GenerateRequest, internal map[uuid.UUID]*model.Employee) ([]*model.Employee, float64, *errors.AppError) {
+	if req.Options != nil && req.Options.NoExternal {
+		return nil, 0, nil
+	}
+	workers := req.ExternalWorkers
+	var hoursCap float64
+	if len(workers) > 0 {
+		for _, worker := range workers {
+			if worker == nil {
+				return nil, 0, errors.New(errors.CodeInvalidInput, "外部人员不能为空")
+			}
+			if err := worker.Validate(); err != nil {
+				return nil, 0, errors.Wrap(err, errors.CodeInvalidInput, err.Error())
+			}
+		}
+	} else if h.store != nil {
+		if pool, err := h.store.GetExternalPool(orgID); err == nil {
+			workers, hoursCap = pool.Workers, pool.MaxHoursPerPeriod
+		}
+	}
+	if req.Options != nil && req.Options.ExternalHoursCap > 0 {
+		hoursCap = req.Options.ExternalHoursCap
+	}
+
+	result := make([]*model.Employee, 0, len(workers))
+	for _, worker := range workers {
+		if _, ok := internal[worker.ID]; ok {
+			continue
+		}
+		result = append(result, worker.Employee(orgID))
+	}
+	return result, hoursCap, nil
+}
+
+// summarizeExternal 汇总外部人员的分配、工时和费用，没有外部分配时返回 nil
+func summarizeExternal(assignments []*model.Assignment, external map[uuid.UUID]*model.Employee, hoursCap float64) *ExternalLaborSummary {
+	summary := &ExternalLaborSummary{HoursCap: hoursCap, ByAgency: make([]ExternalAgencySummary, 0)}
+	byAgency := make(map[string]*ExternalAgencySummary)
+	for _, a := range assignments {
+		emp, ok := external[a.EmployeeID]
+		if !ok {
+			continue
+		}
+		hours := a.WorkingHours()
+		cost := hours * emp.HourlyRate
+		summary.Assignments++
+		summary.Hours += hours
+		summary.Cost += cost
+		agency, ok := byAgency[emp.External.Agency]
+		if !ok {
+			agency = &ExternalAgencySummary{Agency: emp.External.Agency}
+			byAgency[emp.External.Agency] = agency
+		}
+		agency.Assignments++
+		agency.Hours += hours
+		agency.Cost += cost
+	}
+	if summary.Assignments == 0 {
+		return nil
+	}
+	summary.Cost = roundCost(summary.Cost)
+	for _, agency := range byAgency {
+		agency.Cost = roundCost(agency.Cost)
+		summary.ByAgency = append(summary.ByAgency, *agency)
+	}
+	sort.Slice(summary.ByAgency, func(i, j int) bool { return summary.ByAgency[i].Agency < summary.ByAgency[j].Agency })
+	return summary
+}
+
+// roundCost 费用保留两位小数
+func roundCost(cost float64) float64 {
+	return math.Round(cost*100) / 100
+}
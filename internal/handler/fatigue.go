@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/fatigue"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// EmployeeFatigue 员工疲劳指数（带姓名）
+type EmployeeFatigue struct {
+	fatigue.Index
+	EmployeeName string `json:"employee_name"`
+}
+
+// EmployeeFatigue 查询员工当前疲劳指数
+// 路由: GET /api/v1/employees/{employee_id}/fatigue?as_of=&include_drafts=
+// as_of 为 RFC3339 时间或 YYYY-MM-DD（当天零点），默认当前时间；默认只统计已发布排班
+func (h *AnalyticsHandler) EmployeeFatigue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	employeeID, err := uuid.Parse(r.PathValue("employee_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式"))
+		return
+	}
+	emp, err := h.store.GetEmployee(employeeID)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "员工不存在"))
+		return
+	}
+	asOf, appErr := parseAsOf(r.URL.Query().Get("as_of"))
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+
+	byEmployee := h.recentAssignments(emp.OrgID, r.URL.Query().Get("include_drafts") == "true")
+	idx := fatigue.NewScorer(fatigue.Config{}).Score(emp.ID, byEmployee[emp.ID], asOf)
+	respondJSON(w, http.StatusOK, EmployeeFatigue{Index: idx, EmployeeName: emp.Name})
+}
+
+// OrgFatigue 查询组织全部在职员工的疲劳指数（按指数降序）
+// 路由: GET /api/v1/orgs/{org_id}/fatigue?as_of=&include_drafts=
+func (h *AnalyticsHandler) OrgFatigue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	asOf, appErr := parseAsOf(r.URL.Query().Get("as_of"))
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+
+	scorer := fatigue.NewScorer(fatigue.Config{})
+	byEmployee := h.recentAssignments(orgID, r.URL.Query().Get("include_drafts") == "true")
+	result := make([]EmployeeFatigue, 0)
+	for _, emp := range h.store.ListEmployees(orgID) {
+		if emp.Status != "" && !emp.IsActive() {
+			continue
+		}
+		result = append(result, EmployeeFatigue{
+			Index:        scorer.Score(emp.ID, byEmployee[emp.ID], asOf),
+			EmployeeName: emp.Name,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	respondJSON(w, http.StatusOK, result)
+}
+
+// recentAssignments 按员工汇总组织排班中的分配（已发布，可选包含草稿），同一分配只计一次
+func (h *AnalyticsHandler) recentAssignments(orgID uuid.UUID, includeDrafts bool) map[uuid.UUID][]*model.Assignment {
+	result := make(map[uuid.UUID][]*model.Assignment)
+	seen := make(map[uuid.UUID]bool)
+	for _, schedule := range h.store.ListSchedules(orgID) {
+		if schedule.Status != "published" && !(includeDrafts && schedule.Status == "draft") {
+			continue
+		}
+		for i := range schedule.Assignments {
+			a := &schedule.Assignments[i]
+			if a.Status == "cancelled" || seen[a.ID] {
+				continue
+			}
+			seen[a.ID] = true
+			result[a.EmployeeID] = append(result[a.EmployeeID], a)
+		}
+	}
+	return result
+}
+
+// parseAsOf 解析疲劳指数的计算时间点
+func parseAsOf(value string) (time.Time, *errors.AppError) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, errors.CodeInvalidInput, "as_of 格式应为 RFC3339 或 YYYY-MM-DD")
+	}
+	return t, nil
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/forecast"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ForecastRequirementsRequest 需求预测请求
+// 历史数据二选一：history 为已执行的排班（按班次、岗位统计每天在岗人数），
+// traffic 为每天各班次的业务量（销售额、客流等），配合 productivity 换算人数
+type ForecastRequirementsRequest struct {
+	StartDate    string            `json:"start_date"`
+	EndDate      string            `json:"end_date"`
+	History      []AssignmentInput `json:"history,omitempty"`
+	Traffic      []TrafficInput    `json:"traffic,omitempty"`
+	Productivity float64           `json:"productivity,omitempty"` // 人效：每人每天处理的业务量（traffic 必填）
+	Window       int               `json:"window,omitempty"`       // 移动平均窗口（天），默认 28
+	MinStaff     int               `json:"min_staff,omitempty"`    // 有需求的日期至少安排的人数
+}
+
+// TrafficInput 一天的业务量
+type TrafficInput struct {
+	ShiftID  string  `json:"shift_id"`
+	Position string  `json:"position,omitempty"`
+	Date     string  `json:"date"`
+	Value    float64 `json:"value"`
+}
+
+// ForecastRequirementsResponse 需求预测结果
+type ForecastRequirementsResponse struct {
+	// 可直接用作生成排班请求的 requirements
+	Requirements []RequirementInput    `json:"requirements"`
+	Suggestions  []forecast.Suggestion `json:"suggestions"` // 每条需求的预测明细
+}
+
+// ForecastRequirements 根据历史排班或业务量预测日期范围内的班次需求，不执行排班
+// 路由: POST /api/v1/requirements/forecast
+func (h *ScheduleHandler) ForecastRequirements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+
+	var req ForecastRequirementsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+
+	observations, appErr := forecastObservations(req)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	suggestions, err := forecast.Forecast(observations, req.StartDate, req.EndDate, forecast.Options{
+		Window:       req.Window,
+		Productivity: req.Productivity,
+		MinStaff:     req.MinStaff,
+	})
+	if err != nil {
+		if stderrors.Is(err, forecast.ErrInvalidInput) {
+			respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+			return
+		}
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "需求预测失败"))
+		return
+	}
+
+	resp := ForecastRequirementsResponse{Requirements: make([]RequirementInput, 0, len(suggestions)), Suggestions: suggestions}
+	for _, s := range suggestions {
+		resp.Requirements = append(resp.Requirements, RequirementInput{
+			ShiftID:      s.ShiftID.String(),
+			Date:         s.Date,
+			Position:     s.Position,
+			MinEmployees: s.MinEmployees,
+			MaxEmployees: s.MaxEmployees,
+		})
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// forecastObservations 将请求中的历史排班或业务量转换为预测观测值
+func forecastObservations(req ForecastRequirementsRequest) ([]forecast.Observation, *errors.AppError) {
+	if (len(req.History) == 0) == (len(req.Traffic) == 0) {
+		return nil, errors.New(errors.CodeInvalidInput, "history 和 traffic 须且只能提供一项")
+	}
+	if len(req.Traffic) > 0 {
+		if req.Productivity <= 0 {
+			return nil, errors.New(errors.CodeInvalidInput, "使用业务量预测时 productivity 应大于 0")
+		}
+		observations := make([]forecast.Observation, 0, len(req.Traffic))
+		for i, t := range req.Traffic {
+			shiftID, err := uuid.Parse(t.ShiftID)
+			if err != nil {
+				return nil, errors.Wrap(err, errors.CodeInvalidInput, fmt.Sprintf("第 %d 条业务量的班次ID无效", i+1))
+			}
+			observations = append(observations, forecast.Observation{ShiftID: shiftID, Position: t.Position, Date: t.Date, Value: t.Value})
+		}
+		return observations, nil
+	}
+
+	if req.Productivity > 0 {
+		return nil, errors.New(errors.CodeInvalidInput, "按历史排班预测时不使用 productivity")
+	}
+	assignments := make([]*model.Assignment, 0, len(req.History))
+	for i, a := range req.History {
+		shiftID, err := uuid.Parse(a.ShiftID)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInvalidInput, fmt.Sprintf("第 %d 条历史排班的班次ID无效", i+1))
+		}
+		empID, err := uuid.Parse(a.EmployeeID)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInvalidInput, fmt.Sprintf("第 %d 条历史排班的员工ID无效", i+1))
+		}
+		assignments = append(assignments, &model.Assignment{EmployeeID: empID, ShiftID: shiftID, Date: a.Date, Position: a.Position})
+	}
+	return forecast.FromAssignments(assignments), nil
+}
@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/genjob"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// asyncGenerateTimeout 异步生成未指定 timeout_seconds 时的求解超时（秒）
+const asyncGenerateTimeout = 600
+
+// sseHeartbeat 进度事件流的心跳间隔，避免代理因连接空闲而断开
+const sseHeartbeat = 15 * time.Second
+
+// GenerateJobHandler 异步排班生成作业处理器
+type GenerateJobHandler struct {
+	store  *memstore.Store
+	runner *genjob.Runner
+}
+
+// NewGenerateJobHandler 创建异步排班生成作业处理器
+func NewGenerateJobHandler(store *memstore.Store, runner *genjob.Runner) *GenerateJobHandler {
+	return &GenerateJobHandler{
+		store:  store,
+		runner: runner,
+	}
+}
+
+// GenerateJobProcessor 返回异步作业的排班生成函数：与同步生成相同，未指定超时时使用较长的默认超时
+func GenerateJobProcessor(schedule *ScheduleHandler) genjob.Processor {
+	return func(ctx context.Context, job *model.GenerateJob, progress solver.ProgressHook) (json.RawMessage, string, error) {
+		var req GenerateRequest
+		if err := json.Unmarshal(job.Request, &req); err != nil {
+			return nil, "", errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败")
+		}
+		if req.Options == nil {
+			req.Options = &GenerateOptions{}
+		}
+		if req.Options.Timeout <= 0 {
+			req.Options.Timeout = asyncGenerateTimeout
+		}
+		resp, appErr := schedule.generate(solver.WithProgress(ctx, progress), &req)
+		if appErr != nil {
+			return nil, "", appErr
+		}
+		result, err := json.Marshal(resp)
+		if err != nil {
+			return nil, "", err
+		}
+		return result, resp.ScheduleID, nil
+	}
+}
+
+// Jobs 查询排班生成作业
+// 路由: GET /api/v1/schedule/jobs?org_id=
+func (h *GenerateJobHandler) Jobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !h.ready(w) {
+		return
+	}
+	orgID, ok := listOrgID(w, r)
+	if !ok {
+		return
+	}
+	jobs := h.store.ListGenerateJobs(orgID)
+	summaries := make([]*model.GenerateJob, len(jobs))
+	for i, job := range jobs {
+		summaries[i] = job.Summary()
+	}
+	respondJSON(w, http.StatusOK, summaries)
+}
+
+// Job 查询排班生成作业的状态和进度
+// 路由: GET /api/v1/schedule/jobs/{id}
+func (h *GenerateJobHandler) Job(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+	if !job.IsFinished() {
+		w.Header().Set("Retry-After", "1")
+	}
+	respondJSON(w, http.StatusOK, job.Summary())
+}
+
+// Result 获取排班生成作业的结果（与同步生成的响应相同）
+// 路由: GET /api/v1/schedule/jobs/{id}/result
+// 作业未完成时返回 202 和作业进度；失败时返回作业的错误
+func (h *GenerateJobHandler) Result(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+	switch job.Status {
+	case model.GenerateJobCompleted:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(job.Result)
+	case model.GenerateJobFailed:
+		code := errors.Code(job.ErrorCode)
+		if code == "" {
+			code = errors.CodeInternal
+		}
+		respondError(w, errors.New(code, job.Error))
+	case model.GenerateJobCancelled:
+		respondError(w, errors.New(errors.CodeAlreadyExists, "排班生成作业已取消"))
+	default:
+		w.Header().Set("Retry-After", "1")
+		respondJSON(w, http.StatusAccepted, job.Summary())
+	}
+}
+
+// Events 以 Server-Sent Events 推送排班生成作业的求解进度
+// 路由: GET /api/v1/schedule/jobs/{id}/events
+// 先发送 status 事件（作业当前状态），求解中每次报告进度发送 progress 事件（阶段、轮次、分配数、当前得分），
+// 作业结束（完成、失败或取消）时发送 done 事件（作业最终状态）后关闭连接
+func (h *GenerateJobHandler) Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+	events, unsubscribe, err := h.runner.Subscribe(job.ID)
+	if err != nil {
+		respondGenerateJobError(w, err)
+		return
+	}
+	defer unsubscribe()
+
+	// 事件流持续到作业结束，不受服务器写超时限制
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	writeEvent(w, "status", job.Summary())
+	rc.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			rc.Flush()
+		case p, ok := <-events:
+			if !ok {
+				if final, err := h.store.GetGenerateJob(job.ID); err == nil {
+					writeEvent(w, "done", final.Summary())
+					rc.Flush()
+				}
+				return
+			}
+			writeEvent(w, "progress", p)
+			rc.Flush()
+		}
+	}
+}
+
+// writeEvent 写出一条 Server-Sent Event，数据为 JSON
+func writeEvent(w http.ResponseWriter, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
+
+// Cancel 取消排班生成作业，正在求解的作业中断求解
+// 路由: POST /api/v1/schedule/jobs/{id}/cancel
+func (h *GenerateJobHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+	cancelled, err := h.runner.Cancel(job.ID)
+	if err != nil {
+		respondGenerateJobError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, cancelled)
+}
+
+// submitAsync 将已校验的生成请求保存为异步作业，返回 202 和作业进度
+func (h *ScheduleHandler) submitAsync(w http.ResponseWriter, r *http.Request, req *GenerateRequest) {
+	if h.jobs == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(req.OrgID)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	raw, err := json.Marshal(req)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "保存请求失败"))
+		return
+	}
+	job, err := h.jobs.Submit(orgID, raw, r.Header.Get(AuthorHeader))
+	if err != nil {
+		respondGenerateJobError(w, err)
+		return
+	}
+	recordAudit(w, r, orgID, model.AuditScheduleGenerate, "generate_job", job.ID.String(), map[string]interface{}{
+		"start_date": req.StartDate,
+		"end_date":   req.EndDate,
+		"async":      true,
+	})
+	w.Header().Set("Location", "/api/v1/schedule/jobs/"+job.ID.String())
+	w.Header().Set("Retry-After", "1")
+	respondJSON(w, http.StatusAccepted, job)
+}
+
+// job 按路径中的作业ID读取作业
+func (h *GenerateJobHandler) job(w http.ResponseWriter, r *http.Request) (*model.GenerateJob, bool) {
+	if !h.ready(w) {
+		return nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的作业ID格式"))
+		return nil, false
+	}
+	job, err := h.store.GetGenerateJob(id)
+	if err != nil {
+		respondGenerateJobError(w, err)
+		return nil, false
+	}
+	if !authorizeOrg(w, r, job.OrgID) {
+		return nil, false
+	}
+	return job, true
+}
+
+// ready 检查是否启用了排班存储
+func (h *GenerateJobHandler) ready(w http.ResponseWriter) bool {
+	if h.store == nil || h.runner == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// respondGenerateJobError 将排班生成作业错误转换为响应
+func respondGenerateJobError(w http.ResponseWriter, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "排班生成作业不存在"))
+	case stderrors.Is(err, genjob.ErrInvalidJob):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	case stderrors.Is(err, genjob.ErrJobFinished):
+		respondError(w, errors.New(errors.CodeAlreadyExists, fmt.Sprintf("%v，不能取消", err)))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "处理排班生成作业失败"))
+	}
+}
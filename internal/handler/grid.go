@@ -0,0 +1,394 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 网格分组方式
+const (
+	GridGroupNone     = "none"
+	GridGroupPosition = "position"
+	GridGroupStore    = "store"
+)
+
+// 网格单元格标记
+const (
+	GridFlagOvertime  = "overtime"
+	GridFlagSwapped   = "swapped"
+	GridFlagNight     = "night"
+	GridFlagCancelled = "cancelled"
+	GridFlagDouble    = "double_booked" // 同一天多个班次
+)
+
+// ScheduleGrid 排班网格视图（行=员工，列=日期）
+type ScheduleGrid struct {
+	ScheduleID string       `json:"schedule_id"`
+	OrgID      string       `json:"org_id"`
+	StartDate  string       `json:"start_date"`
+	EndDate    string       `json:"end_date"`
+	Status     string       `json:"status"`
+	Version    int          `json:"version"`
+	GroupBy    string       `json:"group_by"`
+	Columns    []GridColumn `json:"columns"`
+	Groups     []GridGroup  `json:"groups"`
+	Totals     *GridTotals  `json:"totals,omitempty"`
+}
+
+// GridColumn 日期列
+type GridColumn struct {
+	Date       string   `json:"date"`
+	Weekday    int      `json:"weekday"` // 0=周日
+	Weekend    bool     `json:"weekend"`
+	TotalHours *float64 `json:"total_hours,omitempty"`
+	Headcount  *int     `json:"headcount,omitempty"` // 当天上班人数
+}
+
+// GridGroup 行分组
+type GridGroup struct {
+	Key        string    `json:"key"`
+	Label      string    `json:"label"`
+	Rows       []GridRow `json:"rows"`
+	TotalHours *float64  `json:"total_hours,omitempty"`
+}
+
+// GridRow 员工行
+type GridRow struct {
+	EmployeeID   string     `json:"employee_id"`
+	EmployeeName string     `json:"employee_name"`
+	Position     string     `json:"position,omitempty"`
+	StoreID      string     `json:"store_id,omitempty"`
+	Cells        []GridCell `json:"cells"` // 与 columns 一一对应
+	TotalHours   *float64   `json:"total_hours,omitempty"`
+	ShiftCount   *int       `json:"shift_count,omitempty"`
+}
+
+// GridCell 单元格（某员工某天的班次）
+type GridCell struct {
+	Date   string      `json:"date"`
+	Shifts []GridShift `json:"shifts"`
+	Hours  float64     `json:"hours"`
+	Flags  []string    `json:"flags,omitempty"`
+}
+
+// GridShift 单元格中的班次
+type GridShift struct {
+	AssignmentID string   `json:"assignment_id"`
+	ShiftID      string   `json:"shift_id"`
+	Code         string   `json:"code"`
+	Name         string   `json:"name,omitempty"`
+	StartTime    string   `json:"start_time"`
+	EndTime      string   `json:"end_time"`
+	Hours        float64  `json:"hours"`
+	Flags        []string `json:"flags,omitempty"`
+}
+
+// GridTotals 网格合计
+type GridTotals struct {
+	TotalHours float64 `json:"total_hours"`
+	ShiftCount int     `json:"shift_count"`
+	Employees  int     `json:"employees"`
+}
+
+// GridHandler 排班网格视图处理器
+type GridHandler struct {
+	store *memstore.Store
+}
+
+// NewGridHandler 创建排班网格视图处理器
+func NewGridHandler(store *memstore.Store) *GridHandler {
+	return &GridHandler{store: store}
+}
+
+// Grid 获取排班网格视图
+// 路由: GET /api/v1/schedules/{id}/grid?group_by=none|position|store&totals=true&include_idle=false
+func (h *GridHandler) Grid(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式"))
+		return
+	}
+
+	query := r.URL.Query()
+	groupBy := query.Get("group_by")
+	switch groupBy {
+	case "":
+		groupBy = GridGroupNone
+	case GridGroupNone, GridGroupPosition, GridGroupStore:
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "group_by 应为 none/position/store"))
+		return
+	}
+
+	schedule, err := h.store.GetSchedule(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+	if !authorizeOrg(w, r, schedule.OrgID) {
+		return
+	}
+
+	employees := make(map[uuid.UUID]*model.Employee)
+	for _, emp := range h.store.ListEmployees(schedule.OrgID) {
+		employees[emp.ID] = emp
+	}
+	shifts := make(map[uuid.UUID]*model.Shift)
+	for _, shift := range h.store.ListShifts(schedule.OrgID) {
+		shifts[shift.ID] = shift
+	}
+
+	grid := BuildScheduleGrid(schedule, employees, shifts, groupBy, query.Get("totals") != "false", query.Get("include_idle") == "true")
+	w.Header().Set("ETag", versionETag(schedule.Version))
+	respondJSON(w, http.StatusOK, grid)
+}
+
+// BuildScheduleGrid 由排班分配计算网格视图
+// includeIdle 为 true 时包含当期无分配的在职员工
+func BuildScheduleGrid(schedule *model.Schedule, employees map[uuid.UUID]*model.Employee, shifts map[uuid.UUID]*model.Shift, groupBy string, withTotals, includeIdle bool) *ScheduleGrid {
+	grid := &ScheduleGrid{
+		ScheduleID: schedule.ID.String(),
+		OrgID:      schedule.OrgID.String(),
+		StartDate:  schedule.StartDate,
+		EndDate:    schedule.EndDate,
+		Status:     schedule.Status,
+		Version:    schedule.Version,
+		GroupBy:    groupBy,
+		Columns:    make([]GridColumn, 0),
+		Groups:     make([]GridGroup, 0),
+	}
+
+	// 列：排班周期内的每一天
+	colIndex := make(map[string]int)
+	start, err1 := time.Parse("2006-01-02", schedule.StartDate)
+	end, err2 := time.Parse("2006-01-02", schedule.EndDate)
+	if err1 == nil && err2 == nil {
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			colIndex[d.Format("2006-01-02")] = len(grid.Columns)
+			grid.Columns = append(grid.Columns, GridColumn{
+				Date:    d.Format("2006-01-02"),
+				Weekday: int(d.Weekday()),
+				Weekend: d.Weekday() == time.Saturday || d.Weekday() == time.Sunday,
+			})
+		}
+	}
+
+	rows := make(map[uuid.UUID]*GridRow)
+	newRow := func(empID uuid.UUID) *GridRow {
+		row := &GridRow{EmployeeID: empID.String(), Cells: make([]GridCell, len(grid.Columns))}
+		if emp, ok := employees[empID]; ok {
+			row.EmployeeName = emp.Name
+			row.Position = emp.Position
+			row.StoreID = emp.StoreID
+		}
+		for i, col := range grid.Columns {
+			row.Cells[i] = GridCell{Date: col.Date, Shifts: make([]GridShift, 0)}
+		}
+		rows[empID] = row
+		return row
+	}
+
+	if includeIdle {
+		for id, emp := range employees {
+			if emp.Status == "" || emp.IsActive() {
+				newRow(id)
+			}
+		}
+	}
+
+	for i := range schedule.Assignments {
+		a := &schedule.Assignments[i]
+		idx, ok := colIndex[a.Date]
+		if !ok {
+			continue
+		}
+		row, ok := rows[a.EmployeeID]
+		if !ok {
+			row = newRow(a.EmployeeID)
+		}
+		if row.Position == "" {
+			row.Position = a.Position
+		}
+
+		gs := GridShift{
+			AssignmentID: a.ID.String(),
+			ShiftID:      a.ShiftID.String(),
+			StartTime:    a.StartTime.Format("15:04"),
+			EndTime:      a.EndTime.Format("15:04"),
+			Hours:        a.WorkingHours(),
+		}
+		if shift, ok := shifts[a.ShiftID]; ok {
+			gs.Code = shift.Code
+			gs.Name = shift.Name
+			if shift.IsNightShift() {
+				gs.Flags = append(gs.Flags, GridFlagNight)
+			}
+		}
+		if a.IsOvertime {
+			gs.Flags = append(gs.Flags, GridFlagOvertime)
+		}
+		if a.IsSwapped {
+			gs.Flags = append(gs.Flags, GridFlagSwapped)
+		}
+		if a.Status == "cancelled" {
+			gs.Flags = append(gs.Flags, GridFlagCancelled)
+			gs.Hours = 0
+		}
+
+		cell := &row.Cells[idx]
+		cell.Shifts = append(cell.Shifts, gs)
+		cell.Hours = roundHours(cell.Hours + gs.Hours)
+	}
+
+	// 单元格标记：汇总班次标记，同一天多个有效班次标记为重复
+	for _, row := range rows {
+		for i := range row.Cells {
+			cell := &row.Cells[i]
+			active := 0
+			for _, s := range cell.Shifts {
+				cell.Flags = appendUnique(cell.Flags, s.Flags...)
+				if !containsString(s.Flags, GridFlagCancelled) {
+					active++
+				}
+			}
+			if active > 1 {
+				cell.Flags = appendUnique(cell.Flags, GridFlagDouble)
+			}
+		}
+	}
+
+	// 分组并排序：组按标签，组内按姓名
+	groups := make(map[string]*GridGroup)
+	for _, row := range rows {
+		key, label := gridGroupKey(row, groupBy)
+		g, ok := groups[key]
+		if !ok {
+			g = &GridGroup{Key: key, Label: label, Rows: make([]GridRow, 0)}
+			groups[key] = g
+		}
+		g.Rows = append(g.Rows, *row)
+	}
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		g := groups[key]
+		sort.Slice(g.Rows, func(i, j int) bool {
+			if g.Rows[i].EmployeeName != g.Rows[j].EmployeeName {
+				return g.Rows[i].EmployeeName < g.Rows[j].EmployeeName
+			}
+			return g.Rows[i].EmployeeID < g.Rows[j].EmployeeID
+		})
+		grid.Groups = append(grid.Groups, *g)
+	}
+
+	if withTotals {
+		addGridTotals(grid)
+	}
+	return grid
+}
+
+// addGridTotals 计算行、列、分组及整体合计
+func addGridTotals(grid *ScheduleGrid) {
+	colHours := make([]float64, len(grid.Columns))
+	colHeads := make([]int, len(grid.Columns))
+	totals := &GridTotals{}
+
+	for gi := range grid.Groups {
+		g := &grid.Groups[gi]
+		groupHours := 0.0
+		for ri := range g.Rows {
+			row := &g.Rows[ri]
+			rowHours, rowShifts := 0.0, 0
+			for ci, cell := range row.Cells {
+				for _, s := range cell.Shifts {
+					if !containsString(s.Flags, GridFlagCancelled) {
+						rowShifts++
+					}
+				}
+				rowHours += cell.Hours
+				colHours[ci] += cell.Hours
+				if cell.Hours > 0 {
+					colHeads[ci]++
+				}
+			}
+			rowHours = roundHours(rowHours)
+			row.TotalHours = &rowHours
+			row.ShiftCount = &rowShifts
+			groupHours += rowHours
+			totals.ShiftCount += rowShifts
+			totals.Employees++
+		}
+		groupHours = roundHours(groupHours)
+		g.TotalHours = &groupHours
+		totals.TotalHours += groupHours
+	}
+
+	for i := range grid.Columns {
+		hours, heads := roundHours(colHours[i]), colHeads[i]
+		grid.Columns[i].TotalHours = &hours
+		grid.Columns[i].Headcount = &heads
+	}
+	totals.TotalHours = roundHours(totals.TotalHours)
+	grid.Totals = totals
+}
+
+// gridGroupKey 返回行所属分组
+func gridGroupKey(row *GridRow, groupBy string) (string, string) {
+	switch groupBy {
+	case GridGroupPosition:
+		if row.Position == "" {
+			return "", "未设置岗位"
+		}
+		return row.Position, row.Position
+	case GridGroupStore:
+		if row.StoreID == "" {
+			return "", "未分配门店"
+		}
+		return row.StoreID, row.StoreID
+	default:
+		return "all", "全部员工"
+	}
+}
+
+// appendUnique 追加不重复的元素
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if !containsString(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// containsString 检查列表是否包含元素
+func containsString(list []string, item string) bool {
+	for _, s := range list {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// roundHours 工时保留两位小数
+func roundHours(h float64) float64 {
+	return math.Round(h*100) / 100
+}
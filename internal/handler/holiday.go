@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/holiday"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// HolidayHandler 节假日日历处理器（内置中国法定节假日 + 组织自定义节假日）
+type HolidayHandler struct {
+	store *memstore.Store
+}
+
+// NewHolidayHandler 创建节假日日历处理器
+func NewHolidayHandler(store *memstore.Store) *HolidayHandler {
+	return &HolidayHandler{store: store}
+}
+
+// HolidayListResponse 组织自定义节假日列表响应
+type HolidayListResponse struct {
+	Holidays []model.Holiday `json:"holidays"`
+	Total    int             `json:"total"`
+}
+
+// CalendarDay 节假日日历中的一天
+type CalendarDay struct {
+	Date string     `json:"date"`
+	Name string     `json:"name"`
+	Type string     `json:"type"`
+	Off  bool       `json:"off"`          // 是否休息日（调休上班日为 false）
+	Rate float64    `json:"premium_rate"` // 当天上班的加班倍率
+	ID   *uuid.UUID `json:"id,omitempty"` // 组织自定义节假日的ID，内置节假日为空
+}
+
+// HolidayCalendarResponse 节假日日历响应
+type HolidayCalendarResponse struct {
+	Year  int           `json:"year"`
+	Days  []CalendarDay `json:"days"`
+	Total int           `json:"total"`
+}
+
+// Calendar 查询某年的节假日日历：内置法定节假日叠加组织自定义节假日（同一天以自定义为准）
+// 路由: GET /api/v1/holidays?year=YYYY[&org_id=][&builtin=false]
+func (h *HolidayHandler) Calendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	query := r.URL.Query()
+	year := time.Now().Year()
+	if v := query.Get("year"); v != "" {
+		y, err := strconv.Atoi(v)
+		if err != nil || y < 1900 || y > 9999 {
+			respondError(w, errors.New(errors.CodeInvalidInput, "无效的年份"))
+			return
+		}
+		year = y
+	}
+
+	var custom []model.Holiday
+	if v := query.Get("org_id"); v != "" {
+		orgID, err := uuid.Parse(v)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		custom = h.orgHolidays(orgID)
+	}
+
+	calendar := holiday.NewCalendar(query.Get("builtin") != "false", custom)
+	days := make([]CalendarDay, 0)
+	for _, hd := range calendar.Year(year) {
+		day := CalendarDay{Date: hd.Date, Name: hd.Name, Type: hd.Type, Off: hd.IsOff(), Rate: calendar.Rate(hd.Date)}
+		if hd.ID != uuid.Nil {
+			id := hd.ID
+			day.ID = &id
+		}
+		days = append(days, day)
+	}
+	respondJSON(w, http.StatusOK, HolidayCalendarResponse{Year: year, Days: days, Total: len(days)})
+}
+
+// Collection 列出或新增组织自定义节假日
+// 路由: GET /api/v1/orgs/{org_id}/holidays[?year=YYYY]
+// 路由: POST /api/v1/orgs/{org_id}/holidays
+func (h *HolidayHandler) Collection(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		year := r.URL.Query().Get("year")
+		list := make([]model.Holiday, 0)
+		for _, hd := range h.store.ListHolidays(orgID) {
+			if year == "" || strings.HasPrefix(hd.Date, year+"-") {
+				list = append(list, *hd)
+			}
+		}
+		respondJSON(w, http.StatusOK, HolidayListResponse{Holidays: list, Total: len(list)})
+
+	case http.MethodPost:
+		if !requireManager(w, r) {
+			return
+		}
+		hd, ok := h.decode(w, r)
+		if !ok {
+			return
+		}
+		hd.ID, hd.OrgID = uuid.New(), orgID
+		hd.CreatedAt = time.Now()
+		hd.UpdatedAt = hd.CreatedAt
+		if !h.save(w, hd) {
+			return
+		}
+		respondJSON(w, http.StatusCreated, hd)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Item 获取、修改或删除组织自定义节假日
+// 路由: GET|PUT|DELETE /api/v1/orgs/{org_id}/holidays/{id}
+func (h *HolidayHandler) Item(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.holiday(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, existing)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		hd, ok := h.decode(w, r)
+		if !ok {
+			return
+		}
+		hd.ID, hd.OrgID, hd.CreatedAt = existing.ID, existing.OrgID, existing.CreatedAt
+		hd.UpdatedAt = time.Now()
+		if !h.save(w, hd) {
+			return
+		}
+		respondJSON(w, http.StatusOK, hd)
+
+	case http.MethodDelete:
+		if !requireManager(w, r) {
+			return
+		}
+		if err := h.store.DeleteHoliday(existing.ID); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "删除节假日失败"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT/DELETE方法"))
+	}
+}
+
+// decode 解析并校验节假日，未填写类型时为自定义假日
+func (h *HolidayHandler) decode(w http.ResponseWriter, r *http.Request) (*model.Holiday, bool) {
+	var hd model.Holiday
+	if err := json.NewDecoder(r.Body).Decode(&hd); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return nil, false
+	}
+	if hd.Type == "" {
+		hd.Type = model.HolidayCustom
+	}
+	if err := hd.Validate(); err != nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+		return nil, false
+	}
+	return &hd, true
+}
+
+// save 保存节假日，同一组织同一天只能有一个自定义节假日
+func (h *HolidayHandler) save(w http.ResponseWriter, hd *model.Holiday) bool {
+	for _, other := range h.store.ListHolidays(hd.OrgID) {
+		if other.Date == hd.Date && other.ID != hd.ID {
+			respondError(w, errors.New(errors.CodeAlreadyExists, "该日期已有自定义节假日: "+other.Name))
+			return false
+		}
+	}
+	if err := h.store.PutHoliday(hd); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "保存节假日失败"))
+		return false
+	}
+	return true
+}
+
+// holiday 获取组织的自定义节假日
+func (h *HolidayHandler) holiday(w http.ResponseWriter, r *http.Request) (*model.Holiday, bool) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的节假日ID格式"))
+		return nil, false
+	}
+	hd, err := h.store.GetHoliday(id)
+	if err != nil || hd.OrgID != orgID {
+		respondError(w, errors.New(errors.CodeNotFound, "节假日不存在"))
+		return nil, false
+	}
+	return hd, true
+}
+
+// orgID 检查存储是否启用并解析组织ID
+func (h *HolidayHandler) orgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// orgHolidays 返回组织的自定义节假日，未启用存储时为空
+func (h *HolidayHandler) orgHolidays(orgID uuid.UUID) []model.Holiday {
+	if h.store == nil {
+		return nil
+	}
+	return storedHolidays(h.store, orgID)
+}
+
+// storedHolidays 返回存储中组织的自定义节假日
+func storedHolidays(store *memstore.Store, orgID uuid.UUID) []model.Holiday {
+	stored := store.ListHolidays(orgID)
+	result := make([]model.Holiday, len(stored))
+	for i, hd := range stored {
+		result[i] = *hd
+	}
+	return result
+}
+
+// withHolidays 将组织的自定义节假日注入约束配置（请求已指定 holidays 时以请求为准）
+func (h *ScheduleHandler) withHolidays(orgID uuid.UUID, config map[string]interface{}) map[string]interface{} {
+	if h.store == nil {
+		return config
+	}
+	if _, ok := config["holidays"]; ok {
+		return config
+	}
+	stored := storedHolidays(h.store, orgID)
+	if len(stored) == 0 {
+		return config
+	}
+	merged := make(map[string]interface{}, len(config)+1)
+	for k, v := range config {
+		merged[k] = v
+	}
+	merged["holidays"] = stored
+	return merged
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+)
+
+// validateHourlyStaffing 校验约束配置中的每小时最低在岗人数要求（hourly_staffing），未配置时不校验
+func validateHourlyStaffing(config map[string]interface{}) *errors.AppError {
+	if _, ok := config["hourly_staffing"]; !ok {
+		return nil
+	}
+	rules := builtin.ConfigHourlyStaffing(config)
+	if len(rules) == 0 {
+		return errors.New(errors.CodeInvalidInput, "hourly_staffing 应为非空数组，如 [{\"start\": \"10:00\", \"end\": \"22:00\", \"min_staff\": 2}]")
+	}
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return errors.Wrap(err, errors.CodeInvalidInput, "hourly_staffing 无效: "+err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/hrsync"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// HRSignatureHeader HR 同步 Webhook 签名请求头
+const HRSignatureHeader = "X-HR-Signature"
+
+// maxHREventBody HR 同步请求体大小上限
+const maxHREventBody = 4 << 20
+
+// HRSyncHandler HR 系统同步处理器
+type HRSyncHandler struct {
+	store  *memstore.Store
+	syncer *hrsync.Syncer
+}
+
+// NewHRSyncHandler 创建 HR 同步处理器
+func NewHRSyncHandler(store *memstore.Store, syncer *hrsync.Syncer) *HRSyncHandler {
+	return &HRSyncHandler{
+		store:  store,
+		syncer: syncer,
+	}
+}
+
+// HRIngestResponse 事件接收响应
+type HRIngestResponse struct {
+	Accepted int `json:"accepted"`
+	Pending  int `json:"pending"`
+}
+
+// Mapping 查询/设置来源字段映射（管理者）
+// 路由: GET|PUT /api/v1/hrsync/sources/{source}/mapping
+func (h *HRSyncHandler) Mapping(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) || !requireManager(w, r) {
+		return
+	}
+	source := r.PathValue("source")
+
+	switch r.Method {
+	case http.MethodGet:
+		mapping, err := h.store.GetHRMapping(source)
+		if err != nil {
+			respondError(w, errors.New(errors.CodeNotFound, "来源未配置字段映射"))
+			return
+		}
+		if !authorizeOrg(w, r, mapping.OrgID) {
+			return
+		}
+		respondJSON(w, http.StatusOK, maskSecret(mapping))
+
+	case http.MethodPut:
+		var mapping model.HRMapping
+		if err := json.NewDecoder(r.Body).Decode(&mapping); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if len(mapping.Fields) == 0 {
+			respondError(w, errors.New(errors.CodeInvalidInput, "fields 不能为空"))
+			return
+		}
+		// 已有映射的组织和新映射的组织都须可访问，避免改写其他组织的来源
+		if existing, err := h.store.GetHRMapping(source); err == nil && !authorizeOrg(w, r, existing.OrgID) {
+			return
+		}
+		if !authorizeOrg(w, r, mapping.OrgID) {
+			return
+		}
+		mapping.Source = source
+		mapping.UpdatedAt = time.Now()
+		h.store.PutHRMapping(&mapping)
+		respondJSON(w, http.StatusOK, maskSecret(&mapping))
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// Events 接收 HR 系统推送的员工变更事件（单个事件或事件数组）
+// 路由: POST /api/v1/hrsync/sources/{source}/events
+// 事件入队后异步处理，返回 202；超过来源限流或队列已满返回 429
+func (h *HRSyncHandler) Events(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if !h.ready(w) {
+		return
+	}
+	source := r.PathValue("source")
+	mapping, err := h.store.GetHRMapping(source)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "来源未配置字段映射"))
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHREventBody))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "读取请求失败"))
+		return
+	}
+	if !hrsync.VerifySignature(mapping, body, r.Header.Get(HRSignatureHeader)) {
+		respondError(w, errors.New(errors.CodeUnauthorized, "签名校验失败"))
+		return
+	}
+
+	var events []*model.HREvent
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &events)
+	} else {
+		var event model.HREvent
+		err = json.Unmarshal(body, &event)
+		events = []*model.HREvent{&event}
+	}
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析事件失败"))
+		return
+	}
+
+	accepted, err := h.syncer.Ingest(source, events)
+	resp := HRIngestResponse{Accepted: accepted, Pending: h.syncer.Pending()}
+	switch err {
+	case nil:
+		respondJSON(w, http.StatusAccepted, resp)
+	case hrsync.ErrThrottled, hrsync.ErrQueueFull:
+		// 已入队的事件会被处理，其余事件由来源稍后重试
+		w.Header().Set("Retry-After", strconv.Itoa(60))
+		appErr := errors.New(errors.CodeRateLimited, err.Error()).
+			WithDetails("已接收 " + strconv.Itoa(accepted) + " 个事件，请稍后重试其余事件")
+		respondError(w, appErr)
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "接收事件失败"))
+	}
+}
+
+// Conflicts 查询同步冲突，DELETE 标记冲突已处理
+// 路由: GET /api/v1/hrsync/conflicts?source=  DELETE /api/v1/hrsync/conflicts/{id}
+func (h *HRSyncHandler) Conflicts(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) || !requireManager(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		// 组织受限的调用方只能看到映射到所属组织的来源的冲突
+		result := make([]*model.HRSyncConflict, 0)
+		for _, conflict := range h.store.ListHRConflicts(r.URL.Query().Get("source")) {
+			if orgAccessError(r.Context(), h.sourceOrg(conflict.Source)) == nil {
+				result = append(result, conflict)
+			}
+		}
+		respondJSON(w, http.StatusOK, result)
+	case http.MethodDelete:
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的冲突ID格式"))
+			return
+		}
+		for _, conflict := range h.store.ListHRConflicts("") {
+			if conflict.ID == id && !authorizeOrg(w, r, h.sourceOrg(conflict.Source)) {
+				return
+			}
+		}
+		if err := h.store.DeleteHRConflict(id); err != nil {
+			respondError(w, errors.New(errors.CodeNotFound, "冲突不存在"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/DELETE方法"))
+	}
+}
+
+// Repairs 查询员工停用后的排班修复建议，未指定 org_id 时组织受限的调用方只查看所属组织的建议
+// 路由: GET /api/v1/hrsync/repairs?org_id=
+func (h *HRSyncHandler) Repairs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !h.ready(w) || !requireManager(w, r) {
+		return
+	}
+
+	orgID, ok := listOrgID(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, h.store.ListRepairSuggestions(orgID))
+}
+
+// sourceOrg 来源映射到的组织，来源未配置映射时返回 uuid.Nil
+func (h *HRSyncHandler) sourceOrg(source string) uuid.UUID {
+	mapping, err := h.store.GetHRMapping(source)
+	if err != nil {
+		return uuid.Nil
+	}
+	return mapping.OrgID
+}
+
+// ready 检查是否启用了排班存储
+func (h *HRSyncHandler) ready(w http.ResponseWriter) bool {
+	if h.store == nil || h.syncer == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// maskSecret 隐藏映射中的签名密钥
+func maskSecret(mapping *model.HRMapping) *model.HRMapping {
+	c := *mapping
+	if c.Secret != "" {
+		c.Secret = "******"
+	}
+	return &c
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/paiban/paiban/pkg/compliance"
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// JurisdictionListResponse 合规规则包列表响应
+type JurisdictionListResponse struct {
+	Jurisdictions []*compliance.Pack `json:"jurisdictions"`
+	Total         int                `json:"total"`
+}
+
+// ListJurisdictionsHandler 列出可在排班请求 jurisdiction 中指定的劳动法合规规则包
+// 路由: GET /api/v1/jurisdictions
+func ListJurisdictionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	packs := compliance.Packs()
+	respondJSON(w, http.StatusOK, JurisdictionListResponse{Jurisdictions: packs, Total: len(packs)})
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// StoreCoverage 多门店排班中单个门店的需求覆盖情况
+type StoreCoverage struct {
+	StoreID   string  `json:"store_id"`
+	StoreName string  `json:"store_name,omitempty"`
+	Required  int     `json:"required"` // 需求最少人数合计
+	Assigned  int     `json:"assigned"` // 已分配人次
+	Shortage  int     `json:"shortage"` // 缺口人次
+	Hours     float64 `json:"hours"`    // 已分配工时
+	Borrowed  int     `json:"borrowed"` // 其他门店员工跨店支援的人次
+	Unfilled  int     `json:"unfilled"` // 未满足的需求数
+}
+
+// validateStores 校验多门店排班的门店列表，以及班次、需求和员工引用的门店是否都在列表中
+// 未提供门店列表时不做引用校验（门店仅作为标识使用）
+func validateStores(req *GenerateRequest) *errors.AppError {
+	if len(req.Stores) == 0 {
+		return nil
+	}
+	known := make(map[string]bool, len(req.Stores))
+	for _, s := range req.Stores {
+		if s.ID == "" {
+			return errors.New(errors.CodeInvalidInput, "门店ID不能为空")
+		}
+		if known[s.ID] {
+			return errors.New(errors.CodeInvalidInput, fmt.Sprintf("门店 %s 重复", s.ID))
+		}
+		known[s.ID] = true
+	}
+	for _, s := range req.Stores {
+		for to, minutes := range s.TravelMinutes {
+			if !known[to] {
+				return errors.New(errors.CodeInvalidInput, fmt.Sprintf("门店 %s 的通勤时间引用了未知门店 %s", s.ID, to))
+			}
+			if minutes < 0 {
+				return errors.New(errors.CodeInvalidInput, fmt.Sprintf("门店 %s 到门店 %s 的通勤时间不能为负数", s.ID, to))
+			}
+		}
+	}
+
+	unknown := func(storeID string) bool { return storeID != "" && !known[storeID] }
+	for _, s := range req.Shifts {
+		if unknown(s.StoreID) {
+			return errors.New(errors.CodeInvalidInput, fmt.Sprintf("班次 %s 的门店 %s 不在门店列表中", s.Name, s.StoreID))
+		}
+	}
+	for _, r := range req.Requirements {
+		if unknown(r.StoreID) {
+			return errors.New(errors.CodeInvalidInput, fmt.Sprintf("%s 的需求门店 %s 不在门店列表中", r.Date, r.StoreID))
+		}
+	}
+	for _, e := range req.Employees {
+		if unknown(e.homeStore()) {
+			return errors.New(errors.CodeInvalidInput, fmt.Sprintf("员工 %s 的所属门店 %s 不在门店列表中", e.Name, e.homeStore()))
+		}
+		for _, s := range e.AllowedStores {
+			if unknown(s) {
+				return errors.New(errors.CodeInvalidInput, fmt.Sprintf("员工 %s 的可支援门店 %s 不在门店列表中", e.Name, s))
+			}
+		}
+	}
+	return nil
+}
+
+// homeStore 返回员工所属门店，home_store 为 store_id 的别名
+func (e EmployeeInput) homeStore() string {
+	if e.StoreID != "" {
+		return e.StoreID
+	}
+	return e.HomeStore
+}
+
+// withStores 将请求中的门店列表合并到约束配置，供跨店通勤约束使用
+func withStores(stores []model.Store, config map[string]interface{}) map[string]interface{} {
+	if len(stores) == 0 {
+		return config
+	}
+	if _, ok := config["stores"]; ok {
+		return config
+	}
+	merged := make(map[string]interface{}, len(config)+1)
+	for k, v := range config {
+		merged[k] = v
+	}
+	merged["stores"] = stores
+	return merged
+}
+
+// storeFillRates 按门店计算需求满足率 (%)，与排班统计的 FillRate 口径一致，需求都未指定门店时返回 nil
+func storeFillRates(requirements []*model.ShiftRequirement, unfilled []UnfilledRequirement) map[string]float64 {
+	total := make(map[string]int)
+	for _, r := range requirements {
+		if r.StoreID != "" {
+			total[r.StoreID]++
+		}
+	}
+	if len(total) == 0 {
+		return nil
+	}
+	missing := make(map[string]int)
+	for _, u := range unfilled {
+		if u.StoreID != "" {
+			missing[u.StoreID]++
+		}
+	}
+	rates := make(map[string]float64, len(total))
+	for storeID, n := range total {
+		rates[storeID] = float64(n-missing[storeID]) / float64(n) * 100
+	}
+	return rates
+}
+
+// summarizeStores 按门店汇总需求覆盖情况，需求都未指定门店时返回 nil
+func summarizeStores(
+	stores []model.Store,
+	requirements []*model.ShiftRequirement,
+	assignments []*model.Assignment,
+	unfilled []UnfilledRequirement,
+	empMap map[uuid.UUID]*model.Employee,
+) []StoreCoverage {
+	byStore := make(map[string]*StoreCoverage)
+	coverage := func(storeID string) *StoreCoverage {
+		c, ok := byStore[storeID]
+		if !ok {
+			c = &StoreCoverage{StoreID: storeID, StoreName: model.StoreName(stores, storeID)}
+			byStore[storeID] = c
+		}
+		return c
+	}
+	for _, r := range requirements {
+		if r.StoreID != "" {
+			coverage(r.StoreID).Required += r.MinEmployees
+		}
+	}
+	if len(byStore) == 0 {
+		return nil
+	}
+	for _, a := range assignments {
+		if a.StoreID == "" {
+			continue
+		}
+		c := coverage(a.StoreID)
+		c.Assigned++
+		c.Hours += a.WorkingHours()
+		if emp := empMap[a.EmployeeID]; emp != nil && emp.StoreID != "" && emp.StoreID != a.StoreID {
+			c.Borrowed++
+		}
+	}
+	for _, u := range unfilled {
+		if u.StoreID == "" {
+			continue
+		}
+		c := coverage(u.StoreID)
+		c.Shortage += u.Shortage
+		c.Unfilled++
+	}
+
+	result := make([]StoreCoverage, 0, len(byStore))
+	for _, c := range byStore {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StoreID < result[j].StoreID })
+	return result
+}
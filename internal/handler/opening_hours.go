@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// OpeningHoursHandler 门店营业时间处理器
+type OpeningHoursHandler struct {
+	store *memstore.Store
+}
+
+// NewOpeningHoursHandler 创建门店营业时间处理器
+func NewOpeningHoursHandler(store *memstore.Store) *OpeningHoursHandler {
+	return &OpeningHoursHandler{store: store}
+}
+
+// OpeningHours 查询/替换组织的门店营业时间（替换需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/opening-hours
+// PUT 请求体为完整的门店营业时间列表，会覆盖该组织已有配置
+func (h *OpeningHoursHandler) OpeningHours(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, h.store.ListOpeningHours(orgID))
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var hours []*model.StoreOpeningHours
+		if err := json.NewDecoder(r.Body).Decode(&hours); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		seen := make(map[string]bool, len(hours))
+		now := time.Now()
+		for _, oh := range hours {
+			if oh == nil {
+				respondError(w, errors.New(errors.CodeInvalidInput, "营业时间不能为空"))
+				return
+			}
+			if err := oh.Validate(); err != nil {
+				respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+				return
+			}
+			if seen[oh.StoreID] {
+				respondError(w, errors.New(errors.CodeInvalidInput, fmt.Sprintf("门店 %s 重复配置营业时间", oh.StoreID)))
+				return
+			}
+			seen[oh.StoreID] = true
+			oh.UpdatedAt = now
+		}
+		if err := h.store.ReplaceOpeningHours(orgID, hours); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "保存营业时间失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, h.store.ListOpeningHours(orgID))
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// withOpeningHours 请求未配置门店营业时间时，补充存储中该组织的营业时间
+// 返回新的配置，不修改请求中的配置
+func (h *ScheduleHandler) withOpeningHours(orgID uuid.UUID, config map[string]interface{}) map[string]interface{} {
+	if h.store == nil {
+		return config
+	}
+	if _, ok := config["store_opening_hours"]; ok {
+		return config
+	}
+	stored := h.store.ListOpeningHours(orgID)
+	if len(stored) == 0 {
+		return config
+	}
+	hours := make([]model.StoreOpeningHours, len(stored))
+	for i, oh := range stored {
+		hours[i] = *oh
+	}
+	merged := make(map[string]interface{}, len(config)+1)
+	for k, v := range config {
+		merged[k] = v
+	}
+	merged["store_opening_hours"] = hours
+	return merged
+}
+
+// filterOpeningHours 剔除门店闭店或班次超出门店营业时间的需求，返回保留的需求和冲突说明
+// 需求未指定门店或门店未配置营业时间时不受限制
+func filterOpeningHours(hours []model.StoreOpeningHours, shifts []*model.Shift, requirements []*model.ShiftRequirement) ([]*model.ShiftRequirement, []string) {
+	if len(hours) == 0 {
+		return requirements, nil
+	}
+	shiftMap := make(map[uuid.UUID]*model.Shift, len(shifts))
+	for _, s := range shifts {
+		shiftMap[s.ID] = s
+	}
+
+	kept := make([]*model.ShiftRequirement, 0, len(requirements))
+	var conflicts []string
+	for _, req := range requirements {
+		oh := model.ResolveOpeningHours(hours, req.StoreID)
+		shift := shiftMap[req.ShiftID]
+		if oh == nil || shift == nil || oh.CoversShift(req.Date, shift) {
+			kept = append(kept, req)
+			continue
+		}
+		if sp, ok := oh.Special(req.Date); ok && sp.Closed {
+			conflicts = append(conflicts, fmt.Sprintf("门店 %s 在 %s 闭店（%s），跳过班次 %s 的需求", req.StoreID, req.Date, sp.Name, shift.Name))
+			continue
+		}
+		conflicts = append(conflicts, fmt.Sprintf("门店 %s 在 %s 的班次 %s（%s-%s）超出营业时间，跳过该需求",
+			req.StoreID, req.Date, shift.Name, shift.StartTime, shift.EndTime))
+	}
+	return kept, conflicts
+}
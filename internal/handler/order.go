@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/order"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// OrderHandler 服务订单处理器（订单记录、状态流转、完成凭证、结算导出）
+type OrderHandler struct {
+	store   *memstore.Store
+	service *order.Service
+}
+
+// NewOrderHandler 创建服务订单处理器
+func NewOrderHandler(store *memstore.Store, service *order.Service) *OrderHandler {
+	return &OrderHandler{
+		store:   store,
+		service: service,
+	}
+}
+
+// OrderStatusRequest 订单状态变更请求
+type OrderStatusRequest struct {
+	Status     string     `json:"status"`
+	EmployeeID *uuid.UUID `json:"employee_id,omitempty"` // 派单时指定服务人员
+}
+
+// Orders 查询/创建组织的服务订单
+// 路由: GET|POST /api/v1/orgs/{org_id}/orders
+// GET 支持 start_date、end_date（服务日期）和 status 过滤；POST 保存订单记录（如派单结果）
+func (h *OrderHandler) Orders(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		respondJSON(w, http.StatusOK, h.store.ListOrders(orgID, query.Get("start_date"), query.Get("end_date"), query.Get("status")))
+
+	case http.MethodPost:
+		var o model.ServiceOrder
+		if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		o.OrgID = orgID
+		created, err := h.service.Create(&o)
+		if err != nil {
+			respondOrderError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, created)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Status 变更订单状态
+// 路由: POST /api/v1/orgs/{org_id}/orders/{id}/status
+// 组织配置了完工策略时，完成凭证不满足要求的订单不能变更为 completed
+func (h *OrderHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	orgID, id, ok := h.orderID(w, r)
+	if !ok {
+		return
+	}
+	var req OrderStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	o, err := h.service.Transition(orgID, id, req.Status, req.EmployeeID)
+	if err != nil {
+		respondOrderError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, o)
+}
+
+// CompletionProof 查询/提交订单的完成凭证
+// 路由: GET|PUT /api/v1/orgs/{org_id}/orders/{id}/completion-proof
+// PUT 提交客户签名图片引用、完工照片和完工说明（覆盖之前的凭证），提交人取 X-User-ID
+func (h *OrderHandler) CompletionProof(w http.ResponseWriter, r *http.Request) {
+	orgID, id, ok := h.orderID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		o, err := h.store.GetOrder(id)
+		if err != nil || o.OrgID != orgID {
+			respondError(w, errors.New(errors.CodeNotFound, "订单不存在"))
+			return
+		}
+		if o.CompletionProof == nil {
+			respondError(w, errors.New(errors.CodeNotFound, "订单未提交完成凭证"))
+			return
+		}
+		respondJSON(w, http.StatusOK, o.CompletionProof)
+
+	case http.MethodPut:
+		var proof model.CompletionProof
+		if err := json.NewDecoder(r.Body).Decode(&proof); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		o, err := h.service.AttachProof(orgID, id, &proof, r.Header.Get(AuthorHeader))
+		if err != nil {
+			respondOrderError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusOK, o)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// Policy 查询/设置组织的订单完工策略（设置需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/completion-policy
+func (h *OrderHandler) Policy(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil || org.CompletionPolicy == nil {
+			respondError(w, errors.New(errors.CodeNotFound, "组织未配置完工策略"))
+			return
+		}
+		respondJSON(w, http.StatusOK, org.CompletionPolicy)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var policy model.CompletionPolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := policy.Validate(); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil {
+			org = &model.Organization{BaseModel: model.NewBaseModel()}
+			org.ID = orgID
+		}
+		org.CompletionPolicy = &policy
+		org.UpdatedAt = time.Now()
+		h.store.PutOrganization(org)
+		respondJSON(w, http.StatusOK, org.CompletionPolicy)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// BillingExport 导出已完成订单的结算清单（含完成凭证）
+// 路由: GET /api/v1/orgs/{org_id}/orders/billing-export?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD[&format=csv]
+func (h *OrderHandler) BillingExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	lines := h.service.Billing(orgID, query.Get("start_date"), query.Get("end_date"))
+	if query.Get("format") != "csv" {
+		respondJSON(w, http.StatusOK, lines)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=billing-%s.csv", orgID))
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"order_no", "service_date", "service_type", "customer_id", "employee_ids", "duration", "amount",
+		"completed_at", "signature_ref", "signer_name", "photos", "notes"})
+	for _, l := range lines {
+		employees := make([]string, len(l.EmployeeIDs))
+		for i, id := range l.EmployeeIDs {
+			employees[i] = id.String()
+		}
+		completedAt := ""
+		if l.CompletedAt != nil {
+			completedAt = l.CompletedAt.Format(time.RFC3339)
+		}
+		cw.Write([]string{l.OrderNo, l.ServiceDate, l.ServiceType, l.CustomerID.String(), strings.Join(employees, ";"),
+			fmt.Sprint(l.Duration), fmt.Sprintf("%.2f", l.Amount), completedAt, l.SignatureRef, l.SignerName,
+			strings.Join(l.Photos, ";"), l.Notes})
+	}
+	cw.Flush()
+}
+
+// CustomerCaregivers 客户服务人员数量报表
+// 路由: GET /api/v1/orgs/{org_id}/customer-caregivers?date=YYYY-MM-DD[&window_days=30][&max_caregivers=N]
+// 按已派出的订单统计截至 date 的滚动窗口内各客户的不同服务人员数及分布，max_caregivers 用于标记超过上限的客户
+func (h *OrderHandler) CustomerCaregivers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	var days, max int
+	var err error
+	if v := query.Get("window_days"); v != "" {
+		if days, err = strconv.Atoi(v); err != nil || days < 1 || days > 366 {
+			respondError(w, errors.New(errors.CodeInvalidInput, "window_days 应为 1-366 的整数"))
+			return
+		}
+	}
+	if v := query.Get("max_caregivers"); v != "" {
+		if max, err = strconv.Atoi(v); err != nil || max < 0 {
+			respondError(w, errors.New(errors.CodeInvalidInput, "max_caregivers 应为非负整数"))
+			return
+		}
+	}
+	report, err := h.service.CaregiverReport(orgID, query.Get("date"), days, max)
+	if err != nil {
+		respondOrderError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, report)
+}
+
+// orgID 检查存储是否启用并解析组织ID
+func (h *OrderHandler) orgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil || h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// orderID 解析组织ID和订单ID
+func (h *OrderHandler) orderID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的订单ID格式"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, id, true
+}
+
+// respondOrderError 将服务订单错误转换为响应
+func respondOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "订单不存在"))
+	case stderrors.Is(err, order.ErrInvalidOrder):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	case stderrors.Is(err, order.ErrProofRequired):
+		respondError(w, errors.New(errors.CodeValidationFail, err.Error()))
+	case stderrors.Is(err, order.ErrInvalidTransition):
+		respondError(w, errors.New(errors.CodeAlreadyExists, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "处理订单失败"))
+	}
+}
@@ -0,0 +1,369 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/constraints"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// OrgConstraintHandler 组织级约束配置处理器
+// 配置数据库时读写 ConstraintRepository，否则使用内存存储
+type OrgConstraintHandler struct {
+	repo    repository.ConstraintRepositoryInterface
+	store   *memstore.Store
+	catalog *constraints.Catalog // 校验约束名称并补充默认类别和权重
+}
+
+// NewOrgConstraintHandler 创建组织级约束配置处理器，repo 为空时使用内存存储
+func NewOrgConstraintHandler(repo *repository.ConstraintRepository, store *memstore.Store, catalog *constraints.Catalog) *OrgConstraintHandler {
+	h := &OrgConstraintHandler{
+		store:   store,
+		catalog: catalog,
+	}
+	if repo != nil {
+		h.repo = repo
+	}
+	return h
+}
+
+// OrgConstraintInput 组织级约束配置的新增/更新请求，更新时只修改给出的字段
+type OrgConstraintInput struct {
+	OrgID    string                 `json:"org_id,omitempty"` // 仅新增时使用
+	Name     *string                `json:"name,omitempty"`
+	Category *string                `json:"category,omitempty"`
+	Weight   *int                   `json:"weight,omitempty"`
+	Params   map[string]interface{} `json:"params,omitempty"`
+	Enabled  *bool                  `json:"enabled,omitempty"`
+}
+
+// OrgConstraintListResponse 组织级约束配置列表响应
+type OrgConstraintListResponse struct {
+	OrgID       string                 `json:"org_id"`
+	Constraints []*model.OrgConstraint `json:"constraints"`
+	// 已启用约束合并后的配置，即排班请求未提供 constraints 时使用的默认约束参数
+	Effective map[string]interface{} `json:"effective"`
+	Total     int                    `json:"total"`
+}
+
+// Collection 列出或新增组织级约束配置
+// 未指定 org_id 时组织受限的调用方列出所属组织的配置
+// 路由: GET /api/v1/constraints?org_id=
+// 路由: POST /api/v1/constraints
+func (h *OrgConstraintHandler) Collection(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		orgID, ok := listOrgID(w, r)
+		if !ok {
+			return
+		}
+		if orgID == uuid.Nil {
+			respondError(w, errors.New(errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		list, err := h.list(r.Context(), orgID)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询约束配置失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, OrgConstraintListResponse{
+			OrgID:       orgID.String(),
+			Constraints: list,
+			Effective:   constraints.OrgConfig(list),
+			Total:       len(list),
+		})
+
+	case http.MethodPost:
+		if !requireManager(w, r) {
+			return
+		}
+		var input OrgConstraintInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		orgID, err := uuid.Parse(input.OrgID)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		now := time.Now()
+		c := &model.OrgConstraint{
+			ID:        uuid.New(),
+			OrgID:     orgID,
+			Enabled:   true,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		input.apply(c)
+		if appErr := h.save(r.Context(), c, true); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		auditOrgConstraint(w, r, "create", c)
+		respondJSON(w, http.StatusCreated, c)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Item 获取、更新或删除单条组织级约束配置
+// 路由: GET|PUT|DELETE /api/v1/constraints/{id}
+func (h *OrgConstraintHandler) Item(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的约束配置ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		c, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, c.OrgID) {
+			return
+		}
+		respondJSON(w, http.StatusOK, c)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var input OrgConstraintInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		c, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, c.OrgID) {
+			return
+		}
+		// 更换约束时类别和权重按新约束重新取默认值（请求中给出的除外）
+		if input.Name != nil && *input.Name != c.Name {
+			c.Category, c.Weight = "", 0
+			if input.Params == nil {
+				c.Params = nil
+			}
+		}
+		input.apply(c)
+		c.UpdatedAt = time.Now()
+		if appErr := h.save(r.Context(), c, false); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		auditOrgConstraint(w, r, "update", c)
+		respondJSON(w, http.StatusOK, c)
+
+	case http.MethodDelete:
+		if !requireManager(w, r) {
+			return
+		}
+		c, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, c.OrgID) {
+			return
+		}
+		var err error
+		if h.repo != nil {
+			err = h.repo.Delete(r.Context(), id)
+		} else {
+			err = h.store.DeleteOrgConstraint(id)
+		}
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "删除约束配置失败"))
+			return
+		}
+		auditOrgConstraint(w, r, "delete", c)
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"deleted": true,
+			"id":      id.String(),
+		})
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT/DELETE方法"))
+	}
+}
+
+// auditOrgConstraint 记录组织级约束配置的变更
+func auditOrgConstraint(w http.ResponseWriter, r *http.Request, operation string, c *model.OrgConstraint) {
+	recordAudit(w, r, c.OrgID, model.AuditConstraintChange, "org_constraint", c.ID.String(), map[string]interface{}{
+		"operation": operation,
+		"name":      c.Name,
+		"weight":    c.Weight,
+		"params":    c.Params,
+		"enabled":   c.Enabled,
+	})
+}
+
+// apply 将请求中给出的字段写入约束配置
+func (in OrgConstraintInput) apply(c *model.OrgConstraint) {
+	if in.Name != nil {
+		c.Name = *in.Name
+	}
+	if in.Category != nil {
+		c.Category = *in.Category
+	}
+	if in.Weight != nil {
+		c.Weight = *in.Weight
+	}
+	if in.Params != nil {
+		c.Params = model.JSONMap(in.Params)
+	}
+	if in.Enabled != nil {
+		c.Enabled = *in.Enabled
+	}
+	if c.Params == nil {
+		c.Params = model.JSONMap{}
+	}
+}
+
+// save 校验并保存约束配置，同一组织内约束名称重复时返回 409
+func (h *OrgConstraintHandler) save(ctx context.Context, c *model.OrgConstraint, create bool) *errors.AppError {
+	library := constraints.GetLibrary()
+	if h.catalog != nil {
+		library = h.catalog.Library()
+	}
+	if err := constraints.ValidateOrgConstraint(c, library); err != nil {
+		return errors.Wrap(err, errors.CodeInvalidInput, err.Error())
+	}
+
+	if h.repo == nil {
+		err := h.store.PutOrgConstraint(c)
+		if err == memstore.ErrDuplicate {
+			return errors.New(errors.CodeAlreadyExists, "组织已存在同名约束配置: "+c.Name)
+		}
+		if err != nil {
+			return errors.Wrap(err, errors.CodeInternal, "保存约束配置失败")
+		}
+		return nil
+	}
+
+	existing, err := h.repo.ListAllByOrg(ctx, c.OrgID)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "查询约束配置失败")
+	}
+	for _, e := range existing {
+		if e.ID != c.ID && e.Name == c.Name {
+			return errors.New(errors.CodeAlreadyExists, "组织已存在同名约束配置: "+c.Name)
+		}
+	}
+	record := toConstraintConfig(c)
+	if create {
+		err = h.repo.Create(ctx, record)
+	} else {
+		err = h.repo.Update(ctx, record)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "保存约束配置失败")
+	}
+	return nil
+}
+
+// get 获取约束配置，不存在时返回 404
+func (h *OrgConstraintHandler) get(ctx context.Context, id uuid.UUID) (*model.OrgConstraint, *errors.AppError) {
+	if h.repo == nil {
+		c, err := h.store.GetOrgConstraint(id)
+		if err != nil {
+			return nil, errors.New(errors.CodeNotFound, "约束配置不存在")
+		}
+		return c, nil
+	}
+	record, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "查询约束配置失败")
+	}
+	if record == nil {
+		return nil, errors.New(errors.CodeNotFound, "约束配置不存在")
+	}
+	return fromConstraintConfig(record), nil
+}
+
+// list 列出组织的全部约束配置（含已停用的）
+func (h *OrgConstraintHandler) list(ctx context.Context, orgID uuid.UUID) ([]*model.OrgConstraint, error) {
+	if h.repo == nil {
+		return h.store.ListOrgConstraints(orgID, false), nil
+	}
+	records, err := h.repo.ListAllByOrg(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return fromConstraintConfigs(records), nil
+}
+
+// ready 检查是否启用了约束配置存储
+func (h *OrgConstraintHandler) ready(w http.ResponseWriter) bool {
+	if h.repo == nil && h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// toConstraintConfig 转换为数据库约束配置记录（type 记录约束名称，config 记录参数）
+func toConstraintConfig(c *model.OrgConstraint) *repository.ConstraintConfig {
+	return &repository.ConstraintConfig{
+		ID:        c.ID,
+		OrgID:     c.OrgID,
+		Name:      c.Name,
+		Type:      c.Name,
+		Category:  c.Category,
+		Weight:    c.Weight,
+		Config:    c.Params,
+		Enabled:   c.Enabled,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// fromConstraintConfig 从数据库约束配置记录转换
+func fromConstraintConfig(r *repository.ConstraintConfig) *model.OrgConstraint {
+	params := model.JSONMap(r.Config)
+	if params == nil {
+		params = model.JSONMap{}
+	}
+	return &model.OrgConstraint{
+		ID:        r.ID,
+		OrgID:     r.OrgID,
+		Name:      r.Name,
+		Category:  r.Category,
+		Weight:    r.Weight,
+		Params:    params,
+		Enabled:   r.Enabled,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// fromConstraintConfigs 批量转换数据库约束配置记录
+func fromConstraintConfigs(records []repository.ConstraintConfig) []*model.OrgConstraint {
+	result := make([]*model.OrgConstraint, 0, len(records))
+	for i := range records {
+		result = append(result, fromConstraintConfig(&records[i]))
+	}
+	return result
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/pattern"
+)
+
+// PatternHandler 循环排班模板处理器（按周重复的轮班表，展开为未来若干周的排班）
+type PatternHandler struct {
+	store *memstore.Store
+}
+
+// NewPatternHandler 创建循环排班模板处理器
+func NewPatternHandler(store *memstore.Store) *PatternHandler {
+	return &PatternHandler{store: store}
+}
+
+// PatternListResponse 循环排班模板列表响应
+type PatternListResponse struct {
+	Patterns []*model.SchedulePattern `json:"patterns"`
+	Total    int                      `json:"total"`
+}
+
+// PatternExpandRequest 展开循环排班模板请求
+type PatternExpandRequest struct {
+	StartDate string              `json:"start_date"`
+	EndDate   string              `json:"end_date,omitempty"`
+	Weeks     int                 `json:"weeks,omitempty"`                // 未填写 end_date 时从 start_date 起展开的周数
+	Employees []*model.Employee   `json:"employees,omitempty"`            // 员工信息，用于检测可用时段冲突
+	Existing  []*model.Assignment `json:"existing_assignments,omitempty"` // 已有排班，与展开的排班一起检测冲突
+}
+
+// Collection 列出或新增循环排班模板
+// 未指定 org_id 时组织受限的调用方列出所属组织的模板
+// 路由: GET /api/v1/schedule/patterns?org_id=
+// 路由: POST /api/v1/schedule/patterns
+func (h *PatternHandler) Collection(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		orgID, ok := listOrgID(w, r)
+		if !ok {
+			return
+		}
+		if orgID == uuid.Nil {
+			respondError(w, errors.New(errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		list := h.store.ListSchedulePatterns(orgID)
+		respondJSON(w, http.StatusOK, PatternListResponse{Patterns: list, Total: len(list)})
+
+	case http.MethodPost:
+		if !requireManager(w, r) {
+			return
+		}
+		p, ok := h.decode(w, r)
+		if !ok {
+			return
+		}
+		if p.OrgID == uuid.Nil {
+			respondError(w, errors.New(errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		p.BaseModel = model.NewBaseModel()
+		if !h.save(w, p) {
+			return
+		}
+		respondJSON(w, http.StatusCreated, p)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Item 获取、替换或删除循环排班模板
+// 路由: GET|PUT|DELETE /api/v1/schedule/patterns/{id}
+func (h *PatternHandler) Item(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.pattern(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, existing)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		p, ok := h.decode(w, r)
+		if !ok {
+			return
+		}
+		// 模板所属组织和创建时间不变
+		p.ID, p.OrgID, p.CreatedAt = existing.ID, existing.OrgID, existing.CreatedAt
+		p.UpdatedAt = time.Now()
+		if !h.save(w, p) {
+			return
+		}
+		respondJSON(w, http.StatusOK, p)
+
+	case http.MethodDelete:
+		if !requireManager(w, r) {
+			return
+		}
+		if err := h.store.DeleteSchedulePattern(existing.ID); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "删除循环排班模板失败"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT/DELETE方法"))
+	}
+}
+
+// Expand 将循环排班模板展开为一段日期内的排班（不保存），并检测冲突
+// 路由: POST /api/v1/schedule/patterns/{id}/expand
+func (h *PatternHandler) Expand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	p, ok := h.pattern(w, r)
+	if !ok {
+		return
+	}
+	var req PatternExpandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if req.StartDate == "" {
+		respondError(w, errors.New(errors.CodeInvalidInput, "start_date 不能为空"))
+		return
+	}
+	end := req.EndDate
+	if end == "" {
+		if req.Weeks <= 0 {
+			respondError(w, errors.New(errors.CodeInvalidInput, "需填写 end_date 或 weeks"))
+			return
+		}
+		start, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "开始日期格式应为 YYYY-MM-DD"))
+			return
+		}
+		end = start.AddDate(0, 0, req.Weeks*7-1).Format("2006-01-02")
+	}
+
+	expansion, err := pattern.Expand(p, req.StartDate, end, pattern.Options{
+		Employees: req.Employees,
+		Existing:  req.Existing,
+	})
+	if err != nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+		return
+	}
+	respondJSON(w, http.StatusOK, expansion)
+}
+
+// decode 解析并校验模板
+func (h *PatternHandler) decode(w http.ResponseWriter, r *http.Request) (*model.SchedulePattern, bool) {
+	var p model.SchedulePattern
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return nil, false
+	}
+	p.Normalize()
+	if err := p.Validate(); err != nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+		return nil, false
+	}
+	return &p, true
+}
+
+// save 保存模板
+func (h *PatternHandler) save(w http.ResponseWriter, p *model.SchedulePattern) bool {
+	if err := h.store.PutSchedulePattern(p); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "保存循环排班模板失败"))
+		return false
+	}
+	return true
+}
+
+// pattern 解析路径中的模板ID并获取模板，检查调用方可访问模板所属的组织
+func (h *PatternHandler) pattern(w http.ResponseWriter, r *http.Request) (*model.SchedulePattern, bool) {
+	if !h.ready(w) {
+		return nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的模板ID格式"))
+		return nil, false
+	}
+	p, err := h.store.GetSchedulePattern(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "循环排班模板不存在"))
+		return nil, false
+	}
+	if !authorizeOrg(w, r, p.OrgID) {
+		return nil, false
+	}
+	return p, true
+}
+
+// ready 检查存储是否启用
+func (h *PatternHandler) ready(w http.ResponseWriter) bool {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/payroll"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// PayrollHandler 工资结账处理器
+type PayrollHandler struct {
+	service *payroll.Service
+}
+
+// NewPayrollHandler 创建工资结账处理器
+func NewPayrollHandler(service *payroll.Service) *PayrollHandler {
+	return &PayrollHandler{service: service}
+}
+
+// PayrollCloseRequest 工资结账请求
+type PayrollCloseRequest struct {
+	LockedBefore string `json:"locked_before"` // 该日期之前（不含）的分配被锁定
+	Note         string `json:"note,omitempty"`
+}
+
+// PayrollAdjustRequest 已结账分配调整请求
+type PayrollAdjustRequest struct {
+	ScheduleID uuid.UUID                `json:"schedule_id"`
+	Changes    []model.AssignmentChange `json:"changes"`
+	Reason     string                   `json:"reason"`
+}
+
+// Closes 查询结账记录或结账
+// 路由: GET|POST /api/v1/orgs/{org_id}/payroll/closes
+// POST 需管理者，锁定 locked_before 之前的分配并将未结算的调整纳入本次结账
+func (h *PayrollHandler) Closes(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		closes, lockedBefore := h.service.Closes(orgID)
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"org_id":        orgID,
+			"locked_before": lockedBefore,
+			"closes":        closes,
+		})
+
+	case http.MethodPost:
+		if !requireManager(w, r) {
+			return
+		}
+		var req PayrollCloseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		c, err := h.service.Close(orgID, req.LockedBefore, r.Header.Get(AuthorHeader), req.Note)
+		if err != nil {
+			respondPayrollError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, c)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET和POST方法"))
+	}
+}
+
+// Adjustments 查询或提交已结账分配的调整
+// 路由: GET|POST /api/v1/orgs/{org_id}/payroll/adjustments
+// GET 支持 ?status=pending|settled，返回调整记录及按员工汇总的工时差额；POST 需管理者
+func (h *PayrollHandler) Adjustments(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		status := r.URL.Query().Get("status")
+		if status != "" && status != "pending" && status != "settled" {
+			respondError(w, errors.New(errors.CodeInvalidInput, "status 仅支持 pending 或 settled"))
+			return
+		}
+		adjustments := h.service.Adjustments(orgID, status)
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"org_id":      orgID,
+			"status":      status,
+			"adjustments": adjustments,
+			"totals":      payroll.Totals(adjustments),
+		})
+
+	case http.MethodPost:
+		if !requireManager(w, r) {
+			return
+		}
+		var req PayrollAdjustRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		adjustments, err := h.service.Adjust(orgID, req.ScheduleID, req.Changes, req.Reason, r.Header.Get(AuthorHeader))
+		if err != nil {
+			respondPayrollError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, map[string]interface{}{
+			"adjustments": adjustments,
+			"totals":      payroll.Totals(adjustments),
+		})
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET和POST方法"))
+	}
+}
+
+// Report 工资报表：区分支付加班费的加班与计入调休的小时数
+// 路由: GET /api/v1/orgs/{org_id}/payroll/report?month=YYYY-MM
+func (h *PayrollHandler) Report(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.service.Report(orgID, r.URL.Query().Get("month"))
+	if err != nil {
+		respondPayrollError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, report)
+}
+
+// orgID 检查存储是否启用并解析组织ID
+func (h *PayrollHandler) orgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// respondPayrollError 将工资结账错误转换为响应
+func respondPayrollError(w http.ResponseWriter, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+	case err == memstore.ErrVersionConflict:
+		respondError(w, errors.New(errors.CodeScheduleConflict, "排班已被他人修改，请重试"))
+	case stderrors.Is(err, payroll.ErrInvalidClose), stderrors.Is(err, payroll.ErrInvalidAdjustment), stderrors.Is(err, payroll.ErrInvalidMonth):
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "工资结账操作失败"))
+	}
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/posimport"
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// RequirementImportHandler POS 需求导入处理器
+type RequirementImportHandler struct {
+	store   *memstore.Store
+	service *posimport.Service
+}
+
+// NewRequirementImportHandler 创建 POS 需求导入处理器
+func NewRequirementImportHandler(store *memstore.Store, service *posimport.Service) *RequirementImportHandler {
+	return &RequirementImportHandler{
+		store:   store,
+		service: service,
+	}
+}
+
+// RequirementImportReviewRequest 需求导入审核请求
+type RequirementImportReviewRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note,omitempty"` // 驳回时必填
+}
+
+// Imports 查询/导入 POS 小时业务量
+// 路由: GET|POST /api/v1/orgs/{org_id}/requirement-imports
+// GET 支持 status 过滤；POST 请求体为 CSV，参数 productivity（每人每小时交易数，必填）、min_staff、
+// shift_ids（逗号分隔，默认组织全部启用班次）、source，生成待审核的草稿需求，导入人取 X-User-ID
+func (h *RequirementImportHandler) Imports(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, h.store.ListRequirementImports(orgID, r.URL.Query().Get("status")))
+
+	case http.MethodPost:
+		query := r.URL.Query()
+		opts := posimport.Options{Source: query.Get("source")}
+		var err error
+		if opts.Productivity, err = strconv.ParseFloat(query.Get("productivity"), 64); err != nil {
+			respondError(w, errors.New(errors.CodeInvalidInput, "productivity 应为每人每小时处理的交易数"))
+			return
+		}
+		if v := query.Get("min_staff"); v != "" {
+			if opts.MinStaff, err = strconv.Atoi(v); err != nil {
+				respondError(w, errors.New(errors.CodeInvalidInput, "min_staff 应为整数"))
+				return
+			}
+		}
+		if v := query.Get("shift_ids"); v != "" {
+			for _, s := range strings.Split(v, ",") {
+				id, err := uuid.Parse(strings.TrimSpace(s))
+				if err != nil {
+					respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的班次ID格式: "+s))
+					return
+				}
+				opts.ShiftIDs = append(opts.ShiftIDs, id)
+			}
+		}
+
+		imp, err := h.service.Import(orgID, r.Body, opts, r.Header.Get(AuthorHeader))
+		if err != nil {
+			respondRequirementImportError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, imp)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Import 获取需求导入记录（业务量曲线、草稿需求和未覆盖的小时）
+// 路由: GET /api/v1/orgs/{org_id}/requirement-imports/{id}
+func (h *RequirementImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的导入ID格式"))
+		return
+	}
+	imp, err := h.store.GetRequirementImport(id)
+	if err != nil || imp.OrgID != orgID {
+		respondError(w, errors.New(errors.CodeNotFound, "需求导入不存在"))
+		return
+	}
+	respondJSON(w, http.StatusOK, imp)
+}
+
+// Review 审核需求导入（需管理者），采用后草稿需求写入组织需求
+// 路由: POST /api/v1/orgs/{org_id}/requirement-imports/{id}/review
+func (h *RequirementImportHandler) Review(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok || !requireManager(w, r) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的导入ID格式"))
+		return
+	}
+	var req RequirementImportReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	imp, err := h.service.Review(orgID, id, req.Approve, r.Header.Get(AuthorHeader), req.Note)
+	if err != nil {
+		respondRequirementImportError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, imp)
+}
+
+// orgID 检查存储是否启用并解析组织ID
+func (h *RequirementImportHandler) orgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil || h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// respondRequirementImportError 将需求导入服务错误转换为响应
+func respondRequirementImportError(w http.ResponseWriter, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "需求导入不存在"))
+	case stderrors.Is(err, posimport.ErrInvalidCSV), stderrors.Is(err, posimport.ErrInvalidOptions):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	case stderrors.Is(err, posimport.ErrReviewed):
+		respondError(w, errors.New(errors.CodeAlreadyExists, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "处理需求导入失败"))
+	}
+}
@@ -0,0 +1,537 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/publication"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// RoleHeader 调用方角色请求头
+// manager/admin 角色可在公布前查看排班（预览）
+const RoleHeader = "X-User-Role"
+
+// maxViolationDetails 拒绝发布时错误详情中列出的硬约束违反数
+const maxViolationDetails = 10
+
+// PublicationHandler 排班发布处理器
+// 启用内存存储时由发布器管理排班生命周期（发布规则、审计、通知），配置数据库时同步排班状态；
+// 只配置数据库时直接在 ScheduleRepository 中变更排班状态
+type PublicationHandler struct {
+	store     *memstore.Store
+	publisher *publication.Publisher
+	repo      repository.ScheduleRepositoryInterface
+}
+
+// NewPublicationHandler 创建排班发布处理器
+func NewPublicationHandler(store *memstore.Store, publisher *publication.Publisher) *PublicationHandler {
+	return &PublicationHandler{
+		store:     store,
+		publisher: publisher,
+	}
+}
+
+// SetScheduleRepository 设置排班仓储，设置后发布和归档同步更新数据库中的排班状态；
+// 未启用内存存储时发布、归档和员工排班查询直接使用数据库
+func (h *PublicationHandler) SetScheduleRepository(repo repository.ScheduleRepositoryInterface) {
+	h.repo = repo
+}
+
+// PublishRequest 发布请求
+type PublishRequest struct {
+	Immediate bool `json:"immediate,omitempty"` // 忽略发布规则立即发布
+}
+
+// ArchiveRequest 归档请求
+type ArchiveRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// EmployeeScheduleResponse 员工排班响应
+type EmployeeScheduleResponse struct {
+	EmployeeID    string             `json:"employee_id"`
+	Assignments   []AssignmentOutput `json:"assignments"`
+	Preview       bool               `json:"preview,omitempty"`         // 是否包含未公布的排班（管理者预览）
+	NextPublishAt *time.Time         `json:"next_publish_at,omitempty"` // 下一次公布时间
+}
+
+// PublicationRule 查询/设置组织发布规则
+// 路由: GET|PUT /api/v1/orgs/{org_id}/publication-rule
+func (h *PublicationHandler) PublicationRule(w http.ResponseWriter, r *http.Request) {
+	if !h.storeReady(w) {
+		return
+	}
+
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil || org.PublicationRule == nil {
+			respondError(w, errors.New(errors.CodeNotFound, "组织未配置发布规则"))
+			return
+		}
+		respondJSON(w, http.StatusOK, org.PublicationRule)
+
+	case http.MethodPut:
+		var rule model.PublicationRule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := rule.Validate(); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "发布规则无效"))
+			return
+		}
+
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil {
+			org = &model.Organization{BaseModel: model.NewBaseModel()}
+			org.ID = orgID
+		}
+		org.PublicationRule = &rule
+		org.UpdatedAt = time.Now()
+		h.store.PutOrganization(org)
+		respondJSON(w, http.StatusOK, org.PublicationRule)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// Publish 发布排班（草稿 → 已发布），存在硬约束违反时返回 400
+// 路由: POST /api/v1/schedules/{id}/publish
+func (h *PublicationHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	var req PublishRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+	}
+
+	if h.store == nil {
+		record, err := h.publishRecord(r.Context(), id, r.Header.Get(AuthorHeader))
+		if err != nil {
+			respondLifecycleError(w, err, "发布排班失败")
+			return
+		}
+		recordAudit(w, r, record.OrgID, model.AuditSchedulePublish, "schedule", record.ID.String(), map[string]interface{}{
+			"status":    record.Status,
+			"immediate": true,
+		})
+		respondJSON(w, http.StatusOK, record)
+		return
+	}
+
+	schedule, err := h.publisher.Publish(id, req.Immediate, r.Header.Get(AuthorHeader))
+	if err != nil {
+		respondLifecycleError(w, err, "发布排班失败")
+		return
+	}
+	h.syncRecord(r.Context(), schedule)
+	recordAudit(w, r, schedule.OrgID, model.AuditSchedulePublish, "schedule", schedule.ID.String(), map[string]interface{}{
+		"status":    schedule.Status,
+		"immediate": req.Immediate,
+	})
+	respondJSON(w, http.StatusOK, schedule)
+}
+
+// Archive 归档排班（草稿或已发布 → 已归档），归档后员工不再可见
+// 路由: POST /api/v1/schedules/{id}/archive
+func (h *PublicationHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ArchiveRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+	}
+
+	if h.store == nil {
+		record, err := h.archiveRecord(r.Context(), id, r.Header.Get(AuthorHeader), req.Reason)
+		if err != nil {
+			respondLifecycleError(w, err, "归档排班失败")
+			return
+		}
+		recordAudit(w, r, record.OrgID, model.AuditScheduleArchive, "schedule", record.ID.String(), map[string]interface{}{
+			"reason": req.Reason,
+		})
+		respondJSON(w, http.StatusOK, record)
+		return
+	}
+
+	schedule, err := h.publisher.Archive(id, r.Header.Get(AuthorHeader), req.Reason)
+	if err != nil {
+		respondLifecycleError(w, err, "归档排班失败")
+		return
+	}
+	h.syncRecord(r.Context(), schedule)
+	recordAudit(w, r, schedule.OrgID, model.AuditScheduleArchive, "schedule", schedule.ID.String(), map[string]interface{}{
+		"reason": req.Reason,
+	})
+	respondJSON(w, http.StatusOK, schedule)
+}
+
+// History 查询排班的生命周期审计记录（需启用内存存储）
+// 路由: GET /api/v1/schedules/{id}/history
+func (h *PublicationHandler) History(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !h.storeReady(w) {
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, h.publisher.History(id))
+}
+
+// EmployeeSchedule 员工查看自己的排班，仅返回已公布的排班
+// 路由: GET /api/v1/employees/{employee_id}/schedule?start_date=&end_date=
+// 管理者（X-User-Role: manager/admin）可通过 preview=true 查看未公布的排班
+func (h *PublicationHandler) EmployeeSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !h.ready(w) {
+		return
+	}
+
+	employeeID, err := uuid.Parse(r.PathValue("employee_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式"))
+		return
+	}
+
+	query := r.URL.Query()
+	preview := query.Get("preview") == "true"
+	if preview && !isManagerRequest(r) {
+		respondError(w, errors.New(errors.CodeForbidden, "仅管理者可预览未公布的排班"))
+		return
+	}
+	startDate, endDate := query.Get("start_date"), query.Get("end_date")
+	if h.store == nil {
+		h.employeeRecordSchedule(w, r, employeeID, startDate, endDate, preview)
+		return
+	}
+
+	now := h.publisher.Now()
+	resp := EmployeeScheduleResponse{
+		EmployeeID:  employeeID.String(),
+		Assignments: make([]AssignmentOutput, 0),
+		Preview:     preview,
+	}
+	shiftNames := make(map[uuid.UUID]string)
+
+	for _, schedule := range h.store.ListSchedules(uuid.Nil) {
+		if schedule.Status == "archived" {
+			continue
+		}
+		if !schedule.IsVisibleAt(now) {
+			// 记录下一次公布时间，便于客户端提示
+			if schedule.PublishAt != nil && (resp.NextPublishAt == nil || schedule.PublishAt.Before(*resp.NextPublishAt)) {
+				resp.NextPublishAt = schedule.PublishAt
+			}
+			if !preview {
+				continue
+			}
+		}
+
+		for _, a := range schedule.Assignments {
+			if a.EmployeeID != employeeID || a.Status == "cancelled" {
+				continue
+			}
+			if (startDate != "" && a.Date < startDate) || (endDate != "" && a.Date > endDate) {
+				continue
+			}
+			if _, ok := shiftNames[a.ShiftID]; !ok {
+				if shift, err := h.store.GetShift(a.ShiftID); err == nil {
+					shiftNames[a.ShiftID] = shift.Name
+				}
+			}
+			resp.Assignments = append(resp.Assignments, AssignmentOutput{
+				ID:         a.ID.String(),
+				EmployeeID: a.EmployeeID.String(),
+				ShiftID:    a.ShiftID.String(),
+				ShiftName:  shiftNames[a.ShiftID],
+				Date:       a.Date,
+				StartTime:  a.StartTime.Format("15:04"),
+				EndTime:    a.EndTime.Format("15:04"),
+				Position:   a.Position,
+				Hours:      a.WorkingHours(),
+			})
+		}
+	}
+
+	sort.Slice(resp.Assignments, func(i, j int) bool {
+		if resp.Assignments[i].Date != resp.Assignments[j].Date {
+			return resp.Assignments[i].Date < resp.Assignments[j].Date
+		}
+		return resp.Assignments[i].StartTime < resp.Assignments[j].StartTime
+	})
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// scheduleID 检查存储并解析排班ID，排班存在时检查调用方可访问其所属组织
+func (h *PublicationHandler) scheduleID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if !h.ready(w) {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式"))
+		return uuid.Nil, false
+	}
+	if h.store == nil {
+		if record, err := h.repo.GetByID(r.Context(), id); err == nil && record != nil && !authorizeOrg(w, r, record.OrgID) {
+			return uuid.Nil, false
+		}
+		return id, true
+	}
+	if schedule, err := h.store.GetSchedule(id); err == nil && !authorizeOrg(w, r, schedule.OrgID) {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// publishRecord 在数据库中发布排班记录（未启用内存存储时），只有草稿可以发布
+// 数据库模式没有组织发布规则，发布即公布；生成时未满足全部硬约束（feasible 为 false）的排班拒绝发布
+func (h *PublicationHandler) publishRecord(ctx context.Context, id uuid.UUID, actor string) (*repository.Schedule, error) {
+	record, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, memstore.ErrNotFound
+	}
+	switch record.Status {
+	case "draft":
+	case "published":
+		return record, publication.ErrAlreadyPublished
+	default:
+		return nil, fmt.Errorf("%w: 排班状态为 %s", publication.ErrInvalidTransition, record.Status)
+	}
+	if !record.Feasible {
+		return nil, fmt.Errorf("%w：生成结果未满足全部硬约束", publication.ErrHardViolations)
+	}
+
+	now := time.Now()
+	record.Status = "published"
+	if record.Metadata == nil {
+		record.Metadata = make(map[string]any)
+	}
+	record.Metadata["publish_at"] = now
+	record.Metadata["published_at"] = now
+	record.Metadata["published_by"] = actor
+	if err := h.repo.Update(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// archiveRecord 在数据库中归档排班记录（未启用内存存储时），草稿和已发布的排班都可归档
+func (h *PublicationHandler) archiveRecord(ctx context.Context, id uuid.UUID, actor, reason string) (*repository.Schedule, error) {
+	record, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, memstore.ErrNotFound
+	}
+	if record.Status != "draft" && record.Status != "published" {
+		return nil, fmt.Errorf("%w: 排班状态为 %s", publication.ErrInvalidTransition, record.Status)
+	}
+
+	record.Status = "archived"
+	if record.Metadata == nil {
+		record.Metadata = make(map[string]any)
+	}
+	record.Metadata["archived_at"] = time.Now()
+	record.Metadata["archived_by"] = actor
+	if reason != "" {
+		record.Metadata["archive_reason"] = reason
+	}
+	if err := h.repo.Update(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// employeeRecordSchedule 从数据库查询员工的排班（未启用内存存储时），只返回已发布排班的分配，
+// 管理者预览时包含草稿
+func (h *PublicationHandler) employeeRecordSchedule(w http.ResponseWriter, r *http.Request, employeeID uuid.UUID, startDate, endDate string, preview bool) {
+	assignments, err := h.repo.GetAssignmentsByEmployee(r.Context(), employeeID, startDate, endDate)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "查询员工排班失败"))
+		return
+	}
+	resp := EmployeeScheduleResponse{
+		EmployeeID:  employeeID.String(),
+		Assignments: make([]AssignmentOutput, 0),
+		Preview:     preview,
+	}
+	status := make(map[uuid.UUID]string)
+	for _, a := range assignments {
+		if a.Status == "cancelled" {
+			continue
+		}
+		if _, ok := status[a.ScheduleID]; !ok {
+			if record, err := h.repo.GetByID(r.Context(), a.ScheduleID); err == nil && record != nil {
+				status[a.ScheduleID] = record.Status
+			} else {
+				status[a.ScheduleID] = ""
+			}
+		}
+		switch status[a.ScheduleID] {
+		case "published":
+		case "draft":
+			if !preview {
+				continue
+			}
+		default:
+			continue
+		}
+		resp.Assignments = append(resp.Assignments, AssignmentOutput{
+			ID:         a.ID.String(),
+			EmployeeID: a.EmployeeID.String(),
+			ShiftID:    a.ShiftID.String(),
+			ShiftName:  a.ShiftName,
+			Date:       a.Date,
+			StartTime:  a.StartTime,
+			EndTime:    a.EndTime,
+			Position:   a.Position,
+			Hours:      clockHours(a.StartTime, a.EndTime),
+		})
+	}
+
+	sort.Slice(resp.Assignments, func(i, j int) bool {
+		if resp.Assignments[i].Date != resp.Assignments[j].Date {
+			return resp.Assignments[i].Date < resp.Assignments[j].Date
+		}
+		return resp.Assignments[i].StartTime < resp.Assignments[j].StartTime
+	})
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// syncRecord 将排班状态同步到数据库中的排班记录（记录不存在时跳过）
+func (h *PublicationHandler) syncRecord(ctx context.Context, schedule *model.Schedule) {
+	if h.repo == nil {
+		return
+	}
+	record, err := h.repo.GetByID(ctx, schedule.ID)
+	if err != nil || record == nil {
+		return
+	}
+	record.Status = schedule.Status
+	if record.Metadata == nil {
+		record.Metadata = make(map[string]any)
+	}
+	record.Metadata["version"] = schedule.Version
+	if schedule.PublishAt != nil {
+		record.Metadata["publish_at"] = schedule.PublishAt
+	}
+	if schedule.PublishedAt != nil {
+		record.Metadata["published_at"] = schedule.PublishedAt
+		record.Metadata["published_by"] = schedule.PublishedBy
+	}
+	if schedule.ArchivedAt != nil {
+		record.Metadata["archived_at"] = schedule.ArchivedAt
+		record.Metadata["archived_by"] = schedule.ArchivedBy
+	}
+	h.repo.Update(ctx, record)
+}
+
+// respondLifecycleError 将发布、归档错误转换为响应
+func respondLifecycleError(w http.ResponseWriter, err error, message string) {
+	var violationErr *publication.ViolationError
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+	case err == publication.ErrAlreadyPublished:
+		respondError(w, errors.New(errors.CodeAlreadyExists, "排班已发布"))
+	case err == publication.ErrPeriodClosed:
+		respondError(w, errors.New(errors.CodeForbidden, "工资期间已结账").WithDetails(err.Error()))
+	case stderrors.As(err, &violationErr):
+		details := make([]string, 0, maxViolationDetails)
+		for i, v := range violationErr.Violations {
+			if i == maxViolationDetails {
+				details = append(details, fmt.Sprintf("等共 %d 项", len(violationErr.Violations)))
+				break
+			}
+			details = append(details, v.Message)
+		}
+		respondError(w, errors.New(errors.CodeValidationFail, violationErr.Error()).WithDetails(strings.Join(details, "；")))
+	case stderrors.Is(err, publication.ErrHardViolations):
+		respondError(w, errors.New(errors.CodeValidationFail, err.Error()))
+	case stderrors.Is(err, publication.ErrInvalidTransition):
+		respondError(w, errors.New(errors.CodeScheduleConflict, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, message))
+	}
+}
+
+// ready 检查是否启用了内存存储或配置了数据库
+func (h *PublicationHandler) ready(w http.ResponseWriter) bool {
+	if (h.store == nil || h.publisher == nil) && (h.store != nil || h.repo == nil) {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// storeReady 检查是否启用了内存存储（发布规则和生命周期审计只保存在内存存储中）
+func (h *PublicationHandler) storeReady(w http.ResponseWriter) bool {
+	if h.store == nil || h.publisher == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// isManagerRequest 检查调用方是否为管理者
+func isManagerRequest(r *http.Request) bool {
+	switch r.Header.Get(RoleHeader) {
+	case "manager", "admin":
+		return true
+	}
+	return false
+}
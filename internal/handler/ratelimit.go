@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/paiban/paiban/internal/middleware"
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// RateLimitHandler 限流状态处理器
+type RateLimitHandler struct {
+	limiter *middleware.RateLimiter
+}
+
+// NewRateLimitHandler 创建限流状态处理器
+func NewRateLimitHandler(limiter *middleware.RateLimiter) *RateLimitHandler {
+	return &RateLimitHandler{limiter: limiter}
+}
+
+// Status 查询限流规则和各客户端的令牌桶状态（仅管理员）
+// 路由: GET /api/v1/admin/rate-limits[?client=]
+// client 按客户端标识（api_key:名称、jwt:组织/用户、ip:地址）的子串过滤，结果按拒绝次数降序
+func (h *RateLimitHandler) Status(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !requireAdmin(w, r) {
+		return
+	}
+	respondJSON(w, http.StatusOK, h.limiter.Status(r.URL.Query().Get("client")))
+}
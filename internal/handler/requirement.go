@@ -0,0 +1,736 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+const (
+	maxRequirementBulkDays = 366   // 按周模式展开需求的最大天数
+	maxRequirementBulkRows = 20000 // 单次展开的最大需求条数
+)
+
+// RequirementHandler 排班需求处理器
+// 配置数据库时读写 RequirementRepository，否则使用内存存储；需求引用的班次须为已保存的班次定义
+type RequirementHandler struct {
+	repo      repository.RequirementRepositoryInterface
+	shiftRepo repository.ShiftRepositoryInterface
+	store     *memstore.Store
+	schedules *ScheduleHandler // PATCH /api/v1/requirements/bulk 的批量修改预览
+}
+
+// NewRequirementHandler 创建排班需求处理器，repo 为空时使用内存存储
+func NewRequirementHandler(repo *repository.RequirementRepository, shiftRepo *repository.ShiftRepository, store *memstore.Store, schedules *ScheduleHandler) *RequirementHandler {
+	h := &RequirementHandler{
+		store:     store,
+		schedules: schedules,
+	}
+	if repo != nil {
+		h.repo = repo
+	}
+	if shiftRepo != nil {
+		h.shiftRepo = shiftRepo
+	}
+	return h
+}
+
+// RequirementRecordInput 排班需求的新增/更新请求，更新时只修改给出的字段
+type RequirementRecordInput struct {
+	OrgID        string             `json:"org_id,omitempty"` // 仅新增时使用
+	ShiftID      *string            `json:"shift_id,omitempty"`
+	ShiftCode    *string            `json:"shift_code,omitempty"` // 未给出 shift_id 时按班次编码匹配
+	Date         *string            `json:"date,omitempty"`
+	Position     *string            `json:"position,omitempty"`
+	MinEmployees *int               `json:"min_employees,omitempty"`
+	MaxEmployees *int               `json:"max_employees,omitempty"` // 0 表示最少人数的 2 倍
+	OptEmployees *int               `json:"opt_employees,omitempty"`
+	Skills       []string           `json:"skills,omitempty"`
+	SkillGroups  []model.SkillGroup `json:"skill_groups,omitempty"`
+	SkillLevels  []model.Skill      `json:"skill_levels,omitempty"`
+	Priority     *int               `json:"priority,omitempty"` // 1-10，默认 5
+	StoreID      *string            `json:"store_id,omitempty"`
+}
+
+// RequirementPattern 按周重复的需求模式，展开为日期范围内命中星期的每日需求
+type RequirementPattern struct {
+	Weekdays     []int              `json:"weekdays,omitempty"` // 星期几，0=周日 ... 6=周六，为空表示每天
+	ShiftID      string             `json:"shift_id,omitempty"`
+	ShiftCode    string             `json:"shift_code,omitempty"`
+	Position     string             `json:"position,omitempty"`
+	MinEmployees int                `json:"min_employees"`
+	MaxEmployees int                `json:"max_employees,omitempty"`
+	OptEmployees int                `json:"opt_employees,omitempty"`
+	Skills       []string           `json:"skills,omitempty"`
+	SkillGroups  []model.SkillGroup `json:"skill_groups,omitempty"`
+	SkillLevels  []model.Skill      `json:"skill_levels,omitempty"`
+	Priority     int                `json:"priority,omitempty"`
+	StoreID      string             `json:"store_id,omitempty"`
+}
+
+// RequirementBulkRequest 按周模式批量生成需求请求
+// patterns 和 spec（需求简写，班次按组织的启用班次匹配）可同时给出，后展开的覆盖先展开的同一需求
+type RequirementBulkRequest struct {
+	OrgID     string               `json:"org_id"`
+	StartDate string               `json:"start_date"`
+	EndDate   string               `json:"end_date"`
+	Patterns  []RequirementPattern `json:"patterns,omitempty"`
+	Spec      string               `json:"spec,omitempty"`
+	Replace   bool                 `json:"replace,omitempty"` // 先删除范围内的全部需求；否则按（班次、日期、岗位、门店）更新已有需求
+}
+
+// RequirementBulkResponse 按周模式批量生成需求响应
+type RequirementBulkResponse struct {
+	Created      int                       `json:"created"`
+	Updated      int                       `json:"updated"`
+	Deleted      int                       `json:"deleted"`
+	Requirements []*model.ShiftRequirement `json:"requirements"`
+}
+
+// RequirementListResponse 排班需求列表响应
+type RequirementListResponse struct {
+	Requirements []*model.ShiftRequirement `json:"requirements"`
+	Total        int                       `json:"total"`
+	Offset       int                       `json:"offset"`
+	Limit        int                       `json:"limit"`
+}
+
+// Collection 列出或新增排班需求
+// 未指定 org_id 时组织受限的调用方列出所属组织的需求
+// 路由: GET /api/v1/requirements?org_id=&start_date=&end_date=&shift_id=&position=&store_id=&offset=&limit=
+// 路由: POST /api/v1/requirements
+func (h *RequirementHandler) Collection(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w, r)
+
+	case http.MethodPost:
+		var input RequirementRecordInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		orgID, err := uuid.Parse(input.OrgID)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		req := &model.ShiftRequirement{BaseModel: model.NewBaseModel(), OrgID: orgID, MinEmployees: 1}
+		if appErr := h.apply(r.Context(), req, &input); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if appErr := h.save(r.Context(), req, true); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		respondJSON(w, http.StatusCreated, req)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Item 获取、更新或删除排班需求
+// 路由: GET|PUT|DELETE /api/v1/requirements/{id}
+func (h *RequirementHandler) Item(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的需求ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		req, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, req.OrgID) {
+			return
+		}
+		respondJSON(w, http.StatusOK, req)
+
+	case http.MethodPut:
+		var input RequirementRecordInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		req, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, req.OrgID) {
+			return
+		}
+		if appErr := h.apply(r.Context(), req, &input); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		req.UpdatedAt = time.Now()
+		if appErr := h.save(r.Context(), req, false); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		respondJSON(w, http.StatusOK, req)
+
+	case http.MethodDelete:
+		req, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, req.OrgID) {
+			return
+		}
+		if h.repo != nil {
+			err = h.repo.Delete(r.Context(), id)
+		} else {
+			err = h.store.DeleteRequirement(id)
+		}
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "删除需求失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"deleted": true,
+			"id":      id.String(),
+		})
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT/DELETE方法"))
+	}
+}
+
+// Bulk 按周模式批量生成需求（POST），或批量修改草稿需求并预览求解影响（PATCH）
+// 路由: POST|PATCH /api/v1/requirements/bulk
+func (h *RequirementHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+	case http.MethodPatch:
+		h.schedules.BulkEditRequirements(w, r)
+		return
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST和PATCH方法"))
+		return
+	}
+	if !h.ready(w) {
+		return
+	}
+
+	var req RequirementBulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	orgID, err := uuid.Parse(req.OrgID)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	start, err1 := time.Parse("2006-01-02", req.StartDate)
+	end, err2 := time.Parse("2006-01-02", req.EndDate)
+	if err1 != nil || err2 != nil || end.Before(start) {
+		respondError(w, errors.New(errors.CodeInvalidInput, "日期范围无效，应为 YYYY-MM-DD 且结束日期不早于开始日期"))
+		return
+	}
+	if int(end.Sub(start).Hours()/24)+1 > maxRequirementBulkDays {
+		respondError(w, errors.New(errors.CodeInvalidInput, fmt.Sprintf("日期范围最多 %d 天", maxRequirementBulkDays)))
+		return
+	}
+	if len(req.Patterns) == 0 && req.Spec == "" {
+		respondError(w, errors.New(errors.CodeInvalidInput, "需求模式和需求简写不能同时为空"))
+		return
+	}
+
+	rows, appErr := h.expand(r.Context(), orgID, &req, start, end)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	resp, appErr := h.saveBulk(r.Context(), orgID, &req, rows)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// expand 将需求模式和需求简写展开为每日需求，同一（班次、日期、岗位、门店）以后展开的为准
+func (h *RequirementHandler) expand(ctx context.Context, orgID uuid.UUID, req *RequirementBulkRequest, start, end time.Time) ([]*model.ShiftRequirement, *errors.AppError) {
+	var rows []*model.ShiftRequirement
+	for i, p := range req.Patterns {
+		shiftID, appErr := h.shiftID(ctx, orgID, p.ShiftID, p.ShiftCode)
+		if appErr != nil {
+			return nil, appErr.WithDetails(fmt.Sprintf("第 %d 个需求模式", i+1))
+		}
+		weekdays := make(map[time.Weekday]bool, len(p.Weekdays))
+		for _, d := range p.Weekdays {
+			if d < 0 || d > 6 {
+				return nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("第 %d 个需求模式的星期无效: %d（0=周日 ... 6=周六）", i+1, d))
+			}
+			weekdays[time.Weekday(d)] = true
+		}
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			if len(weekdays) > 0 && !weekdays[day.Weekday()] {
+				continue
+			}
+			rows = append(rows, &model.ShiftRequirement{
+				BaseModel:    model.NewBaseModel(),
+				OrgID:        orgID,
+				ShiftID:      shiftID,
+				Date:         day.Format("2006-01-02"),
+				Position:     p.Position,
+				MinEmployees: p.MinEmployees,
+				MaxEmployees: p.MaxEmployees,
+				OptEmployees: p.OptEmployees,
+				Skills:       p.Skills,
+				SkillGroups:  p.SkillGroups,
+				SkillLevels:  p.SkillLevels,
+				Priority:     p.Priority,
+				StoreID:      p.StoreID,
+			})
+			if len(rows) > maxRequirementBulkRows {
+				return nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("单次最多生成 %d 条需求", maxRequirementBulkRows))
+			}
+		}
+	}
+
+	if req.Spec != "" {
+		shifts, appErr := h.activeShifts(ctx, orgID)
+		if appErr != nil {
+			return nil, appErr
+		}
+		inputs := make([]ShiftInput, 0, len(shifts))
+		for _, shift := range shifts {
+			inputs = append(inputs, toShiftInput(shift))
+		}
+		parsed, appErr := expandRequirementSpec(req.Spec, inputs, req.StartDate, req.EndDate)
+		if appErr != nil {
+			return nil, appErr
+		}
+		for _, in := range parsed {
+			shiftID, _ := uuid.Parse(in.ShiftID)
+			rows = append(rows, &model.ShiftRequirement{
+				BaseModel:    model.NewBaseModel(),
+				OrgID:        orgID,
+				ShiftID:      shiftID,
+				Date:         in.Date,
+				Position:     in.Position,
+				MinEmployees: in.MinEmployees,
+				MaxEmployees: in.MaxEmployees,
+			})
+		}
+	}
+
+	// 同一需求以后展开的为准
+	index := make(map[string]int, len(rows))
+	merged := make([]*model.ShiftRequirement, 0, len(rows))
+	for _, row := range rows {
+		if row.Priority == 0 {
+			row.Priority = 5
+		}
+		if appErr := validateRequirementRecord(row); appErr != nil {
+			return nil, appErr.WithDetails(fmt.Sprintf("%s %s", row.Date, row.Position))
+		}
+		key := requirementMapKey(row.ShiftID, row.Date, row.Position, row.StoreID)
+		if i, ok := index[key]; ok {
+			merged[i] = row
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, row)
+	}
+	return merged, nil
+}
+
+// saveBulk 保存展开的需求：replace 时替换范围内的全部需求，否则更新同一（班次、日期、岗位、门店）的已有需求
+func (h *RequirementHandler) saveBulk(ctx context.Context, orgID uuid.UUID, req *RequirementBulkRequest, rows []*model.ShiftRequirement) (*RequirementBulkResponse, *errors.AppError) {
+	existing, appErr := h.listRange(ctx, orgID, req.StartDate, req.EndDate)
+	if appErr != nil {
+		return nil, appErr
+	}
+	resp := &RequirementBulkResponse{Requirements: rows}
+
+	if req.Replace {
+		if h.repo != nil {
+			deleted, err := h.repo.DeleteByDateRange(ctx, orgID, req.StartDate, req.EndDate)
+			if err != nil {
+				return nil, errors.Wrap(err, errors.CodeInternal, "删除需求失败")
+			}
+			resp.Deleted = deleted
+			for _, row := range rows {
+				if err := h.repo.Create(ctx, row); err != nil {
+					return nil, errors.Wrap(err, errors.CodeInternal, "保存需求失败")
+				}
+			}
+		} else if err := h.store.ReplaceRequirements(orgID, req.StartDate, req.EndDate, rows); err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "保存需求失败")
+		} else {
+			resp.Deleted = len(existing)
+		}
+		resp.Created = len(rows)
+		return resp, nil
+	}
+
+	byKey := make(map[string]*model.ShiftRequirement, len(existing))
+	for _, e := range existing {
+		byKey[requirementMapKey(e.ShiftID, e.Date, e.Position, e.StoreID)] = e
+	}
+	for _, row := range rows {
+		create := true
+		if e, ok := byKey[requirementMapKey(row.ShiftID, row.Date, row.Position, row.StoreID)]; ok {
+			row.ID, row.CreatedAt, create = e.ID, e.CreatedAt, false
+		}
+		if appErr := h.save(ctx, row, create); appErr != nil {
+			return nil, appErr
+		}
+		if create {
+			resp.Created++
+		} else {
+			resp.Updated++
+		}
+	}
+	return resp, nil
+}
+
+// list 分页查询排班需求（按日期、班次、岗位排序）
+func (h *RequirementHandler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	orgID, ok := listOrgID(w, r)
+	if !ok {
+		return
+	}
+	if orgID == uuid.Nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	filter := repository.DefaultListFilter().WithOrgID(orgID).WithDateRange(query.Get("start_date"), query.Get("end_date"))
+	filter.Extra = map[string]interface{}{
+		"shift_id": query.Get("shift_id"),
+		"position": query.Get("position"),
+		"store_id": query.Get("store_id"),
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		filter = filter.WithOffset(offset)
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= 100 {
+		filter = filter.WithLimit(limit)
+	}
+
+	var requirements []*model.ShiftRequirement
+	var total int
+	var err error
+	if h.repo != nil {
+		requirements, total, err = h.repo.List(r.Context(), filter)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询需求失败"))
+			return
+		}
+	} else {
+		requirements, total = h.listStore(orgID, filter)
+	}
+	if requirements == nil {
+		requirements = make([]*model.ShiftRequirement, 0)
+	}
+	respondJSON(w, http.StatusOK, RequirementListResponse{Requirements: requirements, Total: total, Offset: filter.Offset, Limit: filter.Limit})
+}
+
+// listStore 按过滤条件分页查询内存存储中的需求
+func (h *RequirementHandler) listStore(orgID uuid.UUID, filter repository.ListFilter) ([]*model.ShiftRequirement, int) {
+	shiftID, _ := filter.Extra["shift_id"].(string)
+	position, _ := filter.Extra["position"].(string)
+	storeID, _ := filter.Extra["store_id"].(string)
+
+	matched := make([]*model.ShiftRequirement, 0)
+	for _, req := range h.store.ListRequirements(orgID, filter.StartDate, filter.EndDate) {
+		if (shiftID != "" && req.ShiftID.String() != shiftID) || (position != "" && req.Position != position) ||
+			(storeID != "" && req.StoreID != storeID) {
+			continue
+		}
+		matched = append(matched, req)
+	}
+	sortRequirements(matched)
+
+	total := len(matched)
+	if filter.Offset >= total {
+		return nil, total
+	}
+	end := filter.Offset + filter.Limit
+	if end > total {
+		end = total
+	}
+	return matched[filter.Offset:end], total
+}
+
+// listRange 获取组织在日期范围内的全部需求
+func (h *RequirementHandler) listRange(ctx context.Context, orgID uuid.UUID, startDate, endDate string) ([]*model.ShiftRequirement, *errors.AppError) {
+	if h.repo != nil {
+		requirements, err := h.repo.ListByDateRange(ctx, orgID, startDate, endDate)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "查询需求失败")
+		}
+		return requirements, nil
+	}
+	return h.store.ListRequirements(orgID, startDate, endDate), nil
+}
+
+// get 获取需求，不存在时返回 404
+func (h *RequirementHandler) get(ctx context.Context, id uuid.UUID) (*model.ShiftRequirement, *errors.AppError) {
+	if h.repo != nil {
+		req, err := h.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "查询需求失败")
+		}
+		if req == nil {
+			return nil, errors.New(errors.CodeNotFound, "需求不存在")
+		}
+		return req, nil
+	}
+	req, err := h.store.GetRequirement(id)
+	if err != nil {
+		return nil, errors.New(errors.CodeNotFound, "需求不存在")
+	}
+	return req, nil
+}
+
+// apply 将请求中给出的字段写入需求，班次按 shift_id 或 shift_code 匹配组织的班次定义
+func (h *RequirementHandler) apply(ctx context.Context, req *model.ShiftRequirement, in *RequirementRecordInput) *errors.AppError {
+	if in.ShiftID != nil || in.ShiftCode != nil {
+		var id, code string
+		if in.ShiftID != nil {
+			id = *in.ShiftID
+		}
+		if in.ShiftCode != nil {
+			code = *in.ShiftCode
+		}
+		shiftID, appErr := h.shiftID(ctx, req.OrgID, id, code)
+		if appErr != nil {
+			return appErr
+		}
+		req.ShiftID = shiftID
+	}
+	if in.Date != nil {
+		req.Date = *in.Date
+	}
+	if in.Position != nil {
+		req.Position = *in.Position
+	}
+	if in.MinEmployees != nil {
+		req.MinEmployees = *in.MinEmployees
+	}
+	if in.MaxEmployees != nil {
+		req.MaxEmployees = *in.MaxEmployees
+	}
+	if in.OptEmployees != nil {
+		req.OptEmployees = *in.OptEmployees
+	}
+	if in.Skills != nil {
+		req.Skills = in.Skills
+	}
+	if in.SkillGroups != nil {
+		req.SkillGroups = in.SkillGroups
+	}
+	if in.SkillLevels != nil {
+		req.SkillLevels = in.SkillLevels
+	}
+	if in.Priority != nil {
+		req.Priority = *in.Priority
+	}
+	if in.StoreID != nil {
+		req.StoreID = *in.StoreID
+	}
+	if req.Priority == 0 {
+		req.Priority = 5
+	}
+	return nil
+}
+
+// shiftID 按班次ID或编码查找组织的班次定义
+func (h *RequirementHandler) shiftID(ctx context.Context, orgID uuid.UUID, id, code string) (uuid.UUID, *errors.AppError) {
+	var shift *model.Shift
+	switch {
+	case id != "":
+		shiftID, err := uuid.Parse(id)
+		if err != nil {
+			return uuid.Nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的班次ID格式: "+id)
+		}
+		if h.shiftRepo != nil {
+			if shift, err = h.shiftRepo.GetByID(ctx, shiftID); err != nil {
+				return uuid.Nil, errors.Wrap(err, errors.CodeInternal, "查询班次定义失败")
+			}
+		} else if h.store != nil {
+			shift, _ = h.store.GetShift(shiftID)
+		}
+	case code != "":
+		var err error
+		if shift, err = findShiftByCode(ctx, h.shiftRepo, h.store, orgID, code); err != nil {
+			return uuid.Nil, errors.Wrap(err, errors.CodeInternal, "查询班次定义失败")
+		}
+	default:
+		return uuid.Nil, errors.New(errors.CodeInvalidInput, "须给出班次ID（shift_id）或班次编码（shift_code）")
+	}
+	if shift == nil || shift.OrgID != orgID {
+		return uuid.Nil, errors.New(errors.CodeInvalidInput, "班次定义不存在: "+id+code)
+	}
+	return shift.ID, nil
+}
+
+// activeShifts 获取组织的启用班次
+func (h *RequirementHandler) activeShifts(ctx context.Context, orgID uuid.UUID) ([]*model.Shift, *errors.AppError) {
+	if h.shiftRepo != nil {
+		shifts, err := h.shiftRepo.ListActive(ctx, orgID)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "查询班次定义失败")
+		}
+		return shifts, nil
+	}
+	var shifts []*model.Shift
+	if h.store != nil {
+		for _, shift := range h.store.ListShifts(orgID) {
+			if shift.IsActive {
+				shifts = append(shifts, shift)
+			}
+		}
+	}
+	return shifts, nil
+}
+
+// save 校验并保存需求
+func (h *RequirementHandler) save(ctx context.Context, req *model.ShiftRequirement, create bool) *errors.AppError {
+	if appErr := validateRequirementRecord(req); appErr != nil {
+		return appErr
+	}
+	var err error
+	switch {
+	case h.repo != nil && create:
+		err = h.repo.Create(ctx, req)
+	case h.repo != nil:
+		err = h.repo.Update(ctx, req)
+	default:
+		err = h.store.PutRequirement(req)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "保存需求失败")
+	}
+	return nil
+}
+
+// ready 检查是否启用了存储
+func (h *RequirementHandler) ready(w http.ResponseWriter) bool {
+	if h.repo == nil && h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// validateRequirementRecord 校验排班需求字段
+func validateRequirementRecord(req *model.ShiftRequirement) *errors.AppError {
+	ve := &errors.ValidationErrors{}
+	if req.ShiftID == uuid.Nil {
+		ve.Add("shift_id", "班次不能为空")
+	}
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		ve.Add("date", "日期格式无效，应为YYYY-MM-DD")
+	}
+	if req.MinEmployees < 0 {
+		ve.Add("min_employees", "最少人数不能为负数")
+	}
+	if req.MaxEmployees != 0 && req.MaxEmployees < req.MinEmployees {
+		ve.Add("max_employees", "最多人数不能少于最少人数")
+	}
+	if req.OptEmployees < 0 {
+		ve.Add("opt_employees", "最优人数不能为负数")
+	}
+	if req.Priority < 1 || req.Priority > 10 {
+		ve.Add("priority", "优先级应为 1-10")
+	}
+	if ve.HasErrors() {
+		return ve.ToAppError()
+	}
+	return nil
+}
+
+// sortRequirements 按日期、班次、岗位、门店排序
+func sortRequirements(requirements []*model.ShiftRequirement) {
+	sort.SliceStable(requirements, func(i, j int) bool {
+		a, b := requirements[i], requirements[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.ShiftID != b.ShiftID {
+			return a.ShiftID.String() < b.ShiftID.String()
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.StoreID < b.StoreID
+	})
+}
+
+// withStoredRequirements 排班请求未提供需求（及需求简写）时，使用组织在排班周期内保存的需求
+// 只使用请求中班次的需求；配置数据库时读取 RequirementRepository，否则读取内存存储
+func (h *ScheduleHandler) withStoredRequirements(ctx context.Context, req *GenerateRequest) *errors.AppError {
+	if len(req.Requirements) > 0 || req.RequirementSpec != "" || req.StartDate == "" || req.EndDate == "" {
+		return nil
+	}
+	orgID, err := uuid.Parse(req.OrgID)
+	if err != nil {
+		return nil // 由请求校验报告组织ID格式错误
+	}
+	var stored []*model.ShiftRequirement
+	switch {
+	case h.requirementRepo != nil:
+		if stored, err = h.requirementRepo.ListByDateRange(ctx, orgID, req.StartDate, req.EndDate); err != nil {
+			return errors.Wrap(err, errors.CodeInternal, "查询排班需求失败")
+		}
+	case h.store != nil:
+		stored = h.store.ListRequirements(orgID, req.StartDate, req.EndDate)
+		sortRequirements(stored)
+	}
+	shifts := make(map[string]bool, len(req.Shifts))
+	for _, s := range req.Shifts {
+		shifts[s.ID] = true
+	}
+	for _, r := range stored {
+		if !shifts[r.ShiftID.String()] {
+			continue
+		}
+		req.Requirements = append(req.Requirements, RequirementInput{
+			ID:           r.ID.String(),
+			ShiftID:      r.ShiftID.String(),
+			Date:         r.Date,
+			Position:     r.Position,
+			MinEmployees: r.MinEmployees,
+			MaxEmployees: r.MaxEmployees,
+			OptEmployees: r.OptEmployees,
+			Skills:       r.Skills,
+			Priority:     r.Priority,
+			StoreID:      r.StoreID,
+			SkillGroups:  r.SkillGroups,
+			SkillLevels:  r.SkillLevels,
+		})
+	}
+	return nil
+}
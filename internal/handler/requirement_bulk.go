@@ -0,0 +1,389 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// BulkRequirementRequest 需求批量修改请求
+// 在草稿需求集上依次应用 edits，并对修改前后各做一次试算，返回覆盖率和成本的变化；
+// commit 为 true 且启用了排班存储时，保存修改后的班次和需求
+type BulkRequirementRequest struct {
+	Draft     GenerateRequest   `json:"draft"`                // 草稿需求集（与排班生成请求相同）
+	Edits     []RequirementEdit `json:"edits"`                // 批量修改，按顺序应用
+	LaborRate float64           `json:"labor_rate,omitempty"` // 内部员工平均时薪，用于估算人工成本
+	Commit    bool              `json:"commit,omitempty"`     // 是否保存修改结果
+}
+
+// RequirementEdit 需求批量修改
+// 筛选条件为空时不限制，各条件同时满足的需求才会被修改
+type RequirementEdit struct {
+	Weekdays  []int    `json:"weekdays,omitempty"`   // 星期几，0=周日 ... 6=周六
+	Shifts    []string `json:"shifts,omitempty"`     // 班次ID或编码
+	Positions []string `json:"positions,omitempty"`  // 岗位
+	StartDate string   `json:"start_date,omitempty"` // 日期范围（含）
+	EndDate   string   `json:"end_date,omitempty"`
+
+	MinEmployeesDelta int `json:"min_employees_delta,omitempty"` // 最少人数增减
+	ShiftMinutes      int `json:"shift_minutes,omitempty"`       // 班次起止时间平移分钟数，正数推后
+}
+
+// BulkRequirementResponse 需求批量修改响应
+type BulkRequirementResponse struct {
+	Shifts       []ShiftInput            `json:"shifts"`
+	Requirements []RequirementInput      `json:"requirements"`
+	Edits        []RequirementEditResult `json:"edits"`
+	Before       *RequirementImpact      `json:"before"`
+	After        *RequirementImpact      `json:"after"`
+	Delta        *RequirementImpact      `json:"delta"` // After - Before
+	Committed    bool                    `json:"committed"`
+}
+
+// RequirementEditResult 单条修改的应用结果
+type RequirementEditResult struct {
+	Matched       int      `json:"matched"`                  // 命中的需求数
+	DerivedShifts []string `json:"derived_shifts,omitempty"` // 平移时新建的班次ID（原班次仍被其他需求使用时）
+}
+
+// RequirementImpact 试算结果摘要
+type RequirementImpact struct {
+	Requirements int     `json:"requirements"`  // 需求数
+	Filled       int     `json:"filled"`        // 已满足的需求数
+	Unfilled     int     `json:"unfilled"`      // 缺口人数
+	FillRate     float64 `json:"fill_rate"`     // 需求满足率（百分比）
+	Assignments  int     `json:"assignments"`   // 分配数
+	TotalHours   float64 `json:"total_hours"`   // 总工时
+	ExternalCost float64 `json:"external_cost"` // 外部人员费用
+	LaborCost    float64 `json:"labor_cost"`    // 内部工时 × labor_rate + 外部人员费用
+}
+
+// BulkEditRequirements 批量修改需求并预览求解影响
+// 路由: PATCH /api/v1/requirements/bulk
+// 试算不保存排班；commit=true 时只保存修改后的班次和需求
+func (h *ScheduleHandler) BulkEditRequirements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持PATCH方法"))
+		return
+	}
+
+	var req BulkRequirementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if err := validateGenerateRequest(&req.Draft); err != nil {
+		respondError(w, err)
+		return
+	}
+	if len(req.Draft.Requirements) == 0 {
+		requirements, appErr := expandRequirementSpec(req.Draft.RequirementSpec, req.Draft.Shifts, req.Draft.StartDate, req.Draft.EndDate)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		req.Draft.Requirements = requirements
+	}
+	if len(req.Edits) == 0 {
+		respondError(w, errors.New(errors.CodeInvalidInput, "修改列表不能为空"))
+		return
+	}
+	if req.LaborRate < 0 {
+		respondError(w, errors.New(errors.CodeInvalidInput, "时薪不能为负数"))
+		return
+	}
+	if req.Commit && h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+
+	shifts, requirements, results, appErr := applyRequirementEdits(req.Draft.Shifts, req.Draft.Requirements, req.Edits)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+
+	before, appErr := h.previewImpact(r, &req.Draft, req.Draft.Shifts, req.Draft.Requirements, req.LaborRate)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	after, appErr := h.previewImpact(r, &req.Draft, shifts, requirements, req.LaborRate)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+
+	resp := &BulkRequirementResponse{
+		Shifts:       shifts,
+		Requirements: requirements,
+		Edits:        results,
+		Before:       before,
+		After:        after,
+		Delta:        impactDelta(before, after),
+	}
+	if req.Commit {
+		if appErr := h.commitRequirements(&req.Draft, shifts, requirements); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		resp.Committed = true
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// previewImpact 用给定的班次和需求试算一次（不保存），返回覆盖率和成本摘要
+func (h *ScheduleHandler) previewImpact(r *http.Request, draft *GenerateRequest, shifts []ShiftInput, requirements []RequirementInput, laborRate float64) (*RequirementImpact, *errors.AppError) {
+	trial := *draft
+	trial.Employees = append([]EmployeeInput(nil), draft.Employees...)
+	trial.Shifts = shifts
+	trial.Requirements = requirements
+	var options GenerateOptions
+	if draft.Options != nil {
+		options = *draft.Options
+	}
+	options.DryRun = true
+	trial.Options = &options
+
+	resp, appErr := h.generate(r.Context(), &trial)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	impact := &RequirementImpact{Assignments: len(resp.Assignments)}
+	if st := resp.Statistics; st != nil {
+		impact.Requirements = st.TotalRequirements
+		impact.Filled = st.FilledRequirements
+		impact.FillRate = st.FillRate
+		impact.TotalHours = st.TotalHours
+	}
+	for _, u := range resp.Unfilled {
+		impact.Unfilled += u.Shortage
+	}
+	internalHours := 0.0
+	for _, a := range resp.Assignments {
+		if a.External {
+			impact.ExternalCost += a.Cost
+		} else {
+			internalHours += a.Hours
+		}
+	}
+	impact.ExternalCost = roundCost(impact.ExternalCost)
+	impact.LaborCost = roundCost(internalHours*laborRate + impact.ExternalCost)
+	return impact, nil
+}
+
+// impactDelta 计算试算结果的变化
+func impactDelta(before, after *RequirementImpact) *RequirementImpact {
+	return &RequirementImpact{
+		Requirements: after.Requirements - before.Requirements,
+		Filled:       after.Filled - before.Filled,
+		Unfilled:     after.Unfilled - before.Unfilled,
+		FillRate:     math.Round((after.FillRate-before.FillRate)*100) / 100,
+		Assignments:  after.Assignments - before.Assignments,
+		TotalHours:   math.Round((after.TotalHours-before.TotalHours)*100) / 100,
+		ExternalCost: roundCost(after.ExternalCost - before.ExternalCost),
+		LaborCost:    roundCost(after.LaborCost - before.LaborCost),
+	}
+}
+
+// commitRequirements 保存修改后的班次和需求（替换组织在草稿日期范围内的全部需求）
+func (h *ScheduleHandler) commitRequirements(draft *GenerateRequest, shifts []ShiftInput, requirements []RequirementInput) *errors.AppError {
+	orgID, err := uuid.Parse(draft.OrgID)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式")
+	}
+	norm := h.normalizer(orgID)
+	for _, s := range shifts {
+		shift, appErr := shiftFromInput(s)
+		if appErr != nil {
+			return appErr
+		}
+		shift.OrgID = orgID
+		h.store.PutShift(shift)
+	}
+	reqs := make([]*model.ShiftRequirement, 0, len(requirements))
+	for _, in := range requirements {
+		requirement, appErr := requirementFromInput(in)
+		if appErr != nil {
+			return appErr
+		}
+		norm.Requirement(requirement)
+		requirement.OrgID = orgID
+		reqs = append(reqs, requirement)
+	}
+	if err := h.store.ReplaceRequirements(orgID, draft.StartDate, draft.EndDate, reqs); err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "保存需求失败")
+	}
+	return nil
+}
+
+// applyRequirementEdits 依次应用批量修改，返回修改后的班次、需求和每条修改的命中情况
+// 后一条修改的筛选基于前一条修改后的结果；平移班次时，若原班次仍被未命中的需求使用，
+// 则新建平移后的班次（同一原班次、同一平移量只新建一次），否则直接修改原班次
+func applyRequirementEdits(shifts []ShiftInput, requirements []RequirementInput, edits []RequirementEdit) ([]ShiftInput, []RequirementInput, []RequirementEditResult, *errors.AppError) {
+	shifts = append([]ShiftInput(nil), shifts...)
+	requirements = append([]RequirementInput(nil), requirements...)
+	results := make([]RequirementEditResult, len(edits))
+
+	for i, edit := range edits {
+		if err := edit.validate(); err != nil {
+			return nil, nil, nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("第 %d 条修改无效: %v", i+1, err))
+		}
+		shiftIndex := make(map[string]int, len(shifts))
+		for j, s := range shifts {
+			shiftIndex[s.ID] = j
+		}
+
+		matched := make([]int, 0)
+		for j, req := range requirements {
+			var shift *ShiftInput
+			if k, ok := shiftIndex[req.ShiftID]; ok {
+				shift = &shifts[k]
+			}
+			if edit.matches(req, shift) {
+				matched = append(matched, j)
+			}
+		}
+		results[i].Matched = len(matched)
+
+		for _, j := range matched {
+			req := &requirements[j]
+			if edit.MinEmployeesDelta != 0 {
+				req.MinEmployees = max(req.MinEmployees+edit.MinEmployeesDelta, 0)
+				if req.MaxEmployees > 0 && req.MaxEmployees < req.MinEmployees {
+					req.MaxEmployees = req.MinEmployees
+				}
+				if req.OptEmployees > 0 && req.OptEmployees < req.MinEmployees {
+					req.OptEmployees = req.MinEmployees
+				}
+			}
+		}
+
+		if edit.ShiftMinutes == 0 || len(matched) == 0 {
+			continue
+		}
+		// 统计每个班次被命中和总共被引用的次数，决定原地平移还是新建班次
+		hits := make(map[string]int)
+		refs := make(map[string]int)
+		for _, req := range requirements {
+			refs[req.ShiftID]++
+		}
+		for _, j := range matched {
+			hits[requirements[j].ShiftID]++
+		}
+		derived := make(map[string]string) // 原班次ID -> 平移后的班次ID
+		moved := make(map[string]bool)
+		for _, j := range matched {
+			shiftID := requirements[j].ShiftID
+			if moved[shiftID] {
+				continue
+			}
+			moved[shiftID] = true
+			k, ok := shiftIndex[shiftID]
+			if !ok {
+				return nil, nil, nil, errors.New(errors.CodeInvalidInput, "需求引用的班次不存在: "+shiftID)
+			}
+			shifted, err := shiftClock(shifts[k], edit.ShiftMinutes)
+			if err != nil {
+				return nil, nil, nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("班次 %s 的时间格式无效", shifts[k].Name))
+			}
+			if hits[shiftID] == refs[shiftID] {
+				shifts[k].StartTime, shifts[k].EndTime = shifted.StartTime, shifted.EndTime
+				continue
+			}
+			shifted.ID = uuid.New().String()
+			shifted.Name = fmt.Sprintf("%s(%+d分钟)", shifted.Name, edit.ShiftMinutes)
+			if shifted.Code != "" {
+				shifted.Code = fmt.Sprintf("%s%+d", shifted.Code, edit.ShiftMinutes)
+			}
+			shifts = append(shifts, shifted)
+			derived[shiftID] = shifted.ID
+			results[i].DerivedShifts = append(results[i].DerivedShifts, shifted.ID)
+		}
+		for _, j := range matched {
+			if id, ok := derived[requirements[j].ShiftID]; ok {
+				requirements[j].ShiftID = id
+			}
+		}
+	}
+	return shifts, requirements, results, nil
+}
+
+// validate 检查修改是否合法
+func (e *RequirementEdit) validate() error {
+	if e.MinEmployeesDelta == 0 && e.ShiftMinutes == 0 {
+		return fmt.Errorf("未指定修改内容")
+	}
+	if e.ShiftMinutes <= -24*60 || e.ShiftMinutes >= 24*60 {
+		return fmt.Errorf("平移分钟数应在 ±1440 以内")
+	}
+	for _, d := range e.Weekdays {
+		if d < 0 || d > 6 {
+			return fmt.Errorf("星期几应在 0~6 之间")
+		}
+	}
+	for _, d := range []string{e.StartDate, e.EndDate} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("日期格式无效，应为YYYY-MM-DD")
+		}
+	}
+	return nil
+}
+
+// matches 判断需求是否满足筛选条件，shift 为需求引用的班次（可能为空）
+func (e *RequirementEdit) matches(req RequirementInput, shift *ShiftInput) bool {
+	if e.StartDate != "" && req.Date < e.StartDate {
+		return false
+	}
+	if e.EndDate != "" && req.Date > e.EndDate {
+		return false
+	}
+	if len(e.Weekdays) > 0 {
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil || !containsInt(e.Weekdays, int(date.Weekday())) {
+			return false
+		}
+	}
+	if len(e.Positions) > 0 && !containsString(e.Positions, req.Position) {
+		return false
+	}
+	if len(e.Shifts) > 0 {
+		if containsString(e.Shifts, req.ShiftID) {
+			return true
+		}
+		return shift != nil && shift.Code != "" && containsString(e.Shifts, shift.Code)
+	}
+	return true
+}
+
+// shiftClock 返回起止时间平移 minutes 分钟后的班次副本，跨零点时按 24 小时取模
+func shiftClock(s ShiftInput, minutes int) (ShiftInput, error) {
+	for _, t := range []*string{&s.StartTime, &s.EndTime} {
+		clock, err := time.Parse("15:04", *t)
+		if err != nil {
+			return s, err
+		}
+		*t = clock.Add(time.Duration(minutes) * time.Minute).Format("15:04")
+	}
+	return s, nil
+}
+
+// containsInt 判断切片是否包含 v
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/reqspec"
+)
+
+// ParseRequirementSpecRequest 需求简写预览请求
+type ParseRequirementSpecRequest struct {
+	StartDate string       `json:"start_date"`
+	EndDate   string       `json:"end_date"`
+	Shifts    []ShiftInput `json:"shifts"`
+	Spec      string       `json:"spec"`
+}
+
+// ParseRequirementSpecResponse 需求简写预览结果
+type ParseRequirementSpecResponse struct {
+	Requirements []RequirementInput `json:"requirements"`
+	// 按班次汇总的需求条数和人数下限
+	Summary []RequirementSpecSummary `json:"summary"`
+}
+
+// RequirementSpecSummary 单个班次（岗位）的需求汇总
+type RequirementSpecSummary struct {
+	ShiftID      string `json:"shift_id"`
+	ShiftName    string `json:"shift_name"`
+	Position     string `json:"position,omitempty"`
+	Days         int    `json:"days"`
+	MinEmployees int    `json:"min_employees"` // 各天人数下限之和
+}
+
+// ParseRequirementSpec 预览需求简写展开后的班次需求，不执行排班
+// 路由: POST /api/v1/requirements/parse
+// 简写无法解析时返回 VALIDATION_FAILED，details 中给出出错的条目序号、原文和原因
+func (h *ScheduleHandler) ParseRequirementSpec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+
+	var req ParseRequirementSpecRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if len(req.Shifts) == 0 {
+		respondError(w, errors.New(errors.CodeInvalidInput, "班次列表不能为空"))
+		return
+	}
+
+	requirements, appErr := expandRequirementSpec(req.Spec, req.Shifts, req.StartDate, req.EndDate)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+
+	names := make(map[string]string, len(req.Shifts))
+	for _, s := range req.Shifts {
+		names[s.ID] = s.Name
+	}
+	resp := ParseRequirementSpecResponse{Requirements: requirements, Summary: make([]RequirementSpecSummary, 0)}
+	index := make(map[string]int)
+	for _, rq := range requirements {
+		key := rq.ShiftID + "/" + rq.Position
+		i, ok := index[key]
+		if !ok {
+			i = len(resp.Summary)
+			index[key] = i
+			resp.Summary = append(resp.Summary, RequirementSpecSummary{ShiftID: rq.ShiftID, ShiftName: names[rq.ShiftID], Position: rq.Position})
+		}
+		resp.Summary[i].Days++
+		resp.Summary[i].MinEmployees += rq.MinEmployees
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// expandRequirementSpec 将需求简写展开为需求输入
+func expandRequirementSpec(spec string, shiftInputs []ShiftInput, startDate, endDate string) ([]RequirementInput, *errors.AppError) {
+	shifts := make([]*model.Shift, 0, len(shiftInputs))
+	for _, s := range shiftInputs {
+		shift, appErr := shiftFromInput(s)
+		if appErr != nil {
+			return nil, appErr
+		}
+		shifts = append(shifts, shift)
+	}
+
+	parsed, err := reqspec.Parse(spec, shifts, startDate, endDate)
+	if err != nil {
+		var pe *reqspec.ParseError
+		if stderrors.As(err, &pe) {
+			return nil, errors.New(errors.CodeValidationFail, "需求简写解析失败").WithDetails(pe.Error())
+		}
+		return nil, errors.New(errors.CodeInvalidInput, "需求简写解析失败: "+err.Error())
+	}
+
+	requirements := make([]RequirementInput, 0, len(parsed))
+	for _, rq := range parsed {
+		requirements = append(requirements, RequirementInput{
+			ShiftID:      rq.ShiftID.String(),
+			Date:         rq.Date,
+			Position:     rq.Position,
+			MinEmployees: rq.MinEmployees,
+			MaxEmployees: rq.MaxEmployees,
+		})
+	}
+	return requirements, nil
+}
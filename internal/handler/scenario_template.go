@@ -0,0 +1,376 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/constraints"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// scenarioKeyPattern 自定义场景标识：小写字母开头，由小写字母、数字和下划线组成
+var scenarioKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// ScenarioTemplateHandler 自定义场景模板处理器
+// 配置数据库时读写 ScenarioTemplateRepository，否则使用内存存储
+type ScenarioTemplateHandler struct {
+	repo    repository.ScenarioTemplateRepositoryInterface
+	store   *memstore.Store
+	catalog *constraints.Catalog // 内置场景标识不能用作自定义场景
+}
+
+// NewScenarioTemplateHandler 创建自定义场景模板处理器，repo 为空时使用内存存储
+func NewScenarioTemplateHandler(repo *repository.ScenarioTemplateRepository, store *memstore.Store, catalog *constraints.Catalog) *ScenarioTemplateHandler {
+	h := &ScenarioTemplateHandler{
+		store:   store,
+		catalog: catalog,
+	}
+	if repo != nil {
+		h.repo = repo
+	}
+	return h
+}
+
+// ScenarioTemplateInput 自定义场景模板的新增/更新/复制请求，更新和复制时只修改给出的字段
+type ScenarioTemplateInput struct {
+	Scenario    *string                `json:"scenario,omitempty"`
+	Name        *string                `json:"name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	Constraints map[string]interface{} `json:"constraints,omitempty"` // 格式同排班请求的 constraints
+}
+
+// ScenarioTemplateListResponse 场景模板列表响应
+type ScenarioTemplateListResponse struct {
+	Templates []*model.ScenarioTemplate `json:"templates"`
+	Total     int                       `json:"total"`
+}
+
+// Collection 列出或新增场景模板
+// 路由: GET /api/v1/scenario-templates
+// 路由: POST /api/v1/scenario-templates
+func (h *ScenarioTemplateHandler) Collection(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := h.list(r.Context())
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询场景模板失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, ScenarioTemplateListResponse{Templates: list, Total: len(list)})
+
+	case http.MethodPost:
+		if !requireAdmin(w, r) {
+			return
+		}
+		var input ScenarioTemplateInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		now := time.Now()
+		t := &model.ScenarioTemplate{ID: uuid.New(), CreatedAt: now, UpdatedAt: now}
+		input.apply(t)
+		if appErr := h.save(r.Context(), t, true); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		respondJSON(w, http.StatusCreated, t)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Item 获取、更新或删除场景模板（内置场景的默认模板只读）
+// 路由: GET|PUT|DELETE /api/v1/scenario-templates/{id}
+func (h *ScenarioTemplateHandler) Item(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的场景模板ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		t, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		respondJSON(w, http.StatusOK, t)
+
+	case http.MethodPut:
+		if !requireAdmin(w, r) {
+			return
+		}
+		var input ScenarioTemplateInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		t, appErr := h.editable(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		input.apply(t)
+		t.UpdatedAt = time.Now()
+		if appErr := h.save(r.Context(), t, false); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		respondJSON(w, http.StatusOK, t)
+
+	case http.MethodDelete:
+		if !requireAdmin(w, r) {
+			return
+		}
+		if _, appErr := h.editable(r.Context(), id); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		var err error
+		if h.repo != nil {
+			err = h.repo.Delete(r.Context(), id)
+		} else {
+			err = h.store.DeleteScenarioTemplate(id)
+		}
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "删除场景模板失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"deleted": true,
+			"id":      id.String(),
+		})
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT/DELETE方法"))
+	}
+}
+
+// Clone 复制场景模板为新的自定义场景模板（可复制内置场景的默认模板），须给出新的场景标识
+// 路由: POST /api/v1/scenario-templates/{id}/clone
+func (h *ScenarioTemplateHandler) Clone(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if !h.ready(w) || !requireAdmin(w, r) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的场景模板ID格式"))
+		return
+	}
+	var input ScenarioTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if input.Scenario == nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "复制模板须给出新的场景标识 scenario"))
+		return
+	}
+	source, appErr := h.get(r.Context(), id)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+
+	now := time.Now()
+	t := &model.ScenarioTemplate{
+		ID:          uuid.New(),
+		Name:        source.Name + "（副本）",
+		Description: source.Description,
+		Constraints: source.Constraints,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	input.apply(t)
+	if appErr := h.save(r.Context(), t, true); appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	respondJSON(w, http.StatusCreated, t)
+}
+
+// apply 将请求中给出的字段写入场景模板
+func (in ScenarioTemplateInput) apply(t *model.ScenarioTemplate) {
+	if in.Scenario != nil {
+		t.Scenario = *in.Scenario
+	}
+	if in.Name != nil {
+		t.Name = *in.Name
+	}
+	if in.Description != nil {
+		t.Description = *in.Description
+	}
+	if in.Constraints != nil {
+		t.Constraints = model.JSONMap(in.Constraints)
+	}
+	if t.Constraints == nil {
+		t.Constraints = model.JSONMap{}
+	}
+}
+
+// save 校验并保存自定义场景模板，场景标识重复时返回 409
+func (h *ScenarioTemplateHandler) save(ctx context.Context, t *model.ScenarioTemplate, create bool) *errors.AppError {
+	if appErr := h.validate(t); appErr != nil {
+		return appErr
+	}
+
+	if h.repo == nil {
+		err := h.store.PutScenarioTemplate(t)
+		if err == memstore.ErrDuplicate {
+			return errors.New(errors.CodeAlreadyExists, "场景标识已存在: "+t.Scenario)
+		}
+		if err != nil {
+			return errors.Wrap(err, errors.CodeInternal, "保存场景模板失败")
+		}
+		return nil
+	}
+
+	existing, err := h.repo.GetCustom(ctx, t.Scenario)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "查询场景模板失败")
+	}
+	if existing != nil && existing.ID != t.ID {
+		return errors.New(errors.CodeAlreadyExists, "场景标识已存在: "+t.Scenario)
+	}
+	record := toScenarioTemplateRecord(t)
+	if create {
+		err = h.repo.Create(ctx, record)
+	} else {
+		err = h.repo.Update(ctx, record)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "保存场景模板失败")
+	}
+	return nil
+}
+
+// validate 校验场景标识、名称和约束配置（插件约束按工厂校验参数）
+func (h *ScenarioTemplateHandler) validate(t *model.ScenarioTemplate) *errors.AppError {
+	if !scenarioKeyPattern.MatchString(t.Scenario) {
+		return errors.New(errors.CodeInvalidInput, "场景标识须以小写字母开头，由小写字母、数字和下划线组成（2-50个字符）")
+	}
+	if h.catalog != nil {
+		for _, tpl := range h.catalog.Templates() {
+			if tpl.Scenario == t.Scenario {
+				return errors.New(errors.CodeInvalidInput, fmt.Sprintf("%s 为内置场景，不能用作自定义场景标识", t.Scenario))
+			}
+		}
+	}
+	if t.Name == "" {
+		return errors.New(errors.CodeInvalidInput, "模板名称不能为空")
+	}
+	if _, appErr := newConstraintManager(t.Constraints); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// get 获取场景模板，不存在时返回 404
+func (h *ScenarioTemplateHandler) get(ctx context.Context, id uuid.UUID) (*model.ScenarioTemplate, *errors.AppError) {
+	if h.repo == nil {
+		t, err := h.store.GetScenarioTemplate(id)
+		if err != nil {
+			return nil, errors.New(errors.CodeNotFound, "场景模板不存在")
+		}
+		return t, nil
+	}
+	record, err := h.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInternal, "查询场景模板失败")
+	}
+	if record == nil {
+		return nil, errors.New(errors.CodeNotFound, "场景模板不存在")
+	}
+	return fromScenarioTemplateRecord(record), nil
+}
+
+// editable 获取可修改的场景模板，内置场景的默认模板返回 403
+func (h *ScenarioTemplateHandler) editable(ctx context.Context, id uuid.UUID) (*model.ScenarioTemplate, *errors.AppError) {
+	t, appErr := h.get(ctx, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if t.IsDefault {
+		return nil, errors.New(errors.CodeForbidden, "内置场景的默认模板不可修改，请复制后修改")
+	}
+	return t, nil
+}
+
+// list 列出全部场景模板（含数据库中内置场景的默认模板）
+func (h *ScenarioTemplateHandler) list(ctx context.Context) ([]*model.ScenarioTemplate, error) {
+	if h.repo == nil {
+		return h.store.ListScenarioTemplates(), nil
+	}
+	records, err := h.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*model.ScenarioTemplate, 0, len(records))
+	for i := range records {
+		result = append(result, fromScenarioTemplateRecord(&records[i]))
+	}
+	return result, nil
+}
+
+// ready 检查是否启用了场景模板存储
+func (h *ScenarioTemplateHandler) ready(w http.ResponseWriter) bool {
+	if h.repo == nil && h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// toScenarioTemplateRecord 转换为数据库场景模板记录
+func toScenarioTemplateRecord(t *model.ScenarioTemplate) *repository.ScenarioTemplate {
+	return &repository.ScenarioTemplate{
+		ID:          t.ID,
+		Name:        t.Name,
+		Scenario:    t.Scenario,
+		Description: t.Description,
+		Constraints: t.Constraints,
+		IsDefault:   t.IsDefault,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
+
+// fromScenarioTemplateRecord 从数据库场景模板记录转换
+func fromScenarioTemplateRecord(r *repository.ScenarioTemplate) *model.ScenarioTemplate {
+	config := model.JSONMap(r.Constraints)
+	if config == nil {
+		config = model.JSONMap{}
+	}
+	return &model.ScenarioTemplate{
+		ID:          r.ID,
+		Scenario:    r.Scenario,
+		Name:        r.Name,
+		Description: r.Description,
+		Constraints: config,
+		IsDefault:   r.IsDefault,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
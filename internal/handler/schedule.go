@@ -6,15 +6,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/constraints"
+	"github.com/paiban/paiban/internal/genjob"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/metrics"
+	"github.com/paiban/paiban/internal/notify"
 	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/alias"
+	"github.com/paiban/paiban/pkg/compliance"
+	"github.com/paiban/paiban/pkg/costing"
 	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/logger"
 	"github.com/paiban/paiban/pkg/model"
 	"github.com/paiban/paiban/pkg/scheduler/constraint"
 	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/optimizer"
 	"github.com/paiban/paiban/pkg/scheduler/solver"
+	"github.com/paiban/paiban/pkg/stats"
 )
 
 // ScheduleHandler 排班处理器
@@ -22,6 +34,30 @@ type ScheduleHandler struct {
 	scheduleRepo *repository.ScheduleRepository
 	employeeRepo *repository.EmployeeRepository
 	shiftRepo    *repository.ShiftRepository
+
+	// 组织级约束配置（配置数据库时），请求未提供 constraints 时作为默认约束参数
+	constraintRepo repository.ConstraintRepositoryInterface
+	// 自定义场景模板（配置数据库时），请求的 scenario 为自定义场景标识时作为约束参数的基础
+	scenarioRepo repository.ScenarioTemplateRepositoryInterface
+	// 排班需求（配置数据库时），请求未提供需求时使用组织在排班周期内的需求
+	requirementRepo repository.RequirementRepositoryInterface
+	// 班组（配置数据库时），请求未提供班组时使用组织的班组
+	teamRepo repository.TeamRepositoryInterface
+
+	// 无数据库模式下的内存状态存储（可选）
+	store    *memstore.Store
+	jobs     *genjob.Runner  // 异步生成作业（启用存储时）
+	notifier notify.Notifier // 缺员通知（可选）
+
+	defaults GenerateDefaults
+}
+
+// GenerateDefaults 服务端配置的生成默认值，请求未指定时使用
+type GenerateDefaults struct {
+	Timeout           time.Duration
+	OptimizationLevel int
+	Workers           int
+	Constraints       map[string]interface{} // 优先级最低的默认约束参数
 }
 
 // NewScheduleHandler 创建排班处理器
@@ -42,17 +78,82 @@ func NewScheduleHandlerWithoutDB() *ScheduleHandler {
 	return &ScheduleHandler{}
 }
 
+// SetStore 设置内存状态存储，设置后生成的排班及其员工、班次会被保存
+func (h *ScheduleHandler) SetStore(store *memstore.Store) {
+	h.store = store
+}
+
+// SetJobRunner 设置异步生成作业执行器，设置后支持 POST /api/v1/schedule/generate?async=true
+func (h *ScheduleHandler) SetJobRunner(runner *genjob.Runner) {
+	h.jobs = runner
+}
+
+// SetNotifier 设置通知发送器，设置后保存的排班存在未满足的需求时发送 unfilled_shift 通知
+func (h *ScheduleHandler) SetNotifier(notifier notify.Notifier) {
+	h.notifier = notifier
+}
+
+// SetDefaults 设置生成默认值（超时、优化级别、并行协程数和默认约束参数）
+func (h *ScheduleHandler) SetDefaults(defaults GenerateDefaults) {
+	h.defaults = defaults
+}
+
+// SetConstraintRepository 设置约束配置仓储，设置后排班请求未提供 constraints 时从数据库加载组织默认约束
+func (h *ScheduleHandler) SetConstraintRepository(repo *repository.ConstraintRepository) {
+	if repo != nil {
+		h.constraintRepo = repo
+	}
+}
+
+// SetScenarioTemplateRepository 设置场景模板仓储，设置后请求的 scenario 为自定义场景标识时从数据库解析模板
+func (h *ScheduleHandler) SetScenarioTemplateRepository(repo *repository.ScenarioTemplateRepository) {
+	if repo != nil {
+		h.scenarioRepo = repo
+	}
+}
+
+// SetRequirementRepository 设置排班需求仓储，设置后请求未提供需求时从数据库加载组织的需求
+func (h *ScheduleHandler) SetRequirementRepository(repo *repository.RequirementRepository) {
+	if repo != nil {
+		h.requirementRepo = repo
+	}
+}
+
+// SetTeamRepository 设置班组仓储，设置后请求未提供班组时从数据库加载组织的班组
+func (h *ScheduleHandler) SetTeamRepository(repo *repository.TeamRepository) {
+	if repo != nil {
+		h.teamRepo = repo
+	}
+}
+
 // GenerateRequest 排班生成请求
 type GenerateRequest struct {
-	OrgID        string                 `json:"org_id"`
-	StartDate    string                 `json:"start_date"`
-	EndDate      string                 `json:"end_date"`
-	Scenario     string                 `json:"scenario,omitempty"` // restaurant/factory/housekeeping/nursing
-	Employees    []EmployeeInput        `json:"employees"`
-	Shifts       []ShiftInput           `json:"shifts"`
-	Requirements []RequirementInput     `json:"requirements"`
-	Constraints  map[string]interface{} `json:"constraints,omitempty"`
-	Options      *GenerateOptions       `json:"options,omitempty"`
+	OrgID        string             `json:"org_id"`
+	StartDate    string             `json:"start_date"`
+	EndDate      string             `json:"end_date"`
+	Scenario     string             `json:"scenario,omitempty"` // restaurant/factory/housekeeping/nursing
+	Employees    []EmployeeInput    `json:"employees"`
+	Shifts       []ShiftInput       `json:"shifts"`
+	Requirements []RequirementInput `json:"requirements"`
+	// 需求简写（如 "早班 weekdays 2-3人; 厨师≥1 every shift"），requirements 为空时按简写生成需求
+	RequirementSpec string                 `json:"requirement_spec,omitempty"`
+	Constraints     map[string]interface{} `json:"constraints,omitempty"`
+	Options         *GenerateOptions       `json:"options,omitempty"`
+
+	// 外部人员（派遣、零工），为空时使用组织的外部人员池；只在内部员工无法满足需求时排班
+	ExternalWorkers []*model.ExternalWorker `json:"external_workers,omitempty"`
+
+	// 多门店排班的门店列表（名称、门店间通勤时间），班次和需求通过 store_id 引用
+	Stores []model.Store `json:"stores,omitempty"`
+
+	// 班组（成员、班次一致性要求），未提供时使用组织保存的班组；同组成员优先安排在同一天的同一班次
+	Teams []model.Team `json:"teams,omitempty"`
+
+	// 劳动法合规规则包（CN/CN-Shanghai/EU-working-time），按规则包注册对应的硬约束并返回合规报告
+	Jurisdiction string `json:"jurisdiction,omitempty"`
+
+	// 重新生成的排班草稿ID：结果保存为该排班的新版本（排班ID不变），旧版本可通过版本接口查看和对比
+	ScheduleID string `json:"schedule_id,omitempty"`
 }
 
 // EmployeeInput 员工输入
@@ -61,8 +162,26 @@ type EmployeeInput struct {
 	Name                string         `json:"name"`
 	Position            string         `json:"position,omitempty"`
 	Skills              []string       `json:"skills,omitempty"`
+	Certifications      []string       `json:"certifications,omitempty"`
 	Status              string         `json:"status,omitempty"`
+	BirthDate           string         `json:"birth_date,omitempty"`            // 出生日期，未成年工保护规则按此判断
+	HourlyRate          float64        `json:"hourly_rate,omitempty"`           // 时薪，用于人工成本估算
+	StoreID             string         `json:"store_id,omitempty"`              // 所属门店
+	HomeStore           string         `json:"home_store,omitempty"`            // 所属门店（store_id 的别名）
+	AllowedStores       []string       `json:"allowed_stores,omitempty"`        // 可跨店支援的门店
 	MonthlyShiftsCounts map[string]int `json:"monthly_shifts_counts,omitempty"` // 每月已有班次数 (key: YYYY-MM)
+
+	Preferences *model.EmployeePreferences `json:"preferences,omitempty"` // 员工偏好（含班次志愿排名）
+	Contract    *model.EmployeeContract    `json:"contract,omitempty"`    // 合同约束（月度汇总目标工时）
+
+	AvailabilityWindows []model.AvailabilityWindow   `json:"availability_windows,omitempty"` // 可用时间窗口（如工作日 09:00-14:00）
+	Availability        []model.EmployeeAvailability `json:"availability,omitempty"`         // 按日期登记的可用性，为空时使用存储中的登记
+	UnavailableWindows  []model.AvailabilityWindow   `json:"unavailable_windows,omitempty"`  // 每周重复的不可用时段，start/end 为空表示全天
+	Leaves              []model.EmployeeLeave        `json:"leaves,omitempty"`               // 请假日期区间
+
+	VerifiedCertifications []model.VerifiedCertification `json:"verified_certifications,omitempty"` // 已核验的证书，为空时使用存储中审核通过的证书材料
+	CertificationRecords   []model.Certification         `json:"certification_records,omitempty"`   // 带有效期的证书，过期后不满足资质要求
+	SkillLevels            []model.Skill                 `json:"skill_levels,omitempty"`            // 技能等级，需求要求技能等级时使用
 }
 
 // ShiftInput 班次输入
@@ -74,11 +193,14 @@ type ShiftInput struct {
 	EndTime   string `json:"end_time"`   // HH:MM
 	Duration  int    `json:"duration"`   // 分钟
 	Type      string `json:"type,omitempty"`
+	StoreID   string `json:"store_id,omitempty"` // 所属门店，未指定门店的需求沿用班次的门店
 }
 
 // RequirementInput 需求输入
 type RequirementInput struct {
+	ID           string   `json:"id,omitempty"` // 已保存需求的ID，为空时生成新ID
 	ShiftID      string   `json:"shift_id"`
+	ShiftCode    string   `json:"shift_code,omitempty"` // 未给出 shift_id 时按班次编码匹配
 	Date         string   `json:"date"`
 	Position     string   `json:"position,omitempty"`
 	MinEmployees int      `json:"min_employees"`
@@ -86,15 +208,36 @@ type RequirementInput struct {
 	OptEmployees int      `json:"opt_employees,omitempty"`
 	Skills       []string `json:"skills,omitempty"`
 	Priority     int      `json:"priority,omitempty"`
+	StoreID      string   `json:"store_id,omitempty"` // 所属门店，配置了营业时间时班次须在营业时间内
+
+	SkillGroups []model.SkillGroup `json:"skill_groups,omitempty"` // 技能组（组内任选，组间都需满足）
+	SkillLevels []model.Skill      `json:"skill_levels,omitempty"` // 技能最低等级，如 {"name": "grill", "level": 3}
 }
 
 // GenerateOptions 生成选项
 type GenerateOptions struct {
 	Timeout            int  `json:"timeout_seconds,omitempty"`
-	OptimizationLevel  int  `json:"optimization_level,omitempty"` // 1=快速, 2=平衡, 3=最优
+	OptimizationLevel  int  `json:"optimization_level,omitempty"` // 1=快速, 2=平衡（贪心 + 优化）, 3=最优（模拟退火）
 	RespectPreferences bool `json:"respect_preferences,omitempty"`
+	HistoryDays        int  `json:"history_days,omitempty"` // 加载上一期排班末尾的天数，默认 7，负数不加载
+
+	Optimizer string `json:"optimizer,omitempty"` // optimization_level=2 的优化算法：local_search（默认）/genetic
+	Seed      int64  `json:"seed,omitempty"`      // 随机种子，非 0 时启用确定性模式：相同输入和种子总是得到相同的排班
+	Workers   int    `json:"workers,omitempty"`   // 并行评估候选人的工作协程数（最多 32），0 或 1 时逐个评估；结果与逐个评估相同
+
+	ExternalHoursCap float64 `json:"external_hours_cap,omitempty"` // 本期外部人员总工时上限，覆盖外部人员池的配置
+	NoExternal       bool    `json:"no_external,omitempty"`        // 不使用外部人员
+
+	DryRun bool `json:"dry_run,omitempty"` // 只试算，不保存排班、员工、班次和需求
+
+	// 多目标评分权重（coverage/fairness/cost/preference/continuity，未给出的目标为 1），
+	// 作为 optimization_level=2 优化阶段的目标函数，生效的方案和各目标子得分在 statistics.scoring 中返回
+	ScoringProfile map[string]float64 `json:"scoring_profile,omitempty"`
 }
 
+// maxCandidateWorkers 并行评估候选人的工作协程数上限
+const maxCandidateWorkers = 32
+
 // GenerateResponse 排班生成响应
 type GenerateResponse struct {
 	Success     bool                    `json:"success"`
@@ -102,11 +245,30 @@ type GenerateResponse struct {
 	Message     string                  `json:"message,omitempty"`
 	ScheduleID  string                  `json:"schedule_id,omitempty"`
 	Assignments []AssignmentOutput      `json:"assignments"`
+	OnCall      []AssignmentOutput      `json:"on_call,omitempty"`  // 待命安排（不计入工时、满足率和成本）
 	Unfilled    []UnfilledRequirement   `json:"unfilled,omitempty"` // 未满足的需求
 	Statistics  *solver.Statistics      `json:"statistics"`
 	Constraints *ConstraintResultOutput `json:"constraint_result"`
 	Duration    string                  `json:"duration"`
 	Suggestions []StaffingSuggestion    `json:"suggestions,omitempty"` // 补员建议
+	Anomalies   []stats.Anomaly         `json:"anomalies,omitempty"`   // 与历史相比的异常结果
+
+	UnmappedLabels []model.UnmappedLabel `json:"unmapped_labels,omitempty"`         // 未映射或无人具备的技能/岗位标签
+	Blackouts      []string              `json:"blackout_periods,omitempty"`        // 生效的请假管控期（期间需求已提升优先级）
+	OpeningHours   []string              `json:"opening_hours_conflicts,omitempty"` // 因门店闭店或超出营业时间而跳过的需求
+
+	ExternalLabor      *ExternalLaborSummary `json:"external_labor,omitempty"`       // 外部人员用工与费用
+	PreviousScheduleID string                `json:"previous_schedule_id,omitempty"` // 作为固定历史加载的上一期排班
+	HistoryAssignments int                   `json:"history_assignments,omitempty"`  // 加载的历史分配数量
+
+	Stores []StoreCoverage `json:"stores,omitempty"` // 多门店排班时各门店的覆盖情况
+
+	ExpiringCertifications []CertificationExpiryWarning `json:"expiring_certifications,omitempty"` // 排班期间到期的证书
+
+	Compliance  *compliance.Report `json:"compliance,omitempty"`   // 指定 jurisdiction 时的劳动法合规报告
+	CostSummary *costing.Summary   `json:"cost_summary,omitempty"` // 人工成本估算（正常/加班/节假日工时，按岗位和日期汇总）
+
+	Seed int64 `json:"seed,omitempty"` // 确定性模式使用的随机种子
 }
 
 // StaffingSuggestion 补员建议
@@ -131,6 +293,9 @@ type UnfilledRequirement struct {
 	Reason    string `json:"reason,omitempty"`
 	StoreID   string `json:"store_id,omitempty"`
 	StoreName string `json:"store_name,omitempty"`
+
+	// 未排满原因诊断：逐个员工说明被哪项筛选条件或硬约束排除（仅生成排班时提供）
+	Diagnostics *solver.Diagnosis `json:"diagnostics,omitempty"`
 }
 
 // AssignmentOutput 排班输出
@@ -144,10 +309,17 @@ type AssignmentOutput struct {
 	StartTime    string  `json:"start_time"`
 	EndTime      string  `json:"end_time"`
 	Position     string  `json:"position,omitempty"`
+	StoreID      string  `json:"store_id,omitempty"`
+	StoreName    string  `json:"store_name,omitempty"`
 	Hours        float64 `json:"hours"`
 	// 综合评分（0-100）
 	Score       float64          `json:"score"`
 	ScoreDetail *AssignmentScore `json:"score_detail,omitempty"`
+
+	// 外部人员分配
+	External bool    `json:"external,omitempty"`
+	Agency   string  `json:"agency,omitempty"`
+	Cost     float64 `json:"cost,omitempty"` // 工时 × 外部人员时薪
 }
 
 // AssignmentScore 排班分配评分明细
@@ -169,6 +341,7 @@ type ConstraintResultOutput struct {
 }
 
 // Generate 生成排班
+// 路由: POST /api/v1/schedule/generate[?async=true]
 func (h *ScheduleHandler) Generate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
@@ -182,132 +355,326 @@ func (h *ScheduleHandler) Generate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// 未提供员工列表、需求时使用组织保存的员工档案和需求，班次可按编码引用组织的班次定义
+	if err := h.withStoredEmployees(r.Context(), &req); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := h.withStoredShifts(r.Context(), &req); err != nil {
+		respondError(w, err)
+		return
+	}
+	if err := h.withStoredRequirements(r.Context(), &req); err != nil {
+		respondError(w, err)
+		return
+	}
+
 	// 验证请求
 	if err := validateGenerateRequest(&req); err != nil {
 		respondError(w, err)
 		return
 	}
 
+	// 异步模式：保存为作业立即返回，通过 /api/v1/schedule/jobs/{id} 查询进度
+	if r.URL.Query().Get("async") == "true" {
+		h.submitAsync(w, r, &req)
+		return
+	}
+
+	resp, appErr := h.generate(r.Context(), &req)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	if req.Options == nil || !req.Options.DryRun {
+		orgID, _ := uuid.Parse(req.OrgID)
+		recordAudit(w, r, orgID, model.AuditScheduleGenerate, "schedule", resp.ScheduleID, map[string]interface{}{
+			"start_date":  req.StartDate,
+			"end_date":    req.EndDate,
+			"assignments": len(resp.Assignments),
+			"unfilled":    len(resp.Unfilled),
+			"regenerate":  req.ScheduleID != "",
+		})
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// generate 执行排班生成，供各版本 API 共用
+func (h *ScheduleHandler) generate(reqCtx context.Context, req *GenerateRequest) (*GenerateResponse, *errors.AppError) {
 	// 构建排班上下文
 	orgID, err := uuid.Parse(req.OrgID)
 	if err != nil {
-		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
-		return
+		return nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式")
+	}
+	if appErr := h.checkRegenerate(orgID, req); appErr != nil {
+		return nil, appErr
+	}
+	if len(req.Requirements) == 0 && req.RequirementSpec != "" {
+		requirements, appErr := expandRequirementSpec(req.RequirementSpec, req.Shifts, req.StartDate, req.EndDate)
+		if appErr != nil {
+			return nil, appErr
+		}
+		req.Requirements = requirements
+	}
+	if appErr := validateStores(req); appErr != nil {
+		return nil, appErr
+	}
+	if appErr := validateTeams(req); appErr != nil {
+		return nil, appErr
+	}
+	req.Options = h.withDefaultOptions(req.Options)
+	// 约束参数优先级：请求 constraints > 自定义场景模板 > 组织默认约束（仅请求未提供 constraints 时）> 服务端默认约束
+	template, appErr := h.scenarioTemplate(reqCtx, req.Scenario)
+	if appErr != nil {
+		return nil, appErr
+	}
+	var baseConfig map[string]interface{}
+	if len(req.Constraints) == 0 {
+		if baseConfig, appErr = h.orgConstraints(reqCtx, orgID); appErr != nil {
+			return nil, appErr
+		}
+	}
+	if template != nil {
+		baseConfig = mergeConfig(baseConfig, template.Constraints)
+	}
+	if len(h.defaults.Constraints) > 0 {
+		baseConfig = mergeConfig(h.defaults.Constraints, baseConfig)
+	}
+	if len(baseConfig) > 0 {
+		req.Constraints = mergeConfig(baseConfig, req.Constraints)
+	}
+	// 合规规则包：与约束参数合并，取更严格的取值
+	var pack *compliance.Pack
+	if req.Jurisdiction != "" {
+		var ok bool
+		if pack, ok = compliance.Lookup(req.Jurisdiction); !ok {
+			return nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("未知的合规辖区: %s（可选: %s）",
+				req.Jurisdiction, strings.Join(compliance.Codes(), ", ")))
+		}
+		req.Constraints = pack.Apply(req.Constraints)
+	}
+	if appErr := validateHourlyStaffing(req.Constraints); appErr != nil {
+		return nil, appErr
 	}
 	ctx := constraint.NewContext(orgID, req.StartDate, req.EndDate)
+	norm := h.normalizer(orgID)
 
 	// 设置员工
 	employees := make([]*model.Employee, 0, len(req.Employees))
 	empNameMap := make(map[uuid.UUID]string)
 	empMap := make(map[uuid.UUID]*model.Employee)
-	for _, e := range req.Employees {
+	verified := h.verifiedCertifications(orgID)
+	for i, e := range req.Employees {
 		id, err := uuid.Parse(e.ID)
 		if err != nil {
-			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式: "+e.ID))
-			return
+			return nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式: "+e.ID)
 		}
 		emp := &model.Employee{
 			BaseModel:           model.BaseModel{ID: id},
 			Name:                e.Name,
 			Position:            e.Position,
 			Skills:              e.Skills,
+			Certifications:      e.Certifications,
+			SkillLevels:         e.SkillLevels,
 			Status:              e.Status,
+			BirthDate:           e.BirthDate,
+			HourlyRate:          e.HourlyRate,
+			StoreID:             e.homeStore(),
+			AllowedStores:       e.AllowedStores,
 			MonthlyShiftsCounts: e.MonthlyShiftsCounts,
+			Preferences:         e.Preferences,
+			Contract:            e.Contract,
+			AvailabilityWindows: e.AvailabilityWindows,
+			Availability:        e.Availability,
+			UnavailableWindows:  e.UnavailableWindows,
+			Leaves:              e.Leaves,
+
+			VerifiedCertifications: e.VerifiedCertifications,
+			CertificationRecords:   e.CertificationRecords,
 		}
 		if emp.Status == "" {
 			emp.Status = "active"
 		}
+		for _, skill := range emp.SkillLevels {
+			if err := skill.Validate(); err != nil {
+				return nil, errors.Wrap(err, errors.CodeInvalidInput, fmt.Sprintf("员工 %s: %s", e.Name, err.Error()))
+			}
+		}
+		for _, cert := range emp.CertificationRecords {
+			if err := cert.Validate(); err != nil {
+				return nil, errors.Wrap(err, errors.CodeInvalidInput, fmt.Sprintf("员工 %s: %s", e.Name, err.Error()))
+			}
+		}
+		for _, w := range emp.AvailabilityWindows {
+			if !validClock(w.Start) || !validClock(w.End) {
+				return nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("员工 %s 的可用时间窗口格式无效: %s-%s", e.Name, w.Start, w.End))
+			}
+		}
+		for _, w := range emp.UnavailableWindows {
+			if (w.Start != "" || w.End != "") && (!validClock(w.Start) || !validClock(w.End)) {
+				return nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("员工 %s 的不可用时段格式无效: %s-%s", e.Name, w.Start, w.End))
+			}
+		}
+		for _, l := range emp.Leaves {
+			if !validLeave(l) {
+				return nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("员工 %s 的请假日期无效: %s~%s", e.Name, l.StartDate, l.EndDate))
+			}
+		}
+		if len(emp.Availability) == 0 && h.store != nil {
+			for _, av := range h.store.ListAvailability(id, req.StartDate, req.EndDate) {
+				emp.Availability = append(emp.Availability, *av)
+			}
+		}
+		if len(emp.VerifiedCertifications) == 0 {
+			emp.VerifiedCertifications = verified[id]
+		}
+		// 岗位/技能按组织别名归一化，并同步回输入供补员建议等使用
+		norm.Employee(emp)
+		req.Employees[i].Position, req.Employees[i].Skills = emp.Position, emp.Skills
 		employees = append(employees, emp)
 		empNameMap[id] = e.Name
 		empMap[id] = emp
 	}
-	ctx.SetEmployees(employees)
+
+	// 外部人员排在内部员工之后参与候选，不写入员工库
+	externals, externalCap, appErr := h.externalWorkers(orgID, req, empMap)
+	if appErr != nil {
+		return nil, appErr
+	}
+	externalMap := make(map[uuid.UUID]*model.Employee, len(externals))
+	for _, emp := range externals {
+		externalMap[emp.ID] = emp
+		empNameMap[emp.ID] = emp.Name
+		empMap[emp.ID] = emp
+	}
+	candidates := make([]*model.Employee, 0, len(employees)+len(externals))
+	ctx.SetEmployees(append(append(candidates, employees...), externals...))
+	ctx.ExternalHoursCap = externalCap
 
 	// 设置班次
 	shifts := make([]*model.Shift, 0, len(req.Shifts))
 	shiftNameMap := make(map[uuid.UUID]string)
+	shiftStores := make(map[uuid.UUID]string)
 	for _, s := range req.Shifts {
-		id, err := uuid.Parse(s.ID)
-		if err != nil {
-			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的班次ID格式: "+s.ID))
-			return
-		}
-		shift := &model.Shift{
-			BaseModel: model.BaseModel{ID: id},
-			Name:      s.Name,
-			Code:      s.Code,
-			StartTime: s.StartTime,
-			EndTime:   s.EndTime,
-			Duration:  s.Duration,
-			ShiftType: s.Type,
-			IsActive:  true,
+		shift, appErr := shiftFromInput(s)
+		if appErr != nil {
+			return nil, appErr
 		}
 		shifts = append(shifts, shift)
-		shiftNameMap[id] = s.Name
+		shiftNameMap[shift.ID] = s.Name
+		shiftStores[shift.ID] = shift.StoreID
 	}
 	ctx.SetShifts(shifts)
 
+	// 相邻上一期排班的末尾分配作为固定历史，跨周期检查休息时间、连续天数和倒班规律
+	previous := h.loadHistory(ctx, orgID, req.StartDate, stabilityHistoryDays(historyDays(req.Options), req.Constraints))
+
 	// 设置需求
 	requirements := make([]*model.ShiftRequirement, 0, len(req.Requirements))
-	reqMap := make(map[string]*model.ShiftRequirement) // key: shiftID-date-position
+	reqMap := make(map[string]*model.ShiftRequirement) // key: shiftID-date-position-store
 	for _, reqItem := range req.Requirements {
-		shiftID, err := uuid.Parse(reqItem.ShiftID)
-		if err != nil {
-			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的班次ID格式: "+reqItem.ShiftID))
-			return
-		}
-		requirement := &model.ShiftRequirement{
-			BaseModel:    model.BaseModel{ID: uuid.New()},
-			ShiftID:      shiftID,
-			Date:         reqItem.Date,
-			Position:     reqItem.Position,
-			MinEmployees: reqItem.MinEmployees,
-			MaxEmployees: reqItem.MaxEmployees,
-			OptEmployees: reqItem.OptEmployees,
-			Skills:       reqItem.Skills,
-			Priority:     reqItem.Priority,
-		}
-		if requirement.MaxEmployees == 0 {
-			requirement.MaxEmployees = requirement.MinEmployees * 2
+		requirement, appErr := requirementFromInput(reqItem)
+		if appErr != nil {
+			return nil, appErr
 		}
-		if requirement.Priority == 0 {
-			requirement.Priority = 5
+		if requirement.StoreID == "" {
+			requirement.StoreID = shiftStores[requirement.ShiftID]
 		}
+		norm.Requirement(requirement)
 		requirements = append(requirements, requirement)
 		// 添加到映射
-		key := fmt.Sprintf("%s-%s-%s", shiftID.String(), reqItem.Date, reqItem.Position)
-		reqMap[key] = requirement
+		reqMap[requirementMapKey(requirement.ShiftID, requirement.Date, requirement.Position, requirement.StoreID)] = requirement
 	}
+	// 门店闭店或班次超出营业时间的需求不参与排班
+	constraintConfig := h.withHolidays(orgID, h.withScheduleCycle(orgID, h.withOpeningHours(orgID, h.withStoreBudgets(orgID, withStores(req.Stores, req.Constraints)))))
+	constraintConfig = h.withTeams(reqCtx, orgID, req.Teams, constraintConfig)
+	requirements, closedConflicts := filterOpeningHours(builtin.ConfigOpeningHours(constraintConfig), shifts, requirements)
 	ctx.Requirements = requirements
 
-	// 创建约束管理器并注册约束
-	cm := constraint.NewManager()
-	builtin.RegisterDefaultConstraints(cm, req.Constraints)
+	// 请假管控期内的需求提升优先级，优先保证覆盖
+	var blackouts []string
+	if h.store != nil {
+		blackouts = boostBlackoutRequirements(h.store.ListBlackoutPeriods(orgID), employees, requirements)
+	}
 
-	// 创建求解器
-	s := solver.NewGreedySolver(cm)
+	// 创建约束管理器并注册约束
+	cm, appErr := newConstraintManager(constraintConfig)
+	if appErr != nil {
+		return nil, appErr
+	}
 
 	// 设置超时上下文
 	timeout := 30 * time.Second // 默认30秒超时
+	if h.defaults.Timeout > 0 {
+		timeout = h.defaults.Timeout
+	}
 	if req.Options != nil && req.Options.Timeout > 0 {
 		timeout = time.Duration(req.Options.Timeout) * time.Second
 	}
-	solveCtx, cancel := context.WithTimeout(r.Context(), timeout)
+
+	// 创建求解器：optimization_level=2 时在贪心解基础上做局部搜索（或遗传算法）优化，
+	// optimization_level=3 时在贪心解基础上做模拟退火搜索
+	var s solver.Solver = solver.NewGreedySolver(cm)
+	if req.Options != nil && req.Options.OptimizationLevel >= 3 {
+		s = solver.NewAnnealingSolver(cm)
+	} else if req.Options != nil && req.Options.OptimizationLevel == 2 {
+		optConfig := solver.DefaultOptimizationConfig()
+		switch req.Options.Optimizer {
+		case "":
+		case optimizer.AlgorithmLocalSearch, optimizer.AlgorithmGenetic:
+			optConfig.Algorithm = req.Options.Optimizer
+		default:
+			return nil, errors.New(errors.CodeInvalidInput, fmt.Sprintf("不支持的优化算法: %s", req.Options.Optimizer))
+		}
+		// 优化阶段最多占用四分之一的超时时间；确定性模式不按时间截止，只受迭代次数和请求超时限制
+		optConfig.MaxTime = min(optConfig.MaxTime, timeout/4)
+		if req.Options.Seed != 0 {
+			optConfig.MaxTime = 0
+		}
+		s = solver.NewOptimizingSolver(cm, optConfig)
+	}
+	if req.Options != nil && req.Options.Seed != 0 {
+		if seeded, ok := s.(interface{ SetSeed(int64) }); ok {
+			seeded.SetSeed(req.Options.Seed)
+		}
+	}
+	if req.Options != nil && len(req.Options.ScoringProfile) > 0 {
+		profile, err := solver.NewScoringProfile(req.Options.ScoringProfile)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInvalidInput, "scoring_profile 无效: "+err.Error())
+		}
+		// 只有优化阶段以加权得分为目标函数，其余级别的贪心排序和退火搜索不使用权重
+		if req.Options.OptimizationLevel != 2 {
+			return nil, errors.New(errors.CodeInvalidInput, "scoring_profile 仅在 optimization_level=2 时生效")
+		}
+		if scored, ok := s.(interface{ SetScoringProfile(solver.ScoringProfile) }); ok {
+			scored.SetScoringProfile(profile)
+		}
+	}
+	if req.Options != nil && req.Options.Workers != 0 {
+		if req.Options.Workers < 0 {
+			return nil, errors.New(errors.CodeInvalidInput, "workers 不能为负数")
+		}
+		if parallel, ok := s.(interface{ SetWorkers(int) }); ok {
+			parallel.SetWorkers(min(req.Options.Workers, maxCandidateWorkers))
+		}
+	}
+	// 异步生成作业通过上下文传入进度回调
+	s.SetProgressHook(solver.ProgressFromContext(reqCtx))
+	solveCtx, cancel := context.WithTimeout(reqCtx, timeout)
 	defer cancel()
 
 	// 执行排班
 	result, err := s.Solve(solveCtx, ctx)
 	if err != nil {
 		if err == context.DeadlineExceeded {
-			respondError(w, errors.New(errors.CodeTimeout, "排班计算超时，请尝试减少员工数量或缩短排班周期"))
-			return
+			return nil, errors.New(errors.CodeTimeout, "排班计算超时，请尝试减少员工数量或缩短排班周期")
 		}
 		if err == context.Canceled {
-			respondError(w, errors.New(errors.CodeInternal, "排班请求已取消"))
-			return
+			return nil, errors.New(errors.CodeInternal, "排班请求已取消")
 		}
-		respondError(w, errors.Wrap(err, errors.CodeInternal, "排班失败"))
-		return
+		return nil, errors.Wrap(err, errors.CodeInternal, "排班失败")
 	}
 
 	// 构建响应
@@ -337,7 +704,7 @@ func (h *ScheduleHandler) Generate(w http.ResponseWriter, r *http.Request) {
 	assignments := make([]AssignmentOutput, len(result.Assignments))
 	for i, a := range result.Assignments {
 		// 计算综合评分
-		score, detail := calculateAssignmentScore(a, empMap[a.EmployeeID], reqMap, empHours[a.EmployeeID], avgHours, len(empDays[a.EmployeeID]))
+		score, detail := calculateAssignmentScore(a, empMap[a.EmployeeID], ctx.GetShift(a.ShiftID), reqMap, empHours[a.EmployeeID], avgHours, len(empDays[a.EmployeeID]))
 
 		assignments[i] = AssignmentOutput{
 			ID:           a.ID.String(),
@@ -349,30 +716,65 @@ func (h *ScheduleHandler) Generate(w http.ResponseWriter, r *http.Request) {
 			StartTime:    a.StartTime.Format("15:04"),
 			EndTime:      a.EndTime.Format("15:04"),
 			Position:     a.Position,
+			StoreID:      a.StoreID,
 			Hours:        a.WorkingHours(),
 			Score:        score,
 			ScoreDetail:  detail,
 		}
+		if a.StoreID != "" {
+			assignments[i].StoreName = model.StoreName(req.Stores, a.StoreID)
+		}
+		if emp, ok := externalMap[a.EmployeeID]; ok {
+			assignments[i].External = true
+			assignments[i].Agency = emp.External.Agency
+			assignments[i].Cost = roundCost(a.WorkingHours() * emp.HourlyRate)
+		}
+	}
+
+	// 待命安排单独输出
+	var onCall []AssignmentOutput
+	for _, a := range result.OnCall {
+		onCall = append(onCall, AssignmentOutput{
+			ID:           a.ID.String(),
+			EmployeeID:   a.EmployeeID.String(),
+			EmployeeName: empNameMap[a.EmployeeID],
+			ShiftID:      a.ShiftID.String(),
+			ShiftName:    shiftNameMap[a.ShiftID],
+			Date:         a.Date,
+			StartTime:    a.StartTime.Format("15:04"),
+			EndTime:      a.EndTime.Format("15:04"),
+			Position:     a.Position,
+			StoreID:      a.StoreID,
+		})
 	}
 
-	// 计算未满足的需求
-	unfilled := calculateUnfilledRequirements(requirements, result.Assignments, shiftNameMap)
+	// 计算未满足的需求（待命需求按待命安排计算）
+	filled := append(append([]*model.Assignment{}, result.Assignments...), result.OnCall...)
+	unfilled := calculateUnfilledRequirements(requirements, filled, shiftNameMap, req.Stores)
+	diagnoseUnfilled(cm, ctx, requirements, unfilled)
 	isPartial := len(unfilled) > 0 && len(result.Assignments) > 0
 
 	// 生成补员建议
 	suggestions := generateStaffingSuggestions(unfilled, req.Employees, result.ConstraintResult)
 
-	resp := GenerateResponse{
+	resp := &GenerateResponse{
 		Success:     result.Success,
 		Partial:     isPartial,
 		Message:     result.Message,
-		ScheduleID:  uuid.New().String(),
+		ScheduleID:  req.ScheduleID,
 		Assignments: assignments,
+		OnCall:      onCall,
 		Unfilled:    unfilled,
 		Statistics:  result.Statistics,
 		Duration:    result.Duration.String(),
 		Suggestions: suggestions,
 	}
+	if resp.ScheduleID == "" {
+		resp.ScheduleID = uuid.New().String()
+	}
+	if req.Options != nil {
+		resp.Seed = req.Options.Seed
+	}
 
 	// 如果是部分解，更新消息
 	if isPartial && !result.Success {
@@ -388,8 +790,414 @@ func (h *ScheduleHandler) Generate(w http.ResponseWriter, r *http.Request) {
 			SoftViolations: result.ConstraintResult.SoftViolations,
 		}
 	}
+	if pack != nil {
+		var hard []constraint.ViolationDetail
+		if result.ConstraintResult != nil {
+			hard = result.ConstraintResult.HardViolations
+		}
+		resp.Compliance = pack.Report(constraintConfig, hard)
+	}
 
-	respondJSON(w, http.StatusOK, resp)
+	resp.UnmappedLabels = norm.Unmapped()
+	if len(blackouts) > 0 {
+		resp.Blackouts = blackouts
+	}
+	if len(closedConflicts) > 0 {
+		resp.OpeningHours = closedConflicts
+	}
+	resp.CostSummary = builtin.ConfigCostCalculator(constraintConfig).Calculate(ctx.Employees, result.Assignments)
+	resp.ExternalLabor = summarizeExternal(result.Assignments, externalMap, externalCap)
+	resp.Stores = summarizeStores(req.Stores, requirements, result.Assignments, unfilled, empMap)
+	resp.ExpiringCertifications = expiringCertifications(employees, result.Assignments, req.StartDate, req.EndDate)
+	if previous != nil {
+		resp.PreviousScheduleID = previous.ID.String()
+		resp.HistoryAssignments = len(ctx.History)
+	}
+
+	// 异常检测需在保存前进行，避免当前排班进入历史基准
+	resp.Anomalies = h.detectAnomalies(orgID, req, result, requirements, unfilled, empNameMap)
+
+	if h.scheduleRepo != nil && (req.Options == nil || !req.Options.DryRun) {
+		if err := h.saveToRepository(reqCtx, orgID, req, resp, result); err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "保存排班失败")
+		}
+	}
+	if h.store != nil && (req.Options == nil || !req.Options.DryRun) {
+		h.store.RecordUnmapped(orgID, resp.UnmappedLabels)
+		h.saveToStore(orgID, req, resp, employees, shifts, requirements, result)
+	}
+	if req.Options == nil || !req.Options.DryRun {
+		h.notifyUnfilled(orgID, req, resp)
+	}
+
+	return resp, nil
+}
+
+// notifyUnfilled 排班存在未满足的需求时发送缺员通知，失败只记录日志
+func (h *ScheduleHandler) notifyUnfilled(orgID uuid.UUID, req *GenerateRequest, resp *GenerateResponse) {
+	if h.notifier == nil || len(resp.Unfilled) == 0 {
+		return
+	}
+	shortage := 0
+	for _, u := range resp.Unfilled {
+		shortage += u.Shortage
+	}
+	n := notify.New(notify.TypeUnfilledShift, orgID, "排班存在缺员",
+		fmt.Sprintf("%s 至 %s 的排班有 %d 个需求未满足，共缺 %d 人", req.StartDate, req.EndDate, len(resp.Unfilled), shortage),
+		map[string]interface{}{
+			"schedule_id": resp.ScheduleID,
+			"start_date":  req.StartDate,
+			"end_date":    req.EndDate,
+			"shortage":    shortage,
+			"unfilled":    resp.Unfilled,
+		})
+	if err := h.notifier.Notify(context.Background(), n); err != nil {
+		logger.Error().Err(err).Str("type", n.Type).Msg("发送缺员通知失败")
+	}
+}
+
+// shiftFromInput 将班次输入转换为班次模型
+func shiftFromInput(s ShiftInput) (*model.Shift, *errors.AppError) {
+	id, err := uuid.Parse(s.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的班次ID格式: "+s.ID)
+	}
+	return &model.Shift{
+		BaseModel: model.BaseModel{ID: id},
+		Name:      s.Name,
+		Code:      s.Code,
+		StartTime: s.StartTime,
+		EndTime:   s.EndTime,
+		Duration:  s.Duration,
+		ShiftType: s.Type,
+		IsActive:  true,
+		StoreID:   s.StoreID,
+	}, nil
+}
+
+// requirementFromInput 将需求输入转换为需求模型，补充最大人数和优先级的默认值
+func requirementFromInput(in RequirementInput) (*model.ShiftRequirement, *errors.AppError) {
+	shiftID, err := uuid.Parse(in.ShiftID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的班次ID格式: "+in.ShiftID)
+	}
+	id, err := uuid.Parse(in.ID)
+	if err != nil {
+		id = uuid.New()
+	}
+	requirement := &model.ShiftRequirement{
+		BaseModel:    model.BaseModel{ID: id},
+		ShiftID:      shiftID,
+		Date:         in.Date,
+		Position:     in.Position,
+		MinEmployees: in.MinEmployees,
+		MaxEmployees: in.MaxEmployees,
+		OptEmployees: in.OptEmployees,
+		Skills:       in.Skills,
+		SkillGroups:  in.SkillGroups,
+		SkillLevels:  in.SkillLevels,
+		Priority:     in.Priority,
+		StoreID:      in.StoreID,
+	}
+	if requirement.MaxEmployees == 0 {
+		requirement.MaxEmployees = requirement.MinEmployees * 2
+	}
+	if requirement.Priority == 0 {
+		requirement.Priority = 5
+	}
+	return requirement, nil
+}
+
+// normalizer 创建组织的标签归一化器，未启用存储时仅检测无人具备的标签
+func (h *ScheduleHandler) normalizer(orgID uuid.UUID) *alias.Normalizer {
+	if h.store == nil {
+		return alias.NewNormalizer(orgID, nil)
+	}
+	return alias.NewNormalizer(orgID, h.store.ListAliases(orgID))
+}
+
+// validClock 检查时间是否为 HH:MM 格式
+func validClock(s string) bool {
+	_, err := time.Parse("15:04", s)
+	return err == nil
+}
+
+// validLeave 检查请假日期区间格式有效且结束日期不早于开始日期
+func validLeave(l model.EmployeeLeave) bool {
+	if _, err := time.Parse("2006-01-02", l.StartDate); err != nil {
+		return false
+	}
+	if l.EndDate == "" {
+		return true
+	}
+	_, err := time.Parse("2006-01-02", l.EndDate)
+	return err == nil && l.EndDate >= l.StartDate
+}
+
+// orgConstraints 组织已启用的约束配置合并后的约束参数（请求未提供 constraints 时使用）
+// 配置数据库时从 ConstraintRepository 加载，否则从内存存储加载；组织未配置时返回 nil
+func (h *ScheduleHandler) orgConstraints(ctx context.Context, orgID uuid.UUID) (map[string]interface{}, *errors.AppError) {
+	var list []*model.OrgConstraint
+	switch {
+	case h.constraintRepo != nil:
+		records, err := h.constraintRepo.ListByOrg(ctx, orgID)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "加载组织约束配置失败")
+		}
+		list = fromConstraintConfigs(records)
+	case h.store != nil:
+		list = h.store.ListOrgConstraints(orgID, true)
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return constraints.OrgConfig(list), nil
+}
+
+// scenarioTemplate 按场景标识解析自定义场景模板（内置场景的默认模板不参与解析）
+// 配置数据库时从 ScenarioTemplateRepository 查找，否则从内存存储查找；未找到时返回 nil，scenario 仅作为标记
+func (h *ScheduleHandler) scenarioTemplate(ctx context.Context, scenario string) (*model.ScenarioTemplate, *errors.AppError) {
+	if scenario == "" {
+		return nil, nil
+	}
+	switch {
+	case h.scenarioRepo != nil:
+		record, err := h.scenarioRepo.GetCustom(ctx, scenario)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "加载场景模板失败")
+		}
+		if record != nil {
+			return fromScenarioTemplateRecord(record), nil
+		}
+	case h.store != nil:
+		if t, err := h.store.GetScenarioTemplateByScenario(scenario); err == nil {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// mergeConfig 合并两份约束配置，override 中的键覆盖 base，返回新的配置
+func mergeConfig(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// withDefaultOptions 返回以服务端默认值补全优化级别和并行协程数的生成选项副本，不修改请求中的选项
+func (h *ScheduleHandler) withDefaultOptions(opts *GenerateOptions) *GenerateOptions {
+	if h.defaults.OptimizationLevel == 0 && h.defaults.Workers == 0 {
+		return opts
+	}
+	merged := GenerateOptions{}
+	if opts != nil {
+		merged = *opts
+	}
+	if merged.OptimizationLevel == 0 {
+		merged.OptimizationLevel = h.defaults.OptimizationLevel
+	}
+	if merged.Workers == 0 {
+		merged.Workers = h.defaults.Workers
+	}
+	return &merged
+}
+
+// withStoreBudgets 请求未配置门店工时预算时，补充存储中该组织的预算
+// 返回新的配置，不修改请求中的配置
+func (h *ScheduleHandler) withStoreBudgets(orgID uuid.UUID, config map[string]interface{}) map[string]interface{} {
+	if h.store == nil {
+		return config
+	}
+	if _, ok := config["store_hours_budgets"]; ok {
+		return config
+	}
+	stored := h.store.ListStoreBudgets(orgID)
+	if len(stored) == 0 {
+		return config
+	}
+	budgets := make([]model.StoreHoursBudget, len(stored))
+	for i, b := range stored {
+		budgets[i] = *b
+	}
+	merged := make(map[string]interface{}, len(config)+1)
+	for k, v := range config {
+		merged[k] = v
+	}
+	merged["store_hours_budgets"] = budgets
+	return merged
+}
+
+// withScheduleCycle 请求未配置排班周期（cycle_weeks）时，补充组织的排班周期
+// 返回新的配置，不修改请求中的配置
+func (h *ScheduleHandler) withScheduleCycle(orgID uuid.UUID, config map[string]interface{}) map[string]interface{} {
+	if h.store == nil {
+		return config
+	}
+	org, err := h.store.GetOrganization(orgID)
+	if err != nil {
+		return config
+	}
+	return builtin.WithCycle(config, org.ScheduleCycle)
+}
+
+// saveToStore 将生成结果保存到内存存储
+func (h *ScheduleHandler) saveToStore(orgID uuid.UUID, req *GenerateRequest, resp *GenerateResponse, employees []*model.Employee, shifts []*model.Shift, requirements []*model.ShiftRequirement, result *solver.Result) {
+	for _, emp := range employees {
+		emp.OrgID = orgID
+		// 请求中不含工号等档案字段，保留员工档案中的取值
+		if existing, err := h.store.GetEmployee(emp.ID); err == nil {
+			keepEmployeeRecord(emp, existing)
+		}
+		h.store.PutEmployee(emp)
+	}
+	for _, shift := range shifts {
+		shift.OrgID = orgID
+		// 请求中不含休息时间、颜色等定义字段，保留班次定义中的取值
+		if existing, err := h.store.GetShift(shift.ID); err == nil {
+			keepShiftRecord(shift, existing)
+		}
+		h.store.PutShift(shift)
+	}
+	for _, requirement := range requirements {
+		requirement.OrgID = orgID
+	}
+	h.store.ReplaceRequirements(orgID, req.StartDate, req.EndDate, requirements)
+
+	scheduleID, _ := uuid.Parse(resp.ScheduleID)
+	reason := model.ScheduleVersionGenerate
+	schedule := &model.Schedule{
+		BaseModel:   model.NewBaseModel(),
+		OrgID:       orgID,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Status:      "draft",
+		Version:     1,
+		Assignments: make([]model.Assignment, 0, len(result.Assignments)),
+
+		ConstraintConfig: model.JSONMap(req.Constraints),
+		Statistics: &model.ScheduleStats{
+			TotalAssignments: result.Statistics.TotalAssignments,
+			TotalHours:       result.Statistics.TotalHours,
+			UnfilledShifts:   len(resp.Unfilled),
+			FillRate:         result.Statistics.FillRate,
+		},
+	}
+	schedule.ID = scheduleID
+	// 重新生成：保留排班的创建时间和名称，版本加 1
+	if existing, err := h.store.GetSchedule(scheduleID); err == nil {
+		schedule.CreatedAt, schedule.Name = existing.CreatedAt, existing.Name
+		schedule.Version = existing.Version + 1
+		reason = model.ScheduleVersionRegenerate
+	}
+	for _, a := range result.Assignments {
+		a.ScheduleID = scheduleID
+		schedule.Assignments = append(schedule.Assignments, *a)
+	}
+	if result.ConstraintResult != nil {
+		schedule.Statistics.ConstraintScore = result.ConstraintResult.Score
+	}
+	h.store.PutSchedule(schedule)
+	h.store.AddScheduleVersion(schedule, reason, "")
+}
+
+// checkRegenerate 检查重新生成的目标排班：须为同一组织的草稿（需启用排班存储）
+func (h *ScheduleHandler) checkRegenerate(orgID uuid.UUID, req *GenerateRequest) *errors.AppError {
+	if req.ScheduleID == "" {
+		return nil
+	}
+	id, err := uuid.Parse(req.ScheduleID)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式")
+	}
+	if h.store == nil {
+		return errors.New(errors.CodeInvalidInput, "重新生成排班需要启用排班存储")
+	}
+	schedule, err := h.store.GetSchedule(id)
+	if err != nil || schedule.OrgID != orgID {
+		return errors.New(errors.CodeNotFound, "排班不存在")
+	}
+	if schedule.Status != "draft" {
+		return errors.New(errors.CodeScheduleConflict, "仅草稿状态的排班可重新生成")
+	}
+	return nil
+}
+
+// anomalyHistoryLimit 异常检测使用的历史排班数量
+const anomalyHistoryLimit = 4
+
+// detectAnomalies 将生成结果与组织历史排班对比，返回异常并记录指标
+// 多门店排班的历史覆盖率按排班期间的需求和分配逐门店重新计算
+func (h *ScheduleHandler) detectAnomalies(
+	orgID uuid.UUID,
+	req *GenerateRequest,
+	result *solver.Result,
+	requirements []*model.ShiftRequirement,
+	unfilled []UnfilledRequirement,
+	empNameMap map[uuid.UUID]string,
+) []stats.Anomaly {
+	current := &stats.ScheduleSample{
+		StartDate:      req.StartDate,
+		FillRate:       result.Statistics.FillRate,
+		Assignments:    make([]*stats.AssignmentInfo, 0, len(result.Assignments)),
+		StoreFillRates: storeFillRates(requirements, unfilled),
+	}
+	for _, a := range result.Assignments {
+		current.Assignments = append(current.Assignments, toAssignmentInfo(a, empNameMap))
+	}
+
+	var history []*stats.ScheduleSample
+	if h.store != nil {
+		// ListSchedules 按创建时间倒序返回
+		for _, schedule := range h.store.ListSchedules(orgID) {
+			if schedule.StartDate >= req.StartDate {
+				continue
+			}
+			sample := &stats.ScheduleSample{
+				ScheduleID:  schedule.ID.String(),
+				StartDate:   schedule.StartDate,
+				Assignments: make([]*stats.AssignmentInfo, 0, len(schedule.Assignments)),
+			}
+			if schedule.Statistics != nil {
+				sample.FillRate = schedule.Statistics.FillRate
+			}
+			assignments := make([]*model.Assignment, len(schedule.Assignments))
+			for i := range schedule.Assignments {
+				assignments[i] = &schedule.Assignments[i]
+				sample.Assignments = append(sample.Assignments, toAssignmentInfo(assignments[i], empNameMap))
+			}
+			if current.StoreFillRates != nil {
+				past := h.store.ListRequirements(orgID, schedule.StartDate, schedule.EndDate)
+				sample.StoreFillRates = storeFillRates(past, calculateUnfilledRequirements(past, assignments, nil, nil))
+			}
+			history = append(history, sample)
+			if len(history) >= anomalyHistoryLimit {
+				break
+			}
+		}
+	}
+
+	anomalies := stats.NewAnomalyDetector().Detect(current, history)
+	for _, a := range anomalies {
+		metrics.RecordScheduleAnomaly(orgID.String(), a.Type, a.Severity)
+	}
+	return anomalies
+}
+
+// toAssignmentInfo 转换为统计分析使用的分配信息
+func toAssignmentInfo(a *model.Assignment, empNameMap map[uuid.UUID]string) *stats.AssignmentInfo {
+	return &stats.AssignmentInfo{
+		ShiftID:      a.ShiftID.String(),
+		EmployeeID:   a.EmployeeID.String(),
+		EmployeeName: empNameMap[a.EmployeeID],
+		Date:         a.Date,
+		StartTime:    a.StartTime,
+		EndTime:      a.EndTime,
+	}
 }
 
 // validateGenerateRequest 验证请求
@@ -411,8 +1219,8 @@ func validateGenerateRequest(req *GenerateRequest) *errors.AppError {
 	if len(req.Shifts) == 0 {
 		ve.Add("shifts", "班次列表不能为空")
 	}
-	if len(req.Requirements) == 0 {
-		ve.Add("requirements", "需求列表不能为空")
+	if len(req.Requirements) == 0 && strings.TrimSpace(req.RequirementSpec) == "" {
+		ve.Add("requirements", "需求列表和需求简写不能同时为空")
 	}
 
 	// 验证日期格式
@@ -456,6 +1264,8 @@ type ValidateResponse struct {
 	IsValid    bool                         `json:"is_valid"`
 	Score      float64                      `json:"score"`
 	Violations []constraint.ViolationDetail `json:"violations"`
+
+	UnmappedLabels []model.UnmappedLabel `json:"unmapped_labels,omitempty"` // 未映射的技能/岗位标签
 }
 
 // Validate 验证排班
@@ -471,31 +1281,52 @@ func (h *ScheduleHandler) Validate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	resp, appErr := h.validate(&req)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// validate 执行排班验证，供各版本 API 共用
+func (h *ScheduleHandler) validate(req *ValidateRequest) (*ValidateResponse, *errors.AppError) {
 	// 验证组织ID
 	if req.OrgID == "" {
-		respondError(w, errors.New(errors.CodeInvalidInput, "组织ID不能为空"))
-		return
+		return nil, errors.New(errors.CodeInvalidInput, "组织ID不能为空")
 	}
 
 	// 构建排班上下文
 	orgID, err := uuid.Parse(req.OrgID)
 	if err != nil {
-		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
-		return
+		return nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式")
 	}
 	ctx := constraint.NewContext(orgID, "", "")
+	norm := h.normalizer(orgID)
 
 	// 设置员工
 	employees := make([]*model.Employee, len(req.Employees))
+	verified := h.verifiedCertifications(orgID)
 	for i, e := range req.Employees {
 		id, _ := uuid.Parse(e.ID)
 		employees[i] = &model.Employee{
-			BaseModel: model.BaseModel{ID: id},
-			Name:      e.Name,
-			Position:  e.Position,
-			Skills:    e.Skills,
-			Status:    "active",
+			BaseModel:      model.BaseModel{ID: id},
+			Name:           e.Name,
+			Position:       e.Position,
+			Skills:         e.Skills,
+			Certifications: e.Certifications,
+			SkillLevels:    e.SkillLevels,
+			Status:         "active",
+			StoreID:        e.StoreID,
+
+			VerifiedCertifications: e.VerifiedCertifications,
+			CertificationRecords:   e.CertificationRecords,
+		}
+		if len(employees[i].VerifiedCertifications) == 0 {
+			employees[i].VerifiedCertifications = verified[id]
 		}
+		norm.Employee(employees[i])
 	}
 	ctx.SetEmployees(employees)
 
@@ -514,14 +1345,16 @@ func (h *ScheduleHandler) Validate(w http.ResponseWriter, r *http.Request) {
 			Date:       a.Date,
 			StartTime:  startTime,
 			EndTime:    endTime,
-			Position:   a.Position,
+			Position:   norm.Label(model.AliasKindPosition, a.Position, alias.SourceAssignment),
 		}
 	}
 	ctx.SetAssignments(assignments)
 
 	// 创建约束管理器
-	cm := constraint.NewManager()
-	builtin.RegisterDefaultConstraints(cm, req.Constraints)
+	cm, appErr := newConstraintManager(h.withHolidays(orgID, h.withScheduleCycle(orgID, h.withOpeningHours(orgID, h.withStoreBudgets(orgID, req.Constraints)))))
+	if appErr != nil {
+		return nil, appErr
+	}
 
 	// 评估约束
 	result := cm.Evaluate(ctx)
@@ -530,13 +1363,90 @@ func (h *ScheduleHandler) Validate(w http.ResponseWriter, r *http.Request) {
 	violations = append(violations, result.HardViolations...)
 	violations = append(violations, result.SoftViolations...)
 
-	resp := ValidateResponse{
-		IsValid:    result.IsValid,
-		Score:      result.Score,
-		Violations: violations,
+	resp := &ValidateResponse{
+		IsValid:        result.IsValid,
+		Score:          result.Score,
+		Violations:     violations,
+		UnmappedLabels: norm.Unmapped(),
+	}
+	if h.store != nil {
+		h.store.RecordUnmapped(orgID, resp.UnmappedLabels)
 	}
 
-	respondJSON(w, http.StatusOK, resp)
+	return resp, nil
+}
+
+// HardViolations 复核存储中排班当前分配的硬约束（发布前检查）
+// 使用生成时的约束配置，没有时使用组织最新的约束配置；员工、班次取自存储
+func (h *ScheduleHandler) HardViolations(schedule *model.Schedule) []constraint.ViolationDetail {
+	if h.store == nil {
+		return nil
+	}
+	ctx, cm := h.storedContext(schedule)
+	return cm.Evaluate(ctx).HardViolations
+}
+
+// storedContext 按存储中的排班构建约束上下文和约束管理器
+// 员工、班次和需求取自存储，extra 为排班之外需要加入上下文的员工（如换班目标）
+func (h *ScheduleHandler) storedContext(schedule *model.Schedule, extra ...uuid.UUID) (*constraint.Context, *constraint.Manager) {
+	orgID := schedule.OrgID
+	config := map[string]interface{}(schedule.ConstraintConfig)
+	if config == nil {
+		if cfg, err := h.store.GetConstraintConfig(orgID, 0); err == nil {
+			config = cfg.Config
+		}
+	}
+
+	ctx := constraint.NewContext(orgID, schedule.StartDate, schedule.EndDate)
+	ctx.SetShifts(h.store.ListShifts(orgID))
+	ctx.Requirements = h.store.ListRequirements(orgID, schedule.StartDate, schedule.EndDate)
+	employees := make([]*model.Employee, 0)
+	listed := make(map[uuid.UUID]bool)
+	addEmployee := func(id uuid.UUID) {
+		if listed[id] {
+			return
+		}
+		listed[id] = true
+		emp := &model.Employee{BaseModel: model.BaseModel{ID: id}, Name: id.String(), Status: "active"}
+		if stored, err := h.store.GetEmployee(id); err == nil {
+			emp = stored
+		}
+		employees = append(employees, emp)
+	}
+	assignments := make([]*model.Assignment, 0, len(schedule.Assignments))
+	for i := range schedule.Assignments {
+		a := schedule.Assignments[i]
+		if a.Status == "cancelled" {
+			continue
+		}
+		assignments = append(assignments, &a)
+		addEmployee(a.EmployeeID)
+	}
+	for _, id := range extra {
+		addEmployee(id)
+	}
+	ctx.SetEmployees(employees)
+	ctx.SetAssignments(assignments)
+
+	config = h.withHolidays(orgID, h.withScheduleCycle(orgID, h.withOpeningHours(orgID, h.withStoreBudgets(orgID, config))))
+	cm, appErr := newConstraintManager(config)
+	if appErr != nil {
+		// 插件约束的配置在生成时已校验，之后失效（如插件参数规则变化）时只复核默认约束
+		cm = constraint.NewManager()
+		builtin.RegisterDefaultConstraints(cm, config)
+	}
+	return ctx, cm
+}
+
+// newConstraintManager 创建约束管理器：注册默认约束，再按约束配置实例化已注册的插件约束
+// （配置中的键为插件名称时，同类型的插件约束替换默认约束）
+func newConstraintManager(config map[string]interface{}) (*constraint.Manager, *errors.AppError) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, config)
+	if err := cm.RegisterConfigured(config); err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidInput, err.Error())
+	}
+	return cm, nil
 }
 
 // respondJSON 返回JSON响应
@@ -563,18 +1473,17 @@ func calculateUnfilledRequirements(
 	requirements []*model.ShiftRequirement,
 	assignments []*model.Assignment,
 	shiftNameMap map[uuid.UUID]string,
+	stores []model.Store,
 ) []UnfilledRequirement {
 	// 统计每个需求的分配数量
-	assignmentCount := make(map[string]int) // key: shiftID-date-position
+	assignmentCount := make(map[string]int) // key: shiftID-date-position-store
 	for _, a := range assignments {
-		key := fmt.Sprintf("%s-%s-%s", a.ShiftID.String(), a.Date, a.Position)
-		assignmentCount[key]++
+		assignmentCount[requirementMapKey(a.ShiftID, a.Date, a.Position, a.StoreID)]++
 	}
 
 	var unfilled []UnfilledRequirement
 	for _, req := range requirements {
-		key := fmt.Sprintf("%s-%s-%s", req.ShiftID.String(), req.Date, req.Position)
-		assigned := assignmentCount[key]
+		assigned := assignmentCount[requirementMapKey(req.ShiftID, req.Date, req.Position, req.StoreID)]
 
 		if assigned < req.MinEmployees {
 			shortage := req.MinEmployees - assigned
@@ -585,7 +1494,7 @@ func calculateUnfilledRequirements(
 
 			shiftName := shiftNameMap[req.ShiftID]
 
-			unfilled = append(unfilled, UnfilledRequirement{
+			item := UnfilledRequirement{
 				ShiftID:   req.ShiftID.String(),
 				ShiftName: shiftName,
 				Date:      req.Date,
@@ -594,17 +1503,49 @@ func calculateUnfilledRequirements(
 				Assigned:  assigned,
 				Shortage:  shortage,
 				Reason:    reason,
-			})
+			}
+			if req.StoreID != "" {
+				item.StoreID = req.StoreID
+				item.StoreName = model.StoreName(stores, req.StoreID)
+			}
+			unfilled = append(unfilled, item)
 		}
 	}
 
 	return unfilled
 }
 
+// diagnoseUnfilled 在最终排班结果上逐个诊断未满足需求的候选员工被排除的原因
+func diagnoseUnfilled(cm *constraint.Manager, ctx *constraint.Context, requirements []*model.ShiftRequirement, unfilled []UnfilledRequirement) {
+	if len(unfilled) == 0 {
+		return
+	}
+	byKey := make(map[string]*model.ShiftRequirement, len(requirements))
+	for _, req := range requirements {
+		byKey[requirementMapKey(req.ShiftID, req.Date, req.Position, req.StoreID)] = req
+	}
+	for i := range unfilled {
+		u := &unfilled[i]
+		shiftID, err := uuid.Parse(u.ShiftID)
+		if err != nil {
+			continue
+		}
+		if req := byKey[requirementMapKey(shiftID, u.Date, u.Position, u.StoreID)]; req != nil {
+			u.Diagnostics = solver.Diagnose(cm, ctx, req)
+		}
+	}
+}
+
+// requirementMapKey 需求的唯一键（班次、日期、岗位、门店），分配按同样的键归属到需求
+func requirementMapKey(shiftID uuid.UUID, date, position, storeID string) string {
+	return fmt.Sprintf("%s-%s-%s-%s", shiftID.String(), date, position, storeID)
+}
+
 // calculateAssignmentScore 计算单个排班分配的综合评分
 func calculateAssignmentScore(
 	assignment *model.Assignment,
 	employee *model.Employee,
+	shift *model.Shift,
 	reqMap map[string]*model.ShiftRequirement,
 	empTotalHours float64,
 	avgHours float64,
@@ -624,19 +1565,13 @@ func calculateAssignmentScore(
 	}
 
 	// 1. 技能匹配评分 (30%)
-	key := fmt.Sprintf("%s-%s-%s", assignment.ShiftID.String(), assignment.Date, assignment.Position)
-	if req, ok := reqMap[key]; ok && len(req.Skills) > 0 {
-		matchedSkills := 0
-		for _, reqSkill := range req.Skills {
-			for _, empSkill := range employee.Skills {
-				if empSkill == reqSkill {
-					matchedSkills++
-					break
-				}
-			}
-		}
-		if len(req.Skills) > 0 {
-			detail.SkillMatch = float64(matchedSkills) / float64(len(req.Skills)) * 100
+	key := requirementMapKey(assignment.ShiftID, assignment.Date, assignment.Position, assignment.StoreID)
+	if req, ok := reqMap[key]; ok && len(req.Skills)+len(req.SkillGroups)+len(req.SkillLevels) > 0 {
+		// 每项必需技能、每个技能组和每项技能等级要求各计一项
+		totalSkills := len(req.Skills) + len(req.SkillGroups) + len(req.SkillLevels)
+		matchedSkills := totalSkills - len(employee.MissingSkills(req.Skills, req.SkillGroups)) - len(employee.MissingSkillLevels(req.SkillLevels))
+		if totalSkills > 0 {
+			detail.SkillMatch = float64(matchedSkills) / float64(totalSkills) * 100
 			if detail.SkillMatch >= 100 {
 				detail.Reasons = append(detail.Reasons, "技能完全匹配")
 			} else if detail.SkillMatch >= 50 {
@@ -665,10 +1600,13 @@ func calculateAssignmentScore(
 
 	// 3. 员工偏好评分 (20%)
 	if employee.Preferences != nil {
+		shiftCode, shiftType := "", ""
+		if shift != nil {
+			shiftCode, shiftType = shift.Code, shift.ShiftType
+		}
 		// 检查避免班次
-		shiftCode := "" // 需要从 shiftNameMap 获取，简化处理
 		for _, avoid := range employee.Preferences.AvoidShifts {
-			if avoid == shiftCode || avoid == assignment.ShiftID.String() {
+			if avoid == shiftCode || (avoid != "" && avoid == shiftType) || avoid == assignment.ShiftID.String() {
 				detail.Preference = 30
 				detail.Reasons = append(detail.Reasons, "员工避免此班次")
 				break
@@ -676,12 +1614,22 @@ func calculateAssignmentScore(
 		}
 		// 检查偏好班次
 		for _, prefer := range employee.Preferences.PreferredShifts {
-			if prefer == shiftCode || prefer == assignment.ShiftID.String() {
+			if prefer == shiftCode || (prefer != "" && prefer == shiftType) || prefer == assignment.ShiftID.String() {
 				detail.Preference = 100
 				detail.Reasons = append(detail.Reasons, "符合员工偏好")
 				break
 			}
 		}
+		// 检查班次志愿排名（按名次比例评分）
+		if employee.Preferences.HasShiftRankings() && detail.Preference > 30 {
+			if rank := employee.Preferences.ShiftRank(shiftCode, shiftType); rank > 0 {
+				detail.Preference = 50 + 50*employee.Preferences.ShiftRankScore(shiftCode, shiftType)
+				detail.Reasons = append(detail.Reasons, fmt.Sprintf("第%d志愿班次", rank))
+			} else {
+				detail.Preference = 50
+				detail.Reasons = append(detail.Reasons, "非志愿班次")
+			}
+		}
 	}
 
 	// 4. 工时均衡评分 (15%)
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+)
+
+const (
+	// defaultHistoryDays 默认加载上一期排班末尾的天数
+	defaultHistoryDays = 7
+	// maxHistoryDays 最多加载上一期排班末尾的天数
+	maxHistoryDays = 31
+)
+
+// historyDays 返回生成选项中的历史天数，0 表示默认值，负数表示不加载
+func historyDays(opts *GenerateOptions) int {
+	if opts == nil || opts.HistoryDays == 0 {
+		return defaultHistoryDays
+	}
+	if opts.HistoryDays > maxHistoryDays {
+		return maxHistoryDays
+	}
+	return opts.HistoryDays
+}
+
+// stabilityHistoryDays 启用周间稳定性约束时，历史天数至少覆盖比较的周数（不超过上限）；不加载历史时保持不变
+func stabilityHistoryDays(days int, config map[string]interface{}) int {
+	weeks := builtin.ConfigStabilityWeeks(config)
+	if days <= 0 || weeks == 0 || days >= weeks*7 {
+		return days
+	}
+	return min(weeks*7, maxHistoryDays)
+}
+
+// loadHistory 将相邻上一期排班末尾 days 天的分配作为固定历史加入排班上下文
+// 上一期排班为结束日期早于本期开始日期、且在 days 天内结束的排班中结束最晚的一个（已发布优先，其次最近更新），
+// 只加载本期员工的分配；返回使用的排班，没有相邻排班时返回 nil
+func (h *ScheduleHandler) loadHistory(ctx *constraint.Context, orgID uuid.UUID, startDate string, days int) *model.Schedule {
+	if h.store == nil || days <= 0 {
+		return nil
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil
+	}
+	from := start.AddDate(0, 0, -days).Format("2006-01-02")
+
+	var previous *model.Schedule
+	for _, s := range h.store.ListSchedules(orgID) {
+		if s.EndDate >= startDate || s.EndDate < from || (s.Status != "draft" && s.Status != "published") {
+			continue
+		}
+		if previous == nil || newerAdjacent(s, previous) {
+			previous = s
+		}
+	}
+	if previous == nil {
+		return nil
+	}
+
+	history := make([]*model.Assignment, 0)
+	shifts := make([]*model.Shift, 0)
+	seenShift := make(map[uuid.UUID]bool)
+	for i := range previous.Assignments {
+		a := &previous.Assignments[i]
+		if a.Date < from || a.Date >= startDate || ctx.GetEmployee(a.EmployeeID) == nil || a.Status == "cancelled" {
+			continue
+		}
+		history = append(history, a)
+		if ctx.GetShift(a.ShiftID) != nil || seenShift[a.ShiftID] {
+			continue
+		}
+		seenShift[a.ShiftID] = true
+		if shift, err := h.store.GetShift(a.ShiftID); err == nil {
+			shifts = append(shifts, shift)
+		}
+	}
+	ctx.SetHistory(history, shifts)
+	return previous
+}
+
+// newerAdjacent 判断排班 a 是否比 b 更适合作为相邻上一期排班
+func newerAdjacent(a, b *model.Schedule) bool {
+	if a.EndDate != b.EndDate {
+		return a.EndDate > b.EndDate
+	}
+	if (a.Status == "published") != (b.Status == "published") {
+		return a.Status == "published"
+	}
+	return a.UpdatedAt.After(b.UpdatedAt)
+}
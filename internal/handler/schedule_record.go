@@ -0,0 +1,379 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// saveToRepository 将生成结果（排班及其分配）保存到数据库
+// 分配保存失败时删除已保存的排班，不留下不完整的记录
+func (h *ScheduleHandler) saveToRepository(ctx context.Context, orgID uuid.UUID, req *GenerateRequest, resp *GenerateResponse, result *solver.Result) error {
+	scheduleID, err := uuid.Parse(resp.ScheduleID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	schedule := &repository.Schedule{
+		ID:          scheduleID,
+		OrgID:       orgID,
+		Scenario:    req.Scenario,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Status:      "draft",
+		TotalSlots:  result.Statistics.TotalRequirements,
+		FilledSlots: result.Statistics.FilledRequirements,
+		FillRate:    result.Statistics.FillRate,
+		Feasible:    true,
+		GeneratedAt: now,
+		GeneratedBy: "system",
+		Metadata: map[string]any{
+			"total_assignments": result.Statistics.TotalAssignments,
+			"total_hours":       result.Statistics.TotalHours,
+			"unfilled":          len(resp.Unfilled),
+			"duration":          resp.Duration,
+		},
+	}
+	if result.ConstraintResult != nil {
+		schedule.Feasible = result.ConstraintResult.IsValid
+		schedule.SoftScore = result.ConstraintResult.Score
+	}
+	// 重新生成时替换数据库中的排班记录（各版本保存在排班存储中）
+	if req.ScheduleID != "" {
+		if err := h.scheduleRepo.Delete(ctx, scheduleID); err != nil {
+			return err
+		}
+	}
+	if err := h.scheduleRepo.Create(ctx, schedule); err != nil {
+		return err
+	}
+
+	for _, a := range resp.Assignments {
+		assignment := &repository.ScheduleAssignment{
+			ID:           uuid.New(),
+			ScheduleID:   scheduleID,
+			EmployeeName: a.EmployeeName,
+			ShiftName:    a.ShiftName,
+			Date:         a.Date,
+			StartTime:    a.StartTime,
+			EndTime:      a.EndTime,
+			Position:     a.Position,
+			Status:       "assigned",
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if id, err := uuid.Parse(a.ID); err == nil {
+			assignment.ID = id
+		}
+		assignment.EmployeeID, _ = uuid.Parse(a.EmployeeID)
+		assignment.ShiftID, _ = uuid.Parse(a.ShiftID)
+		if err := h.scheduleRepo.CreateAssignment(ctx, assignment); err != nil {
+			h.scheduleRepo.Delete(context.WithoutCancel(ctx), scheduleID)
+			return err
+		}
+	}
+	return nil
+}
+
+// ScheduleRecordHandler 已保存排班的查询和删除处理器
+// 配置数据库时读写 ScheduleRepository，否则使用内存存储
+type ScheduleRecordHandler struct {
+	repo  repository.ScheduleRepositoryInterface
+	store *memstore.Store
+	draft *DraftHandler // 内存存储中排班的获取（带 ETag）和分配修改
+}
+
+// NewScheduleRecordHandler 创建已保存排班处理器，repo 为空时使用内存存储
+func NewScheduleRecordHandler(repo *repository.ScheduleRepository, store *memstore.Store, draft *DraftHandler) *ScheduleRecordHandler {
+	h := &ScheduleRecordHandler{
+		store: store,
+		draft: draft,
+	}
+	if repo != nil {
+		h.repo = repo
+	}
+	return h
+}
+
+// ScheduleListResponse 排班列表响应
+type ScheduleListResponse struct {
+	Schedules []*repository.Schedule `json:"schedules"`
+	Total     int                    `json:"total"`
+	Offset    int                    `json:"offset"`
+	Limit     int                    `json:"limit"`
+}
+
+// List 分页列出已保存的排班（按创建时间倒序）
+// 路由: GET /api/v1/schedules?org_id=&status=&start_date=&end_date=&offset=&limit=
+func (h *ScheduleRecordHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !h.ready(w) {
+		return
+	}
+	query := r.URL.Query()
+	filter := repository.DefaultListFilter()
+	orgID, ok := listOrgID(w, r)
+	if !ok {
+		return
+	}
+	if orgID != uuid.Nil {
+		filter = filter.WithOrgID(orgID)
+	}
+	filter = filter.WithStatus(query.Get("status")).WithDateRange(query.Get("start_date"), query.Get("end_date"))
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		filter = filter.WithOffset(offset)
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= 100 {
+		filter = filter.WithLimit(limit)
+	}
+
+	var schedules []*repository.Schedule
+	var total int
+	if h.repo != nil {
+		var err error
+		schedules, total, err = h.repo.List(r.Context(), filter)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询排班失败"))
+			return
+		}
+	} else {
+		schedules, total = h.listStore(filter)
+	}
+	if schedules == nil {
+		schedules = make([]*repository.Schedule, 0)
+	}
+	respondJSON(w, http.StatusOK, ScheduleListResponse{Schedules: schedules, Total: total, Offset: filter.Offset, Limit: filter.Limit})
+}
+
+// Schedule 获取或删除已保存的排班
+// 路由: GET|DELETE /api/v1/schedules/{id}
+func (h *ScheduleRecordHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if h.repo == nil {
+			h.draft.GetSchedule(w, r)
+			return
+		}
+		id, ok := h.scheduleID(w, r)
+		if !ok {
+			return
+		}
+		schedule, ok := h.getRecord(w, r, id)
+		if !ok {
+			return
+		}
+		respondJSON(w, http.StatusOK, schedule)
+	case http.MethodDelete:
+		id, ok := h.scheduleID(w, r)
+		if !ok {
+			return
+		}
+		if h.repo != nil {
+			if _, ok := h.getRecord(w, r, id); !ok {
+				return
+			}
+			if err := h.repo.Delete(r.Context(), id); err != nil {
+				respondError(w, errors.Wrap(err, errors.CodeInternal, "删除排班失败"))
+				return
+			}
+		}
+		// 内存存储中的排班一并删除（配置数据库时内存中可能没有）
+		if h.store != nil {
+			if err := h.store.DeleteSchedule(id); err != nil && h.repo == nil {
+				respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+				return
+			}
+		}
+		respondJSON(w, http.StatusOK, map[string]string{"id": id.String(), "status": "deleted"})
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET和DELETE方法"))
+	}
+}
+
+// Assignments 获取、批量修改或新增已保存排班的分配
+// 路由: GET|PATCH|POST /api/v1/schedules/{id}/assignments
+// 修改和新增分配只作用于内存存储中的排班草稿
+func (h *ScheduleRecordHandler) Assignments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		id, ok := h.scheduleID(w, r)
+		if !ok {
+			return
+		}
+		if h.repo == nil {
+			schedule, err := h.store.GetSchedule(id)
+			if err != nil {
+				respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+				return
+			}
+			respondJSON(w, http.StatusOK, h.recordAssignments(schedule))
+			return
+		}
+		if _, ok := h.getRecord(w, r, id); !ok {
+			return
+		}
+		assignments, err := h.repo.GetAssignments(r.Context(), id)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询排班分配失败"))
+			return
+		}
+		if assignments == nil {
+			assignments = make([]*repository.ScheduleAssignment, 0)
+		}
+		respondJSON(w, http.StatusOK, assignments)
+	case http.MethodPatch:
+		h.draft.EditAssignments(w, r)
+	case http.MethodPost:
+		h.draft.AddAssignment(w, r)
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET、PATCH和POST方法"))
+	}
+}
+
+// getRecord 从数据库读取排班，不存在时返回 404
+func (h *ScheduleRecordHandler) getRecord(w http.ResponseWriter, r *http.Request, id uuid.UUID) (*repository.Schedule, bool) {
+	schedule, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "查询排班失败"))
+		return nil, false
+	}
+	if schedule == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return nil, false
+	}
+	return schedule, true
+}
+
+// scheduleID 检查存储并解析排班ID，排班存在时检查调用方可访问其所属组织
+func (h *ScheduleRecordHandler) scheduleID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if !h.ready(w) {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式"))
+		return uuid.Nil, false
+	}
+	if h.repo != nil {
+		if record, err := h.repo.GetByID(r.Context(), id); err == nil && record != nil && !authorizeOrg(w, r, record.OrgID) {
+			return uuid.Nil, false
+		}
+	}
+	if h.store != nil {
+		if schedule, err := h.store.GetSchedule(id); err == nil && !authorizeOrg(w, r, schedule.OrgID) {
+			return uuid.Nil, false
+		}
+	}
+	return id, true
+}
+
+// ready 检查是否配置了数据库或内存存储
+func (h *ScheduleRecordHandler) ready(w http.ResponseWriter) bool {
+	if h.repo == nil && h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// listStore 按过滤条件分页列出内存存储中的排班
+func (h *ScheduleRecordHandler) listStore(filter repository.ListFilter) ([]*repository.Schedule, int) {
+	orgID := uuid.Nil
+	if filter.OrgID != nil {
+		orgID = *filter.OrgID
+	}
+	var matched []*model.Schedule
+	for _, schedule := range h.store.ListSchedules(orgID) {
+		if filter.Status != "" && schedule.Status != filter.Status {
+			continue
+		}
+		if filter.StartDate != "" && schedule.StartDate < filter.StartDate {
+			continue
+		}
+		if filter.EndDate != "" && schedule.EndDate > filter.EndDate {
+			continue
+		}
+		matched = append(matched, schedule)
+	}
+
+	var result []*repository.Schedule
+	for i := filter.Offset; i < len(matched) && len(result) < filter.Limit; i++ {
+		result = append(result, scheduleRecord(matched[i]))
+	}
+	return result, len(matched)
+}
+
+// scheduleRecord 将内存存储中的排班转换为排班记录（不含分配）
+func scheduleRecord(schedule *model.Schedule) *repository.Schedule {
+	record := &repository.Schedule{
+		ID:          schedule.ID,
+		OrgID:       schedule.OrgID,
+		StartDate:   schedule.StartDate,
+		EndDate:     schedule.EndDate,
+		Status:      schedule.Status,
+		Feasible:    true,
+		GeneratedAt: schedule.CreatedAt,
+		GeneratedBy: "system",
+		CreatedAt:   schedule.CreatedAt,
+		UpdatedAt:   schedule.UpdatedAt,
+		Metadata:    map[string]any{"version": schedule.Version},
+	}
+	if stats := schedule.Statistics; stats != nil {
+		record.FillRate = stats.FillRate
+		record.SoftScore = stats.ConstraintScore
+	}
+	return record
+}
+
+// recordAssignments 将内存存储中排班的分配转换为分配记录，员工、班次名称取自内存存储
+func (h *ScheduleRecordHandler) recordAssignments(schedule *model.Schedule) []*repository.ScheduleAssignment {
+	empNames := make(map[uuid.UUID]string)
+	shiftNames := make(map[uuid.UUID]string)
+	result := make([]*repository.ScheduleAssignment, 0, len(schedule.Assignments))
+	for _, a := range schedule.Assignments {
+		if _, ok := empNames[a.EmployeeID]; !ok {
+			if emp, err := h.store.GetEmployee(a.EmployeeID); err == nil {
+				empNames[a.EmployeeID] = emp.Name
+			}
+		}
+		if _, ok := shiftNames[a.ShiftID]; !ok {
+			if shift, err := h.store.GetShift(a.ShiftID); err == nil {
+				shiftNames[a.ShiftID] = shift.Name
+			}
+		}
+		status := a.Status
+		if status == "" {
+			status = "assigned"
+		}
+		result = append(result, &repository.ScheduleAssignment{
+			ID:           a.ID,
+			ScheduleID:   schedule.ID,
+			EmployeeID:   a.EmployeeID,
+			EmployeeName: empNames[a.EmployeeID],
+			ShiftID:      a.ShiftID,
+			ShiftName:    shiftNames[a.ShiftID],
+			Date:         a.Date,
+			StartTime:    a.StartTime.Format("15:04"),
+			EndTime:      a.EndTime.Format("15:04"),
+			Position:     a.Position,
+			Status:       status,
+			CreatedAt:    a.CreatedAt,
+			UpdatedAt:    a.UpdatedAt,
+
+			IsSwapped:          a.IsSwapped,
+			OriginalEmployeeID: a.OriginalEmpID,
+		})
+	}
+	return result
+}
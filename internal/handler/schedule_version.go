@@ -0,0 +1,301 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/draft"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ScheduleVersionSummary 排班版本摘要（不含分配）
+type ScheduleVersionSummary struct {
+	Version     int                  `json:"version"`
+	Reason      string               `json:"reason"` // generate/regenerate/edit/publish/rollback
+	Status      string               `json:"status"`
+	Author      string               `json:"author,omitempty"`
+	Assignments int                  `json:"assignment_count"`
+	Statistics  *model.ScheduleStats `json:"statistics,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+}
+
+// ScheduleVersionListResponse 排班版本列表响应
+type ScheduleVersionListResponse struct {
+	ScheduleID string                   `json:"schedule_id"`
+	Current    int                      `json:"current"` // 排班当前版本
+	Versions   []ScheduleVersionSummary `json:"versions"`
+	Total      int                      `json:"total"`
+}
+
+// ScheduleDiffResponse 两个排班版本的差异
+// delta 为 to 版本相对 from 版本的指标变化（after - before）
+type ScheduleDiffResponse struct {
+	ScheduleID string `json:"schedule_id"`
+	From       int    `json:"from"`
+	To         int    `json:"to"`
+	draft.AssignmentDiff
+	Before AssignmentEditMetrics `json:"before"`
+	After  AssignmentEditMetrics `json:"after"`
+	Delta  AssignmentEditMetrics `json:"delta"`
+}
+
+// 回滚后失效分配的原因
+const (
+	RollbackEmployeeMissing  = "employee_missing"  // 员工已删除
+	RollbackEmployeeInactive = "employee_inactive" // 员工已离职或休假
+	RollbackShiftMissing     = "shift_missing"     // 班次已删除
+	RollbackHardConstraint   = "hard_constraint"   // 违反组织当前的硬约束
+)
+
+// RollbackIssue 回滚后按当前数据和约束失效的分配
+type RollbackIssue struct {
+	Reason        string      `json:"reason"`
+	Constraint    string      `json:"constraint,omitempty"` // 违反的约束名称（hard_constraint）
+	EmployeeID    uuid.UUID   `json:"employee_id,omitempty"`
+	ShiftID       uuid.UUID   `json:"shift_id,omitempty"`
+	Date          string      `json:"date,omitempty"`
+	AssignmentIDs []uuid.UUID `json:"assignment_ids"`
+	Message       string      `json:"message"`
+}
+
+// ScheduleRollbackResponse 排班回滚结果
+// invalid 列出恢复的分配中按当前员工、班次和约束已失效的分配（不阻止回滚，需人工处理）
+type ScheduleRollbackResponse struct {
+	Schedule     *model.Schedule          `json:"schedule"`
+	RestoredFrom int                      `json:"restored_from"`
+	Changes      []model.AssignmentChange `json:"changes"`
+	Invalid      []RollbackIssue          `json:"invalid"`
+	DryRun       bool                     `json:"dry_run,omitempty"`
+}
+
+// Versions 列出排班的版本（按版本升序）
+// 路由: GET /api/v1/schedules/{id}/versions
+// 生成、重新生成、修改分配和发布时各保存一个版本快照
+func (h *DraftHandler) Versions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+	schedule, err := h.store.GetSchedule(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+
+	versions := h.store.ListScheduleVersions(id)
+	summaries := make([]ScheduleVersionSummary, len(versions))
+	for i, v := range versions {
+		summaries[i] = ScheduleVersionSummary{
+			Version:     v.Version,
+			Reason:      v.Reason,
+			Status:      v.Status,
+			Author:      v.Author,
+			Assignments: len(v.Assignments),
+			Statistics:  v.Statistics,
+			CreatedAt:   v.CreatedAt,
+		}
+	}
+	respondJSON(w, http.StatusOK, ScheduleVersionListResponse{
+		ScheduleID: id.String(),
+		Current:    schedule.Version,
+		Versions:   summaries,
+		Total:      len(summaries),
+	})
+}
+
+// Diff 对比排班的两个版本：新增、删除、修改的分配及覆盖、公平性指标变化
+// 路由: GET /api/v1/schedules/{id}/diff?from=&to=
+// to 默认为当前版本
+func (h *DraftHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+	schedule, err := h.store.GetSchedule(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := strconv.Atoi(query.Get("from"))
+	if err != nil || from < 1 {
+		respondError(w, errors.New(errors.CodeInvalidInput, "from 必须为正整数"))
+		return
+	}
+	to := schedule.Version
+	if v := query.Get("to"); v != "" {
+		if to, err = strconv.Atoi(v); err != nil || to < 1 {
+			respondError(w, errors.New(errors.CodeInvalidInput, "to 必须为正整数"))
+			return
+		}
+	}
+
+	before, appErr := h.versionSchedule(schedule, from)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	after, appErr := h.versionSchedule(schedule, to)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+
+	employees := h.store.ListEmployees(schedule.OrgID)
+	resp := ScheduleDiffResponse{
+		ScheduleID:     id.String(),
+		From:           from,
+		To:             to,
+		AssignmentDiff: draft.DiffAssignments(before.Assignments, after.Assignments),
+		Before:         h.assignmentMetrics(before, employees),
+		After:          h.assignmentMetrics(after, employees),
+	}
+	resp.Delta = resp.After.sub(resp.Before)
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// Rollback 将排班的分配恢复到之前的版本，作为新版本保存
+// 路由: POST /api/v1/schedules/{id}/rollback/{version}[?dry_run=true]
+// 草稿和已发布的排班都可回滚，基准版本取 If-Match 请求头，未给出时以当前版本为准；
+// 恢复的分配按组织当前的员工、班次和硬约束复核，失效的分配列在 invalid 中
+func (h *DraftHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	id, ok := h.scheduleID(w, r)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil || version < 1 {
+		respondError(w, errors.New(errors.CodeInvalidInput, "版本必须为正整数"))
+		return
+	}
+	current, err := h.store.GetSchedule(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+	baseVersion := current.Version
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if baseVersion, err = parseVersionETag(ifMatch); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的 If-Match"))
+			return
+		}
+	}
+
+	resp := &ScheduleRollbackResponse{RestoredFrom: version, DryRun: r.URL.Query().Get("dry_run") == "true"}
+	if resp.DryRun {
+		resp.Schedule, resp.Changes, err = h.editor.PreviewRollback(id, version)
+	} else {
+		resp.Schedule, resp.Changes, err = h.editor.Rollback(id, version, baseVersion, r.Header.Get(AuthorHeader))
+	}
+	if err != nil {
+		h.respondEditError(w, id, err)
+		return
+	}
+	resp.Invalid = h.rollbackIssues(resp.Schedule)
+
+	if !resp.DryRun {
+		recordAudit(w, r, resp.Schedule.OrgID, model.AuditScheduleRollback, "schedule", id.String(), map[string]interface{}{
+			"restored_from": version,
+			"base_version":  baseVersion,
+			"version":       resp.Schedule.Version,
+			"changes":       len(resp.Changes),
+			"invalid":       len(resp.Invalid),
+		})
+		w.Header().Set("ETag", versionETag(resp.Schedule.Version))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// rollbackIssues 按组织当前的员工、班次和硬约束复核排班的分配
+// 同一原因的失效分配按员工（班次已删除时按班次）合并为一项
+func (h *DraftHandler) rollbackIssues(schedule *model.Schedule) []RollbackIssue {
+	issues := make([]RollbackIssue, 0)
+	index := make(map[string]int)
+	add := func(key string, issue RollbackIssue, assignmentID uuid.UUID) {
+		if i, ok := index[key]; ok {
+			issues[i].AssignmentIDs = append(issues[i].AssignmentIDs, assignmentID)
+			return
+		}
+		index[key] = len(issues)
+		issue.AssignmentIDs = []uuid.UUID{assignmentID}
+		issues = append(issues, issue)
+	}
+
+	for _, a := range schedule.Assignments {
+		if a.Status == "cancelled" {
+			continue
+		}
+		if emp, err := h.store.GetEmployee(a.EmployeeID); err != nil {
+			add(RollbackEmployeeMissing+a.EmployeeID.String(), RollbackIssue{
+				Reason: RollbackEmployeeMissing, EmployeeID: a.EmployeeID,
+				Message: fmt.Sprintf("员工 %s 已删除", a.EmployeeID),
+			}, a.ID)
+		} else if !emp.IsActive() {
+			add(RollbackEmployeeInactive+a.EmployeeID.String(), RollbackIssue{
+				Reason: RollbackEmployeeInactive, EmployeeID: a.EmployeeID,
+				Message: fmt.Sprintf("员工 %s 当前状态为 %s", emp.Name, emp.Status),
+			}, a.ID)
+		}
+		if _, err := h.store.GetShift(a.ShiftID); err != nil {
+			add(RollbackShiftMissing+a.ShiftID.String(), RollbackIssue{
+				Reason: RollbackShiftMissing, ShiftID: a.ShiftID,
+				Message: fmt.Sprintf("班次 %s 已删除", a.ShiftID),
+			}, a.ID)
+		}
+	}
+
+	if h.check == nil {
+		return issues
+	}
+	// 不使用生成时保存的约束配置，按组织当前的约束配置复核
+	c := *schedule
+	c.ConstraintConfig = nil
+	for _, v := range h.check(&c) {
+		issue := RollbackIssue{
+			Reason:        RollbackHardConstraint,
+			Constraint:    v.ConstraintName,
+			EmployeeID:    v.EmployeeID,
+			Date:          v.Date,
+			AssignmentIDs: make([]uuid.UUID, 0),
+			Message:       v.Message,
+		}
+		for _, a := range schedule.Assignments {
+			if a.Status != "cancelled" && a.EmployeeID == v.EmployeeID && (v.Date == "" || a.Date == v.Date) {
+				issue.AssignmentIDs = append(issue.AssignmentIDs, a.ID)
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// versionSchedule 返回排班在指定版本时的内容，当前版本没有快照时使用排班本身
+func (h *DraftHandler) versionSchedule(schedule *model.Schedule, version int) (*model.Schedule, *errors.AppError) {
+	v, err := h.store.GetScheduleVersion(schedule.ID, version)
+	if err != nil {
+		if version == schedule.Version {
+			return schedule, nil
+		}
+		return nil, errors.New(errors.CodeNotFound, fmt.Sprintf("排班版本 %d 不存在", version))
+	}
+	c := *schedule
+	c.Version, c.Status, c.Assignments = v.Version, v.Status, v.Assignments
+	return &c, nil
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/share"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ShareHandler 排班分享链接处理器
+type ShareHandler struct {
+	store   *memstore.Store
+	service *share.Service
+}
+
+// NewShareHandler 创建排班分享链接处理器
+func NewShareHandler(store *memstore.Store, service *share.Service) *ShareHandler {
+	return &ShareHandler{
+		store:   store,
+		service: service,
+	}
+}
+
+// ShareLinkRequest 创建分享链接请求
+type ShareLinkRequest struct {
+	StoreID        string `json:"store_id,omitempty"`         // 只分享该门店员工的排班
+	Position       string `json:"position,omitempty"`         // 只分享该岗位的排班
+	ExpiresInHours int    `json:"expires_in_hours,omitempty"` // 有效期（小时），默认 7 天，最长 90 天
+}
+
+// ShareLinkOutput 分享链接（含访问令牌和地址）
+type ShareLinkOutput struct {
+	*model.ShareLink
+	Token  string `json:"token"`
+	URL    string `json:"url"`
+	Active bool   `json:"active"`
+}
+
+// Links 查询/创建排班的分享链接（需管理者）
+// 路由: GET|POST /api/v1/schedules/{id}/share-links
+// 只有已公布的排班可以分享，创建人取 X-User-ID
+func (h *ShareHandler) Links(w http.ResponseWriter, r *http.Request) {
+	scheduleID, ok := h.scheduleID(w, r)
+	if !ok || !requireManager(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		now := time.Now()
+		links := h.store.ListShareLinks(scheduleID)
+		result := make([]ShareLinkOutput, 0, len(links))
+		for _, l := range links {
+			result = append(result, h.output(l, now))
+		}
+		respondJSON(w, http.StatusOK, result)
+
+	case http.MethodPost:
+		var req ShareLinkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		opts := share.Options{
+			StoreID:  req.StoreID,
+			Position: req.Position,
+			TTL:      time.Duration(req.ExpiresInHours) * time.Hour,
+		}
+		link, err := h.service.Create(scheduleID, opts, r.Header.Get(AuthorHeader))
+		if err != nil {
+			respondShareError(w, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, h.output(link, time.Now()))
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Revoke 撤销分享链接（需管理者），撤销后链接立即失效
+// 路由: POST /api/v1/schedules/{id}/share-links/{link_id}/revoke
+func (h *ShareHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	scheduleID, ok := h.scheduleID(w, r)
+	if !ok || !requireManager(w, r) {
+		return
+	}
+	linkID, err := uuid.Parse(r.PathValue("link_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的分享链接ID格式"))
+		return
+	}
+	link, err := h.service.Revoke(scheduleID, linkID, r.Header.Get(AuthorHeader))
+	if err != nil {
+		respondShareError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, h.output(link, time.Now()))
+}
+
+// Accesses 查询分享链接的访问记录（需管理者，按时间倒序，含被拒绝的访问）
+// 路由: GET /api/v1/schedules/{id}/share-links/{link_id}/accesses
+func (h *ShareHandler) Accesses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	scheduleID, ok := h.scheduleID(w, r)
+	if !ok || !requireManager(w, r) {
+		return
+	}
+	linkID, err := uuid.Parse(r.PathValue("link_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的分享链接ID格式"))
+		return
+	}
+	link, err := h.store.GetShareLink(linkID)
+	if err != nil || link.ScheduleID != scheduleID {
+		respondError(w, errors.New(errors.CodeNotFound, "分享链接不存在"))
+		return
+	}
+	respondJSON(w, http.StatusOK, h.store.ListShareAccesses(linkID))
+}
+
+// View 通过分享链接查看只读排班（无需登录）
+// 路由: GET /share/{token}?format=json（默认返回 HTML 页面）
+// 令牌无效返回 404，链接过期、撤销或排班撤回公布返回 403
+func (h *ShareHandler) View(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	format := "html"
+	if r.URL.Query().Get("format") == "json" {
+		format = "json"
+	}
+	// 令牌在地址中，禁止缓存和通过 Referer 外泄
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	view, err := h.service.Open(r.PathValue("token"), model.ShareAccess{
+		RemoteAddr: r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+		Format:     format,
+	})
+	if err != nil {
+		if format == "html" {
+			status := http.StatusForbidden
+			if err == share.ErrInvalidToken {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		respondShareError(w, err)
+		return
+	}
+
+	if format == "json" {
+		respondJSON(w, http.StatusOK, view)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	share.RenderHTML(w, view)
+}
+
+// output 生成分享链接输出
+func (h *ShareHandler) output(l *model.ShareLink, now time.Time) ShareLinkOutput {
+	token := h.service.Token(l)
+	return ShareLinkOutput{
+		ShareLink: l,
+		Token:     token,
+		URL:       "/share/" + token,
+		Active:    l.IsActiveAt(now),
+	}
+}
+
+// scheduleID 检查存储是否启用并解析排班ID
+func (h *ShareHandler) scheduleID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil || h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式"))
+		return uuid.Nil, false
+	}
+	if schedule, err := h.store.GetSchedule(id); err == nil && !authorizeOrg(w, r, schedule.OrgID) {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// respondShareError 将分享服务错误转换为响应
+func respondShareError(w http.ResponseWriter, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "排班或分享链接不存在"))
+	case err == share.ErrInvalidToken:
+		respondError(w, errors.New(errors.CodeNotFound, err.Error()))
+	case stderrors.Is(err, share.ErrInvalidOptions):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	case err == share.ErrNotPublished, err == share.ErrExpired, err == share.ErrRevoked:
+		respondError(w, errors.New(errors.CodeForbidden, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "处理分享链接失败"))
+	}
+}
@@ -0,0 +1,516 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// shiftTypes 班次类型（与 shifts 表的 shift_type 取值一致）
+var shiftTypes = map[string]bool{
+	"morning": true, "afternoon": true, "evening": true, "night": true, "split": true, "regular": true,
+}
+
+// ShiftHandler 班次定义处理器
+// 配置数据库时读写 ShiftRepository（删除为软删除），否则使用内存存储
+type ShiftHandler struct {
+	repo  repository.ShiftRepositoryInterface
+	store *memstore.Store
+}
+
+// NewShiftHandler 创建班次定义处理器，repo 为空时使用内存存储
+func NewShiftHandler(repo *repository.ShiftRepository, store *memstore.Store) *ShiftHandler {
+	h := &ShiftHandler{store: store}
+	if repo != nil {
+		h.repo = repo
+	}
+	return h
+}
+
+// ShiftRecordInput 班次定义的新增/更新请求，更新时只修改给出的字段
+type ShiftRecordInput struct {
+	OrgID       string  `json:"org_id,omitempty"` // 仅新增时使用
+	Name        *string `json:"name,omitempty"`
+	Code        *string `json:"code,omitempty"` // 班次编码，组织内唯一，排班请求可按编码引用
+	Description *string `json:"description,omitempty"`
+	StartTime   *string `json:"start_time,omitempty"` // HH:MM
+	EndTime     *string `json:"end_time,omitempty"`   // HH:MM，早于开始时间表示跨天
+	Duration    *int    `json:"duration,omitempty"`   // 分钟，默认为开始到结束的时长
+	BreakTime   *int    `json:"break_time,omitempty"` // 休息时间（分钟）
+	ShiftType   *string `json:"shift_type,omitempty"` // morning/afternoon/evening/night/split/regular，默认 regular
+	Color       *string `json:"color,omitempty"`
+	IsActive    *bool   `json:"is_active,omitempty"` // 默认 true
+}
+
+// ShiftListResponse 班次列表响应
+type ShiftListResponse struct {
+	Shifts []*model.Shift `json:"shifts"`
+	Total  int            `json:"total"`
+	Offset int            `json:"offset"`
+	Limit  int            `json:"limit"`
+}
+
+// Collection 列出或新增班次
+// 未指定 org_id 时组织受限的调用方列出所属组织的班次
+// 路由: GET /api/v1/shifts?org_id=&active=&q=&offset=&limit=
+// 路由: POST /api/v1/shifts
+func (h *ShiftHandler) Collection(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w, r)
+
+	case http.MethodPost:
+		var input ShiftRecordInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		orgID, err := uuid.Parse(input.OrgID)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		shift := &model.Shift{
+			BaseModel: model.NewBaseModel(),
+			OrgID:     orgID,
+			ShiftType: "regular",
+			IsActive:  true,
+		}
+		input.apply(shift)
+		if appErr := h.save(r.Context(), shift, true); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		respondJSON(w, http.StatusCreated, shift)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Item 获取、更新或删除班次
+// 路由: GET|PUT|DELETE /api/v1/shifts/{id}
+func (h *ShiftHandler) Item(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的班次ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		shift, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, shift.OrgID) {
+			return
+		}
+		respondJSON(w, http.StatusOK, shift)
+
+	case http.MethodPut:
+		var input ShiftRecordInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		shift, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, shift.OrgID) {
+			return
+		}
+		// 修改了起止时间但未给出时长时，按新的起止时间重新计算
+		if input.Duration == nil && (input.StartTime != nil || input.EndTime != nil) {
+			shift.Duration = 0
+		}
+		input.apply(shift)
+		shift.UpdatedAt = time.Now()
+		if appErr := h.save(r.Context(), shift, false); appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		respondJSON(w, http.StatusOK, shift)
+
+	case http.MethodDelete:
+		shift, appErr := h.get(r.Context(), id)
+		if appErr != nil {
+			respondError(w, appErr)
+			return
+		}
+		if !authorizeOrg(w, r, shift.OrgID) {
+			return
+		}
+		// 数据库中为软删除，已保存排班中的班次关联不受影响；内存存储直接删除
+		if h.repo != nil {
+			err = h.repo.Delete(r.Context(), id)
+		} else {
+			err = h.store.DeleteShift(id)
+		}
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "删除班次失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"deleted": true,
+			"id":      id.String(),
+		})
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT/DELETE方法"))
+	}
+}
+
+// list 分页查询班次（按开始时间排序），active=true/false 按启用状态过滤
+func (h *ShiftHandler) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	orgID, ok := listOrgID(w, r)
+	if !ok {
+		return
+	}
+	if orgID == uuid.Nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+	filter := repository.DefaultListFilter().WithOrgID(orgID)
+	switch query.Get("active") {
+	case "":
+	case "true":
+		filter = filter.WithStatus("active")
+	case "false":
+		filter = filter.WithStatus("inactive")
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "active 须为 true 或 false"))
+		return
+	}
+	filter.Search = strings.TrimSpace(query.Get("q"))
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		filter = filter.WithOffset(offset)
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= 100 {
+		filter = filter.WithLimit(limit)
+	}
+
+	var shifts []*model.Shift
+	var total int
+	var err error
+	if h.repo != nil {
+		shifts, total, err = h.repo.List(r.Context(), filter)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询班次失败"))
+			return
+		}
+	} else {
+		shifts, total = h.listStore(orgID, filter)
+	}
+	if shifts == nil {
+		shifts = make([]*model.Shift, 0)
+	}
+	respondJSON(w, http.StatusOK, ShiftListResponse{Shifts: shifts, Total: total, Offset: filter.Offset, Limit: filter.Limit})
+}
+
+// listStore 按过滤条件分页查询内存存储中的班次
+func (h *ShiftHandler) listStore(orgID uuid.UUID, filter repository.ListFilter) ([]*model.Shift, int) {
+	search := strings.ToLower(filter.Search)
+	matched := make([]*model.Shift, 0)
+	for _, shift := range h.store.ListShifts(orgID) {
+		if filter.Status != "" && shift.IsActive != (filter.Status == "active") {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(shift.Name), search) &&
+			!strings.Contains(strings.ToLower(shift.Code), search) {
+			continue
+		}
+		matched = append(matched, shift)
+	}
+
+	total := len(matched)
+	if filter.Offset >= total {
+		return nil, total
+	}
+	end := filter.Offset + filter.Limit
+	if end > total {
+		end = total
+	}
+	return matched[filter.Offset:end], total
+}
+
+// get 获取班次，不存在时返回 404
+func (h *ShiftHandler) get(ctx context.Context, id uuid.UUID) (*model.Shift, *errors.AppError) {
+	if h.repo != nil {
+		shift, err := h.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInternal, "查询班次失败")
+		}
+		if shift == nil {
+			return nil, errors.New(errors.CodeNotFound, "班次不存在")
+		}
+		return shift, nil
+	}
+	shift, err := h.store.GetShift(id)
+	if err != nil {
+		return nil, errors.New(errors.CodeNotFound, "班次不存在")
+	}
+	return shift, nil
+}
+
+// save 校验并保存班次，班次编码在组织内须唯一
+func (h *ShiftHandler) save(ctx context.Context, shift *model.Shift, create bool) *errors.AppError {
+	if shift.Duration == 0 && validClock(shift.StartTime) && validClock(shift.EndTime) {
+		shift.Duration = clockSpanMinutes(shift.StartTime, shift.EndTime)
+	}
+	if appErr := validateShiftRecord(shift); appErr != nil {
+		return appErr
+	}
+	other, err := findShiftByCode(ctx, h.repo, h.store, shift.OrgID, shift.Code)
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "查询班次失败")
+	}
+	if other != nil && other.ID != shift.ID {
+		return errors.New(errors.CodeAlreadyExists, "班次编码已存在: "+shift.Code)
+	}
+
+	switch {
+	case h.repo != nil && create:
+		err = h.repo.Create(ctx, shift)
+	case h.repo != nil:
+		err = h.repo.Update(ctx, shift)
+	default:
+		err = h.store.PutShift(shift)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.CodeInternal, "保存班次失败")
+	}
+	return nil
+}
+
+// apply 将请求中给出的字段写入班次
+func (in *ShiftRecordInput) apply(shift *model.Shift) {
+	if in.Name != nil {
+		shift.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Code != nil {
+		shift.Code = strings.TrimSpace(*in.Code)
+	}
+	if in.Description != nil {
+		shift.Description = *in.Description
+	}
+	if in.StartTime != nil {
+		shift.StartTime = *in.StartTime
+	}
+	if in.EndTime != nil {
+		shift.EndTime = *in.EndTime
+	}
+	if in.Duration != nil {
+		shift.Duration = *in.Duration
+	}
+	if in.BreakTime != nil {
+		shift.BreakTime = *in.BreakTime
+	}
+	if in.ShiftType != nil {
+		shift.ShiftType = *in.ShiftType
+	}
+	if in.Color != nil {
+		shift.Color = *in.Color
+	}
+	if in.IsActive != nil {
+		shift.IsActive = *in.IsActive
+	}
+}
+
+// validateShiftRecord 校验班次定义字段
+func validateShiftRecord(shift *model.Shift) *errors.AppError {
+	ve := &errors.ValidationErrors{}
+	if shift.Name == "" {
+		ve.Add("name", "班次名称不能为空")
+	}
+	if shift.Code == "" {
+		ve.Add("code", "班次编码不能为空")
+	}
+	if !validClock(shift.StartTime) {
+		ve.Add("start_time", "开始时间格式须为 HH:MM")
+	}
+	if !validClock(shift.EndTime) {
+		ve.Add("end_time", "结束时间格式须为 HH:MM")
+	}
+	if shift.Duration <= 0 || shift.Duration > 24*60 {
+		ve.Add("duration", "时长须在 1 到 1440 分钟之间")
+	}
+	if shift.BreakTime < 0 || (shift.Duration > 0 && shift.BreakTime >= shift.Duration) {
+		ve.Add("break_time", "休息时间不能为负数，且须短于班次时长")
+	}
+	if !shiftTypes[shift.ShiftType] {
+		ve.Add("shift_type", "班次类型须为 morning、afternoon、evening、night、split 或 regular")
+	}
+	if len(shift.Color) > 20 {
+		ve.Add("color", "颜色标识最长 20 个字符")
+	}
+	if ve.HasErrors() {
+		return ve.ToAppError()
+	}
+	return nil
+}
+
+// clockSpanMinutes 计算 HH:MM 起止时间的分钟数，结束不晚于开始时视为跨天
+func clockSpanMinutes(start, end string) int {
+	s, _ := time.Parse("15:04", start)
+	e, _ := time.Parse("15:04", end)
+	minutes := int(e.Sub(s).Minutes())
+	if minutes <= 0 {
+		minutes += 24 * 60
+	}
+	return minutes
+}
+
+// findShiftByCode 按编码查找组织下的班次，不存在时返回 nil
+func findShiftByCode(ctx context.Context, repo repository.ShiftRepositoryInterface, store *memstore.Store, orgID uuid.UUID, code string) (*model.Shift, error) {
+	if code == "" {
+		return nil, nil
+	}
+	if repo != nil {
+		return repo.GetByCode(ctx, orgID, code)
+	}
+	for _, shift := range store.ListShifts(orgID) {
+		if shift.Code == code {
+			return shift, nil
+		}
+	}
+	return nil, nil
+}
+
+// ready 检查是否启用了存储
+func (h *ShiftHandler) ready(w http.ResponseWriter) bool {
+	if h.repo == nil && h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// withStoredShifts 排班请求按编码引用已保存的班次：
+//   - 未提供 shifts 时使用组织的全部启用班次
+//   - 只给出 code（无 id 和起止时间）的班次从组织的班次定义补全
+//   - 需求未给出 shift_id 时按 shift_code 匹配班次
+//
+// 配置数据库时读取 ShiftRepository，否则读取内存存储
+func (h *ScheduleHandler) withStoredShifts(ctx context.Context, req *GenerateRequest) *errors.AppError {
+	orgID, err := uuid.Parse(req.OrgID)
+	if err != nil {
+		return nil // 由请求校验报告组织ID格式错误
+	}
+	var repo repository.ShiftRepositoryInterface
+	if h.shiftRepo != nil {
+		repo = h.shiftRepo
+	} else if h.store == nil {
+		return h.resolveShiftCodes(req)
+	}
+
+	if len(req.Shifts) == 0 {
+		var shifts []*model.Shift
+		if repo != nil {
+			if shifts, err = repo.ListActive(ctx, orgID); err != nil {
+				return errors.Wrap(err, errors.CodeInternal, "查询班次定义失败")
+			}
+		} else {
+			for _, shift := range h.store.ListShifts(orgID) {
+				if shift.IsActive {
+					shifts = append(shifts, shift)
+				}
+			}
+		}
+		for _, shift := range shifts {
+			req.Shifts = append(req.Shifts, toShiftInput(shift))
+		}
+	}
+	for i, s := range req.Shifts {
+		if s.ID != "" || s.StartTime != "" || s.Code == "" {
+			continue
+		}
+		shift, err := findShiftByCode(ctx, repo, h.store, orgID, s.Code)
+		if err != nil {
+			return errors.Wrap(err, errors.CodeInternal, "查询班次定义失败")
+		}
+		if shift == nil {
+			return errors.New(errors.CodeInvalidInput, "未找到班次定义: "+s.Code)
+		}
+		stored := toShiftInput(shift)
+		if s.StoreID != "" {
+			stored.StoreID = s.StoreID
+		}
+		req.Shifts[i] = stored
+	}
+	return h.resolveShiftCodes(req)
+}
+
+// resolveShiftCodes 将需求中的 shift_code 解析为请求中班次的ID
+func (h *ScheduleHandler) resolveShiftCodes(req *GenerateRequest) *errors.AppError {
+	for i, r := range req.Requirements {
+		if r.ShiftID != "" || r.ShiftCode == "" {
+			continue
+		}
+		for _, s := range req.Shifts {
+			if s.Code == r.ShiftCode {
+				req.Requirements[i].ShiftID = s.ID
+				break
+			}
+		}
+		if req.Requirements[i].ShiftID == "" {
+			return errors.New(errors.CodeInvalidInput, fmt.Sprintf("需求引用的班次不存在: %s", r.ShiftCode))
+		}
+	}
+	return nil
+}
+
+// toShiftInput 将班次定义转换为排班请求中的班次
+func toShiftInput(shift *model.Shift) ShiftInput {
+	return ShiftInput{
+		ID:        shift.ID.String(),
+		Name:      shift.Name,
+		Code:      shift.Code,
+		StartTime: shift.StartTime,
+		EndTime:   shift.EndTime,
+		Duration:  shift.Duration,
+		Type:      shift.ShiftType,
+		StoreID:   shift.StoreID,
+	}
+}
+
+// keepShiftRecord 保留班次定义中排班请求不包含的字段
+func keepShiftRecord(shift, record *model.Shift) {
+	if shift.Description == "" {
+		shift.Description = record.Description
+	}
+	if shift.BreakTime == 0 {
+		shift.BreakTime = record.BreakTime
+	}
+	if shift.Color == "" {
+		shift.Color = record.Color
+	}
+	if shift.ShiftType == "" {
+		shift.ShiftType = record.ShiftType
+	}
+	shift.IsActive = record.IsActive
+	if shift.CreatedAt.IsZero() {
+		shift.CreatedAt = record.CreatedAt
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"sort"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
 	"github.com/paiban/paiban/pkg/model"
 	"github.com/paiban/paiban/pkg/stats"
 )
@@ -19,6 +22,15 @@ type StatsRequest struct {
 	Employees   []*model.Employee   `json:"employees"`
 	Shifts      []*model.Shift      `json:"shifts"`
 	Assignments []*model.Assignment `json:"assignments"`
+
+	// 门店每周工时预算（用于工作量统计的预算对比），为空时使用存储中该组织的预算
+	StoreBudgets []model.StoreHoursBudget `json:"store_budgets,omitempty"`
+
+	// 人工成本计算参数（standard_hours_per_day、overtime_pay_rate、holidays 等，键与排班约束配置一致）
+	CostConfig map[string]interface{} `json:"cost_config,omitempty"`
+
+	// 每小时最低在岗人数要求（用于覆盖率分析识别人手不足时段，与排班约束 hourly_staffing 格式相同）
+	HourlyStaffing []model.HourlyStaffing `json:"hourly_staffing,omitempty"`
 }
 
 // FairnessResponse 公平性响应
@@ -53,6 +65,19 @@ type WorkloadSummary struct {
 	ByEmployee        []EmployeeWorkload       `json:"by_employee"`
 	ByDate            map[string]DailyWorkload `json:"by_date"`
 	ByShiftType       map[string]float64       `json:"by_shift_type"`
+	ByStore           []StoreBudgetUsage       `json:"by_store,omitempty"` // 门店每周工时与预算对比
+}
+
+// StoreBudgetUsage 门店每周工时预算执行情况
+// 未设置预算时预算相关字段为空
+type StoreBudgetUsage struct {
+	StoreID       string   `json:"store_id"`
+	WeekStart     string   `json:"week_start"`
+	ActualHours   float64  `json:"actual_hours"`
+	BudgetHours   *float64 `json:"budget_hours,omitempty"`
+	VarianceHours *float64 `json:"variance_hours,omitempty"` // 实际 - 预算，正数表示超支
+	UsagePercent  *float64 `json:"usage_percent,omitempty"`
+	OverBudget    bool     `json:"over_budget"`
 }
 
 // EmployeeWorkload 员工工作量
@@ -74,27 +99,47 @@ type DailyWorkload struct {
 }
 
 // GetFairnessHandler 公平性分析API
+// 支持 NDJSON 流式请求（Content-Type: application/x-ndjson）和响应（Accept: application/x-ndjson）
 func GetFairnessHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req StatsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	// 逐条累加分配，内存占用与员工数成正比；待命班次（shift_type 为 on_call）的分配单独统计
+	var acc *stats.FairnessAccumulator
+	req, count, err := decodeStatsRequest(r, func(req *StatsRequest) func(*model.Assignment) {
+		acc = stats.NewFairnessAnalyzer().NewAccumulator(convertToEmployeeInfo(req.Employees))
+		onCall := make(map[uuid.UUID]bool)
+		for _, s := range req.Shifts {
+			if s.IsOnCall() {
+				onCall[s.ID] = true
+			}
+		}
+		return func(a *model.Assignment) {
+			info := toStatsAssignment(a)
+			info.OnCall = onCall[a.ShiftID]
+			acc.Add(info)
+		}
+	})
+	if err != nil {
 		sendJSONError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	log.Printf("接收公平性分析请求: org_id=%s, employees=%d, assignments=%d",
-		req.OrgID, len(req.Employees), len(req.Assignments))
+		req.OrgID, len(req.Employees), count)
 
-	// 转换为stats包的类型
-	assignments := convertToAssignmentInfo(req.Assignments)
-	employees := convertToEmployeeInfo(req.Employees)
+	metrics := acc.Result()
 
-	analyzer := stats.NewFairnessAnalyzer()
-	metrics := analyzer.Analyze(assignments, employees)
+	if wantsNDJSON(r) {
+		rows := metrics.EmployeeStats
+		metrics.EmployeeStats = nil
+		respondStatsNDJSON(w, FairnessResponse{Success: true, Data: metrics}, len(rows), func(i int) interface{} {
+			return rows[i]
+		})
+		return
+	}
 
 	resp := FairnessResponse{
 		Success: true,
@@ -106,27 +151,41 @@ func GetFairnessHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetCoverageHandler 覆盖率分析API
+// 支持 NDJSON 流式请求（Content-Type: application/x-ndjson）
 func GetCoverageHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req StatsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	// 只记录已分配的班次和每小时在岗人数，内存占用与班次数、小时数成正比
+	analyzer := stats.NewCoverageAnalyzer()
+	acc := analyzer.NewAccumulator()
+	req, count, err := decodeStatsRequest(r, func(*StatsRequest) func(*model.Assignment) {
+		return func(a *model.Assignment) { acc.Add(toStatsAssignment(a)) }
+	})
+	if err != nil {
 		sendJSONError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	log.Printf("接收覆盖率分析请求: org_id=%s, shifts=%d, assignments=%d",
-		req.OrgID, len(req.Shifts), len(req.Assignments))
+		req.OrgID, len(req.Shifts), count)
 
-	// 转换为stats包的类型
-	shifts := convertToShiftInfo(req.Shifts)
-	assignments := convertToAssignmentInfo(req.Assignments)
+	for _, rule := range req.HourlyStaffing {
+		if err := rule.Validate(); err != nil {
+			sendJSONError(w, "Invalid hourly_staffing: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	analyzer.SetDailyMinStaffRequirements(model.HourlyMinimumsBetween(req.HourlyStaffing, req.StartDate, req.EndDate))
 
-	analyzer := stats.NewCoverageAnalyzer()
-	metrics := analyzer.Analyze(shifts, assignments)
+	metrics := acc.Result(convertToShiftInfo(req.Shifts))
+
+	if wantsNDJSON(r) {
+		respondStatsNDJSON(w, CoverageResponse{Success: true, Data: metrics}, 0, nil)
+		return
+	}
 
 	resp := CoverageResponse{
 		Success: true,
@@ -137,15 +196,33 @@ func GetCoverageHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// workloadStore 门店工时预算和自定义节假日来源，为空时仅使用请求中的参数
+var workloadStore *memstore.Store
+
+// SetWorkloadStore 设置统计接口使用的内存存储（用于读取门店工时预算和组织自定义节假日）
+func SetWorkloadStore(store *memstore.Store) {
+	workloadStore = store
+}
+
 // GetWorkloadHandler 工作量统计API
+// 支持 NDJSON 流式请求（Content-Type: application/x-ndjson）和响应（Accept: application/x-ndjson）
 func GetWorkloadHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req StatsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	var acc *workloadAccumulator
+	req, _, err := decodeStatsRequest(r, func(req *StatsRequest) func(*model.Assignment) {
+		// 构建员工映射
+		employeeMap := make(map[string]*model.Employee)
+		for _, e := range req.Employees {
+			employeeMap[e.ID.String()] = e
+		}
+		acc = newWorkloadAccumulator(employeeMap, req.StartDate, req.EndDate)
+		return acc.add
+	})
+	if err != nil {
 		sendJSONError(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -153,14 +230,25 @@ func GetWorkloadHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("接收工作量统计请求: org_id=%s, start_date=%s, end_date=%s",
 		req.OrgID, req.StartDate, req.EndDate)
 
-	// 构建员工映射
-	employeeMap := make(map[string]*model.Employee)
-	for _, e := range req.Employees {
-		employeeMap[e.ID.String()] = e
+	// 门店预算对比
+	budgets := req.StoreBudgets
+	if len(budgets) == 0 && workloadStore != nil {
+		if orgID, err := uuid.Parse(req.OrgID); err == nil {
+			for _, b := range workloadStore.ListStoreBudgets(orgID) {
+				budgets = append(budgets, *b)
+			}
+		}
+	}
+	summary := acc.result(budgets)
+
+	if wantsNDJSON(r) {
+		rows := summary.ByEmployee
+		summary.ByEmployee = nil
+		respondStatsNDJSON(w, WorkloadResponse{Success: true, Data: summary}, len(rows), func(i int) interface{} {
+			return rows[i]
+		})
+		return
 	}
-
-	// 计算工作量
-	summary := calculateWorkload(req.Assignments, employeeMap, req.StartDate, req.EndDate)
 
 	resp := WorkloadResponse{
 		Success: true,
@@ -171,65 +259,99 @@ func GetWorkloadHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
-// calculateWorkload 计算工作量
-func calculateWorkload(assignments []*model.Assignment, employeeMap map[string]*model.Employee, startDate, endDate string) *WorkloadSummary {
-	summary := &WorkloadSummary{
-		Period:      startDate + " ~ " + endDate,
-		ByDate:      make(map[string]DailyWorkload),
-		ByShiftType: make(map[string]float64),
-	}
-
-	// 员工工作量统计
-	employeeStats := make(map[string]*EmployeeWorkload)
+// storeWeekKey 门店 + 周起始日
+type storeWeekKey struct{ store, week string }
+
+// workloadAccumulator 工作量增量统计
+// 逐条累加分配，只保留员工、日期和门店周级汇总，内存占用与分配数无关
+type workloadAccumulator struct {
+	employeeMap   map[string]*model.Employee
+	startDate     string
+	endDate       string
+	summary       *WorkloadSummary
+	employeeStats map[string]*EmployeeWorkload
+	storeHours    map[storeWeekKey]float64
+	stores        map[string]bool
+}
 
-	standardWeeklyHours := 40.0
+// newWorkloadAccumulator 创建工作量增量统计
+func newWorkloadAccumulator(employeeMap map[string]*model.Employee, startDate, endDate string) *workloadAccumulator {
+	return &workloadAccumulator{
+		employeeMap: employeeMap,
+		startDate:   startDate,
+		endDate:     endDate,
+		summary: &WorkloadSummary{
+			Period:      startDate + " ~ " + endDate,
+			ByDate:      make(map[string]DailyWorkload),
+			ByShiftType: make(map[string]float64),
+		},
+		employeeStats: make(map[string]*EmployeeWorkload),
+		storeHours:    make(map[storeWeekKey]float64),
+		stores:        make(map[string]bool),
+	}
+}
 
-	for _, a := range assignments {
-		// 计算工时
-		hours := a.EndTime.Sub(a.StartTime).Hours()
-		summary.TotalHours += hours
-		summary.TotalShifts++
-
-		empID := a.EmployeeID.String()
-		// 员工统计
-		ew, exists := employeeStats[empID]
-		if !exists {
-			name := empID
-			if emp, ok := employeeMap[empID]; ok {
-				name = emp.Name
-			}
-			ew = &EmployeeWorkload{
-				EmployeeID:   empID,
-				EmployeeName: name,
-			}
-			employeeStats[empID] = ew
+// add 累加一条分配
+func (acc *workloadAccumulator) add(a *model.Assignment) {
+	summary := acc.summary
+
+	// 计算工时
+	hours := a.EndTime.Sub(a.StartTime).Hours()
+	summary.TotalHours += hours
+	summary.TotalShifts++
+
+	empID := a.EmployeeID.String()
+	emp := acc.employeeMap[empID]
+	// 员工统计
+	ew, exists := acc.employeeStats[empID]
+	if !exists {
+		name := empID
+		if emp != nil {
+			name = emp.Name
 		}
-		ew.TotalHours += hours
-		ew.ShiftCount++
-
-		// 日期统计
-		daily, exists := summary.ByDate[a.Date]
-		if !exists {
-			daily = DailyWorkload{Date: a.Date}
+		ew = &EmployeeWorkload{
+			EmployeeID:   empID,
+			EmployeeName: name,
 		}
-		daily.TotalHours += hours
-		daily.ShiftCount++
-		daily.StaffCount++
-		summary.ByDate[a.Date] = daily
-
-		// 班次类型统计
-		shiftType := classifyShiftType(a.StartTime)
-		summary.ByShiftType[shiftType] += hours
+		acc.employeeStats[empID] = ew
+	}
+	ew.TotalHours += hours
+	ew.ShiftCount++
+
+	// 日期统计
+	daily, exists := summary.ByDate[a.Date]
+	if !exists {
+		daily = DailyWorkload{Date: a.Date}
+	}
+	daily.TotalHours += hours
+	daily.ShiftCount++
+	daily.StaffCount++
+	summary.ByDate[a.Date] = daily
+
+	// 班次类型统计
+	shiftType := classifyShiftType(a.StartTime)
+	summary.ByShiftType[shiftType] += hours
+
+	// 门店每周工时（按上班门店统计）
+	if store := a.Store(emp); store != "" {
+		acc.stores[store] = true
+		acc.storeHours[storeWeekKey{store, model.BudgetWeekStart(a.Date)}] += hours
 	}
+}
+
+// result 计算加班、利用率和门店预算对比，得到工作量汇总
+func (acc *workloadAccumulator) result(budgets []model.StoreHoursBudget) *WorkloadSummary {
+	summary := acc.summary
+	standardWeeklyHours := 40.0
 
 	// 计算加班和利用率
-	summary.EmployeeCount = len(employeeStats)
+	summary.EmployeeCount = len(acc.employeeStats)
 
 	// 计算周数
 	weeks := 1.0
-	if startDate != "" && endDate != "" {
-		start, err1 := time.Parse("2006-01-02", startDate)
-		end, err2 := time.Parse("2006-01-02", endDate)
+	if acc.startDate != "" && acc.endDate != "" {
+		start, err1 := time.Parse("2006-01-02", acc.startDate)
+		end, err2 := time.Parse("2006-01-02", acc.endDate)
 		if err1 == nil && err2 == nil {
 			days := end.Sub(start).Hours() / 24
 			weeks = days / 7
@@ -241,7 +363,7 @@ func calculateWorkload(assignments []*model.Assignment, employeeMap map[string]*
 
 	expectedHours := standardWeeklyHours * weeks
 
-	for _, ew := range employeeStats {
+	for _, ew := range acc.employeeStats {
 		if ew.TotalHours > expectedHours {
 			ew.OvertimeHours = ew.TotalHours - expectedHours
 			summary.OvertimeHours += ew.OvertimeHours
@@ -255,9 +377,66 @@ func calculateWorkload(assignments []*model.Assignment, employeeMap map[string]*
 		summary.AvgHoursPerPerson = summary.TotalHours / float64(summary.EmployeeCount)
 	}
 
+	summary.ByStore = acc.storeBudgetUsage(budgets)
 	return summary
 }
 
+// storeBudgetUsage 按门店和周汇总实际工时，并与预算对比
+// 门店取自员工的 store_id；排班周期内有预算但无排班的周也会列出
+func (acc *workloadAccumulator) storeBudgetUsage(budgets []model.StoreHoursBudget) []StoreBudgetUsage {
+	stores := make(map[string]bool, len(acc.stores))
+	for store := range acc.stores {
+		stores[store] = true
+	}
+	for _, b := range budgets {
+		stores[b.StoreID] = true
+	}
+	if len(stores) == 0 {
+		return nil
+	}
+
+	// 周期内的周；日期无效时仅使用有排班的周
+	weekSet := make(map[string]bool)
+	start, err1 := time.Parse("2006-01-02", acc.startDate)
+	end, err2 := time.Parse("2006-01-02", acc.endDate)
+	if err1 == nil && err2 == nil {
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			weekSet[model.BudgetWeekStart(d.Format("2006-01-02"))] = true
+		}
+	}
+	for k := range acc.storeHours {
+		weekSet[k.week] = true
+	}
+
+	result := make([]StoreBudgetUsage, 0)
+	for store := range stores {
+		for week := range weekSet {
+			hours := acc.storeHours[storeWeekKey{store, week}]
+			usage := StoreBudgetUsage{StoreID: store, WeekStart: week, ActualHours: hours}
+			if budget, ok := model.ResolveStoreBudget(budgets, store, week); ok {
+				variance := hours - budget
+				usage.BudgetHours = &budget
+				usage.VarianceHours = &variance
+				if budget > 0 {
+					percent := hours / budget * 100
+					usage.UsagePercent = &percent
+				}
+				usage.OverBudget = hours > budget
+			} else if hours == 0 {
+				continue
+			}
+			result = append(result, usage)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].StoreID != result[j].StoreID {
+			return result[i].StoreID < result[j].StoreID
+		}
+		return result[i].WeekStart < result[j].WeekStart
+	})
+	return result
+}
+
 // classifyShiftType 分类班次类型
 func classifyShiftType(start time.Time) string {
 	hour := start.Hour()
@@ -279,20 +458,16 @@ func sendJSONError(w http.ResponseWriter, message string, status int) {
 	})
 }
 
-// convertToAssignmentInfo 转换Assignment为stats包类型
-func convertToAssignmentInfo(assignments []*model.Assignment) []*stats.AssignmentInfo {
-	result := make([]*stats.AssignmentInfo, len(assignments))
-	for i, a := range assignments {
-		result[i] = &stats.AssignmentInfo{
-			ShiftID:      a.ShiftID.String(),
-			EmployeeID:   a.EmployeeID.String(),
-			EmployeeName: "", // 由统计包从员工列表获取
-			Date:         a.Date,
-			StartTime:    a.StartTime,
-			EndTime:      a.EndTime,
-		}
+// toStatsAssignment 转换单条Assignment为stats包类型
+func toStatsAssignment(a *model.Assignment) *stats.AssignmentInfo {
+	return &stats.AssignmentInfo{
+		ShiftID:      a.ShiftID.String(),
+		EmployeeID:   a.EmployeeID.String(),
+		EmployeeName: "", // 由统计包从员工列表获取
+		Date:         a.Date,
+		StartTime:    a.StartTime,
+		EndTime:      a.EndTime,
 	}
-	return result
 }
 
 // convertToEmployeeInfo 转换Employee为stats包类型
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ndjsonContentType 流式统计请求/响应的内容类型（每行一个 JSON 对象）
+const ndjsonContentType = "application/x-ndjson"
+
+// isNDJSON 判断媒体类型是否为 NDJSON
+func isNDJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == ndjsonContentType || mediaType == "application/ndjson"
+}
+
+// wantsNDJSON 客户端是否通过 Accept 头要求流式响应
+func wantsNDJSON(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		if isNDJSON(strings.TrimSpace(part)) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeStatsRequest 解析统计请求，并将分配逐条交给 begin 返回的回调，返回分配条数
+// JSON 请求一次性解码；NDJSON 请求首行为请求头（org_id、日期、employees、shifts 等），
+// 其后每行一条分配，边读边累加，不在内存中保留分配列表
+func decodeStatsRequest(r *http.Request, begin func(req *StatsRequest) func(*model.Assignment)) (*StatsRequest, int, error) {
+	var req StatsRequest
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&req); err != nil {
+		return nil, 0, err
+	}
+
+	add := begin(&req)
+	count := 0
+	for _, a := range req.Assignments {
+		add(a)
+		count++
+	}
+	req.Assignments = nil
+	if !isNDJSON(r.Header.Get("Content-Type")) {
+		return &req, count, nil
+	}
+
+	for line := 2; ; line++ {
+		var a model.Assignment
+		if err := dec.Decode(&a); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, count, fmt.Errorf("第 %d 行: %w", line, err)
+		}
+		add(&a)
+		count++
+	}
+	return &req, count, nil
+}
+
+// respondStatsNDJSON 以 NDJSON 返回统计结果
+// 首行为响应主体（逐员工明细已移出），其后每行一条员工明细
+func respondStatsNDJSON(w http.ResponseWriter, head interface{}, rows int, row func(i int) interface{}) {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(head); err != nil {
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	for i := 0; i < rows; i++ {
+		if err := enc.Encode(row(i)); err != nil {
+			return
+		}
+		if flusher != nil && (i+1)%500 == 0 {
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/summary"
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// SummaryHandler 员工月度汇总处理器
+type SummaryHandler struct {
+	store   *memstore.Store
+	service *summary.Service
+}
+
+// NewSummaryHandler 创建月度汇总处理器
+func NewSummaryHandler(store *memstore.Store, service *summary.Service) *SummaryHandler {
+	return &SummaryHandler{
+		store:   store,
+		service: service,
+	}
+}
+
+// DisputeRequest 汇总争议请求
+// date 与 assignment_id 均为空表示对整月汇总提出争议
+type DisputeRequest struct {
+	Date         string     `json:"date,omitempty"`
+	AssignmentID *uuid.UUID `json:"assignment_id,omitempty"`
+	Reason       string     `json:"reason"`
+}
+
+// ReviewDisputeRequest 争议审核请求
+type ReviewDisputeRequest struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note,omitempty"`
+}
+
+// EmployeeSummary 员工查看月度汇总
+// 路由: GET /api/v1/employees/{employee_id}/summaries/{month}
+func (h *SummaryHandler) EmployeeSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !h.ready(w) {
+		return
+	}
+	employeeID, ok := h.employee(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Summary(employeeID, r.PathValue("month"))
+	if err != nil {
+		respondSummaryError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, result)
+}
+
+// CreateDispute 员工对汇总记录提出争议，提交后通知管理者审核
+// 路由: POST /api/v1/employees/{employee_id}/summaries/{month}/disputes
+func (h *SummaryHandler) CreateDispute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if !h.ready(w) {
+		return
+	}
+	employeeID, ok := h.employee(w, r)
+	if !ok {
+		return
+	}
+
+	var req DisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	if req.Reason == "" {
+		respondError(w, errors.New(errors.CodeInvalidInput, "reason 不能为空"))
+		return
+	}
+
+	dispute, err := h.service.Dispute(r.Context(), employeeID, r.PathValue("month"), req.Date, req.AssignmentID, req.Reason)
+	if err != nil {
+		respondSummaryError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, dispute)
+}
+
+// ListDisputes 管理者查看待审核的争议
+// 未指定 org_id 时组织受限的调用方只查看所属组织的争议
+// 路由: GET /api/v1/summary-disputes?org_id=&status=
+func (h *SummaryHandler) ListDisputes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if !h.ready(w) || !requireManager(w, r) {
+		return
+	}
+
+	orgID, ok := listOrgID(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, h.store.ListDisputes(orgID, r.URL.Query().Get("status")))
+}
+
+// ReviewDispute 管理者审核争议，结果通知员工
+// 路由: POST /api/v1/summary-disputes/{id}/review
+func (h *SummaryHandler) ReviewDispute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if !h.ready(w) || !requireManager(w, r) {
+		return
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的争议ID格式"))
+		return
+	}
+	pending, err := h.store.GetDispute(id)
+	if err != nil {
+		respondSummaryError(w, err)
+		return
+	}
+	if !authorizeOrg(w, r, pending.OrgID) {
+		return
+	}
+
+	var req ReviewDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+
+	dispute, err := h.service.Review(r.Context(), id, req.Approve, r.Header.Get(AuthorHeader), req.Note)
+	if err != nil {
+		respondSummaryError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, dispute)
+}
+
+// Deliver 手动触发某月汇总推送（已推送的员工不会重复推送）
+// 推送全部组织的员工，组织受限的调用方无权触发
+// 路由: POST /api/v1/summaries/{month}/deliver
+func (h *SummaryHandler) Deliver(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	if !h.ready(w) || !requireManager(w, r) || !requireAllOrgs(w, r) {
+		return
+	}
+
+	month := r.PathValue("month")
+	count, err := h.service.Deliver(r.Context(), month)
+	if err != nil {
+		respondSummaryError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"month":     month,
+		"delivered": count,
+	})
+}
+
+// employee 解析路径中的员工ID，检查员工存在且调用方可访问员工所属的组织
+func (h *SummaryHandler) employee(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("employee_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式"))
+		return uuid.Nil, false
+	}
+	emp, err := h.store.GetEmployee(id)
+	if err != nil {
+		respondSummaryError(w, err)
+		return uuid.Nil, false
+	}
+	if !authorizeOrg(w, r, emp.OrgID) {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// ready 检查是否启用了排班存储
+func (h *SummaryHandler) ready(w http.ResponseWriter) bool {
+	if h.store == nil || h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// requireManager 检查调用方为管理者
+func requireManager(w http.ResponseWriter, r *http.Request) bool {
+	if !isManagerRequest(r) {
+		respondError(w, errors.New(errors.CodeForbidden, "仅管理者可操作"))
+		return false
+	}
+	return true
+}
+
+// respondSummaryError 将汇总服务错误转换为响应
+func respondSummaryError(w http.ResponseWriter, err error) {
+	switch {
+	case err == memstore.ErrNotFound:
+		respondError(w, errors.New(errors.CodeNotFound, "员工或争议不存在"))
+	case stderrors.Is(err, summary.ErrInvalidMonth):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	case stderrors.Is(err, summary.ErrEntryNotFound):
+		respondError(w, errors.New(errors.CodeNotFound, err.Error()))
+	case stderrors.Is(err, summary.ErrDisputeReviewed):
+		respondError(w, errors.New(errors.CodeAlreadyExists, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "处理月度汇总失败"))
+	}
+}
@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/swap"
+)
+
+// SwapHandler 换班处理器
+// 评估和应用已保存排班（内存存储）中两名员工之间的换班
+type SwapHandler struct {
+	store     *memstore.Store
+	schedules *ScheduleHandler               // 按排班生成时的约束配置构建评估上下文
+	draft     *DraftHandler                  // 换班通过草稿编辑器保存（版本控制和修订记录）
+	repo      *repository.ScheduleRepository // 配置数据库时同步换班后的分配
+	notifier  notify.Notifier                // 换班通过通知（可选）
+}
+
+// NewSwapHandler 创建换班处理器
+func NewSwapHandler(store *memstore.Store, schedules *ScheduleHandler, draft *DraftHandler) *SwapHandler {
+	return &SwapHandler{
+		store:     store,
+		schedules: schedules,
+		draft:     draft,
+	}
+}
+
+// SetScheduleRepository 设置排班仓储，设置后应用换班同步更新数据库中的分配
+func (h *SwapHandler) SetScheduleRepository(repo *repository.ScheduleRepository) {
+	h.repo = repo
+}
+
+// SetNotifier 设置通知发送器，设置后应用换班发送 swap_approved 通知
+func (h *SwapHandler) SetNotifier(notifier notify.Notifier) {
+	h.notifier = notifier
+}
+
+// SwapRequest 换班请求
+// 只给 target_employee_id 时为替班（源分配交给目标员工）；
+// 给 target_assignment_id 时为互换（两个分配的员工交换），目标员工取目标分配的员工
+type SwapRequest struct {
+	ScheduleID         string `json:"schedule_id"`
+	SourceAssignmentID string `json:"source_assignment_id"`
+	TargetEmployeeID   string `json:"target_employee_id,omitempty"`
+	TargetAssignmentID string `json:"target_assignment_id,omitempty"`
+	BaseVersion        *int   `json:"base_version,omitempty"` // 应用换班时基于的排班版本，也可使用 If-Match 请求头
+}
+
+// SwapApplyResponse 应用换班响应
+type SwapApplyResponse struct {
+	Schedule   *model.Schedule          `json:"schedule"`
+	Changes    []model.AssignmentChange `json:"changes"`
+	Evaluation *swap.SwapEvaluation     `json:"evaluation"`
+}
+
+// maxSwapCandidates 替班候选最多返回的数量
+const maxSwapCandidates = 50
+
+// SwapCandidatesRequest 替班候选请求
+type SwapCandidatesRequest struct {
+	ScheduleID        string `json:"schedule_id"`
+	AssignmentID      string `json:"assignment_id"`
+	Limit             int    `json:"limit,omitempty"`              // 默认 5，最多 50
+	IncludeInfeasible bool   `json:"include_infeasible,omitempty"` // 同时列出不可行的员工及冲突原因
+}
+
+// SwapCandidate 替班候选
+type SwapCandidate struct {
+	EmployeeID   string `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	Position     string `json:"position,omitempty"`
+	swap.Candidate
+}
+
+// SwapCandidatesResponse 替班候选响应
+type SwapCandidatesResponse struct {
+	ScheduleID   string          `json:"schedule_id"`
+	AssignmentID string          `json:"assignment_id"`
+	Date         string          `json:"date"`
+	Candidates   []SwapCandidate `json:"candidates"`
+}
+
+// swapPlan 解析后的换班
+type swapPlan struct {
+	schedule *model.Schedule
+	request  *swap.SwapRequest
+	eval     *swap.SwapEvaluation
+}
+
+// Evaluate 评估换班可行性和影响，不修改排班
+// 路由: POST /api/v1/swap/evaluate
+func (h *SwapHandler) Evaluate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	var req SwapRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	plan, appErr := h.plan(r.Context(), &req)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	respondJSON(w, http.StatusOK, plan.eval)
+}
+
+// Apply 评估换班，可行时应用到排班
+// 路由: POST /api/v1/swap/apply
+// 换班后的分配标记 is_swapped，original_employee_id 记录最初排班的员工；
+// 存在硬约束冲突时返回 400，排班不变
+func (h *SwapHandler) Apply(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	var req SwapRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	plan, appErr := h.plan(r.Context(), &req)
+	if appErr != nil {
+		respondError(w, appErr)
+		return
+	}
+	if !plan.eval.Feasible {
+		details := make([]string, 0, len(plan.eval.Issues))
+		for _, issue := range plan.eval.Issues {
+			if issue.Severity == "error" {
+				details = append(details, issue.Message)
+			}
+		}
+		respondError(w, errors.New(errors.CodeValidationFail, "换班不可行").WithDetails(strings.Join(details, "；")))
+		return
+	}
+
+	// 未指定基准版本时以评估所用的版本为准，评估后排班被修改则返回版本冲突
+	baseVersion := plan.schedule.Version
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseVersionETag(ifMatch)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的 If-Match"))
+			return
+		}
+		baseVersion = version
+	} else if req.BaseVersion != nil {
+		baseVersion = *req.BaseVersion
+	}
+
+	changes := swapChanges(plan.request)
+	schedule, err := h.draft.editor.Edit(plan.schedule.ID, baseVersion, r.Header.Get(AuthorHeader), changes)
+	if err != nil {
+		h.draft.respondEditError(w, plan.schedule.ID, err)
+		return
+	}
+	h.syncRecord(r, changes)
+	h.notifyApproved(schedule, plan.request, r.Header.Get(AuthorHeader))
+	recordAudit(w, r, schedule.OrgID, model.AuditSwapApply, "schedule", schedule.ID.String(), map[string]interface{}{
+		"version": schedule.Version,
+		"changes": changes,
+	})
+
+	w.Header().Set("ETag", versionETag(schedule.Version))
+	respondJSON(w, http.StatusOK, SwapApplyResponse{
+		Schedule:   schedule,
+		Changes:    changes,
+		Evaluation: plan.eval,
+	})
+}
+
+// Candidates 为要空出的分配推荐替班员工
+// 路由: POST /api/v1/swap/candidates
+// 候选为组织内除原员工外的在职员工，按接班评估的可行性、得分和本期已排工时排序
+func (h *SwapHandler) Candidates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	var req SwapCandidatesRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	if req.Limit < 0 || req.Limit > maxSwapCandidates {
+		respondError(w, errors.New(errors.CodeInvalidInput, fmt.Sprintf("limit 须在 0-%d 之间", maxSwapCandidates)))
+		return
+	}
+	scheduleID, err := uuid.Parse(req.ScheduleID)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式"))
+		return
+	}
+	assignmentID, err := uuid.Parse(req.AssignmentID)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的分配ID格式"))
+		return
+	}
+	schedule, err := h.store.GetSchedule(scheduleID)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+	if !authorizeOrg(w, r, schedule.OrgID) {
+		return
+	}
+	assignment := findAssignment(schedule, assignmentID)
+	if assignment == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "分配不存在"))
+		return
+	}
+
+	pool := make([]uuid.UUID, 0)
+	for _, emp := range h.store.ListEmployees(schedule.OrgID) {
+		pool = append(pool, emp.ID)
+	}
+	ctx, cm := h.schedules.storedContext(schedule, pool...)
+	candidates := swap.NewRecommender(cm).FindCandidates(ctx, assignment, &swap.CandidateOptions{
+		Limit:             req.Limit,
+		IncludeInfeasible: req.IncludeInfeasible,
+	})
+
+	resp := SwapCandidatesResponse{
+		ScheduleID:   schedule.ID.String(),
+		AssignmentID: assignment.ID.String(),
+		Date:         assignment.Date,
+		Candidates:   make([]SwapCandidate, 0, len(candidates)),
+	}
+	for _, c := range candidates {
+		resp.Candidates = append(resp.Candidates, SwapCandidate{
+			EmployeeID:   c.Employee.ID.String(),
+			EmployeeName: c.Employee.Name,
+			Position:     c.Employee.Position,
+			Candidate:    c,
+		})
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// decode 检查存储并解析请求
+func (h *SwapHandler) decode(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return false
+	}
+	return true
+}
+
+// plan 加载排班和分配并评估换班
+func (h *SwapHandler) plan(reqCtx context.Context, req *SwapRequest) (*swapPlan, *errors.AppError) {
+	scheduleID, err := uuid.Parse(req.ScheduleID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式")
+	}
+	sourceID, err := uuid.Parse(req.SourceAssignmentID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的源分配ID格式")
+	}
+	schedule, err := h.store.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, errors.New(errors.CodeNotFound, "排班不存在")
+	}
+	if appErr := orgAccessError(reqCtx, schedule.OrgID); appErr != nil {
+		return nil, appErr
+	}
+	source := findAssignment(schedule, sourceID)
+	if source == nil {
+		return nil, errors.New(errors.CodeNotFound, "源分配不存在")
+	}
+
+	var target *model.Assignment
+	var targetEmpID uuid.UUID
+	if req.TargetAssignmentID != "" {
+		id, err := uuid.Parse(req.TargetAssignmentID)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的目标分配ID格式")
+		}
+		if target = findAssignment(schedule, id); target == nil {
+			return nil, errors.New(errors.CodeNotFound, "目标分配不存在")
+		}
+		targetEmpID = target.EmployeeID
+	}
+	if req.TargetEmployeeID != "" {
+		id, err := uuid.Parse(req.TargetEmployeeID)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.CodeInvalidInput, "无效的目标员工ID格式")
+		}
+		if target != nil && id != targetEmpID {
+			return nil, errors.New(errors.CodeInvalidInput, "target_employee_id 与目标分配的员工不一致")
+		}
+		targetEmpID = id
+	}
+	if targetEmpID == uuid.Nil {
+		return nil, errors.New(errors.CodeInvalidInput, "需要 target_employee_id 或 target_assignment_id")
+	}
+	if targetEmpID == source.EmployeeID {
+		return nil, errors.New(errors.CodeInvalidInput, "目标员工与源分配的员工相同")
+	}
+	targetEmp, err := h.store.GetEmployee(targetEmpID)
+	if err != nil {
+		return nil, errors.New(errors.CodeNotFound, "目标员工不存在")
+	}
+
+	ctx, cm := h.schedules.storedContext(schedule, targetEmpID)
+	request := &swap.SwapRequest{
+		SourceAssignment: source,
+		TargetEmployee:   targetEmp,
+		TargetAssignment: target,
+	}
+	return &swapPlan{
+		schedule: schedule,
+		request:  request,
+		eval:     swap.NewSwapEvaluator(cm).EvaluateSwap(ctx, request),
+	}, nil
+}
+
+// syncRecord 将换班后的分配同步到数据库，数据库中没有对应分配时忽略
+func (h *SwapHandler) syncRecord(r *http.Request, changes []model.AssignmentChange) {
+	if h.repo == nil {
+		return
+	}
+	for _, c := range changes {
+		a := c.Assignment
+		record := &repository.ScheduleAssignment{
+			ID:                 a.ID,
+			EmployeeID:         a.EmployeeID,
+			IsSwapped:          a.IsSwapped,
+			OriginalEmployeeID: a.OriginalEmpID,
+		}
+		if emp, err := h.store.GetEmployee(a.EmployeeID); err == nil {
+			record.EmployeeName = emp.Name
+		}
+		h.repo.UpdateAssignment(r.Context(), record)
+	}
+}
+
+// notifyApproved 发送换班通过通知，失败只记录日志
+func (h *SwapHandler) notifyApproved(schedule *model.Schedule, req *swap.SwapRequest, author string) {
+	if h.notifier == nil {
+		return
+	}
+	source := req.SourceAssignment
+	data := map[string]interface{}{
+		"schedule_id":          schedule.ID,
+		"version":              schedule.Version,
+		"author":               author,
+		"source_assignment_id": source.ID,
+		"source_employee_id":   source.EmployeeID,
+		"target_employee_id":   req.TargetEmployee.ID,
+		"date":                 source.Date,
+	}
+	body := fmt.Sprintf("%s 的班次由 %s 替班", source.Date, req.TargetEmployee.Name)
+	if req.TargetAssignment != nil {
+		data["target_assignment_id"] = req.TargetAssignment.ID
+		body = fmt.Sprintf("%s 的班次与 %s（%s）互换", source.Date, req.TargetEmployee.Name, req.TargetAssignment.Date)
+	}
+	n := notify.New(notify.TypeSwapApproved, schedule.OrgID, "换班已通过", body, data)
+	if err := h.notifier.Notify(context.Background(), n); err != nil {
+		logger.Error().Err(err).Str("type", n.Type).Msg("发送换班通知失败")
+	}
+}
+
+// swapChanges 生成换班的分配变更
+// 已换过班的分配保留最初排班的员工
+func swapChanges(req *swap.SwapRequest) []model.AssignmentChange {
+	reassign := func(a *model.Assignment, empID uuid.UUID) model.AssignmentChange {
+		updated := *a
+		if updated.OriginalEmpID == nil {
+			original := a.EmployeeID
+			updated.OriginalEmpID = &original
+		}
+		updated.EmployeeID = empID
+		updated.IsSwapped = true
+		return model.AssignmentChange{Op: model.ChangeUpdate, AssignmentID: a.ID, Assignment: &updated}
+	}
+
+	changes := []model.AssignmentChange{reassign(req.SourceAssignment, req.TargetEmployee.ID)}
+	if req.TargetAssignment != nil {
+		changes = append(changes, reassign(req.TargetAssignment, req.SourceAssignment.EmployeeID))
+	}
+	return changes
+}
+
+// findAssignment 查找排班中的分配，返回副本
+func findAssignment(schedule *model.Schedule, id uuid.UUID) *model.Assignment {
+	for i := range schedule.Assignments {
+		if schedule.Assignments[i].ID == id {
+			a := schedule.Assignments[i]
+			return &a
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TeamHandler 班组处理器
+// 配置数据库时读写 TeamRepository，否则使用内存存储
+type TeamHandler struct {
+	repo  repository.TeamRepositoryInterface
+	store *memstore.Store
+}
+
+// NewTeamHandler 创建班组处理器，repo 为空时使用内存存储
+func NewTeamHandler(repo *repository.TeamRepository, store *memstore.Store) *TeamHandler {
+	h := &TeamHandler{store: store}
+	if repo != nil {
+		h.repo = repo
+	}
+	return h
+}
+
+// Teams 查询/替换组织的班组（替换需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/teams
+// PUT 请求体为完整的班组列表，会覆盖该组织已有班组；排班请求未提供 teams 时使用组织的班组
+func (h *TeamHandler) Teams(w http.ResponseWriter, r *http.Request) {
+	if h.repo == nil && h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		teams, err := h.list(r.Context(), orgID)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询班组失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, teams)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var teams []*model.Team
+		if err := json.NewDecoder(r.Body).Decode(&teams); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		list := make([]model.Team, len(teams))
+		now := time.Now()
+		for i, t := range teams {
+			if t == nil {
+				respondError(w, errors.New(errors.CodeInvalidInput, "班组不能为空"))
+				return
+			}
+			if t.Cohesion == "" {
+				t.Cohesion = model.TeamCohesionPreferred
+			}
+			t.OrgID, t.UpdatedAt = orgID, now
+			list[i] = *t
+		}
+		if err := model.ValidateTeams(list); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+
+		if h.repo != nil {
+			err = h.repo.Replace(r.Context(), orgID, teams)
+		} else {
+			err = h.store.ReplaceTeams(orgID, teams)
+		}
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "保存班组失败"))
+			return
+		}
+		saved, err := h.list(r.Context(), orgID)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "查询班组失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, saved)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// list 列出组织的班组
+func (h *TeamHandler) list(ctx context.Context, orgID uuid.UUID) ([]*model.Team, error) {
+	if h.repo != nil {
+		return h.repo.List(ctx, orgID)
+	}
+	return h.store.ListTeams(orgID), nil
+}
+
+// validateTeams 校验排班请求中的班组：班组配置有效，成员都在请求的员工中
+func validateTeams(req *GenerateRequest) *errors.AppError {
+	if len(req.Teams) == 0 {
+		return nil
+	}
+	if err := model.ValidateTeams(req.Teams); err != nil {
+		return errors.Wrap(err, errors.CodeInvalidInput, err.Error())
+	}
+	employees := make(map[uuid.UUID]bool, len(req.Employees))
+	for _, e := range req.Employees {
+		if id, err := uuid.Parse(e.ID); err == nil {
+			employees[id] = true
+		}
+	}
+	for _, t := range req.Teams {
+		for _, id := range t.Members {
+			if !employees[id] {
+				return errors.New(errors.CodeInvalidInput, fmt.Sprintf("班组 %s 的成员 %s 不在员工列表中", t.Label(), id))
+			}
+		}
+	}
+	return nil
+}
+
+// withTeams 将班组合并到约束配置，供班组约束和求解器按班组选人使用
+// 请求未提供 teams 时使用组织保存的班组（数据库优先，其次内存存储）；配置中已有 teams 时不覆盖
+func (h *ScheduleHandler) withTeams(ctx context.Context, orgID uuid.UUID, teams []model.Team, config map[string]interface{}) map[string]interface{} {
+	if _, ok := config["teams"]; ok {
+		return config
+	}
+	if len(teams) == 0 {
+		var stored []*model.Team
+		switch {
+		case h.teamRepo != nil:
+			var err error
+			if stored, err = h.teamRepo.List(ctx, orgID); err != nil {
+				logger.Error().Err(err).Str("org_id", orgID.String()).Msg("加载班组失败")
+			}
+		case h.store != nil:
+			stored = h.store.ListTeams(orgID)
+		}
+		for _, t := range stored {
+			teams = append(teams, *t)
+		}
+	}
+	if len(teams) == 0 {
+		return config
+	}
+	merged := make(map[string]interface{}, len(config)+1)
+	for k, v := range config {
+		merged[k] = v
+	}
+	merged["teams"] = teams
+	return merged
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/timebank"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TimeBankHandler 调休账户处理器
+type TimeBankHandler struct {
+	store   *memstore.Store
+	service *timebank.Service
+}
+
+// NewTimeBankHandler 创建调休账户处理器
+func NewTimeBankHandler(store *memstore.Store, service *timebank.Service) *TimeBankHandler {
+	return &TimeBankHandler{
+		store:   store,
+		service: service,
+	}
+}
+
+// TimeBankAdjustRequest 调休余额调整请求
+type TimeBankAdjustRequest struct {
+	EmployeeID uuid.UUID `json:"employee_id"`
+	Date       string    `json:"date,omitempty"` // 默认当天
+	Hours      float64   `json:"hours"`          // 正数增加、负数扣减
+	Note       string    `json:"note"`
+}
+
+// TimeBankDetail 员工调休余额及明细
+type TimeBankDetail struct {
+	*model.TimeBankBalance
+	Entries []*model.TimeBankEntry        `json:"entries"`
+	Leaves  []*model.EmployeeAvailability `json:"leaves"` // 使用调休的请假记录
+}
+
+// TimeBank 查询组织员工的调休余额
+// 路由: GET /api/v1/orgs/{org_id}/time-bank
+// 指定 employee_id 时返回该员工的余额、账户记录和使用调休的请假记录，否则返回有调休记录的员工余额列表
+func (h *TimeBankHandler) TimeBank(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	v := r.URL.Query().Get("employee_id")
+	if v == "" {
+		respondJSON(w, http.StatusOK, h.service.Balances(orgID))
+		return
+	}
+	employeeID, err := uuid.Parse(v)
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的员工ID格式"))
+		return
+	}
+	detail := &TimeBankDetail{
+		TimeBankBalance: h.service.Balance(employeeID),
+		Entries:         h.store.ListTimeBankEntries(orgID, employeeID, "", ""),
+		Leaves:          make([]*model.EmployeeAvailability, 0),
+	}
+	for _, av := range h.store.ListAvailability(employeeID, "", "") {
+		if av.TimeBankHours > 0 {
+			detail.Leaves = append(detail.Leaves, av)
+		}
+	}
+	respondJSON(w, http.StatusOK, detail)
+}
+
+// Adjust 手工调整员工调休余额（需管理者），如录入期初余额或清零
+// 路由: POST /api/v1/orgs/{org_id}/time-bank/adjustments
+func (h *TimeBankHandler) Adjust(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+	orgID, ok := h.orgID(w, r)
+	if !ok || !requireManager(w, r) {
+		return
+	}
+	var req TimeBankAdjustRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+	e, err := h.service.Adjust(orgID, req.EmployeeID, req.Date, req.Hours, req.Note, r.Header.Get(AuthorHeader))
+	if err != nil {
+		respondTimeBankError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"entry":   e,
+		"balance": h.service.Balance(req.EmployeeID),
+	})
+}
+
+// Policy 查询/设置组织调休策略（设置需管理者）
+// 路由: GET|PUT /api/v1/orgs/{org_id}/time-bank/policy
+// 策略只影响之后审批通过的加班，已结算的加班不会重新计算
+func (h *TimeBankHandler) Policy(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := h.orgID(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil || org.TimeBankPolicy == nil {
+			respondError(w, errors.New(errors.CodeNotFound, "组织未配置调休策略"))
+			return
+		}
+		respondJSON(w, http.StatusOK, org.TimeBankPolicy)
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var policy model.TimeBankPolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		if err := policy.Validate(); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, err.Error()))
+			return
+		}
+
+		org, err := h.store.GetOrganization(orgID)
+		if err != nil {
+			org = &model.Organization{BaseModel: model.NewBaseModel()}
+			org.ID = orgID
+		}
+		org.TimeBankPolicy = &policy
+		org.UpdatedAt = time.Now()
+		h.store.PutOrganization(org)
+		respondJSON(w, http.StatusOK, org.TimeBankPolicy)
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT方法"))
+	}
+}
+
+// orgID 检查存储是否启用并解析组织ID
+func (h *TimeBankHandler) orgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.store == nil || h.service == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(r.PathValue("org_id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
+
+// respondTimeBankError 将调休账户错误转换为响应
+func respondTimeBankError(w http.ResponseWriter, err error) {
+	switch {
+	case stderrors.Is(err, timebank.ErrInvalidEntry), stderrors.Is(err, timebank.ErrInsufficientBalance):
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+	default:
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "处理调休账户失败"))
+	}
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/errors"
+)
+
+// API v2 资源定义
+// v2 资源字段一经发布即保持稳定：只允许新增可选字段，不允许修改或删除已有字段。
+// 内部模型的变化由 compat.go 中的转换函数吸收。
+
+// APIVersionV2 v2 版本标识
+const APIVersionV2 = "v2"
+
+// 资源类型
+const (
+	KindSchedule   = "Schedule"
+	KindValidation = "Validation"
+	KindError      = "Error"
+)
+
+// 排班状态
+const (
+	ScheduleStatusComplete = "complete" // 所有需求均已满足
+	ScheduleStatusPartial  = "partial"  // 部分需求未满足
+	ScheduleStatusFailed   = "failed"   // 未生成任何分配
+)
+
+// 违反级别
+const (
+	ViolationLevelHard = "hard"
+	ViolationLevelSoft = "soft"
+)
+
+// ScheduleV2 排班资源
+type ScheduleV2 struct {
+	APIVersion  string         `json:"api_version"`
+	Kind        string         `json:"kind"`
+	ID          string         `json:"id"`
+	OrgID       string         `json:"org_id"`
+	Period      PeriodV2       `json:"period"`
+	Status      string         `json:"status"`              // complete/partial/failed
+	Lifecycle   string         `json:"lifecycle,omitempty"` // draft/published/archived
+	Message     string         `json:"message,omitempty"`
+	Summary     SummaryV2      `json:"summary"`
+	Assignments []AssignmentV2 `json:"assignments"`
+	Unfilled    []UnfilledV2   `json:"unfilled"`
+	Violations  []ViolationV2  `json:"violations"`
+	Warnings    []WarningV2    `json:"warnings"`
+	DurationMs  int64          `json:"duration_ms"`
+}
+
+// PeriodV2 排班周期
+type PeriodV2 struct {
+	Start string `json:"start"` // YYYY-MM-DD
+	End   string `json:"end"`   // YYYY-MM-DD
+}
+
+// SummaryV2 排班汇总
+type SummaryV2 struct {
+	Valid              bool    `json:"valid"`
+	ConstraintScore    float64 `json:"constraint_score"`
+	TotalAssignments   int     `json:"total_assignments"`
+	FilledRequirements int     `json:"filled_requirements"`
+	TotalRequirements  int     `json:"total_requirements"`
+	FillRate           float64 `json:"fill_rate"` // 需求满足率 (%)
+	TotalHours         float64 `json:"total_hours"`
+}
+
+// AssignmentV2 分配资源
+type AssignmentV2 struct {
+	ID           string  `json:"id"`
+	EmployeeID   string  `json:"employee_id"`
+	EmployeeName string  `json:"employee_name,omitempty"`
+	ShiftID      string  `json:"shift_id"`
+	ShiftName    string  `json:"shift_name,omitempty"`
+	Date         string  `json:"date"`       // YYYY-MM-DD
+	StartTime    string  `json:"start_time"` // HH:MM
+	EndTime      string  `json:"end_time"`   // HH:MM
+	Position     string  `json:"position,omitempty"`
+	Hours        float64 `json:"hours"`
+	Score        float64 `json:"score"` // 0-100
+}
+
+// UnfilledV2 未满足的需求
+type UnfilledV2 struct {
+	ShiftID  string `json:"shift_id"`
+	Date     string `json:"date"`
+	Position string `json:"position,omitempty"`
+	Required int    `json:"required"`
+	Assigned int    `json:"assigned"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ViolationV2 约束违反资源
+type ViolationV2 struct {
+	Constraint string `json:"constraint"` // 约束类型标识
+	Name       string `json:"name"`       // 约束名称
+	Level      string `json:"level"`      // hard/soft
+	EmployeeID string `json:"employee_id,omitempty"`
+	Date       string `json:"date,omitempty"`
+	Message    string `json:"message"`
+	Penalty    int    `json:"penalty"`
+}
+
+// WarningV2 警告（补员建议、异常检测等）
+type WarningV2 struct {
+	Type       string `json:"type"`
+	Severity   string `json:"severity"` // info/warning/critical
+	EmployeeID string `json:"employee_id,omitempty"`
+	Message    string `json:"message"`
+}
+
+// ValidationV2 验证结果资源
+type ValidationV2 struct {
+	APIVersion string        `json:"api_version"`
+	Kind       string        `json:"kind"`
+	Valid      bool          `json:"valid"`
+	Score      float64       `json:"score"`
+	Violations []ViolationV2 `json:"violations"`
+}
+
+// ErrorV2 错误资源
+type ErrorV2 struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Details    string `json:"details,omitempty"`
+}
+
+// GenerateV2 生成排班（v2）
+// 请求体与 v1 相同，响应为 ScheduleV2 资源
+func (h *ScheduleHandler) GenerateV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondErrorV2(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+
+	var req GenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorV2(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+
+	if err := h.withStoredEmployees(r.Context(), &req); err != nil {
+		respondErrorV2(w, err)
+		return
+	}
+	if err := h.withStoredShifts(r.Context(), &req); err != nil {
+		respondErrorV2(w, err)
+		return
+	}
+	if err := h.withStoredRequirements(r.Context(), &req); err != nil {
+		respondErrorV2(w, err)
+		return
+	}
+	if err := validateGenerateRequest(&req); err != nil {
+		respondErrorV2(w, err)
+		return
+	}
+
+	resp, appErr := h.generate(r.Context(), &req)
+	if appErr != nil {
+		respondErrorV2(w, appErr)
+		return
+	}
+
+	respondJSONV2(w, http.StatusOK, toScheduleV2(&req, resp))
+}
+
+// ValidateV2 验证排班（v2）
+func (h *ScheduleHandler) ValidateV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondErrorV2(w, errors.New(errors.CodeInvalidInput, "仅支持POST方法"))
+		return
+	}
+
+	var req ValidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorV2(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+		return
+	}
+
+	resp, appErr := h.validate(&req)
+	if appErr != nil {
+		respondErrorV2(w, appErr)
+		return
+	}
+
+	respondJSONV2(w, http.StatusOK, toValidationV2(resp))
+}
+
+// GetScheduleV2 获取已保存的排班（v2），需启用内存状态存储
+// 路由: GET /api/v2/schedules/{id}
+func (h *ScheduleHandler) GetScheduleV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondErrorV2(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	if h.store == nil {
+		respondErrorV2(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondErrorV2(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的排班ID格式"))
+		return
+	}
+
+	schedule, err := h.store.GetSchedule(id)
+	if err != nil {
+		respondErrorV2(w, errors.New(errors.CodeNotFound, "排班不存在"))
+		return
+	}
+	if appErr := orgAccessError(r.Context(), schedule.OrgID); appErr != nil {
+		respondErrorV2(w, appErr)
+		return
+	}
+
+	respondJSONV2(w, http.StatusOK, scheduleFromModelV2(schedule))
+}
+
+// respondJSONV2 返回 v2 JSON 响应
+func respondJSONV2(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("API-Version", APIVersionV2)
+	respondJSON(w, status, data)
+}
+
+// respondErrorV2 返回 v2 错误响应
+func respondErrorV2(w http.ResponseWriter, err *errors.AppError) {
+	respondJSONV2(w, err.HTTPStatus, ErrorV2{
+		APIVersion: APIVersionV2,
+		Kind:       KindError,
+		Code:       string(err.Code),
+		Message:    err.Message,
+		Details:    err.Details,
+	})
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/errors"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// WebhookHandler 通知订阅处理器
+// 组织订阅排班发布、分配修改、换班通过、缺员等通知事件，事件发生时投递到 Webhook 或邮件
+type WebhookHandler struct {
+	store *memstore.Store
+}
+
+// NewWebhookHandler 创建通知订阅处理器
+func NewWebhookHandler(store *memstore.Store) *WebhookHandler {
+	return &WebhookHandler{store: store}
+}
+
+// WebhookInput 通知订阅的新增和修改请求，修改时只更新给出的字段
+type WebhookInput struct {
+	OrgID      string    `json:"org_id,omitempty"` // 仅新增时使用
+	Name       *string   `json:"name,omitempty"`
+	Channel    *string   `json:"channel,omitempty"` // webhook/email，默认 webhook
+	URL        *string   `json:"url,omitempty"`
+	Secret     *string   `json:"secret,omitempty"` // 签名密钥，传空字符串清除
+	Recipients *[]string `json:"recipients,omitempty"`
+	Events     *[]string `json:"events,omitempty"` // 通知类型，* 表示全部
+	Template   *string   `json:"template,omitempty"`
+	Active     *bool     `json:"active,omitempty"` // 默认 true
+}
+
+// WebhookView 通知订阅响应（不返回签名密钥）
+type WebhookView struct {
+	*model.Webhook
+	Secret    string `json:"secret,omitempty"`
+	HasSecret bool   `json:"has_secret"`
+}
+
+// WebhookListResponse 通知订阅列表响应
+type WebhookListResponse struct {
+	Webhooks []WebhookView `json:"webhooks"`
+	Total    int           `json:"total"`
+	Events   []string      `json:"events"` // 可订阅的通知类型
+}
+
+// WebhookDeliveryListResponse 投递记录列表响应
+type WebhookDeliveryListResponse struct {
+	Deliveries []*model.WebhookDelivery `json:"deliveries"`
+	Total      int                      `json:"total"`
+}
+
+// Collection 列出或新增通知订阅
+// 路由: GET /api/v1/webhooks?org_id=
+// 路由: POST /api/v1/webhooks
+func (h *WebhookHandler) Collection(w http.ResponseWriter, r *http.Request) {
+	if !h.ready(w) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		orgID, err := uuid.Parse(r.URL.Query().Get("org_id"))
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		hooks := h.store.ListWebhooks(orgID)
+		views := make([]WebhookView, len(hooks))
+		for i, hook := range hooks {
+			views[i] = webhookView(hook)
+		}
+		respondJSON(w, http.StatusOK, WebhookListResponse{Webhooks: views, Total: len(views), Events: notify.Types()})
+
+	case http.MethodPost:
+		if !requireManager(w, r) {
+			return
+		}
+		var input WebhookInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		orgID, err := uuid.Parse(input.OrgID)
+		if err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的组织ID格式"))
+			return
+		}
+		hook := &model.Webhook{
+			BaseModel: model.NewBaseModel(),
+			OrgID:     orgID,
+			Channel:   model.ChannelWebhook,
+			Active:    true,
+		}
+		input.apply(hook)
+		if !h.save(w, hook) {
+			return
+		}
+		respondJSON(w, http.StatusCreated, webhookView(hook))
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/POST方法"))
+	}
+}
+
+// Item 获取、修改或删除通知订阅
+// 路由: GET|PUT|DELETE /api/v1/webhooks/{id}
+func (h *WebhookHandler) Item(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.webhook(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, webhookView(hook))
+
+	case http.MethodPut:
+		if !requireManager(w, r) {
+			return
+		}
+		var input WebhookInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "解析请求失败"))
+			return
+		}
+		input.apply(hook)
+		hook.UpdatedAt = time.Now()
+		if !h.save(w, hook) {
+			return
+		}
+		respondJSON(w, http.StatusOK, webhookView(hook))
+
+	case http.MethodDelete:
+		if !requireManager(w, r) {
+			return
+		}
+		if err := h.store.DeleteWebhook(hook.ID); err != nil {
+			respondError(w, errors.Wrap(err, errors.CodeInternal, "删除通知订阅失败"))
+			return
+		}
+		respondJSON(w, http.StatusOK, map[string]interface{}{
+			"deleted": true,
+			"id":      hook.ID.String(),
+		})
+
+	default:
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET/PUT/DELETE方法"))
+	}
+}
+
+// Deliveries 查询通知订阅最近的投递记录（按时间倒序）
+// 路由: GET /api/v1/webhooks/{id}/deliveries
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondError(w, errors.New(errors.CodeInvalidInput, "仅支持GET方法"))
+		return
+	}
+	hook, ok := h.webhook(w, r)
+	if !ok {
+		return
+	}
+	deliveries := h.store.ListWebhookDeliveries(hook.ID)
+	respondJSON(w, http.StatusOK, WebhookDeliveryListResponse{Deliveries: deliveries, Total: len(deliveries)})
+}
+
+// apply 将请求中给出的字段写入通知订阅
+func (in *WebhookInput) apply(hook *model.Webhook) {
+	if in.Name != nil {
+		hook.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Channel != nil {
+		hook.Channel = strings.TrimSpace(*in.Channel)
+	}
+	if in.URL != nil {
+		hook.URL = strings.TrimSpace(*in.URL)
+	}
+	if in.Secret != nil {
+		hook.Secret = *in.Secret
+	}
+	if in.Recipients != nil {
+		hook.Recipients = *in.Recipients
+	}
+	if in.Events != nil {
+		hook.Events = *in.Events
+	}
+	if in.Template != nil {
+		hook.Template = *in.Template
+	}
+	if in.Active != nil {
+		hook.Active = *in.Active
+	}
+}
+
+// save 校验并保存通知订阅：事件须为已知的通知类型，模板须能解析
+func (h *WebhookHandler) save(w http.ResponseWriter, hook *model.Webhook) bool {
+	if err := hook.Validate(); err != nil {
+		respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+		return false
+	}
+	for _, event := range hook.Events {
+		if event != model.WebhookAllEvents && !notify.KnownType(event) {
+			respondError(w, errors.New(errors.CodeInvalidInput, "未知的通知类型: "+event).
+				WithDetails("可订阅: "+strings.Join(notify.Types(), ", ")))
+			return false
+		}
+	}
+	if hook.Template != "" {
+		if _, err := notify.ParseTemplate(hook.Template); err != nil {
+			respondError(w, errors.New(errors.CodeInvalidInput, err.Error()))
+			return false
+		}
+	}
+	if err := h.store.PutWebhook(hook); err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInternal, "保存通知订阅失败"))
+		return false
+	}
+	return true
+}
+
+// webhook 解析路径中的订阅ID并获取通知订阅，检查调用方可访问订阅所属的组织
+func (h *WebhookHandler) webhook(w http.ResponseWriter, r *http.Request) (*model.Webhook, bool) {
+	if !h.ready(w) {
+		return nil, false
+	}
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		respondError(w, errors.Wrap(err, errors.CodeInvalidInput, "无效的通知订阅ID格式"))
+		return nil, false
+	}
+	hook, err := h.store.GetWebhook(id)
+	if err != nil {
+		respondError(w, errors.New(errors.CodeNotFound, "通知订阅不存在"))
+		return nil, false
+	}
+	if !authorizeOrg(w, r, hook.OrgID) {
+		return nil, false
+	}
+	return hook, true
+}
+
+// ready 检查是否启用了存储
+func (h *WebhookHandler) ready(w http.ResponseWriter) bool {
+	if h.store == nil {
+		respondError(w, errors.New(errors.CodeNotFound, "未启用排班存储"))
+		return false
+	}
+	return true
+}
+
+// webhookView 隐去签名密钥
+func webhookView(hook *model.Webhook) WebhookView {
+	return WebhookView{Webhook: hook, HasSecret: hook.Secret != ""}
+}
@@ -0,0 +1,474 @@
+// Package hrsync 提供外部 HR 系统员工主数据同步
+// HR 系统通过 Webhook 推送入职、变更、调岗、离职事件，按来源配置的字段映射写入员工数据；
+// 接收按来源限流并进入有界队列，由后台任务匀速处理；无法自动处理的事件记录为冲突，
+// 员工停用时自动取消其在草稿排班中的未来分配，并为受影响的分配生成替补建议
+package hrsync
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/draft"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/security"
+	"github.com/paiban/paiban/pkg/alias"
+	"github.com/paiban/paiban/pkg/fatigue"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 默认配置
+const (
+	DefaultRateLimit = 600  // 每个来源每分钟最多接收的事件数
+	DefaultQueueSize = 1000 // 待处理事件队列容量
+	maxCandidates    = 3    // 每条修复建议的替补人数
+)
+
+// 冲突原因
+const (
+	ConflictInvalid          = "invalid"           // 事件缺少必要字段
+	ConflictUnknownEmployee  = "unknown_employee"  // 员工不存在
+	ConflictDuplicateHire    = "duplicate_hire"    // 员工已在职
+	ConflictOutdated         = "outdated"          // 本地记录在事件发生后已被修改
+	ConflictUnmappedPosition = "unmapped_position" // 岗位未配置映射
+	ConflictUnmappedStatus   = "unmapped_status"   // 状态未配置映射
+)
+
+var (
+	// ErrUnknownSource 来源未配置字段映射
+	ErrUnknownSource = errors.New("来源未配置字段映射")
+	// ErrThrottled 超过来源限流
+	ErrThrottled = errors.New("超过同步限流")
+	// ErrQueueFull 待处理队列已满
+	ErrQueueFull = errors.New("同步队列已满")
+)
+
+// Syncer HR 同步器
+type Syncer struct {
+	store  *memstore.Store
+	editor *draft.Editor
+	queue  chan *model.HREvent
+	now    func() time.Time
+
+	mu       sync.Mutex
+	limiters map[string]*limiter
+}
+
+// limiter 来源限流器（限额变化时重建）
+type limiter struct {
+	limit int
+	rl    *security.RateLimiter
+}
+
+// NewSyncer 创建 HR 同步器
+func NewSyncer(store *memstore.Store, queueSize int) *Syncer {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	return &Syncer{
+		store:    store,
+		editor:   draft.NewEditor(store),
+		queue:    make(chan *model.HREvent, queueSize),
+		now:      time.Now,
+		limiters: make(map[string]*limiter),
+	}
+}
+
+// VerifySignature 校验 Webhook 签名（请求体的 HMAC-SHA256 十六进制值）
+// 映射未配置密钥时不校验
+func VerifySignature(mapping *model.HRMapping, body []byte, signature string) bool {
+	if mapping.Secret == "" {
+		return true
+	}
+	mac := hmac.New(sha256.New, []byte(mapping.Secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(strings.TrimPrefix(signature, "sha256=")), []byte(expected))
+}
+
+// Ingest 接收来源推送的事件，返回入队数量
+// 超过来源限流返回 ErrThrottled，队列已满返回 ErrQueueFull，此前的事件已入队
+func (s *Syncer) Ingest(source string, events []*model.HREvent) (int, error) {
+	mapping, err := s.store.GetHRMapping(source)
+	if err != nil {
+		return 0, ErrUnknownSource
+	}
+	rl := s.limiter(mapping)
+
+	accepted := 0
+	for _, event := range events {
+		if !rl.Allow(source) {
+			return accepted, ErrThrottled
+		}
+		event.Source = source
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = s.now()
+		}
+		select {
+		case s.queue <- event:
+			accepted++
+		default:
+			return accepted, ErrQueueFull
+		}
+	}
+	return accepted, nil
+}
+
+// Pending 返回待处理事件数
+func (s *Syncer) Pending() int {
+	return len(s.queue)
+}
+
+// Run 以每秒 ratePerSecond 个事件的速度处理队列，直到 ctx 取消
+func (s *Syncer) Run(ctx context.Context, ratePerSecond int) {
+	if ratePerSecond <= 0 {
+		ratePerSecond = 1
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(ratePerSecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-s.queue:
+			if err := s.Process(event); err != nil {
+				logger.Error().Err(err).Str("source", event.Source).Str("event_id", event.EventID).Msg("处理 HR 同步事件失败")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// Process 处理单个事件，无法自动处理的事件记录为冲突
+func (s *Syncer) Process(event *model.HREvent) error {
+	mapping, err := s.store.GetHRMapping(event.Source)
+	if err != nil {
+		return ErrUnknownSource
+	}
+
+	idField := mapping.IDField
+	if idField == "" {
+		idField = "employee_id"
+	}
+	externalID := stringValue(event.Data[idField])
+	if externalID == "" {
+		return s.conflict(event, "", nil, ConflictInvalid, fmt.Sprintf("缺少员工编号字段 %s", idField))
+	}
+
+	emp := s.findEmployee(mapping.OrgID, externalID)
+	if emp == nil && event.Type != model.HREventHire {
+		return s.conflict(event, externalID, nil, ConflictUnknownEmployee, "员工不存在")
+	}
+	if emp != nil && emp.UpdatedAt.After(event.OccurredAt) {
+		return s.conflict(event, externalID, &emp.ID, ConflictOutdated,
+			fmt.Sprintf("本地记录于 %s 修改，晚于事件时间 %s", emp.UpdatedAt.Format(time.RFC3339), event.OccurredAt.Format(time.RFC3339)))
+	}
+
+	wasActive := emp != nil && isActive(emp)
+	switch event.Type {
+	case model.HREventHire:
+		if wasActive {
+			return s.conflict(event, externalID, &emp.ID, ConflictDuplicateHire, "员工已在职")
+		}
+		if emp == nil {
+			emp = &model.Employee{BaseModel: model.NewBaseModel(), OrgID: mapping.OrgID, Code: externalID}
+		}
+		emp.Status = "active"
+	case model.HREventUpdate, model.HREventPositionChange:
+	case model.HREventTerminate:
+		emp.Status = "inactive"
+	default:
+		return s.conflict(event, externalID, &emp.ID, ConflictInvalid, fmt.Sprintf("未知事件类型 %q", event.Type))
+	}
+
+	if reason, detail := applyFields(mapping, event, emp); reason != "" {
+		return s.conflict(event, externalID, &emp.ID, reason, detail)
+	}
+	if emp.Name == "" {
+		return s.conflict(event, externalID, &emp.ID, ConflictInvalid, "缺少员工姓名")
+	}
+
+	// 岗位/技能按组织别名归一化，未映射的标签记录供管理员补充映射
+	norm := alias.NewNormalizer(mapping.OrgID, s.store.ListAliases(mapping.OrgID))
+	norm.Employee(emp)
+	s.store.RecordUnmapped(mapping.OrgID, norm.Unmapped())
+
+	emp.UpdatedAt = event.OccurredAt
+	if err := s.store.PutEmployee(emp); err != nil {
+		return err
+	}
+
+	if wasActive && !isActive(emp) {
+		effective := event.OccurredAt.Format("2006-01-02")
+		if v := stringValue(event.Data["effective_date"]); v != "" {
+			effective = v
+		}
+		s.propagateDeactivation(emp, effective)
+	}
+	return nil
+}
+
+// propagateDeactivation 取消停用员工在草稿中的未来分配，并为受影响分配生成替补建议
+func (s *Syncer) propagateDeactivation(emp *model.Employee, effective string) {
+	employees := s.store.ListEmployees(emp.OrgID)
+	for _, schedule := range s.store.ListSchedules(emp.OrgID) {
+		if schedule.Status != "draft" && schedule.Status != "published" {
+			continue
+		}
+		var cancels []model.AssignmentChange
+		var suggestions []*model.RepairSuggestion
+		for _, a := range schedule.Assignments {
+			if a.EmployeeID != emp.ID || a.Date < effective || a.Status == "cancelled" {
+				continue
+			}
+			suggestions = append(suggestions, &model.RepairSuggestion{
+				ID:           uuid.New(),
+				OrgID:        emp.OrgID,
+				EmployeeID:   emp.ID,
+				ScheduleID:   schedule.ID,
+				AssignmentID: a.ID,
+				ShiftID:      a.ShiftID,
+				Date:         a.Date,
+				Reason:       fmt.Sprintf("员工 %s 自 %s 起停用", emp.Name, effective),
+				Candidates:   repairCandidates(emp, a.Date, schedule, employees),
+				CreatedAt:    s.now(),
+			})
+			if schedule.Status == "draft" {
+				cancelled := a
+				cancelled.Status = "cancelled"
+				cancelled.Notes = "员工已停用（HR 同步）"
+				cancels = append(cancels, model.AssignmentChange{Op: model.ChangeUpdate, AssignmentID: a.ID, Assignment: &cancelled})
+			}
+		}
+
+		cancelled := false
+		if len(cancels) > 0 {
+			_, err := s.editor.Edit(schedule.ID, schedule.Version, "hrsync", cancels)
+			if err != nil {
+				logger.Error().Err(err).Str("schedule_id", schedule.ID.String()).Msg("取消停用员工的草稿分配失败")
+			}
+			cancelled = err == nil
+		}
+		for _, suggestion := range suggestions {
+			suggestion.Cancelled = cancelled && schedule.Status == "draft"
+			s.store.AddRepairSuggestion(suggestion)
+		}
+	}
+}
+
+// repairCandidates 选择替补员工：在职、当天在该排班中无分配，同岗位优先，高疲劳者靠后，分配少者优先
+func repairCandidates(emp *model.Employee, date string, schedule *model.Schedule, employees []*model.Employee) []model.RepairCandidate {
+	load := make(map[uuid.UUID]int)
+	busy := make(map[uuid.UUID]bool)
+	byEmployee := make(map[uuid.UUID][]*model.Assignment)
+	for i := range schedule.Assignments {
+		a := &schedule.Assignments[i]
+		if a.Status == "cancelled" {
+			continue
+		}
+		load[a.EmployeeID]++
+		byEmployee[a.EmployeeID] = append(byEmployee[a.EmployeeID], a)
+		if a.Date == date {
+			busy[a.EmployeeID] = true
+		}
+	}
+
+	pool := make([]*model.Employee, 0)
+	for _, e := range employees {
+		if e.ID == emp.ID || !isActive(e) || busy[e.ID] {
+			continue
+		}
+		pool = append(pool, e)
+	}
+
+	scorer := fatigue.NewScorer(fatigue.Config{})
+	day, _ := time.Parse("2006-01-02", date)
+	tiredness := make(map[uuid.UUID]fatigue.Index, len(pool))
+	for _, e := range pool {
+		tiredness[e.ID] = scorer.Score(e.ID, byEmployee[e.ID], day)
+	}
+
+	samePosition := func(e *model.Employee) bool { return emp.Position != "" && e.Position == emp.Position }
+	tired := func(e *model.Employee) bool { return tiredness[e.ID].Level == fatigue.LevelHigh }
+	sort.SliceStable(pool, func(i, j int) bool {
+		if samePosition(pool[i]) != samePosition(pool[j]) {
+			return samePosition(pool[i])
+		}
+		if tired(pool[i]) != tired(pool[j]) {
+			return !tired(pool[i])
+		}
+		return load[pool[i].ID] < load[pool[j].ID]
+	})
+
+	candidates := make([]model.RepairCandidate, 0, maxCandidates)
+	for _, e := range pool {
+		if len(candidates) >= maxCandidates {
+			break
+		}
+		idx := tiredness[e.ID]
+		reason := fmt.Sprintf("当天空闲，本期已排 %d 班", load[e.ID])
+		if samePosition(e) {
+			reason = "同岗位，" + reason
+		}
+		if idx.Level == fatigue.LevelHigh {
+			reason += fmt.Sprintf("，疲劳指数偏高（%.0f）", idx.Score)
+		}
+		candidates = append(candidates, model.RepairCandidate{
+			EmployeeID:   e.ID,
+			Name:         e.Name,
+			Position:     e.Position,
+			Reason:       reason,
+			FatigueScore: idx.Score,
+			FatigueLevel: idx.Level,
+		})
+	}
+	return candidates
+}
+
+// applyFields 按映射将事件字段写入员工，返回冲突原因和说明
+func applyFields(mapping *model.HRMapping, event *model.HREvent, emp *model.Employee) (string, string) {
+	for field, key := range mapping.Fields {
+		raw, ok := event.Data[key]
+		if !ok {
+			continue
+		}
+		value := stringValue(raw)
+		switch field {
+		case "name":
+			emp.Name = value
+		case "phone":
+			emp.Phone = value
+		case "email":
+			emp.Email = value
+		case "hire_date":
+			emp.HireDate = value
+		case "position":
+			if len(mapping.PositionMap) > 0 {
+				mapped, ok := mapping.PositionMap[value]
+				if !ok {
+					return ConflictUnmappedPosition, fmt.Sprintf("岗位 %q 未配置映射", value)
+				}
+				value = mapped
+			}
+			emp.Position = value
+		case "status":
+			if event.Type == model.HREventTerminate {
+				continue
+			}
+			if mapped, ok := mapping.StatusMap[value]; ok {
+				value = mapped
+			}
+			switch value {
+			case "active", "inactive", "leave":
+				emp.Status = value
+			default:
+				return ConflictUnmappedStatus, fmt.Sprintf("状态 %q 未配置映射", value)
+			}
+		case "skills":
+			emp.Skills = stringList(raw)
+		}
+	}
+	return "", ""
+}
+
+// conflict 记录冲突
+func (s *Syncer) conflict(event *model.HREvent, externalID string, employeeID *uuid.UUID, reason, detail string) error {
+	logger.Warn().
+		Str("source", event.Source).
+		Str("event_id", event.EventID).
+		Str("reason", reason).
+		Msg("HR 同步冲突")
+	return s.store.AddHRConflict(&model.HRSyncConflict{
+		ID:         uuid.New(),
+		Source:     event.Source,
+		EventID:    event.EventID,
+		ExternalID: externalID,
+		EmployeeID: employeeID,
+		Reason:     reason,
+		Detail:     detail,
+		Event:      *event,
+		CreatedAt:  s.now(),
+	})
+}
+
+// findEmployee 按外部员工编号（code）查找员工
+func (s *Syncer) findEmployee(orgID uuid.UUID, code string) *model.Employee {
+	for _, emp := range s.store.ListEmployees(orgID) {
+		if emp.Code == code {
+			return emp
+		}
+	}
+	return nil
+}
+
+// limiter 返回来源的限流器
+func (s *Syncer) limiter(mapping *model.HRMapping) *security.RateLimiter {
+	limit := mapping.RateLimit
+	if limit <= 0 {
+		limit = DefaultRateLimit
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.limiters[mapping.Source]
+	if !ok || l.limit != limit {
+		l = &limiter{limit: limit, rl: security.NewRateLimiter(limit, time.Minute)}
+		s.limiters[mapping.Source] = l
+	}
+	return l.rl
+}
+
+// isActive 未设置状态的员工视为在职
+func isActive(emp *model.Employee) bool {
+	return emp.Status == "" || emp.IsActive()
+}
+
+// stringValue 将 JSON 值转换为字符串
+func stringValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(val)
+	case float64:
+		if val == float64(int64(val)) {
+			return fmt.Sprintf("%d", int64(val))
+		}
+		return fmt.Sprintf("%g", val)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// stringList 将 JSON 数组或逗号分隔字符串转换为字符串列表
+func stringList(v interface{}) []string {
+	var result []string
+	switch val := v.(type) {
+	case []interface{}:
+		for _, item := range val {
+			if s := stringValue(item); s != "" {
+				result = append(result, s)
+			}
+		}
+	case string:
+		for _, item := range strings.Split(val, ",") {
+			if s := strings.TrimSpace(item); s != "" {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package hrsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func newTestSyncer(t *testing.T, rateLimit int) (*Syncer, uuid.UUID) {
+	t.Helper()
+	store := memstore.New("")
+	orgID := uuid.New()
+	store.PutHRMapping(&model.HRMapping{
+		Source:      "hr",
+		OrgID:       orgID,
+		IDField:     "worker_id",
+		Fields:      map[string]string{"name": "full_name", "position": "job"},
+		PositionMap: map[string]string{"CK": "cook"},
+		RateLimit:   rateLimit,
+	})
+	return NewSyncer(store, 10), orgID
+}
+
+func event(eventType, workerID string, data map[string]interface{}) *model.HREvent {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["worker_id"] = workerID
+	return &model.HREvent{Source: "hr", EventID: uuid.NewString(), Type: eventType, OccurredAt: time.Now(), Data: data}
+}
+
+func TestSyncer_HireAndConflicts(t *testing.T) {
+	s, orgID := newTestSyncer(t, 0)
+
+	if err := s.Process(event(model.HREventHire, "E1", map[string]interface{}{"full_name": "张三", "job": "CK"})); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	emp := s.findEmployee(orgID, "E1")
+	if emp == nil || emp.Name != "张三" || emp.Position != "cook" || emp.Status != "active" {
+		t.Fatalf("入职应创建员工, got %+v", emp)
+	}
+
+	s.Process(event(model.HREventPositionChange, "E1", map[string]interface{}{"job": "XX"}))
+	s.Process(event(model.HREventUpdate, "E404", nil))
+	s.Process(event(model.HREventHire, "E1", map[string]interface{}{"full_name": "张三"}))
+
+	reasons := make(map[string]bool)
+	for _, c := range s.store.ListHRConflicts("hr") {
+		reasons[c.Reason] = true
+	}
+	for _, r := range []string{ConflictUnmappedPosition, ConflictUnknownEmployee, ConflictDuplicateHire} {
+		if !reasons[r] {
+			t.Errorf("缺少冲突 %s, got %v", r, reasons)
+		}
+	}
+}
+
+func TestSyncer_TerminatePropagates(t *testing.T) {
+	s, orgID := newTestSyncer(t, 0)
+	s.Process(event(model.HREventHire, "E1", map[string]interface{}{"full_name": "张三", "job": "CK"}))
+	s.Process(event(model.HREventHire, "E2", map[string]interface{}{"full_name": "李四", "job": "CK"}))
+	leaving, backup := s.findEmployee(orgID, "E1"), s.findEmployee(orgID, "E2")
+
+	schedule := &model.Schedule{BaseModel: model.NewBaseModel(), OrgID: orgID, Status: "draft", Version: 1}
+	for _, date := range []string{"2026-01-19", "2026-01-21"} {
+		schedule.Assignments = append(schedule.Assignments, model.Assignment{
+			BaseModel: model.NewBaseModel(), EmployeeID: leaving.ID, ShiftID: uuid.New(), Date: date, Status: "scheduled",
+		})
+	}
+	s.store.PutSchedule(schedule)
+
+	s.Process(event(model.HREventTerminate, "E1", map[string]interface{}{"effective_date": "2026-01-20"}))
+
+	saved, _ := s.store.GetSchedule(schedule.ID)
+	if saved.Assignments[0].Status == "cancelled" || saved.Assignments[1].Status != "cancelled" {
+		t.Errorf("仅生效日之后的草稿分配应取消, got %s/%s", saved.Assignments[0].Status, saved.Assignments[1].Status)
+	}
+	if saved.Version != 2 {
+		t.Errorf("version = %d, expected 2", saved.Version)
+	}
+
+	repairs := s.store.ListRepairSuggestions(orgID)
+	if len(repairs) != 1 || !repairs[0].Cancelled || len(repairs[0].Candidates) != 1 || repairs[0].Candidates[0].EmployeeID != backup.ID {
+		t.Fatalf("应生成一条以李四为替补的修复建议, got %+v", repairs)
+	}
+}
+
+func TestSyncer_Throttled(t *testing.T) {
+	s, _ := newTestSyncer(t, 2)
+
+	events := []*model.HREvent{event(model.HREventUpdate, "E1", nil), event(model.HREventUpdate, "E2", nil), event(model.HREventUpdate, "E3", nil)}
+	accepted, err := s.Ingest("hr", events)
+	if err != ErrThrottled || accepted != 2 {
+		t.Errorf("Ingest() = %d, %v; expected 2, ErrThrottled", accepted, err)
+	}
+	if s.Pending() != 2 {
+		t.Errorf("pending = %d, expected 2", s.Pending())
+	}
+	if _, err := s.Ingest("unknown", events); err != ErrUnknownSource {
+		t.Errorf("未配置来源 error = %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package memstore
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 标签别名
+// ========================================
+
+// ReplaceAliases 替换组织的全部标签别名映射
+// 已被新映射覆盖的未映射标签记录会被清除
+func (s *Store) ReplaceAliases(orgID uuid.UUID, aliases []*model.LabelAlias) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*model.LabelAlias, 0, len(aliases))
+	mapped := make(map[string]bool)
+	for _, a := range aliases {
+		if a == nil || a.Canonical == "" {
+			return ErrInvalid
+		}
+		c := *a
+		c.OrgID = orgID
+		c.Aliases = append([]string(nil), a.Aliases...)
+		list = append(list, &c)
+		mapped[c.Kind+"/"+strings.ToLower(c.Canonical)] = true
+		for _, label := range c.Aliases {
+			mapped[c.Kind+"/"+strings.ToLower(strings.TrimSpace(label))] = true
+		}
+	}
+	s.aliases[orgID] = list
+
+	for k, u := range s.unmapped {
+		if u.OrgID == orgID && u.Reason == model.UnmappedNoAlias && mapped[u.Kind+"/"+strings.ToLower(u.Label)] {
+			delete(s.unmapped, k)
+		}
+	}
+	s.dirty = true
+	return nil
+}
+
+// ListAliases 列出组织的标签别名映射（按类型和规范编码排序）
+func (s *Store) ListAliases(orgID uuid.UUID) []*model.LabelAlias {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.LabelAlias, 0, len(s.aliases[orgID]))
+	for _, a := range s.aliases[orgID] {
+		c := *a
+		c.Aliases = append([]string(nil), a.Aliases...)
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Canonical < result[j].Canonical
+	})
+	return result
+}
+
+// RecordUnmapped 累计组织的未映射标签（同类型、同原因、同标签合并计数）
+func (s *Store) RecordUnmapped(orgID uuid.UUID, labels []model.UnmappedLabel) {
+	if len(labels) == 0 {
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, label := range labels {
+		k := unmappedKey(orgID, label.Kind, label.Reason, label.Label)
+		if u, ok := s.unmapped[k]; ok {
+			u.Count += label.Count
+			u.Source = label.Source
+			u.LastSeen = now
+			continue
+		}
+		c := label
+		c.OrgID = orgID
+		c.FirstSeen = now
+		c.LastSeen = now
+		s.unmapped[k] = &c
+	}
+	s.dirty = true
+}
+
+// ListUnmapped 列出组织的未映射标签（按出现次数降序）
+func (s *Store) ListUnmapped(orgID uuid.UUID) []*model.UnmappedLabel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.UnmappedLabel, 0)
+	for _, u := range s.unmapped {
+		if u.OrgID == orgID {
+			c := *u
+			result = append(result, &c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Label < result[j].Label
+	})
+	return result
+}
+
+func unmappedKey(orgID uuid.UUID, kind, reason, label string) string {
+	return orgID.String() + "/" + kind + "/" + reason + "/" + label
+}
@@ -0,0 +1,117 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 审批单与审批委托
+// ========================================
+
+// PutApproval 保存审批单（新增或覆盖）
+func (s *Store) PutApproval(a *model.ApprovalRequest) error {
+	if a == nil || a.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.approvals[a.ID] = cloneApproval(a)
+	s.dirty = true
+	return nil
+}
+
+// GetApproval 获取审批单
+func (s *Store) GetApproval(id uuid.UUID) (*model.ApprovalRequest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.approvals[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneApproval(a), nil
+}
+
+// ListApprovals 列出组织下的审批单（按创建时间升序）
+// orgID 为 uuid.Nil 时返回全部组织，status 为空表示不限状态
+func (s *Store) ListApprovals(orgID uuid.UUID, status string) []*model.ApprovalRequest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ApprovalRequest, 0)
+	for _, a := range s.approvals {
+		if orgID != uuid.Nil && a.OrgID != orgID {
+			continue
+		}
+		if status != "" && a.Status != status {
+			continue
+		}
+		result = append(result, cloneApproval(a))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+// PutDelegation 保存审批委托（新增或覆盖）
+func (s *Store) PutDelegation(d *model.ApprovalDelegation) error {
+	if d == nil || d.ID == uuid.Nil || d.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *d
+	c.Kinds = append([]string(nil), d.Kinds...)
+	list := s.delegations[d.OrgID]
+	for i, existing := range list {
+		if existing.ID == d.ID {
+			list[i] = &c
+			s.dirty = true
+			return nil
+		}
+	}
+	s.delegations[d.OrgID] = append(list, &c)
+	s.dirty = true
+	return nil
+}
+
+// DeleteDelegation 删除审批委托
+func (s *Store) DeleteDelegation(orgID, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.delegations[orgID]
+	for i, d := range list {
+		if d.ID == id {
+			s.delegations[orgID] = append(list[:i:i], list[i+1:]...)
+			s.dirty = true
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// ListDelegations 列出组织的审批委托（按开始日期排序）
+func (s *Store) ListDelegations(orgID uuid.UUID) []*model.ApprovalDelegation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ApprovalDelegation, 0, len(s.delegations[orgID]))
+	for _, d := range s.delegations[orgID] {
+		c := *d
+		c.Kinds = append([]string(nil), d.Kinds...)
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].StartDate != result[j].StartDate {
+			return result[i].StartDate < result[j].StartDate
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// cloneApproval 复制审批单（含流转记录）
+func cloneApproval(a *model.ApprovalRequest) *model.ApprovalRequest {
+	c := *a
+	c.Routes = append([]model.ApprovalRoute(nil), a.Routes...)
+	return &c
+}
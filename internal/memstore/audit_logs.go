@@ -0,0 +1,60 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 审计日志（未配置数据库时）
+// ========================================
+
+// maxAuditLogs 内存中保留的审计日志条数，超出时丢弃最早的记录
+const maxAuditLogs = 100000
+
+// AddAuditLog 追加审计日志
+func (s *Store) AddAuditLog(l *model.AuditLog) error {
+	if l == nil || l.Action == "" {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *l
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	s.auditLogs = append(s.auditLogs, &c)
+	if len(s.auditLogs) > maxAuditLogs {
+		s.auditLogs = s.auditLogs[len(s.auditLogs)-maxAuditLogs:]
+	}
+	s.dirty = true
+	return nil
+}
+
+// ListAuditLogs 按条件查询审计日志（按时间倒序），返回当前页和总数
+func (s *Store) ListAuditLogs(filter model.AuditFilter) ([]*model.AuditLog, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	matched := make([]*model.AuditLog, 0)
+	for i := len(s.auditLogs) - 1; i >= 0; i-- {
+		if filter.Match(s.auditLogs[i]) {
+			matched = append(matched, s.auditLogs[i])
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].CreatedAt.After(matched[j].CreatedAt) })
+
+	total := len(matched)
+	start := min(filter.Offset, total)
+	end := total
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, total)
+	}
+	result := make([]*model.AuditLog, 0, end-start)
+	for _, l := range matched[start:end] {
+		c := *l
+		result = append(result, &c)
+	}
+	return result, total
+}
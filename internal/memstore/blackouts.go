@@ -0,0 +1,52 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 请假管控期
+// ========================================
+
+// ReplaceBlackoutPeriods 替换组织的全部请假管控期
+func (s *Store) ReplaceBlackoutPeriods(orgID uuid.UUID, periods []*model.BlackoutPeriod) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*model.BlackoutPeriod, 0, len(periods))
+	for _, p := range periods {
+		if p == nil || p.StartDate == "" || p.EndDate == "" {
+			return ErrInvalid
+		}
+		c := *p
+		c.OrgID = orgID
+		if c.ID == uuid.Nil {
+			c.ID = uuid.New()
+		}
+		list = append(list, &c)
+	}
+	s.blackouts[orgID] = list
+	s.dirty = true
+	return nil
+}
+
+// ListBlackoutPeriods 列出组织的请假管控期（按开始日期排序）
+func (s *Store) ListBlackoutPeriods(orgID uuid.UUID) []*model.BlackoutPeriod {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.BlackoutPeriod, 0, len(s.blackouts[orgID]))
+	for _, p := range s.blackouts[orgID] {
+		c := *p
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].StartDate != result[j].StartDate {
+			return result[i].StartDate < result[j].StartDate
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,49 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 门店工时预算
+// ========================================
+
+// ReplaceStoreBudgets 替换组织的全部门店每周工时预算
+func (s *Store) ReplaceStoreBudgets(orgID uuid.UUID, budgets []*model.StoreHoursBudget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*model.StoreHoursBudget, 0, len(budgets))
+	for _, b := range budgets {
+		if b == nil || b.StoreID == "" || b.Hours < 0 {
+			return ErrInvalid
+		}
+		c := *b
+		c.OrgID = orgID
+		list = append(list, &c)
+	}
+	s.storeBudgets[orgID] = list
+	s.dirty = true
+	return nil
+}
+
+// ListStoreBudgets 列出组织的门店工时预算（按门店、周起始日排序，默认预算在前）
+func (s *Store) ListStoreBudgets(orgID uuid.UUID) []*model.StoreHoursBudget {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.StoreHoursBudget, 0, len(s.storeBudgets[orgID]))
+	for _, b := range s.storeBudgets[orgID] {
+		c := *b
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].StoreID != result[j].StoreID {
+			return result[i].StoreID < result[j].StoreID
+		}
+		return result[i].WeekStart < result[j].WeekStart
+	})
+	return result
+}
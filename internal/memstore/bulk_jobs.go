@@ -0,0 +1,60 @@
+package memstore
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 批量作业
+// ========================================
+
+// PutBulkJob 保存批量作业（新增或覆盖）
+func (s *Store) PutBulkJob(job *model.BulkJob) error {
+	if job == nil || job.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bulkJobs[job.ID] = cloneBulkJob(job)
+	s.dirty = true
+	return nil
+}
+
+// GetBulkJob 获取批量作业
+func (s *Store) GetBulkJob(id uuid.UUID) (*model.BulkJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.bulkJobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneBulkJob(job), nil
+}
+
+// ListBulkJobs 列出组织下的批量作业摘要（按创建时间倒序，不含原始数据和结果）
+// orgID 为 uuid.Nil 时返回全部组织
+func (s *Store) ListBulkJobs(orgID uuid.UUID) []*model.BulkJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.BulkJob, 0)
+	for _, job := range s.bulkJobs {
+		if orgID != uuid.Nil && job.OrgID != orgID {
+			continue
+		}
+		result = append(result, job.Summary())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
+// cloneBulkJob 复制批量作业（原始数据和结果的单行内容不可变，仅复制切片）
+func cloneBulkJob(job *model.BulkJob) *model.BulkJob {
+	c := job.Summary()
+	c.Rows = append([]json.RawMessage(nil), job.Rows...)
+	c.Results = append([]json.RawMessage(nil), job.Results...)
+	return c
+}
@@ -0,0 +1,62 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 护理计划
+// ========================================
+
+// PutCarePlan 保存护理计划（新增或覆盖）
+func (s *Store) PutCarePlan(p *model.CarePlan) error {
+	if p == nil || p.ID == uuid.Nil || p.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.carePlans[p.ID] = cloneCarePlan(p)
+	s.dirty = true
+	return nil
+}
+
+// GetCarePlan 获取护理计划
+func (s *Store) GetCarePlan(id uuid.UUID) (*model.CarePlan, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.carePlans[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneCarePlan(p), nil
+}
+
+// ListCarePlans 列出组织的护理计划（按计划编号升序），customerID 为 uuid.Nil 表示不限客户
+func (s *Store) ListCarePlans(orgID, customerID uuid.UUID) []*model.CarePlan {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.CarePlan, 0)
+	for _, p := range s.carePlans {
+		if p.OrgID != orgID || (customerID != uuid.Nil && p.CustomerID != customerID) {
+			continue
+		}
+		result = append(result, cloneCarePlan(p))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PlanNo < result[j].PlanNo })
+	return result
+}
+
+// cloneCarePlan 复制护理计划（服务项目、备选护理员和位置）
+func cloneCarePlan(p *model.CarePlan) *model.CarePlan {
+	c := *p
+	c.ServiceItems = append([]model.CareItem(nil), p.ServiceItems...)
+	c.BackupCarerIDs = append([]uuid.UUID(nil), p.BackupCarerIDs...)
+	if p.Location != nil {
+		loc := *p.Location
+		c.Location = &loc
+	}
+	return &c
+}
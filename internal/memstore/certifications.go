@@ -0,0 +1,57 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 证书材料
+// ========================================
+
+// PutCertificationDocument 保存证书材料（新增或覆盖）
+func (s *Store) PutCertificationDocument(d *model.CertificationDocument) error {
+	if d == nil || d.ID == uuid.Nil || d.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *d
+	s.certDocuments[d.ID] = &c
+	s.dirty = true
+	return nil
+}
+
+// GetCertificationDocument 获取证书材料
+func (s *Store) GetCertificationDocument(id uuid.UUID) (*model.CertificationDocument, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.certDocuments[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *d
+	return &c, nil
+}
+
+// ListCertificationDocuments 列出组织下的证书材料（按提交时间升序）
+// employeeID 为 uuid.Nil 时不限员工，status 为空表示不限状态
+func (s *Store) ListCertificationDocuments(orgID, employeeID uuid.UUID, status string) []*model.CertificationDocument {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.CertificationDocument, 0)
+	for _, d := range s.certDocuments {
+		if d.OrgID != orgID || (employeeID != uuid.Nil && d.EmployeeID != employeeID) {
+			continue
+		}
+		if status != "" && d.Status != status {
+			continue
+		}
+		c := *d
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].SubmittedAt.Before(result[j].SubmittedAt) })
+	return result
+}
@@ -0,0 +1,61 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 工时合规证明
+// ========================================
+
+// AddComplianceCertificate 保存签发的合规证明
+func (s *Store) AddComplianceCertificate(c *model.ComplianceCertificate) error {
+	if c == nil || c.ID == uuid.Nil || c.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.certificates[c.ID] = cloneCertificate(c)
+	s.dirty = true
+	return nil
+}
+
+// GetComplianceCertificate 获取合规证明
+func (s *Store) GetComplianceCertificate(id uuid.UUID) (*model.ComplianceCertificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.certificates[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneCertificate(c), nil
+}
+
+// ListComplianceCertificates 列出组织的合规证明（按签发时间倒序）
+func (s *Store) ListComplianceCertificates(orgID uuid.UUID) []*model.ComplianceCertificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ComplianceCertificate, 0)
+	for _, c := range s.certificates {
+		if c.OrgID == orgID {
+			result = append(result, cloneCertificate(c))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].IssuedAt.After(result[j].IssuedAt) })
+	return result
+}
+
+// cloneCertificate 复制合规证明，规则和员工列表单独复制
+func cloneCertificate(c *model.ComplianceCertificate) *model.ComplianceCertificate {
+	cp := *c
+	cp.Rules = append([]model.ComplianceRule(nil), c.Rules...)
+	cp.Employees = make([]model.EmployeeCompliance, len(c.Employees))
+	for i, e := range c.Employees {
+		e.Exceptions = append([]model.ComplianceException(nil), e.Exceptions...)
+		cp.Employees[i] = e
+	}
+	return &cp
+}
@@ -0,0 +1,59 @@
+package memstore
+
+import (
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 组织约束配置版本
+// ========================================
+
+// AddConstraintConfig 保存组织约束配置的新版本，返回带版本号的副本
+func (s *Store) AddConstraintConfig(cfg *model.ConstraintConfigVersion) (*model.ConstraintConfigVersion, error) {
+	if cfg == nil || cfg.OrgID == uuid.Nil {
+		return nil, ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := cloneConstraintConfig(cfg)
+	c.Version = len(s.constraintConfigs[cfg.OrgID]) + 1
+	s.constraintConfigs[cfg.OrgID] = append(s.constraintConfigs[cfg.OrgID], c)
+	s.dirty = true
+	return cloneConstraintConfig(c), nil
+}
+
+// GetConstraintConfig 获取组织约束配置的指定版本，version 为 0 时返回最新版本
+func (s *Store) GetConstraintConfig(orgID uuid.UUID, version int) (*model.ConstraintConfigVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := s.constraintConfigs[orgID]
+	if version == 0 {
+		version = len(versions)
+	}
+	if version < 1 || version > len(versions) {
+		return nil, ErrNotFound
+	}
+	return cloneConstraintConfig(versions[version-1]), nil
+}
+
+// ListConstraintConfigs 列出组织约束配置的全部版本（按版本升序）
+func (s *Store) ListConstraintConfigs(orgID uuid.UUID) []*model.ConstraintConfigVersion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ConstraintConfigVersion, 0, len(s.constraintConfigs[orgID]))
+	for _, c := range s.constraintConfigs[orgID] {
+		result = append(result, cloneConstraintConfig(c))
+	}
+	return result
+}
+
+// cloneConstraintConfig 复制约束配置版本（顶层配置键）
+func cloneConstraintConfig(cfg *model.ConstraintConfigVersion) *model.ConstraintConfigVersion {
+	c := *cfg
+	c.Config = make(model.JSONMap, len(cfg.Config))
+	for k, v := range cfg.Config {
+		c.Config[k] = v
+	}
+	return &c
+}
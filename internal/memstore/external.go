@@ -0,0 +1,44 @@
+package memstore
+
+import (
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 外部人员池
+// ========================================
+
+// PutExternalPool 保存组织的外部人员池（覆盖已有配置）
+func (s *Store) PutExternalPool(pool *model.ExternalPool) error {
+	if pool == nil || pool.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.externalPools[pool.OrgID] = cloneExternalPool(pool)
+	s.dirty = true
+	return nil
+}
+
+// GetExternalPool 获取组织的外部人员池
+func (s *Store) GetExternalPool(orgID uuid.UUID) (*model.ExternalPool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pool, ok := s.externalPools[orgID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneExternalPool(pool), nil
+}
+
+// cloneExternalPool 复制外部人员池
+func cloneExternalPool(pool *model.ExternalPool) *model.ExternalPool {
+	c := *pool
+	c.Workers = make([]*model.ExternalWorker, len(pool.Workers))
+	for i, w := range pool.Workers {
+		wc := *w
+		c.Workers[i] = &wc
+	}
+	return &c
+}
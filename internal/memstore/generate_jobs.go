@@ -0,0 +1,53 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 异步排班生成作业
+// ========================================
+
+// PutGenerateJob 保存排班生成作业（新增或覆盖）
+func (s *Store) PutGenerateJob(job *model.GenerateJob) error {
+	if job == nil || job.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *job
+	s.generateJobs[job.ID] = &c
+	s.dirty = true
+	return nil
+}
+
+// GetGenerateJob 获取排班生成作业（请求和结果内容不可变，与存储共用）
+func (s *Store) GetGenerateJob(id uuid.UUID) (*model.GenerateJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.generateJobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *job
+	return &c, nil
+}
+
+// ListGenerateJobs 列出组织下的排班生成作业摘要（按创建时间倒序，不含请求和结果）
+// orgID 为 uuid.Nil 时返回全部组织
+func (s *Store) ListGenerateJobs(orgID uuid.UUID) []*model.GenerateJob {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.GenerateJob, 0)
+	for _, job := range s.generateJobs {
+		if orgID != uuid.Nil && job.OrgID != orgID {
+			continue
+		}
+		result = append(result, job.Summary())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
@@ -0,0 +1,64 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 组织自定义节假日
+// ========================================
+
+// PutHoliday 保存组织自定义节假日（新增或覆盖）
+func (s *Store) PutHoliday(h *model.Holiday) error {
+	if h == nil || h.ID == uuid.Nil || h.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp := *h
+	s.holidays[h.ID] = &cp
+	s.dirty = true
+	return nil
+}
+
+// GetHoliday 获取组织自定义节假日
+func (s *Store) GetHoliday(id uuid.UUID) (*model.Holiday, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	h, ok := s.holidays[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *h
+	return &cp, nil
+}
+
+// ListHolidays 列出组织的自定义节假日（按日期升序）
+func (s *Store) ListHolidays(orgID uuid.UUID) []*model.Holiday {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.Holiday, 0)
+	for _, h := range s.holidays {
+		if h.OrgID == orgID {
+			cp := *h
+			result = append(result, &cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+// DeleteHoliday 删除组织自定义节假日
+func (s *Store) DeleteHoliday(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.holidays[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.holidays, id)
+	s.dirty = true
+	return nil
+}
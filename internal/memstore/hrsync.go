@@ -0,0 +1,125 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// HR 同步
+// ========================================
+
+// PutHRMapping 保存 HR 字段映射（按来源覆盖）
+func (s *Store) PutHRMapping(mapping *model.HRMapping) error {
+	if mapping == nil || mapping.Source == "" {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hrMappings[mapping.Source] = cloneHRMapping(mapping)
+	s.dirty = true
+	return nil
+}
+
+// GetHRMapping 获取来源的 HR 字段映射
+func (s *Store) GetHRMapping(source string) (*model.HRMapping, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	mapping, ok := s.hrMappings[source]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneHRMapping(mapping), nil
+}
+
+// cloneHRMapping 复制 HR 字段映射
+func cloneHRMapping(mapping *model.HRMapping) *model.HRMapping {
+	c := *mapping
+	c.Fields = cloneStringMap(mapping.Fields)
+	c.StatusMap = cloneStringMap(mapping.StatusMap)
+	c.PositionMap = cloneStringMap(mapping.PositionMap)
+	return &c
+}
+
+func cloneStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// AddHRConflict 记录 HR 同步冲突
+func (s *Store) AddHRConflict(conflict *model.HRSyncConflict) error {
+	if conflict == nil || conflict.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *conflict
+	s.hrConflicts[conflict.ID] = &c
+	s.dirty = true
+	return nil
+}
+
+// ListHRConflicts 列出 HR 同步冲突（按时间升序），source 为空返回全部
+func (s *Store) ListHRConflicts(source string) []*model.HRSyncConflict {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.HRSyncConflict, 0)
+	for _, conflict := range s.hrConflicts {
+		if source != "" && conflict.Source != source {
+			continue
+		}
+		c := *conflict
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+// DeleteHRConflict 删除（处理完成的）HR 同步冲突
+func (s *Store) DeleteHRConflict(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.hrConflicts[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.hrConflicts, id)
+	s.dirty = true
+	return nil
+}
+
+// AddRepairSuggestion 记录修复建议
+func (s *Store) AddRepairSuggestion(suggestion *model.RepairSuggestion) error {
+	if suggestion == nil || suggestion.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *suggestion
+	s.repairs[suggestion.ID] = &c
+	s.dirty = true
+	return nil
+}
+
+// ListRepairSuggestions 列出组织的修复建议（按日期升序），orgID 为 uuid.Nil 时返回全部
+func (s *Store) ListRepairSuggestions(orgID uuid.UUID) []*model.RepairSuggestion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.RepairSuggestion, 0)
+	for _, suggestion := range s.repairs {
+		if orgID != uuid.Nil && suggestion.OrgID != orgID {
+			continue
+		}
+		c := *suggestion
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
@@ -0,0 +1,1018 @@
+// Package memstore 提供无数据库模式下的内存状态存储
+// 数据保存在内存中，并可定期以 JSON 快照形式持久化到磁盘，启动时自动加载
+package memstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/chaos"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// snapshotVersion 快照格式版本
+const snapshotVersion = 1
+
+var (
+	ErrNotFound        = errors.New("记录不存在")
+	ErrInvalid         = errors.New("无效的记录")
+	ErrVersionConflict = errors.New("版本冲突")
+	ErrDuplicate       = errors.New("记录已存在")
+)
+
+// Snapshot 快照文件内容
+type Snapshot struct {
+	Version       int                           `json:"version"`
+	SavedAt       time.Time                     `json:"saved_at"`
+	Organizations []*model.Organization         `json:"organizations"`
+	Employees     []*model.Employee             `json:"employees"`
+	Shifts        []*model.Shift                `json:"shifts"`
+	Schedules     []*model.Schedule             `json:"schedules"`
+	Revisions     []*model.ScheduleRevision     `json:"revisions,omitempty"`
+	Requirements  []*model.ShiftRequirement     `json:"requirements,omitempty"`
+	Availability  []*model.EmployeeAvailability `json:"availability,omitempty"`
+	Summaries     []*model.MonthlySummary       `json:"summaries,omitempty"`
+	Disputes      []*model.SummaryDispute       `json:"disputes,omitempty"`
+	HRMappings    []*model.HRMapping            `json:"hr_mappings,omitempty"`
+	HRConflicts   []*model.HRSyncConflict       `json:"hr_conflicts,omitempty"`
+	Repairs       []*model.RepairSuggestion     `json:"repairs,omitempty"`
+	Aliases       []*model.LabelAlias           `json:"aliases,omitempty"`
+	Unmapped      []*model.UnmappedLabel        `json:"unmapped_labels,omitempty"`
+	StoreBudgets  []*model.StoreHoursBudget     `json:"store_budgets,omitempty"`
+	Blackouts     []*model.BlackoutPeriod       `json:"blackout_periods,omitempty"`
+	OpeningHours  []*model.StoreOpeningHours    `json:"opening_hours,omitempty"`
+	Teams         []*model.Team                 `json:"teams,omitempty"`
+	Approvals     []*model.ApprovalRequest      `json:"approvals,omitempty"`
+	Delegations   []*model.ApprovalDelegation   `json:"delegations,omitempty"`
+
+	ConstraintConfigs []*model.ConstraintConfigVersion `json:"constraint_configs,omitempty"`
+	BulkJobs          []*model.BulkJob                 `json:"bulk_jobs,omitempty"`
+	PayrollCloses     []*model.PayrollClose            `json:"payroll_closes,omitempty"`
+	Adjustments       []*model.PayrollAdjustment       `json:"payroll_adjustments,omitempty"`
+	ExternalPools     []*model.ExternalPool            `json:"external_pools,omitempty"`
+	CertDocuments     []*model.CertificationDocument   `json:"certification_documents,omitempty"`
+	TimeBank          []*model.TimeBankEntry           `json:"time_bank,omitempty"`
+	Orders            []*model.ServiceOrder            `json:"orders,omitempty"`
+	Certificates      []*model.ComplianceCertificate   `json:"compliance_certificates,omitempty"`
+
+	RequirementImports []*model.RequirementImport  `json:"requirement_imports,omitempty"`
+	ShareLinks         []*model.ShareLink          `json:"share_links,omitempty"`
+	ShareAccesses      []*model.ShareAccess        `json:"share_accesses,omitempty"`
+	GenerateJobs       []*model.GenerateJob        `json:"generate_jobs,omitempty"`
+	ScheduleAudit      []*model.ScheduleAuditEntry `json:"schedule_audit,omitempty"`
+	OrgConstraints     []*model.OrgConstraint      `json:"org_constraints,omitempty"`
+	ScenarioTemplates  []*model.ScenarioTemplate   `json:"scenario_templates,omitempty"`
+	CarePlans          []*model.CarePlan           `json:"care_plans,omitempty"`
+	SchedulePatterns   []*model.SchedulePattern    `json:"schedule_patterns,omitempty"`
+	Holidays           []*model.Holiday            `json:"holidays,omitempty"`
+	Webhooks           []*model.Webhook            `json:"webhooks,omitempty"`
+	WebhookDeliveries  []*model.WebhookDelivery    `json:"webhook_deliveries,omitempty"`
+	AuditLogs          []*model.AuditLog           `json:"audit_logs,omitempty"`
+	ScheduleVersions   []*model.ScheduleVersion    `json:"schedule_versions,omitempty"`
+}
+
+// Store 内存状态存储（并发安全）
+type Store struct {
+	mu        sync.RWMutex
+	orgs      map[uuid.UUID]*model.Organization
+	employees map[uuid.UUID]*model.Employee
+	shifts    map[uuid.UUID]*model.Shift
+	schedules map[uuid.UUID]*model.Schedule
+	revisions map[uuid.UUID][]*model.ScheduleRevision // 排班ID -> 修订记录（按版本升序）
+
+	requirements map[uuid.UUID]*model.ShiftRequirement
+	availability map[string]*model.EmployeeAvailability // 员工ID/日期 -> 可用性
+	summaries    map[string]*model.MonthlySummary       // 员工ID/月份 -> 月度汇总
+	disputes     map[uuid.UUID]*model.SummaryDispute
+	hrMappings   map[string]*model.HRMapping // 来源 -> 字段映射
+	hrConflicts  map[uuid.UUID]*model.HRSyncConflict
+	repairs      map[uuid.UUID]*model.RepairSuggestion
+	aliases      map[uuid.UUID][]*model.LabelAlias        // 组织ID -> 标签别名
+	unmapped     map[string]*model.UnmappedLabel          // 组织ID/类型/原因/标签 -> 未映射标签
+	storeBudgets map[uuid.UUID][]*model.StoreHoursBudget  // 组织ID -> 门店工时预算
+	blackouts    map[uuid.UUID][]*model.BlackoutPeriod    // 组织ID -> 请假管控期
+	openingHours map[uuid.UUID][]*model.StoreOpeningHours // 组织ID -> 门店营业时间
+	teams        map[uuid.UUID][]*model.Team              // 组织ID -> 班组
+	approvals    map[uuid.UUID]*model.ApprovalRequest
+	delegations  map[uuid.UUID][]*model.ApprovalDelegation // 组织ID -> 审批委托
+
+	constraintConfigs map[uuid.UUID][]*model.ConstraintConfigVersion // 组织ID -> 约束配置版本（按版本升序）
+	bulkJobs          map[uuid.UUID]*model.BulkJob
+	payrollCloses     map[uuid.UUID][]*model.PayrollClose // 组织ID -> 工资结账记录
+	adjustments       map[uuid.UUID]*model.PayrollAdjustment
+	externalPools     map[uuid.UUID]*model.ExternalPool // 组织ID -> 外部人员池
+	certDocuments     map[uuid.UUID]*model.CertificationDocument
+	timeBank          map[uuid.UUID]*model.TimeBankEntry
+	orders            map[uuid.UUID]*model.ServiceOrder
+	certificates      map[uuid.UUID]*model.ComplianceCertificate
+
+	requirementImports map[uuid.UUID]*model.RequirementImport
+	shareLinks         map[uuid.UUID]*model.ShareLink
+	shareAccesses      map[uuid.UUID][]*model.ShareAccess // 分享链接ID -> 访问记录（按时间升序）
+	generateJobs       map[uuid.UUID]*model.GenerateJob
+	scheduleAudit      map[uuid.UUID][]*model.ScheduleAuditEntry // 排班ID -> 生命周期审计记录（按时间升序）
+	orgConstraints     map[uuid.UUID]*model.OrgConstraint        // 组织级约束配置
+	scenarioTemplates  map[uuid.UUID]*model.ScenarioTemplate     // 自定义场景模板
+	carePlans          map[uuid.UUID]*model.CarePlan             // 护理计划
+	schedulePatterns   map[uuid.UUID]*model.SchedulePattern      // 循环排班模板
+	holidays           map[uuid.UUID]*model.Holiday              // 组织自定义节假日
+	webhooks           map[uuid.UUID]*model.Webhook              // 通知订阅
+	webhookDeliveries  map[uuid.UUID][]*model.WebhookDelivery    // 通知订阅ID -> 投递记录（按时间升序）
+	auditLogs          []*model.AuditLog                         // 审计日志（按时间升序）
+	scheduleVersions   map[uuid.UUID][]*model.ScheduleVersion    // 排班ID -> 版本快照（按版本升序）
+
+	path   string     // 快照文件路径，为空则不持久化
+	dirty  bool       // 自上次快照后是否有变更
+	saveMu sync.Mutex // 串行化快照写入
+}
+
+// New 创建内存存储
+// path 为快照文件路径，传空字符串表示纯内存模式
+func New(path string) *Store {
+	return &Store{
+		orgs:      make(map[uuid.UUID]*model.Organization),
+		employees: make(map[uuid.UUID]*model.Employee),
+		shifts:    make(map[uuid.UUID]*model.Shift),
+		schedules: make(map[uuid.UUID]*model.Schedule),
+		revisions: make(map[uuid.UUID][]*model.ScheduleRevision),
+
+		requirements: make(map[uuid.UUID]*model.ShiftRequirement),
+		availability: make(map[string]*model.EmployeeAvailability),
+		summaries:    make(map[string]*model.MonthlySummary),
+		disputes:     make(map[uuid.UUID]*model.SummaryDispute),
+		hrMappings:   make(map[string]*model.HRMapping),
+		hrConflicts:  make(map[uuid.UUID]*model.HRSyncConflict),
+		repairs:      make(map[uuid.UUID]*model.RepairSuggestion),
+		aliases:      make(map[uuid.UUID][]*model.LabelAlias),
+		unmapped:     make(map[string]*model.UnmappedLabel),
+		storeBudgets: make(map[uuid.UUID][]*model.StoreHoursBudget),
+		blackouts:    make(map[uuid.UUID][]*model.BlackoutPeriod),
+		openingHours: make(map[uuid.UUID][]*model.StoreOpeningHours),
+		teams:        make(map[uuid.UUID][]*model.Team),
+		approvals:    make(map[uuid.UUID]*model.ApprovalRequest),
+		delegations:  make(map[uuid.UUID][]*model.ApprovalDelegation),
+
+		constraintConfigs: make(map[uuid.UUID][]*model.ConstraintConfigVersion),
+		bulkJobs:          make(map[uuid.UUID]*model.BulkJob),
+		payrollCloses:     make(map[uuid.UUID][]*model.PayrollClose),
+		adjustments:       make(map[uuid.UUID]*model.PayrollAdjustment),
+		externalPools:     make(map[uuid.UUID]*model.ExternalPool),
+		certDocuments:     make(map[uuid.UUID]*model.CertificationDocument),
+		timeBank:          make(map[uuid.UUID]*model.TimeBankEntry),
+		orders:            make(map[uuid.UUID]*model.ServiceOrder),
+		certificates:      make(map[uuid.UUID]*model.ComplianceCertificate),
+
+		requirementImports: make(map[uuid.UUID]*model.RequirementImport),
+		shareLinks:         make(map[uuid.UUID]*model.ShareLink),
+		shareAccesses:      make(map[uuid.UUID][]*model.ShareAccess),
+		generateJobs:       make(map[uuid.UUID]*model.GenerateJob),
+		scheduleAudit:      make(map[uuid.UUID][]*model.ScheduleAuditEntry),
+		orgConstraints:     make(map[uuid.UUID]*model.OrgConstraint),
+		scenarioTemplates:  make(map[uuid.UUID]*model.ScenarioTemplate),
+		carePlans:          make(map[uuid.UUID]*model.CarePlan),
+		schedulePatterns:   make(map[uuid.UUID]*model.SchedulePattern),
+		holidays:           make(map[uuid.UUID]*model.Holiday),
+		webhooks:           make(map[uuid.UUID]*model.Webhook),
+		webhookDeliveries:  make(map[uuid.UUID][]*model.WebhookDelivery),
+		scheduleVersions:   make(map[uuid.UUID][]*model.ScheduleVersion),
+		path:               path,
+	}
+}
+
+// Path 返回快照文件路径
+func (s *Store) Path() string {
+	return s.path
+}
+
+// ========================================
+// 组织
+// ========================================
+
+// PutOrganization 保存组织（新增或覆盖）
+func (s *Store) PutOrganization(org *model.Organization) error {
+	if org == nil || org.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs[org.ID] = cloneOrganization(org)
+	s.dirty = true
+	return nil
+}
+
+// GetOrganization 获取组织
+func (s *Store) GetOrganization(id uuid.UUID) (*model.Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	org, ok := s.orgs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneOrganization(org), nil
+}
+
+// ListOrganizations 列出所有组织
+func (s *Store) ListOrganizations() []*model.Organization {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.Organization, 0, len(s.orgs))
+	for _, org := range s.orgs {
+		result = append(result, cloneOrganization(org))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+// DeleteOrganization 删除组织
+func (s *Store) DeleteOrganization(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.orgs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.orgs, id)
+	s.dirty = true
+	return nil
+}
+
+// cloneOrganization 复制组织（包括发布规则）
+func cloneOrganization(org *model.Organization) *model.Organization {
+	c := *org
+	if org.PublicationRule != nil {
+		rule := *org.PublicationRule
+		c.PublicationRule = &rule
+	}
+	if org.ApprovalPolicy != nil {
+		policy := *org.ApprovalPolicy
+		c.ApprovalPolicy = &policy
+	}
+	if org.DocumentAlertPolicy != nil {
+		policy := *org.DocumentAlertPolicy
+		policy.LeadDays = make(map[string]int, len(org.DocumentAlertPolicy.LeadDays))
+		for k, v := range org.DocumentAlertPolicy.LeadDays {
+			policy.LeadDays[k] = v
+		}
+		c.DocumentAlertPolicy = &policy
+	}
+	if org.ScheduleCycle != nil {
+		cycle := *org.ScheduleCycle
+		c.ScheduleCycle = &cycle
+	}
+	return &c
+}
+
+// ========================================
+// 员工
+// ========================================
+
+// PutEmployee 保存员工（新增或覆盖）
+func (s *Store) PutEmployee(emp *model.Employee) error {
+	if emp == nil || emp.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.employees[emp.ID] = cloneEmployee(emp)
+	s.dirty = true
+	return nil
+}
+
+// GetEmployee 获取员工
+func (s *Store) GetEmployee(id uuid.UUID) (*model.Employee, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	emp, ok := s.employees[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneEmployee(emp), nil
+}
+
+// ListEmployees 列出组织下的员工，orgID 为 uuid.Nil 时返回全部
+func (s *Store) ListEmployees(orgID uuid.UUID) []*model.Employee {
+	chaos.Delay(chaos.StoreLatency, "employees")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.Employee, 0)
+	for _, emp := range s.employees {
+		if orgID != uuid.Nil && emp.OrgID != orgID {
+			continue
+		}
+		result = append(result, cloneEmployee(emp))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Code < result[j].Code })
+	return result
+}
+
+// cloneEmployee 复制员工（包括技能、证书、偏好、可用性等切片、映射和指针字段），
+// 调用方修改返回的员工不影响存储中的数据
+func cloneEmployee(emp *model.Employee) *model.Employee {
+	c := *emp
+	c.Skills = slices.Clone(emp.Skills)
+	c.Certifications = slices.Clone(emp.Certifications)
+	c.SkillLevels = slices.Clone(emp.SkillLevels)
+	c.CertificationRecords = slices.Clone(emp.CertificationRecords)
+	c.VerifiedCertifications = slices.Clone(emp.VerifiedCertifications)
+	c.AllowedStores = slices.Clone(emp.AllowedStores)
+	c.AvailabilityWindows = cloneAvailabilityWindows(emp.AvailabilityWindows)
+	c.UnavailableWindows = cloneAvailabilityWindows(emp.UnavailableWindows)
+	c.Leaves = slices.Clone(emp.Leaves)
+	c.MonthlyShiftsCounts = maps.Clone(emp.MonthlyShiftsCounts)
+	if emp.Availability != nil {
+		c.Availability = make([]model.EmployeeAvailability, len(emp.Availability))
+		for i, av := range emp.Availability {
+			av.TimeRanges = slices.Clone(av.TimeRanges)
+			c.Availability[i] = av
+		}
+	}
+	if emp.Preferences != nil {
+		prefs := *emp.Preferences
+		prefs.PreferredShifts = slices.Clone(prefs.PreferredShifts)
+		prefs.AvoidShifts = slices.Clone(prefs.AvoidShifts)
+		prefs.PreferredDays = slices.Clone(prefs.PreferredDays)
+		prefs.AvoidDays = slices.Clone(prefs.AvoidDays)
+		prefs.ShiftRankings = slices.Clone(prefs.ShiftRankings)
+		prefs.FixedShiftCodes = slices.Clone(prefs.FixedShiftCodes)
+		prefs.FixedDaysOff = slices.Clone(prefs.FixedDaysOff)
+		prefs.CustomPreferences = maps.Clone(prefs.CustomPreferences)
+		c.Preferences = &prefs
+	}
+	if emp.Contract != nil {
+		contract := *emp.Contract
+		c.Contract = &contract
+	}
+	if emp.ServiceArea != nil {
+		area := *emp.ServiceArea
+		area.Districts = slices.Clone(area.Districts)
+		area.ZipCodes = slices.Clone(area.ZipCodes)
+		c.ServiceArea = &area
+	}
+	if emp.HomeLocation != nil {
+		location := *emp.HomeLocation
+		c.HomeLocation = &location
+	}
+	if emp.External != nil {
+		external := *emp.External
+		c.External = &external
+	}
+	return &c
+}
+
+// cloneAvailabilityWindows 复制可用时间窗口（包括星期列表）
+func cloneAvailabilityWindows(windows []model.AvailabilityWindow) []model.AvailabilityWindow {
+	if windows == nil {
+		return nil
+	}
+	c := make([]model.AvailabilityWindow, len(windows))
+	for i, w := range windows {
+		w.Weekdays = slices.Clone(w.Weekdays)
+		c[i] = w
+	}
+	return c
+}
+
+// DeleteEmployee 删除员工
+func (s *Store) DeleteEmployee(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.employees[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.employees, id)
+	s.dirty = true
+	return nil
+}
+
+// ========================================
+// 班次
+// ========================================
+
+// PutShift 保存班次（新增或覆盖）
+func (s *Store) PutShift(shift *model.Shift) error {
+	if shift == nil || shift.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *shift
+	s.shifts[shift.ID] = &c
+	s.dirty = true
+	return nil
+}
+
+// GetShift 获取班次
+func (s *Store) GetShift(id uuid.UUID) (*model.Shift, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	shift, ok := s.shifts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *shift
+	return &c, nil
+}
+
+// ListShifts 列出组织下的班次，orgID 为 uuid.Nil 时返回全部
+func (s *Store) ListShifts(orgID uuid.UUID) []*model.Shift {
+	chaos.Delay(chaos.StoreLatency, "shifts")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.Shift, 0)
+	for _, shift := range s.shifts {
+		if orgID != uuid.Nil && shift.OrgID != orgID {
+			continue
+		}
+		c := *shift
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartTime < result[j].StartTime })
+	return result
+}
+
+// DeleteShift 删除班次
+func (s *Store) DeleteShift(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.shifts[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.shifts, id)
+	s.dirty = true
+	return nil
+}
+
+// ========================================
+// 排班
+// ========================================
+
+// PutSchedule 保存排班（新增或覆盖）
+func (s *Store) PutSchedule(schedule *model.Schedule) error {
+	if schedule == nil || schedule.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	chaos.Delay(chaos.StoreLatency, "schedules")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules[schedule.ID] = cloneSchedule(schedule)
+	s.dirty = true
+	return nil
+}
+
+// GetSchedule 获取排班
+func (s *Store) GetSchedule(id uuid.UUID) (*model.Schedule, error) {
+	chaos.Delay(chaos.StoreLatency, "schedules")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneSchedule(schedule), nil
+}
+
+// ListSchedules 列出组织下的排班（按创建时间倒序），orgID 为 uuid.Nil 时返回全部
+func (s *Store) ListSchedules(orgID uuid.UUID) []*model.Schedule {
+	chaos.Delay(chaos.StoreLatency, "schedules")
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.Schedule, 0)
+	for _, schedule := range s.schedules {
+		if orgID != uuid.Nil && schedule.OrgID != orgID {
+			continue
+		}
+		result = append(result, cloneSchedule(schedule))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
+// DeleteSchedule 删除排班
+func (s *Store) DeleteSchedule(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schedules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.schedules, id)
+	delete(s.revisions, id)
+	delete(s.scheduleVersions, id)
+	s.dirty = true
+	return nil
+}
+
+// CompareAndSwapSchedule 仅当存储中排班版本等于 expectedVersion 时保存排班及其修订记录
+// 有修订记录时同时保存 edit 版本快照；版本不一致时返回 ErrVersionConflict，用于乐观并发控制
+func (s *Store) CompareAndSwapSchedule(schedule *model.Schedule, expectedVersion int, revision *model.ScheduleRevision) error {
+	if schedule == nil || schedule.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	chaos.Delay(chaos.StoreLatency, "schedules")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.schedules[schedule.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if current.Version != expectedVersion {
+		return ErrVersionConflict
+	}
+	s.schedules[schedule.ID] = cloneSchedule(schedule)
+	if revision != nil {
+		r := *revision
+		s.revisions[schedule.ID] = append(s.revisions[schedule.ID], &r)
+		reason := model.ScheduleVersionEdit
+		if revision.RestoredFrom > 0 {
+			reason = model.ScheduleVersionRollback
+		}
+		s.addScheduleVersionLocked(schedule, reason, revision.Author, revision.CreatedAt)
+	}
+	s.dirty = true
+	return nil
+}
+
+// ListScheduleRevisions 列出排班在 afterVersion 之后的修订记录（按版本升序）
+func (s *Store) ListScheduleRevisions(scheduleID uuid.UUID, afterVersion int) []*model.ScheduleRevision {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ScheduleRevision, 0)
+	for _, r := range s.revisions[scheduleID] {
+		if r.Version > afterVersion {
+			c := *r
+			result = append(result, &c)
+		}
+	}
+	return result
+}
+
+// cloneSchedule 复制排班（包括分配列表）
+func cloneSchedule(schedule *model.Schedule) *model.Schedule {
+	c := *schedule
+	if schedule.Assignments != nil {
+		c.Assignments = make([]model.Assignment, len(schedule.Assignments))
+		copy(c.Assignments, schedule.Assignments)
+	}
+	if schedule.PublishAt != nil {
+		publishAt := *schedule.PublishAt
+		c.PublishAt = &publishAt
+	}
+	if schedule.ArchivedAt != nil {
+		archivedAt := *schedule.ArchivedAt
+		c.ArchivedAt = &archivedAt
+	}
+	if schedule.Statistics != nil {
+		stats := *schedule.Statistics
+		c.Statistics = &stats
+	}
+	return &c
+}
+
+// ========================================
+// 快照持久化
+// ========================================
+
+// Snapshot 生成当前状态的快照
+func (s *Store) Snapshot() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshotLocked()
+}
+
+// snapshotLocked 生成快照（调用方需持有读锁）
+func (s *Store) snapshotLocked() *Snapshot {
+	snap := &Snapshot{
+		Version:       snapshotVersion,
+		SavedAt:       time.Now(),
+		Organizations: make([]*model.Organization, 0, len(s.orgs)),
+		Employees:     make([]*model.Employee, 0, len(s.employees)),
+		Shifts:        make([]*model.Shift, 0, len(s.shifts)),
+		Schedules:     make([]*model.Schedule, 0, len(s.schedules)),
+	}
+	for _, org := range s.orgs {
+		snap.Organizations = append(snap.Organizations, org)
+	}
+	for _, emp := range s.employees {
+		snap.Employees = append(snap.Employees, emp)
+	}
+	for _, shift := range s.shifts {
+		snap.Shifts = append(snap.Shifts, shift)
+	}
+	for _, schedule := range s.schedules {
+		snap.Schedules = append(snap.Schedules, schedule)
+	}
+	for _, revisions := range s.revisions {
+		snap.Revisions = append(snap.Revisions, revisions...)
+	}
+	for _, req := range s.requirements {
+		snap.Requirements = append(snap.Requirements, req)
+	}
+	for _, av := range s.availability {
+		snap.Availability = append(snap.Availability, av)
+	}
+	for _, summary := range s.summaries {
+		snap.Summaries = append(snap.Summaries, summary)
+	}
+	for _, dispute := range s.disputes {
+		snap.Disputes = append(snap.Disputes, dispute)
+	}
+	for _, mapping := range s.hrMappings {
+		snap.HRMappings = append(snap.HRMappings, mapping)
+	}
+	for _, conflict := range s.hrConflicts {
+		snap.HRConflicts = append(snap.HRConflicts, conflict)
+	}
+	for _, suggestion := range s.repairs {
+		snap.Repairs = append(snap.Repairs, suggestion)
+	}
+	for _, aliases := range s.aliases {
+		snap.Aliases = append(snap.Aliases, aliases...)
+	}
+	for _, label := range s.unmapped {
+		snap.Unmapped = append(snap.Unmapped, label)
+	}
+	for _, budgets := range s.storeBudgets {
+		snap.StoreBudgets = append(snap.StoreBudgets, budgets...)
+	}
+	for _, periods := range s.blackouts {
+		snap.Blackouts = append(snap.Blackouts, periods...)
+	}
+	for _, hours := range s.openingHours {
+		snap.OpeningHours = append(snap.OpeningHours, hours...)
+	}
+	for _, teams := range s.teams {
+		snap.Teams = append(snap.Teams, teams...)
+	}
+	for _, a := range s.approvals {
+		snap.Approvals = append(snap.Approvals, a)
+	}
+	for _, delegations := range s.delegations {
+		snap.Delegations = append(snap.Delegations, delegations...)
+	}
+	for _, versions := range s.constraintConfigs {
+		snap.ConstraintConfigs = append(snap.ConstraintConfigs, versions...)
+	}
+	for _, job := range s.bulkJobs {
+		snap.BulkJobs = append(snap.BulkJobs, job)
+	}
+	for _, closes := range s.payrollCloses {
+		snap.PayrollCloses = append(snap.PayrollCloses, closes...)
+	}
+	for _, a := range s.adjustments {
+		snap.Adjustments = append(snap.Adjustments, a)
+	}
+	for _, pool := range s.externalPools {
+		snap.ExternalPools = append(snap.ExternalPools, pool)
+	}
+	for _, d := range s.certDocuments {
+		snap.CertDocuments = append(snap.CertDocuments, d)
+	}
+	for _, e := range s.timeBank {
+		snap.TimeBank = append(snap.TimeBank, e)
+	}
+	for _, o := range s.orders {
+		snap.Orders = append(snap.Orders, o)
+	}
+	for _, c := range s.certificates {
+		snap.Certificates = append(snap.Certificates, c)
+	}
+	for _, imp := range s.requirementImports {
+		snap.RequirementImports = append(snap.RequirementImports, imp)
+	}
+	for _, l := range s.shareLinks {
+		snap.ShareLinks = append(snap.ShareLinks, l)
+	}
+	for _, log := range s.shareAccesses {
+		snap.ShareAccesses = append(snap.ShareAccesses, log...)
+	}
+	for _, job := range s.generateJobs {
+		snap.GenerateJobs = append(snap.GenerateJobs, job)
+	}
+	for _, entries := range s.scheduleAudit {
+		snap.ScheduleAudit = append(snap.ScheduleAudit, entries...)
+	}
+	for _, c := range s.orgConstraints {
+		snap.OrgConstraints = append(snap.OrgConstraints, c)
+	}
+	for _, t := range s.scenarioTemplates {
+		snap.ScenarioTemplates = append(snap.ScenarioTemplates, t)
+	}
+	for _, p := range s.carePlans {
+		snap.CarePlans = append(snap.CarePlans, p)
+	}
+	for _, p := range s.schedulePatterns {
+		snap.SchedulePatterns = append(snap.SchedulePatterns, p)
+	}
+	for _, h := range s.holidays {
+		snap.Holidays = append(snap.Holidays, h)
+	}
+	for _, w := range s.webhooks {
+		snap.Webhooks = append(snap.Webhooks, w)
+	}
+	for _, log := range s.webhookDeliveries {
+		snap.WebhookDeliveries = append(snap.WebhookDeliveries, log...)
+	}
+	snap.AuditLogs = append(snap.AuditLogs, s.auditLogs...)
+	for _, versions := range s.scheduleVersions {
+		snap.ScheduleVersions = append(snap.ScheduleVersions, versions...)
+	}
+	return snap
+}
+
+// Save 将快照写入磁盘
+// 先写入临时文件再重命名，避免写入中断导致快照损坏
+func (s *Store) Save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	s.mu.Lock()
+	data, err := json.Marshal(s.snapshotLocked())
+	if err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("序列化快照失败: %w", err)
+	}
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		s.markDirty()
+		return fmt.Errorf("创建快照目录失败: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		s.markDirty()
+		return fmt.Errorf("写入快照失败: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		s.markDirty()
+		return fmt.Errorf("替换快照文件失败: %w", err)
+	}
+	return nil
+}
+
+// markDirty 标记存在未持久化的变更
+func (s *Store) markDirty() {
+	s.mu.Lock()
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+// Load 从磁盘加载快照，快照文件不存在时视为空状态
+func (s *Store) Load() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取快照失败: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("解析快照失败: %w", err)
+	}
+	if snap.Version > snapshotVersion {
+		return fmt.Errorf("不支持的快照版本: %d", snap.Version)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs = make(map[uuid.UUID]*model.Organization, len(snap.Organizations))
+	for _, org := range snap.Organizations {
+		s.orgs[org.ID] = org
+	}
+	s.employees = make(map[uuid.UUID]*model.Employee, len(snap.Employees))
+	for _, emp := range snap.Employees {
+		s.employees[emp.ID] = emp
+	}
+	s.shifts = make(map[uuid.UUID]*model.Shift, len(snap.Shifts))
+	for _, shift := range snap.Shifts {
+		s.shifts[shift.ID] = shift
+	}
+	s.schedules = make(map[uuid.UUID]*model.Schedule, len(snap.Schedules))
+	for _, schedule := range snap.Schedules {
+		s.schedules[schedule.ID] = schedule
+	}
+	s.revisions = make(map[uuid.UUID][]*model.ScheduleRevision)
+	sort.Slice(snap.Revisions, func(i, j int) bool { return snap.Revisions[i].Version < snap.Revisions[j].Version })
+	for _, r := range snap.Revisions {
+		s.revisions[r.ScheduleID] = append(s.revisions[r.ScheduleID], r)
+	}
+	s.requirements = make(map[uuid.UUID]*model.ShiftRequirement, len(snap.Requirements))
+	for _, req := range snap.Requirements {
+		s.requirements[req.ID] = req
+	}
+	s.availability = make(map[string]*model.EmployeeAvailability, len(snap.Availability))
+	for _, av := range snap.Availability {
+		s.availability[availabilityKey(av.EmployeeID, av.Date)] = av
+	}
+	s.summaries = make(map[string]*model.MonthlySummary, len(snap.Summaries))
+	for _, summary := range snap.Summaries {
+		s.summaries[summaryKey(summary.EmployeeID, summary.Month)] = summary
+	}
+	s.disputes = make(map[uuid.UUID]*model.SummaryDispute, len(snap.Disputes))
+	for _, dispute := range snap.Disputes {
+		s.disputes[dispute.ID] = dispute
+	}
+	s.hrMappings = make(map[string]*model.HRMapping, len(snap.HRMappings))
+	for _, mapping := range snap.HRMappings {
+		s.hrMappings[mapping.Source] = mapping
+	}
+	s.hrConflicts = make(map[uuid.UUID]*model.HRSyncConflict, len(snap.HRConflicts))
+	for _, conflict := range snap.HRConflicts {
+		s.hrConflicts[conflict.ID] = conflict
+	}
+	s.repairs = make(map[uuid.UUID]*model.RepairSuggestion, len(snap.Repairs))
+	for _, suggestion := range snap.Repairs {
+		s.repairs[suggestion.ID] = suggestion
+	}
+	s.aliases = make(map[uuid.UUID][]*model.LabelAlias)
+	for _, a := range snap.Aliases {
+		s.aliases[a.OrgID] = append(s.aliases[a.OrgID], a)
+	}
+	s.unmapped = make(map[string]*model.UnmappedLabel, len(snap.Unmapped))
+	for _, u := range snap.Unmapped {
+		s.unmapped[unmappedKey(u.OrgID, u.Kind, u.Reason, u.Label)] = u
+	}
+	s.storeBudgets = make(map[uuid.UUID][]*model.StoreHoursBudget)
+	for _, b := range snap.StoreBudgets {
+		s.storeBudgets[b.OrgID] = append(s.storeBudgets[b.OrgID], b)
+	}
+	s.blackouts = make(map[uuid.UUID][]*model.BlackoutPeriod)
+	for _, p := range snap.Blackouts {
+		s.blackouts[p.OrgID] = append(s.blackouts[p.OrgID], p)
+	}
+	s.openingHours = make(map[uuid.UUID][]*model.StoreOpeningHours)
+	for _, h := range snap.OpeningHours {
+		s.openingHours[h.OrgID] = append(s.openingHours[h.OrgID], h)
+	}
+	s.teams = make(map[uuid.UUID][]*model.Team)
+	for _, t := range snap.Teams {
+		s.teams[t.OrgID] = append(s.teams[t.OrgID], t)
+	}
+	s.approvals = make(map[uuid.UUID]*model.ApprovalRequest, len(snap.Approvals))
+	for _, a := range snap.Approvals {
+		s.approvals[a.ID] = a
+	}
+	s.delegations = make(map[uuid.UUID][]*model.ApprovalDelegation)
+	for _, d := range snap.Delegations {
+		s.delegations[d.OrgID] = append(s.delegations[d.OrgID], d)
+	}
+	s.constraintConfigs = make(map[uuid.UUID][]*model.ConstraintConfigVersion)
+	for _, c := range snap.ConstraintConfigs {
+		s.constraintConfigs[c.OrgID] = append(s.constraintConfigs[c.OrgID], c)
+	}
+	for _, versions := range s.constraintConfigs {
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	}
+	s.bulkJobs = make(map[uuid.UUID]*model.BulkJob, len(snap.BulkJobs))
+	for _, job := range snap.BulkJobs {
+		s.bulkJobs[job.ID] = job
+	}
+	s.payrollCloses = make(map[uuid.UUID][]*model.PayrollClose)
+	sort.Slice(snap.PayrollCloses, func(i, j int) bool { return snap.PayrollCloses[i].ClosedAt.Before(snap.PayrollCloses[j].ClosedAt) })
+	for _, c := range snap.PayrollCloses {
+		s.payrollCloses[c.OrgID] = append(s.payrollCloses[c.OrgID], c)
+	}
+	s.adjustments = make(map[uuid.UUID]*model.PayrollAdjustment, len(snap.Adjustments))
+	for _, a := range snap.Adjustments {
+		s.adjustments[a.ID] = a
+	}
+	s.externalPools = make(map[uuid.UUID]*model.ExternalPool, len(snap.ExternalPools))
+	for _, pool := range snap.ExternalPools {
+		s.externalPools[pool.OrgID] = pool
+	}
+	s.certDocuments = make(map[uuid.UUID]*model.CertificationDocument, len(snap.CertDocuments))
+	for _, d := range snap.CertDocuments {
+		s.certDocuments[d.ID] = d
+	}
+	s.timeBank = make(map[uuid.UUID]*model.TimeBankEntry, len(snap.TimeBank))
+	for _, e := range snap.TimeBank {
+		s.timeBank[e.ID] = e
+	}
+	s.orders = make(map[uuid.UUID]*model.ServiceOrder, len(snap.Orders))
+	for _, o := range snap.Orders {
+		s.orders[o.ID] = o
+	}
+	s.certificates = make(map[uuid.UUID]*model.ComplianceCertificate, len(snap.Certificates))
+	for _, c := range snap.Certificates {
+		s.certificates[c.ID] = c
+	}
+	s.requirementImports = make(map[uuid.UUID]*model.RequirementImport, len(snap.RequirementImports))
+	for _, imp := range snap.RequirementImports {
+		s.requirementImports[imp.ID] = imp
+	}
+	s.shareLinks = make(map[uuid.UUID]*model.ShareLink, len(snap.ShareLinks))
+	for _, l := range snap.ShareLinks {
+		s.shareLinks[l.ID] = l
+	}
+	s.shareAccesses = make(map[uuid.UUID][]*model.ShareAccess)
+	for _, a := range snap.ShareAccesses {
+		s.shareAccesses[a.LinkID] = append(s.shareAccesses[a.LinkID], a)
+	}
+	s.generateJobs = make(map[uuid.UUID]*model.GenerateJob, len(snap.GenerateJobs))
+	for _, job := range snap.GenerateJobs {
+		s.generateJobs[job.ID] = job
+	}
+	s.scheduleAudit = make(map[uuid.UUID][]*model.ScheduleAuditEntry)
+	for _, e := range snap.ScheduleAudit {
+		s.scheduleAudit[e.ScheduleID] = append(s.scheduleAudit[e.ScheduleID], e)
+	}
+	s.orgConstraints = make(map[uuid.UUID]*model.OrgConstraint, len(snap.OrgConstraints))
+	for _, c := range snap.OrgConstraints {
+		s.orgConstraints[c.ID] = c
+	}
+	s.scenarioTemplates = make(map[uuid.UUID]*model.ScenarioTemplate, len(snap.ScenarioTemplates))
+	for _, t := range snap.ScenarioTemplates {
+		s.scenarioTemplates[t.ID] = t
+	}
+	s.carePlans = make(map[uuid.UUID]*model.CarePlan, len(snap.CarePlans))
+	for _, p := range snap.CarePlans {
+		s.carePlans[p.ID] = p
+	}
+	s.schedulePatterns = make(map[uuid.UUID]*model.SchedulePattern, len(snap.SchedulePatterns))
+	for _, p := range snap.SchedulePatterns {
+		s.schedulePatterns[p.ID] = p
+	}
+	s.holidays = make(map[uuid.UUID]*model.Holiday, len(snap.Holidays))
+	for _, h := range snap.Holidays {
+		s.holidays[h.ID] = h
+	}
+	s.webhooks = make(map[uuid.UUID]*model.Webhook, len(snap.Webhooks))
+	for _, w := range snap.Webhooks {
+		s.webhooks[w.ID] = w
+	}
+	s.webhookDeliveries = make(map[uuid.UUID][]*model.WebhookDelivery)
+	for _, d := range snap.WebhookDeliveries {
+		s.webhookDeliveries[d.WebhookID] = append(s.webhookDeliveries[d.WebhookID], d)
+	}
+	s.auditLogs = snap.AuditLogs
+	s.scheduleVersions = make(map[uuid.UUID][]*model.ScheduleVersion)
+	sort.Slice(snap.ScheduleVersions, func(i, j int) bool { return snap.ScheduleVersions[i].Version < snap.ScheduleVersions[j].Version })
+	for _, v := range snap.ScheduleVersions {
+		s.scheduleVersions[v.ScheduleID] = append(s.scheduleVersions[v.ScheduleID], v)
+	}
+	s.dirty = false
+	return nil
+}
+
+// Run 定期保存快照，直到 ctx 取消；退出前会保存最后一次快照
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	if s.path == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Save(); err != nil {
+				logger.Error().Err(err).Str("path", s.path).Msg("保存内存快照失败")
+			}
+			return
+		case <-ticker.C:
+			s.mu.RLock()
+			dirty := s.dirty
+			s.mu.RUnlock()
+			if !dirty {
+				continue
+			}
+			if err := s.Save(); err != nil {
+				logger.Error().Err(err).Str("path", s.path).Msg("保存内存快照失败")
+			}
+		}
+	}
+}
+
+// Stats 返回各类记录数量
+func (s *Store) Stats() map[string]int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return map[string]int{
+		"organizations": len(s.orgs),
+		"employees":     len(s.employees),
+		"shifts":        len(s.shifts),
+		"schedules":     len(s.schedules),
+		"requirements":  len(s.requirements),
+		"availability":  len(s.availability),
+	}
+}
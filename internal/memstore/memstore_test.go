@@ -0,0 +1,158 @@
+package memstore
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestStore_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	orgID := uuid.New()
+
+	store := New(path)
+	emp := &model.Employee{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "张三", Code: "E001"}
+	shift := &model.Shift{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "早班", StartTime: "08:00"}
+	schedule := &model.Schedule{
+		BaseModel:   model.NewBaseModel(),
+		OrgID:       orgID,
+		Status:      "draft",
+		Assignments: []model.Assignment{{EmployeeID: emp.ID, ShiftID: shift.ID, Date: "2026-01-05"}},
+	}
+
+	if err := store.PutEmployee(emp); err != nil {
+		t.Fatalf("PutEmployee() error = %v", err)
+	}
+	store.PutShift(shift)
+	store.PutSchedule(schedule)
+	store.PutOrganization(&model.Organization{BaseModel: model.BaseModel{ID: orgID}, Name: "测试门店"})
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded := New(path)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	stats := loaded.Stats()
+	for _, key := range []string{"organizations", "employees", "shifts", "schedules"} {
+		if stats[key] != 1 {
+			t.Errorf("%s 数量 = %d, expected 1", key, stats[key])
+		}
+	}
+
+	got, err := loaded.GetSchedule(schedule.ID)
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if len(got.Assignments) != 1 || got.Assignments[0].EmployeeID != emp.ID {
+		t.Error("排班分配未正确恢复")
+	}
+	if len(loaded.ListEmployees(orgID)) != 1 {
+		t.Error("应能按组织列出员工")
+	}
+	if len(loaded.ListEmployees(uuid.New())) != 0 {
+		t.Error("其他组织不应有员工")
+	}
+}
+
+func TestStore_LoadMissingFile(t *testing.T) {
+	store := New(filepath.Join(t.TempDir(), "missing.json"))
+	if err := store.Load(); err != nil {
+		t.Errorf("快照不存在时不应报错: %v", err)
+	}
+}
+
+func TestStore_ReturnsCopies(t *testing.T) {
+	store := New("")
+	emp := &model.Employee{
+		BaseModel:   model.NewBaseModel(),
+		Name:        "张三",
+		Skills:      []string{"收银"},
+		Preferences: &model.EmployeePreferences{PreferredShifts: []string{"D"}, CustomPreferences: map[string]string{"k": "v"}},
+	}
+	store.PutEmployee(emp)
+
+	emp.Name = "李四"
+	emp.Skills[0] = "厨师"
+	emp.Preferences.PreferredShifts[0] = "N"
+	emp.Preferences.CustomPreferences["k"] = "x"
+	got, _ := store.GetEmployee(emp.ID)
+	if got.Name != "张三" || got.Skills[0] != "收银" || got.Preferences.PreferredShifts[0] != "D" || got.Preferences.CustomPreferences["k"] != "v" {
+		t.Errorf("存储的记录不应受调用方修改影响: %+v", got)
+	}
+
+	got.Name = "王五"
+	got.Skills[0] = "厨师"
+	got.Preferences.PreferredShifts[0] = "N"
+	got.Preferences.CustomPreferences["k"] = "x"
+	again, _ := store.GetEmployee(emp.ID)
+	if again.Name != "张三" || again.Skills[0] != "收银" || again.Preferences.PreferredShifts[0] != "D" || again.Preferences.CustomPreferences["k"] != "v" {
+		t.Errorf("返回的记录不应影响存储内容: %+v", again)
+	}
+	listed := store.ListEmployees(uuid.Nil)
+	listed[0].Skills[0] = "厨师"
+	if again, _ := store.GetEmployee(emp.ID); again.Skills[0] != "收银" {
+		t.Error("列表返回的记录不应影响存储内容")
+	}
+
+	if _, err := store.GetEmployee(uuid.New()); err != ErrNotFound {
+		t.Errorf("GetEmployee() error = %v, expected ErrNotFound", err)
+	}
+	if err := store.PutEmployee(&model.Employee{}); err != ErrInvalid {
+		t.Errorf("PutEmployee() error = %v, expected ErrInvalid", err)
+	}
+}
+
+func TestStore_ConcurrentAccess(t *testing.T) {
+	store := New(filepath.Join(t.TempDir(), "state.json"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			store.PutEmployee(&model.Employee{BaseModel: model.NewBaseModel()})
+		}()
+		go func() {
+			defer wg.Done()
+			store.ListEmployees(uuid.Nil)
+			store.Save()
+		}()
+	}
+	wg.Wait()
+
+	if store.Stats()["employees"] != 20 {
+		t.Errorf("员工数量 = %d, expected 20", store.Stats()["employees"])
+	}
+}
+
+func TestStore_RunSavesOnCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := New(path)
+	store.PutEmployee(&model.Employee{BaseModel: model.NewBaseModel()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		store.Run(ctx, time.Hour)
+		close(done)
+	}()
+	cancel()
+	<-done
+
+	loaded := New(path)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Stats()["employees"] != 1 {
+		t.Error("取消时应保存最后一次快照")
+	}
+}
@@ -0,0 +1,46 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 门店营业时间
+// ========================================
+
+// ReplaceOpeningHours 替换组织的全部门店营业时间
+func (s *Store) ReplaceOpeningHours(orgID uuid.UUID, hours []*model.StoreOpeningHours) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*model.StoreOpeningHours, 0, len(hours))
+	seen := make(map[string]bool, len(hours))
+	for _, h := range hours {
+		if h == nil || h.StoreID == "" || seen[h.StoreID] {
+			return ErrInvalid
+		}
+		seen[h.StoreID] = true
+		c := *h
+		c.OrgID = orgID
+		list = append(list, &c)
+	}
+	s.openingHours[orgID] = list
+	s.dirty = true
+	return nil
+}
+
+// ListOpeningHours 列出组织的门店营业时间（按门店排序）
+func (s *Store) ListOpeningHours(orgID uuid.UUID) []*model.StoreOpeningHours {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.StoreOpeningHours, 0, len(s.openingHours[orgID]))
+	for _, h := range s.openingHours[orgID] {
+		c := *h
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StoreID < result[j].StoreID })
+	return result
+}
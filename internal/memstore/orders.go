@@ -0,0 +1,70 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 服务订单
+// ========================================
+
+// PutOrder 保存服务订单（新增或覆盖）
+func (s *Store) PutOrder(o *model.ServiceOrder) error {
+	if o == nil || o.ID == uuid.Nil || o.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders[o.ID] = cloneOrder(o)
+	s.dirty = true
+	return nil
+}
+
+// GetOrder 获取服务订单
+func (s *Store) GetOrder(id uuid.UUID) (*model.ServiceOrder, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	o, ok := s.orders[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneOrder(o), nil
+}
+
+// ListOrders 列出组织下服务日期在 [start, end] 内的订单（按服务日期、开始时间升序）
+// start/end 为空表示不限，status 为空表示不限状态
+func (s *Store) ListOrders(orgID uuid.UUID, start, end, status string) []*model.ServiceOrder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ServiceOrder, 0)
+	for _, o := range s.orders {
+		if o.OrgID != orgID || (status != "" && o.Status != status) {
+			continue
+		}
+		if (start != "" && o.ServiceDate < start) || (end != "" && o.ServiceDate > end) {
+			continue
+		}
+		result = append(result, cloneOrder(o))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ServiceDate != result[j].ServiceDate {
+			return result[i].ServiceDate < result[j].ServiceDate
+		}
+		return result[i].StartTime < result[j].StartTime
+	})
+	return result
+}
+
+// cloneOrder 复制订单，完成凭证单独复制
+func cloneOrder(o *model.ServiceOrder) *model.ServiceOrder {
+	c := *o
+	if o.CompletionProof != nil {
+		proof := *o.CompletionProof
+		proof.Photos = append([]string(nil), o.CompletionProof.Photos...)
+		c.CompletionProof = &proof
+	}
+	return &c
+}
@@ -0,0 +1,76 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 组织级约束配置
+// ========================================
+
+// PutOrgConstraint 新增或更新组织级约束配置（同一组织内约束名称唯一）
+func (s *Store) PutOrgConstraint(c *model.OrgConstraint) error {
+	if c == nil || c.ID == uuid.Nil || c.OrgID == uuid.Nil || c.Name == "" {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.orgConstraints {
+		if existing.ID != c.ID && existing.OrgID == c.OrgID && existing.Name == c.Name {
+			return ErrDuplicate
+		}
+	}
+	s.orgConstraints[c.ID] = cloneOrgConstraint(c)
+	s.dirty = true
+	return nil
+}
+
+// GetOrgConstraint 获取组织级约束配置
+func (s *Store) GetOrgConstraint(id uuid.UUID) (*model.OrgConstraint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.orgConstraints[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneOrgConstraint(c), nil
+}
+
+// ListOrgConstraints 列出组织的约束配置（按名称排序），enabledOnly 时只列出已启用的
+func (s *Store) ListOrgConstraints(orgID uuid.UUID, enabledOnly bool) []*model.OrgConstraint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.OrgConstraint, 0)
+	for _, c := range s.orgConstraints {
+		if c.OrgID == orgID && (c.Enabled || !enabledOnly) {
+			result = append(result, cloneOrgConstraint(c))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// DeleteOrgConstraint 删除组织级约束配置
+func (s *Store) DeleteOrgConstraint(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.orgConstraints[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.orgConstraints, id)
+	s.dirty = true
+	return nil
+}
+
+// cloneOrgConstraint 复制约束配置（顶层参数键）
+func cloneOrgConstraint(c *model.OrgConstraint) *model.OrgConstraint {
+	cp := *c
+	cp.Params = make(model.JSONMap, len(c.Params))
+	for k, v := range c.Params {
+		cp.Params[k] = v
+	}
+	return &cp
+}
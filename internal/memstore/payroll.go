@@ -0,0 +1,97 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 工资结账与调整
+// ========================================
+
+// AddPayrollClose 记录工资结账，并将组织尚未结算的调整标记为纳入本次结账，返回纳入数量
+func (s *Store) AddPayrollClose(c *model.PayrollClose) (int, error) {
+	if c == nil || c.ID == uuid.Nil || c.OrgID == uuid.Nil || c.LockedBefore == "" {
+		return 0, ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	settled := 0
+	for _, a := range s.adjustments {
+		if a.OrgID != c.OrgID || a.IsSettled() {
+			continue
+		}
+		id, at := c.ID, c.ClosedAt
+		a.SettledIn, a.SettledAt = &id, &at
+		settled++
+	}
+	cc := *c
+	cc.Settled = settled
+	s.payrollCloses[c.OrgID] = append(s.payrollCloses[c.OrgID], &cc)
+	s.dirty = true
+	return settled, nil
+}
+
+// ListPayrollCloses 列出组织的工资结账记录（按结账时间升序）
+func (s *Store) ListPayrollCloses(orgID uuid.UUID) []*model.PayrollClose {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.PayrollClose, 0, len(s.payrollCloses[orgID]))
+	for _, c := range s.payrollCloses[orgID] {
+		cc := *c
+		result = append(result, &cc)
+	}
+	return result
+}
+
+// PayrollLockedBefore 返回组织已结账的截止日期（该日期之前的分配已锁定），未结账时返回空字符串
+func (s *Store) PayrollLockedBefore(orgID uuid.UUID) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	locked := ""
+	for _, c := range s.payrollCloses[orgID] {
+		if c.LockedBefore > locked {
+			locked = c.LockedBefore
+		}
+	}
+	return locked
+}
+
+// AddPayrollAdjustments 保存分配调整记录
+func (s *Store) AddPayrollAdjustments(adjustments []*model.PayrollAdjustment) error {
+	for _, a := range adjustments {
+		if a == nil || a.ID == uuid.Nil || a.OrgID == uuid.Nil {
+			return ErrInvalid
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range adjustments {
+		c := *a
+		s.adjustments[a.ID] = &c
+	}
+	s.dirty = true
+	return nil
+}
+
+// ListPayrollAdjustments 列出组织的分配调整（按创建时间升序）
+// status 为 pending 时只返回未结算的调整，settled 时只返回已结算的调整，为空表示全部
+func (s *Store) ListPayrollAdjustments(orgID uuid.UUID, status string) []*model.PayrollAdjustment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.PayrollAdjustment, 0)
+	for _, a := range s.adjustments {
+		if a.OrgID != orgID {
+			continue
+		}
+		if (status == "pending" && a.IsSettled()) || (status == "settled" && !a.IsSettled()) {
+			continue
+		}
+		c := *a
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
@@ -0,0 +1,59 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// POS 需求导入
+// ========================================
+
+// PutRequirementImport 保存需求导入记录（新增或覆盖）
+func (s *Store) PutRequirementImport(imp *model.RequirementImport) error {
+	if imp == nil || imp.ID == uuid.Nil || imp.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requirementImports[imp.ID] = cloneRequirementImport(imp)
+	s.dirty = true
+	return nil
+}
+
+// GetRequirementImport 获取需求导入记录
+func (s *Store) GetRequirementImport(id uuid.UUID) (*model.RequirementImport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	imp, ok := s.requirementImports[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneRequirementImport(imp), nil
+}
+
+// ListRequirementImports 列出组织的需求导入记录（按导入时间倒序），status 为空表示不限
+func (s *Store) ListRequirementImports(orgID uuid.UUID, status string) []*model.RequirementImport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.RequirementImport, 0)
+	for _, imp := range s.requirementImports {
+		if imp.OrgID != orgID || (status != "" && imp.Status != status) {
+			continue
+		}
+		result = append(result, cloneRequirementImport(imp))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
+// cloneRequirementImport 复制需求导入记录，业务量曲线和需求单独复制
+func cloneRequirementImport(imp *model.RequirementImport) *model.RequirementImport {
+	c := *imp
+	c.Curve = append([]model.HourlyDemand(nil), imp.Curve...)
+	c.Requirements = append([]model.ShiftRequirement(nil), imp.Requirements...)
+	c.Uncovered = append([]model.HourlyDemand(nil), imp.Uncovered...)
+	return &c
+}
@@ -0,0 +1,134 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 排班需求
+// ========================================
+
+// PutRequirement 保存排班需求（新增或覆盖）
+func (s *Store) PutRequirement(req *model.ShiftRequirement) error {
+	if req == nil || req.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *req
+	s.requirements[req.ID] = &c
+	s.dirty = true
+	return nil
+}
+
+// GetRequirement 获取排班需求
+func (s *Store) GetRequirement(id uuid.UUID) (*model.ShiftRequirement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	req, ok := s.requirements[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *req
+	return &c, nil
+}
+
+// ListRequirements 列出组织在日期范围内的排班需求（按日期升序）
+// orgID 为 uuid.Nil 时返回全部组织，startDate/endDate 为空表示不限
+func (s *Store) ListRequirements(orgID uuid.UUID, startDate, endDate string) []*model.ShiftRequirement {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ShiftRequirement, 0)
+	for _, req := range s.requirements {
+		if orgID != uuid.Nil && req.OrgID != orgID {
+			continue
+		}
+		if (startDate != "" && req.Date < startDate) || (endDate != "" && req.Date > endDate) {
+			continue
+		}
+		c := *req
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+// ReplaceRequirements 用 reqs 替换组织在日期范围内的全部排班需求
+// 重复生成同一周期的排班时避免需求重复累计
+func (s *Store) ReplaceRequirements(orgID uuid.UUID, startDate, endDate string, reqs []*model.ShiftRequirement) error {
+	for _, req := range reqs {
+		if req == nil || req.ID == uuid.Nil {
+			return ErrInvalid
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, req := range s.requirements {
+		if req.OrgID == orgID && req.Date >= startDate && req.Date <= endDate {
+			delete(s.requirements, id)
+		}
+	}
+	for _, req := range reqs {
+		c := *req
+		s.requirements[req.ID] = &c
+	}
+	s.dirty = true
+	return nil
+}
+
+// DeleteRequirement 删除排班需求
+func (s *Store) DeleteRequirement(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.requirements[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.requirements, id)
+	s.dirty = true
+	return nil
+}
+
+// ========================================
+// 员工可用性
+// ========================================
+
+// availabilityKey 可用性记录键（员工+日期）
+func availabilityKey(employeeID uuid.UUID, date string) string {
+	return employeeID.String() + "/" + date
+}
+
+// PutAvailability 保存员工某日的可用性（同一员工同一天覆盖）
+func (s *Store) PutAvailability(av *model.EmployeeAvailability) error {
+	if av == nil || av.EmployeeID == uuid.Nil || av.Date == "" {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *av
+	s.availability[availabilityKey(av.EmployeeID, av.Date)] = &c
+	s.dirty = true
+	return nil
+}
+
+// ListAvailability 列出员工在日期范围内的可用性（按日期升序）
+// employeeID 为 uuid.Nil 时返回全部员工，startDate/endDate 为空表示不限
+func (s *Store) ListAvailability(employeeID uuid.UUID, startDate, endDate string) []*model.EmployeeAvailability {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.EmployeeAvailability, 0)
+	for _, av := range s.availability {
+		if employeeID != uuid.Nil && av.EmployeeID != employeeID {
+			continue
+		}
+		if (startDate != "" && av.Date < startDate) || (endDate != "" && av.Date > endDate) {
+			continue
+		}
+		c := *av
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
@@ -0,0 +1,86 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 自定义场景模板
+// ========================================
+
+// PutScenarioTemplate 新增或更新自定义场景模板（场景标识唯一）
+func (s *Store) PutScenarioTemplate(t *model.ScenarioTemplate) error {
+	if t == nil || t.ID == uuid.Nil || t.Scenario == "" {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.scenarioTemplates {
+		if existing.ID != t.ID && existing.Scenario == t.Scenario {
+			return ErrDuplicate
+		}
+	}
+	s.scenarioTemplates[t.ID] = cloneScenarioTemplate(t)
+	s.dirty = true
+	return nil
+}
+
+// GetScenarioTemplate 获取自定义场景模板
+func (s *Store) GetScenarioTemplate(id uuid.UUID) (*model.ScenarioTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.scenarioTemplates[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneScenarioTemplate(t), nil
+}
+
+// GetScenarioTemplateByScenario 按场景标识获取自定义场景模板
+func (s *Store) GetScenarioTemplateByScenario(scenario string) (*model.ScenarioTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, t := range s.scenarioTemplates {
+		if t.Scenario == scenario {
+			return cloneScenarioTemplate(t), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ListScenarioTemplates 列出自定义场景模板（按场景标识排序）
+func (s *Store) ListScenarioTemplates() []*model.ScenarioTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ScenarioTemplate, 0, len(s.scenarioTemplates))
+	for _, t := range s.scenarioTemplates {
+		result = append(result, cloneScenarioTemplate(t))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Scenario < result[j].Scenario })
+	return result
+}
+
+// DeleteScenarioTemplate 删除自定义场景模板
+func (s *Store) DeleteScenarioTemplate(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.scenarioTemplates[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.scenarioTemplates, id)
+	s.dirty = true
+	return nil
+}
+
+// cloneScenarioTemplate 复制场景模板（顶层约束配置键）
+func cloneScenarioTemplate(t *model.ScenarioTemplate) *model.ScenarioTemplate {
+	cp := *t
+	cp.Constraints = make(model.JSONMap, len(t.Constraints))
+	for k, v := range t.Constraints {
+		cp.Constraints[k] = v
+	}
+	return &cp
+}
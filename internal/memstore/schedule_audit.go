@@ -0,0 +1,39 @@
+package memstore
+
+import (
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 排班生命周期审计
+// ========================================
+
+// AddScheduleAudit 追加排班生命周期审计记录（排班删除后记录仍保留）
+func (s *Store) AddScheduleAudit(entry *model.ScheduleAuditEntry) error {
+	if entry == nil || entry.ScheduleID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *entry
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	s.scheduleAudit[c.ScheduleID] = append(s.scheduleAudit[c.ScheduleID], &c)
+	s.dirty = true
+	return nil
+}
+
+// ListScheduleAudit 列出排班的生命周期审计记录（按时间升序）
+func (s *Store) ListScheduleAudit(scheduleID uuid.UUID) []*model.ScheduleAuditEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries := s.scheduleAudit[scheduleID]
+	result := make([]*model.ScheduleAuditEntry, 0, len(entries))
+	for _, e := range entries {
+		c := *e
+		result = append(result, &c)
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 循环排班模板
+// ========================================
+
+// PutSchedulePattern 保存循环排班模板（新增或覆盖）
+func (s *Store) PutSchedulePattern(p *model.SchedulePattern) error {
+	if p == nil || p.ID == uuid.Nil || p.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedulePatterns[p.ID] = cloneSchedulePattern(p)
+	s.dirty = true
+	return nil
+}
+
+// GetSchedulePattern 获取循环排班模板
+func (s *Store) GetSchedulePattern(id uuid.UUID) (*model.SchedulePattern, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.schedulePatterns[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneSchedulePattern(p), nil
+}
+
+// ListSchedulePatterns 列出组织的循环排班模板（按名称升序）
+func (s *Store) ListSchedulePatterns(orgID uuid.UUID) []*model.SchedulePattern {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.SchedulePattern, 0)
+	for _, p := range s.schedulePatterns {
+		if p.OrgID == orgID {
+			result = append(result, cloneSchedulePattern(p))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// DeleteSchedulePattern 删除循环排班模板
+func (s *Store) DeleteSchedulePattern(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schedulePatterns[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.schedulePatterns, id)
+	s.dirty = true
+	return nil
+}
+
+// cloneSchedulePattern 复制循环排班模板（班次定义和排班）
+func cloneSchedulePattern(p *model.SchedulePattern) *model.SchedulePattern {
+	c := *p
+	c.Shifts = make([]*model.Shift, 0, len(p.Shifts))
+	for _, shift := range p.Shifts {
+		if shift != nil {
+			cp := *shift
+			c.Shifts = append(c.Shifts, &cp)
+		}
+	}
+	c.Entries = append([]model.PatternEntry(nil), p.Entries...)
+	return &c
+}
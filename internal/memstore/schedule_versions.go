@@ -0,0 +1,92 @@
+package memstore
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 排班版本快照
+// ========================================
+
+// maxScheduleVersions 每个排班保留的版本快照数，超出时丢弃最早的版本
+const maxScheduleVersions = 200
+
+// AddScheduleVersion 保存排班当前内容为版本快照（生成、重新生成、发布时调用）
+// 已有同一版本的快照时覆盖
+func (s *Store) AddScheduleVersion(schedule *model.Schedule, reason, author string) error {
+	if schedule == nil || schedule.ID == uuid.Nil || reason == "" {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addScheduleVersionLocked(schedule, reason, author, time.Now())
+	s.dirty = true
+	return nil
+}
+
+// addScheduleVersionLocked 保存版本快照（调用方需持有写锁）
+func (s *Store) addScheduleVersionLocked(schedule *model.Schedule, reason, author string, at time.Time) {
+	c := cloneSchedule(schedule)
+	v := &model.ScheduleVersion{
+		ScheduleID:  c.ID,
+		Version:     c.Version,
+		Reason:      reason,
+		Status:      c.Status,
+		Author:      author,
+		Assignments: c.Assignments,
+		Statistics:  c.Statistics,
+		CreatedAt:   at,
+	}
+	versions := s.scheduleVersions[c.ID]
+	for i, existing := range versions {
+		if existing.Version == v.Version {
+			versions[i] = v
+			return
+		}
+	}
+	versions = append(versions, v)
+	if len(versions) > maxScheduleVersions {
+		versions = versions[len(versions)-maxScheduleVersions:]
+	}
+	s.scheduleVersions[c.ID] = versions
+}
+
+// ListScheduleVersions 列出排班的版本快照（按版本升序）
+func (s *Store) ListScheduleVersions(scheduleID uuid.UUID) []*model.ScheduleVersion {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ScheduleVersion, 0, len(s.scheduleVersions[scheduleID]))
+	for _, v := range s.scheduleVersions[scheduleID] {
+		result = append(result, cloneScheduleVersion(v))
+	}
+	return result
+}
+
+// GetScheduleVersion 获取排班的指定版本快照
+func (s *Store) GetScheduleVersion(scheduleID uuid.UUID, version int) (*model.ScheduleVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.scheduleVersions[scheduleID] {
+		if v.Version == version {
+			return cloneScheduleVersion(v), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// cloneScheduleVersion 复制版本快照（包括分配列表）
+func cloneScheduleVersion(v *model.ScheduleVersion) *model.ScheduleVersion {
+	c := *v
+	if v.Assignments != nil {
+		c.Assignments = make([]model.Assignment, len(v.Assignments))
+		copy(c.Assignments, v.Assignments)
+	}
+	if v.Statistics != nil {
+		stats := *v.Statistics
+		c.Statistics = &stats
+	}
+	return &c
+}
@@ -0,0 +1,92 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// maxShareAccesses 每个分享链接保留的访问记录数，超出时丢弃最早的记录
+const maxShareAccesses = 500
+
+// ========================================
+// 排班分享链接
+// ========================================
+
+// PutShareLink 保存分享链接（新增或覆盖）
+func (s *Store) PutShareLink(l *model.ShareLink) error {
+	if l == nil || l.ID == uuid.Nil || l.OrgID == uuid.Nil || l.ScheduleID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *l
+	s.shareLinks[l.ID] = &c
+	s.dirty = true
+	return nil
+}
+
+// GetShareLink 获取分享链接
+func (s *Store) GetShareLink(id uuid.UUID) (*model.ShareLink, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	l, ok := s.shareLinks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *l
+	return &c, nil
+}
+
+// ListShareLinks 列出排班的分享链接（按创建时间倒序）
+func (s *Store) ListShareLinks(scheduleID uuid.UUID) []*model.ShareLink {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.ShareLink, 0)
+	for _, l := range s.shareLinks {
+		if l.ScheduleID == scheduleID {
+			c := *l
+			result = append(result, &c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result
+}
+
+// RecordShareAccess 记录分享链接访问，访问成功时累计链接的访问次数
+func (s *Store) RecordShareAccess(a *model.ShareAccess) error {
+	if a == nil || a.LinkID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.Denied == "" {
+		if l, ok := s.shareLinks[a.LinkID]; ok {
+			at := a.At
+			l.Accesses++
+			l.LastAccessAt = &at
+		}
+	}
+	c := *a
+	log := append(s.shareAccesses[a.LinkID], &c)
+	if len(log) > maxShareAccesses {
+		log = log[len(log)-maxShareAccesses:]
+	}
+	s.shareAccesses[a.LinkID] = log
+	s.dirty = true
+	return nil
+}
+
+// ListShareAccesses 列出分享链接的访问记录（按时间倒序）
+func (s *Store) ListShareAccesses(linkID uuid.UUID) []*model.ShareAccess {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	log := s.shareAccesses[linkID]
+	result := make([]*model.ShareAccess, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		c := *log[i]
+		result = append(result, &c)
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 月度汇总
+// ========================================
+
+// summaryKey 月度汇总记录键（员工+月份）
+func summaryKey(employeeID uuid.UUID, month string) string {
+	return employeeID.String() + "/" + month
+}
+
+// PutMonthlySummary 保存员工月度汇总（同一员工同一月份覆盖）
+func (s *Store) PutMonthlySummary(summary *model.MonthlySummary) error {
+	if summary == nil || summary.EmployeeID == uuid.Nil || summary.Month == "" {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.summaries[summaryKey(summary.EmployeeID, summary.Month)] = cloneSummary(summary)
+	s.dirty = true
+	return nil
+}
+
+// GetMonthlySummary 获取员工月度汇总
+func (s *Store) GetMonthlySummary(employeeID uuid.UUID, month string) (*model.MonthlySummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	summary, ok := s.summaries[summaryKey(employeeID, month)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneSummary(summary), nil
+}
+
+// cloneSummary 复制月度汇总
+func cloneSummary(summary *model.MonthlySummary) *model.MonthlySummary {
+	c := *summary
+	if summary.Entries != nil {
+		c.Entries = make([]model.SummaryEntry, len(summary.Entries))
+		copy(c.Entries, summary.Entries)
+	}
+	if summary.DeliveredAt != nil {
+		deliveredAt := *summary.DeliveredAt
+		c.DeliveredAt = &deliveredAt
+	}
+	return &c
+}
+
+// ========================================
+// 汇总争议
+// ========================================
+
+// PutDispute 保存汇总争议（新增或覆盖）
+func (s *Store) PutDispute(dispute *model.SummaryDispute) error {
+	if dispute == nil || dispute.ID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *dispute
+	s.disputes[dispute.ID] = &c
+	s.dirty = true
+	return nil
+}
+
+// GetDispute 获取汇总争议
+func (s *Store) GetDispute(id uuid.UUID) (*model.SummaryDispute, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dispute, ok := s.disputes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *dispute
+	return &c, nil
+}
+
+// ListDisputes 列出组织下的汇总争议（按创建时间升序）
+// orgID 为 uuid.Nil 时返回全部组织，status 为空表示不限状态
+func (s *Store) ListDisputes(orgID uuid.UUID, status string) []*model.SummaryDispute {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.SummaryDispute, 0)
+	for _, dispute := range s.disputes {
+		if orgID != uuid.Nil && dispute.OrgID != orgID {
+			continue
+		}
+		if status != "" && dispute.Status != status {
+			continue
+		}
+		c := *dispute
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
@@ -0,0 +1,52 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 班组
+// ========================================
+
+// ReplaceTeams 替换组织的全部班组
+func (s *Store) ReplaceTeams(orgID uuid.UUID, teams []*model.Team) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*model.Team, 0, len(teams))
+	seen := make(map[string]bool, len(teams))
+	for _, t := range teams {
+		if t == nil || t.ID == "" || seen[t.ID] {
+			return ErrInvalid
+		}
+		seen[t.ID] = true
+		c := cloneTeam(t)
+		c.OrgID = orgID
+		list = append(list, c)
+	}
+	s.teams[orgID] = list
+	s.dirty = true
+	return nil
+}
+
+// ListTeams 列出组织的班组（按班组ID排序）
+func (s *Store) ListTeams(orgID uuid.UUID) []*model.Team {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.Team, 0, len(s.teams[orgID]))
+	for _, t := range s.teams[orgID] {
+		result = append(result, cloneTeam(t))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// cloneTeam 复制班组（包括成员列表）
+func cloneTeam(t *model.Team) *model.Team {
+	c := *t
+	c.Members = append([]uuid.UUID(nil), t.Members...)
+	return &c
+}
@@ -0,0 +1,58 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 调休账户
+// ========================================
+
+// AddTimeBankEntry 新增调休账户记录
+// 关联审批单的记录每个审批单只记录一次，重复时返回 false
+func (s *Store) AddTimeBankEntry(e *model.TimeBankEntry) (bool, error) {
+	if e == nil || e.ID == uuid.Nil || e.OrgID == uuid.Nil || e.EmployeeID == uuid.Nil {
+		return false, ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.ApprovalID != nil {
+		for _, existing := range s.timeBank {
+			if existing.ApprovalID != nil && *existing.ApprovalID == *e.ApprovalID {
+				return false, nil
+			}
+		}
+	}
+	c := *e
+	s.timeBank[e.ID] = &c
+	s.dirty = true
+	return true, nil
+}
+
+// ListTimeBankEntries 列出调休账户记录（按日期、创建时间升序）
+// orgID 为 uuid.Nil 时返回全部组织，employeeID 为 uuid.Nil 时不限员工，startDate/endDate 为空表示不限
+func (s *Store) ListTimeBankEntries(orgID, employeeID uuid.UUID, startDate, endDate string) []*model.TimeBankEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.TimeBankEntry, 0)
+	for _, e := range s.timeBank {
+		if (orgID != uuid.Nil && e.OrgID != orgID) || (employeeID != uuid.Nil && e.EmployeeID != employeeID) {
+			continue
+		}
+		if (startDate != "" && e.Date < startDate) || (endDate != "" && e.Date > endDate) {
+			continue
+		}
+		c := *e
+		result = append(result, &c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date != result[j].Date {
+			return result[i].Date < result[j].Date
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
@@ -0,0 +1,106 @@
+package memstore
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ========================================
+// 通知订阅
+// ========================================
+
+// maxWebhookDeliveries 每个通知订阅保留的投递记录数
+const maxWebhookDeliveries = 100
+
+// PutWebhook 保存通知订阅（新增或覆盖）
+func (s *Store) PutWebhook(w *model.Webhook) error {
+	if w == nil || w.ID == uuid.Nil || w.OrgID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhooks[w.ID] = cloneWebhook(w)
+	s.dirty = true
+	return nil
+}
+
+// GetWebhook 获取通知订阅
+func (s *Store) GetWebhook(id uuid.UUID) (*model.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	w, ok := s.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneWebhook(w), nil
+}
+
+// ListWebhooks 列出组织的通知订阅（按创建时间升序）
+func (s *Store) ListWebhooks(orgID uuid.UUID) []*model.Webhook {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*model.Webhook, 0)
+	for _, w := range s.webhooks {
+		if w.OrgID == orgID {
+			result = append(result, cloneWebhook(w))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+// DeleteWebhook 删除通知订阅及其投递记录
+func (s *Store) DeleteWebhook(id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.webhooks, id)
+	delete(s.webhookDeliveries, id)
+	s.dirty = true
+	return nil
+}
+
+// RecordWebhookDelivery 记录通知订阅的投递结果，每个订阅只保留最近的记录
+func (s *Store) RecordWebhookDelivery(d *model.WebhookDelivery) error {
+	if d == nil || d.WebhookID == uuid.Nil {
+		return ErrInvalid
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[d.WebhookID]; !ok {
+		return ErrNotFound
+	}
+	c := *d
+	log := append(s.webhookDeliveries[d.WebhookID], &c)
+	if len(log) > maxWebhookDeliveries {
+		log = log[len(log)-maxWebhookDeliveries:]
+	}
+	s.webhookDeliveries[d.WebhookID] = log
+	s.dirty = true
+	return nil
+}
+
+// ListWebhookDeliveries 列出通知订阅的投递记录（按时间倒序）
+func (s *Store) ListWebhookDeliveries(webhookID uuid.UUID) []*model.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	log := s.webhookDeliveries[webhookID]
+	result := make([]*model.WebhookDelivery, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		c := *log[i]
+		result = append(result, &c)
+	}
+	return result
+}
+
+// cloneWebhook 复制通知订阅（包括事件和收件人列表）
+func cloneWebhook(w *model.Webhook) *model.Webhook {
+	c := *w
+	c.Events = append([]string(nil), w.Events...)
+	c.Recipients = append([]string(nil), w.Recipients...)
+	return &c
+}
@@ -101,6 +101,15 @@ func initDefaultMetrics() {
 
 	// 覆盖率
 	registry.NewGauge("paiban_coverage_rate", "班次覆盖率", []string{"org_id"})
+
+	// 排班异常
+	registry.NewCounter("paiban_schedule_anomalies_total", "排班异常检测次数", []string{"org_id", "type", "severity"})
+
+	// 请求处理 panic
+	registry.NewCounter("paiban_http_panics_total", "请求处理panic次数", []string{"method", "path"})
+
+	// 限流拒绝
+	registry.NewCounter("paiban_rate_limit_rejected_total", "限流拒绝的请求数", []string{"rule", "client_type"})
 }
 
 // NewCounter 创建计数器
@@ -399,6 +408,20 @@ func RecordScheduleGeneration(scenario string, success bool, duration time.Durat
 	}
 }
 
+// RecordPanic 记录请求处理 panic
+func RecordPanic(method, path string) {
+	if counter := GetRegistry().GetCounter("paiban_http_panics_total"); counter != nil {
+		counter.Inc(method, path)
+	}
+}
+
+// RecordRateLimitRejected 记录限流拒绝的请求（rule 为接口规则的路径前缀或 default，client_type 为 api_key/jwt/ip）
+func RecordRateLimitRejected(rule, clientType string) {
+	if counter := GetRegistry().GetCounter("paiban_rate_limit_rejected_total"); counter != nil {
+		counter.Inc(rule, clientType)
+	}
+}
+
 // RecordConstraintEvaluation 记录约束评估指标
 func RecordConstraintEvaluation(constraintType string, satisfied bool) {
 	registry := GetRegistry()
@@ -432,6 +455,17 @@ func SetFairnessGini(orgID, metricType string, gini float64) {
 	}
 }
 
+// SetDBConnections 设置数据库连接池的连接数
+func SetDBConnections(open, inUse, idle int) {
+	registry := GetRegistry()
+	gauge := registry.GetGauge("paiban_db_connections")
+	if gauge != nil {
+		gauge.Set(float64(open), "open")
+		gauge.Set(float64(inUse), "in_use")
+		gauge.Set(float64(idle), "idle")
+	}
+}
+
 // SetCoverageRate 设置覆盖率
 func SetCoverageRate(orgID string, rate float64) {
 	registry := GetRegistry()
@@ -440,3 +474,12 @@ func SetCoverageRate(orgID string, rate float64) {
 		gauge.Set(rate, orgID)
 	}
 }
+
+// RecordScheduleAnomaly 记录排班异常
+func RecordScheduleAnomaly(orgID, anomalyType, severity string) {
+	registry := GetRegistry()
+	counter := registry.GetCounter("paiban_schedule_anomalies_total")
+	if counter != nil {
+		counter.Inc(orgID, anomalyType, severity)
+	}
+}
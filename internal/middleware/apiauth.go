@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/config"
+	"github.com/paiban/paiban/internal/security"
+)
+
+// AllOrgs 可访问全部组织的凭证的 org_id
+const AllOrgs = "*"
+
+// RoleAdmin 管理员角色
+const RoleAdmin = "admin"
+
+// adminPathPrefix 管理接口路径前缀，仅管理员可访问
+const adminPathPrefix = "/api/v1/admin/"
+
+// errOrgIDKey 请求体含有与 org_id 仅大小写不同的键
+var errOrgIDKey = errors.New("请求体中的组织ID字段名须为 org_id")
+
+// maxOrgScanBody 组织受限的凭证检查请求体 org_id 时允许的最大请求体
+const maxOrgScanBody = 32 << 20
+
+// Principal 通过认证的调用方
+type Principal struct {
+	ID        string `json:"id"`     // 密钥名称或令牌 sub
+	Method    string `json:"method"` // api_key/jwt
+	OrgID     string `json:"org_id"`
+	Role      string `json:"role,omitempty"`
+	RateLimit int    `json:"rate_limit,omitempty"` // 每秒请求数，覆盖限流默认规则的速率，0 表示沿用默认规则
+}
+
+// CanAccess 检查调用方是否可访问指定组织
+func (p *Principal) CanAccess(orgID string) bool {
+	return p.OrgID == AllOrgs || sameOrg(p.OrgID, orgID)
+}
+
+// IsAdmin 调用方是否为管理员：角色为 admin 或可访问全部组织
+func (p *Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin || p.OrgID == AllOrgs
+}
+
+type principalKey struct{}
+
+// WithPrincipal 返回携带调用方的上下文
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext 从上下文获取调用方
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// APIAuth API 认证与组织授权中间件：
+//   - 凭证为静态 API 密钥（X-API-Key 或 Authorization: Bearer）或 JWT（HS256/RS256，org_id 声明为所属组织）；
+//   - 请求路径 /orgs/{org_id}/、查询参数 org_id 或 JSON 请求体顶层的 org_id 须与凭证的组织一致，否则返回 403；
+//     按ID访问的资源由处理器读取资源所属组织后检查；
+//   - /api/v1/admin/ 下的管理接口只允许管理员（角色为 admin 或可访问全部组织的凭证）。
+//
+// 通过认证的调用方写入上下文，密钥配置的 rate_limit（未配置时取默认值）由其后的限流中间件使用。
+// 操作人（X-User-ID）和角色（X-User-Role）请求头只取自凭证，客户端自带的值被忽略。
+//
+// 只保护 /api/ 下的接口（API 索引页除外），未启用认证时直接放行
+func APIAuth(cfg *config.AuthConfig) (func(http.Handler) http.Handler, error) {
+	if cfg == nil || !cfg.Enabled {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+
+	keys := make(map[[sha256.Size]byte]*Principal, len(cfg.APIKeys))
+	for _, k := range cfg.APIKeys {
+		limit := k.RateLimit
+		if limit == 0 {
+			limit = cfg.RateLimit
+		}
+		keys[sha256.Sum256([]byte(k.Key))] = &Principal{ID: k.Name, Method: "api_key", OrgID: k.OrgID, Role: k.Role, RateLimit: limit}
+	}
+
+	var verifier *security.JWTVerifier
+	if cfg.JWT.HS256Secret != "" || cfg.JWT.PublicKeyFile != "" {
+		var publicKey string
+		if cfg.JWT.PublicKeyFile != "" {
+			data, err := os.ReadFile(cfg.JWT.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("读取 RS256 公钥失败: %w", err)
+			}
+			publicKey = string(data)
+		}
+		v, err := security.NewJWTVerifier(cfg.JWT.HS256Secret, publicKey, cfg.JWT.Issuer, cfg.JWT.Audience)
+		if err != nil {
+			return nil, err
+		}
+		verifier = v
+	}
+	if len(keys) == 0 && verifier == nil {
+		return nil, fmt.Errorf("已启用认证但未配置 API 密钥或 JWT")
+	}
+
+	authenticate := func(credential string) (*Principal, error) {
+		if verifier != nil && security.LooksLikeJWT(credential) {
+			claims, err := verifier.Verify(credential)
+			if err != nil {
+				return nil, err
+			}
+			if claims.OrgID == "" {
+				return nil, security.ErrInvalidToken
+			}
+			return &Principal{ID: claims.Subject, Method: "jwt", OrgID: claims.OrgID, Role: claims.Role, RateLimit: cfg.RateLimit}, nil
+		}
+		if p, ok := keys[sha256.Sum256([]byte(credential))]; ok {
+			return p, nil
+		}
+		return nil, security.ErrInvalidAPIKey
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, "/api/") || isAPIIndex(r.URL.Path) || r.Method == http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			credential := security.ExtractAPIKey(r)
+			if credential == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeAuthError(w, http.StatusUnauthorized, "UNAUTHORIZED", "未提供API密钥或令牌")
+				return
+			}
+			principal, err := authenticate(credential)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeAuthError(w, http.StatusUnauthorized, "UNAUTHORIZED", err.Error())
+				return
+			}
+
+			if principal.OrgID != AllOrgs {
+				orgID, err := requestOrgID(r)
+				if errors.Is(err, errOrgIDKey) {
+					writeAuthError(w, http.StatusForbidden, "FORBIDDEN", err.Error())
+					return
+				}
+				if err != nil {
+					writeAuthError(w, http.StatusBadRequest, "INVALID_INPUT", err.Error())
+					return
+				}
+				if orgID != "" && !principal.CanAccess(orgID) {
+					writeAuthError(w, http.StatusForbidden, "FORBIDDEN", "无权访问该组织的数据")
+					return
+				}
+			}
+
+			if strings.HasPrefix(r.URL.Path, adminPathPrefix) && !principal.IsAdmin() {
+				writeAuthError(w, http.StatusForbidden, "FORBIDDEN", "仅管理员可访问")
+				return
+			}
+
+			// 操作人和角色取自凭证（密钥的名称和角色、令牌的 sub 和 role 声明），
+			// 供审批、证书审核、审计等按 X-User-ID/X-User-Role 识别操作人的接口使用，不信任客户端自带的请求头
+			r.Header.Del("X-User-ID")
+			r.Header.Del("X-User-Role")
+			if principal.ID != "" {
+				r.Header.Set("X-User-ID", principal.ID)
+			}
+			if principal.Role != "" {
+				r.Header.Set("X-User-Role", principal.Role)
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}, nil
+}
+
+// isAPIIndex 是否为 API 索引页（/api/v1/、/api/v2/）
+func isAPIIndex(path string) bool {
+	return path == "/api/v1/" || path == "/api/v2/"
+}
+
+// requestOrgID 提取请求指定的组织：路径 /orgs/{org_id}/、查询参数 org_id、JSON 请求体顶层的 org_id，
+// 多处同时指定且不一致时返回错误。读取请求体后会还原，处理器可照常读取
+func requestOrgID(r *http.Request) (string, error) {
+	var found []string
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] == "orgs" {
+			found = append(found, segments[i+1])
+			break
+		}
+	}
+	if orgID := r.URL.Query().Get("org_id"); orgID != "" {
+		found = append(found, orgID)
+	}
+	if orgID, err := bodyOrgID(r); err != nil {
+		return "", err
+	} else if orgID != "" {
+		found = append(found, orgID)
+	}
+
+	if len(found) == 0 {
+		return "", nil
+	}
+	for _, orgID := range found[1:] {
+		if !sameOrg(found[0], orgID) {
+			return "", fmt.Errorf("请求中的 org_id 不一致")
+		}
+	}
+	return found[0], nil
+}
+
+// bodyOrgID 读取 JSON 请求体顶层的 org_id
+func bodyOrgID(r *http.Request) (string, error) {
+	// 不依赖 Content-Type（curl -d 默认为表单类型），文件上传除外
+	if r.Body == nil || r.Body == http.NoBody || strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		return "", nil
+	}
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return "", nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxOrgScanBody+1))
+	if err != nil {
+		return "", fmt.Errorf("读取请求体失败: %w", err)
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), r.Body), r.Body}
+	if len(data) > maxOrgScanBody {
+		return "", fmt.Errorf("请求体超过 %dMB", maxOrgScanBody>>20)
+	}
+
+	// encoding/json 匹配字段名不区分大小写，ORG_ID、Org_Id 等键同样会被处理器解码为 org_id，
+	// 因此始终解析请求体，并拒绝与 org_id 仅大小写不同的键
+	var body map[string]json.RawMessage
+	if json.Unmarshal(data, &body) != nil {
+		return "", nil // 非对象或格式错误的请求体由处理器报错
+	}
+	var orgID string
+	for key, value := range body {
+		if !strings.EqualFold(key, "org_id") {
+			continue
+		}
+		if key != "org_id" {
+			return "", errOrgIDKey
+		}
+		if json.Unmarshal(value, &orgID) != nil {
+			return "", nil // 类型错误由处理器报错
+		}
+	}
+	return orgID, nil
+}
+
+// sameOrg 比较组织ID，UUID 不区分大小写
+func sameOrg(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ua, errA := uuid.Parse(a)
+	ub, errB := uuid.Parse(b)
+	return errA == nil && errB == nil && ua == ub
+}
+
+// writeAuthError 写入认证错误响应
+func writeAuthError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   true,
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/paiban/paiban/internal/config"
+	"github.com/paiban/paiban/internal/security"
+)
+
+func TestAPIAuth(t *testing.T) {
+	const orgA, orgB = "6f1c0a52-4a5e-4d8b-9d0e-1f7f3c2b9a01", "0b7e3c1d-2f4a-4b6c-8d9e-a1b2c3d4e5f6"
+	auth, err := APIAuth(&config.AuthConfig{
+		Enabled: true,
+		APIKeys: []config.APIKeyConfig{
+			{Name: "org-a", Key: "key-a", OrgID: orgA},
+			{Name: "admin", Key: "key-admin", OrgID: AllOrgs},
+			{Name: "org-a-admin", Key: "key-a-admin", OrgID: orgA, Role: RoleAdmin},
+		},
+		JWT: config.JWTConfig{HS256Secret: "secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotBody string
+	var got *Principal
+	h := auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		got, _ = PrincipalFromContext(r.Context())
+		w.Header().Set("X-User", r.Header.Get("X-User-ID"))
+		w.Header().Set("X-Role", r.Header.Get("X-User-Role"))
+	}))
+	do := func(method, path, credential, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-User-ID", "boss")
+		req.Header.Set("X-User-Role", "admin")
+		if credential != "" {
+			req.Header.Set("Authorization", "Bearer "+credential)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	token, _ := security.SignHS256(&security.JWTClaims{Subject: "u1", OrgID: orgB}, "secret")
+	body := `{"org_id":"` + orgA + `","employees":[]}`
+	tests := []struct {
+		name, method, path, credential, body string
+		want                                 int
+	}{
+		{"未携带凭证", http.MethodPost, "/api/v1/schedule/generate", "", body, http.StatusUnauthorized},
+		{"无效密钥", http.MethodPost, "/api/v1/schedule/generate", "key-x", body, http.StatusUnauthorized},
+		{"请求体组织一致", http.MethodPost, "/api/v1/schedule/generate", "key-a", body, http.StatusOK},
+		{"请求体组织不一致", http.MethodPost, "/api/v1/schedule/generate", token, body, http.StatusForbidden},
+		{"请求体组织键大小写不同", http.MethodPost, "/api/v1/schedule/generate", token, `{"ORG_ID":"` + orgA + `"}`, http.StatusForbidden},
+		{"请求体组织键大小写不同（本组织）", http.MethodPost, "/api/v1/schedule/generate", token, `{"Org_Id":"` + orgB + `"}`, http.StatusForbidden},
+		{"请求体组织键转义", http.MethodPost, "/api/v1/schedule/generate", token, `{"org\u005fid":"` + orgA + `"}`, http.StatusForbidden},
+		{"路径组织不一致", http.MethodGet, "/api/v1/orgs/" + orgB + "/aliases", "key-a", "", http.StatusForbidden},
+		{"查询参数组织", http.MethodGet, "/api/v1/analytics/fairness?org_id=" + orgB, token, "", http.StatusOK},
+		{"组织大小写不同", http.MethodGet, "/api/v1/orgs/" + strings.ToUpper(orgB) + "/aliases", token, "", http.StatusOK},
+		{"全部组织（请求体）", http.MethodPost, "/api/v1/orgs/" + orgB + "/blackout-periods", "key-admin", body, http.StatusOK},
+		{"令牌多处组织不一致", http.MethodPost, "/api/v1/orgs/" + orgB + "/x?org_id=" + orgA, token, "", http.StatusBadRequest},
+		{"全部组织（路径）", http.MethodGet, "/api/v1/orgs/" + orgB + "/aliases", "key-admin", "", http.StatusOK},
+		{"未指定组织", http.MethodPost, "/api/v1/dispatch/single", token, `{"order":{}}`, http.StatusOK},
+		{"管理接口（组织密钥）", http.MethodGet, "/api/v1/admin/config", "key-a", "", http.StatusForbidden},
+		{"管理接口（组织令牌）", http.MethodPost, "/api/v1/admin/constraints/reload", token, "", http.StatusForbidden},
+		{"管理接口（全部组织）", http.MethodGet, "/api/v1/admin/config", "key-admin", "", http.StatusOK},
+		{"管理接口（管理员角色）", http.MethodDelete, "/api/v1/admin/faults", "key-a-admin", "", http.StatusOK},
+		{"非 API 路径", http.MethodGet, "/health", "", "", http.StatusOK},
+		{"API 索引", http.MethodGet, "/api/v1/", "", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := do(tt.method, tt.path, tt.credential, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d, body = %s", tt.name, w.Code, tt.want, w.Body.String())
+		}
+	}
+
+	// 处理器可照常读取请求体，并能取得调用方；令牌的 sub 覆盖 X-User-ID，客户端自带的角色被忽略
+	if w := do(http.MethodPost, "/api/v1/schedule/generate", token, `{"org_id":"`+orgB+`"}`); w.Code != http.StatusOK ||
+		gotBody != `{"org_id":"`+orgB+`"}` || got == nil || got.Method != "jwt" || w.Header().Get("X-User") != "u1" || w.Header().Get("X-Role") != "" {
+		t.Errorf("status = %d, body = %q, principal = %+v, role = %q", w.Code, gotBody, got, w.Header().Get("X-Role"))
+	}
+	// API 密钥的操作人和角色取自密钥配置
+	if w := do(http.MethodGet, "/api/v1/schedules", "key-a", ""); w.Header().Get("X-User") != "org-a" || w.Header().Get("X-Role") != "" {
+		t.Errorf("密钥调用方 X-User-ID = %q, X-User-Role = %q", w.Header().Get("X-User"), w.Header().Get("X-Role"))
+	}
+	if w := do(http.MethodGet, "/api/v1/schedules", "key-a-admin", ""); w.Header().Get("X-Role") != RoleAdmin {
+		t.Errorf("密钥配置的角色 X-User-Role = %q", w.Header().Get("X-Role"))
+	}
+}
+
+func TestAPIAuthConfig(t *testing.T) {
+	// 未启用时直接放行
+	auth, err := APIAuth(&config.AuthConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/employees", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("未启用认证 status = %d", w.Code)
+	}
+
+	if _, err := APIAuth(&config.AuthConfig{Enabled: true}); err == nil {
+		t.Error("启用认证但未配置凭证应返回错误")
+	}
+}
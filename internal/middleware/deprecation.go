@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DeprecationConfig API 版本弃用配置
+type DeprecationConfig struct {
+	PathPrefix string            // 已弃用的路径前缀，如 /api/v1/
+	Sunset     time.Time         // 计划下线时间（零值表示未定）
+	Successors map[string]string // 旧路径 -> 新版本路径
+	DocURL     string            // 迁移说明文档（可选）
+}
+
+// DeprecationMiddleware 为已弃用版本的 API 响应添加弃用相关响应头
+//
+//	Deprecation: true
+//	Sunset: <HTTP-date>
+//	Link: </api/v2/...>; rel="successor-version"
+func DeprecationMiddleware(config *DeprecationConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, config.PathPrefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Deprecation", "true")
+			if !config.Sunset.IsZero() {
+				w.Header().Set("Sunset", config.Sunset.UTC().Format(http.TimeFormat))
+			}
+
+			var links []string
+			if successor, ok := config.Successors[r.URL.Path]; ok {
+				links = append(links, fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+			}
+			if config.DocURL != "" {
+				links = append(links, fmt.Sprintf("<%s>; rel=\"deprecation\"", config.DocURL))
+			}
+			if len(links) > 0 {
+				w.Header().Set("Link", strings.Join(links, ", "))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
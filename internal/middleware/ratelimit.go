@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paiban/paiban/internal/config"
+	"github.com/paiban/paiban/internal/metrics"
+)
+
+// defaultRule 未匹配接口规则时的规则名
+const defaultRule = "default"
+
+// bucketIdleTTL 令牌桶闲置超过该时间（且已补满）后回收
+const bucketIdleTTL = 10 * time.Minute
+
+// tokenBucket 令牌桶
+type tokenBucket struct {
+	tokens   float64
+	rate     float64
+	capacity float64
+	last     time.Time
+
+	allowed  int64
+	rejected int64
+}
+
+// take 补充令牌后尝试取出一个，失败时返回需要等待的时间
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.tokens = math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		b.allowed++
+		return true, 0
+	}
+	b.rejected++
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+type bucketKey struct {
+	client string
+	rule   string
+}
+
+// RateLimiter 按客户端和接口分别计数的限流器：
+// 每个客户端（API 密钥、令牌或 IP）在每条规则下有独立的令牌桶，一个客户端超限不影响其他客户端。
+// 请求匹配路径前缀最长的接口规则，未匹配时使用默认规则；凭证配置了 rate_limit 时覆盖默认规则的速率
+type RateLimiter struct {
+	config *config.RateLimitConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[bucketKey]*tokenBucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter 创建限流器
+func NewRateLimiter(cfg *config.RateLimitConfig) *RateLimiter {
+	if cfg == nil {
+		cfg = &config.RateLimitConfig{}
+	}
+	endpoints := append([]config.EndpointRateRule(nil), cfg.Endpoints...)
+	sort.SliceStable(endpoints, func(i, j int) bool { return len(endpoints[i].Path) > len(endpoints[j].Path) })
+	return &RateLimiter{
+		config:  &config.RateLimitConfig{Default: cfg.Default, Endpoints: endpoints, TrustProxy: cfg.TrustProxy},
+		now:     time.Now,
+		buckets: make(map[bucketKey]*tokenBucket),
+	}
+}
+
+// rule 返回请求路径适用的规则名和规则
+func (l *RateLimiter) rule(path string, p *Principal) (string, config.RateRule) {
+	for _, e := range l.config.Endpoints {
+		if strings.HasPrefix(path, e.Path) {
+			return e.Path, e.RateRule
+		}
+	}
+	rule := l.config.Default
+	if p != nil && p.RateLimit > 0 {
+		rule = config.RateRule{QPS: float64(p.RateLimit)}
+	}
+	return defaultRule, rule
+}
+
+// Allow 检查客户端对该路径的请求是否允许，拒绝时返回建议的重试等待时间
+func (l *RateLimiter) Allow(client, path string, p *Principal) (bool, string, time.Duration) {
+	name, rule := l.rule(path, p)
+	if rule.QPS <= 0 {
+		return true, name, 0
+	}
+	capacity := float64(rule.Burst)
+	if capacity <= 0 {
+		capacity = math.Max(rule.QPS*2, 1)
+	}
+
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	key := bucketKey{client: client, rule: name}
+	b := l.buckets[key]
+	if b == nil {
+		b = &tokenBucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+	b.rate, b.capacity = rule.QPS, capacity
+	ok, wait := b.take(now)
+	return ok, name, wait
+}
+
+// sweep 定期回收闲置且已补满的令牌桶，调用方须持有锁
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Middleware 限流中间件，超限返回 429 并在 Retry-After 中给出等待秒数。
+// 须放在认证中间件之后，才能按 API 密钥或令牌识别客户端
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := PrincipalFromContext(r.Context())
+		client, clientType := l.clientKey(r, p)
+		ok, rule, wait := l.Allow(client, r.URL.Path, p)
+		if !ok {
+			metrics.RecordRateLimitRejected(rule, clientType)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":   true,
+				"code":    "RATE_LIMITED",
+				"message": "请求过于频繁，请稍后重试",
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientKey 识别客户端：已认证时按凭证（密钥名称或令牌的组织和用户），否则按 IP
+func (l *RateLimiter) clientKey(r *http.Request, p *Principal) (string, string) {
+	if p != nil {
+		if p.Method == "jwt" {
+			return "jwt:" + p.OrgID + "/" + p.ID, p.Method
+		}
+		return p.Method + ":" + p.ID, p.Method
+	}
+	return "ip:" + l.clientIP(r), "ip"
+}
+
+// clientIP 客户端 IP，信任代理时取 X-Forwarded-For 的首个地址
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	if l.config.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimitBucket 客户端在某条规则下的令牌桶状态
+type RateLimitBucket struct {
+	Client   string    `json:"client"`
+	Rule     string    `json:"rule"`
+	Tokens   float64   `json:"tokens"` // 当前可用令牌数
+	QPS      float64   `json:"qps"`
+	Burst    int       `json:"burst"`
+	Allowed  int64     `json:"allowed"`
+	Rejected int64     `json:"rejected"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// RateLimitStatus 限流器状态
+type RateLimitStatus struct {
+	Default   config.RateRule           `json:"default"`
+	Endpoints []config.EndpointRateRule `json:"endpoints"`
+	Buckets   []RateLimitBucket         `json:"buckets"` // 按拒绝次数降序
+}
+
+// Status 返回限流规则和各客户端的令牌桶状态，client 非空时只返回包含该字符串的客户端
+func (l *RateLimiter) Status(client string) RateLimitStatus {
+	now := l.now()
+	l.mu.Lock()
+	buckets := make([]RateLimitBucket, 0, len(l.buckets))
+	for key, b := range l.buckets {
+		if client != "" && !strings.Contains(key.client, client) {
+			continue
+		}
+		buckets = append(buckets, RateLimitBucket{
+			Client:   key.client,
+			Rule:     key.rule,
+			Tokens:   math.Min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate),
+			QPS:      b.rate,
+			Burst:    int(b.capacity),
+			Allowed:  b.allowed,
+			Rejected: b.rejected,
+			LastSeen: b.last,
+		})
+	}
+	l.mu.Unlock()
+
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Rejected != buckets[j].Rejected {
+			return buckets[i].Rejected > buckets[j].Rejected
+		}
+		if buckets[i].Client != buckets[j].Client {
+			return buckets[i].Client < buckets[j].Client
+		}
+		return buckets[i].Rule < buckets[j].Rule
+	})
+	endpoints := l.config.Endpoints
+	if endpoints == nil {
+		endpoints = []config.EndpointRateRule{}
+	}
+	return RateLimitStatus{Default: l.config.Default, Endpoints: endpoints, Buckets: buckets}
+}
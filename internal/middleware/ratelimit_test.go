@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/paiban/paiban/internal/config"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(&config.RateLimitConfig{
+		Default: config.RateRule{QPS: 1, Burst: 2},
+		Endpoints: []config.EndpointRateRule{
+			{Path: "/api/v1/schedule/", RateRule: config.RateRule{QPS: 10, Burst: 10}},
+			{Path: "/api/v1/schedule/generate", RateRule: config.RateRule{QPS: 1, Burst: 1}},
+			{Path: "/api/v1/dispatch/", RateRule: config.RateRule{}}, // 不限制
+		},
+	})
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	h := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	do := func(path, ip string, p *Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = ip + ":12345"
+		if p != nil {
+			req = req.WithContext(WithPrincipal(req.Context(), p))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// 默认规则：突发 2 次后超限，其他客户端不受影响
+	for i := 0; i < 2; i++ {
+		if w := do("/api/v1/employees", "10.0.0.1", nil); w.Code != http.StatusOK {
+			t.Fatalf("第 %d 次请求 status = %d", i+1, w.Code)
+		}
+	}
+	w := do("/api/v1/employees", "10.0.0.1", nil)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("超限 status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := do("/api/v1/employees", "10.0.0.2", nil); w.Code != http.StatusOK {
+		t.Errorf("其他客户端不应受影响: %d", w.Code)
+	}
+
+	// 接口规则独立计数，取最长匹配的路径前缀
+	if w := do("/api/v1/schedule/validate", "10.0.0.1", nil); w.Code != http.StatusOK {
+		t.Errorf("接口规则应独立计数: %d", w.Code)
+	}
+	do("/api/v1/schedule/generate", "10.0.0.1", nil)
+	if w := do("/api/v1/schedule/generate", "10.0.0.1", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("generate 规则应为每秒 1 次: %d", w.Code)
+	}
+	for i := 0; i < 5; i++ {
+		if w := do("/api/v1/dispatch/single", "10.0.0.1", nil); w.Code != http.StatusOK {
+			t.Fatalf("qps 为 0 的规则不应限制: %d", w.Code)
+		}
+	}
+
+	// 令牌按速率补充
+	now = now.Add(time.Second)
+	if w := do("/api/v1/employees", "10.0.0.1", nil); w.Code != http.StatusOK {
+		t.Errorf("1 秒后应补充 1 个令牌: %d", w.Code)
+	}
+
+	// 认证后按凭证计数（同一 IP 的不同密钥互不影响），凭证的 rate_limit 覆盖默认规则
+	key := &Principal{ID: "java", Method: "api_key", OrgID: "org-1", RateLimit: 5}
+	for i := 0; i < 10; i++ {
+		if w := do("/api/v1/employees", "10.0.0.1", key); w.Code != http.StatusOK {
+			t.Fatalf("密钥第 %d 次请求 status = %d", i+1, w.Code)
+		}
+	}
+	if w := do("/api/v1/employees", "10.0.0.1", key); w.Code != http.StatusTooManyRequests {
+		t.Errorf("密钥超过突发数应限流: %d", w.Code)
+	}
+
+	status := limiter.Status("")
+	if len(status.Endpoints) != 3 || status.Endpoints[0].Path != "/api/v1/schedule/generate" {
+		t.Errorf("接口规则应按路径长度降序: %+v", status.Endpoints)
+	}
+	if n := len(status.Buckets); n != 5 {
+		t.Errorf("令牌桶数 = %d, want 5", n)
+	}
+	if last := status.Buckets[len(status.Buckets)-1]; last.Rejected != 0 {
+		t.Errorf("应按拒绝次数降序: %+v", status.Buckets)
+	}
+	ip := limiter.Status("ip:10.0.0.1")
+	if b := ip.Buckets[0]; len(ip.Buckets) != 3 || b.Rule != "/api/v1/schedule/generate" || b.Rejected != 1 || b.Allowed != 1 {
+		t.Errorf("按客户端过滤 = %+v", ip.Buckets)
+	}
+	filtered := limiter.Status("api_key:")
+	if len(filtered.Buckets) != 1 || filtered.Buckets[0].QPS != 5 || filtered.Buckets[0].Burst != 10 || filtered.Buckets[0].Rejected != 1 {
+		t.Errorf("密钥令牌桶 = %+v", filtered.Buckets)
+	}
+
+	// 闲置的令牌桶被回收
+	now = now.Add(bucketIdleTTL + time.Minute)
+	do("/api/v1/employees", "10.0.0.3", nil)
+	if n := len(limiter.Status("").Buckets); n != 1 {
+		t.Errorf("闲置令牌桶应回收，剩余 %d", n)
+	}
+}
+
+func TestRateLimiterClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/employees", nil)
+	req.RemoteAddr = "10.0.0.1:5000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+
+	if client, _ := NewRateLimiter(nil).clientKey(req, nil); client != "ip:10.0.0.1" {
+		t.Errorf("不信任代理时应取连接地址: %s", client)
+	}
+	trusted := NewRateLimiter(&config.RateLimitConfig{TrustProxy: true})
+	if client, _ := trusted.clientKey(req, nil); client != "ip:203.0.113.7" {
+		t.Errorf("信任代理时应取 X-Forwarded-For 首个地址: %s", client)
+	}
+	if client, kind := trusted.clientKey(req, &Principal{ID: "u1", Method: "jwt", OrgID: "org-1"}); client != "jwt:org-1/u1" || kind != "jwt" {
+		t.Errorf("令牌客户端 = %s %s", client, kind)
+	}
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/metrics"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/logger"
+)
+
+// incidentTimeout 投递事故报告的超时时间
+const incidentTimeout = 10 * time.Second
+
+// RecoveryConfig 恢复中间件配置
+type RecoveryConfig struct {
+	Notifier notify.Notifier // 事故报告投递渠道（可选，如 Webhook），为空时只记录日志
+}
+
+// Incident panic 事故报告
+type Incident struct {
+	ID         uuid.UUID `json:"id"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Pattern    string    `json:"pattern,omitempty"` // 匹配的路由
+	UserID     string    `json:"user_id,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	Panic      string    `json:"panic"`
+	Stack      string    `json:"stack"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// RecoveryMiddleware 恢复中间件（捕获panic），只记录日志和指标
+func RecoveryMiddleware(next http.Handler) http.Handler {
+	return Recovery(nil)(next)
+}
+
+// Recovery 恢复中间件：请求处理 panic 时返回带 request_id 的 500 响应，
+// 记录包含调用栈和请求上下文的事故日志、累加 panic 指标，并按配置投递事故报告。
+// request_id 取外层中间件设置的 X-Request-ID 响应头，其次为请求头；已开始写响应时只记录不再改写
+func Recovery(config *RecoveryConfig) func(http.Handler) http.Handler {
+	if config == nil {
+		config = &RecoveryConfig{}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &recoveryWriter{ResponseWriter: w}
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+
+				incident := newIncident(w, r, p)
+				path := r.Pattern
+				if path == "" {
+					path = r.URL.Path
+				}
+				metrics.RecordPanic(r.Method, path)
+				logger.Error().
+					Str("incident_id", incident.ID.String()).
+					Str("request_id", incident.RequestID).
+					Str("method", incident.Method).
+					Str("path", incident.Path).
+					Str("pattern", incident.Pattern).
+					Str("user_id", incident.UserID).
+					Str("panic", incident.Panic).
+					Str("stack", incident.Stack).
+					Msg("请求处理panic")
+				if config.Notifier != nil {
+					go report(config.Notifier, incident)
+				}
+
+				if rw.wroteHeader {
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"error":       true,
+					"code":        "INTERNAL_ERROR",
+					"message":     "服务器内部错误",
+					"request_id":  incident.RequestID,
+					"incident_id": incident.ID,
+				})
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// newIncident 根据请求和 panic 值生成事故报告
+func newIncident(w http.ResponseWriter, r *http.Request, p interface{}) *Incident {
+	requestID := w.Header().Get("X-Request-ID")
+	if requestID == "" {
+		requestID = r.Header.Get("X-Request-ID")
+	}
+	return &Incident{
+		ID:         uuid.New(),
+		RequestID:  requestID,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Pattern:    r.Pattern,
+		UserID:     r.Header.Get("X-User-ID"),
+		Role:       r.Header.Get("X-User-Role"),
+		Panic:      fmt.Sprint(p),
+		Stack:      string(debug.Stack()),
+		OccurredAt: time.Now(),
+	}
+}
+
+// report 投递事故报告，失败时只记录日志
+func report(notifier notify.Notifier, incident *Incident) {
+	ctx, cancel := context.WithTimeout(context.Background(), incidentTimeout)
+	defer cancel()
+	n := notify.New(notify.TypeIncident, uuid.Nil,
+		fmt.Sprintf("请求处理panic: %s %s", incident.Method, incident.Path), incident.Panic, incident)
+	if err := notifier.Notify(ctx, n); err != nil {
+		logger.Error().Err(err).Str("incident_id", incident.ID.String()).Msg("投递事故报告失败")
+	}
+}
+
+// recoveryWriter 记录是否已开始写响应
+type recoveryWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (rw *recoveryWriter) WriteHeader(code int) {
+	rw.wroteHeader = true
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recoveryWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush 支持流式响应
+func (rw *recoveryWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		rw.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Unwrap 供 http.ResponseController 获取底层 ResponseWriter
+func (rw *recoveryWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
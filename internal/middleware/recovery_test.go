@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/paiban/paiban/internal/notify"
+)
+
+// captureNotifier 记录投递的通知
+type captureNotifier chan *notify.Notification
+
+func (c captureNotifier) Notify(ctx context.Context, n *notify.Notification) error {
+	c <- n
+	return nil
+}
+
+func TestRecovery(t *testing.T) {
+	notifier := make(captureNotifier, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/orgs/{org_id}/boom", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	mux.HandleFunc("/api/v1/partial", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("partial")
+	})
+	h := RequestIDMiddleware(Recovery(&RecoveryConfig{Notifier: notifier})(mux))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/orgs/42/boom", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-User-ID", "u1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("响应应为 JSON: %v", err)
+	}
+	if w.Code != http.StatusInternalServerError || body["request_id"] != "req-1" || body["incident_id"] == "" {
+		t.Errorf("panic 应返回带 request_id 的 500, got %d %v", w.Code, body)
+	}
+
+	select {
+	case n := <-notifier:
+		incident, ok := n.Data.(*Incident)
+		if n.Type != notify.TypeIncident || !ok || incident.RequestID != "req-1" || incident.UserID != "u1" ||
+			incident.Pattern != "/api/v1/orgs/{org_id}/boom" || incident.Panic != "boom" || incident.Stack == "" {
+			t.Errorf("事故报告内容不完整: %+v", n.Data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("应投递事故报告")
+	}
+
+	// 已开始写响应时不改写状态码
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/partial", nil))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("已写出的状态码不应被改写, got %d", w.Code)
+	}
+	<-notifier
+}
@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// Webhook 投递请求头
+const (
+	HeaderEvent     = "X-Paiban-Event"     // 通知类型
+	HeaderDelivery  = "X-Paiban-Delivery"  // 投递ID（重试时不变，接收方可据此去重）
+	HeaderTimestamp = "X-Paiban-Timestamp" // 签名时间（Unix 秒）
+	HeaderSignature = "X-Paiban-Signature" // sha256=HMAC-SHA256(secret, timestamp + "." + body) 的十六进制
+)
+
+// SubscriptionStore 通知订阅存储
+type SubscriptionStore interface {
+	ListWebhooks(orgID uuid.UUID) []*model.Webhook
+	RecordWebhookDelivery(d *model.WebhookDelivery) error
+}
+
+// Mailer 邮件发送器
+type Mailer interface {
+	Send(ctx context.Context, to []string, subject, body string) error
+}
+
+// Dispatcher 通知订阅分发器
+// 通知先交给基础发送器（日志或全局 Webhook），再异步投递给组织订阅了该类型的 Webhook 和邮件；
+// 投递失败（网络错误、408、429 和 5xx）按指数退避重试，每个订阅的投递结果记录在存储中
+type Dispatcher struct {
+	base   Notifier
+	store  SubscriptionStore
+	client *http.Client
+	mailer Mailer
+
+	MaxAttempts int           // 最多投递次数（含首次）
+	Backoff     time.Duration // 首次重试间隔，之后每次加倍
+	MaxBackoff  time.Duration // 重试间隔上限
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher 创建通知订阅分发器，base 为空时通知写入日志
+func NewDispatcher(base Notifier, store SubscriptionStore) *Dispatcher {
+	if base == nil {
+		base = LogNotifier{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		base:        base,
+		store:       store,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: 5,
+		Backoff:     2 * time.Second,
+		MaxBackoff:  time.Minute,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// SetMailer 设置邮件发送器，未设置时邮件订阅的投递记为失败
+func (d *Dispatcher) SetMailer(mailer Mailer) {
+	d.mailer = mailer
+}
+
+// Notify 发送通知：基础发送器同步发送，订阅投递异步进行，返回基础发送器的错误
+func (d *Dispatcher) Notify(ctx context.Context, n *Notification) error {
+	err := d.base.Notify(ctx, n)
+	for _, sub := range d.store.ListWebhooks(n.OrgID) {
+		if !sub.Active || !sub.Subscribes(n.Type) {
+			continue
+		}
+		d.wg.Add(1)
+		go func(sub *model.Webhook) {
+			defer d.wg.Done()
+			d.deliver(sub, n)
+		}(sub)
+	}
+	return err
+}
+
+// Wait 等待进行中的订阅投递完成
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Close 停止重试并等待进行中的投递结束
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// deliver 投递通知到订阅，失败时按指数退避重试，并记录投递结果
+func (d *Dispatcher) deliver(sub *model.Webhook, n *Notification) {
+	delivery := &model.WebhookDelivery{
+		ID:             uuid.New(),
+		WebhookID:      sub.ID,
+		NotificationID: n.ID,
+		Event:          n.Type,
+		CreatedAt:      time.Now(),
+	}
+	wait := d.Backoff
+	for {
+		delivery.Attempts++
+		retry, err := d.send(sub, n, delivery)
+		if err == nil {
+			delivery.Success, delivery.Error = true, ""
+			break
+		}
+		delivery.Error = err.Error()
+		if !retry || delivery.Attempts >= d.MaxAttempts {
+			break
+		}
+		select {
+		case <-d.ctx.Done():
+		case <-time.After(wait):
+		}
+		if d.ctx.Err() != nil {
+			break
+		}
+		if wait *= 2; wait > d.MaxBackoff {
+			wait = d.MaxBackoff
+		}
+	}
+	delivery.FinishedAt = time.Now()
+
+	if !delivery.Success {
+		logger.Warn().Str("webhook_id", sub.ID.String()).Str("type", n.Type).
+			Int("attempts", delivery.Attempts).Str("error", delivery.Error).Msg("通知订阅投递失败")
+	}
+	if err := d.store.RecordWebhookDelivery(delivery); err != nil {
+		logger.Debug().Err(err).Str("webhook_id", sub.ID.String()).Msg("记录通知订阅投递结果失败")
+	}
+}
+
+// send 按渠道投递一次，返回失败是否可重试
+func (d *Dispatcher) send(sub *model.Webhook, n *Notification, delivery *model.WebhookDelivery) (bool, error) {
+	if sub.Channel == model.ChannelEmail {
+		if d.mailer == nil {
+			return false, fmt.Errorf("未配置 SMTP 邮件服务")
+		}
+		text := n.Body
+		if sub.Template != "" {
+			body, err := Render(sub.Template, n)
+			if err != nil {
+				return false, err
+			}
+			text = string(body)
+		}
+		if err := d.mailer.Send(d.ctx, sub.Recipients, n.Title, text); err != nil {
+			return true, fmt.Errorf("发送邮件失败: %w", err)
+		}
+		return false, nil
+	}
+
+	body, err := Render(sub.Template, n)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("创建投递请求失败: %w", err)
+	}
+	contentType := "application/json"
+	if sub.Template != "" && !json.Valid(body) {
+		contentType = "text/plain; charset=utf-8"
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(HeaderEvent, n.Type)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if sub.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("投递通知失败: %w", err)
+	}
+	defer resp.Body.Close()
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("投递通知失败: HTTP %d", resp.StatusCode)
+}
+
+// Sign 计算 Webhook 签名：sha256=HMAC-SHA256(secret, timestamp + "." + body) 的十六进制
+// 接收方用相同密钥按 X-Paiban-Timestamp 和请求体重新计算并比较
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// templateFuncs 模板可用的函数
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// ParseTemplate 解析通知模板（Go text/template 语法）
+func ParseTemplate(tmpl string) (*template.Template, error) {
+	t, err := template.New("webhook").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("模板解析失败: %w", err)
+	}
+	return t, nil
+}
+
+// Render 按模板渲染通知，模板为空时返回通知的 JSON
+// 模板数据为通知本身（.Type、.OrgID、.Title、.Body、.Data、.CreatedAt），可用 {{json .Data}} 输出 JSON
+func Render(tmpl string, n *Notification) ([]byte, error) {
+	if tmpl == "" {
+		data, err := json.Marshal(n)
+		if err != nil {
+			return nil, fmt.Errorf("序列化通知失败: %w", err)
+		}
+		return data, nil
+	}
+	t, err := ParseTemplate(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, n); err != nil {
+		return nil, fmt.Errorf("模板渲染失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// testStore 测试用通知订阅存储
+type testStore struct {
+	mu         sync.Mutex
+	hooks      []*model.Webhook
+	deliveries []*model.WebhookDelivery
+}
+
+func (s *testStore) ListWebhooks(orgID uuid.UUID) []*model.Webhook {
+	return s.hooks
+}
+
+func (s *testStore) RecordWebhookDelivery(d *model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, d)
+	return nil
+}
+
+// testMailer 测试用邮件发送器
+type testMailer struct {
+	to            []string
+	subject, body string
+}
+
+func (m *testMailer) Send(ctx context.Context, to []string, subject, body string) error {
+	m.to, m.subject, m.body = to, subject, body
+	return nil
+}
+
+func TestDispatcher_Deliver(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	var signed *http.Request
+	var signedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[r.URL.Path]++
+		switch r.URL.Path {
+		case "/flaky": // 首次 503，重试成功
+			if calls[r.URL.Path] == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			signed = r
+			signedBody, _ = io.ReadAll(r.Body)
+		case "/gone": // 4xx 不重试
+			w.WriteHeader(http.StatusGone)
+		}
+	}))
+	defer server.Close()
+
+	orgID := uuid.New()
+	hook := func(path string, events ...string) *model.Webhook {
+		return &model.Webhook{BaseModel: model.NewBaseModel(), OrgID: orgID, Channel: model.ChannelWebhook,
+			URL: server.URL + path, Secret: "s3cret", Events: events, Active: true}
+	}
+	inactive := hook("/inactive", model.WebhookAllEvents)
+	inactive.Active = false
+	mailer := &testMailer{}
+	store := &testStore{hooks: []*model.Webhook{
+		hook("/flaky", TypeSwapApproved),
+		hook("/gone", model.WebhookAllEvents),
+		hook("/other", TypeUnfilledShift),
+		inactive,
+		{BaseModel: model.NewBaseModel(), OrgID: orgID, Channel: model.ChannelEmail, Recipients: []string{"ops@example.com"},
+			Events: []string{TypeSwapApproved}, Template: "{{.Body}}（{{index .Data \"date\"}}）", Active: true},
+	}}
+
+	d := NewDispatcher(nil, store)
+	d.SetMailer(mailer)
+	d.Backoff = time.Millisecond
+	n := New(TypeSwapApproved, orgID, "换班已通过", "张三与李四换班", map[string]interface{}{"date": "2026-01-15"})
+	if err := d.Notify(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	d.Wait()
+
+	if calls["/flaky"] != 2 || calls["/gone"] != 1 || calls["/other"] != 0 || calls["/inactive"] != 0 {
+		t.Errorf("calls = %v", calls)
+	}
+	timestamp := signed.Header.Get(HeaderTimestamp)
+	if got := signed.Header.Get(HeaderSignature); got != Sign("s3cret", timestamp, signedBody) {
+		t.Errorf("签名 = %s", got)
+	}
+	if signed.Header.Get(HeaderEvent) != TypeSwapApproved || signed.Header.Get(HeaderDelivery) == "" {
+		t.Errorf("headers = %v", signed.Header)
+	}
+	if mailer.subject != "换班已通过" || mailer.body != "张三与李四换班（2026-01-15）" || mailer.to[0] != "ops@example.com" {
+		t.Errorf("mail = %+v", mailer)
+	}
+
+	results := map[int]int{} // 投递次数 -> 成功数
+	for _, delivery := range store.deliveries {
+		if delivery.Success {
+			results[delivery.Attempts]++
+		} else if delivery.StatusCode != http.StatusGone || delivery.Attempts != 1 {
+			t.Errorf("4xx 应不重试: %+v", delivery)
+		}
+	}
+	if len(store.deliveries) != 3 || results[2] != 1 || results[1] != 1 {
+		t.Errorf("deliveries = %d, results = %v", len(store.deliveries), results)
+	}
+}
+
+func TestRender(t *testing.T) {
+	n := New(TypeUnfilledShift, uuid.New(), "排班存在缺员", "缺 2 人", map[string]int{"shortage": 2})
+	body, err := Render(`{"text":"{{.Title}}","data":{{json .Data}}}`, n)
+	if err != nil || string(body) != `{"text":"排班存在缺员","data":{"shortage":2}}` {
+		t.Errorf("body = %s, err = %v", body, err)
+	}
+	if _, err := ParseTemplate("{{.Title"); err == nil {
+		t.Error("无效模板应返回错误")
+	}
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPMailer 通过 SMTP 发送纯文本邮件
+type SMTPMailer struct {
+	Addr string // host:port
+	From string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer 创建 SMTP 邮件发送器，username 为空时不认证
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	m := &SMTPMailer{Addr: net.JoinHostPort(host, strconv.Itoa(port)), From: from}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+// Send 发送邮件（net/smtp 不支持取消，ctx 已取消时不再发送）
+func (m *SMTPMailer) Send(ctx context.Context, to []string, subject, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := smtp.SendMail(m.Addr, m.auth, m.From, to, m.message(to, subject, body)); err != nil {
+		return fmt.Errorf("SMTP 发送失败: %w", err)
+	}
+	return nil
+}
+
+// message 构造 UTF-8 纯文本邮件，标题按 RFC 2047 编码
+func (m *SMTPMailer) message(to []string, subject, body string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", m.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return buf.Bytes()
+}
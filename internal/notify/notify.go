@@ -0,0 +1,163 @@
+// Package notify 提供通知投递
+// 业务模块通过 Notifier 发送通知，具体渠道（日志、Webhook）在服务启动时配置；
+// 启用存储时 Dispatcher 还会按组织的通知订阅投递到订阅的 Webhook 和邮件
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/logger"
+)
+
+// 通知类型
+const (
+	TypeMonthlySummary    = "monthly_summary"
+	TypeSummaryDispute    = "summary_dispute"
+	TypeDisputeResult     = "summary_dispute_result"
+	TypeApprovalAssigned  = "approval_assigned"
+	TypeApprovalReminder  = "approval_reminder"
+	TypeApprovalEscalated = "approval_escalated"
+	TypeApprovalDecided   = "approval_decided"
+	TypeIncident          = "incident"
+	TypeDocumentExpiry    = "document_expiry"
+	TypeSchedulePublished = "schedule_published"
+	TypeScheduleArchived  = "schedule_archived"
+	TypeAssignmentChanged = "assignment_changed"
+	TypeSwapApproved      = "swap_approved"
+	TypeUnfilledShift     = "unfilled_shift"
+)
+
+// Types 返回全部通知类型
+func Types() []string {
+	return []string{
+		TypeMonthlySummary, TypeSummaryDispute, TypeDisputeResult,
+		TypeApprovalAssigned, TypeApprovalReminder, TypeApprovalEscalated, TypeApprovalDecided,
+		TypeIncident, TypeDocumentExpiry,
+		TypeSchedulePublished, TypeScheduleArchived, TypeAssignmentChanged, TypeSwapApproved, TypeUnfilledShift,
+	}
+}
+
+// KnownType 是否为已知的通知类型
+func KnownType(t string) bool {
+	for _, known := range Types() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// 接收方角色
+const (
+	RecipientEmployee = "employee"
+	RecipientManager  = "manager"
+)
+
+// Notification 通知
+type Notification struct {
+	ID            uuid.UUID   `json:"id"`
+	Type          string      `json:"type"`
+	OrgID         uuid.UUID   `json:"org_id"`
+	RecipientRole string      `json:"recipient_role"`           // employee/manager
+	RecipientID   *uuid.UUID  `json:"recipient_id,omitempty"`   // 员工通知的员工ID
+	RecipientUser string      `json:"recipient_user,omitempty"` // 指定用户（如审批人）的用户ID
+	Title         string      `json:"title"`
+	Body          string      `json:"body"`
+	Data          interface{} `json:"data,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// New 创建通知
+func New(notifType string, orgID uuid.UUID, title, body string, data interface{}) *Notification {
+	return &Notification{
+		ID:            uuid.New(),
+		Type:          notifType,
+		OrgID:         orgID,
+		RecipientRole: RecipientManager,
+		Title:         title,
+		Body:          body,
+		Data:          data,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// ToEmployee 设置接收员工
+func (n *Notification) ToEmployee(employeeID uuid.UUID) *Notification {
+	n.RecipientRole = RecipientEmployee
+	n.RecipientID = &employeeID
+	return n
+}
+
+// ToUser 设置接收用户（管理者等非员工用户，按用户ID投递）
+func (n *Notification) ToUser(userID string) *Notification {
+	n.RecipientUser = userID
+	return n
+}
+
+// Notifier 通知发送器
+type Notifier interface {
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// LogNotifier 将通知写入日志（未配置投递渠道时使用）
+type LogNotifier struct{}
+
+// Notify 记录通知
+func (LogNotifier) Notify(ctx context.Context, n *Notification) error {
+	event := logger.Info().
+		Str("notification_id", n.ID.String()).
+		Str("type", n.Type).
+		Str("org_id", n.OrgID.String()).
+		Str("recipient_role", n.RecipientRole)
+	if n.RecipientID != nil {
+		event = event.Str("recipient_id", n.RecipientID.String())
+	}
+	if n.RecipientUser != "" {
+		event = event.Str("recipient_user", n.RecipientUser)
+	}
+	event.Str("title", n.Title).Msg("发送通知")
+	return nil
+}
+
+// WebhookNotifier 以 JSON POST 方式将通知投递到 Webhook
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier 创建 Webhook 通知发送器
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify 投递通知，非 2xx 响应视为失败
+func (w *WebhookNotifier) Notify(ctx context.Context, n *Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("序列化通知失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建通知请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("投递通知失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("投递通知失败: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
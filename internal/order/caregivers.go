@@ -0,0 +1,171 @@
+package order
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// CustomerCaregivers 客户在统计窗口内的服务人员
+type CustomerCaregivers struct {
+	CustomerID  uuid.UUID   `json:"customer_id"`
+	Orders      int         `json:"orders"`
+	Caregivers  int         `json:"caregivers"` // 不同服务人员数
+	EmployeeIDs []uuid.UUID `json:"employee_ids"`
+	OverCap     bool        `json:"over_cap,omitempty"` // 超过报表指定的上限
+}
+
+// CaregiverBucket 服务人员数分布：有 Caregivers 名不同服务人员的客户数
+type CaregiverBucket struct {
+	Caregivers int `json:"caregivers"`
+	Customers  int `json:"customers"`
+}
+
+// CaregiverReport 客户服务人员数量报表
+type CaregiverReport struct {
+	StartDate     string               `json:"start_date"`
+	EndDate       string               `json:"end_date"`
+	WindowDays    int                  `json:"window_days"`
+	MaxCaregivers int                  `json:"max_caregivers,omitempty"`
+	Customers     []CustomerCaregivers `json:"customers"`
+	Distribution  []CaregiverBucket    `json:"distribution"`
+	AvgCaregivers float64              `json:"avg_caregivers"`
+	OverCap       int                  `json:"over_cap"` // 超过上限的客户数
+}
+
+// Caregivers 返回截至 date（含）的 days 天滚动窗口内为客户服务过的不同服务人员（按首次服务先后）
+// 只统计已派单、服务中和已完成的订单；days 不大于 0 时按 model.DefaultCaregiverWindowDays
+func (s *Service) Caregivers(orgID, customerID uuid.UUID, date string, days int) []uuid.UUID {
+	start, end, ok := caregiverWindow(date, days)
+	if !ok {
+		return nil
+	}
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, o := range s.store.ListOrders(orgID, start, end, "") {
+		if o.CustomerID != customerID || !countsForCaregivers(o) {
+			continue
+		}
+		for _, id := range orderEmployees(o) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// CaregiverReport 统计截至 date 的滚动窗口内各客户的不同服务人员数及其分布
+// date 为空时取当天；maxCaregivers 大于 0 时标记超过上限的客户
+func (s *Service) CaregiverReport(orgID uuid.UUID, date string, days, maxCaregivers int) (*CaregiverReport, error) {
+	if date == "" {
+		date = s.now().Format("2006-01-02")
+	}
+	if days <= 0 {
+		days = model.DefaultCaregiverWindowDays
+	}
+	start, end, ok := caregiverWindow(date, days)
+	if !ok {
+		return nil, fmt.Errorf("%w: 日期格式应为 YYYY-MM-DD", ErrInvalidOrder)
+	}
+
+	report := &CaregiverReport{
+		StartDate:     start,
+		EndDate:       end,
+		WindowDays:    days,
+		MaxCaregivers: maxCaregivers,
+		Customers:     make([]CustomerCaregivers, 0),
+		Distribution:  make([]CaregiverBucket, 0),
+	}
+	byCustomer := make(map[uuid.UUID]*CustomerCaregivers)
+	seen := make(map[uuid.UUID]map[uuid.UUID]bool)
+	var customers []uuid.UUID
+	for _, o := range s.store.ListOrders(orgID, start, end, "") {
+		if o.CustomerID == uuid.Nil || !countsForCaregivers(o) {
+			continue
+		}
+		c, ok := byCustomer[o.CustomerID]
+		if !ok {
+			c = &CustomerCaregivers{CustomerID: o.CustomerID}
+			byCustomer[o.CustomerID] = c
+			seen[o.CustomerID] = make(map[uuid.UUID]bool)
+			customers = append(customers, o.CustomerID)
+		}
+		c.Orders++
+		for _, id := range orderEmployees(o) {
+			if !seen[o.CustomerID][id] {
+				seen[o.CustomerID][id] = true
+				c.EmployeeIDs = append(c.EmployeeIDs, id)
+			}
+		}
+	}
+
+	counts := make(map[int]int)
+	total := 0
+	for _, id := range customers {
+		c := byCustomer[id]
+		c.Caregivers = len(c.EmployeeIDs)
+		c.OverCap = maxCaregivers > 0 && c.Caregivers > maxCaregivers
+		if c.OverCap {
+			report.OverCap++
+		}
+		counts[c.Caregivers]++
+		total += c.Caregivers
+		report.Customers = append(report.Customers, *c)
+	}
+	sort.Slice(report.Customers, func(i, j int) bool {
+		if report.Customers[i].Caregivers != report.Customers[j].Caregivers {
+			return report.Customers[i].Caregivers > report.Customers[j].Caregivers
+		}
+		return report.Customers[i].CustomerID.String() < report.Customers[j].CustomerID.String()
+	})
+	for n, customers := range counts {
+		report.Distribution = append(report.Distribution, CaregiverBucket{Caregivers: n, Customers: customers})
+	}
+	sort.Slice(report.Distribution, func(i, j int) bool {
+		return report.Distribution[i].Caregivers < report.Distribution[j].Caregivers
+	})
+	if len(customers) > 0 {
+		report.AvgCaregivers = float64(total) / float64(len(customers))
+	}
+	return report, nil
+}
+
+// caregiverWindow 返回截至 date 的 days 天窗口的起止日期
+func caregiverWindow(date string, days int) (string, string, bool) {
+	end, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", "", false
+	}
+	if days <= 0 {
+		days = model.DefaultCaregiverWindowDays
+	}
+	return end.AddDate(0, 0, 1-days).Format("2006-01-02"), date, true
+}
+
+// countsForCaregivers 检查订单是否计入客户的服务人员（已派出且未取消）
+func countsForCaregivers(o *model.ServiceOrder) bool {
+	switch o.Status {
+	case model.OrderAssigned, model.OrderInProgress, model.OrderCompleted:
+		return true
+	}
+	return false
+}
+
+// orderEmployees 返回订单的全部服务人员（带队人和团队成员）
+func orderEmployees(o *model.ServiceOrder) []uuid.UUID {
+	ids := append([]uuid.UUID(nil), o.EmployeeIDs...)
+	if o.EmployeeID != nil {
+		for _, id := range ids {
+			if id == *o.EmployeeID {
+				return ids
+			}
+		}
+		ids = append([]uuid.UUID{*o.EmployeeID}, ids...)
+	}
+	return ids
+}
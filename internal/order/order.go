@@ -0,0 +1,231 @@
+// Package order 提供服务订单记录与完工凭证
+// 派单结果以订单记录保存，订单按状态流转（待派单、已派单、服务中、已完成、已取消）；
+// 上门服务完成时提交客户签名、完工照片和说明作为完成凭证，组织的完工策略要求时凭证不全不能完成订单；
+// 已完成订单连同完成凭证导出为结算清单
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+var (
+	// ErrInvalidOrder 订单参数无效
+	ErrInvalidOrder = errors.New("订单无效")
+	// ErrInvalidTransition 订单状态不能流转
+	ErrInvalidTransition = errors.New("订单状态不能流转")
+	// ErrProofRequired 完成凭证不满足组织的完工策略
+	ErrProofRequired = errors.New("完成凭证不完整")
+)
+
+// BillingLine 结算清单行（已完成订单及其完成凭证）
+type BillingLine struct {
+	OrderID      uuid.UUID   `json:"order_id"`
+	OrderNo      string      `json:"order_no"`
+	CustomerID   uuid.UUID   `json:"customer_id"`
+	ServiceType  string      `json:"service_type"`
+	ServiceDate  string      `json:"service_date"`
+	Duration     int         `json:"duration"` // 分钟
+	Amount       float64     `json:"amount"`
+	EmployeeIDs  []uuid.UUID `json:"employee_ids"`
+	CompletedAt  *time.Time  `json:"completed_at,omitempty"`
+	SignatureRef string      `json:"signature_ref,omitempty"`
+	SignerName   string      `json:"signer_name,omitempty"`
+	Photos       []string    `json:"photos,omitempty"`
+	Notes        string      `json:"notes,omitempty"`
+}
+
+// Service 服务订单服务
+type Service struct {
+	store *memstore.Store
+	now   func() time.Time
+}
+
+// NewService 创建服务订单服务
+func NewService(store *memstore.Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// Create 保存订单记录，新订单只能为待派单或已派单（填写了服务人员）状态，完成须经状态流转
+func (s *Service) Create(o *model.ServiceOrder) (*model.ServiceOrder, error) {
+	if o.OrderNo == "" {
+		return nil, fmt.Errorf("%w: 订单号不能为空", ErrInvalidOrder)
+	}
+	if _, err := time.Parse("2006-01-02", o.ServiceDate); err != nil {
+		return nil, fmt.Errorf("%w: 服务日期格式应为 YYYY-MM-DD", ErrInvalidOrder)
+	}
+	switch o.Status {
+	case "":
+		o.Status = model.OrderPending
+		if o.EmployeeID != nil {
+			o.Status = model.OrderAssigned
+		}
+	case model.OrderPending, model.OrderAssigned:
+	default:
+		return nil, fmt.Errorf("%w: 新订单状态只能为 pending 或 assigned", ErrInvalidOrder)
+	}
+	if o.Status == model.OrderAssigned && o.EmployeeID == nil {
+		return nil, fmt.Errorf("%w: 已派单的订单须填写 employee_id", ErrInvalidOrder)
+	}
+
+	now := s.now()
+	o.BaseModel = model.BaseModel{ID: uuid.New(), CreatedAt: now, UpdatedAt: now}
+	o.CompletedAt, o.CompletionProof = nil, nil
+	if o.Status == model.OrderAssigned {
+		o.AssignedAt = &now
+	}
+	if err := s.store.PutOrder(o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// CreateMissing 保存一批订单（如按护理计划生成的订单），组织内服务日期相同且订单号已存在的订单跳过，
+// 返回新保存的订单和跳过的订单号
+func (s *Service) CreateMissing(orgID uuid.UUID, orders []*model.ServiceOrder) ([]*model.ServiceOrder, []string, error) {
+	existing := make(map[string]bool) // 已存在的订单号
+	loaded := make(map[string]bool)   // 已查询的服务日期
+	for _, o := range orders {
+		if loaded[o.ServiceDate] {
+			continue
+		}
+		loaded[o.ServiceDate] = true
+		for _, e := range s.store.ListOrders(orgID, o.ServiceDate, o.ServiceDate, "") {
+			existing[e.OrderNo] = true
+		}
+	}
+	created := make([]*model.ServiceOrder, 0, len(orders))
+	skipped := make([]string, 0)
+	for _, o := range orders {
+		if existing[o.OrderNo] {
+			skipped = append(skipped, o.OrderNo)
+			continue
+		}
+		o.OrgID = orgID
+		c, err := s.Create(o)
+		if err != nil {
+			return created, skipped, err
+		}
+		existing[o.OrderNo] = true
+		created = append(created, c)
+	}
+	return created, skipped, nil
+}
+
+// Transition 变更订单状态
+// 派单、开始服务和完成须有服务人员；完成时按组织的完工策略检查完成凭证
+func (s *Service) Transition(orgID, id uuid.UUID, status string, employeeID *uuid.UUID) (*model.ServiceOrder, error) {
+	if !model.IsValidOrderStatus(status) {
+		return nil, fmt.Errorf("%w: 不支持的状态 %q", ErrInvalidOrder, status)
+	}
+	o, err := s.get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !model.CanTransitionOrder(o.Status, status) {
+		return o, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, o.Status, status)
+	}
+	if employeeID != nil {
+		o.EmployeeID = employeeID
+	}
+
+	now := s.now()
+	switch status {
+	case model.OrderPending:
+		o.EmployeeID, o.EmployeeIDs, o.AssignedAt = nil, nil, nil
+	case model.OrderAssigned, model.OrderInProgress, model.OrderCompleted:
+		if o.EmployeeID == nil {
+			return o, fmt.Errorf("%w: 订单未指定服务人员", ErrInvalidOrder)
+		}
+		if o.AssignedAt == nil {
+			o.AssignedAt = &now
+		}
+	}
+	if status == model.OrderCompleted {
+		if policy := s.policy(orgID); policy != nil {
+			if missing := policy.Check(o.CompletionProof); len(missing) > 0 {
+				return o, fmt.Errorf("%w: 缺少%s", ErrProofRequired, strings.Join(missing, "、"))
+			}
+		}
+		o.CompletedAt = &now
+	}
+	o.Status = status
+	o.UpdatedAt = now
+	if err := s.store.PutOrder(o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// AttachProof 提交订单的完成凭证（覆盖之前提交的凭证），已取消的订单不能提交
+func (s *Service) AttachProof(orgID, id uuid.UUID, proof *model.CompletionProof, capturedBy string) (*model.ServiceOrder, error) {
+	if proof.SignatureRef == "" && len(proof.Photos) == 0 && proof.Notes == "" {
+		return nil, fmt.Errorf("%w: 签名、照片和完工说明不能都为空", ErrInvalidOrder)
+	}
+	o, err := s.get(orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if o.Status == model.OrderCancelled {
+		return o, fmt.Errorf("%w: 订单已取消", ErrInvalidTransition)
+	}
+	now := s.now()
+	proof.CapturedBy = capturedBy
+	proof.CapturedAt = now
+	o.CompletionProof = proof
+	o.UpdatedAt = now
+	if err := s.store.PutOrder(o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Billing 导出服务日期在 [start, end] 内已完成订单的结算清单
+func (s *Service) Billing(orgID uuid.UUID, start, end string) []BillingLine {
+	lines := make([]BillingLine, 0)
+	for _, o := range s.store.ListOrders(orgID, start, end, model.OrderCompleted) {
+		line := BillingLine{
+			OrderID:     o.ID,
+			OrderNo:     o.OrderNo,
+			CustomerID:  o.CustomerID,
+			ServiceType: o.ServiceType,
+			ServiceDate: o.ServiceDate,
+			Duration:    o.Duration,
+			Amount:      o.Amount,
+			EmployeeIDs: o.EmployeeIDs,
+			CompletedAt: o.CompletedAt,
+		}
+		if len(line.EmployeeIDs) == 0 && o.EmployeeID != nil {
+			line.EmployeeIDs = []uuid.UUID{*o.EmployeeID}
+		}
+		if p := o.CompletionProof; p != nil {
+			line.SignatureRef, line.SignerName, line.Photos, line.Notes = p.SignatureRef, p.SignerName, p.Photos, p.Notes
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// policy 返回组织的完工策略，未配置时返回 nil
+func (s *Service) policy(orgID uuid.UUID) *model.CompletionPolicy {
+	org, err := s.store.GetOrganization(orgID)
+	if err != nil {
+		return nil
+	}
+	return org.CompletionPolicy
+}
+
+// get 获取组织下的订单
+func (s *Service) get(orgID, id uuid.UUID) (*model.ServiceOrder, error) {
+	o, err := s.store.GetOrder(id)
+	if err != nil || o.OrgID != orgID {
+		return nil, memstore.ErrNotFound
+	}
+	return o, nil
+}
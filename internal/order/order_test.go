@@ -0,0 +1,128 @@
+package order
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestService_CompletionRequiresProof(t *testing.T) {
+	store := memstore.New("")
+	s := NewService(store)
+	orgID, empID := uuid.New(), uuid.New()
+	store.PutOrganization(&model.Organization{
+		BaseModel:        model.BaseModel{ID: orgID},
+		CompletionPolicy: &model.CompletionPolicy{RequireSignature: true, MinPhotos: 1},
+	})
+
+	o, err := s.Create(&model.ServiceOrder{OrgID: orgID, OrderNo: "SO-1", ServiceDate: "2026-03-02", EmployeeID: &empID, Amount: 120})
+	if err != nil || o.Status != model.OrderAssigned {
+		t.Fatalf("创建订单失败: %v %+v", err, o)
+	}
+	if _, err := s.Transition(orgID, o.ID, model.OrderCompleted, nil); !errors.Is(err, ErrProofRequired) {
+		t.Fatalf("缺少凭证时不能完成订单: %v", err)
+	}
+	if _, err := s.AttachProof(orgID, o.ID, &model.CompletionProof{SignatureRef: "sign/so-1.png"}, "u1"); err != nil {
+		t.Fatalf("提交凭证失败: %v", err)
+	}
+	if _, err := s.Transition(orgID, o.ID, model.OrderCompleted, nil); !errors.Is(err, ErrProofRequired) {
+		t.Errorf("照片不足时不能完成订单: %v", err)
+	}
+	if _, err := s.AttachProof(orgID, o.ID, &model.CompletionProof{SignatureRef: "sign/so-1.png", Photos: []string{"photo/1.jpg"}, Notes: "已完成"}, "u1"); err != nil {
+		t.Fatalf("提交凭证失败: %v", err)
+	}
+	done, err := s.Transition(orgID, o.ID, model.OrderCompleted, nil)
+	if err != nil || done.CompletedAt == nil {
+		t.Fatalf("凭证齐全应能完成订单: %v", err)
+	}
+	if _, err := s.Transition(orgID, o.ID, model.OrderCancelled, nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("已完成订单不能取消: %v", err)
+	}
+
+	lines := s.Billing(orgID, "2026-03-01", "2026-03-31")
+	if len(lines) != 1 || lines[0].SignatureRef != "sign/so-1.png" || len(lines[0].Photos) != 1 ||
+		len(lines[0].EmployeeIDs) != 1 || lines[0].EmployeeIDs[0] != empID {
+		t.Errorf("结算清单应包含完成凭证: %+v", lines)
+	}
+
+	// 未配置完工策略的组织不要求凭证
+	other := uuid.New()
+	o, _ = s.Create(&model.ServiceOrder{OrgID: other, OrderNo: "SO-2", ServiceDate: "2026-03-02", EmployeeID: &empID})
+	if _, err := s.Transition(other, o.ID, model.OrderCompleted, nil); err != nil {
+		t.Errorf("未配置完工策略时应能直接完成: %v", err)
+	}
+}
+
+func TestService_CaregiverReport(t *testing.T) {
+	store := memstore.New("")
+	s := NewService(store)
+	orgID, c1, c2 := uuid.New(), uuid.New(), uuid.New()
+	e1, e2, e3 := uuid.New(), uuid.New(), uuid.New()
+	create := func(customer uuid.UUID, date string, emp uuid.UUID, crew ...uuid.UUID) *model.ServiceOrder {
+		o, err := s.Create(&model.ServiceOrder{OrgID: orgID, CustomerID: customer, OrderNo: date, ServiceDate: date, EmployeeID: &emp, EmployeeIDs: crew})
+		if err != nil {
+			t.Fatalf("创建订单失败: %v", err)
+		}
+		return o
+	}
+	create(c1, "2026-03-01", e1)
+	create(c1, "2026-03-10", e2, e2, e3)
+	create(c1, "2026-01-15", e3) // 窗口外
+	create(c2, "2026-03-05", e1)
+	cancelled := create(c2, "2026-03-06", e2)
+	if _, err := s.Transition(orgID, cancelled.ID, model.OrderCancelled, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := s.Caregivers(orgID, c1, "2026-03-10", 30); len(ids) != 3 || ids[0] != e1 {
+		t.Errorf("Caregivers() = %v, want e1 e2 e3", ids)
+	}
+	if ids := s.Caregivers(orgID, c2, "2026-03-10", 30); len(ids) != 1 {
+		t.Errorf("已取消订单不应计入: %v", ids)
+	}
+
+	report, err := s.CaregiverReport(orgID, "2026-03-10", 30, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.StartDate != "2026-02-09" || len(report.Customers) != 2 || report.Customers[0].CustomerID != c1 ||
+		report.Customers[0].Caregivers != 3 || !report.Customers[0].OverCap || report.OverCap != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Distribution) != 2 || report.Distribution[0] != (CaregiverBucket{Caregivers: 1, Customers: 1}) ||
+		report.AvgCaregivers != 2 {
+		t.Errorf("distribution = %+v, avg = %v", report.Distribution, report.AvgCaregivers)
+	}
+	if _, err := s.CaregiverReport(orgID, "2026/03/10", 30, 0); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("日期格式错误应返回 ErrInvalidOrder: %v", err)
+	}
+}
+
+func TestService_CreateMissing(t *testing.T) {
+	store := memstore.New("")
+	s := NewService(store)
+	orgID := uuid.New()
+	batch := func() []*model.ServiceOrder {
+		return []*model.ServiceOrder{
+			{OrderNo: "CP1-20260302", ServiceDate: "2026-03-02"},
+			{OrderNo: "CP1-20260304", ServiceDate: "2026-03-04"},
+		}
+	}
+	created, skipped, err := s.CreateMissing(orgID, batch())
+	if err != nil || len(created) != 2 || len(skipped) != 0 || created[0].OrgID != orgID {
+		t.Fatalf("首次生成: created=%d skipped=%v err=%v", len(created), skipped, err)
+	}
+
+	// 重复生成时跳过已有订单号
+	more := append(batch(), &model.ServiceOrder{OrderNo: "CP1-20260306", ServiceDate: "2026-03-06"})
+	created, skipped, err = s.CreateMissing(orgID, more)
+	if err != nil || len(created) != 1 || len(skipped) != 2 {
+		t.Errorf("重复生成: created=%d skipped=%v err=%v", len(created), skipped, err)
+	}
+	if n := len(store.ListOrders(orgID, "", "", "")); n != 3 {
+		t.Errorf("orders = %d, want 3", n)
+	}
+}
@@ -0,0 +1,282 @@
+// Package payroll 提供工资结账期间锁定与结账后调整
+// 结账后组织截止日期之前的分配不可再通过排班编辑修改；确需修改时走调整流程，
+// 调整直接修改排班并单独记录影响工资的工时差额，在下一次结账时纳入工资计算
+package payroll
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/draft"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/timebank"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+var (
+	// ErrInvalidClose 结账日期无效
+	ErrInvalidClose = errors.New("结账日期无效")
+	// ErrInvalidAdjustment 调整无效
+	ErrInvalidAdjustment = errors.New("工资调整无效")
+	// ErrInvalidMonth 月份格式错误
+	ErrInvalidMonth = errors.New("月份格式应为 YYYY-MM")
+)
+
+// EmployeeDelta 员工工时差额汇总
+type EmployeeDelta struct {
+	EmployeeID  uuid.UUID `json:"employee_id"`
+	HoursDelta  float64   `json:"hours_delta"`
+	Adjustments int       `json:"adjustments"`
+}
+
+// OvertimeLine 员工当月的加班结算与调休
+type OvertimeLine struct {
+	EmployeeID        uuid.UUID `json:"employee_id"`
+	OvertimeHours     float64   `json:"overtime_hours"`      // 审批通过的加班时长
+	PaidOvertimeHours float64   `json:"paid_overtime_hours"` // 按加班费结算
+	BankedHours       float64   `json:"banked_hours"`        // 计入调休的加班时长（折算前）
+	TimeBankCredit    float64   `json:"time_bank_credit"`    // 计入调休账户的小时数（含手工调整）
+	TimeBankUsed      float64   `json:"time_bank_used"`      // 当月已批准请假使用的调休
+	TimeBankBalance   float64   `json:"time_bank_balance"`   // 当前调休余额
+}
+
+// Report 工资报表：按组织调休策略区分支付加班费的加班与计入调休的小时数
+type Report struct {
+	OrgID             uuid.UUID             `json:"org_id"`
+	Month             string                `json:"month"`
+	Policy            *model.TimeBankPolicy `json:"time_bank_policy,omitempty"`
+	OvertimeHours     float64               `json:"overtime_hours"`
+	PaidOvertimeHours float64               `json:"paid_overtime_hours"`
+	BankedHours       float64               `json:"banked_hours"`
+	Employees         []OvertimeLine        `json:"employees"`
+}
+
+// Service 工资结账服务
+type Service struct {
+	store *memstore.Store
+	now   func() time.Time
+}
+
+// NewService 创建工资结账服务
+func NewService(store *memstore.Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// Close 结账：锁定 lockedBefore 之前的分配，并将尚未结算的调整纳入本次结账
+// 结账日期必须晚于组织当前的结账日期
+func (s *Service) Close(orgID uuid.UUID, lockedBefore, closedBy, note string) (*model.PayrollClose, error) {
+	if _, err := time.Parse("2006-01-02", lockedBefore); err != nil {
+		return nil, fmt.Errorf("%w: locked_before 格式应为 YYYY-MM-DD", ErrInvalidClose)
+	}
+	if current := s.store.PayrollLockedBefore(orgID); lockedBefore <= current {
+		return nil, fmt.Errorf("%w: 必须晚于当前结账日期 %s", ErrInvalidClose, current)
+	}
+
+	c := &model.PayrollClose{
+		ID:           uuid.New(),
+		OrgID:        orgID,
+		LockedBefore: lockedBefore,
+		Note:         note,
+		ClosedBy:     closedBy,
+		ClosedAt:     s.now(),
+	}
+	settled, err := s.store.AddPayrollClose(c)
+	if err != nil {
+		return nil, err
+	}
+	c.Settled = settled
+	return c, nil
+}
+
+// Closes 列出组织的结账记录及当前结账日期
+func (s *Service) Closes(orgID uuid.UUID) ([]*model.PayrollClose, string) {
+	return s.store.ListPayrollCloses(orgID), s.store.PayrollLockedBefore(orgID)
+}
+
+// Adjustments 列出组织的调整记录，status 为 pending/settled 时按结算状态过滤
+func (s *Service) Adjustments(orgID uuid.UUID, status string) []*model.PayrollAdjustment {
+	return s.store.ListPayrollAdjustments(orgID, status)
+}
+
+// Adjust 修改已结账期间的分配
+// 只接受涉及已结账日期的变更（未结账的分配应通过排班编辑修改），适用于任何状态的排班；
+// 排班版本递增并记录修订，每个变更按员工记录工时差额，等待下一次结账
+func (s *Service) Adjust(orgID, scheduleID uuid.UUID, changes []model.AssignmentChange, reason, author string) ([]*model.PayrollAdjustment, error) {
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason 不能为空", ErrInvalidAdjustment)
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: changes 不能为空", ErrInvalidAdjustment)
+	}
+	schedule, err := s.store.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.OrgID != orgID {
+		return nil, memstore.ErrNotFound
+	}
+
+	lockedBefore := s.store.PayrollLockedBefore(orgID)
+	now := s.now()
+	adjustments := make([]*model.PayrollAdjustment, 0, len(changes))
+	applied := make([]model.AssignmentChange, 0, len(changes))
+	for i, c := range changes {
+		if _, locked := draft.LockedDate(schedule, c, lockedBefore); !locked {
+			return nil, fmt.Errorf("%w: 第 %d 个变更不涉及已结账期间，请通过排班编辑修改", ErrInvalidAdjustment, i)
+		}
+		var before *model.Assignment
+		if c.Op != model.ChangeAdd {
+			for _, a := range schedule.Assignments {
+				if a.ID == c.AssignmentID {
+					a := a
+					before = &a
+					break
+				}
+			}
+		}
+		result, err := draft.ApplyChanges(schedule, []model.AssignmentChange{c})
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAdjustment, err)
+		}
+		applied = append(applied, result...)
+		for _, adj := range deltas(before, result[0].Assignment) {
+			adj.ID = uuid.New()
+			adj.OrgID = orgID
+			adj.ScheduleID = scheduleID
+			adj.AssignmentID = result[0].AssignmentID
+			adj.Op = c.Op
+			adj.Reason = reason
+			adj.CreatedBy = author
+			adj.CreatedAt = now
+			adjustments = append(adjustments, adj)
+		}
+	}
+
+	expected := schedule.Version
+	schedule.Version = expected + 1
+	schedule.UpdatedAt = now
+	revision := &model.ScheduleRevision{
+		ScheduleID: schedule.ID,
+		Version:    schedule.Version,
+		Changes:    applied,
+		Author:     author,
+		CreatedAt:  now,
+	}
+	if err := s.store.CompareAndSwapSchedule(schedule, expected, revision); err != nil {
+		return nil, err
+	}
+	if err := s.store.AddPayrollAdjustments(adjustments); err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+// Report 生成组织某月的工资报表
+// 加班按审批通过时的调休策略结算（见调休账户记录），员工为当月有加班结算、调休调整或使用调休的员工
+func (s *Service) Report(orgID uuid.UUID, month string) (*Report, error) {
+	first, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, ErrInvalidMonth
+	}
+	startDate, endDate := first.Format("2006-01-02"), first.AddDate(0, 1, -1).Format("2006-01-02")
+
+	report := &Report{OrgID: orgID, Month: month, Employees: make([]OvertimeLine, 0)}
+	if org, err := s.store.GetOrganization(orgID); err == nil {
+		report.Policy = org.TimeBankPolicy
+	}
+
+	bank := timebank.NewService(s.store)
+	lines := make(map[uuid.UUID]*OvertimeLine)
+	line := func(employeeID uuid.UUID) *OvertimeLine {
+		l, ok := lines[employeeID]
+		if !ok {
+			l = &OvertimeLine{EmployeeID: employeeID}
+			lines[employeeID] = l
+		}
+		return l
+	}
+	for _, e := range s.store.ListTimeBankEntries(orgID, uuid.Nil, "", "") {
+		l := line(e.EmployeeID)
+		if e.Date < startDate || e.Date > endDate {
+			continue // 其他月份的记录只用于确定员工，以统计当月使用的调休
+		}
+		l.OvertimeHours += e.OvertimeHours
+		l.PaidOvertimeHours += e.PaidHours
+		l.BankedHours += e.BankedHours
+		l.TimeBankCredit += e.Hours
+	}
+	for employeeID, l := range lines {
+		for _, av := range s.store.ListAvailability(employeeID, startDate, endDate) {
+			used, _ := timebank.Spend(av)
+			l.TimeBankUsed += used
+		}
+		l.TimeBankBalance = bank.Balance(employeeID).Balance
+	}
+
+	for _, l := range lines {
+		if l.OvertimeHours == 0 && l.TimeBankCredit == 0 && l.TimeBankUsed == 0 {
+			continue
+		}
+		l.OvertimeHours, l.PaidOvertimeHours, l.BankedHours = round(l.OvertimeHours), round(l.PaidOvertimeHours), round(l.BankedHours)
+		l.TimeBankCredit, l.TimeBankUsed = round(l.TimeBankCredit), round(l.TimeBankUsed)
+		report.OvertimeHours += l.OvertimeHours
+		report.PaidOvertimeHours += l.PaidOvertimeHours
+		report.BankedHours += l.BankedHours
+		report.Employees = append(report.Employees, *l)
+	}
+	report.OvertimeHours, report.PaidOvertimeHours, report.BankedHours = round(report.OvertimeHours), round(report.PaidOvertimeHours), round(report.BankedHours)
+	sort.Slice(report.Employees, func(i, j int) bool {
+		return report.Employees[i].EmployeeID.String() < report.Employees[j].EmployeeID.String()
+	})
+	return report, nil
+}
+
+// deltas 计算变更前后分配的工时差额
+// 同一员工记录一条差额；更换员工时原员工记录负差额、新员工记录正差额
+func deltas(before, after *model.Assignment) []*model.PayrollAdjustment {
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return []*model.PayrollAdjustment{{EmployeeID: after.EmployeeID, Date: after.Date, After: after, HoursDelta: round(after.WorkingHours())}}
+	case after == nil:
+		return []*model.PayrollAdjustment{{EmployeeID: before.EmployeeID, Date: before.Date, Before: before, HoursDelta: round(-before.WorkingHours())}}
+	case before.EmployeeID == after.EmployeeID:
+		return []*model.PayrollAdjustment{{EmployeeID: after.EmployeeID, Date: after.Date, Before: before, After: after,
+			HoursDelta: round(after.WorkingHours() - before.WorkingHours())}}
+	default:
+		return []*model.PayrollAdjustment{
+			{EmployeeID: before.EmployeeID, Date: before.Date, Before: before, After: after, HoursDelta: round(-before.WorkingHours())},
+			{EmployeeID: after.EmployeeID, Date: after.Date, Before: before, After: after, HoursDelta: round(after.WorkingHours())},
+		}
+	}
+}
+
+// Totals 按员工汇总工时差额（按员工ID排序）
+func Totals(adjustments []*model.PayrollAdjustment) []EmployeeDelta {
+	byEmployee := make(map[uuid.UUID]*EmployeeDelta)
+	for _, a := range adjustments {
+		d, ok := byEmployee[a.EmployeeID]
+		if !ok {
+			d = &EmployeeDelta{EmployeeID: a.EmployeeID}
+			byEmployee[a.EmployeeID] = d
+		}
+		d.HoursDelta = round(d.HoursDelta + a.HoursDelta)
+		d.Adjustments++
+	}
+	result := make([]EmployeeDelta, 0, len(byEmployee))
+	for _, d := range byEmployee {
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].EmployeeID.String() < result[j].EmployeeID.String() })
+	return result
+}
+
+// round 保留两位小数
+func round(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
@@ -0,0 +1,128 @@
+package payroll
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func newTestSchedule(t *testing.T) (*Service, *memstore.Store, *model.Schedule) {
+	t.Helper()
+	store := memstore.New("")
+	schedule := &model.Schedule{
+		BaseModel: model.NewBaseModel(),
+		OrgID:     uuid.New(),
+		StartDate: "2026-03-01",
+		EndDate:   "2026-03-31",
+		Status:    "published",
+		Version:   1,
+	}
+	for _, date := range []string{"2026-03-10", "2026-03-20"} {
+		start, _ := time.Parse("2006-01-02 15:04", date+" 09:00")
+		schedule.Assignments = append(schedule.Assignments, model.Assignment{
+			BaseModel:  model.NewBaseModel(),
+			EmployeeID: uuid.New(),
+			ShiftID:    uuid.New(),
+			Date:       date,
+			StartTime:  start,
+			EndTime:    start.Add(8 * time.Hour),
+			Status:     "completed",
+		})
+	}
+	store.PutSchedule(schedule)
+	return NewService(store), store, schedule
+}
+
+func TestService_CloseMustAdvance(t *testing.T) {
+	s, store, schedule := newTestSchedule(t)
+
+	if _, err := s.Close(schedule.OrgID, "2026-03-16", "hr", "三月上半月"); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := store.PayrollLockedBefore(schedule.OrgID); got != "2026-03-16" {
+		t.Errorf("PayrollLockedBefore() = %q", got)
+	}
+	for _, date := range []string{"2026-03-16", "2026-03-01", "2026/04/01"} {
+		if _, err := s.Close(schedule.OrgID, date, "hr", ""); !errors.Is(err, ErrInvalidClose) {
+			t.Errorf("Close(%s) 应被拒绝, got %v", date, err)
+		}
+	}
+}
+
+func TestService_AdjustRecordsDeltas(t *testing.T) {
+	s, store, schedule := newTestSchedule(t)
+	locked, open := schedule.Assignments[0], schedule.Assignments[1]
+	s.Close(schedule.OrgID, "2026-03-16", "hr", "")
+
+	// 未结账的分配应通过排班编辑修改
+	if _, err := s.Adjust(schedule.OrgID, schedule.ID, []model.AssignmentChange{{Op: model.ChangeRemove, AssignmentID: open.ID}}, "补录", "hr"); !errors.Is(err, ErrInvalidAdjustment) {
+		t.Fatalf("未结账的分配不应走调整流程, got %v", err)
+	}
+
+	// 更换员工并缩短 2 小时
+	substitute := uuid.New()
+	after := locked
+	after.EmployeeID = substitute
+	after.EndTime = after.EndTime.Add(-2 * time.Hour)
+	adjustments, err := s.Adjust(schedule.OrgID, schedule.ID, []model.AssignmentChange{{Op: model.ChangeUpdate, AssignmentID: locked.ID, Assignment: &after}}, "实际由他人顶班", "hr")
+	if err != nil {
+		t.Fatalf("Adjust() error = %v", err)
+	}
+	if len(adjustments) != 2 || adjustments[0].HoursDelta != -8 || adjustments[1].HoursDelta != 6 || adjustments[1].EmployeeID != substitute {
+		t.Errorf("更换员工应分别记录差额: %+v %+v", adjustments[0], adjustments[1])
+	}
+	updated, _ := store.GetSchedule(schedule.ID)
+	if updated.Version != 2 || updated.Assignments[0].EmployeeID != substitute {
+		t.Errorf("调整应修改排班并递增版本: %+v", updated)
+	}
+
+	pending := store.ListPayrollAdjustments(schedule.OrgID, "pending")
+	if totals := Totals(pending); len(totals) != 2 {
+		t.Errorf("Totals() = %+v", totals)
+	}
+
+	// 下一次结账纳入调整
+	c, err := s.Close(schedule.OrgID, "2026-04-01", "hr", "")
+	if err != nil || c.Settled != 2 {
+		t.Fatalf("Close() = %+v, %v", c, err)
+	}
+	if len(store.ListPayrollAdjustments(schedule.OrgID, "pending")) != 0 || len(store.ListPayrollAdjustments(schedule.OrgID, "settled")) != 2 {
+		t.Error("结账后调整应标记为已结算")
+	}
+}
+
+func TestService_ReportSplitsPaidAndBanked(t *testing.T) {
+	s, store, _ := newTestSchedule(t)
+	orgID, empID := uuid.New(), uuid.New()
+	org := &model.Organization{BaseModel: model.NewBaseModel()}
+	org.ID = orgID
+	org.TimeBankPolicy = &model.TimeBankPolicy{BankRatio: 0.25}
+	store.PutOrganization(org)
+
+	for _, date := range []string{"2026-02-28", "2026-03-07"} {
+		id := uuid.New()
+		store.AddTimeBankEntry(&model.TimeBankEntry{
+			ID: uuid.New(), OrgID: orgID, EmployeeID: empID, Date: date, Kind: model.TimeBankOvertime,
+			ApprovalID: &id, OvertimeHours: 8, PaidHours: 6, BankedHours: 2, Hours: 2,
+		})
+	}
+	store.PutAvailability(&model.EmployeeAvailability{EmployeeID: empID, Date: "2026-03-20", Type: "unavailable", TimeBankHours: 3})
+
+	if _, err := s.Report(orgID, "2026-3"); !errors.Is(err, ErrInvalidMonth) {
+		t.Errorf("月份格式错误应被拒绝: %v", err)
+	}
+	report, err := s.Report(orgID, "2026-03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Policy == nil || report.OvertimeHours != 8 || report.PaidOvertimeHours != 6 || report.BankedHours != 2 || len(report.Employees) != 1 {
+		t.Fatalf("只统计当月加班: %+v", report)
+	}
+	if line := report.Employees[0]; line.TimeBankUsed != 3 || line.TimeBankBalance != 1 {
+		t.Errorf("当月使用调休 3 小时，余额 4-3=1: %+v", line)
+	}
+}
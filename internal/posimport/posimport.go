@@ -0,0 +1,377 @@
+// Package posimport 提供 POS 业务量导入
+// 餐厅从收银系统导出的小时交易笔数（CSV）按人效（每人每小时处理的交易数）换算为每小时所需人数，
+// 再按班次覆盖的小时生成各班次的草稿需求；管理者审核采用后才写入组织需求，用于排班生成
+package posimport
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// minShiftOverlap 班次覆盖某小时的最短重叠时间
+const minShiftOverlap = 30 * time.Minute
+
+var (
+	// ErrInvalidCSV CSV 内容无法解析
+	ErrInvalidCSV = errors.New("业务量 CSV 无效")
+	// ErrInvalidOptions 导入参数无效
+	ErrInvalidOptions = errors.New("导入参数无效")
+	// ErrReviewed 导入记录已审核
+	ErrReviewed = errors.New("需求导入已审核")
+)
+
+// 表头别名（小写）
+var (
+	dateColumns     = []string{"date", "日期"}
+	hourColumns     = []string{"hour", "time", "小时", "时段"}
+	datetimeColumns = []string{"datetime", "timestamp", "时间"}
+	volumeColumns   = []string{"transactions", "orders", "count", "volume", "交易笔数", "订单数", "单数"}
+)
+
+// Options 导入参数
+type Options struct {
+	Source       string
+	Productivity float64     // 每人每小时处理的交易数
+	MinStaff     int         // 有业务量的小时至少安排的人数
+	ShiftIDs     []uuid.UUID // 生成需求的班次，为空表示组织的全部启用班次
+}
+
+// Service POS 需求导入服务
+type Service struct {
+	store *memstore.Store
+	now   func() time.Time
+}
+
+// NewService 创建 POS 需求导入服务
+func NewService(store *memstore.Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// Import 解析小时业务量 CSV 并生成待审核的草稿需求
+// CSV 须有表头：日期列（date/日期）和小时列（hour/时段，0-23 或 HH:MM），或单个时间列（datetime，YYYY-MM-DD HH:MM），
+// 以及业务量列（transactions/orders/交易笔数 等）；同一小时的多行（如多台收银机）累加
+func (s *Service) Import(orgID uuid.UUID, r io.Reader, opts Options, createdBy string) (*model.RequirementImport, error) {
+	if opts.Productivity <= 0 || opts.MinStaff < 0 {
+		return nil, fmt.Errorf("%w: 人效应大于 0，最少人数不能为负数", ErrInvalidOptions)
+	}
+	shifts, err := s.shifts(orgID, opts.ShiftIDs)
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := parseVolumes(r)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &model.RequirementImport{
+		ID:           uuid.New(),
+		OrgID:        orgID,
+		Source:       opts.Source,
+		StartDate:    volumes[0].Date,
+		EndDate:      volumes[len(volumes)-1].Date,
+		Productivity: opts.Productivity,
+		MinStaff:     opts.MinStaff,
+		Curve:        staffingCurve(volumes, opts.Productivity, opts.MinStaff),
+		Status:       model.RequirementImportPending,
+		CreatedBy:    createdBy,
+		CreatedAt:    s.now(),
+	}
+	imp.Requirements, imp.Uncovered = shiftRequirements(orgID, imp.Curve, shifts)
+	if err := s.store.PutRequirementImport(imp); err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
+
+// Review 审核导入记录：采用时用草稿需求替换组织在导入日期范围内、相同班次的需求，其他班次的需求保留
+func (s *Service) Review(orgID, id uuid.UUID, approve bool, reviewer, note string) (*model.RequirementImport, error) {
+	imp, err := s.store.GetRequirementImport(id)
+	if err != nil || imp.OrgID != orgID {
+		return nil, memstore.ErrNotFound
+	}
+	if imp.Status != model.RequirementImportPending {
+		return imp, ErrReviewed
+	}
+	if !approve && note == "" {
+		return nil, fmt.Errorf("%w: 驳回原因不能为空", ErrInvalidOptions)
+	}
+
+	if approve {
+		imported := make(map[uuid.UUID]bool)
+		reqs := make([]*model.ShiftRequirement, 0, len(imp.Requirements))
+		for i := range imp.Requirements {
+			r := imp.Requirements[i]
+			imported[r.ShiftID] = true
+			reqs = append(reqs, &r)
+		}
+		for _, existing := range s.store.ListRequirements(orgID, imp.StartDate, imp.EndDate) {
+			if !imported[existing.ShiftID] {
+				reqs = append(reqs, existing)
+			}
+		}
+		if err := s.store.ReplaceRequirements(orgID, imp.StartDate, imp.EndDate, reqs); err != nil {
+			return nil, err
+		}
+		imp.Status = model.RequirementImportApproved
+	} else {
+		imp.Status = model.RequirementImportRejected
+	}
+	now := s.now()
+	imp.ReviewedBy, imp.ReviewNote, imp.ReviewedAt = reviewer, note, &now
+	if err := s.store.PutRequirementImport(imp); err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
+
+// shifts 返回生成需求的班次
+func (s *Service) shifts(orgID uuid.UUID, ids []uuid.UUID) ([]*model.Shift, error) {
+	all := s.store.ListShifts(orgID)
+	if len(ids) == 0 {
+		result := make([]*model.Shift, 0, len(all))
+		for _, shift := range all {
+			if shift.IsActive {
+				result = append(result, shift)
+			}
+		}
+		if len(result) == 0 {
+			return nil, fmt.Errorf("%w: 组织没有启用的班次", ErrInvalidOptions)
+		}
+		return result, nil
+	}
+
+	byID := make(map[uuid.UUID]*model.Shift, len(all))
+	for _, shift := range all {
+		byID[shift.ID] = shift
+	}
+	result := make([]*model.Shift, 0, len(ids))
+	for _, id := range ids {
+		shift, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: 班次 %s 不存在", ErrInvalidOptions, id)
+		}
+		result = append(result, shift)
+	}
+	return result, nil
+}
+
+// parseVolumes 解析 CSV，返回按日期、小时升序的业务量（Staff 未计算）
+func parseVolumes(r io.Reader) ([]model.HourlyDemand, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: 缺少表头", ErrInvalidCSV)
+	}
+	dateCol, hourCol := column(header, dateColumns), column(header, hourColumns)
+	datetimeCol, volumeCol := column(header, datetimeColumns), column(header, volumeColumns)
+	if volumeCol < 0 || (datetimeCol < 0 && (dateCol < 0 || hourCol < 0)) {
+		return nil, fmt.Errorf("%w: 表头须包含日期和小时（或时间）列以及业务量列", ErrInvalidCSV)
+	}
+
+	totals := make(map[string]*model.HourlyDemand)
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: 第 %d 行: %v", ErrInvalidCSV, line, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		var at time.Time
+		if datetimeCol >= 0 {
+			at, err = time.Parse("2006-01-02 15:04", field(record, datetimeCol))
+		} else {
+			hour := field(record, hourCol)
+			if !strings.Contains(hour, ":") {
+				hour += ":00"
+			}
+			if len(hour) == 4 {
+				hour = "0" + hour
+			}
+			at, err = time.Parse("2006-01-02 15:04", field(record, dateCol)+" "+hour)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: 第 %d 行: 日期或小时格式无效", ErrInvalidCSV, line)
+		}
+		volume, err := strconv.ParseFloat(field(record, volumeCol), 64)
+		if err != nil || volume < 0 {
+			return nil, fmt.Errorf("%w: 第 %d 行: 业务量应为非负数", ErrInvalidCSV, line)
+		}
+
+		date := at.Format("2006-01-02")
+		key := fmt.Sprintf("%s/%02d", date, at.Hour())
+		if d, ok := totals[key]; ok {
+			d.Volume += volume
+		} else {
+			totals[key] = &model.HourlyDemand{Date: date, Hour: at.Hour(), Volume: volume}
+		}
+	}
+	if len(totals) == 0 {
+		return nil, fmt.Errorf("%w: 没有业务量数据", ErrInvalidCSV)
+	}
+
+	keys := make([]string, 0, len(totals))
+	for k := range totals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := make([]model.HourlyDemand, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, *totals[k])
+	}
+	return result, nil
+}
+
+// staffingCurve 按人效计算每小时所需人数：有业务量的小时为 ⌈业务量/人效⌉，且不少于 minStaff
+func staffingCurve(volumes []model.HourlyDemand, productivity float64, minStaff int) []model.HourlyDemand {
+	curve := make([]model.HourlyDemand, len(volumes))
+	for i, v := range volumes {
+		if v.Volume > 0 {
+			v.Staff = max(int(math.Ceil(v.Volume/productivity)), minStaff)
+		}
+		curve[i] = v
+	}
+	return curve
+}
+
+// shiftRequirements 按班次覆盖的小时生成各班次每天的需求，返回需求和没有班次覆盖的小时
+// 班次与某小时重叠不少于 30 分钟即覆盖该小时（跨夜班次覆盖次日凌晨）；多个班次覆盖同一小时时所需人数平均分摊，
+// 班次需求人数取其覆盖各小时分摊人数的最大值
+func shiftRequirements(orgID uuid.UUID, curve []model.HourlyDemand, shifts []*model.Shift) ([]model.ShiftRequirement, []model.HourlyDemand) {
+	if len(curve) == 0 {
+		return nil, nil
+	}
+	staff := make(map[string]int, len(curve))
+	for _, d := range curve {
+		staff[fmt.Sprintf("%s/%02d", d.Date, d.Hour)] = d.Staff
+	}
+
+	first, _ := time.Parse("2006-01-02", curve[0].Date)
+	last, _ := time.Parse("2006-01-02", curve[len(curve)-1].Date)
+
+	// 每个班次每天覆盖的小时键（日期/小时），以及每个小时键被几个班次覆盖
+	type slot struct {
+		shift *model.Shift
+		date  string
+		hours []string
+	}
+	var slots []slot
+	coverCount := make(map[string]int)
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		for _, shift := range shifts {
+			hours := coveredHours(shift, day)
+			if len(hours) == 0 {
+				continue
+			}
+			for _, h := range hours {
+				coverCount[h]++
+			}
+			slots = append(slots, slot{shift: shift, date: day.Format("2006-01-02"), hours: hours})
+		}
+	}
+
+	requirements := make([]model.ShiftRequirement, 0)
+	for _, sl := range slots {
+		need := 0
+		for _, h := range sl.hours {
+			if n := coverCount[h]; n > 0 {
+				need = max(need, int(math.Ceil(float64(staff[h])/float64(n))))
+			}
+		}
+		if need == 0 {
+			continue
+		}
+		requirements = append(requirements, model.ShiftRequirement{
+			BaseModel:    model.NewBaseModel(),
+			OrgID:        orgID,
+			ShiftID:      sl.shift.ID,
+			Date:         sl.date,
+			MinEmployees: need,
+			MaxEmployees: need,
+			Priority:     5,
+		})
+	}
+
+	uncovered := make([]model.HourlyDemand, 0)
+	for _, d := range curve {
+		if d.Staff > 0 && coverCount[fmt.Sprintf("%s/%02d", d.Date, d.Hour)] == 0 {
+			uncovered = append(uncovered, d)
+		}
+	}
+	return requirements, uncovered
+}
+
+// coveredHours 返回班次在 day 当天开始时覆盖的小时键（日期/小时）
+func coveredHours(shift *model.Shift, day time.Time) []string {
+	start, err1 := time.Parse("15:04", shift.StartTime)
+	end, err2 := time.Parse("15:04", shift.EndTime)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	from := day.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute)
+	to := day.Add(time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute)
+	if !to.After(from) {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	var hours []string
+	for h := from.Truncate(time.Hour); h.Before(to); h = h.Add(time.Hour) {
+		overlap := minTime(to, h.Add(time.Hour)).Sub(maxTime(from, h))
+		if overlap >= minShiftOverlap {
+			hours = append(hours, fmt.Sprintf("%s/%02d", h.Format("2006-01-02"), h.Hour()))
+		}
+	}
+	return hours
+}
+
+// column 返回表头中第一个匹配别名的列序号，没有时返回 -1
+func column(header []string, names []string) int {
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		for _, name := range names {
+			if h == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// field 返回去除空白的字段，列不存在时返回空
+func field(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
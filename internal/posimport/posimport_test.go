@@ -0,0 +1,112 @@
+package posimport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// newTestService 早班 10:00-14:00、晚班 13:30-21:00（13 点两班重叠）
+func newTestService(t *testing.T) (*Service, *memstore.Store, uuid.UUID, *model.Shift, *model.Shift) {
+	t.Helper()
+	store := memstore.New("")
+	orgID := uuid.New()
+	morning := &model.Shift{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "早班", StartTime: "10:00", EndTime: "14:00", IsActive: true}
+	evening := &model.Shift{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "晚班", StartTime: "13:30", EndTime: "21:00", IsActive: true}
+	store.PutShift(morning)
+	store.PutShift(evening)
+	return NewService(store), store, orgID, morning, evening
+}
+
+const testCSV = `日期,时段,交易笔数
+2026-03-02,11,30
+2026-03-02,12:00,50
+2026-03-02,12,10
+2026-03-02,13,70
+2026-03-02,18,45
+2026-03-02,22,5
+`
+
+func TestService_Import(t *testing.T) {
+	s, _, orgID, morning, evening := newTestService(t)
+
+	imp, err := s.Import(orgID, strings.NewReader(testCSV), Options{Productivity: 12, MinStaff: 1}, "manager")
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if imp.Status != model.RequirementImportPending || imp.StartDate != "2026-03-02" || imp.EndDate != "2026-03-02" {
+		t.Fatalf("import = %+v", imp)
+	}
+	// 12 点两行累加为 60 笔 => 5 人；13 点 70 笔 => 6 人，由两班分摊各 3 人；18 点 45 笔 => 4 人
+	staff := make(map[int]int)
+	for _, d := range imp.Curve {
+		staff[d.Hour] = d.Staff
+	}
+	if staff[11] != 3 || staff[12] != 5 || staff[13] != 6 || staff[18] != 4 {
+		t.Errorf("curve = %+v", imp.Curve)
+	}
+	need := make(map[uuid.UUID]int)
+	for _, r := range imp.Requirements {
+		need[r.ShiftID] = r.MinEmployees
+	}
+	if need[morning.ID] != 5 || need[evening.ID] != 4 {
+		t.Errorf("requirements = %+v", imp.Requirements)
+	}
+	if len(imp.Uncovered) != 1 || imp.Uncovered[0].Hour != 22 {
+		t.Errorf("uncovered = %+v", imp.Uncovered)
+	}
+}
+
+func TestService_ReviewReplacesImportedShifts(t *testing.T) {
+	s, store, orgID, morning, _ := newTestService(t)
+	other := uuid.New()
+	store.ReplaceRequirements(orgID, "2026-03-02", "2026-03-02", []*model.ShiftRequirement{
+		{BaseModel: model.NewBaseModel(), OrgID: orgID, ShiftID: morning.ID, Date: "2026-03-02", MinEmployees: 1},
+		{BaseModel: model.NewBaseModel(), OrgID: orgID, ShiftID: other, Date: "2026-03-02", MinEmployees: 2},
+	})
+
+	imp, err := s.Import(orgID, strings.NewReader(testCSV), Options{Productivity: 12, ShiftIDs: []uuid.UUID{morning.ID}}, "")
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(store.ListRequirements(orgID, "", "")) != 2 {
+		t.Fatal("审核前不应写入需求")
+	}
+	if _, err := s.Review(orgID, imp.ID, false, "boss", ""); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("驳回须填写原因, got %v", err)
+	}
+	if _, err := s.Review(orgID, imp.ID, true, "boss", ""); err != nil {
+		t.Fatalf("Review() error = %v", err)
+	}
+	reqs := store.ListRequirements(orgID, "", "")
+	byShift := make(map[uuid.UUID]int)
+	for _, r := range reqs {
+		byShift[r.ShiftID] = r.MinEmployees
+	}
+	// 只有早班参与分摊，13 点 6 人全部计入早班
+	if len(reqs) != 2 || byShift[morning.ID] != 6 || byShift[other] != 2 {
+		t.Errorf("requirements = %+v", byShift)
+	}
+	if _, err := s.Review(orgID, imp.ID, true, "boss", ""); !errors.Is(err, ErrReviewed) {
+		t.Errorf("重复审核应报错, got %v", err)
+	}
+}
+
+func TestParseVolumes_Errors(t *testing.T) {
+	tests := []string{
+		"",
+		"date,amount\n2026-03-02,10\n",
+		"datetime,orders\n2026-03-02 25:00,10\n",
+		"date,hour,orders\n2026-03-02,10,-1\n",
+		"date,hour,orders\n",
+	}
+	for _, csv := range tests {
+		if _, err := parseVolumes(strings.NewReader(csv)); !errors.Is(err, ErrInvalidCSV) {
+			t.Errorf("parseVolumes(%q) error = %v, want ErrInvalidCSV", csv, err)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package publication 提供排班发布（公布）管理
+// 按组织配置的发布规则计算排班对员工可见的时间，并由后台任务在到点时自动发布。
+// 排班状态只能按 草稿 → 已发布 → 已归档（草稿也可直接归档）变化，每次变化记录审计并通知下游系统
+package publication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// SystemActor 自动操作（到点自动发布）的操作人
+const SystemActor = "system"
+
+var (
+	// ErrAlreadyPublished 排班已发布
+	ErrAlreadyPublished = errors.New("排班已发布")
+	// ErrPeriodClosed 草稿包含已结账期间的分配，发布会改变已结算的工资
+	ErrPeriodClosed = errors.New("排班包含已结账期间的分配")
+	// ErrHardViolations 排班存在硬约束违反，不能发布
+	ErrHardViolations = errors.New("排班存在硬约束违反")
+	// ErrInvalidTransition 排班当前状态不允许该操作
+	ErrInvalidTransition = errors.New("排班状态不允许该操作")
+)
+
+// ViolationError 因硬约束违反拒绝发布
+type ViolationError struct {
+	Violations []constraint.ViolationDetail
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("%v（%d 项）", ErrHardViolations, len(e.Violations))
+}
+
+func (e *ViolationError) Unwrap() error {
+	return ErrHardViolations
+}
+
+// Checker 复核排班当前分配的硬约束，返回硬约束违反
+type Checker func(schedule *model.Schedule) []constraint.ViolationDetail
+
+// Publisher 排班发布器
+type Publisher struct {
+	store    *memstore.Store
+	notifier notify.Notifier
+	check    Checker
+	now      func() time.Time
+}
+
+// NewPublisher 创建排班发布器，notifier 为空时通知写入日志
+func NewPublisher(store *memstore.Store, notifier notify.Notifier) *Publisher {
+	if notifier == nil {
+		notifier = notify.LogNotifier{}
+	}
+	return &Publisher{
+		store:    store,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+// SetChecker 设置发布前的硬约束复核，未设置时不复核
+func (p *Publisher) SetChecker(check Checker) {
+	p.check = check
+}
+
+// Now 返回发布器使用的当前时间
+func (p *Publisher) Now() time.Time {
+	return p.now()
+}
+
+// Publish 发布排班，只有草稿可以发布
+// immediate 为 true 时忽略发布规则立即发布；否则按组织发布规则设置计划公布时间，
+// 公布时间已过或组织未配置规则时立即发布。存在硬约束违反时拒绝发布并返回 *ViolationError
+func (p *Publisher) Publish(scheduleID uuid.UUID, immediate bool, actor string) (*model.Schedule, error) {
+	schedule, err := p.store.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	switch schedule.Status {
+	case "draft":
+	case "published":
+		return schedule, ErrAlreadyPublished
+	default:
+		return nil, fmt.Errorf("%w: 排班状态为 %s", ErrInvalidTransition, schedule.Status)
+	}
+	if p.touchesClosedPeriod(schedule) {
+		return nil, ErrPeriodClosed
+	}
+
+	now := p.now()
+	if violations := p.violations(schedule); len(violations) > 0 {
+		p.audit(schedule, model.ScheduleActionPublishRejected, schedule.Status, actor, fmt.Sprintf("%d 项硬约束违反", len(violations)), now)
+		return nil, &ViolationError{Violations: violations}
+	}
+
+	publishAt := now
+	if !immediate {
+		if org, err := p.store.GetOrganization(schedule.OrgID); err == nil && org.PublicationRule != nil {
+			release, err := org.PublicationRule.ReleaseTime(schedule.StartDate)
+			if err != nil {
+				return nil, err
+			}
+			if release.After(now) {
+				publishAt = release
+			}
+		}
+	}
+
+	schedule.PublishAt = &publishAt
+	action := model.ScheduleActionSchedulePublish
+	if !publishAt.After(now) {
+		markPublished(schedule, now, actor)
+		action = model.ScheduleActionPublish
+	}
+	schedule.UpdatedAt = now
+	if err := p.store.PutSchedule(schedule); err != nil {
+		return nil, err
+	}
+	p.audit(schedule, action, "draft", actor, "", now)
+	if schedule.Status == "published" {
+		p.store.AddScheduleVersion(schedule, model.ScheduleVersionPublish, actor)
+		p.notifyPublished(schedule)
+	}
+	return schedule, nil
+}
+
+// Archive 归档排班，草稿和已发布的排班都可归档；归档的草稿不再按计划公布
+func (p *Publisher) Archive(scheduleID uuid.UUID, actor, reason string) (*model.Schedule, error) {
+	schedule, err := p.store.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	from := schedule.Status
+	if from != "draft" && from != "published" {
+		return nil, fmt.Errorf("%w: 排班状态为 %s", ErrInvalidTransition, from)
+	}
+
+	now := p.now()
+	if from == "draft" {
+		schedule.PublishAt = nil
+	}
+	schedule.Status = "archived"
+	schedule.ArchivedAt, schedule.ArchivedBy = &now, actor
+	schedule.UpdatedAt = now
+	if err := p.store.PutSchedule(schedule); err != nil {
+		return nil, err
+	}
+	p.audit(schedule, model.ScheduleActionArchive, from, actor, reason, now)
+	p.notify(notify.New(notify.TypeScheduleArchived, schedule.OrgID, "排班已归档",
+		fmt.Sprintf("%s 至 %s 的排班已归档", schedule.StartDate, schedule.EndDate), scheduleEvent(schedule)))
+	return schedule, nil
+}
+
+// History 返回排班的生命周期审计记录（按时间升序）
+func (p *Publisher) History(scheduleID uuid.UUID) []*model.ScheduleAuditEntry {
+	return p.store.ListScheduleAudit(scheduleID)
+}
+
+// PublishDue 发布所有已到计划公布时间的排班，返回发布数量
+func (p *Publisher) PublishDue() int {
+	now := p.now()
+	count := 0
+	for _, schedule := range p.store.ListSchedules(uuid.Nil) {
+		if schedule.Status != "draft" || schedule.PublishAt == nil || now.Before(*schedule.PublishAt) {
+			continue
+		}
+		if p.touchesClosedPeriod(schedule) {
+			logger.Warn().Str("schedule_id", schedule.ID.String()).Msg("排班包含已结账期间的分配，跳过自动发布")
+			continue
+		}
+		if violations := p.violations(schedule); len(violations) > 0 {
+			logger.Warn().Str("schedule_id", schedule.ID.String()).Int("violations", len(violations)).Msg("排班存在硬约束违反，跳过自动发布")
+			// 每次检查都会跳过，只记录一次审计
+			if history := p.store.ListScheduleAudit(schedule.ID); len(history) == 0 || history[len(history)-1].Action != model.ScheduleActionAutoPublishBlock {
+				p.audit(schedule, model.ScheduleActionAutoPublishBlock, schedule.Status, SystemActor, fmt.Sprintf("%d 项硬约束违反", len(violations)), now)
+			}
+			continue
+		}
+		markPublished(schedule, now, SystemActor)
+		schedule.UpdatedAt = now
+		if err := p.store.PutSchedule(schedule); err != nil {
+			logger.Error().Err(err).Str("schedule_id", schedule.ID.String()).Msg("自动发布排班失败")
+			continue
+		}
+		p.audit(schedule, model.ScheduleActionAutoPublish, "draft", SystemActor, "", now)
+		p.store.AddScheduleVersion(schedule, model.ScheduleVersionPublish, SystemActor)
+		p.notifyPublished(schedule)
+		logger.Info().
+			Str("schedule_id", schedule.ID.String()).
+			Str("org_id", schedule.OrgID.String()).
+			Time("publish_at", *schedule.PublishAt).
+			Msg("排班已自动发布")
+		count++
+	}
+	return count
+}
+
+// Run 定期检查并发布到期排班，直到 ctx 取消
+func (p *Publisher) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.PublishDue()
+		}
+	}
+}
+
+// touchesClosedPeriod 检查排班是否包含已结账期间的分配
+func (p *Publisher) touchesClosedPeriod(schedule *model.Schedule) bool {
+	lockedBefore := p.store.PayrollLockedBefore(schedule.OrgID)
+	for _, a := range schedule.Assignments {
+		if model.IsPayrollLocked(a.Date, lockedBefore) {
+			return true
+		}
+	}
+	return false
+}
+
+// violations 复核排班的硬约束
+func (p *Publisher) violations(schedule *model.Schedule) []constraint.ViolationDetail {
+	if p.check == nil {
+		return nil
+	}
+	return p.check(schedule)
+}
+
+// audit 记录排班状态变化
+func (p *Publisher) audit(schedule *model.Schedule, action, from, actor, reason string, now time.Time) {
+	entry := &model.ScheduleAuditEntry{
+		ScheduleID: schedule.ID,
+		OrgID:      schedule.OrgID,
+		Action:     action,
+		FromStatus: from,
+		ToStatus:   schedule.Status,
+		Actor:      actor,
+		Reason:     reason,
+		PublishAt:  schedule.PublishAt,
+		Version:    schedule.Version,
+		CreatedAt:  now,
+	}
+	if err := p.store.AddScheduleAudit(entry); err != nil {
+		logger.Error().Err(err).Str("schedule_id", schedule.ID.String()).Msg("记录排班审计失败")
+	}
+}
+
+// notifyPublished 通知排班已正式生效
+func (p *Publisher) notifyPublished(schedule *model.Schedule) {
+	p.notify(notify.New(notify.TypeSchedulePublished, schedule.OrgID, "排班已发布",
+		fmt.Sprintf("%s 至 %s 的排班已发布", schedule.StartDate, schedule.EndDate), scheduleEvent(schedule)))
+}
+
+// notify 发送通知，失败只记录日志
+func (p *Publisher) notify(n *notify.Notification) {
+	if err := p.notifier.Notify(context.Background(), n); err != nil {
+		logger.Error().Err(err).Str("type", n.Type).Msg("发送排班状态通知失败")
+	}
+}
+
+// scheduleEvent 排班状态通知的数据
+func scheduleEvent(schedule *model.Schedule) map[string]interface{} {
+	return map[string]interface{}{
+		"schedule_id":  schedule.ID,
+		"status":       schedule.Status,
+		"version":      schedule.Version,
+		"start_date":   schedule.StartDate,
+		"end_date":     schedule.EndDate,
+		"published_at": schedule.PublishedAt,
+		"archived_at":  schedule.ArchivedAt,
+	}
+}
+
+// markPublished 标记排班为已发布
+func markPublished(schedule *model.Schedule, now time.Time, actor string) {
+	schedule.Status = "published"
+	schedule.Version++ // 发布产生新版本
+	publishedAt := now
+	schedule.PublishedAt = &publishedAt
+	schedule.PublishedBy = actor
+}
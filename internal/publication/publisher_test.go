@@ -0,0 +1,187 @@
+package publication
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+type captureNotifier struct {
+	sent []*notify.Notification
+}
+
+func (c *captureNotifier) Notify(ctx context.Context, n *notify.Notification) error {
+	c.sent = append(c.sent, n)
+	return nil
+}
+
+func newTestPublisher(t *testing.T, now time.Time, rule *model.PublicationRule) (*Publisher, *model.Schedule) {
+	t.Helper()
+	store := memstore.New("")
+	orgID := uuid.New()
+	store.PutOrganization(&model.Organization{BaseModel: model.BaseModel{ID: orgID}, PublicationRule: rule})
+
+	schedule := &model.Schedule{
+		BaseModel: model.NewBaseModel(),
+		OrgID:     orgID,
+		StartDate: "2026-01-19", // 周一
+		EndDate:   "2026-01-25",
+		Status:    "draft",
+	}
+	store.PutSchedule(schedule)
+
+	p := NewPublisher(store, nil)
+	p.now = func() time.Time { return now }
+	return p, schedule
+}
+
+func TestPublisher_EmbargoUntilRelease(t *testing.T) {
+	rule := &model.PublicationRule{Weekday: time.Thursday, Time: "18:00"}
+	now := time.Date(2026, 1, 14, 9, 0, 0, 0, time.Local) // 周三
+	p, schedule := newTestPublisher(t, now, rule)
+
+	published, err := p.Publish(schedule.ID, false, "u1")
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	release := time.Date(2026, 1, 15, 18, 0, 0, 0, time.Local)
+	if published.Status != "draft" || published.PublishAt == nil || !published.PublishAt.Equal(release) {
+		t.Fatalf("应在周四 18:00 公布, got status=%s publish_at=%v", published.Status, published.PublishAt)
+	}
+	if published.IsVisibleAt(now) {
+		t.Error("公布前员工不可见")
+	}
+
+	if n := p.PublishDue(); n != 0 {
+		t.Errorf("未到公布时间不应发布, got %d", n)
+	}
+
+	p.now = func() time.Time { return release.Add(time.Minute) }
+	if n := p.PublishDue(); n != 1 {
+		t.Fatalf("到点应自动发布, got %d", n)
+	}
+	got, _ := p.store.GetSchedule(schedule.ID)
+	if got.Status != "published" || !got.IsVisibleAt(p.now()) {
+		t.Error("自动发布后应可见")
+	}
+}
+
+func TestPublisher_Immediate(t *testing.T) {
+	rule := &model.PublicationRule{Weekday: time.Thursday, Time: "18:00"}
+	now := time.Date(2026, 1, 14, 9, 0, 0, 0, time.Local)
+	p, schedule := newTestPublisher(t, now, rule)
+
+	published, err := p.Publish(schedule.ID, true, "u1")
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if published.Status != "published" || !published.IsVisibleAt(now) {
+		t.Error("立即发布后应可见")
+	}
+	if _, err := p.Publish(schedule.ID, true, "u1"); err != ErrAlreadyPublished {
+		t.Errorf("重复发布 error = %v, expected ErrAlreadyPublished", err)
+	}
+}
+
+func TestPublisher_NoRule(t *testing.T) {
+	now := time.Date(2026, 1, 14, 9, 0, 0, 0, time.Local)
+	p, schedule := newTestPublisher(t, now, nil)
+
+	published, err := p.Publish(schedule.ID, false, "u1")
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if published.Status != "published" {
+		t.Error("未配置规则时应立即发布")
+	}
+}
+
+func TestPublisher_RejectsHardViolations(t *testing.T) {
+	now := time.Date(2026, 1, 14, 9, 0, 0, 0, time.Local)
+	p, schedule := newTestPublisher(t, now, nil)
+	violations := []constraint.ViolationDetail{{ConstraintType: constraint.TypeMaxHoursPerWeek, Message: "周工时超过 44 小时"}}
+	p.SetChecker(func(*model.Schedule) []constraint.ViolationDetail { return violations })
+
+	_, err := p.Publish(schedule.ID, true, "u1")
+	var violationErr *ViolationError
+	if !errors.As(err, &violationErr) || !errors.Is(err, ErrHardViolations) || len(violationErr.Violations) != 1 {
+		t.Fatalf("Publish() error = %v, want ViolationError", err)
+	}
+	got, _ := p.store.GetSchedule(schedule.ID)
+	if got.Status != "draft" {
+		t.Errorf("拒绝发布后应仍为草稿, got %s", got.Status)
+	}
+
+	// 修正后可以发布
+	violations = nil
+	published, err := p.Publish(schedule.ID, true, "u1")
+	if err != nil || published.Status != "published" || published.PublishedBy != "u1" {
+		t.Fatalf("Publish() = %+v, %v", published, err)
+	}
+	history := p.History(schedule.ID)
+	if len(history) != 2 || history[0].Action != model.ScheduleActionPublishRejected || history[1].Action != model.ScheduleActionPublish {
+		t.Errorf("history = %+v", history)
+	}
+}
+
+func TestPublisher_AutoPublishBlocked(t *testing.T) {
+	rule := &model.PublicationRule{Weekday: time.Thursday, Time: "18:00"}
+	now := time.Date(2026, 1, 14, 9, 0, 0, 0, time.Local)
+	p, schedule := newTestPublisher(t, now, rule)
+	if _, err := p.Publish(schedule.ID, false, "u1"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	// 计划公布后修改引入了硬约束违反
+	p.SetChecker(func(*model.Schedule) []constraint.ViolationDetail {
+		return []constraint.ViolationDetail{{Message: "连续工作超过 6 天"}}
+	})
+	p.now = func() time.Time { return time.Date(2026, 1, 15, 18, 1, 0, 0, time.Local) }
+	if n := p.PublishDue(); n != 0 {
+		t.Fatalf("存在硬约束违反不应自动发布, got %d", n)
+	}
+	p.PublishDue()
+	history := p.History(schedule.ID)
+	if len(history) != 2 || history[1].Action != model.ScheduleActionAutoPublishBlock {
+		t.Errorf("跳过自动发布只记录一次, history = %+v", history)
+	}
+}
+
+func TestPublisher_Archive(t *testing.T) {
+	now := time.Date(2026, 1, 14, 9, 0, 0, 0, time.Local)
+	p, schedule := newTestPublisher(t, now, nil)
+	notifier := &captureNotifier{}
+	p.notifier = notifier
+
+	if _, err := p.Publish(schedule.ID, false, "u1"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	archived, err := p.Archive(schedule.ID, "u2", "已被新版本替代")
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if archived.Status != "archived" || archived.ArchivedBy != "u2" || archived.ArchivedAt == nil || archived.IsVisibleAt(now) {
+		t.Errorf("归档结果错误: %+v", archived)
+	}
+	if _, err := p.Archive(schedule.ID, "u2", ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("重复归档 error = %v, want ErrInvalidTransition", err)
+	}
+	if _, err := p.Publish(schedule.ID, true, "u1"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("发布已归档排班 error = %v, want ErrInvalidTransition", err)
+	}
+
+	history := p.History(schedule.ID)
+	if len(history) != 2 || history[1].FromStatus != "published" || history[1].ToStatus != "archived" || history[1].Reason != "已被新版本替代" {
+		t.Errorf("history = %+v", history)
+	}
+	if len(notifier.sent) != 2 || notifier.sent[0].Type != notify.TypeSchedulePublished || notifier.sent[1].Type != notify.TypeScheduleArchived {
+		t.Errorf("notifications = %+v", notifier.sent)
+	}
+}
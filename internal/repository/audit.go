@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// AuditRepositoryInterface 审计日志仓储接口
+type AuditRepositoryInterface interface {
+	Create(ctx context.Context, l *model.AuditLog) error
+	List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditLog, int, error)
+}
+
+// AuditRepository 审计日志仓储（audit_logs 表，只追加）
+type AuditRepository struct {
+	db DB
+}
+
+// NewAuditRepository 创建审计日志仓储
+func NewAuditRepository(db DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Create 写入审计日志
+func (r *AuditRepository) Create(ctx context.Context, l *model.AuditLog) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = time.Now()
+	}
+	detailJSON, _ := json.Marshal(l.Detail)
+
+	query := `
+		INSERT INTO audit_logs (
+			id, org_id, actor, role, action, resource_type, resource_id, request_id, method, path, detail, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		l.ID, l.OrgID, l.Actor, l.Role, l.Action, l.ResourceType, l.ResourceID, l.RequestID, l.Method, l.Path, detailJSON, l.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("写入审计日志失败: %w", err)
+	}
+	return nil
+}
+
+// List 按条件查询审计日志（按时间倒序），返回当前页和总数
+func (r *AuditRepository) List(ctx context.Context, filter model.AuditFilter) ([]*model.AuditLog, int, error) {
+	conditions := []string{"1 = 1"}
+	var args []interface{}
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.OrgID != uuid.Nil {
+		add("org_id = $%d", filter.OrgID)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $%d", filter.To)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.Actor != "" {
+		add("actor = $%d", filter.Actor)
+	}
+	if filter.RequestID != "" {
+		add("request_id = $%d", filter.RequestID)
+	}
+	whereClause := strings.Join(conditions, " AND ")
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM audit_logs WHERE %s", whereClause)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("查询总数失败: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, org_id, actor, role, action, resource_type, resource_id, request_id, method, path, detail, created_at
+		FROM audit_logs
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, whereClause, len(args)+1, len(args)+2)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+	defer rows.Close()
+
+	logs := make([]*model.AuditLog, 0)
+	for rows.Next() {
+		l := &model.AuditLog{}
+		var detailJSON []byte
+		if err := rows.Scan(&l.ID, &l.OrgID, &l.Actor, &l.Role, &l.Action, &l.ResourceType, &l.ResourceID,
+			&l.RequestID, &l.Method, &l.Path, &detailJSON, &l.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("扫描行失败: %w", err)
+		}
+		json.Unmarshal(detailJSON, &l.Detail)
+		logs = append(logs, l)
+	}
+	return logs, total, rows.Err()
+}
@@ -13,6 +13,22 @@ import (
 	"github.com/paiban/paiban/pkg/model"
 )
 
+// employeeColumns 员工查询列，顺序与 scanEmployee 一致
+const employeeColumns = `id, org_id, name, code, COALESCE(phone, ''), COALESCE(email, ''), status, to_char(hire_date, 'YYYY-MM-DD'),
+			COALESCE(position, ''), skills, certifications, hourly_rate,
+			preferences, service_area, home_location, COALESCE(store_id, ''), availability_windows, created_at, updated_at`
+
+// EmployeeRepositoryInterface 员工仓储接口
+type EmployeeRepositoryInterface interface {
+	Create(ctx context.Context, emp *model.Employee) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Employee, error)
+	GetByCode(ctx context.Context, orgID uuid.UUID, code string) (*model.Employee, error)
+	Update(ctx context.Context, emp *model.Employee) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter ListFilter) ([]*model.Employee, int, error)
+	ListActive(ctx context.Context, orgID uuid.UUID) ([]*model.Employee, error)
+}
+
 // EmployeeRepository 员工仓储
 type EmployeeRepository struct {
 	db DB
@@ -37,19 +53,20 @@ func (r *EmployeeRepository) Create(ctx context.Context, emp *model.Employee) er
 	prefsJSON, _ := json.Marshal(emp.Preferences)
 	areaJSON, _ := json.Marshal(emp.ServiceArea)
 	locJSON, _ := json.Marshal(emp.HomeLocation)
+	windowsJSON, _ := json.Marshal(emp.AvailabilityWindows)
 
 	query := `
 		INSERT INTO employees (
 			id, org_id, name, code, phone, email, status, hire_date,
 			position, skills, certifications, hourly_rate,
-			preferences, service_area, home_location, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			preferences, service_area, home_location, store_id, availability_windows, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
 		emp.ID, emp.OrgID, emp.Name, emp.Code, emp.Phone, emp.Email, emp.Status, emp.HireDate,
 		emp.Position, skillsJSON, certsJSON, emp.HourlyRate,
-		prefsJSON, areaJSON, locJSON, emp.CreatedAt, emp.UpdatedAt,
+		prefsJSON, areaJSON, locJSON, emp.StoreID, windowsJSON, emp.CreatedAt, emp.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("创建员工失败: %w", err)
@@ -61,9 +78,7 @@ func (r *EmployeeRepository) Create(ctx context.Context, emp *model.Employee) er
 // GetByID 根据ID获取员工
 func (r *EmployeeRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Employee, error) {
 	query := `
-		SELECT id, org_id, name, code, phone, email, status, hire_date,
-			position, skills, certifications, hourly_rate,
-			preferences, service_area, home_location, created_at, updated_at
+		SELECT ` + employeeColumns + `
 		FROM employees
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -74,9 +89,7 @@ func (r *EmployeeRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.
 // GetByCode 根据组织和工号获取员工
 func (r *EmployeeRepository) GetByCode(ctx context.Context, orgID uuid.UUID, code string) (*model.Employee, error) {
 	query := `
-		SELECT id, org_id, name, code, phone, email, status, hire_date,
-			position, skills, certifications, hourly_rate,
-			preferences, service_area, home_location, created_at, updated_at
+		SELECT ` + employeeColumns + `
 		FROM employees
 		WHERE org_id = $1 AND code = $2 AND deleted_at IS NULL
 	`
@@ -93,12 +106,14 @@ func (r *EmployeeRepository) Update(ctx context.Context, emp *model.Employee) er
 	prefsJSON, _ := json.Marshal(emp.Preferences)
 	areaJSON, _ := json.Marshal(emp.ServiceArea)
 	locJSON, _ := json.Marshal(emp.HomeLocation)
+	windowsJSON, _ := json.Marshal(emp.AvailabilityWindows)
 
 	query := `
 		UPDATE employees SET
 			name = $2, code = $3, phone = $4, email = $5, status = $6,
 			position = $7, skills = $8, certifications = $9, hourly_rate = $10,
-			preferences = $11, service_area = $12, home_location = $13, updated_at = $14
+			preferences = $11, service_area = $12, home_location = $13, updated_at = $14,
+			hire_date = $15, store_id = $16, availability_windows = $17
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
@@ -106,6 +121,7 @@ func (r *EmployeeRepository) Update(ctx context.Context, emp *model.Employee) er
 		emp.ID, emp.Name, emp.Code, emp.Phone, emp.Email, emp.Status,
 		emp.Position, skillsJSON, certsJSON, emp.HourlyRate,
 		prefsJSON, areaJSON, locJSON, emp.UpdatedAt,
+		emp.HireDate, emp.StoreID, windowsJSON,
 	)
 	if err != nil {
 		return fmt.Errorf("更新员工失败: %w", err)
@@ -169,6 +185,20 @@ func (r *EmployeeRepository) List(ctx context.Context, filter ListFilter) ([]*mo
 		argIndex++
 	}
 
+	// 门店过滤
+	if storeID, ok := filter.Extra["store_id"].(string); ok && storeID != "" {
+		conditions = append(conditions, fmt.Sprintf("store_id = $%d", argIndex))
+		args = append(args, storeID)
+		argIndex++
+	}
+
+	// 技能过滤（具备该技能）
+	if skill, ok := filter.Extra["skill"].(string); ok && skill != "" {
+		conditions = append(conditions, fmt.Sprintf("skills ? $%d", argIndex))
+		args = append(args, skill)
+		argIndex++
+	}
+
 	whereClause := strings.Join(conditions, " AND ")
 
 	// 查询总数
@@ -189,9 +219,7 @@ func (r *EmployeeRepository) List(ctx context.Context, filter ListFilter) ([]*mo
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, org_id, name, code, phone, email, status, hire_date,
-			position, skills, certifications, hourly_rate,
-			preferences, service_area, home_location, created_at, updated_at
+		SELECT `+employeeColumns+`
 		FROM employees
 		WHERE %s
 		ORDER BY %s %s
@@ -232,9 +260,7 @@ func (r *EmployeeRepository) ListByIDs(ctx context.Context, ids []uuid.UUID) ([]
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, org_id, name, code, phone, email, status, hire_date,
-			position, skills, certifications, hourly_rate,
-			preferences, service_area, home_location, created_at, updated_at
+		SELECT `+employeeColumns+`
 		FROM employees
 		WHERE id IN (%s) AND deleted_at IS NULL
 	`, strings.Join(placeholders, ","))
@@ -267,12 +293,12 @@ func (r *EmployeeRepository) ListActive(ctx context.Context, orgID uuid.UUID) ([
 // scanEmployee 扫描单行员工数据
 func (r *EmployeeRepository) scanEmployee(row *sql.Row) (*model.Employee, error) {
 	emp := &model.Employee{}
-	var skillsJSON, certsJSON, prefsJSON, areaJSON, locJSON []byte
+	var skillsJSON, certsJSON, prefsJSON, areaJSON, locJSON, windowsJSON []byte
 
 	err := row.Scan(
 		&emp.ID, &emp.OrgID, &emp.Name, &emp.Code, &emp.Phone, &emp.Email, &emp.Status, &emp.HireDate,
 		&emp.Position, &skillsJSON, &certsJSON, &emp.HourlyRate,
-		&prefsJSON, &areaJSON, &locJSON, &emp.CreatedAt, &emp.UpdatedAt,
+		&prefsJSON, &areaJSON, &locJSON, &emp.StoreID, &windowsJSON, &emp.CreatedAt, &emp.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -286,6 +312,7 @@ func (r *EmployeeRepository) scanEmployee(row *sql.Row) (*model.Employee, error)
 	json.Unmarshal(prefsJSON, &emp.Preferences)
 	json.Unmarshal(areaJSON, &emp.ServiceArea)
 	json.Unmarshal(locJSON, &emp.HomeLocation)
+	json.Unmarshal(windowsJSON, &emp.AvailabilityWindows)
 
 	return emp, nil
 }
@@ -293,12 +320,12 @@ func (r *EmployeeRepository) scanEmployee(row *sql.Row) (*model.Employee, error)
 // scanEmployeeRow 扫描Rows中的员工数据
 func (r *EmployeeRepository) scanEmployeeRow(rows *sql.Rows) (*model.Employee, error) {
 	emp := &model.Employee{}
-	var skillsJSON, certsJSON, prefsJSON, areaJSON, locJSON []byte
+	var skillsJSON, certsJSON, prefsJSON, areaJSON, locJSON, windowsJSON []byte
 
 	err := rows.Scan(
 		&emp.ID, &emp.OrgID, &emp.Name, &emp.Code, &emp.Phone, &emp.Email, &emp.Status, &emp.HireDate,
 		&emp.Position, &skillsJSON, &certsJSON, &emp.HourlyRate,
-		&prefsJSON, &areaJSON, &locJSON, &emp.CreatedAt, &emp.UpdatedAt,
+		&prefsJSON, &areaJSON, &locJSON, &emp.StoreID, &windowsJSON, &emp.CreatedAt, &emp.UpdatedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("扫描员工数据失败: %w", err)
@@ -309,6 +336,7 @@ func (r *EmployeeRepository) scanEmployeeRow(rows *sql.Rows) (*model.Employee, e
 	json.Unmarshal(prefsJSON, &emp.Preferences)
 	json.Unmarshal(areaJSON, &emp.ServiceArea)
 	json.Unmarshal(locJSON, &emp.HomeLocation)
+	json.Unmarshal(windowsJSON, &emp.AvailabilityWindows)
 
 	return emp, nil
 }
@@ -0,0 +1,243 @@
+// Package repository 提供数据访问层
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// requirementColumns 排班需求查询列，顺序与 scanRequirement 一致
+const requirementColumns = `id, org_id, shift_id, to_char(date, 'YYYY-MM-DD'), COALESCE(position, ''),
+			min_employees, max_employees, COALESCE(opt_employees, 0), skills, skill_groups,
+			COALESCE(priority, 5), COALESCE(store_id, ''), created_at, updated_at`
+
+// RequirementRepositoryInterface 排班需求仓储接口
+type RequirementRepositoryInterface interface {
+	Create(ctx context.Context, req *model.ShiftRequirement) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.ShiftRequirement, error)
+	Update(ctx context.Context, req *model.ShiftRequirement) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter ListFilter) ([]*model.ShiftRequirement, int, error)
+	ListByDateRange(ctx context.Context, orgID uuid.UUID, startDate, endDate string) ([]*model.ShiftRequirement, error)
+	DeleteByDateRange(ctx context.Context, orgID uuid.UUID, startDate, endDate string) (int, error)
+}
+
+// RequirementRepository 排班需求仓储
+type RequirementRepository struct {
+	db DB
+}
+
+// NewRequirementRepository 创建排班需求仓储
+func NewRequirementRepository(db DB) *RequirementRepository {
+	return &RequirementRepository{db: db}
+}
+
+// Create 创建排班需求
+func (r *RequirementRepository) Create(ctx context.Context, req *model.ShiftRequirement) error {
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+	now := time.Now()
+	req.CreatedAt = now
+	req.UpdatedAt = now
+
+	skillsJSON, _ := json.Marshal(req.Skills)
+	groupsJSON, _ := json.Marshal(req.SkillGroups)
+
+	query := `
+		INSERT INTO shift_requirements (
+			id, org_id, shift_id, date, position, min_employees, max_employees, opt_employees,
+			skills, skill_groups, priority, store_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		req.ID, req.OrgID, req.ShiftID, req.Date, req.Position, req.MinEmployees, req.MaxEmployees, req.OptEmployees,
+		skillsJSON, groupsJSON, req.Priority, req.StoreID, req.CreatedAt, req.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("创建排班需求失败: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID 根据ID获取排班需求，不存在时返回 nil
+func (r *RequirementRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.ShiftRequirement, error) {
+	query := `
+		SELECT ` + requirementColumns + `
+		FROM shift_requirements
+		WHERE id = $1
+	`
+
+	req, err := scanRequirement(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询排班需求失败: %w", err)
+	}
+
+	return req, nil
+}
+
+// Update 更新排班需求
+func (r *RequirementRepository) Update(ctx context.Context, req *model.ShiftRequirement) error {
+	req.UpdatedAt = time.Now()
+
+	skillsJSON, _ := json.Marshal(req.Skills)
+	groupsJSON, _ := json.Marshal(req.SkillGroups)
+
+	query := `
+		UPDATE shift_requirements SET
+			shift_id = $2, date = $3, position = $4, min_employees = $5, max_employees = $6, opt_employees = $7,
+			skills = $8, skill_groups = $9, priority = $10, store_id = $11, updated_at = $12
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		req.ID, req.ShiftID, req.Date, req.Position, req.MinEmployees, req.MaxEmployees, req.OptEmployees,
+		skillsJSON, groupsJSON, req.Priority, req.StoreID, req.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("更新排班需求失败: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("排班需求不存在")
+	}
+
+	return nil
+}
+
+// Delete 删除排班需求
+func (r *RequirementRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM shift_requirements WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("删除排班需求失败: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("排班需求不存在")
+	}
+
+	return nil
+}
+
+// DeleteByDateRange 删除组织在日期范围内的全部排班需求，返回删除的条数
+func (r *RequirementRepository) DeleteByDateRange(ctx context.Context, orgID uuid.UUID, startDate, endDate string) (int, error) {
+	query := `DELETE FROM shift_requirements WHERE org_id = $1 AND date >= $2 AND date <= $3`
+
+	result, err := r.db.ExecContext(ctx, query, orgID, startDate, endDate)
+	if err != nil {
+		return 0, fmt.Errorf("删除排班需求失败: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return int(rows), nil
+}
+
+// List 查询排班需求列表（按日期、班次、岗位排序）
+// Extra 支持 shift_id、position、store_id 过滤
+func (r *RequirementRepository) List(ctx context.Context, filter ListFilter) ([]*model.ShiftRequirement, int, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	conditions = append(conditions, "1 = 1")
+
+	if filter.OrgID != nil {
+		conditions = append(conditions, fmt.Sprintf("org_id = $%d", argIndex))
+		args = append(args, *filter.OrgID)
+		argIndex++
+	}
+
+	if filter.StartDate != "" {
+		conditions = append(conditions, fmt.Sprintf("date >= $%d", argIndex))
+		args = append(args, filter.StartDate)
+		argIndex++
+	}
+
+	if filter.EndDate != "" {
+		conditions = append(conditions, fmt.Sprintf("date <= $%d", argIndex))
+		args = append(args, filter.EndDate)
+		argIndex++
+	}
+
+	for _, column := range []string{"shift_id", "position", "store_id"} {
+		if v, ok := filter.Extra[column].(string); ok && v != "" {
+			conditions = append(conditions, fmt.Sprintf("%s = $%d", column, argIndex))
+			args = append(args, v)
+			argIndex++
+		}
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	// 查询总数
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM shift_requirements WHERE %s", whereClause)
+	var total int
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("查询总数失败: %w", err)
+	}
+
+	// 查询列表
+	query := fmt.Sprintf(`
+		SELECT `+requirementColumns+`
+		FROM shift_requirements
+		WHERE %s
+		ORDER BY date, shift_id, position, store_id
+		LIMIT $%d OFFSET $%d
+	`, whereClause, argIndex, argIndex+1)
+
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var requirements []*model.ShiftRequirement
+	for rows.Next() {
+		req, err := scanRequirement(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("扫描行失败: %w", err)
+		}
+		requirements = append(requirements, req)
+	}
+
+	return requirements, total, nil
+}
+
+// ListByDateRange 获取组织在日期范围内的全部排班需求
+func (r *RequirementRepository) ListByDateRange(ctx context.Context, orgID uuid.UUID, startDate, endDate string) ([]*model.ShiftRequirement, error) {
+	filter := DefaultListFilter().WithOrgID(orgID).WithDateRange(startDate, endDate).WithLimit(100000)
+	requirements, _, err := r.List(ctx, filter)
+	return requirements, err
+}
+
+// scanRequirement 扫描排班需求行
+func scanRequirement(row interface{ Scan(...interface{}) error }) (*model.ShiftRequirement, error) {
+	req := &model.ShiftRequirement{}
+	var skillsJSON, groupsJSON []byte
+	if err := row.Scan(
+		&req.ID, &req.OrgID, &req.ShiftID, &req.Date, &req.Position,
+		&req.MinEmployees, &req.MaxEmployees, &req.OptEmployees, &skillsJSON, &groupsJSON,
+		&req.Priority, &req.StoreID, &req.CreatedAt, &req.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	json.Unmarshal(skillsJSON, &req.Skills)
+	json.Unmarshal(groupsJSON, &req.SkillGroups)
+	return req, nil
+}
@@ -48,6 +48,10 @@ type ScheduleAssignment struct {
 	Status       string    `json:"status"` // assigned/confirmed/cancelled
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// 换班记录
+	IsSwapped          bool       `json:"is_swapped"`
+	OriginalEmployeeID *uuid.UUID `json:"original_employee_id,omitempty"`
 }
 
 // ScheduleRepository 排班仓储接口
@@ -62,6 +66,7 @@ type ScheduleRepositoryInterface interface {
 	// 排班分配操作
 	CreateAssignment(ctx context.Context, assignment *ScheduleAssignment) error
 	CreateAssignments(ctx context.Context, scheduleID uuid.UUID, assignments []*model.Assignment) error
+	UpdateAssignment(ctx context.Context, assignment *ScheduleAssignment) error
 	GetAssignments(ctx context.Context, scheduleID uuid.UUID) ([]*ScheduleAssignment, error)
 	GetAssignmentsByEmployee(ctx context.Context, employeeID uuid.UUID, startDate, endDate string) ([]*ScheduleAssignment, error)
 	DeleteAssignments(ctx context.Context, scheduleID uuid.UUID) error
@@ -268,8 +273,9 @@ func (r *ScheduleRepository) CreateAssignment(ctx context.Context, assignment *S
 	query := `
 		INSERT INTO schedule_assignments (
 			id, schedule_id, employee_id, employee_name, shift_id, shift_name,
-			date, start_time, end_time, position, status, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			date, start_time, end_time, position, status, created_at, updated_at,
+			is_swapped, original_employee_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -277,6 +283,7 @@ func (r *ScheduleRepository) CreateAssignment(ctx context.Context, assignment *S
 		assignment.ShiftID, assignment.ShiftName, assignment.Date, assignment.StartTime,
 		assignment.EndTime, assignment.Position, assignment.Status,
 		assignment.CreatedAt, assignment.UpdatedAt,
+		assignment.IsSwapped, assignment.OriginalEmployeeID,
 	)
 	if err != nil {
 		return fmt.Errorf("创建排班分配失败: %w", err)
@@ -285,11 +292,38 @@ func (r *ScheduleRepository) CreateAssignment(ctx context.Context, assignment *S
 	return nil
 }
 
+// UpdateAssignment 更新排班分配的员工和换班记录
+func (r *ScheduleRepository) UpdateAssignment(ctx context.Context, assignment *ScheduleAssignment) error {
+	query := `
+		UPDATE schedule_assignments SET
+			employee_id = $2, employee_name = $3,
+			is_swapped = $4, original_employee_id = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	assignment.UpdatedAt = time.Now()
+	result, err := r.db.ExecContext(ctx, query,
+		assignment.ID, assignment.EmployeeID, assignment.EmployeeName,
+		assignment.IsSwapped, assignment.OriginalEmployeeID, assignment.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("更新排班分配失败: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("排班分配不存在: %s", assignment.ID)
+	}
+
+	return nil
+}
+
 // GetAssignments 获取排班分配
 func (r *ScheduleRepository) GetAssignments(ctx context.Context, scheduleID uuid.UUID) ([]*ScheduleAssignment, error) {
 	query := `
 		SELECT id, schedule_id, employee_id, employee_name, shift_id, shift_name,
-			date, start_time, end_time, position, status, created_at, updated_at
+			date, start_time, end_time, position, status, created_at, updated_at,
+			is_swapped, original_employee_id
 		FROM schedule_assignments
 		WHERE schedule_id = $1
 		ORDER BY date, start_time
@@ -308,6 +342,7 @@ func (r *ScheduleRepository) GetAssignments(ctx context.Context, scheduleID uuid
 			&a.ID, &a.ScheduleID, &a.EmployeeID, &a.EmployeeName,
 			&a.ShiftID, &a.ShiftName, &a.Date, &a.StartTime,
 			&a.EndTime, &a.Position, &a.Status, &a.CreatedAt, &a.UpdatedAt,
+			&a.IsSwapped, &a.OriginalEmployeeID,
 		); err != nil {
 			return nil, fmt.Errorf("扫描排班分配失败: %w", err)
 		}
@@ -321,7 +356,8 @@ func (r *ScheduleRepository) GetAssignments(ctx context.Context, scheduleID uuid
 func (r *ScheduleRepository) GetAssignmentsByEmployee(ctx context.Context, employeeID uuid.UUID, startDate, endDate string) ([]*ScheduleAssignment, error) {
 	query := `
 		SELECT id, schedule_id, employee_id, employee_name, shift_id, shift_name,
-			date, start_time, end_time, position, status, created_at, updated_at
+			date, start_time, end_time, position, status, created_at, updated_at,
+			is_swapped, original_employee_id
 		FROM schedule_assignments
 		WHERE employee_id = $1 AND date >= $2 AND date <= $3
 		ORDER BY date, start_time
@@ -340,6 +376,7 @@ func (r *ScheduleRepository) GetAssignmentsByEmployee(ctx context.Context, emplo
 			&a.ID, &a.ScheduleID, &a.EmployeeID, &a.EmployeeName,
 			&a.ShiftID, &a.ShiftName, &a.Date, &a.StartTime,
 			&a.EndTime, &a.Position, &a.Status, &a.CreatedAt, &a.UpdatedAt,
+			&a.IsSwapped, &a.OriginalEmployeeID,
 		); err != nil {
 			return nil, fmt.Errorf("扫描排班分配失败: %w", err)
 		}
@@ -13,6 +13,21 @@ import (
 	"github.com/paiban/paiban/pkg/model"
 )
 
+// shiftColumns 班次查询列，顺序与 scanShift 一致
+const shiftColumns = `id, org_id, name, code, COALESCE(description, ''), to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'),
+			duration, COALESCE(break_time, 0), shift_type, COALESCE(color, ''), COALESCE(is_active, true), created_at, updated_at`
+
+// ShiftRepositoryInterface 班次仓储接口
+type ShiftRepositoryInterface interface {
+	Create(ctx context.Context, shift *model.Shift) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Shift, error)
+	GetByCode(ctx context.Context, orgID uuid.UUID, code string) (*model.Shift, error)
+	Update(ctx context.Context, shift *model.Shift) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter ListFilter) ([]*model.Shift, int, error)
+	ListActive(ctx context.Context, orgID uuid.UUID) ([]*model.Shift, error)
+}
+
 // ShiftRepository 班次仓储
 type ShiftRepository struct {
 	db DB
@@ -54,18 +69,31 @@ func (r *ShiftRepository) Create(ctx context.Context, shift *model.Shift) error
 // GetByID 根据ID获取班次
 func (r *ShiftRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Shift, error) {
 	query := `
-		SELECT id, org_id, name, code, description, start_time, end_time,
-			duration, break_time, shift_type, color, is_active, created_at, updated_at
+		SELECT ` + shiftColumns + `
 		FROM shifts
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 
-	shift := &model.Shift{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&shift.ID, &shift.OrgID, &shift.Name, &shift.Code, &shift.Description,
-		&shift.StartTime, &shift.EndTime, &shift.Duration, &shift.BreakTime,
-		&shift.ShiftType, &shift.Color, &shift.IsActive, &shift.CreatedAt, &shift.UpdatedAt,
-	)
+	shift, err := scanShift(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询班次失败: %w", err)
+	}
+
+	return shift, nil
+}
+
+// GetByCode 根据组织和班次编码获取班次，不存在时返回 nil
+func (r *ShiftRepository) GetByCode(ctx context.Context, orgID uuid.UUID, code string) (*model.Shift, error) {
+	query := `
+		SELECT ` + shiftColumns + `
+		FROM shifts
+		WHERE org_id = $1 AND code = $2 AND deleted_at IS NULL
+	`
+
+	shift, err := scanShift(r.db.QueryRowContext(ctx, query, orgID, code))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -158,8 +186,7 @@ func (r *ShiftRepository) List(ctx context.Context, filter ListFilter) ([]*model
 
 	// 查询列表
 	query := fmt.Sprintf(`
-		SELECT id, org_id, name, code, description, start_time, end_time,
-			duration, break_time, shift_type, color, is_active, created_at, updated_at
+		SELECT `+shiftColumns+`
 		FROM shifts
 		WHERE %s
 		ORDER BY start_time ASC
@@ -176,12 +203,8 @@ func (r *ShiftRepository) List(ctx context.Context, filter ListFilter) ([]*model
 
 	var shifts []*model.Shift
 	for rows.Next() {
-		shift := &model.Shift{}
-		if err := rows.Scan(
-			&shift.ID, &shift.OrgID, &shift.Name, &shift.Code, &shift.Description,
-			&shift.StartTime, &shift.EndTime, &shift.Duration, &shift.BreakTime,
-			&shift.ShiftType, &shift.Color, &shift.IsActive, &shift.CreatedAt, &shift.UpdatedAt,
-		); err != nil {
+		shift, err := scanShift(rows)
+		if err != nil {
 			return nil, 0, fmt.Errorf("扫描行失败: %w", err)
 		}
 		shifts = append(shifts, shift)
@@ -197,6 +220,19 @@ func (r *ShiftRepository) ListActive(ctx context.Context, orgID uuid.UUID) ([]*m
 	return shifts, err
 }
 
+// scanShift 扫描班次行
+func scanShift(row interface{ Scan(...interface{}) error }) (*model.Shift, error) {
+	shift := &model.Shift{}
+	if err := row.Scan(
+		&shift.ID, &shift.OrgID, &shift.Name, &shift.Code, &shift.Description,
+		&shift.StartTime, &shift.EndTime, &shift.Duration, &shift.BreakTime,
+		&shift.ShiftType, &shift.Color, &shift.IsActive, &shift.CreatedAt, &shift.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
 // AssignmentRepository 排班分配仓储
 type AssignmentRepository struct {
 	db DB
@@ -438,21 +474,137 @@ func (r *ConstraintRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) (
 			return nil, fmt.Errorf("扫描行失败: %w", err)
 		}
 		json.Unmarshal(configJSON, &c.Config)
+		c.OrgID = orgID
 		constraints = append(constraints, c)
 	}
 
 	return constraints, nil
 }
 
+// ListAllByOrg 获取组织的全部约束配置（含已停用的），按名称排序
+func (r *ConstraintRepository) ListAllByOrg(ctx context.Context, orgID uuid.UUID) ([]ConstraintConfig, error) {
+	query := `
+		SELECT id, org_id, name, type, category, weight, config, enabled, created_at, updated_at
+		FROM constraints
+		WHERE org_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("查询约束配置失败: %w", err)
+	}
+	defer rows.Close()
+
+	var constraints []ConstraintConfig
+	for rows.Next() {
+		c, err := scanConstraintConfig(rows)
+		if err != nil {
+			return nil, fmt.Errorf("扫描行失败: %w", err)
+		}
+		constraints = append(constraints, *c)
+	}
+
+	return constraints, nil
+}
+
+// GetByID 根据ID获取约束配置，不存在时返回 nil
+func (r *ConstraintRepository) GetByID(ctx context.Context, id uuid.UUID) (*ConstraintConfig, error) {
+	query := `
+		SELECT id, org_id, name, type, category, weight, config, enabled, created_at, updated_at
+		FROM constraints
+		WHERE id = $1
+	`
+
+	c, err := scanConstraintConfig(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询约束配置失败: %w", err)
+	}
+	return c, nil
+}
+
+// Create 创建约束配置
+func (r *ConstraintRepository) Create(ctx context.Context, c *ConstraintConfig) error {
+	query := `
+		INSERT INTO constraints (id, org_id, name, type, category, weight, config, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	configJSON, _ := json.Marshal(c.Config)
+	_, err := r.db.ExecContext(ctx, query,
+		c.ID, c.OrgID, c.Name, c.Type, c.Category, c.Weight, configJSON, c.Enabled, c.CreatedAt, c.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("创建约束配置失败: %w", err)
+	}
+	return nil
+}
+
+// Update 更新约束配置
+func (r *ConstraintRepository) Update(ctx context.Context, c *ConstraintConfig) error {
+	query := `
+		UPDATE constraints
+		SET name = $2, type = $3, category = $4, weight = $5, config = $6, enabled = $7, updated_at = $8
+		WHERE id = $1
+	`
+
+	configJSON, _ := json.Marshal(c.Config)
+	_, err := r.db.ExecContext(ctx, query,
+		c.ID, c.Name, c.Type, c.Category, c.Weight, configJSON, c.Enabled, c.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("更新约束配置失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除约束配置
+func (r *ConstraintRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM constraints WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("删除约束配置失败: %w", err)
+	}
+	return nil
+}
+
+// scanConstraintConfig 扫描约束配置行
+func scanConstraintConfig(row interface{ Scan(...interface{}) error }) (*ConstraintConfig, error) {
+	c := &ConstraintConfig{}
+	var configJSON []byte
+	if err := row.Scan(&c.ID, &c.OrgID, &c.Name, &c.Type, &c.Category, &c.Weight, &configJSON, &c.Enabled, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	json.Unmarshal(configJSON, &c.Config)
+	return c, nil
+}
+
 // ConstraintConfig 约束配置
 type ConstraintConfig struct {
-	ID       uuid.UUID              `json:"id"`
-	Name     string                 `json:"name"`
-	Type     string                 `json:"type"`
-	Category string                 `json:"category"`
-	Weight   int                    `json:"weight"`
-	Config   map[string]interface{} `json:"config"`
-	Enabled  bool                   `json:"enabled"`
+	ID        uuid.UUID              `json:"id"`
+	OrgID     uuid.UUID              `json:"org_id"`
+	Name      string                 `json:"name"`
+	Type      string                 `json:"type"`
+	Category  string                 `json:"category"`
+	Weight    int                    `json:"weight"`
+	Config    map[string]interface{} `json:"config"`
+	Enabled   bool                   `json:"enabled"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// ConstraintRepositoryInterface 约束配置仓储接口
+type ConstraintRepositoryInterface interface {
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]ConstraintConfig, error)
+	ListAllByOrg(ctx context.Context, orgID uuid.UUID) ([]ConstraintConfig, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*ConstraintConfig, error)
+	Create(ctx context.Context, c *ConstraintConfig) error
+	Update(ctx context.Context, c *ConstraintConfig) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 // ScenarioTemplateRepository 场景模板仓储
@@ -468,32 +620,63 @@ func NewScenarioTemplateRepository(db DB) *ScenarioTemplateRepository {
 // GetByScenario 获取场景的默认模板
 func (r *ScenarioTemplateRepository) GetByScenario(ctx context.Context, scenario string) (*ScenarioTemplate, error) {
 	query := `
-		SELECT id, name, scenario, description, constraints, is_default, created_at
+		SELECT id, name, scenario, description, constraints, is_default, created_at, updated_at
 		FROM scenario_templates
 		WHERE scenario = $1 AND is_default = true
 		LIMIT 1
 	`
 
-	t := &ScenarioTemplate{}
-	var constraintsJSON []byte
-	err := r.db.QueryRowContext(ctx, query, scenario).Scan(
-		&t.ID, &t.Name, &t.Scenario, &t.Description, &constraintsJSON, &t.IsDefault, &t.CreatedAt,
-	)
+	t, err := scanScenarioTemplate(r.db.QueryRowContext(ctx, query, scenario))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询场景模板失败: %w", err)
+	}
+	return t, nil
+}
+
+// GetCustom 按场景标识获取自定义场景模板，不存在时返回 nil
+func (r *ScenarioTemplateRepository) GetCustom(ctx context.Context, scenario string) (*ScenarioTemplate, error) {
+	query := `
+		SELECT id, name, scenario, description, constraints, is_default, created_at, updated_at
+		FROM scenario_templates
+		WHERE scenario = $1 AND is_default = false
+		LIMIT 1
+	`
+
+	t, err := scanScenarioTemplate(r.db.QueryRowContext(ctx, query, scenario))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, fmt.Errorf("查询场景模板失败: %w", err)
 	}
+	return t, nil
+}
 
-	json.Unmarshal(constraintsJSON, &t.Constraints)
+// GetByID 根据ID获取场景模板，不存在时返回 nil
+func (r *ScenarioTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*ScenarioTemplate, error) {
+	query := `
+		SELECT id, name, scenario, description, constraints, is_default, created_at, updated_at
+		FROM scenario_templates
+		WHERE id = $1
+	`
+
+	t, err := scanScenarioTemplate(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询场景模板失败: %w", err)
+	}
 	return t, nil
 }
 
 // List 获取所有场景模板
 func (r *ScenarioTemplateRepository) List(ctx context.Context) ([]ScenarioTemplate, error) {
 	query := `
-		SELECT id, name, scenario, description, constraints, is_default, created_at
+		SELECT id, name, scenario, description, constraints, is_default, created_at, updated_at
 		FROM scenario_templates
 		ORDER BY scenario, is_default DESC
 	`
@@ -506,18 +689,75 @@ func (r *ScenarioTemplateRepository) List(ctx context.Context) ([]ScenarioTempla
 
 	var templates []ScenarioTemplate
 	for rows.Next() {
-		t := ScenarioTemplate{}
-		var constraintsJSON []byte
-		if err := rows.Scan(&t.ID, &t.Name, &t.Scenario, &t.Description, &constraintsJSON, &t.IsDefault, &t.CreatedAt); err != nil {
+		t, err := scanScenarioTemplate(rows)
+		if err != nil {
 			return nil, fmt.Errorf("扫描行失败: %w", err)
 		}
-		json.Unmarshal(constraintsJSON, &t.Constraints)
-		templates = append(templates, t)
+		templates = append(templates, *t)
 	}
 
 	return templates, nil
 }
 
+// Create 创建场景模板
+func (r *ScenarioTemplateRepository) Create(ctx context.Context, t *ScenarioTemplate) error {
+	query := `
+		INSERT INTO scenario_templates (id, name, scenario, description, constraints, is_default, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	constraintsJSON, _ := json.Marshal(t.Constraints)
+	_, err := r.db.ExecContext(ctx, query,
+		t.ID, t.Name, t.Scenario, t.Description, constraintsJSON, t.IsDefault, t.CreatedAt, t.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("创建场景模板失败: %w", err)
+	}
+	return nil
+}
+
+// Update 更新场景模板
+func (r *ScenarioTemplateRepository) Update(ctx context.Context, t *ScenarioTemplate) error {
+	query := `
+		UPDATE scenario_templates
+		SET name = $2, scenario = $3, description = $4, constraints = $5, updated_at = $6
+		WHERE id = $1
+	`
+
+	constraintsJSON, _ := json.Marshal(t.Constraints)
+	_, err := r.db.ExecContext(ctx, query,
+		t.ID, t.Name, t.Scenario, t.Description, constraintsJSON, t.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("更新场景模板失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除场景模板
+func (r *ScenarioTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM scenario_templates WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("删除场景模板失败: %w", err)
+	}
+	return nil
+}
+
+// scanScenarioTemplate 扫描场景模板行
+func scanScenarioTemplate(row interface{ Scan(...interface{}) error }) (*ScenarioTemplate, error) {
+	t := &ScenarioTemplate{}
+	var constraintsJSON []byte
+	var description sql.NullString
+	if err := row.Scan(&t.ID, &t.Name, &t.Scenario, &description, &constraintsJSON, &t.IsDefault, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	t.Description = description.String
+	json.Unmarshal(constraintsJSON, &t.Constraints)
+	return t, nil
+}
+
 // ScenarioTemplate 场景模板
 type ScenarioTemplate struct {
 	ID          uuid.UUID              `json:"id"`
@@ -527,4 +767,15 @@ type ScenarioTemplate struct {
 	Constraints map[string]interface{} `json:"constraints"`
 	IsDefault   bool                   `json:"is_default"`
 	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// ScenarioTemplateRepositoryInterface 场景模板仓储接口
+type ScenarioTemplateRepositoryInterface interface {
+	List(ctx context.Context) ([]ScenarioTemplate, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*ScenarioTemplate, error)
+	GetCustom(ctx context.Context, scenario string) (*ScenarioTemplate, error)
+	Create(ctx context.Context, t *ScenarioTemplate) error
+	Update(ctx context.Context, t *ScenarioTemplate) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TeamRepositoryInterface 班组仓储接口
+type TeamRepositoryInterface interface {
+	Replace(ctx context.Context, orgID uuid.UUID, teams []*model.Team) error
+	List(ctx context.Context, orgID uuid.UUID) ([]*model.Team, error)
+}
+
+// TeamRepository 班组仓储（teams 表，按组织整体替换）
+type TeamRepository struct {
+	db DB
+}
+
+// NewTeamRepository 创建班组仓储
+func NewTeamRepository(db DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// Replace 替换组织的全部班组
+func (r *TeamRepository) Replace(ctx context.Context, orgID uuid.UUID, teams []*model.Team) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM teams WHERE org_id = $1`, orgID); err != nil {
+		return fmt.Errorf("删除班组失败: %w", err)
+	}
+
+	query := `
+		INSERT INTO teams (org_id, id, name, members, cohesion, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	for _, t := range teams {
+		if t.UpdatedAt.IsZero() {
+			t.UpdatedAt = time.Now()
+		}
+		cohesion := t.Cohesion
+		if cohesion == "" {
+			cohesion = model.TeamCohesionPreferred
+		}
+		membersJSON, _ := json.Marshal(t.Members)
+		if _, err := r.db.ExecContext(ctx, query, orgID, t.ID, t.Name, membersJSON, cohesion, t.UpdatedAt); err != nil {
+			return fmt.Errorf("保存班组 %s 失败: %w", t.ID, err)
+		}
+	}
+	return nil
+}
+
+// List 列出组织的班组（按班组ID排序）
+func (r *TeamRepository) List(ctx context.Context, orgID uuid.UUID) ([]*model.Team, error) {
+	query := `
+		SELECT org_id, id, name, members, cohesion, updated_at
+		FROM teams
+		WHERE org_id = $1
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("查询班组失败: %w", err)
+	}
+	defer rows.Close()
+
+	teams := make([]*model.Team, 0)
+	for rows.Next() {
+		t := &model.Team{}
+		var membersJSON []byte
+		if err := rows.Scan(&t.OrgID, &t.ID, &t.Name, &membersJSON, &t.Cohesion, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("扫描行失败: %w", err)
+		}
+		json.Unmarshal(membersJSON, &t.Members)
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
@@ -0,0 +1,202 @@
+package security
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("无效的令牌")
+	ErrExpiredToken = errors.New("令牌已过期")
+)
+
+// JWTClaims 令牌声明
+type JWTClaims struct {
+	Subject   string   `json:"sub,omitempty"`
+	OrgID     string   `json:"org_id,omitempty"` // 令牌所属组织，"*" 表示可访问全部组织
+	Role      string   `json:"role,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+// audience aud 声明，可以是字符串或字符串数组
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return err
+	}
+	*a = multi
+	return nil
+}
+
+// JWTVerifier JWT 令牌验证器，支持 HS256（共享密钥）和 RS256（公钥）签名
+type JWTVerifier struct {
+	secret    []byte
+	publicKey *rsa.PublicKey
+	issuer    string
+	audience  string
+	leeway    time.Duration
+	now       func() time.Time
+}
+
+// NewJWTVerifier 创建令牌验证器。secret 和 publicKeyPEM 至少配置一个；
+// issuer、aud 非空时要求令牌的 iss、aud 与之匹配
+func NewJWTVerifier(secret, publicKeyPEM, issuer, aud string) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		secret:   []byte(secret),
+		issuer:   issuer,
+		audience: aud,
+		leeway:   30 * time.Second,
+		now:      time.Now,
+	}
+	if publicKeyPEM != "" {
+		key, err := ParseRSAPublicKey(publicKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		v.publicKey = key
+	}
+	if len(v.secret) == 0 && v.publicKey == nil {
+		return nil, errors.New("未配置 HS256 密钥或 RS256 公钥")
+	}
+	return v, nil
+}
+
+// ParseRSAPublicKey 解析 PEM 格式的 RSA 公钥（PKIX 或 PKCS#1）
+func ParseRSAPublicKey(data string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("无效的 PEM 公钥")
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析公钥失败: %w", err)
+	}
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("公钥不是 RSA 公钥")
+	}
+	return key, nil
+}
+
+// LooksLikeJWT 判断凭证是否为 JWT 格式（三段 base64url，以 . 分隔）
+func LooksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Verify 验证令牌签名和有效期，返回声明
+func (v *JWTVerifier) Verify(token string) (*JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+
+	// 按服务端配置的密钥校验算法，避免令牌自行指定算法（如 none 或以公钥作 HMAC 密钥）
+	switch header.Alg {
+	case "HS256":
+		if len(v.secret) == 0 {
+			return nil, ErrInvalidToken
+		}
+		h := hmac.New(sha256.New, v.secret)
+		h.Write(signed)
+		if !hmac.Equal(signature, h.Sum(nil)) {
+			return nil, ErrInvalidSignature
+		}
+	case "RS256":
+		if v.publicKey == nil {
+			return nil, ErrInvalidToken
+		}
+		digest := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(v.publicKey, crypto.SHA256, digest[:], signature) != nil {
+			return nil, ErrInvalidSignature
+		}
+	default:
+		return nil, ErrInvalidToken
+	}
+
+	var claims JWTClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	now := v.now()
+	if claims.ExpiresAt != 0 && now.After(time.Unix(claims.ExpiresAt, 0).Add(v.leeway)) {
+		return nil, ErrExpiredToken
+	}
+	if claims.NotBefore != 0 && now.Add(v.leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, ErrInvalidToken
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, ErrInvalidToken
+	}
+	if v.audience != "" && !claims.hasAudience(v.audience) {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+func (c *JWTClaims) hasAudience(aud string) bool {
+	for _, a := range c.Audience {
+		if a == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// SignHS256 以 HS256 签发令牌（用于测试和内部工具）
+func SignHS256(claims *JWTClaims, secret string) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// decodeSegment 解码 base64url 编码的 JSON 片段
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
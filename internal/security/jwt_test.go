@@ -0,0 +1,104 @@
+package security
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signRS256 以 RS256 签发测试令牌
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims *JWTClaims) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTVerifier_HS256(t *testing.T) {
+	v, err := NewJWTVerifier("secret", "", "paiban-auth", "paiban")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	token, _ := SignHS256(&JWTClaims{Subject: "u1", OrgID: "org-1", Issuer: "paiban-auth", Audience: audience{"paiban"}, ExpiresAt: exp}, "secret")
+	claims, err := v.Verify(token)
+	if err != nil || claims.OrgID != "org-1" || claims.Subject != "u1" {
+		t.Fatalf("Verify = %+v, %v", claims, err)
+	}
+
+	tests := []struct {
+		name   string
+		claims *JWTClaims
+		secret string
+		want   error
+	}{
+		{"密钥错误", &JWTClaims{OrgID: "org-1", Issuer: "paiban-auth", Audience: audience{"paiban"}}, "other", ErrInvalidSignature},
+		{"已过期", &JWTClaims{OrgID: "org-1", Issuer: "paiban-auth", Audience: audience{"paiban"}, ExpiresAt: time.Now().Add(-time.Hour).Unix()}, "secret", ErrExpiredToken},
+		{"签发方不符", &JWTClaims{OrgID: "org-1", Issuer: "other", Audience: audience{"paiban"}}, "secret", ErrInvalidToken},
+		{"受众不符", &JWTClaims{OrgID: "org-1", Issuer: "paiban-auth", Audience: audience{"other"}}, "secret", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		token, _ := SignHS256(tt.claims, tt.secret)
+		if _, err := v.Verify(token); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// 篡改载荷（aud 为字符串）后签名不再匹配
+	parts := strings.Split(token, ".")
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"org_id":"org-2","iss":"paiban-auth","aud":"paiban"}`))
+	if _, err := v.Verify(parts[0] + "." + payload + "." + parts[2]); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("篡改载荷应签名失败: %v", err)
+	}
+	if _, err := v.Verify(parts[0] + "." + parts[1] + "."); err == nil {
+		t.Error("缺少签名应验证失败")
+	}
+}
+
+func TestJWTVerifier_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	publicPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	v, err := NewJWTVerifier("", publicPEM, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := v.Verify(signRS256(t, key, &JWTClaims{Subject: "svc", OrgID: "*"}))
+	if err != nil || claims.OrgID != "*" {
+		t.Fatalf("Verify = %+v, %v", claims, err)
+	}
+
+	// 仅配置公钥时不接受 HS256（防止以公钥作 HMAC 密钥伪造令牌）
+	forged, _ := SignHS256(&JWTClaims{OrgID: "*"}, publicPEM)
+	if _, err := v.Verify(forged); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("HS256 令牌应被拒绝: %v", err)
+	}
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if _, err := v.Verify(signRS256(t, other, &JWTClaims{OrgID: "*"})); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("其他私钥签发的令牌应被拒绝: %v", err)
+	}
+
+	if _, err := NewJWTVerifier("", "", "", ""); err == nil {
+		t.Error("未配置密钥应返回错误")
+	}
+}
@@ -0,0 +1,44 @@
+package share
+
+import (
+	"html/template"
+	"io"
+)
+
+// pageTemplate 分享排班页面（内联样式，不引用外部资源，便于手机直接打开）
+var pageTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex, nofollow">
+<title>{{if .ScheduleName}}{{.ScheduleName}}{{else}}排班{{end}}</title>
+<style>
+body{font-family:sans-serif;margin:16px;color:#222}
+h1{font-size:20px;margin:0 0 4px}
+.meta{color:#666;font-size:13px;margin-bottom:16px}
+h2{font-size:16px;margin:20px 0 6px}
+table{border-collapse:collapse;width:100%}
+td{border-bottom:1px solid #eee;padding:6px 4px;font-size:14px}
+.time{white-space:nowrap;color:#555}
+</style>
+</head>
+<body>
+<h1>{{if .ScheduleName}}{{.ScheduleName}}{{else}}排班{{end}}</h1>
+<div class="meta">{{.StartDate}} 至 {{.EndDate}}{{if .StoreID}} · 门店 {{.StoreID}}{{end}}{{if .Position}} · 岗位 {{.Position}}{{end}} · 链接有效期至 {{.ExpiresAt.Format "2006-01-02 15:04"}}</div>
+{{range .Days}}
+<h2>{{.Date}} {{.Weekday}}</h2>
+<table>
+{{range .Entries}}<tr><td class="time">{{.StartTime}}-{{.EndTime}}</td><td>{{.Shift}}</td><td>{{.EmployeeName}}</td><td>{{.Position}}</td></tr>
+{{end}}</table>
+{{else}}
+<p>暂无排班</p>
+{{end}}
+</body>
+</html>
+`))
+
+// RenderHTML 将分享的排班渲染为只读 HTML 页面
+func RenderHTML(w io.Writer, v *View) error {
+	return pageTemplate.Execute(w, v)
+}
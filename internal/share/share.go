@@ -0,0 +1,268 @@
+// Package share 提供已发布排班的只读分享链接
+// 管理者为已公布的排班生成带到期时间的签名链接（可按门店、岗位筛选），没有账号的员工凭链接查看 HTML/JSON 只读排班；
+// 链接可单独撤销，每次访问（包括被拒绝的访问）都会记录
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+const (
+	// DefaultTTL 分享链接默认有效期
+	DefaultTTL = 7 * 24 * time.Hour
+	// MaxTTL 分享链接最长有效期
+	MaxTTL = 90 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidOptions 分享参数无效
+	ErrInvalidOptions = errors.New("分享参数无效")
+	// ErrNotPublished 排班尚未公布
+	ErrNotPublished = errors.New("排班尚未公布，不能分享")
+	// ErrInvalidToken 链接无效（格式错误或签名不符）
+	ErrInvalidToken = errors.New("分享链接无效")
+	// ErrExpired 链接已过期
+	ErrExpired = errors.New("分享链接已过期")
+	// ErrRevoked 链接已撤销
+	ErrRevoked = errors.New("分享链接已撤销")
+)
+
+// Options 分享参数
+type Options struct {
+	StoreID  string
+	Position string
+	TTL      time.Duration // 为 0 时使用 DefaultTTL
+}
+
+// View 分享的只读排班
+type View struct {
+	ScheduleName string    `json:"schedule_name,omitempty"`
+	StartDate    string    `json:"start_date"`
+	EndDate      string    `json:"end_date"`
+	StoreID      string    `json:"store_id,omitempty"`
+	Position     string    `json:"position,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Days         []Day     `json:"days"`
+}
+
+// Day 某天的排班
+type Day struct {
+	Date    string  `json:"date"`
+	Weekday string  `json:"weekday"`
+	Entries []Entry `json:"entries"`
+}
+
+// Entry 一条排班（只含姓名、班次和时间，不含员工联系方式等信息）
+type Entry struct {
+	Shift        string `json:"shift"`
+	StartTime    string `json:"start_time"` // HH:MM
+	EndTime      string `json:"end_time"`
+	EmployeeName string `json:"employee_name"`
+	Position     string `json:"position,omitempty"`
+}
+
+// Service 分享链接服务
+type Service struct {
+	store *memstore.Store
+	key   []byte
+	now   func() time.Time
+}
+
+// NewService 创建分享链接服务，secret 为空时使用随机密钥（服务重启后已发出的链接失效）
+func NewService(store *memstore.Store, secret string) *Service {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	return &Service{store: store, key: key, now: time.Now}
+}
+
+// Create 为已公布的排班创建分享链接
+func (s *Service) Create(scheduleID uuid.UUID, opts Options, createdBy string) (*model.ShareLink, error) {
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 || ttl > MaxTTL {
+		return nil, fmt.Errorf("%w: 有效期应在 %d 天以内", ErrInvalidOptions, int(MaxTTL.Hours()/24))
+	}
+	schedule, err := s.store.GetSchedule(scheduleID)
+	if err != nil {
+		return nil, memstore.ErrNotFound
+	}
+	now := s.now()
+	if !schedule.IsVisibleAt(now) {
+		return nil, ErrNotPublished
+	}
+
+	l := &model.ShareLink{
+		ID:         uuid.New(),
+		OrgID:      schedule.OrgID,
+		ScheduleID: scheduleID,
+		StoreID:    opts.StoreID,
+		Position:   opts.Position,
+		ExpiresAt:  now.Add(ttl).Truncate(time.Second),
+		CreatedBy:  createdBy,
+		CreatedAt:  now,
+	}
+	if err := s.store.PutShareLink(l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Revoke 撤销排班的分享链接，已撤销的链接保持原撤销记录
+func (s *Service) Revoke(scheduleID, linkID uuid.UUID, revokedBy string) (*model.ShareLink, error) {
+	l, err := s.store.GetShareLink(linkID)
+	if err != nil || l.ScheduleID != scheduleID {
+		return nil, memstore.ErrNotFound
+	}
+	if l.RevokedAt != nil {
+		return l, nil
+	}
+	now := s.now()
+	l.RevokedAt, l.RevokedBy = &now, revokedBy
+	if err := s.store.PutShareLink(l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Token 返回链接的访问令牌：链接ID.到期时间戳.签名
+func (s *Service) Token(l *model.ShareLink) string {
+	payload := fmt.Sprintf("%s.%d", l.ID, l.ExpiresAt.Unix())
+	return payload + "." + s.sign(payload)
+}
+
+// Open 校验令牌并返回分享的排班，access 为本次访问信息（链接ID和时间由服务填写）
+// 令牌签名有效时无论是否允许访问都会记录访问
+func (s *Service) Open(token string, access model.ShareAccess) (*View, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || !hmac.Equal([]byte(s.sign(parts[0]+"."+parts[1])), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+	id, err := uuid.Parse(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	l, err := s.store.GetShareLink(id)
+	if err != nil || l.ExpiresAt.Unix() != expires {
+		return nil, ErrInvalidToken
+	}
+
+	now := s.now()
+	access.LinkID, access.At = id, now
+	var denied error
+	schedule, err := s.store.GetSchedule(l.ScheduleID)
+	switch {
+	case l.RevokedAt != nil:
+		denied = ErrRevoked
+	case !now.Before(l.ExpiresAt):
+		denied = ErrExpired
+	case err != nil || !schedule.IsVisibleAt(now):
+		denied = ErrNotPublished
+	}
+	if denied != nil {
+		access.Denied = denied.Error()
+		s.store.RecordShareAccess(&access)
+		return nil, denied
+	}
+	s.store.RecordShareAccess(&access)
+	return s.view(l, schedule), nil
+}
+
+// view 按链接的门店、岗位筛选排班（不含已取消的分配），按日期、开始时间、姓名排序
+func (s *Service) view(l *model.ShareLink, schedule *model.Schedule) *View {
+	v := &View{
+		ScheduleName: schedule.Name,
+		StartDate:    schedule.StartDate,
+		EndDate:      schedule.EndDate,
+		StoreID:      l.StoreID,
+		Position:     l.Position,
+		ExpiresAt:    l.ExpiresAt,
+		Days:         make([]Day, 0),
+	}
+
+	employees := make(map[uuid.UUID]*model.Employee)
+	shifts := make(map[uuid.UUID]string)
+	byDate := make(map[string][]Entry)
+	for _, a := range schedule.Assignments {
+		if a.Status == "cancelled" {
+			continue
+		}
+		emp, ok := employees[a.EmployeeID]
+		if !ok {
+			if emp, _ = s.store.GetEmployee(a.EmployeeID); emp == nil {
+				emp = &model.Employee{Name: "未知员工"}
+			}
+			employees[a.EmployeeID] = emp
+		}
+		position := a.Position
+		if position == "" {
+			position = emp.Position
+		}
+		if (l.StoreID != "" && emp.StoreID != l.StoreID) || (l.Position != "" && position != l.Position) {
+			continue
+		}
+		if _, ok := shifts[a.ShiftID]; !ok {
+			if shift, err := s.store.GetShift(a.ShiftID); err == nil {
+				shifts[a.ShiftID] = shift.Name
+			}
+		}
+		byDate[a.Date] = append(byDate[a.Date], Entry{
+			Shift:        shifts[a.ShiftID],
+			StartTime:    a.StartTime.Format("15:04"),
+			EndTime:      a.EndTime.Format("15:04"),
+			EmployeeName: emp.Name,
+			Position:     position,
+		})
+	}
+
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for _, date := range dates {
+		entries := byDate[date]
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].StartTime != entries[j].StartTime {
+				return entries[i].StartTime < entries[j].StartTime
+			}
+			return entries[i].EmployeeName < entries[j].EmployeeName
+		})
+		day := Day{Date: date, Entries: entries}
+		if t, err := time.Parse("2006-01-02", date); err == nil {
+			day.Weekday = weekdayNames[t.Weekday()]
+		}
+		v.Days = append(v.Days, day)
+	}
+	return v
+}
+
+// sign 计算载荷的 HMAC-SHA256 签名（十六进制前 32 位）
+func (s *Service) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+var weekdayNames = [...]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
@@ -0,0 +1,149 @@
+package share
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// newTestService 已公布排班：门店 A 的收银员张三（早班）、门店 B 的厨师李四（晚班）
+func newTestService(t *testing.T) (*Service, *memstore.Store, *model.Schedule) {
+	t.Helper()
+	store := memstore.New("")
+	orgID := uuid.New()
+	morning := &model.Shift{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "早班", StartTime: "08:00", EndTime: "16:00"}
+	evening := &model.Shift{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "晚班", StartTime: "16:00", EndTime: "23:00"}
+	store.PutShift(morning)
+	store.PutShift(evening)
+	zhang := &model.Employee{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "张三", Position: "cashier", StoreID: "A", Phone: "13800000000"}
+	li := &model.Employee{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "李四", Position: "cook", StoreID: "B"}
+	store.PutEmployee(zhang)
+	store.PutEmployee(li)
+
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	schedule := &model.Schedule{
+		BaseModel: model.NewBaseModel(),
+		OrgID:     orgID,
+		Name:      "三月第一周",
+		StartDate: "2026-03-02",
+		EndDate:   "2026-03-08",
+		Status:    "published",
+		Assignments: []model.Assignment{
+			{EmployeeID: zhang.ID, ShiftID: morning.ID, Date: "2026-03-02", StartTime: day.Add(8 * time.Hour), EndTime: day.Add(16 * time.Hour), Status: "scheduled"},
+			{EmployeeID: li.ID, ShiftID: evening.ID, Date: "2026-03-02", StartTime: day.Add(16 * time.Hour), EndTime: day.Add(23 * time.Hour), Status: "scheduled"},
+			{EmployeeID: li.ID, ShiftID: evening.ID, Date: "2026-03-03", StartTime: day.Add(40 * time.Hour), EndTime: day.Add(47 * time.Hour), Status: "cancelled"},
+		},
+	}
+	store.PutSchedule(schedule)
+	return NewService(store, "test-secret"), store, schedule
+}
+
+func TestService_CreateAndOpen(t *testing.T) {
+	s, store, schedule := newTestService(t)
+
+	link, err := s.Create(schedule.ID, Options{StoreID: "A"}, "manager")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if got := link.ExpiresAt.Sub(link.CreatedAt); got < DefaultTTL-time.Second || got > DefaultTTL {
+		t.Errorf("ttl = %v", got)
+	}
+
+	view, err := s.Open(s.Token(link), model.ShareAccess{Format: "json"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(view.Days) != 1 || len(view.Days[0].Entries) != 1 {
+		t.Fatalf("days = %+v", view.Days)
+	}
+	entry := view.Days[0].Entries[0]
+	if entry.EmployeeName != "张三" || entry.Shift != "早班" || entry.StartTime != "08:00" || entry.Position != "cashier" {
+		t.Errorf("entry = %+v", entry)
+	}
+	if view.Days[0].Weekday != "周一" {
+		t.Errorf("weekday = %q", view.Days[0].Weekday)
+	}
+
+	got, _ := store.GetShareLink(link.ID)
+	if got.Accesses != 1 || got.LastAccessAt == nil {
+		t.Errorf("link = %+v", got)
+	}
+
+	var html strings.Builder
+	if err := RenderHTML(&html, view); err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+	if !strings.Contains(html.String(), "张三") || strings.Contains(html.String(), "李四") || strings.Contains(html.String(), "13800000000") {
+		t.Errorf("html = %s", html.String())
+	}
+}
+
+func TestService_CreateRequiresPublished(t *testing.T) {
+	s, store, schedule := newTestService(t)
+	schedule.Status = "draft"
+	store.PutSchedule(schedule)
+
+	if _, err := s.Create(schedule.ID, Options{}, "manager"); !errors.Is(err, ErrNotPublished) {
+		t.Errorf("Create() error = %v, want ErrNotPublished", err)
+	}
+	if _, err := s.Create(uuid.New(), Options{}, "manager"); err != memstore.ErrNotFound {
+		t.Errorf("Create() error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Create(schedule.ID, Options{TTL: MaxTTL + time.Hour}, "manager"); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Create() error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestService_OpenRejectsTamperedExpiredAndRevoked(t *testing.T) {
+	s, store, schedule := newTestService(t)
+	link, _ := s.Create(schedule.ID, Options{Position: "cook", TTL: time.Hour}, "manager")
+	token := s.Token(link)
+
+	// 篡改到期时间或使用其他密钥签名的令牌均无效，且不记录访问
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + ".9999999999." + parts[2]
+	if _, err := s.Open(tampered, model.ShareAccess{}); err != ErrInvalidToken {
+		t.Errorf("Open(tampered) error = %v, want ErrInvalidToken", err)
+	}
+	other := NewService(store, "other-secret")
+	if _, err := other.Open(token, model.ShareAccess{}); err != ErrInvalidToken {
+		t.Errorf("Open(other key) error = %v, want ErrInvalidToken", err)
+	}
+	if n := len(store.ListShareAccesses(link.ID)); n != 0 {
+		t.Errorf("accesses = %d, want 0", n)
+	}
+
+	view, err := s.Open(token, model.ShareAccess{})
+	if err != nil || len(view.Days) != 1 || view.Days[0].Entries[0].EmployeeName != "李四" {
+		t.Fatalf("Open() = %+v, %v", view, err)
+	}
+
+	s.now = func() time.Time { return link.ExpiresAt }
+	if _, err := s.Open(token, model.ShareAccess{}); err != ErrExpired {
+		t.Errorf("Open(expired) error = %v, want ErrExpired", err)
+	}
+	s.now = time.Now
+
+	if _, err := s.Revoke(uuid.New(), link.ID, "manager"); err != memstore.ErrNotFound {
+		t.Errorf("Revoke(other schedule) error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Revoke(schedule.ID, link.ID, "manager"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if _, err := s.Open(token, model.ShareAccess{}); err != ErrRevoked {
+		t.Errorf("Open(revoked) error = %v, want ErrRevoked", err)
+	}
+
+	accesses := store.ListShareAccesses(link.ID)
+	if len(accesses) != 3 || accesses[0].Denied != ErrRevoked.Error() || accesses[1].Denied != ErrExpired.Error() || accesses[2].Denied != "" {
+		t.Errorf("accesses = %+v", accesses)
+	}
+	if got, _ := store.GetShareLink(link.ID); got.Accesses != 1 {
+		t.Errorf("link accesses = %d, want 1", got.Accesses)
+	}
+}
@@ -0,0 +1,349 @@
+// Package summary 提供员工月度排班汇总
+// 月末为每位在职员工生成汇总（总工时、合同目标对比、加班、夜班/周末班、请假），
+// 通过通知模块推送给员工；员工可对汇总记录提出争议，由管理者审核
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// defaultWeeklyHours 员工未配置合同时的标准周工时
+const defaultWeeklyHours = 40
+
+var (
+	// ErrInvalidMonth 月份格式错误
+	ErrInvalidMonth = errors.New("月份格式应为 YYYY-MM")
+	// ErrEntryNotFound 争议的记录不在汇总中
+	ErrEntryNotFound = errors.New("汇总中不存在该记录")
+	// ErrDisputeReviewed 争议已审核
+	ErrDisputeReviewed = errors.New("争议已审核")
+)
+
+// Service 月度汇总服务
+type Service struct {
+	store    *memstore.Store
+	notifier notify.Notifier
+	now      func() time.Time
+}
+
+// NewService 创建月度汇总服务
+func NewService(store *memstore.Store, notifier notify.Notifier) *Service {
+	if notifier == nil {
+		notifier = notify.LogNotifier{}
+	}
+	return &Service{
+		store:    store,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+// Build 计算员工某月的汇总（仅统计已发布的排班）
+func (s *Service) Build(emp *model.Employee, month string) (*model.MonthlySummary, error) {
+	first, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, ErrInvalidMonth
+	}
+	last := first.AddDate(0, 1, -1)
+	startDate, endDate := first.Format("2006-01-02"), last.Format("2006-01-02")
+
+	summary := &model.MonthlySummary{
+		EmployeeID:   emp.ID,
+		OrgID:        emp.OrgID,
+		EmployeeName: emp.Name,
+		Month:        month,
+		Entries:      make([]model.SummaryEntry, 0),
+		GeneratedAt:  s.now(),
+	}
+
+	shifts := make(map[uuid.UUID]*model.Shift)
+	seen := make(map[uuid.UUID]bool)
+	weekHours := make(map[string]float64)
+	for _, schedule := range s.store.ListSchedules(emp.OrgID) {
+		if schedule.Status != "published" {
+			continue
+		}
+		for i := range schedule.Assignments {
+			a := &schedule.Assignments[i]
+			if a.EmployeeID != emp.ID || a.Date < startDate || a.Date > endDate || seen[a.ID] || a.Status == "cancelled" {
+				continue
+			}
+			seen[a.ID] = true
+
+			shift, ok := shifts[a.ShiftID]
+			if !ok {
+				shift, _ = s.store.GetShift(a.ShiftID)
+				shifts[a.ShiftID] = shift
+			}
+			entry := buildShiftEntry(a, shift)
+			summary.Entries = append(summary.Entries, entry)
+			summary.TotalHours += entry.Hours
+			summary.Shifts++
+			if entry.Night {
+				summary.NightShifts++
+			}
+			if entry.Weekend {
+				summary.WeekendShifts++
+			}
+			weekHours[isoWeek(a.Date)] += entry.Hours
+		}
+	}
+
+	for _, av := range s.store.ListAvailability(emp.ID, startDate, endDate) {
+		if !av.IsApprovedLeave() {
+			continue
+		}
+		summary.LeaveDays++
+		summary.Entries = append(summary.Entries, model.SummaryEntry{
+			Date: av.Date,
+			Type: "leave",
+			Note: av.Reason,
+		})
+	}
+
+	sort.SliceStable(summary.Entries, func(i, j int) bool { return summary.Entries[i].Date < summary.Entries[j].Date })
+
+	weeklyTarget, weeklyMax := contractHours(emp.Contract)
+	for _, hours := range weekHours {
+		if hours > weeklyMax {
+			summary.OvertimeHours += hours - weeklyMax
+		}
+	}
+	summary.TargetHours = round1(weeklyTarget * float64(last.Day()) / 7)
+	summary.TotalHours = round1(summary.TotalHours)
+	summary.OvertimeHours = round1(summary.OvertimeHours)
+	summary.HoursVsTarget = round1(summary.TotalHours - summary.TargetHours)
+	return summary, nil
+}
+
+// Deliver 生成并推送某月所有在职员工的汇总，已推送的员工不会重复推送，返回推送数量
+func (s *Service) Deliver(ctx context.Context, month string) (int, error) {
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return 0, ErrInvalidMonth
+	}
+
+	count := 0
+	for _, emp := range s.store.ListEmployees(uuid.Nil) {
+		if emp.Status != "" && !emp.IsActive() {
+			continue
+		}
+		if existing, err := s.store.GetMonthlySummary(emp.ID, month); err == nil && existing.DeliveredAt != nil {
+			continue
+		}
+
+		summary, err := s.Build(emp, month)
+		if err != nil {
+			return count, err
+		}
+		n := notify.New(notify.TypeMonthlySummary, emp.OrgID,
+			fmt.Sprintf("%s 月度排班汇总", month),
+			fmt.Sprintf("总工时 %.1f 小时（目标 %.1f，差额 %+.1f），加班 %.1f 小时，夜班 %d 次，周末班 %d 次，请假 %d 天",
+				summary.TotalHours, summary.TargetHours, summary.HoursVsTarget, summary.OvertimeHours,
+				summary.NightShifts, summary.WeekendShifts, summary.LeaveDays),
+			summary).ToEmployee(emp.ID)
+		if err := s.notifier.Notify(ctx, n); err != nil {
+			logger.Error().Err(err).Str("employee_id", emp.ID.String()).Str("month", month).Msg("推送月度汇总失败")
+			continue
+		}
+
+		deliveredAt := s.now()
+		summary.DeliveredAt = &deliveredAt
+		if err := s.store.PutMonthlySummary(summary); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Run 定期检查并推送上一个月的汇总，直到 ctx 取消
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			month := s.now().AddDate(0, 0, -s.now().Day()).Format("2006-01")
+			if n, err := s.Deliver(ctx, month); err != nil {
+				logger.Error().Err(err).Str("month", month).Msg("推送月度汇总失败")
+			} else if n > 0 {
+				logger.Info().Int("count", n).Str("month", month).Msg("月度汇总已推送")
+			}
+		}
+	}
+}
+
+// Summary 获取员工某月的汇总，已推送的返回推送时的快照，否则实时计算
+func (s *Service) Summary(employeeID uuid.UUID, month string) (*model.MonthlySummary, error) {
+	if existing, err := s.store.GetMonthlySummary(employeeID, month); err == nil {
+		return existing, nil
+	}
+	emp, err := s.store.GetEmployee(employeeID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Build(emp, month)
+}
+
+// Dispute 员工对月度汇总中的记录提出争议，并通知管理者审核
+func (s *Service) Dispute(ctx context.Context, employeeID uuid.UUID, month, date string, assignmentID *uuid.UUID, reason string) (*model.SummaryDispute, error) {
+	summary, err := s.Summary(employeeID, month)
+	if err != nil {
+		return nil, err
+	}
+	if !hasEntry(summary, date, assignmentID) {
+		return nil, ErrEntryNotFound
+	}
+
+	dispute := &model.SummaryDispute{
+		BaseModel:    model.NewBaseModel(),
+		OrgID:        summary.OrgID,
+		EmployeeID:   employeeID,
+		Month:        month,
+		Date:         date,
+		AssignmentID: assignmentID,
+		Reason:       reason,
+		Status:       model.DisputePending,
+	}
+	if err := s.store.PutDispute(dispute); err != nil {
+		return nil, err
+	}
+
+	n := notify.New(notify.TypeSummaryDispute, summary.OrgID,
+		fmt.Sprintf("%s 对 %s 月度汇总提出争议", summary.EmployeeName, month),
+		reason, dispute)
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		logger.Error().Err(err).Str("dispute_id", dispute.ID.String()).Msg("通知管理者审核争议失败")
+	}
+	return dispute, nil
+}
+
+// Review 管理者审核争议，并将结果通知员工
+func (s *Service) Review(ctx context.Context, disputeID uuid.UUID, approve bool, reviewer, note string) (*model.SummaryDispute, error) {
+	dispute, err := s.store.GetDispute(disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != model.DisputePending {
+		return dispute, ErrDisputeReviewed
+	}
+
+	now := s.now()
+	dispute.Status = model.DisputeRejected
+	if approve {
+		dispute.Status = model.DisputeApproved
+	}
+	dispute.ReviewedBy = reviewer
+	dispute.ReviewNote = note
+	dispute.ReviewedAt = &now
+	dispute.UpdatedAt = now
+	if err := s.store.PutDispute(dispute); err != nil {
+		return nil, err
+	}
+
+	result := "已驳回"
+	if approve {
+		result = "已通过"
+	}
+	n := notify.New(notify.TypeDisputeResult, dispute.OrgID,
+		fmt.Sprintf("%s 月度汇总争议%s", dispute.Month, result), note, dispute).ToEmployee(dispute.EmployeeID)
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		logger.Error().Err(err).Str("dispute_id", dispute.ID.String()).Msg("通知员工争议结果失败")
+	}
+	return dispute, nil
+}
+
+// buildShiftEntry 生成班次记录
+func buildShiftEntry(a *model.Assignment, shift *model.Shift) model.SummaryEntry {
+	id := a.ID
+	entry := model.SummaryEntry{
+		Date:         a.Date,
+		Type:         "shift",
+		AssignmentID: &id,
+		Hours:        a.WorkingHours(),
+		Note:         a.Notes,
+	}
+	if shift != nil {
+		entry.ShiftName = shift.Name
+		entry.Night = shift.IsNightShift()
+		if entry.Hours <= 0 {
+			entry.Hours = shift.DurationHours()
+		}
+	}
+	if !entry.Night && !a.StartTime.IsZero() {
+		hour := a.StartTime.Hour()
+		entry.Night = hour >= 22 || hour < 6
+	}
+	if d, err := time.Parse("2006-01-02", a.Date); err == nil {
+		entry.Weekend = d.Weekday() == time.Saturday || d.Weekday() == time.Sunday
+	}
+	return entry
+}
+
+// hasEntry 检查汇总中是否存在争议的记录
+func hasEntry(summary *model.MonthlySummary, date string, assignmentID *uuid.UUID) bool {
+	if date == "" && assignmentID == nil {
+		return true // 针对整月汇总
+	}
+	for _, e := range summary.Entries {
+		if assignmentID != nil {
+			if e.AssignmentID != nil && *e.AssignmentID == *assignmentID {
+				return true
+			}
+			continue
+		}
+		if e.Date == date {
+			return true
+		}
+	}
+	return false
+}
+
+// contractHours 返回合同周目标工时和周最大工时
+func contractHours(contract *model.EmployeeContract) (target, max float64) {
+	target, max = defaultWeeklyHours, defaultWeeklyHours
+	if contract == nil {
+		return
+	}
+	if contract.MaxHoursPerWeek > 0 {
+		max = float64(contract.MaxHoursPerWeek)
+		target = max
+	}
+	if contract.MinHoursPerWeek > 0 {
+		target = float64(contract.MinHoursPerWeek)
+	}
+	return
+}
+
+// isoWeek 返回日期所在的 ISO 周
+func isoWeek(date string) string {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	year, week := d.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// round1 保留一位小数
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
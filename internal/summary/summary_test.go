@@ -0,0 +1,128 @@
+package summary
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+type captureNotifier struct {
+	sent []*notify.Notification
+}
+
+func (c *captureNotifier) Notify(ctx context.Context, n *notify.Notification) error {
+	c.sent = append(c.sent, n)
+	return nil
+}
+
+func newTestService(t *testing.T) (*Service, *captureNotifier, *model.Employee, *model.Schedule) {
+	t.Helper()
+	store := memstore.New("")
+	orgID := uuid.New()
+
+	emp := &model.Employee{
+		BaseModel: model.NewBaseModel(),
+		OrgID:     orgID,
+		Name:      "张三",
+		Status:    "active",
+		Contract:  &model.EmployeeContract{MinHoursPerWeek: 35, MaxHoursPerWeek: 40},
+	}
+	store.PutEmployee(emp)
+
+	night := &model.Shift{BaseModel: model.NewBaseModel(), OrgID: orgID, Name: "夜班", ShiftType: "night", Duration: 600}
+	store.PutShift(night)
+
+	// 2026-01-05 ~ 2026-01-09 周一至周五，2026-01-10 周六，每天 10 小时夜班 => 该周 60 小时
+	schedule := &model.Schedule{BaseModel: model.NewBaseModel(), OrgID: orgID, Status: "published"}
+	for day := 5; day <= 10; day++ {
+		start := time.Date(2026, 1, day, 20, 0, 0, 0, time.UTC)
+		schedule.Assignments = append(schedule.Assignments, model.Assignment{
+			BaseModel:  model.NewBaseModel(),
+			EmployeeID: emp.ID,
+			ShiftID:    night.ID,
+			Date:       start.Format("2006-01-02"),
+			StartTime:  start,
+			EndTime:    start.Add(10 * time.Hour),
+		})
+	}
+	store.PutSchedule(schedule)
+	store.PutAvailability(&model.EmployeeAvailability{EmployeeID: emp.ID, Date: "2026-01-20", Type: "unavailable", Reason: "年假"})
+
+	notifier := &captureNotifier{}
+	s := NewService(store, notifier)
+	s.now = func() time.Time { return time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC) }
+	return s, notifier, emp, schedule
+}
+
+func TestService_Build(t *testing.T) {
+	s, _, emp, _ := newTestService(t)
+
+	summary, err := s.Build(emp, "2026-01")
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if summary.TotalHours != 60 || summary.Shifts != 6 {
+		t.Errorf("total = %.1f/%d, expected 60/6", summary.TotalHours, summary.Shifts)
+	}
+	if summary.OvertimeHours != 20 {
+		t.Errorf("overtime = %.1f, expected 20", summary.OvertimeHours)
+	}
+	if summary.NightShifts != 6 || summary.WeekendShifts != 1 || summary.LeaveDays != 1 {
+		t.Errorf("night/weekend/leave = %d/%d/%d, expected 6/1/1", summary.NightShifts, summary.WeekendShifts, summary.LeaveDays)
+	}
+	if summary.TargetHours != 155 || summary.HoursVsTarget != -95 {
+		t.Errorf("target = %.1f (%+.1f), expected 155 (-95)", summary.TargetHours, summary.HoursVsTarget)
+	}
+}
+
+func TestService_DeliverOnce(t *testing.T) {
+	s, notifier, emp, _ := newTestService(t)
+
+	if n, err := s.Deliver(context.Background(), "2026-01"); err != nil || n != 1 {
+		t.Fatalf("Deliver() = %d, %v", n, err)
+	}
+	if n, _ := s.Deliver(context.Background(), "2026-01"); n != 0 {
+		t.Errorf("重复推送数量 = %d, expected 0", n)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].RecipientID == nil || *notifier.sent[0].RecipientID != emp.ID {
+		t.Errorf("应向员工推送一次汇总, got %+v", notifier.sent)
+	}
+}
+
+func TestService_DisputeReview(t *testing.T) {
+	s, notifier, emp, schedule := newTestService(t)
+	ctx := context.Background()
+
+	missing := uuid.New()
+	if _, err := s.Dispute(ctx, emp.ID, "2026-01", "", &missing, "不存在"); err != ErrEntryNotFound {
+		t.Errorf("Dispute() error = %v, expected ErrEntryNotFound", err)
+	}
+
+	assignmentID := schedule.Assignments[0].ID
+	dispute, err := s.Dispute(ctx, emp.ID, "2026-01", "", &assignmentID, "实际提前下班")
+	if err != nil {
+		t.Fatalf("Dispute() error = %v", err)
+	}
+	if dispute.Status != model.DisputePending || notifier.sent[0].RecipientRole != notify.RecipientManager {
+		t.Error("争议应待审核并通知管理者")
+	}
+
+	reviewed, err := s.Review(ctx, dispute.ID, true, "mgr", "已核实")
+	if err != nil {
+		t.Fatalf("Review() error = %v", err)
+	}
+	if reviewed.Status != model.DisputeApproved || reviewed.ReviewedAt == nil {
+		t.Error("审核后应为已通过")
+	}
+	if last := notifier.sent[len(notifier.sent)-1]; last.Type != notify.TypeDisputeResult || *last.RecipientID != emp.ID {
+		t.Error("审核结果应通知员工")
+	}
+	if _, err := s.Review(ctx, dispute.ID, false, "mgr", ""); err != ErrDisputeReviewed {
+		t.Errorf("重复审核 error = %v, expected ErrDisputeReviewed", err)
+	}
+}
@@ -0,0 +1,223 @@
+// Package timebank 提供调休账户
+// 审批通过的加班按组织调休策略拆分为加班费和调休，调休部分计入员工调休账户；
+// 员工请假时可使用调休（请假记录的 time_bank_hours），提交时校验余额，请假批准后扣除
+package timebank
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// maxDailyHours 一天请假最多使用的调休小时数
+const maxDailyHours = 24
+
+var (
+	// ErrInvalidEntry 调休记录无效
+	ErrInvalidEntry = errors.New("调休记录无效")
+	// ErrInsufficientBalance 调休余额不足
+	ErrInsufficientBalance = errors.New("调休余额不足")
+)
+
+// Service 调休账户服务
+type Service struct {
+	store *memstore.Store
+	now   func() time.Time
+}
+
+// NewService 创建调休账户服务
+func NewService(store *memstore.Store) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+// Accrue 结算审批通过的加班：按组织调休策略拆分为加班费和调休并记录，
+// 非加班审批或未填写加班时长时不处理；每个审批单只结算一次
+func (s *Service) Accrue(a *model.ApprovalRequest) (*model.TimeBankEntry, error) {
+	if a.Kind != model.ApprovalKindOvertime || a.Status != model.ApprovalApproved || a.Hours <= 0 || a.EmployeeID == nil {
+		return nil, nil
+	}
+
+	var policy *model.TimeBankPolicy
+	if org, err := s.store.GetOrganization(a.OrgID); err == nil {
+		policy = org.TimeBankPolicy
+	}
+	banked := 0.0
+	credit := 0.0
+	if policy != nil && policy.BankRatio > 0 {
+		banked = a.Hours * policy.BankRatio
+		credit = banked * policy.Rate()
+		// 余额上限：超出部分改为按加班费结算
+		if policy.MaxBalance > 0 {
+			room := math.Max(policy.MaxBalance-s.Balance(*a.EmployeeID).Balance, 0)
+			if credit > room {
+				credit = room
+				banked = room / policy.Rate()
+			}
+		}
+	}
+
+	id := a.ID
+	e := &model.TimeBankEntry{
+		ID:            uuid.New(),
+		OrgID:         a.OrgID,
+		EmployeeID:    *a.EmployeeID,
+		Date:          a.Date,
+		Kind:          model.TimeBankOvertime,
+		ApprovalID:    &id,
+		OvertimeHours: a.Hours,
+		PaidHours:     round2(a.Hours - banked),
+		BankedHours:   round2(banked),
+		Hours:         round2(credit),
+		Note:          a.Title,
+		CreatedBy:     a.DecidedBy,
+		CreatedAt:     s.now(),
+	}
+	added, err := s.store.AddTimeBankEntry(e)
+	if err != nil || !added {
+		return nil, err
+	}
+	return e, nil
+}
+
+// OnApproved 审批通过回调：结算加班，失败时只记录日志
+func (s *Service) OnApproved(a *model.ApprovalRequest) {
+	e, err := s.Accrue(a)
+	if err != nil {
+		logger.Error().Err(err).Str("approval_id", a.ID.String()).Msg("加班结算失败")
+		return
+	}
+	if e != nil {
+		logger.Info().
+			Str("approval_id", a.ID.String()).
+			Str("employee_id", e.EmployeeID.String()).
+			Float64("paid_hours", e.PaidHours).
+			Float64("banked_hours", e.Hours).
+			Msg("加班已结算")
+	}
+}
+
+// Adjust 手工调整调休余额（如期初余额、清零），hours 可为负但调整后余额不能为负
+func (s *Service) Adjust(orgID, employeeID uuid.UUID, date string, hours float64, note, createdBy string) (*model.TimeBankEntry, error) {
+	if employeeID == uuid.Nil || hours == 0 || note == "" {
+		return nil, fmt.Errorf("%w: 员工、调整小时数和说明不能为空", ErrInvalidEntry)
+	}
+	if date == "" {
+		date = s.now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, fmt.Errorf("%w: 日期格式应为 YYYY-MM-DD", ErrInvalidEntry)
+	}
+	if balance := s.Balance(employeeID); hours < 0 && balance.Available+hours < 0 {
+		return nil, fmt.Errorf("%w: 可用余额 %.2f 小时", ErrInsufficientBalance, balance.Available)
+	}
+
+	e := &model.TimeBankEntry{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		EmployeeID: employeeID,
+		Date:       date,
+		Kind:       model.TimeBankAdjust,
+		Hours:      round2(hours),
+		Note:       note,
+		CreatedBy:  createdBy,
+		CreatedAt:  s.now(),
+	}
+	if _, err := s.store.AddTimeBankEntry(e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Balance 计算员工的调休余额
+// 已批准的请假扣除余额，待审核的请假计入 Pending，被驳回的请假不扣除
+func (s *Service) Balance(employeeID uuid.UUID) *model.TimeBankBalance {
+	b := &model.TimeBankBalance{EmployeeID: employeeID}
+	for _, e := range s.store.ListTimeBankEntries(uuid.Nil, employeeID, "", "") {
+		b.Accrued += e.Hours
+	}
+	for _, av := range s.store.ListAvailability(employeeID, "", "") {
+		used, pending := Spend(av)
+		b.Used += used
+		b.Pending += pending
+	}
+	b.Accrued, b.Used, b.Pending = round2(b.Accrued), round2(b.Used), round2(b.Pending)
+	b.Balance = round2(b.Accrued - b.Used)
+	b.Available = round2(b.Balance - b.Pending)
+	return b
+}
+
+// Balances 列出组织内有调休记录的员工余额（按员工ID排序）
+func (s *Service) Balances(orgID uuid.UUID) []*model.TimeBankBalance {
+	seen := make(map[uuid.UUID]bool)
+	result := make([]*model.TimeBankBalance, 0)
+	for _, e := range s.store.ListTimeBankEntries(orgID, uuid.Nil, "", "") {
+		if seen[e.EmployeeID] {
+			continue
+		}
+		seen[e.EmployeeID] = true
+		result = append(result, s.Balance(e.EmployeeID))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].EmployeeID.String() < result[j].EmployeeID.String() })
+	return result
+}
+
+// CheckSpend 校验员工提交的请假记录使用的调休：只有全天请假可使用调休，每天不超过 24 小时，
+// 合计不超过可用余额（同一天已有的请假记录会被替换，其使用的调休先退回）
+func (s *Service) CheckSpend(employeeID uuid.UUID, items []*model.EmployeeAvailability) error {
+	requested := 0.0
+	dates := make(map[string]bool, len(items))
+	for _, av := range items {
+		if av.TimeBankHours == 0 {
+			continue
+		}
+		if av.TimeBankHours < 0 || av.TimeBankHours > maxDailyHours {
+			return fmt.Errorf("%w: %s 使用的调休小时数应在 0~%d 之间", ErrInvalidEntry, av.Date, maxDailyHours)
+		}
+		if !av.IsLeave() {
+			return fmt.Errorf("%w: %s 只有全天请假可以使用调休", ErrInvalidEntry, av.Date)
+		}
+		requested += av.TimeBankHours
+		dates[av.Date] = true
+	}
+	if requested == 0 {
+		return nil
+	}
+
+	balance := s.Balance(employeeID)
+	available := balance.Available
+	for _, av := range s.store.ListAvailability(employeeID, "", "") {
+		if dates[av.Date] {
+			used, pending := Spend(av)
+			available += used + pending
+		}
+	}
+	if requested > available+1e-9 {
+		return fmt.Errorf("%w: 本次使用 %.2f 小时，可用余额 %.2f 小时", ErrInsufficientBalance, requested, round2(available))
+	}
+	return nil
+}
+
+// Spend 返回请假记录使用的调休：已批准部分和待审核部分
+func Spend(av *model.EmployeeAvailability) (used, pending float64) {
+	if av.TimeBankHours <= 0 || !av.IsLeave() {
+		return 0, 0
+	}
+	switch av.ReviewStatus {
+	case "", model.LeaveApproved:
+		return av.TimeBankHours, 0
+	case model.LeavePendingReview:
+		return 0, av.TimeBankHours
+	}
+	return 0, 0
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
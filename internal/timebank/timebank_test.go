@@ -0,0 +1,91 @@
+package timebank
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func overtime(orgID, employeeID uuid.UUID, date string, hours float64) *model.ApprovalRequest {
+	return &model.ApprovalRequest{
+		BaseModel:  model.NewBaseModel(),
+		OrgID:      orgID,
+		Kind:       model.ApprovalKindOvertime,
+		Title:      "周末加班",
+		EmployeeID: &employeeID,
+		Date:       date,
+		Hours:      hours,
+		Status:     model.ApprovalApproved,
+	}
+}
+
+func TestService_AccrueByPolicy(t *testing.T) {
+	store := memstore.New("")
+	s := NewService(store)
+	orgID, empID := uuid.New(), uuid.New()
+
+	// 未配置策略：全部支付加班费
+	e, err := s.Accrue(overtime(orgID, empID, "2026-03-07", 4))
+	if err != nil || e.PaidHours != 4 || e.Hours != 0 {
+		t.Fatalf("未配置策略应全部支付加班费: %v %+v", err, e)
+	}
+
+	org := &model.Organization{BaseModel: model.NewBaseModel()}
+	org.ID = orgID
+	org.TimeBankPolicy = &model.TimeBankPolicy{BankRatio: 0.5, AccrualRate: 1.5, MaxBalance: 9}
+	store.PutOrganization(org)
+
+	a := overtime(orgID, empID, "2026-03-14", 8)
+	if e, _ = s.Accrue(a); e.PaidHours != 4 || e.BankedHours != 4 || e.Hours != 6 {
+		t.Errorf("一半计入调休并按 1.5 折算: %+v", e)
+	}
+	if e, _ = s.Accrue(a); e != nil {
+		t.Error("同一审批单不应重复结算")
+	}
+	// 余额 6，上限 9：本次 8 小时加班只能折算 3 小时调休（2 小时加班），其余支付加班费
+	if e, _ = s.Accrue(overtime(orgID, empID, "2026-03-21", 8)); e.Hours != 3 || e.BankedHours != 2 || e.PaidHours != 6 {
+		t.Errorf("超出余额上限的部分应支付加班费: %+v", e)
+	}
+	if b := s.Balance(empID); b.Balance != 9 {
+		t.Errorf("余额 = %+v", b)
+	}
+}
+
+func TestService_CheckSpend(t *testing.T) {
+	store := memstore.New("")
+	s := NewService(store)
+	orgID, empID := uuid.New(), uuid.New()
+	if _, err := s.Adjust(orgID, empID, "2026-03-01", 8, "期初余额", "hr"); err != nil {
+		t.Fatal(err)
+	}
+
+	leave := func(date string, hours float64) *model.EmployeeAvailability {
+		return &model.EmployeeAvailability{EmployeeID: empID, Date: date, Type: "unavailable", TimeBankHours: hours}
+	}
+	if err := s.CheckSpend(empID, []*model.EmployeeAvailability{leave("2026-03-10", 8), leave("2026-03-11", 1)}); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("超出余额应被拒绝: %v", err)
+	}
+	partial := &model.EmployeeAvailability{Date: "2026-03-10", Type: "preferred", TimeBankHours: 2}
+	if err := s.CheckSpend(empID, []*model.EmployeeAvailability{partial}); !errors.Is(err, ErrInvalidEntry) {
+		t.Errorf("非全天请假不能使用调休: %v", err)
+	}
+
+	store.PutAvailability(leave("2026-03-10", 6))
+	if b := s.Balance(empID); b.Used != 6 || b.Available != 2 {
+		t.Errorf("已批准请假应扣除余额: %+v", b)
+	}
+	// 替换同一天的请假时先退回原来使用的调休
+	if err := s.CheckSpend(empID, []*model.EmployeeAvailability{leave("2026-03-10", 8)}); err != nil {
+		t.Errorf("替换同一天请假应按退回后的余额校验: %v", err)
+	}
+
+	rejected := leave("2026-03-10", 6)
+	rejected.ReviewStatus = model.LeaveRejected
+	store.PutAvailability(rejected)
+	if b := s.Balance(empID); b.Used != 0 || b.Balance != 8 {
+		t.Errorf("被驳回的请假不应扣除余额: %+v", b)
+	}
+}
@@ -0,0 +1,6 @@
+-- PaiBan 排班引擎 - 删除技能组
+-- Migration: 003_skill_groups (DOWN)
+-- ====================================
+
+ALTER TABLE service_orders DROP COLUMN IF EXISTS skill_groups;
+ALTER TABLE shift_requirements DROP COLUMN IF EXISTS skill_groups;
@@ -0,0 +1,8 @@
+-- PaiBan 排班引擎 - 技能组（OR 语义）
+-- Migration: 003_skill_groups
+-- ====================================
+
+-- skill_groups: [{"skills": ["cook", "grill"], "min_count": 1}, ...]
+-- 组内具备至少 min_count 项即满足，组间及与 skills 之间均为 AND
+ALTER TABLE shift_requirements ADD COLUMN IF NOT EXISTS skill_groups JSONB DEFAULT '[]';
+ALTER TABLE service_orders ADD COLUMN IF NOT EXISTS skill_groups JSONB DEFAULT '[]';
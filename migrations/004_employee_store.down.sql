@@ -0,0 +1,6 @@
+-- PaiBan 排班引擎 - 删除员工所属门店
+-- Migration: 004_employee_store (DOWN)
+-- ====================================
+
+DROP INDEX IF EXISTS idx_employees_store;
+ALTER TABLE employees DROP COLUMN IF EXISTS store_id;
@@ -0,0 +1,7 @@
+-- PaiBan 排班引擎 - 员工所属门店
+-- Migration: 004_employee_store
+-- ====================================
+
+-- 排班网格视图按门店分组时使用
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS store_id VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_employees_store ON employees(org_id, store_id);
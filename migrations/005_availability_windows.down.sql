@@ -0,0 +1,5 @@
+-- PaiBan 排班引擎 - 删除员工可用时间窗口
+-- Migration: 005_availability_windows (DOWN)
+-- ====================================
+
+ALTER TABLE employees DROP COLUMN IF EXISTS availability_windows;
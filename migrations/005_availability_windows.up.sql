@@ -0,0 +1,6 @@
+-- PaiBan 排班引擎 - 员工可用时间窗口
+-- Migration: 005_availability_windows
+-- ====================================
+
+-- 每周重复的可用时间窗口，如 [{"weekdays":[1,2,3,4,5],"start":"09:00","end":"14:00"}]
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS availability_windows JSONB;
@@ -0,0 +1,7 @@
+-- PaiBan 排班引擎 - 删除团队订单字段
+-- Migration: 006_team_orders (DOWN)
+-- ====================================
+
+ALTER TABLE service_orders DROP COLUMN IF EXISTS employee_ids;
+ALTER TABLE service_orders DROP COLUMN IF EXISTS roles;
+ALTER TABLE service_orders DROP COLUMN IF EXISTS required_headcount;
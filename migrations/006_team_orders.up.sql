@@ -0,0 +1,9 @@
+-- PaiBan 排班引擎 - 团队订单（多人同时上门）
+-- Migration: 006_team_orders
+-- ====================================
+
+-- roles: [{"role": "leader", "count": 1, "skills": ["深度保洁"]}, {"role": "cleaner", "count": 2}]
+-- employee_ids: 团队订单的全部成员，employee_id 为带队人
+ALTER TABLE service_orders ADD COLUMN IF NOT EXISTS required_headcount INT NOT NULL DEFAULT 1;
+ALTER TABLE service_orders ADD COLUMN IF NOT EXISTS roles JSONB DEFAULT '[]';
+ALTER TABLE service_orders ADD COLUMN IF NOT EXISTS employee_ids JSONB DEFAULT '[]';
@@ -0,0 +1,18 @@
+-- PaiBan 排班引擎 - 删除生成排班的保存字段
+-- Migration: 007_generated_schedules (DOWN)
+-- ====================================
+
+DROP TABLE IF EXISTS schedule_assignments;
+
+ALTER TABLE schedules ADD CONSTRAINT schedules_org_id_fkey FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE schedules DROP COLUMN IF EXISTS metadata;
+ALTER TABLE schedules DROP COLUMN IF EXISTS generated_by;
+ALTER TABLE schedules DROP COLUMN IF EXISTS generated_at;
+ALTER TABLE schedules DROP COLUMN IF EXISTS soft_score;
+ALTER TABLE schedules DROP COLUMN IF EXISTS feasible;
+ALTER TABLE schedules DROP COLUMN IF EXISTS fill_rate;
+ALTER TABLE schedules DROP COLUMN IF EXISTS filled_slots;
+ALTER TABLE schedules DROP COLUMN IF EXISTS total_slots;
+ALTER TABLE schedules DROP COLUMN IF EXISTS scenario;
+ALTER TABLE schedules ALTER COLUMN name DROP DEFAULT;
@@ -0,0 +1,38 @@
+-- PaiBan 排班引擎 - 保存生成的排班
+-- Migration: 007_generated_schedules
+-- ====================================
+
+-- 生成结果的汇总字段（ScheduleRepository 读写）
+ALTER TABLE schedules ALTER COLUMN name SET DEFAULT '';
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS scenario VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS total_slots INT NOT NULL DEFAULT 0;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS filled_slots INT NOT NULL DEFAULT 0;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS fill_rate DECIMAL(6,2) NOT NULL DEFAULT 0;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS feasible BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS soft_score DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS generated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS generated_by VARCHAR(50) NOT NULL DEFAULT 'system';
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS metadata JSONB DEFAULT '{}';
+
+-- 生成请求中的组织、员工和班次不要求已录入数据库
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS schedules_org_id_fkey;
+
+-- 生成的排班分配（保存员工、班次名称快照）
+CREATE TABLE IF NOT EXISTS schedule_assignments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL,
+    employee_name VARCHAR(100) NOT NULL DEFAULT '',
+    shift_id UUID NOT NULL,
+    shift_name VARCHAR(100) NOT NULL DEFAULT '',
+    date DATE NOT NULL,
+    start_time VARCHAR(5) NOT NULL,
+    end_time VARCHAR(5) NOT NULL,
+    position VARCHAR(100) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'assigned' CHECK (status IN ('assigned', 'confirmed', 'cancelled')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_assignments_schedule ON schedule_assignments(schedule_id, date);
+CREATE INDEX IF NOT EXISTS idx_schedule_assignments_employee ON schedule_assignments(employee_id, date);
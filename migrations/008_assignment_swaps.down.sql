@@ -0,0 +1,6 @@
+-- PaiBan 排班引擎 - 删除已保存排班的换班记录
+-- Migration: 008_assignment_swaps (DOWN)
+-- ====================================
+
+ALTER TABLE schedule_assignments DROP COLUMN IF EXISTS original_employee_id;
+ALTER TABLE schedule_assignments DROP COLUMN IF EXISTS is_swapped;
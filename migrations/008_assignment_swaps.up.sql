@@ -0,0 +1,7 @@
+-- PaiBan 排班引擎 - 已保存排班的换班记录
+-- Migration: 008_assignment_swaps
+-- ====================================
+
+-- 换班后的分配标记为已换班，并记录换班前的员工
+ALTER TABLE schedule_assignments ADD COLUMN IF NOT EXISTS is_swapped BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE schedule_assignments ADD COLUMN IF NOT EXISTS original_employee_id UUID;
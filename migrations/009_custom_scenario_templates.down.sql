@@ -0,0 +1,8 @@
+-- PaiBan 排班引擎 - 删除自定义场景模板
+-- Migration: 009_custom_scenario_templates (DOWN)
+-- ====================================
+
+DROP INDEX IF EXISTS idx_templates_custom_scenario;
+DELETE FROM scenario_templates WHERE scenario NOT IN ('restaurant', 'factory', 'housekeeping', 'nursing');
+ALTER TABLE scenario_templates ADD CONSTRAINT scenario_templates_scenario_check
+    CHECK (scenario IN ('restaurant', 'factory', 'housekeeping', 'nursing'));
@@ -0,0 +1,9 @@
+-- PaiBan 排班引擎 - 自定义场景模板
+-- Migration: 009_custom_scenario_templates
+-- ====================================
+
+-- 自定义场景模板使用任意场景标识（如 my_custom_template），不再限定为内置场景
+ALTER TABLE scenario_templates DROP CONSTRAINT IF EXISTS scenario_templates_scenario_check;
+
+-- 自定义场景模板的场景标识唯一，排班请求按场景标识解析模板
+CREATE UNIQUE INDEX IF NOT EXISTS idx_templates_custom_scenario ON scenario_templates(scenario) WHERE is_default = false;
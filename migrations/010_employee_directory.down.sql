@@ -0,0 +1,7 @@
+-- PaiBan 排班引擎 - 删除员工档案管理的表结构调整
+-- Migration: 010_employee_directory (DOWN)
+-- ====================================
+
+DROP INDEX IF EXISTS idx_employees_org_code;
+ALTER TABLE employees ADD CONSTRAINT employees_org_id_code_key UNIQUE (org_id, code);
+ALTER TABLE employees ADD CONSTRAINT employees_org_id_fkey FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE;
@@ -0,0 +1,10 @@
+-- PaiBan 排班引擎 - 员工档案管理
+-- Migration: 010_employee_directory
+-- ====================================
+
+-- 员工通过 /api/v1/employees 维护，组织不要求已录入数据库
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_org_id_fkey;
+
+-- 工号只在未删除的员工中唯一，软删除后可重新使用
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS employees_org_id_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employees_org_code ON employees(org_id, code) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- PaiBan 排班引擎 - 删除班次定义管理的表结构调整
+-- Migration: 011_shift_definitions (DOWN)
+-- ====================================
+
+DROP INDEX IF EXISTS idx_shifts_org_code;
+ALTER TABLE shifts ADD CONSTRAINT shifts_org_id_code_key UNIQUE (org_id, code);
+ALTER TABLE shifts ADD CONSTRAINT shifts_org_id_fkey FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE;
@@ -0,0 +1,10 @@
+-- PaiBan 排班引擎 - 班次定义管理
+-- Migration: 011_shift_definitions
+-- ====================================
+
+-- 班次通过 /api/v1/shifts 维护，组织不要求已录入数据库
+ALTER TABLE shifts DROP CONSTRAINT IF EXISTS shifts_org_id_fkey;
+
+-- 班次编码只在未删除的班次中唯一，软删除后可重新使用
+ALTER TABLE shifts DROP CONSTRAINT IF EXISTS shifts_org_id_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_org_code ON shifts(org_id, code) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- PaiBan 排班引擎 - 删除排班需求管理的表结构调整
+-- Migration: 012_requirement_management (DOWN)
+-- ====================================
+
+DROP INDEX IF EXISTS idx_shift_requirements_org_date;
+ALTER TABLE shift_requirements DROP COLUMN IF EXISTS store_id;
+ALTER TABLE shift_requirements ADD CONSTRAINT shift_requirements_org_id_fkey FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE;
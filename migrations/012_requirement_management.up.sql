@@ -0,0 +1,11 @@
+-- PaiBan 排班引擎 - 排班需求管理
+-- Migration: 012_requirement_management
+-- ====================================
+
+-- 需求通过 /api/v1/requirements 维护，组织不要求已录入数据库（班次须为已保存的班次定义）
+ALTER TABLE shift_requirements DROP CONSTRAINT IF EXISTS shift_requirements_org_id_fkey;
+
+-- 所属门店（按门店营业时间校验）
+ALTER TABLE shift_requirements ADD COLUMN IF NOT EXISTS store_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_shift_requirements_org_date ON shift_requirements(org_id, date);
@@ -0,0 +1,5 @@
+-- PaiBan 排班引擎 - 删除审计日志
+-- Migration: 013_audit_logs (DOWN)
+-- ====================================
+
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,5 @@
+-- PaiBan 排班引擎 - 删除班组
+-- Migration: 014_teams (DOWN)
+-- ====================================
+
+DROP TABLE IF EXISTS teams;
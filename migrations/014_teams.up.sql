@@ -0,0 +1,14 @@
+-- PaiBan 排班引擎 - 班组
+-- Migration: 014_teams
+-- ====================================
+
+-- members: 成员员工ID数组；cohesion: preferred（尽量同一班次）/required（同一天上班必须同一班次）
+CREATE TABLE IF NOT EXISTS teams (
+    org_id UUID NOT NULL,
+    id VARCHAR(64) NOT NULL,
+    name VARCHAR(128) NOT NULL DEFAULT '',
+    members JSONB NOT NULL DEFAULT '[]',
+    cohesion VARCHAR(16) NOT NULL DEFAULT 'preferred',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, id)
+);
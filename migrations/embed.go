@@ -0,0 +1,9 @@
+// Package migrations 内嵌数据库迁移脚本，文件名格式为 {版本}_{名称}.up.sql / .down.sql
+package migrations
+
+import "embed"
+
+// FS 迁移脚本
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,302 @@
+// Package alias 提供技能/岗位/资质标签的多语言别名归一化
+package alias
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 标签来源
+const (
+	SourceEmployee    = "employee"
+	SourceRequirement = "requirement"
+	SourceOrder       = "order"
+	SourceAssignment  = "assignment"
+)
+
+// Normalizer 标签归一化器
+// 标签按去除首尾空白、不区分大小写的方式与规范编码及别名比较；
+// 未配置某类别名时，该类标签仅做空白处理，不报告未映射
+type Normalizer struct {
+	orgID  uuid.UUID
+	lookup map[string]map[string]string // 类型 -> 归一化键 -> 规范编码
+
+	unmapped map[string]*model.UnmappedLabel
+	owned    map[string]map[string]bool // 类型 -> 员工具备的规范标签
+	demanded map[string]map[string]string
+}
+
+// NewNormalizer 由组织的别名映射创建归一化器
+func NewNormalizer(orgID uuid.UUID, aliases []*model.LabelAlias) *Normalizer {
+	n := &Normalizer{
+		orgID:    orgID,
+		lookup:   make(map[string]map[string]string),
+		unmapped: make(map[string]*model.UnmappedLabel),
+		owned:    make(map[string]map[string]bool),
+		demanded: make(map[string]map[string]string),
+	}
+	for _, a := range aliases {
+		if a == nil || strings.TrimSpace(a.Canonical) == "" {
+			continue
+		}
+		m, ok := n.lookup[a.Kind]
+		if !ok {
+			m = make(map[string]string)
+			n.lookup[a.Kind] = m
+		}
+		canonical := strings.TrimSpace(a.Canonical)
+		m[key(canonical)] = canonical
+		for _, label := range a.Aliases {
+			if k := key(label); k != "" {
+				m[k] = canonical
+			}
+		}
+	}
+	return n
+}
+
+// Validate 检查别名映射：类型有效、规范编码非空、同一类型下别名不能指向不同规范编码
+func Validate(aliases []*model.LabelAlias) error {
+	seen := make(map[string]string)
+	for _, a := range aliases {
+		switch a.Kind {
+		case model.AliasKindSkill, model.AliasKindPosition, model.AliasKindCertification:
+		default:
+			return fmt.Errorf("无效的别名类型: %s", a.Kind)
+		}
+		canonical := strings.TrimSpace(a.Canonical)
+		if canonical == "" {
+			return fmt.Errorf("规范编码不能为空")
+		}
+		for _, label := range append([]string{canonical}, a.Aliases...) {
+			k := a.Kind + "/" + key(label)
+			if prev, ok := seen[k]; ok && prev != canonical {
+				return fmt.Errorf("%s 标签 %q 同时映射到 %s 和 %s", a.Kind, label, prev, canonical)
+			}
+			seen[k] = canonical
+		}
+	}
+	return nil
+}
+
+// Normalize 返回标签的规范编码，mapped 表示标签命中了别名映射
+func (n *Normalizer) Normalize(kind, label string) (canonical string, mapped bool) {
+	label = strings.TrimSpace(label)
+	if canonical, ok := n.lookup[kind][key(label)]; ok {
+		return canonical, true
+	}
+	return label, false
+}
+
+// Label 归一化单个标签，未映射时记录
+func (n *Normalizer) Label(kind, label, source string) string {
+	if strings.TrimSpace(label) == "" {
+		return label
+	}
+	canonical, mapped := n.Normalize(kind, label)
+	if !mapped && len(n.lookup[kind]) > 0 {
+		n.record(kind, canonical, model.UnmappedNoAlias, source)
+	}
+	return canonical
+}
+
+// Labels 归一化标签列表（去重并保持顺序）
+func (n *Normalizer) Labels(kind string, labels []string, source string) []string {
+	if len(labels) == 0 {
+		return labels
+	}
+	result := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		canonical := n.Label(kind, label, source)
+		if canonical != "" && !seen[canonical] {
+			seen[canonical] = true
+			result = append(result, canonical)
+		}
+	}
+	return result
+}
+
+// Employee 归一化员工的岗位、技能和资质
+func (n *Normalizer) Employee(emp *model.Employee) {
+	if emp == nil {
+		return
+	}
+	emp.Position = n.Label(model.AliasKindPosition, emp.Position, SourceEmployee)
+	emp.Skills = n.Labels(model.AliasKindSkill, emp.Skills, SourceEmployee)
+	emp.Certifications = n.Labels(model.AliasKindCertification, emp.Certifications, SourceEmployee)
+	if len(emp.VerifiedCertifications) > 0 {
+		verified := make([]model.VerifiedCertification, len(emp.VerifiedCertifications))
+		for i, cert := range emp.VerifiedCertifications {
+			cert.Name = n.Label(model.AliasKindCertification, cert.Name, SourceEmployee)
+			verified[i] = cert
+		}
+		emp.VerifiedCertifications = verified
+	}
+	emp.SkillLevels = n.skillLevels(emp.SkillLevels, SourceEmployee)
+	if len(emp.CertificationRecords) > 0 {
+		records := make([]model.Certification, len(emp.CertificationRecords))
+		for i, cert := range emp.CertificationRecords {
+			cert.Name = n.Label(model.AliasKindCertification, cert.Name, SourceEmployee)
+			records[i] = cert
+		}
+		emp.CertificationRecords = records
+	}
+
+	n.own(model.AliasKindPosition, emp.Position)
+	for _, skill := range emp.Skills {
+		n.own(model.AliasKindSkill, skill)
+	}
+	for _, skill := range emp.SkillLevels {
+		n.own(model.AliasKindSkill, skill.Name)
+	}
+	for _, cert := range emp.Certifications {
+		n.own(model.AliasKindCertification, cert)
+	}
+	for _, cert := range emp.CertificationRecords {
+		n.own(model.AliasKindCertification, cert.Name)
+	}
+}
+
+// Requirement 归一化排班需求的岗位和技能
+func (n *Normalizer) Requirement(req *model.ShiftRequirement) {
+	if req == nil {
+		return
+	}
+	req.Position = n.Label(model.AliasKindPosition, req.Position, SourceRequirement)
+	req.Skills = n.Labels(model.AliasKindSkill, req.Skills, SourceRequirement)
+	req.SkillGroups = n.skillGroups(req.SkillGroups, SourceRequirement)
+	req.SkillLevels = n.skillLevels(req.SkillLevels, SourceRequirement)
+
+	n.demand(model.AliasKindPosition, req.Position, SourceRequirement)
+	for _, skill := range req.Skills {
+		n.demand(model.AliasKindSkill, skill, SourceRequirement)
+	}
+	for _, skill := range req.SkillLevels {
+		n.demand(model.AliasKindSkill, skill.Name, SourceRequirement)
+	}
+}
+
+// Order 归一化服务订单的技能要求
+func (n *Normalizer) Order(order *model.ServiceOrder) {
+	if order == nil {
+		return
+	}
+	order.Skills = n.Labels(model.AliasKindSkill, order.Skills, SourceOrder)
+	order.SkillGroups = n.skillGroups(order.SkillGroups, SourceOrder)
+	for _, skill := range order.Skills {
+		n.demand(model.AliasKindSkill, skill, SourceOrder)
+	}
+	if len(order.Roles) > 0 {
+		roles := make([]model.OrderRole, len(order.Roles))
+		for i, r := range order.Roles {
+			r.Skills = n.Labels(model.AliasKindSkill, r.Skills, SourceOrder)
+			for _, skill := range r.Skills {
+				n.demand(model.AliasKindSkill, skill, SourceOrder)
+			}
+			roles[i] = r
+		}
+		order.Roles = roles
+	}
+}
+
+// skillGroups 归一化技能组（返回新切片，不修改调用方共享的数据）
+func (n *Normalizer) skillGroups(groups []model.SkillGroup, source string) []model.SkillGroup {
+	if len(groups) == 0 {
+		return groups
+	}
+	result := make([]model.SkillGroup, len(groups))
+	for i, g := range groups {
+		result[i] = model.SkillGroup{
+			Skills:   n.Labels(model.AliasKindSkill, g.Skills, source),
+			MinCount: g.MinCount,
+		}
+	}
+	return result
+}
+
+// skillLevels 归一化技能等级中的技能名称
+func (n *Normalizer) skillLevels(levels []model.Skill, source string) []model.Skill {
+	if len(levels) == 0 {
+		return levels
+	}
+	result := make([]model.Skill, len(levels))
+	for i, l := range levels {
+		result[i] = model.Skill{Name: n.Label(model.AliasKindSkill, l.Name, source), Level: l.Level}
+	}
+	return result
+}
+
+// Unmapped 返回未映射的标签，以及需求要求但没有任何员工具备的标签
+// 需在归一化完所有员工和需求后调用
+func (n *Normalizer) Unmapped() []model.UnmappedLabel {
+	for kind, labels := range n.demanded {
+		for label, source := range labels {
+			if !n.owned[kind][label] {
+				n.record(kind, label, model.UnmappedNoMatch, source)
+			}
+		}
+	}
+	n.demanded = make(map[string]map[string]string)
+
+	result := make([]model.UnmappedLabel, 0, len(n.unmapped))
+	for _, u := range n.unmapped {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind < result[j].Kind
+		}
+		return result[i].Label < result[j].Label
+	})
+	return result
+}
+
+func (n *Normalizer) record(kind, label, reason, source string) {
+	k := kind + "/" + reason + "/" + label
+	if u, ok := n.unmapped[k]; ok {
+		u.Count++
+		return
+	}
+	now := time.Now()
+	n.unmapped[k] = &model.UnmappedLabel{
+		OrgID:     n.orgID,
+		Kind:      kind,
+		Label:     label,
+		Reason:    reason,
+		Source:    source,
+		Count:     1,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+}
+
+func (n *Normalizer) own(kind, label string) {
+	if label == "" {
+		return
+	}
+	if n.owned[kind] == nil {
+		n.owned[kind] = make(map[string]bool)
+	}
+	n.owned[kind][label] = true
+}
+
+func (n *Normalizer) demand(kind, label, source string) {
+	if label == "" {
+		return
+	}
+	if n.demanded[kind] == nil {
+		n.demanded[kind] = make(map[string]string)
+	}
+	n.demanded[kind][label] = source
+}
+
+// key 返回标签的比较键
+func key(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
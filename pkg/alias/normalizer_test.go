@@ -0,0 +1,77 @@
+package alias
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func testAliases() []*model.LabelAlias {
+	return []*model.LabelAlias{
+		{Kind: model.AliasKindPosition, Canonical: "cook", Aliases: []string{"厨师", "Chef"}},
+		{Kind: model.AliasKindSkill, Canonical: "grill", Aliases: []string{"烧烤"}},
+	}
+}
+
+func TestNormalizer_EmployeeAndRequirement(t *testing.T) {
+	n := NewNormalizer(uuid.Nil, testAliases())
+
+	emp := &model.Employee{Position: " 厨师 ", Skills: []string{"烧烤", "GRILL", "刀工"}}
+	n.Employee(emp)
+	if emp.Position != "cook" {
+		t.Errorf("position = %q, expected cook", emp.Position)
+	}
+	if len(emp.Skills) != 2 || emp.Skills[0] != "grill" || emp.Skills[1] != "刀工" {
+		t.Errorf("skills = %v, expected [grill 刀工]", emp.Skills)
+	}
+
+	req := &model.ShiftRequirement{
+		Position:    "chef",
+		Skills:      []string{"烧烤"},
+		SkillGroups: []model.SkillGroup{{Skills: []string{"Grill", "bake"}}},
+	}
+	n.Requirement(req)
+	if req.Position != "cook" || req.Skills[0] != "grill" || req.SkillGroups[0].Skills[0] != "grill" {
+		t.Errorf("requirement = %+v", req)
+	}
+	if !emp.MeetsSkillRequirements(req.Skills, nil) {
+		t.Error("归一化后员工应满足技能要求")
+	}
+
+	unmapped := n.Unmapped()
+	reasons := make(map[string]string)
+	for _, u := range unmapped {
+		reasons[u.Label] = u.Reason
+	}
+	if reasons["刀工"] != model.UnmappedNoAlias || reasons["bake"] != model.UnmappedNoAlias {
+		t.Errorf("未配置别名的技能应报告为 no_alias, got %+v", unmapped)
+	}
+	if _, ok := reasons["grill"]; ok {
+		t.Error("已映射且有人具备的技能不应报告")
+	}
+}
+
+func TestNormalizer_NoMatchWithoutAliases(t *testing.T) {
+	n := NewNormalizer(uuid.Nil, nil)
+	n.Employee(&model.Employee{Position: "cook"})
+	n.Requirement(&model.ShiftRequirement{Position: "厨师"})
+
+	unmapped := n.Unmapped()
+	if len(unmapped) != 1 || unmapped[0].Label != "厨师" || unmapped[0].Reason != model.UnmappedNoMatch {
+		t.Errorf("无人具备的岗位应报告为 no_match, got %+v", unmapped)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(testAliases()); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	conflicting := append(testAliases(), &model.LabelAlias{Kind: model.AliasKindPosition, Canonical: "kitchen", Aliases: []string{"厨师"}})
+	if err := Validate(conflicting); err == nil {
+		t.Error("同一别名映射到两个规范编码应报错")
+	}
+	if err := Validate([]*model.LabelAlias{{Kind: "team", Canonical: "a"}}); err == nil {
+		t.Error("无效类型应报错")
+	}
+}
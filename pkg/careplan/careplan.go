@@ -3,6 +3,7 @@ package careplan
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -75,72 +76,59 @@ func (pm *PlanManager) CreatePlan(customerID uuid.UUID, level int, startDate str
 	return plan, nil
 }
 
-// GenerateServiceOrders 根据护理计划生成服务订单
-func (pm *PlanManager) GenerateServiceOrders(plan *model.CarePlan, customer *model.Customer, startDate, endDate string) ([]*model.ServiceOrder, error) {
-	if plan == nil || plan.Status != "active" {
-		return nil, fmt.Errorf("护理计划无效或已过期")
+// Complete 按护理等级补全计划中未填写的周服务时长、服务项目和服务频率，并生成计划编号；
+// 计划不完整或无效时返回错误
+func (pm *PlanManager) Complete(plan *model.CarePlan) error {
+	if plan.CustomerID == uuid.Nil {
+		return fmt.Errorf("客户ID不能为空")
 	}
-
-	start, err := time.Parse("2006-01-02", startDate)
+	defaults, err := pm.CreatePlan(plan.CustomerID, plan.Level, plan.StartDate)
 	if err != nil {
-		return nil, fmt.Errorf("开始日期格式错误: %v", err)
+		return err
 	}
-
-	end, err := time.Parse("2006-01-02", endDate)
-	if err != nil {
-		return nil, fmt.Errorf("结束日期格式错误: %v", err)
+	if plan.WeeklyHours == 0 {
+		plan.WeeklyHours = defaults.WeeklyHours
+	}
+	if len(plan.ServiceItems) == 0 {
+		plan.ServiceItems = defaults.ServiceItems
+	}
+	if plan.Frequency == "" {
+		plan.Frequency = calculateFrequency(plan.WeeklyHours)
+	}
+	if plan.PlanNo == "" {
+		plan.PlanNo = defaults.PlanNo
+	}
+	if plan.Status == "" {
+		plan.Status = "active"
 	}
 
-	var orders []*model.ServiceOrder
-
-	// 计算每周服务次数
-	sessionsPerWeek := calculateSessionsPerWeek(plan.WeeklyHours)
-	sessionDuration := plan.WeeklyHours * 60 / sessionsPerWeek // 每次服务时长（分钟）
-
-	// 服务日安排（均匀分布在一周内）
-	serviceDays := getServiceDays(sessionsPerWeek)
-
-	for current := start; !current.After(end); current = current.AddDate(0, 0, 1) {
-		weekday := int(current.Weekday())
-
-		// 检查是否是服务日
-		isServiceDay := false
-		for _, day := range serviceDays {
-			if day == weekday {
-				isServiceDay = true
-				break
-			}
-		}
-
-		if !isServiceDay {
-			continue
-		}
-
-		// 创建订单
-		order := &model.ServiceOrder{
-			CustomerID:  plan.CustomerID,
-			OrderNo:     generateOrderNo(current),
-			ServiceType: "nursing",
-			ServiceDate: current.Format("2006-01-02"),
-			StartTime:   "09:00",
-			EndTime:     calculateEndTime("09:00", sessionDuration),
-			Duration:    sessionDuration,
-			Status:      "pending",
-			Priority:    3,
+	errs := pm.ValidatePlan(plan)
+	if plan.StartDate != "" {
+		if _, err := time.Parse("2006-01-02", plan.StartDate); err != nil {
+			errs = append(errs, "开始日期格式应为 YYYY-MM-DD")
 		}
-
-		if customer != nil {
-			order.Address = customer.Address
-			order.Location = customer.Location
+	}
+	if plan.EndDate != "" && plan.EndDate < plan.StartDate {
+		errs = append(errs, "结束日期不能早于开始日期")
+	}
+	if plan.VisitStartTime != "" {
+		if _, err := time.Parse("15:04", plan.VisitStartTime); err != nil {
+			errs = append(errs, "上门时间格式应为 HH:MM")
 		}
-
-		// 设置技能要求
-		order.Skills = pm.getPlanSkillRequirements(plan.Level)
-
-		orders = append(orders, order)
 	}
+	if len(errs) > 0 {
+		return fmt.Errorf("护理计划无效: %s", strings.Join(errs, "；"))
+	}
+	return nil
+}
 
-	return orders, nil
+// GenerateServiceOrders 根据护理计划生成服务订单（见 Expand）
+func (pm *PlanManager) GenerateServiceOrders(plan *model.CarePlan, customer *model.Customer, startDate, endDate string) ([]*model.ServiceOrder, error) {
+	expansion, err := pm.Expand(plan, customer, startDate, endDate, ExpandOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return expansion.Orders, nil
 }
 
 // ValidatePlan 验证护理计划
@@ -286,6 +274,12 @@ func getServiceDays(sessionsPerWeek int) []int {
 		return []int{2, 5} // 周二、周五
 	case 3:
 		return []int{1, 3, 5} // 周一、周三、周五
+	case 4:
+		return []int{1, 2, 4, 5} // 周一、周二、周四、周五
+	case 6:
+		return []int{1, 2, 3, 4, 5, 6} // 周一至周六
+	case 7:
+		return []int{1, 2, 3, 4, 5, 6, 0} // 每天
 	default:
 		return []int{1, 2, 3, 4, 5} // 工作日
 	}
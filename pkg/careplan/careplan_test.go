@@ -187,3 +187,95 @@ func TestPlanManager_GetRecommendedCarers_NoCert(t *testing.T) {
 		t.Error("无护理员证不应被推荐")
 	}
 }
+
+func TestPlanManager_Expand(t *testing.T) {
+	manager := NewPlanManager()
+
+	plan := &model.CarePlan{
+		CustomerID:  uuid.New(),
+		PlanNo:      "CP002",
+		Level:       3,
+		StartDate:   "2026-01-14",
+		EndDate:     "2026-01-31",
+		WeeklyHours: 4,
+		ServiceItems: []model.CareItem{
+			{Code: "bath", Name: "助浴", Duration: 60, Frequency: 1, RequiresCert: "助浴培训"},
+			{Code: "care", Name: "基础护理", Duration: 40, Frequency: 3},
+			{Code: "check", Name: "健康监测", Duration: 20, Frequency: 2},
+		},
+		Frequency:      "3_times_per_week",
+		Status:         "active",
+		Address:        "计划地址",
+		VisitStartTime: "14:00",
+	}
+
+	// 2026-01-12（周一）至 01-18：计划 01-14 起生效，只有周三、周五两次上门
+	exp, err := manager.Expand(plan, &model.Customer{Address: "客户地址"}, "2026-01-12", "2026-01-18", ExpandOptions{})
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if exp.SessionsPerWeek != 3 || exp.StartDate != "2026-01-14" {
+		t.Errorf("sessions = %d, start = %s", exp.SessionsPerWeek, exp.StartDate)
+	}
+	// 每周合计 60 + 40×3 + 20×2 = 220 分钟，未超过计划的 4 小时
+	if exp.WeeklyMinutes != 220 || len(exp.Warnings) != 0 {
+		t.Errorf("weekly = %d, warnings = %v", exp.WeeklyMinutes, exp.Warnings)
+	}
+	// 各次上门时长均衡：60+40、40+20、40+20
+	for _, s := range exp.WeeklySessions {
+		if s.Duration < 60 || s.Duration > 100 {
+			t.Errorf("上门安排不均衡: %+v", exp.WeeklySessions)
+			break
+		}
+	}
+	if len(exp.Orders) != 2 {
+		t.Fatalf("orders = %d, want 2", len(exp.Orders))
+	}
+	first := exp.Orders[0]
+	if first.OrderNo != "CP002-20260114" || first.ServiceDate != "2026-01-14" || first.StartTime != "14:00" || first.Address != "计划地址" {
+		t.Errorf("第一单 = %+v", first)
+	}
+	if first.EndTime != calculateEndTime("14:00", first.Duration) || exp.TotalMinutes != exp.Orders[0].Duration+exp.Orders[1].Duration {
+		t.Errorf("时长 = %+v, total = %d", first, exp.TotalMinutes)
+	}
+
+	// 包含助浴的上门需要助浴资质
+	bath := 0
+	for _, s := range exp.WeeklySessions {
+		for _, item := range s.Items {
+			if item.Code == "bath" {
+				bath++
+			}
+		}
+	}
+	if bath != 1 {
+		t.Errorf("助浴每周 %d 次, want 1", bath)
+	}
+
+	// 超出计划时长给出提示；上门时间可在请求中指定
+	plan.WeeklyHours = 3
+	exp, _ = manager.Expand(plan, nil, "2026-01-19", "2026-01-25", ExpandOptions{StartTime: "08:30"})
+	if len(exp.Orders) != 3 || exp.Orders[0].StartTime != "08:30" || len(exp.Warnings) != 1 {
+		t.Errorf("orders = %d, warnings = %v", len(exp.Orders), exp.Warnings)
+	}
+
+	// 不在有效期内
+	exp, err = manager.Expand(plan, nil, "2026-02-02", "2026-02-08", ExpandOptions{})
+	if err != nil || len(exp.Orders) != 0 || len(exp.Warnings) == 0 {
+		t.Errorf("有效期外: %+v, %v", exp, err)
+	}
+
+	for _, c := range [][2]string{{"2026-01-20", "2026-01-19"}, {"2026-01-01", "2027-01-10"}, {"2026/01/01", "2026-01-02"}} {
+		if _, err := manager.Expand(plan, nil, c[0], c[1], ExpandOptions{}); err == nil {
+			t.Errorf("Expand(%s, %s) 应返回错误", c[0], c[1])
+		}
+	}
+}
+
+func TestParseFrequency(t *testing.T) {
+	for s, want := range map[string]int{"weekly": 1, "twice_weekly": 2, "daily": 7, "5_times_per_week": 5, "1_time_per_week": 1, "often": 0} {
+		if got, _ := parseFrequency(s); got != want {
+			t.Errorf("parseFrequency(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
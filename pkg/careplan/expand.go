@@ -0,0 +1,271 @@
+package careplan
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// MaxExpandDays 单次展开的最大天数
+const MaxExpandDays = 366
+
+// DefaultVisitStartTime 未指定上门时间时的开始时间
+const DefaultVisitStartTime = "09:00"
+
+// ExpandOptions 护理计划展开选项
+type ExpandOptions struct {
+	StartTime string // 上门开始时间（HH:MM），为空时取计划的 visit_start_time，再为空时为 09:00
+}
+
+// Session 一周内的一次上门服务
+type Session struct {
+	Weekday  int              `json:"weekday"` // 0 为周日
+	Items    []model.CareItem `json:"items"`
+	Duration int              `json:"duration"` // 分钟
+}
+
+// Expansion 护理计划在一段时间内展开的服务订单
+type Expansion struct {
+	PlanNo               string                `json:"plan_no,omitempty"`
+	StartDate            string                `json:"start_date"` // 实际展开的起止日期（与计划有效期取交集）
+	EndDate              string                `json:"end_date"`
+	SessionsPerWeek      int                   `json:"sessions_per_week"`
+	WeeklySessions       []Session             `json:"weekly_sessions"`        // 每周的上门安排
+	WeeklyMinutes        int                   `json:"weekly_minutes"`         // 每周服务时长合计
+	PlannedWeeklyMinutes int                   `json:"planned_weekly_minutes"` // 计划的每周服务时长
+	TotalMinutes         int                   `json:"total_minutes"`
+	Orders               []*model.ServiceOrder `json:"orders"`
+	Warnings             []string              `json:"warnings,omitempty"`
+}
+
+// Expand 将护理计划展开为 [startDate, endDate] 内的服务订单
+// 每周上门次数取计划频率与服务项目最高频率中的较大者，上门日均匀分布在一周内；
+// 每个服务项目按每周次数均匀分配到各次上门中（尽量使各次时长均衡），每次上门的时长为所含项目时长之和，
+// 没有服务项目时按每周服务时长平均分配。订单号为"计划编号-日期"，重复生成同一时段时订单号不变
+func (pm *PlanManager) Expand(plan *model.CarePlan, customer *model.Customer, startDate, endDate string, opts ExpandOptions) (*Expansion, error) {
+	if plan == nil || plan.Status != "active" {
+		return nil, fmt.Errorf("护理计划无效或已过期")
+	}
+
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("开始日期格式错误: %v", err)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("结束日期格式错误: %v", err)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("结束日期不能早于开始日期")
+	}
+	if end.Sub(start).Hours()/24 >= MaxExpandDays {
+		return nil, fmt.Errorf("单次最多生成 %d 天的订单", MaxExpandDays)
+	}
+	// 只在计划有效期内生成
+	if d, err := time.Parse("2006-01-02", plan.StartDate); err == nil && d.After(start) {
+		start = d
+	}
+	if d, err := time.Parse("2006-01-02", plan.EndDate); err == nil && d.Before(end) {
+		end = d
+	}
+
+	startTime := opts.StartTime
+	if startTime == "" {
+		startTime = plan.VisitStartTime
+	}
+	if startTime == "" {
+		startTime = DefaultVisitStartTime
+	}
+	if _, err := time.Parse("15:04", startTime); err != nil {
+		return nil, fmt.Errorf("上门时间格式应为 HH:MM: %s", startTime)
+	}
+
+	result := &Expansion{
+		PlanNo:               plan.PlanNo,
+		StartDate:            start.Format("2006-01-02"),
+		EndDate:              end.Format("2006-01-02"),
+		PlannedWeeklyMinutes: plan.WeeklyHours * 60,
+		Orders:               make([]*model.ServiceOrder, 0),
+	}
+	if end.Before(start) {
+		result.StartDate, result.EndDate = startDate, endDate
+		result.Warnings = append(result.Warnings, "所选时段不在护理计划有效期内")
+		return result, nil
+	}
+
+	sessions, warnings := pm.weeklySessions(plan)
+	result.SessionsPerWeek = len(sessions)
+	result.WeeklySessions = sessions
+	result.Warnings = append(result.Warnings, warnings...)
+	byWeekday := make(map[int]Session, len(sessions))
+	for _, session := range sessions {
+		byWeekday[session.Weekday] = session
+		result.WeeklyMinutes += session.Duration
+	}
+	if result.PlannedWeeklyMinutes > 0 && result.WeeklyMinutes > result.PlannedWeeklyMinutes {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("服务项目每周合计 %.1f 小时，超过计划的 %d 小时",
+			float64(result.WeeklyMinutes)/60, plan.WeeklyHours))
+	}
+
+	address, location := plan.Address, plan.Location
+	if address == "" && customer != nil {
+		address = customer.Address
+	}
+	if location == nil && customer != nil {
+		location = customer.Location
+	}
+
+	for current := start; !current.After(end); current = current.AddDate(0, 0, 1) {
+		session, ok := byWeekday[int(current.Weekday())]
+		if !ok || session.Duration <= 0 {
+			continue
+		}
+		order := &model.ServiceOrder{
+			OrgID:       plan.OrgID,
+			CustomerID:  plan.CustomerID,
+			OrderNo:     planOrderNo(plan, current),
+			ServiceType: "nursing",
+			ServiceDate: current.Format("2006-01-02"),
+			StartTime:   startTime,
+			EndTime:     calculateEndTime(startTime, session.Duration),
+			Duration:    session.Duration,
+			Address:     address,
+			Location:    location,
+			Status:      "pending",
+			Priority:    3,
+			Skills:      pm.sessionSkills(plan.Level, session.Items),
+			Notes:       itemNotes(session.Items),
+		}
+		result.Orders = append(result.Orders, order)
+		result.TotalMinutes += session.Duration
+	}
+	return result, nil
+}
+
+// weeklySessions 每周的上门安排
+func (pm *PlanManager) weeklySessions(plan *model.CarePlan) ([]Session, []string) {
+	var warnings []string
+	n, ok := parseFrequency(plan.Frequency)
+	if !ok && plan.Frequency != "" {
+		warnings = append(warnings, fmt.Sprintf("无法识别服务频率 %q，按服务项目和每周时长安排", plan.Frequency))
+	}
+	for _, item := range plan.ServiceItems {
+		if item.Frequency > n {
+			n = item.Frequency
+		}
+	}
+	if n <= 0 {
+		n = calculateSessionsPerWeek(plan.WeeklyHours)
+	}
+	if n > 7 {
+		warnings = append(warnings, fmt.Sprintf("每周服务 %d 次超过 7 天，按每天一次安排", n))
+		n = 7
+	}
+
+	days := getServiceDays(n)
+	sessions := make([]Session, len(days))
+	for i, day := range days {
+		sessions[i].Weekday = day
+	}
+	if len(plan.ServiceItems) == 0 {
+		for i := range sessions {
+			sessions[i].Duration = plan.WeeklyHours * 60 / n
+		}
+		return sessions, warnings
+	}
+
+	for _, item := range plan.ServiceItems {
+		f := item.Frequency
+		if f <= 0 || f > n {
+			f = n // 未指定次数的项目每次上门都提供
+		}
+		// 选择使各次上门时长最均衡的起始位置
+		best, bestMax, bestSum := 0, -1, 0
+		for phase := 0; phase < n; phase++ {
+			maxLoad, sum := 0, 0
+			for j := 0; j < f; j++ {
+				load := sessions[(phase+j*n/f)%n].Duration + item.Duration
+				sum += load
+				if load > maxLoad {
+					maxLoad = load
+				}
+			}
+			if bestMax < 0 || maxLoad < bestMax || (maxLoad == bestMax && sum < bestSum) {
+				best, bestMax, bestSum = phase, maxLoad, sum
+			}
+		}
+		for j := 0; j < f; j++ {
+			s := &sessions[(best+j*n/f)%n]
+			s.Items = append(s.Items, item)
+			s.Duration += item.Duration
+		}
+	}
+	return sessions, warnings
+}
+
+// sessionSkills 上门所需技能：护理等级要求的技能加上服务项目要求的资质
+func (pm *PlanManager) sessionSkills(level int, items []model.CareItem) []string {
+	skills := pm.getPlanSkillRequirements(level)
+	for _, item := range items {
+		if item.RequiresCert == "" {
+			continue
+		}
+		found := false
+		for _, s := range skills {
+			if s == item.RequiresCert {
+				found = true
+				break
+			}
+		}
+		if !found {
+			skills = append(skills, item.RequiresCert)
+		}
+	}
+	return skills
+}
+
+// itemNotes 订单备注中的服务项目
+func itemNotes(items []model.CareItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Name
+	}
+	return "服务项目：" + strings.Join(names, "、")
+}
+
+// planOrderNo 计划生成的订单号，无计划编号时随机生成
+func planOrderNo(plan *model.CarePlan, date time.Time) string {
+	if plan.PlanNo == "" {
+		return generateOrderNo(date)
+	}
+	return plan.PlanNo + "-" + date.Format("20060102")
+}
+
+var timesPerWeek = regexp.MustCompile(`^(\d+)_times?_per_week$`)
+
+// parseFrequency 解析服务频率为每周上门次数
+// 支持 weekly、twice_weekly、three_times_weekly、daily 和 N_times_per_week
+func parseFrequency(frequency string) (int, bool) {
+	switch frequency {
+	case "weekly":
+		return 1, true
+	case "twice_weekly":
+		return 2, true
+	case "three_times_weekly":
+		return 3, true
+	case "daily":
+		return 7, true
+	}
+	if m := timesPerWeek.FindStringSubmatch(frequency); m != nil {
+		n, err := strconv.Atoi(m[1])
+		return n, err == nil && n > 0
+	}
+	return 0, false
+}
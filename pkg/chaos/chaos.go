@@ -0,0 +1,149 @@
+// Package chaos 提供故障注入钩子，用于在测试环境验证服务的容错行为（重试、panic 转 500、作业恢复）
+// 钩子埋在存储读写、约束评估和批量作业执行中，通过管理接口布置故障后触发；
+// 只有使用 -tags chaos 构建时钩子才生效，默认构建中 Enabled 为常量 false，钩子直接返回且无法布置故障
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 注入点
+const (
+	StoreLatency    = "store.latency"    // 存储读写延迟，目标为数据集名称（schedules、employees、shifts）或数据库事务（transaction）
+	ConstraintPanic = "constraint.panic" // 约束评估时 panic，目标为约束类型
+	JobCrash        = "job.crash"        // 批量作业批次执行中工作协程崩溃（panic），目标为作业类型
+)
+
+// maxLatency 单次注入延迟上限
+const maxLatency = time.Minute
+
+var (
+	// ErrDisabled 未使用 -tags chaos 构建
+	ErrDisabled = errors.New("未启用故障注入（需使用 -tags chaos 构建）")
+	// ErrInvalidFault 故障参数无效
+	ErrInvalidFault = errors.New("故障参数无效")
+)
+
+// Fault 布置的故障
+type Fault struct {
+	Point     string    `json:"point"`
+	Target    string    `json:"target,omitempty"`     // 只对该目标生效，空表示该注入点的所有目标
+	LatencyMs int       `json:"latency_ms,omitempty"` // store.latency 的延迟（毫秒）
+	Count     int       `json:"count,omitempty"`      // 最多触发次数，0 表示不限，触发完后自动解除
+	Fired     int       `json:"fired"`                // 已触发次数
+	ArmedAt   time.Time `json:"armed_at"`
+}
+
+// Injected 注入的 panic 值，便于恢复逻辑和测试识别
+type Injected struct {
+	Point  string
+	Target string
+}
+
+func (i *Injected) Error() string {
+	return fmt.Sprintf("故障注入: %s (%s)", i.Point, i.Target)
+}
+
+var (
+	mu     sync.Mutex
+	faults = make(map[string]*Fault)
+)
+
+// Points 返回支持的注入点
+func Points() []string {
+	return []string{StoreLatency, ConstraintPanic, JobCrash}
+}
+
+// Arm 布置故障，同一注入点已有故障时替换
+func Arm(f Fault) (*Fault, error) {
+	if !Enabled {
+		return nil, ErrDisabled
+	}
+	switch f.Point {
+	case StoreLatency:
+		if f.LatencyMs <= 0 || time.Duration(f.LatencyMs)*time.Millisecond > maxLatency {
+			return nil, fmt.Errorf("%w: latency_ms 应在 1~%d 之间", ErrInvalidFault, maxLatency.Milliseconds())
+		}
+	case ConstraintPanic, JobCrash:
+	default:
+		return nil, fmt.Errorf("%w: 不支持的注入点 %q", ErrInvalidFault, f.Point)
+	}
+	if f.Count < 0 {
+		return nil, fmt.Errorf("%w: count 不能为负数", ErrInvalidFault)
+	}
+
+	f.Fired = 0
+	f.ArmedAt = time.Now()
+	mu.Lock()
+	defer mu.Unlock()
+	faults[f.Point] = &f
+	result := f
+	return &result, nil
+}
+
+// Disarm 解除注入点的故障，返回是否存在
+func Disarm(point string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	_, ok := faults[point]
+	delete(faults, point)
+	return ok
+}
+
+// Reset 解除所有故障
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	faults = make(map[string]*Fault)
+}
+
+// List 列出当前布置的故障（按注入点排序）
+func List() []Fault {
+	mu.Lock()
+	defer mu.Unlock()
+	result := make([]Fault, 0, len(faults))
+	for _, f := range faults {
+		result = append(result, *f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Point < result[j].Point })
+	return result
+}
+
+// Delay 注入点布置了延迟故障时休眠
+func Delay(point, target string) {
+	if !Enabled {
+		return
+	}
+	if f, ok := fire(point, target); ok {
+		time.Sleep(time.Duration(f.LatencyMs) * time.Millisecond)
+	}
+}
+
+// Panic 注入点布置了故障时以 *Injected panic
+func Panic(point, target string) {
+	if !Enabled {
+		return
+	}
+	if _, ok := fire(point, target); ok {
+		panic(&Injected{Point: point, Target: target})
+	}
+}
+
+// fire 检查故障是否对目标生效并记录触发，达到触发次数后解除
+func fire(point, target string) (Fault, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	f, ok := faults[point]
+	if !ok || (f.Target != "" && f.Target != target) {
+		return Fault{}, false
+	}
+	f.Fired++
+	if f.Count > 0 && f.Fired >= f.Count {
+		delete(faults, point)
+	}
+	return *f, true
+}
@@ -0,0 +1,41 @@
+package chaos
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArm(t *testing.T) {
+	t.Cleanup(Reset)
+
+	if !Enabled {
+		if _, err := Arm(Fault{Point: ConstraintPanic}); !errors.Is(err, ErrDisabled) {
+			t.Errorf("默认构建不应允许布置故障, err = %v", err)
+		}
+		Panic(ConstraintPanic, "max_hours_per_day")
+		return
+	}
+
+	if _, err := Arm(Fault{Point: StoreLatency}); !errors.Is(err, ErrInvalidFault) {
+		t.Errorf("延迟故障必须指定 latency_ms, err = %v", err)
+	}
+	if _, err := Arm(Fault{Point: "db.drop"}); !errors.Is(err, ErrInvalidFault) {
+		t.Errorf("不支持的注入点应报错, err = %v", err)
+	}
+
+	if _, err := Arm(Fault{Point: ConstraintPanic, Target: "min_rest", Count: 1}); err != nil {
+		t.Fatalf("Arm() error = %v", err)
+	}
+	Panic(ConstraintPanic, "max_hours_per_day") // 目标不匹配，不触发
+	func() {
+		defer func() {
+			if p, ok := recover().(*Injected); !ok || p.Target != "min_rest" {
+				t.Errorf("应注入 panic, got %v", p)
+			}
+		}()
+		Panic(ConstraintPanic, "min_rest")
+	}()
+	if len(List()) != 0 {
+		t.Error("达到触发次数后应自动解除")
+	}
+}
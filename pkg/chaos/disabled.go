@@ -0,0 +1,6 @@
+//go:build !chaos
+
+package chaos
+
+// Enabled 默认构建不启用故障注入，钩子为空操作
+const Enabled = false
@@ -0,0 +1,6 @@
+//go:build chaos
+
+package chaos
+
+// Enabled 使用 -tags chaos 构建时启用故障注入
+const Enabled = true
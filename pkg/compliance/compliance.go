@@ -0,0 +1,224 @@
+// Package compliance 提供按司法辖区的劳动法合规规则包
+// 规则包把法定的工时上限、休息要求、加班上限和未成年工保护转换为约束配置，
+// 排班时以规则包和请求配置中更严格的取值注册硬约束，并按规则汇总合规报告
+package compliance
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// Rule 合规规则：约束配置键及法定取值
+type Rule struct {
+	Key         string      `json:"key"`               // 约束配置键
+	Value       interface{} `json:"value"`             // 取值（小时、天数或时段）
+	Minimum     bool        `json:"minimum,omitempty"` // 取值为下限（如最短休息），否则为上限
+	Constraint  string      `json:"constraint"`        // 对应的硬约束类型
+	Description string      `json:"description"`
+	Basis       string      `json:"basis"` // 法规依据
+}
+
+// Pack 司法辖区的合规规则包
+type Pack struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Rules       []Rule `json:"rules"`
+}
+
+var cn = &Pack{
+	Code:        "CN",
+	Name:        "中国劳动法",
+	Description: "标准工时制：每日 8 小时、每周 40 小时，延长工作时间每日不超过 3 小时、每月不超过 36 小时，每周至少休息一日",
+	Rules: []Rule{
+		{Key: "max_hours_per_day", Value: 11, Constraint: string(constraint.TypeMaxHoursPerDay),
+			Description: "每日工作时间（含延长工作时间）不超过 11 小时", Basis: "《劳动法》第三十六条、第四十一条"},
+		{Key: "max_consecutive_days", Value: 6, Constraint: string(constraint.TypeMaxConsecutiveDays),
+			Description: "每周至少休息一日，连续工作不超过 6 天", Basis: "《劳动法》第三十八条"},
+		{Key: "standard_hours_per_day", Value: 8, Constraint: string(constraint.TypeOvertimeCap),
+			Description: "标准工作日 8 小时，超出部分计为加班", Basis: "《国务院关于职工工作时间的规定》第三条"},
+		{Key: "standard_hours_per_week", Value: 40, Constraint: string(constraint.TypeOvertimeCap),
+			Description: "标准工作周 40 小时，超出部分计为加班", Basis: "《国务院关于职工工作时间的规定》第三条"},
+		{Key: "max_overtime_hours_per_day", Value: 3, Constraint: string(constraint.TypeOvertimeCap),
+			Description: "延长工作时间每日不超过 3 小时", Basis: "《劳动法》第四十一条"},
+		{Key: "max_overtime_hours_per_month", Value: 36, Constraint: string(constraint.TypeOvertimeCap),
+			Description: "延长工作时间每月不超过 36 小时", Basis: "《劳动法》第四十一条"},
+		{Key: "minor_max_hours_per_day", Value: 8, Constraint: string(constraint.TypeMinorProtection),
+			Description: "未成年工（16-18 周岁）不安排延长工作时间，每日不超过 8 小时", Basis: "《劳动法》第五十八条"},
+	},
+}
+
+var packs = []*Pack{
+	cn,
+	extend(cn, "CN-Shanghai", "上海市劳动保护",
+		"在中国劳动法基础上，未成年工每周不超过 40 小时，不安排夜班",
+		Rule{Key: "minor_max_hours_per_week", Value: 40, Constraint: string(constraint.TypeMinorProtection),
+			Description: "未成年工每周工作时间不超过 40 小时", Basis: "《上海市未成年工特殊保护办法》"},
+		Rule{Key: "minor_night_window", Value: "22:00-06:00", Constraint: string(constraint.TypeMinorProtection),
+			Description: "未成年工不安排 22:00 至次日 06:00 的夜班", Basis: "《上海市未成年工特殊保护办法》"},
+	),
+	{
+		Code:        "EU-working-time",
+		Name:        "欧盟工作时间指令",
+		Description: "每 24 小时至少连续休息 11 小时，每 7 天至少休息 24 小时，每周工作时间（含加班）不超过 48 小时；青少年工人另有限制",
+		Rules: []Rule{
+			{Key: "max_hours_per_day", Value: 13, Constraint: string(constraint.TypeMaxHoursPerDay),
+				Description: "每日连续休息 11 小时，每日工作不超过 13 小时", Basis: "Directive 2003/88/EC Art. 3"},
+			{Key: "min_rest_between_shifts", Value: 11, Minimum: true, Constraint: string(constraint.TypeMinRestBetweenShifts),
+				Description: "班次间至少连续休息 11 小时", Basis: "Directive 2003/88/EC Art. 3"},
+			{Key: "max_consecutive_days", Value: 6, Constraint: string(constraint.TypeMaxConsecutiveDays),
+				Description: "每 7 天至少休息 24 小时，连续工作不超过 6 天", Basis: "Directive 2003/88/EC Art. 5"},
+			{Key: "max_hours_per_week", Value: 48, Constraint: string(constraint.TypeMaxHoursPerWeek),
+				Description: "每周工作时间（含加班）不超过 48 小时（按每周核查）", Basis: "Directive 2003/88/EC Art. 6"},
+			{Key: "minor_max_hours_per_day", Value: 8, Constraint: string(constraint.TypeMinorProtection),
+				Description: "未满 18 周岁的青少年每日工作不超过 8 小时", Basis: "Directive 94/33/EC Art. 8"},
+			{Key: "minor_max_hours_per_week", Value: 40, Constraint: string(constraint.TypeMinorProtection),
+				Description: "未满 18 周岁的青少年每周工作不超过 40 小时", Basis: "Directive 94/33/EC Art. 8"},
+			{Key: "minor_night_window", Value: "22:00-06:00", Constraint: string(constraint.TypeMinorProtection),
+				Description: "青少年不安排 22:00 至次日 06:00 的夜间工作", Basis: "Directive 94/33/EC Art. 9"},
+			{Key: "minor_min_rest_hours", Value: 12, Minimum: true, Constraint: string(constraint.TypeMinorProtection),
+				Description: "青少年每 24 小时至少连续休息 12 小时", Basis: "Directive 94/33/EC Art. 10"},
+		},
+	},
+}
+
+// extend 在已有规则包基础上追加或覆盖规则（同一配置键以新规则为准）
+func extend(base *Pack, code, name, description string, rules ...Rule) *Pack {
+	p := &Pack{Code: code, Name: name, Description: description}
+	overridden := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		overridden[r.Key] = true
+	}
+	for _, r := range base.Rules {
+		if !overridden[r.Key] {
+			p.Rules = append(p.Rules, r)
+		}
+	}
+	p.Rules = append(p.Rules, rules...)
+	return p
+}
+
+// Packs 返回所有内置规则包
+func Packs() []*Pack {
+	return append([]*Pack(nil), packs...)
+}
+
+// Lookup 按辖区代码查找规则包（不区分大小写）
+func Lookup(code string) (*Pack, bool) {
+	for _, p := range packs {
+		if strings.EqualFold(p.Code, code) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Codes 返回所有辖区代码
+func Codes() []string {
+	codes := make([]string, len(packs))
+	for i, p := range packs {
+		codes[i] = p.Code
+	}
+	return codes
+}
+
+// Apply 返回应用规则包后的约束配置（不修改原配置）：
+// 上限取配置和规则包中较小的值（配置为 0 或未配置时取规则包），下限取较大的值，时段类规则以规则包为准
+func (p *Pack) Apply(config map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(config)+len(p.Rules))
+	for k, v := range config {
+		merged[k] = v
+	}
+	for _, r := range p.Rules {
+		merged[r.Key] = r.effective(merged[r.Key])
+	}
+	return merged
+}
+
+// effective 返回配置中已有取值与规则取值中更严格的一个
+func (r Rule) effective(current interface{}) interface{} {
+	limit, ok := number(r.Value)
+	if !ok {
+		return r.Value
+	}
+	value, ok := number(current)
+	if !ok || value <= 0 {
+		return r.Value
+	}
+	if (r.Minimum && value > limit) || (!r.Minimum && value < limit) {
+		return current
+	}
+	return r.Value
+}
+
+// number 将配置取值转换为数值
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// Check 合规报告中一项硬约束的核查结果
+type Check struct {
+	Constraint string `json:"constraint"`
+	Rules      []Rule `json:"rules"`      // 该约束对应的规则（取值为实际生效值）
+	Violations int    `json:"violations"` // 违规次数
+	Employees  int    `json:"employees"`  // 涉及员工数
+	Passed     bool   `json:"passed"`
+}
+
+// Report 合规报告
+type Report struct {
+	Jurisdiction string                       `json:"jurisdiction"`
+	Name         string                       `json:"name"`
+	Compliant    bool                         `json:"compliant"`
+	Checks       []Check                      `json:"checks"`
+	Violations   []constraint.ViolationDetail `json:"violations,omitempty"` // 规则包相关的硬约束违规
+}
+
+// Report 按规则包汇总合规报告：config 为实际生效的约束配置，violations 为排班的硬约束违规
+func (p *Pack) Report(config map[string]interface{}, violations []constraint.ViolationDetail) *Report {
+	report := &Report{Jurisdiction: p.Code, Name: p.Name, Compliant: true, Checks: make([]Check, 0)}
+	index := make(map[string]int)
+	for _, r := range p.Rules {
+		if v, ok := config[r.Key]; ok {
+			r.Value = v
+		}
+		i, ok := index[r.Constraint]
+		if !ok {
+			i = len(report.Checks)
+			index[r.Constraint] = i
+			report.Checks = append(report.Checks, Check{Constraint: r.Constraint, Passed: true})
+		}
+		report.Checks[i].Rules = append(report.Checks[i].Rules, r)
+	}
+
+	employees := make([]map[uuid.UUID]bool, len(report.Checks))
+	for _, v := range violations {
+		i, ok := index[string(v.ConstraintType)]
+		if !ok {
+			continue
+		}
+		check := &report.Checks[i]
+		check.Violations++
+		check.Passed = false
+		if employees[i] == nil {
+			employees[i] = make(map[uuid.UUID]bool)
+		}
+		employees[i][v.EmployeeID] = true
+		check.Employees = len(employees[i])
+		report.Compliant = false
+		report.Violations = append(report.Violations, v)
+	}
+	sort.SliceStable(report.Violations, func(i, j int) bool {
+		return report.Violations[i].Date < report.Violations[j].Date
+	})
+	return report
+}
@@ -0,0 +1,97 @@
+package compliance
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+func TestLookup(t *testing.T) {
+	for _, code := range Codes() {
+		p, ok := Lookup(code)
+		if !ok || p.Code != code || len(p.Rules) == 0 {
+			t.Fatalf("规则包 %s 应可查找且包含规则", code)
+		}
+	}
+	if p, ok := Lookup("cn-shanghai"); !ok || p.Code != "CN-Shanghai" {
+		t.Error("辖区代码应不区分大小写")
+	}
+	if _, ok := Lookup("US"); ok {
+		t.Error("未知辖区不应找到规则包")
+	}
+
+	// 上海规则包继承中国劳动法规则
+	sh, _ := Lookup("CN-Shanghai")
+	keys := make(map[string]bool)
+	for _, r := range sh.Rules {
+		keys[r.Key] = true
+	}
+	if !keys["max_overtime_hours_per_month"] || !keys["minor_night_window"] {
+		t.Errorf("上海规则包应包含全国规则和地方规则: %v", keys)
+	}
+}
+
+func TestPack_Apply(t *testing.T) {
+	eu, _ := Lookup("EU-working-time")
+	config := map[string]interface{}{
+		"max_hours_per_week":      60,   // 比规则宽松，取规则值
+		"max_hours_per_day":       10.0, // 比规则严格，保留配置
+		"min_rest_between_shifts": 8,    // 下限比规则宽松，取规则值
+		"minor_min_rest_hours":    14,   // 下限比规则严格，保留配置
+		"max_consecutive_days":    0,    // 未限制，取规则值
+		"minor_night_window":      "23:00-05:00",
+		"preference_weight":       50,
+	}
+	merged := eu.Apply(config)
+
+	expect := map[string]interface{}{
+		"max_hours_per_week":      48,
+		"max_hours_per_day":       10.0,
+		"min_rest_between_shifts": 11,
+		"minor_min_rest_hours":    14,
+		"max_consecutive_days":    6,
+		"minor_night_window":      "22:00-06:00",
+		"preference_weight":       50,
+		"minor_max_hours_per_day": 8,
+	}
+	for key, want := range expect {
+		if merged[key] != want {
+			t.Errorf("%s = %v, expected %v", key, merged[key], want)
+		}
+	}
+	if config["max_hours_per_week"] != 60 {
+		t.Error("Apply 不应修改原配置")
+	}
+}
+
+func TestPack_Report(t *testing.T) {
+	cn, _ := Lookup("CN")
+	config := cn.Apply(nil)
+
+	report := cn.Report(config, nil)
+	if !report.Compliant || len(report.Checks) != 4 {
+		t.Fatalf("无违规时应合规，按约束分组: %+v", report)
+	}
+
+	emp := uuid.New()
+	report = cn.Report(config, []constraint.ViolationDetail{
+		{ConstraintType: constraint.TypeOvertimeCap, EmployeeID: emp, Date: "2025-03-05"},
+		{ConstraintType: constraint.TypeOvertimeCap, EmployeeID: emp, Date: "2025-03-04"},
+		{ConstraintType: constraint.TypeSkillRequired, EmployeeID: emp, Date: "2025-03-01"},
+	})
+	if report.Compliant || len(report.Violations) != 2 || report.Violations[0].Date != "2025-03-04" {
+		t.Fatalf("规则包相关违规应按日期列出，其他约束不计入: %+v", report.Violations)
+	}
+	for _, check := range report.Checks {
+		if check.Constraint != string(constraint.TypeOvertimeCap) {
+			if !check.Passed {
+				t.Errorf("%s 无违规应通过", check.Constraint)
+			}
+			continue
+		}
+		if check.Passed || check.Violations != 2 || check.Employees != 1 || len(check.Rules) != 4 {
+			t.Errorf("加班上限核查结果错误: %+v", check)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// Package costing 提供排班的人工成本计算
+// 按员工时薪把每个分配的工时拆分为正常、加班和节假日工时：当天超过标准日工时、
+// 当周（周日开始）正常工时超过标准周工时的部分为加班，节假日（含调休休息日）的工时按节假日倍率计，
+// 外部人员按固定时薪结算，不区分加班和节假日
+package costing
+
+import (
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/holiday"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// Config 成本计算参数（JSON 键与排班约束配置一致）
+type Config struct {
+	StandardDailyHours  float64 `json:"standard_hours_per_day"`  // 标准日工时
+	StandardWeeklyHours float64 `json:"standard_hours_per_week"` // 标准周工时
+	OvertimeRate        float64 `json:"overtime_pay_rate"`       // 加班工资倍率
+	HolidayRate         float64 `json:"holiday_bonus_rate"`      // 节假日统一倍率，0 表示按节假日日历（法定 3 倍、调休休息日 2 倍）
+}
+
+// DefaultConfig 返回默认参数：每日 8 小时、每周 40 小时，加班 1.5 倍
+func DefaultConfig() Config {
+	return Config{
+		StandardDailyHours:  8,
+		StandardWeeklyHours: 40,
+		OvertimeRate:        1.5,
+	}
+}
+
+// Amount 工时拆分及费用
+type Amount struct {
+	Hours         float64 `json:"hours"`
+	NormalHours   float64 `json:"normal_hours"`
+	OvertimeHours float64 `json:"overtime_hours"`
+	HolidayHours  float64 `json:"holiday_hours"`
+	Cost          float64 `json:"cost"`
+}
+
+// EmployeeCost 员工的人工成本
+type EmployeeCost struct {
+	EmployeeID   uuid.UUID `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	HourlyRate   float64   `json:"hourly_rate"`
+	External     bool      `json:"external,omitempty"` // 外部人员按固定时薪结算
+	Amount
+}
+
+// PositionCost 岗位的人工成本（分配未指定岗位时按员工岗位统计）
+type PositionCost struct {
+	Position  string `json:"position"`
+	Employees int    `json:"employees"`
+	Amount
+}
+
+// DayCost 每日的人工成本
+type DayCost struct {
+	Date    string `json:"date"`
+	Holiday bool   `json:"holiday,omitempty"`
+	Amount
+}
+
+// Summary 人工成本汇总
+type Summary struct {
+	Amount
+	NormalCost   float64 `json:"normal_cost"`
+	OvertimeCost float64 `json:"overtime_cost"`
+	HolidayCost  float64 `json:"holiday_cost"`
+	// 未设置时薪（按 0 计费）的员工
+	Unpriced []uuid.UUID `json:"unpriced_employees,omitempty"`
+
+	ByEmployee []EmployeeCost `json:"by_employee"`
+	ByPosition []PositionCost `json:"by_position"`
+	ByDay      []DayCost      `json:"by_day"`
+}
+
+// Calculator 人工成本计算器
+type Calculator struct {
+	cfg      Config
+	calendar *holiday.Calendar
+}
+
+// NewCalculator 创建人工成本计算器，零值参数使用默认值；calendar 为空时不区分节假日
+func NewCalculator(cfg Config, calendar *holiday.Calendar) *Calculator {
+	def := DefaultConfig()
+	if cfg.StandardDailyHours <= 0 {
+		cfg.StandardDailyHours = def.StandardDailyHours
+	}
+	if cfg.StandardWeeklyHours <= 0 {
+		cfg.StandardWeeklyHours = def.StandardWeeklyHours
+	}
+	if cfg.OvertimeRate <= 0 {
+		cfg.OvertimeRate = def.OvertimeRate
+	}
+	return &Calculator{cfg: cfg, calendar: calendar}
+}
+
+// line 单个分配的成本
+type line struct {
+	normal, overtime, holiday             float64 // 工时
+	normalCost, overtimeCost, holidayCost float64 // 费用
+	cost                                  float64
+}
+
+// Calculate 计算排班的人工成本，员工列表中找不到的分配按未设置时薪处理
+func (c *Calculator) Calculate(employees []*model.Employee, assignments []*model.Assignment) *Summary {
+	empMap := make(map[uuid.UUID]*model.Employee, len(employees))
+	for _, e := range employees {
+		empMap[e.ID] = e
+	}
+	byEmployee := make(map[uuid.UUID][]*model.Assignment)
+	for _, a := range assignments {
+		byEmployee[a.EmployeeID] = append(byEmployee[a.EmployeeID], a)
+	}
+
+	summary := &Summary{
+		ByEmployee: make([]EmployeeCost, 0, len(byEmployee)),
+		ByPosition: make([]PositionCost, 0),
+		ByDay:      make([]DayCost, 0),
+	}
+	positions := make(map[string]*PositionCost)
+	positionEmployees := make(map[string]map[uuid.UUID]bool)
+	days := make(map[string]*DayCost)
+
+	for empID, list := range byEmployee {
+		emp := empMap[empID]
+		ec := EmployeeCost{EmployeeID: empID, EmployeeName: empID.String()}
+		if emp != nil {
+			ec.EmployeeName, ec.HourlyRate, ec.External = emp.Name, emp.HourlyRate, emp.IsExternal()
+		}
+		if ec.HourlyRate <= 0 {
+			summary.Unpriced = append(summary.Unpriced, empID)
+		}
+
+		for i, l := range c.split(list, ec.HourlyRate, ec.External) {
+			a := list[i]
+			ec.add(l)
+			summary.Amount.add(l)
+			summary.NormalCost += l.normalCost
+			summary.OvertimeCost += l.overtimeCost
+			summary.HolidayCost += l.holidayCost
+
+			position := a.Position
+			if position == "" && emp != nil {
+				position = emp.Position
+			}
+			pc, ok := positions[position]
+			if !ok {
+				pc = &PositionCost{Position: position}
+				positions[position] = pc
+				positionEmployees[position] = make(map[uuid.UUID]bool)
+			}
+			pc.add(l)
+			positionEmployees[position][empID] = true
+
+			dc, ok := days[a.Date]
+			if !ok {
+				dc = &DayCost{Date: a.Date, Holiday: c.isHoliday(a.Date)}
+				days[a.Date] = dc
+			}
+			dc.add(l)
+		}
+		ec.round()
+		summary.ByEmployee = append(summary.ByEmployee, ec)
+	}
+
+	for position, pc := range positions {
+		pc.Employees = len(positionEmployees[position])
+		pc.round()
+		summary.ByPosition = append(summary.ByPosition, *pc)
+	}
+	for _, dc := range days {
+		dc.round()
+		summary.ByDay = append(summary.ByDay, *dc)
+	}
+	summary.round()
+	summary.NormalCost, summary.OvertimeCost, summary.HolidayCost = round(summary.NormalCost), round(summary.OvertimeCost), round(summary.HolidayCost)
+
+	sort.Slice(summary.ByEmployee, func(i, j int) bool {
+		if summary.ByEmployee[i].Cost != summary.ByEmployee[j].Cost {
+			return summary.ByEmployee[i].Cost > summary.ByEmployee[j].Cost
+		}
+		return summary.ByEmployee[i].EmployeeName < summary.ByEmployee[j].EmployeeName
+	})
+	sort.Slice(summary.ByPosition, func(i, j int) bool {
+		if summary.ByPosition[i].Cost != summary.ByPosition[j].Cost {
+			return summary.ByPosition[i].Cost > summary.ByPosition[j].Cost
+		}
+		return summary.ByPosition[i].Position < summary.ByPosition[j].Position
+	})
+	sort.Slice(summary.ByDay, func(i, j int) bool { return summary.ByDay[i].Date < summary.ByDay[j].Date })
+	sort.Slice(summary.Unpriced, func(i, j int) bool { return summary.Unpriced[i].String() < summary.Unpriced[j].String() })
+	return summary
+}
+
+// split 按开始时间先后拆分员工各分配的正常、加班和节假日工时，返回与 assignments 顺序一致的结果
+func (c *Calculator) split(assignments []*model.Assignment, rate float64, flat bool) []line {
+	order := make([]int, len(assignments))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return assignments[order[i]].StartTime.Before(assignments[order[j]].StartTime)
+	})
+
+	week := model.ScheduleCycle{Weeks: 1}
+	daily := make(map[string]float64)
+	weekly := make(map[string]float64)
+	lines := make([]line, len(assignments))
+	for _, i := range order {
+		a := assignments[i]
+		hours := a.WorkingHours()
+		l := &lines[i]
+		switch {
+		case flat:
+			l.normal = hours
+			l.normalCost = hours * rate
+		case c.isHoliday(a.Date):
+			l.holiday = hours
+			l.holidayCost = hours * rate * c.holidayRate(a.Date)
+		default:
+			start := week.Start(a.Date)
+			regular := math.Min(hours, math.Max(0, c.cfg.StandardDailyHours-daily[a.Date]))
+			regular = math.Min(regular, math.Max(0, c.cfg.StandardWeeklyHours-weekly[start]))
+			daily[a.Date] += hours
+			weekly[start] += regular
+			l.normal, l.overtime = regular, hours-regular
+			l.normalCost = regular * rate
+			l.overtimeCost = l.overtime * rate * c.cfg.OvertimeRate
+		}
+		l.cost = l.normalCost + l.overtimeCost + l.holidayCost
+	}
+	return lines
+}
+
+// isHoliday 是否为休息的节假日（调休上班日按工作日计）
+func (c *Calculator) isHoliday(date string) bool {
+	return c.calendar != nil && c.calendar.IsHoliday(date)
+}
+
+// holidayRate 节假日工时倍率
+func (c *Calculator) holidayRate(date string) float64 {
+	if c.cfg.HolidayRate > 0 {
+		return c.cfg.HolidayRate
+	}
+	return c.calendar.Rate(date)
+}
+
+func (m *Amount) add(l line) {
+	m.NormalHours += l.normal
+	m.OvertimeHours += l.overtime
+	m.HolidayHours += l.holiday
+	m.Hours += l.normal + l.overtime + l.holiday
+	m.Cost += l.cost
+}
+
+func (m *Amount) round() {
+	m.Hours, m.NormalHours, m.OvertimeHours = round(m.Hours), round(m.NormalHours), round(m.OvertimeHours)
+	m.HolidayHours, m.Cost = round(m.HolidayHours), round(m.Cost)
+}
+
+// round 保留两位小数
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,79 @@
+package costing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/holiday"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func shift(emp *model.Employee, date, start, end string) *model.Assignment {
+	s, _ := time.Parse("2006-01-02 15:04", date+" "+start)
+	e, _ := time.Parse("2006-01-02 15:04", date+" "+end)
+	return &model.Assignment{
+		BaseModel:  model.BaseModel{ID: uuid.New()},
+		EmployeeID: emp.ID,
+		Date:       date,
+		StartTime:  s,
+		EndTime:    e,
+	}
+}
+
+func TestCalculator_Calculate(t *testing.T) {
+	cook := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "张三", Position: "厨师", HourlyRate: 30}
+	waiter := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "李四", Position: "服务员", HourlyRate: 20}
+
+	// 2025-09-29 周一：张三 10 小时（加班 2 小时）；2025-10-01 国庆：张三 8 小时（3 倍）
+	// 李四周一 8 小时，另有一个未登记员工的分配
+	assignments := []*model.Assignment{
+		shift(cook, "2025-10-01", "09:00", "17:00"),
+		shift(cook, "2025-09-29", "08:00", "18:00"),
+		shift(waiter, "2025-09-29", "09:00", "17:00"),
+		shift(&model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}}, "2025-09-29", "09:00", "13:00"),
+	}
+	summary := NewCalculator(Config{}, holiday.NewCalendar(true, nil)).Calculate([]*model.Employee{cook, waiter}, assignments)
+
+	// 张三：8×30 + 2×30×1.5 + 8×30×3 = 240 + 90 + 720；李四：160
+	if summary.Cost != 1210 || summary.NormalCost != 400 || summary.OvertimeCost != 90 || summary.HolidayCost != 720 {
+		t.Errorf("总成本 = %+v", summary)
+	}
+	if summary.Hours != 30 || summary.NormalHours != 20 || summary.OvertimeHours != 2 || summary.HolidayHours != 8 {
+		t.Errorf("工时拆分 = %+v", summary.Amount)
+	}
+	if len(summary.Unpriced) != 1 {
+		t.Errorf("未设置时薪的员工 = %v", summary.Unpriced)
+	}
+
+	if len(summary.ByEmployee) != 3 || summary.ByEmployee[0].EmployeeName != "张三" || summary.ByEmployee[0].Cost != 1050 {
+		t.Errorf("按员工汇总应按成本降序: %+v", summary.ByEmployee)
+	}
+	if len(summary.ByPosition) != 3 || summary.ByPosition[0].Position != "厨师" || summary.ByPosition[0].Employees != 1 {
+		t.Errorf("按岗位汇总 = %+v", summary.ByPosition)
+	}
+	if len(summary.ByDay) != 2 || summary.ByDay[0].Date != "2025-09-29" || summary.ByDay[0].Cost != 490 || !summary.ByDay[1].Holiday {
+		t.Errorf("按日期汇总 = %+v", summary.ByDay)
+	}
+}
+
+func TestCalculator_WeeklyOvertimeAndRates(t *testing.T) {
+	emp := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "张三", HourlyRate: 10}
+	// 2026-01-04 周日起连续 6 天每天 8 小时：第 6 天超过标准周工时 40 小时，全部为加班
+	var assignments []*model.Assignment
+	for _, date := range []string{"2026-01-04", "2026-01-05", "2026-01-06", "2026-01-07", "2026-01-08", "2026-01-09"} {
+		assignments = append(assignments, shift(emp, date, "09:00", "17:00"))
+	}
+
+	summary := NewCalculator(Config{OvertimeRate: 2}, nil).Calculate([]*model.Employee{emp}, assignments)
+	if summary.NormalHours != 40 || summary.OvertimeHours != 8 || summary.Cost != 40*10+8*10*2 {
+		t.Errorf("超过标准周工时的部分应按加班倍率计: %+v", summary.Amount)
+	}
+
+	// 外部人员按固定时薪结算
+	emp.External = &model.ExternalLabor{Agency: "派遣公司"}
+	summary = NewCalculator(Config{}, nil).Calculate([]*model.Employee{emp}, assignments)
+	if summary.OvertimeHours != 0 || summary.Cost != 480 || !summary.ByEmployee[0].External {
+		t.Errorf("外部人员不应计加班: %+v", summary.Amount)
+	}
+}
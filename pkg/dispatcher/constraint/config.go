@@ -0,0 +1,247 @@
+package constraint
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ParamDefinition 派单约束参数定义
+type ParamDefinition struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // int, float
+	Description string `json:"description"`
+	Default     string `json:"default,omitempty"`
+	Min         string `json:"min,omitempty"`
+	Max         string `json:"max,omitempty"`
+}
+
+// Definition 派单约束定义
+type Definition struct {
+	Name        string            `json:"name"`
+	DisplayName string            `json:"display_name"`
+	Type        string            `json:"type"` // hard 硬约束, soft 软约束
+	Description string            `json:"description"`
+	Weight      float64           `json:"weight"` // 默认权重
+	Params      []ParamDefinition `json:"params"`
+}
+
+// LibraryResponse 派单约束库响应
+type LibraryResponse struct {
+	Library []Definition `json:"library"`
+}
+
+// MaxWeight 派单约束权重上限
+const MaxWeight = 10000
+
+// entry 派单约束库条目：约束定义和按参数创建约束的函数
+type entry struct {
+	def   Definition
+	build func(params map[string]float64) DispatchConstraint
+}
+
+// param 创建参数定义
+func param(name, typ, desc string, def, min, max float64) ParamDefinition {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return ParamDefinition{Name: name, Type: typ, Description: desc, Default: format(def), Min: format(min), Max: format(max)}
+}
+
+// library 派单约束库，默认约束按此顺序创建
+var library = []entry{
+	{
+		def: Definition{
+			Name: "service_area_match", DisplayName: "服务区域匹配", Type: "hard", Weight: 1000,
+			Description: "员工位置（实时位置或家庭住址）到订单地点的距离不得超过最大服务距离，距离越远惩罚越高。",
+			Params:      []ParamDefinition{param("max_distance_km", "float", "最大服务距离（公里）", 20, 0.1, 500)},
+		},
+		build: func(p map[string]float64) DispatchConstraint {
+			return NewServiceAreaMatchConstraint(p["max_distance_km"])
+		},
+	},
+	{
+		def: Definition{
+			Name: "travel_time_buffer", DisplayName: "路程时间缓冲", Type: "hard", Weight: 500,
+			Description: "与员工当天已有订单的时间不得重叠，间隔不少于最小缓冲时间；配置路程服务时还须不少于两单间的路程时间。",
+			Params:      []ParamDefinition{param("min_buffer_minutes", "int", "订单间最小缓冲时间（分钟）", 30, 0, 240)},
+		},
+		build: func(p map[string]float64) DispatchConstraint {
+			return NewTravelTimeBufferConstraint(int(p["min_buffer_minutes"]))
+		},
+	},
+	{
+		def: Definition{
+			Name: "max_orders_per_day", DisplayName: "每日最大订单数", Type: "hard", Weight: 300,
+			Description: "员工当天已有订单达到上限时不可派，已有订单越多惩罚越高。",
+			Params:      []ParamDefinition{param("max_orders", "int", "每日最多订单数", 8, 1, 50)},
+		},
+		build: func(p map[string]float64) DispatchConstraint {
+			return NewMaxOrdersPerDayConstraint(int(p["max_orders"]))
+		},
+	},
+	{
+		def: Definition{
+			Name: "customer_preference", DisplayName: "客户偏好", Type: "soft", Weight: 50,
+			Description: "客户黑名单中的员工不可派；优先客户偏好的员工，客户要求同一服务者时其他员工加罚。",
+			Params: []ParamDefinition{
+				param("preferred_bonus", "float", "客户偏好员工的奖励", 20, 0, 1000),
+				param("same_worker_penalty", "float", "客户要求同一服务者时非偏好员工的惩罚", 30, 0, 1000),
+			},
+		},
+		build: func(p map[string]float64) DispatchConstraint {
+			c := NewCustomerPreferenceConstraint()
+			c.PreferredBonus, c.SameWorkerPenalty = p["preferred_bonus"], p["same_worker_penalty"]
+			return c
+		},
+	},
+	{
+		def: Definition{
+			Name: "certification_level", DisplayName: "资质等级", Type: "hard", Weight: 800,
+			Description: "护理、月嫂、康复等服务类型要求员工持有对应证书。",
+			Params:      []ParamDefinition{},
+		},
+		build: func(map[string]float64) DispatchConstraint { return NewCertificationLevelConstraint() },
+	},
+	{
+		def: Definition{
+			Name: "caregiver_continuity", DisplayName: "服务连续性", Type: "soft", Weight: 40,
+			Description: "按客户服务历史奖励服务次数多、评分高的员工和主护理员，没有服务历史的员工轻微加罚。",
+			Params:      []ParamDefinition{},
+		},
+		build: func(map[string]float64) DispatchConstraint { return NewCaregiverContinuityConstraint() },
+	},
+	{
+		def: Definition{
+			Name: "skill_match", DisplayName: "技能匹配", Type: "hard", Weight: 600,
+			Description: "员工须具备订单要求的全部技能，并满足每个技能组。",
+			Params:      []ParamDefinition{},
+		},
+		build: func(map[string]float64) DispatchConstraint { return NewSkillMatchConstraint() },
+	},
+	{
+		def: Definition{
+			Name: "live_status", DisplayName: "实时状态", Type: "hard", Weight: 1000,
+			Description: "已下班的员工不可派；途中员工预计到达时间晚于订单开始时间时不可派。",
+			Params:      []ParamDefinition{param("avg_speed_kmh", "float", "未配置路程服务时估算路程的平均速度（公里/小时）", 30, 5, 120)},
+		},
+		build: func(p map[string]float64) DispatchConstraint {
+			return NewLiveStatusConstraint(p["avg_speed_kmh"])
+		},
+	},
+	{
+		def: Definition{
+			Name: "distinct_caregiver", DisplayName: "客户服务人员数量", Type: "soft", Weight: 40,
+			Description: "优先派给滚动窗口内已服务过客户的员工；客户设置上限且已达上限时新人不可派。",
+			Params: []ParamDefinition{
+				param("penalty_per_caregiver", "float", "客户每多一名已有服务人员，新人的惩罚增加值", 10, 0, 1000),
+				param("cap_penalty", "float", "达到客户服务人员上限时的惩罚", 1000, 0, 10000),
+			},
+		},
+		build: func(p map[string]float64) DispatchConstraint {
+			c := NewDistinctCaregiverConstraint()
+			c.PenaltyPerCaregiver, c.CapPenalty = p["penalty_per_caregiver"], p["cap_penalty"]
+			return c
+		},
+	},
+}
+
+// Library 返回派单约束库（全部派单约束及参数定义）
+func Library() []Definition {
+	defs := make([]Definition, len(library))
+	for i, e := range library {
+		defs[i] = e.def
+	}
+	return defs
+}
+
+// lookup 按名称查找约束库条目
+func lookup(name string) *entry {
+	for i := range library {
+		if library[i].def.Name == name {
+			return &library[i]
+		}
+	}
+	return nil
+}
+
+// Validate 校验派单约束配置：约束和参数须在约束库中，参数在取值范围内，权重不超过 MaxWeight（0 表示默认权重）
+func Validate(config model.DispatchConstraintConfig) error {
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		e := lookup(name)
+		if e == nil {
+			return fmt.Errorf("未知派单约束: %s", name)
+		}
+		s := config[name]
+		if s.Weight < 0 || s.Weight > MaxWeight {
+			return fmt.Errorf("派单约束 %s 的权重应在 0-%d 之间: %g", name, MaxWeight, s.Weight)
+		}
+		for key, v := range s.Params {
+			var def *ParamDefinition
+			for i := range e.def.Params {
+				if e.def.Params[i].Name == key {
+					def = &e.def.Params[i]
+				}
+			}
+			if def == nil {
+				return fmt.Errorf("参数 %s 不属于派单约束 %s", key, name)
+			}
+			min, _ := strconv.ParseFloat(def.Min, 64)
+			max, _ := strconv.ParseFloat(def.Max, 64)
+			if v < min || v > max {
+				return fmt.Errorf("派单约束 %s 的参数 %s 应在 %s-%s 之间: %g", name, key, def.Min, def.Max, v)
+			}
+			if def.Type == "int" && v != float64(int(v)) {
+				return fmt.Errorf("派单约束 %s 的参数 %s 应为整数: %g", name, key, v)
+			}
+		}
+	}
+	return nil
+}
+
+// Build 按配置创建派单约束：未配置的约束使用默认参数和权重，enabled 为 false 的约束不启用
+func Build(config model.DispatchConstraintConfig) ([]DispatchConstraint, error) {
+	if err := Validate(config); err != nil {
+		return nil, err
+	}
+	constraints := make([]DispatchConstraint, 0, len(library))
+	for _, e := range library {
+		s := config[e.def.Name]
+		if s.Enabled != nil && !*s.Enabled {
+			continue
+		}
+		params := make(map[string]float64, len(e.def.Params))
+		for _, p := range e.def.Params {
+			params[p.Name], _ = strconv.ParseFloat(p.Default, 64)
+		}
+		for k, v := range s.Params {
+			params[k] = v
+		}
+		c := e.build(params)
+		if s.Weight > 0 && s.Weight != e.def.Weight {
+			c = &weightedConstraint{DispatchConstraint: c, weight: s.Weight, scale: s.Weight / e.def.Weight}
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints, nil
+}
+
+// weightedConstraint 调整权重的约束：按与默认权重的比例缩放惩罚和奖励
+type weightedConstraint struct {
+	DispatchConstraint
+	weight float64
+	scale  float64
+}
+
+func (c *weightedConstraint) Weight() float64 { return c.weight }
+
+func (c *weightedConstraint) Evaluate(order *model.ServiceOrder, employee *model.Employee, ctx *DispatchContext) (bool, float64, string) {
+	valid, penalty, violation := c.DispatchConstraint.Evaluate(order, employee, ctx)
+	return valid, penalty * c.scale, violation
+}
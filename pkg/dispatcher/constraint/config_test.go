@@ -0,0 +1,76 @@
+package constraint
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestBuild_Defaults(t *testing.T) {
+	defaults := DefaultDispatchConstraints()
+	names := []string{"ServiceAreaMatch", "TravelTimeBuffer", "MaxOrdersPerDay", "CustomerPreference", "CertificationLevel",
+		"CaregiverContinuity", "SkillMatch", "LiveStatus", "DistinctCaregiver"}
+	if len(defaults) != len(names) || len(Library()) != len(names) {
+		t.Fatalf("约束数 = %d, 约束库 = %d", len(defaults), len(Library()))
+	}
+	for i, c := range defaults {
+		if c.Name() != names[i] || c.Weight() != Library()[i].Weight {
+			t.Errorf("第 %d 个约束 = %s/%g, 约束库 = %s/%g", i, c.Name(), c.Weight(), Library()[i].Name, Library()[i].Weight)
+		}
+	}
+	if area := defaults[0].(*ServiceAreaMatchConstraint); area.MaxDistanceKm != 20 {
+		t.Errorf("默认最大服务距离 = %g", area.MaxDistanceKm)
+	}
+}
+
+func TestBuild_Config(t *testing.T) {
+	disabled := false
+	built, err := Build(model.DispatchConstraintConfig{
+		"service_area_match":  {Params: map[string]float64{"max_distance_km": 5}},
+		"customer_preference": {Weight: 100},
+		"skill_match":         {Enabled: &disabled},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]DispatchConstraint)
+	for _, c := range built {
+		byName[c.Name()] = c
+	}
+	if _, ok := byName["SkillMatch"]; ok {
+		t.Error("未启用的约束不应创建")
+	}
+	if area := byName["ServiceAreaMatch"].(*ServiceAreaMatchConstraint); area.MaxDistanceKm != 5 {
+		t.Errorf("最大服务距离 = %g", area.MaxDistanceKm)
+	}
+
+	// 权重加倍时偏好奖励加倍
+	pref := byName["CustomerPreference"]
+	employee := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}}
+	ctx := &DispatchContext{Customer: &model.Customer{PreferredEmpIDs: []uuid.UUID{employee.ID}}}
+	if _, penalty, _ := pref.Evaluate(&model.ServiceOrder{}, employee, ctx); pref.Weight() != 100 || penalty != -40 {
+		t.Errorf("weight = %g, penalty = %g", pref.Weight(), penalty)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config model.DispatchConstraintConfig
+		valid  bool
+	}{
+		{"有效", model.DispatchConstraintConfig{"max_orders_per_day": {Params: map[string]float64{"max_orders": 6}}}, true},
+		{"未知约束", model.DispatchConstraintConfig{"unknown": {}}, false},
+		{"参数不属于约束", model.DispatchConstraintConfig{"skill_match": {Params: map[string]float64{"max_orders": 6}}}, false},
+		{"参数超出范围", model.DispatchConstraintConfig{"service_area_match": {Params: map[string]float64{"max_distance_km": 0}}}, false},
+		{"整数参数", model.DispatchConstraintConfig{"max_orders_per_day": {Params: map[string]float64{"max_orders": 6.5}}}, false},
+		{"权重超出范围", model.DispatchConstraintConfig{"live_status": {Weight: MaxWeight + 1}}, false},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.config); (err == nil) != tt.valid {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+	}
+}
@@ -2,9 +2,12 @@
 package constraint
 
 import (
+	"fmt"
 	"math"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/dispatcher/travel"
 	"github.com/paiban/paiban/pkg/model"
 )
 
@@ -23,6 +26,20 @@ type DispatchContext struct {
 	EmployeeOrders   []*model.ServiceOrder           // 员工今日已分配订单
 	ServiceHistory   []model.CustomerEmployeeHistory // 客户服务历史
 	EmployeeLocation *model.Location                 // 员工当前位置
+	LiveStatus       *model.EmployeeLiveStatus       // 员工实时状态（无上报或已过期时为nil）
+	Now              time.Time                       // 评估时间（零值表示当前时间）
+	WindowCaregivers []uuid.UUID                     // 滚动窗口内为客户服务过的不同服务人员
+	Travel           travel.Provider                 // 路程估算服务（为nil时按直线距离计算）
+}
+
+// estimate 估算两点间路程：未配置路程服务或服务出错时按直线距离，ok 表示路程时间可用
+func (ctx *DispatchContext) estimate(from, to model.Location) (e travel.Estimate, ok bool) {
+	if ctx.Travel != nil {
+		if e, err := ctx.Travel.Estimate(from, to); err == nil {
+			return e, true
+		}
+	}
+	return travel.Estimate{DistanceKm: calculateDistance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)}, false
 }
 
 // BaseDispatchConstraint 基础派出约束
@@ -61,10 +78,9 @@ func (c *ServiceAreaMatchConstraint) Evaluate(order *model.ServiceOrder, employe
 		return true, 0, ""
 	}
 
-	distance := calculateDistance(
-		order.Location.Latitude, order.Location.Longitude,
-		ctx.EmployeeLocation.Latitude, ctx.EmployeeLocation.Longitude,
-	)
+	// 配置路程服务时按路网距离判断
+	est, _ := ctx.estimate(*ctx.EmployeeLocation, *order.Location)
+	distance := est.DistanceKm
 
 	if distance > c.MaxDistanceKm {
 		return false, c.weight, "服务距离超出范围"
@@ -77,6 +93,7 @@ func (c *ServiceAreaMatchConstraint) Evaluate(order *model.ServiceOrder, employe
 
 // =========================================
 // 2. TravelTimeBufferConstraint 路程时间缓冲
+// 配置路程服务时，订单间隔还须不少于两单地点间的路程时间
 // =========================================
 type TravelTimeBufferConstraint struct {
 	BaseDispatchConstraint
@@ -122,6 +139,16 @@ func (c *TravelTimeBufferConstraint) Evaluate(order *model.ServiceOrder, employe
 		if buffer > 0 && buffer < c.MinBufferMinutes {
 			return false, c.weight * 0.5, "订单间缓冲时间不足"
 		}
+		if buffer > 0 && order.Location != nil && existingOrder.Location != nil {
+			// 按先后顺序估算从前一单到后一单的路程
+			from, to := *existingOrder.Location, *order.Location
+			if existStart.After(orderEnd) {
+				from, to = to, from
+			}
+			if est, ok := ctx.estimate(from, to); ok && float64(buffer) < est.Minutes {
+				return false, c.weight * 0.5, fmt.Sprintf("订单间路程时间不足：需 %d 分钟，间隔 %d 分钟", int(math.Ceil(est.Minutes)), buffer)
+			}
+		}
 	}
 
 	return true, 0, ""
@@ -163,6 +190,8 @@ func (c *MaxOrdersPerDayConstraint) Evaluate(order *model.ServiceOrder, employee
 // =========================================
 type CustomerPreferenceConstraint struct {
 	BaseDispatchConstraint
+	PreferredBonus    float64 // 客户偏好员工的奖励
+	SameWorkerPenalty float64 // 客户要求同一服务者时，非偏好员工的惩罚
 }
 
 func NewCustomerPreferenceConstraint() *CustomerPreferenceConstraint {
@@ -172,6 +201,8 @@ func NewCustomerPreferenceConstraint() *CustomerPreferenceConstraint {
 			ctype:  "soft",
 			weight: 50,
 		},
+		PreferredBonus:    20,
+		SameWorkerPenalty: 30,
 	}
 }
 
@@ -194,7 +225,7 @@ func (c *CustomerPreferenceConstraint) Evaluate(order *model.ServiceOrder, emplo
 	for _, prefID := range ctx.Customer.PreferredEmpIDs {
 		if prefID == employee.ID {
 			isPreferred = true
-			penalty -= 20 // 奖励
+			penalty -= c.PreferredBonus // 奖励
 			break
 		}
 	}
@@ -205,7 +236,7 @@ func (c *CustomerPreferenceConstraint) Evaluate(order *model.ServiceOrder, emplo
 
 		// 要求同一服务者
 		if prefs.RequireSameWorker && !isPreferred && len(ctx.ServiceHistory) > 0 {
-			penalty += 30
+			penalty += c.SameWorkerPenalty
 		}
 	}
 
@@ -333,24 +364,133 @@ func NewSkillMatchConstraint() *SkillMatchConstraint {
 }
 
 func (c *SkillMatchConstraint) Evaluate(order *model.ServiceOrder, employee *model.Employee, ctx *DispatchContext) (bool, float64, string) {
-	if len(order.Skills) == 0 {
+	if len(order.Skills) == 0 && len(order.SkillGroups) == 0 {
 		return true, 0, ""
 	}
 
-	empSkills := make(map[string]bool)
-	for _, skill := range employee.Skills {
-		empSkills[skill] = true
+	if missing := employee.MissingSkills(order.Skills, order.SkillGroups); len(missing) > 0 {
+		return false, c.weight, "缺少必需技能: " + missing[0]
 	}
 
-	for _, reqSkill := range order.Skills {
-		if !empSkills[reqSkill] {
-			return false, c.weight, "缺少必需技能: " + reqSkill
-		}
+	return true, 0, ""
+}
+
+// =========================================
+// 8. LiveStatusConstraint 员工实时状态
+// =========================================
+type LiveStatusConstraint struct {
+	BaseDispatchConstraint
+	AvgSpeedKmh float64 // 估算路程时间使用的平均速度
+}
+
+func NewLiveStatusConstraint(avgSpeedKmh float64) *LiveStatusConstraint {
+	return &LiveStatusConstraint{
+		BaseDispatchConstraint: BaseDispatchConstraint{
+			name:   "LiveStatus",
+			ctype:  "hard",
+			weight: 1000,
+		},
+		AvgSpeedKmh: avgSpeedKmh,
 	}
+}
 
+func (c *LiveStatusConstraint) Evaluate(order *model.ServiceOrder, employee *model.Employee, ctx *DispatchContext) (bool, float64, string) {
+	status := ctx.LiveStatus
+	if status == nil {
+		// 无实时状态，跳过检查
+		return true, 0, ""
+	}
+
+	switch status.Status {
+	case model.LiveStatusOffDuty:
+		return false, c.weight, "员工已下班"
+	case model.LiveStatusOnBreak:
+		return true, 5, ""
+	case model.LiveStatusEnRoute:
+		return c.evaluateEnRoute(order, status, ctx)
+	}
 	return true, 0, ""
 }
 
+// evaluateEnRoute 估算途中员工到达订单地点的时间
+// 到达时间 = 到达当前目的地的时间 + 目的地到订单地点的路程时间
+func (c *LiveStatusConstraint) evaluateEnRoute(order *model.ServiceOrder, status *model.EmployeeLiveStatus, ctx *DispatchContext) (bool, float64, string) {
+	now := ctx.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	arrival := now
+	if status.ETA != nil && status.ETA.After(now) {
+		arrival = *status.ETA
+	}
+
+	from := status.Destination
+	if from == nil {
+		from = status.Location
+	}
+	if from != nil && order.Location != nil {
+		if est, ok := ctx.estimate(*from, *order.Location); ok {
+			arrival = arrival.Add(time.Duration(est.Minutes * float64(time.Minute)))
+		} else if c.AvgSpeedKmh > 0 {
+			arrival = arrival.Add(time.Duration(est.DistanceKm / c.AvgSpeedKmh * float64(time.Hour)))
+		}
+	}
+
+	orderStart, err := time.ParseInLocation("2006-01-02 15:04", order.ServiceDate+" "+order.StartTime, now.Location())
+	if err != nil {
+		// 无法确定订单开始时间，跳过检查
+		return true, 0, ""
+	}
+
+	if arrival.After(orderStart) {
+		late := int(math.Ceil(arrival.Sub(orderStart).Minutes()))
+		return false, c.weight, fmt.Sprintf("预计晚到 %d 分钟", late)
+	}
+
+	// 软惩罚：路程占用时间越长惩罚越高
+	penalty := arrival.Sub(now).Minutes() / 60 * 10
+	return true, penalty, ""
+}
+
+// =========================================
+// 9. DistinctCaregiverConstraint 客户服务人员数量
+// =========================================
+type DistinctCaregiverConstraint struct {
+	BaseDispatchConstraint
+	PenaltyPerCaregiver float64 // 客户每多一名已有服务人员，新人的惩罚增加值
+	CapPenalty          float64 // 超过客户服务人员上限时的惩罚
+}
+
+func NewDistinctCaregiverConstraint() *DistinctCaregiverConstraint {
+	return &DistinctCaregiverConstraint{
+		BaseDispatchConstraint: BaseDispatchConstraint{
+			name:   "DistinctCaregiver",
+			ctype:  "soft",
+			weight: 40,
+		},
+		PenaltyPerCaregiver: 10,
+		CapPenalty:          1000,
+	}
+}
+
+// Evaluate 窗口内已服务过该客户的员工不惩罚；新人按客户已有服务人员数递增惩罚（不超过权重），
+// 客户设置了上限且已达上限时新人不可派
+func (c *DistinctCaregiverConstraint) Evaluate(order *model.ServiceOrder, employee *model.Employee, ctx *DispatchContext) (bool, float64, string) {
+	for _, id := range ctx.WindowCaregivers {
+		if id == employee.ID {
+			return true, 0, ""
+		}
+	}
+
+	n := len(ctx.WindowCaregivers)
+	days, max := ctx.Customer.CaregiverWindow()
+	if max > 0 && n >= max {
+		return false, c.CapPenalty, fmt.Sprintf("客户近%d天已有%d名服务人员，达到上限%d", days, n, max)
+	}
+	return true, math.Min(float64(n)*c.PenaltyPerCaregiver, c.weight), ""
+}
+
 // =========================================
 // 辅助函数
 // =========================================
@@ -372,15 +512,8 @@ func calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
 	return earthRadius * c
 }
 
-// DefaultDispatchConstraints 返回默认派出约束集合
+// DefaultDispatchConstraints 返回默认派出约束集合（派单约束库中全部约束的默认参数和权重，见 Library）
 func DefaultDispatchConstraints() []DispatchConstraint {
-	return []DispatchConstraint{
-		NewServiceAreaMatchConstraint(20),  // 最大20km
-		NewTravelTimeBufferConstraint(30),  // 最小30分钟缓冲
-		NewMaxOrdersPerDayConstraint(8),    // 每日最多8单
-		NewCustomerPreferenceConstraint(),  // 客户偏好
-		NewCertificationLevelConstraint(),  // 资质检查
-		NewCaregiverContinuityConstraint(), // 连续性偏好
-		NewSkillMatchConstraint(),          // 技能匹配
-	}
+	constraints, _ := Build(nil)
+	return constraints
 }
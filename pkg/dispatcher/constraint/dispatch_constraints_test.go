@@ -2,8 +2,10 @@ package constraint
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/dispatcher/travel"
 	"github.com/paiban/paiban/pkg/model"
 )
 
@@ -146,17 +148,20 @@ func TestSkillMatchConstraint_Evaluate(t *testing.T) {
 		orderSkills []string
 		empSkills   []string
 		expected    bool
+		groups      []model.SkillGroup
 	}{
-		{"无技能要求", nil, []string{"cooking"}, true},
-		{"技能匹配", []string{"cooking"}, []string{"cooking", "cleaning"}, true},
-		{"技能不匹配", []string{"nursing"}, []string{"cooking"}, false},
-		{"多技能全匹配", []string{"a", "b"}, []string{"a", "b", "c"}, true},
-		{"多技能部分匹配", []string{"a", "b"}, []string{"a"}, false},
+		{"无技能要求", nil, []string{"cooking"}, true, nil},
+		{"技能匹配", []string{"cooking"}, []string{"cooking", "cleaning"}, true, nil},
+		{"技能不匹配", []string{"nursing"}, []string{"cooking"}, false, nil},
+		{"多技能全匹配", []string{"a", "b"}, []string{"a", "b", "c"}, true, nil},
+		{"多技能部分匹配", []string{"a", "b"}, []string{"a"}, false, nil},
+		{"技能组任选其一", nil, []string{"b"}, true, []model.SkillGroup{{Skills: []string{"a", "b"}}}},
+		{"技能组均不具备", nil, []string{"c"}, false, []model.SkillGroup{{Skills: []string{"a", "b"}}}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			order := &model.ServiceOrder{Skills: tt.orderSkills}
+			order := &model.ServiceOrder{Skills: tt.orderSkills, SkillGroups: tt.groups}
 			employee := &model.Employee{
 				BaseModel: model.BaseModel{ID: uuid.New()},
 				Skills:    tt.empSkills,
@@ -209,3 +214,126 @@ func TestTravelTimeBufferConstraint_NoExistingOrders(t *testing.T) {
 		t.Error("Should pass when no existing orders")
 	}
 }
+
+func TestLiveStatusConstraint_Evaluate(t *testing.T) {
+	constraint := NewLiveStatusConstraint(30)
+	now := time.Date(2026, 1, 11, 8, 0, 0, 0, time.Local)
+
+	order := &model.ServiceOrder{
+		ServiceDate: "2026-01-11",
+		StartTime:   "09:00",
+		Location:    &model.Location{Latitude: 39.91, Longitude: 116.41},
+	}
+	employee := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}}
+	eta := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+
+	tests := []struct {
+		name     string
+		status   *model.EmployeeLiveStatus
+		expected bool
+	}{
+		{"无状态", nil, true},
+		{"在岗", &model.EmployeeLiveStatus{Status: model.LiveStatusOnDuty}, true},
+		{"下班", &model.EmployeeLiveStatus{Status: model.LiveStatusOffDuty}, false},
+		{
+			name: "途中可及时到达",
+			status: &model.EmployeeLiveStatus{
+				Status:      model.LiveStatusEnRoute,
+				Destination: &model.Location{Latitude: 39.92, Longitude: 116.42},
+				ETA:         eta(20 * time.Minute),
+			},
+			expected: true,
+		},
+		{
+			name: "途中无法及时到达",
+			status: &model.EmployeeLiveStatus{
+				Status:      model.LiveStatusEnRoute,
+				Destination: &model.Location{Latitude: 39.92, Longitude: 116.42},
+				ETA:         eta(59 * time.Minute),
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &DispatchContext{LiveStatus: tt.status, Now: now}
+			passed, _, _ := constraint.Evaluate(order, employee, ctx)
+			if passed != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, passed)
+			}
+		})
+	}
+}
+
+func TestDistinctCaregiverConstraint_Evaluate(t *testing.T) {
+	constraint := NewDistinctCaregiverConstraint()
+	order := &model.ServiceOrder{ServiceDate: "2026-01-11"}
+	served := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}}
+	newcomer := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}}
+	window := []uuid.UUID{served.ID, uuid.New()}
+
+	ctx := &DispatchContext{WindowCaregivers: window}
+	if passed, penalty, _ := constraint.Evaluate(order, served, ctx); !passed || penalty != 0 {
+		t.Errorf("已服务过的员工不应惩罚，got %v %v", passed, penalty)
+	}
+	passed, penalty, _ := constraint.Evaluate(order, newcomer, ctx)
+	if !passed || penalty != 20 {
+		t.Errorf("新员工应按已有人数惩罚，got %v %v", passed, penalty)
+	}
+	if _, first, _ := constraint.Evaluate(order, newcomer, &DispatchContext{}); first != 0 {
+		t.Errorf("客户尚无服务人员时不应惩罚，got %v", first)
+	}
+
+	// 客户设置上限 2 人且已达上限
+	ctx.Customer = &model.Customer{Preferences: &model.CustomerPrefs{MaxCaregivers: 2}}
+	if passed, _, reason := constraint.Evaluate(order, newcomer, ctx); passed || reason == "" {
+		t.Error("达到客户服务人员上限时新员工不可派")
+	}
+	if passed, _, _ := constraint.Evaluate(order, served, ctx); !passed {
+		t.Error("达到上限时已服务过的员工仍可派")
+	}
+}
+
+// fixedTravel 固定倍率的路程估算（路网距离为直线距离的 1.5 倍，速度 20km/h）
+type fixedTravel struct{}
+
+func (fixedTravel) Name() string { return "fixed" }
+
+func (fixedTravel) Estimate(from, to model.Location) (travel.Estimate, error) {
+	km := from.Distance(to) * 1.5
+	return travel.Estimate{DistanceKm: km, Minutes: km / 20 * 60}, nil
+}
+
+func TestTravelProvider_Constraints(t *testing.T) {
+	employee := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}}
+
+	// 直线约 8.9km 在 10km 范围内，路网约 13.4km 超出范围
+	area := NewServiceAreaMatchConstraint(10)
+	order := &model.ServiceOrder{Location: &model.Location{Latitude: 39.98, Longitude: 116.40}}
+	ctx := &DispatchContext{EmployeeLocation: &model.Location{Latitude: 39.90, Longitude: 116.40}}
+	if ok, _, _ := area.Evaluate(order, employee, ctx); !ok {
+		t.Error("按直线距离应在服务范围内")
+	}
+	ctx.Travel = fixedTravel{}
+	if ok, _, _ := area.Evaluate(order, employee, ctx); ok {
+		t.Error("按路网距离应超出服务范围")
+	}
+
+	// 两单间隔 35 分钟，满足 30 分钟缓冲；路网约 13.4km 按 20km/h 需 41 分钟
+	buffer := NewTravelTimeBufferConstraint(30)
+	existing := &model.ServiceOrder{StartTime: "09:00", EndTime: "10:00", Location: &model.Location{Latitude: 39.90, Longitude: 116.40}}
+	next := &model.ServiceOrder{StartTime: "10:35", EndTime: "12:00", Location: order.Location}
+	ctx = &DispatchContext{EmployeeOrders: []*model.ServiceOrder{existing}}
+	if ok, _, v := buffer.Evaluate(next, employee, ctx); !ok {
+		t.Errorf("未配置路程服务时只检查缓冲时间: %s", v)
+	}
+	ctx.Travel = fixedTravel{}
+	if ok, _, v := buffer.Evaluate(next, employee, ctx); ok || v != "订单间路程时间不足：需 41 分钟，间隔 35 分钟" {
+		t.Errorf("路程时间不足应不可派: ok=%v, %s", ok, v)
+	}
+	next.StartTime = "10:45"
+	if ok, _, v := buffer.Evaluate(next, employee, ctx); !ok {
+		t.Errorf("间隔足够时应可派: %s", v)
+	}
+}
@@ -3,17 +3,27 @@ package dispatcher
 
 import (
 	"log"
+	"math"
 	"sort"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/paiban/paiban/pkg/dispatcher/constraint"
+	"github.com/paiban/paiban/pkg/dispatcher/routing"
+	"github.com/paiban/paiban/pkg/dispatcher/travel"
 	"github.com/paiban/paiban/pkg/model"
 )
 
 // DispatchEngine 派单引擎
 type DispatchEngine struct {
 	constraints []constraint.DispatchConstraint
+	tracker     *StatusTracker     // 员工实时状态（可选）
+	travel      TravelTimeProvider // 路程估算服务（可选，默认按直线距离）
 }
 
+// TravelTimeProvider 路程估算服务：直线距离、OSRM、高德或 Google（见 travel.New）
+type TravelTimeProvider = travel.Provider
+
 // NewDispatchEngine 创建派单引擎
 func NewDispatchEngine() *DispatchEngine {
 	return &DispatchEngine{
@@ -28,6 +38,28 @@ func NewDispatchEngineWithConstraints(constraints []constraint.DispatchConstrain
 	}
 }
 
+// WithConstraints 返回使用指定约束的派单引擎副本，实时状态和路程估算服务与原引擎相同
+func (e *DispatchEngine) WithConstraints(constraints []constraint.DispatchConstraint) *DispatchEngine {
+	c := *e
+	c.constraints = constraints
+	return &c
+}
+
+// SetStatusTracker 设置员工实时状态跟踪器，设置后派单会参考员工实时状态与位置
+func (e *DispatchEngine) SetStatusTracker(tracker *StatusTracker) {
+	e.tracker = tracker
+}
+
+// SetTravelProvider 设置路程估算服务，设置后服务距离、订单间路程时间和路线规划按该服务估算
+func (e *DispatchEngine) SetTravelProvider(provider TravelTimeProvider) {
+	e.travel = provider
+}
+
+// TravelProvider 返回路程估算服务（未设置时为nil）
+func (e *DispatchEngine) TravelProvider() TravelTimeProvider {
+	return e.travel
+}
+
 // DispatchRequest 派单请求
 type DispatchRequest struct {
 	Order          *model.ServiceOrder
@@ -35,7 +67,11 @@ type DispatchRequest struct {
 	Customer       *model.Customer
 	TodayOrders    []*model.ServiceOrder
 	ServiceHistory []model.CustomerEmployeeHistory
+	KeepApart      []model.KeepApartRule // 不可同组规则（团队订单使用）
 	MaxResults     int
+
+	WindowCaregivers []uuid.UUID // 滚动窗口内为客户服务过的不同服务人员（用于控制客户的服务人员数量）
+	Now              time.Time   // 评估时间（零值表示当前时间）
 }
 
 // DispatchResponse 派单响应
@@ -45,6 +81,7 @@ type DispatchResponse struct {
 	BestMatch    *CandidateScore  `json:"best_match,omitempty"`
 	Alternatives []CandidateScore `json:"alternatives,omitempty"`
 	Reason       string           `json:"reason,omitempty"`
+	Crew         []CrewMember     `json:"crew,omitempty"` // 团队订单的成员构成（best_match 为带队人）
 }
 
 // CandidateScore 候选人评分
@@ -69,17 +106,12 @@ func (e *DispatchEngine) Dispatch(req *DispatchRequest) *DispatchResponse {
 
 	log.Printf("开始派单: 订单=%s, 候选人=%d", req.Order.OrderNo, len(req.Candidates))
 
-	// 评估所有候选人
-	scores := e.evaluateCandidates(req)
+	if req.Order.IsTeamOrder() {
+		return e.dispatchTeam(req)
+	}
 
-	// 按分数排序（分数越低越好）
-	sort.Slice(scores, func(i, j int) bool {
-		// 可行解优先
-		if scores[i].Feasible != scores[j].Feasible {
-			return scores[i].Feasible
-		}
-		return scores[i].Score < scores[j].Score
-	})
+	// 评估所有候选人并排序
+	scores := e.rankCandidates(req)
 
 	// 过滤可行解
 	var feasibleScores []CandidateScore
@@ -122,6 +154,22 @@ func (e *DispatchEngine) Dispatch(req *DispatchRequest) *DispatchResponse {
 	return response
 }
 
+// rankCandidates 评估所有候选人，按分数排序（可行解优先，分数越低越好）
+func (e *DispatchEngine) rankCandidates(req *DispatchRequest) []CandidateScore {
+	return sortScores(e.evaluateCandidates(req))
+}
+
+// sortScores 按可行优先、分数升序排序候选人
+func sortScores(scores []CandidateScore) []CandidateScore {
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Feasible != scores[j].Feasible {
+			return scores[i].Feasible
+		}
+		return scores[i].Score < scores[j].Score
+	})
+	return scores
+}
+
 // evaluateCandidates 评估所有候选人
 func (e *DispatchEngine) evaluateCandidates(req *DispatchRequest) []CandidateScore {
 	scores := make([]CandidateScore, 0, len(req.Candidates))
@@ -145,7 +193,7 @@ func (e *DispatchEngine) evaluateCandidate(employee *model.Employee, req *Dispat
 	// 获取员工今日已分配订单
 	var employeeOrders []*model.ServiceOrder
 	for _, order := range req.TodayOrders {
-		if order.EmployeeID != nil && *order.EmployeeID == employee.ID {
+		if order.HasEmployee(employee.ID) {
 			employeeOrders = append(employeeOrders, order)
 		}
 	}
@@ -157,6 +205,27 @@ func (e *DispatchEngine) evaluateCandidate(employee *model.Employee, req *Dispat
 		EmployeeOrders:   employeeOrders,
 		ServiceHistory:   req.ServiceHistory,
 		EmployeeLocation: employee.HomeLocation, // 使用员工的家庭位置
+		WindowCaregivers: req.WindowCaregivers,
+		Travel:           e.travel,
+		Now:              req.Now,
+	}
+
+	// 有实时状态时优先使用上报位置
+	if e.tracker != nil {
+		ctx.LiveStatus = e.tracker.Get(employee.ID)
+		if ctx.LiveStatus != nil && ctx.LiveStatus.Location != nil {
+			ctx.EmployeeLocation = ctx.LiveStatus.Location
+		}
+	}
+
+	if req.Order.Location != nil && ctx.EmployeeLocation != nil {
+		score.Distance = round2(ctx.EmployeeLocation.Distance(*req.Order.Location))
+		if e.travel != nil {
+			if est, err := e.travel.Estimate(*ctx.EmployeeLocation, *req.Order.Location); err == nil {
+				score.Distance = round2(est.DistanceKm)
+				score.TravelTime = int(math.Ceil(est.Minutes))
+			}
+		}
 	}
 
 	// 评估所有约束
@@ -181,18 +250,35 @@ func (e *DispatchEngine) evaluateCandidate(employee *model.Employee, req *Dispat
 
 // BatchDispatch 批量派单
 func (e *DispatchEngine) BatchDispatch(orders []*model.ServiceOrder, candidates []*model.Employee, customer *model.Customer) []*DispatchResponse {
+	return e.BatchDispatchWithRules(orders, candidates, customer, nil)
+}
+
+// BatchDispatchWithRules 批量派单（团队订单遵守不可同组规则）
+func (e *DispatchEngine) BatchDispatchWithRules(orders []*model.ServiceOrder, candidates []*model.Employee, customer *model.Customer, keepApart []model.KeepApartRule) []*DispatchResponse {
+	return e.BatchDispatchWithCaregivers(orders, candidates, customer, keepApart, nil)
+}
+
+// BatchDispatchWithCaregivers 批量派单，caregivers 为各客户（按客户ID）滚动窗口内已有的服务人员，
+// 本批次派出的人员会计入对应客户，使同一客户的后续订单优先派给已服务过的人员
+func (e *DispatchEngine) BatchDispatchWithCaregivers(orders []*model.ServiceOrder, candidates []*model.Employee, customer *model.Customer, keepApart []model.KeepApartRule, caregivers map[uuid.UUID][]uuid.UUID) []*DispatchResponse {
 	responses := make([]*DispatchResponse, len(orders))
 
 	// 已分配的订单（用于避免时间冲突）
 	assignedOrders := make([]*model.ServiceOrder, 0)
+	window := make(map[uuid.UUID][]uuid.UUID, len(caregivers))
+	for customerID, ids := range caregivers {
+		window[customerID] = append([]uuid.UUID(nil), ids...)
+	}
 
 	for i, order := range orders {
 		req := &DispatchRequest{
-			Order:       order,
-			Candidates:  candidates,
-			Customer:    customer,
-			TodayOrders: assignedOrders,
-			MaxResults:  3,
+			Order:            order,
+			Candidates:       candidates,
+			Customer:         customer,
+			TodayOrders:      assignedOrders,
+			KeepApart:        keepApart,
+			MaxResults:       3,
+			WindowCaregivers: window[order.CustomerID],
 		}
 
 		resp := e.Dispatch(req)
@@ -202,14 +288,37 @@ func (e *DispatchEngine) BatchDispatch(orders []*model.ServiceOrder, candidates
 		if resp.Success && resp.BestMatch != nil {
 			orderCopy := *order
 			orderCopy.EmployeeID = &resp.BestMatch.Employee.ID
+			orderCopy.EmployeeIDs = resp.CrewIDs()
 			orderCopy.Status = "assigned"
 			assignedOrders = append(assignedOrders, &orderCopy)
+			if order.CustomerID != uuid.Nil {
+				window[order.CustomerID] = appendCaregivers(window[order.CustomerID], orderCopy.EmployeeID, orderCopy.EmployeeIDs)
+			}
 		}
 	}
 
 	return responses
 }
 
+// appendCaregivers 将订单的服务人员（带队人和团队成员）去重追加到客户的服务人员列表
+func appendCaregivers(ids []uuid.UUID, lead *uuid.UUID, crew []uuid.UUID) []uuid.UUID {
+	add := func(id uuid.UUID) {
+		for _, existing := range ids {
+			if existing == id {
+				return
+			}
+		}
+		ids = append(ids, id)
+	}
+	if lead != nil {
+		add(*lead)
+	}
+	for _, id := range crew {
+		add(id)
+	}
+	return ids
+}
+
 // limitCandidates 限制候选人数量
 func limitCandidates(scores []CandidateScore, max int) []CandidateScore {
 	if len(scores) <= max {
@@ -218,46 +327,15 @@ func limitCandidates(scores []CandidateScore, max int) []CandidateScore {
 	return scores[:max]
 }
 
-// OptimalRoute 计算最优路线
+// OptimalRoute 计算最优路线：按时间窗和路程优化访问顺序（见 routing.Optimize），
+// 订单无法规划（如服务日期不一致、时间格式无效）时保持原顺序
 func (e *DispatchEngine) OptimalRoute(orders []*model.ServiceOrder, startLocation *model.Location) []*model.ServiceOrder {
 	if len(orders) <= 1 || startLocation == nil {
 		return orders
 	}
-
-	// 简单贪心算法：每次选择最近的订单
-	result := make([]*model.ServiceOrder, 0, len(orders))
-	remaining := make([]*model.ServiceOrder, len(orders))
-	copy(remaining, orders)
-
-	currentLoc := *startLocation
-
-	for len(remaining) > 0 {
-		// 找最近的订单
-		minDist := -1.0
-		minIdx := 0
-
-		for i, order := range remaining {
-			if order.Location == nil {
-				continue
-			}
-			dist := currentLoc.Distance(*order.Location)
-			if minDist < 0 || dist < minDist {
-				minDist = dist
-				minIdx = i
-			}
-		}
-
-		// 添加到结果
-		result = append(result, remaining[minIdx])
-
-		// 更新当前位置
-		if remaining[minIdx].Location != nil {
-			currentLoc = *remaining[minIdx].Location
-		}
-
-		// 移除已处理订单
-		remaining = append(remaining[:minIdx], remaining[minIdx+1:]...)
+	route, err := routing.Optimize(orders, startLocation, routing.Options{Travel: e.travel})
+	if err != nil {
+		return orders
 	}
-
-	return result
+	return route.Sequence()
 }
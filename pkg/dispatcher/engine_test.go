@@ -2,6 +2,7 @@ package dispatcher
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/paiban/paiban/pkg/model"
@@ -137,6 +138,51 @@ func TestDispatchEngine_BatchDispatch(t *testing.T) {
 	}
 }
 
+func TestDispatchEngine_BatchDispatchWithCaregivers(t *testing.T) {
+	engine := NewDispatchEngine()
+	newEmp := func(name string) *model.Employee {
+		return &model.Employee{
+			BaseModel:    model.BaseModel{ID: uuid.New()},
+			Name:         name,
+			Status:       "active",
+			HomeLocation: &model.Location{Latitude: 39.9, Longitude: 116.4},
+		}
+	}
+	a, b := newEmp("员工A"), newEmp("员工B")
+	customer := &model.Customer{
+		BaseModel:   model.BaseModel{ID: uuid.New()},
+		Preferences: &model.CustomerPrefs{MaxCaregivers: 1},
+	}
+	newOrder := func(no, start, end string) *model.ServiceOrder {
+		return &model.ServiceOrder{
+			BaseModel:   model.BaseModel{ID: uuid.New()},
+			CustomerID:  customer.ID,
+			OrderNo:     no,
+			ServiceDate: "2026-01-11",
+			StartTime:   start,
+			EndTime:     end,
+			Status:      "pending",
+			Location:    &model.Location{Latitude: 39.91, Longitude: 116.41},
+		}
+	}
+	orders := []*model.ServiceOrder{newOrder("ORD001", "09:00", "10:00"), newOrder("ORD002", "13:00", "14:00")}
+
+	// 客户近期由员工B服务且上限 1 人，两单都应派给员工B
+	results := engine.BatchDispatchWithCaregivers(orders, []*model.Employee{a, b}, customer, nil,
+		map[uuid.UUID][]uuid.UUID{customer.ID: {b.ID}})
+	for i, r := range results {
+		if !r.Success || r.BestMatch.Employee.ID != b.ID {
+			t.Errorf("订单 %d 应派给已服务过的员工B: %+v", i, r.BestMatch)
+		}
+	}
+
+	// 无历史时第一单派出的员工计入本批次，第二单不能换人
+	results = engine.BatchDispatchWithCaregivers(orders, []*model.Employee{a, b}, customer, nil, nil)
+	if !results[0].Success || !results[1].Success || results[0].BestMatch.Employee.ID != results[1].BestMatch.Employee.ID {
+		t.Error("同一客户的订单应派给同一名员工")
+	}
+}
+
 func TestDispatchEngine_OptimalRoute(t *testing.T) {
 	engine := NewDispatchEngine()
 
@@ -171,3 +217,49 @@ func TestDispatchEngine_OptimalRoute(t *testing.T) {
 		t.Errorf("Expected ORD2 first, got %s", result[0].OrderNo)
 	}
 }
+
+func TestDispatchEngine_Dispatch_SkipsOffDuty(t *testing.T) {
+	engine := NewDispatchEngine()
+	tracker := NewStatusTracker(DefaultStatusTTL)
+	engine.SetStatusTracker(tracker)
+
+	offDuty := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "下班员工", Status: "active"}
+	onDuty := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "在岗员工", Status: "active"}
+	tracker.Report(&model.EmployeeLiveStatus{EmployeeID: offDuty.ID, Status: model.LiveStatusOffDuty})
+	tracker.Report(&model.EmployeeLiveStatus{EmployeeID: onDuty.ID, Status: model.LiveStatusOnDuty})
+
+	order := &model.ServiceOrder{BaseModel: model.BaseModel{ID: uuid.New()}, OrderNo: "ORD001"}
+	result := engine.Dispatch(&DispatchRequest{Order: order, Candidates: []*model.Employee{offDuty, onDuty}})
+
+	if !result.Success || result.BestMatch.Employee.ID != onDuty.ID {
+		t.Fatalf("应派给在岗员工, got %+v", result)
+	}
+	for _, alt := range result.Alternatives {
+		if alt.Employee.ID == offDuty.ID {
+			t.Error("下班员工不应出现在可行备选中")
+		}
+	}
+}
+
+func TestStatusTracker_Expiry(t *testing.T) {
+	tracker := NewStatusTracker(time.Minute)
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+
+	id := uuid.New()
+	tracker.Report(&model.EmployeeLiveStatus{EmployeeID: id, Status: model.LiveStatusOnDuty})
+	if tracker.Get(id) == nil {
+		t.Fatal("刚上报的状态应可获取")
+	}
+
+	// 乱序到达的旧上报不应覆盖新状态
+	tracker.Report(&model.EmployeeLiveStatus{EmployeeID: id, Status: model.LiveStatusOffDuty, ReportedAt: now.Add(-time.Second)})
+	if tracker.Get(id).Status != model.LiveStatusOnDuty {
+		t.Error("旧上报不应覆盖新状态")
+	}
+
+	tracker.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if tracker.Get(id) != nil || len(tracker.List()) != 0 {
+		t.Error("过期状态应视为未知")
+	}
+}
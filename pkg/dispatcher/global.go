@@ -0,0 +1,284 @@
+package dispatcher
+
+import (
+	"log"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 批量派单的优化方式
+const (
+	OptimizationGreedy = "greedy" // 逐单贪心：按订单顺序依次派给当前最优员工
+	OptimizationGlobal = "global" // 全局优化：所有订单与员工联合求最小代价分配
+)
+
+// BatchQuality 批量派单质量指标（分数越低越好，与候选人评分一致）
+type BatchQuality struct {
+	Optimization         string  `json:"optimization"`
+	AssignedOrders       int     `json:"assigned_orders"`
+	FillRate             float64 `json:"fill_rate"`               // 派单成功率（%）
+	TotalScore           float64 `json:"total_score"`             // 已派订单最佳匹配分数之和
+	AverageScore         float64 `json:"average_score"`           // 已派订单最佳匹配的平均分数
+	TotalDistanceKm      float64 `json:"total_distance_km"`       // 员工位置到订单位置的距离之和
+	AverageDistanceKm    float64 `json:"average_distance_km"`     // 有位置信息的已派订单的平均距离
+	MaxOrdersPerEmployee int     `json:"max_orders_per_employee"` // 单个员工（带队人）最多承接的订单数
+}
+
+// EvaluateBatch 汇总批量派单结果的质量指标
+func EvaluateBatch(responses []*DispatchResponse, optimization string) *BatchQuality {
+	q := &BatchQuality{Optimization: optimization}
+	perEmployee := make(map[uuid.UUID]int)
+	located := 0
+	for _, resp := range responses {
+		if !resp.Success || resp.BestMatch == nil {
+			continue
+		}
+		q.AssignedOrders++
+		q.TotalScore += resp.BestMatch.Score
+		if resp.BestMatch.Distance > 0 {
+			q.TotalDistanceKm += resp.BestMatch.Distance
+			located++
+		}
+		id := resp.BestMatch.Employee.ID
+		perEmployee[id]++
+		if perEmployee[id] > q.MaxOrdersPerEmployee {
+			q.MaxOrdersPerEmployee = perEmployee[id]
+		}
+	}
+	if len(responses) > 0 {
+		q.FillRate = round2(float64(q.AssignedOrders) / float64(len(responses)) * 100)
+	}
+	if q.AssignedOrders > 0 {
+		q.AverageScore = round2(q.TotalScore / float64(q.AssignedOrders))
+	}
+	if located > 0 {
+		q.AverageDistanceKm = round2(q.TotalDistanceKm / float64(located))
+	}
+	q.TotalScore = round2(q.TotalScore)
+	q.TotalDistanceKm = round2(q.TotalDistanceKm)
+	return q
+}
+
+// BatchDispatchGlobal 全局优化的批量派单
+// 逐单贪心时排在前面的订单会先占用离得近的员工，使后面的订单只能派给远处的员工甚至无人可派。
+// 全局优化按轮次求解：每轮以当前已派订单为上下文评估全部待派订单与员工的分数，
+// 用匈牙利算法求每名员工至多承接一单的最小代价分配（先使派出订单数最多，再使分数之和最小），
+// 派出的订单计入下一轮的上下文（时间冲突、每日单量、客户服务人员），直到没有订单可派。
+// 团队订单需要多人同时上门，先按订单顺序派出，再对单人订单做全局优化
+func (e *DispatchEngine) BatchDispatchGlobal(orders []*model.ServiceOrder, candidates []*model.Employee, customer *model.Customer, keepApart []model.KeepApartRule, caregivers map[uuid.UUID][]uuid.UUID) []*DispatchResponse {
+	responses := make([]*DispatchResponse, len(orders))
+	assignedOrders := make([]*model.ServiceOrder, 0, len(orders))
+	window := make(map[uuid.UUID][]uuid.UUID, len(caregivers))
+	for customerID, ids := range caregivers {
+		window[customerID] = append([]uuid.UUID(nil), ids...)
+	}
+	assign := func(order *model.ServiceOrder, resp *DispatchResponse) {
+		orderCopy := *order
+		orderCopy.EmployeeID = &resp.BestMatch.Employee.ID
+		orderCopy.EmployeeIDs = resp.CrewIDs()
+		orderCopy.Status = "assigned"
+		assignedOrders = append(assignedOrders, &orderCopy)
+		if order.CustomerID != uuid.Nil {
+			window[order.CustomerID] = appendCaregivers(window[order.CustomerID], orderCopy.EmployeeID, orderCopy.EmployeeIDs)
+		}
+	}
+	request := func(order *model.ServiceOrder) *DispatchRequest {
+		return &DispatchRequest{
+			Order:            order,
+			Candidates:       candidates,
+			Customer:         customer,
+			TodayOrders:      assignedOrders,
+			KeepApart:        keepApart,
+			MaxResults:       3,
+			WindowCaregivers: window[order.CustomerID],
+		}
+	}
+
+	var pending []int
+	for i, order := range orders {
+		if order == nil {
+			responses[i] = e.Dispatch(&DispatchRequest{Candidates: candidates})
+			continue
+		}
+		if !order.IsTeamOrder() || len(candidates) == 0 {
+			pending = append(pending, i)
+			continue
+		}
+		resp := e.Dispatch(request(order))
+		responses[i] = resp
+		if resp.Success && resp.BestMatch != nil {
+			assign(order, resp)
+		}
+	}
+
+	rounds := 0
+	for len(pending) > 0 && len(candidates) > 0 {
+		rounds++
+		ranked := make([][]CandidateScore, len(pending))
+		matrix := make([][]CandidateScore, len(pending))
+		for r, i := range pending {
+			matrix[r] = e.evaluateCandidates(request(orders[i]))
+			ranked[r] = sortScores(append([]CandidateScore(nil), matrix[r]...))
+		}
+
+		chosen := solveAssignment(matrix)
+		var next []int
+		for r, i := range pending {
+			c := chosen[r]
+			if c < 0 {
+				next = append(next, i)
+				continue
+			}
+			best := matrix[r][c]
+			resp := &DispatchResponse{OrderID: orders[i].OrderNo, Success: true, BestMatch: &best}
+			for _, s := range ranked[r] {
+				if s.Feasible && s.Employee.ID != best.Employee.ID && len(resp.Alternatives) < 2 {
+					resp.Alternatives = append(resp.Alternatives, s)
+				}
+			}
+			responses[i] = resp
+		}
+		// 本轮的分配全部确定后再计入上下文
+		for r, i := range pending {
+			if chosen[r] >= 0 {
+				assign(orders[i], responses[i])
+			}
+		}
+		if len(next) == len(pending) {
+			for r, i := range pending {
+				responses[i] = &DispatchResponse{
+					OrderID:      orders[i].OrderNo,
+					Success:      false,
+					Reason:       "没有符合条件的员工",
+					Alternatives: limitCandidates(ranked[r], 3),
+				}
+			}
+			break
+		}
+		pending = next
+	}
+	for i, resp := range responses {
+		if resp == nil {
+			responses[i] = e.Dispatch(request(orders[i]))
+		}
+	}
+
+	log.Printf("全局批量派单完成: 订单=%d, 候选人=%d, 轮次=%d", len(orders), len(candidates), rounds)
+	return responses
+}
+
+// solveAssignment 求订单（行）与员工（列）的最小代价分配，每名员工至多承接一单；
+// 不可行的组合不分配。返回每行分配的列，未分配为 -1
+func solveAssignment(matrix [][]CandidateScore) []int {
+	rows := len(matrix)
+	cols := 0
+	spread := 1.0
+	for _, row := range matrix {
+		if len(row) > cols {
+			cols = len(row)
+		}
+		for _, s := range row {
+			if s.Feasible {
+				spread += math.Abs(s.Score)
+			}
+		}
+	}
+	// 不可行组合的代价大于任意可行分配的分数之和，保证先使派出订单数最多
+	forbidden := spread * 2
+	n := rows
+	if cols > n {
+		n = cols
+	}
+	cost := make([][]float64, n)
+	for r := range cost {
+		cost[r] = make([]float64, n)
+		for c := range cost[r] {
+			switch {
+			case r >= rows:
+				cost[r][c] = 0 // 虚拟订单
+			case c < len(matrix[r]) && matrix[r][c].Feasible:
+				cost[r][c] = matrix[r][c].Score
+			default:
+				cost[r][c] = forbidden
+			}
+		}
+	}
+
+	result := make([]int, rows)
+	for r, c := range hungarian(cost) {
+		if r >= rows {
+			continue
+		}
+		if c < len(matrix[r]) && matrix[r][c].Feasible {
+			result[r] = c
+		} else {
+			result[r] = -1
+		}
+	}
+	return result
+}
+
+// hungarian 匈牙利算法求 n×n 代价矩阵的最小代价完全匹配，返回每行匹配的列
+func hungarian(cost [][]float64) []int {
+	n := len(cost)
+	u := make([]float64, n+1)
+	v := make([]float64, n+1)
+	p := make([]int, n+1) // p[j] 为与列 j 匹配的行（1 起）
+	way := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]float64, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = math.Inf(1)
+		}
+		for {
+			used[j0] = true
+			i0, delta, j1 := p[j0], math.Inf(1), 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				cur := cost[i0-1][j-1] - u[i0] - v[j]
+				if cur < minv[j] {
+					minv[j], way[j] = cur, j0
+				}
+				if minv[j] < delta {
+					delta, j1 = minv[j], j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+			if p[j0] == 0 {
+				break
+			}
+		}
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+	match := make([]int, n)
+	for j := 1; j <= n; j++ {
+		if p[j] > 0 {
+			match[p[j]-1] = j - 1
+		}
+	}
+	return match
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
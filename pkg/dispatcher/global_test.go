@@ -0,0 +1,138 @@
+package dispatcher
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func newGlobalOrder(no, start, end string, lat float64) *model.ServiceOrder {
+	return &model.ServiceOrder{
+		BaseModel:   model.BaseModel{ID: uuid.New()},
+		OrderNo:     no,
+		ServiceDate: "2026-01-11",
+		StartTime:   start,
+		EndTime:     end,
+		Status:      "pending",
+		Skills:      []string{"cleaning"},
+		Location:    &model.Location{Latitude: lat, Longitude: 116.40},
+	}
+}
+
+func newGlobalEmployee(name string, lat float64) *model.Employee {
+	return &model.Employee{
+		BaseModel:    model.BaseModel{ID: uuid.New()},
+		Name:         name,
+		Skills:       []string{"cleaning"},
+		Status:       "active",
+		HomeLocation: &model.Location{Latitude: lat, Longitude: 116.40},
+	}
+}
+
+func TestDispatchEngine_BatchDispatchGlobal(t *testing.T) {
+	engine := NewDispatchEngine()
+	// 张三离两单都在服务范围内，李四只能服务第一单；两单时间重叠
+	zhang := newGlobalEmployee("张三", 39.90)
+	li := newGlobalEmployee("李四", 39.75)
+	orders := []*model.ServiceOrder{
+		newGlobalOrder("ORD1", "09:00", "11:00", 39.90),
+		newGlobalOrder("ORD2", "09:00", "11:00", 40.05),
+	}
+	candidates := []*model.Employee{zhang, li}
+
+	// 逐单贪心：第一单占用张三，第二单无人可派
+	greedy := engine.BatchDispatch(orders, candidates, nil)
+	if !greedy[0].Success || greedy[0].BestMatch.Employee.Name != "张三" || greedy[1].Success {
+		t.Fatalf("贪心派单结果不符合预期: %+v %+v", greedy[0], greedy[1])
+	}
+
+	global := engine.BatchDispatchGlobal(orders, candidates, nil, nil, nil)
+	if !global[0].Success || !global[1].Success {
+		t.Fatalf("全局优化应派出全部订单: %+v %+v", global[0], global[1])
+	}
+	if global[0].BestMatch.Employee.Name != "李四" || global[1].BestMatch.Employee.Name != "张三" {
+		t.Errorf("分配 = %s, %s", global[0].BestMatch.Employee.Name, global[1].BestMatch.Employee.Name)
+	}
+
+	gq, q := EvaluateBatch(greedy, OptimizationGreedy), EvaluateBatch(global, OptimizationGlobal)
+	if gq.AssignedOrders != 1 || gq.FillRate != 50 || q.AssignedOrders != 2 || q.FillRate != 100 {
+		t.Errorf("质量指标: greedy=%+v global=%+v", gq, q)
+	}
+	if q.AverageDistanceKm < 16 || q.AverageDistanceKm > 17 || q.MaxOrdersPerEmployee != 1 {
+		t.Errorf("全局优化质量指标 = %+v", q)
+	}
+}
+
+func TestDispatchEngine_BatchDispatchGlobal_Rounds(t *testing.T) {
+	engine := NewDispatchEngine()
+	// 只有一名员工：时间不冲突的订单在后续轮次派给同一人，冲突的订单无人可派
+	emp := newGlobalEmployee("张三", 39.90)
+	orders := []*model.ServiceOrder{
+		newGlobalOrder("ORD1", "09:00", "10:00", 39.90),
+		newGlobalOrder("ORD2", "14:00", "15:00", 39.91),
+		newGlobalOrder("ORD3", "09:30", "10:30", 39.92),
+		nil,
+	}
+	results := engine.BatchDispatchGlobal(orders, []*model.Employee{emp}, nil, nil, nil)
+	if len(results) != 4 {
+		t.Fatalf("results = %d", len(results))
+	}
+	assigned := 0
+	for _, r := range results[:3] {
+		if r.Success {
+			assigned++
+		}
+	}
+	if assigned != 2 || !results[1].Success || results[3].Success {
+		t.Errorf("派单结果: %+v %+v %+v %+v", results[0], results[1], results[2], results[3])
+	}
+	if q := EvaluateBatch(results, OptimizationGlobal); q.MaxOrdersPerEmployee != 2 {
+		t.Errorf("质量指标 = %+v", q)
+	}
+}
+
+func TestHungarian(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 1; n <= 6; n++ {
+		cost := make([][]float64, n)
+		for i := range cost {
+			cost[i] = make([]float64, n)
+			for j := range cost[i] {
+				cost[i][j] = float64(rng.Intn(40) - 10)
+			}
+		}
+		got := 0.0
+		for i, j := range hungarian(cost) {
+			got += cost[i][j]
+		}
+		if want := bruteForce(cost); math.Abs(got-want) > 1e-9 {
+			t.Errorf("n=%d: hungarian = %v, want %v", n, got, want)
+		}
+	}
+}
+
+// bruteForce 枚举全部排列求最小代价
+func bruteForce(cost [][]float64) float64 {
+	n := len(cost)
+	used := make([]bool, n)
+	best := math.Inf(1)
+	var walk func(i int, sum float64)
+	walk = func(i int, sum float64) {
+		if i == n {
+			best = math.Min(best, sum)
+			return
+		}
+		for j := 0; j < n; j++ {
+			if !used[j] {
+				used[j] = true
+				walk(i+1, sum+cost[i][j])
+				used[j] = false
+			}
+		}
+	}
+	walk(0, 0)
+	return best
+}
@@ -0,0 +1,265 @@
+package dispatcher
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// reassignSpeedKmh 未配置路程服务时估算赶往订单地点的平均速度
+const reassignSpeedKmh = 30
+
+// ReassignRequest 改派请求：订单的服务人员临时不可用（如当天请假），为该订单及其当日后续订单寻找替补
+type ReassignRequest struct {
+	Order                 *model.ServiceOrder
+	UnavailableEmployeeID uuid.UUID // 不可用的服务人员，为空时取订单的带队人
+	Candidates            []*model.Employee
+	Customer              *model.Customer   // 订单的客户
+	Customers             []*model.Customer // 后续订单的客户（按客户ID匹配）
+	TodayOrders           []*model.ServiceOrder
+	ServiceHistory        []model.CustomerEmployeeHistory
+	MaxResults            int
+	Now                   time.Time // 改派时间（零值表示当前时间），用于判断替补能否按时到达
+}
+
+// ReassignResult 改派结果
+type ReassignResult struct {
+	OrderID      string           `json:"order_id"`
+	Success      bool             `json:"success"`
+	Substitute   *CandidateScore  `json:"substitute,omitempty"`
+	Alternatives []CandidateScore `json:"alternatives,omitempty"`
+	Reason       string           `json:"reason,omitempty"`
+
+	// 受影响订单（本订单及不可用人员当日尚未开始的后续订单）的改派方案，按开始时间排序
+	Plan       []ReassignStep `json:"plan"`
+	Unresolved int            `json:"unresolved"` // 未找到替补的订单数
+}
+
+// ReassignStep 单个受影响订单的改派方案
+type ReassignStep struct {
+	OrderID            string              `json:"order_id"`
+	PreviousEmployeeID uuid.UUID           `json:"previous_employee_id"`
+	Success            bool                `json:"success"`
+	Substitute         *CandidateScore     `json:"substitute,omitempty"`
+	Reason             string              `json:"reason,omitempty"`
+	Order              *model.ServiceOrder `json:"order"` // 改派后的订单（未找到替补时为待派单状态）
+}
+
+// Reassign 改派：按开始时间依次为受影响订单选择替补，已选替补的订单计入其当日订单，
+// 候选人须满足派单约束（时间冲突、路程缓冲、客户偏好等），且能从当前位置按时赶到订单地点
+// 团队订单只替换不可用的成员，其余成员保持不变
+func (e *DispatchEngine) Reassign(req *ReassignRequest) *ReassignResult {
+	if req.Order == nil {
+		return &ReassignResult{Reason: "缺少订单"}
+	}
+	result := &ReassignResult{OrderID: req.Order.OrderNo, Plan: make([]ReassignStep, 0)}
+
+	unavailable := req.UnavailableEmployeeID
+	if unavailable == uuid.Nil && req.Order.EmployeeID != nil {
+		unavailable = *req.Order.EmployeeID
+	}
+	if unavailable == uuid.Nil || !req.Order.HasEmployee(unavailable) {
+		result.Reason = "订单未分配给不可用的服务人员"
+		return result
+	}
+	now := req.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	maxResults := req.MaxResults
+	if maxResults <= 0 {
+		maxResults = 5
+	}
+
+	log.Printf("开始改派: 订单=%s, 不可用人员=%s, 候选人=%d", req.Order.OrderNo, unavailable, len(req.Candidates))
+
+	affected, base := splitAffectedOrders(req.Order, req.TodayOrders, unavailable)
+	customers := make(map[uuid.UUID]*model.Customer, len(req.Customers)+1)
+	for _, c := range req.Customers {
+		if c != nil {
+			customers[c.ID] = c
+		}
+	}
+	if req.Customer != nil {
+		customers[req.Order.CustomerID] = req.Customer
+	}
+
+	for i, order := range affected {
+		step := ReassignStep{OrderID: order.OrderNo, PreviousEmployeeID: unavailable}
+		revised := releaseEmployee(order, unavailable)
+
+		candidates := make([]*model.Employee, 0, len(req.Candidates))
+		for _, emp := range req.Candidates {
+			if emp != nil && emp.ID != unavailable && !revised.HasEmployee(emp.ID) {
+				candidates = append(candidates, emp)
+			}
+		}
+
+		// 团队订单按单人名额评估替补
+		single := *revised
+		single.RequiredHeadcount, single.Roles = 0, nil
+		scores := e.evaluateCandidates(&DispatchRequest{
+			Order:          &single,
+			Candidates:     candidates,
+			Customer:       customers[order.CustomerID],
+			TodayOrders:    base,
+			ServiceHistory: req.ServiceHistory,
+			Now:            now,
+		})
+		for j := range scores {
+			e.checkArrival(&scores[j], order, base, now)
+		}
+		scores = sortScores(scores)
+
+		if len(scores) > 0 && scores[0].Feasible {
+			best := scores[0]
+			step.Success, step.Substitute = true, &best
+			assignSubstitute(revised, order, unavailable, best.Employee.ID)
+			base = append(base, revised)
+		} else {
+			step.Reason = "没有符合条件的替补人员"
+			if len(candidates) == 0 {
+				step.Reason = "没有候选人"
+			}
+			result.Unresolved++
+		}
+		step.Order = revised
+		result.Plan = append(result.Plan, step)
+
+		if i == 0 {
+			result.Success, result.Substitute, result.Reason = step.Success, step.Substitute, step.Reason
+			var rest []CandidateScore
+			for _, s := range scores {
+				if step.Substitute == nil || s.Employee.ID != step.Substitute.Employee.ID {
+					rest = append(rest, s)
+				}
+			}
+			result.Alternatives = limitCandidates(rest, maxResults-1)
+		}
+	}
+
+	log.Printf("改派完成: 订单=%s, 受影响订单=%d, 未解决=%d", req.Order.OrderNo, len(result.Plan), result.Unresolved)
+	return result
+}
+
+// splitAffectedOrders 拆分受影响订单和其余订单
+// 受影响订单为本订单，以及不可用人员同日开始时间不早于本订单、尚未开始或完成的其他订单
+func splitAffectedOrders(order *model.ServiceOrder, todayOrders []*model.ServiceOrder, unavailable uuid.UUID) (affected, base []*model.ServiceOrder) {
+	affected = []*model.ServiceOrder{order}
+	var downstream []*model.ServiceOrder
+	for _, o := range todayOrders {
+		if o == nil || sameOrder(o, order) {
+			continue
+		}
+		if o.HasEmployee(unavailable) && o.ServiceDate == order.ServiceDate && o.StartTime >= order.StartTime &&
+			(o.Status == model.OrderPending || o.Status == model.OrderAssigned || o.Status == "") {
+			downstream = append(downstream, o)
+			continue
+		}
+		base = append(base, o)
+	}
+	sort.SliceStable(downstream, func(i, j int) bool { return downstream[i].StartTime < downstream[j].StartTime })
+	return append(affected, downstream...), base
+}
+
+// sameOrder 按订单ID（未填写时按订单号）判断是否为同一订单
+func sameOrder(a, b *model.ServiceOrder) bool {
+	if a.ID != uuid.Nil || b.ID != uuid.Nil {
+		return a.ID == b.ID
+	}
+	return a.OrderNo == b.OrderNo
+}
+
+// releaseEmployee 返回移除不可用人员后的订单副本，单人订单回到待派单状态
+func releaseEmployee(order *model.ServiceOrder, unavailable uuid.UUID) *model.ServiceOrder {
+	revised := *order
+	revised.EmployeeIDs = nil
+	for _, id := range order.EmployeeIDs {
+		if id != unavailable {
+			revised.EmployeeIDs = append(revised.EmployeeIDs, id)
+		}
+	}
+	if revised.EmployeeID != nil && *revised.EmployeeID == unavailable {
+		revised.EmployeeID = nil
+	}
+	if revised.EmployeeID == nil && len(revised.EmployeeIDs) == 0 {
+		revised.Status = model.OrderPending
+		revised.AssignedAt = nil
+	}
+	return &revised
+}
+
+// assignSubstitute 由替补接替不可用人员：接替带队人或团队中的同一位置
+func assignSubstitute(revised, original *model.ServiceOrder, unavailable, substitute uuid.UUID) {
+	if original.EmployeeID != nil && *original.EmployeeID == unavailable {
+		revised.EmployeeID = &substitute
+	}
+	if len(original.EmployeeIDs) > 0 {
+		revised.EmployeeIDs = make([]uuid.UUID, len(original.EmployeeIDs))
+		for i, id := range original.EmployeeIDs {
+			if id == unavailable {
+				id = substitute
+			}
+			revised.EmployeeIDs[i] = id
+		}
+	}
+	revised.Status = model.OrderAssigned
+	assignedAt := time.Now()
+	revised.AssignedAt = &assignedAt
+}
+
+// checkArrival 检查当日订单的替补能否从当前位置（实时上报位置，否则为家庭位置）按时赶到订单地点
+// 替补当日在本订单之前另有订单时由路程缓冲约束检查，途中的替补由实时状态约束检查；
+// 订单已开始时不判为不可行，按路程时间加罚，优先最快赶到的替补
+func (e *DispatchEngine) checkArrival(score *CandidateScore, order *model.ServiceOrder, base []*model.ServiceOrder, now time.Time) {
+	if order.Location == nil || order.ServiceDate != now.Format("2006-01-02") {
+		return
+	}
+	for _, o := range base {
+		if o.HasEmployee(score.Employee.ID) && o.ServiceDate == order.ServiceDate && o.EndTime <= order.StartTime {
+			return
+		}
+	}
+	from := score.Employee.HomeLocation
+	if e.tracker != nil {
+		if status := e.tracker.Get(score.Employee.ID); status != nil {
+			if status.Status == model.LiveStatusEnRoute {
+				return
+			}
+			if status.Location != nil {
+				from = status.Location
+			}
+		}
+	}
+	if from == nil {
+		return
+	}
+	start, err := time.ParseInLocation("2006-01-02 15:04", order.ServiceDate+" "+order.StartTime, now.Location())
+	if err != nil {
+		return
+	}
+
+	minutes := from.Distance(*order.Location) / reassignSpeedKmh * 60
+	if e.travel != nil {
+		if est, err := e.travel.Estimate(*from, *order.Location); err == nil {
+			minutes = est.Minutes
+		}
+	}
+	score.TravelTime = int(math.Ceil(minutes))
+
+	left := start.Sub(now).Minutes()
+	if left <= 0 {
+		score.Score += minutes / 60 * 10
+		return
+	}
+	if minutes > left {
+		score.Feasible = false
+		score.Score += 1000
+		score.Violations = append(score.Violations, fmt.Sprintf("无法按时到达：路程约 %d 分钟，距开始 %d 分钟", score.TravelTime, int(left)))
+	}
+}
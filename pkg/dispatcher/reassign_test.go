@@ -0,0 +1,91 @@
+package dispatcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestDispatchEngine_Reassign(t *testing.T) {
+	engine := NewDispatchEngine()
+
+	sick := newTeamEmployee("请假员工")
+	near := newTeamEmployee("近处员工") // 约 1km，被客户拉黑
+	near.HomeLocation = &model.Location{Latitude: 39.919, Longitude: 116.41}
+	mid := newTeamEmployee("中等距离员工") // 约 5km，10 分钟路程
+	mid.HomeLocation = &model.Location{Latitude: 39.955, Longitude: 116.41}
+	far := newTeamEmployee("远处员工") // 约 19km，来不及赶到
+	far.HomeLocation = &model.Location{Latitude: 40.08, Longitude: 116.41}
+	busy := newTeamEmployee("忙碌员工")
+
+	customer := &model.Customer{BaseModel: model.BaseModel{ID: uuid.New()}, BlockedEmpIDs: []uuid.UUID{near.ID}}
+	order := newTeamOrder("R001", "11:00", "12:00", 1)
+	order.CustomerID = customer.ID
+	order.EmployeeID, order.Status = &sick.ID, model.OrderAssigned
+
+	later := newTeamOrder("R002", "14:00", "15:00", 1)
+	later.EmployeeID, later.Status = &sick.ID, model.OrderAssigned
+	done := newTeamOrder("R000", "08:00", "09:00", 1)
+	done.EmployeeID, done.Status = &sick.ID, model.OrderCompleted
+	occupied := newTeamOrder("B001", "10:30", "12:30", 1)
+	occupied.EmployeeID, occupied.Status = &busy.ID, model.OrderAssigned
+
+	now := time.Date(2026, 1, 11, 10, 45, 0, 0, time.Local)
+	result := engine.Reassign(&ReassignRequest{
+		Order:       order,
+		Candidates:  []*model.Employee{sick, near, mid, far, busy},
+		Customer:    customer,
+		TodayOrders: []*model.ServiceOrder{done, order, later, occupied},
+		Now:         now,
+	})
+
+	if !result.Success || result.Substitute.Employee.ID != mid.ID {
+		t.Fatalf("应改派给能按时到达且未被拉黑的员工: %+v", result)
+	}
+	if len(result.Plan) != 2 || result.Unresolved != 0 {
+		t.Fatalf("应包含本订单和后续订单的改派方案: %+v", result.Plan)
+	}
+	if got := result.Plan[0].Order; *got.EmployeeID != mid.ID || got.Status != model.OrderAssigned {
+		t.Errorf("改派后的订单 = %+v", got)
+	}
+	if next := result.Plan[1]; next.OrderID != "R002" || !next.Success || next.Substitute.Employee.ID == sick.ID {
+		t.Errorf("后续订单改派 = %+v", next)
+	}
+
+	for _, alt := range result.Alternatives {
+		if alt.Feasible && (alt.Employee.ID == far.ID || alt.Employee.ID == busy.ID) {
+			t.Errorf("%s 不应可行: %+v", alt.Employee.Name, alt)
+		}
+	}
+}
+
+func TestDispatchEngine_ReassignTeamMember(t *testing.T) {
+	engine := NewDispatchEngine()
+
+	a := newTeamEmployee("员工A")
+	b := newTeamEmployee("员工B")
+	c := newTeamEmployee("员工C")
+	order := newTeamOrder("DEEP010", "14:00", "17:00", 2)
+	order.EmployeeID, order.EmployeeIDs, order.Status = &a.ID, []uuid.UUID{a.ID, b.ID}, model.OrderAssigned
+
+	result := engine.Reassign(&ReassignRequest{
+		Order:                 order,
+		UnavailableEmployeeID: b.ID,
+		Candidates:            []*model.Employee{a, b, c},
+		Now:                   time.Date(2026, 1, 11, 9, 0, 0, 0, time.Local),
+	})
+	if !result.Success || result.Substitute.Employee.ID != c.ID {
+		t.Fatalf("应由 C 替换团队成员 B: %+v", result)
+	}
+	got := result.Plan[0].Order
+	if *got.EmployeeID != a.ID || len(got.EmployeeIDs) != 2 || got.EmployeeIDs[1] != c.ID {
+		t.Errorf("团队成员 = %v, 带队人 = %v", got.EmployeeIDs, got.EmployeeID)
+	}
+
+	result = engine.Reassign(&ReassignRequest{Order: order, UnavailableEmployeeID: c.ID, Candidates: []*model.Employee{c}})
+	if result.Success || result.Reason == "" {
+		t.Errorf("不可用人员不在订单中时应失败: %+v", result)
+	}
+}
@@ -0,0 +1,450 @@
+// Package routing 提供单个服务人员一天内上门订单的路线优化
+// 在遵守订单时间窗的前提下最小化路程：以最近邻和按时间窗排序两种初始解中较好者为起点，
+// 再用 2-opt（路段反转）和 or-opt（单站移位）局部搜索改进，直到没有更好的邻域解。
+// 方案优劣先比较迟到分钟数，再比较总路程
+package routing
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/paiban/paiban/pkg/dispatcher/travel"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// DefaultSpeedKmh 未指定平均速度时估算路程时间使用的速度（与派单实时状态约束一致）
+const DefaultSpeedKmh = 30
+
+// MaxStops 单条路线的最大订单数
+const MaxStops = 100
+
+// Options 路线优化选项
+type Options struct {
+	StartTime     string  // 出发时间（HH:MM），为空时按第一站的时间窗开始倒推出发时间
+	SpeedKmh      float64 // 平均速度，默认 DefaultSpeedKmh
+	ReturnToStart bool    // 是否返回出发点（路程和到达时间计入返程）
+
+	// Travel 路程估算服务（如 OSRM、高德），为nil时按直线距离和 SpeedKmh 估算
+	Travel travel.Provider
+}
+
+// Stop 路线中的一站（按访问顺序）
+type Stop struct {
+	Sequence      int                 `json:"sequence"`
+	OrderID       string              `json:"order_id"`
+	OrderNo       string              `json:"order_no,omitempty"`
+	Address       string              `json:"address,omitempty"`
+	Location      *model.Location     `json:"location,omitempty"`
+	DistanceKm    float64             `json:"distance_km"`    // 上一站（或出发点）到本站的路程
+	TravelMinutes int                 `json:"travel_minutes"` // 上一站到本站的路程时间
+	Depart        string              `json:"depart"`         // 从上一站出发时间
+	Arrival       string              `json:"arrival"`        // 预计到达时间（ETA）
+	WaitMinutes   int                 `json:"wait_minutes"`   // 早于时间窗开始到达时的等待时间
+	ServiceStart  string              `json:"service_start"`  // 开始服务时间
+	ServiceEnd    string              `json:"service_end"`    // 服务结束时间
+	LateMinutes   int                 `json:"late_minutes"`   // 晚于最晚开始时间的分钟数
+	Order         *model.ServiceOrder `json:"-"`
+}
+
+// Route 优化后的路线
+type Route struct {
+	Stops              []Stop          `json:"stops"`
+	TotalDistanceKm    float64         `json:"total_distance_km"`
+	TotalTravelMinutes int             `json:"total_travel_minutes"`
+	TotalWaitMinutes   int             `json:"total_wait_minutes"`
+	TotalLateMinutes   int             `json:"total_late_minutes"`
+	Feasible           bool            `json:"feasible"` // 全部订单在时间窗内开始服务
+	Start              string          `json:"start"`    // 出发时间
+	Finish             string          `json:"finish"`   // 最后一站服务结束（或返回出发点）时间
+	ReturnDistanceKm   float64         `json:"return_distance_km,omitempty"`
+	StartLocation      *model.Location `json:"start_location,omitempty"`
+	TravelProvider     string          `json:"travel_provider"` // 路程估算服务
+}
+
+// window 订单的服务时间窗（自零点起的分钟数）
+type window struct {
+	earliest int // 最早开始服务
+	latest   int // 最晚开始服务
+	duration int // 服务时长
+	timed    bool
+}
+
+// planner 路线优化的输入：0 号点为出发点，1..n 号点为订单
+type planner struct {
+	orders    []*model.ServiceOrder
+	windows   []window
+	located   []bool
+	dist      [][]float64 // 点之间的路程（公里），缺少位置的点与其他点路程为 0
+	minutes   [][]int     // 点之间的路程时间（分钟，向上取整）
+	provider  string
+	start     int // 出发时间（分钟），-1 表示按第一站倒推
+	roundTrip bool
+}
+
+// cost 方案代价：先比较迟到，再比较路程
+type cost struct {
+	late int
+	dist float64
+}
+
+func (c cost) less(o cost) bool {
+	if c.late != o.late {
+		return c.late < o.late
+	}
+	return c.dist < o.dist-1e-9
+}
+
+// Optimize 计算访问顺序和各站预计到达时间
+// 订单须为同一服务日期；缺少位置的订单不产生路程，缺少时间的订单不受时间窗约束
+func Optimize(orders []*model.ServiceOrder, start *model.Location, opts Options) (*Route, error) {
+	if len(orders) > MaxStops {
+		return nil, fmt.Errorf("单条路线最多 %d 个订单", MaxStops)
+	}
+	p, err := newPlanner(orders, start, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	best := p.nearestNeighbor()
+	if alt := p.byWindow(); p.cost(alt).less(p.cost(best)) {
+		best = alt
+	}
+	best = p.improve(best)
+	return p.route(best, start), nil
+}
+
+func newPlanner(orders []*model.ServiceOrder, start *model.Location, opts Options) (*planner, error) {
+	p := &planner{
+		orders:    orders,
+		windows:   make([]window, len(orders)+1),
+		located:   make([]bool, len(orders)+1),
+		start:     -1,
+		roundTrip: opts.ReturnToStart,
+	}
+	if opts.StartTime != "" {
+		m, err := parseClock(opts.StartTime)
+		if err != nil {
+			return nil, fmt.Errorf("出发时间格式无效: %s", opts.StartTime)
+		}
+		p.start = m
+	}
+
+	date := ""
+	locations := make([]*model.Location, len(orders)+1)
+	locations[0] = start
+	for i, o := range orders {
+		if o == nil {
+			return nil, fmt.Errorf("第 %d 个订单为空", i+1)
+		}
+		if o.ServiceDate != "" {
+			if date != "" && o.ServiceDate != date {
+				return nil, fmt.Errorf("订单服务日期不一致: %s 与 %s", date, o.ServiceDate)
+			}
+			date = o.ServiceDate
+		}
+		w, err := orderWindow(o)
+		if err != nil {
+			return nil, err
+		}
+		p.windows[i+1] = w
+		locations[i+1] = o.Location
+	}
+
+	if err := p.measure(locations, opts); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// measure 计算有位置的点两两之间的路程和路程时间
+func (p *planner) measure(locations []*model.Location, opts Options) error {
+	provider := opts.Travel
+	if provider == nil {
+		speed := opts.SpeedKmh
+		if speed <= 0 {
+			speed = DefaultSpeedKmh
+		}
+		provider = travel.NewStraightLine(speed)
+	}
+	p.provider = provider.Name()
+
+	var points []model.Location
+	var index []int // points 中各点对应的点号
+	for i, loc := range locations {
+		p.located[i] = loc != nil
+		if loc != nil {
+			points = append(points, *loc)
+			index = append(index, i)
+		}
+	}
+	matrix, err := travel.Matrix(provider, points)
+	if err != nil {
+		return fmt.Errorf("路程估算失败: %w", err)
+	}
+
+	p.dist = make([][]float64, len(locations))
+	p.minutes = make([][]int, len(locations))
+	for i := range locations {
+		p.dist[i] = make([]float64, len(locations))
+		p.minutes[i] = make([]int, len(locations))
+	}
+	for a, i := range index {
+		for b, j := range index {
+			if i != j {
+				p.dist[i][j] = matrix[a][b].DistanceKm
+				p.minutes[i][j] = int(math.Ceil(matrix[a][b].Minutes - 1e-9))
+			}
+		}
+	}
+	return nil
+}
+
+// orderWindow 订单的服务时间窗：start_time 前到达需等待，最晚开始时间为 end_time 减去服务时长
+func orderWindow(o *model.ServiceOrder) (window, error) {
+	w := window{duration: o.Duration}
+	if o.StartTime == "" {
+		return w, nil
+	}
+	s, err := parseClock(o.StartTime)
+	if err != nil {
+		return w, fmt.Errorf("订单 %s 开始时间格式无效: %s", o.OrderNo, o.StartTime)
+	}
+	w.earliest, w.latest, w.timed = s, s, true
+	if o.EndTime != "" {
+		e, err := parseClock(o.EndTime)
+		if err != nil {
+			return w, fmt.Errorf("订单 %s 结束时间格式无效: %s", o.OrderNo, o.EndTime)
+		}
+		if e <= s {
+			e += 24 * 60
+		}
+		if w.duration <= 0 {
+			w.duration = e - s
+		}
+		if latest := e - w.duration; latest > s {
+			w.latest = latest
+		}
+	}
+	return w, nil
+}
+
+// simulate 按访问顺序推算时间，visit 对每站回调（可为 nil），prev 为上一个有位置的点
+// （缺少位置的订单不改变当前位置）
+func (p *planner) simulate(seq []int, visit func(i, prev int, depart, arrival, begin int)) cost {
+	var c cost
+	clock, prev := p.start, 0
+	for _, i := range seq {
+		c.dist += p.dist[prev][i]
+		minutes := p.minutes[prev][i]
+		w := p.windows[i]
+		depart := clock
+		if depart < 0 {
+			// 未指定出发时间：恰好在第一站时间窗开始时到达
+			depart = w.earliest - minutes
+			if !w.timed || depart < 0 {
+				depart = 0
+			}
+		}
+		arrival := depart + minutes
+		begin := arrival
+		if w.timed && begin < w.earliest {
+			begin = w.earliest
+		}
+		if w.timed && begin > w.latest {
+			c.late += begin - w.latest
+		}
+		if visit != nil {
+			visit(i, prev, depart, arrival, begin)
+		}
+		clock = begin + w.duration
+		if p.located[i] {
+			prev = i
+		}
+	}
+	if p.roundTrip {
+		c.dist += p.dist[prev][0]
+	}
+	return c
+}
+
+func (p *planner) cost(seq []int) cost {
+	return p.simulate(seq, nil)
+}
+
+// nearestNeighbor 最近邻初始解（缺少位置的订单路程为 0，排在前面不影响路程）
+func (p *planner) nearestNeighbor() []int {
+	n := len(p.orders)
+	seq := make([]int, 0, n)
+	used := make([]bool, n+1)
+	cur := 0
+	for len(seq) < n {
+		next := -1
+		for i := 1; i <= n; i++ {
+			if !used[i] && (next < 0 || p.dist[cur][i] < p.dist[cur][next]) {
+				next = i
+			}
+		}
+		seq = append(seq, next)
+		used[next] = true
+		if p.located[next] {
+			cur = next
+		}
+	}
+	return seq
+}
+
+// byWindow 按时间窗排序的初始解（时间窗紧的订单较多时通常优于最近邻）
+func (p *planner) byWindow() []int {
+	seq := make([]int, len(p.orders))
+	for i := range seq {
+		seq[i] = i + 1
+	}
+	sort.SliceStable(seq, func(a, b int) bool {
+		wa, wb := p.windows[seq[a]], p.windows[seq[b]]
+		if wa.timed != wb.timed {
+			return wa.timed
+		}
+		if wa.latest != wb.latest {
+			return wa.latest < wb.latest
+		}
+		return wa.earliest < wb.earliest
+	})
+	return seq
+}
+
+// improve 2-opt 与 or-opt 局部搜索，直到没有改进
+func (p *planner) improve(seq []int) []int {
+	best := p.cost(seq)
+	candidate := make([]int, len(seq))
+	for improved := true; improved; {
+		improved = false
+		// 2-opt：反转 seq[i..j]
+		for i := 0; i < len(seq)-1; i++ {
+			for j := i + 1; j < len(seq); j++ {
+				copy(candidate, seq)
+				for a, b := i, j; a < b; a, b = a+1, b-1 {
+					candidate[a], candidate[b] = candidate[b], candidate[a]
+				}
+				if c := p.cost(candidate); c.less(best) {
+					copy(seq, candidate)
+					best, improved = c, true
+				}
+			}
+		}
+		// or-opt：将 seq[i] 移到位置 j
+		for i := range seq {
+			for j := range seq {
+				if i == j {
+					continue
+				}
+				move(candidate, seq, i, j)
+				if c := p.cost(candidate); c.less(best) {
+					copy(seq, candidate)
+					best, improved = c, true
+				}
+			}
+		}
+	}
+	return seq
+}
+
+// move 将 src[i] 移到位置 j，结果写入 dst
+func move(dst, src []int, i, j int) {
+	v := src[i]
+	k := 0
+	for idx, x := range src {
+		if idx == i {
+			continue
+		}
+		if k == j {
+			dst[k] = v
+			k++
+		}
+		dst[k] = x
+		k++
+	}
+	if k == j {
+		dst[k] = v
+	}
+}
+
+// route 按访问顺序生成逐站路线
+func (p *planner) route(seq []int, start *model.Location) *Route {
+	r := &Route{Stops: make([]Stop, 0, len(seq)), StartLocation: start, TravelProvider: p.provider}
+	finish, last := 0, 0
+	c := p.simulate(seq, func(i, prev int, depart, arrival, begin int) {
+		o := p.orders[i-1]
+		w := p.windows[i]
+		stop := Stop{
+			Sequence:      len(r.Stops) + 1,
+			OrderID:       o.ID.String(),
+			OrderNo:       o.OrderNo,
+			Address:       o.Address,
+			Location:      o.Location,
+			DistanceKm:    round(p.dist[prev][i]),
+			TravelMinutes: p.minutes[prev][i],
+			Depart:        formatClock(depart),
+			Arrival:       formatClock(arrival),
+			WaitMinutes:   begin - arrival,
+			ServiceStart:  formatClock(begin),
+			ServiceEnd:    formatClock(begin + w.duration),
+			Order:         o,
+		}
+		if w.timed && begin > w.latest {
+			stop.LateMinutes = begin - w.latest
+		}
+		if len(r.Stops) == 0 {
+			r.Start = stop.Depart
+		}
+		r.TotalTravelMinutes += stop.TravelMinutes
+		r.TotalWaitMinutes += stop.WaitMinutes
+		r.Stops = append(r.Stops, stop)
+		finish = begin + w.duration
+		if p.located[i] {
+			last = i
+		}
+	})
+	if p.roundTrip && len(seq) > 0 {
+		r.ReturnDistanceKm = round(p.dist[last][0])
+		r.TotalTravelMinutes += p.minutes[last][0]
+		finish += p.minutes[last][0]
+	}
+	r.TotalDistanceKm = round(c.dist)
+	r.TotalLateMinutes = c.late
+	r.Feasible = c.late == 0
+	if len(seq) > 0 {
+		r.Finish = formatClock(finish)
+	}
+	return r
+}
+
+// Sequence 路线中订单的访问顺序
+func (r *Route) Sequence() []*model.ServiceOrder {
+	orders := make([]*model.ServiceOrder, len(r.Stops))
+	for i, s := range r.Stops {
+		orders[i] = s.Order
+	}
+	return orders
+}
+
+// parseClock 解析 HH:MM 为自零点起的分钟数
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// formatClock 分钟数格式化为 HH:MM，跨午夜时取次日时刻
+func formatClock(m int) string {
+	m = ((m % (24 * 60)) + 24*60) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+// round 保留两位小数
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,168 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/dispatcher/travel"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func newOrder(no string, lat float64, start, end string, duration int) *model.ServiceOrder {
+	return &model.ServiceOrder{
+		BaseModel:   model.BaseModel{ID: uuid.New()},
+		OrderNo:     no,
+		ServiceDate: "2026-01-12",
+		StartTime:   start,
+		EndTime:     end,
+		Duration:    duration,
+		Location:    &model.Location{Latitude: lat, Longitude: 116.40},
+	}
+}
+
+func orderNos(r *Route) string {
+	nos := make([]string, len(r.Stops))
+	for i, s := range r.Stops {
+		nos[i] = s.OrderNo
+	}
+	return strings.Join(nos, ",")
+}
+
+func TestOptimize_MinimizesDistance(t *testing.T) {
+	// 同一经线上的订单，最短路线为由近及远
+	start := &model.Location{Latitude: 39.90, Longitude: 116.40}
+	orders := []*model.ServiceOrder{
+		newOrder("C", 39.93, "", "", 60),
+		newOrder("A", 39.91, "", "", 60),
+		newOrder("D", 39.94, "", "", 60),
+		newOrder("B", 39.92, "", "", 60),
+	}
+	r, err := Optimize(orders, start, Options{StartTime: "08:00"})
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+	if got := orderNos(r); got != "A,B,C,D" {
+		t.Errorf("顺序 = %s, want A,B,C,D", got)
+	}
+	if r.TotalDistanceKm < 4.4 || r.TotalDistanceKm > 4.5 || !r.Feasible {
+		t.Errorf("路线 = %+v", r)
+	}
+	// 1.11km 按 30km/h 需 3 分钟
+	first := r.Stops[0]
+	if first.Depart != "08:00" || first.TravelMinutes != 3 || first.Arrival != "08:03" || first.ServiceEnd != "09:03" {
+		t.Errorf("第一站 = %+v", first)
+	}
+	if r.Stops[1].Depart != "09:03" || r.Stops[1].Arrival != "09:06" {
+		t.Errorf("第二站 = %+v", r.Stops[1])
+	}
+
+	// 返回出发点时计入返程
+	r, _ = Optimize(orders, start, Options{StartTime: "08:00", ReturnToStart: true})
+	if r.ReturnDistanceKm < 4.4 || r.TotalDistanceKm < 8.8 {
+		t.Errorf("往返路线 = %+v", r)
+	}
+}
+
+func TestOptimize_RespectsTimeWindows(t *testing.T) {
+	// 近处订单下午服务，远处订单上午服务：按时间窗先去远处
+	start := &model.Location{Latitude: 39.90, Longitude: 116.40}
+	orders := []*model.ServiceOrder{
+		newOrder("NEAR", 39.91, "14:00", "16:00", 120),
+		newOrder("FAR", 39.99, "09:00", "11:00", 120),
+	}
+	r, err := Optimize(orders, start, Options{})
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+	if got := orderNos(r); got != "FAR,NEAR" || !r.Feasible || r.TotalLateMinutes != 0 {
+		t.Fatalf("路线 = %s %+v", got, r)
+	}
+	// 未指定出发时间：恰好在第一站时间窗开始时到达
+	far, near := r.Stops[0], r.Stops[1]
+	if far.Arrival != "09:00" || far.ServiceStart != "09:00" || r.Start != far.Depart {
+		t.Errorf("第一站 = %+v", far)
+	}
+	if near.WaitMinutes == 0 || near.ServiceStart != "14:00" || r.Finish != "16:00" {
+		t.Errorf("第二站 = %+v", near)
+	}
+
+	// 时间窗无法同时满足时记录迟到
+	orders = []*model.ServiceOrder{
+		newOrder("X", 39.91, "09:00", "10:00", 60),
+		newOrder("Y", 39.92, "09:00", "10:00", 60),
+	}
+	r, _ = Optimize(orders, start, Options{StartTime: "08:00"})
+	if r.Feasible || r.TotalLateMinutes == 0 || r.Stops[1].LateMinutes != r.TotalLateMinutes {
+		t.Errorf("冲突路线 = %+v", r)
+	}
+}
+
+func TestOptimize_Invalid(t *testing.T) {
+	a := newOrder("A", 39.91, "09:00", "10:00", 60)
+	b := newOrder("B", 39.92, "09:00", "10:00", 60)
+	b.ServiceDate = "2026-01-13"
+	if _, err := Optimize([]*model.ServiceOrder{a, b}, nil, Options{}); err == nil {
+		t.Error("服务日期不一致应返回错误")
+	}
+	if _, err := Optimize([]*model.ServiceOrder{newOrder("C", 39.9, "9点", "", 60)}, nil, Options{}); err == nil {
+		t.Error("时间格式无效应返回错误")
+	}
+	if _, err := Optimize([]*model.ServiceOrder{a}, nil, Options{StartTime: "25:00"}); err == nil {
+		t.Error("出发时间无效应返回错误")
+	}
+
+	// 缺少出发点和位置的订单不产生路程
+	c := newOrder("C", 0, "", "", 30)
+	c.Location = nil
+	r, err := Optimize([]*model.ServiceOrder{a, c}, nil, Options{})
+	if err != nil || len(r.Stops) != 2 || r.TotalDistanceKm != 0 {
+		t.Errorf("路线 = %+v, err = %v", r, err)
+	}
+}
+
+func TestMove(t *testing.T) {
+	src := []int{1, 2, 3, 4}
+	dst := make([]int, 4)
+	for _, c := range []struct {
+		i, j int
+		want string
+	}{{0, 3, "2341"}, {3, 0, "4123"}, {1, 2, "1324"}, {2, 1, "1324"}} {
+		move(dst, src, c.i, c.j)
+		var b strings.Builder
+		for _, v := range dst {
+			b.WriteByte(byte('0' + v))
+		}
+		if b.String() != c.want {
+			t.Errorf("move(%d, %d) = %s, want %s", c.i, c.j, b.String(), c.want)
+		}
+	}
+}
+
+// slowTravel 路网路程为直线距离的 2 倍，速度 20km/h
+type slowTravel struct{}
+
+func (slowTravel) Name() string { return "slow" }
+
+func (slowTravel) Estimate(from, to model.Location) (travel.Estimate, error) {
+	km := from.Distance(to) * 2
+	return travel.Estimate{DistanceKm: km, Minutes: km / 20 * 60}, nil
+}
+
+func TestOptimize_TravelProvider(t *testing.T) {
+	start := &model.Location{Latitude: 39.90, Longitude: 116.40}
+	orders := []*model.ServiceOrder{newOrder("A", 39.91, "", "", 60)}
+	r, err := Optimize(orders, start, Options{StartTime: "08:00", Travel: slowTravel{}})
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+	// 2.22km 按 20km/h 需 7 分钟
+	if s := r.Stops[0]; s.DistanceKm != 2.22 || s.TravelMinutes != 7 || s.Arrival != "08:07" || r.TravelProvider != "slow" {
+		t.Errorf("路线 = %+v, 第一站 = %+v", r, s)
+	}
+
+	r, _ = Optimize(orders, start, Options{StartTime: "08:00"})
+	if r.TravelProvider != travel.ProviderStraight || r.Stops[0].TravelMinutes != 3 {
+		t.Errorf("默认直线估算: %+v", r)
+	}
+}
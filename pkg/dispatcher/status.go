@@ -0,0 +1,76 @@
+package dispatcher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// DefaultStatusTTL 实时状态默认有效期，超过该时间未上报视为状态未知
+const DefaultStatusTTL = 15 * time.Minute
+
+// StatusTracker 员工实时状态跟踪器
+type StatusTracker struct {
+	mu       sync.RWMutex
+	statuses map[uuid.UUID]*model.EmployeeLiveStatus
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewStatusTracker 创建状态跟踪器
+func NewStatusTracker(ttl time.Duration) *StatusTracker {
+	if ttl <= 0 {
+		ttl = DefaultStatusTTL
+	}
+	return &StatusTracker{
+		statuses: make(map[uuid.UUID]*model.EmployeeLiveStatus),
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// Report 上报员工状态
+func (t *StatusTracker) Report(status *model.EmployeeLiveStatus) {
+	s := *status
+	if s.ReportedAt.IsZero() {
+		s.ReportedAt = t.now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// 忽略乱序到达的旧上报
+	if existing, ok := t.statuses[s.EmployeeID]; ok && existing.ReportedAt.After(s.ReportedAt) {
+		return
+	}
+	t.statuses[s.EmployeeID] = &s
+}
+
+// Get 获取员工实时状态，无上报或已过期时返回nil
+func (t *StatusTracker) Get(employeeID uuid.UUID) *model.EmployeeLiveStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	status, ok := t.statuses[employeeID]
+	if !ok || t.now().Sub(status.ReportedAt) > t.ttl {
+		return nil
+	}
+	s := *status
+	return &s
+}
+
+// List 列出所有未过期的员工状态
+func (t *StatusTracker) List() []*model.EmployeeLiveStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	now := t.now()
+	result := make([]*model.EmployeeLiveStatus, 0, len(t.statuses))
+	for _, status := range t.statuses {
+		if now.Sub(status.ReportedAt) > t.ttl {
+			continue
+		}
+		s := *status
+		result = append(result, &s)
+	}
+	return result
+}
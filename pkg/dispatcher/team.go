@@ -0,0 +1,142 @@
+package dispatcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// CrewMember 团队订单成员
+type CrewMember struct {
+	Role string `json:"role,omitempty"` // 担任的角色，未指定角色的名额为空
+	CandidateScore
+}
+
+// CrewIDs 返回团队成员ID（单人订单返回 nil）
+func (r *DispatchResponse) CrewIDs() []uuid.UUID {
+	if len(r.Crew) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(r.Crew))
+	for i, m := range r.Crew {
+		ids[i] = m.Employee.ID
+	}
+	return ids
+}
+
+// crewSlot 团队中待填补的一类名额
+type crewSlot struct {
+	role   string
+	count  int
+	skills []string
+}
+
+// crewSlots 按订单角色拆分名额：有技能要求的角色先填，角色人数合计不足总人数时补不限角色名额
+func crewSlots(order *model.ServiceOrder) []crewSlot {
+	var withSkills, others []crewSlot
+	total := 0
+	for _, r := range order.Roles {
+		if r.Count <= 0 {
+			continue
+		}
+		slot := crewSlot{role: r.Role, count: r.Count, skills: r.Skills}
+		if len(r.Skills) > 0 {
+			withSkills = append(withSkills, slot)
+		} else {
+			others = append(others, slot)
+		}
+		total += r.Count
+	}
+	slots := append(withSkills, others...)
+	if rest := order.Headcount() - total; rest > 0 {
+		slots = append(slots, crewSlot{count: rest})
+	}
+	return slots
+}
+
+// dispatchTeam 团队订单派单
+// 每名成员都需单独满足派单约束（时间冲突、距离、资质等），再按角色贪心组队，
+// 同组成员不得违反不可同组规则；人数凑不齐时派单失败
+func (e *DispatchEngine) dispatchTeam(req *DispatchRequest) *DispatchResponse {
+	scores := e.rankCandidates(req)
+	headcount := req.Order.Headcount()
+
+	crew := make([]CrewMember, 0, headcount)
+	used := make(map[uuid.UUID]bool)
+	var shortages []string
+	for _, slot := range crewSlots(req.Order) {
+		filled := 0
+		for _, s := range scores {
+			if filled == slot.count {
+				break
+			}
+			if !s.Feasible || used[s.Employee.ID] {
+				continue
+			}
+			if len(slot.skills) > 0 && len(s.Employee.MissingSkills(slot.skills, nil)) > 0 {
+				continue
+			}
+			if keptApart(req.KeepApart, s.Employee.ID, crew) {
+				continue
+			}
+			crew = append(crew, CrewMember{Role: slot.role, CandidateScore: s})
+			used[s.Employee.ID] = true
+			filled++
+		}
+		if filled < slot.count {
+			role := slot.role
+			if role == "" {
+				role = "不限角色"
+			}
+			shortages = append(shortages, fmt.Sprintf("%s 需要 %d 人，仅找到 %d 人", role, slot.count, filled))
+		}
+	}
+
+	maxResults := req.MaxResults
+	if maxResults <= 0 {
+		maxResults = 5
+	}
+
+	if len(shortages) > 0 {
+		return &DispatchResponse{
+			OrderID:      req.Order.OrderNo,
+			Success:      false,
+			Reason:       fmt.Sprintf("团队人数不足（需要 %d 人）: %s", headcount, strings.Join(shortages, "；")),
+			Alternatives: limitCandidates(scores, maxResults),
+		}
+	}
+
+	// 未入选的可行候选人作为备选（可用于替换成员）
+	var rest []CandidateScore
+	for _, s := range scores {
+		if s.Feasible && !used[s.Employee.ID] {
+			rest = append(rest, s)
+		}
+	}
+
+	log.Printf("团队派单完成: 订单=%s, 人数=%d, 带队=%s",
+		req.Order.OrderNo, len(crew), crew[0].Employee.Name)
+
+	return &DispatchResponse{
+		OrderID:      req.Order.OrderNo,
+		Success:      true,
+		BestMatch:    &crew[0].CandidateScore,
+		Alternatives: limitCandidates(rest, maxResults),
+		Crew:         crew,
+	}
+}
+
+// keptApart 检查员工是否与已入选成员存在不可同组规则
+func keptApart(rules []model.KeepApartRule, id uuid.UUID, crew []CrewMember) bool {
+	for _, m := range crew {
+		for _, r := range rules {
+			if r.Conflicts(id, m.Employee.ID) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package dispatcher
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func newTeamEmployee(name string, skills ...string) *model.Employee {
+	return &model.Employee{
+		BaseModel:    model.BaseModel{ID: uuid.New()},
+		Name:         name,
+		Skills:       append([]string{"cleaning"}, skills...),
+		Status:       "active",
+		HomeLocation: &model.Location{Latitude: 39.91, Longitude: 116.41},
+	}
+}
+
+func newTeamOrder(no, start, end string, headcount int, roles ...model.OrderRole) *model.ServiceOrder {
+	return &model.ServiceOrder{
+		BaseModel:         model.BaseModel{ID: uuid.New()},
+		OrderNo:           no,
+		ServiceDate:       "2026-01-11",
+		StartTime:         start,
+		EndTime:           end,
+		Status:            "pending",
+		Skills:            []string{"cleaning"},
+		Location:          &model.Location{Latitude: 39.91, Longitude: 116.41},
+		RequiredHeadcount: headcount,
+		Roles:             roles,
+	}
+}
+
+func TestDispatchEngine_TeamOrderCrew(t *testing.T) {
+	engine := NewDispatchEngine()
+
+	leader := newTeamEmployee("领班", "team_lead")
+	a := newTeamEmployee("员工A")
+	b := newTeamEmployee("员工B")
+	c := newTeamEmployee("员工C")
+
+	order := newTeamOrder("DEEP001", "09:00", "13:00", 3,
+		model.OrderRole{Role: "leader", Count: 1, Skills: []string{"team_lead"}},
+		model.OrderRole{Role: "cleaner", Count: 2},
+	)
+
+	resp := engine.Dispatch(&DispatchRequest{
+		Order:      order,
+		Candidates: []*model.Employee{a, b, c, leader},
+		KeepApart:  []model.KeepApartRule{{EmployeeIDs: []uuid.UUID{a.ID, b.ID}, Reason: "不合"}},
+	})
+
+	if !resp.Success {
+		t.Fatalf("团队派单应成功: %s", resp.Reason)
+	}
+	if len(resp.Crew) != 3 {
+		t.Fatalf("团队应有3人, got %d", len(resp.Crew))
+	}
+	if resp.Crew[0].Role != "leader" || resp.Crew[0].Employee.ID != leader.ID {
+		t.Errorf("带队角色应由具备 team_lead 技能的员工担任, got %+v", resp.Crew[0])
+	}
+	if resp.BestMatch.Employee.ID != leader.ID {
+		t.Error("best_match 应为带队人")
+	}
+
+	members := make(map[uuid.UUID]bool)
+	for _, m := range resp.Crew {
+		members[m.Employee.ID] = true
+	}
+	if members[a.ID] && members[b.ID] {
+		t.Error("不可同组的员工不应出现在同一团队")
+	}
+}
+
+func TestDispatchEngine_TeamOrderShortage(t *testing.T) {
+	engine := NewDispatchEngine()
+
+	order := newTeamOrder("DEEP002", "09:00", "13:00", 3)
+	resp := engine.Dispatch(&DispatchRequest{
+		Order:      order,
+		Candidates: []*model.Employee{newTeamEmployee("员工A"), newTeamEmployee("员工B")},
+	})
+
+	if resp.Success {
+		t.Fatal("人数不足时团队派单应失败")
+	}
+	if len(resp.Crew) != 0 {
+		t.Error("失败时不应返回团队成员")
+	}
+}
+
+func TestDispatchEngine_BatchTeamOrders(t *testing.T) {
+	engine := NewDispatchEngine()
+
+	employees := []*model.Employee{
+		newTeamEmployee("员工A"), newTeamEmployee("员工B"),
+		newTeamEmployee("员工C"), newTeamEmployee("员工D"),
+	}
+	orders := []*model.ServiceOrder{
+		newTeamOrder("DEEP003", "09:00", "12:00", 2),
+		newTeamOrder("DEEP004", "09:00", "12:00", 2),
+		newTeamOrder("STD001", "10:00", "11:00", 1),
+	}
+
+	results := engine.BatchDispatch(orders, employees, nil)
+
+	if !results[0].Success || !results[1].Success {
+		t.Fatalf("两张团队订单应都能派出: %s / %s", results[0].Reason, results[1].Reason)
+	}
+	seen := make(map[uuid.UUID]bool)
+	for _, r := range results[:2] {
+		for _, id := range r.CrewIDs() {
+			if seen[id] {
+				t.Errorf("员工 %s 被同时派往两张时间重叠的订单", id)
+			}
+			seen[id] = true
+		}
+	}
+	if results[2].Success {
+		t.Error("所有员工都在团队订单中，时间重叠的单人订单应派单失败")
+	}
+}
@@ -0,0 +1,150 @@
+package travel
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 缓存默认值
+const (
+	DefaultCacheTTL  = 6 * time.Hour
+	DefaultCacheSize = 100000
+)
+
+// Cache 按起终点对缓存路程估算结果（坐标按 6 位小数归一）
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+	size     int
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	estimate Estimate
+	expires  time.Time
+}
+
+// NewCache 创建带缓存的路程服务，ttl、size 不大于 0 时使用默认值
+func NewCache(provider Provider, ttl time.Duration, size int) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &Cache{
+		provider: provider,
+		ttl:      ttl,
+		size:     size,
+		entries:  make(map[string]cacheEntry),
+		now:      time.Now,
+	}
+}
+
+// Name 返回底层服务名称
+func (c *Cache) Name() string { return c.provider.Name() }
+
+// Estimate 命中缓存时直接返回，否则调用底层服务并缓存结果
+func (c *Cache) Estimate(from, to model.Location) (Estimate, error) {
+	key := pairKey(from, to)
+	if e, ok := c.get(key); ok {
+		return e, nil
+	}
+	e, err := c.provider.Estimate(from, to)
+	if err != nil {
+		return Estimate{}, err
+	}
+	c.put(map[string]Estimate{key: e})
+	return e, nil
+}
+
+// Matrix 全部起终点对命中缓存时直接返回，否则批量计算并缓存
+func (c *Cache) Matrix(points []model.Location) ([][]Estimate, error) {
+	result := make([][]Estimate, len(points))
+	hit := true
+	for i := range points {
+		result[i] = make([]Estimate, len(points))
+		for j := range points {
+			if i == j {
+				continue
+			}
+			e, ok := c.get(pairKey(points[i], points[j]))
+			if !ok {
+				hit = false
+				break
+			}
+			result[i][j] = e
+		}
+		if !hit {
+			break
+		}
+	}
+	if hit {
+		return result, nil
+	}
+
+	m, err := Matrix(c.provider, points)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(map[string]Estimate, len(points)*len(points))
+	for i := range points {
+		for j := range points {
+			if i != j {
+				entries[pairKey(points[i], points[j])] = m[i][j]
+			}
+		}
+	}
+	c.put(entries)
+	return m, nil
+}
+
+// Len 缓存的起终点对数（含已过期未清理的）
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+func (c *Cache) get(key string) (Estimate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || c.now().After(entry.expires) {
+		return Estimate{}, false
+	}
+	return entry.estimate, true
+}
+
+// put 写入缓存，超过容量时先清理过期项，仍超过时清空
+func (c *Cache) put(entries map[string]Estimate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries)+len(entries) > c.size {
+		for key, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries)+len(entries) > c.size {
+			c.entries = make(map[string]cacheEntry)
+		}
+	}
+	expires := now.Add(c.ttl)
+	for key, e := range entries {
+		c.entries[key] = cacheEntry{estimate: e, expires: expires}
+	}
+}
+
+// pairKey 起终点对的缓存键
+func pairKey(from, to model.Location) string {
+	return fmt.Sprintf("%.6f,%.6f>%.6f,%.6f",
+		round6(from.Latitude), round6(from.Longitude), round6(to.Latitude), round6(to.Longitude))
+}
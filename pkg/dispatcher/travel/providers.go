@@ -0,0 +1,351 @@
+package travel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// requestTimeout 路网服务请求超时
+const requestTimeout = 5 * time.Second
+
+// getJSON 发送 GET 请求并解析 JSON 响应
+func getJSON(client *http.Client, rawURL string, out interface{}) error {
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("路程服务返回状态 %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// fromMetersSeconds 米、秒转换为估算结果
+func fromMetersSeconds(meters, seconds float64) Estimate {
+	return Estimate{DistanceKm: meters / 1000, Minutes: seconds / 60}
+}
+
+// =========================================
+// OSRM（自建路网服务）
+// =========================================
+
+// OSRM 通过 OSRM HTTP 接口（route/table 服务）估算路程
+type OSRM struct {
+	BaseURL string
+	Profile string // 出行方式，默认 driving
+	Client  *http.Client
+}
+
+// NewOSRM 创建 OSRM 路程服务
+func NewOSRM(baseURL string) *OSRM {
+	return &OSRM{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Profile: "driving",
+		Client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Name 返回服务名称
+func (o *OSRM) Name() string { return ProviderOSRM }
+
+// Estimate 两点间路程（route 服务）
+func (o *OSRM) Estimate(from, to model.Location) (Estimate, error) {
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Routes  []struct {
+			Distance float64 `json:"distance"`
+			Duration float64 `json:"duration"`
+		} `json:"routes"`
+	}
+	u := fmt.Sprintf("%s/route/v1/%s/%s?overview=false", o.BaseURL, o.Profile, osrmCoords([]model.Location{from, to}))
+	if err := getJSON(o.Client, u, &body); err != nil {
+		return Estimate{}, fmt.Errorf("OSRM 请求失败: %w", err)
+	}
+	if body.Code != "Ok" || len(body.Routes) == 0 {
+		return Estimate{}, fmt.Errorf("OSRM 未找到路线: %s %s", body.Code, body.Message)
+	}
+	return fromMetersSeconds(body.Routes[0].Distance, body.Routes[0].Duration), nil
+}
+
+// Matrix 多点间路程（table 服务，一次请求）
+func (o *OSRM) Matrix(points []model.Location) ([][]Estimate, error) {
+	var body struct {
+		Code      string       `json:"code"`
+		Message   string       `json:"message"`
+		Durations [][]*float64 `json:"durations"`
+		Distances [][]*float64 `json:"distances"`
+	}
+	u := fmt.Sprintf("%s/table/v1/%s/%s?annotations=duration,distance", o.BaseURL, o.Profile, osrmCoords(points))
+	if err := getJSON(o.Client, u, &body); err != nil {
+		return nil, fmt.Errorf("OSRM 请求失败: %w", err)
+	}
+	if body.Code != "Ok" || len(body.Durations) != len(points) || len(body.Distances) != len(points) {
+		return nil, fmt.Errorf("OSRM 路程矩阵无效: %s %s", body.Code, body.Message)
+	}
+	result := make([][]Estimate, len(points))
+	for i := range points {
+		result[i] = make([]Estimate, len(points))
+		if len(body.Durations[i]) != len(points) || len(body.Distances[i]) != len(points) {
+			return nil, fmt.Errorf("OSRM 路程矩阵无效")
+		}
+		for j := range points {
+			if i == j {
+				continue
+			}
+			d, s := body.Distances[i][j], body.Durations[i][j]
+			if d == nil || s == nil {
+				return nil, fmt.Errorf("OSRM 无法到达: 第 %d 点到第 %d 点", i+1, j+1)
+			}
+			result[i][j] = fromMetersSeconds(*d, *s)
+		}
+	}
+	return result, nil
+}
+
+// osrmCoords OSRM 坐标参数：经度,纬度;经度,纬度
+func osrmCoords(points []model.Location) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = lonLat(p)
+	}
+	return strings.Join(parts, ";")
+}
+
+// =========================================
+// 高德（AMap）距离测量
+// =========================================
+
+// DefaultAMapURL 高德 Web 服务地址
+const DefaultAMapURL = "https://restapi.amap.com"
+
+// AMap 通过高德距离测量接口估算驾车路程（坐标为高德 GCJ-02 坐标系）
+type AMap struct {
+	Key     string
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewAMap 创建高德路程服务，baseURL 为空时使用 DefaultAMapURL
+func NewAMap(key, baseURL string) *AMap {
+	if baseURL == "" {
+		baseURL = DefaultAMapURL
+	}
+	return &AMap{Key: key, BaseURL: strings.TrimRight(baseURL, "/"), Client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Name 返回服务名称
+func (a *AMap) Name() string { return ProviderAMap }
+
+// Estimate 两点间驾车路程
+func (a *AMap) Estimate(from, to model.Location) (Estimate, error) {
+	results, err := a.distance([]model.Location{from}, to)
+	if err != nil {
+		return Estimate{}, err
+	}
+	return results[0], nil
+}
+
+// Matrix 多点间路程：每个终点一次请求（高德单次最多 100 个起点）
+func (a *AMap) Matrix(points []model.Location) ([][]Estimate, error) {
+	if len(points) > 100 {
+		return nil, fmt.Errorf("高德距离测量最多支持 100 个起点")
+	}
+	result := make([][]Estimate, len(points))
+	for i := range result {
+		result[i] = make([]Estimate, len(points))
+	}
+	for j, dest := range points {
+		estimates, err := a.distance(points, dest)
+		if err != nil {
+			return nil, err
+		}
+		for i := range points {
+			if i != j {
+				result[i][j] = estimates[i]
+			}
+		}
+	}
+	return result, nil
+}
+
+// distance 多个起点到同一终点的驾车路程
+func (a *AMap) distance(origins []model.Location, dest model.Location) ([]Estimate, error) {
+	parts := make([]string, len(origins))
+	for i, o := range origins {
+		parts[i] = lonLat(o)
+	}
+	q := url.Values{}
+	q.Set("key", a.Key)
+	q.Set("origins", strings.Join(parts, "|"))
+	q.Set("destination", lonLat(dest))
+	q.Set("type", "1") // 驾车导航距离
+
+	var body struct {
+		Status  string `json:"status"`
+		Info    string `json:"info"`
+		Results []struct {
+			OriginID string `json:"origin_id"`
+			Distance string `json:"distance"`
+			Duration string `json:"duration"`
+		} `json:"results"`
+	}
+	if err := getJSON(a.Client, a.BaseURL+"/v3/distance?"+q.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("高德请求失败: %w", err)
+	}
+	if body.Status != "1" {
+		return nil, fmt.Errorf("高德距离测量失败: %s", body.Info)
+	}
+	estimates := make([]Estimate, len(origins))
+	found := 0
+	for _, r := range body.Results {
+		idx, err := strconv.Atoi(r.OriginID)
+		if err != nil || idx < 1 || idx > len(origins) {
+			continue
+		}
+		meters, err1 := strconv.ParseFloat(r.Distance, 64)
+		seconds, err2 := strconv.ParseFloat(r.Duration, 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("高德距离测量结果无效: %s/%s", r.Distance, r.Duration)
+		}
+		estimates[idx-1] = fromMetersSeconds(meters, seconds)
+		found++
+	}
+	if found != len(origins) {
+		return nil, fmt.Errorf("高德距离测量结果不完整: %d/%d", found, len(origins))
+	}
+	return estimates, nil
+}
+
+// =========================================
+// Google Distance Matrix
+// =========================================
+
+// DefaultGoogleURL Google Maps 接口地址
+const DefaultGoogleURL = "https://maps.googleapis.com"
+
+// googleChunk Distance Matrix 单次请求的起点/终点数（每次最多 100 个元素）
+const googleChunk = 10
+
+// Google 通过 Google Distance Matrix 接口估算驾车路程
+type Google struct {
+	Key     string
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewGoogle 创建 Google 路程服务，baseURL 为空时使用 DefaultGoogleURL
+func NewGoogle(key, baseURL string) *Google {
+	if baseURL == "" {
+		baseURL = DefaultGoogleURL
+	}
+	return &Google{Key: key, BaseURL: strings.TrimRight(baseURL, "/"), Client: &http.Client{Timeout: requestTimeout}}
+}
+
+// Name 返回服务名称
+func (g *Google) Name() string { return ProviderGoogle }
+
+// Estimate 两点间驾车路程
+func (g *Google) Estimate(from, to model.Location) (Estimate, error) {
+	m, err := g.matrix([]model.Location{from}, []model.Location{to})
+	if err != nil {
+		return Estimate{}, err
+	}
+	return m[0][0], nil
+}
+
+// Matrix 多点间路程，按每次 10×10 分块请求
+func (g *Google) Matrix(points []model.Location) ([][]Estimate, error) {
+	result := make([][]Estimate, len(points))
+	for i := range result {
+		result[i] = make([]Estimate, len(points))
+	}
+	for oi := 0; oi < len(points); oi += googleChunk {
+		oEnd := min(oi+googleChunk, len(points))
+		for di := 0; di < len(points); di += googleChunk {
+			dEnd := min(di+googleChunk, len(points))
+			block, err := g.matrix(points[oi:oEnd], points[di:dEnd])
+			if err != nil {
+				return nil, err
+			}
+			for i := range block {
+				for j := range block[i] {
+					if oi+i != di+j {
+						result[oi+i][di+j] = block[i][j]
+					}
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// matrix 单次 Distance Matrix 请求
+func (g *Google) matrix(origins, destinations []model.Location) ([][]Estimate, error) {
+	q := url.Values{}
+	q.Set("key", g.Key)
+	q.Set("origins", latLngs(origins))
+	q.Set("destinations", latLngs(destinations))
+	q.Set("mode", "driving")
+
+	var body struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"error_message"`
+		Rows         []struct {
+			Elements []struct {
+				Status   string `json:"status"`
+				Distance struct {
+					Value float64 `json:"value"`
+				} `json:"distance"`
+				Duration struct {
+					Value float64 `json:"value"`
+				} `json:"duration"`
+			} `json:"elements"`
+		} `json:"rows"`
+	}
+	if err := getJSON(g.Client, g.BaseURL+"/maps/api/distancematrix/json?"+q.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("Google 请求失败: %w", err)
+	}
+	if body.Status != "OK" || len(body.Rows) != len(origins) {
+		return nil, fmt.Errorf("Google Distance Matrix 失败: %s %s", body.Status, body.ErrorMessage)
+	}
+	result := make([][]Estimate, len(origins))
+	for i, row := range body.Rows {
+		if len(row.Elements) != len(destinations) {
+			return nil, fmt.Errorf("Google Distance Matrix 结果不完整")
+		}
+		result[i] = make([]Estimate, len(destinations))
+		for j, e := range row.Elements {
+			if e.Status != "OK" {
+				if origins[i] == destinations[j] {
+					continue
+				}
+				return nil, fmt.Errorf("Google 无法计算路程: %s", e.Status)
+			}
+			result[i][j] = fromMetersSeconds(e.Distance.Value, e.Duration.Value)
+		}
+	}
+	return result, nil
+}
+
+// lonLat 经度,纬度
+func lonLat(p model.Location) string {
+	return strconv.FormatFloat(round6(p.Longitude), 'f', -1, 64) + "," + strconv.FormatFloat(round6(p.Latitude), 'f', -1, 64)
+}
+
+// latLngs Google 坐标参数：纬度,经度|纬度,经度
+func latLngs(points []model.Location) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = strconv.FormatFloat(round6(p.Latitude), 'f', -1, 64) + "," + strconv.FormatFloat(round6(p.Longitude), 'f', -1, 64)
+	}
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,185 @@
+// Package travel 提供派单和路线规划使用的路程估算
+// 默认按直线距离（Haversine）和平均速度估算；可配置 OSRM、高德（AMap）或 Google 的路网距离服务，
+// 服务不可用时退回直线估算，起终点对的结果缓存在内存中
+package travel
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/paiban/paiban/pkg/logger"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 路程服务
+const (
+	ProviderStraight = "straight" // 直线距离
+	ProviderOSRM     = "osrm"
+	ProviderAMap     = "amap"
+	ProviderGoogle   = "google"
+)
+
+// DefaultSpeedKmh 直线估算的默认平均速度
+const DefaultSpeedKmh = 30
+
+// Estimate 两点间的路程估算
+type Estimate struct {
+	DistanceKm float64 `json:"distance_km"`
+	Minutes    float64 `json:"minutes"`
+}
+
+// Provider 路程估算服务
+type Provider interface {
+	Name() string
+	Estimate(from, to model.Location) (Estimate, error)
+}
+
+// MatrixProvider 支持批量计算多点间路程的服务（路线规划时减少请求次数）
+type MatrixProvider interface {
+	Provider
+	Matrix(points []model.Location) ([][]Estimate, error)
+}
+
+// Matrix 计算多点两两之间的路程，result[i][j] 为 points[i] 到 points[j]；
+// 服务支持批量计算时一次请求，否则逐对估算
+func Matrix(p Provider, points []model.Location) ([][]Estimate, error) {
+	if len(points) < 2 {
+		// 不足两点时无需请求服务
+		result := make([][]Estimate, len(points))
+		for i := range result {
+			result[i] = make([]Estimate, len(points))
+		}
+		return result, nil
+	}
+	if mp, ok := p.(MatrixProvider); ok {
+		return mp.Matrix(points)
+	}
+	result := make([][]Estimate, len(points))
+	for i := range points {
+		result[i] = make([]Estimate, len(points))
+		for j := range points {
+			if i == j {
+				continue
+			}
+			e, err := p.Estimate(points[i], points[j])
+			if err != nil {
+				return nil, err
+			}
+			result[i][j] = e
+		}
+	}
+	return result, nil
+}
+
+// Config 路程服务配置
+type Config struct {
+	Provider  string        // straight/osrm/amap/google，默认 straight
+	URL       string        // 服务地址（OSRM 必填，其他服务默认官方地址）
+	Key       string        // 高德、Google 的 API Key
+	SpeedKmh  float64       // 直线估算的平均速度，默认 DefaultSpeedKmh
+	CacheTTL  time.Duration // 缓存有效期，默认 DefaultCacheTTL
+	CacheSize int           // 缓存的起终点对数，默认 DefaultCacheSize
+}
+
+// New 按配置创建路程服务：路网服务失败时退回直线估算，结果带缓存
+func New(cfg Config) (Provider, error) {
+	straight := NewStraightLine(cfg.SpeedKmh)
+	var p Provider
+	switch strings.ToLower(cfg.Provider) {
+	case "", ProviderStraight:
+		return straight, nil
+	case ProviderOSRM:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("OSRM 需要配置服务地址")
+		}
+		p = NewOSRM(cfg.URL)
+	case ProviderAMap:
+		if cfg.Key == "" {
+			return nil, fmt.Errorf("高德路程服务需要配置 API Key")
+		}
+		p = NewAMap(cfg.Key, cfg.URL)
+	case ProviderGoogle:
+		if cfg.Key == "" {
+			return nil, fmt.Errorf("Google 路程服务需要配置 API Key")
+		}
+		p = NewGoogle(cfg.Key, cfg.URL)
+	default:
+		return nil, fmt.Errorf("不支持的路程服务: %s", cfg.Provider)
+	}
+	// 只缓存路网服务的结果，退回直线估算的结果不缓存
+	fallback := NewFallback(NewCache(p, cfg.CacheTTL, cfg.CacheSize), straight)
+	fallback.OnError = func(err error) {
+		logger.Warn().Err(err).Str("provider", p.Name()).Msg("路程服务不可用，按直线距离估算")
+	}
+	return fallback, nil
+}
+
+// StraightLine 按直线距离和平均速度估算
+type StraightLine struct {
+	SpeedKmh float64
+}
+
+// NewStraightLine 创建直线估算，speedKmh 不大于 0 时使用 DefaultSpeedKmh
+func NewStraightLine(speedKmh float64) *StraightLine {
+	if speedKmh <= 0 {
+		speedKmh = DefaultSpeedKmh
+	}
+	return &StraightLine{SpeedKmh: speedKmh}
+}
+
+// Name 返回服务名称
+func (s *StraightLine) Name() string { return ProviderStraight }
+
+// Estimate 直线距离（Haversine）及按平均速度估算的时间
+func (s *StraightLine) Estimate(from, to model.Location) (Estimate, error) {
+	km := from.Distance(to)
+	return Estimate{DistanceKm: km, Minutes: km / s.SpeedKmh * 60}, nil
+}
+
+// Fallback 主服务失败时使用备用服务
+type Fallback struct {
+	Primary   Provider
+	Secondary Provider
+	OnError   func(err error) // 主服务失败时回调（如记录日志），可为 nil
+}
+
+// NewFallback 创建带备用服务的路程服务
+func NewFallback(primary, secondary Provider) *Fallback {
+	return &Fallback{Primary: primary, Secondary: secondary}
+}
+
+// Name 返回主服务名称
+func (f *Fallback) Name() string { return f.Primary.Name() }
+
+// Estimate 主服务估算，失败时使用备用服务
+func (f *Fallback) Estimate(from, to model.Location) (Estimate, error) {
+	e, err := f.Primary.Estimate(from, to)
+	if err == nil {
+		return e, nil
+	}
+	f.failed(err)
+	return f.Secondary.Estimate(from, to)
+}
+
+// Matrix 主服务批量计算，失败时使用备用服务
+func (f *Fallback) Matrix(points []model.Location) ([][]Estimate, error) {
+	m, err := Matrix(f.Primary, points)
+	if err == nil {
+		return m, nil
+	}
+	f.failed(err)
+	return Matrix(f.Secondary, points)
+}
+
+func (f *Fallback) failed(err error) {
+	if f.OnError != nil {
+		f.OnError(err)
+	}
+}
+
+// round6 坐标保留 6 位小数（约 0.1 米），用于请求参数和缓存键
+func round6(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
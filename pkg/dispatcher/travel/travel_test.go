@@ -0,0 +1,315 @@
+package travel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// road 测试服务的路网路程：直线距离的 1.2 倍，速度 36km/h（10 米/秒）
+func road(a, b model.Location) (meters, seconds float64) {
+	meters = a.Distance(b) * 1200
+	return meters, meters / 10
+}
+
+func parsePoint(s string, lonFirst bool) model.Location {
+	parts := strings.Split(s, ",")
+	x, _ := strconv.ParseFloat(parts[0], 64)
+	y, _ := strconv.ParseFloat(parts[1], 64)
+	if lonFirst {
+		return model.Location{Longitude: x, Latitude: y}
+	}
+	return model.Location{Latitude: x, Longitude: y}
+}
+
+func testPoints(n int) []model.Location {
+	points := make([]model.Location, n)
+	for i := range points {
+		points[i] = model.Location{Latitude: 39.90 + float64(i)*0.01, Longitude: 116.40}
+	}
+	return points
+}
+
+func assertRoad(t *testing.T, name string, got Estimate, from, to model.Location) {
+	t.Helper()
+	meters, seconds := road(from, to)
+	if math.Abs(got.DistanceKm-meters/1000) > 1e-3 || math.Abs(got.Minutes-seconds/60) > 1e-3 {
+		t.Errorf("%s = %+v, want %.3fkm %.3fmin", name, got, meters/1000, seconds/60)
+	}
+}
+
+func TestOSRM(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var points []model.Location
+		coords := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		for _, c := range strings.Split(coords, ";") {
+			points = append(points, parsePoint(c, true))
+		}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/route/v1/driving/"):
+			meters, seconds := road(points[0], points[1])
+			fmt.Fprintf(w, `{"code":"Ok","routes":[{"distance":%f,"duration":%f}]}`, meters, seconds)
+		case strings.HasPrefix(r.URL.Path, "/table/v1/driving/"):
+			if r.URL.Query().Get("annotations") != "duration,distance" {
+				t.Errorf("annotations = %s", r.URL.Query().Get("annotations"))
+			}
+			n := len(points)
+			body := map[string][][]float64{"distances": make([][]float64, n), "durations": make([][]float64, n)}
+			for i := range points {
+				body["distances"][i] = make([]float64, n)
+				body["durations"][i] = make([]float64, n)
+				for j := range points {
+					body["distances"][i][j], body["durations"][i][j] = road(points[i], points[j])
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": "Ok", "distances": body["distances"], "durations": body["durations"]})
+		default:
+			w.Write([]byte(`{"code":"NoRoute","message":"no route"}`))
+		}
+	}))
+	defer srv.Close()
+
+	p := NewOSRM(srv.URL + "/")
+	points := testPoints(3)
+	e, err := p.Estimate(points[0], points[2])
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+	assertRoad(t, "Estimate", e, points[0], points[2])
+
+	m, err := Matrix(p, points)
+	if err != nil {
+		t.Fatalf("Matrix() error = %v", err)
+	}
+	assertRoad(t, "Matrix[2][1]", m[2][1], points[2], points[1])
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2（路程矩阵一次请求）", requests)
+	}
+
+	p.Profile = "walking"
+	if _, err := p.Estimate(points[0], points[1]); err == nil {
+		t.Error("未找到路线应返回错误")
+	}
+}
+
+func TestAMap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v3/distance" || q.Get("type") != "1" {
+			t.Errorf("请求 = %s", r.URL)
+		}
+		if q.Get("key") != "k1" {
+			w.Write([]byte(`{"status":"0","info":"INVALID_USER_KEY"}`))
+			return
+		}
+		dest := parsePoint(q.Get("destination"), true)
+		var results []map[string]string
+		for i, o := range strings.Split(q.Get("origins"), "|") {
+			meters, seconds := road(parsePoint(o, true), dest)
+			results = append(results, map[string]string{
+				"origin_id": strconv.Itoa(i + 1),
+				"dest_id":   "1",
+				"distance":  strconv.FormatFloat(meters, 'f', 3, 64),
+				"duration":  strconv.FormatFloat(seconds, 'f', 3, 64),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "1", "info": "OK", "results": results})
+	}))
+	defer srv.Close()
+
+	p := NewAMap("k1", srv.URL)
+	points := testPoints(3)
+	e, err := p.Estimate(points[1], points[0])
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+	assertRoad(t, "Estimate", e, points[1], points[0])
+
+	m, err := p.Matrix(points)
+	if err != nil {
+		t.Fatalf("Matrix() error = %v", err)
+	}
+	assertRoad(t, "Matrix[0][2]", m[0][2], points[0], points[2])
+	if m[1][1] != (Estimate{}) {
+		t.Errorf("Matrix[1][1] = %+v", m[1][1])
+	}
+
+	if _, err := NewAMap("bad", srv.URL).Estimate(points[0], points[1]); err == nil || !strings.Contains(err.Error(), "INVALID_USER_KEY") {
+		t.Errorf("Key 无效应返回错误: %v", err)
+	}
+}
+
+func TestGoogle(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		q := r.URL.Query()
+		if r.URL.Path != "/maps/api/distancematrix/json" || q.Get("key") != "g1" {
+			t.Errorf("请求 = %s", r.URL)
+		}
+		origins := strings.Split(q.Get("origins"), "|")
+		destinations := strings.Split(q.Get("destinations"), "|")
+		if len(origins)*len(destinations) > 100 {
+			t.Errorf("单次请求元素数 = %d", len(origins)*len(destinations))
+		}
+		type value struct {
+			Value float64 `json:"value"`
+		}
+		type element struct {
+			Status   string `json:"status"`
+			Distance value  `json:"distance"`
+			Duration value  `json:"duration"`
+		}
+		var rows []map[string][]element
+		for _, o := range origins {
+			var elements []element
+			for _, d := range destinations {
+				meters, seconds := road(parsePoint(o, false), parsePoint(d, false))
+				elements = append(elements, element{Status: "OK", Distance: value{meters}, Duration: value{seconds}})
+			}
+			rows = append(rows, map[string][]element{"elements": elements})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "OK", "rows": rows})
+	}))
+	defer srv.Close()
+
+	p := NewGoogle("g1", srv.URL)
+	points := testPoints(12)
+	m, err := p.Matrix(points)
+	if err != nil {
+		t.Fatalf("Matrix() error = %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("requests = %d, want 4（12 个点按 10×10 分块）", requests)
+	}
+	assertRoad(t, "Matrix[11][0]", m[11][0], points[11], points[0])
+	assertRoad(t, "Matrix[3][10]", m[3][10], points[3], points[10])
+
+	e, err := p.Estimate(points[0], points[5])
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+	assertRoad(t, "Estimate", e, points[0], points[5])
+}
+
+// countingProvider 记录调用次数，可模拟故障
+type countingProvider struct {
+	calls int
+	fail  bool
+}
+
+func (c *countingProvider) Name() string { return "counting" }
+
+func (c *countingProvider) Estimate(from, to model.Location) (Estimate, error) {
+	c.calls++
+	if c.fail {
+		return Estimate{}, errors.New("服务不可用")
+	}
+	return Estimate{DistanceKm: from.Distance(to) * 2, Minutes: 1}, nil
+}
+
+func TestCache(t *testing.T) {
+	base := &countingProvider{}
+	cache := NewCache(base, time.Hour, 0)
+	now := time.Date(2026, 1, 12, 8, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	points := testPoints(3)
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Estimate(points[0], points[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 坐标差异小于 6 位小数时命中同一缓存
+	near := model.Location{Latitude: points[0].Latitude + 1e-8, Longitude: points[0].Longitude}
+	cache.Estimate(near, points[1])
+	if base.calls != 1 {
+		t.Errorf("calls = %d, want 1", base.calls)
+	}
+
+	if _, err := cache.Matrix(points); err != nil {
+		t.Fatal(err)
+	}
+	if base.calls != 7 || cache.Len() != 6 {
+		t.Errorf("calls = %d, len = %d", base.calls, cache.Len())
+	}
+	cache.Matrix(points)
+	if base.calls != 7 {
+		t.Errorf("路程矩阵应全部命中缓存: calls = %d", base.calls)
+	}
+
+	// 过期后重新请求
+	now = now.Add(2 * time.Hour)
+	cache.Estimate(points[0], points[1])
+	if base.calls != 8 {
+		t.Errorf("过期后 calls = %d, want 8", base.calls)
+	}
+
+	// 超出容量时清理
+	small := NewCache(&countingProvider{}, time.Hour, 2)
+	small.Matrix(points)
+	if small.Len() > 6 {
+		t.Errorf("len = %d", small.Len())
+	}
+	small.Estimate(points[0], points[0])
+	if small.Len() > 2 {
+		t.Errorf("超出容量后 len = %d, want <= 2", small.Len())
+	}
+}
+
+func TestFallback(t *testing.T) {
+	primary := &countingProvider{fail: true}
+	var failures int
+	f := NewFallback(primary, NewStraightLine(0))
+	f.OnError = func(error) { failures++ }
+
+	points := testPoints(2)
+	e, err := f.Estimate(points[0], points[1])
+	if err != nil {
+		t.Fatalf("Estimate() error = %v", err)
+	}
+	// 1.11km 按 30km/h 约 2.2 分钟
+	if math.Abs(e.DistanceKm-points[0].Distance(points[1])) > 1e-9 || math.Abs(e.Minutes-2.22) > 0.01 {
+		t.Errorf("直线估算 = %+v", e)
+	}
+	if _, err := f.Matrix(points); err != nil || failures != 2 {
+		t.Errorf("Matrix() err = %v, failures = %d", err, failures)
+	}
+	if f.Name() != "counting" {
+		t.Errorf("Name() = %s", f.Name())
+	}
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(Config{})
+	if err != nil || p.Name() != ProviderStraight {
+		t.Errorf("默认应为直线估算: %v %v", p, err)
+	}
+	p, err = New(Config{Provider: "OSRM", URL: "http://127.0.0.1:1"})
+	if err != nil || p.Name() != ProviderOSRM {
+		t.Fatalf("New(osrm) = %v, %v", p, err)
+	}
+	// 服务不可达时退回直线估算
+	points := testPoints(2)
+	if e, err := p.Estimate(points[0], points[1]); err != nil || e.DistanceKm == 0 {
+		t.Errorf("退回直线估算: %+v, %v", e, err)
+	}
+
+	for _, cfg := range []Config{{Provider: ProviderOSRM}, {Provider: ProviderAMap}, {Provider: ProviderGoogle}, {Provider: "baidu"}} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) 应返回错误", cfg)
+		}
+	}
+}
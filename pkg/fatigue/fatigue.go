@@ -0,0 +1,185 @@
+// Package fatigue 提供员工疲劳指数计算
+// 疲劳指数由近期工作累计：每个工时计分，夜班、长班次、班次间休息不足额外加分，
+// 越早的班次按半衰期衰减，结果截断到 0-100
+package fatigue
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 疲劳等级
+const (
+	LevelLow      = "low"
+	LevelModerate = "moderate"
+	LevelHigh     = "high"
+)
+
+// Config 疲劳指数参数
+type Config struct {
+	WindowDays        int     `json:"window_days"`        // 统计窗口（天）
+	HalfLifeDays      float64 `json:"half_life_days"`     // 衰减半衰期（天）
+	HourPoints        float64 `json:"hour_points"`        // 每工时得分
+	NightPoints       float64 `json:"night_points"`       // 夜班（覆盖 00:00-05:00）额外得分
+	LongShiftHours    float64 `json:"long_shift_hours"`   // 长班次阈值（小时）
+	LongShiftPoints   float64 `json:"long_shift_points"`  // 长班次额外得分
+	ShortRestHours    float64 `json:"short_rest_hours"`   // 休息不足阈值（小时）
+	ShortRestPoints   float64 `json:"short_rest_points"`  // 休息不足额外得分
+	ModerateThreshold float64 `json:"moderate_threshold"` // 中度疲劳起点
+	HighThreshold     float64 `json:"high_threshold"`     // 高度疲劳起点
+}
+
+// DefaultConfig 返回默认参数
+// 连续 6 个 8 小时白班约 29 分（低），连续 6 个夜班约 65 分（高）
+func DefaultConfig() Config {
+	return Config{
+		WindowDays:        14,
+		HalfLifeDays:      3,
+		HourPoints:        1,
+		NightPoints:       10,
+		LongShiftHours:    10,
+		LongShiftPoints:   8,
+		ShortRestHours:    11,
+		ShortRestPoints:   8,
+		ModerateThreshold: 30,
+		HighThreshold:     60,
+	}
+}
+
+// Index 员工疲劳指数
+type Index struct {
+	EmployeeID  uuid.UUID `json:"employee_id"`
+	AsOf        time.Time `json:"as_of"`
+	Score       float64   `json:"score"` // 0-100
+	Level       string    `json:"level"` // low/moderate/high
+	Shifts      int       `json:"shifts"`
+	Hours       float64   `json:"hours"`
+	NightShifts int       `json:"night_shifts"`
+	LongShifts  int       `json:"long_shifts"`
+	ShortRests  int       `json:"short_rests"`
+}
+
+// Scorer 疲劳指数计算器
+type Scorer struct {
+	cfg Config
+}
+
+// NewScorer 创建疲劳指数计算器，零值参数使用默认值
+func NewScorer(cfg Config) *Scorer {
+	def := DefaultConfig()
+	if cfg.WindowDays <= 0 {
+		cfg.WindowDays = def.WindowDays
+	}
+	if cfg.HalfLifeDays <= 0 {
+		cfg.HalfLifeDays = def.HalfLifeDays
+	}
+	if cfg.HourPoints <= 0 {
+		cfg.HourPoints = def.HourPoints
+	}
+	if cfg.NightPoints <= 0 {
+		cfg.NightPoints = def.NightPoints
+	}
+	if cfg.LongShiftHours <= 0 {
+		cfg.LongShiftHours = def.LongShiftHours
+	}
+	if cfg.LongShiftPoints <= 0 {
+		cfg.LongShiftPoints = def.LongShiftPoints
+	}
+	if cfg.ShortRestHours <= 0 {
+		cfg.ShortRestHours = def.ShortRestHours
+	}
+	if cfg.ShortRestPoints <= 0 {
+		cfg.ShortRestPoints = def.ShortRestPoints
+	}
+	if cfg.ModerateThreshold <= 0 {
+		cfg.ModerateThreshold = def.ModerateThreshold
+	}
+	if cfg.HighThreshold <= 0 {
+		cfg.HighThreshold = def.HighThreshold
+	}
+	return &Scorer{cfg: cfg}
+}
+
+// Config 返回生效的参数
+func (s *Scorer) Config() Config {
+	return s.cfg
+}
+
+// Score 计算截至 asOf 的疲劳指数
+// 只统计窗口内、开始时间早于 asOf 的非取消分配；进行中的班次按已工作部分计
+func (s *Scorer) Score(employeeID uuid.UUID, assignments []*model.Assignment, asOf time.Time) Index {
+	idx := Index{EmployeeID: employeeID, AsOf: asOf, Level: LevelLow}
+	windowStart := asOf.AddDate(0, 0, -s.cfg.WindowDays)
+
+	list := make([]*model.Assignment, 0, len(assignments))
+	for _, a := range assignments {
+		if a.Status == "cancelled" || a.StartTime.IsZero() || !a.StartTime.Before(asOf) || a.EndTime.Before(windowStart) {
+			continue
+		}
+		list = append(list, a)
+	}
+	less := func(i, j int) bool { return list[i].StartTime.Before(list[j].StartTime) }
+	if !sort.SliceIsSorted(list, less) {
+		sort.Slice(list, less)
+	}
+
+	total := 0.0
+	var prevEnd time.Time
+	for _, a := range list {
+		end := a.EndTime
+		if end.After(asOf) {
+			end = asOf
+		}
+		hours := end.Sub(a.StartTime).Hours()
+		points := hours * s.cfg.HourPoints
+		idx.Shifts++
+		idx.Hours += hours
+
+		if isNight(a) {
+			points += s.cfg.NightPoints
+			idx.NightShifts++
+		}
+		if a.EndTime.Sub(a.StartTime).Hours() >= s.cfg.LongShiftHours {
+			points += s.cfg.LongShiftPoints
+			idx.LongShifts++
+		}
+		if !prevEnd.IsZero() && a.StartTime.Sub(prevEnd).Hours() < s.cfg.ShortRestHours {
+			points += s.cfg.ShortRestPoints
+			idx.ShortRests++
+		}
+		if a.EndTime.After(prevEnd) {
+			prevEnd = a.EndTime
+		}
+
+		ageDays := asOf.Sub(end).Hours() / 24
+		total += points * math.Exp2(-ageDays/s.cfg.HalfLifeDays)
+	}
+
+	idx.Score = math.Round(math.Min(total, 100)*10) / 10
+	idx.Hours = math.Round(idx.Hours*10) / 10
+	idx.Level = s.Level(idx.Score)
+	return idx
+}
+
+// Level 返回疲劳指数对应的等级
+func (s *Scorer) Level(score float64) string {
+	switch {
+	case score >= s.cfg.HighThreshold:
+		return LevelHigh
+	case score >= s.cfg.ModerateThreshold:
+		return LevelModerate
+	default:
+		return LevelLow
+	}
+}
+
+// isNight 班次是否覆盖凌晨 00:00-05:00：开始于当天 05:00 前，或跨过次日 00:00
+func isNight(a *model.Assignment) bool {
+	y, m, d := a.StartTime.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, a.StartTime.Location())
+	return a.StartTime.Before(day.Add(5*time.Hour)) || a.EndTime.After(day.AddDate(0, 0, 1))
+}
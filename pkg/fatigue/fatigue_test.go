@@ -0,0 +1,73 @@
+package fatigue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// shifts 生成从 2026-01-05 起连续 n 天、每天 startHour 开始、时长 hours 小时的分配
+func shifts(n, startHour int, hours float64) []*model.Assignment {
+	base := time.Date(2026, 1, 5, startHour, 0, 0, 0, time.UTC)
+	list := make([]*model.Assignment, n)
+	for i := range list {
+		start := base.AddDate(0, 0, i)
+		list[i] = &model.Assignment{
+			Date:      start.Format("2006-01-02"),
+			StartTime: start,
+			EndTime:   start.Add(time.Duration(hours * float64(time.Hour))),
+		}
+	}
+	return list
+}
+
+func TestScorer_Score(t *testing.T) {
+	s := NewScorer(Config{})
+	id := uuid.New()
+
+	tests := []struct {
+		name        string
+		assignments []*model.Assignment
+		level       string
+	}{
+		{"无排班", nil, LevelLow},
+		{"连续 6 个白班", shifts(6, 9, 8), LevelLow},
+		{"连续 6 个夜班", shifts(6, 22, 8), LevelHigh},
+		{"连续 4 个 12 小时班", shifts(4, 8, 12), LevelModerate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asOf := time.Date(2026, 1, 11, 12, 0, 0, 0, time.UTC)
+			idx := s.Score(id, tt.assignments, asOf)
+			if idx.Level != tt.level {
+				t.Errorf("Score() = %.1f (%s), want level %s", idx.Score, idx.Level, tt.level)
+			}
+		})
+	}
+}
+
+func TestScorer_Components(t *testing.T) {
+	s := NewScorer(Config{})
+	list := shifts(2, 22, 8)
+	// 第三班在上一班结束 6 小时后开始，且时长 11 小时
+	list = append(list, &model.Assignment{
+		StartTime: list[1].EndTime.Add(6 * time.Hour),
+		EndTime:   list[1].EndTime.Add(17 * time.Hour),
+	})
+
+	idx := s.Score(uuid.New(), list, list[2].EndTime)
+	if idx.Shifts != 3 || idx.NightShifts != 2 || idx.LongShifts != 1 || idx.ShortRests != 1 {
+		t.Errorf("unexpected components: %+v", idx)
+	}
+
+	// 更早的排班影响更小，窗口外的排班不计入
+	later := s.Score(uuid.New(), list, list[2].EndTime.AddDate(0, 0, 5))
+	if later.Score >= idx.Score {
+		t.Errorf("score should decay: %.1f >= %.1f", later.Score, idx.Score)
+	}
+	if gone := s.Score(uuid.New(), list, list[2].EndTime.AddDate(0, 0, 20)); gone.Shifts != 0 {
+		t.Errorf("assignments outside window should be ignored, got %+v", gone)
+	}
+}
@@ -0,0 +1,226 @@
+// Package forecast 根据历史数据预测排班需求
+// 历史数据可以是已执行的排班（按班次、岗位统计每天的在岗人数），也可以是每天的销售额/客流等业务量曲线
+// （按人效换算为所需人数）。对每个班次（岗位）序列，以最近若干天的移动平均作为需求水平，
+// 再乘以按星期统计的季节指数（该星期的平均值 / 全部历史的平均值），得到预测期每天的人数下限
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+const (
+	// DefaultWindow 默认移动平均窗口（天）
+	DefaultWindow = 28
+	// MaxDays 单次预测的最大天数
+	MaxDays = 92
+)
+
+// ErrInvalidInput 预测输入无效
+var ErrInvalidInput = errors.New("需求预测输入无效")
+
+// Observation 一天的历史观测值：在岗人数，或配合人效使用的业务量（销售额、客流、订单数）
+type Observation struct {
+	ShiftID  uuid.UUID `json:"shift_id"`
+	Position string    `json:"position,omitempty"`
+	Date     string    `json:"date"`
+	Value    float64   `json:"value"`
+}
+
+// Options 预测参数
+type Options struct {
+	Window       int     // 移动平均窗口（天），0 使用默认值
+	Productivity float64 // 人效（每人每天处理的业务量），大于 0 时观测值按业务量换算人数，否则视为人数
+	MinStaff     int     // 有需求的日期至少安排的人数
+}
+
+// Suggestion 预测的一天需求
+type Suggestion struct {
+	ShiftID      uuid.UUID `json:"shift_id"`
+	Position     string    `json:"position,omitempty"`
+	Date         string    `json:"date"`
+	Weekday      string    `json:"weekday"`
+	Level        float64   `json:"level"`    // 移动平均需求水平（人）
+	Seasonal     float64   `json:"seasonal"` // 星期季节指数
+	Expected     float64   `json:"expected"` // 预测人数
+	MinEmployees int       `json:"min_employees"`
+	MaxEmployees int       `json:"max_employees"` // 历史同星期的峰值人数（不低于下限）
+}
+
+// FromAssignments 将历史排班按班次、岗位和日期统计在岗人数
+func FromAssignments(assignments []*model.Assignment) []Observation {
+	type key struct {
+		shift    uuid.UUID
+		position string
+		date     string
+	}
+	counts := make(map[key]map[uuid.UUID]bool)
+	for _, a := range assignments {
+		if a.ShiftID == uuid.Nil || a.Date == "" {
+			continue
+		}
+		k := key{a.ShiftID, a.Position, a.Date}
+		if counts[k] == nil {
+			counts[k] = make(map[uuid.UUID]bool)
+		}
+		counts[k][a.EmployeeID] = true
+	}
+	observations := make([]Observation, 0, len(counts))
+	for k, employees := range counts {
+		observations = append(observations, Observation{ShiftID: k.shift, Position: k.position, Date: k.date, Value: float64(len(employees))})
+	}
+	sort.Slice(observations, func(i, j int) bool {
+		if observations[i].Date != observations[j].Date {
+			return observations[i].Date < observations[j].Date
+		}
+		if observations[i].ShiftID != observations[j].ShiftID {
+			return observations[i].ShiftID.String() < observations[j].ShiftID.String()
+		}
+		return observations[i].Position < observations[j].Position
+	})
+	return observations
+}
+
+// series 一个班次（岗位）的历史序列
+type series struct {
+	shift    uuid.UUID
+	position string
+	values   map[string]float64 // 日期 -> 人数
+}
+
+// Forecast 预测 [startDate, endDate] 每天各班次（岗位）的需求
+// 历史中从首个到最后一个观测日期之间没有观测值的日期按 0 计；预测人数为 0 的日期不生成需求
+func Forecast(observations []Observation, startDate, endDate string, opts Options) ([]Suggestion, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 开始日期格式应为 YYYY-MM-DD", ErrInvalidInput)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 结束日期格式应为 YYYY-MM-DD", ErrInvalidInput)
+	}
+	if end.Before(start) || end.Sub(start) >= MaxDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: 预测日期范围应在 1-%d 天内", ErrInvalidInput, MaxDays)
+	}
+	if len(observations) == 0 {
+		return nil, fmt.Errorf("%w: 历史数据不能为空", ErrInvalidInput)
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultWindow
+	}
+	if opts.MinStaff < 0 {
+		return nil, fmt.Errorf("%w: 最少人数不能为负数", ErrInvalidInput)
+	}
+
+	var list []*series
+	index := make(map[string]*series)
+	for i, o := range observations {
+		if _, err := time.Parse("2006-01-02", o.Date); err != nil {
+			return nil, fmt.Errorf("%w: 第 %d 条历史数据日期格式应为 YYYY-MM-DD", ErrInvalidInput, i+1)
+		}
+		if o.ShiftID == uuid.Nil || o.Value < 0 {
+			return nil, fmt.Errorf("%w: 第 %d 条历史数据缺少班次或取值为负数", ErrInvalidInput, i+1)
+		}
+		key := o.ShiftID.String() + "/" + o.Position
+		s, ok := index[key]
+		if !ok {
+			s = &series{shift: o.ShiftID, position: o.Position, values: make(map[string]float64)}
+			index[key] = s
+			list = append(list, s)
+		}
+		value := o.Value
+		if opts.Productivity > 0 {
+			value /= opts.Productivity
+		}
+		s.values[o.Date] += value
+	}
+
+	suggestions := make([]Suggestion, 0)
+	for _, s := range list {
+		suggestions = append(suggestions, s.forecast(start, end, opts)...)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Date != suggestions[j].Date {
+			return suggestions[i].Date < suggestions[j].Date
+		}
+		if suggestions[i].ShiftID != suggestions[j].ShiftID {
+			return suggestions[i].ShiftID.String() < suggestions[j].ShiftID.String()
+		}
+		return suggestions[i].Position < suggestions[j].Position
+	})
+	return suggestions, nil
+}
+
+// forecast 按移动平均和星期季节指数预测序列的需求
+func (s *series) forecast(start, end time.Time, opts Options) []Suggestion {
+	dates := make([]string, 0, len(s.values))
+	for date := range s.values {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	first, _ := time.Parse("2006-01-02", dates[0])
+	last, _ := time.Parse("2006-01-02", dates[len(dates)-1])
+
+	// 按日补齐历史（缺失日期为 0），统计全部及各星期的平均值和峰值
+	var total, recent float64
+	var days, recentDays int
+	var weekdayTotal, weekdayPeak [7]float64
+	var weekdayDays [7]int
+	windowStart := last.AddDate(0, 0, 1-opts.Window)
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		v := s.values[d.Format("2006-01-02")]
+		total += v
+		days++
+		wd := d.Weekday()
+		weekdayTotal[wd] += v
+		weekdayDays[wd]++
+		weekdayPeak[wd] = math.Max(weekdayPeak[wd], v)
+		if !d.Before(windowStart) {
+			recent += v
+			recentDays++
+		}
+	}
+	mean := total / float64(days)
+	if mean == 0 {
+		return nil
+	}
+	level := recent / float64(recentDays)
+
+	var suggestions []Suggestion
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		wd := d.Weekday()
+		seasonal := 1.0
+		if weekdayDays[wd] > 0 {
+			seasonal = weekdayTotal[wd] / float64(weekdayDays[wd]) / mean
+		}
+		expected := round(level * seasonal)
+		if expected <= 0 {
+			continue
+		}
+		minEmployees := max(int(math.Ceil(expected)), opts.MinStaff)
+		maxEmployees := max(int(math.Ceil(round(weekdayPeak[wd]))), minEmployees)
+		suggestions = append(suggestions, Suggestion{
+			ShiftID:      s.shift,
+			Position:     s.position,
+			Date:         d.Format("2006-01-02"),
+			Weekday:      wd.String(),
+			Level:        round(level),
+			Seasonal:     round(seasonal),
+			Expected:     expected,
+			MinEmployees: minEmployees,
+			MaxEmployees: maxEmployees,
+		})
+	}
+	return suggestions
+}
+
+// round 保留两位小数
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,84 @@
+package forecast
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestForecast_WeekdaySeasonality(t *testing.T) {
+	shift := uuid.New()
+	// 2026-02-01 周日起 4 周：工作日 2 人、周六 4 人、周日不营业
+	var observations []Observation
+	start, _ := time.Parse("2006-01-02", "2026-02-01")
+	for d := start; d.Before(start.AddDate(0, 0, 28)); d = d.AddDate(0, 0, 1) {
+		switch d.Weekday() {
+		case time.Sunday:
+		case time.Saturday:
+			observations = append(observations, Observation{ShiftID: shift, Date: d.Format("2006-01-02"), Value: 4})
+		default:
+			observations = append(observations, Observation{ShiftID: shift, Date: d.Format("2006-01-02"), Value: 2})
+		}
+	}
+
+	// 2026-03-01 周日至 03-07 周六
+	suggestions, err := Forecast(observations, "2026-03-01", "2026-03-07", Options{})
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if len(suggestions) != 6 {
+		t.Fatalf("周日无历史需求不应生成，len = %d", len(suggestions))
+	}
+	if s := suggestions[0]; s.Date != "2026-03-02" || s.MinEmployees != 2 || s.Expected != 2 {
+		t.Errorf("周一预测 = %+v", s)
+	}
+	if s := suggestions[5]; s.Date != "2026-03-07" || s.MinEmployees != 4 || s.MaxEmployees != 4 || s.Expected != 4 {
+		t.Errorf("周六预测 = %+v", s)
+	}
+}
+
+func TestForecast_TrafficAndMovingAverage(t *testing.T) {
+	shift := uuid.New()
+	// 业务量按人效 100 换算：前两周每天 200（2 人），最近一周每天 300（3 人）
+	var observations []Observation
+	start, _ := time.Parse("2006-01-02", "2026-02-01")
+	for i := 0; i < 21; i++ {
+		value := 200.0
+		if i >= 14 {
+			value = 300
+		}
+		observations = append(observations, Observation{ShiftID: shift, Position: "收银", Date: start.AddDate(0, 0, i).Format("2006-01-02"), Value: value})
+	}
+
+	suggestions, err := Forecast(observations, "2026-02-22", "2026-02-22", Options{Window: 7, Productivity: 100, MinStaff: 1})
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Level != 3 || suggestions[0].MinEmployees != 3 || suggestions[0].Position != "收银" {
+		t.Fatalf("移动平均应只取最近 7 天: %+v", suggestions)
+	}
+
+	if _, err := Forecast(observations, "2026-03-01", "2026-02-01", Options{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("结束日期早于开始日期应返回 ErrInvalidInput, got %v", err)
+	}
+	if _, err := Forecast(nil, "2026-03-01", "2026-03-07", Options{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("历史数据为空应返回 ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestFromAssignments(t *testing.T) {
+	shift := uuid.New()
+	emp1, emp2 := uuid.New(), uuid.New()
+	observations := FromAssignments([]*model.Assignment{
+		{EmployeeID: emp1, ShiftID: shift, Date: "2026-02-02"},
+		{EmployeeID: emp2, ShiftID: shift, Date: "2026-02-02"},
+		{EmployeeID: emp2, ShiftID: shift, Date: "2026-02-02"}, // 同一员工重复分配只计一次
+		{EmployeeID: emp1, ShiftID: shift, Date: "2026-02-01", Position: "厨师"},
+	})
+	if len(observations) != 2 || observations[0].Date != "2026-02-01" || observations[1].Value != 2 {
+		t.Errorf("FromAssignments() = %+v", observations)
+	}
+}
@@ -0,0 +1,167 @@
+// Package holiday 提供节假日日历：内置中国法定节假日及调休安排，可叠加组织自定义的节假日
+package holiday
+
+import (
+	"sort"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// CalendarCN 内置日历：中国法定节假日
+const CalendarCN = "cn"
+
+// china 中国法定节假日及调休安排（按国务院办公厅每年发布的放假通知）
+// 法定节假日为 statutory，放假调休的其余休息日为 rest，调休上班日为 workday
+var china = map[int][]model.Holiday{
+	2025: join(
+		days("元旦", model.HolidayStatutory, "2025-01-01"),
+		days("春节", model.HolidayStatutory, "2025-01-28", "2025-01-29", "2025-01-30", "2025-01-31"),
+		days("春节", model.HolidayRest, "2025-02-01", "2025-02-02", "2025-02-03", "2025-02-04"),
+		days("春节调休", model.HolidayWorkday, "2025-01-26", "2025-02-08"),
+		days("清明节", model.HolidayStatutory, "2025-04-04"),
+		days("清明节", model.HolidayRest, "2025-04-05", "2025-04-06"),
+		days("劳动节", model.HolidayStatutory, "2025-05-01", "2025-05-02"),
+		days("劳动节", model.HolidayRest, "2025-05-03", "2025-05-04", "2025-05-05"),
+		days("劳动节调休", model.HolidayWorkday, "2025-04-27"),
+		days("端午节", model.HolidayStatutory, "2025-05-31"),
+		days("端午节", model.HolidayRest, "2025-06-01", "2025-06-02"),
+		days("国庆节", model.HolidayStatutory, "2025-10-01", "2025-10-02", "2025-10-03"),
+		days("中秋节", model.HolidayStatutory, "2025-10-06"),
+		days("国庆节", model.HolidayRest, "2025-10-04", "2025-10-05", "2025-10-07", "2025-10-08"),
+		days("国庆节调休", model.HolidayWorkday, "2025-09-28", "2025-10-11"),
+	),
+	2026: join(
+		days("元旦", model.HolidayStatutory, "2026-01-01"),
+		days("元旦", model.HolidayRest, "2026-01-02", "2026-01-03"),
+		days("元旦调休", model.HolidayWorkday, "2026-01-04"),
+		days("春节", model.HolidayStatutory, "2026-02-16", "2026-02-17", "2026-02-18", "2026-02-19"),
+		days("春节", model.HolidayRest, "2026-02-15", "2026-02-20", "2026-02-21", "2026-02-22", "2026-02-23"),
+		days("春节调休", model.HolidayWorkday, "2026-02-14", "2026-02-28"),
+		days("清明节", model.HolidayStatutory, "2026-04-05"),
+		days("清明节", model.HolidayRest, "2026-04-04", "2026-04-06"),
+		days("劳动节", model.HolidayStatutory, "2026-05-01", "2026-05-02"),
+		days("劳动节", model.HolidayRest, "2026-05-03", "2026-05-04", "2026-05-05"),
+		days("劳动节调休", model.HolidayWorkday, "2026-05-09"),
+		days("端午节", model.HolidayStatutory, "2026-06-19"),
+		days("端午节", model.HolidayRest, "2026-06-20", "2026-06-21"),
+		days("中秋节", model.HolidayStatutory, "2026-09-25"),
+		days("中秋节", model.HolidayRest, "2026-09-26", "2026-09-27"),
+		days("国庆节", model.HolidayStatutory, "2026-10-01", "2026-10-02", "2026-10-03"),
+		days("国庆节", model.HolidayRest, "2026-10-04", "2026-10-05", "2026-10-06", "2026-10-07"),
+		days("国庆节调休", model.HolidayWorkday, "2026-09-20", "2026-10-10"),
+	),
+}
+
+// days 生成同名同类型的多天节假日
+func days(name, kind string, dates ...string) []model.Holiday {
+	result := make([]model.Holiday, len(dates))
+	for i, d := range dates {
+		result[i] = model.Holiday{Date: d, Name: name, Type: kind}
+	}
+	return result
+}
+
+func join(groups ...[]model.Holiday) []model.Holiday {
+	var result []model.Holiday
+	for _, g := range groups {
+		result = append(result, g...)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+// Builtin 返回内置的某年中国法定节假日及调休安排（按日期升序），没有该年数据时返回空
+func Builtin(year int) []model.Holiday {
+	return append([]model.Holiday(nil), china[year]...)
+}
+
+// BuiltinYears 返回有内置数据的年份（升序）
+func BuiltinYears() []int {
+	years := make([]int, 0, len(china))
+	for y := range china {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+	return years
+}
+
+// Calendar 节假日日历（内置法定节假日叠加组织自定义节假日），nil 表示没有节假日
+type Calendar struct {
+	days      map[string]model.Holiday
+	bonusRate float64 // 大于 0 时统一覆盖休息日的加班倍率
+}
+
+// NewCalendar 创建日历：builtin 为 true 时包含内置的中国法定节假日，
+// custom 中与内置节假日同一天的覆盖内置节假日
+func NewCalendar(builtin bool, custom []model.Holiday) *Calendar {
+	c := &Calendar{days: make(map[string]model.Holiday)}
+	if builtin {
+		for _, list := range china {
+			for _, h := range list {
+				c.days[h.Date] = h
+			}
+		}
+	}
+	for _, h := range custom {
+		if h.Type == "" {
+			h.Type = model.HolidayCustom
+		}
+		c.days[h.Date] = h
+	}
+	return c
+}
+
+// SetBonusRate 设置统一的假日加班倍率（大于 0 时覆盖各节假日按类型的倍率）
+func (c *Calendar) SetBonusRate(rate float64) {
+	c.bonusRate = rate
+}
+
+// Get 返回日期的节假日（含调休上班日）
+func (c *Calendar) Get(date string) (model.Holiday, bool) {
+	if c == nil {
+		return model.Holiday{}, false
+	}
+	h, ok := c.days[date]
+	return h, ok
+}
+
+// IsHoliday 检查日期是否为节假日休息日（调休上班日不是）
+func (c *Calendar) IsHoliday(date string) bool {
+	h, ok := c.Get(date)
+	return ok && h.IsOff()
+}
+
+// Rate 返回日期上班的加班倍率，非节假日为 1
+func (c *Calendar) Rate(date string) float64 {
+	h, ok := c.Get(date)
+	if !ok || !h.IsOff() {
+		return 1
+	}
+	if c.bonusRate > 0 {
+		return c.bonusRate
+	}
+	return h.Rate()
+}
+
+// Between 返回 [start, end] 内的节假日及调休上班日（按日期升序）
+func (c *Calendar) Between(start, end string) []model.Holiday {
+	result := make([]model.Holiday, 0)
+	if c == nil {
+		return result
+	}
+	for date, h := range c.days {
+		if date >= start && date <= end {
+			result = append(result, h)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
+
+// Year 返回某年的节假日及调休上班日（按日期升序）
+func (c *Calendar) Year(year int) []model.Holiday {
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	end := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	return c.Between(start, end)
+}
@@ -0,0 +1,65 @@
+package holiday
+
+import (
+	"testing"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestBuiltin(t *testing.T) {
+	for _, year := range BuiltinYears() {
+		list := Builtin(year)
+		if len(list) == 0 {
+			t.Fatalf("%d 年应有内置节假日", year)
+		}
+		for i, h := range list {
+			if err := h.Validate(); err != nil {
+				t.Errorf("%d 年内置节假日 %s 无效: %v", year, h.Date, err)
+			}
+			if i > 0 && list[i-1].Date >= h.Date {
+				t.Errorf("%d 年内置节假日应按日期升序且不重复: %s", year, h.Date)
+			}
+		}
+	}
+	if len(Builtin(1999)) != 0 {
+		t.Error("没有数据的年份应返回空")
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	cal := NewCalendar(true, []model.Holiday{
+		{Date: "2025-10-08", Name: "店内补休", Type: model.HolidayWorkday},
+		{Date: "2025-06-18", Name: "店庆", PremiumRate: 1.5},
+	})
+
+	if !cal.IsHoliday("2025-10-01") || cal.Rate("2025-10-01") != 3 {
+		t.Errorf("国庆为法定节假日，加班倍率 3: %v", cal.Rate("2025-10-01"))
+	}
+	if !cal.IsHoliday("2025-10-05") || cal.Rate("2025-10-05") != 2 {
+		t.Errorf("调休休息日加班倍率 2: %v", cal.Rate("2025-10-05"))
+	}
+	if cal.IsHoliday("2025-10-11") || cal.Rate("2025-10-11") != 1 {
+		t.Error("调休上班日按工作日处理")
+	}
+	if cal.IsHoliday("2025-10-08") {
+		t.Error("自定义节假日应覆盖内置节假日")
+	}
+	if h, _ := cal.Get("2025-06-18"); h.Type != model.HolidayCustom || cal.Rate("2025-06-18") != 1.5 {
+		t.Errorf("自定义节假日默认 custom，倍率取自定义值: %+v", h)
+	}
+
+	cal.SetBonusRate(2)
+	if cal.Rate("2025-10-01") != 2 || cal.Rate("2025-10-11") != 1 {
+		t.Error("统一加班倍率只覆盖休息日")
+	}
+
+	list := cal.Between("2025-09-28", "2025-10-11")
+	if len(list) != 10 || list[0].Date != "2025-09-28" || list[len(list)-1].Date != "2025-10-11" {
+		t.Errorf("国庆前后应有 10 天节假日及调休安排: %d", len(list))
+	}
+
+	var none *Calendar
+	if none.IsHoliday("2025-10-01") || none.Rate("2025-10-01") != 1 || len(none.Year(2025)) != 0 {
+		t.Error("空日历没有节假日")
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 标签别名类型
+const (
+	AliasKindSkill         = "skill"
+	AliasKindPosition      = "position"
+	AliasKindCertification = "certification"
+)
+
+// 未映射标签原因
+const (
+	UnmappedNoAlias = "no_alias" // 该类型已配置别名，但标签不在任何映射中
+	UnmappedNoMatch = "no_match" // 需求要求的标签没有任何员工具备
+)
+
+// LabelAlias 标签别名映射（如 "厨师"/"Cook" -> "cook"）
+type LabelAlias struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	Kind      string    `json:"kind"`      // skill/position/certification
+	Canonical string    `json:"canonical"` // 规范编码
+	Aliases   []string  `json:"aliases"`   // 别名（不区分大小写）
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UnmappedLabel 无法归一化或无法匹配的标签
+type UnmappedLabel struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	Kind      string    `json:"kind"`
+	Label     string    `json:"label"`
+	Reason    string    `json:"reason"` // no_alias/no_match
+	Source    string    `json:"source"` // employee/requirement/order
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
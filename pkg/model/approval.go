@@ -0,0 +1,160 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 审批类型
+const (
+	ApprovalKindSwap     = "swap"     // 换班
+	ApprovalKindOvertime = "overtime" // 加班
+	ApprovalKindSchedule = "schedule" // 排班发布/调整
+)
+
+// 审批状态
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// 审批流转原因（ApprovalRoute.Reason）
+const (
+	RouteAssigned  = "assigned"  // 提交时指定审批人
+	RouteDelegated = "delegated" // 审批人外出，转交委托人
+	RouteEscalated = "escalated" // 超过时限未处理，升级
+	RouteReturned  = "returned"  // 委托结束或撤销，退回原审批人
+)
+
+// IsValidApprovalKind 检查审批类型是否受支持
+func IsValidApprovalKind(kind string) bool {
+	switch kind {
+	case ApprovalKindSwap, ApprovalKindOvertime, ApprovalKindSchedule:
+		return true
+	}
+	return false
+}
+
+// ApprovalRequest 审批单
+// Approver 为提交时指定的审批人，AssignedTo 为当前处理人（委托或升级后会变化），
+// 委托人代为审批时 DecidedBy 为实际审批人、OnBehalfOf 为原审批人
+type ApprovalRequest struct {
+	BaseModel
+	OrgID       uuid.UUID       `json:"org_id" db:"org_id"`
+	Kind        string          `json:"kind" db:"kind"`                           // swap/overtime/schedule
+	SubjectID   *uuid.UUID      `json:"subject_id,omitempty" db:"subject_id"`     // 审批对象（换班申请、排班等）
+	Title       string          `json:"title" db:"title"`                         // 审批标题
+	Detail      string          `json:"detail,omitempty" db:"detail"`             // 审批说明
+	EmployeeID  *uuid.UUID      `json:"employee_id,omitempty" db:"employee_id"`   // 加班员工（加班审批）
+	Date        string          `json:"date,omitempty" db:"date"`                 // 加班日期 YYYY-MM-DD（加班审批）
+	Hours       float64         `json:"hours,omitempty" db:"hours"`               // 加班时长（加班审批），审批通过后按调休策略结算
+	RequestedBy string          `json:"requested_by,omitempty" db:"requested_by"` // 提交人
+	Approver    string          `json:"approver" db:"approver"`                   // 指定审批人
+	AssignedTo  string          `json:"assigned_to" db:"assigned_to"`             // 当前处理人
+	Status      string          `json:"status" db:"status"`                       // pending/approved/rejected
+	DueAt       *time.Time      `json:"due_at,omitempty" db:"due_at"`             // 处理时限（按组织审批策略）
+	Reminders   int             `json:"reminders,omitempty" db:"reminders"`       // 已发送催办次数
+	RemindedAt  *time.Time      `json:"reminded_at,omitempty" db:"reminded_at"`   // 最近一次催办时间
+	EscalatedAt *time.Time      `json:"escalated_at,omitempty" db:"escalated_at"` // 升级时间
+	DecidedBy   string          `json:"decided_by,omitempty" db:"decided_by"`     // 实际审批人
+	OnBehalfOf  string          `json:"on_behalf_of,omitempty" db:"on_behalf_of"` // 代审批时的原审批人
+	Note        string          `json:"note,omitempty" db:"note"`                 // 审批意见
+	DecidedAt   *time.Time      `json:"decided_at,omitempty" db:"decided_at"`     // 审批时间
+	Routes      []ApprovalRoute `json:"routes,omitempty" db:"routes"`             // 流转记录
+}
+
+// ApprovalRoute 审批流转记录
+type ApprovalRoute struct {
+	At           time.Time  `json:"at"`
+	From         string     `json:"from,omitempty"`
+	To           string     `json:"to"`
+	Reason       string     `json:"reason"`                  // assigned/delegated/escalated/returned
+	DelegationID *uuid.UUID `json:"delegation_id,omitempty"` // 按哪条委托转交
+}
+
+// IsPending 是否待审批
+func (a *ApprovalRequest) IsPending() bool {
+	return a.Status == ApprovalPending
+}
+
+// ApprovalDelegation 审批委托：Delegator 在 [StartDate, EndDate] 期间外出，审批转交 Delegate
+type ApprovalDelegation struct {
+	ID        uuid.UUID `json:"id"`
+	OrgID     uuid.UUID `json:"org_id"`
+	Delegator string    `json:"delegator"`       // 委托人（外出的审批人）
+	Delegate  string    `json:"delegate"`        // 受托人
+	StartDate string    `json:"start_date"`      // YYYY-MM-DD
+	EndDate   string    `json:"end_date"`        // YYYY-MM-DD（含）
+	Kinds     []string  `json:"kinds,omitempty"` // 委托的审批类型，为空表示全部
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate 校验委托配置
+func (d *ApprovalDelegation) Validate() error {
+	if d.Delegator == "" || d.Delegate == "" {
+		return fmt.Errorf("委托人和受托人不能为空")
+	}
+	if d.Delegator == d.Delegate {
+		return fmt.Errorf("不能委托给自己")
+	}
+	start, err1 := time.Parse("2006-01-02", d.StartDate)
+	end, err2 := time.Parse("2006-01-02", d.EndDate)
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("委托日期格式应为 YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return fmt.Errorf("委托结束日期不能早于开始日期")
+	}
+	for _, k := range d.Kinds {
+		if !IsValidApprovalKind(k) {
+			return fmt.Errorf("不支持的审批类型: %s", k)
+		}
+	}
+	return nil
+}
+
+// Covers 委托在某日对某类审批是否生效
+func (d *ApprovalDelegation) Covers(date, kind string) bool {
+	if date < d.StartDate || date > d.EndDate {
+		return false
+	}
+	if len(d.Kinds) == 0 {
+		return true
+	}
+	for _, k := range d.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ApprovalPolicy 组织审批策略
+// 审批单提交 SLAHours 小时后未处理则催办当前处理人，之后每隔 SLAHours 再次催办；
+// 催办 EscalateAfter 次仍未处理时升级给 EscalateTo（未配置则只催办）
+type ApprovalPolicy struct {
+	SLAHours      float64 `json:"sla_hours"`
+	EscalateAfter int     `json:"escalate_after,omitempty"` // 升级前的催办次数，默认 1
+	EscalateTo    string  `json:"escalate_to,omitempty"`    // 升级审批人
+}
+
+// Validate 检查审批策略是否合法
+func (p *ApprovalPolicy) Validate() error {
+	if p.SLAHours <= 0 {
+		return fmt.Errorf("审批时限必须大于0")
+	}
+	if p.EscalateAfter < 0 {
+		return fmt.Errorf("升级前的催办次数不能为负数")
+	}
+	return nil
+}
+
+// SLA 返回审批时限
+func (p *ApprovalPolicy) SLA() time.Duration {
+	return time.Duration(p.SLAHours * float64(time.Hour))
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 审计操作
+const (
+	AuditScheduleGenerate = "schedule.generate"        // 生成排班（保存的排班或异步生成作业）
+	AuditSchedulePublish  = "schedule.publish"         // 发布排班或设置计划公布时间
+	AuditScheduleArchive  = "schedule.archive"         // 归档排班
+	AuditScheduleRollback = "schedule.rollback"        // 回滚排班到之前的版本
+	AuditAssignmentEdit   = "assignment.edit"          // 手工修改分配
+	AuditAssignmentMerge  = "assignment.merge"         // 基于旧版本合并分配修改
+	AuditSwapApply        = "swap.apply"               // 应用换班
+	AuditConstraintChange = "constraint_config.change" // 修改约束配置（组织约束、约束参数、派单约束）
+)
+
+// AuditLog 审计日志
+// 记录修改类操作的操作人（who）、时间（when）和内容（what），RequestID 与请求日志的 X-Request-ID 对应
+type AuditLog struct {
+	ID           uuid.UUID              `json:"id" db:"id"`
+	OrgID        uuid.UUID              `json:"org_id" db:"org_id"`
+	Actor        string                 `json:"actor,omitempty" db:"actor"` // 操作人用户ID（X-User-ID）
+	Role         string                 `json:"role,omitempty" db:"role"`   // 操作人角色（X-User-Role）
+	Action       string                 `json:"action" db:"action"`
+	ResourceType string                 `json:"resource_type" db:"resource_type"` // schedule/org_constraint/constraint_config/dispatch_constraints
+	ResourceID   string                 `json:"resource_id,omitempty" db:"resource_id"`
+	RequestID    string                 `json:"request_id,omitempty" db:"request_id"`
+	Method       string                 `json:"method,omitempty" db:"method"`
+	Path         string                 `json:"path,omitempty" db:"path"`
+	Detail       map[string]interface{} `json:"detail,omitempty" db:"detail"`
+	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter 审计日志查询条件，零值字段不过滤
+type AuditFilter struct {
+	OrgID     uuid.UUID
+	From      time.Time // 含
+	To        time.Time // 不含
+	Action    string
+	Actor     string
+	RequestID string
+	Offset    int
+	Limit     int
+}
+
+// Match 审计日志是否满足查询条件（不含分页）
+func (f AuditFilter) Match(l *AuditLog) bool {
+	if f.OrgID != uuid.Nil && l.OrgID != f.OrgID {
+		return false
+	}
+	if !f.From.IsZero() && l.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !l.CreatedAt.Before(f.To) {
+		return false
+	}
+	return (f.Action == "" || l.Action == f.Action) &&
+		(f.Actor == "" || l.Actor == f.Actor) &&
+		(f.RequestID == "" || l.RequestID == f.RequestID)
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 请假审核状态（EmployeeAvailability.ReviewStatus）
+const (
+	LeaveApproved      = "approved"       // 已批准（未设置审核状态的请假视为已批准）
+	LeavePendingReview = "pending_review" // 管控期内超出名额，待管理者审核
+	LeaveRejected      = "rejected"       // 已驳回，排班时不再视为不可用
+)
+
+// DefaultBlackoutPriorityBoost 管控期内排班需求默认提升的优先级
+const DefaultBlackoutPriorityBoost = 3
+
+// BlackoutPeriod 请假管控期（如春节周、促销季）
+// 管控期内每天的请假人数超过名额时，新的请假自动标记为待审核；排班时自动提升该期间需求的覆盖优先级
+type BlackoutPeriod struct {
+	ID             uuid.UUID `json:"id"`
+	OrgID          uuid.UUID `json:"org_id"`
+	Name           string    `json:"name"`
+	StoreID        string    `json:"store_id,omitempty"` // 为空表示适用于全组织
+	StartDate      string    `json:"start_date"`         // YYYY-MM-DD
+	EndDate        string    `json:"end_date"`           // YYYY-MM-DD（含）
+	MaxLeavePerDay int       `json:"max_leave_per_day"`  // 每天允许的请假人数，0 表示不允许请假
+	PriorityBoost  int       `json:"priority_boost"`     // 期间排班需求提升的优先级（上限 10），0 表示使用默认值
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Validate 校验管控期配置
+func (p *BlackoutPeriod) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("管控期名称不能为空")
+	}
+	start, err1 := time.Parse("2006-01-02", p.StartDate)
+	end, err2 := time.Parse("2006-01-02", p.EndDate)
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("管控期 %s 的日期格式应为 YYYY-MM-DD", p.Name)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("管控期 %s 的结束日期不能早于开始日期", p.Name)
+	}
+	if p.MaxLeavePerDay < 0 {
+		return fmt.Errorf("管控期 %s 的每日请假名额不能为负数", p.Name)
+	}
+	if p.PriorityBoost < 0 {
+		return fmt.Errorf("管控期 %s 的优先级提升不能为负数", p.Name)
+	}
+	return nil
+}
+
+// Contains 检查日期是否在管控期内
+func (p *BlackoutPeriod) Contains(date string) bool {
+	return date >= p.StartDate && date <= p.EndDate
+}
+
+// AppliesTo 检查管控期是否适用于某门店的员工
+func (p *BlackoutPeriod) AppliesTo(storeID string) bool {
+	return p.StoreID == "" || p.StoreID == storeID
+}
+
+// Boost 返回期间排班需求提升的优先级
+func (p *BlackoutPeriod) Boost() int {
+	if p.PriorityBoost == 0 {
+		return DefaultBlackoutPriorityBoost
+	}
+	return p.PriorityBoost
+}
+
+// IsLeave 是否为全天请假（全天不可用）
+func (av *EmployeeAvailability) IsLeave() bool {
+	return av.Type == "unavailable" && len(av.TimeRanges) == 0
+}
+
+// IsApprovedLeave 是否为已批准的全天请假
+func (av *EmployeeAvailability) IsApprovedLeave() bool {
+	return av.IsLeave() && (av.ReviewStatus == "" || av.ReviewStatus == LeaveApproved)
+}
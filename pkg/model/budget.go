@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StoreHoursBudget 门店每周工时预算（由财务下达）
+type StoreHoursBudget struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	StoreID   string    `json:"store_id"`
+	WeekStart string    `json:"week_start,omitempty"` // 周起始日（周日，YYYY-MM-DD），为空表示该门店的默认每周预算
+	Hours     float64   `json:"hours"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BudgetWeekStart 返回日期所在周的起始日（周日），与每周最大工时约束的周划分一致
+func BudgetWeekStart(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.AddDate(0, 0, -int(t.Weekday())).Format("2006-01-02")
+}
+
+// ResolveStoreBudget 查找门店某周的工时预算，指定周的预算优先于默认预算
+func ResolveStoreBudget(budgets []StoreHoursBudget, storeID, weekStart string) (float64, bool) {
+	hours, found := 0.0, false
+	for _, b := range budgets {
+		if b.StoreID != storeID {
+			continue
+		}
+		if b.WeekStart == weekStart {
+			return b.Hours, true
+		}
+		if b.WeekStart == "" {
+			hours, found = b.Hours, true
+		}
+	}
+	return hours, found
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 批量作业类型
+const (
+	BulkKindImport   = "import"   // 历史分配导入（回填）
+	BulkKindDispatch = "dispatch" // 批量派单
+	BulkKindValidate = "validate" // 批量验证排班
+)
+
+// 批量作业状态
+const (
+	BulkQueued    = "queued"
+	BulkRunning   = "running"
+	BulkCompleted = "completed" // 全部批次完成（可能有行级错误）
+	BulkPartial   = "partial"   // 部分批次多次重试仍失败，可恢复
+	BulkCancelled = "cancelled"
+)
+
+// 批次状态
+const (
+	BatchPending   = "pending"
+	BatchRunning   = "running"
+	BatchCompleted = "completed"
+	BatchFailed    = "failed"
+	BatchCancelled = "cancelled"
+)
+
+// BulkJob 批量作业
+// 大批量请求按 BatchSize 拆分为批次在后台依次执行，每批记录进度、行级错误和重试次数；
+// 原始数据与结果随作业保存，服务重启或批次失败后可从未完成的批次继续
+type BulkJob struct {
+	ID         uuid.UUID       `json:"id"`
+	OrgID      uuid.UUID       `json:"org_id"`
+	Kind       string          `json:"kind"`
+	Status     string          `json:"status"`
+	BatchSize  int             `json:"batch_size"`
+	Params     json.RawMessage `json:"params,omitempty"` // 各批次共用的参数（如派单候选人）
+	Total      int             `json:"total"`
+	Processed  int             `json:"processed"`
+	Succeeded  int             `json:"succeeded"`
+	Failed     int             `json:"failed"`
+	Batches    []BulkBatch     `json:"batches"`
+	Errors     []BulkRowError  `json:"errors"`
+	CreatedBy  string          `json:"created_by,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+
+	Rows    []json.RawMessage `json:"rows,omitempty"`    // 原始数据
+	Results []json.RawMessage `json:"results,omitempty"` // 与 Rows 一一对应的结果，失败行为空
+}
+
+// BulkBatch 批次（行号区间 [Start, End)）
+type BulkBatch struct {
+	Index     int        `json:"index"`
+	Start     int        `json:"start"`
+	End       int        `json:"end"`
+	Status    string     `json:"status"`
+	Attempts  int        `json:"attempts"`
+	Succeeded int        `json:"succeeded"`
+	Failed    int        `json:"failed"`
+	Error     string     `json:"error,omitempty"` // 整批失败的原因
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+// BulkRowError 行级错误
+type BulkRowError struct {
+	Row     int    `json:"row"` // 原始数据中的行号（从 0 开始）
+	Message string `json:"message"`
+}
+
+// IsValidBulkKind 检查批量作业类型是否受支持
+func IsValidBulkKind(kind string) bool {
+	switch kind {
+	case BulkKindImport, BulkKindDispatch, BulkKindValidate:
+		return true
+	}
+	return false
+}
+
+// IsFinished 作业是否已结束（不再有待执行的批次）
+func (j *BulkJob) IsFinished() bool {
+	return j.Status == BulkCompleted || j.Status == BulkPartial || j.Status == BulkCancelled
+}
+
+// Summary 返回不含原始数据和结果的副本（用于查询进度）
+func (j *BulkJob) Summary() *BulkJob {
+	c := *j
+	c.Rows, c.Results = nil, nil
+	c.Batches = append([]BulkBatch(nil), j.Batches...)
+	c.Errors = append([]BulkRowError(nil), j.Errors...)
+	return &c
+}
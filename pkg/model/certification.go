@@ -0,0 +1,73 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 证书材料审核状态
+const (
+	CertificationPending  = "pending"  // 待审核
+	CertificationVerified = "verified" // 已核验
+	CertificationRejected = "rejected" // 已驳回
+)
+
+// VerifiedCertification 员工已核验的证书
+type VerifiedCertification struct {
+	Name      string `json:"name"`
+	ExpiresAt string `json:"expires_at,omitempty"` // 有效期至 YYYY-MM-DD（含当天），为空表示长期有效
+}
+
+// CertificationDocument 证书材料（健康证、无犯罪证明等）
+// 只保存文件引用和证书信息，文件本身存放在外部存储；审核通过后员工的该证书视为已核验
+type CertificationDocument struct {
+	ID            uuid.UUID  `json:"id"`
+	OrgID         uuid.UUID  `json:"org_id"`
+	EmployeeID    uuid.UUID  `json:"employee_id"`
+	Certification string     `json:"certification"`         // 证书名称，与员工 certifications 一致，如"健康证"
+	FileRef       string     `json:"file_ref"`              // 文件引用（对象存储 key 或 URL）
+	Issuer        string     `json:"issuer,omitempty"`      // 发证机构
+	DocumentNo    string     `json:"document_no,omitempty"` // 证书编号
+	IssuedAt      string     `json:"issued_at,omitempty"`   // 发证日期 YYYY-MM-DD
+	ExpiresAt     string     `json:"expires_at,omitempty"`  // 有效期至 YYYY-MM-DD（含当天），为空表示长期有效
+	Status        string     `json:"status"`
+	ReviewNote    string     `json:"review_note,omitempty"`
+	SubmittedBy   string     `json:"submitted_by,omitempty"`
+	SubmittedAt   time.Time  `json:"submitted_at"`
+	ReviewedBy    string     `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// Validate 校验证书材料
+func (d *CertificationDocument) Validate() error {
+	if d.EmployeeID == uuid.Nil {
+		return fmt.Errorf("员工ID不能为空")
+	}
+	if d.Certification == "" || d.FileRef == "" {
+		return fmt.Errorf("证书名称和文件引用不能为空")
+	}
+	for _, date := range []string{d.IssuedAt, d.ExpiresAt} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("日期格式无效: %s", date)
+		}
+	}
+	if d.IssuedAt != "" && d.ExpiresAt != "" && d.ExpiresAt < d.IssuedAt {
+		return fmt.Errorf("有效期不能早于发证日期")
+	}
+	return nil
+}
+
+// IsPending 是否待审核
+func (d *CertificationDocument) IsPending() bool {
+	return d.Status == CertificationPending
+}
+
+// IsVerifiedOn 证书在指定日期是否已核验且在有效期内
+func (d *CertificationDocument) IsVerifiedOn(date string) bool {
+	return d.Status == CertificationVerified && (d.ExpiresAt == "" || date <= d.ExpiresAt)
+}
@@ -2,6 +2,7 @@
 package model
 
 import (
+	"fmt"
 	"math"
 	"time"
 
@@ -51,6 +52,81 @@ type Organization struct {
 	Code     string       `json:"code" db:"code"`
 	Type     ScenarioType `json:"type" db:"type"`
 	Settings JSONMap      `json:"settings" db:"settings"`
+
+	// 排班发布规则，为空表示发布后立即对员工可见
+	PublicationRule *PublicationRule `json:"publication_rule,omitempty" db:"publication_rule"`
+
+	// 审批策略（催办与升级），为空表示不催办
+	ApprovalPolicy *ApprovalPolicy `json:"approval_policy,omitempty" db:"approval_policy"`
+
+	// 调休策略（加班计入调休的比例），为空表示加班全部支付加班费
+	TimeBankPolicy *TimeBankPolicy `json:"time_bank_policy,omitempty" db:"time_bank_policy"`
+
+	// 订单完工策略（完成前须提交的凭证），为空表示不要求凭证
+	CompletionPolicy *CompletionPolicy `json:"completion_policy,omitempty" db:"completion_policy"`
+
+	// 证件到期提醒策略（各类证件提前提醒天数），为空表示统一提前 30 天
+	DocumentAlertPolicy *DocumentAlertPolicy `json:"document_alert_policy,omitempty" db:"document_alert_policy"`
+
+	// 派单约束配置（参数、权重、启用状态），为空表示使用默认约束；派单请求的 constraints 可单独覆盖
+	DispatchConstraints DispatchConstraintConfig `json:"dispatch_constraints,omitempty" db:"dispatch_constraints"`
+
+	// 排班周期（两周/四周轮班），为空表示按周；排班请求的约束配置可单独覆盖
+	ScheduleCycle *ScheduleCycle `json:"schedule_cycle,omitempty" db:"schedule_cycle"`
+}
+
+// PublicationRule 排班发布规则
+// 排班在周期开始前最近一次的 Weekday Time 对员工可见，
+// 例如 Weekday=4、Time="18:00" 表示下周排班在本周四 18:00 公布
+type PublicationRule struct {
+	Weekday  time.Weekday `json:"weekday"`            // 0=周日, 1=周一, ..., 6=周六
+	Time     string       `json:"time"`               // HH:MM
+	Timezone string       `json:"timezone,omitempty"` // IANA 时区，默认服务器本地时区
+}
+
+// Validate 检查规则是否合法
+func (r *PublicationRule) Validate() error {
+	if r.Weekday < time.Sunday || r.Weekday > time.Saturday {
+		return fmt.Errorf("weekday 必须在 0-6 之间")
+	}
+	if _, err := time.Parse("15:04", r.Time); err != nil {
+		return fmt.Errorf("time 格式应为 HH:MM")
+	}
+	if _, err := r.location(); err != nil {
+		return fmt.Errorf("无效的时区: %s", r.Timezone)
+	}
+	return nil
+}
+
+// ReleaseTime 计算周期从 startDate (YYYY-MM-DD) 开始的排班的公布时间
+func (r *PublicationRule) ReleaseTime(startDate string) (time.Time, error) {
+	loc, err := r.location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	start, err := time.ParseInLocation("2006-01-02", startDate, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	at, err := time.Parse("15:04", r.Time)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// 周期开始前最近的一个 Weekday（不含开始当天）
+	days := (int(start.Weekday()) - int(r.Weekday) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	release := start.AddDate(0, 0, -days)
+	return time.Date(release.Year(), release.Month(), release.Day(), at.Hour(), at.Minute(), 0, 0, loc), nil
+}
+
+func (r *PublicationRule) location() (*time.Location, error) {
+	if r.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(r.Timezone)
 }
 
 // JSONMap 用于存储 JSONB 数据
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ComplianceCertificate 工时合规证明
+// 针对已结账期间的已发布排班，按组织约束配置的工时类硬性规则逐个员工核查；
+// Digest 为证明内容（不含 Digest、Signature）的 SHA-256 摘要，配置签名密钥时 Signature 为 HMAC-SHA256 签名
+type ComplianceCertificate struct {
+	ID            uuid.UUID            `json:"id"`
+	OrgID         uuid.UUID            `json:"org_id"`
+	OrgName       string               `json:"org_name,omitempty"`
+	StartDate     string               `json:"start_date"`
+	EndDate       string               `json:"end_date"`
+	ConfigVersion int                  `json:"config_version,omitempty"` // 使用的组织约束配置版本，0 表示默认配置
+	Rules         []ComplianceRule     `json:"rules"`
+	Employees     []EmployeeCompliance `json:"employees"`
+	Compliant     bool                 `json:"compliant"`  // 所有员工均无未审批的例外
+	Exceptions    int                  `json:"exceptions"` // 例外总数
+	Approved      int                  `json:"approved"`   // 其中有审批依据的例外数
+	IssuedBy      string               `json:"issued_by,omitempty"`
+	IssuedAt      time.Time            `json:"issued_at"`
+	Digest        string               `json:"digest"`
+	Signature     string               `json:"signature,omitempty"`
+}
+
+// ComplianceRule 参与核查的硬性规则
+type ComplianceRule struct {
+	Type  string `json:"type"`  // 约束类型，如 max_hours_per_week
+	Name  string `json:"name"`  // 约束名称
+	Limit int    `json:"limit"` // 规则阈值（小时或天数）
+}
+
+// EmployeeCompliance 员工合规情况
+type EmployeeCompliance struct {
+	EmployeeID   uuid.UUID             `json:"employee_id"`
+	EmployeeName string                `json:"employee_name"`
+	Shifts       int                   `json:"shifts"`
+	Hours        float64               `json:"hours"`
+	Compliant    bool                  `json:"compliant"` // 没有未审批的例外
+	Exceptions   []ComplianceException `json:"exceptions,omitempty"`
+}
+
+// ComplianceException 违反硬性规则的例外
+// 员工当天有审批通过的加班审批时视为有审批依据，Reason 为审批标题和说明
+type ComplianceException struct {
+	Rule       string     `json:"rule"`
+	Date       string     `json:"date"`
+	Message    string     `json:"message"`
+	Approved   bool       `json:"approved"`
+	ApprovalID *uuid.UUID `json:"approval_id,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConstraintConfigVersion 组织约束配置版本
+// 每次保存组织约束配置生成一个新版本（从 1 开始递增），用于跨组织、跨版本对比规则设置
+type ConstraintConfigVersion struct {
+	OrgID     uuid.UUID `json:"org_id"`
+	Version   int       `json:"version"`
+	Config    JSONMap   `json:"config"` // 格式同排班请求的 constraints
+	Note      string    `json:"note,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrgConstraint 组织级约束配置：单条约束的权重、参数和启用状态
+// 排班请求未提供 constraints 时，组织已启用的约束配置作为默认约束参数
+type OrgConstraint struct {
+	ID        uuid.UUID `json:"id"`
+	OrgID     uuid.UUID `json:"org_id"`
+	Name      string    `json:"name"`     // 约束名称（约束库名称或插件名称）
+	Category  string    `json:"category"` // hard/soft
+	Weight    int       `json:"weight"`   // 1-100
+	Params    JSONMap   `json:"params"`   // 约束参数，键同排班请求的 constraints
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ScenarioTemplate 自定义场景模板：以场景标识命名的一组约束配置
+// 排班请求的 scenario 为自定义场景标识时，模板的约束配置作为约束参数的基础（请求中的约束优先）
+type ScenarioTemplate struct {
+	ID          uuid.UUID `json:"id"`
+	Scenario    string    `json:"scenario"` // 场景标识（如 my_custom_template），全局唯一
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Constraints JSONMap   `json:"constraints"` // 格式同排班请求的 constraints
+	IsDefault   bool      `json:"is_default"`  // 内置场景的默认模板（只读）
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
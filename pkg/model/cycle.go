@@ -0,0 +1,86 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultCycleAnchor 未指定起始日时排班周期的起算日（周日，与按周统计的周起始日一致）
+const DefaultCycleAnchor = "2023-01-01"
+
+// ScheduleCycle 排班周期（轮班表周期）
+// 工厂常按两周或四周轮班，周工时上限、公平性统计窗口和倒班轮换按周期计算；
+// 周期从 AnchorDate 起每 Weeks 周为一期，跨排班保持一致
+type ScheduleCycle struct {
+	Weeks      int    `json:"weeks"`                 // 1/2/4
+	AnchorDate string `json:"anchor_date,omitempty"` // 任一周期的起始日，默认 DefaultCycleAnchor
+}
+
+// Validate 检查排班周期是否合法
+func (c *ScheduleCycle) Validate() error {
+	if c.Weeks != 1 && c.Weeks != 2 && c.Weeks != 4 {
+		return fmt.Errorf("排班周期只支持 1、2、4 周")
+	}
+	if c.AnchorDate != "" {
+		if _, err := time.Parse("2006-01-02", c.AnchorDate); err != nil {
+			return fmt.Errorf("周期起始日格式应为 YYYY-MM-DD")
+		}
+	}
+	return nil
+}
+
+// Days 返回周期天数（未配置时按 1 周）
+func (c ScheduleCycle) Days() int {
+	if c.Weeks <= 0 {
+		return 7
+	}
+	return c.Weeks * 7
+}
+
+// Start 返回日期所在周期的起始日，日期格式错误时原样返回
+func (c ScheduleCycle) Start(date string) string {
+	return c.SegmentStart(date, c.Days())
+}
+
+// End 返回日期所在周期的最后一天
+func (c ScheduleCycle) End(date string) string {
+	_, end := c.Bounds(date)
+	return end
+}
+
+// Bounds 返回日期所在周期的起始日和最后一天，日期格式错误时都原样返回
+func (c ScheduleCycle) Bounds(date string) (start, end string) {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date, date
+	}
+	first := c.segmentStart(t, c.Days())
+	return first.Format("2006-01-02"), first.AddDate(0, 0, c.Days()-1).Format("2006-01-02")
+}
+
+// SegmentStart 从周期起始日起每 days 天为一段，返回日期所在段的起始日（如倒班轮换段），日期格式错误时原样返回
+func (c ScheduleCycle) SegmentStart(date string, days int) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil || days <= 0 {
+		return date
+	}
+	return c.segmentStart(t, days).Format("2006-01-02")
+}
+
+// defaultAnchor 解析后的 DefaultCycleAnchor
+var defaultAnchor, _ = time.Parse("2006-01-02", DefaultCycleAnchor)
+
+// segmentStart 返回 t 所在段（从周期起始日起每 days 天）的起始日
+func (c ScheduleCycle) segmentStart(t time.Time, days int) time.Time {
+	anchor := defaultAnchor
+	if c.AnchorDate != "" && c.AnchorDate != DefaultCycleAnchor {
+		if a, err := time.Parse("2006-01-02", c.AnchorDate); err == nil {
+			anchor = a
+		}
+	}
+	offset := int(t.Sub(anchor).Hours()/24) % days
+	if offset < 0 {
+		offset += days
+	}
+	return t.AddDate(0, 0, -offset)
+}
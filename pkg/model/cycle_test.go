@@ -0,0 +1,41 @@
+package model
+
+import "testing"
+
+func TestScheduleCycle_Start(t *testing.T) {
+	tests := []struct {
+		name  string
+		cycle ScheduleCycle
+		date  string
+		start string
+		end   string
+	}{
+		{"按周从周日开始", ScheduleCycle{Weeks: 1}, "2026-03-04", "2026-03-01", "2026-03-07"},
+		{"两周周期", ScheduleCycle{Weeks: 2, AnchorDate: "2026-03-02"}, "2026-03-15", "2026-03-02", "2026-03-15"},
+		{"两周周期下一期", ScheduleCycle{Weeks: 2, AnchorDate: "2026-03-02"}, "2026-03-16", "2026-03-16", "2026-03-29"},
+		{"起始日之前的日期", ScheduleCycle{Weeks: 4, AnchorDate: "2026-03-02"}, "2026-03-01", "2026-02-02", "2026-03-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cycle.Start(tt.date); got != tt.start {
+				t.Errorf("Start(%s) = %s, want %s", tt.date, got, tt.start)
+			}
+			if got := tt.cycle.End(tt.date); got != tt.end {
+				t.Errorf("End(%s) = %s, want %s", tt.date, got, tt.end)
+			}
+		})
+	}
+}
+
+func TestScheduleCycle_Validate(t *testing.T) {
+	for _, c := range []ScheduleCycle{{Weeks: 1}, {Weeks: 2}, {Weeks: 4, AnchorDate: "2026-03-02"}} {
+		if err := c.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", c, err)
+		}
+	}
+	for _, c := range []ScheduleCycle{{Weeks: 0}, {Weeks: 3}, {Weeks: 2, AnchorDate: "2026/03/02"}} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) 应返回错误", c)
+		}
+	}
+}
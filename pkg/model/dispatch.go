@@ -2,6 +2,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -35,6 +36,25 @@ type CustomerPrefs struct {
 	LanguageRequired  string            `json:"language_required,omitempty"`   // 语言要求
 	RequireSameWorker bool              `json:"require_same_worker,omitempty"` // 要求同一服务者
 	CustomPrefs       map[string]string `json:"custom,omitempty"`
+
+	// 服务人员数量控制：滚动窗口内为客户服务的不同服务人员不超过上限
+	MaxCaregivers       int `json:"max_caregivers,omitempty"`        // 不同服务人员上限，0 表示不限
+	CaregiverWindowDays int `json:"caregiver_window_days,omitempty"` // 统计窗口天数，默认 DefaultCaregiverWindowDays
+}
+
+// DefaultCaregiverWindowDays 统计客户不同服务人员数量的默认滚动窗口（天）
+const DefaultCaregiverWindowDays = 30
+
+// CaregiverWindow 返回客户统计不同服务人员的窗口天数和上限（上限 0 表示不限）
+func (c *Customer) CaregiverWindow() (days, max int) {
+	days = DefaultCaregiverWindowDays
+	if c == nil || c.Preferences == nil {
+		return days, 0
+	}
+	if c.Preferences.CaregiverWindowDays > 0 {
+		days = c.Preferences.CaregiverWindowDays
+	}
+	return days, c.Preferences.MaxCaregivers
 }
 
 // ServiceNeed 服务需求
@@ -48,24 +68,171 @@ type ServiceNeed struct {
 // ServiceOrder 服务订单
 type ServiceOrder struct {
 	BaseModel
-	OrgID       uuid.UUID  `json:"org_id" db:"org_id"`
-	CustomerID  uuid.UUID  `json:"customer_id" db:"customer_id"`
-	OrderNo     string     `json:"order_no" db:"order_no"`
-	ServiceType string     `json:"service_type" db:"service_type"`
-	ServiceDate string     `json:"service_date" db:"service_date"` // YYYY-MM-DD
-	StartTime   string     `json:"start_time" db:"start_time"`     // HH:MM
-	EndTime     string     `json:"end_time" db:"end_time"`         // HH:MM
-	Duration    int        `json:"duration" db:"duration"`         // 分钟
-	Address     string     `json:"address" db:"address"`
-	Location    *Location  `json:"location,omitempty" db:"location"`
-	Status      string     `json:"status" db:"status"` // pending/assigned/in_progress/completed/cancelled
-	EmployeeID  *uuid.UUID `json:"employee_id,omitempty" db:"employee_id"`
-	Skills      []string   `json:"skills,omitempty" db:"skills"`
-	Priority    int        `json:"priority" db:"priority"`
-	Notes       string     `json:"notes,omitempty" db:"notes"`
-	Amount      float64    `json:"amount" db:"amount"`
-	AssignedAt  *time.Time `json:"assigned_at,omitempty" db:"assigned_at"`
-	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	OrgID       uuid.UUID    `json:"org_id" db:"org_id"`
+	CustomerID  uuid.UUID    `json:"customer_id" db:"customer_id"`
+	OrderNo     string       `json:"order_no" db:"order_no"`
+	ServiceType string       `json:"service_type" db:"service_type"`
+	ServiceDate string       `json:"service_date" db:"service_date"` // YYYY-MM-DD
+	StartTime   string       `json:"start_time" db:"start_time"`     // HH:MM
+	EndTime     string       `json:"end_time" db:"end_time"`         // HH:MM
+	Duration    int          `json:"duration" db:"duration"`         // 分钟
+	Address     string       `json:"address" db:"address"`
+	Location    *Location    `json:"location,omitempty" db:"location"`
+	Status      string       `json:"status" db:"status"` // pending/assigned/in_progress/completed/cancelled
+	EmployeeID  *uuid.UUID   `json:"employee_id,omitempty" db:"employee_id"`
+	Skills      []string     `json:"skills,omitempty" db:"skills"`             // 必须全部具备的技能
+	SkillGroups []SkillGroup `json:"skill_groups,omitempty" db:"skill_groups"` // 技能组（组内任选，组间都需满足）
+	Priority    int          `json:"priority" db:"priority"`
+	Notes       string       `json:"notes,omitempty" db:"notes"`
+	Amount      float64      `json:"amount" db:"amount"`
+	AssignedAt  *time.Time   `json:"assigned_at,omitempty" db:"assigned_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty" db:"completed_at"`
+
+	// 团队订单（如深度保洁需 2-3 人同时上门）
+	RequiredHeadcount int         `json:"required_headcount,omitempty" db:"required_headcount"` // 需要人数，0/1 表示单人订单
+	Roles             []OrderRole `json:"roles,omitempty" db:"roles"`                           // 按角色的人员构成，人数合计不足 required_headcount 时其余为不限角色
+	EmployeeIDs       []uuid.UUID `json:"employee_ids,omitempty" db:"employee_ids"`             // 团队订单的全部成员（employee_id 为带队人）
+
+	// 完成凭证（客户签名、照片、完工说明），组织的完工策略要求时必须提交后才能完成订单
+	CompletionProof *CompletionProof `json:"completion_proof,omitempty" db:"completion_proof"`
+}
+
+// 订单状态
+const (
+	OrderPending    = "pending"
+	OrderAssigned   = "assigned"
+	OrderInProgress = "in_progress"
+	OrderCompleted  = "completed"
+	OrderCancelled  = "cancelled"
+)
+
+// orderTransitions 订单允许的状态流转
+var orderTransitions = map[string][]string{
+	OrderPending:    {OrderAssigned, OrderCancelled},
+	OrderAssigned:   {OrderPending, OrderInProgress, OrderCompleted, OrderCancelled},
+	OrderInProgress: {OrderCompleted, OrderCancelled},
+}
+
+// IsValidOrderStatus 检查订单状态是否合法
+func IsValidOrderStatus(status string) bool {
+	switch status {
+	case OrderPending, OrderAssigned, OrderInProgress, OrderCompleted, OrderCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransitionOrder 检查订单能否从 from 状态流转到 to 状态，已完成和已取消的订单不能再变更
+func CanTransitionOrder(from, to string) bool {
+	for _, s := range orderTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CompletionProof 订单完成凭证
+// 签名和照片为文件存储中的引用（如对象存储 key 或 URL），不保存文件内容
+type CompletionProof struct {
+	SignatureRef string    `json:"signature_ref,omitempty"` // 客户签名图片
+	SignerName   string    `json:"signer_name,omitempty"`   // 签名人
+	Photos       []string  `json:"photos,omitempty"`        // 完工照片
+	Notes        string    `json:"notes,omitempty"`         // 完工说明
+	CapturedBy   string    `json:"captured_by,omitempty"`
+	CapturedAt   time.Time `json:"captured_at"`
+}
+
+// CompletionPolicy 组织的订单完工策略，订单完成前须提交满足要求的完成凭证
+type CompletionPolicy struct {
+	RequireSignature bool `json:"require_signature"`       // 要求客户签名
+	MinPhotos        int  `json:"min_photos,omitempty"`    // 最少完工照片数
+	RequireNotes     bool `json:"require_notes,omitempty"` // 要求完工说明
+}
+
+// Validate 检查完工策略是否合法
+func (p *CompletionPolicy) Validate() error {
+	if p.MinPhotos < 0 {
+		return fmt.Errorf("最少照片数不能为负数")
+	}
+	return nil
+}
+
+// Check 检查完成凭证是否满足策略，返回缺少的内容
+func (p *CompletionPolicy) Check(proof *CompletionProof) []string {
+	var missing []string
+	if p.RequireSignature && (proof == nil || proof.SignatureRef == "") {
+		missing = append(missing, "客户签名")
+	}
+	if p.MinPhotos > 0 && (proof == nil || len(proof.Photos) < p.MinPhotos) {
+		missing = append(missing, fmt.Sprintf("完工照片（至少 %d 张）", p.MinPhotos))
+	}
+	if p.RequireNotes && (proof == nil || proof.Notes == "") {
+		missing = append(missing, "完工说明")
+	}
+	return missing
+}
+
+// DispatchConstraintSettings 单个派单约束的配置，未给出的字段使用约束默认值
+type DispatchConstraintSettings struct {
+	Enabled *bool              `json:"enabled,omitempty"` // false 表示不启用该约束
+	Weight  float64            `json:"weight,omitempty"`  // 权重，按与默认权重的比例缩放该约束的惩罚和奖励
+	Params  map[string]float64 `json:"params,omitempty"`  // 约束参数（见派单约束库）
+}
+
+// DispatchConstraintConfig 派单约束配置（约束名称 → 配置）
+type DispatchConstraintConfig map[string]DispatchConstraintSettings
+
+// Merge 合并约束配置：override 中给出的约束覆盖同名约束的启用状态和权重，参数按键合并
+func (c DispatchConstraintConfig) Merge(override DispatchConstraintConfig) DispatchConstraintConfig {
+	merged := make(DispatchConstraintConfig, len(c)+len(override))
+	for name, s := range c {
+		merged[name] = s
+	}
+	for name, o := range override {
+		s := merged[name]
+		if o.Enabled != nil {
+			s.Enabled = o.Enabled
+		}
+		if o.Weight != 0 {
+			s.Weight = o.Weight
+		}
+		if len(o.Params) > 0 {
+			params := make(map[string]float64, len(s.Params)+len(o.Params))
+			for k, v := range s.Params {
+				params[k] = v
+			}
+			for k, v := range o.Params {
+				params[k] = v
+			}
+			s.Params = params
+		}
+		merged[name] = s
+	}
+	return merged
+}
+
+// OrderRole 团队订单中的角色需求
+type OrderRole struct {
+	Role   string   `json:"role"`             // 角色名称，如 leader/cleaner
+	Count  int      `json:"count"`            // 人数
+	Skills []string `json:"skills,omitempty"` // 该角色额外需要的技能
+}
+
+// KeepApartRule 不可同组规则：列出的员工两两不得出现在同一团队订单中
+type KeepApartRule struct {
+	EmployeeIDs []uuid.UUID `json:"employee_ids"`
+	Reason      string      `json:"reason,omitempty"`
+}
+
+// Conflicts 检查两名员工是否受该规则约束
+func (r KeepApartRule) Conflicts(a, b uuid.UUID) bool {
+	hasA, hasB := false, false
+	for _, id := range r.EmployeeIDs {
+		hasA = hasA || id == a
+		hasB = hasB || id == b
+	}
+	return a != b && hasA && hasB
 }
 
 // ServiceRecord 服务记录
@@ -89,6 +256,7 @@ type ServiceRecord struct {
 // CarePlan 护理计划（长护险）
 type CarePlan struct {
 	BaseModel
+	OrgID          uuid.UUID   `json:"org_id,omitempty" db:"org_id"`
 	CustomerID     uuid.UUID   `json:"customer_id" db:"customer_id"`
 	PlanNo         string      `json:"plan_no" db:"plan_no"`
 	Level          int         `json:"level" db:"level"` // 护理等级 1-6
@@ -101,6 +269,11 @@ type CarePlan struct {
 	BackupCarerIDs []uuid.UUID `json:"backup_carer_ids,omitempty" db:"backup_carer_ids"`
 	Status         string      `json:"status" db:"status"` // active/suspended/expired
 	Notes          string      `json:"notes,omitempty" db:"notes"`
+
+	// 生成服务订单使用的上门信息（为空时取客户地址、默认 09:00 开始）
+	Address        string    `json:"address,omitempty" db:"address"`
+	Location       *Location `json:"location,omitempty" db:"location"`
+	VisitStartTime string    `json:"visit_start_time,omitempty" db:"visit_start_time"` // HH:MM
 }
 
 // CareItem 护理服务项目
@@ -144,11 +317,73 @@ type CustomerEmployeeHistory struct {
 	IsPrimary     bool      `json:"is_primary" db:"is_primary"`
 }
 
+// 员工实时状态
+const (
+	LiveStatusOnDuty  = "on_duty"  // 在岗
+	LiveStatusOnBreak = "on_break" // 休息中
+	LiveStatusOffDuty = "off_duty" // 下班
+	LiveStatusEnRoute = "en_route" // 途中
+)
+
+// EmployeeLiveStatus 员工实时状态（由移动端上报）
+type EmployeeLiveStatus struct {
+	EmployeeID  uuid.UUID  `json:"employee_id"`
+	Status      string     `json:"status"` // on_duty/on_break/off_duty/en_route
+	Location    *Location  `json:"location,omitempty"`
+	Destination *Location  `json:"destination,omitempty"` // 途中时的目的地
+	ETA         *time.Time `json:"eta,omitempty"`         // 途中时预计到达目的地时间
+	ReportedAt  time.Time  `json:"reported_at"`
+}
+
+// IsValidLiveStatus 检查实时状态值是否合法
+func IsValidLiveStatus(status string) bool {
+	switch status {
+	case LiveStatusOnDuty, LiveStatusOnBreak, LiveStatusOffDuty, LiveStatusEnRoute:
+		return true
+	}
+	return false
+}
+
 // IsAssigned 检查订单是否已分配
 func (o *ServiceOrder) IsAssigned() bool {
 	return o.EmployeeID != nil && o.Status != "pending"
 }
 
+// Headcount 返回订单需要的人数（至少 1 人，且不少于各角色人数之和）
+func (o *ServiceOrder) Headcount() int {
+	n := 0
+	for _, r := range o.Roles {
+		if r.Count > 0 {
+			n += r.Count
+		}
+	}
+	if o.RequiredHeadcount > n {
+		n = o.RequiredHeadcount
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// IsTeamOrder 检查是否为需要多人的团队订单
+func (o *ServiceOrder) IsTeamOrder() bool {
+	return o.Headcount() > 1
+}
+
+// HasEmployee 检查员工是否被分配到该订单（带队人或团队成员）
+func (o *ServiceOrder) HasEmployee(id uuid.UUID) bool {
+	if o.EmployeeID != nil && *o.EmployeeID == id {
+		return true
+	}
+	for _, eid := range o.EmployeeIDs {
+		if eid == id {
+			return true
+		}
+	}
+	return false
+}
+
 // NeedsDispatch 检查订单是否需要派单
 func (o *ServiceOrder) NeedsDispatch() bool {
 	return o.Status == "pending" && o.EmployeeID == nil
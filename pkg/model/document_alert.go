@@ -0,0 +1,71 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// DocumentTypeContract 劳动合同到期（员工合同 valid_to）的证件类型
+// 其他证件类型为证书材料的证书名称，如"健康证"、"签证"、"工作许可"
+const DocumentTypeContract = "contract"
+
+// defaultDocumentLeadDays 未配置时证件到期提前提醒的天数
+const defaultDocumentLeadDays = 30
+
+// 证件到期提醒级别
+const (
+	DocumentAlertExpiring = "expiring" // 即将到期
+	DocumentAlertExpired  = "expired"  // 已过期
+)
+
+// DocumentAlertPolicy 组织的证件到期提醒策略
+// 证件在到期前 LeadDays[类型]（未配置的类型为 DefaultLeadDays，默认 30）天内开始提醒，留出办理续期的时间
+type DocumentAlertPolicy struct {
+	DefaultLeadDays int            `json:"default_lead_days,omitempty"`
+	LeadDays        map[string]int `json:"lead_days,omitempty"` // 证件类型 -> 提前天数
+}
+
+// Validate 检查提醒策略是否合法
+func (p *DocumentAlertPolicy) Validate() error {
+	if p.DefaultLeadDays < 0 {
+		return fmt.Errorf("提前提醒天数不能为负数")
+	}
+	for docType, days := range p.LeadDays {
+		if docType == "" || days < 0 {
+			return fmt.Errorf("证件类型不能为空，提前提醒天数不能为负数")
+		}
+	}
+	return nil
+}
+
+// Lead 返回证件类型的提前提醒天数，p 为 nil 时使用默认值
+func (p *DocumentAlertPolicy) Lead(docType string) int {
+	if p == nil {
+		return defaultDocumentLeadDays
+	}
+	if days, ok := p.LeadDays[docType]; ok {
+		return days
+	}
+	if p.DefaultLeadDays > 0 {
+		return p.DefaultLeadDays
+	}
+	return defaultDocumentLeadDays
+}
+
+// DocumentAlert 员工证件到期提醒
+// 证件进入提前提醒期、已过期，或员工被排在证件到期之后上班时生成
+type DocumentAlert struct {
+	EmployeeID   uuid.UUID  `json:"employee_id"`
+	EmployeeName string     `json:"employee_name"`
+	DocumentType string     `json:"document_type"`         // 证书名称或 contract
+	DocumentID   *uuid.UUID `json:"document_id,omitempty"` // 证书材料ID，合同到期时为空
+	ExpiresAt    string     `json:"expires_at"`
+	DaysLeft     int        `json:"days_left"` // 距到期天数（到期当天为 0，已过期为负数）
+	LeadDays     int        `json:"lead_days"`
+	Level        string     `json:"level"`
+
+	// 到期之后仍有的排班（草稿和已发布排班，不含已取消的分配）
+	ScheduledBeyond    int    `json:"scheduled_beyond"`
+	FirstScheduledDate string `json:"first_scheduled_date,omitempty"`
+}
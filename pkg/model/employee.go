@@ -17,23 +17,53 @@ type Employee struct {
 	Email    string    `json:"email,omitempty" db:"email"`
 	Status   string    `json:"status" db:"status"` // active/inactive/leave
 	HireDate string    `json:"hire_date" db:"hire_date"`
+	// 出生日期（YYYY-MM-DD），用于未成年工保护等按年龄的合规规则
+	BirthDate string `json:"birth_date,omitempty" db:"-"`
 
 	// 排班相关
 	Position       string   `json:"position" db:"position"`
 	Skills         []string `json:"skills" db:"skills"`
 	Certifications []string `json:"certifications,omitempty" db:"certifications"`
-	HourlyRate     float64  `json:"hourly_rate" db:"hourly_rate"`
+	// 技能等级，需求要求技能等级时使用；只在 skills 中登记的技能按 1 级计
+	SkillLevels []Skill `json:"skill_levels,omitempty" db:"-"`
+	// 带有效期的证书，过期后不再满足资质要求；只在 certifications 中登记的证书视为长期有效
+	CertificationRecords []Certification `json:"certification_records,omitempty" db:"-"`
+	// 已核验的证书（证书材料审核通过），资质约束要求核验时使用
+	VerifiedCertifications []VerifiedCertification `json:"verified_certifications,omitempty" db:"-"`
+	HourlyRate             float64                 `json:"hourly_rate" db:"hourly_rate"`
+	StoreID                string                  `json:"store_id,omitempty" db:"store_id"` // 所属门店
+	// 可跨店支援的门店（多门店排班时，除所属门店外还可排到这些门店）
+	AllowedStores []string `json:"allowed_stores,omitempty" db:"-"`
 
 	// 工作偏好
 	Preferences *EmployeePreferences `json:"preferences,omitempty" db:"preferences"`
 
+	// 可用时间窗口（如工作日 09:00-14:00），为空表示全天可用
+	AvailabilityWindows []AvailabilityWindow `json:"availability_windows,omitempty" db:"availability_windows"`
+
+	// 按日期登记的可用性，优先于可用时间窗口
+	Availability []EmployeeAvailability `json:"availability,omitempty" db:"-"`
+
+	// 每周重复的不可用时段（如每周三 14:00-18:00 上课），Start/End 为空表示全天；
+	// 与可用时间窗口一样，当天有按日期登记的可用性时以登记为准
+	UnavailableWindows []AvailabilityWindow `json:"unavailable_windows,omitempty" db:"-"`
+
+	// 请假（按日期区间登记），区间内全天不可排班，优先于所有可用性设置
+	Leaves []EmployeeLeave `json:"leaves,omitempty" db:"-"`
+
 	// 每月已有班次数（前端传入，用于月度班次限制约束）
 	// key: 月份 (YYYY-MM 格式), value: 该月班次数
 	MonthlyShiftsCounts map[string]int `json:"monthly_shifts_counts,omitempty" db:"-"`
 
+	// 合同约束（用于月度汇总的目标工时和加班计算）
+	Contract *EmployeeContract `json:"contract,omitempty" db:"-"`
+
 	// 服务区域（派出服务使用）
 	ServiceArea  *ServiceArea `json:"service_area,omitempty" db:"service_area"`
 	HomeLocation *Location    `json:"home_location,omitempty" db:"home_location"`
+
+	// 外部人员（派遣、零工）标记，为空表示内部员工
+	External *ExternalLabor `json:"external,omitempty" db:"-"`
 }
 
 // EmployeePreferences 员工偏好
@@ -44,9 +74,19 @@ type EmployeePreferences struct {
 	AvoidDays         []time.Weekday    `json:"avoid_days,omitempty"`         // 避免工作日
 	MaxHoursPerWeek   int               `json:"max_hours_per_week,omitempty"` // 期望最大周工时
 	MinHoursPerWeek   int               `json:"min_hours_per_week,omitempty"` // 期望最小周工时
+	ShiftRankings     []ShiftRanking    `json:"shift_rankings,omitempty"`     // 班次志愿排名
+	FixedShiftCodes   []string          `json:"fixed_shift_codes,omitempty"`  // 固定班次（班次编码或班次类型），设置后只排这些班次
+	FixedDaysOff      []time.Weekday    `json:"fixed_days_off,omitempty"`     // 固定休息日，当天不排班
 	CustomPreferences map[string]string `json:"custom,omitempty"`             // 自定义偏好
 }
 
+// ShiftRanking 班次志愿排名（Rank 越小越偏好，1 为第一志愿）
+// 不愿上的班次请使用 AvoidShifts 表达
+type ShiftRanking struct {
+	Shift string `json:"shift"` // 班次编码或班次类型
+	Rank  int    `json:"rank"`
+}
+
 // ServiceArea 服务区域
 type ServiceArea struct {
 	Districts []string `json:"districts,omitempty"`  // 服务区/街道
@@ -61,6 +101,37 @@ type EmployeeAvailability struct {
 	Type       string      `json:"type" db:"type"` // available/unavailable/preferred
 	TimeRanges []TimeRange `json:"time_ranges,omitempty" db:"time_ranges"`
 	Reason     string      `json:"reason,omitempty" db:"reason"`
+
+	// 请假管控期内超出名额的请假需管理者审核，见 BlackoutPeriod
+	ReviewStatus string `json:"review_status,omitempty" db:"review_status"` // approved/pending_review/rejected，为空视为已批准
+	ReviewNote   string `json:"review_note,omitempty" db:"review_note"`
+
+	// 请假使用的调休小时数（仅全天请假），请假批准后从调休账户扣除
+	TimeBankHours float64 `json:"time_bank_hours,omitempty" db:"time_bank_hours"`
+}
+
+// AvailabilityWindow 每周重复的可用时间窗口
+type AvailabilityWindow struct {
+	Weekdays []time.Weekday `json:"weekdays,omitempty"` // 适用的星期，为空表示每天
+	Start    string         `json:"start"`              // HH:MM
+	End      string         `json:"end"`                // HH:MM，不晚于 Start 表示跨午夜
+}
+
+// EmployeeLeave 员工请假（日期区间，含首尾两天）
+type EmployeeLeave struct {
+	StartDate string `json:"start_date"`         // YYYY-MM-DD
+	EndDate   string `json:"end_date,omitempty"` // YYYY-MM-DD，为空表示仅 StartDate 当天
+	Type      string `json:"type,omitempty"`     // annual/sick/personal 等
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Covers 检查请假区间是否包含 date (YYYY-MM-DD)
+func (l EmployeeLeave) Covers(date string) bool {
+	end := l.EndDate
+	if end == "" {
+		end = l.StartDate
+	}
+	return l.StartDate != "" && date >= l.StartDate && date <= end
 }
 
 // EmployeeContract 员工合同约束
@@ -81,23 +152,317 @@ func (e *Employee) IsActive() bool {
 	return e.Status == "active"
 }
 
-// HasSkill 检查员工是否具备某技能
+// AgeOn 返回员工在某日的周岁年龄，未登记出生日期或日期无效时返回 -1
+func (e *Employee) AgeOn(date string) int {
+	birth, err := time.Parse("2006-01-02", e.BirthDate)
+	if err != nil {
+		return -1
+	}
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil || d.Before(birth) {
+		return -1
+	}
+	age := d.Year() - birth.Year()
+	if d.Month() < birth.Month() || (d.Month() == birth.Month() && d.Day() < birth.Day()) {
+		age--
+	}
+	return age
+}
+
+// IsMinorOn 检查员工在某日是否未满 18 周岁（未登记出生日期时视为成年）
+func (e *Employee) IsMinorOn(date string) bool {
+	age := e.AgeOn(date)
+	return age >= 0 && age < 18
+}
+
+// IsExternal 检查是否为外部人员
+func (e *Employee) IsExternal() bool {
+	return e.External != nil
+}
+
+// HasSkill 检查员工是否具备某技能（skills 或 skill_levels 中登记）
 func (e *Employee) HasSkill(skill string) bool {
-	for _, s := range e.Skills {
-		if s == skill {
-			return true
+	return e.SkillLevel(skill) > 0
+}
+
+// MeetsSkillGroup 检查员工是否满足技能组（具备组内至少 MinCount 项技能）
+func (e *Employee) MeetsSkillGroup(group SkillGroup) bool {
+	count := 0
+	for _, skill := range group.Skills {
+		if e.HasSkill(skill) {
+			count++
 		}
 	}
-	return false
+	return count >= group.Required()
 }
 
-// HasCertification 检查员工是否具备某证书
+// MissingSkills 返回员工未满足的技能要求
+// skills 为必须全部具备的技能（AND），groups 为技能组（组内 OR，组间 AND）
+func (e *Employee) MissingSkills(skills []string, groups []SkillGroup) []string {
+	var missing []string
+	for _, skill := range skills {
+		if !e.HasSkill(skill) {
+			missing = append(missing, skill)
+		}
+	}
+	for _, group := range groups {
+		if !e.MeetsSkillGroup(group) {
+			missing = append(missing, group.String())
+		}
+	}
+	return missing
+}
+
+// MeetsSkillRequirements 检查员工是否满足全部技能要求
+func (e *Employee) MeetsSkillRequirements(skills []string, groups []SkillGroup) bool {
+	for _, skill := range skills {
+		if !e.HasSkill(skill) {
+			return false
+		}
+	}
+	for _, group := range groups {
+		if !e.MeetsSkillGroup(group) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasCertification 检查员工是否登记了某证书（不考虑有效期）
 func (e *Employee) HasCertification(cert string) bool {
 	for _, c := range e.Certifications {
 		if c == cert {
 			return true
 		}
 	}
+	for _, c := range e.CertificationRecords {
+		if c.Name == cert {
+			return true
+		}
+	}
+	return false
+}
+
+// HasVerifiedCertification 检查员工的证书在指定日期是否已核验且在有效期内
+func (e *Employee) HasVerifiedCertification(cert, date string) bool {
+	for _, c := range e.VerifiedCertifications {
+		if c.Name == cert && (c.ExpiresAt == "" || date <= c.ExpiresAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAvailable 检查员工在 [start, end) 时段是否可用（date 为班次所属日期）
+// 当日登记了可用性时按登记判断：全天不可用、不可用时段有重叠均为不可用，
+// 可用时段需完整包含班次；否则班次须完整落在某个可用时间窗口内。被驳回的请假不计入
+func (e *Employee) IsAvailable(date string, start, end time.Time) bool {
+	if e.LeaveOn(date) != nil {
+		return false
+	}
+	for _, av := range e.Availability {
+		if av.Date != date || av.ReviewStatus == LeaveRejected {
+			continue
+		}
+		if av.Type == "unavailable" {
+			if len(av.TimeRanges) == 0 {
+				return false
+			}
+			shift := TimeRange{Start: start, End: end}
+			for _, tr := range av.TimeRanges {
+				if tr.Overlaps(shift) {
+					return false
+				}
+			}
+			return true
+		}
+		if len(av.TimeRanges) == 0 {
+			return true
+		}
+		for _, tr := range av.TimeRanges {
+			if !start.Before(tr.Start) && !end.After(tr.End) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(e.AvailabilityWindows) == 0 && len(e.UnavailableWindows) == 0 {
+		return true
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return true
+	}
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, start.Location())
+	// 跨午夜的班次可能与次日的不可用时段重叠
+	for _, d := range []time.Time{day.AddDate(0, 0, -1), day, day.AddDate(0, 0, 1)} {
+		for _, w := range e.UnavailableWindows {
+			if w.Overlaps(d, start, end) {
+				return false
+			}
+		}
+	}
+	if len(e.AvailabilityWindows) == 0 {
+		return true
+	}
+	// 前一天的跨午夜窗口也可能覆盖凌晨班次
+	for _, d := range []time.Time{day, day.AddDate(0, 0, -1)} {
+		for _, w := range e.AvailabilityWindows {
+			if w.Contains(d, start, end) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LeaveOn 返回员工在 date 当天的请假，未请假返回 nil
+func (e *Employee) LeaveOn(date string) *EmployeeLeave {
+	for i := range e.Leaves {
+		if e.Leaves[i].Covers(date) {
+			return &e.Leaves[i]
+		}
+	}
+	return nil
+}
+
+// Contains 检查窗口在 day 当天的时段是否完整包含 [start, end)
+func (w AvailabilityWindow) Contains(day time.Time, start, end time.Time) bool {
+	winStart, winEnd, ok := w.span(day)
+	if !ok {
+		return false
+	}
+	return !start.Before(winStart) && !end.After(winEnd)
+}
+
+// Overlaps 检查窗口在 day 当天的时段是否与 [start, end) 有交集
+// Start 和 End 都为空时表示全天
+func (w AvailabilityWindow) Overlaps(day time.Time, start, end time.Time) bool {
+	if w.Start == "" && w.End == "" {
+		if !w.appliesTo(day) {
+			return false
+		}
+		return start.Before(day.AddDate(0, 0, 1)) && end.After(day)
+	}
+	winStart, winEnd, ok := w.span(day)
+	if !ok {
+		return false
+	}
+	return start.Before(winEnd) && end.After(winStart)
+}
+
+// appliesTo 检查窗口是否适用于 day 所在的星期
+func (w AvailabilityWindow) appliesTo(day time.Time) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, wd := range w.Weekdays {
+		if wd == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// span 返回窗口在 day 当天的起止时间，不适用当天或时间格式无效时 ok 为 false
+func (w AvailabilityWindow) span(day time.Time) (time.Time, time.Time, bool) {
+	if !w.appliesTo(day) {
+		return time.Time{}, time.Time{}, false
+	}
+	ws, err1 := time.Parse("15:04", w.Start)
+	we, err2 := time.Parse("15:04", w.End)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	winStart := day.Add(time.Duration(ws.Hour())*time.Hour + time.Duration(ws.Minute())*time.Minute)
+	winEnd := day.Add(time.Duration(we.Hour())*time.Hour + time.Duration(we.Minute())*time.Minute)
+	if !winEnd.After(winStart) {
+		winEnd = winEnd.Add(24 * time.Hour)
+	}
+	return winStart, winEnd, true
+}
+
+// ShiftRank 返回班次在志愿排名中的名次，未排名返回 0
+// shift 可以匹配班次编码或班次类型，取最靠前的名次
+func (p *EmployeePreferences) ShiftRank(code, shiftType string) int {
+	if p == nil {
+		return 0
+	}
+	best := 0
+	for _, r := range p.ShiftRankings {
+		if r.Rank <= 0 || (r.Shift != code && r.Shift != shiftType) {
+			continue
+		}
+		if best == 0 || r.Rank < best {
+			best = r.Rank
+		}
+	}
+	return best
+}
+
+// ShiftRankScore 返回班次志愿得分 (0-1)
+// 第一志愿得 1，名次越靠后得分按比例递减，未排名得 0
+func (p *EmployeePreferences) ShiftRankScore(code, shiftType string) float64 {
+	rank := p.ShiftRank(code, shiftType)
+	if rank == 0 {
+		return 0
+	}
+	maxRank := 0
+	for _, r := range p.ShiftRankings {
+		if r.Rank > maxRank {
+			maxRank = r.Rank
+		}
+	}
+	return float64(maxRank-rank+1) / float64(maxRank)
+}
+
+// AllowsShift 检查固定班次是否允许排该班次
+// 未设置固定班次时不限制；否则班次编码或班次类型须在固定班次中
+func (p *EmployeePreferences) AllowsShift(code, shiftType string) bool {
+	if p == nil || len(p.FixedShiftCodes) == 0 {
+		return true
+	}
+	for _, s := range p.FixedShiftCodes {
+		if s == code || (shiftType != "" && s == shiftType) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFixedDayOff 检查某天是否为固定休息日
+func (p *EmployeePreferences) IsFixedDayOff(day time.Weekday) bool {
+	if p == nil {
+		return false
+	}
+	for _, d := range p.FixedDaysOff {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// HasShiftRankings 检查是否设置了班次志愿排名
+func (p *EmployeePreferences) HasShiftRankings() bool {
+	return p != nil && len(p.ShiftRankings) > 0
+}
+
+// CanWorkAt 检查员工是否可以在某门店上班
+// 门店为空、员工未设置所属门店和可支援门店时不限制；否则须为所属门店或可支援门店之一
+func (e *Employee) CanWorkAt(storeID string) bool {
+	if storeID == "" || (e.StoreID == "" && len(e.AllowedStores) == 0) {
+		return true
+	}
+	if e.StoreID == storeID {
+		return true
+	}
+	for _, s := range e.AllowedStores {
+		if s == storeID {
+			return true
+		}
+	}
 	return false
 }
 
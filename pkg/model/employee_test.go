@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 )
 
 func TestEmployee_IsActive(t *testing.T) {
@@ -26,6 +27,66 @@ func TestEmployee_IsActive(t *testing.T) {
 	}
 }
 
+func TestEmployee_AgeOn(t *testing.T) {
+	tests := []struct {
+		name      string
+		birthDate string
+		date      string
+		age       int
+		minor     bool
+	}{
+		{"生日前一天", "2008-06-01", "2026-05-31", 17, true},
+		{"18 周岁生日当天", "2008-06-01", "2026-06-01", 18, false},
+		{"未登记出生日期", "", "2026-06-01", -1, false},
+		{"出生日期无效", "2008/06/01", "2026-06-01", -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Employee{BirthDate: tt.birthDate}
+			if age := e.AgeOn(tt.date); age != tt.age {
+				t.Errorf("AgeOn() = %d, expected %d", age, tt.age)
+			}
+			if minor := e.IsMinorOn(tt.date); minor != tt.minor {
+				t.Errorf("IsMinorOn() = %v, expected %v", minor, tt.minor)
+			}
+		})
+	}
+}
+
+func TestEmployee_MeetsSkillRequirements(t *testing.T) {
+	e := &Employee{
+		Skills: []string{"grill", "cashier"},
+	}
+
+	tests := []struct {
+		name     string
+		skills   []string
+		groups   []SkillGroup
+		expected bool
+	}{
+		{"无要求", nil, nil, true},
+		{"AND 满足", []string{"grill", "cashier"}, nil, true},
+		{"AND 缺少", []string{"cook", "cashier"}, nil, false},
+		{"OR 满足", nil, []SkillGroup{{Skills: []string{"cook", "grill"}}, {Skills: []string{"cashier"}}}, true},
+		{"OR 某组不满足", nil, []SkillGroup{{Skills: []string{"cook", "grill"}}, {Skills: []string{"bake"}}}, false},
+		{"最少数量不足", nil, []SkillGroup{{Skills: []string{"cook", "grill", "bake"}, MinCount: 2}}, false},
+		{"最少数量满足", nil, []SkillGroup{{Skills: []string{"cook", "grill", "cashier"}, MinCount: 2}}, true},
+		{"AND 与 OR 组合", []string{"cashier"}, []SkillGroup{{Skills: []string{"cook", "grill"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := e.MeetsSkillRequirements(tt.skills, tt.groups); result != tt.expected {
+				t.Errorf("MeetsSkillRequirements() = %v, expected %v", result, tt.expected)
+			}
+			if missing := e.MissingSkills(tt.skills, tt.groups); (len(missing) == 0) != tt.expected {
+				t.Errorf("MissingSkills() = %v, expected satisfied=%v", missing, tt.expected)
+			}
+		})
+	}
+}
+
 func TestEmployee_HasSkill(t *testing.T) {
 	e := &Employee{
 		Skills: []string{"cooking", "service", "cleaning"},
@@ -101,3 +162,143 @@ func TestEmployee_CanServeLocation(t *testing.T) {
 		t.Error("远距离位置不应该可服务")
 	}
 }
+
+func TestEmployeePreferences_ShiftRankScore(t *testing.T) {
+	prefs := &EmployeePreferences{
+		ShiftRankings: []ShiftRanking{
+			{Shift: "morning", Rank: 1},
+			{Shift: "afternoon", Rank: 2},
+			{Shift: "E", Rank: 4},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		code      string
+		shiftType string
+		wantRank  int
+		wantScore float64
+	}{
+		{"第一志愿", "M", "morning", 1, 1.0},
+		{"第二志愿", "A", "afternoon", 2, 0.75},
+		{"按编码匹配", "E", "evening", 4, 0.25},
+		{"未排名", "N", "night", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rank := prefs.ShiftRank(tt.code, tt.shiftType); rank != tt.wantRank {
+				t.Errorf("ShiftRank() = %d, expected %d", rank, tt.wantRank)
+			}
+			if score := prefs.ShiftRankScore(tt.code, tt.shiftType); score != tt.wantScore {
+				t.Errorf("ShiftRankScore() = %v, expected %v", score, tt.wantScore)
+			}
+		})
+	}
+
+	var empty *EmployeePreferences
+	if empty.HasShiftRankings() || empty.ShiftRank("M", "morning") != 0 {
+		t.Error("nil 偏好不应有志愿排名")
+	}
+}
+
+func TestEmployee_IsAvailable(t *testing.T) {
+	at := func(s string) time.Time {
+		v, _ := time.Parse("2006-01-02 15:04", s)
+		return v
+	}
+	// 2024-01-15 为周一，2024-01-20 为周六
+	e := &Employee{
+		AvailabilityWindows: []AvailabilityWindow{
+			{Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, Start: "09:00", End: "14:00"},
+			{Weekdays: []time.Weekday{time.Saturday}, Start: "22:00", End: "06:00"},
+		},
+		Availability: []EmployeeAvailability{
+			{Date: "2024-01-16", Type: "unavailable"},
+			{Date: "2024-01-17", Type: "available", TimeRanges: []TimeRange{{Start: at("2024-01-17 15:00"), End: at("2024-01-17 20:00")}}},
+			{Date: "2024-01-18", Type: "unavailable", ReviewStatus: LeaveRejected},
+			{Date: "2024-01-19", Type: "unavailable", ReviewStatus: LeavePendingReview},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		date       string
+		start, end string
+		want       bool
+	}{
+		{"工作日窗口内", "2024-01-15", "2024-01-15 09:00", "2024-01-15 13:00", true},
+		{"超出窗口结束时间", "2024-01-15", "2024-01-15 10:00", "2024-01-15 15:00", false},
+		{"周末无窗口", "2024-01-21", "2024-01-21 09:00", "2024-01-21 13:00", false},
+		{"跨午夜窗口内的凌晨班次", "2024-01-21", "2024-01-21 01:00", "2024-01-21 05:00", true},
+		{"当日登记全天不可用", "2024-01-16", "2024-01-16 09:00", "2024-01-16 13:00", false},
+		{"当日登记可用时段优先于窗口", "2024-01-17", "2024-01-17 16:00", "2024-01-17 19:00", true},
+		{"当日登记可用时段不包含班次", "2024-01-17", "2024-01-17 09:00", "2024-01-17 13:00", false},
+		{"请假被驳回按窗口判断", "2024-01-18", "2024-01-18 09:00", "2024-01-18 13:00", true},
+		{"待审核请假仍视为不可用", "2024-01-19", "2024-01-19 09:00", "2024-01-19 13:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.IsAvailable(tt.date, at(tt.start), at(tt.end)); got != tt.want {
+				t.Errorf("IsAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !(&Employee{}).IsAvailable("2024-01-15", at("2024-01-15 00:00"), at("2024-01-15 23:00")) {
+		t.Error("未设置窗口的员工应全天可用")
+	}
+}
+
+func TestEmployee_LeavesAndUnavailableWindows(t *testing.T) {
+	at := func(s string) time.Time {
+		v, _ := time.Parse("2006-01-02 15:04", s)
+		return v
+	}
+	// 2024-01-17 为周三
+	e := &Employee{
+		Leaves: []EmployeeLeave{
+			{StartDate: "2024-01-22", EndDate: "2024-01-24", Type: "annual"},
+			{StartDate: "2024-01-26"},
+		},
+		UnavailableWindows: []AvailabilityWindow{
+			{Weekdays: []time.Weekday{time.Wednesday}, Start: "14:00", End: "18:00"},
+			{Weekdays: []time.Weekday{time.Sunday}},
+		},
+		Availability: []EmployeeAvailability{
+			{Date: "2024-01-31", Type: "available"},
+			{Date: "2024-01-23", Type: "available"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		date       string
+		start, end string
+		want       bool
+	}{
+		{"每周不可用时段外", "2024-01-17", "2024-01-17 08:00", "2024-01-17 14:00", true},
+		{"与每周不可用时段重叠", "2024-01-17", "2024-01-17 12:00", "2024-01-17 15:00", false},
+		{"每周全天不可用", "2024-01-21", "2024-01-21 09:00", "2024-01-21 13:00", false},
+		{"周六夜班跨入周日", "2024-01-20", "2024-01-20 22:00", "2024-01-21 06:00", false},
+		{"当日登记可用优先于每周不可用", "2024-01-31", "2024-01-31 14:00", "2024-01-31 18:00", true},
+		{"请假区间首日", "2024-01-22", "2024-01-22 09:00", "2024-01-22 13:00", false},
+		{"请假优先于当日登记可用", "2024-01-23", "2024-01-23 09:00", "2024-01-23 13:00", false},
+		{"请假区间之后", "2024-01-25", "2024-01-25 09:00", "2024-01-25 13:00", true},
+		{"单日请假", "2024-01-26", "2024-01-26 09:00", "2024-01-26 13:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.IsAvailable(tt.date, at(tt.start), at(tt.end)); got != tt.want {
+				t.Errorf("IsAvailable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if leave := e.LeaveOn("2024-01-24"); leave == nil || leave.Type != "annual" {
+		t.Errorf("LeaveOn() = %+v", leave)
+	}
+	if e.LeaveOn("2024-01-27") != nil {
+		t.Error("请假区间外不应返回请假")
+	}
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalLabor 外部人员（派遣、零工）标记，附加在参与排班的 Employee 上
+type ExternalLabor struct {
+	Agency   string  `json:"agency,omitempty"`    // 所属派遣机构/平台
+	MaxHours float64 `json:"max_hours,omitempty"` // 本期最多可分配工时，0 表示不限
+}
+
+// ExternalWorker 外部人员
+// 只在内部员工都无法满足需求时才会被排班，按 HourlyRate 计算费用
+type ExternalWorker struct {
+	ID                  uuid.UUID              `json:"id"`
+	Name                string                 `json:"name"`
+	Agency              string                 `json:"agency,omitempty"`
+	HourlyRate          float64                `json:"hourly_rate"`
+	Position            string                 `json:"position,omitempty"`
+	Skills              []string               `json:"skills,omitempty"`
+	AvailabilityWindows []AvailabilityWindow   `json:"availability_windows,omitempty"` // 为空表示全天可用
+	Availability        []EmployeeAvailability `json:"availability,omitempty"`         // 按日期登记的可用性
+	MaxHours            float64                `json:"max_hours,omitempty"`            // 每期最多可分配工时，0 表示不限
+}
+
+// ExternalPool 组织的外部人员池
+type ExternalPool struct {
+	OrgID             uuid.UUID         `json:"org_id"`
+	Workers           []*ExternalWorker `json:"workers"`
+	MaxHoursPerPeriod float64           `json:"max_hours_per_period,omitempty"` // 每个排班周期外部人员总工时上限，0 表示不限
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+// Validate 校验外部人员配置
+func (w *ExternalWorker) Validate() error {
+	if w.ID == uuid.Nil {
+		return fmt.Errorf("外部人员ID不能为空")
+	}
+	if w.Name == "" {
+		return fmt.Errorf("外部人员 %s 的姓名不能为空", w.ID)
+	}
+	if w.HourlyRate < 0 || w.MaxHours < 0 {
+		return fmt.Errorf("外部人员 %s 的费率和工时上限不能为负数", w.Name)
+	}
+	return nil
+}
+
+// Validate 校验外部人员池
+func (p *ExternalPool) Validate() error {
+	if p.MaxHoursPerPeriod < 0 {
+		return fmt.Errorf("外部人员工时上限不能为负数")
+	}
+	seen := make(map[uuid.UUID]bool, len(p.Workers))
+	for _, w := range p.Workers {
+		if w == nil {
+			return fmt.Errorf("外部人员不能为空")
+		}
+		if err := w.Validate(); err != nil {
+			return err
+		}
+		if seen[w.ID] {
+			return fmt.Errorf("外部人员 %s 重复", w.ID)
+		}
+		seen[w.ID] = true
+	}
+	return nil
+}
+
+// Employee 转换为参与排班的员工（带外部人员标记）
+func (w *ExternalWorker) Employee(orgID uuid.UUID) *Employee {
+	return &Employee{
+		BaseModel:           BaseModel{ID: w.ID},
+		OrgID:               orgID,
+		Name:                w.Name,
+		Status:              "active",
+		Position:            w.Position,
+		Skills:              w.Skills,
+		HourlyRate:          w.HourlyRate,
+		AvailabilityWindows: w.AvailabilityWindows,
+		Availability:        w.Availability,
+		External:            &ExternalLabor{Agency: w.Agency, MaxHours: w.MaxHours},
+	}
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 异步排班生成作业状态
+const (
+	GenerateJobQueued    = "queued"
+	GenerateJobRunning   = "running"
+	GenerateJobCompleted = "completed"
+	GenerateJobFailed    = "failed"
+	GenerateJobCancelled = "cancelled"
+)
+
+// GenerateJob 异步排班生成作业
+// 大规模排班（数百名员工、整月）在后台求解，客户端轮询进度并在完成后获取结果；
+// 作业请求、进度与结果保存在内存存储中，服务重启后未完成的作业重新执行
+type GenerateJob struct {
+	ID         uuid.UUID       `json:"id"`
+	OrgID      uuid.UUID       `json:"org_id"`
+	Status     string          `json:"status"`
+	Phase      string          `json:"phase,omitempty"` // 求解阶段（如 greedy、annealing）
+	Progress   float64         `json:"progress"`        // 0-100
+	ScheduleID string          `json:"schedule_id,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	ErrorCode  string          `json:"error_code,omitempty"`
+	Attempts   int             `json:"attempts"`
+	CreatedBy  string          `json:"created_by,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Request    json.RawMessage `json:"request,omitempty"` // 原始生成请求
+	Result     json.RawMessage `json:"result,omitempty"`  // 生成响应（完成时）
+}
+
+// IsFinished 作业是否已结束
+func (j *GenerateJob) IsFinished() bool {
+	return j.Status == GenerateJobCompleted || j.Status == GenerateJobFailed || j.Status == GenerateJobCancelled
+}
+
+// Summary 返回不含请求和结果的副本（用于查询进度）
+func (j *GenerateJob) Summary() *GenerateJob {
+	c := *j
+	c.Request, c.Result = nil, nil
+	return &c
+}
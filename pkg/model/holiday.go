@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 节假日类型
+const (
+	HolidayStatutory = "statutory" // 法定节假日（加班工资不低于工资的 300%）
+	HolidayRest      = "rest"      // 放假调休的休息日（加班工资不低于工资的 200%）
+	HolidayWorkday   = "workday"   // 调休上班日，按工作日排班
+	HolidayCustom    = "custom"    // 组织自定义假日（如店庆、企业假日）
+)
+
+// Holiday 节假日
+// 内置的法定节假日没有 ID 和 OrgID；组织自定义的节假日与内置节假日同一天时覆盖内置节假日
+type Holiday struct {
+	ID          uuid.UUID `json:"id,omitempty"`
+	OrgID       uuid.UUID `json:"org_id,omitempty"`
+	Date        string    `json:"date"` // YYYY-MM-DD
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`                   // statutory/rest/workday/custom，默认 custom
+	PremiumRate float64   `json:"premium_rate,omitempty"` // 当天上班的加班倍率，默认按类型（法定 3、调休休息日 2、其他 1）
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+}
+
+// Validate 校验节假日
+func (h *Holiday) Validate() error {
+	if _, err := time.Parse("2006-01-02", h.Date); err != nil {
+		return fmt.Errorf("节假日日期格式应为 YYYY-MM-DD")
+	}
+	if h.Name == "" {
+		return fmt.Errorf("节假日名称不能为空")
+	}
+	switch h.Type {
+	case HolidayStatutory, HolidayRest, HolidayWorkday, HolidayCustom:
+	default:
+		return fmt.Errorf("无效的节假日类型: %s", h.Type)
+	}
+	if h.PremiumRate < 0 || h.PremiumRate > 5 {
+		return fmt.Errorf("加班倍率应在 0-5 之间")
+	}
+	return nil
+}
+
+// IsOff 是否为休息日（调休上班日不是）
+func (h Holiday) IsOff() bool {
+	return h.Type != HolidayWorkday
+}
+
+// Rate 返回当天上班的加班倍率
+func (h Holiday) Rate() float64 {
+	if h.PremiumRate > 0 {
+		return h.PremiumRate
+	}
+	switch h.Type {
+	case HolidayStatutory:
+		return 3
+	case HolidayRest:
+		return 2
+	}
+	return 1
+}
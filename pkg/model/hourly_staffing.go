@@ -0,0 +1,86 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// HourlyStaffing 每小时最低在岗人数要求（如 10:00-22:00 每小时至少 2 人）
+// 按整点小时计算，跨零点的时段按所在自然日的小时计入
+type HourlyStaffing struct {
+	Start    string         `json:"start"`              // HH:00
+	End      string         `json:"end"`                // HH:00，不晚于 Start 表示到次日
+	MinStaff int            `json:"min_staff"`          // 每小时最少在岗人数
+	Weekdays []time.Weekday `json:"weekdays,omitempty"` // 适用的星期，为空表示每天
+}
+
+// Validate 校验每小时最低在岗人数要求
+func (h HourlyStaffing) Validate() error {
+	start, err1 := time.Parse("15:04", h.Start)
+	end, err2 := time.Parse("15:04", h.End)
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("时段格式应为 HH:00: %s-%s", h.Start, h.End)
+	}
+	if start.Minute() != 0 || end.Minute() != 0 {
+		return fmt.Errorf("时段须为整点: %s-%s", h.Start, h.End)
+	}
+	if h.MinStaff <= 0 {
+		return fmt.Errorf("时段 %s-%s 的最少在岗人数须大于 0", h.Start, h.End)
+	}
+	return nil
+}
+
+// hours 返回时段覆盖的小时（0-23），起止相同表示全天
+func (h HourlyStaffing) hours() []int {
+	start, err1 := time.Parse("15:04", h.Start)
+	end, err2 := time.Parse("15:04", h.End)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	n := (end.Hour() - start.Hour() + 24) % 24
+	if n == 0 {
+		n = 24
+	}
+	hours := make([]int, n)
+	for i := range hours {
+		hours[i] = (start.Hour() + i) % 24
+	}
+	return hours
+}
+
+// HourlyMinimums 返回某日各小时（0-23）的最少在岗人数，多条要求覆盖同一小时时取最大值
+func HourlyMinimums(rules []HourlyStaffing, date string) map[int]int {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil
+	}
+	minimums := make(map[int]int)
+	for _, r := range rules {
+		if len(r.Weekdays) > 0 && !containsWeekday(r.Weekdays, day.Weekday()) {
+			continue
+		}
+		for _, hour := range r.hours() {
+			if r.MinStaff > minimums[hour] {
+				minimums[hour] = r.MinStaff
+			}
+		}
+	}
+	return minimums
+}
+
+// HourlyMinimumsBetween 返回 start 至 end 每天各小时的最少在岗人数（日期 -> 小时 -> 人数），没有要求的日期不返回
+func HourlyMinimumsBetween(rules []HourlyStaffing, start, end string) map[string]map[int]int {
+	from, err1 := time.Parse("2006-01-02", start)
+	to, err2 := time.Parse("2006-01-02", end)
+	if err1 != nil || err2 != nil || len(rules) == 0 {
+		return nil
+	}
+	result := make(map[string]map[int]int)
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		if minimums := HourlyMinimums(rules, date); len(minimums) > 0 {
+			result[date] = minimums
+		}
+	}
+	return result
+}
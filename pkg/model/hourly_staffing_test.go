@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHourlyMinimums(t *testing.T) {
+	rules := []HourlyStaffing{
+		{Start: "10:00", End: "22:00", MinStaff: 2},
+		{Start: "11:00", End: "13:00", MinStaff: 4, Weekdays: []time.Weekday{time.Saturday}},
+		{Start: "22:00", End: "02:00", MinStaff: 1},
+	}
+
+	// 2026-03-07 为周六
+	saturday := HourlyMinimums(rules, "2026-03-07")
+	if saturday[10] != 2 || saturday[11] != 4 || saturday[13] != 2 || saturday[23] != 1 || saturday[1] != 1 || saturday[2] != 0 {
+		t.Errorf("HourlyMinimums(周六) = %v", saturday)
+	}
+	if friday := HourlyMinimums(rules, "2026-03-06"); friday[11] != 2 {
+		t.Errorf("周五不适用周六的要求: %v", friday)
+	}
+	if all := HourlyMinimums([]HourlyStaffing{{Start: "08:00", End: "08:00", MinStaff: 1}}, "2026-03-06"); len(all) != 24 {
+		t.Errorf("起止相同表示全天: %v", all)
+	}
+	if got := HourlyMinimumsBetween(rules[1:2], "2026-03-01", "2026-03-14"); len(got) != 2 {
+		t.Errorf("HourlyMinimumsBetween() 应只返回两个周六: %v", got)
+	}
+
+	for _, invalid := range []HourlyStaffing{
+		{Start: "10:30", End: "22:00", MinStaff: 2},
+		{Start: "10:00", End: "25:00", MinStaff: 2},
+		{Start: "10:00", End: "22:00"},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate(%+v) 应失败", invalid)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HR 系统同步事件类型
+const (
+	HREventHire           = "hire"
+	HREventUpdate         = "update"
+	HREventPositionChange = "position_change"
+	HREventTerminate      = "terminate"
+)
+
+// HRMapping 外部 HR 系统字段映射配置
+type HRMapping struct {
+	Source      string            `json:"source"`                 // 来源系统标识
+	OrgID       uuid.UUID         `json:"org_id"`                 // 同步到的组织
+	IDField     string            `json:"id_field"`               // 外部员工编号字段，对应员工 code
+	Fields      map[string]string `json:"fields"`                 // 员工字段 -> 外部字段，支持 name/position/status/phone/email/hire_date/skills
+	StatusMap   map[string]string `json:"status_map,omitempty"`   // 外部状态 -> active/inactive/leave
+	PositionMap map[string]string `json:"position_map,omitempty"` // 外部岗位 -> 本系统岗位，非空时未映射的岗位视为冲突
+	RateLimit   int               `json:"rate_limit,omitempty"`   // 每分钟最多接收的事件数，0 使用默认值
+	Secret      string            `json:"secret,omitempty"`       // Webhook 签名密钥（HMAC-SHA256），为空不校验
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// HREvent 外部 HR 系统推送的员工变更事件
+type HREvent struct {
+	Source     string                 `json:"source"`
+	EventID    string                 `json:"event_id"`
+	Type       string                 `json:"type"` // hire/update/position_change/terminate
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// HRSyncConflict HR 同步冲突（需人工处理）
+type HRSyncConflict struct {
+	ID         uuid.UUID  `json:"id"`
+	Source     string     `json:"source"`
+	EventID    string     `json:"event_id"`
+	ExternalID string     `json:"external_id,omitempty"`
+	EmployeeID *uuid.UUID `json:"employee_id,omitempty"`
+	Reason     string     `json:"reason"`
+	Detail     string     `json:"detail"`
+	Event      HREvent    `json:"event"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// RepairSuggestion 员工停用后受影响分配的修复建议
+type RepairSuggestion struct {
+	ID           uuid.UUID         `json:"id"`
+	OrgID        uuid.UUID         `json:"org_id"`
+	EmployeeID   uuid.UUID         `json:"employee_id"`
+	ScheduleID   uuid.UUID         `json:"schedule_id"`
+	AssignmentID uuid.UUID         `json:"assignment_id"`
+	ShiftID      uuid.UUID         `json:"shift_id"`
+	Date         string            `json:"date"`
+	Reason       string            `json:"reason"`
+	Cancelled    bool              `json:"cancelled"` // 草稿中的分配已自动取消
+	Candidates   []RepairCandidate `json:"candidates"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// RepairCandidate 修复建议中的替补员工
+type RepairCandidate struct {
+	EmployeeID uuid.UUID `json:"employee_id"`
+	Name       string    `json:"name"`
+	Position   string    `json:"position,omitempty"`
+	Reason     string    `json:"reason"`
+
+	FatigueScore float64 `json:"fatigue_score"` // 替补当天开始时的疲劳指数（0-100）
+	FatigueLevel string  `json:"fatigue_level"` // low/moderate/high
+}
@@ -0,0 +1,188 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpeningWindow 营业时间段
+type OpeningWindow struct {
+	Weekdays []time.Weekday `json:"weekdays,omitempty"` // 适用的星期，为空表示每天（特殊日期中忽略）
+	Open     string         `json:"open"`               // HH:MM
+	Close    string         `json:"close"`              // HH:MM，不晚于 Open 表示营业到次日
+}
+
+// SpecialHours 特殊日期营业时间（节假日、店庆等），优先于每周营业时间
+type SpecialHours struct {
+	Date    string          `json:"date"` // YYYY-MM-DD
+	Name    string          `json:"name,omitempty"`
+	Closed  bool            `json:"closed,omitempty"`  // 当天闭店
+	Windows []OpeningWindow `json:"windows,omitempty"` // 当天营业时间段
+}
+
+// StoreOpeningHours 门店营业时间
+// 排班须落在营业时间内；允许开店前 PrepMinutes 分钟到岗准备、闭店后 CleanupMinutes 分钟收尾
+type StoreOpeningHours struct {
+	OrgID          uuid.UUID       `json:"org_id"`
+	StoreID        string          `json:"store_id"`
+	Weekly         []OpeningWindow `json:"weekly"`
+	Holidays       []SpecialHours  `json:"holidays,omitempty"`
+	PrepMinutes    int             `json:"prep_minutes,omitempty"`
+	CleanupMinutes int             `json:"cleanup_minutes,omitempty"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// Validate 校验营业时间配置
+func (h *StoreOpeningHours) Validate() error {
+	if h.StoreID == "" {
+		return fmt.Errorf("门店ID不能为空")
+	}
+	if h.PrepMinutes < 0 || h.CleanupMinutes < 0 {
+		return fmt.Errorf("门店 %s 的准备/收尾时间不能为负数", h.StoreID)
+	}
+	for _, w := range h.Weekly {
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("门店 %s 的营业时间%v", h.StoreID, err)
+		}
+	}
+	seen := make(map[string]bool, len(h.Holidays))
+	for _, sp := range h.Holidays {
+		if _, err := time.Parse("2006-01-02", sp.Date); err != nil {
+			return fmt.Errorf("门店 %s 的特殊日期格式应为 YYYY-MM-DD: %s", h.StoreID, sp.Date)
+		}
+		if seen[sp.Date] {
+			return fmt.Errorf("门店 %s 的特殊日期 %s 重复", h.StoreID, sp.Date)
+		}
+		seen[sp.Date] = true
+		if !sp.Closed && len(sp.Windows) == 0 {
+			return fmt.Errorf("门店 %s 的特殊日期 %s 需设置营业时间段或 closed", h.StoreID, sp.Date)
+		}
+		for _, w := range sp.Windows {
+			if err := w.validate(); err != nil {
+				return fmt.Errorf("门店 %s 在 %s 的营业时间%v", h.StoreID, sp.Date, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate 校验时间段格式
+func (w OpeningWindow) validate() error {
+	_, err1 := time.Parse("15:04", w.Open)
+	_, err2 := time.Parse("15:04", w.Close)
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("格式应为 HH:MM: %s-%s", w.Open, w.Close)
+	}
+	return nil
+}
+
+// Special 返回某日的特殊营业时间
+func (h *StoreOpeningHours) Special(date string) (SpecialHours, bool) {
+	for _, sp := range h.Holidays {
+		if sp.Date == date {
+			return sp, true
+		}
+	}
+	return SpecialHours{}, false
+}
+
+// Windows 返回某日开始的营业时间段（已包含准备和收尾时间），闭店日返回空
+// loc 为时间段所在时区，应与班次时间一致
+func (h *StoreOpeningHours) Windows(date string, loc *time.Location) []TimeRange {
+	day, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return nil
+	}
+
+	windows := h.Weekly
+	if sp, ok := h.Special(date); ok {
+		if sp.Closed {
+			return nil
+		}
+		windows = sp.Windows
+	}
+
+	result := make([]TimeRange, 0, len(windows))
+	for _, w := range windows {
+		if len(w.Weekdays) > 0 && !containsWeekday(w.Weekdays, day.Weekday()) && !h.isSpecial(date) {
+			continue
+		}
+		open, err1 := time.Parse("15:04", w.Open)
+		closeAt, err2 := time.Parse("15:04", w.Close)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		start := day.Add(time.Duration(open.Hour())*time.Hour + time.Duration(open.Minute())*time.Minute)
+		end := day.Add(time.Duration(closeAt.Hour())*time.Hour + time.Duration(closeAt.Minute())*time.Minute)
+		if !end.After(start) {
+			end = end.Add(24 * time.Hour)
+		}
+		result = append(result, TimeRange{
+			Start: start.Add(-time.Duration(h.PrepMinutes) * time.Minute),
+			End:   end.Add(time.Duration(h.CleanupMinutes) * time.Minute),
+		})
+	}
+	return result
+}
+
+// Covers 检查 [start, end) 是否完整落在某个营业时间段内
+// date 为班次所属日期；前一天营业到次日凌晨的时间段同样计入
+func (h *StoreOpeningHours) Covers(date string, start, end time.Time) bool {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return false
+	}
+	prev := day.AddDate(0, 0, -1).Format("2006-01-02")
+	for _, d := range []string{prev, date} {
+		for _, w := range h.Windows(d, start.Location()) {
+			if !start.Before(w.Start) && !end.After(w.End) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CoversShift 检查班次在指定日期是否完整落在营业时间内（跨日班次结束时间顺延一天）
+func (h *StoreOpeningHours) CoversShift(date string, shift *Shift) bool {
+	start, err1 := time.Parse("2006-01-02 15:04", date+" "+shift.StartTime)
+	end, err2 := time.Parse("2006-01-02 15:04", date+" "+shift.EndTime)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	if !end.After(start) {
+		end = end.Add(24 * time.Hour)
+	}
+	return h.Covers(date, start, end)
+}
+
+// isSpecial 是否为特殊日期
+func (h *StoreOpeningHours) isSpecial(date string) bool {
+	_, ok := h.Special(date)
+	return ok
+}
+
+// ResolveOpeningHours 查找门店的营业时间，未配置时返回 nil
+func ResolveOpeningHours(list []StoreOpeningHours, storeID string) *StoreOpeningHours {
+	if storeID == "" {
+		return nil
+	}
+	for i := range list {
+		if list[i].StoreID == storeID {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+// containsWeekday 检查星期列表是否包含某天
+func containsWeekday(days []time.Weekday, d time.Weekday) bool {
+	for _, w := range days {
+		if w == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStoreOpeningHours_CoversShift(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	weekend := []time.Weekday{time.Saturday, time.Sunday}
+	hours := &StoreOpeningHours{
+		StoreID: "store-a",
+		Weekly: []OpeningWindow{
+			{Weekdays: weekdays, Open: "10:00", Close: "22:00"},
+			{Weekdays: weekend, Open: "09:00", Close: "02:00"}, // 周末营业到次日凌晨
+		},
+		Holidays: []SpecialHours{
+			{Date: "2024-01-17", Name: "店休", Closed: true},
+			{Date: "2024-01-18", Name: "盘点", Windows: []OpeningWindow{{Open: "12:00", Close: "18:00"}}},
+		},
+		PrepMinutes:    30,
+		CleanupMinutes: 30,
+	}
+	if err := hours.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		date  string
+		start string
+		end   string
+		want  bool
+	}{
+		{"工作日开店前30分钟到岗", "2024-01-15", "09:30", "17:00", true},
+		{"工作日早于准备时间", "2024-01-15", "09:00", "17:00", false},
+		{"工作日闭店后收尾", "2024-01-15", "16:00", "22:30", true},
+		{"闭店日", "2024-01-17", "10:00", "18:00", false},
+		{"特殊营业时间内", "2024-01-18", "12:00", "18:00", true},
+		{"超出特殊营业时间", "2024-01-18", "10:00", "18:00", false},
+		{"周末跨日班次", "2024-01-20", "20:00", "02:30", true},
+		{"凌晨属于前一天的营业时间", "2024-01-21", "00:00", "02:00", true},
+		{"凌晨超出前一天的营业时间", "2024-01-22", "03:00", "06:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift := &Shift{StartTime: tt.start, EndTime: tt.end}
+			if got := hours.CoversShift(tt.date, shift); got != tt.want {
+				t.Errorf("CoversShift(%s %s-%s) = %v, want %v", tt.date, tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreOpeningHours_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		hours StoreOpeningHours
+	}{
+		{"门店为空", StoreOpeningHours{}},
+		{"时间格式错误", StoreOpeningHours{StoreID: "s", Weekly: []OpeningWindow{{Open: "9点", Close: "18:00"}}}},
+		{"特殊日期重复", StoreOpeningHours{StoreID: "s", Holidays: []SpecialHours{
+			{Date: "2024-01-01", Closed: true}, {Date: "2024-01-01", Closed: true},
+		}}},
+		{"特殊日期未设置时间段", StoreOpeningHours{StoreID: "s", Holidays: []SpecialHours{{Date: "2024-01-01"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hours.Validate(); err == nil {
+				t.Error("Validate() should fail")
+			}
+		})
+	}
+}
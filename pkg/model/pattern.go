@@ -0,0 +1,131 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxPatternWeeks 循环排班模板最多的周数
+const MaxPatternWeeks = 8
+
+// SchedulePattern 循环排班模板（按周重复的轮班表）
+// 模板由 Weeks 周的排班组成，从 AnchorDate 起每 Weeks 周循环一次（与排班周期的计算方式一致），
+// 展开时每天按所在循环周和星期取模板中的排班
+type SchedulePattern struct {
+	BaseModel
+	OrgID       uuid.UUID      `json:"org_id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Weeks       int            `json:"weeks"`                 // 循环周数，默认 1
+	AnchorDate  string         `json:"anchor_date,omitempty"` // 模板第 1 周的起始日，默认 DefaultCycleAnchor
+	Shifts      []*Shift       `json:"shifts"`                // 模板使用的班次定义
+	Entries     []PatternEntry `json:"entries"`
+}
+
+// PatternEntry 模板中的一条排班：第 Week 周的星期 Weekday 由员工上 ShiftCode 班次
+type PatternEntry struct {
+	Week       int          `json:"week,omitempty"` // 第几周（从 1 起），默认 1
+	Weekday    time.Weekday `json:"weekday"`        // 0 为周日
+	EmployeeID uuid.UUID    `json:"employee_id"`
+	ShiftCode  string       `json:"shift_code"`
+	Position   string       `json:"position,omitempty"`
+	StoreID    string       `json:"store_id,omitempty"`
+}
+
+// Normalize 补全默认值：循环周数默认 1，排班的周次默认第 1 周，未设置ID的班次生成ID
+func (p *SchedulePattern) Normalize() {
+	if p.Weeks == 0 {
+		p.Weeks = 1
+	}
+	for i := range p.Entries {
+		if p.Entries[i].Week == 0 {
+			p.Entries[i].Week = 1
+		}
+	}
+	for _, s := range p.Shifts {
+		if s != nil && s.ID == uuid.Nil {
+			s.ID = uuid.New()
+		}
+	}
+}
+
+// Validate 检查模板是否合法（应先调用 Normalize）
+func (p *SchedulePattern) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("模板名称不能为空")
+	}
+	if p.Weeks < 1 || p.Weeks > MaxPatternWeeks {
+		return fmt.Errorf("循环周数应在 1-%d 之间", MaxPatternWeeks)
+	}
+	if p.AnchorDate != "" {
+		if _, err := time.Parse("2006-01-02", p.AnchorDate); err != nil {
+			return fmt.Errorf("模板起始日格式应为 YYYY-MM-DD")
+		}
+	}
+	if len(p.Entries) == 0 {
+		return fmt.Errorf("模板至少需要一条排班")
+	}
+
+	shifts := make(map[string]bool, len(p.Shifts))
+	for _, s := range p.Shifts {
+		if s == nil || s.Code == "" {
+			return fmt.Errorf("班次编码不能为空")
+		}
+		if shifts[s.Code] {
+			return fmt.Errorf("班次编码 %s 重复", s.Code)
+		}
+		_, err1 := time.Parse("15:04", s.StartTime)
+		_, err2 := time.Parse("15:04", s.EndTime)
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("班次 %s 的时间格式应为 HH:MM", s.Code)
+		}
+		shifts[s.Code] = true
+	}
+
+	seen := make(map[PatternEntry]bool, len(p.Entries))
+	for _, e := range p.Entries {
+		if e.Week < 1 || e.Week > p.Weeks {
+			return fmt.Errorf("排班的周次 %d 超出循环周数 %d", e.Week, p.Weeks)
+		}
+		if e.Weekday < time.Sunday || e.Weekday > time.Saturday {
+			return fmt.Errorf("无效的星期: %d", e.Weekday)
+		}
+		if e.EmployeeID == uuid.Nil {
+			return fmt.Errorf("排班缺少员工ID")
+		}
+		if !shifts[e.ShiftCode] {
+			return fmt.Errorf("班次 %s 未定义", e.ShiftCode)
+		}
+		if seen[e] {
+			return fmt.Errorf("第 %d 周星期 %d 的排班重复", e.Week, e.Weekday)
+		}
+		seen[e] = true
+	}
+	return nil
+}
+
+// Shift 按编码查找模板中的班次
+func (p *SchedulePattern) Shift(code string) *Shift {
+	for _, s := range p.Shifts {
+		if s != nil && s.Code == code {
+			return s
+		}
+	}
+	return nil
+}
+
+// WeekOf 返回日期在模板中的周次（从 1 起），日期格式错误时返回 0
+func (p *SchedulePattern) WeekOf(date string) int {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return 0
+	}
+	cycle := ScheduleCycle{Weeks: p.Weeks, AnchorDate: p.AnchorDate}
+	start, err := time.Parse("2006-01-02", cycle.Start(date))
+	if err != nil {
+		return 0
+	}
+	return int(t.Sub(start).Hours()/24)/7 + 1
+}
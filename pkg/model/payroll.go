@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PayrollClose 工资结账
+// 结账后组织 LockedBefore 之前（不含当天）的分配不可再通过排班编辑修改，只能走调整流程
+type PayrollClose struct {
+	ID           uuid.UUID `json:"id"`
+	OrgID        uuid.UUID `json:"org_id"`
+	LockedBefore string    `json:"locked_before"` // YYYY-MM-DD
+	Note         string    `json:"note,omitempty"`
+	Settled      int       `json:"settled"` // 本次结账纳入的调整数量
+	ClosedBy     string    `json:"closed_by,omitempty"`
+	ClosedAt     time.Time `json:"closed_at"`
+}
+
+// PayrollAdjustment 已结账分配的调整
+// 记录调整前后的分配和工时差额，在下一次结账时纳入工资计算（SettledIn 为该次结账）
+type PayrollAdjustment struct {
+	ID           uuid.UUID   `json:"id"`
+	OrgID        uuid.UUID   `json:"org_id"`
+	ScheduleID   uuid.UUID   `json:"schedule_id"`
+	AssignmentID uuid.UUID   `json:"assignment_id"`
+	EmployeeID   uuid.UUID   `json:"employee_id"` // 更换员工时为调整后的员工，原员工另有一条负差额记录
+	Date         string      `json:"date"`
+	Op           string      `json:"op"` // add/update/remove
+	Before       *Assignment `json:"before,omitempty"`
+	After        *Assignment `json:"after,omitempty"`
+	HoursDelta   float64     `json:"hours_delta"` // 该员工工时变化（小时）
+	Reason       string      `json:"reason"`
+	CreatedBy    string      `json:"created_by,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+	SettledIn    *uuid.UUID  `json:"settled_in,omitempty"`
+	SettledAt    *time.Time  `json:"settled_at,omitempty"`
+}
+
+// IsSettled 调整是否已纳入结账
+func (a *PayrollAdjustment) IsSettled() bool {
+	return a.SettledIn != nil
+}
+
+// IsPayrollLocked 检查日期是否在已结账期间内（lockedBefore 为空表示未结账）
+func IsPayrollLocked(date, lockedBefore string) bool {
+	return lockedBefore != "" && date != "" && date < lockedBefore
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 需求导入审核状态
+const (
+	RequirementImportPending  = "pending"  // 待审核
+	RequirementImportApproved = "approved" // 已采用，需求已写入
+	RequirementImportRejected = "rejected" // 已驳回
+)
+
+// HourlyDemand 某日某小时的业务量及换算出的所需人数
+type HourlyDemand struct {
+	Date   string  `json:"date"`   // YYYY-MM-DD
+	Hour   int     `json:"hour"`   // 0-23
+	Volume float64 `json:"volume"` // 交易笔数/订单数
+	Staff  int     `json:"staff"`
+}
+
+// RequirementImport 由 POS 小时业务量导入的草稿需求
+// 业务量按人效（每人每小时处理的交易数）换算为每小时所需人数，再按班次覆盖的小时生成各班次需求；
+// 管理者审核采用后需求才写入组织需求，用于排班生成
+type RequirementImport struct {
+	ID           uuid.UUID `json:"id"`
+	OrgID        uuid.UUID `json:"org_id"`
+	Source       string    `json:"source,omitempty"` // 来源说明，如导出文件名
+	StartDate    string    `json:"start_date"`
+	EndDate      string    `json:"end_date"`
+	Productivity float64   `json:"productivity"`        // 每人每小时处理的交易数
+	MinStaff     int       `json:"min_staff,omitempty"` // 有业务量的小时至少安排的人数
+
+	Curve        []HourlyDemand     `json:"curve"`
+	Requirements []ShiftRequirement `json:"requirements"`
+	// 有业务量但没有任何班次覆盖的小时
+	Uncovered []HourlyDemand `json:"uncovered,omitempty"`
+
+	Status     string     `json:"status"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReviewedBy string     `json:"reviewed_by,omitempty"`
+	ReviewNote string     `json:"review_note,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+}
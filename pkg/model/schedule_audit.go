@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 排班生命周期操作
+const (
+	ScheduleActionPublish          = "publish"              // 发布（立即对员工可见）
+	ScheduleActionSchedulePublish  = "schedule_publish"     // 按发布规则设置计划公布时间
+	ScheduleActionAutoPublish      = "auto_publish"         // 到达计划公布时间后自动发布
+	ScheduleActionArchive          = "archive"              // 归档
+	ScheduleActionPublishRejected  = "publish_rejected"     // 存在硬约束违反，拒绝发布
+	ScheduleActionAutoPublishBlock = "auto_publish_blocked" // 到点时存在硬约束违反，跳过自动发布
+)
+
+// ScheduleAuditEntry 排班生命周期审计记录
+// 记录排班状态的每次变化（草稿 → 已发布 → 已归档）及被拒绝的发布，供下游系统确认排班何时正式生效
+type ScheduleAuditEntry struct {
+	ID         uuid.UUID  `json:"id"`
+	ScheduleID uuid.UUID  `json:"schedule_id"`
+	OrgID      uuid.UUID  `json:"org_id"`
+	Action     string     `json:"action"`
+	FromStatus string     `json:"from_status"`
+	ToStatus   string     `json:"to_status"`
+	Actor      string     `json:"actor,omitempty"` // 操作人用户ID，自动操作为 system
+	Reason     string     `json:"reason,omitempty"`
+	PublishAt  *time.Time `json:"publish_at,omitempty"` // 计划公布时间
+	Version    int        `json:"version"`              // 操作时的排班版本
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareLink 已发布排班的只读分享链接
+// 没有账号的员工通过带签名的链接查看排班；可按门店、岗位只分享部分员工的排班，到期或撤销后失效
+type ShareLink struct {
+	ID         uuid.UUID  `json:"id"`
+	OrgID      uuid.UUID  `json:"org_id"`
+	ScheduleID uuid.UUID  `json:"schedule_id"`
+	StoreID    string     `json:"store_id,omitempty"` // 只分享该门店员工的排班
+	Position   string     `json:"position,omitempty"` // 只分享该岗位的排班
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedBy  string     `json:"revoked_by,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+
+	Accesses     int        `json:"accesses"` // 成功访问次数
+	LastAccessAt *time.Time `json:"last_access_at,omitempty"`
+}
+
+// IsActiveAt 链接在 now 时刻是否可用（未撤销且未过期）
+func (l *ShareLink) IsActiveAt(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// ShareAccess 分享链接访问记录
+type ShareAccess struct {
+	LinkID     uuid.UUID `json:"link_id"`
+	At         time.Time `json:"at"`
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	Format     string    `json:"format"`           // html/json
+	Denied     string    `json:"denied,omitempty"` // 拒绝原因（已过期、已撤销等），为空表示访问成功
+}
@@ -2,6 +2,8 @@
 package model
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,29 +20,62 @@ type Shift struct {
 	EndTime     string    `json:"end_time" db:"end_time"`     // HH:MM
 	Duration    int       `json:"duration" db:"duration"`     // 分钟
 	BreakTime   int       `json:"break_time" db:"break_time"` // 休息时间（分钟）
-	ShiftType   string    `json:"shift_type" db:"shift_type"` // morning/afternoon/evening/night/split
+	ShiftType   string    `json:"shift_type" db:"shift_type"` // morning/afternoon/evening/night/split/on_call
 	Color       string    `json:"color,omitempty" db:"color"` // 颜色标识
 	IsActive    bool      `json:"is_active" db:"is_active"`
+	StoreID     string    `json:"store_id,omitempty" db:"-"` // 所属门店（多门店排班时，未指定门店的需求沿用班次的门店）
 }
 
 // ShiftRequirement 班次需求
 type ShiftRequirement struct {
 	BaseModel
-	OrgID        uuid.UUID `json:"org_id" db:"org_id"`
-	ShiftID      uuid.UUID `json:"shift_id" db:"shift_id"`
-	Date         string    `json:"date" db:"date"` // YYYY-MM-DD
-	Position     string    `json:"position,omitempty" db:"position"`
-	MinEmployees int       `json:"min_employees" db:"min_employees"`
-	MaxEmployees int       `json:"max_employees" db:"max_employees"`
-	OptEmployees int       `json:"opt_employees" db:"opt_employees"` // 最优人数
-	Skills       []string  `json:"skills,omitempty" db:"skills"`
-	Priority     int       `json:"priority" db:"priority"` // 优先级 1-10
+	OrgID        uuid.UUID    `json:"org_id" db:"org_id"`
+	ShiftID      uuid.UUID    `json:"shift_id" db:"shift_id"`
+	Date         string       `json:"date" db:"date"` // YYYY-MM-DD
+	Position     string       `json:"position,omitempty" db:"position"`
+	MinEmployees int          `json:"min_employees" db:"min_employees"`
+	MaxEmployees int          `json:"max_employees" db:"max_employees"`
+	OptEmployees int          `json:"opt_employees" db:"opt_employees"`         // 最优人数
+	Skills       []string     `json:"skills,omitempty" db:"skills"`             // 必须全部具备的技能
+	SkillGroups  []SkillGroup `json:"skill_groups,omitempty" db:"skill_groups"` // 技能组（组内任选，组间都需满足）
+	SkillLevels  []Skill      `json:"skill_levels,omitempty" db:"-"`            // 技能最低等级要求
+	Priority     int          `json:"priority" db:"priority"`                   // 优先级 1-10
+	StoreID      string       `json:"store_id,omitempty" db:"store_id"`         // 所属门店（按门店营业时间校验）
 
 	// 工作地点（用于计算员工通勤距离）
 	WorkLocation *Location `json:"work_location,omitempty" db:"work_location"`
 	Note         string    `json:"note,omitempty" db:"note"` // 备注说明
 }
 
+// SkillGroup 技能组：需具备组内至少 MinCount 项技能
+// 例如 {"skills": ["cook", "grill"]} 表示会 cook 或 grill 即可
+type SkillGroup struct {
+	Skills   []string `json:"skills"`
+	MinCount int      `json:"min_count,omitempty"` // 最少具备数量，默认 1
+}
+
+// Required 返回需具备的最少技能数
+func (g SkillGroup) Required() int {
+	n := g.MinCount
+	if n <= 0 {
+		n = 1
+	}
+	// 空技能组或最少数量超过组内技能数时，按组内技能数计
+	if n > len(g.Skills) {
+		n = len(g.Skills)
+	}
+	return n
+}
+
+// String 返回技能组描述，如 "cook/grill"、"cook/grill/bake(至少2项)"
+func (g SkillGroup) String() string {
+	desc := strings.Join(g.Skills, "/")
+	if g.Required() > 1 {
+		desc += fmt.Sprintf("(至少%d项)", g.Required())
+	}
+	return desc
+}
+
 // Assignment 排班分配
 type Assignment struct {
 	BaseModel
@@ -56,6 +91,7 @@ type Assignment struct {
 	IsOvertime    bool       `json:"is_overtime" db:"is_overtime"`
 	IsSwapped     bool       `json:"is_swapped" db:"is_swapped"`
 	OriginalEmpID *uuid.UUID `json:"original_employee_id,omitempty" db:"original_employee_id"`
+	StoreID       string     `json:"store_id,omitempty" db:"-"` // 上班门店（多门店排班时为需求所属门店）
 	Notes         string     `json:"notes,omitempty" db:"notes"`
 }
 
@@ -70,8 +106,15 @@ type Schedule struct {
 	Version     int            `json:"version" db:"version"`
 	CreatedBy   *uuid.UUID     `json:"created_by,omitempty" db:"created_by"`
 	PublishedAt *time.Time     `json:"published_at,omitempty" db:"published_at"`
+	PublishAt   *time.Time     `json:"publish_at,omitempty" db:"publish_at"` // 计划公布时间（公布前员工不可见）
+	PublishedBy string         `json:"published_by,omitempty" db:"published_by"`
+	ArchivedAt  *time.Time     `json:"archived_at,omitempty" db:"archived_at"`
+	ArchivedBy  string         `json:"archived_by,omitempty" db:"archived_by"`
 	Assignments []Assignment   `json:"assignments,omitempty" db:"-"`
 	Statistics  *ScheduleStats `json:"statistics,omitempty" db:"-"`
+
+	// 生成时使用的约束配置（发布前按此配置复核硬约束）
+	ConstraintConfig JSONMap `json:"constraint_config,omitempty" db:"constraint_config"`
 }
 
 // ScheduleStats 排班统计
@@ -81,11 +124,67 @@ type ScheduleStats struct {
 	TotalHours       float64 `json:"total_hours"`
 	OvertimeHours    float64 `json:"overtime_hours"`
 	UnfilledShifts   int     `json:"unfilled_shifts"`
+	FillRate         float64 `json:"fill_rate"`        // 需求满足率 (%)
 	ConstraintScore  float64 `json:"constraint_score"` // 约束满足率
 	FairnessScore    float64 `json:"fairness_score"`   // 公平性得分
 	PreferenceScore  float64 `json:"preference_score"` // 偏好满足率
 }
 
+// IsVisibleAt 检查排班在 now 时刻是否对员工可见
+// 已发布且已过计划公布时间的排班可见；到达公布时间但尚未被后台任务发布的排班同样可见
+func (s *Schedule) IsVisibleAt(now time.Time) bool {
+	if s.Status == "published" {
+		return s.PublishAt == nil || !now.Before(*s.PublishAt)
+	}
+	return s.Status == "draft" && s.PublishAt != nil && !now.Before(*s.PublishAt)
+}
+
+// 分配变更操作
+const (
+	ChangeAdd    = "add"
+	ChangeUpdate = "update"
+	ChangeRemove = "remove"
+)
+
+// AssignmentChange 排班分配变更
+type AssignmentChange struct {
+	Op           string      `json:"op"`                      // add/update/remove
+	AssignmentID uuid.UUID   `json:"assignment_id,omitempty"` // update/remove 的目标分配
+	Assignment   *Assignment `json:"assignment,omitempty"`    // add/update 的新内容
+}
+
+// ScheduleRevision 排班修订记录（每次修改产生一个新版本）
+type ScheduleRevision struct {
+	ScheduleID   uuid.UUID          `json:"schedule_id"`
+	Version      int                `json:"version"`
+	Changes      []AssignmentChange `json:"changes"`
+	Author       string             `json:"author,omitempty"`
+	RestoredFrom int                `json:"restored_from,omitempty"` // 回滚时恢复的版本
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+// 排班版本的产生原因
+const (
+	ScheduleVersionGenerate   = "generate"   // 生成排班
+	ScheduleVersionRegenerate = "regenerate" // 重新生成已有排班
+	ScheduleVersionEdit       = "edit"       // 修改分配（含换班、工资调整）
+	ScheduleVersionPublish    = "publish"    // 发布排班
+	ScheduleVersionRollback   = "rollback"   // 回滚到之前的版本
+)
+
+// ScheduleVersion 排班版本快照
+// 生成、重新生成、修改分配和发布时保存，Version 与排班版本（ETag）一致
+type ScheduleVersion struct {
+	ScheduleID  uuid.UUID      `json:"schedule_id"`
+	Version     int            `json:"version"`
+	Reason      string         `json:"reason"` // generate/regenerate/edit/publish/rollback
+	Status      string         `json:"status"` // 保存时的排班状态
+	Author      string         `json:"author,omitempty"`
+	Assignments []Assignment   `json:"assignments,omitempty"`
+	Statistics  *ScheduleStats `json:"statistics,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
 // SwapRequest 换班请求
 type SwapRequest struct {
 	BaseModel
@@ -111,6 +210,14 @@ func (a *Assignment) IsOnDate(date string) bool {
 	return a.Date == date
 }
 
+// Store 返回分配的上班门店，未指定时为员工的所属门店
+func (a *Assignment) Store(emp *Employee) string {
+	if a.StoreID != "" || emp == nil {
+		return a.StoreID
+	}
+	return emp.StoreID
+}
+
 // DurationHours 返回班次时长（小时）
 func (s *Shift) DurationHours() float64 {
 	return float64(s.Duration-s.BreakTime) / 60.0
@@ -125,3 +232,8 @@ func (s *Shift) IsNightShift() bool {
 func (s *Shift) IsSplitShift() bool {
 	return s.ShiftType == "split"
 }
+
+// IsOnCall 检查是否为待命班次（随叫随到，不计入常规工时，在常规班次排完后单独安排）
+func (s *Shift) IsOnCall() bool {
+	return s.ShiftType == "on_call"
+}
@@ -98,3 +98,12 @@ func TestShift_IsSplitShift(t *testing.T) {
 		t.Error("普通班应返回false")
 	}
 }
+
+func TestShift_IsOnCall(t *testing.T) {
+	if !(&Shift{ShiftType: "on_call"}).IsOnCall() {
+		t.Error("待命班次应返回true")
+	}
+	if (&Shift{ShiftType: "night"}).IsOnCall() {
+		t.Error("夜班应返回false")
+	}
+}
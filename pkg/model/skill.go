@@ -0,0 +1,155 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Skill 带等级的技能，Level 从 1 开始，越大越熟练
+// 员工只在 skills 中登记、未给出等级的技能按 1 级计
+type Skill struct {
+	Name  string `json:"name"`
+	Level int    `json:"level,omitempty"`
+}
+
+// String 返回技能描述，如 "grill(≥3级)"
+func (s Skill) String() string {
+	if s.Level <= 1 {
+		return s.Name
+	}
+	return fmt.Sprintf("%s(≥%d级)", s.Name, s.Level)
+}
+
+// Validate 校验技能等级
+func (s Skill) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("技能名称不能为空")
+	}
+	if s.Level < 0 {
+		return fmt.Errorf("技能 %s 的等级不能为负数", s.Name)
+	}
+	return nil
+}
+
+// Certification 带有效期的证书
+type Certification struct {
+	Name      string `json:"name"`
+	ExpiresAt string `json:"expires_at,omitempty"` // 有效期至 YYYY-MM-DD（含当天），为空表示长期有效
+}
+
+// Validate 校验证书
+func (c Certification) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("证书名称不能为空")
+	}
+	if c.ExpiresAt != "" {
+		if _, err := time.Parse("2006-01-02", c.ExpiresAt); err != nil {
+			return fmt.Errorf("证书 %s 的有效期格式须为 YYYY-MM-DD", c.Name)
+		}
+	}
+	return nil
+}
+
+// ValidOn 检查证书在指定日期是否在有效期内
+func (c Certification) ValidOn(date string) bool {
+	return c.ExpiresAt == "" || date <= c.ExpiresAt
+}
+
+// ExpiresWithin 检查证书是否在 start 至 end 期间失效（有效期最后一天不早于 start 且早于 end）
+func (c Certification) ExpiresWithin(start, end string) bool {
+	return c.ExpiresAt != "" && c.ExpiresAt >= start && c.ExpiresAt < end
+}
+
+// SkillLevel 返回员工某技能的等级，不具备返回 0
+// skill_levels 中的等级优先，只在 skills 中登记的技能为 1 级
+func (e *Employee) SkillLevel(skill string) int {
+	for _, s := range e.SkillLevels {
+		if s.Name == skill {
+			if s.Level <= 0 {
+				return 1
+			}
+			return s.Level
+		}
+	}
+	for _, s := range e.Skills {
+		if s == skill {
+			return 1
+		}
+	}
+	return 0
+}
+
+// MissingSkillLevels 返回员工未达到的技能等级要求
+func (e *Employee) MissingSkillLevels(levels []Skill) []string {
+	var missing []string
+	for _, l := range levels {
+		required := l.Level
+		if required <= 0 {
+			required = 1
+		}
+		if e.SkillLevel(l.Name) < required {
+			missing = append(missing, l.String())
+		}
+	}
+	return missing
+}
+
+// HasCertificationOn 检查员工的证书在指定日期是否有效
+// certification_records 中登记了有效期的证书按有效期判断，只在 certifications 中登记的证书视为长期有效
+func (e *Employee) HasCertificationOn(cert, date string) bool {
+	found := false
+	for _, c := range e.CertificationRecords {
+		if c.Name != cert {
+			continue
+		}
+		if c.ValidOn(date) {
+			return true
+		}
+		found = true
+	}
+	if found {
+		return false
+	}
+	for _, c := range e.Certifications {
+		if c == cert {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpiringCertifications 返回在 start 至 end 期间失效的证书（含已核验证书），同名证书取最晚的有效期
+// 有效期晚于 end 或长期有效的同名证书视为已续期，不返回
+func (e *Employee) ExpiringCertifications(start, end string) []Certification {
+	latest := make(map[string]string)
+	var names []string
+	add := func(name, expiresAt string) {
+		prev, ok := latest[name]
+		if !ok {
+			names = append(names, name)
+		}
+		switch {
+		case !ok:
+			latest[name] = expiresAt
+		case prev == "" || expiresAt == "":
+			latest[name] = ""
+		case expiresAt > prev:
+			latest[name] = expiresAt
+		}
+	}
+	for _, c := range e.CertificationRecords {
+		add(c.Name, c.ExpiresAt)
+	}
+	for _, c := range e.VerifiedCertifications {
+		add(c.Name, c.ExpiresAt)
+	}
+
+	var expiring []Certification
+	for _, name := range names {
+		c := Certification{Name: name, ExpiresAt: latest[name]}
+		if c.ExpiresWithin(start, end) {
+			expiring = append(expiring, c)
+		}
+	}
+	return expiring
+}
@@ -0,0 +1,79 @@
+package model
+
+import "testing"
+
+func TestEmployee_SkillLevels(t *testing.T) {
+	e := &Employee{
+		Skills:      []string{"cashier"},
+		SkillLevels: []Skill{{Name: "grill", Level: 3}, {Name: "bake"}},
+	}
+
+	tests := []struct {
+		name    string
+		levels  []Skill
+		missing int
+	}{
+		{"无要求", nil, 0},
+		{"等级满足", []Skill{{Name: "grill", Level: 3}}, 0},
+		{"等级不足", []Skill{{Name: "grill", Level: 4}}, 1},
+		{"未给出等级按 1 级", []Skill{{Name: "bake", Level: 1}, {Name: "cashier"}}, 0},
+		{"仅登记在 skills 中为 1 级", []Skill{{Name: "cashier", Level: 2}}, 1},
+		{"不具备", []Skill{{Name: "cook"}, {Name: "grill", Level: 2}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if missing := e.MissingSkillLevels(tt.levels); len(missing) != tt.missing {
+				t.Errorf("MissingSkillLevels() = %v, expected %d", missing, tt.missing)
+			}
+		})
+	}
+	if !e.HasSkill("grill") || e.HasSkill("cook") {
+		t.Error("skill_levels 中的技能应视为具备")
+	}
+	if got := (Skill{Name: "grill", Level: 3}).String(); got != "grill(≥3级)" {
+		t.Errorf("String() = %s", got)
+	}
+}
+
+func TestEmployee_CertificationExpiry(t *testing.T) {
+	e := &Employee{
+		Certifications:       []string{"电工证", "健康证"},
+		CertificationRecords: []Certification{{Name: "健康证", ExpiresAt: "2026-03-10"}, {Name: "叉车证", ExpiresAt: "2026-02-01"}},
+		VerifiedCertifications: []VerifiedCertification{
+			{Name: "食品安全培训证", ExpiresAt: "2026-03-05"},
+			{Name: "叉车证", ExpiresAt: "2027-02-01"},
+		},
+	}
+
+	tests := []struct {
+		cert, date string
+		expected   bool
+	}{
+		{"电工证", "2030-01-01", true},  // 只在 certifications 中登记视为长期有效
+		{"健康证", "2026-03-10", true},  // 有效期含当天
+		{"健康证", "2026-03-11", false}, // 登记了有效期时按有效期判断
+		{"叉车证", "2026-02-02", false}, // 已核验证书不影响登记的有效期
+		{"焊工证", "2026-01-01", false},
+	}
+	for _, tt := range tests {
+		if got := e.HasCertificationOn(tt.cert, tt.date); got != tt.expected {
+			t.Errorf("HasCertificationOn(%s, %s) = %v, expected %v", tt.cert, tt.date, got, tt.expected)
+		}
+	}
+	if !e.HasCertification("叉车证") {
+		t.Error("certification_records 中的证书应视为已登记")
+	}
+
+	// 叉车证已续期至 2027 年，不在排班期间到期
+	expiring := e.ExpiringCertifications("2026-03-01", "2026-03-31")
+	if len(expiring) != 2 || expiring[0].Name != "健康证" || expiring[1].Name != "食品安全培训证" {
+		t.Errorf("ExpiringCertifications() = %+v", expiring)
+	}
+	if got := e.ExpiringCertifications("2026-03-01", "2026-03-10"); len(got) != 1 || got[0].Name != "食品安全培训证" {
+		t.Errorf("有效期至排班最后一天不应提醒: %+v", got)
+	}
+
+	if err := (Certification{Name: "健康证", ExpiresAt: "2026/03/10"}).Validate(); err == nil {
+		t.Error("有效期格式错误应校验失败")
+	}
+}
@@ -0,0 +1,47 @@
+package model
+
+// Store 门店（多门店排班时在一次请求中描述各门店及门店间的通勤时间）
+type Store struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+
+	// 前往其他门店的通勤时间（分钟），key 为门店ID；未配置时按默认跨店通勤时间计算
+	TravelMinutes map[string]int `json:"travel_minutes,omitempty"`
+}
+
+// ResolveStore 按门店ID查找门店，未找到时返回 nil
+func ResolveStore(stores []Store, storeID string) *Store {
+	for i := range stores {
+		if stores[i].ID == storeID {
+			return &stores[i]
+		}
+	}
+	return nil
+}
+
+// StoreName 返回门店名称，未配置名称时返回门店ID
+func StoreName(stores []Store, storeID string) string {
+	if s := ResolveStore(stores, storeID); s != nil && s.Name != "" {
+		return s.Name
+	}
+	return storeID
+}
+
+// StoreTravelMinutes 返回两个门店之间的通勤时间（分钟）
+// 同一门店为 0；两个方向任一配置了通勤时间即采用，均未配置时返回 defaultMinutes
+func StoreTravelMinutes(stores []Store, from, to string, defaultMinutes int) int {
+	if from == to {
+		return 0
+	}
+	if s := ResolveStore(stores, from); s != nil {
+		if m, ok := s.TravelMinutes[to]; ok {
+			return m
+		}
+	}
+	if s := ResolveStore(stores, to); s != nil {
+		if m, ok := s.TravelMinutes[from]; ok {
+			return m
+		}
+	}
+	return defaultMinutes
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MonthlySummary 员工月度排班汇总
+type MonthlySummary struct {
+	EmployeeID    uuid.UUID      `json:"employee_id" db:"employee_id"`
+	OrgID         uuid.UUID      `json:"org_id" db:"org_id"`
+	EmployeeName  string         `json:"employee_name" db:"employee_name"`
+	Month         string         `json:"month" db:"month"` // YYYY-MM
+	TotalHours    float64        `json:"total_hours" db:"total_hours"`
+	TargetHours   float64        `json:"target_hours" db:"target_hours"`       // 合同目标工时
+	HoursVsTarget float64        `json:"hours_vs_target" db:"hours_vs_target"` // 实际 - 目标
+	OvertimeHours float64        `json:"overtime_hours" db:"overtime_hours"`
+	Shifts        int            `json:"shifts" db:"shifts"`
+	NightShifts   int            `json:"night_shifts" db:"night_shifts"`
+	WeekendShifts int            `json:"weekend_shifts" db:"weekend_shifts"`
+	LeaveDays     int            `json:"leave_days" db:"leave_days"`
+	Entries       []SummaryEntry `json:"entries" db:"-"`
+	GeneratedAt   time.Time      `json:"generated_at" db:"generated_at"`
+	DeliveredAt   *time.Time     `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// SummaryEntry 月度汇总中的单条记录（班次或请假）
+type SummaryEntry struct {
+	Date         string     `json:"date"`
+	Type         string     `json:"type"` // shift/leave
+	AssignmentID *uuid.UUID `json:"assignment_id,omitempty"`
+	ShiftName    string     `json:"shift_name,omitempty"`
+	Hours        float64    `json:"hours"`
+	Night        bool       `json:"night,omitempty"`
+	Weekend      bool       `json:"weekend,omitempty"`
+	Note         string     `json:"note,omitempty"`
+}
+
+// 汇总争议状态
+const (
+	DisputePending  = "pending"
+	DisputeApproved = "approved"
+	DisputeRejected = "rejected"
+)
+
+// SummaryDispute 员工对月度汇总记录的争议，提交后进入管理者审核
+type SummaryDispute struct {
+	BaseModel
+	OrgID        uuid.UUID  `json:"org_id" db:"org_id"`
+	EmployeeID   uuid.UUID  `json:"employee_id" db:"employee_id"`
+	Month        string     `json:"month" db:"month"`
+	Date         string     `json:"date,omitempty" db:"date"`
+	AssignmentID *uuid.UUID `json:"assignment_id,omitempty" db:"assignment_id"`
+	Reason       string     `json:"reason" db:"reason"`
+	Status       string     `json:"status" db:"status"` // pending/approved/rejected
+	ReviewedBy   string     `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewNote   string     `json:"review_note,omitempty" db:"review_note"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 班组的班次一致性要求
+const (
+	TeamCohesionPreferred = "preferred" // 尽量安排在同一班次（软约束，默认）
+	TeamCohesionRequired  = "required"  // 同一天上班的成员必须在同一班次（硬约束）
+)
+
+// Team 班组：成员尽量（或必须）同一天上同一班次，如工厂的甲乙丙班、同一条产线的作业组
+type Team struct {
+	OrgID     uuid.UUID   `json:"org_id,omitempty"`
+	ID        string      `json:"id"`
+	Name      string      `json:"name,omitempty"`
+	Members   []uuid.UUID `json:"members"`
+	Cohesion  string      `json:"cohesion,omitempty"` // preferred/required
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Label 返回班组名称，未配置名称时返回班组ID
+func (t *Team) Label() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.ID
+}
+
+// IsRequired 班组是否要求成员必须同一班次
+func (t *Team) IsRequired() bool {
+	return t.Cohesion == TeamCohesionRequired
+}
+
+// Validate 校验班组配置
+func (t *Team) Validate() error {
+	if t.ID == "" {
+		return fmt.Errorf("班组ID不能为空")
+	}
+	switch t.Cohesion {
+	case "", TeamCohesionPreferred, TeamCohesionRequired:
+	default:
+		return fmt.Errorf("班组 %s 的 cohesion 无效: %s（可选 preferred/required）", t.ID, t.Cohesion)
+	}
+	if len(t.Members) == 0 {
+		return fmt.Errorf("班组 %s 没有成员", t.ID)
+	}
+	seen := make(map[uuid.UUID]bool, len(t.Members))
+	for _, id := range t.Members {
+		if id == uuid.Nil || seen[id] {
+			return fmt.Errorf("班组 %s 的成员 %s 无效或重复", t.ID, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// ValidateTeams 校验班组列表：每个班组有效，班组ID不重复，每名员工最多属于一个班组
+func ValidateTeams(teams []Team) error {
+	ids := make(map[string]bool, len(teams))
+	memberOf := make(map[uuid.UUID]string)
+	for i := range teams {
+		t := &teams[i]
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		if ids[t.ID] {
+			return fmt.Errorf("班组 %s 重复", t.ID)
+		}
+		ids[t.ID] = true
+		for _, id := range t.Members {
+			if other, ok := memberOf[id]; ok {
+				return fmt.Errorf("员工 %s 同时属于班组 %s 和 %s", id, other, t.ID)
+			}
+			memberOf[id] = t.ID
+		}
+	}
+	return nil
+}
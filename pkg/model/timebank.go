@@ -0,0 +1,70 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 调休账户记录类型
+const (
+	TimeBankOvertime = "overtime" // 加班审批通过后计入
+	TimeBankAdjust   = "adjust"   // 管理者手工调整（如期初余额、清零）
+)
+
+// TimeBankPolicy 组织的调休策略
+// 审批通过的加班按 BankRatio 拆分为调休和加班费：调休部分按 AccrualRate 折算计入调休账户，
+// 其余按加班费结算；计入后余额超过 MaxBalance 的部分改为按加班费结算
+type TimeBankPolicy struct {
+	BankRatio   float64 `json:"bank_ratio"`             // 计入调休的加班比例 0~1，0 表示全部支付加班费
+	AccrualRate float64 `json:"accrual_rate,omitempty"` // 每小时加班折算的调休小时数，默认 1
+	MaxBalance  float64 `json:"max_balance,omitempty"`  // 调休余额上限（小时），0 表示不限
+}
+
+// Validate 检查调休策略是否合法
+func (p *TimeBankPolicy) Validate() error {
+	if p.BankRatio < 0 || p.BankRatio > 1 {
+		return fmt.Errorf("计入调休的加班比例应在 0~1 之间")
+	}
+	if p.AccrualRate < 0 || p.MaxBalance < 0 {
+		return fmt.Errorf("折算比例和余额上限不能为负数")
+	}
+	return nil
+}
+
+// Rate 返回每小时加班折算的调休小时数
+func (p *TimeBankPolicy) Rate() float64 {
+	if p.AccrualRate <= 0 {
+		return 1
+	}
+	return p.AccrualRate
+}
+
+// TimeBankEntry 调休账户记录
+// 加班记录同时保存加班时长及其中按加班费结算、计入调休的部分，Hours 为调休账户变动（小时，调整可为负）
+type TimeBankEntry struct {
+	ID            uuid.UUID  `json:"id"`
+	OrgID         uuid.UUID  `json:"org_id"`
+	EmployeeID    uuid.UUID  `json:"employee_id"`
+	Date          string     `json:"date"` // YYYY-MM-DD
+	Kind          string     `json:"kind"` // overtime/adjust
+	ApprovalID    *uuid.UUID `json:"approval_id,omitempty"`
+	OvertimeHours float64    `json:"overtime_hours,omitempty"` // 审批通过的加班时长
+	PaidHours     float64    `json:"paid_hours,omitempty"`     // 按加班费结算的加班时长
+	BankedHours   float64    `json:"banked_hours,omitempty"`   // 计入调休的加班时长（折算前）
+	Hours         float64    `json:"hours"`
+	Note          string     `json:"note,omitempty"`
+	CreatedBy     string     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TimeBankBalance 员工调休余额
+type TimeBankBalance struct {
+	EmployeeID uuid.UUID `json:"employee_id"`
+	Accrued    float64   `json:"accrued"`   // 加班计入和调整合计
+	Used       float64   `json:"used"`      // 已批准请假使用的调休
+	Pending    float64   `json:"pending"`   // 待审核请假申请使用的调休
+	Balance    float64   `json:"balance"`   // Accrued - Used
+	Available  float64   `json:"available"` // 扣除待审核请假后的可用余额
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 通知订阅渠道
+const (
+	ChannelWebhook = "webhook" // 以 HTTP POST 投递到 URL
+	ChannelEmail   = "email"   // 通过 SMTP 发送邮件给 Recipients
+)
+
+// WebhookAllEvents 订阅全部通知事件
+const WebhookAllEvents = "*"
+
+// Webhook 组织的通知订阅
+// 订阅的事件发生时按渠道投递：webhook 渠道 POST 到 URL（配置 Secret 时附带 HMAC-SHA256 签名），
+// email 渠道发送邮件；Template 为 Go text/template 模板，为空时 webhook 投递通知 JSON、邮件正文为通知内容
+type Webhook struct {
+	BaseModel
+	OrgID      uuid.UUID `json:"org_id"`
+	Name       string    `json:"name,omitempty"`
+	Channel    string    `json:"channel"`              // webhook/email，默认 webhook
+	URL        string    `json:"url,omitempty"`        // webhook 渠道的投递地址
+	Secret     string    `json:"secret,omitempty"`     // 签名密钥，查询时不返回
+	Recipients []string  `json:"recipients,omitempty"` // email 渠道的收件人
+	Events     []string  `json:"events"`               // 订阅的通知类型，* 表示全部
+	Template   string    `json:"template,omitempty"`
+	Active     bool      `json:"active"`
+}
+
+// WebhookDelivery 通知订阅的投递记录
+type WebhookDelivery struct {
+	ID             uuid.UUID `json:"id"`
+	WebhookID      uuid.UUID `json:"webhook_id"`
+	NotificationID uuid.UUID `json:"notification_id"`
+	Event          string    `json:"event"`
+	Success        bool      `json:"success"`
+	Attempts       int       `json:"attempts"`
+	StatusCode     int       `json:"status_code,omitempty"` // webhook 渠道最后一次响应的状态码
+	Error          string    `json:"error,omitempty"`       // 最后一次失败的原因
+	CreatedAt      time.Time `json:"created_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+}
+
+// Validate 校验通知订阅（事件名称和模板由通知模块校验）
+func (w *Webhook) Validate() error {
+	switch w.Channel {
+	case ChannelWebhook:
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook 地址应为 http(s) URL")
+		}
+	case ChannelEmail:
+		if len(w.Recipients) == 0 {
+			return fmt.Errorf("邮件订阅至少需要一个收件人")
+		}
+		for _, r := range w.Recipients {
+			if _, err := mail.ParseAddress(r); err != nil {
+				return fmt.Errorf("无效的收件人邮箱: %s", r)
+			}
+		}
+	default:
+		return fmt.Errorf("无效的通知渠道: %s", w.Channel)
+	}
+	if len(w.Events) == 0 {
+		return fmt.Errorf("至少需要订阅一个事件")
+	}
+	return nil
+}
+
+// Subscribes 是否订阅了该通知类型
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, e := range w.Events {
+		if e == eventType || e == WebhookAllEvents {
+			return true
+		}
+	}
+	return false
+}
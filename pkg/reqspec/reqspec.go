@@ -0,0 +1,292 @@
+// Package reqspec 解析排班需求简写
+// 简写由多条以分号或换行分隔的条目组成，例如 "早班 weekdays 2-3人; 晚班 fri-sun 4人; 厨师≥1 every shift"：
+//   - 班次条目：班次名称或编码、适用的星期（省略为每天）、人数（N 或 N-M，可带"人"），
+//     同一班次同一天被多个条目命中时，后面的条目覆盖前面的
+//   - 岗位条目：岗位≥N（或 >=N），可限定班次和星期（省略或 every shift / 每班 表示所有班次），
+//     在命中的班次需求中拆出该岗位至少 N 人，其余人数仍为不限岗位的需求
+package reqspec
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// ParseError 简写解析错误
+type ParseError struct {
+	Clause int    `json:"clause"` // 条目序号（从 1 开始）
+	Text   string `json:"text"`
+	Reason string `json:"reason"`
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("第 %d 条 %q: %s", e.Clause, e.Text, e.Reason)
+}
+
+var (
+	clauseSeparator = regexp.MustCompile(`[;；\n]+`)
+	listSeparator   = regexp.MustCompile(`[,，、]`)
+	rangeSeparator  = regexp.MustCompile(`^(.+?)(?:-|~|～|至|到)(.+)$`)
+	countPattern    = regexp.MustCompile(`^(\d+)(?:(?:-|~|～|至|到)(\d+))?人?$`)
+	positionPattern = regexp.MustCompile(`^(\S+?)\s*(?:≥|>=)\s*(\d+)\s*人?(?:\s+(.*))?$`)
+)
+
+// dayNames 星期名称（小写）
+var dayNames = map[string]time.Weekday{
+	"mon": time.Monday, "monday": time.Monday, "周一": time.Monday, "星期一": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday, "周二": time.Tuesday, "星期二": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday, "周三": time.Wednesday, "星期三": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday, "周四": time.Thursday, "星期四": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday, "周五": time.Friday, "星期五": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday, "周六": time.Saturday, "星期六": time.Saturday,
+	"sun": time.Sunday, "sunday": time.Sunday, "周日": time.Sunday, "周天": time.Sunday, "星期日": time.Sunday, "星期天": time.Sunday,
+}
+
+// dayGroups 星期分组关键字
+var dayGroups = map[string][]time.Weekday{
+	"daily":    allDays(),
+	"everyday": allDays(),
+	"每天":       allDays(),
+	"每日":       allDays(),
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekday":  {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"工作日":      {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+	"weekend":  {time.Saturday, time.Sunday},
+	"周末":       {time.Saturday, time.Sunday},
+}
+
+// everyShift 岗位条目中表示所有班次的词
+var everyShift = map[string]bool{
+	"every": true, "each": true, "all": true, "shift": true, "shifts": true, "day": true,
+	"每班": true, "每个班次": true, "所有班次": true, "每个班": true,
+}
+
+// Parse 按简写生成 [startDate, endDate] 内的班次需求（按日期、班次顺序），班次按名称或编码匹配（不区分大小写）
+// 返回的需求未设置ID，MaxEmployees 为人数上限（只给一个人数时等于下限）
+func Parse(spec string, shifts []*model.Shift, startDate, endDate string) ([]*model.ShiftRequirement, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("开始日期格式无效: %s", startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("结束日期格式无效: %s", endDate)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("结束日期早于开始日期")
+	}
+
+	p := &parser{shifts: shifts, base: make(map[string]*model.ShiftRequirement)}
+	var positions []positionClause
+	n := 0
+	for _, text := range clauseSeparator.Split(spec, -1) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		n++
+		if m := positionPattern.FindStringSubmatch(text); m != nil {
+			clause, reason := p.positionClause(m)
+			if reason != "" {
+				return nil, &ParseError{Clause: n, Text: text, Reason: reason}
+			}
+			positions = append(positions, clause)
+			continue
+		}
+		if reason := p.shiftClause(text, start, end); reason != "" {
+			return nil, &ParseError{Clause: n, Text: text, Reason: reason}
+		}
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("需求简写为空")
+	}
+
+	result := make([]*model.ShiftRequirement, 0, len(p.order))
+	for _, key := range p.order {
+		base := p.base[key]
+		for _, pc := range positions {
+			if !pc.matches(base) {
+				continue
+			}
+			result = append(result, &model.ShiftRequirement{
+				ShiftID:      base.ShiftID,
+				Date:         base.Date,
+				Position:     pc.position,
+				MinEmployees: pc.min,
+				MaxEmployees: pc.min,
+			})
+			base.MinEmployees = max(base.MinEmployees-pc.min, 0)
+			base.MaxEmployees = max(base.MaxEmployees-pc.min, 0)
+		}
+		if base.MaxEmployees > 0 {
+			result = append(result, base)
+		}
+	}
+	return result, nil
+}
+
+// parser 解析状态：班次条目按 班次ID/日期 生成的需求及其首次出现的顺序
+type parser struct {
+	shifts []*model.Shift
+	base   map[string]*model.ShiftRequirement
+	order  []string
+}
+
+// positionClause 岗位条目
+type positionClause struct {
+	position string
+	min      int
+	shifts   map[string]bool // 班次ID，为空表示所有班次
+	days     map[time.Weekday]bool
+}
+
+// matches 判断岗位条目是否适用于该需求
+func (pc positionClause) matches(r *model.ShiftRequirement) bool {
+	if len(pc.shifts) > 0 && !pc.shifts[r.ShiftID.String()] {
+		return false
+	}
+	date, _ := time.Parse("2006-01-02", r.Date)
+	return pc.days[date.Weekday()]
+}
+
+// shiftClause 解析班次条目并生成需求，返回错误原因
+func (p *parser) shiftClause(text string, start, end time.Time) string {
+	tokens := strings.Fields(text)
+	shift := p.shift(tokens[0])
+	if shift == nil {
+		return fmt.Sprintf("未知班次 %q", tokens[0])
+	}
+
+	minCount, maxCount := -1, -1
+	var dayTokens []string
+	for _, tok := range tokens[1:] {
+		if m := countPattern.FindStringSubmatch(tok); m != nil {
+			if minCount >= 0 {
+				return "人数重复"
+			}
+			minCount, _ = strconv.Atoi(m[1])
+			maxCount = minCount
+			if m[2] != "" {
+				maxCount, _ = strconv.Atoi(m[2])
+			}
+			continue
+		}
+		dayTokens = append(dayTokens, tok)
+	}
+	if minCount < 0 {
+		return "缺少人数（如 2人 或 2-3人）"
+	}
+	if maxCount < minCount || maxCount == 0 {
+		return "人数范围无效"
+	}
+	days, reason := parseDays(dayTokens)
+	if reason != "" {
+		return reason
+	}
+
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if !days[d.Weekday()] {
+			continue
+		}
+		date := d.Format("2006-01-02")
+		key := shift.ID.String() + "/" + date
+		if _, ok := p.base[key]; !ok {
+			p.order = append(p.order, key)
+		}
+		p.base[key] = &model.ShiftRequirement{
+			ShiftID:      shift.ID,
+			Date:         date,
+			MinEmployees: minCount,
+			MaxEmployees: maxCount,
+		}
+	}
+	return ""
+}
+
+// positionClause 解析岗位条目，返回错误原因
+func (p *parser) positionClause(m []string) (positionClause, string) {
+	pc := positionClause{position: m[1], shifts: make(map[string]bool)}
+	pc.min, _ = strconv.Atoi(m[2])
+	if pc.min == 0 {
+		return pc, "岗位人数应大于 0"
+	}
+	var dayTokens []string
+	for _, tok := range strings.Fields(m[3]) {
+		if everyShift[strings.ToLower(tok)] {
+			continue
+		}
+		if shift := p.shift(tok); shift != nil {
+			pc.shifts[shift.ID.String()] = true
+			continue
+		}
+		dayTokens = append(dayTokens, tok)
+	}
+	days, reason := parseDays(dayTokens)
+	pc.days = days
+	return pc, reason
+}
+
+// shift 按名称或编码查找班次
+func (p *parser) shift(name string) *model.Shift {
+	for _, s := range p.shifts {
+		if strings.EqualFold(s.Name, name) || (s.Code != "" && strings.EqualFold(s.Code, name)) {
+			return s
+		}
+	}
+	return nil
+}
+
+// parseDays 解析星期：分组关键字、单个星期、范围（fri-sun、周一至周五，可跨周末）和列表（mon,wed），多个取并集；
+// 为空表示每天
+func parseDays(tokens []string) (map[time.Weekday]bool, string) {
+	days := make(map[time.Weekday]bool)
+	if len(tokens) == 0 {
+		for _, d := range allDays() {
+			days[d] = true
+		}
+		return days, ""
+	}
+	for _, tok := range tokens {
+		for _, item := range listSeparator.Split(strings.ToLower(tok), -1) {
+			if item == "" {
+				continue
+			}
+			if group, ok := dayGroups[item]; ok {
+				for _, d := range group {
+					days[d] = true
+				}
+				continue
+			}
+			if d, ok := dayNames[item]; ok {
+				days[d] = true
+				continue
+			}
+			m := rangeSeparator.FindStringSubmatch(item)
+			if m == nil {
+				return nil, fmt.Sprintf("无法识别的星期 %q", item)
+			}
+			from, ok1 := dayNames[m[1]]
+			to, ok2 := dayNames[m[2]]
+			if !ok1 || !ok2 {
+				return nil, fmt.Sprintf("无法识别的星期范围 %q", item)
+			}
+			for d := from; ; d = (d + 1) % 7 {
+				days[d] = true
+				if d == to {
+					break
+				}
+			}
+		}
+	}
+	return days, ""
+}
+
+// allDays 返回一周七天
+func allDays() []time.Weekday {
+	return []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+}
@@ -0,0 +1,101 @@
+package reqspec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func testShifts() (*model.Shift, *model.Shift) {
+	morning := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "早班", Code: "M"}
+	evening := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "晚班", Code: "E"}
+	return morning, evening
+}
+
+func TestParse(t *testing.T) {
+	morning, evening := testShifts()
+	shifts := []*model.Shift{morning, evening}
+
+	// 2026-03-02 为周一，至 2026-03-08 周日
+	reqs, err := Parse("早班 weekdays 2-3人; 晚班 fri-sun 4人; 厨师≥1 every shift", shifts, "2026-03-02", "2026-03-08")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	type key struct {
+		shift    uuid.UUID
+		date     string
+		position string
+	}
+	got := make(map[key]*model.ShiftRequirement)
+	for _, r := range reqs {
+		got[key{r.ShiftID, r.Date, r.Position}] = r
+	}
+	// 早班 5 天 + 晚班 3 天，每个班次拆出厨师需求
+	if len(reqs) != 16 {
+		t.Fatalf("len(reqs) = %d, want 16", len(reqs))
+	}
+	if r := got[key{morning.ID, "2026-03-02", ""}]; r == nil || r.MinEmployees != 1 || r.MaxEmployees != 2 {
+		t.Errorf("周一早班不限岗位需求 = %+v", r)
+	}
+	if r := got[key{morning.ID, "2026-03-02", "厨师"}]; r == nil || r.MinEmployees != 1 || r.MaxEmployees != 1 {
+		t.Errorf("周一早班厨师需求 = %+v", r)
+	}
+	if r := got[key{evening.ID, "2026-03-08", ""}]; r == nil || r.MinEmployees != 3 || r.MaxEmployees != 3 {
+		t.Errorf("周日晚班不限岗位需求 = %+v", r)
+	}
+	if got[key{morning.ID, "2026-03-07", ""}] != nil || got[key{evening.ID, "2026-03-05", ""}] != nil {
+		t.Error("不应生成未指定星期的需求")
+	}
+}
+
+func TestParse_OverrideAndRestrictedPosition(t *testing.T) {
+	morning, evening := testShifts()
+	shifts := []*model.Shift{morning, evening}
+
+	spec := "M 每天 2人\n早班 周六,周日 1人；收银>=1 e 周末; e 3"
+	reqs, err := Parse(spec, shifts, "2026-03-06", "2026-03-08")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	counts := make(map[string]int)
+	for _, r := range reqs {
+		if r.ShiftID == morning.ID && r.Position == "" {
+			counts[r.Date] = r.MinEmployees
+		}
+		if r.Position == "收银" && (r.ShiftID != evening.ID || r.Date == "2026-03-06") {
+			t.Errorf("收银需求只应出现在周末晚班, got %+v", r)
+		}
+	}
+	if counts["2026-03-06"] != 2 || counts["2026-03-07"] != 1 || counts["2026-03-08"] != 1 {
+		t.Errorf("早班人数 = %v, 周末应被后面的条目覆盖", counts)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	morning, evening := testShifts()
+	shifts := []*model.Shift{morning, evening}
+
+	tests := []struct {
+		spec   string
+		clause int
+	}{
+		{"夜班 weekdays 2人", 1},
+		{"早班 weekdays", 1},
+		{"早班 2人; 晚班 someday 2人", 2},
+		{"早班 3-2人", 1},
+		{"早班 2人; 厨师≥0", 2},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.spec, shifts, "2026-03-02", "2026-03-08")
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Clause != tt.clause {
+			t.Errorf("Parse(%q) error = %v, want clause %d", tt.spec, err, tt.clause)
+		}
+	}
+	if _, err := Parse(" ; ", shifts, "2026-03-02", "2026-03-08"); err == nil {
+		t.Error("空简写应报错")
+	}
+}
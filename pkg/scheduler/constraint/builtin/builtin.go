@@ -2,45 +2,55 @@
 package builtin
 
 import (
+	"encoding/json"
 	"fmt"
 
+	"github.com/paiban/paiban/pkg/fatigue"
+	"github.com/paiban/paiban/pkg/model"
 	"github.com/paiban/paiban/pkg/scheduler/constraint"
 )
 
 // RegisterDefaultConstraints 注册默认约束到管理器
 func RegisterDefaultConstraints(manager *constraint.Manager, config map[string]interface{}) {
 	// 从配置中获取参数，使用默认值
-	maxHoursPerDay := getConfigInt(config, "max_hours_per_day", 10)
-	maxHoursPerWeek := getConfigInt(config, "max_hours_per_week", 44)
-	maxHoursPerPeriod := getConfigInt(config, "max_hours_per_period", 0) // 0表示不限制
 	maxShiftsPerMonth := getConfigInt(config, "max_shifts_per_month", 0) // 0表示不限制
-	minRestBetweenShifts := getConfigInt(config, "min_rest_between_shifts", 10)
-	maxConsecutiveDays := getConfigInt(config, "max_consecutive_days", 6)
 	standardHoursPerWeek := getConfigInt(config, "standard_hours_per_week", 40)
 	workloadBalanceWeight := getConfigInt(config, "workload_balance_weight", 60)
 	preferenceWeight := getConfigInt(config, "preference_weight", 50)
 	minimizeOvertimeWeight := getConfigInt(config, "minimize_overtime_weight", 70)
 	tolerancePercent := getConfigFloat(config, "workload_tolerance_percent", 20.0)
 
-	// 工时模式: "weekly"(按周) 或 "period"(按排班周期)
-	hoursMode := getConfigString(config, "hours_mode", "weekly")
-
 	// 注册硬约束
-	manager.Register(NewMaxHoursPerDayConstraint(maxHoursPerDay))
-
-	// 根据工时模式选择约束
-	if hoursMode == "period" && maxHoursPerPeriod > 0 {
-		// 按排班周期计算工时（适用于月度排班）
-		manager.Register(NewMaxHoursPerPeriodConstraint(maxHoursPerPeriod))
-	} else {
-		// 按周计算工时（默认模式）
-		manager.Register(NewMaxHoursPerWeekConstraint(maxHoursPerWeek))
+	for _, rule := range WorkingTimeRules(config) {
+		manager.Register(rule.Constraint)
 	}
-
-	manager.Register(NewMinRestBetweenShiftsConstraint(minRestBetweenShifts))
-	manager.Register(NewMaxConsecutiveDaysConstraint(maxConsecutiveDays))
 	manager.Register(NewMaxShiftsPerDayConstraint(1)) // 每天最多1个班次
 	manager.Register(NewSkillRequiredConstraint())
+	manager.Register(NewEmployeeUnavailableConstraint())
+	manager.Register(NewFixedShiftConstraint())
+
+	// 加班上限（配置了每日或每月加班上限时启用，如劳动法合规规则包）
+	maxOvertimeDaily := getConfigFloat(config, "max_overtime_hours_per_day", 0)
+	maxOvertimeMonthly := getConfigFloat(config, "max_overtime_hours_per_month", 0)
+	if maxOvertimeDaily > 0 || maxOvertimeMonthly > 0 {
+		manager.Register(NewOvertimeCapConstraint(getConfigFloat(config, "standard_hours_per_day", 8),
+			float64(standardHoursPerWeek), maxOvertimeDaily, maxOvertimeMonthly))
+	}
+
+	// 未成年工保护（配置了 minor_* 参数时启用）
+	if minor := ConfigMinorProtection(config); minor != nil {
+		manager.Register(minor)
+	}
+	manager.Register(NewCrossStoreTravelConstraint(ConfigStores(config),
+		getConfigInt(config, "cross_store_travel_minutes", DefaultCrossStoreTravelMinutes)))
+
+	// 班组（配置了 teams 时启用）：同组成员尽量安排在同一班次，cohesion 为 required 的班组必须同一班次
+	if teams := ConfigTeams(config); len(teams) > 0 {
+		manager.Register(NewTeamTogetherConstraint(getConfigInt(config, "team_together_weight", 70), TeamMembers(teams)))
+		if required := RequiredTeams(teams); len(required) > 0 {
+			manager.Register(NewTeamCohesionConstraint(required))
+		}
+	}
 
 	// 每月最大班次数约束（如果配置了）
 	if maxShiftsPerMonth > 0 {
@@ -56,10 +66,81 @@ func RegisterDefaultConstraints(manager *constraint.Manager, config map[string]i
 		}
 	}
 
+	// 门店每周工时预算（如果配置了）
+	// 模式: "soft"(默认，按超出小时数扣分) 或 "hard"(超预算直接拒绝)
+	if budgets := getConfigStoreBudgets(config, "store_hours_budgets"); len(budgets) > 0 {
+		hard := getConfigString(config, "store_hours_budget_mode", "soft") == "hard"
+		weight := getConfigInt(config, "store_hours_budget_weight", 80)
+		if hard {
+			weight = 100
+		}
+		manager.Register(NewStoreHoursBudgetConstraint(hard, weight, budgets))
+	}
+
+	// 人工成本预算（如果配置了每周或每月预算）
+	// 模式: "soft"(默认，按超出金额扣分) 或 "hard"(超预算直接拒绝)
+	weeklyCost := getConfigFloat(config, "max_labor_cost_per_week", 0)
+	monthlyCost := getConfigFloat(config, "max_labor_cost_per_month", 0)
+	if weeklyCost > 0 || monthlyCost > 0 {
+		hard := getConfigString(config, "max_labor_cost_mode", "soft") == "hard"
+		weight := getConfigInt(config, "max_labor_cost_weight", 80)
+		if hard {
+			weight = 100
+		}
+		manager.Register(NewLaborCostBudgetConstraint(hard, weight, weeklyCost, monthlyCost, ConfigCostCalculator(config)))
+	}
+
+	// 门店营业时间（如果配置了）
+	if hours := ConfigOpeningHours(config); len(hours) > 0 {
+		manager.Register(NewStoreOpeningHoursConstraint(hours))
+	}
+
+	// 每小时最低在岗人数（如果配置了）
+	if rules := ConfigHourlyStaffing(config); len(rules) > 0 {
+		manager.Register(NewHourlyCoverageConstraint(rules))
+	}
+
+	// 待命安排（shift_type 为 on_call 的班次）：每人每周最多待命次数（0 表示不限）、夜班次日不待命
+	manager.Register(NewOnCallConstraint(getConfigInt(config, "max_on_call_per_week", DefaultMaxOnCallPerWeek)))
+
+	// 行业资质要求（如果配置了场景）
+	if scenario := getConfigString(config, "certification_scenario", ""); scenario != "" {
+		manager.Register(newIndustryCertification(scenario, config))
+	}
+
 	// 注册软约束
 	manager.Register(NewWorkloadBalanceConstraint(workloadBalanceWeight, tolerancePercent))
 	manager.Register(NewEmployeePreferenceConstraint(preferenceWeight))
-	manager.Register(NewMinimizeOvertimeConstraint(minimizeOvertimeWeight, standardHoursPerWeek))
+	overtime := NewMinimizeOvertimeConstraint(minimizeOvertimeWeight, standardHoursPerWeek)
+	holidays := ConfigHolidays(config)
+	overtime.SetHolidays(holidays)
+	manager.Register(overtime)
+
+	// 节假日值班轮换（holiday_handling_weight 为 0 时不启用）
+	if holidayWeight := getConfigInt(config, "holiday_handling_weight", 80); holidayWeight > 0 {
+		manager.Register(NewHolidayStaffingConstraint(holidayWeight, holidays))
+	}
+
+	// 周间排班稳定（stability_weight 为 0 时不启用），与前 stability_weeks 周同一星期几的班次比较
+	if weeks := ConfigStabilityWeeks(config); weeks > 0 {
+		manager.Register(NewScheduleStabilityConstraint(getConfigInt(config, "stability_weight", 0), weeks))
+	}
+
+	// 周末/夜班分配公平（fairness_weight 为 0 时不启用），配置了排班周期时按每个周期分别统计
+	if fairnessWeight := getConfigInt(config, "fairness_weight", 0); fairnessWeight > 0 {
+		fairness := NewWorkloadFairnessConstraint(fairnessWeight, tolerancePercent)
+		if cycle, ok := ConfigCycle(config); ok {
+			fairness.SetCycle(cycle)
+		}
+		fairness.SetHolidays(holidays)
+		manager.Register(fairness)
+	}
+
+	// 疲劳指数（fatigue_weight 为 0 时不启用）
+	if fatigueWeight := getConfigInt(config, "fatigue_weight", 40); fatigueWeight > 0 {
+		threshold := getConfigFloat(config, "fatigue_threshold", 0)
+		manager.Register(NewFatigueConstraint(fatigueWeight, threshold, fatigue.Config{}))
+	}
 }
 
 // RegisterRestaurantConstraints 注册餐饮场景约束
@@ -68,7 +149,7 @@ func RegisterRestaurantConstraints(manager *constraint.Manager, config map[strin
 	RegisterDefaultConstraints(manager, config)
 
 	// 餐饮行业资质要求（健康证等）
-	manager.Register(NewIndustryCertificationConstraint("restaurant"))
+	manager.Register(newIndustryCertification("restaurant", config))
 
 	// 高峰期覆盖
 	peakHours := []string{"11:00-13:00", "17:00-20:00"}
@@ -85,6 +166,12 @@ func RegisterRestaurantConstraints(manager *constraint.Manager, config map[strin
 		allowSplit = allow
 	}
 	manager.Register(NewSplitShiftConstraint(60, maxSplitShifts, 3, allowSplit))
+
+	// 晚关早开限制（独立于班次间最小休息）
+	lateEnd := getConfigString(config, "clopening_late_end", "22:00")
+	earlyStart := getConfigString(config, "clopening_early_start", "10:00")
+	maxClopenings := getConfigInt(config, "max_clopenings_per_week", 0)
+	manager.Register(NewClopeningConstraint(100, lateEnd, earlyStart, maxClopenings))
 }
 
 // RegisterFactoryConstraints 注册工厂场景约束
@@ -93,16 +180,38 @@ func RegisterFactoryConstraints(manager *constraint.Manager, config map[string]i
 	RegisterDefaultConstraints(manager, config)
 
 	// 工厂特种作业资质要求
-	manager.Register(NewIndustryCertificationConstraint("factory"))
+	manager.Register(newIndustryCertification("factory", config))
 
-	// 倒班模式
+	// 倒班模式（配置了排班周期时默认每个周期轮换一次，轮换段内只上同一类班次）
 	pattern := getConfigString(config, "shift_rotation_pattern", "三班倒")
-	rotationDays := getConfigInt(config, "rotation_days", 7)
-	manager.Register(NewShiftRotationPatternConstraint(100, pattern, rotationDays))
+	cycle, hasCycle := ConfigCycle(config)
+	rotationDays := getConfigInt(config, "rotation_days", cycle.Days())
+	rotation := NewShiftRotationPatternConstraint(100, pattern, rotationDays)
+	if hasCycle {
+		rotation.SetCycle(cycle)
+	}
+	manager.Register(rotation)
 
 	// 最大连续夜班
 	maxNights := getConfigInt(config, "max_consecutive_nights", 4)
 	manager.Register(NewMaxConsecutiveNightsConstraint(maxNights))
+
+	// 夜班后休息（连续夜班达到 post_night_block_nights 天后休息 post_night_rest_days 天，设为 0 关闭）
+	blockNights := getConfigInt(config, "post_night_block_nights", 3)
+	restDays := getConfigInt(config, "post_night_rest_days", 2)
+	if restDays > 0 {
+		manager.Register(NewPostNightRestConstraint(blockNights, restDays))
+	}
+}
+
+// newIndustryCertification 按配置创建行业资质约束
+// require_verified_certifications 为 true 时要求证书已核验，而不只是登记了证书名称
+func newIndustryCertification(scenario string, config map[string]interface{}) *IndustryCertificationConstraint {
+	c := NewIndustryCertificationConstraint(scenario)
+	if verified, ok := config["require_verified_certifications"].(bool); ok {
+		c.SetRequireVerified(verified)
+	}
+	return c
 }
 
 // getConfigString 从配置中获取字符串
@@ -122,7 +231,7 @@ func RegisterHousekeepingConstraints(manager *constraint.Manager, config map[str
 	RegisterDefaultConstraints(manager, config)
 
 	// 家政行业资质要求（无犯罪证明等）
-	manager.Register(NewIndustryCertificationConstraint("housekeeping"))
+	manager.Register(newIndustryCertification("housekeeping", config))
 
 	// 家政特有约束
 	// 服务区域匹配（硬约束）
@@ -143,7 +252,7 @@ func RegisterNursingConstraints(manager *constraint.Manager, config map[string]i
 	RegisterDefaultConstraints(manager, config)
 
 	// 长护险资质要求（护理员证、无犯罪证明等）
-	manager.Register(NewIndustryCertificationConstraint("nursing"))
+	manager.Register(newIndustryCertification("nursing", config))
 
 	// 长护险特有约束
 	// 护理计划合规（硬约束）
@@ -225,3 +334,199 @@ func getConfigMonthlyLimits(config map[string]interface{}, key string) map[strin
 	
 	return result
 }
+
+// getConfigStoreBudgets 从配置中获取门店每周工时预算
+// 支持 { "store-a": 320, ... }（门店默认每周预算）或已解析的 []model.StoreHoursBudget
+func getConfigStoreBudgets(config map[string]interface{}, key string) []model.StoreHoursBudget {
+	if config == nil {
+		return nil
+	}
+	switch v := config[key].(type) {
+	case []model.StoreHoursBudget:
+		return v
+	case map[string]interface{}:
+		result := make([]model.StoreHoursBudget, 0, len(v))
+		for storeID, hours := range v {
+			switch h := hours.(type) {
+			case float64:
+				result = append(result, model.StoreHoursBudget{StoreID: storeID, Hours: h})
+			case int:
+				result = append(result, model.StoreHoursBudget{StoreID: storeID, Hours: float64(h)})
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// WorkingTimeRule 工时类硬性规则及其阈值（小时或天数）
+type WorkingTimeRule struct {
+	Constraint constraint.Constraint
+	Limit      int
+}
+
+// WorkingTimeRules 按配置返回工时类硬性规则：每日最长工时、每周（或每两周/四周轮班周期、按排班周期）最长工时、
+// 班次间最短休息和最多连续工作天数；排班约束注册和已发布排班的合规核查共用
+func WorkingTimeRules(config map[string]interface{}) []WorkingTimeRule {
+	maxHoursPerDay := getConfigInt(config, "max_hours_per_day", 10)
+	maxHoursPerWeek := getConfigInt(config, "max_hours_per_week", 44)
+	maxHoursPerPeriod := getConfigInt(config, "max_hours_per_period", 0) // 0表示不限制
+	minRestBetweenShifts := getConfigInt(config, "min_rest_between_shifts", 10)
+	maxConsecutiveDays := getConfigInt(config, "max_consecutive_days", 6)
+
+	// 工时模式: "weekly"(按周) 或 "period"(按排班周期)
+	hoursMode := getConfigString(config, "hours_mode", "weekly")
+
+	rules := []WorkingTimeRule{{NewMaxHoursPerDayConstraint(maxHoursPerDay), maxHoursPerDay}}
+	if hoursMode == "period" && maxHoursPerPeriod > 0 {
+		// 按排班周期计算工时（适用于月度排班）
+		rules = append(rules, WorkingTimeRule{NewMaxHoursPerPeriodConstraint(maxHoursPerPeriod), maxHoursPerPeriod})
+	} else if cycle, ok := ConfigCycle(config); ok && cycle.Weeks > 1 {
+		// 按两周/四周轮班周期计算工时，默认上限为周上限乘以周数（周期内各周可不均衡）
+		maxHoursPerCycle := getConfigInt(config, "max_hours_per_cycle", maxHoursPerWeek*cycle.Weeks)
+		rules = append(rules, WorkingTimeRule{NewMaxHoursPerCycleConstraint(maxHoursPerCycle, cycle), maxHoursPerCycle})
+	} else {
+		// 按周计算工时（默认模式）
+		rules = append(rules, WorkingTimeRule{NewMaxHoursPerWeekConstraint(maxHoursPerWeek), maxHoursPerWeek})
+	}
+	return append(rules,
+		WorkingTimeRule{NewMinRestBetweenShiftsConstraint(minRestBetweenShifts), minRestBetweenShifts},
+		WorkingTimeRule{NewMaxConsecutiveDaysConstraint(maxConsecutiveDays), maxConsecutiveDays},
+	)
+}
+
+// ConfigCycle 从配置的 "cycle_weeks"（1/2/4）和 "cycle_anchor_date" 中获取排班周期
+// 未配置 cycle_weeks 或配置不合法时返回按周的默认周期和 false
+func ConfigCycle(config map[string]interface{}) (model.ScheduleCycle, bool) {
+	cycle := model.ScheduleCycle{
+		Weeks:      getConfigInt(config, "cycle_weeks", 0),
+		AnchorDate: getConfigString(config, "cycle_anchor_date", ""),
+	}
+	if cycle.Weeks == 0 || cycle.Validate() != nil {
+		return model.ScheduleCycle{Weeks: 1}, false
+	}
+	return cycle, true
+}
+
+// WithCycle 配置未指定 cycle_weeks 时补充排班周期（如组织的排班周期）
+// 返回新的配置，不修改原配置；cycle 为空时原样返回
+func WithCycle(config map[string]interface{}, cycle *model.ScheduleCycle) map[string]interface{} {
+	if cycle == nil {
+		return config
+	}
+	if _, ok := config["cycle_weeks"]; ok {
+		return config
+	}
+	merged := make(map[string]interface{}, len(config)+2)
+	for k, v := range config {
+		merged[k] = v
+	}
+	merged["cycle_weeks"] = cycle.Weeks
+	if cycle.AnchorDate != "" {
+		merged["cycle_anchor_date"] = cycle.AnchorDate
+	}
+	return merged
+}
+
+// ConfigStabilityWeeks 返回周间稳定性约束比较的周数，未启用（stability_weight 为 0）时返回 0
+func ConfigStabilityWeeks(config map[string]interface{}) int {
+	if getConfigInt(config, "stability_weight", 0) <= 0 {
+		return 0
+	}
+	if weeks := getConfigInt(config, "stability_weeks", constraint.DefaultStabilityWeeks); weeks > 0 {
+		return weeks
+	}
+	return constraint.DefaultStabilityWeeks
+}
+
+// ConfigOpeningHours 从配置的 "store_opening_hours" 中获取门店营业时间
+// 支持已解析的 []model.StoreOpeningHours 或 JSON 数组（与营业时间接口格式相同）
+func ConfigOpeningHours(config map[string]interface{}) []model.StoreOpeningHours {
+	if config == nil {
+		return nil
+	}
+	switch v := config["store_opening_hours"].(type) {
+	case []model.StoreOpeningHours:
+		return v
+	case []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var result []model.StoreOpeningHours
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil
+		}
+		return result
+	}
+	return nil
+}
+
+// ConfigStores 从配置的 "stores" 中获取多门店排班的门店列表
+// 支持已解析的 []model.Store 或 JSON 数组（与生成排班请求的 stores 格式相同）
+func ConfigStores(config map[string]interface{}) []model.Store {
+	if config == nil {
+		return nil
+	}
+	switch v := config["stores"].(type) {
+	case []model.Store:
+		return v
+	case []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var result []model.Store
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil
+		}
+		return result
+	}
+	return nil
+}
+
+// ConfigTeams 从配置的 "teams" 中获取班组
+// 支持已解析的 []model.Team 或 JSON 数组（与生成排班请求的 teams 格式相同）
+func ConfigTeams(config map[string]interface{}) []model.Team {
+	if config == nil {
+		return nil
+	}
+	switch v := config["teams"].(type) {
+	case []model.Team:
+		return v
+	case []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var result []model.Team
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil
+		}
+		return result
+	}
+	return nil
+}
+
+// ConfigHourlyStaffing 从配置的 "hourly_staffing" 中获取每小时最低在岗人数要求
+// 支持已解析的 []model.HourlyStaffing 或 JSON 数组（如 [{"start": "10:00", "end": "22:00", "min_staff": 2}]）
+func ConfigHourlyStaffing(config map[string]interface{}) []model.HourlyStaffing {
+	if config == nil {
+		return nil
+	}
+	switch v := config["hourly_staffing"].(type) {
+	case []model.HourlyStaffing:
+		return v
+	case []interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var result []model.HourlyStaffing
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil
+		}
+		return result
+	}
+	return nil
+}
@@ -40,6 +40,7 @@ type IndustryCertificationConstraint struct {
 	*BaseConstraint
 	scenario         string              // 场景: restaurant/housekeeping/nursing/factory
 	certRequirements map[string][]string // 岗位 -> 所需证书列表
+	requireVerified  bool                // 是否要求证书已核验（证书材料审核通过且在有效期内）
 }
 
 // NewIndustryCertificationConstraint 创建行业资质约束
@@ -112,6 +113,20 @@ func (c *IndustryCertificationConstraint) AddCertRequirement(position string, ce
 	c.certRequirements[position] = certs
 }
 
+// SetRequireVerified 设置是否要求证书已核验
+// 开启后仅登记证书名称不再满足要求，证书须在排班日期已核验且未过期
+func (c *IndustryCertificationConstraint) SetRequireVerified(required bool) {
+	c.requireVerified = required
+}
+
+// hasCert 检查员工在指定日期是否满足证书要求（证书须在有效期内）
+func (c *IndustryCertificationConstraint) hasCert(emp *model.Employee, cert, date string) bool {
+	if c.requireVerified {
+		return emp.HasVerifiedCertification(cert, date)
+	}
+	return emp.HasCertificationOn(cert, date)
+}
+
 // Evaluate 评估整个排班
 func (c *IndustryCertificationConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
 	var violations []constraint.ViolationDetail
@@ -135,10 +150,16 @@ func (c *IndustryCertificationConstraint) Evaluate(ctx *constraint.Context) (boo
 
 		// 检查员工是否持有所有必需证书
 		for _, cert := range requiredCerts {
-			if !emp.HasCertification(cert) {
+			if !c.hasCert(emp, cert, a.Date) {
 				isValid = false
 				penalty := c.Weight()
 				totalPenalty += penalty
+				reason := "缺少必需证书"
+				if c.requireVerified && emp.HasCertification(cert) {
+					reason = "证书未核验或已过期"
+				} else if emp.HasCertification(cert) {
+					reason = "证书已过期"
+				}
 
 				violations = append(violations, constraint.ViolationDetail{
 					ConstraintType: c.Type(),
@@ -146,8 +167,8 @@ func (c *IndustryCertificationConstraint) Evaluate(ctx *constraint.Context) (boo
 					EmployeeID:     emp.ID,
 					Date:           a.Date,
 					Message: fmt.Sprintf(
-						"[%s场景] 员工 %s 岗位 '%s' 缺少必需证书: %s",
-						c.getScenarioName(), emp.Name, position, cert,
+						"[%s场景] 员工 %s 岗位 '%s' %s: %s",
+						c.getScenarioName(), emp.Name, position, reason, cert,
 					),
 					Severity: "error",
 					Penalty:  penalty,
@@ -202,7 +223,7 @@ func (c *IndustryCertificationConstraint) EvaluateAssignment(ctx *constraint.Con
 
 	requiredCerts := c.getRequiredCerts(position)
 	for _, cert := range requiredCerts {
-		if !emp.HasCertification(cert) {
+		if !c.hasCert(emp, cert, a.Date) {
 			return false, c.Weight()
 		}
 	}
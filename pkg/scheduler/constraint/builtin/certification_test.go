@@ -0,0 +1,36 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestIndustryCertificationConstraint_Expiry(t *testing.T) {
+	c := NewIndustryCertificationConstraint("restaurant")
+	before := createAssignmentOnDate("2024-01-16", 8)
+	after := createAssignmentOnDate("2024-01-18", 8)
+	ctx := createTestContext([]*model.Assignment{before, after})
+	emp := ctx.Employees[0]
+	emp.Position = "服务员"
+	emp.CertificationRecords = []model.Certification{{Name: CertHealthCard, ExpiresAt: "2024-01-17"}}
+
+	if ok, _ := c.EvaluateAssignment(ctx, before); !ok {
+		t.Error("有效期内的证书应满足要求")
+	}
+	if ok, _ := c.EvaluateAssignment(ctx, after); ok {
+		t.Error("证书过期后的分配应被拒绝")
+	}
+	valid, _, violations := c.Evaluate(ctx)
+	if valid || len(violations) != 1 || violations[0].Date != "2024-01-18" || !strings.Contains(violations[0].Message, "证书已过期") {
+		t.Fatalf("应只有过期后的一项违规: valid=%v violations=%v", valid, violations)
+	}
+
+	// 只在 certifications 中登记的证书视为长期有效
+	emp.CertificationRecords = nil
+	emp.Certifications = []string{CertHealthCard}
+	if valid, _, _ := c.Evaluate(ctx); !valid {
+		t.Error("未登记有效期的证书应视为长期有效")
+	}
+}
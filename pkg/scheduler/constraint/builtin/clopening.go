@@ -0,0 +1,218 @@
+package builtin
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// ClopeningConstraint 晚关早开约束（睡眠保护）
+// 员工前一天上晚班（下班晚于 lateEndAfter 或跨过午夜）、次日又上早班（上班早于 earlyStartBefore）
+// 记为一次"晚关早开"。与班次间最小休息不同，即使休息小时数满足要求也会计数，
+// 每周（ISO 周，按早班日期计）超过 maxPerWeek 次即违反
+type ClopeningConstraint struct {
+	*BaseConstraint
+	lateEndAfter     int // 晚班下班时间阈值（自零点起的分钟数）
+	earlyStartBefore int // 早班上班时间阈值（自零点起的分钟数）
+	maxPerWeek       int // 每周最多次数
+}
+
+// clopening 一次晚关早开
+type clopening struct {
+	late  *model.Assignment
+	early *model.Assignment
+	week  string
+}
+
+// NewClopeningConstraint 创建晚关早开约束
+// lateEndAfter/earlyStartBefore 格式为 "HH:MM"，格式无效时分别使用 22:00 和 10:00
+func NewClopeningConstraint(weight int, lateEndAfter, earlyStartBefore string, maxPerWeek int) *ClopeningConstraint {
+	if maxPerWeek < 0 {
+		maxPerWeek = 0
+	}
+	return &ClopeningConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"晚关早开限制",
+			constraint.TypeClopening,
+			constraint.CategoryHard,
+			weight,
+		),
+		lateEndAfter:     parseClockMinutes(lateEndAfter, 22*60),
+		earlyStartBefore: parseClockMinutes(earlyStartBefore, 10*60),
+		maxPerWeek:       maxPerWeek,
+	}
+}
+
+// Evaluate 评估整个排班
+func (c *ClopeningConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+	isValid := true
+
+	for _, emp := range ctx.Employees {
+		// 含上一期的固定历史分配，跨周期的同一周合并计数
+		found := c.find(ctx.GetEmployeeTimeline(emp.ID))
+		if len(found) == 0 {
+			continue
+		}
+
+		byWeek := make(map[string][]clopening)
+		weeks := make([]string, 0)
+		for _, cl := range found {
+			if _, ok := byWeek[cl.week]; !ok {
+				weeks = append(weeks, cl.week)
+			}
+			byWeek[cl.week] = append(byWeek[cl.week], cl)
+		}
+		sort.Strings(weeks)
+
+		for _, week := range weeks {
+			list := byWeek[week]
+			if len(list) <= c.maxPerWeek || ctx.IsHistory(list[len(list)-1].early) {
+				continue
+			}
+			isValid = false
+			// 超出限额的每一次都单独报告，便于定位具体日期
+			for _, cl := range list[c.maxPerWeek:] {
+				if ctx.IsHistory(cl.early) {
+					continue
+				}
+				penalty := c.Weight()
+				totalPenalty += penalty
+				violations = append(violations, constraint.ViolationDetail{
+					ConstraintType: c.Type(),
+					ConstraintName: c.Name(),
+					EmployeeID:     emp.ID,
+					Date:           cl.early.Date,
+					Message: fmt.Sprintf(
+						"员工 %s 于 %s %s 下班后次日 %s 上班（晚关早开），本周共 %d 次，超过限制 %d 次",
+						emp.Name, cl.late.Date, cl.late.EndTime.Format("15:04"),
+						cl.early.StartTime.Format("15:04"), len(list), c.maxPerWeek,
+					),
+					Severity: "error",
+					Penalty:  penalty,
+				})
+			}
+		}
+	}
+
+	return isValid, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+func (c *ClopeningConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	existing := ctx.GetEmployeeTimeline(a.EmployeeID)
+	assignments := make([]*model.Assignment, 0, len(existing)+1)
+	for _, e := range existing {
+		if e.ID != a.ID {
+			assignments = append(assignments, e)
+		}
+	}
+	assignments = append(assignments, a)
+
+	found := c.find(assignments)
+	weekCount := make(map[string]int)
+	for _, cl := range found {
+		weekCount[cl.week]++
+	}
+
+	excess := 0
+	for _, cl := range found {
+		if cl.late != a && cl.early != a {
+			continue
+		}
+		if n := weekCount[cl.week] - c.maxPerWeek; n > excess {
+			excess = n
+		}
+	}
+	if excess > 0 {
+		return false, c.Weight() * excess
+	}
+	return true, 0
+}
+
+// find 找出员工分配中的晚关早开（按早班日期排序）
+func (c *ClopeningConstraint) find(assignments []*model.Assignment) []clopening {
+	if len(assignments) < 2 {
+		return nil
+	}
+
+	byDate := make(map[string][]*model.Assignment)
+	for _, a := range assignments {
+		byDate[a.Date] = append(byDate[a.Date], a)
+	}
+
+	var result []clopening
+	for _, late := range assignments {
+		if !c.isLate(late) {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", late.Date)
+		if err != nil {
+			continue
+		}
+		next := day.AddDate(0, 0, 1)
+
+		// 次日最早的一个早班
+		var early *model.Assignment
+		for _, a := range byDate[next.Format("2006-01-02")] {
+			if a == late || !c.isEarly(a) || a.StartTime.Before(late.EndTime) {
+				continue
+			}
+			if early == nil || a.StartTime.Before(early.StartTime) {
+				early = a
+			}
+		}
+		if early == nil {
+			continue
+		}
+
+		year, week := next.ISOWeek()
+		result = append(result, clopening{
+			late:  late,
+			early: early,
+			week:  fmt.Sprintf("%d-W%02d", year, week),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].early.StartTime.Before(result[j].early.StartTime)
+	})
+	return result
+}
+
+// isLate 班次是否为晚班：下班时间不早于阈值，或下班时已跨过午夜
+func (c *ClopeningConstraint) isLate(a *model.Assignment) bool {
+	if a.EndTime.IsZero() {
+		return false
+	}
+	if a.EndTime.Format("2006-01-02") > a.Date {
+		return true
+	}
+	return clockMinutes(a.EndTime) >= c.lateEndAfter
+}
+
+// isEarly 班次是否为早班：上班时间早于阈值
+func (c *ClopeningConstraint) isEarly(a *model.Assignment) bool {
+	if a.StartTime.IsZero() {
+		return false
+	}
+	return clockMinutes(a.StartTime) < c.earlyStartBefore
+}
+
+// clockMinutes 返回时间自零点起的分钟数
+func clockMinutes(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+// parseClockMinutes 解析 "HH:MM" 为自零点起的分钟数
+func parseClockMinutes(s string, defaultVal int) int {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return defaultVal
+	}
+	return clockMinutes(t)
+}
@@ -0,0 +1,119 @@
+package builtin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestClopeningConstraint_Evaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxPerWeek  int
+		assignments []*model.Assignment
+		wantValid   bool
+		wantPenalty int
+	}{
+		{
+			name:       "晚班后次日正常班，应通过",
+			maxPerWeek: 0,
+			assignments: []*model.Assignment{
+				createAssignmentWithTime("2024-01-15", "16:00", "23:00"),
+				createAssignmentWithTime("2024-01-16", "12:00", "20:00"),
+			},
+			wantValid:   true,
+			wantPenalty: 0,
+		},
+		{
+			name:       "晚关早开，休息时间满足仍应失败",
+			maxPerWeek: 0,
+			assignments: []*model.Assignment{
+				createAssignmentWithTime("2024-01-15", "14:00", "22:30"),
+				createAssignmentWithTime("2024-01-16", "09:00", "15:00"), // 间隔10.5小时
+			},
+			wantValid:   false,
+			wantPenalty: 100,
+		},
+		{
+			name:       "跨午夜晚班后次日早班",
+			maxPerWeek: 0,
+			assignments: []*model.Assignment{
+				createOvernightAssignment("2024-01-15", "18:00", "01:00"),
+				createAssignmentWithTime("2024-01-16", "09:30", "14:00"),
+			},
+			wantValid:   false,
+			wantPenalty: 100,
+		},
+		{
+			name:       "每周限额内，应通过",
+			maxPerWeek: 1,
+			assignments: []*model.Assignment{
+				createAssignmentWithTime("2024-01-15", "14:00", "23:00"),
+				createAssignmentWithTime("2024-01-16", "08:00", "14:00"),
+			},
+			wantValid:   true,
+			wantPenalty: 0,
+		},
+		{
+			name:       "超出每周限额",
+			maxPerWeek: 1,
+			assignments: []*model.Assignment{
+				createAssignmentWithTime("2024-01-15", "14:00", "23:00"),
+				createAssignmentWithTime("2024-01-16", "08:00", "14:00"),
+				createAssignmentWithTime("2024-01-17", "15:00", "22:00"),
+				createAssignmentWithTime("2024-01-18", "07:00", "13:00"),
+			},
+			wantValid:   false,
+			wantPenalty: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClopeningConstraint(100, "22:00", "10:00", tt.maxPerWeek)
+			ctx := createTestContext(tt.assignments)
+
+			valid, penalty, _ := c.Evaluate(ctx)
+			if valid != tt.wantValid {
+				t.Errorf("Evaluate() valid = %v, want %v", valid, tt.wantValid)
+			}
+			if penalty != tt.wantPenalty {
+				t.Errorf("Evaluate() penalty = %d, want %d", penalty, tt.wantPenalty)
+			}
+		})
+	}
+}
+
+func TestClopeningConstraint_EvaluateAssignment(t *testing.T) {
+	late := createAssignmentWithTime("2024-01-15", "14:00", "23:00")
+	ctx := createTestContext([]*model.Assignment{late})
+	c := NewClopeningConstraint(100, "22:00", "10:00", 0)
+
+	early := createAssignmentWithTime("2024-01-16", "08:00", "14:00")
+	early.EmployeeID = late.EmployeeID
+	if ok, _ := c.EvaluateAssignment(ctx, early); ok {
+		t.Error("次日早班应被拒绝")
+	}
+
+	noon := createAssignmentWithTime("2024-01-16", "11:00", "17:00")
+	noon.EmployeeID = late.EmployeeID
+	if ok, _ := c.EvaluateAssignment(ctx, noon); !ok {
+		t.Error("次日午班应被允许")
+	}
+}
+
+func createOvernightAssignment(date, start, end string) *model.Assignment {
+	startTime, _ := time.Parse("2006-01-02 15:04", date+" "+start)
+	endTime, _ := time.Parse("2006-01-02 15:04", date+" "+end)
+	endTime = endTime.AddDate(0, 0, 1)
+
+	return &model.Assignment{
+		BaseModel: model.BaseModel{ID: uuid.New()},
+		Date:      date,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Status:    "scheduled",
+	}
+}
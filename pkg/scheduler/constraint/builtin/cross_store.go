@@ -0,0 +1,148 @@
+package builtin
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// DefaultCrossStoreTravelMinutes 门店间未配置通勤时间时的默认跨店通勤时间（分钟）
+const DefaultCrossStoreTravelMinutes = 60
+
+// CrossStoreTravelConstraint 跨店通勤约束（硬约束）
+// 多门店排班时员工只能排到所属门店或可支援门店；同一员工相邻两个班次在不同门店时，
+// 前一班下班到后一班上班的间隔须不少于两店之间的通勤时间
+type CrossStoreTravelConstraint struct {
+	*BaseConstraint
+	stores         []model.Store
+	defaultMinutes int
+}
+
+// NewCrossStoreTravelConstraint 创建跨店通勤约束
+// defaultMinutes 为门店间未配置通勤时间时使用的通勤时间
+func NewCrossStoreTravelConstraint(stores []model.Store, defaultMinutes int) *CrossStoreTravelConstraint {
+	if defaultMinutes < 0 {
+		defaultMinutes = 0
+	}
+	return &CrossStoreTravelConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"跨店通勤",
+			constraint.TypeCrossStoreTravel,
+			constraint.CategoryHard,
+			100,
+		),
+		stores:         stores,
+		defaultMinutes: defaultMinutes,
+	}
+}
+
+// Evaluate 评估整个排班
+func (c *CrossStoreTravelConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, emp := range ctx.Employees {
+		assignments := ctx.GetEmployeeAssignments(emp.ID)
+		for _, a := range assignments {
+			if emp.CanWorkAt(a.StoreID) {
+				continue
+			}
+			totalPenalty += c.Weight()
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				EmployeeID:     emp.ID,
+				Date:           a.Date,
+				Message: fmt.Sprintf("员工 %s 不可在门店 %s 上班（%s）",
+					emp.Name, model.StoreName(c.stores, a.StoreID), a.Date),
+				Severity: "error",
+				Penalty:  c.Weight(),
+			})
+		}
+
+		// 含上一期的固定历史分配，跨周期的相邻班次同样需要留出通勤时间
+		timeline := append([]*model.Assignment(nil), ctx.GetEmployeeTimeline(emp.ID)...)
+		sort.Slice(timeline, func(i, j int) bool {
+			return timeline[i].StartTime.Before(timeline[j].StartTime)
+		})
+		for i := 1; i < len(timeline); i++ {
+			prev, next := timeline[i-1], timeline[i]
+			if !isCurrent(assignments, prev) && !isCurrent(assignments, next) {
+				continue
+			}
+			from, to := prev.Store(emp), next.Store(emp)
+			gap, travel, ok := c.shortfall(prev, next, from, to)
+			if ok {
+				continue
+			}
+			totalPenalty += c.Weight()
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				EmployeeID:     emp.ID,
+				Date:           next.Date,
+				Message: fmt.Sprintf("员工 %s 从门店 %s 到门店 %s 需通勤 %d 分钟，%s 下班到 %s 上班仅间隔 %d 分钟",
+					emp.Name, model.StoreName(c.stores, from), model.StoreName(c.stores, to), travel,
+					prev.EndTime.Format("01-02 15:04"), next.StartTime.Format("01-02 15:04"), gap),
+				Severity: "error",
+				Penalty:  c.Weight(),
+			})
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+func (c *CrossStoreTravelConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	emp := ctx.GetEmployee(a.EmployeeID)
+	if emp == nil {
+		return true, 0
+	}
+	if !emp.CanWorkAt(a.StoreID) {
+		return false, c.Weight()
+	}
+	store := a.Store(emp)
+	for _, existing := range ctx.GetEmployeeTimeline(a.EmployeeID) {
+		if existing.ID == a.ID {
+			continue
+		}
+		other := existing.Store(emp)
+		var ok bool
+		if existing.StartTime.Before(a.StartTime) {
+			_, _, ok = c.shortfall(existing, a, other, store)
+		} else {
+			_, _, ok = c.shortfall(a, existing, store, other)
+		}
+		if !ok {
+			return false, c.Weight()
+		}
+	}
+	return true, 0
+}
+
+// shortfall 检查相邻两个班次之间是否留足跨店通勤时间，返回间隔分钟数和所需通勤分钟数
+// 同店、未指定门店或两个班次时间重叠（由每日班次数等约束处理）时视为满足
+func (c *CrossStoreTravelConstraint) shortfall(prev, next *model.Assignment, from, to string) (int, int, bool) {
+	if from == "" || to == "" || from == to {
+		return 0, 0, true
+	}
+	gap := int(next.StartTime.Sub(prev.EndTime).Minutes())
+	if gap < 0 {
+		return gap, 0, true
+	}
+	travel := model.StoreTravelMinutes(c.stores, from, to, c.defaultMinutes)
+	return gap, travel, gap >= travel
+}
+
+// isCurrent 分配是否属于本期排班（而非固定历史分配）
+func isCurrent(assignments []*model.Assignment, a *model.Assignment) bool {
+	for _, x := range assignments {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
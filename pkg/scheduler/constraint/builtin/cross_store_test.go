@@ -0,0 +1,57 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestCrossStoreTravelConstraint(t *testing.T) {
+	stores := []model.Store{
+		{ID: "A", Name: "东门店", TravelMinutes: map[string]int{"B": 30}},
+		{ID: "B", Name: "西门店"},
+		{ID: "C", Name: "南门店"},
+	}
+	c := NewCrossStoreTravelConstraint(stores, DefaultCrossStoreTravelMinutes)
+
+	morning := createAssignmentWithTime("2024-01-15", "08:00", "12:00")
+	morning.StoreID = "A"
+	ctx := createTestContext([]*model.Assignment{morning})
+	emp := ctx.Employees[0]
+	emp.StoreID = "A"
+	emp.AllowedStores = []string{"B", "C"}
+
+	// A→B 通勤 30 分钟（反向同样适用），间隔 30 分钟可以
+	afternoon := createAssignmentWithTime("2024-01-15", "12:30", "16:00")
+	afternoon.EmployeeID, afternoon.StoreID = emp.ID, "B"
+	if ok, _ := c.EvaluateAssignment(ctx, afternoon); !ok {
+		t.Error("间隔不少于通勤时间的跨店班次应通过")
+	}
+
+	// A→C 未配置，按默认 60 分钟
+	evening := createAssignmentWithTime("2024-01-15", "12:30", "16:00")
+	evening.EmployeeID, evening.StoreID = emp.ID, "C"
+	if ok, _ := c.EvaluateAssignment(ctx, evening); ok {
+		t.Error("间隔少于默认通勤时间的跨店班次应被拒绝")
+	}
+
+	// 不在所属门店和可支援门店内
+	emp.AllowedStores = []string{"B"}
+	evening.StartTime = evening.StartTime.Add(4 * time.Hour)
+	evening.EndTime = evening.EndTime.Add(4 * time.Hour)
+	if ok, _ := c.EvaluateAssignment(ctx, evening); ok {
+		t.Error("不可支援的门店应被拒绝")
+	}
+
+	ctx.SetAssignments([]*model.Assignment{morning, evening})
+	evening.StartTime = morning.EndTime.Add(10 * time.Minute)
+	valid, penalty, violations := c.Evaluate(ctx)
+	if valid || len(violations) != 2 || penalty != 2*c.Weight() {
+		t.Fatalf("应有门店不可支援和通勤不足两项违规: valid=%v penalty=%d violations=%v", valid, penalty, violations)
+	}
+	if !strings.Contains(violations[0].Message, "南门店") || !strings.Contains(violations[1].Message, "需通勤 60 分钟") {
+		t.Errorf("违规说明 = %s / %s", violations[0].Message, violations[1].Message)
+	}
+}
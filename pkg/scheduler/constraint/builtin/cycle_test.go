@@ -0,0 +1,159 @@
+package builtin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// twoWeekCycle 2026-03-02（周一）起每两周一期
+var twoWeekCycle = model.ScheduleCycle{Weeks: 2, AnchorDate: "2026-03-02"}
+
+// cycleDate 返回 2026-03-02 之后第 offset 天
+func cycleDate(offset int) string {
+	return time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC).AddDate(0, 0, offset).Format("2006-01-02")
+}
+
+func newCycleContext(endOffset int, employees []*model.Employee, shifts []*model.Shift, assignments []*model.Assignment) *constraint.Context {
+	ctx := constraint.NewContext(uuid.New(), cycleDate(0), cycleDate(endOffset))
+	ctx.SetEmployees(employees)
+	ctx.SetShifts(shifts)
+	ctx.SetAssignments(assignments)
+	return ctx
+}
+
+func TestMaxHoursPerCycleConstraint(t *testing.T) {
+	emp := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "张三"}
+	// 第一周 5 天 x 10 小时 = 50，第二周 4 天 x 9.5 小时 = 38
+	var assignments []*model.Assignment
+	for i := 0; i < 5; i++ {
+		a := createAssignmentOnDate(cycleDate(i), 10)
+		a.EmployeeID = emp.ID
+		assignments = append(assignments, a)
+	}
+	for i := 7; i < 11; i++ {
+		a := createAssignmentWithTime(cycleDate(i), "09:00", "18:30")
+		a.EmployeeID = emp.ID
+		assignments = append(assignments, a)
+	}
+	ctx := newCycleContext(13, []*model.Employee{emp}, nil, assignments)
+
+	if valid, _, _ := NewMaxHoursPerWeekConstraint(44).Evaluate(ctx); valid {
+		t.Error("按周统计第一周 50 小时应超过 44 小时")
+	}
+	c := NewMaxHoursPerCycleConstraint(88, twoWeekCycle)
+	if valid, _, violations := c.Evaluate(ctx); !valid {
+		t.Errorf("两周 88 小时应满足周期上限，violations = %+v", violations)
+	}
+
+	// 周期内再加一天 8 小时超过 88
+	extra := createAssignmentOnDate(cycleDate(12), 8)
+	extra.EmployeeID = emp.ID
+	if valid, penalty := c.EvaluateAssignment(ctx, extra); valid || penalty == 0 {
+		t.Errorf("EvaluateAssignment() = %v, %d, want 超出周期上限", valid, penalty)
+	}
+	// 下一个周期从 0 开始累计
+	next := createAssignmentOnDate(cycleDate(14), 8)
+	next.EmployeeID = emp.ID
+	if valid, _ := c.EvaluateAssignment(ctx, next); !valid {
+		t.Error("下一个周期的分配不应计入本周期工时")
+	}
+}
+
+func TestShiftRotationPatternConstraint_Segments(t *testing.T) {
+	emp := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "张三"}
+	morning := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "早班", ShiftType: "morning"}
+	night := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "夜班", ShiftType: "night"}
+	assign := func(offset int, shift *model.Shift) *model.Assignment {
+		a := createAssignmentOnDate(cycleDate(offset), 8)
+		a.EmployeeID, a.ShiftID = emp.ID, shift.ID
+		return a
+	}
+
+	c := NewShiftRotationPatternConstraint(100, "两班倒", 14)
+	c.SetCycle(twoWeekCycle)
+
+	// 前两周早班、后两周夜班：每个轮换段内班次一致
+	ctx := newCycleContext(27, []*model.Employee{emp}, []*model.Shift{morning, night},
+		[]*model.Assignment{assign(0, morning), assign(8, morning), assign(15, night), assign(20, night)})
+	if valid, _, violations := c.Evaluate(ctx); !valid {
+		t.Errorf("按段轮换应通过，violations = %+v", violations)
+	}
+
+	// 第一个段内早班和夜班混排
+	ctx = newCycleContext(27, []*model.Employee{emp}, []*model.Shift{morning, night},
+		[]*model.Assignment{assign(0, morning), assign(10, night)})
+	valid, _, violations := c.Evaluate(ctx)
+	if valid || len(violations) != 1 || violations[0].Date != cycleDate(10) {
+		t.Errorf("段内混排应失败，got valid=%v, violations = %+v", valid, violations)
+	}
+	if ok, _ := c.EvaluateAssignment(ctx, assign(12, night)); ok {
+		t.Error("EvaluateAssignment() 段内混排应失败")
+	}
+
+	// 未设置周期时不检查轮换段
+	plain := NewShiftRotationPatternConstraint(100, "两班倒", 14)
+	if valid, _, _ := plain.Evaluate(ctx); !valid {
+		t.Error("未设置周期时不应检查轮换段")
+	}
+}
+
+func TestWorkloadFairnessConstraint_CycleWindows(t *testing.T) {
+	a := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "张三"}
+	b := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "李四"}
+	// 周末：第 5、6、12、13、19、20、26、27 天；张三上前两周的周末，李四上后两周的周末
+	var assignments []*model.Assignment
+	for _, offset := range []int{5, 6, 12, 13} {
+		x := createAssignmentOnDate(cycleDate(offset), 8)
+		x.EmployeeID = a.ID
+		assignments = append(assignments, x)
+	}
+	for _, offset := range []int{19, 20, 26, 27} {
+		x := createAssignmentOnDate(cycleDate(offset), 8)
+		x.EmployeeID = b.ID
+		assignments = append(assignments, x)
+	}
+	ctx := newCycleContext(27, []*model.Employee{a, b}, nil, assignments)
+
+	c := NewWorkloadFairnessConstraint(60, 20)
+	if violations, _ := c.evaluateWeekendFairness(ctx); len(violations) != 0 {
+		t.Errorf("整个排班期周末天数相同，violations = %+v", violations)
+	}
+
+	c.SetCycle(twoWeekCycle)
+	violations, penalty := c.evaluateWeekendFairness(ctx)
+	if len(violations) != 4 || penalty == 0 {
+		t.Fatalf("每个周期内周末分配不均，violations = %+v", violations)
+	}
+	if violations[0].Date != cycleDate(0) || violations[2].Date != cycleDate(14) {
+		t.Errorf("violations = %+v", violations)
+	}
+}
+
+func TestConfigCycle(t *testing.T) {
+	if cycle, ok := ConfigCycle(nil); ok || cycle.Weeks != 1 {
+		t.Errorf("ConfigCycle(nil) = %+v, %v", cycle, ok)
+	}
+	if _, ok := ConfigCycle(map[string]interface{}{"cycle_weeks": 3.0}); ok {
+		t.Error("3 周周期不合法")
+	}
+
+	config := WithCycle(map[string]interface{}{"max_hours_per_week": 44}, &twoWeekCycle)
+	cycle, ok := ConfigCycle(config)
+	if !ok || cycle != twoWeekCycle {
+		t.Errorf("ConfigCycle() = %+v, %v", cycle, ok)
+	}
+	rules := WorkingTimeRules(config)
+	if rules[1].Constraint.Type() != constraint.TypeMaxHoursPerCycle || rules[1].Limit != 88 {
+		t.Errorf("rules[1] = %s %d, want max_hours_per_cycle 88", rules[1].Constraint.Type(), rules[1].Limit)
+	}
+
+	// 请求配置的 cycle_weeks 优先于组织周期
+	override := WithCycle(map[string]interface{}{"cycle_weeks": 4}, &twoWeekCycle)
+	if cycle, _ := ConfigCycle(override); cycle.Weeks != 4 {
+		t.Errorf("cycle = %+v, want 4 周", cycle)
+	}
+}
@@ -3,6 +3,7 @@ package builtin
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"time"
 
@@ -17,6 +18,8 @@ type ShiftRotationPatternConstraint struct {
 	*BaseConstraint
 	pattern      string // 倒班模式：三班倒/两班倒
 	rotationDays int    // 轮换周期（天）
+
+	cycle *model.ScheduleCycle // 排班周期，设置后每个轮换段内员工只上同一类班次，段从周期起始日起每 rotationDays 天划分
 }
 
 // NewShiftRotationPatternConstraint 创建倒班模式约束
@@ -40,12 +43,12 @@ func (c *ShiftRotationPatternConstraint) Evaluate(ctx *constraint.Context) (bool
 	isValid := true
 
 	for _, emp := range ctx.Employees {
-		assignments := ctx.GetEmployeeAssignments(emp.ID)
+		assignments := ctx.GetEmployeeTimeline(emp.ID)
 		if len(assignments) < 2 {
 			continue
 		}
 
-		// 按日期排序
+		// 按日期排序（含上一期的固定历史分配）
 		sorted := make([]*model.Assignment, len(assignments))
 		copy(sorted, assignments)
 		sort.Slice(sorted, func(i, j int) bool {
@@ -68,27 +71,84 @@ func (c *ShiftRotationPatternConstraint) Evaluate(ctx *constraint.Context) (bool
 
 // checkRotationPattern 检查倒班规律
 func (c *ShiftRotationPatternConstraint) checkRotationPattern(ctx *constraint.Context, emp *model.Employee, assignments []*model.Assignment) (bool, []constraint.ViolationDetail) {
+	valid := true
 	var violations []constraint.ViolationDetail
 
-	// 根据倒班模式检查
-	switch c.pattern {
-	case "三班倒":
-		// 三班倒规则：白班->中班->夜班循环
-		shiftSequence := []string{"morning", "afternoon", "night"}
-		return c.checkSequencePattern(ctx, emp, assignments, shiftSequence)
-	case "两班倒":
-		// 两班倒规则：白班<->夜班交替
-		shiftSequence := []string{"morning", "night"}
-		return c.checkSequencePattern(ctx, emp, assignments, shiftSequence)
+	// 根据倒班模式检查（未知模式不检查）
+	if sequence := c.sequence(); sequence != nil {
+		valid, violations = c.checkSequencePattern(ctx, emp, assignments, sequence)
 	}
 
-	return true, violations
+	if c.cycle != nil {
+		if ok, details := c.checkRotationSegments(ctx, emp, assignments); !ok {
+			valid = false
+			// 段内混排的分配同时偏离轮换顺序，同一天只报告段内混排
+			mixed := make(map[string]bool, len(details))
+			for _, d := range details {
+				mixed[d.Date] = true
+			}
+			kept := violations[:0]
+			for _, v := range violations {
+				if !mixed[v.Date] {
+					kept = append(kept, v)
+				}
+			}
+			violations = append(kept, details...)
+		}
+	}
+	return valid, violations
+}
+
+// SetCycle 设置排班周期，按周期起始日划分轮换段，要求员工在每个轮换段内只上同一类班次（段与段之间轮换）
+func (c *ShiftRotationPatternConstraint) SetCycle(cycle model.ScheduleCycle) {
+	c.cycle = &cycle
+}
+
+// segment 返回日期所在轮换段的起始日
+func (c *ShiftRotationPatternConstraint) segment(date string) string {
+	days := c.rotationDays
+	if days <= 0 {
+		days = c.cycle.Days()
+	}
+	return c.cycle.SegmentStart(date, days)
+}
+
+// checkRotationSegments 检查员工在每个轮换段内是否只上同一类班次（含上一期延续到本段的固定历史分配）
+func (c *ShiftRotationPatternConstraint) checkRotationSegments(ctx *constraint.Context, emp *model.Employee, assignments []*model.Assignment) (bool, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	segmentType := make(map[string]string)
+	reported := make(map[string]bool)
+	for _, a := range assignments {
+		shift := ctx.GetShift(a.ShiftID)
+		if shift == nil || shift.ShiftType == "" {
+			continue
+		}
+		seg := c.segment(a.Date)
+		first, ok := segmentType[seg]
+		if !ok {
+			segmentType[seg] = shift.ShiftType
+			continue
+		}
+		if first == shift.ShiftType || ctx.IsHistory(a) || reported[seg] {
+			continue
+		}
+		reported[seg] = true
+		violations = append(violations, constraint.ViolationDetail{
+			ConstraintType: c.Type(),
+			ConstraintName: c.Name(),
+			EmployeeID:     emp.ID,
+			Date:           a.Date,
+			Message:        fmt.Sprintf("员工 %s 在 %s 起的轮换段内混排了 %s 和 %s 班次", emp.Name, seg, first, shift.ShiftType),
+			Severity:       "error",
+			Penalty:        c.Weight(),
+		})
+	}
+	return len(violations) == 0, violations
 }
 
-// checkSequencePattern 检查班次序列模式
-func (c *ShiftRotationPatternConstraint) checkSequencePattern(ctx *constraint.Context, emp *model.Employee, assignments []*model.Assignment, _ []string) (bool, []constraint.ViolationDetail) {
-	// 简化实现：主要检查不能从夜班直接到早班
-	// 注：sequence参数保留用于未来完整实现顺序轮转检查
+// checkSequencePattern 检查班次序列模式：禁止夜班后次日早班，并按轮换顺序检查员工的班次类别
+func (c *ShiftRotationPatternConstraint) checkSequencePattern(ctx *constraint.Context, emp *model.Employee, assignments []*model.Assignment, sequence []string) (bool, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
 	for i := 0; i < len(assignments)-1; i++ {
 		current := assignments[i]
 		next := assignments[i+1]
@@ -96,56 +156,203 @@ func (c *ShiftRotationPatternConstraint) checkSequencePattern(ctx *constraint.Co
 		currentShift := ctx.GetShift(current.ShiftID)
 		nextShift := ctx.GetShift(next.ShiftID)
 
-		if currentShift == nil || nextShift == nil {
+		if currentShift == nil || nextShift == nil || ctx.IsHistory(next) {
 			continue
 		}
 
 		// 检查禁止的班次转换（如夜班->次日早班）
-		if currentShift.ShiftType == "night" && nextShift.ShiftType == "morning" {
-			// 检查是否是连续两天
-			if isConsecutiveDate(current.Date, next.Date) {
-				return false, []constraint.ViolationDetail{{
-					ConstraintType: c.Type(),
-					ConstraintName: c.Name(),
-					EmployeeID:     emp.ID,
-					Date:           next.Date,
-					Message:        fmt.Sprintf("员工 %s 夜班后次日不能安排早班", emp.Name),
-					Severity:       "error",
-					Penalty:        c.Weight(),
-				}}
+		if currentShift.ShiftType == "night" && nextShift.ShiftType == "morning" && isConsecutiveDate(current.Date, next.Date) {
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				EmployeeID:     emp.ID,
+				Date:           next.Date,
+				Message:        fmt.Sprintf("员工 %s 夜班后次日不能安排早班", emp.Name),
+				Severity:       "error",
+				Penalty:        c.Weight(),
+			})
+			break
+		}
+	}
+
+	if detail := c.checkRotationOrder(ctx, emp, assignments, sequence); detail != nil {
+		violations = append(violations, *detail)
+	}
+	return len(violations) == 0, violations
+}
+
+// checkRotationOrder 检查员工的班次类别是否按轮换顺序每 rotationDays 天轮换一次
+// 员工最早一个属于轮换顺序的分配（含固定历史分配）确定其所处的轮换位置，之后每个轮换段应上顺序中的下一类班次；
+// 不在轮换顺序中的班次类别不检查。返回第一个偏离轮换顺序的本期分配，全部符合时返回 nil
+func (c *ShiftRotationPatternConstraint) checkRotationOrder(ctx *constraint.Context, emp *model.Employee, assignments []*model.Assignment, sequence []string) *constraint.ViolationDetail {
+	_, days := c.rotationPeriod()
+	if len(sequence) == 0 || days <= 0 {
+		return nil
+	}
+
+	phase := -1
+	count := 0
+	var first *constraint.ViolationDetail
+	for _, a := range assignments {
+		shift := ctx.GetShift(a.ShiftID)
+		if shift == nil {
+			continue
+		}
+		pos := slices.Index(sequence, shift.ShiftType)
+		index, ok := c.rotationIndex(a.Date)
+		if pos < 0 || !ok {
+			continue
+		}
+		if phase < 0 {
+			phase = floorMod(pos-index, len(sequence))
+			continue
+		}
+		expected := sequence[floorMod(phase+index, len(sequence))]
+		if expected == shift.ShiftType || ctx.IsHistory(a) {
+			continue
+		}
+		count++
+		if first == nil {
+			first = &constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				EmployeeID:     emp.ID,
+				Date:           a.Date,
+				Message: fmt.Sprintf("员工 %s 在 %s 偏离%s轮换顺序：每 %d 天轮换一次，应上 %s 班次，实际为 %s",
+					emp.Name, a.Date, c.pattern, days, expected, shift.ShiftType),
+				Severity: "error",
 			}
 		}
 	}
+	if first == nil {
+		return nil
+	}
+	if count > 1 {
+		first.Message += fmt.Sprintf("（共 %d 个班次偏离）", count)
+	}
+	first.Penalty = c.Weight() * count
+	return first
+}
 
-	return true, nil
+// sequence 返回倒班模式的班次类别轮换顺序，未知模式返回 nil
+func (c *ShiftRotationPatternConstraint) sequence() []string {
+	switch c.pattern {
+	case "三班倒":
+		// 三班倒规则：白班->中班->夜班循环
+		return []string{"morning", "afternoon", "night"}
+	case "两班倒":
+		// 两班倒规则：白班<->夜班交替
+		return []string{"morning", "night"}
+	}
+	return nil
 }
 
-// EvaluateAssignment 评估单个分配
+// rotationPeriod 返回划分轮换段的排班周期和段长（天），未设置周期时从 DefaultCycleAnchor 起划分
+func (c *ShiftRotationPatternConstraint) rotationPeriod() (model.ScheduleCycle, int) {
+	if c.cycle == nil {
+		return model.ScheduleCycle{}, c.rotationDays
+	}
+	days := c.rotationDays
+	if days <= 0 {
+		days = c.cycle.Days()
+	}
+	return *c.cycle, days
+}
+
+// rotationIndex 返回日期所在轮换段的序号（起算日所在段为 0，之前的段为负数），日期格式错误时返回 false
+func (c *ShiftRotationPatternConstraint) rotationIndex(date string) (int, bool) {
+	cycle, days := c.rotationPeriod()
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil || days <= 0 {
+		return 0, false
+	}
+	anchor, err := time.Parse("2006-01-02", cycle.AnchorDate)
+	if err != nil {
+		anchor, _ = time.Parse("2006-01-02", model.DefaultCycleAnchor)
+	}
+	offset := int(t.Sub(anchor).Hours() / 24)
+	return (offset - floorMod(offset, days)) / days, true
+}
+
+// expectedShiftType 按员工已有分配（含固定历史分配）中最早一个属于轮换顺序的分配，返回员工在 date 应上的班次类别
+// 员工还没有这类分配时返回 false
+func (c *ShiftRotationPatternConstraint) expectedShiftType(ctx *constraint.Context, empID uuid.UUID, date string) (string, bool) {
+	sequence := c.sequence()
+	if len(sequence) == 0 {
+		return "", false
+	}
+	var earliest *model.Assignment
+	pos := -1
+	for _, a := range ctx.GetEmployeeTimeline(empID) {
+		shift := ctx.GetShift(a.ShiftID)
+		if shift == nil || (earliest != nil && a.Date >= earliest.Date) {
+			continue
+		}
+		if p := slices.Index(sequence, shift.ShiftType); p >= 0 {
+			earliest, pos = a, p
+		}
+	}
+	if earliest == nil {
+		return "", false
+	}
+	first, ok := c.rotationIndex(earliest.Date)
+	index, ok2 := c.rotationIndex(date)
+	if !ok || !ok2 {
+		return "", false
+	}
+	return sequence[floorMod(pos+index-first, len(sequence))], true
+}
+
+// EvaluateAssignment 评估单个分配，与 Evaluate 的判定一致：
+// 夜班后次日早班（新分配在已有分配之前或之后）、同一轮换段内混排班次类别、班次类别偏离员工的轮换顺序时不可分配
 func (c *ShiftRotationPatternConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
 	shift := ctx.GetShift(a.ShiftID)
 	if shift == nil {
 		return true, 0
 	}
 
-	// 检查前一天的班次
-	assignments := ctx.GetEmployeeAssignments(a.EmployeeID)
+	// 检查前后一天的班次（含上一期的固定历史分配）
+	assignments := ctx.GetEmployeeTimeline(a.EmployeeID)
 	for _, existing := range assignments {
 		existingShift := ctx.GetShift(existing.ShiftID)
 		if existingShift == nil {
 			continue
 		}
 
-		// 检查禁止的转换
-		if existingShift.ShiftType == "night" && shift.ShiftType == "morning" {
-			if isConsecutiveDate(existing.Date, a.Date) {
-				return false, c.Weight()
-			}
+		// 检查禁止的转换：前一天夜班后的早班，或次日早班前的夜班
+		if existingShift.ShiftType == "night" && shift.ShiftType == "morning" && isConsecutiveDate(existing.Date, a.Date) {
+			return false, c.Weight()
+		}
+		if shift.ShiftType == "night" && existingShift.ShiftType == "morning" && isConsecutiveDate(a.Date, existing.Date) {
+			return false, c.Weight()
+		}
+
+		// 检查同一轮换段内的班次类别
+		if c.cycle != nil && shift.ShiftType != "" && existingShift.ShiftType != "" &&
+			existingShift.ShiftType != shift.ShiftType && c.segment(existing.Date) == c.segment(a.Date) {
+			return false, c.Weight()
+		}
+	}
+
+	// 检查轮换顺序
+	if slices.Contains(c.sequence(), shift.ShiftType) {
+		if expected, ok := c.expectedShiftType(ctx, a.EmployeeID, a.Date); ok && expected != shift.ShiftType {
+			return false, c.Weight()
 		}
 	}
 
 	return true, 0
 }
 
+// floorMod 返回 a 除以 n 的非负余数
+func floorMod(a, n int) int {
+	m := a % n
+	if m < 0 {
+		m += n
+	}
+	return m
+}
+
 // MaxConsecutiveNightsConstraint 最大连续夜班约束
 type MaxConsecutiveNightsConstraint struct {
 	*BaseConstraint
@@ -172,9 +379,9 @@ func (c *MaxConsecutiveNightsConstraint) Evaluate(ctx *constraint.Context) (bool
 	isValid := true
 
 	for _, emp := range ctx.Employees {
-		assignments := ctx.GetEmployeeAssignments(emp.ID)
+		assignments := ctx.GetEmployeeTimeline(emp.ID)
 
-		// 统计连续夜班
+		// 统计连续夜班（含上一期的固定历史分配，只统计延续到本期的连续夜班）
 		consecutiveNights := 0
 		maxConsecutive := 0
 		lastNightDate := ""
@@ -196,7 +403,7 @@ func (c *MaxConsecutiveNightsConstraint) Evaluate(ctx *constraint.Context) (bool
 				}
 				lastNightDate = a.Date
 
-				if consecutiveNights > maxConsecutive {
+				if consecutiveNights > maxConsecutive && !ctx.IsHistory(a) {
 					maxConsecutive = consecutiveNights
 				}
 			} else {
@@ -231,33 +438,8 @@ func (c *MaxConsecutiveNightsConstraint) EvaluateAssignment(ctx *constraint.Cont
 		return true, 0
 	}
 
-	// 计算加上此分配后的连续夜班数
-	assignments := ctx.GetEmployeeAssignments(a.EmployeeID)
-	consecutiveNights := 1
-
-	// 往前数
-	currentDate := a.Date
-	for {
-		prevDate := previousDate(currentDate)
-		found := false
-		for _, existing := range assignments {
-			if existing.Date == prevDate {
-				existingShift := ctx.GetShift(existing.ShiftID)
-				if existingShift != nil && existingShift.IsNightShift() {
-					consecutiveNights++
-					currentDate = prevDate
-					found = true
-					break
-				}
-			}
-		}
-		if !found {
-			break
-		}
-		if consecutiveNights > 10 {
-			break
-		}
-	}
+	// 计算加上此分配后的连续夜班数（含上一期的固定历史分配）
+	consecutiveNights := ctx.GetEmployeeConsecutiveNights(a.EmployeeID, a.Date) + 1
 
 	if consecutiveNights > c.maxNights {
 		return false, c.Weight() * (consecutiveNights - c.maxNights)
@@ -514,17 +696,21 @@ func (c *CertificationRequiredConstraint) Evaluate(ctx *constraint.Context) (boo
 		}
 
 		for _, cert := range requiredCerts {
-			if !emp.HasCertification(cert) {
+			if !emp.HasCertificationOn(cert, a.Date) {
 				isValid = false
 				penalty := c.Weight()
 				totalPenalty += penalty
+				message := fmt.Sprintf("员工 %s 缺少岗位 '%s' 所需证书: %s", emp.Name, position, cert)
+				if emp.HasCertification(cert) {
+					message = fmt.Sprintf("员工 %s 岗位 '%s' 所需证书 %s 在 %s 已过期", emp.Name, position, cert, a.Date)
+				}
 
 				violations = append(violations, constraint.ViolationDetail{
 					ConstraintType: c.Type(),
 					ConstraintName: c.Name(),
 					EmployeeID:     emp.ID,
 					Date:           a.Date,
-					Message:        fmt.Sprintf("员工 %s 缺少岗位 '%s' 所需证书: %s", emp.Name, position, cert),
+					Message:        message,
 					Severity:       "error",
 					Penalty:        penalty,
 				})
@@ -553,7 +739,7 @@ func (c *CertificationRequiredConstraint) EvaluateAssignment(ctx *constraint.Con
 	}
 
 	for _, cert := range requiredCerts {
-		if !emp.HasCertification(cert) {
+		if !emp.HasCertificationOn(cert, a.Date) {
 			return false, c.Weight()
 		}
 	}
@@ -4,8 +4,10 @@ package builtin
 import (
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
+	"github.com/paiban/paiban/pkg/holiday"
 	"github.com/paiban/paiban/pkg/model"
 	"github.com/paiban/paiban/pkg/scheduler/constraint"
 )
@@ -17,6 +19,9 @@ type WorkloadFairnessConstraint struct {
 	tolerancePercent float64 // 允许的偏差百分比
 	considerWeekend  bool    // 是否考虑周末分配公平
 	considerNight    bool    // 是否考虑夜班分配公平
+
+	cycle    *model.ScheduleCycle // 周末/夜班公平性统计窗口，为空时按整个排班期
+	holidays *holiday.Calendar    // 节假日日历，设置后统计节假日工作公平性
 }
 
 // NewWorkloadFairnessConstraint 创建工作量公平性约束
@@ -36,10 +41,11 @@ func NewWorkloadFairnessConstraint(weight int, tolerance float64) *WorkloadFairn
 
 // Evaluate 评估整个排班
 func (c *WorkloadFairnessConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	employees := ctx.InternalEmployees() // 外部人员不参与公平性统计
 	var violations []constraint.ViolationDetail
 	totalPenalty := 0
 
-	if len(ctx.Employees) < 2 {
+	if len(employees) < 2 {
 		return true, 0, nil
 	}
 
@@ -62,17 +68,25 @@ func (c *WorkloadFairnessConstraint) Evaluate(ctx *constraint.Context) (bool, in
 		totalPenalty += nightPenalty
 	}
 
+	// 计算节假日工作公平性
+	if c.holidays != nil {
+		holidayViolations, holidayPenalty := c.evaluateHolidayFairness(ctx)
+		violations = append(violations, holidayViolations...)
+		totalPenalty += holidayPenalty
+	}
+
 	return true, totalPenalty, violations
 }
 
 // evaluateHoursFairness 评估工时公平性
 func (c *WorkloadFairnessConstraint) evaluateHoursFairness(ctx *constraint.Context) ([]constraint.ViolationDetail, int) {
+	employees := ctx.InternalEmployees() // 外部人员不参与公平性统计
 	var violations []constraint.ViolationDetail
 	totalPenalty := 0
 
 	// 计算每人工时
-	hours := make([]float64, len(ctx.Employees))
-	for i, emp := range ctx.Employees {
+	hours := make([]float64, len(employees))
+	for i, emp := range employees {
 		hours[i] = ctx.GetEmployeeHoursInRange(emp.ID, ctx.StartDate, ctx.EndDate)
 	}
 
@@ -81,7 +95,7 @@ func (c *WorkloadFairnessConstraint) evaluateHoursFairness(ctx *constraint.Conte
 	tolerance := avg * c.tolerancePercent / 100
 
 	// 检查偏差
-	for i, emp := range ctx.Employees {
+	for i, emp := range employees {
 		deviation := hours[i] - avg
 		if math.Abs(deviation) > tolerance {
 			penalty := int(math.Abs(deviation) * float64(c.Weight()) / (avg + 1))
@@ -106,105 +120,113 @@ func (c *WorkloadFairnessConstraint) evaluateHoursFairness(ctx *constraint.Conte
 
 // evaluateWeekendFairness 评估周末分配公平性
 func (c *WorkloadFairnessConstraint) evaluateWeekendFairness(ctx *constraint.Context) ([]constraint.ViolationDetail, int) {
+	return c.evaluateCountFairness(ctx, "周末工作", "天", func(a *model.Assignment) bool {
+		if h, ok := c.holidays.Get(a.Date); ok && !h.IsOff() {
+			return false // 调休上班日按工作日统计
+		}
+		return isWeekend(a.Date)
+	})
+}
+
+// evaluateNightFairness 评估夜班分配公平性
+func (c *WorkloadFairnessConstraint) evaluateNightFairness(ctx *constraint.Context) ([]constraint.ViolationDetail, int) {
+	return c.evaluateCountFairness(ctx, "夜班", "次", func(a *model.Assignment) bool {
+		shift := ctx.GetShift(a.ShiftID)
+		return shift != nil && shift.IsNightShift()
+	})
+}
+
+// evaluateHolidayFairness 评估节假日工作公平性
+func (c *WorkloadFairnessConstraint) evaluateHolidayFairness(ctx *constraint.Context) ([]constraint.ViolationDetail, int) {
+	return c.evaluateCountFairness(ctx, "节假日工作", "天", func(a *model.Assignment) bool {
+		return c.holidays.IsHoliday(a.Date)
+	})
+}
+
+// evaluateCountFairness 按统计窗口（整个排班期或每个排班周期）统计每人满足 match 的分配数，
+// 偏离窗口内平均值超过 1 时扣分
+func (c *WorkloadFairnessConstraint) evaluateCountFairness(ctx *constraint.Context, what, unit string, match func(*model.Assignment) bool) ([]constraint.ViolationDetail, int) {
+	employees := ctx.InternalEmployees() // 外部人员不参与公平性统计
 	var violations []constraint.ViolationDetail
 	totalPenalty := 0
 
-	// 统计每人周末工作天数
-	weekendDays := make(map[string]int)
-	for _, emp := range ctx.Employees {
-		assignments := ctx.GetEmployeeAssignments(emp.ID)
-		count := 0
-		for _, a := range assignments {
-			if isWeekend(a.Date) {
-				count++
+	// 统计每个窗口内每人的次数
+	counts := make(map[string]map[string]int)
+	for _, emp := range employees {
+		for _, a := range ctx.GetEmployeeAssignments(emp.ID) {
+			if !match(a) {
+				continue
 			}
+			window := c.window(a.Date)
+			if counts[window] == nil {
+				counts[window] = make(map[string]int)
+			}
+			counts[window][emp.ID.String()]++
 		}
-		weekendDays[emp.ID.String()] = count
 	}
 
-	// 计算平均值
-	var total int
-	for _, count := range weekendDays {
-		total += count
+	windows := make([]string, 0, len(counts))
+	for window := range counts {
+		windows = append(windows, window)
 	}
-	avg := float64(total) / float64(len(ctx.Employees))
-
-	// 检查偏差
-	for _, emp := range ctx.Employees {
-		count := weekendDays[emp.ID.String()]
-		deviation := float64(count) - avg
+	sort.Strings(windows)
 
-		if math.Abs(deviation) > 1 { // 允许1天偏差
-			penalty := int(math.Abs(deviation)) * c.Weight() / 4
-			totalPenalty += penalty
-
-			violations = append(violations, constraint.ViolationDetail{
-				ConstraintType: c.Type(),
-				ConstraintName: c.Name(),
-				EmployeeID:     emp.ID,
-				Message: fmt.Sprintf(
-					"员工 %s 周末工作 %d 天，偏离平均 %.1f 天",
-					emp.Name, count, deviation,
-				),
-				Severity: "warning",
-				Penalty:  penalty,
-			})
+	for _, window := range windows {
+		// 计算平均值
+		var total int
+		for _, count := range counts[window] {
+			total += count
 		}
-	}
+		avg := float64(total) / float64(len(employees))
 
-	return violations, totalPenalty
-}
+		// 检查偏差
+		for _, emp := range employees {
+			count := counts[window][emp.ID.String()]
+			deviation := float64(count) - avg
 
-// evaluateNightFairness 评估夜班分配公平性
-func (c *WorkloadFairnessConstraint) evaluateNightFairness(ctx *constraint.Context) ([]constraint.ViolationDetail, int) {
-	var violations []constraint.ViolationDetail
-	totalPenalty := 0
+			if math.Abs(deviation) > 1 { // 允许1天（班）偏差
+				penalty := int(math.Abs(deviation)) * c.Weight() / 4
+				totalPenalty += penalty
 
-	// 统计每人夜班数
-	nightShifts := make(map[string]int)
-	for _, emp := range ctx.Employees {
-		assignments := ctx.GetEmployeeAssignments(emp.ID)
-		count := 0
-		for _, a := range assignments {
-			shift := ctx.GetShift(a.ShiftID)
-			if shift != nil && shift.IsNightShift() {
-				count++
+				scope := ""
+				if window != "" {
+					scope = fmt.Sprintf("在 %s 起的周期内", window)
+				}
+				violations = append(violations, constraint.ViolationDetail{
+					ConstraintType: c.Type(),
+					ConstraintName: c.Name(),
+					EmployeeID:     emp.ID,
+					Date:           window,
+					Message: fmt.Sprintf(
+						"员工 %s %s%s %d %s，偏离平均 %.1f %s",
+						emp.Name, scope, what, count, unit, deviation, unit,
+					),
+					Severity: "warning",
+					Penalty:  penalty,
+				})
 			}
 		}
-		nightShifts[emp.ID.String()] = count
 	}
 
-	// 计算平均值
-	var total int
-	for _, count := range nightShifts {
-		total += count
-	}
-	avg := float64(total) / float64(len(ctx.Employees))
+	return violations, totalPenalty
+}
 
-	// 检查偏差
-	for _, emp := range ctx.Employees {
-		count := nightShifts[emp.ID.String()]
-		deviation := float64(count) - avg
+// SetCycle 设置排班周期，周末和夜班公平性按每个周期分别统计（未设置时按整个排班期统计）
+func (c *WorkloadFairnessConstraint) SetCycle(cycle model.ScheduleCycle) {
+	c.cycle = &cycle
+}
 
-		if math.Abs(deviation) > 1 { // 允许1班偏差
-			penalty := int(math.Abs(deviation)) * c.Weight() / 4
-			totalPenalty += penalty
+// SetHolidays 设置节假日日历，增加节假日工作公平性统计，调休上班日不计入周末
+func (c *WorkloadFairnessConstraint) SetHolidays(calendar *holiday.Calendar) {
+	c.holidays = calendar
+}
 
-			violations = append(violations, constraint.ViolationDetail{
-				ConstraintType: c.Type(),
-				ConstraintName: c.Name(),
-				EmployeeID:     emp.ID,
-				Message: fmt.Sprintf(
-					"员工 %s 夜班 %d 次，偏离平均 %.1f 次",
-					emp.Name, count, deviation,
-				),
-				Severity: "warning",
-				Penalty:  penalty,
-			})
-		}
+// window 返回日期所在的公平性统计窗口（周期起始日），未设置周期时为整个排班期
+func (c *WorkloadFairnessConstraint) window(date string) string {
+	if c.cycle == nil {
+		return ""
 	}
-
-	return violations, totalPenalty
+	return c.cycle.Start(date)
 }
 
 // EvaluateAssignment 评估单个分配
@@ -264,16 +286,17 @@ func NewShiftDistributionConstraint(weight int) *ShiftDistributionConstraint {
 
 // Evaluate 评估整个排班
 func (c *ShiftDistributionConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	employees := ctx.InternalEmployees() // 外部人员不参与公平性统计
 	var violations []constraint.ViolationDetail
 	totalPenalty := 0
 
-	if len(ctx.Employees) < 2 || len(ctx.Shifts) < 2 {
+	if len(employees) < 2 || len(ctx.Shifts) < 2 {
 		return true, 0, nil
 	}
 
 	// 统计每人每种班次的数量
 	shiftCounts := make(map[string]map[string]int) // empID -> shiftType -> count
-	for _, emp := range ctx.Employees {
+	for _, emp := range employees {
 		shiftCounts[emp.ID.String()] = make(map[string]int)
 		assignments := ctx.GetEmployeeAssignments(emp.ID)
 		for _, a := range assignments {
@@ -294,11 +317,11 @@ func (c *ShiftDistributionConstraint) Evaluate(ctx *constraint.Context) (bool, i
 
 	shiftAvg := make(map[string]float64)
 	for shiftType, total := range shiftTotals {
-		shiftAvg[shiftType] = float64(total) / float64(len(ctx.Employees))
+		shiftAvg[shiftType] = float64(total) / float64(len(employees))
 	}
 
 	// 检查偏差
-	for _, emp := range ctx.Employees {
+	for _, emp := range employees {
 		counts := shiftCounts[emp.ID.String()]
 		for shiftType, count := range counts {
 			avg := shiftAvg[shiftType]
@@ -0,0 +1,92 @@
+package builtin
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/paiban/paiban/pkg/fatigue"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// FatigueConstraint 疲劳指数约束（软约束）
+// 分配结束时员工疲劳指数达到阈值即扣分，超出越多扣分越多；
+// 求解器在同等条件下优先选择不会因此扣分的候选人
+type FatigueConstraint struct {
+	*BaseConstraint
+	scorer    *fatigue.Scorer
+	threshold float64
+}
+
+// NewFatigueConstraint 创建疲劳指数约束，threshold <= 0 时使用高度疲劳阈值
+func NewFatigueConstraint(weight int, threshold float64, cfg fatigue.Config) *FatigueConstraint {
+	scorer := fatigue.NewScorer(cfg)
+	if threshold <= 0 {
+		threshold = scorer.Config().HighThreshold
+	}
+	return &FatigueConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"疲劳指数",
+			constraint.TypeFatigue,
+			constraint.CategorySoft,
+			weight,
+		),
+		scorer:    scorer,
+		threshold: threshold,
+	}
+}
+
+// Evaluate 评估整个排班：逐个分配计算其结束时的疲劳指数
+func (c *FatigueConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, emp := range ctx.Employees {
+		// 按开始时间排序一次，逐个计算时不再重复排序
+		assignments := slices.Clone(ctx.GetEmployeeAssignments(emp.ID))
+		slices.SortStableFunc(assignments, func(x, y *model.Assignment) int { return x.StartTime.Compare(y.StartTime) })
+		for _, a := range assignments {
+			idx := c.scorer.Score(emp.ID, assignments, a.EndTime)
+			penalty := c.penalty(idx.Score)
+			if penalty == 0 {
+				continue
+			}
+			totalPenalty += penalty
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				EmployeeID:     emp.ID,
+				Date:           a.Date,
+				Message: fmt.Sprintf("员工 %s 于 %s 下班时疲劳指数 %.1f，达到阈值 %.0f（夜班 %d 次、长班次 %d 次、休息不足 %d 次）",
+					emp.Name, a.Date, idx.Score, c.threshold, idx.NightShifts, idx.LongShifts, idx.ShortRests),
+				Severity: "warning",
+				Penalty:  penalty,
+			})
+		}
+	}
+
+	return true, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配：加入该分配后员工下班时的疲劳指数
+func (c *FatigueConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	existing := ctx.GetEmployeeAssignments(a.EmployeeID)
+	assignments := make([]*model.Assignment, 0, len(existing)+1)
+	for _, e := range existing {
+		if e.ID != a.ID {
+			assignments = append(assignments, e)
+		}
+	}
+	assignments = append(assignments, a)
+
+	idx := c.scorer.Score(a.EmployeeID, assignments, a.EndTime)
+	return true, c.penalty(idx.Score)
+}
+
+// penalty 按超出阈值的幅度扣分：达到阈值扣 1 倍权重，每超出 10 分再加 1 倍
+func (c *FatigueConstraint) penalty(score float64) int {
+	if score < c.threshold {
+		return 0
+	}
+	return c.Weight() * (1 + int(score-c.threshold)/10)
+}
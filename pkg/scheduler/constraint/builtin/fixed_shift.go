@@ -0,0 +1,83 @@
+package builtin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// FixedShiftConstraint 固定班次约束（硬约束）
+// 员工设置了固定班次时只能排这些班次（如只上夜班），固定休息日当天不排班
+type FixedShiftConstraint struct {
+	*BaseConstraint
+}
+
+// NewFixedShiftConstraint 创建固定班次约束
+func NewFixedShiftConstraint() *FixedShiftConstraint {
+	return &FixedShiftConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"固定班次",
+			constraint.TypeFixedShift,
+			constraint.CategoryHard,
+			100,
+		),
+	}
+}
+
+// Evaluate 评估整个排班
+func (c *FixedShiftConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, emp := range ctx.Employees {
+		if emp.Preferences == nil {
+			continue
+		}
+		for _, a := range ctx.GetEmployeeAssignments(emp.ID) {
+			msg := fixedShiftMessage(ctx, emp, a)
+			if msg == "" {
+				continue
+			}
+			totalPenalty += c.Weight()
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				EmployeeID:     emp.ID,
+				Date:           a.Date,
+				Message:        msg,
+				Severity:       "error",
+				Penalty:        c.Weight(),
+			})
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+func (c *FixedShiftConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	emp := ctx.GetEmployee(a.EmployeeID)
+	if emp == nil || fixedShiftMessage(ctx, emp, a) == "" {
+		return true, 0
+	}
+	return false, c.Weight()
+}
+
+var fixedWeekdayNames = [...]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// fixedShiftMessage 返回分配违反固定班次或固定休息日的说明，未违反返回空字符串
+func fixedShiftMessage(ctx *constraint.Context, emp *model.Employee, a *model.Assignment) string {
+	prefs := emp.Preferences
+	if prefs == nil {
+		return ""
+	}
+	if day, err := time.Parse("2006-01-02", a.Date); err == nil && prefs.IsFixedDayOff(day.Weekday()) {
+		return fmt.Sprintf("员工 %s 的固定休息日为%s，%s 不可排班", emp.Name, fixedWeekdayNames[day.Weekday()], a.Date)
+	}
+	if shift := ctx.GetShift(a.ShiftID); shift != nil && !prefs.AllowsShift(shift.Code, shift.ShiftType) {
+		return fmt.Sprintf("员工 %s 固定上 %v 班次，%s 不可排 %s", emp.Name, prefs.FixedShiftCodes, a.Date, shift.Name)
+	}
+	return ""
+}
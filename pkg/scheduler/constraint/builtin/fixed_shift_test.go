@@ -0,0 +1,50 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestFixedShiftConstraint(t *testing.T) {
+	c := NewFixedShiftConstraint()
+
+	night := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "夜班", Code: "N", ShiftType: "night"}
+	day := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "白班", Code: "D", ShiftType: "morning"}
+
+	// 2024-01-15 为周一，2024-01-16 为周二
+	dayShift := createAssignmentWithTime("2024-01-15", "09:00", "17:00")
+	dayShift.ShiftID = day.ID
+	ctx := createTestContext([]*model.Assignment{dayShift})
+	ctx.SetShifts([]*model.Shift{night, day})
+	emp := ctx.Employees[0]
+
+	// 未设置固定班次时不限制
+	if valid, _, _ := c.Evaluate(ctx); !valid {
+		t.Fatal("未设置固定班次时不应违反约束")
+	}
+
+	emp.Preferences = &model.EmployeePreferences{FixedShiftCodes: []string{"night"}, FixedDaysOff: []time.Weekday{time.Tuesday}}
+	valid, penalty, violations := c.Evaluate(ctx)
+	if valid || penalty != c.Weight() || len(violations) != 1 {
+		t.Fatalf("只上夜班的员工排白班应违反约束: valid=%v penalty=%d violations=%d", valid, penalty, len(violations))
+	}
+	if !strings.Contains(violations[0].Message, "白班") {
+		t.Errorf("违规说明应注明班次: %s", violations[0].Message)
+	}
+
+	nightShift := createAssignmentWithTime("2024-01-15", "22:00", "23:59")
+	nightShift.ShiftID, nightShift.EmployeeID = night.ID, emp.ID
+	if ok, _ := c.EvaluateAssignment(ctx, nightShift); !ok {
+		t.Error("固定班次（按班次类型匹配）应通过")
+	}
+
+	dayOff := createAssignmentWithTime("2024-01-16", "22:00", "23:59")
+	dayOff.ShiftID, dayOff.EmployeeID = night.ID, emp.ID
+	if ok, _ := c.EvaluateAssignment(ctx, dayOff); ok {
+		t.Error("固定休息日不应排班")
+	}
+}
@@ -0,0 +1,71 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestHistory_MinRestAcrossPeriods(t *testing.T) {
+	// 上一期最后一天夜班到 23:00，本期第一天 06:00 早班
+	night := createAssignmentWithTime("2024-01-14", "15:00", "23:00")
+	morning := createAssignmentWithTime("2024-01-15", "06:00", "14:00")
+	ctx := createTestContext([]*model.Assignment{morning})
+	night.EmployeeID = morning.EmployeeID
+	c := NewMinRestBetweenShiftsConstraint(11)
+
+	if valid, _, _ := c.Evaluate(ctx); !valid {
+		t.Fatal("未加载历史时本期应单独通过")
+	}
+	ctx.SetHistory([]*model.Assignment{night}, nil)
+	valid, _, violations := c.Evaluate(ctx)
+	if valid || len(violations) != 1 || violations[0].Date != "2024-01-15" {
+		t.Errorf("应发现跨周期休息不足: %+v", violations)
+	}
+	if ok, _ := c.EvaluateAssignment(ctx, createAssignmentWithTime("2024-01-15", "06:00", "14:00")); !ok {
+		t.Error("其他员工不应受历史分配影响")
+	}
+	// 本期尚无分配时，候选分配也应与历史分配一起检查
+	empty := createTestContext(nil)
+	night.EmployeeID = empty.Employees[0].ID
+	empty.SetHistory([]*model.Assignment{night}, nil)
+	candidate := createAssignmentWithTime("2024-01-15", "07:00", "15:00")
+	candidate.EmployeeID = night.EmployeeID
+	if ok, _ := c.EvaluateAssignment(empty, candidate); ok {
+		t.Error("候选分配应与历史分配一起检查休息时间")
+	}
+}
+
+func TestHistory_ConsecutiveDaysAcrossPeriods(t *testing.T) {
+	var current []*model.Assignment
+	for _, date := range []string{"2024-01-15", "2024-01-16"} {
+		current = append(current, createAssignmentWithTime(date, "09:00", "17:00"))
+	}
+	ctx := createTestContext(current)
+	var history []*model.Assignment
+	for _, date := range []string{"2024-01-10", "2024-01-11", "2024-01-12", "2024-01-13", "2024-01-14"} {
+		a := createAssignmentWithTime(date, "09:00", "17:00")
+		a.EmployeeID = current[0].EmployeeID
+		history = append(history, a)
+	}
+	ctx.SetHistory(history, nil)
+
+	c := NewMaxConsecutiveDaysConstraint(6)
+	valid, penalty, _ := c.Evaluate(ctx)
+	if valid || penalty != 100 {
+		t.Errorf("跨周期连续 7 天应违反限制, valid=%v penalty=%d", valid, penalty)
+	}
+	next := createAssignmentWithTime("2024-01-17", "09:00", "17:00")
+	next.EmployeeID = current[0].EmployeeID
+	if ok, _ := c.EvaluateAssignment(ctx, next); ok {
+		t.Error("候选分配应计入历史连续天数")
+	}
+
+	// 历史内部超限且未延续到本期时不报告
+	gap := createAssignmentWithTime("2024-01-17", "09:00", "17:00")
+	gap.EmployeeID = current[0].EmployeeID
+	ctx.SetAssignments([]*model.Assignment{gap})
+	if valid, _, _ := NewMaxConsecutiveDaysConstraint(3).Evaluate(ctx); !valid {
+		t.Error("仅历史分配超限时不应报告本期违规")
+	}
+}
@@ -0,0 +1,118 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/paiban/paiban/pkg/holiday"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// HolidayStaffingConstraint 节假日值班约束（软约束）
+// 节假日值班按人轮流：统计每人本期和上一期固定历史中的节假日值班天数，偏离平均超过 1 天时扣分；
+// 评估单个分配时按员工已有的节假日值班天数扣分，求解器在节假日优先安排值班少的员工
+type HolidayStaffingConstraint struct {
+	*BaseConstraint
+	calendar *holiday.Calendar
+}
+
+// NewHolidayStaffingConstraint 创建节假日值班约束
+func NewHolidayStaffingConstraint(weight int, calendar *holiday.Calendar) *HolidayStaffingConstraint {
+	return &HolidayStaffingConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"节假日值班",
+			constraint.TypeHolidayHandling,
+			constraint.CategorySoft,
+			weight,
+		),
+		calendar: calendar,
+	}
+}
+
+// Calendar 返回节假日日历
+func (c *HolidayStaffingConstraint) Calendar() *holiday.Calendar {
+	return c.calendar
+}
+
+// Evaluate 评估整个排班
+func (c *HolidayStaffingConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	employees := ctx.InternalEmployees() // 外部人员不参与轮值统计
+	if len(employees) < 2 {
+		return true, 0, nil
+	}
+
+	counts := make([]int, len(employees))
+	history := make([]int, len(employees))
+	total := 0
+	for i, emp := range employees {
+		history[i] = c.countDuty(ctx.GetEmployeeHistory(emp.ID))
+		counts[i] = c.countDuty(ctx.GetEmployeeAssignments(emp.ID)) + history[i]
+		total += counts[i]
+	}
+	if total == 0 {
+		return true, 0, nil
+	}
+
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+	avg := float64(total) / float64(len(employees))
+	for i, emp := range employees {
+		deviation := float64(counts[i]) - avg
+		if math.Abs(deviation) <= 1 { // 允许1天偏差
+			continue
+		}
+		penalty := int(math.Abs(deviation)) * c.Weight() / 4
+		totalPenalty += penalty
+		violations = append(violations, constraint.ViolationDetail{
+			ConstraintType: c.Type(),
+			ConstraintName: c.Name(),
+			EmployeeID:     emp.ID,
+			Message: fmt.Sprintf("员工 %s 节假日值班 %d 天（含上期 %d 天），偏离平均 %.1f 天",
+				emp.Name, counts[i], history[i], deviation),
+			Severity: "warning",
+			Penalty:  penalty,
+		})
+	}
+	return true, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配：节假日的分配按员工已有的节假日值班天数扣分
+func (c *HolidayStaffingConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	if !c.calendar.IsHoliday(a.Date) {
+		return true, 0
+	}
+	duty := c.countDuty(ctx.GetEmployeeAssignments(a.EmployeeID)) + c.countDuty(ctx.GetEmployeeHistory(a.EmployeeID))
+	return true, duty * c.Weight() / 4
+}
+
+// countDuty 统计节假日值班天数
+func (c *HolidayStaffingConstraint) countDuty(assignments []*model.Assignment) int {
+	days := make(map[string]bool)
+	for _, a := range assignments {
+		if c.calendar.IsHoliday(a.Date) {
+			days[a.Date] = true
+		}
+	}
+	return len(days)
+}
+
+// ConfigHolidays 从配置中获取节假日日历
+// "holiday_calendar" 为 "none" 时不使用内置的中国法定节假日（默认 "cn"）；
+// "holidays" 为组织自定义节假日（[]model.Holiday 或 JSON 数组），与内置节假日同一天时覆盖；
+// "holiday_bonus_rate" 大于 0 时统一覆盖节假日的加班倍率
+func ConfigHolidays(config map[string]interface{}) *holiday.Calendar {
+	var custom []model.Holiday
+	switch v := config["holidays"].(type) {
+	case []model.Holiday:
+		custom = v
+	case []interface{}:
+		if data, err := json.Marshal(v); err == nil {
+			_ = json.Unmarshal(data, &custom)
+		}
+	}
+	calendar := holiday.NewCalendar(getConfigString(config, "holiday_calendar", holiday.CalendarCN) != "none", custom)
+	calendar.SetBonusRate(getConfigFloat(config, "holiday_bonus_rate", 0))
+	return calendar
+}
@@ -0,0 +1,136 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/holiday"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// holidayContext 两名员工，2025-10-01 至 2025-10-07 国庆假期
+func holidayContext() (*constraint.Context, *model.Employee, *model.Employee) {
+	ctx := constraint.NewContext(uuid.New(), "2025-10-01", "2025-10-07")
+	a := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "张三", Status: "active"}
+	b := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "李四", Status: "active"}
+	ctx.SetEmployees([]*model.Employee{a, b})
+	return ctx, a, b
+}
+
+func holidayShift(emp *model.Employee, date string) *model.Assignment {
+	a := createAssignmentWithTime(date, "09:00", "17:00")
+	a.EmployeeID = emp.ID
+	return a
+}
+
+func TestHolidayStaffingConstraint(t *testing.T) {
+	c := NewHolidayStaffingConstraint(80, holiday.NewCalendar(true, nil))
+	ctx, zhang, li := holidayContext()
+
+	// 张三国庆值班 3 天，李四 0 天
+	ctx.SetAssignments([]*model.Assignment{
+		holidayShift(zhang, "2025-10-01"),
+		holidayShift(zhang, "2025-10-02"),
+		holidayShift(zhang, "2025-10-03"),
+	})
+	valid, penalty, violations := c.Evaluate(ctx)
+	if !valid || penalty == 0 || len(violations) != 2 {
+		t.Fatalf("节假日值班不均应扣分: valid=%v penalty=%d violations=%d", valid, penalty, len(violations))
+	}
+	if !strings.Contains(violations[0].Message, "节假日值班") {
+		t.Errorf("违规说明应注明节假日值班: %s", violations[0].Message)
+	}
+
+	// 节假日的分配按已值班天数扣分，值班少的员工优先
+	_, zhangPenalty := c.EvaluateAssignment(ctx, holidayShift(zhang, "2025-10-04"))
+	_, liPenalty := c.EvaluateAssignment(ctx, holidayShift(li, "2025-10-04"))
+	if zhangPenalty <= liPenalty {
+		t.Errorf("已值班多的员工扣分应更高: 张三=%d 李四=%d", zhangPenalty, liPenalty)
+	}
+
+	// 调休上班日和普通工作日不扣分
+	if _, p := c.EvaluateAssignment(ctx, holidayShift(zhang, "2025-10-11")); p != 0 {
+		t.Errorf("调休上班日不应扣分: %d", p)
+	}
+
+	// 上期历史计入轮换：李四上期值班 3 天后两人持平
+	ctx.SetHistory([]*model.Assignment{
+		holidayShift(li, "2025-05-01"),
+		holidayShift(li, "2025-05-02"),
+		holidayShift(li, "2025-05-03"),
+	}, nil)
+	if _, penalty, _ := c.Evaluate(ctx); penalty != 0 {
+		t.Errorf("计入历史后值班持平不应扣分: %d", penalty)
+	}
+}
+
+func TestConfigHolidays(t *testing.T) {
+	// 自定义节假日（JSON 数组）覆盖内置节假日，未填写类型时为自定义假日
+	config := map[string]interface{}{
+		"holidays": []interface{}{
+			map[string]interface{}{"date": "2025-10-01", "name": "店庆", "type": "workday"},
+			map[string]interface{}{"date": "2025-11-11", "name": "企业假日"},
+		},
+	}
+	cal := ConfigHolidays(config)
+	if cal.IsHoliday("2025-10-01") {
+		t.Error("自定义为上班日后不应为节假日")
+	}
+	if h, ok := cal.Get("2025-11-11"); !ok || h.Type != model.HolidayCustom {
+		t.Errorf("自定义节假日类型应默认 custom: %+v", h)
+	}
+	if !cal.IsHoliday("2025-10-02") || cal.Rate("2025-10-02") != 3 {
+		t.Errorf("内置法定节假日加班倍率应为 3: %v", cal.Rate("2025-10-02"))
+	}
+
+	// 不使用内置日历，统一加班倍率
+	cal = ConfigHolidays(map[string]interface{}{"holiday_calendar": "none", "holiday_bonus_rate": 2.5})
+	if cal.IsHoliday("2025-10-02") {
+		t.Error("holiday_calendar 为 none 时不应包含内置节假日")
+	}
+	cal = ConfigHolidays(map[string]interface{}{"holiday_bonus_rate": 2.5})
+	if cal.Rate("2025-10-02") != 2.5 {
+		t.Errorf("统一加班倍率应覆盖按类型的倍率: %v", cal.Rate("2025-10-02"))
+	}
+}
+
+func TestMinimizeOvertime_HolidayPremium(t *testing.T) {
+	ctx, zhang, _ := holidayContext()
+	// 每班 8 小时，标准 4 小时：加班 4 小时
+	c := NewMinimizeOvertimeConstraint(50, 4)
+
+	_, workday := c.EvaluateAssignment(ctx, holidayShift(zhang, "2025-10-01"))
+	c.SetHolidays(holiday.NewCalendar(true, nil))
+	_, normal := c.EvaluateAssignment(ctx, holidayShift(zhang, "2025-10-11"))
+	_, statutory := c.EvaluateAssignment(ctx, holidayShift(zhang, "2025-10-01"))
+	if normal == 0 || workday != normal || statutory != 3*normal {
+		t.Errorf("法定节假日加班应按 3 倍扣分: 未设置日历=%d 调休上班日=%d 法定=%d", workday, normal, statutory)
+	}
+}
+
+func TestWorkloadFairness_HolidayDuty(t *testing.T) {
+	ctx, zhang, _ := holidayContext()
+	ctx.SetAssignments([]*model.Assignment{
+		holidayShift(zhang, "2025-10-01"),
+		holidayShift(zhang, "2025-10-02"),
+		holidayShift(zhang, "2025-10-03"),
+	})
+	c := NewWorkloadFairnessConstraint(60, 100)
+	c.considerWeekend, c.considerNight = false, false
+	_, before, _ := c.Evaluate(ctx)
+
+	c.SetHolidays(holiday.NewCalendar(true, nil))
+	_, after, violations := c.Evaluate(ctx)
+	if after <= before {
+		t.Fatalf("设置节假日后应统计节假日工作公平性: before=%d after=%d", before, after)
+	}
+	found := false
+	for _, v := range violations {
+		found = found || strings.Contains(v.Message, "节假日工作")
+	}
+	if !found {
+		t.Error("应有节假日工作公平性违规")
+	}
+}
@@ -0,0 +1,92 @@
+package builtin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/stats"
+)
+
+// HourlyCoverageConstraint 每小时最低在岗人数约束（硬约束）
+// 按分配的实际起止时间统计每个整点小时的在岗人数（与该小时重叠的分配都计入，跨零点的班次计入次日），
+// 排班期内每天低于要求的小时视为违反；统计方式与覆盖率分析的人手不足时段一致
+type HourlyCoverageConstraint struct {
+	*BaseConstraint
+	rules []model.HourlyStaffing
+}
+
+// NewHourlyCoverageConstraint 创建每小时最低在岗人数约束
+func NewHourlyCoverageConstraint(rules []model.HourlyStaffing) *HourlyCoverageConstraint {
+	return &HourlyCoverageConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"每小时最低在岗人数",
+			constraint.TypeHourlyCoverage,
+			constraint.CategoryHard,
+			100,
+		),
+		rules: rules,
+	}
+}
+
+// Evaluate 评估整个排班：每天连续缺人且人数相同的小时合并为一条违规，惩罚按缺少的人时计算
+func (c *HourlyCoverageConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	headcount := HourlyHeadcount(ctx.Assignments)
+	start, err1 := time.Parse("2006-01-02", ctx.StartDate)
+	end, err2 := time.Parse("2006-01-02", ctx.EndDate)
+	if err1 != nil || err2 != nil {
+		return true, 0, nil
+	}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		minimums := model.HourlyMinimums(c.rules, date)
+		for hour := 0; hour < 24; {
+			required := minimums[hour]
+			staff := headcount[stats.HourSlot{Date: date, Hour: hour}]
+			if required == 0 || staff >= required {
+				hour++
+				continue
+			}
+			// 合并之后要求和在岗人数都相同的小时
+			from := hour
+			hour++
+			for hour < 24 && minimums[hour] == required && headcount[stats.HourSlot{Date: date, Hour: hour}] == staff {
+				hour++
+			}
+			shortage := (required - staff) * (hour - from)
+			penalty := c.Weight() * shortage
+			totalPenalty += penalty
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				Date:           date,
+				Message: fmt.Sprintf("%s %02d:00-%02d:00 每小时在岗 %d 人，少于要求的 %d 人",
+					date, from, hour, staff, required),
+				Severity: "error",
+				Penalty:  penalty,
+			})
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配：最低在岗人数只能通过增加分配满足，单个分配不会违反
+func (c *HourlyCoverageConstraint) EvaluateAssignment(_ *constraint.Context, _ *model.Assignment) (bool, int) {
+	return true, 0
+}
+
+// HourlyHeadcount 按分配的实际起止时间统计每个整点小时的在岗人数
+func HourlyHeadcount(assignments []*model.Assignment) map[stats.HourSlot]int {
+	headcount := make(map[stats.HourSlot]int)
+	for _, a := range assignments {
+		for _, slot := range stats.OverlappedHours(a.StartTime, a.EndTime) {
+			headcount[slot]++
+		}
+	}
+	return headcount
+}
@@ -0,0 +1,49 @@
+package builtin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestHourlyCoverageConstraint(t *testing.T) {
+	// 2024-01-15 为周一，只要求周一 10:00-22:00 每小时至少 2 人
+	rules := []model.HourlyStaffing{{Start: "10:00", End: "22:00", MinStaff: 2, Weekdays: []time.Weekday{time.Monday}}}
+	ctx := createTestContext([]*model.Assignment{
+		createAssignmentWithTime("2024-01-15", "09:00", "17:00"),
+		createAssignmentWithTime("2024-01-15", "12:30", "22:00"),
+	})
+
+	c := NewHourlyCoverageConstraint(rules)
+	valid, penalty, violations := c.Evaluate(ctx)
+	// 10、11 点只有早班，12 点起晚班到岗（部分重叠也计入），17-21 点只有晚班
+	if valid || len(violations) != 2 || penalty != c.Weight()*7 {
+		t.Fatalf("valid=%v penalty=%d violations=%+v", valid, penalty, violations)
+	}
+	if got := violations[0].Message; got != "2024-01-15 10:00-12:00 每小时在岗 1 人，少于要求的 2 人" {
+		t.Errorf("message = %s", got)
+	}
+	if got := violations[1].Message; got != "2024-01-15 17:00-22:00 每小时在岗 1 人，少于要求的 2 人" {
+		t.Errorf("message = %s", got)
+	}
+
+	// 跨零点的夜班计入次日凌晨
+	night := createAssignmentWithTime("2024-01-15", "22:00", "06:00")
+	night.EndTime = night.EndTime.Add(24 * time.Hour)
+	ctx = createTestContext([]*model.Assignment{night})
+	overnight := NewHourlyCoverageConstraint([]model.HourlyStaffing{{Start: "00:00", End: "06:00", MinStaff: 1}})
+	if _, _, violations := overnight.Evaluate(ctx); len(violations) != 6 || violations[0].Date != "2024-01-15" || violations[1].Date != "2024-01-17" {
+		t.Errorf("16 日凌晨由 15 日夜班覆盖, violations=%+v", violations)
+	}
+
+	if ok, _ := c.EvaluateAssignment(ctx, night); !ok {
+		t.Error("单个分配不应违反最低在岗人数")
+	}
+	config := map[string]interface{}{"hourly_staffing": []interface{}{
+		map[string]interface{}{"start": "10:00", "end": "22:00", "min_staff": 2.0},
+	}}
+	if got := ConfigHourlyStaffing(config); len(got) != 1 || got[0].MinStaff != 2 {
+		t.Errorf("ConfigHourlyStaffing() = %+v", got)
+	}
+}
@@ -0,0 +1,315 @@
+package builtin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// OvertimeCapConstraint 加班上限约束（硬约束）
+// 每天超过标准日工时、每周超过标准周工时的部分计为加班（按日期先后累计，周日开始），
+// 限制每日加班和每月加班小时数；含上一期的固定历史分配
+type OvertimeCapConstraint struct {
+	*BaseConstraint
+	standardDaily  float64
+	standardWeekly float64
+	maxDaily       float64 // 每日加班上限，0 表示不限制
+	maxMonthly     float64 // 每月加班上限，0 表示不限制
+}
+
+// NewOvertimeCapConstraint 创建加班上限约束
+func NewOvertimeCapConstraint(standardDaily, standardWeekly, maxDaily, maxMonthly float64) *OvertimeCapConstraint {
+	return &OvertimeCapConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"加班上限",
+			constraint.TypeOvertimeCap,
+			constraint.CategoryHard,
+			100,
+		),
+		standardDaily:  standardDaily,
+		standardWeekly: standardWeekly,
+		maxDaily:       maxDaily,
+		maxMonthly:     maxMonthly,
+	}
+}
+
+// Evaluate 评估整个排班
+func (c *OvertimeCapConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, emp := range ctx.Employees {
+		current := make(map[string]bool)
+		for _, a := range ctx.GetEmployeeAssignments(emp.ID) {
+			current[a.Date] = true
+		}
+		if len(current) == 0 {
+			continue
+		}
+		overtime := c.overtimeByDate(ctx.GetEmployeeTimeline(emp.ID))
+		dates := make([]string, 0, len(overtime))
+		for date := range overtime {
+			dates = append(dates, date)
+		}
+		sort.Strings(dates)
+
+		monthly := make(map[string]float64)
+		reported := make(map[string]bool)
+		for _, date := range dates {
+			ot := overtime[date]
+			month := date[:7]
+			monthly[month] += ot
+			if !current[date] {
+				continue // 历史分配只计入累计
+			}
+			if c.maxDaily > 0 && ot > c.maxDaily {
+				penalty := c.penalty(ot - c.maxDaily)
+				totalPenalty += penalty
+				violations = append(violations, constraint.ViolationDetail{
+					ConstraintType: c.Type(),
+					ConstraintName: c.Name(),
+					EmployeeID:     emp.ID,
+					Date:           date,
+					Message:        fmt.Sprintf("员工 %s 在 %s 加班 %.1f 小时，超过每日上限 %.0f 小时", emp.Name, date, ot, c.maxDaily),
+					Severity:       "error",
+					Penalty:        penalty,
+				})
+			}
+			if c.maxMonthly > 0 && monthly[month] > c.maxMonthly && !reported[month] {
+				reported[month] = true
+				penalty := c.penalty(monthly[month] - c.maxMonthly)
+				totalPenalty += penalty
+				violations = append(violations, constraint.ViolationDetail{
+					ConstraintType: c.Type(),
+					ConstraintName: c.Name(),
+					EmployeeID:     emp.ID,
+					Date:           date,
+					Message:        fmt.Sprintf("员工 %s 截至 %s 当月加班 %.1f 小时，超过每月上限 %.0f 小时", emp.Name, date, monthly[month], c.maxMonthly),
+					Severity:       "error",
+					Penalty:        penalty,
+				})
+			}
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+func (c *OvertimeCapConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	overtime := c.overtimeByDate(withAssignment(ctx.GetEmployeeTimeline(a.EmployeeID), a))
+	if c.maxDaily > 0 && overtime[a.Date] > c.maxDaily {
+		return false, c.penalty(overtime[a.Date] - c.maxDaily)
+	}
+	if c.maxMonthly > 0 {
+		var monthly float64
+		for date, ot := range overtime {
+			if date[:7] == a.Date[:7] {
+				monthly += ot
+			}
+		}
+		if monthly > c.maxMonthly {
+			return false, c.penalty(monthly - c.maxMonthly)
+		}
+	}
+	return true, 0
+}
+
+// overtimeByDate 按日期计算加班小时数：当天超过标准日工时的部分，
+// 加上本周（周日开始）正常工时累计超过标准周工时的部分
+func (c *OvertimeCapConstraint) overtimeByDate(assignments []*model.Assignment) map[string]float64 {
+	hours := make(map[string]float64)
+	for _, a := range assignments {
+		if len(a.Date) == len("2006-01-02") {
+			hours[a.Date] += a.WorkingHours()
+		}
+	}
+	dates := make([]string, 0, len(hours))
+	for date := range hours {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	week := model.ScheduleCycle{Weeks: 1}
+	regularByWeek := make(map[string]float64)
+	overtime := make(map[string]float64, len(dates))
+	for _, date := range dates {
+		regular := math.Min(hours[date], c.standardDaily)
+		ot := hours[date] - regular
+		start := week.Start(date)
+		if c.standardWeekly > 0 && regularByWeek[start]+regular > c.standardWeekly {
+			extra := regularByWeek[start] + regular - c.standardWeekly
+			regular -= extra
+			ot += extra
+		}
+		regularByWeek[start] += regular
+		overtime[date] = ot
+	}
+	return overtime
+}
+
+func (c *OvertimeCapConstraint) penalty(excess float64) int {
+	return c.Weight() * int(math.Ceil(excess))
+}
+
+// MinorProtectionConstraint 未成年工保护约束（硬约束）
+// 对排班日未满 18 周岁的员工（按出生日期）限制每日/每周工时、禁止夜班，并要求更长的班次间休息
+type MinorProtectionConstraint struct {
+	*BaseConstraint
+	maxDaily   float64 // 每日工时上限，0 表示不限制
+	maxWeekly  float64 // 每周工时上限（周日开始），0 表示不限制
+	night      string  // 禁止排班的夜间时段（如 "22:00-06:00"），为空表示不限制夜班
+	nightStart int     // 夜间时段起止（自零点起的分钟数）
+	nightEnd   int
+	minRest    float64 // 班次间最短休息小时数，0 表示不限制
+}
+
+// NewMinorProtectionConstraint 创建未成年工保护约束
+// night 为禁止排班的夜间时段（如 "22:00-06:00"），为空表示不限制夜班
+func NewMinorProtectionConstraint(maxDaily, maxWeekly float64, night string, minRest float64) *MinorProtectionConstraint {
+	c := &MinorProtectionConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"未成年工保护",
+			constraint.TypeMinorProtection,
+			constraint.CategoryHard,
+			100,
+		),
+		maxDaily:  maxDaily,
+		maxWeekly: maxWeekly,
+		minRest:   minRest,
+	}
+	if start, end, ok := strings.Cut(night, "-"); ok {
+		c.nightStart, c.nightEnd = parseClockMinutes(start, -1), parseClockMinutes(end, -1)
+		if c.nightStart >= 0 && c.nightEnd >= 0 {
+			c.night = night
+		}
+	}
+	return c
+}
+
+// Evaluate 评估整个排班
+func (c *MinorProtectionConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, emp := range ctx.Employees {
+		for _, a := range ctx.GetEmployeeAssignments(emp.ID) {
+			if !emp.IsMinorOn(a.Date) {
+				continue
+			}
+			if reason, penalty := c.check(a, ctx.GetEmployeeTimeline(emp.ID)); reason != "" {
+				totalPenalty += penalty
+				violations = append(violations, constraint.ViolationDetail{
+					ConstraintType: c.Type(),
+					ConstraintName: c.Name(),
+					EmployeeID:     emp.ID,
+					Date:           a.Date,
+					Message:        fmt.Sprintf("未成年员工 %s 在 %s %s", emp.Name, a.Date, reason),
+					Severity:       "error",
+					Penalty:        penalty,
+				})
+			}
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+func (c *MinorProtectionConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	emp := ctx.GetEmployee(a.EmployeeID)
+	if emp == nil || !emp.IsMinorOn(a.Date) {
+		return true, 0
+	}
+	if reason, penalty := c.check(a, withAssignment(ctx.GetEmployeeTimeline(a.EmployeeID), a)); reason != "" {
+		return false, penalty
+	}
+	return true, 0
+}
+
+// check 检查未成年员工的一个分配，timeline 为该员工包含该分配的全部分配（含固定历史），违反时返回原因和扣分
+func (c *MinorProtectionConstraint) check(a *model.Assignment, timeline []*model.Assignment) (string, int) {
+	if c.night != "" && c.inNight(a) {
+		return fmt.Sprintf("排了夜班（%s 禁止安排未成年工）", c.night), c.Weight()
+	}
+
+	week := model.ScheduleCycle{Weeks: 1}
+	var daily, weekly float64
+	for _, other := range timeline {
+		if other.Date == a.Date {
+			daily += other.WorkingHours()
+		}
+		if week.Start(other.Date) == week.Start(a.Date) {
+			weekly += other.WorkingHours()
+		}
+	}
+	if c.maxDaily > 0 && daily > c.maxDaily {
+		return fmt.Sprintf("工作 %.1f 小时，超过每日上限 %.0f 小时", daily, c.maxDaily), c.Weight() * int(math.Ceil(daily-c.maxDaily))
+	}
+	if c.maxWeekly > 0 && weekly > c.maxWeekly {
+		return fmt.Sprintf("所在周工作 %.1f 小时，超过每周上限 %.0f 小时", weekly, c.maxWeekly), c.Weight() * int(math.Ceil(weekly-c.maxWeekly))
+	}
+
+	if c.minRest > 0 {
+		for _, other := range timeline {
+			if other == a || other.ID == a.ID {
+				continue
+			}
+			var rest float64
+			switch {
+			case !a.StartTime.Before(other.EndTime):
+				rest = a.StartTime.Sub(other.EndTime).Hours()
+			case !other.StartTime.Before(a.EndTime):
+				rest = other.StartTime.Sub(a.EndTime).Hours()
+			}
+			if rest < c.minRest {
+				return fmt.Sprintf("与相邻班次间隔 %.1f 小时，少于 %.0f 小时", rest, c.minRest), c.Weight() * int(math.Ceil(c.minRest-rest))
+			}
+		}
+	}
+	return "", 0
+}
+
+// inNight 检查分配是否与禁止排班的夜间时段（当天及前一天开始的夜间时段）重叠
+func (c *MinorProtectionConstraint) inNight(a *model.Assignment) bool {
+	day, err := time.ParseInLocation("2006-01-02", a.Date, a.StartTime.Location())
+	if err != nil {
+		return false
+	}
+	for _, d := range []time.Time{day.AddDate(0, 0, -1), day} {
+		nightStart := d.Add(time.Duration(c.nightStart) * time.Minute)
+		nightEnd := d.Add(time.Duration(c.nightEnd) * time.Minute)
+		if !nightEnd.After(nightStart) {
+			nightEnd = nightEnd.AddDate(0, 0, 1)
+		}
+		if a.StartTime.Before(nightEnd) && a.EndTime.After(nightStart) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigMinorProtection 从配置中获取未成年工保护约束：
+// "minor_max_hours_per_day"、"minor_max_hours_per_week"、"minor_night_window"（如 "22:00-06:00"）、
+// "minor_min_rest_hours"，均未配置时返回 nil
+func ConfigMinorProtection(config map[string]interface{}) *MinorProtectionConstraint {
+	maxDaily := getConfigFloat(config, "minor_max_hours_per_day", 0)
+	maxWeekly := getConfigFloat(config, "minor_max_hours_per_week", 0)
+	night := getConfigString(config, "minor_night_window", "")
+	minRest := getConfigFloat(config, "minor_min_rest_hours", 0)
+	if maxDaily <= 0 && maxWeekly <= 0 && night == "" && minRest <= 0 {
+		return nil
+	}
+	return NewMinorProtectionConstraint(maxDaily, maxWeekly, night, minRest)
+}
+
+// withAssignment 返回员工全部分配加上待评估分配的新切片（不修改上下文中的切片）
+func withAssignment(timeline []*model.Assignment, a *model.Assignment) []*model.Assignment {
+	result := make([]*model.Assignment, 0, len(timeline)+1)
+	return append(append(result, timeline...), a)
+}
@@ -0,0 +1,196 @@
+package builtin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/paiban/paiban/pkg/costing"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+const (
+	// laborCostPenaltyUnit 超出预算时每超出该金额扣一次权重分
+	laborCostPenaltyUnit = 100
+	// laborCostTightRatio 期间人工成本达到预算的该比例时视为预算紧张，求解器优先安排成本低的员工
+	laborCostTightRatio = 0.8
+)
+
+// LaborCostBudgetConstraint 人工成本预算约束
+// 按员工时薪和加班/节假日倍率估算本期排班每周（周日开始）和每月的人工成本，超过预算即违反；
+// 可作为硬约束（超预算的分配直接拒绝）或软约束（按超出金额扣分）使用
+type LaborCostBudgetConstraint struct {
+	*BaseConstraint
+	hard       bool
+	weekly     float64 // 每周预算，0 表示不限制
+	monthly    float64 // 每月预算，0 表示不限制
+	calculator *costing.Calculator
+}
+
+// NewLaborCostBudgetConstraint 创建人工成本预算约束
+func NewLaborCostBudgetConstraint(hard bool, weight int, weekly, monthly float64, calculator *costing.Calculator) *LaborCostBudgetConstraint {
+	category := constraint.CategorySoft
+	if hard {
+		category = constraint.CategoryHard
+	}
+	return &LaborCostBudgetConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"人工成本预算",
+			constraint.TypeMaxLaborCost,
+			category,
+			weight,
+		),
+		hard:       hard,
+		weekly:     weekly,
+		monthly:    monthly,
+		calculator: calculator,
+	}
+}
+
+// costPeriod 预算期间
+type costPeriod struct {
+	label      string // 周/月
+	start, end string
+	budget     float64
+}
+
+// Evaluate 评估整个排班
+func (c *LaborCostBudgetConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+	isValid := true
+
+	seen := make(map[string]bool)
+	var periods []costPeriod
+	for _, a := range ctx.Assignments {
+		for _, p := range c.periods(a.Date) {
+			if !seen[p.label+p.start] {
+				seen[p.label+p.start] = true
+				periods = append(periods, p)
+			}
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		if periods[i].start != periods[j].start {
+			return periods[i].start < periods[j].start
+		}
+		return periods[i].label < periods[j].label
+	})
+
+	severity := "warning"
+	if c.hard {
+		severity = "error"
+	}
+	for _, p := range periods {
+		spent := c.periodCost(ctx, nil, p)
+		if spent <= p.budget {
+			continue
+		}
+		// 软约束同样返回无效，约束管理器才会收集违规明细（软违规不影响排班有效性）
+		isValid = false
+		penalty := c.penalty(spent - p.budget)
+		totalPenalty += penalty
+		violations = append(violations, constraint.ViolationDetail{
+			ConstraintType: c.Type(),
+			ConstraintName: c.Name(),
+			Date:           p.start,
+			Message:        fmt.Sprintf("%s %s 起人工成本 %.2f，超过预算 %.2f", p.label, p.start, spent, p.budget),
+			Severity:       severity,
+			Penalty:        penalty,
+		})
+	}
+
+	return isValid, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配 - 计算加入该分配后所在周和月的人工成本
+func (c *LaborCostBudgetConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	for _, p := range c.periods(a.Date) {
+		if spent := c.periodCost(ctx, a, p); spent > p.budget {
+			return !c.hard, c.penalty(spent - p.budget)
+		}
+	}
+	return true, 0
+}
+
+// MarginalCost 返回分配增加的人工成本（含加班、节假日倍率），以及加入后所在期间的成本是否已接近预算
+func (c *LaborCostBudgetConstraint) MarginalCost(ctx *constraint.Context, a *model.Assignment) (float64, bool) {
+	periods := c.periods(a.Date)
+	if len(periods) == 0 {
+		return 0, false
+	}
+	tight := false
+	for _, p := range periods {
+		if c.periodCost(ctx, a, p) >= p.budget*laborCostTightRatio {
+			tight = true
+		}
+	}
+	return c.periodCost(ctx, a, periods[0]) - c.periodCost(ctx, nil, periods[0]), tight
+}
+
+// periods 返回日期所在的预算期间（周日开始的周、自然月）
+func (c *LaborCostBudgetConstraint) periods(date string) []costPeriod {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil
+	}
+	var periods []costPeriod
+	if c.weekly > 0 {
+		start := d.AddDate(0, 0, -int(d.Weekday()))
+		periods = append(periods, costPeriod{label: "周", start: start.Format("2006-01-02"),
+			end: start.AddDate(0, 0, 6).Format("2006-01-02"), budget: c.weekly})
+	}
+	if c.monthly > 0 {
+		start := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+		periods = append(periods, costPeriod{label: "月", start: start.Format("2006-01-02"),
+			end: start.AddDate(0, 1, -1).Format("2006-01-02"), budget: c.monthly})
+	}
+	return periods
+}
+
+// periodCost 计算期间内本期排班的人工成本，extra 为待评估的分配（可为空）
+// 从期间开始所在周的周日起计入分配（含固定历史），使周加班按完整的周计算；只统计本期排班周期内的日期
+func (c *LaborCostBudgetConstraint) periodCost(ctx *constraint.Context, extra *model.Assignment, p costPeriod) float64 {
+	from := model.BudgetWeekStart(p.start)
+	var list []*model.Assignment
+	for _, group := range [][]*model.Assignment{ctx.History, ctx.Assignments} {
+		for _, a := range group {
+			if a.Date >= from && a.Date <= p.end && (extra == nil || a.ID != extra.ID) {
+				list = append(list, a)
+			}
+		}
+	}
+	if extra != nil {
+		list = append(list, extra)
+	}
+	if len(list) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, day := range c.calculator.Calculate(ctx.Employees, list).ByDay {
+		if day.Date < p.start || (ctx.StartDate != "" && day.Date < ctx.StartDate) ||
+			(ctx.EndDate != "" && day.Date > ctx.EndDate) {
+			continue
+		}
+		total += day.Cost
+	}
+	return total
+}
+
+func (c *LaborCostBudgetConstraint) penalty(excess float64) int {
+	return c.Weight() * int(math.Ceil(excess/laborCostPenaltyUnit))
+}
+
+// ConfigCostCalculator 从配置中获取人工成本计算器：标准工时 "standard_hours_per_day"、"standard_hours_per_week"，
+// 加班倍率 "overtime_pay_rate"，节假日倍率及日历与节假日约束一致（"holiday_bonus_rate"、"holidays"、"holiday_calendar"）
+func ConfigCostCalculator(config map[string]interface{}) *costing.Calculator {
+	return costing.NewCalculator(costing.Config{
+		StandardDailyHours:  getConfigFloat(config, "standard_hours_per_day", 0),
+		StandardWeeklyHours: getConfigFloat(config, "standard_hours_per_week", 0),
+		OvertimeRate:        getConfigFloat(config, "overtime_pay_rate", 0),
+		HolidayRate:         getConfigFloat(config, "holiday_bonus_rate", 0),
+	}, ConfigHolidays(config))
+}
@@ -0,0 +1,72 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestLaborCostBudgetConstraint(t *testing.T) {
+	// 时薪 20，周一至周三每天 8 小时：本周成本 480
+	var week []*model.Assignment
+	for _, date := range []string{"2024-01-15", "2024-01-16", "2024-01-17"} {
+		week = append(week, createAssignmentWithTime(date, "09:00", "17:00"))
+	}
+	ctx := createTestContext(week)
+	emp := ctx.Employees[0]
+	emp.HourlyRate = 20
+	calc := ConfigCostCalculator(map[string]interface{}{})
+
+	if valid, _, _ := NewLaborCostBudgetConstraint(false, 80, 500, 0, calc).Evaluate(ctx); !valid {
+		t.Error("未超过周预算应通过")
+	}
+
+	// 软约束：超出 80，按每 100 扣一次权重分
+	valid, penalty, violations := NewLaborCostBudgetConstraint(false, 80, 400, 0, calc).Evaluate(ctx)
+	if valid || penalty != 80 || len(violations) != 1 || violations[0].Date != "2024-01-14" || violations[0].Severity != "warning" {
+		t.Fatalf("超过周预算应报告违规: valid=%v penalty=%d violations=%+v", valid, penalty, violations)
+	}
+
+	thu := createAssignmentWithTime("2024-01-18", "09:00", "17:00")
+	thu.EmployeeID = emp.ID
+	if ok, p := NewLaborCostBudgetConstraint(false, 80, 500, 0, calc).EvaluateAssignment(ctx, thu); !ok || p != 160 {
+		t.Errorf("软约束超预算应允许分配并扣分: ok=%v penalty=%d", ok, p)
+	}
+	if ok, _ := NewLaborCostBudgetConstraint(true, 100, 500, 0, calc).EvaluateAssignment(ctx, thu); ok {
+		t.Error("硬约束超预算的分配应被拒绝")
+	}
+
+	// 月预算
+	if valid, _, violations := NewLaborCostBudgetConstraint(true, 100, 0, 300, calc).Evaluate(ctx); valid || violations[0].Date != "2024-01-01" {
+		t.Errorf("超过月预算应报告违规: %+v", violations)
+	}
+}
+
+func TestLaborCostBudgetConstraint_MarginalCost(t *testing.T) {
+	ctx := createTestContext([]*model.Assignment{createAssignmentWithTime("2024-01-15", "09:00", "17:00")})
+	ctx.Employees[0].HourlyRate = 20
+	cheap := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}, Name: "低时薪", HourlyRate: 15}
+	ctx.SetEmployees(append(ctx.Employees, cheap))
+
+	c := NewLaborCostBudgetConstraint(false, 80, 400, 0, ConfigCostCalculator(map[string]interface{}{}))
+
+	// 同一天再排 4 小时：原员工超过标准日工时计加班 4×20×1.5，新员工 4×15
+	overtime := createAssignmentWithTime("2024-01-15", "18:00", "22:00")
+	overtime.EmployeeID = ctx.Employees[0].ID
+	other := createAssignmentWithTime("2024-01-15", "18:00", "22:00")
+	other.EmployeeID = cheap.ID
+
+	cost, tight := c.MarginalCost(ctx, overtime)
+	if cost != 120 || tight {
+		t.Errorf("加班分配的增量成本 = %v tight=%v", cost, tight)
+	}
+	if cost, _ := c.MarginalCost(ctx, other); cost != 60 {
+		t.Errorf("低时薪员工的增量成本 = %v", cost)
+	}
+
+	// 预算 270：加入后成本 220，达到预算的 80% 以上
+	if _, tight := NewLaborCostBudgetConstraint(false, 80, 270, 0, ConfigCostCalculator(nil)).MarginalCost(ctx, other); !tight {
+		t.Error("接近预算时应视为预算紧张")
+	}
+}
@@ -0,0 +1,108 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestOvertimeCapConstraint(t *testing.T) {
+	// 标准日工时 8 小时，每日加班不超过 3 小时，每月不超过 6 小时
+	c := NewOvertimeCapConstraint(8, 40, 3, 6)
+
+	// 单日 12 小时：加班 4 小时超过每日上限
+	ctx := createTestContext([]*model.Assignment{createAssignmentWithTime("2024-01-15", "06:00", "18:00")})
+	valid, _, violations := c.Evaluate(ctx)
+	if valid || len(violations) != 1 || !strings.Contains(violations[0].Message, "每日上限") {
+		t.Fatalf("单日加班 4 小时应违反每日上限: valid=%v violations=%+v", valid, violations)
+	}
+
+	// 周一至周四每天 10 小时：每天加班 2 小时，周四累计 8 小时超过每月上限
+	var week []*model.Assignment
+	for _, date := range []string{"2024-01-15", "2024-01-16", "2024-01-17", "2024-01-18"} {
+		week = append(week, createAssignmentWithTime(date, "08:00", "18:00"))
+	}
+	ctx = createTestContext(week)
+	valid, _, violations = c.Evaluate(ctx)
+	if valid || len(violations) != 1 || violations[0].Date != "2024-01-18" {
+		t.Fatalf("月加班超限应只在首次超限的日期报告一次: valid=%v violations=%+v", valid, violations)
+	}
+
+	fri := createAssignmentWithTime("2024-01-19", "08:00", "17:00")
+	fri.EmployeeID = ctx.Employees[0].ID
+	if ok, _ := c.EvaluateAssignment(ctx, fri); ok {
+		t.Error("月加班已超限时再排加班应不通过")
+	}
+
+	// 不限制每月加班时，周正常工时未超过 40 小时的日加班 2 小时通过
+	if valid, _, _ := NewOvertimeCapConstraint(8, 40, 3, 0).Evaluate(ctx); !valid {
+		t.Error("每日加班未超过上限应通过")
+	}
+}
+
+func TestOvertimeCapConstraint_WeeklyOvertime(t *testing.T) {
+	// 周一至周六每天 8 小时：周六的 8 小时超过标准周工时，全部计为加班
+	var week []*model.Assignment
+	for _, date := range []string{"2024-01-15", "2024-01-16", "2024-01-17", "2024-01-18", "2024-01-19", "2024-01-20"} {
+		week = append(week, createAssignmentWithTime(date, "09:00", "17:00"))
+	}
+	ctx := createTestContext(week)
+	valid, _, violations := NewOvertimeCapConstraint(8, 40, 3, 0).Evaluate(ctx)
+	if valid || len(violations) != 1 || violations[0].Date != "2024-01-20" {
+		t.Fatalf("超过标准周工时的部分应计为加班: valid=%v violations=%+v", valid, violations)
+	}
+}
+
+func TestMinorProtectionConstraint(t *testing.T) {
+	c := NewMinorProtectionConstraint(8, 40, "22:00-06:00", 12)
+
+	day := createAssignmentWithTime("2024-01-15", "12:00", "20:00")
+	ctx := createTestContext([]*model.Assignment{day})
+	emp := ctx.Employees[0]
+
+	night := createAssignmentWithTime("2024-01-16", "18:00", "23:00")
+	night.EmployeeID = emp.ID
+	long := createAssignmentWithTime("2024-01-17", "08:00", "18:00")
+	long.EmployeeID = emp.ID
+	early := createAssignmentWithTime("2024-01-16", "06:00", "11:00")
+	early.EmployeeID = emp.ID
+
+	// 未登记出生日期时视为成年
+	if ok, _ := c.EvaluateAssignment(ctx, night); !ok {
+		t.Error("成年员工不应受未成年工保护限制")
+	}
+
+	emp.BirthDate = "2008-06-01" // 2024-01 时 15 周岁
+	if ok, _ := c.EvaluateAssignment(ctx, night); ok {
+		t.Error("未成年工不应排夜班")
+	}
+	if ok, _ := c.EvaluateAssignment(ctx, long); ok {
+		t.Error("未成年工每日工作不应超过 8 小时")
+	}
+	if ok, _ := c.EvaluateAssignment(ctx, early); ok {
+		t.Error("未成年工班次间休息不应少于 12 小时")
+	}
+
+	ctx.SetAssignments([]*model.Assignment{day, night})
+	valid, _, violations := c.Evaluate(ctx)
+	if valid || len(violations) != 1 || !strings.Contains(violations[0].Message, "夜班") {
+		t.Fatalf("未成年工排夜班应违反约束: valid=%v violations=%+v", valid, violations)
+	}
+
+	// 年满 18 周岁后不再限制
+	emp.BirthDate = "2006-01-15"
+	if valid, _, _ := c.Evaluate(ctx); !valid {
+		t.Error("年满 18 周岁的员工不应受限制")
+	}
+}
+
+func TestConfigMinorProtection(t *testing.T) {
+	if ConfigMinorProtection(map[string]interface{}{}) != nil {
+		t.Error("未配置 minor_* 参数时不应启用未成年工保护")
+	}
+	c := ConfigMinorProtection(map[string]interface{}{"minor_night_window": "22:00-06:00"})
+	if c == nil || c.night != "22:00-06:00" {
+		t.Fatalf("应按配置启用夜班限制: %+v", c)
+	}
+}
@@ -84,9 +84,11 @@ func (c *MaxHoursPerDayConstraint) EvaluateAssignment(ctx *constraint.Context, a
 }
 
 // MaxHoursPerWeekConstraint 每周最大工时约束
+// 按排班周期（两周/四周轮班）统计时为每周期最大工时约束，周期内各周工时可以不均衡
 type MaxHoursPerWeekConstraint struct {
 	*BaseConstraint
 	maxHours int
+	cycle    model.ScheduleCycle // 统计窗口，按周统计时为 1 周（周日开始）
 }
 
 // NewMaxHoursPerWeekConstraint 创建每周最大工时约束
@@ -99,10 +101,25 @@ func NewMaxHoursPerWeekConstraint(maxHours int) *MaxHoursPerWeekConstraint {
 			100,
 		),
 		maxHours: maxHours,
+		cycle:    model.ScheduleCycle{Weeks: 1},
 	}
 }
 
-// Evaluate 评估整个排班 - 按周分割计算工时
+// NewMaxHoursPerCycleConstraint 创建每周期最大工时约束，maxHours 为整个周期的工时上限
+func NewMaxHoursPerCycleConstraint(maxHours int, cycle model.ScheduleCycle) *MaxHoursPerWeekConstraint {
+	return &MaxHoursPerWeekConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"每周期最大工时",
+			constraint.TypeMaxHoursPerCycle,
+			constraint.CategoryHard,
+			100,
+		),
+		maxHours: maxHours,
+		cycle:    cycle,
+	}
+}
+
+// Evaluate 评估整个排班 - 按周（周期）分割计算工时
 func (c *MaxHoursPerWeekConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
 	var violations []constraint.ViolationDetail
 	totalPenalty := 0
@@ -135,7 +152,7 @@ func (c *MaxHoursPerWeekConstraint) Evaluate(ctx *constraint.Context) (bool, int
 					ConstraintName: c.Name(),
 					EmployeeID:     emp.ID,
 					Date:           weekStart,
-					Message:        fmt.Sprintf("员工 %s 在周 %s 工作 %.1f 小时，超过限制 %d 小时", emp.Name, weekStart, hours, c.maxHours),
+					Message:        fmt.Sprintf("员工 %s 在%s %s 工作 %.1f 小时，超过限制 %d 小时", emp.Name, c.windowName(), weekStart, hours, c.maxHours),
 					Severity:       "error",
 					Penalty:        penalty,
 				})
@@ -146,19 +163,20 @@ func (c *MaxHoursPerWeekConstraint) Evaluate(ctx *constraint.Context) (bool, int
 	return isValid, totalPenalty, violations
 }
 
-// getWeekStart 获取日期所在周的开始日期（周日）
-func (c *MaxHoursPerWeekConstraint) getWeekStart(dateStr string) string {
-	t, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		return dateStr
+// windowName 统计窗口名称
+func (c *MaxHoursPerWeekConstraint) windowName() string {
+	if c.cycle.Weeks > 1 {
+		return fmt.Sprintf("%d周周期", c.cycle.Weeks)
 	}
-	// 计算到周日的天数偏移
-	weekday := int(t.Weekday())
-	weekStart := t.AddDate(0, 0, -weekday)
-	return weekStart.Format("2006-01-02")
+	return "周"
+}
+
+// getWeekStart 获取日期所在周（周期）的开始日期（周日）
+func (c *MaxHoursPerWeekConstraint) getWeekStart(dateStr string) string {
+	return c.cycle.Start(dateStr)
 }
 
-// getWeeksInRange 获取日期范围内的所有周的起始日期
+// getWeeksInRange 获取日期范围内的所有周（周期）的起始日期
 func (c *MaxHoursPerWeekConstraint) getWeeksInRange(startDate, endDate string) []string {
 	start, err := time.Parse("2006-01-02", startDate)
 	if err != nil {
@@ -183,11 +201,10 @@ func (c *MaxHoursPerWeekConstraint) getWeeksInRange(startDate, endDate string) [
 	return weeks
 }
 
-// EvaluateAssignment 评估单个分配 - 计算该分配所在周的工时
+// EvaluateAssignment 评估单个分配 - 计算该分配所在周（周期）的工时
 func (c *MaxHoursPerWeekConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
 	// 计算该员工在该分配所在周的已有工时
-	weekStart := c.getWeekStart(a.Date)
-	weekEnd := c.getWeekEnd(weekStart)
+	weekStart, weekEnd := c.cycle.Bounds(a.Date)
 
 	currentHours := ctx.GetEmployeeHoursInRange(a.EmployeeID, weekStart, weekEnd)
 	newHours := a.WorkingHours()
@@ -201,16 +218,6 @@ func (c *MaxHoursPerWeekConstraint) EvaluateAssignment(ctx *constraint.Context,
 	return true, 0
 }
 
-// getWeekEnd 获取周结束日期（周六）
-func (c *MaxHoursPerWeekConstraint) getWeekEnd(weekStartStr string) string {
-	t, err := time.Parse("2006-01-02", weekStartStr)
-	if err != nil {
-		return weekStartStr
-	}
-	weekEnd := t.AddDate(0, 0, 6)
-	return weekEnd.Format("2006-01-02")
-}
-
 // MaxHoursPerPeriodConstraint 排班周期最大工时约束（支持月度工时）
 // 适用于按月度或其他长周期计算工时的场景
 type MaxHoursPerPeriodConstraint struct {
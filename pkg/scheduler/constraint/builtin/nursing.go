@@ -246,13 +246,7 @@ func (c *MaxPatientsPerDayConstraint) Evaluate(ctx *constraint.Context) (bool, i
 
 // EvaluateAssignment 评估单个分配
 func (c *MaxPatientsPerDayConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
-	count := 0
-	for _, existing := range ctx.Assignments {
-		if existing.EmployeeID == a.EmployeeID && existing.Date == a.Date {
-			count++
-		}
-	}
-
+	count := ctx.GetEmployeeDayStats(a.EmployeeID, a.Date).Shifts
 	if count >= c.maxPatients {
 		return false, c.Weight()
 	}
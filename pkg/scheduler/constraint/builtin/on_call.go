@@ -0,0 +1,156 @@
+package builtin
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// DefaultMaxOnCallPerWeek 默认每人每周最多待命次数
+const DefaultMaxOnCallPerWeek = 2
+
+// OnCallConstraint 待命安排约束（硬约束）
+// 只检查 Context.OnCall 中的待命分配：每人每周（自然周，周一开始）待命不超过 maxPerWeek 次（0 表示不限），
+// 夜班次日不能待命，待命时段不能与员工的常规班次重叠
+type OnCallConstraint struct {
+	*BaseConstraint
+	maxPerWeek int
+}
+
+// NewOnCallConstraint 创建待命安排约束
+func NewOnCallConstraint(maxPerWeek int) *OnCallConstraint {
+	return &OnCallConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"待命安排",
+			constraint.TypeOnCall,
+			constraint.CategoryHard,
+			100,
+		),
+		maxPerWeek: maxPerWeek,
+	}
+}
+
+// MaxPerWeek 返回每人每周最多待命次数，0 表示不限
+func (c *OnCallConstraint) MaxPerWeek() int {
+	return c.maxPerWeek
+}
+
+// Evaluate 评估全部待命分配
+func (c *OnCallConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+	add := func(emp *model.Employee, date, message string) {
+		penalty := c.Weight()
+		totalPenalty += penalty
+		violations = append(violations, constraint.ViolationDetail{
+			ConstraintType: c.Type(),
+			ConstraintName: c.Name(),
+			EmployeeID:     emp.ID,
+			Date:           date,
+			Message:        message,
+			Severity:       "error",
+			Penalty:        penalty,
+		})
+	}
+
+	for _, emp := range ctx.Employees {
+		onCall := ctx.GetEmployeeOnCall(emp.ID)
+		if len(onCall) == 0 {
+			continue
+		}
+		byWeek := make(map[string][]string)
+		for _, a := range onCall {
+			if msg := c.conflict(ctx, emp, a); msg != "" {
+				add(emp, a.Date, msg)
+			}
+			week := onCallWeek(a.Date)
+			byWeek[week] = append(byWeek[week], a.Date)
+		}
+		if c.maxPerWeek <= 0 {
+			continue
+		}
+		weeks := make([]string, 0, len(byWeek))
+		for week := range byWeek {
+			weeks = append(weeks, week)
+		}
+		sort.Strings(weeks)
+		for _, week := range weeks {
+			if dates := byWeek[week]; len(dates) > c.maxPerWeek {
+				sort.Strings(dates)
+				add(emp, dates[0], fmt.Sprintf("员工 %s 在 %s 待命 %d 次，超过每周 %d 次的限制", emp.Name, week, len(dates), c.maxPerWeek))
+			}
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+// 待命分配：本周待命次数已满、前一天上夜班或与常规班次重叠时不可分配；
+// 常规分配：与已有待命时段重叠或为待命前一天的夜班时不可分配（优化阶段调整常规分配时保持待命安排有效）
+func (c *OnCallConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	shift := ctx.GetShift(a.ShiftID)
+	emp := ctx.GetEmployee(a.EmployeeID)
+	if shift == nil || emp == nil {
+		return true, 0
+	}
+	if !shift.IsOnCall() {
+		for _, oc := range ctx.GetEmployeeOnCall(a.EmployeeID) {
+			if (shift.IsNightShift() && oc.Date == addDays(a.Date, 1)) ||
+				(a.StartTime.Before(oc.EndTime) && oc.StartTime.Before(a.EndTime)) {
+				return false, c.Weight()
+			}
+		}
+		return true, 0
+	}
+	if c.conflict(ctx, emp, a) != "" {
+		return false, c.Weight()
+	}
+	if c.maxPerWeek > 0 && c.weekCount(ctx, a.EmployeeID, onCallWeek(a.Date), a.ID)+1 > c.maxPerWeek {
+		return false, c.Weight()
+	}
+	return true, 0
+}
+
+// conflict 检查待命分配与员工常规班次的冲突，没有冲突时返回空字符串
+func (c *OnCallConstraint) conflict(ctx *constraint.Context, emp *model.Employee, a *model.Assignment) string {
+	// 含上一期的固定历史分配：上一期最后一天的夜班同样要求次日不待命
+	if ctx.WorksOn(emp.ID, addDays(a.Date, -1), true) {
+		return fmt.Sprintf("员工 %s 在 %s 夜班后次日 %s 不能待命", emp.Name, addDays(a.Date, -1), a.Date)
+	}
+	for _, r := range ctx.GetEmployeeTimeline(emp.ID) {
+		if r.Date < addDays(a.Date, -1) || r.Date > addDays(a.Date, 1) {
+			continue
+		}
+		if a.StartTime.Before(r.EndTime) && r.StartTime.Before(a.EndTime) {
+			return fmt.Sprintf("员工 %s 在 %s 的待命时段 %s-%s 与常规班次 %s-%s 重叠", emp.Name, a.Date,
+				a.StartTime.Format("15:04"), a.EndTime.Format("15:04"), r.StartTime.Format("15:04"), r.EndTime.Format("15:04"))
+		}
+	}
+	return ""
+}
+
+// weekCount 统计员工某周的待命次数（不含 exclude）
+func (c *OnCallConstraint) weekCount(ctx *constraint.Context, empID uuid.UUID, week string, exclude uuid.UUID) int {
+	count := 0
+	for _, a := range ctx.GetEmployeeOnCall(empID) {
+		if a.ID != exclude && onCallWeek(a.Date) == week {
+			count++
+		}
+	}
+	return count
+}
+
+// onCallWeek 返回日期所在的自然周，如 "2026-W03"
+func onCallWeek(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
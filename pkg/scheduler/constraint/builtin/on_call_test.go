@@ -0,0 +1,87 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestOnCallConstraint(t *testing.T) {
+	morning := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "M", ShiftType: "morning"}
+	night := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "N", ShiftType: "night"}
+	standby := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "OC", ShiftType: "on_call"}
+	assign := func(date, start, end string, shift *model.Shift) *model.Assignment {
+		a := createAssignmentWithTime(date, start, end)
+		a.ShiftID = shift.ID
+		return a
+	}
+
+	// 2024-01-15 为周一：15 日夜班，17 日早班
+	assignments := []*model.Assignment{
+		assign("2024-01-15", "22:00", "23:59", night),
+		assign("2024-01-17", "08:00", "16:00", morning),
+	}
+	ctx := createTestContext(assignments)
+	ctx.SetShifts([]*model.Shift{morning, night, standby})
+	empID := assignments[0].EmployeeID
+
+	c := NewOnCallConstraint(2)
+	probe := func(date, start, end string) bool {
+		a := assign(date, start, end, standby)
+		a.EmployeeID = empID
+		ok, _ := c.EvaluateAssignment(ctx, a)
+		return ok
+	}
+	if probe("2024-01-16", "18:00", "22:00") {
+		t.Error("夜班次日不能待命")
+	}
+	if probe("2024-01-17", "12:00", "20:00") {
+		t.Error("待命时段与常规班次重叠时不能待命")
+	}
+	if !probe("2024-01-17", "18:00", "22:00") {
+		t.Error("常规班次结束后可以待命")
+	}
+
+	// 本周已待命 2 次
+	onCall := []*model.Assignment{
+		assign("2024-01-18", "18:00", "22:00", standby),
+		assign("2024-01-19", "18:00", "22:00", standby),
+	}
+	for _, a := range onCall {
+		a.EmployeeID = empID
+	}
+	ctx.SetOnCall(onCall)
+	if probe("2024-01-20", "18:00", "22:00") {
+		t.Error("本周待命次数已满时不能待命")
+	}
+	if !probe("2024-01-22", "18:00", "22:00") {
+		t.Error("下一周可以待命")
+	}
+	if valid, _, violations := c.Evaluate(ctx); !valid {
+		t.Fatalf("待命安排有效时不应违规: %v", violations)
+	}
+
+	// 常规分配不能与已有待命冲突
+	regular := assign("2024-01-18", "16:00", "19:00", morning)
+	regular.EmployeeID = empID
+	if ok, _ := c.EvaluateAssignment(ctx, regular); ok {
+		t.Error("常规班次与待命时段重叠时不可分配")
+	}
+	regular = assign("2024-01-18", "22:00", "23:59", night)
+	regular.EmployeeID = empID
+	if ok, _ := c.EvaluateAssignment(ctx, regular); ok {
+		t.Error("待命前一天不能上夜班")
+	}
+
+	extra := assign("2024-01-16", "18:00", "22:00", standby)
+	extra.EmployeeID = empID
+	ctx.SetOnCall(append(onCall, extra))
+	valid, penalty, violations := c.Evaluate(ctx)
+	if valid || len(violations) != 2 || penalty != 2*c.Weight() {
+		t.Fatalf("夜班次日待命和超过每周次数应各违规一次: valid=%v penalty=%d violations=%v", valid, penalty, violations)
+	}
+	if valid, _, _ := NewOnCallConstraint(0).Evaluate(ctx); valid {
+		t.Error("不限次数时仍检查夜班次日待命")
+	}
+}
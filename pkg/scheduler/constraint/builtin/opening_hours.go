@@ -0,0 +1,71 @@
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// StoreOpeningHoursConstraint 门店营业时间约束（硬约束）
+// 上班门店（未指定时为员工所属门店）配置了营业时间时，其班次必须完整落在营业时间段内（含开店准备和闭店收尾时间）；
+// 节假日等特殊日期按特殊营业时间判断，闭店日不可排班
+type StoreOpeningHoursConstraint struct {
+	*BaseConstraint
+	hours []model.StoreOpeningHours
+}
+
+// NewStoreOpeningHoursConstraint 创建门店营业时间约束
+func NewStoreOpeningHoursConstraint(hours []model.StoreOpeningHours) *StoreOpeningHoursConstraint {
+	return &StoreOpeningHoursConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"门店营业时间",
+			constraint.TypeStoreOpeningHours,
+			constraint.CategoryHard,
+			100,
+		),
+		hours: hours,
+	}
+}
+
+// Evaluate 评估整个排班
+func (c *StoreOpeningHoursConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, emp := range ctx.Employees {
+		for _, a := range ctx.GetEmployeeAssignments(emp.ID) {
+			store := a.Store(emp)
+			hours := model.ResolveOpeningHours(c.hours, store)
+			if hours == nil || hours.Covers(a.Date, a.StartTime, a.EndTime) {
+				continue
+			}
+			totalPenalty += c.Weight()
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				EmployeeID:     emp.ID,
+				Date:           a.Date,
+				Message: fmt.Sprintf("员工 %s 在 %s 的班次 %s-%s 不在门店 %s 的营业时间内",
+					emp.Name, a.Date, a.StartTime.Format("15:04"), a.EndTime.Format("15:04"), store),
+				Severity: "error",
+				Penalty:  c.Weight(),
+			})
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+func (c *StoreOpeningHoursConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	emp := ctx.GetEmployee(a.EmployeeID)
+	if emp == nil {
+		return true, 0
+	}
+	hours := model.ResolveOpeningHours(c.hours, a.Store(emp))
+	if hours == nil || hours.Covers(a.Date, a.StartTime, a.EndTime) {
+		return true, 0
+	}
+	return false, c.Weight()
+}
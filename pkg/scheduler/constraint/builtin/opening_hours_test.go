@@ -0,0 +1,41 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestStoreOpeningHoursConstraint(t *testing.T) {
+	hours := []model.StoreOpeningHours{{
+		StoreID:  "store-a",
+		Weekly:   []model.OpeningWindow{{Open: "10:00", Close: "22:00"}},
+		Holidays: []model.SpecialHours{{Date: "2024-01-16", Closed: true}},
+	}}
+	c := NewStoreOpeningHoursConstraint(hours)
+
+	inside := createAssignmentWithTime("2024-01-15", "10:00", "18:00")
+	ctx := createTestContext([]*model.Assignment{inside})
+	ctx.Employees[0].StoreID = "store-a"
+	if valid, _, _ := c.Evaluate(ctx); !valid {
+		t.Error("营业时间内的排班应通过")
+	}
+
+	early := createAssignmentWithTime("2024-01-15", "08:00", "16:00")
+	early.EmployeeID = inside.EmployeeID
+	if ok, _ := c.EvaluateAssignment(ctx, early); ok {
+		t.Error("开店前的班次应被拒绝")
+	}
+
+	closed := createAssignmentWithTime("2024-01-16", "10:00", "18:00")
+	closed.EmployeeID = inside.EmployeeID
+	if ok, _ := c.EvaluateAssignment(ctx, closed); ok {
+		t.Error("闭店日的班次应被拒绝")
+	}
+
+	// 未配置营业时间的门店不受限制
+	ctx.Employees[0].StoreID = "store-b"
+	if ok, _ := c.EvaluateAssignment(ctx, early); !ok {
+		t.Error("未配置营业时间的门店不应受限")
+	}
+}
@@ -0,0 +1,139 @@
+package builtin
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// PostNightRestConstraint 夜班后休息约束（硬约束）
+// 员工连续上夜班达到 blockNights 天后，夜班块结束后的 restDays 天内不能再排任何班次
+type PostNightRestConstraint struct {
+	*BaseConstraint
+	blockNights int
+	restDays    int
+}
+
+// NewPostNightRestConstraint 创建夜班后休息约束
+func NewPostNightRestConstraint(blockNights, restDays int) *PostNightRestConstraint {
+	if blockNights < 1 {
+		blockNights = 1
+	}
+	return &PostNightRestConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"夜班后休息",
+			constraint.TypePostNightRest,
+			constraint.CategoryHard,
+			100,
+		),
+		blockNights: blockNights,
+		restDays:    restDays,
+	}
+}
+
+// Evaluate 评估整个排班：达到长度的夜班块结束后，休息期内有本期分配时违反
+func (c *PostNightRestConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, emp := range ctx.Employees {
+		// 含上一期的固定历史分配，上一期末尾的夜班块同样要求休息
+		var blockEnds []string
+		seen := make(map[string]bool)
+		for _, a := range ctx.GetEmployeeTimeline(emp.ID) {
+			shift := ctx.GetShift(a.ShiftID)
+			if shift == nil || !shift.IsNightShift() || seen[a.Date] {
+				continue
+			}
+			seen[a.Date] = true
+			if !ctx.WorksOn(emp.ID, addDays(a.Date, 1), true) {
+				blockEnds = append(blockEnds, a.Date)
+			}
+		}
+		sort.Strings(blockEnds)
+
+		for _, end := range blockEnds {
+			nights := ctx.GetEmployeeConsecutiveNights(emp.ID, end) + 1
+			if nights < c.blockNights {
+				continue
+			}
+			for k := 1; k <= c.restDays; k++ {
+				date := addDays(end, k)
+				// 只统计本期分配，固定历史分配之间的冲突不在本期处理范围内
+				if ctx.GetEmployeeDayStats(emp.ID, date).Shifts == 0 {
+					continue
+				}
+				penalty := c.Weight()
+				totalPenalty += penalty
+				violations = append(violations, constraint.ViolationDetail{
+					ConstraintType: c.Type(),
+					ConstraintName: c.Name(),
+					EmployeeID:     emp.ID,
+					Date:           date,
+					Message:        fmt.Sprintf("员工 %s 在 %s 结束连续 %d 天夜班，%s 应休息（夜班后需休息 %d 天）", emp.Name, end, nights, date, c.restDays),
+					Severity:       "error",
+					Penalty:        penalty,
+				})
+				break
+			}
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+// 既检查分配是否落在之前夜班块的休息期内，也检查夜班分配形成的夜班块是否挤占了之后已有分配的休息期
+func (c *PostNightRestConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	if c.restDays <= 0 {
+		return true, 0
+	}
+	shift := ctx.GetShift(a.ShiftID)
+	night := shift != nil && shift.IsNightShift()
+
+	// 向前找最近一个有分配的日期，是达到长度的夜班块的最后一天时不可分配
+	// 紧接在夜班后的夜班是延续夜班块，不受限
+	for k := 1; k <= c.restDays; k++ {
+		date := addDays(a.Date, -k)
+		if !ctx.WorksOn(a.EmployeeID, date, false) {
+			continue
+		}
+		if ctx.WorksOn(a.EmployeeID, date, true) && !(k == 1 && night) &&
+			ctx.GetEmployeeConsecutiveNights(a.EmployeeID, date)+1 >= c.blockNights {
+			return false, c.Weight()
+		}
+		break
+	}
+	if !night {
+		return true, 0
+	}
+
+	// 加上此夜班后的夜班块
+	end := a.Date
+	after := 0
+	for ctx.WorksOn(a.EmployeeID, addDays(end, 1), true) && after < 30 {
+		end = addDays(end, 1)
+		after++
+	}
+	if ctx.GetEmployeeConsecutiveNights(a.EmployeeID, a.Date)+1+after < c.blockNights {
+		return true, 0
+	}
+	for k := 1; k <= c.restDays; k++ {
+		if ctx.WorksOn(a.EmployeeID, addDays(end, k), false) {
+			return false, c.Weight()
+		}
+	}
+	return true, 0
+}
+
+// addDays 返回 date 之后 n 天的日期（n 为负数时为之前）
+func addDays(date string, n int) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return t.AddDate(0, 0, n).Format("2006-01-02")
+}
@@ -0,0 +1,84 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestPostNightRestConstraint(t *testing.T) {
+	morning := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "M", ShiftType: "morning"}
+	night := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "N", ShiftType: "night"}
+	assign := func(date string, shift *model.Shift) *model.Assignment {
+		a := createAssignmentWithTime(date, "22:00", "23:00")
+		a.ShiftID = shift.ID
+		return a
+	}
+
+	// 15-17 日连续 3 个夜班，18 日休息，19 日早班
+	assignments := []*model.Assignment{
+		assign("2024-01-15", night), assign("2024-01-16", night), assign("2024-01-17", night),
+		assign("2024-01-19", morning),
+	}
+	ctx := createTestContext(assignments)
+	ctx.SetShifts([]*model.Shift{morning, night})
+	empID := assignments[0].EmployeeID
+
+	c := NewPostNightRestConstraint(3, 2)
+	valid, penalty, violations := c.Evaluate(ctx)
+	if valid || penalty != c.Weight() || len(violations) != 1 || violations[0].Date != "2024-01-19" {
+		t.Fatalf("夜班块后休息期内排班应违规: valid=%v penalty=%d violations=%v", valid, penalty, violations)
+	}
+	if valid, _, _ := NewPostNightRestConstraint(4, 2).Evaluate(ctx); !valid {
+		t.Error("夜班块未达到长度时不要求休息")
+	}
+	if valid, _, _ := NewPostNightRestConstraint(3, 1).Evaluate(ctx); !valid {
+		t.Error("休息期之后排班不应违规")
+	}
+
+	probe := func(date string, shift *model.Shift) bool {
+		a := assign(date, shift)
+		a.EmployeeID = empID
+		ok, _ := c.EvaluateAssignment(ctx, a)
+		return ok
+	}
+	if probe("2024-01-18", morning) {
+		t.Error("夜班块结束次日不可排班")
+	}
+	if probe("2024-01-18", night) {
+		t.Error("延续夜班块后的休息期内已有分配时不可分配")
+	}
+	ctx.SetAssignments(assignments[:3])
+	if !probe("2024-01-18", night) {
+		t.Error("紧接夜班的夜班是延续夜班块，不受休息限制")
+	}
+	ctx.SetAssignments(assignments)
+	if !probe("2024-01-20", morning) {
+		t.Error("休息期之后可以排班")
+	}
+
+	// 向后检查：14 日加夜班使 14-16 日形成 3 个夜班的块时，18 日之前的休息期内已有分配
+	ctx.SetAssignments([]*model.Assignment{assignments[0], assignments[1], assignments[3]})
+	if !probe("2024-01-17", morning) {
+		t.Error("两个夜班的块未达到长度，次日可以排班")
+	}
+	if probe("2024-01-17", night) {
+		t.Error("夜班形成的夜班块后休息期内已有分配时不可分配")
+	}
+
+	// 上一期末尾的夜班块同样要求休息
+	var history []*model.Assignment
+	for _, date := range []string{"2024-01-10", "2024-01-11", "2024-01-12"} {
+		h := assign(date, night)
+		h.EmployeeID = empID
+		history = append(history, h)
+	}
+	ctx.SetHistory(history, []*model.Shift{night})
+	after := assign("2024-01-14", morning)
+	after.EmployeeID = empID
+	ctx.SetAssignments([]*model.Assignment{after})
+	if valid, _, violations := c.Evaluate(ctx); valid || len(violations) != 1 {
+		t.Errorf("上一期夜班块后的休息期内排班应违规: violations=%v", violations)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/paiban/paiban/pkg/holiday"
 	"github.com/paiban/paiban/pkg/model"
 	"github.com/paiban/paiban/pkg/scheduler/constraint"
 )
@@ -128,6 +129,12 @@ func (c *EmployeePreferenceConstraint) EvaluateAssignment(ctx *constraint.Contex
 		}
 	}
 
+	// 检查班次志愿排名（按名次比例给予奖励）
+	if prefs.HasShiftRankings() && shift != nil {
+		score := prefs.ShiftRankScore(shift.Code, shift.ShiftType)
+		penalty -= int(float64(c.Weight()/4) * score)
+	}
+
 	return true, penalty
 }
 
@@ -135,6 +142,7 @@ func (c *EmployeePreferenceConstraint) EvaluateAssignment(ctx *constraint.Contex
 type MinimizeOvertimeConstraint struct {
 	*BaseConstraint
 	standardHoursPerWeek int
+	holidays             *holiday.Calendar // 节假日加班按加班倍率加权，为空时不加权
 }
 
 // NewMinimizeOvertimeConstraint 创建最小化加班约束
@@ -158,20 +166,27 @@ func (c *MinimizeOvertimeConstraint) Evaluate(ctx *constraint.Context) (bool, in
 	for _, emp := range ctx.Employees {
 		assignments := ctx.GetEmployeeAssignments(emp.ID)
 
-		var totalHours float64
+		var totalHours, weightedHours float64
 		for _, a := range assignments {
 			totalHours += a.WorkingHours()
+			weightedHours += a.WorkingHours() * c.holidays.Rate(a.Date)
 		}
 
 		overtime := totalHours - float64(c.standardHoursPerWeek)
 		if overtime > 0 {
-			penalty := int(overtime * float64(c.Weight()) / 10)
+			// 加班按本期工时的平均加班倍率加权，节假日上班越多加班代价越高
+			rate := weightedHours / totalHours
+			penalty := int(overtime * rate * float64(c.Weight()) / 10)
 			totalPenalty += penalty
+			message := fmt.Sprintf("员工 %s 加班 %.1f 小时", emp.Name, overtime)
+			if rate > 1 {
+				message += fmt.Sprintf("（含节假日，平均加班倍率 %.1f）", rate)
+			}
 			violations = append(violations, constraint.ViolationDetail{
 				ConstraintType: c.Type(),
 				ConstraintName: c.Name(),
 				EmployeeID:     emp.ID,
-				Message:        fmt.Sprintf("员工 %s 加班 %.1f 小时", emp.Name, overtime),
+				Message:        message,
 				Severity:       "warning",
 				Penalty:        penalty,
 			})
@@ -189,9 +204,14 @@ func (c *MinimizeOvertimeConstraint) EvaluateAssignment(ctx *constraint.Context,
 
 	if totalHours > float64(c.standardHoursPerWeek) {
 		overtime := totalHours - float64(c.standardHoursPerWeek)
-		penalty := int(overtime * float64(c.Weight()) / 10)
+		penalty := int(overtime * c.holidays.Rate(a.Date) * float64(c.Weight()) / 10)
 		return true, penalty
 	}
 
 	return true, 0
 }
+
+// SetHolidays 设置节假日日历，节假日的加班按加班倍率加权
+func (c *MinimizeOvertimeConstraint) SetHolidays(calendar *holiday.Calendar) {
+	c.holidays = calendar
+}
@@ -36,12 +36,12 @@ func (c *MinRestBetweenShiftsConstraint) Evaluate(ctx *constraint.Context) (bool
 	isValid := true
 
 	for _, emp := range ctx.Employees {
-		assignments := ctx.GetEmployeeAssignments(emp.ID)
+		assignments := ctx.GetEmployeeTimeline(emp.ID)
 		if len(assignments) < 2 {
 			continue
 		}
 
-		// 按时间排序
+		// 按时间排序（含上一期的固定历史分配）
 		sorted := make([]*model.Assignment, len(assignments))
 		copy(sorted, assignments)
 		sort.Slice(sorted, func(i, j int) bool {
@@ -50,6 +50,9 @@ func (c *MinRestBetweenShiftsConstraint) Evaluate(ctx *constraint.Context) (bool
 
 		// 检查相邻班次间隔
 		for i := 0; i < len(sorted)-1; i++ {
+			if ctx.IsHistory(sorted[i+1]) {
+				continue // 历史分配之间的间隔不属于本期
+			}
 			restHours := sorted[i+1].StartTime.Sub(sorted[i].EndTime).Hours()
 
 			if restHours < float64(c.minHours) {
@@ -78,7 +81,7 @@ func (c *MinRestBetweenShiftsConstraint) Evaluate(ctx *constraint.Context) (bool
 
 // EvaluateAssignment 评估单个分配
 func (c *MinRestBetweenShiftsConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
-	assignments := ctx.GetEmployeeAssignments(a.EmployeeID)
+	assignments := ctx.GetEmployeeTimeline(a.EmployeeID)
 
 	for _, existing := range assignments {
 		if existing.ID == a.ID {
@@ -136,10 +139,15 @@ func (c *MaxConsecutiveDaysConstraint) Evaluate(ctx *constraint.Context) (bool,
 			continue
 		}
 
-		// 获取所有工作日期
+		// 获取所有工作日期（含上一期的固定历史分配），只统计延续到本期的连续天数
 		workDates := make(map[string]bool)
+		current := make(map[string]bool)
+		for _, a := range ctx.GetEmployeeHistory(emp.ID) {
+			workDates[a.Date] = true
+		}
 		for _, a := range assignments {
 			workDates[a.Date] = true
+			current[a.Date] = true
 		}
 
 		// 将日期排序
@@ -150,19 +158,17 @@ func (c *MaxConsecutiveDaysConstraint) Evaluate(ctx *constraint.Context) (bool,
 		sort.Strings(dates)
 
 		// 检查连续天数
-		consecutive := 1
-		maxConsecutive := 1
-		for i := 1; i < len(dates); i++ {
-			// 简化实现：假设日期格式正确且连续
-			// 实际应该计算日期差
-			if isConsecutiveDate(dates[i-1], dates[i]) {
+		consecutive := 0
+		maxConsecutive := 0
+		for i := range dates {
+			if i > 0 && isConsecutiveDate(dates[i-1], dates[i]) {
 				consecutive++
-				if consecutive > maxConsecutive {
-					maxConsecutive = consecutive
-				}
 			} else {
 				consecutive = 1
 			}
+			if current[dates[i]] && consecutive > maxConsecutive {
+				maxConsecutive = consecutive
+			}
 		}
 
 		if maxConsecutive > c.maxDays {
@@ -286,13 +292,7 @@ func (c *MaxShiftsPerDayConstraint) Evaluate(ctx *constraint.Context) (bool, int
 // EvaluateAssignment 评估单个分配
 func (c *MaxShiftsPerDayConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
 	// 获取该员工在该日期已有的班次数
-	assignments := ctx.GetEmployeeAssignments(a.EmployeeID)
-	count := 0
-	for _, existing := range assignments {
-		if existing.Date == a.Date {
-			count++
-		}
-	}
+	count := ctx.GetEmployeeDayStats(a.EmployeeID, a.Date).Shifts
 
 	// 加上当前分配后的班次数
 	if count+1 > c.maxShifts {
@@ -297,6 +297,27 @@ func (c *PreferenceMatchConstraint) Evaluate(ctx *constraint.Context) (bool, int
 			})
 		}
 
+		// 检查班次志愿排名：名次越靠后惩罚越高，未排名按最低志愿计
+		if prefs.HasShiftRankings() && shift != nil {
+			if penalty := c.rankPenalty(prefs, shift); penalty > 0 {
+				totalPenalty += penalty
+				message := fmt.Sprintf("员工 %s 未被分配到志愿班次", emp.Name)
+				if rank := prefs.ShiftRank(shift.Code, shift.ShiftType); rank > 0 {
+					message = fmt.Sprintf("员工 %s 被分配到第 %d 志愿班次", emp.Name, rank)
+				}
+				violations = append(violations, constraint.ViolationDetail{
+					ConstraintType: c.Type(),
+					ConstraintName: c.Name(),
+					EmployeeID:     emp.ID,
+					Date:           a.Date,
+					Message:        message,
+					Severity:       "warning",
+					Penalty:        penalty,
+				})
+			}
+			continue
+		}
+
 		// 检查班次偏好
 		if len(prefs.PreferredShifts) > 0 && shift != nil {
 			matched := false
@@ -327,7 +348,21 @@ func (c *PreferenceMatchConstraint) Evaluate(ctx *constraint.Context) (bool, int
 
 // EvaluateAssignment 评估单个分配
 func (c *PreferenceMatchConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
-	return true, 0
+	emp := ctx.GetEmployee(a.EmployeeID)
+	if emp == nil || !emp.Preferences.HasShiftRankings() {
+		return true, 0
+	}
+	shift := ctx.GetShift(a.ShiftID)
+	if shift == nil {
+		return true, 0
+	}
+	return true, c.rankPenalty(emp.Preferences, shift)
+}
+
+// rankPenalty 按志愿名次计算惩罚，第一志愿无惩罚
+func (c *PreferenceMatchConstraint) rankPenalty(prefs *model.EmployeePreferences, shift *model.Shift) int {
+	score := prefs.ShiftRankScore(shift.Code, shift.ShiftType)
+	return int(float64(c.Weight()/2) * (1 - score))
 }
 
 // 辅助函数
@@ -0,0 +1,90 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestShiftRotationPatternConstraint_Order(t *testing.T) {
+	morning := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "M", ShiftType: "morning"}
+	afternoon := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "A", ShiftType: "afternoon"}
+	night := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "N", ShiftType: "night"}
+	shifts := []*model.Shift{morning, afternoon, night}
+	assign := func(date string, shift *model.Shift) *model.Assignment {
+		a := createAssignmentOnDate(date, 8)
+		a.ShiftID = shift.ID
+		return a
+	}
+
+	// 未设置周期时轮换段从 DefaultCycleAnchor（周日）起每 7 天划分：1/14-1/20、1/21-1/27、1/28-2/3
+	c := NewShiftRotationPatternConstraint(100, "三班倒", 7)
+	ctx := createTestContext([]*model.Assignment{
+		assign("2024-01-15", morning), assign("2024-01-16", morning),
+		assign("2024-01-22", afternoon), assign("2024-01-29", night),
+	})
+	ctx.SetShifts(shifts)
+	if valid, _, violations := c.Evaluate(ctx); !valid {
+		t.Fatalf("按早-中-夜每 7 天轮换应通过, violations = %+v", violations)
+	}
+
+	// 第二个轮换段应上中班
+	assignments := []*model.Assignment{
+		assign("2024-01-15", morning), assign("2024-01-22", afternoon),
+		assign("2024-01-23", night), assign("2024-01-25", morning),
+	}
+	ctx = createTestContext(assignments)
+	ctx.SetShifts(shifts)
+	valid, penalty, violations := c.Evaluate(ctx)
+	if valid || len(violations) != 1 || violations[0].Date != "2024-01-23" || penalty != 2*c.Weight() {
+		t.Fatalf("偏离轮换顺序应报告第一个偏离的日期, penalty=%d violations = %+v", penalty, violations)
+	}
+	if !strings.Contains(violations[0].Message, "应上 afternoon 班次，实际为 night") {
+		t.Errorf("message = %s", violations[0].Message)
+	}
+
+	// 求解器评估与 Evaluate 一致：偏离轮换顺序不可分配
+	empID := assignments[0].EmployeeID
+	probe := func(date string, shift *model.Shift) (bool, int) {
+		a := assign(date, shift)
+		a.EmployeeID = empID
+		return c.EvaluateAssignment(ctx, a)
+	}
+	if ok, penalty := probe("2024-01-30", night); !ok || penalty != 0 {
+		t.Errorf("符合轮换顺序不应扣分, ok=%v penalty=%d", ok, penalty)
+	}
+	if ok, _ := probe("2024-01-30", afternoon); ok {
+		t.Error("偏离轮换顺序应被拒绝")
+	}
+	if ok, _ := probe("2024-01-24", morning); ok {
+		t.Error("夜班后次日早班应被拒绝")
+	}
+
+	// 新的夜班排在已有的次日早班之前同样被拒绝（夜班段 1/28-2/3 之后是早班段 2/4 起）
+	ctx = createTestContext([]*model.Assignment{assign("2024-01-15", morning), assign("2024-02-04", morning)})
+	ctx.SetShifts(shifts)
+	empID = ctx.Assignments[0].EmployeeID
+	if ok, _ := probe("2024-02-02", night); !ok {
+		t.Error("符合轮换顺序的夜班不应被拒绝")
+	}
+	if ok, _ := probe("2024-02-03", night); ok {
+		t.Error("次日早班前的夜班应被拒绝")
+	}
+
+	// 上一期的固定历史分配确定轮换位置：上一段为中班，本期第一段应上夜班
+	ctx = createTestContext([]*model.Assignment{assign("2024-01-15", morning)})
+	ctx.SetShifts(shifts)
+	history := assign("2024-01-10", afternoon)
+	history.EmployeeID = ctx.Employees[0].ID
+	ctx.SetHistory([]*model.Assignment{history}, nil)
+	if valid, _, violations := c.Evaluate(ctx); valid || len(violations) != 1 || violations[0].Date != "2024-01-15" {
+		t.Errorf("应延续上一期的轮换位置, violations = %+v", violations)
+	}
+
+	// 未知倒班模式不检查轮换顺序
+	if valid, _, _ := NewShiftRotationPatternConstraint(100, "常白班", 7).Evaluate(ctx); !valid {
+		t.Error("未知倒班模式不应检查轮换顺序")
+	}
+}
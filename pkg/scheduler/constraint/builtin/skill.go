@@ -61,32 +61,26 @@ func (c *SkillRequiredConstraint) Evaluate(ctx *constraint.Context) (bool, int,
 				continue
 			}
 
-			// 检查技能匹配
-			skillMatch := true
-			for _, requiredSkill := range req.Skills {
-				if !emp.HasSkill(requiredSkill) {
-					skillMatch = false
-					isValid = false
-					penalty := c.Weight()
-					totalPenalty += penalty
-
-					violations = append(violations, constraint.ViolationDetail{
-						ConstraintType: c.Type(),
-						ConstraintName: c.Name(),
-						EmployeeID:     emp.ID,
-						Date:           a.Date,
-						Message: fmt.Sprintf(
-							"员工 %s 缺少必需技能: %s",
-							emp.Name, requiredSkill,
-						),
-						Severity: "error",
-						Penalty:  penalty,
-					})
-					break
-				}
-			}
+			// 检查技能匹配（必需技能 + 技能组 + 技能等级）
+			missing := append(emp.MissingSkills(req.Skills, req.SkillGroups), emp.MissingSkillLevels(req.SkillLevels)...)
+			if len(missing) > 0 {
+				isValid = false
+				penalty := c.Weight()
+				totalPenalty += penalty
 
-			if skillMatch {
+				violations = append(violations, constraint.ViolationDetail{
+					ConstraintType: c.Type(),
+					ConstraintName: c.Name(),
+					EmployeeID:     emp.ID,
+					Date:           a.Date,
+					Message: fmt.Sprintf(
+						"员工 %s 缺少必需技能: %s",
+						emp.Name, missing[0],
+					),
+					Severity: "error",
+					Penalty:  penalty,
+				})
+			} else {
 				matchedReq = true
 				break // 找到匹配的需求，不再检查其他需求
 			}
@@ -147,15 +141,8 @@ func (c *SkillRequiredConstraint) EvaluateAssignment(ctx *constraint.Context, a
 			continue // 员工岗位不匹配这个需求，尝试下一个
 		}
 
-		// 检查所有必需技能
-		skillMatch := true
-		for _, skill := range req.Skills {
-			if !emp.HasSkill(skill) {
-				skillMatch = false
-				break
-			}
-		}
-		if !skillMatch {
+		// 检查必需技能、技能组和技能等级
+		if !emp.MeetsSkillRequirements(req.Skills, req.SkillGroups) || len(emp.MissingSkillLevels(req.SkillLevels)) > 0 {
 			continue // 技能不匹配，尝试下一个需求
 		}
 
@@ -0,0 +1,74 @@
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// ScheduleStabilityConstraint 周间稳定性约束（软约束）
+// 员工前几周同一星期几上过班时，本次分配的班次类型与之前都不同则扣分；
+// 比较范围包括上一期排班的固定历史分配，求解器在同等条件下优先安排与之前各周一致的班次
+type ScheduleStabilityConstraint struct {
+	*BaseConstraint
+	weeks int
+}
+
+// NewScheduleStabilityConstraint 创建周间稳定性约束，weeks <= 0 时使用默认周数
+func NewScheduleStabilityConstraint(weight, weeks int) *ScheduleStabilityConstraint {
+	if weeks <= 0 {
+		weeks = constraint.DefaultStabilityWeeks
+	}
+	return &ScheduleStabilityConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"周间排班稳定",
+			constraint.TypeScheduleStability,
+			constraint.CategorySoft,
+			weight,
+		),
+		weeks: weeks,
+	}
+}
+
+// StabilityWeeks 返回比较的周数
+func (c *ScheduleStabilityConstraint) StabilityWeeks() int {
+	return c.weeks
+}
+
+// Evaluate 评估整个排班：逐个本期分配与前几周同一星期几的班次比较
+func (c *ScheduleStabilityConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, emp := range ctx.Employees {
+		for _, a := range ctx.GetEmployeeAssignments(emp.ID) {
+			comparable, matched := ctx.StabilityMatch(a, c.weeks)
+			if !comparable || matched {
+				continue
+			}
+			penalty := c.Weight() / 2
+			totalPenalty += penalty
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				EmployeeID:     emp.ID,
+				Date:           a.Date,
+				Message: fmt.Sprintf("员工 %s 于 %s 的班次与前 %d 周同一天不一致（之前: %v）",
+					emp.Name, a.Date, c.weeks, ctx.WeekdayPattern(emp.ID, a.Date, c.weeks)),
+				Severity: "info",
+				Penalty:  penalty,
+			})
+		}
+	}
+
+	return true, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+func (c *ScheduleStabilityConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	if comparable, matched := ctx.StabilityMatch(a, c.weeks); comparable && !matched {
+		return true, c.Weight() / 2
+	}
+	return true, 0
+}
@@ -0,0 +1,52 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestScheduleStabilityConstraint(t *testing.T) {
+	morning := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "M", ShiftType: "morning"}
+	night := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, Code: "N", ShiftType: "night"}
+
+	// 本期周一（2024-01-15）早班，前两周的周一分别为早班和夜班
+	current := createAssignmentWithTime("2024-01-15", "07:00", "15:00")
+	current.ShiftID = night.ID
+	ctx := createTestContext([]*model.Assignment{current})
+	ctx.SetShifts([]*model.Shift{morning, night})
+
+	c := NewScheduleStabilityConstraint(40, 1)
+	if _, penalty, _ := c.Evaluate(ctx); penalty != 0 {
+		t.Fatalf("没有历史分配时不应扣分, penalty=%d", penalty)
+	}
+
+	var history []*model.Assignment
+	for date, shift := range map[string]*model.Shift{"2024-01-08": morning, "2024-01-01": night} {
+		a := createAssignmentWithTime(date, "07:00", "15:00")
+		a.EmployeeID = current.EmployeeID
+		a.ShiftID = shift.ID
+		history = append(history, a)
+	}
+	ctx.SetHistory(history, nil)
+
+	valid, penalty, violations := c.Evaluate(ctx)
+	if !valid || penalty != 20 || len(violations) != 1 {
+		t.Errorf("与上周同一天班次不同应扣分, valid=%v penalty=%d violations=%d", valid, penalty, len(violations))
+	}
+	// 比较两周时前两周有夜班，视为一致
+	if _, penalty, _ := NewScheduleStabilityConstraint(40, 2).Evaluate(ctx); penalty != 0 {
+		t.Errorf("前两周同一天有相同班次时不应扣分, penalty=%d", penalty)
+	}
+
+	candidate := createAssignmentWithTime("2024-01-15", "07:00", "15:00")
+	candidate.EmployeeID = current.EmployeeID
+	candidate.ShiftID = morning.ID
+	if _, penalty := c.EvaluateAssignment(ctx, candidate); penalty != 0 {
+		t.Errorf("与上周一致的候选分配不应扣分, penalty=%d", penalty)
+	}
+	if weeks := ConfigStabilityWeeks(map[string]interface{}{"stability_weight": 30}); weeks != 4 {
+		t.Errorf("默认比较 4 周, got %d", weeks)
+	}
+}
@@ -0,0 +1,141 @@
+package builtin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// StoreHoursBudgetConstraint 门店每周工时预算约束
+// 按上班门店（未指定时为员工所属门店）汇总每周排班工时，超过财务下达的预算即违反；
+// 可作为硬约束（超预算的分配直接拒绝）或软约束（按超出小时数扣分）使用
+type StoreHoursBudgetConstraint struct {
+	*BaseConstraint
+	hard    bool
+	budgets []model.StoreHoursBudget
+}
+
+// NewStoreHoursBudgetConstraint 创建门店每周工时预算约束
+func NewStoreHoursBudgetConstraint(hard bool, weight int, budgets []model.StoreHoursBudget) *StoreHoursBudgetConstraint {
+	category := constraint.CategorySoft
+	if hard {
+		category = constraint.CategoryHard
+	}
+	return &StoreHoursBudgetConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"门店每周工时预算",
+			constraint.TypeStoreHoursBudget,
+			category,
+			weight,
+		),
+		hard:    hard,
+		budgets: budgets,
+	}
+}
+
+// Evaluate 评估整个排班
+func (c *StoreHoursBudgetConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+	isValid := true
+
+	hours := c.storeWeekHours(ctx, nil, nil)
+	keys := make([]string, 0, len(hours))
+	for k := range hours {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	severity := "warning"
+	if c.hard {
+		severity = "error"
+	}
+	for _, k := range keys {
+		storeID, weekStart := splitStoreWeek(k)
+		budget, ok := model.ResolveStoreBudget(c.budgets, storeID, weekStart)
+		if !ok || hours[k] <= budget {
+			continue
+		}
+		// 软约束同样返回无效，约束管理器才会收集违规明细（软违规不影响排班有效性）
+		isValid = false
+		excess := hours[k] - budget
+		penalty := c.Weight() * int(math.Ceil(excess))
+		totalPenalty += penalty
+
+		violations = append(violations, constraint.ViolationDetail{
+			ConstraintType: c.Type(),
+			ConstraintName: c.Name(),
+			Date:           weekStart,
+			Message: fmt.Sprintf(
+				"门店 %s 在周 %s 排班 %.1f 小时，超过预算 %.1f 小时",
+				storeID, weekStart, hours[k], budget,
+			),
+			Severity: severity,
+			Penalty:  penalty,
+		})
+	}
+
+	return isValid, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配 - 计算加入该分配后所属门店当周的工时
+func (c *StoreHoursBudgetConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	emp := ctx.GetEmployee(a.EmployeeID)
+	store := a.Store(emp)
+	if emp == nil || store == "" {
+		return true, 0
+	}
+	weekStart := model.BudgetWeekStart(a.Date)
+	budget, ok := model.ResolveStoreBudget(c.budgets, store, weekStart)
+	if !ok {
+		return true, 0
+	}
+
+	hours := c.storeWeekHours(ctx, &store, &weekStart)
+	total := hours[store+"|"+weekStart] + a.WorkingHours()
+	for _, existing := range ctx.GetEmployeeAssignments(a.EmployeeID) {
+		if existing.ID == a.ID {
+			total -= existing.WorkingHours() // 已在排班中的分配不重复计算
+		}
+	}
+	if total <= budget {
+		return true, 0
+	}
+	penalty := c.Weight() * int(math.Ceil(total-budget))
+	return !c.hard, penalty
+}
+
+// storeWeekHours 汇总各门店每周工时（key: 门店ID|周起始日），可按门店和周过滤
+func (c *StoreHoursBudgetConstraint) storeWeekHours(ctx *constraint.Context, storeID, weekStart *string) map[string]float64 {
+	hours := make(map[string]float64)
+	employees := make(map[uuid.UUID]*model.Employee, len(ctx.Employees))
+	for _, emp := range ctx.Employees {
+		employees[emp.ID] = emp
+	}
+	for _, a := range ctx.Assignments {
+		store := a.Store(employees[a.EmployeeID])
+		if store == "" || (storeID != nil && store != *storeID) {
+			continue
+		}
+		week := model.BudgetWeekStart(a.Date)
+		if weekStart != nil && week != *weekStart {
+			continue
+		}
+		hours[store+"|"+week] += a.WorkingHours()
+	}
+	return hours
+}
+
+// splitStoreWeek 拆分 "门店ID|周起始日" 键
+func splitStoreWeek(key string) (string, string) {
+	for i := len(key) - 1; i >= 0; i-- {
+		if key[i] == '|' {
+			return key[:i], key[i+1:]
+		}
+	}
+	return key, ""
+}
@@ -0,0 +1,123 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+func TestStoreHoursBudgetConstraint_Evaluate(t *testing.T) {
+	budgets := []model.StoreHoursBudget{
+		{StoreID: "store-a", Hours: 16},
+		{StoreID: "store-a", WeekStart: "2024-01-14", Hours: 20}, // 2024-01-15 所在周
+	}
+	tests := []struct {
+		name        string
+		hard        bool
+		budgets     []model.StoreHoursBudget
+		assignments []*model.Assignment
+		wantValid   bool
+		wantPenalty int
+	}{
+		{
+			name:    "未超预算，应通过",
+			hard:    true,
+			budgets: budgets,
+			assignments: []*model.Assignment{
+				createAssignment("2024-01-15", 8),
+				createAssignment("2024-01-16", 8),
+			},
+			wantValid:   true,
+			wantPenalty: 0,
+		},
+		{
+			name:    "指定周预算优先于默认预算",
+			hard:    true,
+			budgets: budgets,
+			assignments: []*model.Assignment{
+				createAssignment("2024-01-15", 8),
+				createAssignment("2024-01-16", 8),
+				createAssignment("2024-01-17", 6), // 共22小时，超出20小时预算
+			},
+			wantValid:   false,
+			wantPenalty: 200, // 100 * 2
+		},
+		{
+			name:    "软约束超预算按超出小时扣分",
+			hard:    false,
+			budgets: []model.StoreHoursBudget{{StoreID: "store-a", Hours: 16}},
+			assignments: []*model.Assignment{
+				createAssignment("2024-01-15", 8),
+				createAssignment("2024-01-16", 8),
+				createAssignment("2024-01-17", 6),
+			},
+			wantValid:   false,
+			wantPenalty: 600, // 100 * 6
+		},
+		{
+			name:    "门店无预算，不限制",
+			hard:    true,
+			budgets: []model.StoreHoursBudget{{StoreID: "store-b", Hours: 1}},
+			assignments: []*model.Assignment{
+				createAssignment("2024-01-15", 8),
+			},
+			wantValid:   true,
+			wantPenalty: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewStoreHoursBudgetConstraint(tt.hard, 100, tt.budgets)
+			ctx := createTestContext(tt.assignments)
+			ctx.Employees[0].StoreID = "store-a"
+
+			valid, penalty, _ := c.Evaluate(ctx)
+			if valid != tt.wantValid {
+				t.Errorf("Evaluate() valid = %v, want %v", valid, tt.wantValid)
+			}
+			if penalty != tt.wantPenalty {
+				t.Errorf("Evaluate() penalty = %d, want %d", penalty, tt.wantPenalty)
+			}
+		})
+	}
+}
+
+func TestStoreHoursBudgetConstraint_EvaluateAssignment(t *testing.T) {
+	existing := createAssignment("2024-01-15", 8)
+	ctx := createTestContext([]*model.Assignment{existing})
+	ctx.Employees[0].StoreID = "store-a"
+	c := NewStoreHoursBudgetConstraint(true, 100, []model.StoreHoursBudget{{StoreID: "store-a", Hours: 12}})
+
+	next := createAssignment("2024-01-16", 8)
+	next.EmployeeID = existing.EmployeeID
+	if ok, _ := c.EvaluateAssignment(ctx, next); ok {
+		t.Error("超出门店预算的分配应被拒绝")
+	}
+
+	// 下一周不受本周工时影响
+	nextWeek := createAssignment("2024-01-22", 8)
+	nextWeek.EmployeeID = existing.EmployeeID
+	if ok, _ := c.EvaluateAssignment(ctx, nextWeek); !ok {
+		t.Error("下一周的分配应被允许")
+	}
+}
+
+func TestStoreHoursBudgetConstraint_SoftDoesNotInvalidate(t *testing.T) {
+	ctx := createTestContext([]*model.Assignment{
+		createAssignment("2024-01-15", 8),
+		createAssignment("2024-01-16", 8),
+	})
+	ctx.Employees[0].StoreID = "store-a"
+
+	cm := constraint.NewManager()
+	cm.Register(NewStoreHoursBudgetConstraint(false, 80, []model.StoreHoursBudget{{StoreID: "store-a", Hours: 10}}))
+	result := cm.Evaluate(ctx)
+	if !result.IsValid {
+		t.Error("软约束超预算不应使排班无效")
+	}
+	if len(result.SoftViolations) != 1 {
+		t.Errorf("SoftViolations = %d, want 1", len(result.SoftViolations))
+	}
+}
@@ -0,0 +1,143 @@
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// TeamMembers 返回班组ID到成员的映射，供班组完整性约束使用
+func TeamMembers(teams []model.Team) map[string][]uuid.UUID {
+	members := make(map[string][]uuid.UUID, len(teams))
+	for _, t := range teams {
+		members[t.ID] = t.Members
+	}
+	return members
+}
+
+// RequiredTeams 返回要求成员必须同一班次的班组
+func RequiredTeams(teams []model.Team) []model.Team {
+	var required []model.Team
+	for _, t := range teams {
+		if t.IsRequired() {
+			required = append(required, t)
+		}
+	}
+	return required
+}
+
+// Teammates 返回员工所在班组的其他成员，不属于任何班组时返回 nil
+func (c *TeamTogetherConstraint) Teammates(employeeID uuid.UUID) []uuid.UUID {
+	for _, members := range c.teams {
+		for _, id := range members {
+			if id == employeeID {
+				return otherMembers(members, employeeID)
+			}
+		}
+	}
+	return nil
+}
+
+// TeamCohesionConstraint 班组同班次约束（硬约束）
+// cohesion 为 required 的班组，同一天上班的成员必须在同一班次
+type TeamCohesionConstraint struct {
+	*BaseConstraint
+	teams  []model.Team
+	teamOf map[uuid.UUID]int // 员工ID -> teams 下标
+}
+
+// NewTeamCohesionConstraint 创建班组同班次约束
+func NewTeamCohesionConstraint(teams []model.Team) *TeamCohesionConstraint {
+	c := &TeamCohesionConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"班组同班次",
+			constraint.TypeTeamCohesion,
+			constraint.CategoryHard,
+			100,
+		),
+		teams:  teams,
+		teamOf: make(map[uuid.UUID]int),
+	}
+	for i, t := range teams {
+		for _, id := range t.Members {
+			c.teamOf[id] = i
+		}
+	}
+	return c
+}
+
+// Teammates 返回员工所在班组的其他成员，不属于任何班组时返回 nil
+func (c *TeamCohesionConstraint) Teammates(employeeID uuid.UUID) []uuid.UUID {
+	i, ok := c.teamOf[employeeID]
+	if !ok {
+		return nil
+	}
+	return otherMembers(c.teams[i].Members, employeeID)
+}
+
+// Evaluate 评估整个排班：每个班组每天的成员分散在多个班次时违反
+func (c *TeamCohesionConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, date := range getUniqueDates(ctx.Assignments) {
+		// 班组下标 -> 当天成员所在的班次
+		shifts := make(map[int]map[uuid.UUID]bool)
+		for _, a := range ctx.GetDateAssignments(date) {
+			i, ok := c.teamOf[a.EmployeeID]
+			if !ok {
+				continue
+			}
+			if shifts[i] == nil {
+				shifts[i] = make(map[uuid.UUID]bool)
+			}
+			shifts[i][a.ShiftID] = true
+		}
+		for i, t := range c.teams {
+			if n := len(shifts[i]); n > 1 {
+				penalty := c.Weight() * (n - 1)
+				totalPenalty += penalty
+				violations = append(violations, constraint.ViolationDetail{
+					ConstraintType: c.Type(),
+					ConstraintName: c.Name(),
+					Date:           date,
+					Message:        fmt.Sprintf("%s 班组 %s 成员分散在 %d 个不同班次", date, t.Label(), n),
+					Severity:       "error",
+					Penalty:        penalty,
+				})
+			}
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配：同组成员当天已在其他班次时不可分配
+func (c *TeamCohesionConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	i, ok := c.teamOf[a.EmployeeID]
+	if !ok {
+		return true, 0
+	}
+	for _, other := range ctx.GetDateAssignments(a.Date) {
+		if other.EmployeeID == a.EmployeeID || other.ShiftID == a.ShiftID {
+			continue
+		}
+		if j, ok := c.teamOf[other.EmployeeID]; ok && j == i {
+			return false, c.Weight()
+		}
+	}
+	return true, 0
+}
+
+// otherMembers 返回除 employeeID 外的成员
+func otherMembers(members []uuid.UUID, employeeID uuid.UUID) []uuid.UUID {
+	result := make([]uuid.UUID, 0, len(members))
+	for _, id := range members {
+		if id != employeeID {
+			result = append(result, id)
+		}
+	}
+	return result
+}
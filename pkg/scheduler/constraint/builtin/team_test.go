@@ -0,0 +1,63 @@
+package builtin
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestTeamCohesionConstraint(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	teams := []model.Team{
+		{ID: "A", Name: "甲班", Members: []uuid.UUID{a, b, c}, Cohesion: model.TeamCohesionRequired},
+		{ID: "B", Members: []uuid.UUID{d}},
+	}
+	if required := RequiredTeams(teams); len(required) != 1 || required[0].ID != "A" {
+		t.Fatalf("RequiredTeams = %+v", required)
+	}
+	cc := NewTeamCohesionConstraint(RequiredTeams(teams))
+	if got := cc.Teammates(a); len(got) != 2 || got[0] != b || got[1] != c {
+		t.Errorf("Teammates(a) = %v", got)
+	}
+	if got := cc.Teammates(d); got != nil {
+		t.Errorf("非 required 班组成员不应有同组成员: %v", got)
+	}
+	if got := NewTeamTogetherConstraint(70, TeamMembers(teams)).Teammates(d); len(got) != 0 {
+		t.Errorf("单人班组不应有同组成员: %v", got)
+	}
+
+	day, night := uuid.New(), uuid.New()
+	first := createAssignmentOnDate("2024-01-15", 8)
+	first.EmployeeID, first.ShiftID = a, day
+	ctx := createTestContext(nil)
+	ctx.SetAssignments([]*model.Assignment{first})
+
+	// 同组成员已在白班：同一班次可以，其他班次不可以；不在班组的员工不受限
+	probe := createAssignmentOnDate("2024-01-15", 8)
+	probe.EmployeeID, probe.ShiftID = b, day
+	if ok, _ := cc.EvaluateAssignment(ctx, probe); !ok {
+		t.Error("与同组成员同一班次应通过")
+	}
+	probe.ShiftID = night
+	if ok, _ := cc.EvaluateAssignment(ctx, probe); ok {
+		t.Error("与同组成员不同班次应被拒绝")
+	}
+	probe.EmployeeID = d
+	if ok, _ := cc.EvaluateAssignment(ctx, probe); !ok {
+		t.Error("不在 required 班组的员工不受限")
+	}
+	probe.Date = "2024-01-16"
+	probe.EmployeeID = b
+	if ok, _ := cc.EvaluateAssignment(ctx, probe); !ok {
+		t.Error("其他日期不受限")
+	}
+
+	second := createAssignmentOnDate("2024-01-15", 8)
+	second.EmployeeID, second.ShiftID = c, night
+	ctx.SetAssignments([]*model.Assignment{first, second})
+	valid, penalty, violations := cc.Evaluate(ctx)
+	if valid || len(violations) != 1 || penalty != cc.Weight() || violations[0].Severity != "error" {
+		t.Fatalf("成员分散在两个班次应违规: valid=%v penalty=%d violations=%v", valid, penalty, violations)
+	}
+}
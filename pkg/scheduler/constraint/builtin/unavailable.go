@@ -0,0 +1,74 @@
+package builtin
+
+import (
+	"fmt"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// EmployeeUnavailableConstraint 员工不可用时间约束（硬约束）
+// 员工请假期间、每周重复的不可用时段、按日期登记的不可用时段以及可用时间窗口之外均不可排班
+type EmployeeUnavailableConstraint struct {
+	*BaseConstraint
+}
+
+// NewEmployeeUnavailableConstraint 创建员工不可用时间约束
+func NewEmployeeUnavailableConstraint() *EmployeeUnavailableConstraint {
+	return &EmployeeUnavailableConstraint{
+		BaseConstraint: NewBaseConstraint(
+			"员工不可用时间",
+			constraint.TypeEmployeeUnavailable,
+			constraint.CategoryHard,
+			100,
+		),
+	}
+}
+
+// Evaluate 评估整个排班
+func (c *EmployeeUnavailableConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	totalPenalty := 0
+
+	for _, emp := range ctx.Employees {
+		for _, a := range ctx.GetEmployeeAssignments(emp.ID) {
+			if emp.IsAvailable(a.Date, a.StartTime, a.EndTime) {
+				continue
+			}
+			totalPenalty += c.Weight()
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(),
+				ConstraintName: c.Name(),
+				EmployeeID:     emp.ID,
+				Date:           a.Date,
+				Message:        unavailableMessage(emp, a),
+				Severity:       "error",
+				Penalty:        c.Weight(),
+			})
+		}
+	}
+
+	return len(violations) == 0, totalPenalty, violations
+}
+
+// EvaluateAssignment 评估单个分配
+func (c *EmployeeUnavailableConstraint) EvaluateAssignment(ctx *constraint.Context, a *model.Assignment) (bool, int) {
+	emp := ctx.GetEmployee(a.EmployeeID)
+	if emp == nil || emp.IsAvailable(a.Date, a.StartTime, a.EndTime) {
+		return true, 0
+	}
+	return false, c.Weight()
+}
+
+// unavailableMessage 生成不可用违规说明，请假时注明请假类型
+func unavailableMessage(emp *model.Employee, a *model.Assignment) string {
+	if leave := emp.LeaveOn(a.Date); leave != nil {
+		kind := leave.Type
+		if kind == "" {
+			kind = "请假"
+		}
+		return fmt.Sprintf("员工 %s 在 %s 请假（%s），不可排班", emp.Name, a.Date, kind)
+	}
+	return fmt.Sprintf("员工 %s 在 %s 的班次 %s-%s 处于不可用时间",
+		emp.Name, a.Date, a.StartTime.Format("15:04"), a.EndTime.Format("15:04"))
+}
@@ -0,0 +1,40 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func TestEmployeeUnavailableConstraint(t *testing.T) {
+	c := NewEmployeeUnavailableConstraint()
+
+	// 2024-01-17 为周三
+	leave := createAssignmentWithTime("2024-01-16", "09:00", "17:00")
+	ctx := createTestContext([]*model.Assignment{leave})
+	emp := ctx.Employees[0]
+	emp.Leaves = []model.EmployeeLeave{{StartDate: "2024-01-15", EndDate: "2024-01-16", Type: "年假"}}
+	emp.UnavailableWindows = []model.AvailabilityWindow{{Weekdays: []time.Weekday{time.Wednesday}, Start: "14:00", End: "18:00"}}
+
+	valid, penalty, violations := c.Evaluate(ctx)
+	if valid || penalty != c.Weight() || len(violations) != 1 {
+		t.Fatalf("请假当天的排班应违反约束: valid=%v penalty=%d violations=%d", valid, penalty, len(violations))
+	}
+	if !strings.Contains(violations[0].Message, "年假") {
+		t.Errorf("违规说明应注明请假类型: %s", violations[0].Message)
+	}
+
+	overlap := createAssignmentWithTime("2024-01-17", "12:00", "16:00")
+	overlap.EmployeeID = emp.ID
+	if ok, _ := c.EvaluateAssignment(ctx, overlap); ok {
+		t.Error("与每周不可用时段重叠的班次应被拒绝")
+	}
+
+	morning := createAssignmentWithTime("2024-01-17", "08:00", "14:00")
+	morning.EmployeeID = emp.ID
+	if ok, _ := c.EvaluateAssignment(ctx, morning); !ok {
+		t.Error("不可用时段之外的班次应通过")
+	}
+}
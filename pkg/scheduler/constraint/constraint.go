@@ -2,6 +2,7 @@
 package constraint
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,7 @@ const (
 	// 硬约束类型
 	TypeMaxHoursPerDay         Type = "max_hours_per_day"
 	TypeMaxHoursPerWeek        Type = "max_hours_per_week"
+	TypeMaxHoursPerCycle       Type = "max_hours_per_cycle"
 	TypeMinRestBetweenShifts   Type = "min_rest_between_shifts"
 	TypeMaxConsecutiveDays     Type = "max_consecutive_days"
 	TypeMaxShiftsPerDay        Type = "max_shifts_per_day"
@@ -27,6 +29,19 @@ const (
 	TypeMaxOrdersPerDay        Type = "max_orders_per_day"
 	TypeCarePlanCompliance     Type = "care_plan_compliance"
 	TypeCertificationLevel     Type = "certification_level"
+	TypeClopening              Type = "clopening"
+	TypeStoreHoursBudget       Type = "store_hours_budget"
+	TypeStoreOpeningHours      Type = "store_opening_hours"
+	TypeEmployeeUnavailable    Type = "employee_unavailable"
+	TypeCrossStoreTravel       Type = "cross_store_travel"
+	TypeFixedShift             Type = "fixed_shift"
+	TypeOvertimeCap            Type = "overtime_cap"
+	TypeMinorProtection        Type = "minor_protection"
+	TypeMaxLaborCost           Type = "max_labor_cost"
+	TypeTeamCohesion           Type = "team_cohesion"
+	TypePostNightRest          Type = "post_night_rest"
+	TypeHourlyCoverage         Type = "hourly_coverage"
+	TypeOnCall                 Type = "on_call"
 
 	// 软约束类型
 	TypeEmployeePreference     Type = "employee_preference"
@@ -38,8 +53,14 @@ const (
 	TypeMinimizeTravelDistance Type = "minimize_travel_distance"
 	TypeServiceContinuity      Type = "service_continuity"
 	TypeCaregiverContinuity    Type = "caregiver_continuity"
+	TypeFatigue                Type = "fatigue"
+	TypeScheduleStability      Type = "schedule_stability"
+	TypeHolidayHandling        Type = "holiday_handling"
 )
 
+// DefaultStabilityWeeks 周间稳定性默认比较的周数
+const DefaultStabilityWeeks = 4
+
 // Category 约束类别
 type Category string
 
@@ -92,14 +113,32 @@ type Context struct {
 	Shifts       []*model.Shift            `json:"shifts"`
 	Requirements []*model.ShiftRequirement `json:"requirements"`
 
+	// 外部人员（Employee.External 非空）本期总工时上限，0 表示不限
+	ExternalHoursCap float64 `json:"external_hours_cap,omitempty"`
+
 	// 当前排班结果
 	Assignments []*model.Assignment `json:"assignments"`
 
+	// 固定历史：相邻上一期排班在本期开始前的分配，只读，用于跨周期的休息、连续天数和倒班检查
+	History       []*model.Assignment `json:"history,omitempty"`
+	HistoryShifts []*model.Shift      `json:"history_shifts,omitempty"` // 历史分配引用的班次（不参与本期排班）
+
+	// 待命分配：常规班次排完后单独安排，不计入 Assignments，不参与工时、休息等常规约束
+	OnCall []*model.Assignment `json:"on_call,omitempty"`
+
 	// 索引缓存
 	employeeMap       map[uuid.UUID]*model.Employee
 	shiftMap          map[uuid.UUID]*model.Shift
 	assignmentsByEmp  map[uuid.UUID][]*model.Assignment
 	assignmentsByDate map[string][]*model.Assignment
+	historyByEmp      map[uuid.UUID][]*model.Assignment
+	historyShiftMap   map[uuid.UUID]*model.Shift
+	onCallByEmp       map[uuid.UUID][]*model.Assignment
+
+	// 增量汇总：员工每天的班次数、夜班数和工时，随 AddAssignment / RemoveAssignment 增减，
+	// 约束检查据此读取每日/每周工时、连续天数和连续夜班，不再逐条遍历员工的分配
+	dailyByEmp        map[uuid.UUID]map[string]*DayStats
+	historyDailyByEmp map[uuid.UUID]map[string]*DayStats
 
 	// 额外配置
 	Config map[string]interface{} `json:"config,omitempty"`
@@ -119,6 +158,11 @@ func NewContext(orgID uuid.UUID, startDate, endDate string) *Context {
 		shiftMap:          make(map[uuid.UUID]*model.Shift),
 		assignmentsByEmp:  make(map[uuid.UUID][]*model.Assignment),
 		assignmentsByDate: make(map[string][]*model.Assignment),
+		historyByEmp:      make(map[uuid.UUID][]*model.Assignment),
+		historyShiftMap:   make(map[uuid.UUID]*model.Shift),
+		onCallByEmp:       make(map[uuid.UUID][]*model.Assignment),
+		dailyByEmp:        make(map[uuid.UUID]map[string]*DayStats),
+		historyDailyByEmp: make(map[uuid.UUID]map[string]*DayStats),
 		Config:            make(map[string]interface{}),
 	}
 }
@@ -139,6 +183,7 @@ func (c *Context) SetShifts(shifts []*model.Shift) {
 	for _, s := range shifts {
 		c.shiftMap[s.ID] = s
 	}
+	c.rebuildDailyStats() // 夜班数依赖班次类型
 }
 
 // SetAssignments 设置排班分配
@@ -147,22 +192,78 @@ func (c *Context) SetAssignments(assignments []*model.Assignment) {
 	c.rebuildAssignmentIndexes()
 }
 
+// SetHistory 设置固定历史分配及其引用的班次
+// 历史分配不计入 Assignments，只通过 GetEmployeeHistory / GetEmployeeTimeline 参与跨周期检查
+func (c *Context) SetHistory(history []*model.Assignment, shifts []*model.Shift) {
+	c.History = history
+	c.HistoryShifts = shifts
+	c.historyByEmp = make(map[uuid.UUID][]*model.Assignment)
+	for _, a := range history {
+		c.historyByEmp[a.EmployeeID] = append(c.historyByEmp[a.EmployeeID], a)
+	}
+	c.historyShiftMap = make(map[uuid.UUID]*model.Shift)
+	for _, s := range shifts {
+		c.historyShiftMap[s.ID] = s
+	}
+	c.rebuildDailyStats()
+}
+
+// SetOnCall 设置待命分配
+func (c *Context) SetOnCall(onCall []*model.Assignment) {
+	c.OnCall = onCall
+	c.onCallByEmp = make(map[uuid.UUID][]*model.Assignment)
+	for _, a := range onCall {
+		c.onCallByEmp[a.EmployeeID] = append(c.onCallByEmp[a.EmployeeID], a)
+	}
+}
+
+// AddOnCall 添加待命分配
+func (c *Context) AddOnCall(a *model.Assignment) {
+	c.OnCall = append(c.OnCall, a)
+	c.onCallByEmp[a.EmployeeID] = append(c.onCallByEmp[a.EmployeeID], a)
+}
+
+// GetEmployeeOnCall 获取员工的待命分配
+func (c *Context) GetEmployeeOnCall(empID uuid.UUID) []*model.Assignment {
+	return c.onCallByEmp[empID]
+}
+
 // AddAssignment 添加排班分配
 func (c *Context) AddAssignment(a *model.Assignment) {
 	c.Assignments = append(c.Assignments, a)
 	c.assignmentsByEmp[a.EmployeeID] = append(c.assignmentsByEmp[a.EmployeeID], a)
 	c.assignmentsByDate[a.Date] = append(c.assignmentsByDate[a.Date], a)
+	c.accumulate(c.dailyByEmp, a, 1)
 }
 
 // RemoveAssignment 移除排班分配
+// 只更新被移除分配所在员工和日期的索引与汇总，不重建全部索引
 func (c *Context) RemoveAssignment(id uuid.UUID) {
+	var removed *model.Assignment
 	for i, a := range c.Assignments {
 		if a.ID == id {
+			removed = a
 			c.Assignments = append(c.Assignments[:i], c.Assignments[i+1:]...)
 			break
 		}
 	}
-	c.rebuildAssignmentIndexes()
+	if removed == nil {
+		return
+	}
+	c.assignmentsByEmp[removed.EmployeeID] = without(c.assignmentsByEmp[removed.EmployeeID], removed)
+	c.assignmentsByDate[removed.Date] = without(c.assignmentsByDate[removed.Date], removed)
+	c.accumulate(c.dailyByEmp, removed, -1)
+}
+
+// without 返回去掉 a 的新切片（不修改原切片，调用方此前取得的切片保持不变）
+func without(list []*model.Assignment, a *model.Assignment) []*model.Assignment {
+	result := make([]*model.Assignment, 0, len(list))
+	for _, x := range list {
+		if x != a {
+			result = append(result, x)
+		}
+	}
+	return result
 }
 
 // rebuildAssignmentIndexes 重建分配索引
@@ -173,6 +274,65 @@ func (c *Context) rebuildAssignmentIndexes() {
 		c.assignmentsByEmp[a.EmployeeID] = append(c.assignmentsByEmp[a.EmployeeID], a)
 		c.assignmentsByDate[a.Date] = append(c.assignmentsByDate[a.Date], a)
 	}
+	c.rebuildDailyStats()
+}
+
+// DayStats 员工某天的分配汇总
+type DayStats struct {
+	Shifts int     // 班次数
+	Nights int     // 夜班数
+	Hours  float64 // 工作时长
+}
+
+// rebuildDailyStats 重建本期和固定历史的每日汇总
+func (c *Context) rebuildDailyStats() {
+	c.dailyByEmp = make(map[uuid.UUID]map[string]*DayStats)
+	for _, a := range c.Assignments {
+		c.accumulate(c.dailyByEmp, a, 1)
+	}
+	c.historyDailyByEmp = make(map[uuid.UUID]map[string]*DayStats)
+	for _, a := range c.History {
+		c.accumulate(c.historyDailyByEmp, a, 1)
+	}
+}
+
+// accumulate 将分配计入（sign=1）或移出（sign=-1）每日汇总，当天没有分配时删除该日
+func (c *Context) accumulate(index map[uuid.UUID]map[string]*DayStats, a *model.Assignment, sign int) {
+	days := index[a.EmployeeID]
+	if days == nil {
+		days = make(map[string]*DayStats)
+		index[a.EmployeeID] = days
+	}
+	day := days[a.Date]
+	if day == nil {
+		day = &DayStats{}
+		days[a.Date] = day
+	}
+	day.Shifts += sign
+	day.Hours += float64(sign) * a.WorkingHours()
+	if shift := c.GetShift(a.ShiftID); shift != nil && shift.IsNightShift() {
+		day.Nights += sign
+	}
+	if day.Shifts <= 0 {
+		delete(days, a.Date)
+	}
+}
+
+// GetEmployeeDayStats 获取员工某天的本期分配汇总（没有分配时为零值）
+func (c *Context) GetEmployeeDayStats(empID uuid.UUID, date string) DayStats {
+	if day := c.dailyByEmp[empID][date]; day != nil {
+		return *day
+	}
+	return DayStats{}
+}
+
+// WorksOn 员工某天是否有分配（含固定历史分配），night 为 true 时只看夜班
+func (c *Context) WorksOn(empID uuid.UUID, date string, night bool) bool {
+	if day := c.dailyByEmp[empID][date]; day != nil && (!night || day.Nights > 0) {
+		return true
+	}
+	day := c.historyDailyByEmp[empID][date]
+	return day != nil && (!night || day.Nights > 0)
 }
 
 // GetEmployee 获取员工
@@ -180,9 +340,23 @@ func (c *Context) GetEmployee(id uuid.UUID) *model.Employee {
 	return c.employeeMap[id]
 }
 
-// GetShift 获取班次
+// InternalEmployees 返回内部员工（不含外部人员）
+func (c *Context) InternalEmployees() []*model.Employee {
+	internal := make([]*model.Employee, 0, len(c.Employees))
+	for _, e := range c.Employees {
+		if !e.IsExternal() {
+			internal = append(internal, e)
+		}
+	}
+	return internal
+}
+
+// GetShift 获取班次（本期班次中没有时查找历史分配引用的班次）
 func (c *Context) GetShift(id uuid.UUID) *model.Shift {
-	return c.shiftMap[id]
+	if s, ok := c.shiftMap[id]; ok {
+		return s
+	}
+	return c.historyShiftMap[id]
 }
 
 // GetEmployeeAssignments 获取员工的所有排班
@@ -190,6 +364,83 @@ func (c *Context) GetEmployeeAssignments(empID uuid.UUID) []*model.Assignment {
 	return c.assignmentsByEmp[empID]
 }
 
+// GetEmployeeHistory 获取员工的固定历史分配
+func (c *Context) GetEmployeeHistory(empID uuid.UUID) []*model.Assignment {
+	return c.historyByEmp[empID]
+}
+
+// GetEmployeeTimeline 获取员工的固定历史分配和本期分配
+func (c *Context) GetEmployeeTimeline(empID uuid.UUID) []*model.Assignment {
+	history := c.historyByEmp[empID]
+	if len(history) == 0 {
+		return c.assignmentsByEmp[empID]
+	}
+	timeline := make([]*model.Assignment, 0, len(history)+len(c.assignmentsByEmp[empID]))
+	timeline = append(timeline, history...)
+	return append(timeline, c.assignmentsByEmp[empID]...)
+}
+
+// WeekdayPattern 返回员工在 date 之前 weeks 周内同一星期几所上班次的类型（含固定历史分配），
+// 用于比较员工各周的班次是否一致；这些日期都没有排班时返回空
+func (c *Context) WeekdayPattern(empID uuid.UUID, date string, weeks int) []string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil || weeks <= 0 {
+		return nil
+	}
+	// 前 weeks 周同一星期几的日期，按字符串比较，不逐个解析分配日期
+	dates := make([]string, weeks)
+	for i := range dates {
+		dates[i] = day.AddDate(0, 0, -7*(i+1)).Format("2006-01-02")
+	}
+	var kinds []string
+	for _, a := range c.GetEmployeeTimeline(empID) {
+		if a.Date >= date || a.Date < dates[weeks-1] || !slices.Contains(dates, a.Date) {
+			continue
+		}
+		if kind := ShiftKind(c.GetShift(a.ShiftID)); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// StabilityMatch 比较分配与员工前 weeks 周同一星期几的班次：
+// comparable 表示前几周同一星期几有排班，matched 表示其中有相同类型的班次
+func (c *Context) StabilityMatch(a *model.Assignment, weeks int) (comparable, matched bool) {
+	pattern := c.WeekdayPattern(a.EmployeeID, a.Date, weeks)
+	if len(pattern) == 0 {
+		return false, false
+	}
+	kind := ShiftKind(c.GetShift(a.ShiftID))
+	for _, k := range pattern {
+		if k == kind {
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// ShiftKind 返回班次类型，未设置类型时使用班次编码
+func ShiftKind(shift *model.Shift) string {
+	if shift == nil {
+		return ""
+	}
+	if shift.ShiftType != "" {
+		return shift.ShiftType
+	}
+	return shift.Code
+}
+
+// IsHistory 检查分配是否为固定历史分配
+func (c *Context) IsHistory(a *model.Assignment) bool {
+	for _, h := range c.historyByEmp[a.EmployeeID] {
+		if h == a {
+			return true
+		}
+	}
+	return false
+}
+
 // GetDateAssignments 获取某日期的所有排班
 func (c *Context) GetDateAssignments(date string) []*model.Assignment {
 	return c.assignmentsByDate[date]
@@ -197,61 +448,54 @@ func (c *Context) GetDateAssignments(date string) []*model.Assignment {
 
 // GetEmployeeHoursOnDate 获取员工某天的工作时长
 func (c *Context) GetEmployeeHoursOnDate(empID uuid.UUID, date string) float64 {
-	var hours float64
-	for _, a := range c.assignmentsByEmp[empID] {
-		if a.Date == date {
-			hours += a.WorkingHours()
-		}
-	}
-	return hours
+	return c.GetEmployeeDayStats(empID, date).Hours
 }
 
 // GetEmployeeHoursInRange 获取员工在日期范围内的工作时长
 func (c *Context) GetEmployeeHoursInRange(empID uuid.UUID, startDate, endDate string) float64 {
 	var hours float64
-	for _, a := range c.assignmentsByEmp[empID] {
-		if a.Date >= startDate && a.Date <= endDate {
-			hours += a.WorkingHours()
+	for date, day := range c.dailyByEmp[empID] {
+		if date >= startDate && date <= endDate {
+			hours += day.Hours
 		}
 	}
 	return hours
 }
 
-// GetEmployeeConsecutiveDays 获取员工在指定日期前后的连续工作天数
-// 返回：如果在该日期分配，会形成的最大连续工作天数
-func (c *Context) GetEmployeeConsecutiveDays(empID uuid.UUID, targetDate string) int {
-	// 获取员工排班的日期
-	dates := make(map[string]bool)
-	for _, a := range c.assignmentsByEmp[empID] {
-		dates[a.Date] = true
-	}
-
-	// 往前数连续工作天数（不包括目标日期）
-	countBefore := 0
-	currentDate := previousDate(targetDate)
-	for dates[currentDate] {
-		countBefore++
-		currentDate = previousDate(currentDate)
-		if countBefore > 30 { // 防止无限循环
-			break
+// GetEmployeeNightShifts 获取员工在日期范围内的夜班数
+func (c *Context) GetEmployeeNightShifts(empID uuid.UUID, startDate, endDate string) int {
+	nights := 0
+	for date, day := range c.dailyByEmp[empID] {
+		if date >= startDate && date <= endDate {
+			nights += day.Nights
 		}
 	}
+	return nights
+}
 
-	// 往后数连续工作天数（不包括目标日期）
-	countAfter := 0
-	currentDate = nextDate(targetDate)
-	for dates[currentDate] {
-		countAfter++
-		currentDate = nextDate(currentDate)
-		if countAfter > 30 { // 防止无限循环
+// GetEmployeeConsecutiveDays 获取员工在指定日期前后的连续工作天数
+// 返回：如果在该日期分配，会形成的最大连续工作天数（包含固定历史分配）
+func (c *Context) GetEmployeeConsecutiveDays(empID uuid.UUID, targetDate string) int {
+	// 返回前面连续天数 + 后面连续天数（都不包括目标日期），调用方 +1 后得到分配目标日期时的总连续天数
+	return c.countConsecutive(empID, targetDate, previousDate, false) +
+		c.countConsecutive(empID, targetDate, nextDate, false)
+}
+
+// GetEmployeeConsecutiveNights 获取员工在指定日期之前连续上夜班的天数（不含该日期，包含固定历史分配）
+func (c *Context) GetEmployeeConsecutiveNights(empID uuid.UUID, targetDate string) int {
+	return c.countConsecutive(empID, targetDate, previousDate, true)
+}
+
+// countConsecutive 从目标日期沿 step 方向数连续有分配（night 时为夜班）的天数，不包括目标日期
+func (c *Context) countConsecutive(empID uuid.UUID, targetDate string, step func(string) string, night bool) int {
+	count := 0
+	for date := step(targetDate); c.WorksOn(empID, date, night); date = step(date) {
+		count++
+		if count > 30 { // 防止无限循环
 			break
 		}
 	}
-
-	// 返回：前面连续天数 + 后面连续天数（如果分配目标日期，总连续天数 = countBefore + 1 + countAfter）
-	// 但这里只返回前面的连续天数，因为调用方会 +1
-	// 为了正确计算，我们返回 countBefore + countAfter，让调用方 +1 后得到正确的总连续天数
-	return countBefore + countAfter
+	return count
 }
 
 // previousDate 获取前一天日期
@@ -279,6 +523,9 @@ type Result struct {
 	HardViolations []ViolationDetail `json:"hard_violations"`
 	SoftViolations []ViolationDetail `json:"soft_violations"`
 	Score          float64           `json:"score"` // 0-100
+
+	// 软约束按类型的惩罚值（含只报告惩罚、未标记为违反的软约束），用于按目标汇总评分
+	SoftPenalties map[Type]int `json:"soft_penalties,omitempty"`
 }
 
 // CalculateScore 计算约束满足度得分
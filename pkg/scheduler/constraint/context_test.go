@@ -0,0 +1,75 @@
+package constraint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func newTestAssignment(empID uuid.UUID, shift *model.Shift, date string, hours int) *model.Assignment {
+	start, _ := time.Parse("2006-01-02", date)
+	return &model.Assignment{
+		BaseModel:  model.BaseModel{ID: uuid.New()},
+		EmployeeID: empID,
+		ShiftID:    shift.ID,
+		Date:       date,
+		StartTime:  start,
+		EndTime:    start.Add(time.Duration(hours) * time.Hour),
+	}
+}
+
+func TestContext_IncrementalStats(t *testing.T) {
+	ctx := NewContext(uuid.New(), "2026-01-11", "2026-01-17")
+	emp := uuid.New()
+	day := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, ShiftType: "day"}
+	night := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}, ShiftType: "night"}
+	ctx.SetShifts([]*model.Shift{day, night})
+
+	// 上一期 1 月 10 日夜班
+	ctx.SetHistory([]*model.Assignment{newTestAssignment(emp, night, "2026-01-10", 8)}, nil)
+	a := newTestAssignment(emp, night, "2026-01-11", 8)
+	b := newTestAssignment(emp, day, "2026-01-12", 8)
+	c := newTestAssignment(emp, day, "2026-01-12", 4)
+	for _, x := range []*model.Assignment{a, b, c} {
+		ctx.AddAssignment(x)
+	}
+
+	if got := ctx.GetEmployeeDayStats(emp, "2026-01-12"); got.Shifts != 2 || got.Hours != 12 || got.Nights != 0 {
+		t.Errorf("1 月 12 日汇总 = %+v", got)
+	}
+	if got := ctx.GetEmployeeHoursInRange(emp, "2026-01-11", "2026-01-17"); got != 20 {
+		t.Errorf("本周工时 = %.1f, want 20", got)
+	}
+	if got := ctx.GetEmployeeNightShifts(emp, "2026-01-11", "2026-01-17"); got != 1 {
+		t.Errorf("夜班数 = %d, want 1", got)
+	}
+	// 1 月 13 日前连续工作 10-12 日（含历史）
+	if got := ctx.GetEmployeeConsecutiveDays(emp, "2026-01-13"); got != 3 {
+		t.Errorf("连续天数 = %d, want 3", got)
+	}
+	if got := ctx.GetEmployeeConsecutiveNights(emp, "2026-01-12"); got != 2 {
+		t.Errorf("连续夜班 = %d, want 2", got)
+	}
+
+	// 移除后汇总与重建结果一致
+	ctx.RemoveAssignment(c.ID)
+	ctx.RemoveAssignment(a.ID)
+	if got := ctx.GetEmployeeHoursOnDate(emp, "2026-01-12"); got != 8 {
+		t.Errorf("移除后 1 月 12 日工时 = %.1f, want 8", got)
+	}
+	if got := ctx.GetEmployeeDayStats(emp, "2026-01-11"); got.Shifts != 0 {
+		t.Errorf("移除后 1 月 11 日仍有分配: %+v", got)
+	}
+	if got := ctx.GetEmployeeConsecutiveDays(emp, "2026-01-13"); got != 1 {
+		t.Errorf("移除后连续天数 = %d, want 1", got)
+	}
+	if len(ctx.GetEmployeeAssignments(emp)) != 1 || len(ctx.GetDateAssignments("2026-01-12")) != 1 {
+		t.Errorf("移除后索引未更新: %v", ctx.GetEmployeeAssignments(emp))
+	}
+	ctx.SetAssignments(ctx.Assignments)
+	if got := ctx.GetEmployeeHoursInRange(emp, "2026-01-11", "2026-01-17"); got != 8 {
+		t.Errorf("重建后本周工时 = %.1f, want 8", got)
+	}
+}
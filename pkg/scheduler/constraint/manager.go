@@ -6,6 +6,7 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/paiban/paiban/pkg/chaos"
 	"github.com/paiban/paiban/pkg/logger"
 	"github.com/paiban/paiban/pkg/model"
 )
@@ -117,11 +118,19 @@ func (m *Manager) Evaluate(ctx *Context) *Result {
 	maxPenalty := 0
 
 	for _, c := range constraints {
+		chaos.Panic(chaos.ConstraintPanic, string(c.Type()))
 		valid, penalty, details := c.Evaluate(ctx)
 
 		// 累加最大可能惩罚值（用于计算得分）
 		maxPenalty += c.Weight() * 100 // 假设每个约束最多违反100次
 
+		if c.Category() == CategorySoft && penalty != 0 {
+			if result.SoftPenalties == nil {
+				result.SoftPenalties = make(map[Type]int)
+			}
+			result.SoftPenalties[c.Type()] += penalty
+		}
+
 		if !valid {
 			result.TotalPenalty += penalty
 
@@ -153,6 +162,7 @@ func (m *Manager) EvaluateAssignment(ctx *Context, assignment *model.Assignment)
 	isValid := true
 
 	for _, c := range constraints {
+		chaos.Panic(chaos.ConstraintPanic, string(c.Type()))
 		valid, penalty := c.EvaluateAssignment(ctx, assignment)
 		if !valid {
 			totalPenalty += penalty
@@ -177,17 +187,26 @@ func (m *Manager) EvaluateAssignment(ctx *Context, assignment *model.Assignment)
 
 // CanAssign 检查是否可以进行某个分配
 func (m *Manager) CanAssign(ctx *Context, assignment *model.Assignment) (bool, string) {
+	if c := m.BlockingConstraint(ctx, assignment); c != nil {
+		return false, fmt.Sprintf("违反硬约束: %s", c.Name())
+	}
+	return true, ""
+}
+
+// BlockingConstraint 返回第一个拒绝该分配的硬约束，可以分配时返回 nil
+func (m *Manager) BlockingConstraint(ctx *Context, assignment *model.Assignment) Constraint {
 	// 只检查硬约束
 	hardConstraints := m.GetByCategory(CategoryHard)
 
 	for _, c := range hardConstraints {
+		chaos.Panic(chaos.ConstraintPanic, string(c.Type()))
 		valid, _ := c.EvaluateAssignment(ctx, assignment)
 		if !valid {
-			return false, fmt.Sprintf("违反硬约束: %s", c.Name())
+			return c
 		}
 	}
 
-	return true, ""
+	return nil
 }
 
 // GetPenalty 计算分配的惩罚值
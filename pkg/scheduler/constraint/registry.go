@@ -0,0 +1,98 @@
+package constraint
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Factory 约束工厂：按约束配置中的参数创建约束，参数无效时返回错误
+type Factory func(params map[string]interface{}) (Constraint, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// RegisterFactory 按名称注册约束工厂，供企业自定义规则以插件方式接入（通常在插件包的 init 中调用）
+// 约束配置中出现该名称且值为参数对象（或 true）时，由 Manager.RegisterConfigured 创建约束；
+// 名称为空、工厂为 nil 或名称重复注册时 panic
+func RegisterFactory(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if name == "" {
+		panic("constraint: 约束工厂名称不能为空")
+	}
+	if factory == nil {
+		panic("constraint: 约束工厂不能为 nil: " + name)
+	}
+	if _, dup := factories[name]; dup {
+		panic("constraint: 约束工厂重复注册: " + name)
+	}
+	factories[name] = factory
+}
+
+// LookupFactory 按名称查找约束工厂
+func LookupFactory(name string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	f, ok := factories[name]
+	return f, ok
+}
+
+// Factories 返回已注册的约束工厂名称（按名称排序）
+func Factories() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RegisterConfigured 按约束配置实例化已注册工厂的约束并注册到管理器
+// 配置中键为工厂名称、值为参数对象时创建约束（参数 "enabled": false 时跳过），值为 true 时以空参数创建；
+// 未注册工厂的键视为普通配置项忽略。按名称顺序创建，遇到无效配置时返回错误
+func (m *Manager) RegisterConfigured(config map[string]interface{}) error {
+	names := make([]string, 0)
+	for name := range config {
+		if _, ok := LookupFactory(name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var params map[string]interface{}
+		switch v := config[name].(type) {
+		case map[string]interface{}:
+			if enabled, ok := v["enabled"].(bool); ok && !enabled {
+				continue
+			}
+			params = v
+		case bool:
+			if !v {
+				continue
+			}
+			params = map[string]interface{}{}
+		default:
+			return fmt.Errorf("约束 %s 的配置应为参数对象或布尔值", name)
+		}
+
+		factory, _ := LookupFactory(name)
+		c, err := factory(params)
+		if err != nil {
+			return fmt.Errorf("约束 %s 的配置无效: %w", name, err)
+		}
+		if c == nil {
+			return fmt.Errorf("约束 %s 的工厂未返回约束", name)
+		}
+		m.Register(c)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package constraint
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegisterConfigured(t *testing.T) {
+	RegisterFactory("test_plugin_rule", func(params map[string]interface{}) (Constraint, error) {
+		weight, _ := params["weight"].(float64)
+		if weight < 0 {
+			return nil, errors.New("weight 不能为负数")
+		}
+		return &MockConstraint{name: "插件规则", typ: Type("test_plugin_rule"), category: CategorySoft, weight: int(weight), pass: true}, nil
+	})
+
+	manager := NewManager()
+	err := manager.RegisterConfigured(map[string]interface{}{
+		"test_plugin_rule":     map[string]interface{}{"weight": 30.0},
+		"max_shifts_per_month": 20, // 未注册工厂的普通配置项
+	})
+	if err != nil {
+		t.Fatalf("RegisterConfigured: %v", err)
+	}
+	c := manager.GetConstraint(Type("test_plugin_rule"))
+	if c == nil || c.Weight() != 30 || len(manager.GetAll()) != 1 {
+		t.Fatalf("插件约束应按参数创建: %+v", manager.GetAll())
+	}
+
+	disabled := NewManager()
+	if err := disabled.RegisterConfigured(map[string]interface{}{"test_plugin_rule": map[string]interface{}{"enabled": false}}); err != nil || len(disabled.GetAll()) != 0 {
+		t.Errorf("enabled=false 时不应创建约束: err=%v constraints=%d", err, len(disabled.GetAll()))
+	}
+	if err := NewManager().RegisterConfigured(map[string]interface{}{"test_plugin_rule": map[string]interface{}{"weight": -1.0}}); err == nil {
+		t.Error("工厂拒绝的参数应返回错误")
+	}
+	if err := NewManager().RegisterConfigured(map[string]interface{}{"test_plugin_rule": "on"}); err == nil {
+		t.Error("非对象/布尔的配置应返回错误")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册应 panic")
+		}
+	}()
+	RegisterFactory("test_plugin_rule", func(map[string]interface{}) (Constraint, error) { return nil, nil })
+}
@@ -0,0 +1,232 @@
+// Package optimizer 提供排班优化算法
+package optimizer
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// GeneticOptimizer 遗传算法优化器
+// 以初始解及其随机邻域解组成初始种群，每代：
+//   - 精英保留：评分最好的 EliteCount 个个体直接进入下一代；
+//   - 选择：锦标赛选择两个父代；
+//   - 交叉：按日期切片，切点之前的日期取父代一的分配，之后的取父代二的分配；
+//   - 变异：按 MutationRate 对子代施加一次邻域移动（交换、重新分配、插入、移除等）。
+//
+// 子代由 ParallelEvaluator 并行评估适应度（评分越低越好）。多个种群个体同时搜索，
+// 较局部搜索不易陷入局部最优，适合大规模的工厂排班。统计中 Iterations 为代数，
+// NeighborsGenerated 为生成的子代数，AcceptedMoves 为变异次数
+type GeneticOptimizer struct {
+	config    *OptimizationConfig
+	evaluator *ParallelEvaluator
+	neighbors *NeighborhoodGenerator
+	rng       *rand.Rand
+
+	mu    sync.Mutex
+	stats Stats // 最近一次优化的统计
+}
+
+// NewGeneticOptimizer 创建遗传算法优化器
+func NewGeneticOptimizer(config *OptimizationConfig, evaluator ConstraintEvaluator) *GeneticOptimizer {
+	if config == nil {
+		config = DefaultOptConfig()
+	}
+	return &GeneticOptimizer{
+		config:    config,
+		evaluator: NewParallelEvaluator(config.ParallelWorkers, evaluator),
+		neighbors: newConfiguredGenerator(config, evaluator),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// SetSeed 设置随机种子（用于复现结果）
+func (g *GeneticOptimizer) SetSeed(seed int64) {
+	g.rng = rand.New(rand.NewSource(seed))
+	g.neighbors.rng = rand.New(rand.NewSource(seed + 1))
+}
+
+// Optimize 优化排班方案
+func (g *GeneticOptimizer) Optimize(ctx context.Context, initial *Solution, employees []*model.Employee, shifts []*model.Shift) (*Solution, error) {
+	start := time.Now()
+	size := max(g.config.PopulationSize, 2)
+	elites := min(max(g.config.EliteCount, 0), size-1)
+
+	optCtx := &OptimizeContext{
+		Employees: employees,
+		Shifts:    shifts,
+	}
+
+	log.Printf("开始遗传算法优化: population=%d, max_generations=%d, initial_score=%.2f",
+		size, g.config.MaxIterations, initial.Score)
+
+	stats := Stats{StopReason: "max_iterations"}
+	defer func() {
+		stats.TotalMs = msSince(start)
+		g.mu.Lock()
+		g.stats = stats
+		g.mu.Unlock()
+	}()
+
+	// 初始种群：初始解 + 施加 1-3 次邻域移动的变体
+	phase := time.Now()
+	population := []*Solution{initial.Clone()}
+	for len(population) < size {
+		individual := initial
+		for moves := 1 + g.rng.Intn(3); moves > 0; moves-- {
+			if neighbor := g.neighbors.GenerateNeighbor(individual, employees, shifts); neighbor != nil {
+				individual = neighbor
+			}
+		}
+		population = append(population, individual.Clone())
+	}
+	stats.NeighborGenMs += msSince(phase)
+
+	phase = time.Now()
+	g.evaluate(ctx, population[1:], optCtx)
+	stats.EvaluationMs += msSince(phase)
+	g.rank(population)
+	best := population[0].Clone()
+	noImprovementCount := 0
+
+	for gen := 0; gen < g.config.MaxIterations; gen++ {
+		select {
+		case <-ctx.Done():
+			log.Println("遗传算法优化被取消")
+			stats.StopReason = "cancelled"
+			return best, ctx.Err()
+		default:
+		}
+		if g.config.MaxTime > 0 && time.Since(start) > g.config.MaxTime {
+			stats.StopReason = "max_time"
+			break
+		}
+		stats.Iterations++
+
+		// 繁殖下一代
+		phase = time.Now()
+		next := make([]*Solution, 0, size)
+		next = append(next, population[:elites]...)
+		offspring := make([]*Solution, 0, size-elites)
+		for len(next)+len(offspring) < size {
+			child := g.crossover(g.tournament(population), g.tournament(population))
+			if g.rng.Float64() < g.config.MutationRate {
+				if mutated := g.neighbors.GenerateNeighbor(child, employees, shifts); mutated != nil {
+					child = mutated
+					stats.AcceptedMoves++
+				}
+			}
+			offspring = append(offspring, child)
+		}
+		stats.NeighborGenMs += msSince(phase)
+		stats.NeighborsGenerated += len(offspring)
+
+		// 并行评估子代
+		phase = time.Now()
+		g.evaluate(ctx, offspring, optCtx)
+		stats.EvaluationMs += msSince(phase)
+		if ctx.Err() != nil {
+			stats.StopReason = "cancelled"
+			return best, ctx.Err()
+		}
+
+		population = append(next, offspring...)
+		g.rank(population)
+		if population[0].Score < best.Score {
+			best = population[0].Clone()
+			noImprovementCount = 0
+			stats.Improvements++
+			log.Printf("遗传算法发现更优解: generation=%d, score=%.2f", gen, best.Score)
+		} else {
+			noImprovementCount++
+		}
+
+		if g.config.StopOnPlateau && noImprovementCount >= g.config.PlateauThreshold {
+			log.Printf("遗传算法达到平台期，停止优化: generations=%d", gen)
+			stats.StopReason = "plateau"
+			break
+		}
+	}
+
+	log.Printf("遗传算法优化完成: initial=%.2f, final=%.2f, generations=%d, elapsed=%s",
+		initial.Score, best.Score, stats.Iterations, time.Since(start))
+
+	return best, nil
+}
+
+// Stats 返回最近一次优化的统计
+func (g *GeneticOptimizer) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stats
+}
+
+// evaluate 并行评估个体并写回评分
+func (g *GeneticOptimizer) evaluate(ctx context.Context, solutions []*Solution, optCtx *OptimizeContext) {
+	for _, r := range g.evaluator.EvaluateBatch(ctx, solutions, optCtx) {
+		if r.Solution == nil {
+			continue // 评估被取消
+		}
+		r.Solution.Score = r.Score
+		r.Solution.Violations = r.Violations
+		r.Solution.Feasible = r.Feasible
+	}
+}
+
+// rank 按评分升序排列种群（评分相同时保持原顺序，精英优先）
+func (g *GeneticOptimizer) rank(population []*Solution) {
+	sort.SliceStable(population, func(i, j int) bool {
+		return population[i].Score < population[j].Score
+	})
+}
+
+// tournament 锦标赛选择：随机抽取若干个体，返回评分最好的一个
+func (g *GeneticOptimizer) tournament(population []*Solution) *Solution {
+	var winner *Solution
+	for i := 0; i < max(g.config.TournamentSize, 1); i++ {
+		candidate := population[g.rng.Intn(len(population))]
+		if winner == nil || candidate.Score < winner.Score {
+			winner = candidate
+		}
+	}
+	return winner
+}
+
+// crossover 按日期切片交叉：随机选取切点，早于切点的日期取 a 的分配，其余日期取 b 的分配
+func (g *GeneticOptimizer) crossover(a, b *Solution) *Solution {
+	seen := make(map[string]bool)
+	var dates []string
+	for _, s := range []*Solution{a, b} {
+		for _, x := range s.Assignments {
+			if !seen[x.Date] {
+				seen[x.Date] = true
+				dates = append(dates, x.Date)
+			}
+		}
+	}
+	if len(dates) < 2 {
+		return a.Clone()
+	}
+	sort.Strings(dates)
+	cut := dates[1+g.rng.Intn(len(dates)-1)]
+
+	child := &Solution{Assignments: make([]*model.Assignment, 0, len(a.Assignments))}
+	for _, x := range a.Assignments {
+		if x.Date < cut {
+			clone := *x
+			child.Assignments = append(child.Assignments, &clone)
+		}
+	}
+	for _, x := range b.Assignments {
+		if x.Date >= cut {
+			clone := *x
+			child.Assignments = append(child.Assignments, &clone)
+		}
+	}
+	return child
+}
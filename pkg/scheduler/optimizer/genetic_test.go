@@ -0,0 +1,100 @@
+package optimizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// shiftEvaluator 每天一个分配，未排在目标班次的分配扣 1 分，缺少的日期和无日期的分配各扣 10 分
+type shiftEvaluator struct {
+	target uuid.UUID
+	dates  []string
+}
+
+func (e *shiftEvaluator) Evaluate(assignments []*model.Assignment, _ []*model.Employee, _ []*model.Shift) (float64, []string) {
+	score := 0.0
+	covered := make(map[string]bool)
+	for _, a := range assignments {
+		switch {
+		case a.Date == "":
+			score += 10
+		case a.ShiftID != e.target:
+			score++
+		}
+		covered[a.Date] = true
+	}
+	var violations []string
+	for _, d := range e.dates {
+		if !covered[d] {
+			score += 10
+			violations = append(violations, d)
+		}
+	}
+	return score, violations
+}
+
+func TestGeneticOptimizer(t *testing.T) {
+	day, night := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}}, &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}}
+	emp := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}}
+	evaluator := &shiftEvaluator{target: day.ID, dates: []string{"2026-03-02", "2026-03-03", "2026-03-04", "2026-03-05", "2026-03-06", "2026-03-07", "2026-03-08"}}
+
+	initial := &Solution{}
+	for _, d := range evaluator.dates {
+		initial.Assignments = append(initial.Assignments, &model.Assignment{EmployeeID: emp.ID, ShiftID: night.ID, Date: d})
+	}
+	initial.Score, initial.Violations = evaluator.Evaluate(initial.Assignments, nil, nil)
+
+	config := DefaultOptConfig()
+	config.Algorithm = AlgorithmGenetic
+	config.MaxIterations = 300
+	opt, err := NewOptimizer(config, evaluator)
+	if err != nil {
+		t.Fatalf("NewOptimizer() error = %v", err)
+	}
+	ga, ok := opt.(*GeneticOptimizer)
+	if !ok {
+		t.Fatalf("algorithm=genetic 应创建遗传算法优化器, got %T", opt)
+	}
+	ga.SetSeed(1)
+
+	best, err := ga.Optimize(context.Background(), initial, []*model.Employee{emp}, []*model.Shift{day, night})
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+	if best.Score >= initial.Score/2 {
+		t.Errorf("遗传算法应显著改进评分: initial=%.0f best=%.0f", initial.Score, best.Score)
+	}
+	if stats := ga.Stats(); stats.Iterations == 0 || stats.NeighborsGenerated == 0 || stats.Improvements == 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if initial.Assignments[0].ShiftID != night.ID {
+		t.Error("优化不应修改初始解")
+	}
+
+	if _, err := NewOptimizer(&OptimizationConfig{Algorithm: "ant_colony"}, evaluator); err == nil {
+		t.Error("未知算法应返回错误")
+	}
+}
+
+func TestGeneticOptimizer_Crossover(t *testing.T) {
+	g := NewGeneticOptimizer(nil, nil)
+	g.SetSeed(1)
+	a, b := &Solution{}, &Solution{}
+	empA, empB := uuid.New(), uuid.New()
+	for _, d := range []string{"2026-03-02", "2026-03-03", "2026-03-04"} {
+		a.Assignments = append(a.Assignments, &model.Assignment{EmployeeID: empA, Date: d})
+		b.Assignments = append(b.Assignments, &model.Assignment{EmployeeID: empB, Date: d})
+	}
+
+	child := g.crossover(a, b)
+	if len(child.Assignments) != 3 || child.Assignments[0].EmployeeID != empA || child.Assignments[2].EmployeeID != empB {
+		t.Fatalf("子代应由父代一的前段日期和父代二的后段日期组成: %+v", child.Assignments)
+	}
+	child.Assignments[0].EmployeeID = empB
+	if a.Assignments[0].EmployeeID != empA {
+		t.Error("子代不应与父代共享分配")
+	}
+}
@@ -0,0 +1,93 @@
+// Package optimizer 提供排班优化算法
+package optimizer
+
+import (
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 破坏范围
+const (
+	DestroyDay      = "day"      // 移除某一天的分配
+	DestroyEmployee = "employee" // 移除某个员工的分配
+)
+
+// 破坏-修复解的接受准则
+const (
+	LNSAcceptAnnealing = "annealing" // 与其他邻域解相同：更优解接受，较差解按温度以概率接受
+	LNSAcceptImproving = "improving" // 仅接受更优解
+)
+
+// Repairer 破坏后的修复算子（大邻域搜索的重建步骤），通常由求解器以贪心构造实现
+// kept 为保留的分配，removed 为本次移除的分配（修复时应尽量避免原样排回），
+// 返回修复后的完整方案，无法修复时返回 nil
+type Repairer interface {
+	Repair(kept, removed []*model.Assignment, employees []*model.Employee, shifts []*model.Shift) []*model.Assignment
+}
+
+// SetRepairer 设置修复算子，nil 表示不执行破坏-修复移动
+func (n *NeighborhoodGenerator) SetRepairer(r Repairer) {
+	n.repairer = r
+}
+
+// SetLNS 设置破坏-修复移动的概率、破坏范围和每次最多移除的分配数
+func (n *NeighborhoodGenerator) SetLNS(weight float64, destroyMode string, destroySize int) {
+	n.lnsWeight = weight
+	n.destroyMode = destroyMode
+	n.destroySize = destroySize
+}
+
+// newConfiguredGenerator 按优化配置创建邻域生成器，评估器实现 Repairer 时启用破坏-修复移动
+func newConfiguredGenerator(config *OptimizationConfig, evaluator ConstraintEvaluator) *NeighborhoodGenerator {
+	n := NewNeighborhoodGenerator()
+	if r, ok := evaluator.(Repairer); ok {
+		n.SetRepairer(r)
+	}
+	n.SetLNS(config.LNSWeight, config.DestroyMode, config.DestroySize)
+	return n
+}
+
+// generateRuinRecreateMove 生成破坏-修复移动
+// 随机选取一天或一名员工，移除其分配（最多 destroySize 个），再由修复算子重新补位。
+// 单次交换、重新分配在数百个分配的方案上收敛很慢，破坏-修复一次调整一整块分配
+func (n *NeighborhoodGenerator) generateRuinRecreateMove(current *Solution, employees []*model.Employee, shifts []*model.Shift) *Solution {
+	if n.repairer == nil || len(current.Assignments) == 0 {
+		return nil
+	}
+
+	neighbor := current.Clone()
+	pivot := neighbor.Assignments[n.rng.Intn(len(neighbor.Assignments))]
+	byDay := n.destroyMode == DestroyDay || (n.destroyMode == "" && n.rng.Intn(2) == 0)
+
+	var targets []int
+	for i, a := range neighbor.Assignments {
+		if (byDay && a.Date == pivot.Date) || (!byDay && a.EmployeeID == pivot.EmployeeID) {
+			targets = append(targets, i)
+		}
+	}
+	if n.destroySize > 0 && len(targets) > n.destroySize {
+		n.rng.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+		targets = targets[:n.destroySize]
+	}
+	destroyed := make(map[int]bool, len(targets))
+	for _, i := range targets {
+		destroyed[i] = true
+	}
+
+	kept := make([]*model.Assignment, 0, len(neighbor.Assignments)-len(targets))
+	removed := make([]*model.Assignment, 0, len(targets))
+	for i, a := range neighbor.Assignments {
+		if destroyed[i] {
+			removed = append(removed, a)
+		} else {
+			kept = append(kept, a)
+		}
+	}
+
+	repaired := n.repairer.Repair(kept, removed, employees, shifts)
+	if repaired == nil {
+		return nil
+	}
+	neighbor.Assignments = repaired
+	neighbor.lns = true
+	return neighbor
+}
@@ -0,0 +1,96 @@
+package optimizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// dayRepairer 为被移除的分配补一个排在目标班次的分配，并记录移除的分配
+type dayRepairer struct {
+	*shiftEvaluator
+	removed [][]*model.Assignment
+}
+
+func (r *dayRepairer) Repair(kept, removed []*model.Assignment, _ []*model.Employee, _ []*model.Shift) []*model.Assignment {
+	r.removed = append(r.removed, removed)
+	repaired := append([]*model.Assignment{}, kept...)
+	for _, a := range removed {
+		repaired = append(repaired, &model.Assignment{EmployeeID: a.EmployeeID, ShiftID: r.target, Date: a.Date})
+	}
+	return repaired
+}
+
+func TestRuinRecreateMove(t *testing.T) {
+	day, night := uuid.New(), uuid.New()
+	emp1, emp2 := uuid.New(), uuid.New()
+	repairer := &dayRepairer{shiftEvaluator: &shiftEvaluator{target: day}}
+	current := &Solution{}
+	for _, d := range []string{"2026-03-02", "2026-03-03", "2026-03-04"} {
+		current.Assignments = append(current.Assignments,
+			&model.Assignment{EmployeeID: emp1, ShiftID: night, Date: d},
+			&model.Assignment{EmployeeID: emp2, ShiftID: night, Date: d})
+	}
+
+	n := NewNeighborhoodGenerator()
+	n.rng.Seed(1)
+	if n.generateRuinRecreateMove(current, nil, nil) != nil {
+		t.Fatal("未设置修复算子时不应生成破坏-修复移动")
+	}
+	n.SetRepairer(repairer)
+
+	n.SetLNS(1, DestroyDay, 0)
+	neighbor := n.GenerateNeighbor(current, nil, nil)
+	if neighbor == nil || !neighbor.lns || len(neighbor.Assignments) != 6 {
+		t.Fatalf("破坏-修复移动 = %+v", neighbor)
+	}
+	removed := repairer.removed[0]
+	if len(removed) != 2 || removed[0].Date != removed[1].Date {
+		t.Errorf("按天破坏应移除同一天的全部分配: %+v", removed)
+	}
+	if current.Assignments[0].ShiftID != night || current.Assignments[5].ShiftID != night {
+		t.Error("破坏-修复不应修改当前解")
+	}
+
+	n.SetLNS(1, DestroyEmployee, 2)
+	n.GenerateNeighbor(current, nil, nil)
+	removed = repairer.removed[1]
+	if len(removed) != 2 || removed[0].EmployeeID != removed[1].EmployeeID {
+		t.Errorf("按员工破坏应最多移除 destroy_size 个同一员工的分配: %+v", removed)
+	}
+}
+
+func TestLocalSearchWithRuinRecreate(t *testing.T) {
+	day, night := &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}}, &model.Shift{BaseModel: model.BaseModel{ID: uuid.New()}}
+	emp := &model.Employee{BaseModel: model.BaseModel{ID: uuid.New()}}
+	repairer := &dayRepairer{shiftEvaluator: &shiftEvaluator{target: day.ID, dates: []string{"2026-03-02", "2026-03-03", "2026-03-04", "2026-03-05"}}}
+	initial := &Solution{}
+	for _, d := range repairer.dates {
+		initial.Assignments = append(initial.Assignments, &model.Assignment{EmployeeID: emp.ID, ShiftID: night.ID, Date: d})
+	}
+	initial.Score, initial.Violations = repairer.Evaluate(initial.Assignments, nil, nil)
+
+	config := DefaultOptConfig()
+	config.LNSWeight = 1
+	config.DestroyMode = DestroyDay
+	config.LNSAcceptance = LNSAcceptImproving
+	opt, err := NewOptimizer(config, repairer)
+	if err != nil {
+		t.Fatalf("NewOptimizer() error = %v", err)
+	}
+	best, err := opt.Optimize(context.Background(), initial, []*model.Employee{emp}, []*model.Shift{day, night})
+	if err != nil {
+		t.Fatalf("Optimize() error = %v", err)
+	}
+	if best.Score != 0 {
+		t.Errorf("破坏-修复应把每天的分配都改到目标班次, score = %.0f", best.Score)
+	}
+
+	for _, bad := range []*OptimizationConfig{{DestroyMode: "week"}, {LNSAcceptance: "always"}} {
+		if _, err := NewOptimizer(bad, repairer); err == nil {
+			t.Errorf("无效的大邻域搜索配置应返回错误: %+v", bad)
+		}
+	}
+}
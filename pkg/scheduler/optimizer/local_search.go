@@ -3,6 +3,7 @@ package optimizer
 
 import (
 	"context"
+	"fmt"
 	"hash/fnv"
 	"log"
 	"math"
@@ -13,10 +14,17 @@ import (
 	"github.com/paiban/paiban/pkg/model"
 )
 
+// 优化算法
+const (
+	AlgorithmLocalSearch = "local_search" // 局部搜索（禁忌搜索 + 模拟退火）
+	AlgorithmGenetic     = "genetic"      // 遗传算法
+)
+
 // OptimizationConfig 优化配置
 type OptimizationConfig struct {
-	MaxIterations    int           `json:"max_iterations"`    // 最大迭代次数
-	MaxTime          time.Duration `json:"max_time"`          // 最大运行时间
+	Algorithm        string        `json:"algorithm"`         // 优化算法，默认局部搜索
+	MaxIterations    int           `json:"max_iterations"`    // 最大迭代次数（遗传算法为最大代数）
+	MaxTime          time.Duration `json:"max_time"`          // 最大运行时间，0 表示不限（确定性模式，仍受上下文取消约束）
 	InitialTemp      float64       `json:"initial_temp"`      // 模拟退火初始温度
 	CoolingRate      float64       `json:"cooling_rate"`      // 冷却速率
 	TabuSize         int           `json:"tabu_size"`         // 禁忌表大小
@@ -24,6 +32,18 @@ type OptimizationConfig struct {
 	ParallelWorkers  int           `json:"parallel_workers"`  // 并行工作数
 	StopOnPlateau    bool          `json:"stop_on_plateau"`   // 平台期停止
 	PlateauThreshold int           `json:"plateau_threshold"` // 平台期阈值（无改进迭代次数）
+
+	// 遗传算法参数
+	PopulationSize int     `json:"population_size"` // 种群大小
+	EliteCount     int     `json:"elite_count"`     // 每代直接保留的最优个体数
+	MutationRate   float64 `json:"mutation_rate"`   // 子代变异概率
+	TournamentSize int     `json:"tournament_size"` // 锦标赛选择的参赛个体数
+
+	// 大邻域搜索（破坏-修复）参数，评估器同时实现 Repairer 时生效
+	LNSWeight     float64 `json:"lns_weight"`     // 每次邻域移动选择破坏-修复的概率，0 表示不启用
+	DestroyMode   string  `json:"destroy_mode"`   // 破坏范围：day（某天）/employee（某员工），默认随机选择
+	DestroySize   int     `json:"destroy_size"`   // 每次最多移除的分配数，0 表示移除所选范围内的全部分配
+	LNSAcceptance string  `json:"lns_acceptance"` // 局部搜索对破坏-修复解的接受准则：annealing（默认）/improving
 }
 
 // DefaultOptConfig 默认优化配置
@@ -38,6 +58,44 @@ func DefaultOptConfig() *OptimizationConfig {
 		ParallelWorkers:  4,
 		StopOnPlateau:    true,
 		PlateauThreshold: 100,
+		Algorithm:        AlgorithmLocalSearch,
+		PopulationSize:   30,
+		EliteCount:       2,
+		MutationRate:     0.3,
+		TournamentSize:   3,
+		LNSWeight:        0.1,
+		LNSAcceptance:    LNSAcceptAnnealing,
+	}
+}
+
+// Optimizer 排班优化器
+type Optimizer interface {
+	Optimize(ctx context.Context, initial *Solution, employees []*model.Employee, shifts []*model.Shift) (*Solution, error)
+	Stats() Stats
+}
+
+// NewOptimizer 按 config.Algorithm 创建优化器，未指定时使用局部搜索
+func NewOptimizer(config *OptimizationConfig, evaluator ConstraintEvaluator) (Optimizer, error) {
+	if config == nil {
+		config = DefaultOptConfig()
+	}
+	switch config.DestroyMode {
+	case "", DestroyDay, DestroyEmployee:
+	default:
+		return nil, fmt.Errorf("不支持的破坏范围: %s", config.DestroyMode)
+	}
+	switch config.LNSAcceptance {
+	case "", LNSAcceptAnnealing, LNSAcceptImproving:
+	default:
+		return nil, fmt.Errorf("不支持的接受准则: %s", config.LNSAcceptance)
+	}
+	switch config.Algorithm {
+	case "", AlgorithmLocalSearch:
+		return NewLocalSearchOptimizer(config, evaluator), nil
+	case AlgorithmGenetic:
+		return NewGeneticOptimizer(config, evaluator), nil
+	default:
+		return nil, fmt.Errorf("不支持的优化算法: %s", config.Algorithm)
 	}
 }
 
@@ -47,6 +105,8 @@ type Solution struct {
 	Score       float64
 	Violations  []string
 	Feasible    bool
+
+	lns bool // 由破坏-修复移动生成（不随 Clone 复制）
 }
 
 // Clone 深拷贝解决方案
@@ -65,6 +125,23 @@ func (s *Solution) Clone() *Solution {
 	return clone
 }
 
+// Stats 优化过程统计（用于调优）
+type Stats struct {
+	Iterations         int     `json:"iterations"`
+	NeighborsGenerated int     `json:"neighbors_generated"`
+	AcceptedMoves      int     `json:"accepted_moves"`
+	Improvements       int     `json:"improvements"`
+	NeighborGenMs      float64 `json:"neighbor_generation_ms"` // 生成邻域解耗时
+	EvaluationMs       float64 `json:"evaluation_ms"`          // 评估邻域解耗时
+	TotalMs            float64 `json:"total_ms"`
+	StopReason         string  `json:"stop_reason"` // max_iterations/max_time/plateau/cancelled
+}
+
+// msSince 返回自 start 起经过的毫秒数
+func msSince(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
+
 // ConstraintEvaluator 约束评估器接口
 type ConstraintEvaluator interface {
 	Evaluate(assignments []*model.Assignment, employees []*model.Employee, shifts []*model.Shift) (float64, []string)
@@ -78,6 +155,7 @@ type LocalSearchOptimizer struct {
 	tabuList  *TabuList
 	rng       *rand.Rand
 	mu        sync.Mutex
+	stats     Stats // 最近一次优化的统计
 }
 
 // NewLocalSearchOptimizer 创建局部搜索优化器
@@ -88,12 +166,18 @@ func NewLocalSearchOptimizer(config *OptimizationConfig, evaluator ConstraintEva
 	return &LocalSearchOptimizer{
 		config:    config,
 		evaluator: evaluator,
-		neighbors: NewNeighborhoodGenerator(),
+		neighbors: newConfiguredGenerator(config, evaluator),
 		tabuList:  NewTabuList(config.TabuSize),
 		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
+// SetSeed 设置随机种子（用于复现结果）
+func (o *LocalSearchOptimizer) SetSeed(seed int64) {
+	o.rng = rand.New(rand.NewSource(seed))
+	o.neighbors.rng = rand.New(rand.NewSource(seed + 1))
+}
+
 // OptimizeContext 优化上下文
 type OptimizeContext struct {
 	Employees []*model.Employee
@@ -118,28 +202,44 @@ func (o *LocalSearchOptimizer) Optimize(ctx context.Context, initial *Solution,
 	log.Printf("开始局部搜索优化: max_iterations=%d, max_time=%s, initial_score=%.2f",
 		o.config.MaxIterations, o.config.MaxTime, current.Score)
 
+	stats := Stats{StopReason: "max_iterations"}
+	defer func() {
+		stats.TotalMs = msSince(start)
+		o.mu.Lock()
+		o.stats = stats
+		o.mu.Unlock()
+	}()
+
 	for i := 0; i < o.config.MaxIterations; i++ {
 		// 检查超时和取消
 		select {
 		case <-ctx.Done():
 			log.Println("优化被取消")
+			stats.StopReason = "cancelled"
 			return best, ctx.Err()
 		default:
 		}
 
-		if time.Since(start) > o.config.MaxTime {
+		if o.config.MaxTime > 0 && time.Since(start) > o.config.MaxTime {
 			log.Println("达到最大运行时间")
+			stats.StopReason = "max_time"
 			break
 		}
+		stats.Iterations++
 
 		// 生成邻域解
+		phase := time.Now()
 		neighbors := o.generateNeighbors(current, employees, shifts)
+		stats.NeighborGenMs += msSince(phase)
+		stats.NeighborsGenerated += len(neighbors)
 		if len(neighbors) == 0 {
 			continue
 		}
 
 		// 评估邻域解
+		phase = time.Now()
 		bestNeighbor := o.evaluateBestNeighbor(neighbors, optCtx)
+		stats.EvaluationMs += msSince(phase)
 		if bestNeighbor == nil {
 			continue
 		}
@@ -153,8 +253,8 @@ func (o *LocalSearchOptimizer) Optimize(ctx context.Context, initial *Solution,
 		if bestNeighbor.Score < current.Score {
 			// 更优解，接受
 			accept = true
-		} else if !inTabu {
-			// 较差解，以概率接受（模拟退火）
+		} else if !inTabu && !(bestNeighbor.lns && o.config.LNSAcceptance == LNSAcceptImproving) {
+			// 较差解，以概率接受（模拟退火）；破坏-修复解按 improving 准则只接受更优解
 			delta := bestNeighbor.Score - current.Score
 			prob := boltzmannProbability(delta, temperature)
 			if o.rng.Float64() < prob {
@@ -165,11 +265,13 @@ func (o *LocalSearchOptimizer) Optimize(ctx context.Context, initial *Solution,
 		if accept {
 			current = bestNeighbor
 			o.tabuList.Add(moveKey)
+			stats.AcceptedMoves++
 
 			// 更新最优解
 			if current.Score < best.Score {
 				best = current.Clone()
 				noImprovementCount = 0
+				stats.Improvements++
 				log.Printf("发现更优解: iteration=%d, score=%.2f", i, best.Score)
 			} else {
 				noImprovementCount++
@@ -181,6 +283,7 @@ func (o *LocalSearchOptimizer) Optimize(ctx context.Context, initial *Solution,
 		// 检查平台期
 		if o.config.StopOnPlateau && noImprovementCount >= o.config.PlateauThreshold {
 			log.Printf("达到平台期阈值，停止优化: iterations=%d, no_improvement=%d", i, noImprovementCount)
+			stats.StopReason = "plateau"
 			break
 		}
 
@@ -195,6 +298,13 @@ func (o *LocalSearchOptimizer) Optimize(ctx context.Context, initial *Solution,
 	return best, nil
 }
 
+// Stats 返回最近一次优化的统计
+func (o *LocalSearchOptimizer) Stats() Stats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stats
+}
+
 // generateNeighbors 生成邻域解
 func (o *LocalSearchOptimizer) generateNeighbors(current *Solution, employees []*model.Employee, shifts []*model.Shift) []*Solution {
 	neighbors := make([]*Solution, 0, o.config.NeighborhoodSize)
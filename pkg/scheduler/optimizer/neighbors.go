@@ -12,12 +12,13 @@ import (
 type MoveType int
 
 const (
-	MoveSwap     MoveType = iota // 交换两个员工的班次
-	MoveRelocate                 // 重新分配员工到不同班次
-	MoveInsert                   // 插入新分配
-	MoveRemove                   // 移除分配
-	Move2Opt                     // 2-opt改进
-	MoveChain                    // 链式移动
+	MoveSwap         MoveType = iota // 交换两个员工的班次
+	MoveRelocate                     // 重新分配员工到不同班次
+	MoveInsert                       // 插入新分配
+	MoveRemove                       // 移除分配
+	Move2Opt                         // 2-opt改进
+	MoveChain                        // 链式移动
+	MoveRuinRecreate                 // 破坏-修复（大邻域搜索）
 )
 
 // Move 邻域移动操作
@@ -35,6 +36,12 @@ type Move struct {
 type NeighborhoodGenerator struct {
 	rng         *rand.Rand
 	moveWeights map[MoveType]float64
+
+	// 大邻域搜索：设置了修复器且 lnsWeight 大于 0 时按概率执行破坏-修复移动
+	repairer    Repairer
+	lnsWeight   float64
+	destroyMode string
+	destroySize int
 }
 
 // NewNeighborhoodGenerator 创建邻域生成器
@@ -58,6 +65,10 @@ func (n *NeighborhoodGenerator) GenerateNeighbor(current *Solution, employees []
 		return nil
 	}
 
+	if n.repairer != nil && n.lnsWeight > 0 && n.rng.Float64() < n.lnsWeight {
+		return n.generateRuinRecreateMove(current, employees, shifts)
+	}
+
 	moveType := n.selectMoveType()
 
 	switch moveType {
@@ -73,6 +84,8 @@ func (n *NeighborhoodGenerator) GenerateNeighbor(current *Solution, employees []
 		return n.generate2OptMove(current)
 	case MoveChain:
 		return n.generateChainMove(current, employees)
+	case MoveRuinRecreate:
+		return n.generateRuinRecreateMove(current, employees, shifts)
 	default:
 		return n.generateSwapMove(current, employees)
 	}
@@ -83,8 +96,9 @@ func (n *NeighborhoodGenerator) selectMoveType() MoveType {
 	r := n.rng.Float64()
 	cumulative := 0.0
 
-	for moveType, weight := range n.moveWeights {
-		cumulative += weight
+	// 按移动类型顺序累加（不依赖 map 遍历顺序），相同随机种子总是选出相同的移动
+	for moveType := MoveSwap; moveType <= MoveRuinRecreate; moveType++ {
+		cumulative += n.moveWeights[moveType]
 		if r < cumulative {
 			return moveType
 		}
@@ -209,8 +223,8 @@ func (n *NeighborhoodGenerator) generate2OptMove(current *Solution) *Solution {
 
 	neighbor := current.Clone()
 
-	// 随机选择两个位置
-	i := n.rng.Intn(len(neighbor.Assignments) - 1)
+	// 随机选择两个位置（i 之后至少留两个位置）
+	i := n.rng.Intn(len(neighbor.Assignments) - 2)
 	j := i + 2 + n.rng.Intn(len(neighbor.Assignments)-i-2)
 	if j >= len(neighbor.Assignments) {
 		j = len(neighbor.Assignments) - 1
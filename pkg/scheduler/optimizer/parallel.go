@@ -5,6 +5,7 @@ import (
 	"context"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/paiban/paiban/pkg/model"
 )
@@ -132,6 +133,9 @@ type ParallelOptimizer struct {
 	config    *OptimizationConfig
 	evaluator *ParallelEvaluator
 	neighbors *NeighborhoodGenerator
+
+	mu    sync.Mutex
+	stats Stats // 最近一次优化的统计
 }
 
 // NewParallelOptimizer 创建并行优化器
@@ -142,7 +146,7 @@ func NewParallelOptimizer(config *OptimizationConfig, constraintEvaluator Constr
 	return &ParallelOptimizer{
 		config:    config,
 		evaluator: NewParallelEvaluator(config.ParallelWorkers, constraintEvaluator),
-		neighbors: NewNeighborhoodGenerator(),
+		neighbors: newConfiguredGenerator(config, constraintEvaluator),
 	}
 }
 
@@ -161,21 +165,37 @@ func (p *ParallelOptimizer) OptimizeParallel(ctx context.Context, initial *Solut
 
 	noImprovementCount := 0
 
+	start := time.Now()
+	stats := Stats{StopReason: "max_iterations"}
+	defer func() {
+		stats.TotalMs = msSince(start)
+		p.mu.Lock()
+		p.stats = stats
+		p.mu.Unlock()
+	}()
+
 	for iter := 0; iter < p.config.MaxIterations; iter++ {
 		select {
 		case <-ctx.Done():
+			stats.StopReason = "cancelled"
 			return best, ctx.Err()
 		default:
 		}
+		stats.Iterations++
 
 		// 并行生成邻域解
+		phase := time.Now()
 		neighbors := p.generateNeighborsParallel(ctx, current, employees, shifts, p.config.NeighborhoodSize)
+		stats.NeighborGenMs += msSince(phase)
+		stats.NeighborsGenerated += len(neighbors)
 		if len(neighbors) == 0 {
 			continue
 		}
 
 		// 并行评估
+		phase = time.Now()
 		results := p.evaluator.EvaluateBatch(ctx, neighbors, optCtx)
+		stats.EvaluationMs += msSince(phase)
 
 		// 找出最优邻域解
 		bestResult := p.evaluator.FindBest(results)
@@ -189,11 +209,13 @@ func (p *ParallelOptimizer) OptimizeParallel(ctx context.Context, initial *Solut
 			current.Score = bestResult.Score
 			current.Violations = bestResult.Violations
 			current.Feasible = bestResult.Feasible
+			stats.AcceptedMoves++
 
 			// 更新最优解
 			if current.Score < best.Score {
 				best = current.Clone()
 				noImprovementCount = 0
+				stats.Improvements++
 				log.Printf("并行优化发现更优解: iteration=%d, score=%.2f, violations=%d",
 					iter, best.Score, len(best.Violations))
 			}
@@ -204,6 +226,7 @@ func (p *ParallelOptimizer) OptimizeParallel(ctx context.Context, initial *Solut
 		// 检查平台期
 		if p.config.StopOnPlateau && noImprovementCount >= p.config.PlateauThreshold {
 			log.Printf("并行优化达到平台期: iterations=%d", iter)
+			stats.StopReason = "plateau"
 			break
 		}
 	}
@@ -213,6 +236,13 @@ func (p *ParallelOptimizer) OptimizeParallel(ctx context.Context, initial *Solut
 	return best, nil
 }
 
+// Stats 返回最近一次优化的统计
+func (p *ParallelOptimizer) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
 // generateNeighborsParallel 并行生成邻域解
 func (p *ParallelOptimizer) generateNeighborsParallel(ctx context.Context, current *Solution, employees []*model.Employee, shifts []*model.Shift, count int) []*Solution {
 	resultChan := make(chan *Solution, count)
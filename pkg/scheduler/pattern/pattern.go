@@ -0,0 +1,168 @@
+// Package pattern 将循环排班模板（按周重复的轮班表）展开为一段日期内的排班，并检测冲突
+package pattern
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/validator"
+)
+
+// MaxExpandDays 单次展开的最大天数
+const MaxExpandDays = 366
+
+// Options 展开选项
+type Options struct {
+	Employees []*model.Employee         // 员工信息（姓名、可用时段），用于冲突检测
+	Existing  []*model.Assignment       // 已有排班，与展开的排班一起检测冲突
+	Detector  *validator.DetectorConfig // 冲突检测配置，默认 validator.DefaultDetectorConfig
+}
+
+// Expansion 模板展开结果
+type Expansion struct {
+	PatternID   uuid.UUID            `json:"pattern_id"`
+	StartDate   string               `json:"start_date"`
+	EndDate     string               `json:"end_date"`
+	Assignments []*model.Assignment  `json:"assignments"`
+	Conflicts   []validator.Conflict `json:"conflicts"` // 涉及展开排班的冲突
+	Valid       bool                 `json:"valid"`     // 没有 error 级冲突
+}
+
+// Expand 将模板展开为 [start, end] 内的排班
+// 每天按所在循环周和星期取模板中的排班，班次结束时间不晚于开始时间时视为跨日班次；
+// 展开的排班与已有排班一起按冲突检测器检测时间重叠、休息时间、工时和连续工作天数
+func Expand(p *model.SchedulePattern, start, end string, opts Options) (*Expansion, error) {
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return nil, fmt.Errorf("开始日期格式应为 YYYY-MM-DD")
+	}
+	endDate, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return nil, fmt.Errorf("结束日期格式应为 YYYY-MM-DD")
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("结束日期不能早于开始日期")
+	}
+	if days := int(endDate.Sub(startDate).Hours()/24) + 1; days > MaxExpandDays {
+		return nil, fmt.Errorf("展开范围不能超过 %d 天", MaxExpandDays)
+	}
+
+	// 按周次和星期索引模板排班
+	type slot struct {
+		week    int
+		weekday time.Weekday
+	}
+	bySlot := make(map[slot][]model.PatternEntry)
+	for _, e := range p.Entries {
+		k := slot{e.Week, e.Weekday}
+		bySlot[k] = append(bySlot[k], e)
+	}
+
+	result := &Expansion{PatternID: p.ID, StartDate: start, EndDate: end, Assignments: []*model.Assignment{}}
+	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		for _, e := range bySlot[slot{p.WeekOf(date), d.Weekday()}] {
+			shift := p.Shift(e.ShiftCode)
+			if shift == nil {
+				continue
+			}
+			result.Assignments = append(result.Assignments, assignment(p, e, shift, d))
+		}
+	}
+
+	result.Conflicts = detect(result.Assignments, opts)
+	result.Valid = true
+	for _, c := range result.Conflicts {
+		if c.Severity == "error" {
+			result.Valid = false
+			break
+		}
+	}
+	return result, nil
+}
+
+// assignment 生成模板排班在某天的分配
+func assignment(p *model.SchedulePattern, e model.PatternEntry, shift *model.Shift, day time.Time) *model.Assignment {
+	startTime := clockOn(day, shift.StartTime)
+	endTime := clockOn(day, shift.EndTime)
+	if !endTime.After(startTime) {
+		endTime = endTime.Add(24 * time.Hour)
+	}
+	return &model.Assignment{
+		BaseModel:  model.NewBaseModel(),
+		OrgID:      p.OrgID,
+		EmployeeID: e.EmployeeID,
+		ShiftID:    shift.ID,
+		Date:       day.Format("2006-01-02"),
+		StartTime:  startTime,
+		EndTime:    endTime,
+		Position:   e.Position,
+		StoreID:    e.StoreID,
+		Status:     "scheduled",
+	}
+}
+
+// clockOn 返回某天的 HH:MM 时刻
+func clockOn(day time.Time, clock string) time.Time {
+	t, _ := time.Parse("15:04", clock)
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location())
+}
+
+// detect 检测展开的排班与已有排班的冲突，只返回涉及展开排班的冲突
+// 未提供员工信息的员工按员工ID检测时间重叠、休息时间、工时和连续工作天数
+func detect(assignments []*model.Assignment, opts Options) []validator.Conflict {
+	employees := make(map[uuid.UUID]*model.Employee, len(opts.Employees))
+	for _, emp := range opts.Employees {
+		if emp != nil {
+			employees[emp.ID] = emp
+		}
+	}
+	expanded := make(map[uuid.UUID]bool, len(assignments))
+	involved := make(map[uuid.UUID]bool)
+	for _, a := range assignments {
+		expanded[a.ID] = true
+		involved[a.EmployeeID] = true
+		if employees[a.EmployeeID] == nil {
+			employees[a.EmployeeID] = &model.Employee{BaseModel: model.BaseModel{ID: a.EmployeeID}, Name: a.EmployeeID.String()}
+		}
+	}
+
+	all := make([]*model.Assignment, 0, len(opts.Existing)+len(assignments))
+	for _, a := range opts.Existing {
+		if a != nil && involved[a.EmployeeID] {
+			all = append(all, a)
+		}
+	}
+	all = append(all, assignments...)
+
+	conflicts := make([]validator.Conflict, 0)
+	for _, c := range validator.NewConflictDetector(opts.Detector).DetectAll(all, employees) {
+		if len(c.Assignments) == 0 || touches(c, expanded) {
+			conflicts = append(conflicts, c)
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		a, b := conflicts[i], conflicts[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.EmployeeID != b.EmployeeID {
+			return a.EmployeeID.String() < b.EmployeeID.String()
+		}
+		return a.Type < b.Type
+	})
+	return conflicts
+}
+
+// touches 检查冲突是否涉及展开的排班
+func touches(c validator.Conflict, expanded map[uuid.UUID]bool) bool {
+	for _, id := range c.Assignments {
+		if expanded[id] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package pattern
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/validator"
+)
+
+// newRotation 两周轮班：员工 A 第 1 周上早班、第 2 周上晚班（周一至周五），员工 B 相反
+func newRotation(a, b uuid.UUID) *model.SchedulePattern {
+	p := &model.SchedulePattern{
+		BaseModel:  model.NewBaseModel(),
+		OrgID:      uuid.New(),
+		Name:       "两班轮换",
+		Weeks:      2,
+		AnchorDate: "2026-03-01", // 周日
+		Shifts: []*model.Shift{
+			{Code: "E", Name: "早班", StartTime: "06:00", EndTime: "14:00"},
+			{Code: "L", Name: "晚班", StartTime: "14:00", EndTime: "22:00"},
+		},
+	}
+	for wd := time.Monday; wd <= time.Friday; wd++ {
+		p.Entries = append(p.Entries,
+			model.PatternEntry{Week: 1, Weekday: wd, EmployeeID: a, ShiftCode: "E"},
+			model.PatternEntry{Week: 1, Weekday: wd, EmployeeID: b, ShiftCode: "L"},
+			model.PatternEntry{Week: 2, Weekday: wd, EmployeeID: a, ShiftCode: "L"},
+			model.PatternEntry{Week: 2, Weekday: wd, EmployeeID: b, ShiftCode: "E"},
+		)
+	}
+	p.Normalize()
+	return p
+}
+
+func TestExpand_Rotation(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	p := newRotation(a, b)
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	// 展开四周：两个完整的轮换周期
+	exp, err := Expand(p, "2026-03-01", "2026-03-28", Options{})
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if len(exp.Assignments) != 40 || !exp.Valid || len(exp.Conflicts) != 0 {
+		t.Fatalf("assignments = %d, valid = %v, conflicts = %+v", len(exp.Assignments), exp.Valid, exp.Conflicts)
+	}
+	early, late := p.Shift("E").ID, p.Shift("L").ID
+	want := map[string]uuid.UUID{"2026-03-02": early, "2026-03-09": late, "2026-03-16": early, "2026-03-23": late}
+	for _, as := range exp.Assignments {
+		if as.EmployeeID != a {
+			continue
+		}
+		if shift, ok := want[as.Date]; ok && as.ShiftID != shift {
+			t.Errorf("员工 A 在 %s 的班次不符合轮换", as.Date)
+		}
+		if as.StartTime.Weekday() == time.Saturday || as.StartTime.Weekday() == time.Sunday {
+			t.Errorf("周末不应排班: %s", as.Date)
+		}
+	}
+}
+
+func TestExpand_Conflicts(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	p := newRotation(a, b)
+
+	// 已有排班：员工 A 周一加班到 04:00，与周一 06:00 的早班间隔不足
+	start, _ := time.Parse("2006-01-02 15:04", "2026-03-01 20:00")
+	existing := &model.Assignment{
+		BaseModel:  model.NewBaseModel(),
+		EmployeeID: a,
+		Date:       "2026-03-01",
+		StartTime:  start,
+		EndTime:    start.Add(8 * time.Hour),
+	}
+	// 员工 B 周三不可用
+	empB := &model.Employee{BaseModel: model.BaseModel{ID: b}, Name: "李四", Status: "active",
+		Leaves: []model.EmployeeLeave{{StartDate: "2026-03-04", EndDate: "2026-03-04"}}}
+
+	exp, err := Expand(p, "2026-03-02", "2026-03-08", Options{
+		Employees: []*model.Employee{empB},
+		Existing:  []*model.Assignment{existing},
+	})
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if exp.Valid {
+		t.Fatal("存在冲突时 valid 应为 false")
+	}
+	types := make(map[validator.ConflictType]int)
+	for _, c := range exp.Conflicts {
+		types[c.Type]++
+	}
+	if types[validator.ConflictRestTime] != 1 || types[validator.ConflictAvailability] != 1 {
+		t.Errorf("conflicts = %+v", exp.Conflicts)
+	}
+
+	if _, err := Expand(p, "2026-03-08", "2026-03-01", Options{}); err == nil {
+		t.Error("结束日期早于开始日期应返回错误")
+	}
+	if _, err := Expand(p, "2026-01-01", "2027-06-01", Options{}); err == nil {
+		t.Error("超过最大展开天数应返回错误")
+	}
+}
+
+func TestSchedulePattern_Validate(t *testing.T) {
+	p := newRotation(uuid.New(), uuid.New())
+	p.Entries = append(p.Entries, model.PatternEntry{Week: 3, Weekday: time.Monday, EmployeeID: uuid.New(), ShiftCode: "E"})
+	if err := p.Validate(); err == nil {
+		t.Error("周次超出循环周数应返回错误")
+	}
+	p = newRotation(uuid.New(), uuid.New())
+	p.Entries[0].ShiftCode = "N"
+	if err := p.Validate(); err == nil {
+		t.Error("未定义的班次应返回错误")
+	}
+	if got := p.WeekOf("2026-03-10"); got != 2 {
+		t.Errorf("WeekOf(2026-03-10) = %d, want 2", got)
+	}
+	if got := p.WeekOf("2026-02-28"); got != 2 {
+		t.Errorf("WeekOf(2026-02-28) = %d, want 2", got)
+	}
+}
@@ -0,0 +1,402 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/optimizer"
+)
+
+// AnnealingSolver 模拟退火求解器
+// 以贪心解为初始解，在分配空间上做模拟退火搜索，每步针对一个未满足的需求：
+//   - 插入：直接分配一名通过硬约束检查的候选员工（贪心迭代次数用尽时遗漏的需求在这里补上）；
+//   - 换人：将一名满足技能/岗位/可用时段但被硬约束挡住的员工从其已有分配中调出，改派到该需求，
+//     再为调出的需求另找一人补位；
+//
+// 缺口不增加的换人总是接受，缺口增加的按温度以概率接受以跳出局部最优。
+// 始终保留搜索过程中缺口最小的方案，因此满足率不低于贪心解
+type AnnealingSolver struct {
+	greedy        *GreedySolver
+	progress      ProgressHook
+	maxIterations int
+	initialTemp   float64
+	coolingRate   float64
+	rng           *rand.Rand
+}
+
+// NewAnnealingSolver 创建模拟退火求解器
+func NewAnnealingSolver(cm *constraint.Manager) *AnnealingSolver {
+	return &AnnealingSolver{
+		greedy:        NewGreedySolver(cm),
+		maxIterations: 5000,
+		initialTemp:   2.0,
+		coolingRate:   0.999,
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Name 返回求解器名称
+func (s *AnnealingSolver) Name() string {
+	return "AnnealingSolver"
+}
+
+// SetMaxIterations 设置退火阶段的最大迭代次数
+func (s *AnnealingSolver) SetMaxIterations(max int) {
+	s.maxIterations = max
+}
+
+// SetSeed 设置随机种子（用于复现结果），贪心阶段使用同一种子
+func (s *AnnealingSolver) SetSeed(seed int64) {
+	s.rng = rand.New(rand.NewSource(seed))
+	s.greedy.SetSeed(seed)
+}
+
+// SetWorkers 设置贪心阶段和补位时并行评估候选人的工作协程数
+func (s *AnnealingSolver) SetWorkers(workers int) {
+	s.greedy.SetWorkers(workers)
+}
+
+// SetScoringProfile 设置多目标评分权重（退火搜索以需求缺口为目标，评分方案只用于报告最终方案的各目标子得分）
+func (s *AnnealingSolver) SetScoringProfile(profile ScoringProfile) {
+	s.greedy.SetScoringProfile(profile)
+}
+
+// SetProgressHook 设置求解进度回调：贪心阶段占整体进度的前一半，退火阶段每 100 次迭代报告一次
+func (s *AnnealingSolver) SetProgressHook(hook ProgressHook) {
+	s.progress = hook
+	if hook == nil {
+		s.greedy.SetProgressHook(nil)
+		return
+	}
+	s.greedy.SetProgressHook(func(p Progress) {
+		p.Fraction /= 2
+		hook(p)
+	})
+}
+
+// Solve 先用贪心算法生成初始解，再在剩余时间内做模拟退火搜索
+// 退火阶段超时或请求取消时返回已找到的最好方案
+func (s *AnnealingSolver) Solve(ctx context.Context, schedCtx *constraint.Context) (*Result, error) {
+	startTime := time.Now()
+	result, err := s.greedy.Solve(ctx, schedCtx)
+	if err != nil || len(schedCtx.Requirements) == 0 {
+		return result, err
+	}
+
+	phase := time.Now()
+	state := newAnnealState(schedCtx, result.Assignments)
+	optStats := &optimizer.Stats{StopReason: "max_iterations"}
+	best, bestEnergy := state.snapshot(), state.energy
+	temp := s.initialTemp
+
+	for optStats.Iterations < s.maxIterations {
+		if ctx.Err() != nil {
+			optStats.StopReason = "cancelled"
+			break
+		}
+		open := state.openRequirements()
+		if len(open) == 0 {
+			optStats.StopReason = "all_filled"
+			break
+		}
+		optStats.Iterations++
+		optStats.NeighborsGenerated++
+		if optStats.Iterations%100 == 0 {
+			s.progress.report(Progress{
+				Phase:       PhaseAnnealing,
+				Fraction:    0.5 + 0.5*float64(optStats.Iterations)/float64(s.maxIterations),
+				Round:       optStats.Iterations,
+				Assignments: len(state.movable),
+				Score:       fillRate(state.reqs, state.assigned),
+			})
+		}
+
+		before := state.energy
+		req := open[s.rng.Intn(len(open))]
+		undo := s.insert(state, req, uuid.Nil)
+		if undo == nil {
+			undo = s.reassign(state, req)
+		}
+		if undo == nil {
+			temp *= s.coolingRate
+			continue
+		}
+
+		delta := state.energy - before
+		if delta <= 0 || s.rng.Float64() < math.Exp(-float64(delta)/temp) {
+			optStats.AcceptedMoves++
+			if state.energy < bestEnergy {
+				optStats.Improvements++
+				best, bestEnergy = state.snapshot(), state.energy
+			}
+		} else {
+			undo()
+		}
+		temp *= s.coolingRate
+	}
+
+	state.restore(best)
+	optStats.TotalMs = msSince(phase)
+	result.Statistics.Timings.OptimizationMs = optStats.TotalMs
+	result.Statistics.Optimizer = optStats
+	result.Statistics.Iterations += optStats.Iterations
+	finishSearch(s.greedy, schedCtx, result, state)
+	result.Duration = time.Since(startTime)
+	result.Statistics.Timings.TotalMs = msSince(startTime)
+	return result, nil
+}
+
+// insert 为需求分配一名通过硬约束检查的候选员工（跳过 exclude），返回撤销函数，没有可分配的员工时返回 nil
+func (s *AnnealingSolver) insert(state *annealState, req *model.ShiftRequirement, exclude uuid.UUID) func() {
+	shift := state.ctx.GetShift(req.ShiftID)
+	if shift == nil {
+		return nil
+	}
+	scratch := &Statistics{CandidatesFiltered: make(map[string]int)}
+	candidates := s.greedy.getCandidates(state.ctx, req, state.hours, scratch)
+	a := s.greedy.pick(state.ctx, req, shift, candidates, exclude, nil)
+	if a == nil {
+		return nil
+	}
+	state.add(a)
+	return func() { state.remove(a) }
+}
+
+// reassign 将一名满足需求条件但被硬约束挡住的员工从其已有分配中调出并改派到需求，
+// 再为调出的需求补位，返回撤销函数，无法换人时返回 nil
+func (s *AnnealingSolver) reassign(state *annealState, req *model.ShiftRequirement) func() {
+	shift := state.ctx.GetShift(req.ShiftID)
+	if shift == nil {
+		return nil
+	}
+	shiftStart, shiftEnd := shiftTimes(req.Date, shift)
+	employees := state.ctx.Employees
+	for _, i := range s.rng.Perm(len(employees)) {
+		emp := employees[i]
+		if !emp.IsActive() || emp.IsExternal() || requirementFilter(emp, req, shift, shiftStart, shiftEnd) != "" {
+			continue
+		}
+		victim := state.victim(emp.ID, req.Date, s.rng)
+		if victim == nil {
+			continue
+		}
+
+		state.remove(victim)
+		a := s.greedy.createAssignment(state.ctx, emp, req, shift)
+		if s.greedy.constraintManager.BlockingConstraint(state.ctx, a) != nil {
+			state.add(victim)
+			continue
+		}
+		state.add(a)
+
+		var refill func()
+		if vr := state.requirement(victim); vr != nil {
+			refill = s.insert(state, vr, emp.ID)
+		}
+		return func() {
+			if refill != nil {
+				refill()
+			}
+			state.remove(a)
+			state.add(victim)
+		}
+	}
+	return nil
+}
+
+// finishSearch 按搜索得到的最终方案重新评估约束并更新统计
+func finishSearch(greedy *GreedySolver, schedCtx *constraint.Context, result *Result, state *annealState) {
+	result.Assignments = state.snapshot()
+	sort.SliceStable(result.Assignments, func(i, j int) bool {
+		if result.Assignments[i].Date != result.Assignments[j].Date {
+			return result.Assignments[i].Date < result.Assignments[j].Date
+		}
+		return result.Assignments[i].StartTime.Before(result.Assignments[j].StartTime)
+	})
+
+	stats := result.Statistics
+	stats.TotalAssignments = len(result.Assignments)
+	stats.FilledRequirements = 0
+	for _, req := range state.reqs {
+		if state.assigned[req.ID] >= req.MinEmployees {
+			stats.FilledRequirements++
+		}
+	}
+	stats.FillRate = 0
+	if len(state.reqs) > 0 {
+		stats.FillRate = float64(stats.FilledRequirements) / float64(len(state.reqs)) * 100
+	}
+
+	stats.TotalHours, stats.AvgHoursPerEmployee = 0, 0
+	activeEmployees := 0
+	for _, h := range state.hours {
+		stats.TotalHours += h
+		if h > 0 {
+			activeEmployees++
+		}
+	}
+	if activeEmployees > 0 {
+		stats.AvgHoursPerEmployee = stats.TotalHours / float64(activeEmployees)
+	}
+	stats.Stability = greedy.stability(schedCtx, result.Assignments)
+
+	phase := time.Now()
+	result.ConstraintResult = greedy.constraintManager.Evaluate(schedCtx)
+	stats.Timings.EvaluationMs += msSince(phase)
+	stats.Scoring = greedy.profile.score(result.ConstraintResult, state.reqs, state.assigned)
+	result.Success = result.ConstraintResult.IsValid
+	if !result.Success {
+		result.Message = fmt.Sprintf("存在 %d 个硬约束违反", len(result.ConstraintResult.HardViolations))
+	} else {
+		result.Message = fmt.Sprintf("排班成功，满足率 %.1f%%", stats.FillRate)
+	}
+}
+
+// annealState 退火搜索状态：求解器生成的分配可调整，上下文中原有的分配保持不变
+type annealState struct {
+	ctx      *constraint.Context
+	reqs     []*model.ShiftRequirement
+	reqByKey map[string]*model.ShiftRequirement
+	movable  map[uuid.UUID]*model.Assignment
+	assigned map[uuid.UUID]int // 需求ID -> 已分配人数
+	hours    map[uuid.UUID]float64
+	energy   int // 缺口：低于最少人数每人计 10，低于目标人数每人计 1
+}
+
+func newAnnealState(ctx *constraint.Context, assignments []*model.Assignment) *annealState {
+	// 待命需求由贪心求解器单独安排，不参与搜索
+	reqs, _ := splitOnCall(ctx, ctx.Requirements)
+	state := &annealState{
+		ctx:      ctx,
+		reqs:     reqs,
+		reqByKey: make(map[string]*model.ShiftRequirement, len(reqs)),
+		movable:  make(map[uuid.UUID]*model.Assignment, len(assignments)),
+		assigned: make(map[uuid.UUID]int, len(reqs)),
+		hours:    make(map[uuid.UUID]float64, len(ctx.Employees)),
+	}
+	for _, emp := range ctx.Employees {
+		state.hours[emp.ID] = 0
+	}
+	for _, req := range state.reqs {
+		state.reqByKey[requirementKey(req.ShiftID, req.Date, req.Position, req.StoreID)] = req
+	}
+	for _, a := range assignments {
+		state.movable[a.ID] = a
+		state.hours[a.EmployeeID] += a.WorkingHours()
+		if req := state.requirement(a); req != nil {
+			state.assigned[req.ID]++
+		}
+	}
+	for _, req := range state.reqs {
+		state.energy += shortfall(req, state.assigned[req.ID])
+	}
+	return state
+}
+
+// requirementKey 需求的唯一键（班次、日期、岗位、门店）
+func requirementKey(shiftID uuid.UUID, date, position, storeID string) string {
+	return shiftID.String() + "|" + date + "|" + position + "|" + storeID
+}
+
+// shortfall 需求在已分配 n 人时的缺口
+func shortfall(req *model.ShiftRequirement, n int) int {
+	target := req.MinEmployees
+	if req.OptEmployees > target {
+		target = req.OptEmployees
+	}
+	gap := 0
+	if n < req.MinEmployees {
+		gap += 10 * (req.MinEmployees - n)
+	}
+	if n < target {
+		gap += target - n
+	}
+	return gap
+}
+
+// requirement 返回分配对应的需求
+func (st *annealState) requirement(a *model.Assignment) *model.ShiftRequirement {
+	return st.reqByKey[requirementKey(a.ShiftID, a.Date, a.Position, a.StoreID)]
+}
+
+// add 添加分配并更新缺口
+func (st *annealState) add(a *model.Assignment) {
+	st.ctx.AddAssignment(a)
+	st.movable[a.ID] = a
+	st.hours[a.EmployeeID] += a.WorkingHours()
+	if req := st.requirement(a); req != nil {
+		st.energy -= shortfall(req, st.assigned[req.ID])
+		st.assigned[req.ID]++
+		st.energy += shortfall(req, st.assigned[req.ID])
+	}
+}
+
+// remove 移除分配并更新缺口
+func (st *annealState) remove(a *model.Assignment) {
+	st.ctx.RemoveAssignment(a.ID)
+	delete(st.movable, a.ID)
+	st.hours[a.EmployeeID] -= a.WorkingHours()
+	if req := st.requirement(a); req != nil {
+		st.energy -= shortfall(req, st.assigned[req.ID])
+		st.assigned[req.ID]--
+		st.energy += shortfall(req, st.assigned[req.ID])
+	}
+}
+
+// openRequirements 返回未达到目标人数的需求
+func (st *annealState) openRequirements() []*model.ShiftRequirement {
+	var open []*model.ShiftRequirement
+	for _, req := range st.reqs {
+		if shortfall(req, st.assigned[req.ID]) > 0 {
+			open = append(open, req)
+		}
+	}
+	return open
+}
+
+// victim 选择员工可调出的分配：当天已有分配时只能调出当天的（不可调整时返回 nil），否则随机选一个
+func (st *annealState) victim(empID uuid.UUID, date string, rng *rand.Rand) *model.Assignment {
+	var candidates []*model.Assignment
+	for _, a := range st.ctx.GetEmployeeAssignments(empID) {
+		if a.Date == date {
+			return st.movable[a.ID]
+		}
+		if st.movable[a.ID] != nil {
+			candidates = append(candidates, a)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[rng.Intn(len(candidates))]
+}
+
+// snapshot 返回当前可调整的分配
+func (st *annealState) snapshot() []*model.Assignment {
+	result := make([]*model.Assignment, 0, len(st.movable))
+	for _, a := range st.ctx.Assignments {
+		if st.movable[a.ID] != nil {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// restore 恢复到指定方案
+func (st *annealState) restore(assignments []*model.Assignment) {
+	fixed := make([]*model.Assignment, 0, len(st.ctx.Assignments))
+	for _, a := range st.ctx.Assignments {
+		if st.movable[a.ID] == nil {
+			fixed = append(fixed, a)
+		}
+	}
+	st.ctx.SetAssignments(append(fixed, assignments...))
+	*st = *newAnnealState(st.ctx, assignments)
+}
@@ -0,0 +1,160 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// Diagnosis 未满足需求的诊断：逐个检查员工，说明其为何不能再分配到该需求
+type Diagnosis struct {
+	Employees int            `json:"employees"`          // 参与诊断的员工数
+	Filtered  map[string]int `json:"filtered,omitempty"` // 不满足基本条件的淘汰原因 -> 人数（同候选筛选原因）
+	Eligible  int            `json:"eligible"`           // 满足基本条件的员工数
+	Blocked   []BlockedBy    `json:"blocked,omitempty"`  // 拦截满足基本条件员工的硬约束，按人数降序
+	Available int            `json:"available"`          // 满足基本条件且未被硬约束拦截的员工数
+	Summary   string         `json:"summary"`
+}
+
+// BlockedBy 拦截候选员工的硬约束，一名员工同时违反多个硬约束时在每个约束下各计一次
+type BlockedBy struct {
+	Constraint string   `json:"constraint"` // 约束类型
+	Name       string   `json:"name"`
+	Count      int      `json:"count"`
+	Employees  []string `json:"employees"` // 被拦截的员工姓名
+}
+
+// Diagnose 在排班结果（ctx 中的分配）的基础上诊断需求未排满的原因：
+// 先按候选筛选条件（在职、当天已排班、技能、岗位、门店、固定班次、请假、可用时段、外部人员工时上限）淘汰员工，
+// 再对满足基本条件的员工逐个检查全部硬约束，统计每个约束拦截的人数。
+// 待命需求按待命安排的条件诊断：外部人员和当天已待命的员工不参与，只检查待命安排约束
+func Diagnose(cm *constraint.Manager, ctx *constraint.Context, req *model.ShiftRequirement) *Diagnosis {
+	d := &Diagnosis{Employees: len(ctx.Employees), Filtered: make(map[string]int)}
+	shift := ctx.GetShift(req.ShiftID)
+	if shift == nil {
+		d.Summary = "班次不存在"
+		return d
+	}
+	shiftStart, shiftEnd := shiftTimes(req.Date, shift)
+	onCall := shift.IsOnCall()
+
+	hours := make(map[uuid.UUID]float64)
+	var externalHours float64
+	for _, a := range ctx.Assignments {
+		hours[a.EmployeeID] += a.WorkingHours()
+		if emp := ctx.GetEmployee(a.EmployeeID); emp != nil && emp.IsExternal() {
+			externalHours += a.WorkingHours()
+		}
+	}
+	onDate := make(map[uuid.UUID]bool)
+	for _, a := range ctx.OnCall {
+		if a.Date == req.Date {
+			onDate[a.EmployeeID] = true
+		}
+	}
+	shiftHours := shiftEnd.Sub(shiftStart).Hours()
+
+	hard := cm.GetByCategory(constraint.CategoryHard)
+	if onCall {
+		hard = nil
+		if oc := cm.GetConstraint(constraint.TypeOnCall); oc != nil {
+			hard = []constraint.Constraint{oc}
+		}
+	}
+	blocked := make(map[constraint.Type]*BlockedBy)
+	probe := newAssignment(ctx, &model.Employee{}, req, uuid.New(), shiftStart, shiftEnd)
+	for _, emp := range ctx.Employees {
+		reason := ""
+		switch {
+		case !emp.IsActive():
+			reason = FilterInactive
+		case onCall && (emp.IsExternal() || onDate[emp.ID]):
+			reason = FilterOnCall
+		case !onCall && ctx.GetEmployeeDayStats(emp.ID, req.Date).Shifts > 0:
+			reason = FilterAssignedToday
+		default:
+			reason = requirementFilter(emp, req, shift, shiftStart, shiftEnd)
+		}
+		if reason == "" && !onCall && emp.IsExternal() &&
+			((emp.External.MaxHours > 0 && hours[emp.ID]+shiftHours > emp.External.MaxHours) ||
+				(ctx.ExternalHoursCap > 0 && externalHours+shiftHours > ctx.ExternalHoursCap)) {
+			reason = FilterExternalCap
+		}
+		if reason != "" {
+			d.Filtered[reason]++
+			continue
+		}
+
+		d.Eligible++
+		probe.EmployeeID = emp.ID
+		ok := true
+		for _, c := range hard {
+			if valid, _ := c.EvaluateAssignment(ctx, probe); valid {
+				continue
+			}
+			ok = false
+			b := blocked[c.Type()]
+			if b == nil {
+				b = &BlockedBy{Constraint: string(c.Type()), Name: c.Name()}
+				blocked[c.Type()] = b
+			}
+			b.Count++
+			b.Employees = append(b.Employees, emp.Name)
+		}
+		if ok {
+			d.Available++
+		}
+	}
+
+	for _, b := range blocked {
+		d.Blocked = append(d.Blocked, *b)
+	}
+	sort.Slice(d.Blocked, func(i, j int) bool {
+		if d.Blocked[i].Count != d.Blocked[j].Count {
+			return d.Blocked[i].Count > d.Blocked[j].Count
+		}
+		return d.Blocked[i].Constraint < d.Blocked[j].Constraint
+	})
+	d.Summary = d.summary()
+	return d
+}
+
+// summary 生成诊断说明，如 "满足基本条件的 5 名员工均被硬约束拦截：min_rest_between_shifts 3 名、max_consecutive_days 2 名"
+func (d *Diagnosis) summary() string {
+	var parts []string
+	if d.Eligible == 0 {
+		reasons := make([]string, 0, len(d.Filtered))
+		for r := range d.Filtered {
+			reasons = append(reasons, r)
+		}
+		sort.Slice(reasons, func(i, j int) bool {
+			if d.Filtered[reasons[i]] != d.Filtered[reasons[j]] {
+				return d.Filtered[reasons[i]] > d.Filtered[reasons[j]]
+			}
+			return reasons[i] < reasons[j]
+		})
+		for _, r := range reasons {
+			parts = append(parts, fmt.Sprintf("%s %d 名", r, d.Filtered[r]))
+		}
+		if len(parts) == 0 {
+			return "没有员工"
+		}
+		return "没有满足基本条件的员工：" + strings.Join(parts, "、")
+	}
+
+	for _, b := range d.Blocked {
+		parts = append(parts, fmt.Sprintf("%s %d 名", b.Constraint, b.Count))
+	}
+	if d.Available == 0 {
+		return fmt.Sprintf("满足基本条件的 %d 名员工均被硬约束拦截：%s", d.Eligible, strings.Join(parts, "、"))
+	}
+	s := fmt.Sprintf("满足基本条件的 %d 名员工中仍有 %d 名可以分配", d.Eligible, d.Available)
+	if len(parts) > 0 {
+		s += "，其余被硬约束拦截：" + strings.Join(parts, "、")
+	}
+	return s
+}
@@ -2,15 +2,21 @@
 package solver
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
+	"slices"
 	"sort"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/holiday"
 	"github.com/paiban/paiban/pkg/logger"
 	"github.com/paiban/paiban/pkg/model"
 	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/optimizer"
 )
 
 // Solver 求解器接口
@@ -20,6 +26,9 @@ type Solver interface {
 
 	// Name 返回求解器名称
 	Name() string
+
+	// SetProgressHook 设置求解进度回调，nil 表示不报告进度
+	SetProgressHook(hook ProgressHook)
 }
 
 // Result 求解结果
@@ -30,6 +39,9 @@ type Result struct {
 	Duration         time.Duration       `json:"duration"`
 	Success          bool                `json:"success"`
 	Message          string              `json:"message,omitempty"`
+
+	// 待命分配（班次类型为 on_call），不计入 Assignments
+	OnCall []*model.Assignment `json:"on_call,omitempty"`
 }
 
 // Statistics 排班统计
@@ -41,6 +53,59 @@ type Statistics struct {
 	TotalHours          float64 `json:"total_hours"`
 	AvgHoursPerEmployee float64 `json:"avg_hours_per_employee"`
 	Iterations          int     `json:"iterations"`
+
+	// 求解过程剖析（用于调优）
+	Timings              *PhaseTimings       `json:"timings,omitempty"`
+	CandidatesConsidered int                 `json:"candidates_considered"`            // 进入约束检查的候选次数
+	CandidatesFiltered   map[string]int      `json:"candidates_filtered,omitempty"`    // 候选筛选阶段淘汰原因 -> 次数
+	RejectedByConstraint map[string]int      `json:"rejected_by_constraint,omitempty"` // 拒绝分配的硬约束类型 -> 次数
+	Optimizer            *optimizer.Stats    `json:"optimizer,omitempty"`              // 局部搜索优化统计（启用优化时）
+	Optimization         *OptimizationResult `json:"optimization,omitempty"`           // 优化阶段的目标值改进（optimization_level=2 时）
+
+	// 周间稳定性（与员工前几周同一星期几的班次比较），没有可比较的分配时为空
+	Stability *StabilityStats `json:"stability,omitempty"`
+
+	// 待命安排统计，没有待命需求时为空
+	OnCall *OnCallStats `json:"on_call,omitempty"`
+
+	// 生效的多目标评分方案及最终方案的各目标子得分
+	Scoring *ScoringResult `json:"scoring,omitempty"`
+}
+
+// StabilityStats 周间稳定性统计
+type StabilityStats struct {
+	Weeks      int     `json:"weeks"`      // 比较的周数
+	Comparable int     `json:"comparable"` // 前几周同一星期几有排班的分配数
+	Matched    int     `json:"matched"`    // 其中班次类型与之前一致的分配数
+	Score      float64 `json:"score"`      // Matched / Comparable * 100
+}
+
+// PhaseTimings 各阶段耗时（毫秒）
+type PhaseTimings struct {
+	CandidateGenerationMs float64 `json:"candidate_generation_ms"` // 候选员工筛选与排序
+	ConstraintCheckMs     float64 `json:"constraint_check_ms"`     // 候选的疲劳指数评估与硬约束检查
+	OptimizationMs        float64 `json:"optimization_ms"`         // 局部搜索优化
+	EvaluationMs          float64 `json:"evaluation_ms"`           // 最终方案的全量约束评估
+	TotalMs               float64 `json:"total_ms"`
+}
+
+// 候选筛选淘汰原因
+const (
+	FilterInactive      = "inactive"       // 非在职
+	FilterAssignedToday = "assigned_today" // 当天已排班
+	FilterSkill         = "skill"          // 技能不满足
+	FilterPosition      = "position"       // 岗位不匹配
+	FilterAvailability  = "availability"   // 不在可用时段
+	FilterLeave         = "leave"          // 当天请假
+	FilterStore         = "store"          // 不可在需求所属门店上班
+	FilterExternalCap   = "external_cap"   // 外部人员个人或本期总工时已达上限
+	FilterFixedShift    = "fixed_shift"    // 固定班次不含该班次或当天为固定休息日
+	FilterOnCall        = "on_call"        // 外部人员或当天已待命（仅待命需求）
+)
+
+// msSince 返回自 start 起经过的毫秒数
+func msSince(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
 }
 
 // GreedySolver 贪心求解器
@@ -48,6 +113,11 @@ type GreedySolver struct {
 	constraintManager *constraint.Manager
 	logger            *logger.SchedulerLogger
 	maxIterations     int
+	progress          ProgressHook
+	rng               *rand.Rand // 设置随机种子后用于候选打乱和分配ID，nil 时按输入顺序决定先后
+	workers           int        // 并行评估候选人的工作协程数，<= 1 时逐个评估
+
+	profile ScoringProfile // 多目标评分权重
 }
 
 // NewGreedySolver 创建贪心求解器
@@ -56,6 +126,7 @@ func NewGreedySolver(cm *constraint.Manager) *GreedySolver {
 		constraintManager: cm,
 		logger:            logger.NewSchedulerLogger(),
 		maxIterations:     1000,
+		profile:           DefaultScoringProfile(),
 	}
 }
 
@@ -69,6 +140,36 @@ func (s *GreedySolver) SetMaxIterations(max int) {
 	s.maxIterations = max
 }
 
+// SetSeed 设置随机种子：工作量和志愿相同的候选按种子打乱后决定先后，分配ID也由种子生成，
+// 相同输入和种子总是得到相同的排班（用于审计复现）
+func (s *GreedySolver) SetSeed(seed int64) {
+	s.rng = rand.New(rand.NewSource(seed))
+}
+
+// SetWorkers 设置并行评估候选人的工作协程数，<= 1 时逐个评估（默认）
+// 并行评估时每个需求的全部候选人同时做疲劳指数和硬约束检查，选中的员工与逐个评估相同
+func (s *GreedySolver) SetWorkers(workers int) {
+	s.workers = workers
+}
+
+// SetScoringProfile 设置多目标评分权重，只用于报告最终方案的各目标子得分，不影响候选排序（优化求解器以其作为目标函数）
+func (s *GreedySolver) SetScoringProfile(profile ScoringProfile) {
+	s.profile = profile
+}
+
+// newID 生成分配ID，设置了随机种子时由种子生成
+func (s *GreedySolver) newID() uuid.UUID {
+	if s.rng == nil {
+		return uuid.New()
+	}
+	return uuid.Must(uuid.NewRandomFromReader(s.rng))
+}
+
+// SetProgressHook 设置求解进度回调，每轮分配结束时报告
+func (s *GreedySolver) SetProgressHook(hook ProgressHook) {
+	s.progress = hook
+}
+
 // Solve 使用两阶段均衡贪心算法生成排班
 // 第一阶段：为每个需求分配最少1人（保证每天每班次都有基本覆盖）
 // 第二阶段：逐步增加人数直到满足最小需求
@@ -77,9 +178,17 @@ func (s *GreedySolver) Solve(ctx context.Context, schedCtx *constraint.Context)
 	startTime := time.Now()
 	s.logger.StartSchedule(schedCtx.OrgID.String(), len(schedCtx.Employees), countDays(schedCtx.StartDate, schedCtx.EndDate))
 
+	stats := &Statistics{
+		Timings:              &PhaseTimings{},
+		CandidatesFiltered:   make(map[string]int),
+		RejectedByConstraint: make(map[string]int),
+	}
+	defer func() {
+		stats.Timings.TotalMs = msSince(startTime)
+	}()
 	result := &Result{
 		Assignments: make([]*model.Assignment, 0),
-		Statistics:  &Statistics{},
+		Statistics:  stats,
 		Success:     false,
 	}
 
@@ -103,6 +212,8 @@ func (s *GreedySolver) Solve(ctx context.Context, schedCtx *constraint.Context)
 		}
 		return requirements[i].Date < requirements[j].Date // 早日期在前
 	})
+	// 待命需求不参与常规轮次，在常规班次排完后单独安排
+	requirements, onCallReqs := splitOnCall(schedCtx, requirements)
 
 	// 创建员工工作量跟踪
 	employeeHours := make(map[uuid.UUID]float64)
@@ -175,34 +286,42 @@ func (s *GreedySolver) Solve(ctx context.Context, schedCtx *constraint.Context)
 					continue
 				}
 
-				// 获取候选员工（按工作量升序排序以保证公平）
-				candidates := s.getCandidates(schedCtx, req, employeeHours)
-
-				assigned := false
-				for _, emp := range candidates {
-					if assigned {
-						break
+				// 获取候选员工（按工作量升序排序以保证公平），按顺序分配第一个通过硬约束检查的员工
+				phase := time.Now()
+				candidates := s.getCandidates(schedCtx, req, employeeHours, stats)
+				stats.Timings.CandidateGenerationMs += msSince(phase)
+
+				phase = time.Now()
+				assignment := s.pick(schedCtx, req, shift, candidates, uuid.Nil, func(a *model.Assignment, blocking constraint.Constraint) {
+					stats.CandidatesConsidered++
+					if blocking != nil {
+						stats.RejectedByConstraint[string(blocking.Type())]++
+						s.logger.ConstraintViolation("分配检查", fmt.Sprintf("员工 %s: 违反硬约束: %s",
+							schedCtx.GetEmployee(a.EmployeeID).Name, blocking.Name()))
 					}
+				})
+				stats.Timings.ConstraintCheckMs += msSince(phase)
 
-					// 创建候选分配
-					assignment := s.createAssignment(schedCtx, emp, req, shift)
-
-					// 检查约束
-					canAssign, reason := s.constraintManager.CanAssign(schedCtx, assignment)
-					if !canAssign {
-						s.logger.ConstraintViolation("分配检查", fmt.Sprintf("员工 %s: %s", emp.Name, reason))
-						continue
-					}
-
-					// 添加分配
+				// 添加分配
+				if assignment != nil {
 					schedCtx.AddAssignment(assignment)
 					result.Assignments = append(result.Assignments, assignment)
-					employeeHours[emp.ID] += assignment.WorkingHours()
+					employeeHours[assignment.EmployeeID] += assignment.WorkingHours()
 					reqAssigned[req.ID]++
-					assigned = true
 				}
 			}
 		}
+		s.progress.report(Progress{
+			Phase:       PhaseGreedy,
+			Fraction:    float64(round) / float64(maxRounds),
+			Round:       round,
+			Assignments: len(result.Assignments),
+			Score:       fillRate(requirements, reqAssigned),
+		})
+	}
+
+	if len(onCallReqs) > 0 {
+		result.OnCall = s.fillOnCall(schedCtx, onCallReqs, employeeHours, stats)
 	}
 
 	// 统计满足需求数
@@ -214,7 +333,9 @@ func (s *GreedySolver) Solve(ctx context.Context, schedCtx *constraint.Context)
 	}
 
 	// 评估最终结果
+	phase := time.Now()
 	result.ConstraintResult = s.constraintManager.Evaluate(schedCtx)
+	stats.Timings.EvaluationMs = msSince(phase)
 	result.Success = result.ConstraintResult.IsValid
 	result.Duration = time.Since(startTime)
 
@@ -243,6 +364,8 @@ func (s *GreedySolver) Solve(ctx context.Context, schedCtx *constraint.Context)
 	if activeEmployees > 0 {
 		result.Statistics.AvgHoursPerEmployee = totalHours / float64(activeEmployees)
 	}
+	result.Statistics.Stability = s.stability(schedCtx, result.Assignments)
+	result.Statistics.Scoring = s.profile.score(result.ConstraintResult, requirements, reqAssigned)
 
 	s.logger.ScheduleComplete(schedCtx.OrgID.String(), result.Duration, result.ConstraintResult.Score)
 
@@ -256,67 +379,362 @@ func (s *GreedySolver) Solve(ctx context.Context, schedCtx *constraint.Context)
 }
 
 // getCandidates 获取候选员工列表
-func (s *GreedySolver) getCandidates(ctx *constraint.Context, req *model.ShiftRequirement, hours map[uuid.UUID]float64) []*model.Employee {
-	var candidates []*model.Employee
-
-	// 获取该日期已分配的员工ID集合
-	assignedToday := make(map[uuid.UUID]bool)
-	for _, a := range ctx.GetDateAssignments(req.Date) {
-		assignedToday[a.EmployeeID] = true
+// 被淘汰的员工按原因累计到 stats.CandidatesFiltered
+func (s *GreedySolver) getCandidates(ctx *constraint.Context, req *model.ShiftRequirement, hours map[uuid.UUID]float64, stats *Statistics) []*model.Employee {
+	candidates := make([]*model.Employee, 0, len(ctx.Employees))
+
+	shift := ctx.GetShift(req.ShiftID)
+	var shiftStart, shiftEnd time.Time
+	if shift != nil {
+		shiftStart, shiftEnd = shiftTimes(req.Date, shift)
+	}
+	shiftHours := shiftEnd.Sub(shiftStart).Hours()
+	var externalHours float64
+	for _, emp := range ctx.Employees {
+		if emp.IsExternal() {
+			externalHours += hours[emp.ID]
+		}
 	}
 
 	for _, emp := range ctx.Employees {
 		if !emp.IsActive() {
+			stats.CandidatesFiltered[FilterInactive]++
 			continue
 		}
 
 		// 排除今天已经分配过的员工（每天最多1班）
-		if assignedToday[emp.ID] {
+		if ctx.GetEmployeeDayStats(emp.ID, req.Date).Shifts > 0 {
+			stats.CandidatesFiltered[FilterAssignedToday]++
 			continue
 		}
 
-		// 检查技能匹配
-		skillMatch := true
-		for _, skill := range req.Skills {
-			if !emp.HasSkill(skill) {
-				skillMatch = false
-				break
-			}
-		}
-		if !skillMatch {
+		// 检查技能、岗位和可用性
+		if reason := requirementFilter(emp, req, shift, shiftStart, shiftEnd); reason != "" {
+			stats.CandidatesFiltered[reason]++
 			continue
 		}
 
-		// 检查岗位匹配
-		if req.Position != "" && emp.Position != req.Position {
-			continue
+		// 外部人员受个人和本期总工时上限限制
+		if emp.IsExternal() {
+			if (emp.External.MaxHours > 0 && hours[emp.ID]+shiftHours > emp.External.MaxHours) ||
+				(ctx.ExternalHoursCap > 0 && externalHours+shiftHours > ctx.ExternalHoursCap) {
+				stats.CandidatesFiltered[FilterExternalCap]++
+				continue
+			}
 		}
 
 		candidates = append(candidates, emp)
 	}
 
+	// 评估候选人用的临时分配：共用一个分配对象和ID，只替换员工，不进入排班结果
+	var scratch *model.Assignment
+	probe := func(emp *model.Employee) *model.Assignment {
+		if scratch == nil {
+			scratch = newAssignment(ctx, emp, req, uuid.New(), shiftStart, shiftEnd)
+		}
+		scratch.EmployeeID = emp.ID
+		return scratch
+	}
+
+	// 启用周间稳定性约束时，与前几周同一星期几班次一致的员工按权重折减工作量
+	// （权重 100 相当于少算一个班次的工时），在稳定性和工作量均衡之间取舍
+	load := hours
+	if sc := s.constraintManager.GetConstraint(constraint.TypeScheduleStability); sc != nil && shift != nil {
+		weeks := stabilityWeeks(sc)
+		load = make(map[uuid.UUID]float64, len(candidates))
+		for _, emp := range candidates {
+			load[emp.ID] = hours[emp.ID]
+			if _, matched := ctx.StabilityMatch(probe(emp), weeks); matched {
+				load[emp.ID] -= shiftHours * float64(sc.Weight()) / 100
+			}
+		}
+	}
+
 	// 按工作量升序排序（工作量少的优先，确保公平）
-	sort.Slice(candidates, func(i, j int) bool {
-		return hours[candidates[i].ID] < hours[candidates[j].ID]
+	// 工作量相同时，班次志愿排名靠前的员工优先；设置了随机种子时其余并列按种子打乱后的顺序
+	if s.rng != nil {
+		s.rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	}
+	var tie func(*model.Employee) float64
+	if shift != nil {
+		tie = func(emp *model.Employee) float64 { return rankScore(emp, shift) }
+	}
+	sortCandidates(candidates, func(emp *model.Employee) float64 { return load[emp.ID] }, tie)
+
+	// 配置了班组时，同组成员当天已在本班次的员工优先，已在其他班次的员工靠后，尽量整组安排到同一班次
+	if tm := s.teamer(); tm != nil {
+		shiftOf := make(map[uuid.UUID]uuid.UUID)
+		for _, a := range ctx.GetDateAssignments(req.Date) {
+			shiftOf[a.EmployeeID] = a.ShiftID
+		}
+		if len(shiftOf) > 0 {
+			sortCandidates(candidates, func(emp *model.Employee) float64 {
+				score := 0
+				for _, id := range tm.Teammates(emp.ID) {
+					if other, ok := shiftOf[id]; ok {
+						if other == req.ShiftID {
+							score--
+						} else {
+							score++
+						}
+					}
+				}
+				return float64(score)
+			}, nil)
+		}
+	}
+
+	// 启用节假日值班约束时，节假日优先安排已值班天数（含上期）少的员工，轮流承担节假日值班
+	// 非节假日的分配不计值班天数，无需评估
+	if hc := s.constraintManager.GetConstraint(constraint.TypeHolidayHandling); hc != nil && shift != nil && isHoliday(hc, req.Date) {
+		sortCandidates(candidates, func(emp *model.Employee) float64 {
+			_, duty := hc.EvaluateAssignment(ctx, probe(emp))
+			return float64(duty)
+		}, nil)
+	}
+
+	// 启用人工成本预算约束且所在周/月的成本接近预算时，增加成本（含加班、节假日倍率）低的员工优先
+	if lc, ok := s.constraintManager.GetConstraint(constraint.TypeMaxLaborCost).(laborCoster); ok && shift != nil {
+		cost := make(map[uuid.UUID]float64, len(candidates))
+		tight := false
+		for _, emp := range candidates {
+			c, t := lc.MarginalCost(ctx, probe(emp))
+			cost[emp.ID], tight = c, tight || t
+		}
+		if tight {
+			sortCandidates(candidates, func(emp *model.Employee) float64 { return cost[emp.ID] }, nil)
+		}
+	}
+
+	// 外部人员排在所有内部员工之后，只在内部员工都无法分配时使用
+	internal, external := splitExternal(candidates)
+	return append(internal, external...)
+}
+
+// sortCandidates 按 key 升序稳定排序候选员工，key 相同时按 tie 降序（tie 可为空）
+// 排序键对每个员工只计算一次，避免比较时反复查表或评估约束；已按序（如键全部相同）时不排序
+func sortCandidates(candidates []*model.Employee, key, tie func(*model.Employee) float64) {
+	type keyed struct {
+		emp      *model.Employee
+		key, tie float64
+		index    int
+	}
+	items := make([]keyed, len(candidates))
+	ordered := true
+	for i, emp := range candidates {
+		items[i] = keyed{emp: emp, key: key(emp), index: i}
+		if tie != nil {
+			items[i].tie = tie(emp)
+		}
+		if i > 0 && (items[i].key < items[i-1].key || (items[i].key == items[i-1].key && items[i].tie > items[i-1].tie)) {
+			ordered = false
+		}
+	}
+	if ordered {
+		return
+	}
+
+	slices.SortFunc(items, func(a, b keyed) int {
+		if c := cmp.Compare(a.key, b.key); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.tie, a.tie); c != 0 {
+			return c
+		}
+		return a.index - b.index // 保持原顺序，排序稳定
 	})
+	for i, item := range items {
+		candidates[i] = item.emp
+	}
+}
 
-	return candidates
+// pick 按候选顺序返回第一个通过硬约束检查的分配（跳过 exclude），没有可用人选时返回 nil
+// 启用疲劳指数约束时，会因该分配进入高疲劳的员工推迟到同类（内部员工/外部人员）其他候选人之后，
+// 仅在无其他人选时使用；外部人员只在内部员工都无法分配时使用。
+// 逐个评估时疲劳指数只对通过硬约束检查的候选人计算，找到人选后不再评估其余候选人；
+// 设置了工作协程数时改为并行评估全部候选人（见 pickParallel），选中的员工相同。
+// 检查用的分配共用一个临时ID，只有选中的分配才生成正式ID，两种模式消耗的随机数相同。
+// onCheck 在调用方协程中接收每个检查过的分配及拦截它的硬约束（通过时为 nil），可为空
+func (s *GreedySolver) pick(ctx *constraint.Context, req *model.ShiftRequirement, shift *model.Shift, candidates []*model.Employee, exclude uuid.UUID, onCheck func(*model.Assignment, constraint.Constraint)) *model.Assignment {
+	start, end := shiftTimes(req.Date, shift)
+	probeID := uuid.New()
+	create := func(emp *model.Employee) *model.Assignment {
+		return newAssignment(ctx, emp, req, probeID, start, end)
+	}
+	var chosen *model.Assignment
+	if s.workers > 1 && len(candidates) > 1 {
+		chosen = s.pickParallel(ctx, candidates, exclude, create, onCheck)
+	} else {
+		chosen = s.pickSequential(ctx, candidates, exclude, create, onCheck)
+	}
+	if chosen != nil {
+		chosen.ID = s.newID()
+	}
+	return chosen
 }
 
-// createAssignment 创建排班分配
-func (s *GreedySolver) createAssignment(ctx *constraint.Context, emp *model.Employee, req *model.ShiftRequirement, shift *model.Shift) *model.Assignment {
-	// 解析班次时间
-	date, _ := time.Parse("2006-01-02", req.Date)
-	startTime := parseTimeOnDate(date, shift.StartTime)
-	endTime := parseTimeOnDate(date, shift.EndTime)
+// pickSequential 逐个评估候选人，找到人选后不再评估其余候选人
+func (s *GreedySolver) pickSequential(ctx *constraint.Context, candidates []*model.Employee, exclude uuid.UUID, create func(*model.Employee) *model.Assignment, onCheck func(*model.Assignment, constraint.Constraint)) *model.Assignment {
+	fc := s.constraintManager.GetConstraint(constraint.TypeFatigue)
+	check := func(a *model.Assignment) bool {
+		blocking := s.constraintManager.BlockingConstraint(ctx, a)
+		if onCheck != nil {
+			onCheck(a, blocking)
+		}
+		return blocking == nil
+	}
+	internal, external := splitExternal(candidates)
+	for _, group := range [][]*model.Employee{internal, external} {
+		// 疲劳指数只对通过硬约束检查的候选人计算，第一个通过且不疲劳的候选人即为人选
+		var tired *model.Assignment
+		for _, emp := range group {
+			if emp.ID == exclude {
+				continue
+			}
+			a := create(emp)
+			if !check(a) {
+				continue
+			}
+			if fc != nil {
+				if _, penalty := fc.EvaluateAssignment(ctx, a); penalty > 0 {
+					if tired == nil {
+						tired = a
+					}
+					continue
+				}
+			}
+			return a
+		}
+		if tired != nil {
+			return tired
+		}
+	}
+	return nil
+}
 
-	// 处理跨日班次
-	if !endTime.After(startTime) {
-		endTime = endTime.Add(24 * time.Hour)
+// splitExternal 按原顺序拆分内部员工和外部人员
+func splitExternal(candidates []*model.Employee) (internal, external []*model.Employee) {
+	internal = make([]*model.Employee, 0, len(candidates))
+	for _, emp := range candidates {
+		if emp.IsExternal() {
+			external = append(external, emp)
+			continue
+		}
+		internal = append(internal, emp)
+	}
+	return internal, external
+}
+
+// requirementFilter 检查员工是否满足需求的技能、岗位、门店、固定班次、请假和可用时段，不满足时返回淘汰原因
+func requirementFilter(emp *model.Employee, req *model.ShiftRequirement, shift *model.Shift, shiftStart, shiftEnd time.Time) string {
+	// 检查技能匹配（必需技能 + 技能组 + 技能等级）
+	if !emp.MeetsSkillRequirements(req.Skills, req.SkillGroups) || len(emp.MissingSkillLevels(req.SkillLevels)) > 0 {
+		return FilterSkill
+	}
+
+	// 检查岗位匹配
+	if req.Position != "" && emp.Position != req.Position {
+		return FilterPosition
+	}
+
+	// 多门店排班时只能排到所属门店或可支援门店
+	if !emp.CanWorkAt(req.StoreID) {
+		return FilterStore
+	}
+
+	// 设置了固定班次的员工只排固定班次（如只上夜班），固定休息日不排班
+	if emp.Preferences != nil {
+		if shift != nil && !emp.Preferences.AllowsShift(shift.Code, shift.ShiftType) {
+			return FilterFixedShift
+		}
+		if day, err := time.Parse("2006-01-02", req.Date); err == nil && emp.Preferences.IsFixedDayOff(day.Weekday()) {
+			return FilterFixedShift
+		}
+	}
+
+	// 请假期间全天不可排班
+	if emp.LeaveOn(req.Date) != nil {
+		return FilterLeave
+	}
+
+	// 检查可用性（班次时段须落在员工当日可用时段内，且不与不可用时段重叠）
+	if shift != nil && !emp.IsAvailable(req.Date, shiftStart, shiftEnd) {
+		return FilterAvailability
+	}
+	return ""
+}
+
+// isHoliday 节假日约束提供日历时按日历判断日期是否为节假日，否则视为节假日（交由约束逐个评估）
+func isHoliday(c constraint.Constraint, date string) bool {
+	hc, ok := c.(interface{ Calendar() *holiday.Calendar })
+	return !ok || hc.Calendar() == nil || hc.Calendar().IsHoliday(date)
+}
+
+// teamer 班组约束：返回员工所在班组的其他成员
+type teamer interface {
+	Teammates(employeeID uuid.UUID) []uuid.UUID
+}
+
+// teamer 返回启用的班组约束（班组完整性约束包含全部班组，优先使用），未配置班组时返回 nil
+func (s *GreedySolver) teamer() teamer {
+	for _, t := range []constraint.Type{constraint.TypeTeamTogether, constraint.TypeTeamCohesion} {
+		if tm, ok := s.constraintManager.GetConstraint(t).(teamer); ok {
+			return tm
+		}
+	}
+	return nil
+}
+
+// laborCoster 人工成本预算约束：估算分配增加的人工成本及预算是否紧张
+type laborCoster interface {
+	MarginalCost(ctx *constraint.Context, a *model.Assignment) (float64, bool)
+}
+
+// stabilityWeeks 返回周间稳定性约束比较的周数，未启用时使用默认周数
+func stabilityWeeks(c constraint.Constraint) int {
+	if sc, ok := c.(interface{ StabilityWeeks() int }); ok {
+		return sc.StabilityWeeks()
+	}
+	return constraint.DefaultStabilityWeeks
+}
+
+// stability 统计方案的周间稳定性，没有可比较的分配时返回 nil
+func (s *GreedySolver) stability(ctx *constraint.Context, assignments []*model.Assignment) *StabilityStats {
+	stats := &StabilityStats{Weeks: stabilityWeeks(s.constraintManager.GetConstraint(constraint.TypeScheduleStability))}
+	for _, a := range assignments {
+		comparable, matched := ctx.StabilityMatch(a, stats.Weeks)
+		if comparable {
+			stats.Comparable++
+		}
+		if matched {
+			stats.Matched++
+		}
 	}
+	if stats.Comparable == 0 {
+		return nil
+	}
+	stats.Score = math.Round(float64(stats.Matched)/float64(stats.Comparable)*1000) / 10
+	return stats
+}
+
+// rankScore 返回员工对班次的志愿得分
+func rankScore(emp *model.Employee, shift *model.Shift) float64 {
+	if !emp.Preferences.HasShiftRankings() {
+		return 0
+	}
+	return emp.Preferences.ShiftRankScore(shift.Code, shift.ShiftType)
+}
+
+// createAssignment 创建排班分配
+func (s *GreedySolver) createAssignment(ctx *constraint.Context, emp *model.Employee, req *model.ShiftRequirement, shift *model.Shift) *model.Assignment {
+	startTime, endTime := shiftTimes(req.Date, shift)
+	return newAssignment(ctx, emp, req, s.newID(), startTime, endTime)
+}
 
+// newAssignment 按已算好的班次起止时间创建分配
+func newAssignment(ctx *constraint.Context, emp *model.Employee, req *model.ShiftRequirement, id uuid.UUID, startTime, endTime time.Time) *model.Assignment {
 	return &model.Assignment{
-		BaseModel:  model.BaseModel{ID: uuid.New()},
+		BaseModel:  model.BaseModel{ID: id},
 		OrgID:      ctx.OrgID,
 		EmployeeID: emp.ID,
 		ShiftID:    req.ShiftID,
@@ -324,10 +742,24 @@ func (s *GreedySolver) createAssignment(ctx *constraint.Context, emp *model.Empl
 		StartTime:  startTime,
 		EndTime:    endTime,
 		Position:   req.Position,
+		StoreID:    req.StoreID,
 		Status:     "scheduled",
 	}
 }
 
+// shiftTimes 计算班次在指定日期的起止时间（跨日班次结束时间顺延一天）
+func shiftTimes(dateStr string, shift *model.Shift) (time.Time, time.Time) {
+	date, _ := time.Parse("2006-01-02", dateStr)
+	startTime := parseTimeOnDate(date, shift.StartTime)
+	endTime := parseTimeOnDate(date, shift.EndTime)
+
+	// 处理跨日班次
+	if !endTime.After(startTime) {
+		endTime = endTime.Add(24 * time.Hour)
+	}
+	return startTime, endTime
+}
+
 // parseTimeOnDate 在指定日期解析时间
 func parseTimeOnDate(date time.Time, timeStr string) time.Time {
 	t, err := time.Parse("15:04", timeStr)
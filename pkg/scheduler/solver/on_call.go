@@ -0,0 +1,116 @@
+package solver
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// OnCallStats 待命安排统计，与常规班次分开统计（不计入满足率和工时）
+type OnCallStats struct {
+	TotalRequirements  int            `json:"total_requirements"`
+	FilledRequirements int            `json:"filled_requirements"`
+	Assignments        int            `json:"assignments"`
+	ByEmployee         map[string]int `json:"by_employee"` // 员工ID -> 待命次数
+}
+
+// splitOnCall 将需求拆分为常规需求和待命需求（班次类型为 on_call），保持原有顺序
+func splitOnCall(ctx *constraint.Context, requirements []*model.ShiftRequirement) (regular, onCall []*model.ShiftRequirement) {
+	for _, req := range requirements {
+		if shift := ctx.GetShift(req.ShiftID); shift != nil && shift.IsOnCall() {
+			onCall = append(onCall, req)
+		} else {
+			regular = append(regular, req)
+		}
+	}
+	return regular, onCall
+}
+
+// fillOnCall 在常规班次排完后按日期顺序安排待命
+// 候选须满足需求的技能、岗位和可用性条件，外部人员不参与待命；待命次数少的员工优先（轮流待命），
+// 次数相同时本期工时少的优先，并通过待命安排约束检查（每周次数上限、夜班次日不待命、不与常规班次重叠）
+func (s *GreedySolver) fillOnCall(ctx *constraint.Context, requirements []*model.ShiftRequirement, hours map[uuid.UUID]float64, stats *Statistics) []*model.Assignment {
+	reqs := make([]*model.ShiftRequirement, len(requirements))
+	copy(reqs, requirements)
+	sort.SliceStable(reqs, func(i, j int) bool { return reqs[i].Date < reqs[j].Date })
+
+	oc := s.constraintManager.GetConstraint(constraint.TypeOnCall)
+	counts := make(map[uuid.UUID]int)
+	for _, a := range ctx.OnCall {
+		counts[a.EmployeeID]++
+	}
+	onCallStats := &OnCallStats{TotalRequirements: len(reqs), ByEmployee: make(map[string]int)}
+
+	var result []*model.Assignment
+	for _, req := range reqs {
+		shift := ctx.GetShift(req.ShiftID)
+		if shift == nil {
+			continue
+		}
+		target := req.MinEmployees
+		if req.OptEmployees > target {
+			target = req.OptEmployees
+		}
+		shiftStart, shiftEnd := shiftTimes(req.Date, shift)
+
+		filled := 0
+		for filled < target {
+			onDate := make(map[uuid.UUID]bool)
+			for _, a := range ctx.OnCall {
+				if a.Date == req.Date {
+					onDate[a.EmployeeID] = true
+				}
+			}
+			var candidates []*model.Employee
+			for _, emp := range ctx.Employees {
+				if !emp.IsActive() || emp.IsExternal() || onDate[emp.ID] {
+					continue
+				}
+				if requirementFilter(emp, req, shift, shiftStart, shiftEnd) != "" {
+					continue
+				}
+				candidates = append(candidates, emp)
+			}
+			if s.rng != nil {
+				s.rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+			}
+			sortCandidates(candidates, func(emp *model.Employee) float64 {
+				return float64(counts[emp.ID])
+			}, func(emp *model.Employee) float64 {
+				return -hours[emp.ID]
+			})
+
+			var picked *model.Assignment
+			for _, emp := range candidates {
+				a := newAssignment(ctx, emp, req, uuid.Nil, shiftStart, shiftEnd)
+				stats.CandidatesConsidered++
+				if oc != nil {
+					if ok, _ := oc.EvaluateAssignment(ctx, a); !ok {
+						stats.RejectedByConstraint[string(oc.Type())]++
+						continue
+					}
+				}
+				a.ID = s.newID()
+				picked = a
+				break
+			}
+			if picked == nil {
+				break
+			}
+			ctx.AddOnCall(picked)
+			result = append(result, picked)
+			counts[picked.EmployeeID]++
+			onCallStats.ByEmployee[picked.EmployeeID.String()]++
+			filled++
+		}
+		if filled >= req.MinEmployees {
+			onCallStats.FilledRequirements++
+		}
+	}
+
+	onCallStats.Assignments = len(result)
+	stats.OnCall = onCallStats
+	return result
+}
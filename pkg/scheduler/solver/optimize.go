@@ -0,0 +1,406 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/optimizer"
+)
+
+// 优化目标的权重：硬约束违反和无效分配远重于需求缺口，需求缺口重于软约束惩罚
+const (
+	hardViolationPenalty = 1000.0 // 每个硬约束违反或无效分配
+	shortfallPenalty     = 100.0  // 需求缺口每单位（低于最少人数每人 10 单位，低于目标人数每人 1 单位）
+)
+
+// ConstraintEvaluator 约束评估适配器，将 constraint.Manager 桥接为 optimizer.ConstraintEvaluator
+// 优化器的邻域移动只交换员工或班次ID，不维护分配的日期、起止时间和需求归属，评估前先按需求规整：
+// 不对应任何需求、员工不满足需求条件（技能、岗位、门店、可用时段、请假）、同一员工当天重复排班或
+// 外部人员超出工时上限的分配视为无效。评分越低越好：
+//
+//	硬约束违反数 × 1000 + 无效分配数 × 1000 + 需求缺口 × 100 + 约束总惩罚
+//
+// 约束总惩罚逐个累加已注册约束的惩罚值（含未标记为违反的软约束，如员工偏好）。
+// 每次评估使用独立的约束上下文，可被 ParallelEvaluator 并发调用。
+// 同时实现 optimizer.Repairer，以贪心构造修复大邻域搜索破坏后的方案
+type ConstraintEvaluator struct {
+	cm       *constraint.Manager
+	greedy   *GreedySolver
+	base     *constraint.Context
+	fixed    []*model.Assignment // 上下文中不参与优化的分配
+	reqByKey map[string]*model.ShiftRequirement
+
+	reqs []*model.ShiftRequirement // 参与优化的常规需求（待命需求由贪心求解器单独安排）
+
+	profile ScoringProfile // 目标函数的多目标评分权重
+}
+
+// NewConstraintEvaluator 创建约束评估适配器
+// base 提供需求、历史和约束配置，fixed 为评估时始终保留的分配（如锁定的排班）
+func NewConstraintEvaluator(cm *constraint.Manager, base *constraint.Context, fixed []*model.Assignment) *ConstraintEvaluator {
+	e := &ConstraintEvaluator{
+		cm:       cm,
+		greedy:   NewGreedySolver(cm),
+		base:     base,
+		fixed:    fixed,
+		reqByKey: make(map[string]*model.ShiftRequirement, len(base.Requirements)),
+		profile:  DefaultScoringProfile(),
+	}
+	e.reqs, _ = splitOnCall(base, base.Requirements)
+	for _, req := range e.reqs {
+		e.reqByKey[requirementKey(req.ShiftID, req.Date, req.Position, req.StoreID)] = req
+	}
+	return e
+}
+
+// evaluation 一次评估的明细
+type evaluation struct {
+	score      float64
+	hard       int // 硬约束违反数
+	invalid    int // 无效分配数
+	filled     int // 满足最少人数的需求数
+	violations []string
+}
+
+// Evaluate 实现 optimizer.ConstraintEvaluator
+func (e *ConstraintEvaluator) Evaluate(assignments []*model.Assignment, employees []*model.Employee, shifts []*model.Shift) (float64, []string) {
+	ev := e.evaluate(assignments, employees, shifts)
+	return ev.score, ev.violations
+}
+
+// evaluate 规整分配并评估约束
+func (e *ConstraintEvaluator) evaluate(assignments []*model.Assignment, employees []*model.Employee, shifts []*model.Shift) evaluation {
+	ctx := e.newContext(employees, shifts)
+	valid, invalid := e.normalize(ctx, assignments)
+	ctx.SetAssignments(e.withFixed(valid))
+
+	ev := evaluation{invalid: len(invalid), violations: invalid}
+	penalty := 0
+	soft := &constraint.Result{SoftPenalties: make(map[constraint.Type]int)}
+	for _, c := range e.cm.GetAll() {
+		valid, p, details := c.Evaluate(ctx)
+		if c.Category() != constraint.CategoryHard {
+			soft.SoftPenalties[c.Type()] += p // 部分软约束只报告惩罚值，不标记为违反
+			continue
+		}
+		if !valid {
+			penalty += p
+			ev.hard += len(details)
+			for _, d := range details {
+				ev.violations = append(ev.violations, d.Message)
+			}
+		}
+	}
+
+	assigned := make(map[uuid.UUID]int, len(e.reqs))
+	for _, a := range valid {
+		assigned[e.requirement(a).ID]++
+	}
+	for _, req := range e.reqs {
+		if assigned[req.ID] >= req.MinEmployees {
+			ev.filled++
+		}
+	}
+	// 需求缺口和软约束惩罚按评分方案加权，违反的硬约束按固定惩罚计入
+	ev.score = hardViolationPenalty*float64(ev.hard+ev.invalid) + float64(penalty) + e.profile.score(soft, e.reqs, assigned).Weighted
+	return ev
+}
+
+// Repair 实现 optimizer.Repairer：规整保留的分配后按优先级和日期为未达到目标人数的需求贪心补位，
+// 候选员工按工作量排序，本次被移除的员工排在最后，避免原样排回
+func (e *ConstraintEvaluator) Repair(kept, removed []*model.Assignment, employees []*model.Employee, shifts []*model.Shift) []*model.Assignment {
+	ctx := e.newContext(employees, shifts)
+	repaired, _ := e.normalize(ctx, kept)
+	ctx.SetAssignments(e.withFixed(repaired))
+
+	hours := make(map[uuid.UUID]float64, len(employees))
+	for _, a := range ctx.Assignments {
+		hours[a.EmployeeID] += a.WorkingHours()
+	}
+	assigned := make(map[uuid.UUID]int, len(e.reqs))
+	for _, a := range repaired {
+		assigned[e.requirement(a).ID]++
+	}
+	previous := make(map[string]bool, len(removed))
+	for _, a := range removed {
+		previous[requirementKey(a.ShiftID, a.Date, a.Position, a.StoreID)+a.EmployeeID.String()] = true
+	}
+
+	requirements := make([]*model.ShiftRequirement, len(e.reqs))
+	copy(requirements, e.reqs)
+	sort.SliceStable(requirements, func(i, j int) bool {
+		if requirements[i].Priority != requirements[j].Priority {
+			return requirements[i].Priority > requirements[j].Priority
+		}
+		return requirements[i].Date < requirements[j].Date
+	})
+	scratch := &Statistics{CandidatesFiltered: make(map[string]int)}
+	for _, req := range requirements {
+		shift := ctx.GetShift(req.ShiftID)
+		if shift == nil {
+			continue
+		}
+		key := requirementKey(req.ShiftID, req.Date, req.Position, req.StoreID)
+		for assigned[req.ID] < max(req.MinEmployees, req.OptEmployees) {
+			candidates := e.greedy.getCandidates(ctx, req, hours, scratch)
+			sort.SliceStable(candidates, func(i, j int) bool {
+				return !previous[key+candidates[i].ID.String()] && previous[key+candidates[j].ID.String()]
+			})
+			added := e.greedy.pick(ctx, req, shift, candidates, uuid.Nil, nil)
+			if added == nil {
+				break
+			}
+			ctx.AddAssignment(added)
+			repaired = append(repaired, added)
+			hours[added.EmployeeID] += added.WorkingHours()
+			assigned[req.ID]++
+		}
+	}
+	return repaired
+}
+
+// newContext 创建评估用的约束上下文（需求、历史和配置取自基准上下文）
+func (e *ConstraintEvaluator) newContext(employees []*model.Employee, shifts []*model.Shift) *constraint.Context {
+	ctx := constraint.NewContext(e.base.OrgID, e.base.StartDate, e.base.EndDate)
+	ctx.SetEmployees(employees)
+	ctx.SetShifts(shifts)
+	ctx.SetHistory(e.base.History, e.base.HistoryShifts)
+	ctx.Requirements = e.base.Requirements
+	ctx.SetOnCall(e.base.OnCall)
+	ctx.ExternalHoursCap = e.base.ExternalHoursCap
+	ctx.Config = e.base.Config
+	return ctx
+}
+
+// withFixed 返回加上固定分配后的全部分配
+func (e *ConstraintEvaluator) withFixed(assignments []*model.Assignment) []*model.Assignment {
+	all := make([]*model.Assignment, 0, len(e.fixed)+len(assignments))
+	return append(append(all, e.fixed...), assignments...)
+}
+
+// normalize 按需求规整分配（复制后重新计算起止时间），返回有效分配和无效分配的原因
+func (e *ConstraintEvaluator) normalize(ctx *constraint.Context, assignments []*model.Assignment) ([]*model.Assignment, []string) {
+	valid := make([]*model.Assignment, 0, len(assignments))
+	var invalid []string
+	assignedToday := make(map[string]bool, len(e.fixed)+len(assignments))
+	for _, a := range e.fixed {
+		assignedToday[a.EmployeeID.String()+a.Date] = true
+	}
+	hours := make(map[uuid.UUID]float64)
+	var externalHours float64
+
+	for _, a := range assignments {
+		req := e.requirement(a)
+		emp := ctx.GetEmployee(a.EmployeeID)
+		shift := ctx.GetShift(a.ShiftID)
+		if req == nil || emp == nil || shift == nil {
+			invalid = append(invalid, fmt.Sprintf("分配 %s/%s 不对应任何排班需求", a.ShiftID, a.Date))
+			continue
+		}
+		start, end := shiftTimes(a.Date, shift)
+		if !emp.IsActive() || requirementFilter(emp, req, shift, start, end) != "" {
+			invalid = append(invalid, fmt.Sprintf("员工 %s 不满足 %s 的需求条件", emp.Name, a.Date))
+			continue
+		}
+		dayKey := emp.ID.String() + a.Date
+		if assignedToday[dayKey] {
+			invalid = append(invalid, fmt.Sprintf("员工 %s 在 %s 重复排班", emp.Name, a.Date))
+			continue
+		}
+		shiftHours := end.Sub(start).Hours()
+		if emp.IsExternal() {
+			if (emp.External.MaxHours > 0 && hours[emp.ID]+shiftHours > emp.External.MaxHours) ||
+				(ctx.ExternalHoursCap > 0 && externalHours+shiftHours > ctx.ExternalHoursCap) {
+				invalid = append(invalid, fmt.Sprintf("外部人员 %s 超出工时上限", emp.Name))
+				continue
+			}
+			externalHours += shiftHours
+		}
+		assignedToday[dayKey] = true
+		hours[emp.ID] += shiftHours
+
+		clone := *a
+		clone.OrgID = e.base.OrgID
+		clone.StartTime, clone.EndTime = start, end
+		valid = append(valid, &clone)
+	}
+	return valid, invalid
+}
+
+// requirement 返回分配对应的需求
+func (e *ConstraintEvaluator) requirement(a *model.Assignment) *model.ShiftRequirement {
+	return e.reqByKey[requirementKey(a.ShiftID, a.Date, a.Position, a.StoreID)]
+}
+
+// OptimizationResult 优化阶段的结果
+type OptimizationResult struct {
+	Algorithm    string  `json:"algorithm"`
+	InitialScore float64 `json:"initial_score"` // 贪心解的目标值（越低越好）
+	FinalScore   float64 `json:"final_score"`   // 优化后的目标值，未采用时等于初始值
+	Improvement  float64 `json:"improvement"`   // 目标值下降比例（%）
+	Applied      bool    `json:"applied"`       // 是否采用了优化后的方案
+}
+
+// DefaultOptimizationConfig 生成排班时优化阶段的默认配置
+// 每次评估都做全量约束检查，迭代次数和运行时间较 optimizer.DefaultOptConfig 收紧
+func DefaultOptimizationConfig() *optimizer.OptimizationConfig {
+	config := optimizer.DefaultOptConfig()
+	config.MaxIterations = 200
+	config.MaxTime = 5 * time.Second
+	config.NeighborhoodSize = 10
+	config.PlateauThreshold = 30
+	config.PopulationSize = 20
+	return config
+}
+
+// OptimizingSolver 贪心 + 优化求解器
+// 先用贪心算法生成初始解，再用 optimizer 包的优化器（局部搜索或遗传算法）以 ConstraintEvaluator
+// 评分搜索更优的方案。优化后的方案仅在评分更低、满足的需求数不减少且硬约束违反不增加时采用，
+// 否则保留贪心解
+type OptimizingSolver struct {
+	greedy   *GreedySolver
+	config   *optimizer.OptimizationConfig
+	progress ProgressHook
+	seed     int64
+}
+
+// NewOptimizingSolver 创建贪心 + 优化求解器，config 为 nil 时使用 DefaultOptimizationConfig
+func NewOptimizingSolver(cm *constraint.Manager, config *optimizer.OptimizationConfig) *OptimizingSolver {
+	if config == nil {
+		config = DefaultOptimizationConfig()
+	}
+	return &OptimizingSolver{
+		greedy: NewGreedySolver(cm),
+		config: config,
+	}
+}
+
+// Name 返回求解器名称
+func (s *OptimizingSolver) Name() string {
+	return "OptimizingSolver"
+}
+
+// SetSeed 设置随机种子（用于复现结果），贪心阶段、修复算子和优化器均由该种子派生
+func (s *OptimizingSolver) SetSeed(seed int64) {
+	s.seed = seed
+	s.greedy.SetSeed(seed)
+}
+
+// SetWorkers 设置贪心阶段并行评估候选人的工作协程数
+func (s *OptimizingSolver) SetWorkers(workers int) {
+	s.greedy.SetWorkers(workers)
+}
+
+// SetScoringProfile 设置多目标评分权重，作为优化阶段的目标函数
+func (s *OptimizingSolver) SetScoringProfile(profile ScoringProfile) {
+	s.greedy.SetScoringProfile(profile)
+}
+
+// SetProgressHook 设置求解进度回调：贪心阶段占整体进度的前一半，优化完成时报告一次
+func (s *OptimizingSolver) SetProgressHook(hook ProgressHook) {
+	s.progress = hook
+	if hook == nil {
+		s.greedy.SetProgressHook(nil)
+		return
+	}
+	s.greedy.SetProgressHook(func(p Progress) {
+		p.Fraction /= 2
+		hook(p)
+	})
+}
+
+// Solve 先用贪心算法生成初始解，再在剩余时间内优化
+// 优化阶段超时或请求取消时使用已找到的最好方案
+func (s *OptimizingSolver) Solve(ctx context.Context, schedCtx *constraint.Context) (*Result, error) {
+	startTime := time.Now()
+	result, err := s.greedy.Solve(ctx, schedCtx)
+	if err != nil || len(schedCtx.Requirements) == 0 || len(result.Assignments) == 0 {
+		return result, err
+	}
+
+	phase := time.Now()
+	generated := make(map[uuid.UUID]bool, len(result.Assignments))
+	for _, a := range result.Assignments {
+		generated[a.ID] = true
+	}
+	var fixed []*model.Assignment
+	for _, a := range schedCtx.Assignments {
+		if !generated[a.ID] {
+			fixed = append(fixed, a)
+		}
+	}
+	evaluator := NewConstraintEvaluator(s.greedy.constraintManager, schedCtx, fixed)
+	evaluator.profile = s.greedy.profile
+	opt, err := optimizer.NewOptimizer(s.config, evaluator)
+	if err != nil {
+		return nil, err
+	}
+	if s.seed != 0 {
+		evaluator.greedy.SetSeed(s.seed + 2)
+		if seeded, ok := opt.(interface{ SetSeed(int64) }); ok {
+			seeded.SetSeed(s.seed)
+		}
+	}
+
+	before := evaluator.evaluate(result.Assignments, schedCtx.Employees, schedCtx.Shifts)
+	initial := &optimizer.Solution{
+		Assignments: result.Assignments,
+		Score:       before.score,
+		Violations:  before.violations,
+		Feasible:    len(before.violations) == 0,
+	}
+	best, err := opt.Optimize(ctx, initial, schedCtx.Employees, schedCtx.Shifts)
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+
+	optStats := opt.Stats()
+	outcome := &OptimizationResult{
+		Algorithm:    s.config.Algorithm,
+		InitialScore: before.score,
+		FinalScore:   before.score,
+	}
+	if outcome.Algorithm == "" {
+		outcome.Algorithm = optimizer.AlgorithmLocalSearch
+	}
+	if best != nil {
+		candidate, _ := evaluator.normalize(schedCtx, best.Assignments)
+		after := evaluator.evaluate(candidate, schedCtx.Employees, schedCtx.Shifts)
+		if after.score < before.score && after.filled >= before.filled && after.hard <= before.hard {
+			seen := make(map[uuid.UUID]bool, len(candidate))
+			for _, a := range candidate {
+				if a.ID == uuid.Nil || seen[a.ID] {
+					a.ID = s.greedy.newID() // 插入移动新建的分配没有ID
+				}
+				seen[a.ID] = true
+			}
+			schedCtx.SetAssignments(append(append([]*model.Assignment{}, fixed...), candidate...))
+			finishSearch(s.greedy, schedCtx, result, newAnnealState(schedCtx, candidate))
+			outcome.FinalScore = after.score
+			outcome.Applied = true
+			if before.score > 0 {
+				outcome.Improvement = (before.score - after.score) / before.score * 100
+			}
+		}
+	}
+
+	result.Statistics.Optimizer = &optStats
+	result.Statistics.Optimization = outcome
+	result.Statistics.Iterations += optStats.Iterations
+	result.Statistics.Timings.OptimizationMs = msSince(phase)
+	s.progress.report(Progress{
+		Phase:       PhaseOptimization,
+		Fraction:    1,
+		Round:       optStats.Iterations,
+		Assignments: len(result.Assignments),
+		Score:       result.Statistics.FillRate,
+	})
+	result.Duration = time.Since(startTime)
+	result.Statistics.Timings.TotalMs = msSince(startTime)
+	return result, nil
+}
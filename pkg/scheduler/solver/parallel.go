@@ -0,0 +1,88 @@
+package solver
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// candidateVerdict 并行评估中单个候选人的结果
+type candidateVerdict struct {
+	assignment *model.Assignment
+	tier       int                   // 0 内部员工，1 高疲劳内部员工，2 外部人员，3 高疲劳外部人员
+	blocking   constraint.Constraint // 拦截分配的硬约束，通过时为 nil
+	skipped    bool                  // 被排除的员工
+}
+
+// pickParallel 由 workers 个协程并行评估全部候选人的疲劳指数和硬约束，
+// 在通过检查的分配中按 (分组, 候选顺序) 选择排序最前的一个：分组依次为内部员工、高疲劳内部员工、
+// 外部人员、高疲劳外部人员，与逐个评估的 pick 选中同一名员工。
+// 候选顺序由工作量、志愿排名和随机种子决定，选择不依赖协程调度，设置种子时可复现。
+// 评估中的 panic 在调用方协程中重新抛出
+func (s *GreedySolver) pickParallel(ctx *constraint.Context, candidates []*model.Employee, exclude uuid.UUID, create func(*model.Employee) *model.Assignment, onCheck func(*model.Assignment, constraint.Constraint)) *model.Assignment {
+	verdicts := make([]candidateVerdict, len(candidates))
+	for i, emp := range candidates {
+		verdicts[i].skipped = emp.ID == exclude
+		if !verdicts[i].skipped {
+			verdicts[i].assignment = create(emp)
+		}
+	}
+
+	fc := s.constraintManager.GetConstraint(constraint.TypeFatigue)
+	var (
+		next     atomic.Int64
+		wg       sync.WaitGroup
+		once     sync.Once
+		panicked interface{}
+	)
+	for w := 0; w < min(s.workers, len(candidates)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if p := recover(); p != nil {
+					once.Do(func() { panicked = p })
+				}
+			}()
+			for i := int(next.Add(1)) - 1; i < len(verdicts); i = int(next.Add(1)) - 1 {
+				v := &verdicts[i]
+				if v.skipped {
+					continue
+				}
+				if candidates[i].IsExternal() {
+					v.tier = 2
+				}
+				if fc != nil {
+					if _, penalty := fc.EvaluateAssignment(ctx, v.assignment); penalty > 0 {
+						v.tier++
+					}
+				}
+				v.blocking = s.constraintManager.BlockingConstraint(ctx, v.assignment)
+			}
+		}()
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+
+	best := -1
+	for i, v := range verdicts {
+		if v.skipped {
+			continue
+		}
+		if onCheck != nil {
+			onCheck(v.assignment, v.blocking)
+		}
+		if v.blocking == nil && (best < 0 || v.tier < verdicts[best].tier) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return verdicts[best].assignment
+}
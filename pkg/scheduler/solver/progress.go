@@ -0,0 +1,62 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// 求解阶段
+const (
+	PhaseGreedy       = "greedy"       // 贪心分配
+	PhaseAnnealing    = "annealing"    // 模拟退火搜索
+	PhaseOptimization = "optimization" // 局部搜索/遗传算法优化
+)
+
+// Progress 求解进度
+type Progress struct {
+	Phase       string  `json:"phase"`
+	Fraction    float64 `json:"fraction"`    // 整体完成比例（0-1）
+	Round       int     `json:"round"`       // 贪心阶段为分配轮次，退火阶段为迭代次数
+	Assignments int     `json:"assignments"` // 当前方案的分配数
+	Score       float64 `json:"score"`       // 当前方案得分：满足最少人数的需求占比（0-100）
+}
+
+// ProgressHook 求解进度回调，在求解协程中同步调用，应尽快返回
+type ProgressHook func(Progress)
+
+type progressKey struct{}
+
+// WithProgress 返回携带进度回调的上下文（用于异步生成作业），
+// 由创建求解器的一方通过 ProgressFromContext 取出并设置到求解器上
+func WithProgress(ctx context.Context, hook ProgressHook) context.Context {
+	return context.WithValue(ctx, progressKey{}, hook)
+}
+
+// ProgressFromContext 返回上下文携带的进度回调，未设置时返回 nil
+func ProgressFromContext(ctx context.Context) ProgressHook {
+	hook, _ := ctx.Value(progressKey{}).(ProgressHook)
+	return hook
+}
+
+// report 调用进度回调，未设置回调时忽略
+func (hook ProgressHook) report(p Progress) {
+	if hook != nil {
+		hook(p)
+	}
+}
+
+// fillRate 满足最少人数的需求占比（0-100）
+func fillRate(requirements []*model.ShiftRequirement, assigned map[uuid.UUID]int) float64 {
+	if len(requirements) == 0 {
+		return 100
+	}
+	filled := 0
+	for _, req := range requirements {
+		if assigned[req.ID] >= req.MinEmployees {
+			filled++
+		}
+	}
+	return float64(filled) / float64(len(requirements)) * 100
+}
@@ -0,0 +1,140 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// 评分目标
+const (
+	ObjectiveCoverage   = "coverage"   // 需求覆盖
+	ObjectiveFairness   = "fairness"   // 公平
+	ObjectiveCost       = "cost"       // 成本
+	ObjectivePreference = "preference" // 偏好
+	ObjectiveContinuity = "continuity" // 连续性
+)
+
+// ScoringProfile 多目标评分权重
+// 优化阶段的目标函数为 硬约束惩罚 + Σ 权重 × 目标子得分，各权重为 1 时与未配置评分方案相同，权重为 0 表示不考虑该目标
+type ScoringProfile struct {
+	Coverage   float64 `json:"coverage"`
+	Fairness   float64 `json:"fairness"`
+	Cost       float64 `json:"cost"`
+	Preference float64 `json:"preference"`
+	Continuity float64 `json:"continuity"`
+}
+
+// DefaultScoringProfile 返回默认评分方案（各目标权重均为 1）
+func DefaultScoringProfile() ScoringProfile {
+	return ScoringProfile{Coverage: 1, Fairness: 1, Cost: 1, Preference: 1, Continuity: 1}
+}
+
+// NewScoringProfile 按目标名称设置权重，未给出的目标使用默认权重 1
+func NewScoringProfile(weights map[string]float64) (ScoringProfile, error) {
+	p := DefaultScoringProfile()
+	for name, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return p, fmt.Errorf("评分权重 %s 须为非负数", name)
+		}
+		switch name {
+		case ObjectiveCoverage:
+			p.Coverage = w
+		case ObjectiveFairness:
+			p.Fairness = w
+		case ObjectiveCost:
+			p.Cost = w
+		case ObjectivePreference:
+			p.Preference = w
+		case ObjectiveContinuity:
+			p.Continuity = w
+		default:
+			return p, fmt.Errorf("未知的评分目标: %s（支持 coverage/fairness/cost/preference/continuity）", name)
+		}
+	}
+	return p, nil
+}
+
+// ObjectiveScores 各目标子得分，即归入该目标的惩罚值之和，越小越好
+type ObjectiveScores struct {
+	Coverage   float64 `json:"coverage"`   // 需求缺口（低于最少人数每人 1100，低于目标人数每人 100）和高峰时段覆盖
+	Fairness   float64 `json:"fairness"`   // 工作量均衡、班次分配公平、节假日轮换和疲劳
+	Cost       float64 `json:"cost"`       // 加班、人工成本与工时预算、通勤
+	Preference float64 `json:"preference"` // 员工和客户偏好
+	Continuity float64 `json:"continuity"` // 周间稳定、服务连续和班组
+	Other      float64 `json:"other"`      // 未归入上述目标的软约束惩罚（如自定义约束），权重固定为 1
+}
+
+// ScoringResult 生效的评分方案及最终方案的各目标子得分
+type ScoringResult struct {
+	Profile   ScoringProfile  `json:"profile"`
+	SubScores ObjectiveScores `json:"sub_scores"`
+	Weighted  float64         `json:"weighted"` // Σ 权重 × 子得分
+}
+
+// objectiveOf 软约束类型所属的评分目标
+var objectiveOf = map[constraint.Type]string{
+	constraint.TypePeakHoursCoverage:      ObjectiveCoverage,
+	constraint.TypeWorkloadBalance:        ObjectiveFairness,
+	"workload_fairness":                   ObjectiveFairness,
+	"seniority_balance":                   ObjectiveFairness,
+	"shift_distribution":                  ObjectiveFairness,
+	constraint.TypeHolidayHandling:        ObjectiveFairness,
+	constraint.TypeFatigue:                ObjectiveFairness,
+	constraint.TypeMinimizeOvertime:       ObjectiveCost,
+	constraint.TypeMaxLaborCost:           ObjectiveCost,
+	constraint.TypeStoreHoursBudget:       ObjectiveCost,
+	constraint.TypeMinimizeTravelDistance: ObjectiveCost,
+	constraint.TypeTravelTimeBuffer:       ObjectiveCost,
+	constraint.TypeEmployeePreference:     ObjectivePreference,
+	constraint.TypeCustomerPreference:     ObjectivePreference,
+	"preference_match":                    ObjectivePreference,
+	"split_shift":                         ObjectivePreference,
+	constraint.TypeScheduleStability:      ObjectiveContinuity,
+	constraint.TypeServiceContinuity:      ObjectiveContinuity,
+	constraint.TypeCaregiverContinuity:    ObjectiveContinuity,
+	constraint.TypeTeamTogether:           ObjectiveContinuity,
+}
+
+// add 将软约束惩罚计入所属目标
+func (o *ObjectiveScores) add(t constraint.Type, penalty float64) {
+	switch objectiveOf[t] {
+	case ObjectiveCoverage:
+		o.Coverage += penalty
+	case ObjectiveFairness:
+		o.Fairness += penalty
+	case ObjectiveCost:
+		o.Cost += penalty
+	case ObjectivePreference:
+		o.Preference += penalty
+	case ObjectiveContinuity:
+		o.Continuity += penalty
+	default:
+		o.Other += penalty
+	}
+}
+
+// weighted 返回子得分的加权和
+func (p ScoringProfile) weighted(o ObjectiveScores) float64 {
+	return p.Coverage*o.Coverage + p.Fairness*o.Fairness + p.Cost*o.Cost +
+		p.Preference*o.Preference + p.Continuity*o.Continuity + o.Other
+}
+
+// score 按约束评估结果和需求分配人数计算各目标子得分
+func (p ScoringProfile) score(res *constraint.Result, reqs []*model.ShiftRequirement, assigned map[uuid.UUID]int) *ScoringResult {
+	var o ObjectiveScores
+	gap := 0
+	for _, req := range reqs {
+		gap += shortfall(req, assigned[req.ID])
+	}
+	o.Coverage = shortfallPenalty * float64(gap)
+	if res != nil {
+		for t, penalty := range res.SoftPenalties {
+			o.add(t, float64(penalty))
+		}
+	}
+	return &ScoringResult{Profile: p, SubScores: o, Weighted: p.weighted(o)}
+}
@@ -0,0 +1,295 @@
+// Package stats 提供排班统计分析功能
+package stats
+
+import (
+	"fmt"
+	"sort"
+)
+
+// 异常类型
+const (
+	AnomalyHoursOutlier      = "hours_outlier"       // 个人工时远超中位数
+	AnomalyCoverageDrop      = "coverage_drop"       // 覆盖率较历史明显下降
+	AnomalyNightConcentrated = "night_concentration" // 夜班集中在少数人
+)
+
+// Anomaly 排班异常
+type Anomaly struct {
+	Type         string  `json:"type"`
+	Severity     string  `json:"severity"` // warning/critical
+	EmployeeID   string  `json:"employee_id,omitempty"`
+	EmployeeName string  `json:"employee_name,omitempty"`
+	StoreID      string  `json:"store_id,omitempty"`
+	Value        float64 `json:"value"`     // 实际值
+	Baseline     float64 `json:"baseline"`  // 对比基准
+	Threshold    float64 `json:"threshold"` // 触发阈值
+	Message      string  `json:"message"`
+}
+
+// ScheduleSample 用于异常检测的排班样本
+type ScheduleSample struct {
+	ScheduleID  string            `json:"schedule_id"`
+	StartDate   string            `json:"start_date"`
+	FillRate    float64           `json:"fill_rate"` // 需求满足率 (%)
+	Assignments []*AssignmentInfo `json:"assignments"`
+
+	// 各门店的需求满足率 (%)，需求未指定门店时为空
+	StoreFillRates map[string]float64 `json:"store_fill_rates,omitempty"`
+}
+
+// AnomalyDetector 排班异常检测器
+// 将新生成的排班与组织历史排班对比，识别统计意义上的异常结果
+type AnomalyDetector struct {
+	HoursRatio     float64 // 个人工时超过中位数的倍数阈值
+	CoverageDrop   float64 // 覆盖率相对历史均值的下降比例阈值
+	NightShare     float64 // 单人夜班占比阈值
+	MinNightShifts int     // 参与夜班集中度判断的最少夜班数
+
+	nightShiftStart int
+	nightShiftEnd   int
+}
+
+// NewAnomalyDetector 创建异常检测器
+func NewAnomalyDetector() *AnomalyDetector {
+	return &AnomalyDetector{
+		HoursRatio:      3.0,
+		CoverageDrop:    0.30,
+		NightShare:      0.5,
+		MinNightShifts:  4,
+		nightShiftStart: 22,
+		nightShiftEnd:   6,
+	}
+}
+
+// Detect 检测异常
+func (d *AnomalyDetector) Detect(current *ScheduleSample, history []*ScheduleSample) []Anomaly {
+	if current == nil {
+		return nil
+	}
+
+	anomalies := make([]Anomaly, 0)
+	anomalies = append(anomalies, d.detectHoursOutliers(current, history)...)
+	anomalies = append(anomalies, d.detectCoverageDrop(current, history)...)
+	anomalies = append(anomalies, d.detectNightConcentration(current)...)
+	return anomalies
+}
+
+// detectHoursOutliers 检测工时离群的员工
+// 基准为当前及历史排班中每人每期工时的中位数
+func (d *AnomalyDetector) detectHoursOutliers(current *ScheduleSample, history []*ScheduleSample) []Anomaly {
+	hours, names := employeeHours(current.Assignments)
+	if len(hours) == 0 {
+		return nil
+	}
+
+	samples := make([]float64, 0, len(hours))
+	for _, h := range hours {
+		samples = append(samples, h)
+	}
+	for _, s := range history {
+		if s == nil {
+			continue
+		}
+		past, _ := employeeHours(s.Assignments)
+		for _, h := range past {
+			samples = append(samples, h)
+		}
+	}
+
+	median := calculateMedian(samples)
+	if median <= 0 {
+		return nil
+	}
+
+	ids := sortedKeys(hours)
+	var result []Anomaly
+	for _, id := range ids {
+		ratio := hours[id] / median
+		if ratio < d.HoursRatio {
+			continue
+		}
+		severity := "warning"
+		if ratio >= d.HoursRatio*1.5 {
+			severity = "critical"
+		}
+		result = append(result, Anomaly{
+			Type:         AnomalyHoursOutlier,
+			Severity:     severity,
+			EmployeeID:   id,
+			EmployeeName: names[id],
+			Value:        hours[id],
+			Baseline:     median,
+			Threshold:    d.HoursRatio,
+			Message:      fmt.Sprintf("%s 工时 %.1f 小时，为中位数 %.1f 小时的 %.1f 倍", displayName(id, names[id]), hours[id], median, ratio),
+		})
+	}
+	return result
+}
+
+// detectCoverageDrop 检测覆盖率相对历史均值的下降
+// 多门店排班按门店分别对比，避免单个门店的下降被其他门店的总量掩盖
+func (d *AnomalyDetector) detectCoverageDrop(current *ScheduleSample, history []*ScheduleSample) []Anomaly {
+	if len(current.StoreFillRates) == 0 {
+		return d.coverageDrop("", current.FillRate, history, func(s *ScheduleSample) (float64, bool) {
+			return s.FillRate, true
+		})
+	}
+
+	stores := sortedKeys(current.StoreFillRates)
+	var result []Anomaly
+	for _, storeID := range stores {
+		result = append(result, d.coverageDrop(storeID, current.StoreFillRates[storeID], history, func(s *ScheduleSample) (float64, bool) {
+			rate, ok := s.StoreFillRates[storeID]
+			return rate, ok
+		})...)
+	}
+	return result
+}
+
+// coverageDrop 将覆盖率与历史样本中同一口径的均值对比，rateOf 返回样本的覆盖率及样本是否包含该口径
+func (d *AnomalyDetector) coverageDrop(storeID string, value float64, history []*ScheduleSample, rateOf func(*ScheduleSample) (float64, bool)) []Anomaly {
+	var total float64
+	var count int
+	for _, s := range history {
+		if s == nil {
+			continue
+		}
+		rate, ok := rateOf(s)
+		if !ok {
+			continue
+		}
+		total += rate
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+
+	baseline := total / float64(count)
+	if baseline <= 0 {
+		return nil
+	}
+
+	drop := (baseline - value) / baseline
+	if drop < d.CoverageDrop {
+		return nil
+	}
+
+	severity := "warning"
+	if drop >= d.CoverageDrop*2 {
+		severity = "critical"
+	}
+	message := fmt.Sprintf("覆盖率 %.1f%% 较历史均值 %.1f%% 下降 %.0f%%", value, baseline, drop*100)
+	if storeID != "" {
+		message = fmt.Sprintf("门店 %s ", storeID) + message
+	}
+	return []Anomaly{{
+		Type:      AnomalyCoverageDrop,
+		Severity:  severity,
+		StoreID:   storeID,
+		Value:     value,
+		Baseline:  baseline,
+		Threshold: d.CoverageDrop,
+		Message:   message,
+	}}
+}
+
+// detectNightConcentration 检测夜班过度集中在某一员工
+func (d *AnomalyDetector) detectNightConcentration(current *ScheduleSample) []Anomaly {
+	nights := make(map[string]int)
+	names := make(map[string]string)
+	total := 0
+	for _, a := range current.Assignments {
+		if !d.isNightShift(a) {
+			continue
+		}
+		nights[a.EmployeeID]++
+		names[a.EmployeeID] = a.EmployeeName
+		total++
+	}
+	// 只有一名员工上夜班时无从比较
+	if total < d.MinNightShifts || len(nights) < 2 {
+		return nil
+	}
+
+	var result []Anomaly
+	for _, id := range sortedIntKeys(nights) {
+		share := float64(nights[id]) / float64(total)
+		if share <= d.NightShare {
+			continue
+		}
+		result = append(result, Anomaly{
+			Type:         AnomalyNightConcentrated,
+			Severity:     "warning",
+			EmployeeID:   id,
+			EmployeeName: names[id],
+			Value:        share,
+			Baseline:     1 / float64(len(nights)),
+			Threshold:    d.NightShare,
+			Message:      fmt.Sprintf("%s 承担了 %d/%d 个夜班（%.0f%%）", displayName(id, names[id]), nights[id], total, share*100),
+		})
+	}
+	return result
+}
+
+// isNightShift 判断是否是夜班（与公平性分析口径一致）
+func (d *AnomalyDetector) isNightShift(a *AssignmentInfo) bool {
+	return a.StartTime.Hour() >= d.nightShiftStart || a.EndTime.Hour() <= d.nightShiftEnd
+}
+
+// employeeHours 统计每名员工的工时
+func employeeHours(assignments []*AssignmentInfo) (map[string]float64, map[string]string) {
+	hours := make(map[string]float64)
+	names := make(map[string]string)
+	for _, a := range assignments {
+		if a == nil {
+			continue
+		}
+		hours[a.EmployeeID] += a.EndTime.Sub(a.StartTime).Hours()
+		if a.EmployeeName != "" {
+			names[a.EmployeeID] = a.EmployeeName
+		}
+	}
+	return hours, names
+}
+
+// calculateMedian 计算中位数
+func calculateMedian(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedIntKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func displayName(id, name string) string {
+	if name != "" {
+		return name
+	}
+	return id
+}
@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func shiftAt(empID, date string, startHour, hours int) *AssignmentInfo {
+	start := time.Date(2026, 1, 5, startHour, 0, 0, 0, time.Local)
+	return &AssignmentInfo{
+		EmployeeID:   empID,
+		EmployeeName: empID,
+		Date:         date,
+		StartTime:    start,
+		EndTime:      start.Add(time.Duration(hours) * time.Hour),
+	}
+}
+
+func countType(anomalies []Anomaly, typ string) int {
+	n := 0
+	for _, a := range anomalies {
+		if a.Type == typ {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAnomalyDetector_HoursOutlier(t *testing.T) {
+	detector := NewAnomalyDetector()
+
+	current := &ScheduleSample{FillRate: 100}
+	for i := 0; i < 6; i++ {
+		current.Assignments = append(current.Assignments, shiftAt("emp1", "2026-01-05", 8, 8))
+	}
+	for _, id := range []string{"emp2", "emp3", "emp4"} {
+		current.Assignments = append(current.Assignments, shiftAt(id, "2026-01-05", 8, 8))
+	}
+
+	anomalies := detector.Detect(current, nil)
+	if countType(anomalies, AnomalyHoursOutlier) != 1 {
+		t.Fatalf("expected 1 hours outlier, got %+v", anomalies)
+	}
+	if anomalies[0].EmployeeID != "emp1" {
+		t.Errorf("expected emp1 flagged, got %s", anomalies[0].EmployeeID)
+	}
+}
+
+func TestAnomalyDetector_CoverageDrop(t *testing.T) {
+	detector := NewAnomalyDetector()
+	history := []*ScheduleSample{{FillRate: 95}, {FillRate: 100}}
+
+	if n := countType(detector.Detect(&ScheduleSample{FillRate: 90}, history), AnomalyCoverageDrop); n != 0 {
+		t.Errorf("轻微下降不应告警, got %d", n)
+	}
+	if n := countType(detector.Detect(&ScheduleSample{FillRate: 50}, history), AnomalyCoverageDrop); n != 1 {
+		t.Errorf("覆盖率下降超过30%%应告警, got %d", n)
+	}
+	if n := countType(detector.Detect(&ScheduleSample{FillRate: 50}, nil), AnomalyCoverageDrop); n != 0 {
+		t.Errorf("无历史时不应告警, got %d", n)
+	}
+}
+
+func TestAnomalyDetector_CoverageDropByStore(t *testing.T) {
+	detector := NewAnomalyDetector()
+	history := []*ScheduleSample{
+		{FillRate: 90, StoreFillRates: map[string]float64{"store-a": 100, "store-b": 80}},
+		{FillRate: 90, StoreFillRates: map[string]float64{"store-a": 100, "store-b": 80}},
+	}
+
+	// 总覆盖率不变，门店 A 下降 50%
+	current := &ScheduleSample{FillRate: 90, StoreFillRates: map[string]float64{"store-a": 50, "store-b": 100, "store-c": 10}}
+	anomalies := detector.Detect(current, history)
+	if countType(anomalies, AnomalyCoverageDrop) != 1 {
+		t.Fatalf("expected 1 coverage drop, got %+v", anomalies)
+	}
+	if a := anomalies[0]; a.StoreID != "store-a" || a.Value != 50 || a.Baseline != 100 {
+		t.Errorf("unexpected anomaly %+v", a)
+	}
+}
+
+func TestAnomalyDetector_NightConcentration(t *testing.T) {
+	detector := NewAnomalyDetector()
+
+	current := &ScheduleSample{FillRate: 100}
+	for i := 0; i < 4; i++ {
+		current.Assignments = append(current.Assignments, shiftAt("emp1", "2026-01-05", 22, 8))
+	}
+	current.Assignments = append(current.Assignments, shiftAt("emp2", "2026-01-05", 22, 8))
+
+	anomalies := detector.Detect(current, nil)
+	if countType(anomalies, AnomalyNightConcentrated) != 1 {
+		t.Fatalf("expected night concentration anomaly, got %+v", anomalies)
+	}
+}
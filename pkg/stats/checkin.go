@@ -0,0 +1,288 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// GeoPoint 经纬度坐标
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// ScheduledVisit 计划工作（派单订单或门店排班），用于打卡核验
+type ScheduledVisit struct {
+	ID           string    `json:"id"` // 订单或排班ID
+	EmployeeID   string    `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	Date         string    `json:"date"`
+	StartTime    time.Time `json:"start_time"`
+	Location     *GeoPoint `json:"location,omitempty"` // 计划地点（客户或门店），为空时不做位置核验
+}
+
+// ClockIn 考勤打卡记录
+type ClockIn struct {
+	VisitID    string    `json:"visit_id,omitempty"` // 关联的订单/排班ID，为空时按员工和日期匹配
+	EmployeeID string    `json:"employee_id"`
+	Time       time.Time `json:"time"`
+	Location   *GeoPoint `json:"location,omitempty"`
+}
+
+// 打卡核验结果
+const (
+	CheckInVerified         = "verified"          // 在计划地点范围内打卡
+	CheckInLocationMismatch = "location_mismatch" // 打卡位置超出范围
+	CheckInNoLocation       = "no_location"       // 打卡未上报位置
+	CheckInMissing          = "missing"           // 未打卡
+	CheckInUnverifiable     = "unverifiable"      // 计划无地点，无法核验
+)
+
+// CheckInVariance 单条计划工作的打卡差异
+type CheckInVariance struct {
+	VisitID        string     `json:"visit_id"`
+	EmployeeID     string     `json:"employee_id"`
+	EmployeeName   string     `json:"employee_name"`
+	Date           string     `json:"date"`
+	ScheduledStart time.Time  `json:"scheduled_start"`
+	ClockInTime    *time.Time `json:"clock_in_time,omitempty"`
+	LateMinutes    *int       `json:"late_minutes,omitempty"`    // 负数表示提前
+	DistanceMeters *float64   `json:"distance_meters,omitempty"` // 打卡位置与计划地点的距离
+	Status         string     `json:"status"`                    // verified/location_mismatch/no_location/missing/unverifiable
+	Late           bool       `json:"late"`                      // 迟到超过容忍时间
+}
+
+// EmployeeCheckInCompliance 员工打卡合规情况
+type EmployeeCheckInCompliance struct {
+	EmployeeID        string   `json:"employee_id"`
+	EmployeeName      string   `json:"employee_name"`
+	Visits            int      `json:"visits"`
+	CheckedIn         int      `json:"checked_in"`
+	Verified          int      `json:"verified"`
+	LocationMismatch  int      `json:"location_mismatch"`
+	NoLocation        int      `json:"no_location"`
+	Missing           int      `json:"missing"`
+	Late              int      `json:"late"`
+	GeoComplianceRate *float64 `json:"geo_compliance_rate,omitempty"` // 在范围内打卡占可核验计划的百分比
+}
+
+// CheckInReport 打卡差异报告
+type CheckInReport struct {
+	RadiusMeters         float64                     `json:"radius_meters"`
+	LateToleranceMinutes int                         `json:"late_tolerance_minutes"`
+	TotalVisits          int                         `json:"total_visits"`
+	Verified             int                         `json:"verified"`
+	LocationMismatch     int                         `json:"location_mismatch"`
+	NoLocation           int                         `json:"no_location"`
+	Missing              int                         `json:"missing"`
+	Late                 int                         `json:"late"`
+	GeoComplianceRate    float64                     `json:"geo_compliance_rate"`
+	Variances            []CheckInVariance           `json:"variances"` // 仅列出有差异的记录（位置不符、无位置、未打卡、迟到）
+	Employees            []EmployeeCheckInCompliance `json:"employees"` // 按合规率升序
+}
+
+// CheckInAnalyzer 打卡位置核验分析器
+type CheckInAnalyzer struct {
+	radiusMeters         float64 // 允许的打卡半径（米）
+	lateToleranceMinutes int     // 迟到容忍时间（分钟）
+}
+
+// NewCheckInAnalyzer 创建打卡位置核验分析器
+func NewCheckInAnalyzer(radiusMeters float64, lateToleranceMinutes int) *CheckInAnalyzer {
+	if radiusMeters <= 0 {
+		radiusMeters = 200
+	}
+	if lateToleranceMinutes < 0 {
+		lateToleranceMinutes = 0
+	}
+	return &CheckInAnalyzer{radiusMeters: radiusMeters, lateToleranceMinutes: lateToleranceMinutes}
+}
+
+// Analyze 将打卡记录与计划工作逐条比对
+// 打卡优先按 visit_id 关联；未关联的打卡按员工和日期匹配该员工当天最早的未匹配计划。
+// 合规率 = 在范围内打卡数 / 有计划地点的工作数（未打卡、无位置和超出范围均视为不合规）
+func (a *CheckInAnalyzer) Analyze(visits []*ScheduledVisit, clockIns []*ClockIn) *CheckInReport {
+	report := &CheckInReport{
+		RadiusMeters:         a.radiusMeters,
+		LateToleranceMinutes: a.lateToleranceMinutes,
+		TotalVisits:          len(visits),
+		Variances:            make([]CheckInVariance, 0),
+		Employees:            make([]EmployeeCheckInCompliance, 0),
+	}
+
+	sorted := make([]*ScheduledVisit, len(visits))
+	copy(sorted, visits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartTime.Before(sorted[j].StartTime)
+	})
+	matched := a.match(sorted, clockIns)
+
+	byEmployee := make(map[string]*EmployeeCheckInCompliance)
+	order := make([]string, 0)
+	verifiable, verified := 0, 0
+	for _, v := range sorted {
+		item := a.compare(v, matched[v])
+
+		emp, ok := byEmployee[v.EmployeeID]
+		if !ok {
+			emp = &EmployeeCheckInCompliance{EmployeeID: v.EmployeeID, EmployeeName: v.EmployeeName}
+			byEmployee[v.EmployeeID] = emp
+			order = append(order, v.EmployeeID)
+		}
+		emp.Visits++
+		if item.ClockInTime != nil {
+			emp.CheckedIn++
+		}
+		if item.Late {
+			emp.Late++
+			report.Late++
+		}
+		switch item.Status {
+		case CheckInVerified:
+			emp.Verified++
+			report.Verified++
+		case CheckInLocationMismatch:
+			emp.LocationMismatch++
+			report.LocationMismatch++
+		case CheckInNoLocation:
+			emp.NoLocation++
+			report.NoLocation++
+		case CheckInMissing:
+			emp.Missing++
+			report.Missing++
+		}
+		if v.Location != nil {
+			verifiable++
+			if item.Status == CheckInVerified {
+				verified++
+			}
+		}
+
+		if item.Status != CheckInVerified && item.Status != CheckInUnverifiable || item.Late {
+			report.Variances = append(report.Variances, item)
+		}
+	}
+
+	report.GeoComplianceRate = percent(verified, verifiable)
+	for _, id := range order {
+		emp := byEmployee[id]
+		if total := emp.Visits - a.unverifiable(sorted, id); total > 0 {
+			rate := percent(emp.Verified, total)
+			emp.GeoComplianceRate = &rate
+		}
+		report.Employees = append(report.Employees, *emp)
+	}
+
+	// 合规率低的排前面，无法核验的员工排最后
+	sort.SliceStable(report.Employees, func(i, j int) bool {
+		ri, rj := report.Employees[i].GeoComplianceRate, report.Employees[j].GeoComplianceRate
+		if ri == nil || rj == nil {
+			return ri != nil
+		}
+		return *ri < *rj
+	})
+	return report
+}
+
+// match 为每条计划匹配打卡记录
+func (a *CheckInAnalyzer) match(visits []*ScheduledVisit, clockIns []*ClockIn) map[*ScheduledVisit]*ClockIn {
+	matched := make(map[*ScheduledVisit]*ClockIn)
+	byID := make(map[string]*ScheduledVisit)
+	for _, v := range visits {
+		byID[v.EmployeeID+"|"+v.ID] = v
+	}
+
+	var unlinked []*ClockIn
+	for _, c := range clockIns {
+		if v, ok := byID[c.EmployeeID+"|"+c.VisitID]; ok && c.VisitID != "" {
+			// 同一计划多次打卡取最早的一次
+			if prev, exists := matched[v]; !exists || c.Time.Before(prev.Time) {
+				matched[v] = c
+			}
+			continue
+		}
+		unlinked = append(unlinked, c)
+	}
+
+	sort.SliceStable(unlinked, func(i, j int) bool {
+		return unlinked[i].Time.Before(unlinked[j].Time)
+	})
+	for _, c := range unlinked {
+		date := c.Time.Format("2006-01-02")
+		for _, v := range visits {
+			if _, taken := matched[v]; taken || v.EmployeeID != c.EmployeeID || v.Date != date {
+				continue
+			}
+			matched[v] = c
+			break
+		}
+	}
+	return matched
+}
+
+// compare 比对单条计划与打卡记录
+func (a *CheckInAnalyzer) compare(v *ScheduledVisit, c *ClockIn) CheckInVariance {
+	item := CheckInVariance{
+		VisitID:        v.ID,
+		EmployeeID:     v.EmployeeID,
+		EmployeeName:   v.EmployeeName,
+		Date:           v.Date,
+		ScheduledStart: v.StartTime,
+	}
+	if c == nil {
+		item.Status = CheckInMissing
+		if v.Location == nil {
+			item.Status = CheckInUnverifiable
+		}
+		return item
+	}
+
+	t := c.Time
+	item.ClockInTime = &t
+	if !v.StartTime.IsZero() {
+		late := int(math.Round(c.Time.Sub(v.StartTime).Minutes()))
+		item.LateMinutes = &late
+		item.Late = late > a.lateToleranceMinutes
+	}
+
+	switch {
+	case v.Location == nil:
+		item.Status = CheckInUnverifiable
+	case c.Location == nil:
+		item.Status = CheckInNoLocation
+	default:
+		distance := math.Round(haversineMeters(*v.Location, *c.Location))
+		item.DistanceMeters = &distance
+		item.Status = CheckInVerified
+		if distance > a.radiusMeters {
+			item.Status = CheckInLocationMismatch
+		}
+	}
+	return item
+}
+
+// unverifiable 统计员工无计划地点的工作数
+func (a *CheckInAnalyzer) unverifiable(visits []*ScheduledVisit, employeeID string) int {
+	n := 0
+	for _, v := range visits {
+		if v.EmployeeID == employeeID && v.Location == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// haversineMeters 计算两点间距离（米）
+func haversineMeters(p1, p2 GeoPoint) float64 {
+	const earthRadius = 6371000.0
+
+	lat1 := p1.Latitude * math.Pi / 180
+	lat2 := p2.Latitude * math.Pi / 180
+	dLat := (p2.Latitude - p1.Latitude) * math.Pi / 180
+	dLon := (p2.Longitude - p1.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadius * 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckInAnalyzer_Analyze(t *testing.T) {
+	site := &GeoPoint{Latitude: 31.2304, Longitude: 121.4737}
+	near := &GeoPoint{Latitude: 31.2310, Longitude: 121.4740} // 约 70 米
+	far := &GeoPoint{Latitude: 31.2500, Longitude: 121.4737}  // 约 2.2 公里
+	at := func(h, m int) time.Time { return time.Date(2026, 1, 19, h, m, 0, 0, time.UTC) }
+
+	visits := []*ScheduledVisit{
+		{ID: "o1", EmployeeID: "a", EmployeeName: "张三", Date: "2026-01-19", StartTime: at(9, 0), Location: site},
+		{ID: "o2", EmployeeID: "a", EmployeeName: "张三", Date: "2026-01-19", StartTime: at(14, 0), Location: site},
+		{ID: "o3", EmployeeID: "b", EmployeeName: "李四", Date: "2026-01-19", StartTime: at(9, 0), Location: site},
+		{ID: "o4", EmployeeID: "b", EmployeeName: "李四", Date: "2026-01-19", StartTime: at(14, 0), Location: site},
+		{ID: "s1", EmployeeID: "c", EmployeeName: "王五", Date: "2026-01-19", StartTime: at(9, 0)},
+	}
+	clockIns := []*ClockIn{
+		{VisitID: "o1", EmployeeID: "a", Time: at(8, 55), Location: near},
+		{EmployeeID: "a", Time: at(14, 20), Location: near}, // 未关联，按日期匹配到 o2，迟到 20 分钟
+		{VisitID: "o3", EmployeeID: "b", Time: at(9, 2), Location: far},
+		{EmployeeID: "c", Time: at(9, 0)},
+	}
+
+	report := NewCheckInAnalyzer(200, 5).Analyze(visits, clockIns)
+
+	if report.Verified != 2 || report.LocationMismatch != 1 || report.Missing != 1 || report.Late != 1 {
+		t.Fatalf("totals = %+v", report)
+	}
+	if report.GeoComplianceRate != 50 {
+		t.Errorf("geo compliance = %.2f, expected 50", report.GeoComplianceRate)
+	}
+	// o2（迟到）、o3（位置不符）、o4（未打卡）
+	if len(report.Variances) != 3 {
+		t.Fatalf("expected 3 variances, got %+v", report.Variances)
+	}
+	for _, v := range report.Variances {
+		if v.VisitID == "o3" && (v.Status != CheckInLocationMismatch || v.DistanceMeters == nil || *v.DistanceMeters < 2000) {
+			t.Errorf("o3 should be a location mismatch, got %+v", v)
+		}
+	}
+
+	// 李四合规率最低排第一，王五无计划地点无法核验排最后
+	emps := report.Employees
+	if len(emps) != 3 || emps[0].EmployeeID != "b" || *emps[0].GeoComplianceRate != 0 {
+		t.Fatalf("unexpected employee order: %+v", emps)
+	}
+	if *emps[1].GeoComplianceRate != 100 || emps[1].Late != 1 {
+		t.Errorf("张三 should be fully compliant with one late check-in, got %+v", emps[1])
+	}
+	if emps[2].GeoComplianceRate != nil || emps[2].CheckedIn != 1 {
+		t.Errorf("王五 should be unverifiable, got %+v", emps[2])
+	}
+}
+
+func TestCheckInAnalyzer_NoLocation(t *testing.T) {
+	site := &GeoPoint{Latitude: 31.2304, Longitude: 121.4737}
+	start := time.Date(2026, 1, 19, 9, 0, 0, 0, time.UTC)
+	visits := []*ScheduledVisit{{ID: "o1", EmployeeID: "a", Date: "2026-01-19", StartTime: start, Location: site}}
+	clockIns := []*ClockIn{{VisitID: "o1", EmployeeID: "a", Time: start}}
+
+	report := NewCheckInAnalyzer(0, 0).Analyze(visits, clockIns)
+	if report.RadiusMeters != 200 || report.NoLocation != 1 || report.GeoComplianceRate != 0 {
+		t.Errorf("未上报位置的打卡应计为不合规, got %+v", report)
+	}
+}
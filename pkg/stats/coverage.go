@@ -2,6 +2,7 @@
 package stats
 
 import (
+	"sort"
 	"time"
 )
 
@@ -81,11 +82,30 @@ type AssignmentInfo struct {
 	Date         string    `json:"date"`
 	StartTime    time.Time `json:"start_time"`
 	EndTime      time.Time `json:"end_time"`
+
+	OnCall bool `json:"on_call,omitempty"` // 待命分配：公平性分析单独统计，不计入工时和班次分布
+}
+
+// HourSlot 某日的一个整点小时
+type HourSlot struct {
+	Date string
+	Hour int
+}
+
+// OverlappedHours 返回 [start, end) 覆盖到的整点小时，部分重叠的小时同样计入，跨零点的部分计入次日
+func OverlappedHours(start, end time.Time) []HourSlot {
+	var slots []HourSlot
+	for t := start.Truncate(time.Hour); t.Before(end); t = t.Add(time.Hour) {
+		slots = append(slots, HourSlot{Date: t.Format("2006-01-02"), Hour: t.Hour()})
+	}
+	return slots
 }
 
 // CoverageAnalyzer 覆盖率分析器
 type CoverageAnalyzer struct {
 	minStaffPerHour map[int]int // 各时段最低人力需求
+
+	minStaffByDate map[string]map[int]int // 每日各小时最少在岗人数（日期 -> 小时 -> 人数）
 }
 
 // NewCoverageAnalyzer 创建覆盖率分析器
@@ -100,8 +120,53 @@ func (c *CoverageAnalyzer) SetMinStaffRequirements(requirements map[int]int) {
 	c.minStaffPerHour = requirements
 }
 
+// SetDailyMinStaffRequirements 设置每日各小时最少在岗人数（日期 -> 小时 -> 人数）
+// 在岗人数按分配的实际起止时间统计，与时段重叠的分配都计入
+func (c *CoverageAnalyzer) SetDailyMinStaffRequirements(requirements map[string]map[int]int) {
+	c.minStaffByDate = requirements
+}
+
 // Analyze 分析覆盖率
 func (c *CoverageAnalyzer) Analyze(shifts []*ShiftInfo, assignments []*AssignmentInfo) *CoverageMetrics {
+	acc := c.NewAccumulator()
+	for _, a := range assignments {
+		acc.Add(a)
+	}
+	return acc.Result(shifts)
+}
+
+// CoverageAccumulator 覆盖率增量统计
+// 逐条累加分配，只记录已分配的班次ID，内存占用与班次数而非分配数成正比（用于流式请求）
+type CoverageAccumulator struct {
+	analyzer  *CoverageAnalyzer
+	assigned  map[string]bool
+	headcount map[HourSlot]int // 每小时在岗人数
+}
+
+// NewAccumulator 创建覆盖率增量统计
+func (c *CoverageAnalyzer) NewAccumulator() *CoverageAccumulator {
+	return &CoverageAccumulator{
+		analyzer:  c,
+		assigned:  make(map[string]bool),
+		headcount: make(map[HourSlot]int),
+	}
+}
+
+// Add 累加一条分配
+func (acc *CoverageAccumulator) Add(a *AssignmentInfo) {
+	acc.assigned[a.ShiftID] = true
+	for _, slot := range OverlappedHours(a.StartTime, a.EndTime) {
+		acc.headcount[slot]++
+	}
+}
+
+// Result 计算覆盖率指标
+func (acc *CoverageAccumulator) Result(shifts []*ShiftInfo) *CoverageMetrics {
+	return acc.analyzer.analyze(shifts, acc.assigned, acc.headcount)
+}
+
+// analyze 根据已分配班次集合和每小时在岗人数分析覆盖率
+func (c *CoverageAnalyzer) analyze(shifts []*ShiftInfo, assignmentMap map[string]bool, headcount map[HourSlot]int) *CoverageMetrics {
 	if len(shifts) == 0 {
 		return &CoverageMetrics{
 			DailyCoverage:     make(map[string]DayCoverage),
@@ -109,15 +174,10 @@ func (c *CoverageAnalyzer) Analyze(shifts []*ShiftInfo, assignments []*Assignmen
 			SkillCoverage:     make(map[string]float64),
 			HourlyCoverage:    make(map[int]float64),
 			OverallCoverage:   100,
+			Understaffed:      c.identifyHourlyUnderstaffed(headcount),
 		}
 	}
 
-	// 构建分配映射
-	assignmentMap := make(map[string]*AssignmentInfo)
-	for _, a := range assignments {
-		assignmentMap[a.ShiftID] = a
-	}
-
 	// 统计整体覆盖
 	totalShifts := len(shifts)
 	assignedShifts := 0
@@ -140,7 +200,7 @@ func (c *CoverageAnalyzer) Analyze(shifts []*ShiftInfo, assignments []*Assignmen
 
 	for _, shift := range shifts {
 		// 检查是否已分配
-		_, isAssigned := assignmentMap[shift.ID]
+		isAssigned := assignmentMap[shift.ID]
 		if isAssigned {
 			assignedShifts++
 		} else {
@@ -239,7 +299,7 @@ func (c *CoverageAnalyzer) Analyze(shifts []*ShiftInfo, assignments []*Assignmen
 	}
 
 	// 识别人手不足时段
-	understaffed := c.identifyUnderstaffed(shifts, assignments)
+	understaffed := append(c.identifyUnderstaffed(shifts, assignmentMap), c.identifyHourlyUnderstaffed(headcount)...)
 
 	// 计算需求满足度
 	demandSatisfaction := c.calculateDemandSatisfaction(hourlyRequired, hourlyAssigned)
@@ -259,15 +319,9 @@ func (c *CoverageAnalyzer) Analyze(shifts []*ShiftInfo, assignments []*Assignmen
 }
 
 // identifyUnderstaffed 识别人手不足时段
-func (c *CoverageAnalyzer) identifyUnderstaffed(shifts []*ShiftInfo, assignments []*AssignmentInfo) []UnderstaffedPeriod {
+func (c *CoverageAnalyzer) identifyUnderstaffed(shifts []*ShiftInfo, assignmentMap map[string]bool) []UnderstaffedPeriod {
 	var understaffed []UnderstaffedPeriod
 
-	// 构建分配映射
-	assignmentMapLocal := make(map[string]*AssignmentInfo)
-	for _, a := range assignments {
-		assignmentMapLocal[a.ShiftID] = a
-	}
-
 	// 按日期-小时统计
 	type hourKey struct {
 		date string
@@ -277,7 +331,7 @@ func (c *CoverageAnalyzer) identifyUnderstaffed(shifts []*ShiftInfo, assignments
 	hourlyRequiredLocal := make(map[hourKey]int)
 
 	for _, shift := range shifts {
-		_, isAssigned := assignmentMapLocal[shift.ID]
+		isAssigned := assignmentMap[shift.ID]
 
 		startHour := shift.StartTime.Hour()
 		endHour := shift.EndTime.Hour()
@@ -327,6 +381,35 @@ func (c *CoverageAnalyzer) identifyUnderstaffed(shifts []*ShiftInfo, assignments
 	return understaffed
 }
 
+// identifyHourlyUnderstaffed 按每日各小时最少在岗人数识别人手不足时段，按日期和小时排序
+func (c *CoverageAnalyzer) identifyHourlyUnderstaffed(headcount map[HourSlot]int) []UnderstaffedPeriod {
+	dates := make([]string, 0, len(c.minStaffByDate))
+	for date := range c.minStaffByDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	var understaffed []UnderstaffedPeriod
+	for _, date := range dates {
+		minimums := c.minStaffByDate[date]
+		for hour := 0; hour < 24; hour++ {
+			required := minimums[hour]
+			assigned := headcount[HourSlot{Date: date, Hour: hour}]
+			if required > 0 && assigned < required {
+				understaffed = append(understaffed, UnderstaffedPeriod{
+					Date:      date,
+					StartHour: hour,
+					EndHour:   (hour + 1) % 24,
+					Required:  required,
+					Assigned:  assigned,
+					Shortage:  required - assigned,
+				})
+			}
+		}
+	}
+	return understaffed
+}
+
 // calculateDemandSatisfaction 计算需求满足度
 func (c *CoverageAnalyzer) calculateDemandSatisfaction(required, assigned map[int]int) float64 {
 	totalRequired := 0
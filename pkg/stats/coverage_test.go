@@ -136,3 +136,31 @@ func TestCoverageAnalyzer_DailyCoverage(t *testing.T) {
 		t.Errorf("Expected 2 daily coverage entries, got %d", len(metrics.DailyCoverage))
 	}
 }
+
+func TestCoverageAnalyzer_DailyMinStaff(t *testing.T) {
+	analyzer := NewCoverageAnalyzer()
+	analyzer.SetDailyMinStaffRequirements(map[string]map[int]int{
+		"2026-01-11": {10: 2, 11: 2, 12: 2},
+	})
+
+	at := func(hour, minute int) time.Time { return time.Date(2026, 1, 11, hour, minute, 0, 0, time.UTC) }
+	assignments := []*AssignmentInfo{
+		{ShiftID: "s1", EmployeeID: "emp1", Date: "2026-01-11", StartTime: at(9, 0), EndTime: at(17, 0)},
+		{ShiftID: "s2", EmployeeID: "emp2", Date: "2026-01-11", StartTime: at(11, 30), EndTime: at(20, 0)},
+	}
+
+	// 没有班次时同样按分配时间识别人手不足时段，部分重叠的小时计入在岗人数
+	metrics := analyzer.Analyze(nil, assignments)
+	if len(metrics.Understaffed) != 1 {
+		t.Fatalf("Expected 1 understaffed period, got %+v", metrics.Understaffed)
+	}
+	if p := metrics.Understaffed[0]; p.StartHour != 10 || p.Required != 2 || p.Assigned != 1 || p.Shortage != 1 {
+		t.Errorf("Unexpected understaffed period: %+v", p)
+	}
+
+	// 跨零点的分配计入次日
+	slots := OverlappedHours(at(23, 0), at(23, 0).Add(2*time.Hour))
+	if len(slots) != 2 || slots[1] != (HourSlot{Date: "2026-01-12", Hour: 0}) {
+		t.Errorf("OverlappedHours() = %+v", slots)
+	}
+}
@@ -32,6 +32,9 @@ type FairnessMetrics struct {
 	// 员工级别统计
 	EmployeeStats []EmployeeStat `json:"employee_stats"` // 员工统计
 
+	// 待命分配（单独统计），没有待命分配时为空
+	OnCall *OnCallDistribution `json:"on_call,omitempty"`
+
 	// 综合评分
 	OverallFairnessScore float64 `json:"overall_fairness_score"` // 综合公平性评分 (0-100)
 }
@@ -48,6 +51,22 @@ type EmployeeStat struct {
 	Deviation     float64 `json:"deviation"` // 与平均值的偏差百分比
 }
 
+// OnCallDistribution 待命分配分布
+type OnCallDistribution struct {
+	Total      int          `json:"total"`       // 待命总次数
+	Gini       float64      `json:"gini"`        // 待命次数基尼系数（按全部员工计算，未待命的员工计 0 次）
+	MaxPerEmp  int          `json:"max_per_emp"` // 单人最多待命次数
+	MinPerEmp  int          `json:"min_per_emp"` // 单人最少待命次数
+	ByEmployee []OnCallStat `json:"by_employee"` // 按待命次数降序
+}
+
+// OnCallStat 员工待命次数
+type OnCallStat struct {
+	EmployeeID   string `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	Count        int    `json:"count"`
+}
+
 // FairnessAnalyzer 公平性分析器
 type FairnessAnalyzer struct {
 	standardWeeklyHours float64 // 标准周工时
@@ -66,21 +85,99 @@ func NewFairnessAnalyzer() *FairnessAnalyzer {
 
 // Analyze 分析排班公平性
 func (f *FairnessAnalyzer) Analyze(assignments []*AssignmentInfo, employees []*EmployeeInfo) *FairnessMetrics {
-	if len(assignments) == 0 || len(employees) == 0 {
+	acc := f.NewAccumulator(employees)
+	for _, a := range assignments {
+		acc.Add(a)
+	}
+	return acc.Result()
+}
+
+// FairnessAccumulator 公平性增量统计
+// 逐条累加分配，只保留员工级汇总，内存占用与员工数而非分配数成正比（用于流式请求）
+type FairnessAccumulator struct {
+	analyzer    *FairnessAnalyzer
+	employeeMap map[string]*EmployeeInfo
+	statMap     map[string]*EmployeeStat
+	typeCounts  map[string]int
+	total       int
+
+	onCall map[string]int // 员工ID -> 待命次数
+}
+
+// NewAccumulator 创建公平性增量统计
+func (f *FairnessAnalyzer) NewAccumulator(employees []*EmployeeInfo) *FairnessAccumulator {
+	employeeMap := make(map[string]*EmployeeInfo, len(employees))
+	for _, e := range employees {
+		employeeMap[e.ID] = e
+	}
+	return &FairnessAccumulator{
+		analyzer:    f,
+		employeeMap: employeeMap,
+		statMap:     make(map[string]*EmployeeStat),
+		typeCounts:  make(map[string]int),
+		onCall:      make(map[string]int),
+	}
+}
+
+// Add 累加一条分配
+func (acc *FairnessAccumulator) Add(a *AssignmentInfo) {
+	if a.OnCall {
+		acc.onCall[a.EmployeeID]++
+		return
+	}
+	f := acc.analyzer
+	stat, exists := acc.statMap[a.EmployeeID]
+	if !exists {
+		name := a.EmployeeID
+		if e, ok := acc.employeeMap[a.EmployeeID]; ok {
+			name = e.Name
+		}
+		stat = &EmployeeStat{
+			EmployeeID:   a.EmployeeID,
+			EmployeeName: name,
+		}
+		acc.statMap[a.EmployeeID] = stat
+	}
+
+	// 计算工时
+	hours := f.calculateShiftHours(a.StartTime, a.EndTime)
+	stat.TotalHours += hours
+	stat.ShiftCount++
+
+	// 检查是否是夜班
+	if f.isNightShift(a.StartTime, a.EndTime) {
+		stat.NightShifts++
+	}
+
+	// 检查是否是周末
+	if f.isWeekend(a.Date) {
+		stat.WeekendShifts++
+	}
+
+	// 班次类型分布
+	acc.typeCounts[f.classifyShiftType(a.StartTime, a.EndTime)]++
+	acc.total++
+}
+
+// Result 计算公平性指标
+func (acc *FairnessAccumulator) Result() *FairnessMetrics {
+	f := acc.analyzer
+	if acc.total == 0 || len(acc.employeeMap) == 0 {
 		return &FairnessMetrics{
 			ShiftTypeDistribution: make(map[string]float64),
 			OverallFairnessScore:  100,
+			OnCall:                acc.onCallDistribution(),
 		}
 	}
 
-	// 构建员工ID映射
-	employeeMap := make(map[string]*EmployeeInfo)
-	for _, e := range employees {
-		employeeMap[e.ID] = e
+	// 员工统计按工时排序
+	employeeStats := make([]EmployeeStat, 0, len(acc.statMap))
+	for _, stat := range acc.statMap {
+		employeeStats = append(employeeStats, *stat)
 	}
-
-	// 统计每个员工的数据
-	employeeStats := f.calculateEmployeeStats(assignments, employeeMap)
+	sort.Slice(employeeStats, func(i, j int) bool {
+		return employeeStats[i].TotalHours > employeeStats[j].TotalHours
+	})
 
 	// 计算工时列表
 	hours := make([]float64, len(employeeStats))
@@ -112,7 +209,10 @@ func (f *FairnessAnalyzer) Analyze(assignments []*AssignmentInfo, employees []*E
 	weekendGini := f.calculateGini(weekendShifts)
 
 	// 计算班次类型分布
-	shiftTypeDist := f.calculateShiftTypeDistribution(assignments)
+	shiftTypeDist := make(map[string]float64)
+	for shiftType, count := range acc.typeCounts {
+		shiftTypeDist[shiftType] = float64(count) / float64(acc.total) * 100
+	}
 
 	// 计算综合评分
 	overallScore := f.calculateOverallScore(workloadGini, nightGini, weekendGini, stdDev, avgHours)
@@ -130,55 +230,41 @@ func (f *FairnessAnalyzer) Analyze(assignments []*AssignmentInfo, employees []*E
 		WeekendShiftGini:      weekendGini,
 		EmployeeStats:         employeeStats,
 		OverallFairnessScore:  overallScore,
+		OnCall:                acc.onCallDistribution(),
 	}
 }
 
-// calculateEmployeeStats 计算员工统计数据
-func (f *FairnessAnalyzer) calculateEmployeeStats(assignments []*AssignmentInfo, employeeMap map[string]*EmployeeInfo) []EmployeeStat {
-	statMap := make(map[string]*EmployeeStat)
-
-	for _, a := range assignments {
-		stat, exists := statMap[a.EmployeeID]
-		if !exists {
-			name := a.EmployeeID
-			if e, ok := employeeMap[a.EmployeeID]; ok {
-				name = e.Name
-			}
-			stat = &EmployeeStat{
-				EmployeeID:   a.EmployeeID,
-				EmployeeName: name,
-			}
-			statMap[a.EmployeeID] = stat
-		}
-
-		// 计算工时
-		hours := f.calculateShiftHours(a.StartTime, a.EndTime)
-		stat.TotalHours += hours
-		stat.ShiftCount++
-
-		// 检查是否是夜班
-		if f.isNightShift(a.StartTime, a.EndTime) {
-			stat.NightShifts++
-		}
-
-		// 检查是否是周末
-		if f.isWeekend(a.Date) {
-			stat.WeekendShifts++
-		}
+// onCallDistribution 计算待命分配分布，没有待命分配时返回 nil
+// 员工列表中未待命的员工计 0 次，不在员工列表中的待命员工同样计入
+func (acc *FairnessAccumulator) onCallDistribution() *OnCallDistribution {
+	if len(acc.onCall) == 0 {
+		return nil
 	}
-
-	// 转换为切片
-	result := make([]EmployeeStat, 0, len(statMap))
-	for _, stat := range statMap {
-		result = append(result, *stat)
+	dist := &OnCallDistribution{}
+	for id, e := range acc.employeeMap {
+		dist.ByEmployee = append(dist.ByEmployee, OnCallStat{EmployeeID: id, EmployeeName: e.Name, Count: acc.onCall[id]})
 	}
-
-	// 按工时排序
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].TotalHours > result[j].TotalHours
+	for id, n := range acc.onCall {
+		if _, ok := acc.employeeMap[id]; !ok {
+			dist.ByEmployee = append(dist.ByEmployee, OnCallStat{EmployeeID: id, EmployeeName: id, Count: n})
+		}
+	}
+	sort.Slice(dist.ByEmployee, func(i, j int) bool {
+		if dist.ByEmployee[i].Count != dist.ByEmployee[j].Count {
+			return dist.ByEmployee[i].Count > dist.ByEmployee[j].Count
+		}
+		return dist.ByEmployee[i].EmployeeID < dist.ByEmployee[j].EmployeeID
 	})
 
-	return result
+	counts := make([]float64, len(dist.ByEmployee))
+	for i, s := range dist.ByEmployee {
+		counts[i] = float64(s.Count)
+		dist.Total += s.Count
+	}
+	dist.Gini = acc.analyzer.calculateGini(counts)
+	dist.MaxPerEmp = dist.ByEmployee[0].Count
+	dist.MinPerEmp = dist.ByEmployee[len(dist.ByEmployee)-1].Count
+	return dist
 }
 
 // calculateShiftHours 计算班次工时
@@ -281,26 +367,6 @@ func (f *FairnessAnalyzer) calculateGini(values []float64) float64 {
 	return math.Max(0, math.Min(1, gini))
 }
 
-// calculateShiftTypeDistribution 计算班次类型分布
-func (f *FairnessAnalyzer) calculateShiftTypeDistribution(assignments []*AssignmentInfo) map[string]float64 {
-	typeCounts := make(map[string]int)
-	total := len(assignments)
-
-	for _, a := range assignments {
-		shiftType := f.classifyShiftType(a.StartTime, a.EndTime)
-		typeCounts[shiftType]++
-	}
-
-	distribution := make(map[string]float64)
-	if total > 0 {
-		for shiftType, count := range typeCounts {
-			distribution[shiftType] = float64(count) / float64(total) * 100
-		}
-	}
-
-	return distribution
-}
-
 // classifyShiftType 分类班次类型
 func (f *FairnessAnalyzer) classifyShiftType(start, end time.Time) string {
 	startHour := start.Hour()
@@ -106,3 +106,71 @@ func TestFairnessAnalyzer_OverallScore(t *testing.T) {
 		t.Errorf("Score should be 0-100, got %f", metrics.OverallFairnessScore)
 	}
 }
+
+func TestFairnessAccumulator_Incremental(t *testing.T) {
+	analyzer := NewFairnessAnalyzer()
+	employees := []*EmployeeInfo{
+		{ID: "emp1", Name: "员工1"},
+		{ID: "emp2", Name: "员工2"},
+	}
+
+	acc := analyzer.NewAccumulator(employees)
+	start := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 1000; i++ {
+		empID := "emp1"
+		if i%4 == 0 {
+			empID = "emp2"
+		}
+		day := start.AddDate(0, 0, i%7)
+		acc.Add(&AssignmentInfo{
+			EmployeeID: empID,
+			Date:       day.Format("2006-01-02"),
+			StartTime:  day,
+			EndTime:    day.Add(8 * time.Hour),
+		})
+	}
+
+	metrics := acc.Result()
+	if len(metrics.EmployeeStats) != 2 {
+		t.Fatalf("Expected 2 employee stats, got %d", len(metrics.EmployeeStats))
+	}
+	if metrics.EmployeeStats[0].EmployeeName != "员工1" || metrics.EmployeeStats[0].ShiftCount != 750 {
+		t.Errorf("Expected 员工1 with 750 shifts first, got %+v", metrics.EmployeeStats[0])
+	}
+	if metrics.ShiftTypeDistribution["morning"] != 100 {
+		t.Errorf("Expected all morning shifts, got %v", metrics.ShiftTypeDistribution)
+	}
+}
+
+func TestFairnessAccumulator_OnCall(t *testing.T) {
+	employees := []*EmployeeInfo{
+		{ID: "emp1", Name: "员工1"},
+		{ID: "emp2", Name: "员工2"},
+		{ID: "emp3", Name: "员工3"},
+	}
+	acc := NewFairnessAnalyzer().NewAccumulator(employees)
+	day := time.Date(2026, 1, 5, 8, 0, 0, 0, time.UTC)
+	acc.Add(&AssignmentInfo{EmployeeID: "emp1", Date: "2026-01-05", StartTime: day, EndTime: day.Add(8 * time.Hour)})
+	for i, empID := range []string{"emp2", "emp2", "emp1"} {
+		start := day.AddDate(0, 0, i).Add(10 * time.Hour)
+		acc.Add(&AssignmentInfo{EmployeeID: empID, Date: start.Format("2006-01-02"), StartTime: start, EndTime: start.Add(12 * time.Hour), OnCall: true})
+	}
+
+	metrics := acc.Result()
+	if len(metrics.EmployeeStats) != 1 || metrics.EmployeeStats[0].TotalHours != 8 {
+		t.Errorf("待命不应计入工时统计: %+v", metrics.EmployeeStats)
+	}
+	if metrics.ShiftTypeDistribution["morning"] != 100 {
+		t.Errorf("待命不应计入班次类型分布: %v", metrics.ShiftTypeDistribution)
+	}
+	oc := metrics.OnCall
+	if oc == nil || oc.Total != 3 || len(oc.ByEmployee) != 3 || oc.MaxPerEmp != 2 || oc.MinPerEmp != 0 {
+		t.Fatalf("待命分布 = %+v", oc)
+	}
+	if oc.ByEmployee[0].EmployeeName != "员工2" || oc.ByEmployee[2].EmployeeID != "emp3" || oc.Gini <= 0 {
+		t.Errorf("待命分布应按次数降序，未待命员工计 0 次: %+v", oc)
+	}
+	if NewFairnessAnalyzer().Analyze(nil, employees).OnCall != nil {
+		t.Error("没有待命分配时 on_call 应为空")
+	}
+}
@@ -0,0 +1,229 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// PreferenceProfile 员工偏好（用于偏好满足度分析）
+type PreferenceProfile struct {
+	EmployeeID       string         `json:"employee_id"`
+	EmployeeName     string         `json:"employee_name"`
+	PreferredShifts  []string       `json:"preferred_shifts,omitempty"`   // 偏好班次（编码或类型）
+	AvoidShifts      []string       `json:"avoid_shifts,omitempty"`       // 避免班次（编码或类型）
+	PreferredDays    []time.Weekday `json:"preferred_days,omitempty"`     // 偏好工作日
+	AvoidDays        []time.Weekday `json:"avoid_days,omitempty"`         // 避免工作日
+	DaysOffRequested []string       `json:"days_off_requested,omitempty"` // 申请休息的日期
+}
+
+// PreferenceAssignment 分配信息（用于偏好满足度分析）
+type PreferenceAssignment struct {
+	EmployeeID string `json:"employee_id"`
+	Date       string `json:"date"`
+	ShiftCode  string `json:"shift_code,omitempty"`
+	ShiftType  string `json:"shift_type,omitempty"`
+}
+
+// EmployeePreferenceStat 员工偏好满足情况
+// 比率为百分比，员工未设置对应偏好时为空
+type EmployeePreferenceStat struct {
+	EmployeeID            string   `json:"employee_id"`
+	EmployeeName          string   `json:"employee_name"`
+	Shifts                int      `json:"shifts"`
+	PreferredShiftMatches int      `json:"preferred_shift_matches"`
+	PreferredShiftRate    *float64 `json:"preferred_shift_rate,omitempty"` // 偏好班次占比
+	AvoidShiftViolations  int      `json:"avoid_shift_violations"`         // 被安排到避免班次的次数
+	PreferredDayMatches   int      `json:"preferred_day_matches"`
+	PreferredDayRate      *float64 `json:"preferred_day_rate,omitempty"` // 偏好工作日占比
+	AvoidDayViolations    int      `json:"avoid_day_violations"`         // 被安排到避免工作日的次数
+	DaysOffRequested      int      `json:"days_off_requested"`
+	DaysOffGranted        int      `json:"days_off_granted"`
+	DaysOffGrantRate      *float64 `json:"days_off_grant_rate,omitempty"` // 休息申请满足率
+	SatisfactionScore     *float64 `json:"satisfaction_score,omitempty"`  // 综合满足度 (0-100)
+}
+
+// PreferenceReport 偏好满足度报告
+type PreferenceReport struct {
+	StartDate            string                   `json:"start_date"`
+	EndDate              string                   `json:"end_date"`
+	EmployeesWithPrefs   int                      `json:"employees_with_preferences"`
+	PreferredShiftRate   float64                  `json:"preferred_shift_rate"` // 有偏好班次员工的整体偏好班次占比
+	AvoidShiftViolations int                      `json:"avoid_shift_violations"`
+	AvoidDayViolations   int                      `json:"avoid_day_violations"`
+	DaysOffRequested     int                      `json:"days_off_requested"`
+	DaysOffGranted       int                      `json:"days_off_granted"`
+	DaysOffGrantRate     float64                  `json:"days_off_grant_rate"`
+	AvgSatisfaction      float64                  `json:"avg_satisfaction"`
+	MinSatisfaction      float64                  `json:"min_satisfaction"`
+	SatisfactionGini     float64                  `json:"satisfaction_gini"` // 满足度基尼系数，越高表示偏好处理越不均衡
+	Employees            []EmployeePreferenceStat `json:"employees"`         // 按满足度升序
+}
+
+// PreferenceAnalyzer 偏好满足度分析器
+type PreferenceAnalyzer struct{}
+
+// NewPreferenceAnalyzer 创建偏好满足度分析器
+func NewPreferenceAnalyzer() *PreferenceAnalyzer {
+	return &PreferenceAnalyzer{}
+}
+
+// Analyze 统计各员工偏好的满足情况
+// 综合满足度为已设置偏好各项得分的平均值：偏好班次/工作日占比、避免班次/工作日的遵守率、休息申请满足率
+func (a *PreferenceAnalyzer) Analyze(startDate, endDate string, profiles []*PreferenceProfile, assignments []*PreferenceAssignment) *PreferenceReport {
+	report := &PreferenceReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Employees: make([]EmployeePreferenceStat, 0, len(profiles)),
+	}
+
+	byEmployee := make(map[string][]*PreferenceAssignment)
+	for _, as := range assignments {
+		byEmployee[as.EmployeeID] = append(byEmployee[as.EmployeeID], as)
+	}
+
+	preferredShifts, preferredMatches := 0, 0
+	scores := make([]float64, 0, len(profiles))
+	for _, p := range profiles {
+		stat := a.analyzeEmployee(p, byEmployee[p.EmployeeID])
+		report.Employees = append(report.Employees, stat)
+
+		if len(p.PreferredShifts) > 0 {
+			preferredShifts += stat.Shifts
+			preferredMatches += stat.PreferredShiftMatches
+		}
+		report.AvoidShiftViolations += stat.AvoidShiftViolations
+		report.AvoidDayViolations += stat.AvoidDayViolations
+		report.DaysOffRequested += stat.DaysOffRequested
+		report.DaysOffGranted += stat.DaysOffGranted
+		if stat.SatisfactionScore != nil {
+			scores = append(scores, *stat.SatisfactionScore)
+		}
+	}
+
+	report.EmployeesWithPrefs = len(scores)
+	report.PreferredShiftRate = percent(preferredMatches, preferredShifts)
+	report.DaysOffGrantRate = percent(report.DaysOffGranted, report.DaysOffRequested)
+	if len(scores) > 0 {
+		fa := NewFairnessAnalyzer()
+		report.AvgSatisfaction = round2(fa.calculateMean(scores))
+		_, report.MinSatisfaction = fa.calculateRange(scores)
+		report.SatisfactionGini = round2(fa.calculateGini(scores))
+	}
+
+	// 满足度低的排前面，未设置偏好的员工排最后
+	sort.SliceStable(report.Employees, func(i, j int) bool {
+		si, sj := report.Employees[i].SatisfactionScore, report.Employees[j].SatisfactionScore
+		if si == nil || sj == nil {
+			return si != nil
+		}
+		return *si < *sj
+	})
+	return report
+}
+
+// analyzeEmployee 统计单个员工的偏好满足情况
+func (a *PreferenceAnalyzer) analyzeEmployee(p *PreferenceProfile, assignments []*PreferenceAssignment) EmployeePreferenceStat {
+	stat := EmployeePreferenceStat{
+		EmployeeID:   p.EmployeeID,
+		EmployeeName: p.EmployeeName,
+		Shifts:       len(assignments),
+	}
+
+	workDays := make(map[string]bool)
+	for _, as := range assignments {
+		workDays[as.Date] = true
+		if matchShift(p.PreferredShifts, as) {
+			stat.PreferredShiftMatches++
+		}
+		if matchShift(p.AvoidShifts, as) {
+			stat.AvoidShiftViolations++
+		}
+		if d, err := time.Parse("2006-01-02", as.Date); err == nil {
+			if containsWeekday(p.PreferredDays, d.Weekday()) {
+				stat.PreferredDayMatches++
+			}
+			if containsWeekday(p.AvoidDays, d.Weekday()) {
+				stat.AvoidDayViolations++
+			}
+		}
+	}
+
+	requested := make(map[string]bool)
+	for _, date := range p.DaysOffRequested {
+		if requested[date] {
+			continue
+		}
+		requested[date] = true
+		stat.DaysOffRequested++
+		if !workDays[date] {
+			stat.DaysOffGranted++
+		}
+	}
+
+	// 各项得分 (0-100)，仅计入员工设置了的偏好
+	var components []float64
+	if len(p.PreferredShifts) > 0 && stat.Shifts > 0 {
+		rate := percent(stat.PreferredShiftMatches, stat.Shifts)
+		stat.PreferredShiftRate = &rate
+		components = append(components, rate)
+	}
+	if len(p.AvoidShifts) > 0 && stat.Shifts > 0 {
+		components = append(components, 100-percent(stat.AvoidShiftViolations, stat.Shifts))
+	}
+	if len(p.PreferredDays) > 0 && stat.Shifts > 0 {
+		rate := percent(stat.PreferredDayMatches, stat.Shifts)
+		stat.PreferredDayRate = &rate
+		components = append(components, rate)
+	}
+	if len(p.AvoidDays) > 0 && stat.Shifts > 0 {
+		components = append(components, 100-percent(stat.AvoidDayViolations, stat.Shifts))
+	}
+	if stat.DaysOffRequested > 0 {
+		rate := percent(stat.DaysOffGranted, stat.DaysOffRequested)
+		stat.DaysOffGrantRate = &rate
+		components = append(components, rate)
+	}
+	if len(components) > 0 {
+		sum := 0.0
+		for _, c := range components {
+			sum += c
+		}
+		score := round2(sum / float64(len(components)))
+		stat.SatisfactionScore = &score
+	}
+	return stat
+}
+
+// matchShift 检查分配的班次编码或类型是否在列表中
+func matchShift(list []string, as *PreferenceAssignment) bool {
+	for _, s := range list {
+		if s != "" && (s == as.ShiftCode || s == as.ShiftType) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsWeekday 检查列表是否包含某个星期几
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// percent 计算百分比，分母为 0 时返回 0
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return round2(float64(n) / float64(total) * 100)
+}
+
+// round2 保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
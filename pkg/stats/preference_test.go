@@ -0,0 +1,54 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPreferenceAnalyzer_Analyze(t *testing.T) {
+	profiles := []*PreferenceProfile{
+		{
+			EmployeeID:       "a",
+			PreferredShifts:  []string{"morning"},
+			AvoidShifts:      []string{"N"},
+			DaysOffRequested: []string{"2026-01-21", "2026-01-22"},
+		},
+		{EmployeeID: "b", AvoidDays: []time.Weekday{time.Monday}},
+		{EmployeeID: "c"},
+	}
+	// 2026-01-19 为周一
+	assignments := []*PreferenceAssignment{
+		{EmployeeID: "a", Date: "2026-01-19", ShiftCode: "M", ShiftType: "morning"},
+		{EmployeeID: "a", Date: "2026-01-20", ShiftCode: "N", ShiftType: "night"},
+		{EmployeeID: "a", Date: "2026-01-21", ShiftCode: "M", ShiftType: "morning"},
+		{EmployeeID: "a", Date: "2026-01-23", ShiftCode: "M", ShiftType: "morning"},
+		{EmployeeID: "b", Date: "2026-01-20", ShiftCode: "M", ShiftType: "morning"},
+		{EmployeeID: "c", Date: "2026-01-19", ShiftCode: "M", ShiftType: "morning"},
+	}
+
+	report := NewPreferenceAnalyzer().Analyze("2026-01-19", "2026-01-25", profiles, assignments)
+
+	if len(report.Employees) != 3 || report.Employees[0].EmployeeID != "a" || report.Employees[2].EmployeeID != "c" {
+		t.Fatalf("应按满足度升序且无偏好员工排最后, got %+v", report.Employees)
+	}
+	a := report.Employees[0]
+	if a.PreferredShiftRate == nil || *a.PreferredShiftRate != 75 || a.AvoidShiftViolations != 1 {
+		t.Errorf("a 偏好班次 75%% 且违反避免班次 1 次, got %+v", a)
+	}
+	if a.DaysOffRequested != 2 || a.DaysOffGranted != 1 || *a.DaysOffGrantRate != 50 {
+		t.Errorf("a 休息申请 1/2 被满足, got %d/%d", a.DaysOffGranted, a.DaysOffRequested)
+	}
+	// (75 + 75 + 50) / 3
+	if a.SatisfactionScore == nil || *a.SatisfactionScore != 66.67 {
+		t.Errorf("a satisfaction = %v, expected 66.67", a.SatisfactionScore)
+	}
+	if b := report.Employees[1]; b.AvoidDayViolations != 0 || *b.SatisfactionScore != 100 {
+		t.Errorf("b 未被安排到周一, got %+v", b)
+	}
+	if report.Employees[2].SatisfactionScore != nil || report.EmployeesWithPrefs != 2 {
+		t.Error("未设置偏好的员工不计入满足度")
+	}
+	if report.MinSatisfaction != 66.67 || report.DaysOffGrantRate != 50 {
+		t.Errorf("min = %.2f, days off = %.2f", report.MinSatisfaction, report.DaysOffGrantRate)
+	}
+}
@@ -0,0 +1,161 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+)
+
+// 技能缺口维度
+const (
+	GapKindSkill    = "skill"
+	GapKindPosition = "position"
+)
+
+// DemandInfo 需求信息（用于技能缺口分析）
+type DemandInfo struct {
+	Date      string   `json:"date"`
+	Position  string   `json:"position,omitempty"`
+	Skills    []string `json:"skills,omitempty"` // 需具备的技能（技能组以组合键表示）
+	Hours     float64  `json:"hours"`            // 单人工时
+	Headcount int      `json:"headcount"`        // 需求人数
+}
+
+// SupplyInfo 供给信息（用于技能缺口分析）
+type SupplyInfo struct {
+	EmployeeID     string   `json:"employee_id"`
+	Position       string   `json:"position,omitempty"`
+	Skills         []string `json:"skills,omitempty"` // 具备的技能（含满足的技能组组合键）
+	AvailableHours float64  `json:"available_hours"`  // 周期内可用工时
+}
+
+// SkillGap 单个技能/岗位的供需对比
+type SkillGap struct {
+	Kind           string  `json:"kind"` // skill/position
+	Name           string  `json:"name"`
+	RequiredHours  float64 `json:"required_hours"`
+	AvailableHours float64 `json:"available_hours"`
+	Gap            float64 `json:"gap"`            // 需求 - 供给，正数表示缺口
+	CoverageRatio  float64 `json:"coverage_ratio"` // 供给 / 需求
+	Employees      int     `json:"employees"`      // 具备该技能/岗位的员工数
+	Structural     bool    `json:"structural"`     // 结构性缺口：全员全时投入也无法满足
+	Tight          bool    `json:"tight"`          // 供给余量不足
+}
+
+// SkillGapReport 技能供需缺口报告
+type SkillGapReport struct {
+	StartDate           string     `json:"start_date"`
+	EndDate             string     `json:"end_date"`
+	TotalRequiredHours  float64    `json:"total_required_hours"`
+	TotalAvailableHours float64    `json:"total_available_hours"`
+	Skills              []SkillGap `json:"skills"`
+	Positions           []SkillGap `json:"positions"`
+	StructuralShortages []string   `json:"structural_shortages"` // 结构性缺口说明
+}
+
+// SkillGapAnalyzer 技能缺口分析器
+// 员工的可用工时会同时计入其具备的每项技能，因此供给是各技能可投入工时的上限；
+// 供给低于需求时，无论求解器如何调优都无法排满，属于结构性缺口
+type SkillGapAnalyzer struct {
+	TightRatio float64 // 供给/需求低于该比例视为余量不足
+}
+
+// NewSkillGapAnalyzer 创建技能缺口分析器
+func NewSkillGapAnalyzer() *SkillGapAnalyzer {
+	return &SkillGapAnalyzer{TightRatio: 1.2}
+}
+
+// Analyze 分析技能和岗位的供需缺口
+func (a *SkillGapAnalyzer) Analyze(startDate, endDate string, demands []*DemandInfo, supplies []*SupplyInfo) *SkillGapReport {
+	report := &SkillGapReport{
+		StartDate:           startDate,
+		EndDate:             endDate,
+		Skills:              make([]SkillGap, 0),
+		Positions:           make([]SkillGap, 0),
+		StructuralShortages: make([]string, 0),
+	}
+
+	skillDemand := make(map[string]float64)
+	positionDemand := make(map[string]float64)
+	for _, d := range demands {
+		hours := d.Hours * float64(d.Headcount)
+		report.TotalRequiredHours += hours
+		for _, skill := range d.Skills {
+			skillDemand[skill] += hours
+		}
+		if d.Position != "" {
+			positionDemand[d.Position] += hours
+		}
+	}
+
+	skillSupply := make(map[string]float64)
+	skillEmployees := make(map[string]int)
+	positionSupply := make(map[string]float64)
+	positionEmployees := make(map[string]int)
+	for _, s := range supplies {
+		report.TotalAvailableHours += s.AvailableHours
+		for _, skill := range s.Skills {
+			skillSupply[skill] += s.AvailableHours
+			skillEmployees[skill]++
+		}
+		if s.Position != "" {
+			positionSupply[s.Position] += s.AvailableHours
+			positionEmployees[s.Position]++
+		}
+	}
+
+	for _, skill := range sortedKeys(skillDemand) {
+		report.Skills = append(report.Skills, a.gap(GapKindSkill, skill, skillDemand[skill], skillSupply[skill], skillEmployees[skill]))
+	}
+	for _, position := range sortedKeys(positionDemand) {
+		report.Positions = append(report.Positions, a.gap(GapKindPosition, position, positionDemand[position], positionSupply[position], positionEmployees[position]))
+	}
+
+	// 缺口大的排前面
+	byGap := func(gaps []SkillGap) {
+		sort.SliceStable(gaps, func(i, j int) bool { return gaps[i].Gap > gaps[j].Gap })
+	}
+	byGap(report.Skills)
+	byGap(report.Positions)
+
+	if report.TotalAvailableHours < report.TotalRequiredHours {
+		report.StructuralShortages = append(report.StructuralShortages,
+			fmt.Sprintf("总需求 %.1f 小时超过总可用 %.1f 小时", report.TotalRequiredHours, report.TotalAvailableHours))
+	}
+	for _, gaps := range [][]SkillGap{report.Skills, report.Positions} {
+		for _, g := range gaps {
+			if g.Structural {
+				report.StructuralShortages = append(report.StructuralShortages,
+					fmt.Sprintf("%s %s 需求 %.1f 小时，可用 %.1f 小时（%d 人），缺口 %.1f 小时",
+						kindLabel(g.Kind), g.Name, g.RequiredHours, g.AvailableHours, g.Employees, g.Gap))
+			}
+		}
+	}
+
+	return report
+}
+
+// gap 计算单项供需对比
+func (a *SkillGapAnalyzer) gap(kind, name string, required, available float64, employees int) SkillGap {
+	g := SkillGap{
+		Kind:           kind,
+		Name:           name,
+		RequiredHours:  required,
+		AvailableHours: available,
+		Gap:            required - available,
+		Employees:      employees,
+	}
+	if required > 0 {
+		g.CoverageRatio = available / required
+	}
+	g.Structural = available < required
+	g.Tight = !g.Structural && g.CoverageRatio < a.TightRatio
+	return g
+}
+
+// kindLabel 缺口维度的中文名称
+func kindLabel(kind string) string {
+	if kind == GapKindPosition {
+		return "岗位"
+	}
+	return "技能"
+}
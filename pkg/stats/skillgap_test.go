@@ -0,0 +1,41 @@
+package stats
+
+import "testing"
+
+func TestSkillGapAnalyzer_StructuralShortage(t *testing.T) {
+	demands := []*DemandInfo{
+		{Date: "2026-01-19", Position: "cook", Skills: []string{"grill"}, Hours: 8, Headcount: 2},
+		{Date: "2026-01-20", Position: "cook", Skills: []string{"grill"}, Hours: 8, Headcount: 2},
+		{Date: "2026-01-19", Position: "waiter", Hours: 8, Headcount: 1},
+	}
+	supplies := []*SupplyInfo{
+		{EmployeeID: "a", Position: "cook", Skills: []string{"grill"}, AvailableHours: 20},
+		{EmployeeID: "b", Position: "waiter", AvailableHours: 40},
+	}
+
+	report := NewSkillGapAnalyzer().Analyze("2026-01-19", "2026-01-20", demands, supplies)
+
+	if report.TotalRequiredHours != 40 || report.TotalAvailableHours != 60 {
+		t.Errorf("totals = %.0f/%.0f, expected 40/60", report.TotalRequiredHours, report.TotalAvailableHours)
+	}
+	if len(report.Skills) != 1 || !report.Skills[0].Structural || report.Skills[0].Gap != 12 {
+		t.Fatalf("grill 应为结构性缺口 12 小时, got %+v", report.Skills)
+	}
+	if len(report.Positions) != 2 || report.Positions[0].Name != "cook" || report.Positions[1].Structural {
+		t.Errorf("岗位应按缺口排序且 waiter 无缺口, got %+v", report.Positions)
+	}
+	// 总量充足，仅 grill 技能和 cook 岗位存在结构性缺口
+	if len(report.StructuralShortages) != 2 {
+		t.Errorf("expected 2 shortages, got %v", report.StructuralShortages)
+	}
+}
+
+func TestSkillGapAnalyzer_Tight(t *testing.T) {
+	demands := []*DemandInfo{{Skills: []string{"forklift"}, Hours: 10, Headcount: 1}}
+	supplies := []*SupplyInfo{{EmployeeID: "a", Skills: []string{"forklift"}, AvailableHours: 11}}
+
+	report := NewSkillGapAnalyzer().Analyze("", "", demands, supplies)
+	if g := report.Skills[0]; g.Structural || !g.Tight {
+		t.Errorf("供给略高于需求应为余量不足, got %+v", g)
+	}
+}
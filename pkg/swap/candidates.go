@@ -0,0 +1,121 @@
+package swap
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// DefaultCandidateLimit 默认返回的替班候选数
+const DefaultCandidateLimit = 5
+
+// Candidate 替班候选
+type Candidate struct {
+	Employee     *model.Employee `json:"-"`
+	Rank         int             `json:"rank"`
+	Score        float64         `json:"score"` // 接班后的约束满足度得分 (0-100)
+	Feasible     bool            `json:"feasible"`
+	CurrentHours float64         `json:"current_hours"` // 本期已排工时
+	HoursChange  float64         `json:"hours_change"`
+	Reasons      []string        `json:"reasons"` // 推荐原因，不可行时为冲突说明
+	Evaluation   *SwapEvaluation `json:"-"`
+}
+
+// CandidateOptions 替班候选选项
+type CandidateOptions struct {
+	Limit             int  // 最多返回的候选数，<=0 时使用 DefaultCandidateLimit
+	IncludeInfeasible bool // 是否列出不可行的员工（排在所有可行候选之后）
+}
+
+// FindCandidates 为要空出的分配查找替班候选
+// 逐个评估上下文中除原员工外的在职员工接班，可行的排在前面，同为可行时按得分降序、
+// 本期已排工时升序排列，返回前 Limit 个
+func (r *Recommender) FindCandidates(ctx *constraint.Context, assignment *model.Assignment, options *CandidateOptions) []Candidate {
+	if options == nil {
+		options = &CandidateOptions{}
+	}
+	limit := options.Limit
+	if limit <= 0 {
+		limit = DefaultCandidateLimit
+	}
+
+	candidates := make([]Candidate, 0)
+	for _, emp := range ctx.Employees {
+		if emp.ID == assignment.EmployeeID || !emp.IsActive() {
+			continue
+		}
+		evaluation := r.evaluator.EvaluateSwap(ctx, &SwapRequest{
+			SourceAssignment: assignment,
+			TargetEmployee:   emp,
+		})
+		if !evaluation.Feasible && !options.IncludeInfeasible {
+			continue
+		}
+		candidate := Candidate{
+			Employee:     emp,
+			Score:        evaluation.Score,
+			Feasible:     evaluation.Feasible,
+			CurrentHours: ctx.GetEmployeeHoursInRange(emp.ID, ctx.StartDate, ctx.EndDate),
+			Evaluation:   evaluation,
+		}
+		if evaluation.Impact != nil && evaluation.Impact.TargetEmployeeImpact != nil {
+			candidate.HoursChange = evaluation.Impact.TargetEmployeeImpact.HoursChange
+		}
+		candidate.Reasons = candidateReasons(&candidate)
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Feasible != b.Feasible {
+			return a.Feasible
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.CurrentHours != b.CurrentHours {
+			return a.CurrentHours < b.CurrentHours
+		}
+		return a.Employee.Name < b.Employee.Name
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	for i := range candidates {
+		candidates[i].Rank = i + 1
+	}
+	return candidates
+}
+
+// candidateReasons 生成候选的推荐原因，不可行时列出冲突
+func candidateReasons(c *Candidate) []string {
+	reasons := make([]string, 0)
+	if !c.Feasible {
+		for _, issue := range c.Evaluation.Issues {
+			if issue.Severity == "error" {
+				reasons = append(reasons, issue.Message)
+			}
+		}
+		return reasons
+	}
+
+	warnings := 0
+	for _, issue := range c.Evaluation.Issues {
+		if issue.Severity == "warning" {
+			warnings++
+		}
+	}
+	if warnings == 0 {
+		reasons = append(reasons, "无约束冲突")
+	} else {
+		reasons = append(reasons, fmt.Sprintf("有 %d 项软约束提醒", warnings))
+	}
+	reasons = append(reasons, fmt.Sprintf("本期已排 %.1f 小时，接班后 %.1f 小时", c.CurrentHours, c.CurrentHours+c.HoursChange))
+	if c.Evaluation.Impact != nil && c.Evaluation.Impact.TargetEmployeeImpact.OvertimeChange > 0 {
+		reasons = append(reasons, fmt.Sprintf("将产生 %.1f 小时加班", c.Evaluation.Impact.TargetEmployeeImpact.OvertimeChange))
+	}
+	return reasons
+}
@@ -106,15 +106,14 @@ func (e *SwapEvaluator) EvaluateSwap(
 		// 查找对应需求
 		for _, req := range ctx.Requirements {
 			if req.ShiftID == source.ShiftID && req.Date == source.Date {
-				for _, skill := range req.Skills {
-					if !targetEmp.HasSkill(skill) {
-						result.Feasible = false
-						result.Issues = append(result.Issues, SwapIssue{
-							Type:     "skill_mismatch",
-							Severity: "error",
-							Message:  "目标员工缺少必需技能: " + skill,
-						})
-					}
+				missing := append(targetEmp.MissingSkills(req.Skills, req.SkillGroups), targetEmp.MissingSkillLevels(req.SkillLevels)...)
+				for _, skill := range missing {
+					result.Feasible = false
+					result.Issues = append(result.Issues, SwapIssue{
+						Type:     "skill_mismatch",
+						Severity: "error",
+						Message:  "目标员工缺少必需技能: " + skill,
+					})
 				}
 			}
 		}
@@ -127,9 +126,14 @@ func (e *SwapEvaluator) EvaluateSwap(
 		employees[emp.ID] = emp
 	}
 
+	// 互换时源员工接手目标班次，同样检查其冲突和硬约束
+	involved := func(id uuid.UUID) bool {
+		return id == targetEmp.ID || (request.TargetAssignment != nil && id == source.EmployeeID)
+	}
+
 	conflicts := e.conflictDetector.DetectAll(simulatedAssignments, employees)
 	for _, conflict := range conflicts {
-		if conflict.EmployeeID == targetEmp.ID {
+		if involved(conflict.EmployeeID) {
 			result.Issues = append(result.Issues, SwapIssue{
 				Type:     string(conflict.Type),
 				Severity: conflict.Severity,
@@ -149,7 +153,7 @@ func (e *SwapEvaluator) EvaluateSwap(
 
 		if !constraintResult.IsValid {
 			for _, v := range constraintResult.HardViolations {
-				if v.EmployeeID == targetEmp.ID {
+				if involved(v.EmployeeID) {
 					result.Feasible = false
 					result.Issues = append(result.Issues, SwapIssue{
 						Type:     string(v.ConstraintType),
@@ -201,6 +205,7 @@ func (e *SwapEvaluator) createSimulatedContext(ctx *constraint.Context, request
 	simCtx := constraint.NewContext(ctx.OrgID, ctx.StartDate, ctx.EndDate)
 	simCtx.SetEmployees(ctx.Employees)
 	simCtx.SetShifts(ctx.Shifts)
+	simCtx.SetHistory(ctx.History, ctx.HistoryShifts)
 	simCtx.Requirements = ctx.Requirements
 
 	simulated := e.simulateSwap(ctx, request)
@@ -219,14 +224,20 @@ func (e *SwapEvaluator) calculateImpact(ctx *constraint.Context, request *SwapRe
 		return
 	}
 
+	// 互换时源员工接手目标班次的工时
+	exchanged := 0.0
+	if request.TargetAssignment != nil {
+		exchanged = request.TargetAssignment.WorkingHours()
+	}
+
 	// 源员工影响
 	sourceCurrentHours := ctx.GetEmployeeHoursInRange(sourceEmp.ID, ctx.StartDate, ctx.EndDate)
-	sourceNewHours := sourceCurrentHours - source.WorkingHours()
+	sourceNewHours := sourceCurrentHours - source.WorkingHours() + exchanged
 	result.Impact.SourceEmployeeImpact.HoursChange = sourceNewHours - sourceCurrentHours
 
 	// 目标员工影响
 	targetCurrentHours := ctx.GetEmployeeHoursInRange(targetEmp.ID, ctx.StartDate, ctx.EndDate)
-	targetNewHours := targetCurrentHours + source.WorkingHours()
+	targetNewHours := targetCurrentHours + source.WorkingHours() - exchanged
 	result.Impact.TargetEmployeeImpact.HoursChange = targetNewHours - targetCurrentHours
 
 	// 加班变化（假设标准40小时）
@@ -85,6 +85,9 @@ func (d *ConflictDetector) DetectAll(assignments []*model.Assignment, employees
 		conflicts = append(conflicts, d.detectRestTimeViolations(emp, empAssignments)...)
 		conflicts = append(conflicts, d.detectMaxHoursViolations(emp, empAssignments)...)
 		conflicts = append(conflicts, d.detectConsecutiveDaysViolations(emp, empAssignments)...)
+		if d.config.CheckAvailability {
+			conflicts = append(conflicts, d.detectAvailabilityViolations(emp, empAssignments)...)
+		}
 	}
 
 	return conflicts
@@ -132,6 +135,11 @@ func (d *ConflictDetector) DetectForAssignment(
 		}
 	}
 
+	// 检测可用性
+	if d.config.CheckAvailability && employee != nil {
+		conflicts = append(conflicts, d.detectAvailabilityViolations(employee, []*model.Assignment{newAssignment})...)
+	}
+
 	// 检测每日工时
 	dailyHours := d.calculateDailyHours(newAssignment, existingAssignments)
 	if dailyHours > float64(d.config.MaxHoursPerDay) {
@@ -218,14 +226,14 @@ func (d *ConflictDetector) detectRestTimeViolations(emp *model.Employee, assignm
 func (d *ConflictDetector) detectMaxHoursViolations(emp *model.Employee, assignments []*model.Assignment) []Conflict {
 	var conflicts []Conflict
 
-	// 按日期统计工时
+	// 按日期和周（周日起）统计工时
 	dailyHours := make(map[string]float64)
-	var weeklyTotal float64
+	weeklyHours := make(map[string]float64)
 
 	for _, a := range assignments {
 		hours := a.WorkingHours()
 		dailyHours[a.Date] += hours
-		weeklyTotal += hours
+		weeklyHours[weekStart(a.Date)] += hours
 	}
 
 	// 检查每日工时
@@ -241,14 +249,22 @@ func (d *ConflictDetector) detectMaxHoursViolations(emp *model.Employee, assignm
 		}
 	}
 
-	// 检查每周工时
-	if weeklyTotal > float64(d.config.MaxHoursPerWeek) {
-		conflicts = append(conflicts, Conflict{
-			Type:       ConflictMaxHours,
-			Severity:   "error",
-			EmployeeID: emp.ID,
-			Message:    fmt.Sprintf("员工 %s 周工作 %.1f 小时，超过限制 %d 小时", emp.Name, weeklyTotal, d.config.MaxHoursPerWeek),
-		})
+	// 检查每周工时（排班跨多周时逐周检查）
+	weeks := make([]string, 0, len(weeklyHours))
+	for week := range weeklyHours {
+		weeks = append(weeks, week)
+	}
+	sort.Strings(weeks)
+	for _, week := range weeks {
+		if hours := weeklyHours[week]; hours > float64(d.config.MaxHoursPerWeek) {
+			conflicts = append(conflicts, Conflict{
+				Type:       ConflictMaxHours,
+				Severity:   "error",
+				EmployeeID: emp.ID,
+				Date:       week,
+				Message:    fmt.Sprintf("员工 %s 周工作 %.1f 小时，超过限制 %d 小时", emp.Name, hours, d.config.MaxHoursPerWeek),
+			})
+		}
 	}
 
 	return conflicts
@@ -305,6 +321,28 @@ func (d *ConflictDetector) detectConsecutiveDaysViolations(emp *model.Employee,
 	return conflicts
 }
 
+// detectAvailabilityViolations 检测超出员工可用时段的排班
+func (d *ConflictDetector) detectAvailabilityViolations(emp *model.Employee, assignments []*model.Assignment) []Conflict {
+	var conflicts []Conflict
+
+	for _, a := range assignments {
+		if emp.IsAvailable(a.Date, a.StartTime, a.EndTime) {
+			continue
+		}
+		conflicts = append(conflicts, Conflict{
+			Type:       ConflictAvailability,
+			Severity:   "error",
+			EmployeeID: emp.ID,
+			Date:       a.Date,
+			Message: fmt.Sprintf("员工 %s 在 %s %s-%s 不在可用时段内",
+				emp.Name, a.Date, a.StartTime.Format("15:04"), a.EndTime.Format("15:04")),
+			Assignments: []uuid.UUID{a.ID},
+		})
+	}
+
+	return conflicts
+}
+
 // isOverlapping 检查两个排班是否重叠
 func (d *ConflictDetector) isOverlapping(a1, a2 *model.Assignment) bool {
 	return a1.StartTime.Before(a2.EndTime) && a2.StartTime.Before(a1.EndTime)
@@ -343,6 +381,15 @@ func groupByEmployee(assignments []*model.Assignment) map[uuid.UUID][]*model.Ass
 	return result
 }
 
+// weekStart 返回日期所在周的周日，日期格式错误时原样返回
+func weekStart(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.AddDate(0, 0, -int(t.Weekday())).Format("2006-01-02")
+}
+
 // isConsecutiveDateStr 检查两个日期字符串是否连续
 func isConsecutiveDateStr(date1, date2 string) bool {
 	t1, err1 := time.Parse("2006-01-02", date1)
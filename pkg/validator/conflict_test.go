@@ -192,3 +192,73 @@ func TestNewConflictDetector(t *testing.T) {
 		t.Error("Detector should not be nil")
 	}
 }
+
+func TestConflictDetector_DetectAvailability(t *testing.T) {
+	detector := NewConflictDetector(DefaultDetectorConfig())
+
+	emp1 := uuid.New()
+	employees := map[uuid.UUID]*model.Employee{
+		emp1: {
+			BaseModel: model.BaseModel{ID: emp1},
+			Name:      "员工1",
+			AvailabilityWindows: []model.AvailabilityWindow{
+				{Start: "09:00", End: "14:00"},
+			},
+		},
+	}
+
+	start, _ := time.Parse("2006-01-02 15:04", "2024-01-15 12:00")
+	assignments := []*model.Assignment{
+		{
+			BaseModel:  model.BaseModel{ID: uuid.New()},
+			EmployeeID: emp1,
+			Date:       "2024-01-15",
+			StartTime:  start,
+			EndTime:    start.Add(6 * time.Hour), // 超出 14:00
+		},
+	}
+
+	conflicts := detector.DetectAll(assignments, employees)
+	found := false
+	for _, c := range conflicts {
+		if c.Type == ConflictAvailability {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Should detect availability conflict for assignment outside window")
+	}
+}
+
+func TestConflictDetector_WeeklyHoursPerWeek(t *testing.T) {
+	detector := NewConflictDetector(DefaultDetectorConfig())
+
+	emp1 := uuid.New()
+	employees := map[uuid.UUID]*model.Employee{
+		emp1: {BaseModel: model.BaseModel{ID: emp1}, Name: "员工1"},
+	}
+
+	// 两周每周工作 5 天、每天 8 小时：每周 40 小时不超限，两周合计 80 小时不应判为周工时超限
+	var assignments []*model.Assignment
+	day, _ := time.Parse("2006-01-02", "2024-01-15")
+	for i := 0; i < 14; i++ {
+		d := day.AddDate(0, 0, i)
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+		start := d.Add(9 * time.Hour)
+		assignments = append(assignments, &model.Assignment{
+			BaseModel:  model.BaseModel{ID: uuid.New()},
+			EmployeeID: emp1,
+			Date:       d.Format("2006-01-02"),
+			StartTime:  start,
+			EndTime:    start.Add(8 * time.Hour),
+		})
+	}
+
+	for _, c := range detector.DetectAll(assignments, employees) {
+		if c.Type == ConflictMaxHours {
+			t.Errorf("不应检测到工时超限: %s", c.Message)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+//go:build chaos
+
+// Package chaos 故障注入下的容错测试，运行: go test -tags chaos ./tests/chaos/...
+package chaos
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/middleware"
+	"github.com/paiban/paiban/pkg/chaos"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+func generateBody(t *testing.T) []byte {
+	t.Helper()
+	shiftID := uuid.New().String()
+	body, err := json.Marshal(handler.GenerateRequest{
+		OrgID:     uuid.New().String(),
+		StartDate: "2024-01-15",
+		EndDate:   "2024-01-15",
+		Employees: []handler.EmployeeInput{{ID: uuid.New().String(), Name: "张三", Position: "服务员"}},
+		Shifts:    []handler.ShiftInput{{ID: shiftID, Name: "早班", Code: "M", StartTime: "07:00", EndTime: "15:00", Duration: 480}},
+		Requirements: []handler.RequirementInput{
+			{ShiftID: shiftID, Date: "2024-01-15", MinEmployees: 1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// TestConstraintPanicReturns500 约束评估 panic 时返回 500，服务继续处理后续请求
+func TestConstraintPanicReturns500(t *testing.T) {
+	t.Cleanup(chaos.Reset)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedule/generate", handler.NewScheduleHandlerWithoutDB().Generate)
+	srv := httptest.NewServer(middleware.RequestIDMiddleware(middleware.Recovery(nil)(mux)))
+	defer srv.Close()
+
+	post := func() (*http.Response, map[string]interface{}) {
+		resp, err := http.Post(srv.URL+"/api/v1/schedule/generate", "application/json", bytes.NewReader(generateBody(t)))
+		if err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return resp, body
+	}
+
+	if _, err := chaos.Arm(chaos.Fault{Point: chaos.ConstraintPanic, Count: 1}); err != nil {
+		t.Fatalf("Arm() error = %v", err)
+	}
+	resp, body := post()
+	if resp.StatusCode != http.StatusInternalServerError || body["request_id"] != resp.Header.Get("X-Request-ID") {
+		t.Errorf("约束 panic 应返回带请求ID的 500, got %d %v", resp.StatusCode, body)
+	}
+	if resp, _ := post(); resp.StatusCode != http.StatusOK {
+		t.Errorf("故障解除后应恢复正常, got %d", resp.StatusCode)
+	}
+}
+
+// TestStoreLatency 存储延迟只作用于指定数据集，达到触发次数后解除
+func TestStoreLatency(t *testing.T) {
+	t.Cleanup(chaos.Reset)
+	store := memstore.New("")
+	schedule := &model.Schedule{BaseModel: model.BaseModel{ID: uuid.New()}}
+	if err := store.PutSchedule(schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := chaos.Arm(chaos.Fault{Point: chaos.StoreLatency, Target: "schedules", LatencyMs: 50, Count: 1}); err != nil {
+		t.Fatalf("Arm() error = %v", err)
+	}
+	start := time.Now()
+	store.ListEmployees(uuid.Nil)
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("其他数据集不应延迟, elapsed=%v", elapsed)
+	}
+	start = time.Now()
+	if _, err := store.GetSchedule(schedule.ID); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("读取排班应延迟 50ms, elapsed=%v", elapsed)
+	}
+	start = time.Now()
+	store.GetSchedule(schedule.ID)
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("达到触发次数后不应再延迟, elapsed=%v", elapsed)
+	}
+}
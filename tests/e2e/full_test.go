@@ -227,6 +227,7 @@ func TestAPIEndpoints(t *testing.T) {
 		{"POST", "/api/v1/schedule/validate", http.StatusBadRequest},
 		{"POST", "/api/v1/dispatch/single", http.StatusBadRequest},
 		{"POST", "/api/v1/dispatch/batch", http.StatusBadRequest},
+		{"POST", "/api/v1/dispatch/reassign", http.StatusBadRequest},
 		{"POST", "/api/v1/dispatch/route", http.StatusBadRequest},
 		{"POST", "/api/v1/careplan/create", http.StatusBadRequest},
 	}
@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/middleware"
+)
+
+func newGenerateBody(t *testing.T) []byte {
+	t.Helper()
+	shiftID := uuid.New().String()
+	request := map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": "2026-01-13",
+		"end_date":   "2026-01-13",
+		"employees": []map[string]interface{}{
+			{"id": uuid.New().String(), "name": "张三"},
+			{"id": uuid.New().String(), "name": "李四"},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "早班", "code": "M", "start_time": "08:00", "end_time": "16:00", "duration": 480},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": shiftID, "date": "2026-01-13", "min_employees": 1},
+		},
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	return body
+}
+
+// TestScheduleAPIV2_Generate 测试 v2 排班资源格式
+func TestScheduleAPIV2_Generate(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+
+	req := httptest.NewRequest("POST", "/api/v2/schedules", bytes.NewReader(newGenerateBody(t)))
+	rec := httptest.NewRecorder()
+	h.GenerateV2(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("API-Version") != "v2" {
+		t.Error("missing API-Version header")
+	}
+
+	var schedule handler.ScheduleV2
+	if err := json.Unmarshal(rec.Body.Bytes(), &schedule); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if schedule.APIVersion != "v2" || schedule.Kind != "Schedule" {
+		t.Errorf("unexpected resource header: %s/%s", schedule.APIVersion, schedule.Kind)
+	}
+	if schedule.Status != handler.ScheduleStatusComplete {
+		t.Errorf("status = %s, expected complete", schedule.Status)
+	}
+	if len(schedule.Assignments) != 1 {
+		t.Errorf("expected 1 assignment, got %d", len(schedule.Assignments))
+	}
+	if schedule.Violations == nil || schedule.Warnings == nil {
+		t.Error("v2 列表字段应始终存在")
+	}
+}
+
+// TestScheduleAPIV2_Error 测试 v2 错误资源格式
+func TestScheduleAPIV2_Error(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+
+	req := httptest.NewRequest("POST", "/api/v2/schedules", bytes.NewReader([]byte(`{}`)))
+	rec := httptest.NewRecorder()
+	h.GenerateV2(rec, req)
+
+	var apiErr handler.ErrorV2
+	if err := json.Unmarshal(rec.Body.Bytes(), &apiErr); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if rec.Code == http.StatusOK || apiErr.Kind != "Error" || apiErr.Code == "" {
+		t.Errorf("unexpected error response: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+// TestDeprecationHeaders 测试 v1 弃用响应头
+func TestDeprecationHeaders(t *testing.T) {
+	mw := middleware.DeprecationMiddleware(&middleware.DeprecationConfig{
+		PathPrefix: "/api/v1/",
+		Sunset:     time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC),
+		Successors: map[string]string{"/api/v1/schedule/generate": "/api/v2/schedules"},
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	rec := httptest.NewRecorder()
+	mw(next).ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/schedule/generate", nil))
+	if rec.Header().Get("Deprecation") != "true" {
+		t.Error("v1 should carry Deprecation header")
+	}
+	if rec.Header().Get("Sunset") != "Wed, 30 Jun 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", rec.Header().Get("Sunset"))
+	}
+	if rec.Header().Get("Link") != `</api/v2/schedules>; rel="successor-version"` {
+		t.Errorf("Link = %q", rec.Header().Get("Link"))
+	}
+
+	rec = httptest.NewRecorder()
+	mw(next).ServeHTTP(rec, httptest.NewRequest("POST", "/api/v2/schedules", nil))
+	if rec.Header().Get("Deprecation") != "" {
+		t.Error("v2 should not be deprecated")
+	}
+}
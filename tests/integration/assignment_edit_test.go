@@ -0,0 +1,146 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/validator"
+)
+
+// TestAssignmentEdit 测试单个分配的新增、修改和删除：响应包含冲突检测结果和覆盖、公平性指标变化，dry_run 不保存
+func TestAssignmentEdit(t *testing.T) {
+	store := memstore.New("")
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+	drafts := handler.NewDraftHandler(store)
+	records := handler.NewScheduleRecordHandler(nil, store, drafts)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedules/{id}/assignments", records.Assignments)
+	mux.HandleFunc("/api/v1/schedules/{id}/assignments/{assignment_id}", drafts.Assignment)
+	do := func(method, path string, body interface{}) (*httptest.ResponseRecorder, handler.AssignmentEditResponse) {
+		t.Helper()
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewReader(data)))
+		var resp handler.AssignmentEditResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	orgID := uuid.New().String()
+	day, night := uuid.New().String(), uuid.New().String()
+	zhang, li := uuid.New().String(), uuid.New().String()
+	rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", map[string]interface{}{
+		"org_id":     orgID,
+		"start_date": "2026-01-15",
+		"end_date":   "2026-01-16",
+		"employees": []map[string]interface{}{
+			{"id": zhang, "name": "张三"},
+			{"id": li, "name": "李四"},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": day, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480},
+			{"id": night, "name": "夜班", "code": "N", "start_time": "20:00", "end_time": "23:00", "duration": 180},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": day, "date": "2026-01-15", "min_employees": 1},
+			{"shift_id": day, "date": "2026-01-16", "min_employees": 1},
+			{"shift_id": night, "date": "2026-01-16", "min_employees": 1},
+		},
+	})
+	var generated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if rec.Code != http.StatusOK || len(generated.Assignments) != 3 {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	base := "/api/v1/schedules/" + generated.ScheduleID + "/assignments"
+
+	// 删除 16 日夜班：覆盖减少一个需求，dry_run 不保存
+	var nightID, dayEmployee string
+	for _, a := range generated.Assignments {
+		if a.ShiftID == night {
+			nightID = a.ID
+		}
+		if a.ShiftID == day && a.Date == "2026-01-16" {
+			dayEmployee = a.EmployeeID
+		}
+	}
+	rec, resp := do(http.MethodDelete, base+"/"+nightID+"?dry_run=true", nil)
+	if rec.Code != http.StatusOK || !resp.DryRun || resp.Delta.Filled != -1 || resp.Delta.Shortage != 1 || resp.Delta.Assignments != -1 {
+		t.Fatalf("dry run status = %d, delta = %+v, body = %s", rec.Code, resp.Delta, rec.Body.String())
+	}
+	if schedule, _ := store.GetSchedule(uuid.MustParse(generated.ScheduleID)); schedule.Version != 1 || len(schedule.Assignments) != 3 {
+		t.Errorf("dry run 不应保存: version = %d, assignments = %d", schedule.Version, len(schedule.Assignments))
+	}
+	rec, resp = do(http.MethodDelete, base+"/"+nightID, nil)
+	if rec.Code != http.StatusOK || resp.Schedule.Version != 2 || len(resp.Schedule.Assignments) != 2 || resp.After.FillRate >= resp.Before.FillRate {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	// 新增夜班给白班员工：休息不足 10 小时，返回冲突但仍保存
+	rec, resp = do(http.MethodPost, base, map[string]interface{}{
+		"employee_id": dayEmployee, "shift_id": night, "date": "2026-01-16", "base_version": 2,
+	})
+	if rec.Code != http.StatusCreated || resp.Schedule.Version != 3 || resp.Delta.Filled != 1 || resp.Delta.WorkloadGini <= 0 {
+		t.Fatalf("add status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if !hasConflict(resp.Conflicts, validator.ConflictRestTime) {
+		t.Errorf("应检测到休息时间冲突: %+v", resp.Conflicts)
+	}
+	if resp.Change.Assignment == nil || resp.Change.Assignment.StartTime.Format("15:04") != "20:00" {
+		t.Errorf("未给出时间时应取班次时间: %+v", resp.Change.Assignment)
+	}
+
+	// 改派给另一名员工：冲突消除
+	other := zhang
+	if dayEmployee == zhang {
+		other = li
+	}
+	added := resp.Change.AssignmentID.String()
+	rec, resp = do(http.MethodPatch, base+"/"+added, map[string]interface{}{"employee_id": other})
+	if rec.Code != http.StatusOK || resp.Schedule.Version != 4 || hasConflict(resp.Conflicts, validator.ConflictRestTime) {
+		t.Fatalf("patch status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if resp.Delta.Filled != 0 || resp.Delta.TotalHours != 0 {
+		t.Errorf("delta = %+v", resp.Delta)
+	}
+	if rec.Header().Get("ETag") != `"4"` {
+		t.Errorf("ETag = %s", rec.Header().Get("ETag"))
+	}
+
+	// 修改时间；基于旧版本返回 409
+	rec, resp = do(http.MethodPatch, base+"/"+added, map[string]interface{}{"start_time": "19:00", "end_time": "22:00"})
+	if rec.Code != http.StatusOK || resp.Change.Assignment.EndTime.Format("15:04") != "22:00" {
+		t.Fatalf("patch time status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec, _ = do(http.MethodPatch, base+"/"+added, map[string]interface{}{"position": "收银", "base_version": 3}); rec.Code != http.StatusConflict {
+		t.Errorf("旧版本应返回 409: status = %d", rec.Code)
+	}
+
+	if rec, _ = do(http.MethodPost, base, map[string]interface{}{"employee_id": uuid.New().String(), "shift_id": day, "date": "2026-01-15"}); rec.Code != http.StatusBadRequest {
+		t.Errorf("未知员工应返回 400: status = %d", rec.Code)
+	}
+	if rec, _ = do(http.MethodPatch, base+"/"+uuid.New().String(), map[string]interface{}{"employee_id": other}); rec.Code != http.StatusNotFound {
+		t.Errorf("不存在的分配应返回 404: status = %d", rec.Code)
+	}
+}
+
+// hasConflict 冲突列表中是否包含指定类型
+func hasConflict(conflicts []validator.Conflict, typ validator.ConflictType) bool {
+	for _, c := range conflicts {
+		if c.Type == typ {
+			return true
+		}
+	}
+	return false
+}
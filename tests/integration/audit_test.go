@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestAuditLog 测试审计日志：生成排班、换班和约束配置变更记录操作人与请求ID，按组织、时间、操作和请求ID查询
+func TestAuditLog(t *testing.T) {
+	store := memstore.New("")
+	handler.SetAuditStore(store)
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+	drafts := handler.NewDraftHandler(store)
+	swaps := handler.NewSwapHandler(store, schedules, drafts)
+	configs := handler.NewConstraintConfigHandler(store)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedule/generate", schedules.Generate)
+	mux.HandleFunc("/api/v1/swap/apply", swaps.Apply)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/constraint-config", configs.OrgConfig)
+	mux.HandleFunc("/api/v1/audit", handler.AuditHandler)
+	do := func(method, path, requestID string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set(handler.RoleHeader, "manager")
+		req.Header.Set(handler.AuthorHeader, "u-1001")
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	query := func(params string) handler.AuditListResponse {
+		t.Helper()
+		rec := do(http.MethodGet, "/api/v1/audit?"+params, "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("audit status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.AuditListResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+
+	orgID := uuid.New().String()
+	shiftID := uuid.New().String()
+	employees := []string{uuid.New().String(), uuid.New().String()}
+	request := map[string]interface{}{
+		"org_id":     orgID,
+		"start_date": "2026-01-15",
+		"end_date":   "2026-01-15",
+		"employees": []map[string]interface{}{
+			{"id": employees[0], "name": "张三"},
+			{"id": employees[1], "name": "李四"},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": shiftID, "date": "2026-01-15", "min_employees": 1},
+		},
+	}
+	// 试算不记录审计日志
+	request["options"] = map[string]interface{}{"dry_run": true}
+	if rec := do(http.MethodPost, "/api/v1/schedule/generate", "", request); rec.Code != http.StatusOK {
+		t.Fatalf("dry run status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	delete(request, "options")
+	rec := do(http.MethodPost, "/api/v1/schedule/generate", "req-generate", request)
+	var generated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if rec.Code != http.StatusOK || len(generated.Assignments) != 1 {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	source := generated.Assignments[0]
+	target := employees[0]
+	if source.EmployeeID == target {
+		target = employees[1]
+	}
+	if rec = do(http.MethodPost, "/api/v1/swap/apply", "req-swap", map[string]interface{}{
+		"schedule_id": generated.ScheduleID, "source_assignment_id": source.ID, "target_employee_id": target,
+	}); rec.Code != http.StatusOK {
+		t.Fatalf("apply status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodPut, "/api/v1/orgs/"+orgID+"/constraint-config", "req-config", map[string]interface{}{
+		"config": map[string]interface{}{"max_hours_per_week": map[string]interface{}{"max_hours": 40}},
+	}); rec.Code != http.StatusOK {
+		t.Fatalf("config status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	all := query("org_id=" + orgID)
+	if all.Total != 3 {
+		t.Fatalf("total = %d, logs = %+v", all.Total, all.Logs)
+	}
+	// 按时间倒序
+	actions := []string{model.AuditConstraintChange, model.AuditSwapApply, model.AuditScheduleGenerate}
+	for i, l := range all.Logs {
+		if l.Action != actions[i] || l.Actor != "u-1001" || l.Role != "manager" {
+			t.Errorf("logs[%d] = %+v", i, l)
+		}
+	}
+
+	byRequest := query("org_id=" + orgID + "&request_id=req-swap")
+	if byRequest.Total != 1 || byRequest.Logs[0].ResourceID != generated.ScheduleID || byRequest.Logs[0].Method != http.MethodPost {
+		t.Errorf("按请求ID查询 = %+v", byRequest.Logs)
+	}
+	if got := query("org_id=" + orgID + "&action=" + model.AuditScheduleGenerate); got.Total != 1 || got.Logs[0].RequestID != "req-generate" {
+		t.Errorf("按操作查询 = %+v", got.Logs)
+	}
+	today := time.Now().Format("2006-01-02")
+	if got := query("org_id=" + orgID + "&from=" + today + "&to=" + today); got.Total != 3 {
+		t.Errorf("按日期查询 total = %d", got.Total)
+	}
+	if got := query("org_id=" + orgID + "&to=" + time.Now().AddDate(0, 0, -1).Format("2006-01-02")); got.Total != 0 {
+		t.Errorf("昨天之前 total = %d", got.Total)
+	}
+	if got := query("org_id=" + uuid.New().String()); got.Total != 0 {
+		t.Errorf("其他组织 total = %d", got.Total)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/audit?org_id="+orgID, nil)
+	req.Header.Set(handler.RoleHeader, "employee")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("非管理者应返回 403: status=%d", rec.Code)
+	}
+	if rec = do(http.MethodGet, "/api/v1/audit?org_id="+orgID+"&from=bad", "", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("无效时间应返回 400: status=%d", rec.Code)
+	}
+}
@@ -0,0 +1,141 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/order"
+	"github.com/paiban/paiban/pkg/careplan"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestCarePlanOrders 测试创建护理计划、预览订单，以及生成订单保存为待派单订单（重复生成时跳过已有订单）
+func TestCarePlanOrders(t *testing.T) {
+	store := memstore.New("")
+	plans := handler.NewCarePlanHandler(store, order.NewService(store))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/careplan/create", plans.Create)
+	mux.HandleFunc("/api/v1/careplan/generate-orders", plans.GenerateOrders)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/care-plans", plans.Plans)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/care-plans/{id}", plans.Plan)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/care-plans/{id}/preview-orders", plans.PreviewOrders)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/care-plans/{id}/generate-orders", plans.SaveOrders)
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 不保存的接口：缺少请求体返回 400；只填写护理等级时按等级生成服务项目
+	if rec := do(http.MethodPost, "/api/v1/careplan/create", nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("缺少请求体应返回 400: status=%d", rec.Code)
+	}
+	customerID := uuid.New()
+	rec := do(http.MethodPost, "/api/v1/careplan/create", map[string]interface{}{"customer_id": customerID, "level": 2, "start_date": "2026-03-02"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Data model.CarePlan `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.Data.WeeklyHours != 5 || len(created.Data.ServiceItems) != 3 || created.Data.Status != "active" {
+		t.Errorf("plan = %+v", created.Data)
+	}
+	rec = do(http.MethodPost, "/api/v1/careplan/generate-orders", map[string]interface{}{
+		"care_plan":    created.Data,
+		"customer":     map[string]interface{}{"address": "客户地址"},
+		"period_start": "2026-03-02",
+		"period_end":   "2026-03-08",
+	})
+	var preview struct {
+		Data careplan.Expansion `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &preview)
+	if rec.Code != http.StatusOK || len(preview.Data.Orders) != 2 || preview.Data.Orders[0].Address != "客户地址" {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	// 保存的计划
+	orgID := uuid.New()
+	base := "/api/v1/orgs/" + orgID.String() + "/care-plans"
+	rec = do(http.MethodPost, base, map[string]interface{}{
+		"customer_id": customerID,
+		"care_plan": map[string]interface{}{
+			"level":            3,
+			"start_date":       "2026-03-02",
+			"weekly_hours":     4,
+			"frequency":        "twice_weekly",
+			"address":          "幸福路1号",
+			"visit_start_time": "10:00",
+			"service_items": []map[string]interface{}{
+				{"code": "bath", "name": "助浴", "duration": 60, "frequency": 1},
+				{"code": "care", "name": "基础护理", "duration": 45, "frequency": 2},
+			},
+		},
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create plan status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var plan model.CarePlan
+	json.Unmarshal(rec.Body.Bytes(), &plan)
+	if plan.OrgID != orgID || plan.PlanNo == "" {
+		t.Errorf("plan = %+v", plan)
+	}
+	if rec = do(http.MethodPost, base, map[string]interface{}{"customer_id": customerID, "level": 9}); rec.Code != http.StatusBadRequest {
+		t.Errorf("护理等级无效应返回 400: status=%d", rec.Code)
+	}
+
+	period := map[string]interface{}{"period_start": "2026-03-02", "period_end": "2026-03-15"}
+	rec = do(http.MethodPost, base+"/"+plan.ID.String()+"/preview-orders", period)
+	var expansion careplan.Expansion
+	json.Unmarshal(rec.Body.Bytes(), &expansion)
+	// 每周两次（周二、周五），每周合计 60 + 45×2 = 150 分钟
+	if rec.Code != http.StatusOK || len(expansion.Orders) != 4 || expansion.WeeklyMinutes != 150 || expansion.TotalMinutes != 300 {
+		t.Fatalf("preview status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if o := expansion.Orders[0]; o.ServiceDate != "2026-03-03" || o.StartTime != "10:00" || o.Address != "幸福路1号" {
+		t.Errorf("第一单 = %+v", o)
+	}
+	if n := len(store.ListOrders(orgID, "", "", "")); n != 0 {
+		t.Errorf("预览不应保存订单: %d", n)
+	}
+
+	rec = do(http.MethodPost, base+"/"+plan.ID.String()+"/generate-orders", period)
+	var result struct {
+		Created []*model.ServiceOrder `json:"created"`
+		Skipped []string              `json:"skipped"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if rec.Code != http.StatusCreated || len(result.Created) != 4 || result.Created[0].Status != model.OrderPending {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	// 再次生成包含已有时段时跳过已有订单
+	rec = do(http.MethodPost, base+"/"+plan.ID.String()+"/generate-orders", map[string]interface{}{"period_start": "2026-03-09", "period_end": "2026-03-22"})
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if len(result.Created) != 2 || len(result.Skipped) != 2 {
+		t.Errorf("重复生成: created=%d skipped=%v", len(result.Created), result.Skipped)
+	}
+	if n := len(store.ListOrders(orgID, "", "", "")); n != 6 {
+		t.Errorf("orders = %d, want 6", n)
+	}
+
+	if rec = do(http.MethodGet, base+"?customer_id="+customerID.String(), nil); rec.Code != http.StatusOK {
+		t.Errorf("list status = %d", rec.Code)
+	}
+	if rec = do(http.MethodGet, "/api/v1/orgs/"+uuid.New().String()+"/care-plans/"+plan.ID.String(), nil); rec.Code != http.StatusNotFound {
+		t.Errorf("其他组织的计划应返回 404: status=%d", rec.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestCompareSchedules 测试方案对比：不同约束配置的试算结果及已有方案的并排对比
+func TestCompareSchedules(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+	expensive, cheap, shiftID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	dates := []string{"2026-03-02", "2026-03-03", "2026-03-04", "2026-03-05"}
+	var requirements []map[string]interface{}
+	for _, date := range dates {
+		requirements = append(requirements, map[string]interface{}{"shift_id": shiftID, "date": date, "min_employees": 1, "max_employees": 1})
+	}
+	base := map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": dates[0],
+		"end_date":   dates[len(dates)-1],
+		"employees": []map[string]interface{}{
+			{"id": expensive, "name": "张三", "position": "服务员", "hourly_rate": 30},
+			{"id": cheap, "name": "李四", "position": "服务员", "hourly_rate": 20},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480},
+		},
+		"requirements": requirements,
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"base": base,
+		"profiles": []map[string]interface{}{
+			{"name": "均衡", "constraints": map[string]interface{}{}},
+			{"name": "控成本", "constraints": map[string]interface{}{"max_labor_cost_per_week": 200}},
+		},
+	})
+	resp := postCompare(t, h, body)
+	if len(resp.Scenarios) != 2 || resp.Scenarios[0].Delta != nil || resp.Scenarios[1].Delta == nil {
+		t.Fatalf("scenarios = %+v", resp.Scenarios)
+	}
+	balanced, budget := resp.Scenarios[0], resp.Scenarios[1]
+	if balanced.FillRate != 100 || len(balanced.Schedule) != 4 || balanced.WorkloadGini != 0 {
+		t.Errorf("均衡方案 = %+v", balanced)
+	}
+	// 预算紧张时全部安排时薪低的员工：4×8×20
+	if budget.Cost != 640 || budget.Delta.Cost != budget.Cost-balanced.Cost || budget.Delta.Fairness == nil {
+		t.Errorf("控成本方案 = %+v delta = %+v", budget, budget.Delta)
+	}
+	if resp.Best["cost"] != "控成本" || resp.Best["fairness"] != "均衡" {
+		t.Errorf("best = %v", resp.Best)
+	}
+
+	// 已有方案：方案 B 只排了两天
+	var full, half []map[string]interface{}
+	for i, date := range dates {
+		a := map[string]interface{}{"employee_id": cheap, "shift_id": shiftID, "date": date, "start_time": "09:00", "end_time": "17:00"}
+		full = append(full, a)
+		if i < 2 {
+			half = append(half, a)
+		}
+	}
+	body, _ = json.Marshal(map[string]interface{}{
+		"base": base,
+		"schedules": []map[string]interface{}{
+			{"name": "A", "assignments": full},
+			{"name": "B", "assignments": half},
+		},
+	})
+	resp = postCompare(t, h, body)
+	if a, b := resp.Scenarios[0], resp.Scenarios[1]; a.FillRate != 100 || b.FillRate != 50 || b.Shortage != 2 || b.Cost != 320 || b.Delta.Shortage != 2 {
+		t.Errorf("已有方案对比 = %+v / %+v", a, b)
+	}
+	if resp.Best["fill_rate"] != "A" || resp.Best["cost"] != "B" {
+		t.Errorf("best = %v", resp.Best)
+	}
+
+	// 只有一个方案时无法对比
+	body, _ = json.Marshal(map[string]interface{}{"base": base, "schedules": []map[string]interface{}{{"name": "A", "assignments": full}}})
+	rec := httptest.NewRecorder()
+	h.Compare(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/compare", bytes.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("单个方案 status = %d, want 400", rec.Code)
+	}
+}
+
+func postCompare(t *testing.T, h *handler.ScheduleHandler, body []byte) *handler.CompareResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.Compare(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/compare", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.CompareResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	return &resp
+}
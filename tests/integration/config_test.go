@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/config"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestGenerateServerDefaults 测试服务端配置的默认约束参数：请求未提供时生效，请求中的同名参数优先
+func TestGenerateServerDefaults(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+	h.SetDefaults(handler.GenerateDefaults{
+		OptimizationLevel: 1,
+		Constraints:       map[string]interface{}{"max_shifts_per_month": float64(1)},
+	})
+	shiftID := uuid.New().String()
+	generate := func(constraints map[string]interface{}) *handler.GenerateResponse {
+		t.Helper()
+		body, _ := json.Marshal(map[string]interface{}{
+			"org_id":     "6f0c3c2e-8f43-4a43-9d51-2f1b7f7c1a01",
+			"start_date": "2026-03-02",
+			"end_date":   "2026-03-04",
+			"employees":  []map[string]interface{}{{"id": uuid.New().String(), "name": "张三"}},
+			"shifts": []map[string]interface{}{
+				{"id": shiftID, "name": "早班", "code": "E", "start_time": "07:00", "end_time": "15:00", "duration": 480},
+			},
+			"requirements": []map[string]interface{}{
+				{"shift_id": shiftID, "date": "2026-03-02", "min_employees": 1},
+				{"shift_id": shiftID, "date": "2026-03-03", "min_employees": 1},
+				{"shift_id": shiftID, "date": "2026-03-04", "min_employees": 1},
+			},
+			"constraints": constraints,
+		})
+		rec := httptest.NewRecorder()
+		h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.GenerateResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		return &resp
+	}
+
+	if resp := generate(nil); len(resp.Assignments) != 1 {
+		t.Errorf("默认约束 max_shifts_per_month=1 应生效, assignments = %d", len(resp.Assignments))
+	}
+	if resp := generate(map[string]interface{}{"max_shifts_per_month": 3}); len(resp.Assignments) != 3 {
+		t.Errorf("请求中的约束参数应覆盖默认值, assignments = %d", len(resp.Assignments))
+	}
+}
+
+// TestAdminConfig 测试运行配置查询：仅管理员可查询，密钥脱敏
+func TestAdminConfig(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Password = "db-pass"
+	cfg.Auth.APIKeys = []config.APIKeyConfig{{Name: "pos", Key: "secret-key", OrgID: "*"}}
+	h := handler.NewConfigHandler(cfg)
+
+	rec := httptest.NewRecorder()
+	h.Status(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/config", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("非管理员应返回 403, status = %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/config", nil)
+	req.Header.Set(handler.RoleHeader, "admin")
+	rec = httptest.NewRecorder()
+	h.Status(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "db-pass") || strings.Contains(body, "secret-key") {
+		t.Errorf("响应不应包含密码和密钥: %s", body)
+	}
+	var resp struct {
+		Scheduler struct {
+			DefaultTimeout    string `json:"default_timeout"`
+			OptimizationLevel int    `json:"optimization_level"`
+		} `json:"scheduler"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if resp.Scheduler.DefaultTimeout != "30s" || resp.Scheduler.OptimizationLevel != 1 {
+		t.Errorf("scheduler = %+v", resp.Scheduler)
+	}
+}
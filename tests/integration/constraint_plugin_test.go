@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+)
+
+// noWeekendConstraint 示例插件约束：指定员工周末不排班
+type noWeekendConstraint struct {
+	*builtin.BaseConstraint
+	employee string
+}
+
+func (c *noWeekendConstraint) Evaluate(ctx *constraint.Context) (bool, int, []constraint.ViolationDetail) {
+	var violations []constraint.ViolationDetail
+	for _, a := range ctx.Assignments {
+		if ok, _ := c.EvaluateAssignment(ctx, a); !ok {
+			violations = append(violations, constraint.ViolationDetail{
+				ConstraintType: c.Type(), ConstraintName: c.Name(), EmployeeID: a.EmployeeID, Date: a.Date,
+				Message: "周末不排班", Severity: "error", Penalty: c.Weight(),
+			})
+		}
+	}
+	return len(violations) == 0, len(violations) * c.Weight(), violations
+}
+
+func (c *noWeekendConstraint) EvaluateAssignment(_ *constraint.Context, a *model.Assignment) (bool, int) {
+	day := a.StartTime.Weekday()
+	if a.EmployeeID.String() == c.employee && (day == time.Saturday || day == time.Sunday) {
+		return false, c.Weight()
+	}
+	return true, 0
+}
+
+func init() {
+	constraint.RegisterFactory("test_no_weekend", func(params map[string]interface{}) (constraint.Constraint, error) {
+		employee, _ := params["employee_id"].(string)
+		if employee == "" {
+			return nil, fmt.Errorf("缺少 employee_id")
+		}
+		return &noWeekendConstraint{
+			BaseConstraint: builtin.NewBaseConstraint("周末不排班", "test_no_weekend", constraint.CategoryHard, 100),
+			employee:       employee,
+		}, nil
+	})
+}
+
+// TestGenerateWithPluginConstraint 测试生成排班时按约束配置实例化插件约束
+func TestGenerateWithPluginConstraint(t *testing.T) {
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	shiftID, weekdayOnly, other := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	request := map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": "2026-01-16",
+		"end_date":   "2026-01-17",
+		"employees": []map[string]interface{}{
+			{"id": weekdayOnly, "name": "张三"},
+			{"id": other, "name": "李四"},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": shiftID, "date": "2026-01-16", "min_employees": 2},
+			{"shift_id": shiftID, "date": "2026-01-17", "min_employees": 2}, // 周六
+		},
+		"constraints": map[string]interface{}{
+			"test_no_weekend": map[string]interface{}{"employee_id": weekdayOnly},
+		},
+	}
+	rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	for _, a := range resp.Assignments {
+		if a.EmployeeID == weekdayOnly && a.Date == "2026-01-17" {
+			t.Errorf("插件约束应阻止周六排班: %+v", a)
+		}
+	}
+	if len(resp.Assignments) != 3 {
+		t.Errorf("assignments = %+v", resp.Assignments)
+	}
+
+	// 插件拒绝的参数返回 400
+	request["constraints"] = map[string]interface{}{"test_no_weekend": map[string]interface{}{}}
+	if rec = postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request); rec.Code != http.StatusBadRequest {
+		t.Errorf("无效插件配置应返回 400: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestLaborCost 测试人工成本计算：独立的统计接口及排班生成响应中的成本汇总
+func TestLaborCost(t *testing.T) {
+	empID := uuid.New().String()
+	body, _ := json.Marshal(map[string]interface{}{
+		"org_id": uuid.New().String(),
+		"employees": []map[string]interface{}{
+			{"id": empID, "name": "张三", "position": "厨师", "hourly_rate": 30},
+		},
+		// 2025-09-30 加班 2 小时，2025-10-01 国庆按 2 倍（统一节假日倍率）
+		"assignments": []map[string]interface{}{
+			{"employee_id": empID, "date": "2025-09-30", "start_time": "2025-09-30T08:00:00Z", "end_time": "2025-09-30T18:00:00Z"},
+			{"employee_id": empID, "date": "2025-10-01", "start_time": "2025-10-01T09:00:00Z", "end_time": "2025-10-01T13:00:00Z"},
+		},
+		"cost_config": map[string]interface{}{"overtime_pay_rate": 2, "holiday_bonus_rate": 2},
+	})
+	rec := httptest.NewRecorder()
+	handler.GetCostHandler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/stats/cost", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.CostResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	// 8×30 + 2×30×2 + 4×30×2
+	if s := resp.Data; s == nil || s.Cost != 600 || s.OvertimeHours != 2 || s.HolidayHours != 4 || len(s.ByDay) != 2 {
+		t.Fatalf("cost = %+v", resp.Data)
+	}
+
+	shiftID := uuid.New().String()
+	body, _ = json.Marshal(map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": "2026-01-16",
+		"end_date":   "2026-01-16",
+		"employees": []map[string]interface{}{
+			{"id": empID, "name": "张三", "position": "厨师", "hourly_rate": 30},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "早班", "code": "M", "start_time": "08:00", "end_time": "14:00", "duration": 360},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": shiftID, "date": "2026-01-16", "min_employees": 1},
+		},
+	})
+	rec = httptest.NewRecorder()
+	handler.NewScheduleHandlerWithoutDB().Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var generated handler.GenerateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &generated); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	cost := generated.CostSummary
+	if cost == nil || cost.Cost != 180 || len(cost.ByPosition) != 1 || cost.ByPosition[0].Position != "厨师" {
+		t.Errorf("cost_summary = %+v", cost)
+	}
+}
@@ -0,0 +1,55 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestGenerateUnfilledDiagnostics 测试未满足需求的诊断：说明员工被哪项筛选条件或硬约束排除
+// 两人分别排到夜班和次日早班后，夜班的第二个名额无人可排：一人当天已排班，另一人与次日早班间隔不足
+func TestGenerateUnfilledDiagnostics(t *testing.T) {
+	night, morning := uuid.New().String(), uuid.New().String()
+	body := map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": "2026-03-02",
+		"end_date":   "2026-03-03",
+		"employees": []map[string]interface{}{
+			{"id": uuid.New().String(), "name": "张三"},
+			{"id": uuid.New().String(), "name": "李四"},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": night, "name": "夜班", "code": "N", "start_time": "22:00", "end_time": "06:00", "duration": 480, "type": "night"},
+			{"id": morning, "name": "早班", "code": "M", "start_time": "07:00", "end_time": "15:00", "duration": 480, "type": "morning"},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": night, "date": "2026-03-02", "min_employees": 2, "priority": 5},
+			{"shift_id": morning, "date": "2026-03-03", "min_employees": 1, "priority": 1},
+		},
+	}
+
+	h := handler.NewScheduleHandlerWithoutDB()
+	rec := postJSON(t, h.Generate, "/api/v1/schedule/generate", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Unfilled) != 1 || resp.Unfilled[0].ShiftID != night {
+		t.Fatalf("unfilled = %s", rec.Body.String())
+	}
+	d := resp.Unfilled[0].Diagnostics
+	if d == nil || d.Eligible != 1 || d.Filtered["assigned_today"] != 1 || d.Available != 0 || len(d.Blocked) == 0 {
+		t.Fatalf("diagnostics = %+v", d)
+	}
+	if b := d.Blocked[0]; b.Constraint != "min_rest_between_shifts" || b.Count != 1 || len(b.Employees) != 1 {
+		t.Errorf("blocked = %+v", d.Blocked)
+	}
+	if !strings.Contains(d.Summary, "均被硬约束拦截") {
+		t.Errorf("summary = %s", d.Summary)
+	}
+}
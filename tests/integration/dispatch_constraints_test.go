@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/dispatcher/constraint"
+)
+
+// TestDispatchConstraintConfig 测试派单约束库、组织保存的派单约束配置，以及派单请求覆盖约束参数
+func TestDispatchConstraintConfig(t *testing.T) {
+	handler.SetDispatchStore(memstore.New(""))
+	t.Cleanup(func() { handler.SetDispatchStore(nil) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/dispatch/single", handler.DispatchHandler)
+	mux.HandleFunc("/api/v1/dispatch/constraints/library", handler.DispatchConstraintLibraryHandler)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/dispatch-constraints", handler.DispatchConstraintsHandler)
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set(handler.RoleHeader, "manager")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/v1/dispatch/constraints/library", nil)
+	var library constraint.LibraryResponse
+	json.Unmarshal(rec.Body.Bytes(), &library)
+	if rec.Code != http.StatusOK || len(library.Library) == 0 || library.Library[0].Name != "service_area_match" {
+		t.Fatalf("library status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	// 员工距离订单约 11km：默认最大服务距离 20km 可派
+	orgID := uuid.New().String()
+	request := map[string]interface{}{
+		"order": map[string]interface{}{
+			"org_id": orgID, "order_no": "ORD1", "service_date": "2026-01-12", "start_time": "09:00", "end_time": "11:00",
+			"location": map[string]interface{}{"latitude": 39.91, "longitude": 116.41},
+		},
+		"candidates": []map[string]interface{}{{
+			"id": uuid.New().String(), "name": "李阿姨", "status": "active",
+			"home_location": map[string]interface{}{"latitude": 40.01, "longitude": 116.41},
+		}},
+	}
+	dispatch := func() handler.DispatchAPIResponse {
+		t.Helper()
+		rec := do(http.MethodPost, "/api/v1/dispatch/single", request)
+		var resp handler.DispatchAPIResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+	if resp := dispatch(); !resp.Success {
+		t.Fatalf("默认约束应可派: %+v", resp)
+	}
+
+	// 组织配置最大服务距离 10km 后不可派
+	rec = do(http.MethodPut, "/api/v1/orgs/"+orgID+"/dispatch-constraints", map[string]interface{}{
+		"service_area_match": map[string]interface{}{"params": map[string]float64{"max_distance_km": 10}},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("put status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if resp := dispatch(); resp.Success {
+		t.Errorf("组织配置 10km 后不应可派: %+v", resp.Data)
+	}
+	rec = do(http.MethodGet, "/api/v1/orgs/"+orgID+"/dispatch-constraints", nil)
+	var saved handler.DispatchConstraintsResponse
+	json.Unmarshal(rec.Body.Bytes(), &saved)
+	if saved.Constraints["service_area_match"].Params["max_distance_km"] != 10 {
+		t.Errorf("saved = %+v", saved)
+	}
+
+	// 请求的 constraints 覆盖组织配置
+	request["constraints"] = map[string]interface{}{
+		"service_area_match": map[string]interface{}{"params": map[string]float64{"max_distance_km": 15}},
+	}
+	if resp := dispatch(); !resp.Success {
+		t.Errorf("请求覆盖为 15km 后应可派: %+v", resp.Data)
+	}
+
+	// 无效配置返回 400
+	request["constraints"] = map[string]interface{}{"unknown": map[string]interface{}{}}
+	if rec = do(http.MethodPost, "/api/v1/dispatch/single", request); rec.Code != http.StatusBadRequest {
+		t.Errorf("未知约束应返回 400: status=%d", rec.Code)
+	}
+	if rec = do(http.MethodPut, "/api/v1/orgs/"+orgID+"/dispatch-constraints", map[string]interface{}{
+		"max_orders_per_day": map[string]interface{}{"params": map[string]float64{"max_orders": 0}},
+	}); rec.Code != http.StatusBadRequest {
+		t.Errorf("参数超出范围应返回 400: status=%d", rec.Code)
+	}
+}
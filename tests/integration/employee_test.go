@@ -0,0 +1,192 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestEmployeeDirectory 测试员工档案的增删改查、批量导入，以及生成排班时使用员工档案
+func TestEmployeeDirectory(t *testing.T) {
+	store := memstore.New("")
+	employees := handler.NewEmployeeHandler(nil, store)
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/employees", employees.Collection)
+	mux.HandleFunc("/api/v1/employees/import", employees.Import)
+	mux.HandleFunc("/api/v1/employees/{employee_id}", employees.Item)
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	orgID := uuid.New().String()
+	rec := do(http.MethodPost, "/api/v1/employees", map[string]interface{}{
+		"org_id": orgID, "name": "张三", "code": "E001", "position": "cashier", "skills": []string{"收银"},
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var created model.Employee
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.Status != "active" || created.HireDate == "" {
+		t.Errorf("created = %+v", created)
+	}
+
+	// 工号重复返回 409，缺少必填字段或状态无效返回 400
+	if rec = do(http.MethodPost, "/api/v1/employees", map[string]interface{}{"org_id": orgID, "name": "李四", "code": "E001"}); rec.Code != http.StatusConflict {
+		t.Errorf("重复工号应返回 409: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	for _, body := range []map[string]interface{}{
+		{"org_id": orgID, "code": "E009"},
+		{"org_id": orgID, "name": "李四", "code": "E009", "status": "retired"},
+		{"org_id": orgID, "name": "李四", "code": "E009", "hire_date": "2026/01/01"},
+	} {
+		if rec = do(http.MethodPost, "/api/v1/employees", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%v 应返回 400: status=%d", body, rec.Code)
+		}
+	}
+
+	// 批量导入：已存在的工号更新，新工号新增，无效行单独报告
+	rec = do(http.MethodPost, "/api/v1/employees/import", map[string]interface{}{
+		"org_id": orgID,
+		"employees": []map[string]interface{}{
+			{"code": "E001", "phone": "13800000001"},
+			{"name": "李四", "code": "E002", "position": "cook"},
+			{"name": "王五", "code": "E003", "position": "cook", "status": "leave"},
+			{"name": "赵六"},
+		},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var imported handler.EmployeeImportResponse
+	json.Unmarshal(rec.Body.Bytes(), &imported)
+	if imported.Created != 2 || imported.Updated != 1 || imported.Failed != 1 || imported.Errors[0].Row != 4 {
+		t.Errorf("import = %+v", imported)
+	}
+
+	list := func(query string) handler.EmployeeListResponse {
+		t.Helper()
+		rec := do(http.MethodGet, "/api/v1/employees?org_id="+orgID+query, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.EmployeeListResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+	if got := list(""); got.Total != 3 || got.Employees[0].Code != "E001" || got.Employees[0].Phone != "13800000001" {
+		t.Errorf("list = %+v", got)
+	}
+	if got := list("&position=cook&status=active"); got.Total != 1 || got.Employees[0].Name != "李四" {
+		t.Errorf("按职位和状态过滤: %+v", got)
+	}
+	if got := list("&q=e00&offset=1&limit=1"); got.Total != 3 || len(got.Employees) != 1 || got.Employees[0].Code != "E002" {
+		t.Errorf("搜索分页: %+v", got)
+	}
+	if got := list("&skill=收银"); got.Total != 1 {
+		t.Errorf("按技能过滤: %+v", got)
+	}
+
+	// 生成排班未提供员工列表时使用组织的在职员工（请假中的员工不参与）
+	shiftID := uuid.New().String()
+	request := map[string]interface{}{
+		"org_id":     orgID,
+		"start_date": "2026-01-12",
+		"end_date":   "2026-01-12",
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "早班", "code": "M", "start_time": "08:00", "end_time": "12:00", "duration": 240},
+		},
+		"requirements": []map[string]interface{}{{"shift_id": shiftID, "date": "2026-01-12", "min_employees": 3}},
+	}
+	rec = postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var generated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if len(generated.Assignments) != 2 {
+		t.Errorf("应排入 2 名在职员工: assignments=%d", len(generated.Assignments))
+	}
+	if rec = do(http.MethodGet, "/api/v1/employees/"+created.ID.String(), nil); rec.Code != http.StatusOK {
+		t.Fatalf("get status = %d", rec.Code)
+	}
+	var stored model.Employee
+	json.Unmarshal(rec.Body.Bytes(), &stored)
+	if stored.Code != "E001" || stored.Phone != "13800000001" {
+		t.Errorf("生成排班不应覆盖员工档案: %+v", stored)
+	}
+
+	// 更新和删除
+	rec = do(http.MethodPut, "/api/v1/employees/"+created.ID.String(), map[string]interface{}{"code": "E002"})
+	if rec.Code != http.StatusConflict {
+		t.Errorf("更新为已有工号应返回 409: status=%d", rec.Code)
+	}
+	rec = do(http.MethodPut, "/api/v1/employees/"+created.ID.String(), map[string]interface{}{"status": "inactive"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if got := list("&status=active"); got.Total != 1 {
+		t.Errorf("更新后在职员工: %+v", got)
+	}
+	if rec = do(http.MethodDelete, "/api/v1/employees/"+created.ID.String(), nil); rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodGet, "/api/v1/employees/"+created.ID.String(), nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除后应返回 404: status=%d", rec.Code)
+	}
+}
+
+// TestEmployeeOrgAccess 测试组织受限的凭证不能读取、修改或删除其他组织的员工，列表只返回所属组织的员工
+func TestEmployeeOrgAccess(t *testing.T) {
+	store := memstore.New("")
+	employees := handler.NewEmployeeHandler(nil, store)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/employees", employees.Collection)
+	mux.HandleFunc("/api/v1/employees/{employee_id}", employees.Item)
+	orgA, orgB := uuid.New().String(), uuid.New().String()
+	do := orgScopedClient(t, orgA, mux)
+
+	var ids []string
+	for _, orgID := range []string{orgA, orgB} {
+		rec := do(http.MethodPost, "/api/v1/employees", "key-ops", map[string]interface{}{"org_id": orgID, "name": "张三", "code": "E001"})
+		var emp model.Employee
+		json.Unmarshal(rec.Body.Bytes(), &emp)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		ids = append(ids, emp.ID.String())
+	}
+	path := "/api/v1/employees/" + ids[1]
+	expectForbidden(t, do, http.MethodGet, path, nil)
+	expectForbidden(t, do, http.MethodPut, path, map[string]interface{}{"name": "李四"})
+	expectForbidden(t, do, http.MethodDelete, path, nil)
+	expectForbidden(t, do, http.MethodGet, "/api/v1/employees?org_id="+orgB, nil)
+	if emp, err := store.GetEmployee(uuid.MustParse(ids[1])); err != nil || emp.Name != "张三" {
+		t.Errorf("其他组织的员工不应被修改或删除: %+v, %v", emp, err)
+	}
+
+	var list handler.EmployeeListResponse
+	rec := do(http.MethodGet, "/api/v1/employees", "key-a", nil)
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.Total != 1 || list.Employees[0].ID.String() != ids[0] {
+		t.Errorf("未指定组织时应只列出所属组织的员工: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/employees/"+ids[0], "key-a", nil); rec.Code != http.StatusOK {
+		t.Errorf("所属组织的员工 status = %d", rec.Code)
+	}
+}
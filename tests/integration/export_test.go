@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+)
+
+// TestScheduleExport 测试按生成结果和已保存排班导出排班表网格
+func TestScheduleExport(t *testing.T) {
+	store := memstore.New("")
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+	export := handler.NewExportHandler(store, nil)
+
+	shiftID := uuid.New().String()
+	shifts := []map[string]interface{}{
+		{"id": shiftID, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480},
+	}
+	rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": "2026-01-12",
+		"end_date":   "2026-01-13",
+		"employees":  []map[string]interface{}{{"id": uuid.New().String(), "name": "张三"}},
+		"shifts":     shifts,
+		"requirements": []map[string]interface{}{
+			{"shift_id": shiftID, "date": "2026-01-12", "min_employees": 1},
+			{"shift_id": shiftID, "date": "2026-01-13", "min_employees": 2},
+		},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var generated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+
+	// 导出生成结果（CSV）
+	rec = postJSON(t, export.Export, "/api/v1/schedules/export", map[string]interface{}{
+		"format":      "csv",
+		"assignments": generated.Assignments,
+		"unfilled":    generated.Unfilled,
+		"shifts":      shifts,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %s", ct)
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(rec.Body.String(), "\uFEFF"))).ReadAll()
+	if err != nil {
+		t.Fatalf("解析 CSV 失败: %v", err)
+	}
+	want := [][]string{
+		{"员工", "岗位", "01-12 周一", "01-13 周二", "工时", "班次数"},
+		{"张三", "", "D", "D", "16", "2"},
+		{"合计工时", "", "8", "8", "16", ""},
+		{"未满足人次", "", "0", "1", "1", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("records = %v", records)
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("第 %d 行 = %v, want %v", i+1, records[i], want[i])
+		}
+	}
+
+	// 按排班ID导出已保存的排班（xlsx）
+	rec = postJSON(t, export.Export, "/api/v1/schedules/export", map[string]interface{}{"schedule_id": generated.ScheduleID})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != "attachment; filename=schedule-2026-01-12-2026-01-13.xlsx" {
+		t.Errorf("Content-Disposition = %s", cd)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len())); err != nil {
+		t.Errorf("xlsx 不是有效的 zip: %v", err)
+	}
+
+	// 不支持的格式、未知排班和空请求
+	cases := []struct {
+		body map[string]interface{}
+		code int
+	}{
+		{map[string]interface{}{"schedule_id": generated.ScheduleID, "format": "pdf"}, http.StatusBadRequest},
+		{map[string]interface{}{"schedule_id": uuid.New().String()}, http.StatusNotFound},
+		{map[string]interface{}{}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		if rec = postJSON(t, export.Export, "/api/v1/schedules/export", c.body); rec.Code != c.code {
+			t.Errorf("%v: status = %d, want %d", c.body, rec.Code, c.code)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestForecastRequirements 测试需求预测：按历史排班的星期规律生成下周需求
+func TestForecastRequirements(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+
+	shiftID := uuid.New().String()
+	employees := []string{uuid.New().String(), uuid.New().String(), uuid.New().String()}
+	// 2026-01-04 周日起 4 周：工作日 1 人，周末 3 人
+	var history []map[string]interface{}
+	start, _ := time.Parse("2006-01-02", "2026-01-04")
+	for d := start; d.Before(start.AddDate(0, 0, 28)); d = d.AddDate(0, 0, 1) {
+		staff := 1
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			staff = 3
+		}
+		for _, emp := range employees[:staff] {
+			history = append(history, map[string]interface{}{
+				"employee_id": emp, "shift_id": shiftID, "date": d.Format("2006-01-02"),
+			})
+		}
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"start_date": "2026-02-01",
+		"end_date":   "2026-02-07",
+		"history":    history,
+	})
+	rec := httptest.NewRecorder()
+	h.ForecastRequirements(rec, httptest.NewRequest(http.MethodPost, "/api/v1/requirements/forecast", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.ForecastRequirementsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(resp.Requirements) != 7 || len(resp.Suggestions) != 7 {
+		t.Fatalf("应生成 7 天的需求: %+v", resp.Requirements)
+	}
+	for _, r := range resp.Requirements {
+		want := 1
+		if r.Date == "2026-02-01" || r.Date == "2026-02-07" {
+			want = 3
+		}
+		if r.ShiftID != shiftID || r.MinEmployees != want {
+			t.Errorf("%s 需求 = %+v, want %d 人", r.Date, r, want)
+		}
+	}
+
+	// 业务量预测须提供人效
+	body, _ = json.Marshal(map[string]interface{}{
+		"start_date": "2026-02-01",
+		"end_date":   "2026-02-07",
+		"traffic":    []map[string]interface{}{{"shift_id": shiftID, "date": "2026-01-31", "value": 1200}},
+	})
+	rec = httptest.NewRecorder()
+	h.ForecastRequirements(rec, httptest.NewRequest(http.MethodPost, "/api/v1/requirements/forecast", bytes.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("缺少 productivity status = %d, want 400", rec.Code)
+	}
+}
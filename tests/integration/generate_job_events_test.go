@@ -0,0 +1,136 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/genjob"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestGenerateJobEvents 测试以 Server-Sent Events 推送异步生成作业的进度
+func TestGenerateJobEvents(t *testing.T) {
+	store := memstore.New("")
+	release := make(chan struct{})
+	runner := genjob.NewRunner(store, 1, func(ctx context.Context, job *model.GenerateJob, progress solver.ProgressHook) (json.RawMessage, string, error) {
+		<-release
+		progress(solver.Progress{Phase: solver.PhaseGreedy, Fraction: 0.5, Round: 1, Assignments: 3, Score: 50})
+		progress(solver.Progress{Phase: solver.PhaseGreedy, Fraction: 1, Round: 2, Assignments: 6, Score: 100})
+		return json.RawMessage(`{"success":true}`), "", nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runner.Run(ctx)
+
+	jobs := handler.NewGenerateJobHandler(store, runner)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedule/jobs/{id}/events", jobs.Events)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	job, err := runner.Submit(uuid.New(), json.RawMessage(`{}`), "")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	resp, err := http.Get(srv.URL + "/api/v1/schedule/jobs/" + job.ID.String() + "/events")
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %s", ct)
+	}
+
+	// 读取事件直到连接关闭
+	type event struct{ name, data string }
+	events := make(chan event)
+	go func() {
+		defer close(events)
+		var name string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				events <- event{name, strings.TrimPrefix(line, "data: ")}
+			}
+		}
+	}()
+	next := func() event {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("等待事件超时")
+			return event{}
+		}
+	}
+
+	if e := next(); e.name != "status" || !strings.Contains(e.data, `"status":"queued"`) && !strings.Contains(e.data, `"status":"running"`) {
+		t.Fatalf("首个事件 = %+v", e)
+	}
+	close(release)
+
+	var progress []solver.Progress
+	var done event
+	for e := next(); e.name != ""; e = next() {
+		if e.name == "done" {
+			done = e
+			break
+		}
+		var p solver.Progress
+		if err := json.Unmarshal([]byte(e.data), &p); err != nil || e.name != "progress" {
+			t.Fatalf("事件 = %+v", e)
+		}
+		progress = append(progress, p)
+	}
+	if len(progress) != 2 || progress[1].Round != 2 || progress[1].Assignments != 6 || progress[1].Score != 100 {
+		t.Errorf("progress = %+v", progress)
+	}
+	var final model.GenerateJob
+	if err := json.Unmarshal([]byte(done.data), &final); err != nil || final.Status != model.GenerateJobCompleted || final.Progress != 100 {
+		t.Errorf("done = %+v", done)
+	}
+	if _, ok := <-events; ok {
+		t.Error("作业结束后应关闭事件流")
+	}
+
+	// 已结束的作业立即发送 status 和 done
+	resp2, err := http.Get(srv.URL + "/api/v1/schedule/jobs/" + job.ID.String() + "/events")
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer resp2.Body.Close()
+	var names []string
+	scanner := bufio.NewScanner(resp2.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "event: ") {
+			names = append(names, strings.TrimPrefix(line, "event: "))
+		}
+	}
+	if strings.Join(names, ",") != "status,done" {
+		t.Errorf("已结束作业的事件 = %v", names)
+	}
+
+	// 不存在的作业
+	resp3, err := http.Get(srv.URL + "/api/v1/schedule/jobs/" + uuid.New().String() + "/events")
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	resp3.Body.Close()
+	if resp3.StatusCode != http.StatusNotFound {
+		t.Errorf("不存在的作业 status = %d", resp3.StatusCode)
+	}
+}
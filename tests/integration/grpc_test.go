@@ -0,0 +1,158 @@
+package integration
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	paibanv1 "github.com/paiban/paiban/api/proto/paiban/v1"
+	"github.com/paiban/paiban/internal/config"
+	"github.com/paiban/paiban/internal/grpcapi"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TestGRPCServer 测试 gRPC 服务复用 HTTP 处理器：流式生成推送进度后返回结果，认证和组织校验与 HTTP 接口相同，
+// 处理器的错误映射为 gRPC 状态码
+func TestGRPCServer(t *testing.T) {
+	orgID := uuid.New().String()
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedule/generate", schedules.Generate)
+	mux.HandleFunc("/api/v1/schedule/validate", schedules.Validate)
+	mux.HandleFunc("/api/v1/dispatch/single", handler.DispatchHandler)
+	mux.HandleFunc("/api/v1/dispatch/status", handler.EmployeeStatusHandler)
+	auth, err := middleware.APIAuth(&config.AuthConfig{Enabled: true, APIKeys: []config.APIKeyConfig{{Name: "java", Key: "key-a", OrgID: orgID}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpcapi.NewServer(auth(mux)).Register(server)
+	go server.Serve(lis)
+	defer server.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	schedule := paibanv1.NewScheduleServiceClient(conn)
+	dispatch := paibanv1.NewDispatchServiceClient(conn)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "key-a")
+
+	shiftID := uuid.New().String()
+	req := &paibanv1.GenerateRequest{
+		OrgId:     orgID,
+		StartDate: "2026-03-02",
+		EndDate:   "2026-03-04",
+		Shifts:    []*paibanv1.Shift{{Id: shiftID, Name: "白班", Code: "D", StartTime: "09:00", EndTime: "17:00", Duration: 480}},
+		Options:   &paibanv1.GenerateOptions{Seed: 42},
+	}
+	for i := 0; i < 4; i++ {
+		req.Employees = append(req.Employees, &paibanv1.Employee{Id: uuid.New().String(), Name: "员工" + string(rune('A'+i))})
+	}
+	for _, date := range []string{"2026-03-02", "2026-03-03", "2026-03-04"} {
+		req.Requirements = append(req.Requirements, &paibanv1.Requirement{ShiftId: shiftID, Date: date, MinEmployees: 2})
+	}
+
+	stream, err := schedule.GenerateStream(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress int
+	var result *paibanv1.GenerateResponse
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("GenerateStream: %v", err)
+		}
+		if result != nil {
+			t.Fatal("结果之后不应再有消息")
+		}
+		if p := event.GetProgress(); p != nil {
+			progress++
+			if p.Phase == "" || p.Fraction <= 0 {
+				t.Errorf("progress = %+v", p)
+			}
+		}
+		result = event.GetResult()
+	}
+	if progress == 0 || result == nil {
+		t.Fatalf("应先推送进度再返回结果: progress = %d, result = %v", progress, result)
+	}
+	if !result.Success || len(result.Assignments) != 6 || result.Seed != 42 || result.Statistics == nil {
+		t.Errorf("result = %+v", result)
+	}
+	if a := result.Assignments[0]; a.EmployeeName == "" || a.ShiftId != shiftID || a.Hours != 8 {
+		t.Errorf("assignment = %+v", a)
+	}
+
+	// 确定性模式：相同种子的一元调用与流式结果相同
+	unary, err := schedule.Generate(ctx, req)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	for i, a := range unary.Assignments {
+		if a.EmployeeId != result.Assignments[i].EmployeeId || a.Date != result.Assignments[i].Date {
+			t.Fatalf("相同种子的结果不同: %v / %v", a, result.Assignments[i])
+		}
+	}
+
+	// 认证、组织校验和请求校验与 HTTP 接口相同
+	if _, err := schedule.Generate(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("未提供密钥 err = %v", err)
+	}
+	other := &paibanv1.GenerateRequest{OrgId: uuid.New().String(), StartDate: req.StartDate, EndDate: req.EndDate, Employees: req.Employees, Shifts: req.Shifts, Requirements: req.Requirements}
+	if _, err := schedule.Generate(ctx, other); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("其他组织 err = %v", err)
+	}
+	if _, err := schedule.Generate(ctx, &paibanv1.GenerateRequest{OrgId: orgID}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("缺少日期 err = %v", err)
+	}
+
+	validate, err := schedule.Validate(ctx, &paibanv1.ValidateRequest{
+		OrgId:     orgID,
+		Employees: req.Employees,
+		Assignments: []*paibanv1.ValidateAssignment{
+			{EmployeeId: req.Employees[0].Id, ShiftId: shiftID, Date: "2026-03-02", StartTime: "09:00", EndTime: "17:00"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if _, ok := validate.Result.GetFields()["is_valid"]; !ok {
+		t.Errorf("validate = %v", validate.Result)
+	}
+
+	if _, err := dispatch.Single(ctx, &paibanv1.DispatchRequest{}); status.Code(err) != codes.InvalidArgument || status.Convert(err).Message() != "Order is required" {
+		t.Errorf("缺少订单 err = %v", err)
+	}
+	employeeID := uuid.New().String()
+	live, _ := structpb.NewStruct(map[string]interface{}{"employee_id": employeeID, "status": "on_duty"})
+	if _, err := dispatch.ReportStatus(ctx, &paibanv1.ReportStatusRequest{Status: live}); err != nil {
+		t.Fatalf("ReportStatus: %v", err)
+	}
+	statuses, err := dispatch.ListStatus(ctx, &paibanv1.ListStatusRequest{EmployeeId: employeeID})
+	if err != nil {
+		t.Fatalf("ListStatus: %v", err)
+	}
+	if data := statuses.Result.GetFields()["data"].GetListValue().GetValues(); len(data) != 1 {
+		t.Errorf("status = %v", statuses.Result)
+	}
+}
@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestHolidayCalendar 测试组织自定义节假日的增删改查及与内置法定节假日合并的日历
+func TestHolidayCalendar(t *testing.T) {
+	holidays := handler.NewHolidayHandler(memstore.New(""))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/holidays", holidays.Calendar)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/holidays", holidays.Collection)
+	mux.HandleFunc("/api/v1/orgs/{org_id}/holidays/{id}", holidays.Item)
+	do := func(method, path, role string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		if role != "" {
+			req.Header.Set(handler.RoleHeader, role)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	orgID := uuid.New()
+	base := "/api/v1/orgs/" + orgID.String() + "/holidays"
+	body := map[string]interface{}{"date": "2025-10-08", "name": "店内补休", "type": "workday"}
+
+	// 新增需管理者角色；日期格式错误返回 400
+	if rec := do(http.MethodPost, base, "", body); rec.Code != http.StatusForbidden {
+		t.Errorf("非管理者新增应返回 403: status=%d", rec.Code)
+	}
+	if rec := do(http.MethodPost, base, "manager", map[string]interface{}{"date": "2025/10/08", "name": "x"}); rec.Code != http.StatusBadRequest {
+		t.Errorf("日期格式错误应返回 400: status=%d", rec.Code)
+	}
+	rec := do(http.MethodPost, base, "manager", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var created model.Holiday
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.ID == uuid.Nil || created.OrgID != orgID {
+		t.Errorf("应分配ID并归属组织: %+v", created)
+	}
+	if rec := do(http.MethodPost, base, "manager", body); rec.Code != http.StatusConflict {
+		t.Errorf("同一天重复新增应返回 409: status=%d", rec.Code)
+	}
+	rec = do(http.MethodPost, base, "manager", map[string]interface{}{"date": "2025-06-18", "name": "店庆"})
+	var anniversary model.Holiday
+	json.Unmarshal(rec.Body.Bytes(), &anniversary)
+	if rec.Code != http.StatusCreated || anniversary.Type != model.HolidayCustom {
+		t.Errorf("未填写类型时应为 custom: status=%d type=%s", rec.Code, anniversary.Type)
+	}
+
+	rec = do(http.MethodGet, base+"?year=2025", "", nil)
+	var list handler.HolidayListResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.Total != 2 || list.Holidays[0].Date != "2025-06-18" {
+		t.Errorf("list status = %d, total = %d", rec.Code, list.Total)
+	}
+
+	// 合并日历：自定义的补休上班日覆盖内置的国庆休息日
+	rec = do(http.MethodGet, "/api/v1/holidays?year=2025&org_id="+orgID.String(), "", nil)
+	var calendar handler.HolidayCalendarResponse
+	json.Unmarshal(rec.Body.Bytes(), &calendar)
+	days := make(map[string]handler.CalendarDay)
+	for _, d := range calendar.Days {
+		days[d.Date] = d
+	}
+	if rec.Code != http.StatusOK || days["2025-10-01"].Rate != 3 || days["2025-10-01"].ID != nil {
+		t.Errorf("内置国庆节应为法定节假日: status=%d day=%+v", rec.Code, days["2025-10-01"])
+	}
+	if days["2025-10-08"].Off || days["2025-10-08"].Rate != 1 || days["2025-06-18"].ID == nil {
+		t.Errorf("自定义节假日应覆盖内置节假日: %+v %+v", days["2025-10-08"], days["2025-06-18"])
+	}
+
+	// 修改、查询、删除
+	item := base + "/" + anniversary.ID.String()
+	rec = do(http.MethodPut, item, "manager", map[string]interface{}{"date": "2025-06-18", "name": "十周年店庆", "premium_rate": 1.5})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodGet, item, "", nil)
+	var got model.Holiday
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if got.Name != "十周年店庆" || got.PremiumRate != 1.5 || !got.CreatedAt.Equal(anniversary.CreatedAt) {
+		t.Errorf("修改后应保留创建时间: %+v", got)
+	}
+	if rec := do(http.MethodGet, "/api/v1/orgs/"+uuid.NewString()+"/holidays/"+anniversary.ID.String(), "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("其他组织查询应返回 404: status=%d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, item, "manager", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, item, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除后查询应返回 404: status=%d", rec.Code)
+	}
+}
@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+)
+
+// TestHourlyStaffing 测试每小时最低在岗人数：生成排班时按分配时间统计每小时人数并报告缺人时段，覆盖率分析同样识别
+func TestHourlyStaffing(t *testing.T) {
+	a, b := uuid.New().String(), uuid.New().String()
+	morning, evening := uuid.New().String(), uuid.New().String()
+	date := "2026-03-02"
+	staffing := []map[string]interface{}{{"start": "10:00", "end": "22:00", "min_staff": 2}}
+	body := map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": date,
+		"end_date":   date,
+		"employees":  []map[string]interface{}{{"id": a, "name": "张三"}, {"id": b, "name": "李四"}},
+		"shifts": []map[string]interface{}{
+			{"id": morning, "name": "早班", "code": "M", "start_time": "08:00", "end_time": "16:00", "duration": 480},
+			{"id": evening, "name": "晚班", "code": "E", "start_time": "14:00", "end_time": "22:00", "duration": 480},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": morning, "date": date, "min_employees": 1},
+			{"shift_id": evening, "date": date, "min_employees": 1},
+		},
+		"constraints": map[string]interface{}{"hourly_staffing": staffing},
+	}
+
+	h := handler.NewScheduleHandlerWithoutDB()
+	rec := postJSON(t, h.Generate, "/api/v1/schedule/generate", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Assignments) != 2 || resp.Constraints == nil {
+		t.Fatalf("resp = %s", rec.Body.String())
+	}
+	// 早班和晚班只在 14:00-16:00 重叠，其余时段只有 1 人
+	var hourly []constraint.ViolationDetail
+	for _, v := range resp.Constraints.HardViolations {
+		if v.ConstraintType == constraint.TypeHourlyCoverage {
+			hourly = append(hourly, v)
+		}
+	}
+	if len(hourly) != 2 || hourly[0].Message != "2026-03-02 10:00-14:00 每小时在岗 1 人，少于要求的 2 人" {
+		t.Errorf("hourly violations = %+v", hourly)
+	}
+
+	body["constraints"] = map[string]interface{}{"hourly_staffing": []map[string]interface{}{{"start": "10:30", "end": "22:00", "min_staff": 2}}}
+	if rec := postJSON(t, h.Generate, "/api/v1/schedule/generate", body); rec.Code != http.StatusBadRequest {
+		t.Errorf("非整点时段应返回 400: status=%d", rec.Code)
+	}
+
+	// 覆盖率分析按同样的方式识别人手不足时段
+	coverage := map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": date,
+		"end_date":   date,
+		"assignments": []map[string]interface{}{
+			{"shift_id": morning, "employee_id": a, "date": date, "start_time": date + "T08:00:00Z", "end_time": date + "T16:00:00Z"},
+			{"shift_id": evening, "employee_id": b, "date": date, "start_time": date + "T14:00:00Z", "end_time": date + "T22:00:00Z"},
+		},
+		"hourly_staffing": staffing,
+	}
+	rec = postJSON(t, handler.GetCoverageHandler, "/api/v1/stats/coverage", coverage)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("coverage status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var cov handler.CoverageResponse
+	json.Unmarshal(rec.Body.Bytes(), &cov)
+	shortHours := 0
+	for _, p := range cov.Data.Understaffed {
+		if p.Date == date && p.Required == 2 && p.Assigned == 1 {
+			shortHours++
+		}
+	}
+	if shortHours != 10 {
+		t.Errorf("应识别 10 个缺人的小时: %+v", cov.Data.Understaffed)
+	}
+
+	coverage["hourly_staffing"] = []map[string]interface{}{{"start": "10:00", "end": "22:00"}}
+	if rec := postJSON(t, handler.GetCoverageHandler, "/api/v1/stats/coverage", coverage); rec.Code != http.StatusBadRequest {
+		t.Errorf("最少人数缺失应返回 400: status=%d", rec.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestGenerateWithJurisdiction 测试按合规辖区生成排班：规则包注册未成年工保护等硬约束，响应附带合规报告
+func TestGenerateWithJurisdiction(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+
+	rec := httptest.NewRecorder()
+	handler.ListJurisdictionsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/jurisdictions", nil))
+	var list handler.JurisdictionListResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.Total != 3 {
+		t.Fatalf("jurisdictions status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	minorID, adultID := uuid.New().String(), uuid.New().String()
+	shiftID := uuid.New().String()
+	request := map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": "2026-01-16",
+		"end_date":   "2026-01-18",
+		"employees": []map[string]interface{}{
+			{"id": minorID, "name": "小王", "status": "active", "birth_date": "2009-05-01"},
+			{"id": adultID, "name": "张三", "status": "active", "birth_date": "1990-03-12"},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "晚班", "code": "E", "start_time": "17:00", "end_time": "23:00", "duration": 360, "type": "evening"},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": shiftID, "date": "2026-01-16", "min_employees": 1},
+			{"shift_id": shiftID, "date": "2026-01-17", "min_employees": 1},
+			{"shift_id": shiftID, "date": "2026-01-18", "min_employees": 1},
+		},
+		"jurisdiction": "eu-working-time",
+	}
+	generate := func() *httptest.ResponseRecorder {
+		body, _ := json.Marshal(request)
+		rec := httptest.NewRecorder()
+		h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate", bytes.NewReader(body)))
+		return rec
+	}
+
+	rec = generate()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.GenerateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(resp.Assignments) != 3 {
+		t.Fatalf("assignments = %d, want 3", len(resp.Assignments))
+	}
+	for _, a := range resp.Assignments {
+		if a.EmployeeID == minorID {
+			t.Errorf("未成年工不应排 22:00 后的晚班: %+v", a)
+		}
+	}
+
+	report := resp.Compliance
+	if report == nil || report.Jurisdiction != "EU-working-time" || !report.Compliant {
+		t.Fatalf("应返回合规报告: %+v", report)
+	}
+	checked := make(map[string]bool)
+	for _, check := range report.Checks {
+		checked[check.Constraint] = check.Passed
+	}
+	if !checked["minor_protection"] || !checked["min_rest_between_shifts"] {
+		t.Errorf("合规报告应包含规则包的各项核查: %+v", report.Checks)
+	}
+
+	request["jurisdiction"] = "XX"
+	if rec := generate(); rec.Code != http.StatusBadRequest {
+		t.Errorf("未知辖区 status = %d, want 400", rec.Code)
+	}
+}
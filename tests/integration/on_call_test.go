@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestOnCall 测试待命安排：生成排班时单独返回待命分配且不计入未满足需求，公平性分析单独统计待命分布
+func TestOnCall(t *testing.T) {
+	a, b := uuid.New().String(), uuid.New().String()
+	day, standby := uuid.New().String(), uuid.New().String()
+	dates := []string{"2026-03-02", "2026-03-03"}
+	var requirements []map[string]interface{}
+	for _, date := range dates {
+		requirements = append(requirements,
+			map[string]interface{}{"shift_id": day, "date": date, "min_employees": 1},
+			map[string]interface{}{"shift_id": standby, "date": date, "min_employees": 1})
+	}
+	employees := []map[string]interface{}{{"id": a, "name": "张三"}, {"id": b, "name": "李四"}}
+	shifts := []map[string]interface{}{
+		{"id": day, "name": "白班", "code": "D", "start_time": "08:00", "end_time": "16:00", "duration": 480, "type": "morning"},
+		{"id": standby, "name": "待命", "code": "OC", "start_time": "18:00", "end_time": "08:00", "duration": 840, "type": "on_call"},
+	}
+	body := map[string]interface{}{
+		"org_id":       uuid.New().String(),
+		"start_date":   dates[0],
+		"end_date":     dates[1],
+		"employees":    employees,
+		"shifts":       shifts,
+		"requirements": requirements,
+	}
+
+	h := handler.NewScheduleHandlerWithoutDB()
+	rec := postJSON(t, h.Generate, "/api/v1/schedule/generate", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Assignments) != 2 || len(resp.OnCall) != 2 || len(resp.Unfilled) != 0 {
+		t.Fatalf("resp = %s", rec.Body.String())
+	}
+	for _, oc := range resp.OnCall {
+		if oc.ShiftID != standby || oc.ShiftName != "待命" || oc.Hours != 0 {
+			t.Errorf("on_call = %+v", oc)
+		}
+	}
+	// 两人轮流待命
+	if resp.OnCall[0].EmployeeID == resp.OnCall[1].EmployeeID {
+		t.Errorf("待命应轮流安排: %+v", resp.OnCall)
+	}
+	if s := resp.Statistics.OnCall; s == nil || s.FilledRequirements != 2 || resp.Statistics.TotalRequirements != 2 {
+		t.Errorf("statistics = %+v", resp.Statistics)
+	}
+
+	// 公平性分析：待命班次的分配单独统计
+	var assignments []map[string]interface{}
+	for _, x := range append(resp.Assignments, resp.OnCall...) {
+		assignments = append(assignments, map[string]interface{}{
+			"employee_id": x.EmployeeID, "shift_id": x.ShiftID, "date": x.Date,
+			"start_time": x.Date + "T" + x.StartTime + ":00Z", "end_time": x.Date + "T" + x.EndTime + ":00Z",
+		})
+	}
+	fairness := map[string]interface{}{
+		"employees":   employees,
+		"shifts":      []map[string]interface{}{{"id": day, "shift_type": "morning"}, {"id": standby, "shift_type": "on_call"}},
+		"assignments": assignments,
+	}
+	rec = postJSON(t, handler.GetFairnessHandler, "/api/v1/stats/fairness", fairness)
+	var fr handler.FairnessResponse
+	json.Unmarshal(rec.Body.Bytes(), &fr)
+	if rec.Code != http.StatusOK || fr.Data == nil || fr.Data.OnCall == nil {
+		t.Fatalf("fairness status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if fr.Data.OnCall.Total != 2 || fr.Data.OnCall.MaxPerEmp != 1 || fr.Data.OnCall.Gini != 0 {
+		t.Errorf("on_call = %+v", fr.Data.OnCall)
+	}
+	total := 0
+	for _, s := range fr.Data.EmployeeStats {
+		total += s.ShiftCount
+	}
+	if total != 2 {
+		t.Errorf("待命不应计入员工班次数: %+v", fr.Data.EmployeeStats)
+	}
+}
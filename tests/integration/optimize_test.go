@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestGenerateOptimizationPhase 测试 optimization_level=2 的优化阶段：统计中报告迭代次数和目标值改进，评分方案作为目标函数
+func TestGenerateOptimizationPhase(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+	early, late := uuid.New().String(), uuid.New().String()
+	var requirements []map[string]interface{}
+	for _, date := range []string{"2026-03-02", "2026-03-03"} {
+		requirements = append(requirements,
+			map[string]interface{}{"shift_id": early, "date": date, "min_employees": 1, "priority": 5},
+			map[string]interface{}{"shift_id": late, "date": date, "min_employees": 1, "priority": 1})
+	}
+	request := func(options map[string]interface{}) []byte {
+		body, _ := json.Marshal(map[string]interface{}{
+			"org_id":     uuid.New().String(),
+			"start_date": "2026-03-02",
+			"end_date":   "2026-03-03",
+			"employees": []map[string]interface{}{
+				{"id": uuid.New().String(), "name": "张三", "preferences": map[string]interface{}{"avoid_shifts": []string{"E"}}},
+				{"id": uuid.New().String(), "name": "李四"},
+			},
+			"shifts": []map[string]interface{}{
+				{"id": early, "name": "早班", "code": "E", "start_time": "07:00", "end_time": "15:00", "duration": 480},
+				{"id": late, "name": "晚班", "code": "L", "start_time": "13:00", "end_time": "21:00", "duration": 480},
+			},
+			"requirements": requirements,
+			"options":      options,
+		})
+		return body
+	}
+
+	for _, algorithm := range []string{"", "genetic"} {
+		rec := httptest.NewRecorder()
+		h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate",
+			bytes.NewReader(request(map[string]interface{}{"optimization_level": 2, "optimizer": algorithm}))))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.GenerateResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		stats := resp.Statistics
+		if stats.FillRate != 100 || stats.Optimizer == nil || stats.Optimizer.Iterations == 0 || stats.Optimization == nil {
+			t.Fatalf("%q 优化统计 = %+v", algorithm, stats)
+		}
+		if opt := stats.Optimization; !opt.Applied || opt.Improvement <= 0 || (algorithm != "" && opt.Algorithm != algorithm) {
+			t.Errorf("%q 优化结果 = %+v", algorithm, opt)
+		}
+		for _, a := range resp.Assignments {
+			if a.EmployeeName == "张三" && a.ShiftID == early {
+				t.Errorf("%q 张三不应排到早班: %s", algorithm, a.Date)
+			}
+		}
+	}
+
+	// 未启用优化时不报告优化统计
+	rec := httptest.NewRecorder()
+	h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate", bytes.NewReader(request(nil))))
+	var resp handler.GenerateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Statistics.Optimization != nil {
+		t.Errorf("默认不应执行优化阶段: %v %+v", err, resp.Statistics)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate",
+		bytes.NewReader(request(map[string]interface{}{"optimization_level": 2, "optimizer": "tabu"}))))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("未知优化算法 status = %d, want 400", rec.Code)
+	}
+
+	// 评分方案：未给出的目标权重为 1，统计中报告生效的权重和各目标子得分
+	rec = httptest.NewRecorder()
+	h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate",
+		bytes.NewReader(request(map[string]interface{}{"optimization_level": 2, "scoring_profile": map[string]float64{"fairness": 2, "cost": 0}}))))
+	resp = handler.GenerateResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if s := resp.Statistics.Scoring; s == nil || s.Profile.Fairness != 2 || s.Profile.Cost != 0 || s.Profile.Preference != 1 {
+		t.Errorf("scoring = %+v", s)
+	}
+
+	for _, profile := range []map[string]interface{}{{"speed": 1}, {"fairness": -1}} {
+		rec = httptest.NewRecorder()
+		h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate",
+			bytes.NewReader(request(map[string]interface{}{"optimization_level": 2, "scoring_profile": profile}))))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("评分方案 %v status = %d, want 400", profile, rec.Code)
+		}
+	}
+
+	// 评分方案只影响优化阶段，其余优化级别拒绝
+	for _, level := range []int{0, 1, 3} {
+		rec = httptest.NewRecorder()
+		h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate",
+			bytes.NewReader(request(map[string]interface{}{"optimization_level": level, "scoring_profile": map[string]float64{"fairness": 2}}))))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "optimization_level=2") {
+			t.Errorf("optimization_level=%d 配置评分方案 status = %d, body = %s", level, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/approval"
+	"github.com/paiban/paiban/internal/bulk"
+	"github.com/paiban/paiban/internal/config"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/hrsync"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/middleware"
+	"github.com/paiban/paiban/internal/publication"
+	"github.com/paiban/paiban/internal/summary"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestOrgScopedAccess 测试组织受限的凭证按ID访问排班时检查排班所属组织，列表只返回所属组织，管理接口只允许管理员
+func TestOrgScopedAccess(t *testing.T) {
+	store := memstore.New("")
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+	drafts := handler.NewDraftHandler(store)
+	records := handler.NewScheduleRecordHandler(nil, store, drafts)
+	publications := handler.NewPublicationHandler(store, publication.NewPublisher(store, nil))
+
+	generate := func(orgID string) string {
+		day := uuid.New().String()
+		rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", map[string]interface{}{
+			"org_id":       orgID,
+			"start_date":   "2026-02-02",
+			"end_date":     "2026-02-02",
+			"employees":    []map[string]interface{}{{"id": uuid.New().String(), "name": "张三"}},
+			"shifts":       []map[string]interface{}{{"id": day, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480}},
+			"requirements": []map[string]interface{}{{"shift_id": day, "date": "2026-02-02", "min_employees": 1}},
+		})
+		var resp handler.GenerateResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		return resp.ScheduleID
+	}
+	orgA, orgB := uuid.New().String(), uuid.New().String()
+	scheduleA, scheduleB := generate(orgA), generate(orgB)
+
+	cfg := config.Default()
+	cfg.Auth = config.AuthConfig{Enabled: true, APIKeys: []config.APIKeyConfig{
+		{Name: "org-a", Key: "key-a", OrgID: orgA},
+		{Name: "ops", Key: "key-ops", OrgID: middleware.AllOrgs},
+	}}
+	auth, err := middleware.APIAuth(&cfg.Auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedules", records.List)
+	mux.HandleFunc("/api/v1/schedules/{id}", records.Schedule)
+	mux.HandleFunc("/api/v1/schedules/{id}/assignments", records.Assignments)
+	mux.HandleFunc("/api/v1/schedules/{id}/versions", drafts.Versions)
+	mux.HandleFunc("/api/v1/schedules/{id}/publish", publications.Publish)
+	mux.HandleFunc("/api/v1/admin/config", handler.NewConfigHandler(cfg).Status)
+	server := auth(mux)
+	do := func(method, path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", key)
+		req.Header.Set("X-User-Role", "admin") // 客户端自带的角色被忽略
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var list handler.ScheduleListResponse
+	rec := do(http.MethodGet, "/api/v1/schedules", "key-a")
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.Total != 1 || list.Schedules[0].ID.String() != scheduleA {
+		t.Errorf("组织密钥只应列出所属组织的排班: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodGet, "/api/v1/schedules", "key-ops")
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list.Total != 2 {
+		t.Errorf("全部组织的密钥应列出全部排班: %s", rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/schedules?org_id="+orgB, "key-a"); rec.Code != http.StatusForbidden {
+		t.Errorf("指定其他组织 status = %d", rec.Code)
+	}
+
+	for _, tt := range []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/schedules/" + scheduleB},
+		{http.MethodGet, "/api/v1/schedules/" + scheduleB + "/assignments"},
+		{http.MethodGet, "/api/v1/schedules/" + scheduleB + "/versions"},
+		{http.MethodPost, "/api/v1/schedules/" + scheduleB + "/publish"},
+		{http.MethodDelete, "/api/v1/schedules/" + scheduleB},
+	} {
+		if rec := do(tt.method, tt.path, "key-a"); rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s status = %d, want 403", tt.method, tt.path, rec.Code)
+		}
+	}
+	if _, err := store.GetSchedule(uuid.MustParse(scheduleB)); err != nil {
+		t.Error("无权访问时不应删除其他组织的排班")
+	}
+	if rec := do(http.MethodGet, "/api/v1/schedules/"+scheduleA, "key-a"); rec.Code != http.StatusOK {
+		t.Errorf("所属组织的排班 status = %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/schedules/"+scheduleB+"/assignments", "key-ops"); rec.Code != http.StatusOK {
+		t.Errorf("全部组织的密钥 status = %d", rec.Code)
+	}
+
+	if rec := do(http.MethodGet, "/api/v1/admin/config", "key-a"); rec.Code != http.StatusForbidden {
+		t.Errorf("组织密钥访问管理接口 status = %d, want 403", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/config", "key-ops"); rec.Code != http.StatusOK {
+		t.Errorf("全部组织的密钥访问管理接口 status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+// orgScopedClient 返回经认证中间件访问 mux 的请求函数：密钥 key-a 为组织 orgA 的管理者，key-ops 可访问全部组织
+func orgScopedClient(t *testing.T, orgA string, mux *http.ServeMux) func(method, path, key string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	auth, err := middleware.APIAuth(&config.AuthConfig{Enabled: true, APIKeys: []config.APIKeyConfig{
+		{Name: "org-a", Key: "key-a", OrgID: orgA, Role: "manager"},
+		{Name: "ops", Key: "key-ops", OrgID: middleware.AllOrgs, Role: "admin"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := auth(mux)
+	return func(method, path, key string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec
+	}
+}
+
+// expectForbidden 检查组织受限的凭证访问其他组织的资源时返回 403
+func expectForbidden(t *testing.T, do func(method, path, key string, body interface{}) *httptest.ResponseRecorder, method, path string, body interface{}) {
+	t.Helper()
+	if rec := do(method, path, "key-a", body); rec.Code != http.StatusForbidden {
+		t.Errorf("%s %s status = %d, want 403, body = %s", method, path, rec.Code, rec.Body.String())
+	}
+}
+
+// TestApprovalOrgAccess 测试组织受限的凭证不能查看或审批其他组织的审批单
+func TestApprovalOrgAccess(t *testing.T) {
+	store := memstore.New("")
+	approvals := handler.NewApprovalHandler(store, approval.NewService(store, nil))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/orgs/{org_id}/approvals", approvals.Approvals)
+	mux.HandleFunc("/api/v1/approvals/{id}", approvals.Approval)
+	mux.HandleFunc("/api/v1/approvals/{id}/decision", approvals.Decide)
+	orgA, orgB := uuid.New().String(), uuid.New().String()
+	do := orgScopedClient(t, orgA, mux)
+
+	rec := do(http.MethodPost, "/api/v1/orgs/"+orgB+"/approvals", "key-ops", map[string]interface{}{"kind": "schedule", "title": "发布三月排班", "approver": "org-a"})
+	var submitted model.ApprovalRequest
+	json.Unmarshal(rec.Body.Bytes(), &submitted)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("submit status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	path := "/api/v1/approvals/" + submitted.ID.String()
+	expectForbidden(t, do, http.MethodGet, path, nil)
+	expectForbidden(t, do, http.MethodPost, path+"/decision", map[string]interface{}{"approve": true})
+	if a, err := store.GetApproval(submitted.ID); err != nil || a.Status != submitted.Status {
+		t.Errorf("其他组织的审批单不应被审批: %+v, %v", a, err)
+	}
+}
+
+// TestBulkJobOrgAccess 测试组织受限的凭证不能查看或控制其他组织的批量作业，列表只返回所属组织的作业
+func TestBulkJobOrgAccess(t *testing.T) {
+	store := memstore.New("")
+	runner := bulk.NewRunner(store, 0, 1)
+	handler.RegisterBulkProcessors(runner, handler.NewScheduleHandlerWithoutDB(), nil)
+	jobs := handler.NewBulkHandler(store, runner)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/bulk/jobs", jobs.Jobs)
+	mux.HandleFunc("/api/v1/bulk/jobs/{id}", jobs.Job)
+	mux.HandleFunc("/api/v1/bulk/jobs/{id}/results", jobs.Results)
+	mux.HandleFunc("/api/v1/bulk/jobs/{id}/cancel", jobs.Cancel)
+	orgA, orgB := uuid.New().String(), uuid.New().String()
+	do := orgScopedClient(t, orgA, mux)
+
+	rec := do(http.MethodPost, "/api/v1/bulk/jobs", "key-ops", map[string]interface{}{"org_id": orgB, "kind": model.BulkKindValidate, "rows": []map[string]interface{}{{}}})
+	var job model.BulkJob
+	json.Unmarshal(rec.Body.Bytes(), &job)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("submit status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	path := "/api/v1/bulk/jobs/" + job.ID.String()
+	expectForbidden(t, do, http.MethodGet, path, nil)
+	expectForbidden(t, do, http.MethodGet, path+"/results", nil)
+	expectForbidden(t, do, http.MethodPost, path+"/cancel", nil)
+	expectForbidden(t, do, http.MethodGet, "/api/v1/bulk/jobs?org_id="+orgB, nil)
+
+	var list []*model.BulkJob
+	rec = do(http.MethodGet, "/api/v1/bulk/jobs", "key-a", nil)
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || len(list) != 0 {
+		t.Errorf("未指定组织时应只列出所属组织的作业: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+// TestOrgResourceAccess 测试组织受限的凭证不能按ID访问其他组织的循环排班模板、月度汇总和争议、员工可用性、HR 同步映射和冲突，
+// 列表和报表未指定 org_id 时只返回所属组织的数据
+func TestOrgResourceAccess(t *testing.T) {
+	store := memstore.New("")
+	patterns := handler.NewPatternHandler(store)
+	summaries := handler.NewSummaryHandler(store, summary.NewService(store, nil))
+	analytics := handler.NewAnalyticsHandler(store)
+	hr := handler.NewHRSyncHandler(store, hrsync.NewSyncer(store, 10))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedule/patterns", patterns.Collection)
+	mux.HandleFunc("/api/v1/schedule/patterns/{id}", patterns.Item)
+	mux.HandleFunc("/api/v1/schedule/patterns/{id}/expand", patterns.Expand)
+	mux.HandleFunc("/api/v1/employees/{employee_id}/summaries/{month}", summaries.EmployeeSummary)
+	mux.HandleFunc("/api/v1/employees/{employee_id}/summaries/{month}/disputes", summaries.CreateDispute)
+	mux.HandleFunc("/api/v1/summary-disputes", summaries.ListDisputes)
+	mux.HandleFunc("/api/v1/summary-disputes/{id}/review", summaries.ReviewDispute)
+	mux.HandleFunc("/api/v1/summaries/{month}/deliver", summaries.Deliver)
+	mux.HandleFunc("/api/v1/employees/{employee_id}/availability", analytics.Availability)
+	mux.HandleFunc("/api/v1/analytics/skill-gap", analytics.SkillGap)
+	mux.HandleFunc("/api/v1/hrsync/sources/{source}/mapping", hr.Mapping)
+	mux.HandleFunc("/api/v1/hrsync/conflicts", hr.Conflicts)
+	mux.HandleFunc("/api/v1/hrsync/conflicts/{id}", hr.Conflicts)
+	mux.HandleFunc("/api/v1/hrsync/repairs", hr.Repairs)
+	orgA, orgB := uuid.New().String(), uuid.New().String()
+	do := orgScopedClient(t, orgA, mux)
+
+	// 循环排班模板
+	rec := do(http.MethodPost, "/api/v1/schedule/patterns", "key-ops", map[string]interface{}{
+		"org_id":  orgB,
+		"name":    "夜班固定",
+		"shifts":  []map[string]string{{"code": "N", "name": "夜班", "start_time": "22:00", "end_time": "06:00"}},
+		"entries": []map[string]interface{}{{"weekday": 1, "employee_id": uuid.New(), "shift_code": "N"}},
+	})
+	var pattern model.SchedulePattern
+	json.Unmarshal(rec.Body.Bytes(), &pattern)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create pattern status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	path := "/api/v1/schedule/patterns/" + pattern.ID.String()
+	expectForbidden(t, do, http.MethodGet, path, nil)
+	expectForbidden(t, do, http.MethodPut, path, map[string]interface{}{"org_id": orgA, "name": "改写", "shifts": []map[string]string{{"code": "N", "name": "夜班", "start_time": "22:00", "end_time": "06:00"}}})
+	expectForbidden(t, do, http.MethodDelete, path, nil)
+	expectForbidden(t, do, http.MethodPost, path+"/expand", map[string]interface{}{"start_date": "2026-03-02", "weeks": 1})
+	var patternList handler.PatternListResponse
+	rec = do(http.MethodGet, "/api/v1/schedule/patterns", "key-a", nil)
+	json.Unmarshal(rec.Body.Bytes(), &patternList)
+	if rec.Code != http.StatusOK || patternList.Total != 0 {
+		t.Errorf("未指定组织时应只列出所属组织的模板: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	// 月度汇总、争议和员工可用性
+	emp := &model.Employee{BaseModel: model.NewBaseModel(), OrgID: uuid.MustParse(orgB), Name: "张三", Status: "active"}
+	store.PutEmployee(emp)
+	dispute := &model.SummaryDispute{BaseModel: model.NewBaseModel(), OrgID: emp.OrgID, EmployeeID: emp.ID, Month: "2026-03", Reason: "漏记加班", Status: model.DisputePending}
+	store.PutDispute(dispute)
+	expectForbidden(t, do, http.MethodGet, "/api/v1/employees/"+emp.ID.String()+"/summaries/2026-03", nil)
+	expectForbidden(t, do, http.MethodPost, "/api/v1/employees/"+emp.ID.String()+"/summaries/2026-03/disputes", map[string]interface{}{"reason": "漏记加班"})
+	expectForbidden(t, do, http.MethodPost, "/api/v1/summary-disputes/"+dispute.ID.String()+"/review", map[string]interface{}{"approve": true})
+	expectForbidden(t, do, http.MethodPost, "/api/v1/summaries/2026-03/deliver", nil)
+	expectForbidden(t, do, http.MethodGet, "/api/v1/employees/"+emp.ID.String()+"/availability", nil)
+	expectForbidden(t, do, http.MethodPut, "/api/v1/employees/"+emp.ID.String()+"/availability", []map[string]interface{}{{"date": "2026-03-02", "type": "unavailable"}})
+	if d, _ := store.GetDispute(dispute.ID); d.Status != model.DisputePending {
+		t.Errorf("其他组织的争议不应被审核: %+v", d)
+	}
+	var disputes []*model.SummaryDispute
+	rec = do(http.MethodGet, "/api/v1/summary-disputes", "key-a", nil)
+	json.Unmarshal(rec.Body.Bytes(), &disputes)
+	if rec.Code != http.StatusOK || len(disputes) != 0 {
+		t.Errorf("未指定组织时应只列出所属组织的争议: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/analytics/skill-gap?start_date=2026-03-02&end_date=2026-03-08", "key-a", nil); rec.Code != http.StatusOK {
+		t.Errorf("未指定组织时应统计所属组织: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	// HR 同步映射和冲突
+	store.PutHRMapping(&model.HRMapping{Source: "hr-b", OrgID: emp.OrgID, IDField: "id", Fields: map[string]string{"name": "name"}})
+	conflict := &model.HRSyncConflict{ID: uuid.New(), Source: "hr-b", EventID: "e1", Reason: "unknown_position"}
+	store.AddHRConflict(conflict)
+	expectForbidden(t, do, http.MethodGet, "/api/v1/hrsync/sources/hr-b/mapping", nil)
+	expectForbidden(t, do, http.MethodPut, "/api/v1/hrsync/sources/hr-b/mapping", map[string]interface{}{"org_id": orgA, "id_field": "id", "fields": map[string]string{"name": "name"}})
+	expectForbidden(t, do, http.MethodDelete, "/api/v1/hrsync/conflicts/"+conflict.ID.String(), nil)
+	if mapping, _ := store.GetHRMapping("hr-b"); mapping.OrgID != emp.OrgID {
+		t.Errorf("其他组织的映射不应被改写: %+v", mapping)
+	}
+	var conflicts []*model.HRSyncConflict
+	rec = do(http.MethodGet, "/api/v1/hrsync/conflicts", "key-a", nil)
+	json.Unmarshal(rec.Body.Bytes(), &conflicts)
+	if rec.Code != http.StatusOK || len(conflicts) != 0 {
+		t.Errorf("不应列出其他组织来源的冲突: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/hrsync/conflicts", "key-ops", nil); !bytes.Contains(rec.Body.Bytes(), []byte(conflict.ID.String())) {
+		t.Errorf("全部组织的密钥应列出全部冲突: %s", rec.Body.String())
+	}
+}
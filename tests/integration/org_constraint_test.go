@@ -0,0 +1,165 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/constraints"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestOrgConstraintCRUDAndGenerateDefaults 测试组织级约束配置的增删改查，以及排班请求未提供 constraints 时加载组织默认约束
+func TestOrgConstraintCRUDAndGenerateDefaults(t *testing.T) {
+	store := memstore.New("")
+	constraintsHandler := handler.NewOrgConstraintHandler(nil, store, constraints.NewCatalog(nil))
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/constraints", constraintsHandler.Collection)
+	mux.HandleFunc("/api/v1/constraints/{id}", constraintsHandler.Item)
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set(handler.RoleHeader, "manager")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	orgID := uuid.New().String()
+	rec := do(http.MethodPost, "/api/v1/constraints", map[string]interface{}{
+		"org_id": orgID,
+		"name":   "max_hours_per_day",
+		"params": map[string]interface{}{"max_hours_per_day": 8},
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var created model.OrgConstraint
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if !created.Enabled || created.Category != "hard" || created.Weight == 0 {
+		t.Errorf("新增约束应默认启用并补充类别和权重: %+v", created)
+	}
+
+	// 同一组织约束名称重复返回 409，未知约束或不属于该约束的参数返回 400
+	if rec = do(http.MethodPost, "/api/v1/constraints", map[string]interface{}{"org_id": orgID, "name": "max_hours_per_day"}); rec.Code != http.StatusConflict {
+		t.Errorf("重复约束应返回 409: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodPost, "/api/v1/constraints", map[string]interface{}{"org_id": orgID, "name": "no_such_rule"}); rec.Code != http.StatusBadRequest {
+		t.Errorf("未知约束应返回 400: status=%d", rec.Code)
+	}
+	if rec = do(http.MethodPost, "/api/v1/constraints", map[string]interface{}{
+		"org_id": orgID, "name": "workload_balance", "params": map[string]interface{}{"max_hours_per_day": 8},
+	}); rec.Code != http.StatusBadRequest {
+		t.Errorf("不属于约束的参数应返回 400: status=%d", rec.Code)
+	}
+
+	// 组织默认约束：每天最多 8 小时，10 小时班次无法排班
+	shiftID, empID := uuid.New().String(), uuid.New().String()
+	request := map[string]interface{}{
+		"org_id":     orgID,
+		"start_date": "2026-01-12",
+		"end_date":   "2026-01-12",
+		"employees":  []map[string]interface{}{{"id": empID, "name": "张三"}},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "长白班", "code": "L", "start_time": "08:00", "end_time": "18:00", "duration": 600},
+		},
+		"requirements": []map[string]interface{}{{"shift_id": shiftID, "date": "2026-01-12", "min_employees": 1}},
+	}
+	generate := func() handler.GenerateResponse {
+		t.Helper()
+		rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.GenerateResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+	if resp := generate(); len(resp.Assignments) != 0 {
+		t.Errorf("组织默认约束应限制每天工时: %+v", resp.Assignments)
+	}
+
+	// 更新参数后按新配置排班
+	rec = do(http.MethodPut, "/api/v1/constraints/"+created.ID.String(), map[string]interface{}{
+		"params": map[string]interface{}{"max_hours_per_day": 12},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if resp := generate(); len(resp.Assignments) != 1 {
+		t.Errorf("放宽每天工时后应排班: %+v", resp.Assignments)
+	}
+
+	// 请求给出 constraints 时不使用组织默认约束
+	do(http.MethodPut, "/api/v1/constraints/"+created.ID.String(), map[string]interface{}{
+		"params": map[string]interface{}{"max_hours_per_day": 8},
+	})
+	request["constraints"] = map[string]interface{}{"max_hours_per_day": 12}
+	if resp := generate(); len(resp.Assignments) != 1 {
+		t.Errorf("请求约束应优先于组织默认约束: %+v", resp.Assignments)
+	}
+	delete(request, "constraints")
+
+	// 停用后不再作为默认约束
+	do(http.MethodPut, "/api/v1/constraints/"+created.ID.String(), map[string]interface{}{"enabled": false})
+	rec = do(http.MethodGet, "/api/v1/constraints?org_id="+orgID, nil)
+	var list handler.OrgConstraintListResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list.Total != 1 || list.Constraints[0].Enabled || len(list.Effective) != 0 {
+		t.Errorf("list = %+v", list)
+	}
+	if resp := generate(); len(resp.Assignments) != 1 {
+		t.Errorf("停用的约束不应生效: %+v", resp.Assignments)
+	}
+
+	if rec = do(http.MethodDelete, "/api/v1/constraints/"+created.ID.String(), nil); rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodGet, "/api/v1/constraints/"+created.ID.String(), nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除后应返回 404: status=%d", rec.Code)
+	}
+}
+
+// TestOrgConstraintOrgAccess 测试组织受限的凭证不能读取、修改或删除其他组织的约束配置
+func TestOrgConstraintOrgAccess(t *testing.T) {
+	store := memstore.New("")
+	constraintsHandler := handler.NewOrgConstraintHandler(nil, store, constraints.NewCatalog(nil))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/constraints", constraintsHandler.Collection)
+	mux.HandleFunc("/api/v1/constraints/{id}", constraintsHandler.Item)
+	orgA, orgB := uuid.New().String(), uuid.New().String()
+	do := orgScopedClient(t, orgA, mux)
+
+	rec := do(http.MethodPost, "/api/v1/constraints", "key-ops", map[string]interface{}{
+		"org_id": orgB, "name": "max_hours_per_day", "params": map[string]interface{}{"max_hours_per_day": 8},
+	})
+	var created model.OrgConstraint
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	path := "/api/v1/constraints/" + created.ID.String()
+	expectForbidden(t, do, http.MethodGet, path, nil)
+	expectForbidden(t, do, http.MethodPut, path, map[string]interface{}{"enabled": false})
+	expectForbidden(t, do, http.MethodDelete, path, nil)
+	expectForbidden(t, do, http.MethodGet, "/api/v1/constraints?org_id="+orgB, nil)
+	if c, err := store.GetOrgConstraint(created.ID); err != nil || !c.Enabled {
+		t.Errorf("其他组织的约束配置不应被修改或删除: %+v, %v", c, err)
+	}
+
+	var list handler.OrgConstraintListResponse
+	rec = do(http.MethodGet, "/api/v1/constraints", "key-a", nil)
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.OrgID != orgA || list.Total != 0 {
+		t.Errorf("未指定组织时应列出所属组织的配置: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
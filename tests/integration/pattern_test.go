@@ -0,0 +1,116 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/pattern"
+)
+
+// TestSchedulePatterns 测试保存循环排班模板并展开为未来若干周的排班
+func TestSchedulePatterns(t *testing.T) {
+	patterns := handler.NewPatternHandler(memstore.New(""))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedule/patterns", patterns.Collection)
+	mux.HandleFunc("/api/v1/schedule/patterns/{id}", patterns.Item)
+	mux.HandleFunc("/api/v1/schedule/patterns/{id}/expand", patterns.Expand)
+	do := func(method, path, role string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		if role != "" {
+			req.Header.Set(handler.RoleHeader, role)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	orgID, nightWorker := uuid.New(), uuid.New()
+	body := map[string]interface{}{
+		"org_id": orgID,
+		"name":   "夜班固定",
+		"shifts": []map[string]string{{"code": "N", "name": "夜班", "start_time": "22:00", "end_time": "06:00"}},
+		"entries": []map[string]interface{}{
+			{"weekday": 1, "employee_id": nightWorker, "shift_code": "N"},
+			{"weekday": 2, "employee_id": nightWorker, "shift_code": "N"},
+		},
+	}
+
+	// 新增需管理者角色；模板引用未定义的班次返回 400
+	if rec := do(http.MethodPost, "/api/v1/schedule/patterns", "", body); rec.Code != http.StatusForbidden {
+		t.Errorf("非管理者新增应返回 403: status=%d", rec.Code)
+	}
+	bad := map[string]interface{}{"org_id": orgID, "name": "x", "entries": []map[string]interface{}{{"weekday": 1, "employee_id": nightWorker, "shift_code": "D"}}}
+	if rec := do(http.MethodPost, "/api/v1/schedule/patterns", "manager", bad); rec.Code != http.StatusBadRequest {
+		t.Errorf("未定义班次应返回 400: status=%d", rec.Code)
+	}
+	rec := do(http.MethodPost, "/api/v1/schedule/patterns", "manager", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var created model.SchedulePattern
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.Weeks != 1 || created.Entries[0].Week != 1 || created.Shifts[0].ID == uuid.Nil {
+		t.Errorf("模板应补全默认值: %+v", created)
+	}
+
+	rec = do(http.MethodGet, "/api/v1/schedule/patterns?org_id="+orgID.String(), "", nil)
+	var list handler.PatternListResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.Total != 1 {
+		t.Errorf("list status = %d, total = %d", rec.Code, list.Total)
+	}
+
+	// 按周数展开三周：每周周一、周二各一个跨日夜班；周一夜班到周二 06:00，与周二 22:00 间隔 16 小时
+	rec = do(http.MethodPost, "/api/v1/schedule/patterns/"+created.ID.String()+"/expand", "", map[string]interface{}{
+		"start_date": "2026-03-02",
+		"weeks":      3,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expand status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var exp pattern.Expansion
+	json.Unmarshal(rec.Body.Bytes(), &exp)
+	if exp.EndDate != "2026-03-22" || len(exp.Assignments) != 6 || !exp.Valid {
+		t.Fatalf("expansion = %+v", exp)
+	}
+	if first := exp.Assignments[0]; first.Date != "2026-03-02" || first.EndTime.Day() != 3 {
+		t.Errorf("跨日夜班结束时间应顺延一天: %+v", first)
+	}
+
+	// 与已有排班重叠时报告冲突
+	rec = do(http.MethodPost, "/api/v1/schedule/patterns/"+created.ID.String()+"/expand", "", map[string]interface{}{
+		"start_date": "2026-03-02",
+		"end_date":   "2026-03-03",
+		"existing_assignments": []map[string]interface{}{{
+			"id": uuid.New(), "employee_id": nightWorker, "date": "2026-03-02",
+			"start_time": "2026-03-02T20:00:00Z", "end_time": "2026-03-03T02:00:00Z",
+		}},
+	})
+	exp = pattern.Expansion{}
+	json.Unmarshal(rec.Body.Bytes(), &exp)
+	if exp.Valid || len(exp.Conflicts) == 0 {
+		t.Errorf("与已有排班重叠应报告冲突: %+v", exp)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/schedule/patterns/"+created.ID.String()+"/expand", "", map[string]interface{}{"start_date": "2026-03-02"}); rec.Code != http.StatusBadRequest {
+		t.Errorf("缺少 end_date 和 weeks 应返回 400: status=%d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/api/v1/schedule/patterns/"+created.ID.String(), "manager", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("delete status = %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/schedule/patterns/"+created.ID.String(), "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除后应返回 404: status=%d", rec.Code)
+	}
+}
@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/repository"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// scheduleRepoStub 内存实现的排班仓储，模拟只配置数据库的部署
+type scheduleRepoStub struct {
+	schedules   map[uuid.UUID]*repository.Schedule
+	assignments []*repository.ScheduleAssignment
+}
+
+func (s *scheduleRepoStub) Create(ctx context.Context, schedule *repository.Schedule) error {
+	s.schedules[schedule.ID] = schedule
+	return nil
+}
+
+func (s *scheduleRepoStub) GetByID(ctx context.Context, id uuid.UUID) (*repository.Schedule, error) {
+	return s.schedules[id], nil
+}
+
+func (s *scheduleRepoStub) Update(ctx context.Context, schedule *repository.Schedule) error {
+	s.schedules[schedule.ID] = schedule
+	return nil
+}
+
+func (s *scheduleRepoStub) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(s.schedules, id)
+	return nil
+}
+
+func (s *scheduleRepoStub) List(ctx context.Context, filter repository.ListFilter) ([]*repository.Schedule, int, error) {
+	return nil, 0, nil
+}
+
+func (s *scheduleRepoStub) CreateAssignment(ctx context.Context, a *repository.ScheduleAssignment) error {
+	s.assignments = append(s.assignments, a)
+	return nil
+}
+
+func (s *scheduleRepoStub) CreateAssignments(ctx context.Context, scheduleID uuid.UUID, assignments []*model.Assignment) error {
+	return nil
+}
+
+func (s *scheduleRepoStub) UpdateAssignment(ctx context.Context, a *repository.ScheduleAssignment) error {
+	return nil
+}
+
+func (s *scheduleRepoStub) GetAssignments(ctx context.Context, scheduleID uuid.UUID) ([]*repository.ScheduleAssignment, error) {
+	return nil, nil
+}
+
+func (s *scheduleRepoStub) GetAssignmentsByEmployee(ctx context.Context, employeeID uuid.UUID, startDate, endDate string) ([]*repository.ScheduleAssignment, error) {
+	var result []*repository.ScheduleAssignment
+	for _, a := range s.assignments {
+		if a.EmployeeID == employeeID && (startDate == "" || a.Date >= startDate) && (endDate == "" || a.Date <= endDate) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (s *scheduleRepoStub) DeleteAssignments(ctx context.Context, scheduleID uuid.UUID) error {
+	return nil
+}
+
+func (s *scheduleRepoStub) GetLatestSchedule(ctx context.Context, orgID uuid.UUID, scenario string) (*repository.Schedule, error) {
+	return nil, nil
+}
+
+func (s *scheduleRepoStub) CountByDateRange(ctx context.Context, orgID uuid.UUID, startDate, endDate string) (int, error) {
+	return 0, nil
+}
+
+// TestPublicationDatabaseOnly 测试只配置数据库（未启用内存存储）时通过排班仓储发布、归档排班，员工只能看到已发布的排班
+func TestPublicationDatabaseOnly(t *testing.T) {
+	repo := &scheduleRepoStub{schedules: make(map[uuid.UUID]*repository.Schedule)}
+	employeeID := uuid.New()
+	newSchedule := func(date string, feasible bool) uuid.UUID {
+		id := uuid.New()
+		repo.Create(context.Background(), &repository.Schedule{ID: id, OrgID: uuid.New(), StartDate: date, EndDate: date, Status: "draft", Feasible: feasible})
+		repo.CreateAssignment(context.Background(), &repository.ScheduleAssignment{
+			ID: uuid.New(), ScheduleID: id, EmployeeID: employeeID, ShiftID: uuid.New(), ShiftName: "夜班",
+			Date: date, StartTime: "22:00", EndTime: "06:00", Status: "assigned",
+		})
+		return id
+	}
+	published, draft, infeasible := newSchedule("2026-03-02", true), newSchedule("2026-03-03", true), newSchedule("2026-03-04", false)
+
+	h := handler.NewPublicationHandler(nil, nil)
+	h.SetScheduleRepository(repo)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedules/{id}/publish", h.Publish)
+	mux.HandleFunc("/api/v1/schedules/{id}/archive", h.Archive)
+	mux.HandleFunc("/api/v1/employees/{employee_id}/schedule", h.EmployeeSchedule)
+	do := func(method, path, role string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(handler.AuthorHeader, "店长")
+		req.Header.Set(handler.RoleHeader, role)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/v1/schedules/"+published.String()+"/publish", "")
+	if rec.Code != http.StatusOK || repo.schedules[published].Status != "published" {
+		t.Fatalf("发布 status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if by := repo.schedules[published].Metadata["published_by"]; by != "店长" {
+		t.Errorf("published_by = %v", by)
+	}
+	if rec := do(http.MethodPost, "/api/v1/schedules/"+published.String()+"/publish", ""); rec.Code != http.StatusConflict {
+		t.Errorf("重复发布 status = %d, want 409", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/schedules/"+infeasible.String()+"/publish", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("违反硬约束的排班发布 status = %d, want 400", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/schedules/"+uuid.New().String()+"/publish", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("不存在的排班 status = %d, want 404", rec.Code)
+	}
+
+	visible := func(role string, preview bool) int {
+		path := "/api/v1/employees/" + employeeID.String() + "/schedule"
+		if preview {
+			path += "?preview=true"
+		}
+		rec := do(http.MethodGet, path, role)
+		var resp handler.EmployeeScheduleResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("员工排班 status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		return len(resp.Assignments)
+	}
+	if n := visible("", false); n != 1 {
+		t.Errorf("员工应只看到已发布排班的 1 个分配, got %d", n)
+	}
+	if n := visible("manager", true); n != 3 {
+		t.Errorf("管理者预览应包含草稿, got %d", n)
+	}
+
+	rec = do(http.MethodPost, "/api/v1/schedules/"+published.String()+"/archive", "")
+	if rec.Code != http.StatusOK || repo.schedules[published].Status != "archived" {
+		t.Fatalf("归档 status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/v1/schedules/"+published.String()+"/archive", ""); rec.Code != http.StatusConflict {
+		t.Errorf("重复归档 status = %d, want 409", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/schedules/"+draft.String()+"/archive", ""); rec.Code != http.StatusOK {
+		t.Errorf("草稿归档 status = %d", rec.Code)
+	}
+	if n := visible("", false); n != 0 {
+		t.Errorf("归档后员工不再可见, got %d", n)
+	}
+}
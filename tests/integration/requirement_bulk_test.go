@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+)
+
+// TestBulkEditRequirements 测试需求批量修改：周五加人、晚班推后，试算不保存排班，提交后保存需求
+func TestBulkEditRequirements(t *testing.T) {
+	store := memstore.New("")
+	h := handler.NewScheduleHandlerWithoutDB()
+	h.SetStore(store)
+
+	orgID := uuid.New()
+	shiftID := uuid.New().String()
+	request := map[string]interface{}{
+		"draft": map[string]interface{}{
+			"org_id":     orgID.String(),
+			"start_date": "2026-01-15",
+			"end_date":   "2026-01-16",
+			"employees": []map[string]interface{}{
+				{"id": uuid.New().String(), "name": "张三"},
+				{"id": uuid.New().String(), "name": "李四"},
+			},
+			"shifts": []map[string]interface{}{
+				{"id": shiftID, "name": "晚班", "code": "E", "start_time": "18:00", "end_time": "22:00", "duration": 240},
+			},
+			"requirements": []map[string]interface{}{
+				{"shift_id": shiftID, "date": "2026-01-15", "min_employees": 1},
+				{"shift_id": shiftID, "date": "2026-01-16", "min_employees": 1},
+			},
+		},
+		"edits": []map[string]interface{}{
+			{"weekdays": []int{5}, "min_employees_delta": 1},
+			{"weekdays": []int{5}, "shifts": []string{"E"}, "shift_minutes": 30},
+		},
+		"labor_rate": 20,
+		"commit":     true,
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/requirements/bulk", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.BulkEditRequirements(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var resp handler.BulkRequirementResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if resp.Edits[0].Matched != 1 || resp.Edits[1].Matched != 1 {
+		t.Errorf("matched = %+v, expected 1 each", resp.Edits)
+	}
+	if len(resp.Edits[1].DerivedShifts) != 1 || len(resp.Shifts) != 2 {
+		t.Fatalf("周四仍使用原晚班，应新建推后的班次: %+v", resp.Shifts)
+	}
+	derived := resp.Shifts[1]
+	if derived.StartTime != "18:30" || derived.EndTime != "22:30" || derived.Code != "E+30" {
+		t.Errorf("derived shift = %+v", derived)
+	}
+	friday := resp.Requirements[1]
+	if friday.MinEmployees != 2 || friday.ShiftID != derived.ID {
+		t.Errorf("friday requirement = %+v", friday)
+	}
+	if resp.Requirements[0].ShiftID != shiftID || resp.Requirements[0].MinEmployees != 1 {
+		t.Errorf("周四的需求不应被修改: %+v", resp.Requirements[0])
+	}
+
+	if resp.Before.Unfilled != 0 || resp.After.Unfilled != 0 || resp.After.FillRate != 100 {
+		t.Errorf("before = %+v, after = %+v", resp.Before, resp.After)
+	}
+	if resp.Delta.Assignments != 1 || resp.Delta.TotalHours != 4 || resp.Delta.LaborCost != 80 {
+		t.Errorf("delta = %+v", resp.Delta)
+	}
+
+	// 试算不保存排班，提交只保存班次和需求
+	if n := len(store.ListSchedules(orgID)); n != 0 {
+		t.Errorf("试算不应保存排班，实际 %d 个", n)
+	}
+	if !resp.Committed {
+		t.Fatal("expected committed")
+	}
+	saved := store.ListRequirements(orgID, "2026-01-15", "2026-01-16")
+	if len(saved) != 2 || saved[1].MinEmployees != 2 || saved[1].ShiftID.String() != derived.ID {
+		t.Errorf("saved requirements = %+v", saved)
+	}
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestRequirementSpec 测试需求简写：预览展开结果，并直接用简写生成排班
+func TestRequirementSpec(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+
+	shiftID := uuid.New().String()
+	shifts := []map[string]interface{}{
+		{"id": shiftID, "name": "早班", "code": "M", "start_time": "08:00", "end_time": "14:00", "duration": 360},
+	}
+	// 2026-01-16 周五、2026-01-17 周六
+	body, _ := json.Marshal(map[string]interface{}{
+		"start_date": "2026-01-16",
+		"end_date":   "2026-01-17",
+		"shifts":     shifts,
+		"spec":       "早班 weekdays 1人; 早班 周末 2人; 厨师≥1 every shift",
+	})
+	rec := httptest.NewRecorder()
+	h.ParseRequirementSpec(rec, httptest.NewRequest(http.MethodPost, "/api/v1/requirements/parse", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var preview handler.ParseRequirementSpecResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &preview); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	// 周五只需 1 名厨师，周六厨师 1 人 + 不限岗位 1 人
+	if len(preview.Requirements) != 3 || len(preview.Summary) != 2 {
+		t.Fatalf("preview = %+v", preview)
+	}
+	if s := preview.Summary[0]; s.Position != "厨师" || s.Days != 2 || s.MinEmployees != 2 {
+		t.Errorf("厨师汇总 = %+v", s)
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{
+		"start_date": "2026-01-16",
+		"end_date":   "2026-01-17",
+		"shifts":     shifts,
+		"spec":       "早班 weekdays 1人; 夜班 周末 2人",
+	})
+	rec = httptest.NewRecorder()
+	h.ParseRequirementSpec(rec, httptest.NewRequest(http.MethodPost, "/api/v1/requirements/parse", bytes.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("未知班次 status = %d, want 400", rec.Code)
+	}
+
+	body, _ = json.Marshal(map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": "2026-01-16",
+		"end_date":   "2026-01-17",
+		"employees": []map[string]interface{}{
+			{"id": uuid.New().String(), "name": "张三", "position": "厨师"},
+			{"id": uuid.New().String(), "name": "李四", "position": "厨师"},
+			{"id": uuid.New().String(), "name": "王五"},
+		},
+		"shifts":           shifts,
+		"requirement_spec": "早班 2人",
+	})
+	rec = httptest.NewRecorder()
+	h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.GenerateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(resp.Assignments) != 4 {
+		t.Errorf("assignments = %d, want 4", len(resp.Assignments))
+	}
+}
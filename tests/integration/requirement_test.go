@@ -0,0 +1,210 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestRequirementManagement 测试排班需求的增删改查、按周模式批量生成，以及生成排班时使用保存的需求
+func TestRequirementManagement(t *testing.T) {
+	store := memstore.New("")
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+	shifts := handler.NewShiftHandler(nil, store)
+	requirements := handler.NewRequirementHandler(nil, nil, store, schedules)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/shifts", shifts.Collection)
+	mux.HandleFunc("/api/v1/requirements", requirements.Collection)
+	mux.HandleFunc("/api/v1/requirements/bulk", requirements.Bulk)
+	mux.HandleFunc("/api/v1/requirements/{id}", requirements.Item)
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	orgID := uuid.New().String()
+	rec := do(http.MethodPost, "/api/v1/shifts", map[string]interface{}{
+		"org_id": orgID, "name": "午班", "code": "L", "start_time": "10:00", "end_time": "14:00",
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create shift status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var lunch model.Shift
+	json.Unmarshal(rec.Body.Bytes(), &lunch)
+
+	// 按周模式：2026-01-12（周一）至 2026-01-18（周日），工作日午班需要 3 名服务员，周末 2 名
+	bulk := map[string]interface{}{
+		"org_id": orgID, "start_date": "2026-01-12", "end_date": "2026-01-18",
+		"patterns": []map[string]interface{}{
+			{"weekdays": []int{1, 2, 3, 4, 5}, "shift_code": "L", "position": "server", "min_employees": 3},
+			{"weekdays": []int{0, 6}, "shift_code": "L", "position": "server", "min_employees": 2},
+		},
+	}
+	rec = do(http.MethodPost, "/api/v1/requirements/bulk", bulk)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("bulk status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var generated handler.RequirementBulkResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if generated.Created != 7 || generated.Updated != 0 {
+		t.Errorf("bulk = created %d updated %d", generated.Created, generated.Updated)
+	}
+
+	// 再次生成时更新已有需求而不是重复新增
+	bulk["patterns"] = []map[string]interface{}{
+		{"weekdays": []int{1}, "shift_id": lunch.ID.String(), "position": "server", "min_employees": 4},
+	}
+	rec = do(http.MethodPost, "/api/v1/requirements/bulk", bulk)
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if rec.Code != http.StatusOK || generated.Created != 0 || generated.Updated != 1 {
+		t.Errorf("再次生成应更新已有需求: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+
+	// 未知班次、无效星期返回 400
+	for _, pattern := range []map[string]interface{}{
+		{"shift_code": "X", "min_employees": 1},
+		{"weekdays": []int{7}, "shift_code": "L", "min_employees": 1},
+	} {
+		bulk["patterns"] = []map[string]interface{}{pattern}
+		if rec = do(http.MethodPost, "/api/v1/requirements/bulk", bulk); rec.Code != http.StatusBadRequest {
+			t.Errorf("%v 应返回 400: status=%d", pattern, rec.Code)
+		}
+	}
+
+	list := func(query string) handler.RequirementListResponse {
+		t.Helper()
+		rec := do(http.MethodGet, "/api/v1/requirements?org_id="+orgID+query, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.RequirementListResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+	got := list("")
+	if got.Total != 7 || got.Requirements[0].Date != "2026-01-12" || got.Requirements[0].MinEmployees != 4 {
+		t.Errorf("list = %+v", got)
+	}
+	mondayID := got.Requirements[0].ID
+	if got := list("&start_date=2026-01-17&limit=1"); got.Total != 2 || len(got.Requirements) != 1 || got.Requirements[0].MinEmployees != 2 {
+		t.Errorf("按日期过滤分页: %+v", got)
+	}
+
+	// 单条新增、更新和校验
+	rec = do(http.MethodPost, "/api/v1/requirements", map[string]interface{}{
+		"org_id": orgID, "shift_code": "L", "date": "2026-01-19", "position": "cook", "min_employees": 1,
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var created model.ShiftRequirement
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.ShiftID != lunch.ID || created.Priority != 5 {
+		t.Errorf("created = %+v", created)
+	}
+	if rec = do(http.MethodPut, "/api/v1/requirements/"+created.ID.String(), map[string]interface{}{"max_employees": 1, "min_employees": 2}); rec.Code != http.StatusBadRequest {
+		t.Errorf("最多人数少于最少人数应返回 400: status=%d", rec.Code)
+	}
+	if rec = do(http.MethodPut, "/api/v1/requirements/"+created.ID.String(), map[string]interface{}{"min_employees": 2}); rec.Code != http.StatusOK {
+		t.Errorf("update status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodDelete, "/api/v1/requirements/"+created.ID.String(), nil); rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodGet, "/api/v1/requirements/"+created.ID.String(), nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除后应返回 404: status=%d", rec.Code)
+	}
+
+	// 生成排班未提供需求时使用组织在排班周期内保存的需求
+	employees := make([]map[string]interface{}, 0, 4)
+	for _, name := range []string{"张三", "李四", "王五", "赵六"} {
+		employees = append(employees, map[string]interface{}{"id": uuid.New().String(), "name": name, "position": "server"})
+	}
+	request := map[string]interface{}{
+		"org_id":     orgID,
+		"start_date": "2026-01-12",
+		"end_date":   "2026-01-12",
+		"employees":  employees,
+	}
+	rec = postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var schedule handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &schedule)
+	if len(schedule.Assignments) != 4 {
+		t.Errorf("应按保存的需求排入 4 人: assignments=%d", len(schedule.Assignments))
+	}
+	if got := list(""); got.Total != 7 || got.Requirements[0].ID != mondayID {
+		t.Errorf("生成排班不应重复保存需求: %+v", got)
+	}
+
+	// replace 替换范围内的全部需求
+	bulk["patterns"] = []map[string]interface{}{{"shift_code": "L", "min_employees": 1}}
+	bulk["end_date"] = "2026-01-13"
+	bulk["replace"] = true
+	rec = do(http.MethodPost, "/api/v1/requirements/bulk", bulk)
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if rec.Code != http.StatusOK || generated.Deleted != 2 || generated.Created != 2 {
+		t.Errorf("replace: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if got := list(""); got.Total != 7 {
+		t.Errorf("replace 后: total=%d", got.Total)
+	}
+}
+
+// TestRequirementOrgAccess 测试组织受限的凭证不能读取、修改或删除其他组织的排班需求，列表只返回所属组织的需求
+func TestRequirementOrgAccess(t *testing.T) {
+	store := memstore.New("")
+	shifts := handler.NewShiftHandler(nil, store)
+	requirements := handler.NewRequirementHandler(nil, nil, store, handler.NewScheduleHandlerWithoutDB())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/shifts", shifts.Collection)
+	mux.HandleFunc("/api/v1/requirements", requirements.Collection)
+	mux.HandleFunc("/api/v1/requirements/{id}", requirements.Item)
+	orgA, orgB := uuid.New().String(), uuid.New().String()
+	do := orgScopedClient(t, orgA, mux)
+
+	var ids []string
+	for _, orgID := range []string{orgA, orgB} {
+		rec := do(http.MethodPost, "/api/v1/shifts", "key-ops", map[string]interface{}{"org_id": orgID, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00"})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create shift status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		rec = do(http.MethodPost, "/api/v1/requirements", "key-ops", map[string]interface{}{"org_id": orgID, "shift_code": "D", "date": "2026-01-12", "min_employees": 2})
+		var req model.ShiftRequirement
+		json.Unmarshal(rec.Body.Bytes(), &req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create requirement status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		ids = append(ids, req.ID.String())
+	}
+	path := "/api/v1/requirements/" + ids[1]
+	expectForbidden(t, do, http.MethodGet, path, nil)
+	expectForbidden(t, do, http.MethodPut, path, map[string]interface{}{"min_employees": 5})
+	expectForbidden(t, do, http.MethodDelete, path, nil)
+	expectForbidden(t, do, http.MethodGet, "/api/v1/requirements?org_id="+orgB, nil)
+	if req, err := store.GetRequirement(uuid.MustParse(ids[1])); err != nil || req.MinEmployees != 2 {
+		t.Errorf("其他组织的需求不应被修改或删除: %+v, %v", req, err)
+	}
+
+	var list handler.RequirementListResponse
+	rec := do(http.MethodGet, "/api/v1/requirements", "key-a", nil)
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.Total != 1 || list.Requirements[0].ID.String() != ids[0] {
+		t.Errorf("未指定组织时应只列出所属组织的需求: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,146 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/constraints"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestScenarioTemplateManagement 测试自定义场景模板的增删改、复制，以及生成排班按 scenario 解析模板
+func TestScenarioTemplateManagement(t *testing.T) {
+	store := memstore.New("")
+	templates := handler.NewScenarioTemplateHandler(nil, store, constraints.NewCatalog(nil))
+	orgConstraints := handler.NewOrgConstraintHandler(nil, store, constraints.NewCatalog(nil))
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/scenario-templates", templates.Collection)
+	mux.HandleFunc("/api/v1/scenario-templates/{id}", templates.Item)
+	mux.HandleFunc("/api/v1/scenario-templates/{id}/clone", templates.Clone)
+	mux.HandleFunc("/api/v1/constraints", orgConstraints.Collection)
+	do := func(method, path, role string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set(handler.RoleHeader, role)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	template := map[string]interface{}{
+		"scenario":    "short_day",
+		"name":        "短班门店",
+		"constraints": map[string]interface{}{"max_hours_per_day": 8},
+	}
+	if rec := do(http.MethodPost, "/api/v1/scenario-templates", "manager", template); rec.Code != http.StatusForbidden {
+		t.Errorf("非管理员新增模板应返回 403: status=%d", rec.Code)
+	}
+	rec := do(http.MethodPost, "/api/v1/scenario-templates", "admin", template)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var created model.ScenarioTemplate
+	json.Unmarshal(rec.Body.Bytes(), &created)
+
+	// 场景标识重复返回 409，内置场景标识或格式无效返回 400
+	if rec = do(http.MethodPost, "/api/v1/scenario-templates", "admin", template); rec.Code != http.StatusConflict {
+		t.Errorf("重复场景标识应返回 409: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	for _, scenario := range []string{"restaurant", "Bad-Key"} {
+		body := map[string]interface{}{"scenario": scenario, "name": "x"}
+		if rec = do(http.MethodPost, "/api/v1/scenario-templates", "admin", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("场景标识 %s 应返回 400: status=%d", scenario, rec.Code)
+		}
+	}
+
+	// 复制模板并修改约束
+	rec = do(http.MethodPost, "/api/v1/scenario-templates/"+created.ID.String()+"/clone", "admin", map[string]interface{}{
+		"scenario":    "long_day",
+		"constraints": map[string]interface{}{"max_hours_per_day": 12},
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("clone status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var cloned model.ScenarioTemplate
+	json.Unmarshal(rec.Body.Bytes(), &cloned)
+	if cloned.Name != "短班门店（副本）" || cloned.ID == created.ID {
+		t.Errorf("cloned = %+v", cloned)
+	}
+
+	rec = do(http.MethodGet, "/api/v1/scenario-templates", "", nil)
+	var list handler.ScenarioTemplateListResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list.Total != 2 || list.Templates[0].Scenario != "long_day" {
+		t.Errorf("list = %+v", list)
+	}
+
+	// 生成排班按 scenario 解析模板：每天最多 8 小时时 10 小时班次无法排班
+	orgID, shiftID := uuid.New().String(), uuid.New().String()
+	request := map[string]interface{}{
+		"org_id":     orgID,
+		"scenario":   "short_day",
+		"start_date": "2026-01-12",
+		"end_date":   "2026-01-12",
+		"employees":  []map[string]interface{}{{"id": uuid.New().String(), "name": "张三"}},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "长白班", "code": "L", "start_time": "08:00", "end_time": "18:00", "duration": 600},
+		},
+		"requirements": []map[string]interface{}{{"shift_id": shiftID, "date": "2026-01-12", "min_employees": 1}},
+	}
+	assigned := func() int {
+		t.Helper()
+		rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.GenerateResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return len(resp.Assignments)
+	}
+	if n := assigned(); n != 0 {
+		t.Errorf("short_day 模板应限制每天工时: assignments=%d", n)
+	}
+	request["scenario"] = "long_day"
+	if n := assigned(); n != 1 {
+		t.Errorf("long_day 模板应允许 10 小时班次: assignments=%d", n)
+	}
+
+	// 模板优先于组织默认约束，请求中的约束优先于模板
+	do(http.MethodPost, "/api/v1/constraints", "manager", map[string]interface{}{
+		"org_id": orgID, "name": "max_hours_per_day", "params": map[string]interface{}{"max_hours_per_day": 8},
+	})
+	if n := assigned(); n != 1 {
+		t.Errorf("模板应优先于组织默认约束: assignments=%d", n)
+	}
+	request["scenario"] = "short_day"
+	request["constraints"] = map[string]interface{}{"max_hours_per_day": 12}
+	if n := assigned(); n != 1 {
+		t.Errorf("请求约束应优先于模板: assignments=%d", n)
+	}
+
+	// 更新和删除
+	rec = do(http.MethodPut, "/api/v1/scenario-templates/"+created.ID.String(), "admin", map[string]interface{}{"scenario": "long_day"})
+	if rec.Code != http.StatusConflict {
+		t.Errorf("更新为已有场景标识应返回 409: status=%d", rec.Code)
+	}
+	rec = do(http.MethodPut, "/api/v1/scenario-templates/"+created.ID.String(), "admin", map[string]interface{}{"description": "早晚短班"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodDelete, "/api/v1/scenario-templates/"+created.ID.String(), "admin", nil); rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodGet, "/api/v1/scenario-templates/"+created.ID.String(), "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除后应返回 404: status=%d", rec.Code)
+	}
+}
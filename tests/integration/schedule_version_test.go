@@ -0,0 +1,219 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/publication"
+)
+
+// TestScheduleVersions 测试生成、修改、重新生成和发布各产生一个版本，并对比版本间的分配和指标变化
+func TestScheduleVersions(t *testing.T) {
+	store := memstore.New("")
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+	drafts := handler.NewDraftHandler(store)
+	publications := handler.NewPublicationHandler(store, publication.NewPublisher(store, nil))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedules/{id}/assignments/{assignment_id}", drafts.Assignment)
+	mux.HandleFunc("/api/v1/schedules/{id}/publish", publications.Publish)
+	mux.HandleFunc("/api/v1/schedules/{id}/versions", drafts.Versions)
+	mux.HandleFunc("/api/v1/schedules/{id}/diff", drafts.Diff)
+	get := func(path string, out interface{}) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		json.Unmarshal(rec.Body.Bytes(), out)
+		return rec.Code
+	}
+
+	orgID := uuid.New().String()
+	day := uuid.New().String()
+	request := map[string]interface{}{
+		"org_id":     orgID,
+		"start_date": "2026-02-02",
+		"end_date":   "2026-02-03",
+		"employees": []map[string]interface{}{
+			{"id": uuid.New().String(), "name": "张三"},
+			{"id": uuid.New().String(), "name": "李四"},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": day, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": day, "date": "2026-02-02", "min_employees": 1},
+			{"shift_id": day, "date": "2026-02-03", "min_employees": 1},
+		},
+	}
+	rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+	var generated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if rec.Code != http.StatusOK || len(generated.Assignments) != 2 {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	base := "/api/v1/schedules/" + generated.ScheduleID
+
+	// 删除一个分配（版本 2），再重新生成（版本 3）并发布（版本 4）
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, base+"/assignments/"+generated.Assignments[0].ID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	request["schedule_id"] = generated.ScheduleID
+	rec = postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+	var regenerated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &regenerated)
+	if rec.Code != http.StatusOK || regenerated.ScheduleID != generated.ScheduleID {
+		t.Fatalf("regenerate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	rec = postJSON(t, mux.ServeHTTP, base+"/publish", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("publish status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var list handler.ScheduleVersionListResponse
+	if code := get(base+"/versions", &list); code != http.StatusOK || list.Total != 4 || list.Current != 4 {
+		t.Fatalf("versions status = %d, list = %+v", code, list)
+	}
+	for i, reason := range []string{"generate", "edit", "regenerate", "publish"} {
+		if v := list.Versions[i]; v.Version != i+1 || v.Reason != reason {
+			t.Errorf("versions[%d] = %+v, expected version %d reason %s", i, v, i+1, reason)
+		}
+	}
+	if list.Versions[1].Assignments != 1 || list.Versions[3].Status != "published" {
+		t.Errorf("versions = %+v", list.Versions)
+	}
+
+	var diff handler.ScheduleDiffResponse
+	if code := get(base+"/diff?from=1&to=2", &diff); code != http.StatusOK || len(diff.Removed) != 1 || diff.Unchanged != 1 || diff.Delta.Filled != -1 {
+		t.Errorf("diff 1→2 status = %d, diff = %+v", code, diff)
+	}
+	diff = handler.ScheduleDiffResponse{}
+	if code := get(base+"/diff?from=2&to=3", &diff); code != http.StatusOK || diff.Delta.Filled != 1 || diff.After.FillRate != 100 {
+		t.Errorf("diff 2→3 status = %d, diff = %+v", code, diff)
+	}
+	// 不指定 to 时对比当前版本；发布不改变分配
+	diff = handler.ScheduleDiffResponse{}
+	if code := get(base+"/diff?from=3", &diff); code != http.StatusOK || diff.To != 4 || diff.Unchanged != 2 || len(diff.Added)+len(diff.Removed)+len(diff.Changed) != 0 {
+		t.Errorf("diff 3→current status = %d, diff = %+v", code, diff)
+	}
+
+	if code := get(base+"/diff?from=9", &diff); code != http.StatusNotFound {
+		t.Errorf("unknown version status = %d, expected 404", code)
+	}
+	if code := get(base+"/diff", &diff); code != http.StatusBadRequest {
+		t.Errorf("missing from status = %d, expected 400", code)
+	}
+}
+
+// TestScheduleRollback 测试已发布排班回滚到之前的版本：按当前员工状态复核恢复的分配，dry_run 不保存，基准版本过期时拒绝
+func TestScheduleRollback(t *testing.T) {
+	store := memstore.New("")
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+	drafts := handler.NewDraftHandler(store)
+	drafts.SetChecker(schedules.HardViolations)
+	publications := handler.NewPublicationHandler(store, publication.NewPublisher(store, nil))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/schedules/{id}/assignments/{assignment_id}", drafts.Assignment)
+	mux.HandleFunc("/api/v1/schedules/{id}/publish", publications.Publish)
+	mux.HandleFunc("/api/v1/schedules/{id}/versions", drafts.Versions)
+	mux.HandleFunc("/api/v1/schedules/{id}/rollback/{version}", drafts.Rollback)
+	rollback := func(path, ifMatch string) (*httptest.ResponseRecorder, handler.ScheduleRollbackResponse) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		var resp handler.ScheduleRollbackResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	day := uuid.New().String()
+	rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": "2026-02-09",
+		"end_date":   "2026-02-10",
+		"employees": []map[string]interface{}{
+			{"id": uuid.New().String(), "name": "张三"},
+			{"id": uuid.New().String(), "name": "李四"},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": day, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": day, "date": "2026-02-09", "min_employees": 1},
+			{"shift_id": day, "date": "2026-02-10", "min_employees": 1},
+		},
+	})
+	var generated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if rec.Code != http.StatusOK || len(generated.Assignments) != 2 {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	base := "/api/v1/schedules/" + generated.ScheduleID
+	removed := generated.Assignments[0]
+
+	// 版本 2 删除一个分配，版本 3 发布
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, base+"/assignments/"+removed.ID, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = postJSON(t, mux.ServeHTTP, base+"/publish", nil); rec.Code != http.StatusOK {
+		t.Fatalf("publish status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	// 被删除分配的员工已离职：恢复后该分配失效
+	employeeID, _ := uuid.Parse(removed.EmployeeID)
+	emp, err := store.GetEmployee(employeeID)
+	if err != nil {
+		t.Fatalf("GetEmployee() error = %v", err)
+	}
+	emp.Status = "inactive"
+	store.PutEmployee(emp)
+
+	rec, resp := rollback(base+"/rollback/1?dry_run=true", "")
+	if rec.Code != http.StatusOK || !resp.DryRun || len(resp.Schedule.Assignments) != 2 || len(resp.Changes) != 1 || resp.Changes[0].Op != "add" {
+		t.Fatalf("dry run status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if len(resp.Invalid) != 1 || resp.Invalid[0].Reason != handler.RollbackEmployeeInactive || resp.Invalid[0].AssignmentIDs[0].String() != removed.ID {
+		t.Errorf("invalid = %+v", resp.Invalid)
+	}
+	if current, _ := store.GetSchedule(resp.Schedule.ID); current.Version != 3 || len(current.Assignments) != 1 {
+		t.Errorf("dry run saved: version = %d, assignments = %d", current.Version, len(current.Assignments))
+	}
+
+	if rec, _ := rollback(base+"/rollback/1", `"2"`); rec.Code != http.StatusConflict {
+		t.Errorf("stale If-Match status = %d, expected 409", rec.Code)
+	}
+	if rec, _ := rollback(base+"/rollback/9", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown version status = %d, expected 404", rec.Code)
+	}
+
+	rec, resp = rollback(base+"/rollback/1", `"3"`)
+	if rec.Code != http.StatusOK || resp.Schedule.Version != 4 || resp.Schedule.Status != "published" || len(resp.Schedule.Assignments) != 2 || len(resp.Invalid) != 1 {
+		t.Fatalf("rollback status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if etag := rec.Header().Get("ETag"); etag != `"4"` {
+		t.Errorf("ETag = %s, expected \"4\"", etag)
+	}
+
+	var list handler.ScheduleVersionListResponse
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, base+"/versions", nil))
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list.Total != 4 || list.Versions[3].Reason != "rollback" || list.Versions[3].Assignments != 2 {
+		t.Errorf("versions = %+v", list.Versions)
+	}
+}
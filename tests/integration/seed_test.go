@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestGenerateDeterministicSeed 测试确定性模式：相同输入和种子总是生成相同的排班（含分配ID）
+func TestGenerateDeterministicSeed(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+	early, late := uuid.New().String(), uuid.New().String()
+	var employees []map[string]interface{}
+	for _, name := range []string{"张三", "李四", "王五", "赵六", "钱七"} {
+		employees = append(employees, map[string]interface{}{"id": uuid.New().String(), "name": name})
+	}
+	var requirements []map[string]interface{}
+	for _, date := range []string{"2026-03-02", "2026-03-03", "2026-03-04", "2026-03-05", "2026-03-06"} {
+		requirements = append(requirements,
+			map[string]interface{}{"shift_id": early, "date": date, "min_employees": 2},
+			map[string]interface{}{"shift_id": late, "date": date, "min_employees": 1})
+	}
+	generate := func(options map[string]interface{}) *handler.GenerateResponse {
+		t.Helper()
+		body, _ := json.Marshal(map[string]interface{}{
+			"org_id":     "6f0c3c2e-8f43-4a43-9d51-2f1b7f7c1a01",
+			"start_date": "2026-03-02",
+			"end_date":   "2026-03-06",
+			"employees":  employees,
+			"shifts": []map[string]interface{}{
+				{"id": early, "name": "早班", "code": "E", "start_time": "07:00", "end_time": "15:00", "duration": 480},
+				{"id": late, "name": "晚班", "code": "L", "start_time": "13:00", "end_time": "21:00", "duration": 480},
+			},
+			"requirements": requirements,
+			"options":      options,
+		})
+		rec := httptest.NewRecorder()
+		h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate", bytes.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.GenerateResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		return &resp
+	}
+
+	for _, level := range []int{1, 2, 3} {
+		options := map[string]interface{}{"optimization_level": level, "seed": 42}
+		first, second := generate(options), generate(options)
+		if first.Seed != 42 {
+			t.Errorf("level %d 响应应包含种子, seed = %d", level, first.Seed)
+		}
+		if len(first.Assignments) != 15 || !reflect.DeepEqual(first.Assignments, second.Assignments) {
+			t.Errorf("level %d 相同种子应生成相同的排班:\n%+v\n%+v", level, first.Assignments, second.Assignments)
+		}
+		if other := generate(map[string]interface{}{"optimization_level": level, "seed": 7}); other.Assignments[0].ID == first.Assignments[0].ID {
+			t.Errorf("level %d 不同种子的分配ID不应相同", level)
+		}
+	}
+
+	if resp := generate(nil); resp.Seed != 0 {
+		t.Errorf("未指定种子时不应返回 seed, got %d", resp.Seed)
+	}
+}
+
+// TestGenerateParallelWorkers 测试并行评估候选人：与逐个评估得到相同的排班，负数协程数返回 400
+func TestGenerateParallelWorkers(t *testing.T) {
+	h := handler.NewScheduleHandlerWithoutDB()
+	shift := uuid.New().String()
+	var employees []map[string]interface{}
+	for _, name := range []string{"张三", "李四", "王五", "赵六"} {
+		employees = append(employees, map[string]interface{}{"id": uuid.New().String(), "name": name})
+	}
+	generate := func(options map[string]interface{}) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]interface{}{
+			"org_id":     uuid.New().String(),
+			"start_date": "2026-03-02",
+			"end_date":   "2026-03-04",
+			"employees":  employees,
+			"shifts": []map[string]interface{}{
+				{"id": shift, "name": "早班", "code": "E", "start_time": "07:00", "end_time": "15:00", "duration": 480},
+			},
+			"requirements": []map[string]interface{}{
+				{"shift_id": shift, "date": "2026-03-02", "min_employees": 2},
+				{"shift_id": shift, "date": "2026-03-03", "min_employees": 2},
+				{"shift_id": shift, "date": "2026-03-04", "min_employees": 2},
+			},
+			"options": options,
+		})
+		rec := httptest.NewRecorder()
+		h.Generate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/schedule/generate", bytes.NewReader(body)))
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) []handler.AssignmentOutput {
+		t.Helper()
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.GenerateResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		return resp.Assignments
+	}
+
+	for _, level := range []int{1, 3} {
+		sequential := decode(generate(map[string]interface{}{"optimization_level": level, "seed": 3}))
+		parallel := decode(generate(map[string]interface{}{"optimization_level": level, "seed": 3, "workers": 4}))
+		if len(parallel) != 6 || !reflect.DeepEqual(sequential, parallel) {
+			t.Errorf("level %d 并行评估结果应与逐个评估相同:\n%+v\n%+v", level, sequential, parallel)
+		}
+	}
+
+	if rec := generate(map[string]interface{}{"workers": -1}); rec.Code != http.StatusBadRequest {
+		t.Errorf("负数协程数 status = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,169 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestShiftDefinitions 测试班次定义的增删改查，以及生成排班按编码引用班次
+func TestShiftDefinitions(t *testing.T) {
+	store := memstore.New("")
+	shifts := handler.NewShiftHandler(nil, store)
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/shifts", shifts.Collection)
+	mux.HandleFunc("/api/v1/shifts/{id}", shifts.Item)
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	orgID := uuid.New().String()
+	rec := do(http.MethodPost, "/api/v1/shifts", map[string]interface{}{
+		"org_id": orgID, "name": "晚班", "code": "N", "start_time": "22:00", "end_time": "06:00",
+		"break_time": 30, "shift_type": "night", "color": "#334455",
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var night model.Shift
+	json.Unmarshal(rec.Body.Bytes(), &night)
+	if night.Duration != 480 || !night.IsActive {
+		t.Errorf("跨天班次时长应为 480 分钟: %+v", night)
+	}
+	rec = do(http.MethodPost, "/api/v1/shifts", map[string]interface{}{
+		"org_id": orgID, "name": "早班", "code": "M", "start_time": "08:00", "end_time": "12:00",
+	})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var morning model.Shift
+	json.Unmarshal(rec.Body.Bytes(), &morning)
+
+	// 编码重复返回 409，时间格式或类型无效返回 400
+	if rec = do(http.MethodPost, "/api/v1/shifts", map[string]interface{}{
+		"org_id": orgID, "name": "早班2", "code": "M", "start_time": "08:00", "end_time": "12:00",
+	}); rec.Code != http.StatusConflict {
+		t.Errorf("重复编码应返回 409: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	for _, body := range []map[string]interface{}{
+		{"org_id": orgID, "name": "中班", "code": "A", "start_time": "8点", "end_time": "12:00"},
+		{"org_id": orgID, "name": "中班", "code": "A", "start_time": "12:00", "end_time": "18:00", "shift_type": "day"},
+		{"org_id": orgID, "name": "中班", "code": "A", "start_time": "12:00", "end_time": "18:00", "break_time": 360},
+	} {
+		if rec = do(http.MethodPost, "/api/v1/shifts", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%v 应返回 400: status=%d", body, rec.Code)
+		}
+	}
+
+	rec = do(http.MethodGet, "/api/v1/shifts?org_id="+orgID, nil)
+	var list handler.ShiftListResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list.Total != 2 || list.Shifts[0].Code != "M" {
+		t.Errorf("list = %+v", list)
+	}
+
+	// 生成排班：班次只给出编码，需求按 shift_code 引用
+	request := map[string]interface{}{
+		"org_id":       orgID,
+		"start_date":   "2026-01-12",
+		"end_date":     "2026-01-12",
+		"employees":    []map[string]interface{}{{"id": uuid.New().String(), "name": "张三"}},
+		"shifts":       []map[string]interface{}{{"code": "M"}},
+		"requirements": []map[string]interface{}{{"shift_code": "M", "date": "2026-01-12", "min_employees": 1}},
+	}
+	rec = postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var generated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if len(generated.Assignments) != 1 || generated.Assignments[0].ShiftID != morning.ID.String() ||
+		generated.Assignments[0].StartTime != "08:00" {
+		t.Errorf("应按编码使用已保存的班次: %+v", generated.Assignments)
+	}
+	request["shifts"] = []map[string]interface{}{{"code": "X"}}
+	if rec = postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request); rec.Code != http.StatusBadRequest {
+		t.Errorf("未定义的班次编码应返回 400: status=%d", rec.Code)
+	}
+
+	// 停用班次后，未提供班次列表的请求只使用启用的班次
+	rec = do(http.MethodPut, "/api/v1/shifts/"+morning.ID.String(), map[string]interface{}{"is_active": false})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	delete(request, "shifts")
+	request["requirements"] = []map[string]interface{}{{"shift_code": "M", "date": "2026-01-12", "min_employees": 1}}
+	if rec = postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request); rec.Code != http.StatusBadRequest {
+		t.Errorf("停用的班次不应被引用: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	request["requirements"] = []map[string]interface{}{{"shift_code": "N", "date": "2026-01-12", "min_employees": 1}}
+	if rec = postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request); rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodGet, "/api/v1/shifts/"+night.ID.String(), nil)
+	var stored model.Shift
+	json.Unmarshal(rec.Body.Bytes(), &stored)
+	if stored.BreakTime != 30 || stored.Color != "#334455" {
+		t.Errorf("生成排班不应覆盖班次定义: %+v", stored)
+	}
+
+	// 删除
+	if rec = do(http.MethodDelete, "/api/v1/shifts/"+night.ID.String(), nil); rec.Code != http.StatusOK {
+		t.Fatalf("delete status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodGet, "/api/v1/shifts/"+night.ID.String(), nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除后应返回 404: status=%d", rec.Code)
+	}
+}
+
+// TestShiftOrgAccess 测试组织受限的凭证不能读取、修改或删除其他组织的班次，列表只返回所属组织的班次
+func TestShiftOrgAccess(t *testing.T) {
+	store := memstore.New("")
+	shifts := handler.NewShiftHandler(nil, store)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/shifts", shifts.Collection)
+	mux.HandleFunc("/api/v1/shifts/{id}", shifts.Item)
+	orgA, orgB := uuid.New().String(), uuid.New().String()
+	do := orgScopedClient(t, orgA, mux)
+
+	var ids []string
+	for _, orgID := range []string{orgA, orgB} {
+		rec := do(http.MethodPost, "/api/v1/shifts", "key-ops", map[string]interface{}{"org_id": orgID, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00"})
+		var shift model.Shift
+		json.Unmarshal(rec.Body.Bytes(), &shift)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		ids = append(ids, shift.ID.String())
+	}
+	path := "/api/v1/shifts/" + ids[1]
+	expectForbidden(t, do, http.MethodGet, path, nil)
+	expectForbidden(t, do, http.MethodPut, path, map[string]interface{}{"name": "夜班"})
+	expectForbidden(t, do, http.MethodDelete, path, nil)
+	expectForbidden(t, do, http.MethodGet, "/api/v1/shifts?org_id="+orgB, nil)
+	if shift, err := store.GetShift(uuid.MustParse(ids[1])); err != nil || shift.Name != "白班" {
+		t.Errorf("其他组织的班次不应被修改或删除: %+v, %v", shift, err)
+	}
+
+	var list handler.ShiftListResponse
+	rec := do(http.MethodGet, "/api/v1/shifts", "key-a", nil)
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.Total != 1 || list.Shifts[0].ID.String() != ids[0] {
+		t.Errorf("未指定组织时应只列出所属组织的班次: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
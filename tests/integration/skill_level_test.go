@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+)
+
+// TestSkillLevelsAndCertificationExpiry 测试需求的技能等级要求，以及排班期间到期证书的提醒
+func TestSkillLevelsAndCertificationExpiry(t *testing.T) {
+	junior, senior := uuid.New().String(), uuid.New().String()
+	shiftID := uuid.New().String()
+	dates := []string{"2026-03-02", "2026-03-03", "2026-03-04"}
+	var requirements []map[string]interface{}
+	for _, date := range dates {
+		requirements = append(requirements, map[string]interface{}{
+			"shift_id": shiftID, "date": date, "min_employees": 1,
+			"skill_levels": []map[string]interface{}{{"name": "grill", "level": 3}},
+		})
+	}
+	body := map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": dates[0],
+		"end_date":   dates[len(dates)-1],
+		"employees": []map[string]interface{}{
+			// 只在 skills 中登记的技能按 1 级计，不满足 3 级要求
+			{"id": junior, "name": "张三", "skills": []string{"grill"}},
+			{"id": senior, "name": "李四", "skill_levels": []map[string]interface{}{{"name": "grill", "level": 4}},
+				"certification_records": []map[string]interface{}{{"name": "健康证", "expires_at": "2026-03-03"}}},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "早班", "code": "M", "start_time": "08:00", "end_time": "16:00", "duration": 480},
+		},
+		"requirements": requirements,
+	}
+
+	h := handler.NewScheduleHandlerWithoutDB()
+	rec := postJSON(t, h.Generate, "/api/v1/schedule/generate", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Assignments) != 3 {
+		t.Fatalf("assignments = %+v", resp.Assignments)
+	}
+	for _, a := range resp.Assignments {
+		if a.EmployeeID != senior {
+			t.Errorf("技能等级不足的员工不应被排班: %+v", a)
+		}
+	}
+	if w := resp.ExpiringCertifications; len(w) != 1 || w[0].EmployeeID != senior || w[0].ExpiresAt != "2026-03-03" || w[0].AssignmentsAfter != 1 {
+		t.Errorf("expiring_certifications = %+v", w)
+	}
+
+	// 证书有效期格式错误返回 400
+	body["employees"].([]map[string]interface{})[1]["certification_records"] = []map[string]interface{}{{"name": "健康证", "expires_at": "2026/03/03"}}
+	if rec := postJSON(t, h.Generate, "/api/v1/schedule/generate", body); rec.Code != http.StatusBadRequest {
+		t.Errorf("有效期格式错误应返回 400: status=%d", rec.Code)
+	}
+}
@@ -0,0 +1,137 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/swap"
+)
+
+// TestSwapEvaluateAndApply 测试换班：推荐替班候选，请假员工不可接班，可行的替班应用到排班并记录原员工
+func TestSwapEvaluateAndApply(t *testing.T) {
+	store := memstore.New("")
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+	h := handler.NewSwapHandler(store, schedules, handler.NewDraftHandler(store))
+
+	shiftID := uuid.New().String()
+	onLeave := uuid.New().String()
+	request := map[string]interface{}{
+		"org_id":     uuid.New().String(),
+		"start_date": "2026-01-15",
+		"end_date":   "2026-01-16",
+		"employees": []map[string]interface{}{
+			{"id": uuid.New().String(), "name": "张三"},
+			{"id": uuid.New().String(), "name": "李四"},
+			{"id": onLeave, "name": "王五", "leaves": []map[string]string{{"start_date": "2026-01-15", "end_date": "2026-01-16"}}},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": shiftID, "date": "2026-01-15", "min_employees": 1},
+		},
+	}
+	rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var generated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if len(generated.Assignments) != 1 {
+		t.Fatalf("assignments = %+v", generated.Assignments)
+	}
+	source := generated.Assignments[0]
+	target := ""
+	for _, e := range request["employees"].([]map[string]interface{}) {
+		if id := e["id"].(string); id != source.EmployeeID && id != onLeave {
+			target = id
+		}
+	}
+
+	// 替班候选：请假员工默认不列出，列出不可行员工时排在最后
+	rec = postJSON(t, h.Candidates, "/api/v1/swap/candidates", map[string]interface{}{
+		"schedule_id": generated.ScheduleID, "assignment_id": source.ID, "include_infeasible": true,
+	})
+	var candidates handler.SwapCandidatesResponse
+	json.Unmarshal(rec.Body.Bytes(), &candidates)
+	if rec.Code != http.StatusOK || len(candidates.Candidates) != 2 {
+		t.Fatalf("candidates status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	best, last := candidates.Candidates[0], candidates.Candidates[1]
+	if best.EmployeeID != target || !best.Feasible || best.Rank != 1 || best.HoursChange != 8 || len(best.Reasons) == 0 {
+		t.Errorf("best candidate = %+v", best)
+	}
+	if last.EmployeeID != onLeave || last.Feasible || len(last.Reasons) == 0 {
+		t.Errorf("请假员工应不可行并说明原因: %+v", last)
+	}
+	rec = postJSON(t, h.Candidates, "/api/v1/swap/candidates", map[string]interface{}{
+		"schedule_id": generated.ScheduleID, "assignment_id": source.ID,
+	})
+	json.Unmarshal(rec.Body.Bytes(), &candidates)
+	if len(candidates.Candidates) != 1 {
+		t.Errorf("默认只列出可行的候选: %+v", candidates.Candidates)
+	}
+
+	// 请假员工不可接班
+	leaveSwap := map[string]interface{}{
+		"schedule_id":          generated.ScheduleID,
+		"source_assignment_id": source.ID,
+		"target_employee_id":   onLeave,
+	}
+	rec = postJSON(t, h.Evaluate, "/api/v1/swap/evaluate", leaveSwap)
+	var eval swap.SwapEvaluation
+	json.Unmarshal(rec.Body.Bytes(), &eval)
+	if rec.Code != http.StatusOK || eval.Feasible {
+		t.Fatalf("请假员工接班应不可行: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+	if rec = postJSON(t, h.Apply, "/api/v1/swap/apply", leaveSwap); rec.Code != http.StatusBadRequest {
+		t.Errorf("不可行的换班应被拒绝: status=%d", rec.Code)
+	}
+
+	swapRequest := map[string]interface{}{
+		"schedule_id":          generated.ScheduleID,
+		"source_assignment_id": source.ID,
+		"target_employee_id":   target,
+		"base_version":         1,
+	}
+	rec = postJSON(t, h.Apply, "/api/v1/swap/apply", swapRequest)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("apply status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var applied handler.SwapApplyResponse
+	json.Unmarshal(rec.Body.Bytes(), &applied)
+	if !applied.Evaluation.Feasible || applied.Evaluation.Impact.TargetEmployeeImpact.HoursChange != 8 {
+		t.Errorf("evaluation = %+v", applied.Evaluation)
+	}
+	a := applied.Schedule.Assignments[0]
+	if a.EmployeeID.String() != target || !a.IsSwapped || a.OriginalEmpID == nil || a.OriginalEmpID.String() != source.EmployeeID {
+		t.Errorf("换班后的分配 = %+v", a)
+	}
+	if applied.Schedule.Version != 2 || rec.Header().Get("ETag") != `"2"` {
+		t.Errorf("version = %d", applied.Schedule.Version)
+	}
+
+	// 基于旧版本换回原员工返回版本冲突
+	swapRequest["target_employee_id"] = source.EmployeeID
+	if rec = postJSON(t, h.Apply, "/api/v1/swap/apply", swapRequest); rec.Code != http.StatusConflict {
+		t.Errorf("旧版本应返回 409: status=%d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func postJSON(t *testing.T, handle http.HandlerFunc, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	handle(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data)))
+	return rec
+}
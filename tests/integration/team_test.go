@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/pkg/model"
+)
+
+// TestTeams 测试班组的保存和查询，以及生成排班时按班组整组安排到同一班次
+func TestTeams(t *testing.T) {
+	store := memstore.New("")
+	teams := handler.NewTeamHandler(nil, store)
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/orgs/{org_id}/teams", teams.Teams)
+	do := func(method, path, role string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		if role != "" {
+			req.Header.Set(handler.RoleHeader, role)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	orgID := uuid.New()
+	// 不考虑班组时求解器只按工作量选人，两个班组会被拆散
+	a1, b1, a2, b2 := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	morning, afternoon := uuid.New(), uuid.New()
+	dates := []string{"2026-03-02", "2026-03-03", "2026-03-04"}
+	request := func(teams interface{}) map[string]interface{} {
+		var requirements []map[string]interface{}
+		for _, date := range dates {
+			for _, shiftID := range []uuid.UUID{morning, afternoon} {
+				requirements = append(requirements, map[string]interface{}{"shift_id": shiftID, "date": date, "min_employees": 2})
+			}
+		}
+		req := map[string]interface{}{
+			"org_id":     orgID,
+			"start_date": dates[0],
+			"end_date":   dates[len(dates)-1],
+			"employees": []map[string]interface{}{
+				{"id": a1, "name": "甲1"}, {"id": a2, "name": "甲2"}, {"id": b1, "name": "乙1"}, {"id": b2, "name": "乙2"},
+			},
+			"shifts": []map[string]interface{}{
+				{"id": morning, "name": "早班", "code": "M", "start_time": "08:00", "end_time": "12:00", "duration": 240},
+				{"id": afternoon, "name": "午班", "code": "A", "start_time": "13:00", "end_time": "17:00", "duration": 240},
+			},
+			"requirements": requirements,
+		}
+		if teams != nil {
+			req["teams"] = teams
+		}
+		return req
+	}
+	generate := func(body map[string]interface{}) *handler.GenerateResponse {
+		t.Helper()
+		rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("generate status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp handler.GenerateResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return &resp
+	}
+	// together 返回两个班组在每天是否都整组安排在同一班次
+	together := func(resp *handler.GenerateResponse) bool {
+		shiftOf := make(map[string]string)
+		for _, a := range resp.Assignments {
+			shiftOf[a.Date+a.EmployeeID] = a.ShiftID
+		}
+		for _, date := range dates {
+			if shiftOf[date+a1.String()] != shiftOf[date+a2.String()] || shiftOf[date+b1.String()] != shiftOf[date+b2.String()] {
+				return false
+			}
+		}
+		return len(resp.Assignments) == 12
+	}
+
+	if together(generate(request(nil))) {
+		t.Fatal("未配置班组时按员工顺序应拆散班组，测试数据无效")
+	}
+
+	// 请求中的班组：成员不在员工列表或同一员工属于多个班组时返回 400
+	body := request([]map[string]interface{}{
+		{"id": "A", "name": "甲班", "members": []uuid.UUID{a1, a2}},
+		{"id": "B", "name": "乙班", "members": []uuid.UUID{b1, uuid.New()}},
+	})
+	if rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", body); rec.Code != http.StatusBadRequest {
+		t.Errorf("成员不在员工列表应返回 400: status=%d", rec.Code)
+	}
+	body = request([]map[string]interface{}{
+		{"id": "A", "members": []uuid.UUID{a1, a2}},
+		{"id": "B", "members": []uuid.UUID{a2, b2}},
+	})
+	if rec := postJSON(t, schedules.Generate, "/api/v1/schedule/generate", body); rec.Code != http.StatusBadRequest {
+		t.Errorf("员工属于多个班组应返回 400: status=%d", rec.Code)
+	}
+	resp := generate(request([]map[string]interface{}{
+		{"id": "A", "name": "甲班", "members": []uuid.UUID{a1, a2}},
+		{"id": "B", "name": "乙班", "members": []uuid.UUID{b1, b2}, "cohesion": "required"},
+	}))
+	if !together(resp) {
+		t.Errorf("配置班组后应整组安排到同一班次: %+v", resp.Assignments)
+	}
+
+	// 组织班组：保存需管理者，排班请求未提供 teams 时使用
+	base := "/api/v1/orgs/" + orgID.String() + "/teams"
+	saved := []map[string]interface{}{
+		{"id": "A", "name": "甲班", "members": []uuid.UUID{a1, a2}, "cohesion": "required"},
+		{"id": "B", "name": "乙班", "members": []uuid.UUID{b1, b2}},
+	}
+	if rec := do(http.MethodPut, base, "", saved); rec.Code != http.StatusForbidden {
+		t.Errorf("非管理者保存应返回 403: status=%d", rec.Code)
+	}
+	if rec := do(http.MethodPut, base, "manager", []map[string]interface{}{{"id": "A", "members": []uuid.UUID{a1}, "cohesion": "always"}}); rec.Code != http.StatusBadRequest {
+		t.Errorf("cohesion 无效应返回 400: status=%d", rec.Code)
+	}
+	if rec := do(http.MethodPut, base, "manager", saved); rec.Code != http.StatusOK {
+		t.Fatalf("put status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	rec := do(http.MethodGet, base, "", nil)
+	var list []model.Team
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if len(list) != 2 || list[0].ID != "A" || !list[0].IsRequired() || list[1].Cohesion != model.TeamCohesionPreferred || list[1].OrgID != orgID {
+		t.Fatalf("teams = %+v", list)
+	}
+	if resp := generate(request(nil)); !together(resp) {
+		t.Errorf("应使用组织保存的班组: %+v", resp.Assignments)
+	}
+}
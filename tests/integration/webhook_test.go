@@ -0,0 +1,213 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/internal/handler"
+	"github.com/paiban/paiban/internal/memstore"
+	"github.com/paiban/paiban/internal/notify"
+)
+
+// TestWebhookNotifications 测试通知订阅：CRUD 校验，生成缺员、换班通过和分配修改时投递签名的 Webhook，失败重试并记录投递结果
+func TestWebhookNotifications(t *testing.T) {
+	var mu sync.Mutex
+	events := map[string]int{}
+	attempts := 0
+	var templated []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/templated" {
+			templated = body
+			return
+		}
+		if attempts++; attempts == 1 { // 首次投递失败，重试成功
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get(notify.HeaderSignature) != notify.Sign("s3cret", r.Header.Get(notify.HeaderTimestamp), body) {
+			t.Errorf("签名校验失败: %s", r.Header.Get(notify.HeaderEvent))
+		}
+		events[r.Header.Get(notify.HeaderEvent)]++
+	}))
+	defer receiver.Close()
+
+	store := memstore.New("")
+	dispatcher := notify.NewDispatcher(nil, store)
+	dispatcher.Backoff = time.Millisecond
+	schedules := handler.NewScheduleHandlerWithoutDB()
+	schedules.SetStore(store)
+	schedules.SetNotifier(dispatcher)
+	drafts := handler.NewDraftHandler(store)
+	drafts.SetNotifier(dispatcher)
+	swaps := handler.NewSwapHandler(store, schedules, drafts)
+	swaps.SetNotifier(dispatcher)
+
+	webhooks := handler.NewWebhookHandler(store)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/webhooks", webhooks.Collection)
+	mux.HandleFunc("/api/v1/webhooks/{id}", webhooks.Item)
+	mux.HandleFunc("/api/v1/webhooks/{id}/deliveries", webhooks.Deliveries)
+	do := func(method, path, role string, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set(handler.RoleHeader, role)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	orgID := uuid.New().String()
+	hook := map[string]interface{}{
+		"org_id": orgID, "url": receiver.URL + "/hook", "secret": "s3cret",
+		"events": []string{notify.TypeUnfilledShift, notify.TypeSwapApproved, notify.TypeAssignmentChanged},
+	}
+	if rec := do(http.MethodPost, "/api/v1/webhooks", "employee", hook); rec.Code != http.StatusForbidden {
+		t.Errorf("非管理者应返回 403: status=%d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/webhooks", "manager", map[string]interface{}{
+		"org_id": orgID, "url": receiver.URL, "events": []string{"unknown"},
+	}); rec.Code != http.StatusBadRequest {
+		t.Errorf("未知事件应返回 400: status=%d", rec.Code)
+	}
+	rec := do(http.MethodPost, "/api/v1/webhooks", "manager", hook)
+	var created handler.WebhookView
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec.Code != http.StatusCreated || !created.HasSecret || bytes.Contains(rec.Body.Bytes(), []byte("s3cret")) {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	rec = do(http.MethodPost, "/api/v1/webhooks", "manager", map[string]interface{}{
+		"org_id": orgID, "url": receiver.URL + "/templated", "events": []string{notify.TypeSwapApproved},
+		"template": `{"text":"{{.Title}}: {{.Body}}"}`,
+	})
+	var templatedHook handler.WebhookView
+	json.Unmarshal(rec.Body.Bytes(), &templatedHook)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create templated status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	// 生成排班：1 月 16 日需要 3 人只有 2 名员工，发送缺员通知
+	shiftID := uuid.New().String()
+	request := map[string]interface{}{
+		"org_id":     orgID,
+		"start_date": "2026-01-15",
+		"end_date":   "2026-01-16",
+		"employees": []map[string]interface{}{
+			{"id": uuid.New().String(), "name": "张三"},
+			{"id": uuid.New().String(), "name": "李四"},
+		},
+		"shifts": []map[string]interface{}{
+			{"id": shiftID, "name": "白班", "code": "D", "start_time": "09:00", "end_time": "17:00", "duration": 480},
+		},
+		"requirements": []map[string]interface{}{
+			{"shift_id": shiftID, "date": "2026-01-15", "min_employees": 1},
+			{"shift_id": shiftID, "date": "2026-01-16", "min_employees": 3},
+		},
+	}
+	rec = postJSON(t, schedules.Generate, "/api/v1/schedule/generate", request)
+	var generated handler.GenerateResponse
+	json.Unmarshal(rec.Body.Bytes(), &generated)
+	if rec.Code != http.StatusOK || len(generated.Unfilled) == 0 {
+		t.Fatalf("generate status = %d, unfilled = %+v", rec.Code, generated.Unfilled)
+	}
+
+	// 换班：发送换班通过和分配修改通知
+	var source handler.AssignmentOutput
+	for _, a := range generated.Assignments {
+		if a.Date == "2026-01-15" {
+			source = a
+		}
+	}
+	target := ""
+	for _, e := range request["employees"].([]map[string]interface{}) {
+		if id := e["id"].(string); id != source.EmployeeID {
+			target = id
+		}
+	}
+	rec = postJSON(t, swaps.Apply, "/api/v1/swap/apply", map[string]interface{}{
+		"schedule_id": generated.ScheduleID, "source_assignment_id": source.ID, "target_employee_id": target,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("apply status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	dispatcher.Wait()
+
+	mu.Lock()
+	if events[notify.TypeUnfilledShift] != 1 || events[notify.TypeSwapApproved] != 1 || events[notify.TypeAssignmentChanged] != 1 {
+		t.Errorf("events = %v", events)
+	}
+	var text map[string]string
+	if err := json.Unmarshal(templated, &text); err != nil || text["text"] == "" {
+		t.Errorf("模板投递 = %s", templated)
+	}
+	mu.Unlock()
+
+	rec = do(http.MethodGet, "/api/v1/webhooks/"+created.ID.String()+"/deliveries", "manager", nil)
+	var deliveries handler.WebhookDeliveryListResponse
+	json.Unmarshal(rec.Body.Bytes(), &deliveries)
+	retried := 0
+	for _, d := range deliveries.Deliveries {
+		if !d.Success {
+			t.Errorf("投递应成功: %+v", d)
+		}
+		if d.Attempts == 2 {
+			retried++
+		}
+	}
+	if deliveries.Total != 3 || retried != 1 {
+		t.Errorf("deliveries = %+v", deliveries.Deliveries)
+	}
+
+	// 停用后不再投递，删除后返回 404
+	if rec = do(http.MethodPut, "/api/v1/webhooks/"+created.ID.String(), "manager", map[string]interface{}{"active": false}); rec.Code != http.StatusOK {
+		t.Errorf("put status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if rec = do(http.MethodDelete, "/api/v1/webhooks/"+templatedHook.ID.String(), "manager", nil); rec.Code != http.StatusOK {
+		t.Errorf("delete status = %d", rec.Code)
+	}
+	if rec = do(http.MethodGet, "/api/v1/webhooks/"+templatedHook.ID.String(), "manager", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("删除后应返回 404: status=%d", rec.Code)
+	}
+	rec = do(http.MethodGet, "/api/v1/webhooks?org_id="+orgID, "manager", nil)
+	var list handler.WebhookListResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if list.Total != 1 || list.Webhooks[0].Active {
+		t.Errorf("list = %+v", list)
+	}
+}
+
+// TestWebhookOrgAccess 测试组织受限的凭证不能读取、修改或删除其他组织的通知订阅
+func TestWebhookOrgAccess(t *testing.T) {
+	webhooks := handler.NewWebhookHandler(memstore.New(""))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/webhooks", webhooks.Collection)
+	mux.HandleFunc("/api/v1/webhooks/{id}", webhooks.Item)
+	mux.HandleFunc("/api/v1/webhooks/{id}/deliveries", webhooks.Deliveries)
+	orgA, orgB := uuid.New().String(), uuid.New().String()
+	do := orgScopedClient(t, orgA, mux)
+
+	rec := do(http.MethodPost, "/api/v1/webhooks", "key-ops", map[string]interface{}{"org_id": orgB, "url": "http://example.com/hook", "secret": "s3cret", "events": []string{"*"}})
+	var hook handler.WebhookView
+	json.Unmarshal(rec.Body.Bytes(), &hook)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	path := "/api/v1/webhooks/" + hook.ID.String()
+	expectForbidden(t, do, http.MethodGet, path, nil)
+	expectForbidden(t, do, http.MethodPut, path, map[string]interface{}{"url": "http://attacker.example.com/"})
+	expectForbidden(t, do, http.MethodDelete, path, nil)
+	expectForbidden(t, do, http.MethodGet, path+"/deliveries", nil)
+	if rec := do(http.MethodGet, path, "key-ops", nil); rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte("http://example.com/hook")) {
+		t.Errorf("订阅不应被其他组织修改: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
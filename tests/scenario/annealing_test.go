@@ -0,0 +1,113 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestAnnealingSolverImprovesTightStaffing 人手紧张时模拟退火通过换人补上贪心解遗漏的需求
+func TestAnnealingSolverImprovesTightStaffing(t *testing.T) {
+	newContext := func() *constraint.Context {
+		ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-17")
+		// 张三会收银也能做服务员，李四只能做服务员
+		ctx.SetEmployees([]*model.Employee{
+			createEmployee("张三", "", []string{"收银"}),
+			createEmployee("李四", "", nil),
+		})
+		cashier := createShift("收银班", "C", "09:00", "17:00", 480, "day")
+		floor := createShift("服务班", "F", "10:00", "18:00", 480, "day")
+		ctx.SetShifts([]*model.Shift{cashier, floor})
+		for _, date := range []string{"2024-01-15", "2024-01-16", "2024-01-17"} {
+			// 服务班优先级更高，贪心会先把工作量相同的张三排到服务班
+			c := createRequirement(cashier.ID, date, 1, 1)
+			c.Skills = []string{"收银"}
+			ctx.Requirements = append(ctx.Requirements, c, createRequirement(floor.ID, date, 1, 5))
+		}
+		return ctx
+	}
+	solve := func(s solver.Solver) (*solver.Result, *constraint.Context) {
+		ctx := newContext()
+		result, err := s.Solve(context.Background(), ctx)
+		if err != nil {
+			t.Fatalf("%s 排班执行失败: %v", s.Name(), err)
+		}
+		return result, ctx
+	}
+
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+	greedy, _ := solve(solver.NewGreedySolver(cm))
+	if greedy.Statistics.FillRate == 100 {
+		t.Fatal("场景应使贪心解无法满足全部需求")
+	}
+
+	annealing := solver.NewAnnealingSolver(cm)
+	annealing.SetSeed(1)
+	result, ctx := solve(annealing)
+
+	if result.Statistics.FillRate < greedy.Statistics.FillRate {
+		t.Errorf("退火满足率 %.1f%% 不应低于贪心 %.1f%%", result.Statistics.FillRate, greedy.Statistics.FillRate)
+	}
+	if result.Statistics.FillRate != 100 || result.Statistics.TotalAssignments != 6 {
+		t.Fatalf("退火应满足全部需求: %+v", result.Statistics)
+	}
+	for _, a := range result.Assignments {
+		if ctx.GetShift(a.ShiftID).Code == "C" && len(ctx.GetEmployee(a.EmployeeID).Skills) == 0 {
+			t.Errorf("收银班分配给了不会收银的员工: %s", a.Date)
+		}
+	}
+	if result.Statistics.Optimizer == nil || result.Statistics.Optimizer.StopReason != "all_filled" {
+		t.Errorf("优化统计不正确: %+v", result.Statistics.Optimizer)
+	}
+}
+
+// TestSolverProgressHook 求解器按轮次报告进度，退火求解器的贪心阶段占整体进度的前一半
+func TestSolverProgressHook(t *testing.T) {
+	newContext := func() *constraint.Context {
+		ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-16")
+		ctx.SetEmployees([]*model.Employee{
+			createEmployee("张三", "", nil),
+			createEmployee("李四", "", nil),
+		})
+		shift := createShift("白班", "D", "09:00", "17:00", 480, "day")
+		ctx.SetShifts([]*model.Shift{shift})
+		for _, date := range []string{"2024-01-15", "2024-01-16"} {
+			ctx.Requirements = append(ctx.Requirements, createRequirement(shift.ID, date, 2, 2))
+		}
+		return ctx
+	}
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+
+	for _, s := range []solver.Solver{solver.NewGreedySolver(cm), solver.NewAnnealingSolver(cm)} {
+		var events []solver.Progress
+		s.SetProgressHook(func(p solver.Progress) { events = append(events, p) })
+		if _, err := s.Solve(context.Background(), newContext()); err != nil {
+			t.Fatalf("%s 排班执行失败: %v", s.Name(), err)
+		}
+		if len(events) != 2 {
+			t.Fatalf("%s 进度 = %+v, want 2 轮", s.Name(), events)
+		}
+		last := events[1]
+		if last.Phase != solver.PhaseGreedy || last.Round != 2 || last.Assignments != 4 || last.Score != 100 {
+			t.Errorf("%s 最后一轮进度 = %+v", s.Name(), last)
+		}
+		// 第一轮每个需求只分配 1 人，尚未满足最少人数
+		if events[0].Assignments != 2 || events[0].Score != 0 {
+			t.Errorf("%s 第一轮进度 = %+v", s.Name(), events[0])
+		}
+		want := 1.0
+		if s.Name() == "AnnealingSolver" {
+			want = 0.5
+		}
+		if last.Fraction != want {
+			t.Errorf("%s fraction = %v, want %v", s.Name(), last.Fraction, want)
+		}
+	}
+}
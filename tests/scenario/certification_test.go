@@ -277,3 +277,41 @@ func TestGetScenarioCertRequirements(t *testing.T) {
 		}
 	}
 }
+
+// TestCertificationRequireVerified 要求证书已核验：仅登记证书名称或证书已过期均不满足
+func TestCertificationRequireVerified(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, map[string]interface{}{
+		"certification_scenario":          "restaurant",
+		"require_verified_certifications": true,
+	})
+
+	ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-16")
+	emp := &model.Employee{
+		BaseModel:      model.BaseModel{ID: uuid.New()},
+		Name:           "张三",
+		Position:       "服务员",
+		Certifications: []string{"健康证"},
+		Status:         "active",
+	}
+	ctx.SetEmployees([]*model.Employee{emp})
+	shift := createShift("早班", "M", "08:00", "16:00", 480, "morning")
+	ctx.SetShifts([]*model.Shift{shift})
+	ctx.SetAssignments([]*model.Assignment{
+		createAssignment(emp.ID, shift.ID, "2024-01-16", "08:00", "16:00"),
+	})
+
+	if result := cm.Evaluate(ctx); result.IsValid {
+		t.Error("证书未核验时应被拒绝")
+	}
+
+	emp.VerifiedCertifications = []model.VerifiedCertification{{Name: "健康证", ExpiresAt: "2024-01-15"}}
+	if result := cm.Evaluate(ctx); result.IsValid {
+		t.Error("排班日期证书已过期时应被拒绝")
+	}
+
+	emp.VerifiedCertifications[0].ExpiresAt = "2024-12-31"
+	if result := cm.Evaluate(ctx); !result.IsValid {
+		t.Errorf("证书已核验且在有效期内应通过: %+v", result.HardViolations)
+	}
+}
@@ -0,0 +1,53 @@
+package scenario
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestDiagnoseUnfilledRequirement 夜班后的早班排不满：诊断指出上夜班的员工被最小休息时间拦截、其余员工当天已排班或缺少技能
+func TestDiagnoseUnfilledRequirement(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+
+	ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-16")
+	ctx.SetEmployees([]*model.Employee{
+		createEmployee("张三", "", []string{"收银"}),
+		createEmployee("李四", "", []string{"收银"}),
+		createEmployee("王五", "", []string{"收银"}),
+		createEmployee("赵六", "", nil),
+	})
+	night := createShift("夜班", "N", "22:00", "06:00", 480, "night")
+	morning := createShift("早班", "M", "07:00", "15:00", 480, "morning")
+	ctx.SetShifts([]*model.Shift{night, morning})
+	nightReq := createRequirement(night.ID, "2024-01-15", 2, 5)
+	nightReq.Skills = []string{"收银"}
+	morningReq := createRequirement(morning.ID, "2024-01-16", 3, 1)
+	morningReq.Skills = []string{"收银"}
+	ctx.Requirements = []*model.ShiftRequirement{nightReq, morningReq}
+
+	if _, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx); err != nil {
+		t.Fatalf("排班执行失败: %v", err)
+	}
+
+	d := solver.Diagnose(cm, ctx, morningReq)
+	if d.Employees != 4 || d.Eligible != 2 || d.Available != 0 {
+		t.Fatalf("diagnosis = %+v", d)
+	}
+	if d.Filtered[solver.FilterSkill] != 1 || d.Filtered[solver.FilterAssignedToday] != 1 {
+		t.Errorf("基本条件淘汰 = %v", d.Filtered)
+	}
+	if len(d.Blocked) == 0 || d.Blocked[0].Constraint != string(constraint.TypeMinRestBetweenShifts) || d.Blocked[0].Count != 2 {
+		t.Fatalf("应被最小休息时间拦截 2 人: %+v", d.Blocked)
+	}
+	if !strings.Contains(d.Summary, "min_rest_between_shifts 2 名") {
+		t.Errorf("summary = %s", d.Summary)
+	}
+}
@@ -0,0 +1,65 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestExternalWorkersOnlyAfterInternalExhausted 外部人员：内部员工排满后才使用，且受本期外部工时上限限制
+func TestExternalWorkersOnlyAfterInternalExhausted(t *testing.T) {
+	run := func(hoursCap float64) (*model.Employee, *solver.Result) {
+		cm := constraint.NewManager()
+		builtin.RegisterDefaultConstraints(cm, nil)
+
+		ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-16")
+		internal := createEmployee("张三", "服务员", nil)
+		agency := (&model.ExternalWorker{ID: uuid.New(), Name: "派遣-王五", Agency: "速聘", HourlyRate: 45, Position: "服务员"}).Employee(ctx.OrgID)
+		// 外部人员排在名单前面，仍应在内部员工之后使用
+		ctx.SetEmployees([]*model.Employee{agency, internal})
+		ctx.ExternalHoursCap = hoursCap
+
+		day := createShift("白班", "D", "09:00", "17:00", 480, "morning")
+		ctx.SetShifts([]*model.Shift{day})
+		ctx.Requirements = []*model.ShiftRequirement{
+			createRequirement(day.ID, "2024-01-15", 2, 5),
+			createRequirement(day.ID, "2024-01-16", 2, 5),
+		}
+		for _, req := range ctx.Requirements {
+			req.Position = "服务员"
+		}
+
+		result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+		if err != nil {
+			t.Fatalf("排班执行失败: %v", err)
+		}
+		return agency, result
+	}
+
+	agency, result := run(0)
+	external := 0
+	for _, a := range result.Assignments {
+		if a.EmployeeID == agency.ID {
+			external++
+		}
+	}
+	if len(result.Assignments) != 4 || external != 2 {
+		t.Fatalf("内部员工每天排 1 班，缺口由外部人员补足: total=%d external=%d", len(result.Assignments), external)
+	}
+
+	agency, result = run(8)
+	external = 0
+	for _, a := range result.Assignments {
+		if a.EmployeeID == agency.ID {
+			external++
+		}
+	}
+	if external != 1 || result.Statistics.CandidatesFiltered[solver.FilterExternalCap] == 0 {
+		t.Errorf("外部工时上限 8 小时只允许 1 个外部班次: external=%d filtered=%v", external, result.Statistics.CandidatesFiltered)
+	}
+}
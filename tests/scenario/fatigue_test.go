@@ -0,0 +1,57 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestFatigueSteersAwayFromTiredEmployee 疲劳指数：刚连上 5 个夜班的员工让位给休息充分的员工
+func TestFatigueSteersAwayFromTiredEmployee(t *testing.T) {
+	run := func(config map[string]interface{}) (*model.Employee, *solver.Result) {
+		cm := constraint.NewManager()
+		builtin.RegisterDefaultConstraints(cm, config)
+
+		ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-15")
+		tired := createEmployee("张三", "保安", nil)
+		rested := createEmployee("李四", "保安", nil)
+		ctx.SetEmployees([]*model.Employee{tired, rested})
+
+		night := createShift("夜班", "N", "22:00", "06:00", 480, "night")
+		ctx.SetShifts([]*model.Shift{night})
+		ctx.Requirements = []*model.ShiftRequirement{createRequirement(night.ID, "2024-01-15", 1, 5)}
+
+		// 张三 1 月 10-14 日连续夜班（上一班 15 日 06:00 下班）
+		for day := 10; day <= 14; day++ {
+			a := createAssignment(tired.ID, night.ID, fmt.Sprintf("2024-01-%d", day), "22:00", "06:00")
+			a.EndTime = a.EndTime.Add(24 * time.Hour)
+			ctx.AddAssignment(a)
+		}
+
+		result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+		if err != nil {
+			t.Fatalf("排班执行失败: %v", err)
+		}
+		if len(result.Assignments) != 1 {
+			t.Fatalf("expected 1 assignment, got %d", len(result.Assignments))
+		}
+		return tired, result
+	}
+
+	tired, result := run(nil)
+	if result.Assignments[0].EmployeeID == tired.ID {
+		t.Error("启用疲劳约束时应优先安排休息充分的员工")
+	}
+
+	tired, result = run(map[string]interface{}{"fatigue_weight": 0})
+	if result.Assignments[0].EmployeeID != tired.ID {
+		t.Error("关闭疲劳约束时应按原有顺序安排")
+	}
+}
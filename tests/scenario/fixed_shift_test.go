@@ -0,0 +1,63 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestFixedShiftSchedule 只上夜班的员工不排白班，固定休息日不排班
+func TestFixedShiftSchedule(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+
+	// 2024-01-15 为周一，2024-01-16 为周二
+	ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-16")
+	nightOnly := createEmployee("张三", "保安", nil)
+	nightOnly.Preferences = &model.EmployeePreferences{
+		FixedShiftCodes: []string{"N"},
+		FixedDaysOff:    []time.Weekday{time.Tuesday},
+	}
+	others := []*model.Employee{createEmployee("李四", "保安", nil), createEmployee("王五", "保安", nil)}
+	ctx.SetEmployees(append([]*model.Employee{nightOnly}, others...))
+
+	day := createShift("白班", "D", "08:00", "16:00", 480, "morning")
+	night := createShift("夜班", "N", "20:00", "23:59", 239, "night")
+	ctx.SetShifts([]*model.Shift{day, night})
+	for _, date := range []string{"2024-01-15", "2024-01-16"} {
+		ctx.Requirements = append(ctx.Requirements,
+			createRequirement(day.ID, date, 1, 5),
+			createRequirement(night.ID, date, 1, 5))
+	}
+
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("排班执行失败: %v", err)
+	}
+
+	nights := 0
+	for _, a := range result.Assignments {
+		if a.EmployeeID != nightOnly.ID {
+			continue
+		}
+		if a.ShiftID != night.ID || a.Date != "2024-01-15" {
+			t.Errorf("固定夜班员工排到了 %s 的班次 %s", a.Date, a.ShiftID)
+		}
+		nights++
+	}
+	if nights != 1 {
+		t.Errorf("固定夜班员工应排周一夜班，实际排了 %d 个班次", nights)
+	}
+	if result.Statistics.CandidatesFiltered[solver.FilterFixedShift] == 0 {
+		t.Errorf("不符合固定班次的候选应被淘汰: %v", result.Statistics.CandidatesFiltered)
+	}
+	if len(result.ConstraintResult.HardViolations) != 0 {
+		t.Errorf("不应有硬约束违规: %v", result.ConstraintResult.HardViolations)
+	}
+}
@@ -0,0 +1,52 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestHolidayDutyRotation 节假日值班按人轮流：上期劳动节已值班的员工本期国庆排在最后
+func TestHolidayDutyRotation(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+
+	ctx := constraint.NewContext(uuid.New(), "2025-10-01", "2025-10-03")
+	veteran := createEmployee("张三", "服务员", nil)
+	others := []*model.Employee{createEmployee("李四", "服务员", nil), createEmployee("王五", "服务员", nil)}
+	ctx.SetEmployees(append([]*model.Employee{veteran}, others...))
+
+	day := createShift("白班", "D", "09:00", "17:00", 480, "morning")
+	ctx.SetShifts([]*model.Shift{day})
+	var history []*model.Assignment
+	for _, date := range []string{"2025-05-01", "2025-05-02", "2025-05-03"} {
+		history = append(history, &model.Assignment{
+			BaseModel:  model.NewBaseModel(),
+			EmployeeID: veteran.ID,
+			ShiftID:    day.ID,
+			Date:       date,
+		})
+	}
+	ctx.SetHistory(history, []*model.Shift{day})
+	for _, date := range []string{"2025-10-01", "2025-10-02", "2025-10-03"} {
+		ctx.Requirements = append(ctx.Requirements, createRequirement(day.ID, date, 1, 5))
+	}
+
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("排班执行失败: %v", err)
+	}
+	if len(result.Assignments) != 3 {
+		t.Fatalf("应排满 3 个国庆班次，实际 %d", len(result.Assignments))
+	}
+	for _, a := range result.Assignments {
+		if a.EmployeeID == veteran.ID {
+			t.Errorf("上期劳动节已值班的员工不应在 %s 值班", a.Date)
+		}
+	}
+}
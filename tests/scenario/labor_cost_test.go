@@ -0,0 +1,81 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// solveWithLaborBudget 两名同岗位员工（时薪 30、20）排周一至周五的白班，每天 1 人
+func solveWithLaborBudget(t *testing.T, config map[string]interface{}) (*solver.Result, *model.Employee, *model.Employee) {
+	t.Helper()
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, config)
+
+	ctx := constraint.NewContext(uuid.New(), "2025-03-03", "2025-03-07")
+	expensive := createEmployee("张三", "服务员", nil)
+	expensive.HourlyRate = 30
+	cheap := createEmployee("李四", "服务员", nil)
+	cheap.HourlyRate = 20
+	ctx.SetEmployees([]*model.Employee{expensive, cheap})
+
+	day := createShift("白班", "D", "09:00", "17:00", 480, "morning")
+	ctx.SetShifts([]*model.Shift{day})
+	for _, date := range []string{"2025-03-03", "2025-03-04", "2025-03-05", "2025-03-06", "2025-03-07"} {
+		req := createRequirement(day.ID, date, 1, 5)
+		req.MaxEmployees = 1
+		ctx.Requirements = append(ctx.Requirements, req)
+	}
+
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("排班执行失败: %v", err)
+	}
+	return result, expensive, cheap
+}
+
+// TestLaborCostBudgetPrefersCheaper 预算紧张时优先安排时薪低的员工
+func TestLaborCostBudgetPrefersCheaper(t *testing.T) {
+	// 周预算 300：首个班次安排张三（240）即达到预算的 80%，之后每次都按增加的成本排序
+	result, _, cheap := solveWithLaborBudget(t, map[string]interface{}{"max_labor_cost_per_week": 300})
+	if len(result.Assignments) != 5 {
+		t.Fatalf("应排满 5 个班次，实际 %d", len(result.Assignments))
+	}
+	for _, a := range result.Assignments {
+		if a.EmployeeID != cheap.ID {
+			t.Errorf("预算紧张时 %s 应安排时薪低的员工", a.Date)
+		}
+	}
+
+	// 不设预算时按工作量均衡分配
+	result, expensive, _ := solveWithLaborBudget(t, nil)
+	count := 0
+	for _, a := range result.Assignments {
+		if a.EmployeeID == expensive.ID {
+			count++
+		}
+	}
+	if count == 0 {
+		t.Error("未设预算时不应只安排时薪低的员工")
+	}
+}
+
+// TestLaborCostBudgetHard 硬约束模式下宁可缺员也不超过预算
+func TestLaborCostBudgetHard(t *testing.T) {
+	result, _, _ := solveWithLaborBudget(t, map[string]interface{}{
+		"max_labor_cost_per_week": 500,
+		"max_labor_cost_mode":     "hard",
+	})
+	// 预算未紧张时按工作量安排张三（240），之后安排成本低的李四（160），再排任何人都会超过 500
+	if len(result.Assignments) != 2 {
+		t.Fatalf("硬约束下应只排 2 个班次，实际 %d", len(result.Assignments))
+	}
+	if result.Statistics.FilledRequirements != 2 {
+		t.Errorf("应有 3 个需求缺员，已满足 %d", result.Statistics.FilledRequirements)
+	}
+}
@@ -0,0 +1,60 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestMultiStoreSchedule 一次请求为两家门店排班：员工只排到所属门店或可支援门店
+func TestMultiStoreSchedule(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, map[string]interface{}{
+		"stores": []model.Store{{ID: "east", Name: "东门店"}, {ID: "west", Name: "西门店"}},
+	})
+
+	ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-15")
+	east := createEmployee("张三", "服务员", nil)
+	east.StoreID = "east"
+	west := createEmployee("李四", "服务员", nil)
+	west.StoreID = "west"
+	floater := createEmployee("王五", "服务员", nil)
+	floater.StoreID = "east"
+	floater.AllowedStores = []string{"west"}
+	ctx.SetEmployees([]*model.Employee{east, west, floater})
+
+	day := createShift("白班", "D", "09:00", "17:00", 480, "morning")
+	ctx.SetShifts([]*model.Shift{day})
+	eastReq := createRequirement(day.ID, "2024-01-15", 1, 5)
+	eastReq.StoreID = "east"
+	westReq := createRequirement(day.ID, "2024-01-15", 2, 5)
+	westReq.StoreID = "west"
+	ctx.Requirements = []*model.ShiftRequirement{eastReq, westReq}
+
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("排班执行失败: %v", err)
+	}
+
+	byStore := make(map[string][]uuid.UUID)
+	for _, a := range result.Assignments {
+		byStore[a.StoreID] = append(byStore[a.StoreID], a.EmployeeID)
+	}
+	if len(byStore["east"]) != 1 || byStore["east"][0] != east.ID {
+		t.Errorf("东门店应只排所属员工: %v", byStore["east"])
+	}
+	if len(byStore["west"]) != 2 {
+		t.Errorf("西门店应由所属员工和可支援员工补满: %v", byStore["west"])
+	}
+	if result.Statistics.CandidatesFiltered[solver.FilterStore] == 0 {
+		t.Errorf("不可支援门店的员工应被淘汰: %v", result.Statistics.CandidatesFiltered)
+	}
+	if len(result.ConstraintResult.HardViolations) != 0 {
+		t.Errorf("不应有硬约束违规: %v", result.ConstraintResult.HardViolations)
+	}
+}
@@ -0,0 +1,90 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestOnCallSchedule 常规班次排完后安排待命：轮流待命，夜班次日不待命，每周不超过上限，待命不计入常规分配和工时
+func TestOnCallSchedule(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, map[string]interface{}{"max_on_call_per_week": 2})
+
+	// 2024-01-15 为周一，排一整周
+	ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-21")
+	var employees []*model.Employee
+	for _, name := range []string{"张三", "李四", "王五", "赵六"} {
+		employees = append(employees, createEmployee(name, "运维", nil))
+	}
+	ctx.SetEmployees(employees)
+
+	day := createShift("白班", "D", "08:00", "16:00", 480, "morning")
+	night := createShift("夜班", "N", "20:00", "23:59", 239, "night")
+	standby := createShift("待命", "OC", "16:00", "20:00", 240, "on_call")
+	ctx.SetShifts([]*model.Shift{day, night, standby})
+	var dates []string
+	for d := 15; d <= 21; d++ {
+		date := fmt.Sprintf("2024-01-%02d", d)
+		dates = append(dates, date)
+		ctx.Requirements = append(ctx.Requirements,
+			createRequirement(day.ID, date, 1, 5),
+			createRequirement(night.ID, date, 1, 5),
+			createRequirement(standby.ID, date, 1, 5))
+	}
+
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("排班执行失败: %v", err)
+	}
+
+	for _, a := range result.Assignments {
+		if a.ShiftID == standby.ID {
+			t.Fatalf("待命分配不应出现在常规分配中: %+v", a)
+		}
+	}
+	if result.Statistics.TotalRequirements != 14 || result.Statistics.FillRate != 100 {
+		t.Errorf("满足率只按常规需求计算: total=%d fill=%.1f", result.Statistics.TotalRequirements, result.Statistics.FillRate)
+	}
+	if want := 14 * 8.0; result.Statistics.TotalHours > want {
+		t.Errorf("待命不应计入工时: total_hours=%.1f", result.Statistics.TotalHours)
+	}
+
+	onCall := result.Statistics.OnCall
+	if onCall == nil || onCall.TotalRequirements != 7 || onCall.FilledRequirements != 7 || len(result.OnCall) != 7 {
+		t.Fatalf("每天应安排 1 人待命: stats=%+v on_call=%d", onCall, len(result.OnCall))
+	}
+
+	nightOn := make(map[string]uuid.UUID)
+	for _, a := range result.Assignments {
+		if a.ShiftID == night.ID {
+			nightOn[a.Date] = a.EmployeeID
+		}
+	}
+	counts := make(map[uuid.UUID]int)
+	for i, a := range result.OnCall {
+		if i > 0 && nightOn[dates[i-1]] == a.EmployeeID {
+			t.Errorf("%s 夜班次日不应待命", a.Date)
+		}
+		counts[a.EmployeeID]++
+	}
+	minCount, maxCount := 7, 0
+	for _, emp := range employees {
+		minCount, maxCount = min(minCount, counts[emp.ID]), max(maxCount, counts[emp.ID])
+		if onCall.ByEmployee[emp.ID.String()] != counts[emp.ID] {
+			t.Errorf("待命统计与分配不一致: %v", onCall.ByEmployee)
+		}
+	}
+	if maxCount > 2 || maxCount-minCount > 1 {
+		t.Errorf("待命应轮流安排且每周不超过 2 次: %v", counts)
+	}
+	if len(result.ConstraintResult.HardViolations) != 0 {
+		t.Errorf("不应有硬约束违规: %v", result.ConstraintResult.HardViolations)
+	}
+}
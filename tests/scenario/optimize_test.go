@@ -0,0 +1,165 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/optimizer"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// newPreferenceContext 两人两班：张三不愿上早班，但贪心按员工顺序先把张三排到优先级更高的早班
+func newPreferenceContext() *constraint.Context {
+	ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-17")
+	zhang := createEmployee("张三", "", nil)
+	zhang.Preferences = &model.EmployeePreferences{AvoidShifts: []string{"E"}}
+	ctx.SetEmployees([]*model.Employee{zhang, createEmployee("李四", "", nil)})
+	early := createShift("早班", "E", "07:00", "15:00", 480, "day")
+	late := createShift("晚班", "L", "13:00", "21:00", 480, "day")
+	ctx.SetShifts([]*model.Shift{early, late})
+	for _, date := range []string{"2024-01-15", "2024-01-16", "2024-01-17"} {
+		ctx.Requirements = append(ctx.Requirements,
+			createRequirement(early.ID, date, 1, 5), createRequirement(late.ID, date, 1, 1))
+	}
+	return ctx
+}
+
+// TestOptimizingSolverReducesSoftPenalty 优化阶段通过换人消除贪心解中的偏好违反，满足率不降低
+func TestOptimizingSolverReducesSoftPenalty(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+
+	avoided := func(ctx *constraint.Context, assignments []*model.Assignment) int {
+		n := 0
+		for _, a := range assignments {
+			if ctx.GetShift(a.ShiftID).Code == "E" && ctx.GetEmployee(a.EmployeeID).Name == "张三" {
+				n++
+			}
+		}
+		return n
+	}
+
+	ctx := newPreferenceContext()
+	greedy, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("贪心排班执行失败: %v", err)
+	}
+	if avoided(ctx, greedy.Assignments) == 0 {
+		t.Fatal("场景应使贪心解违反员工偏好")
+	}
+
+	for _, algorithm := range []string{optimizer.AlgorithmLocalSearch, optimizer.AlgorithmGenetic} {
+		config := solver.DefaultOptimizationConfig()
+		config.Algorithm = algorithm
+		s := solver.NewOptimizingSolver(cm, config)
+		s.SetSeed(1)
+		ctx := newPreferenceContext()
+		result, err := s.Solve(context.Background(), ctx)
+		if err != nil {
+			t.Fatalf("%s 排班执行失败: %v", algorithm, err)
+		}
+
+		stats := result.Statistics
+		if stats.FillRate != 100 || stats.TotalAssignments != 6 || !result.Success {
+			t.Fatalf("%s 优化后应满足全部需求: %+v", algorithm, stats)
+		}
+		if stats.Optimizer == nil || stats.Optimizer.Iterations == 0 {
+			t.Errorf("%s 应报告优化迭代次数: %+v", algorithm, stats.Optimizer)
+		}
+		opt := stats.Optimization
+		if opt == nil || !opt.Applied || opt.Algorithm != algorithm || opt.FinalScore >= opt.InitialScore || opt.Improvement <= 0 {
+			t.Fatalf("%s 优化结果 = %+v", algorithm, opt)
+		}
+		if n := avoided(ctx, result.Assignments); n != 0 {
+			t.Errorf("%s 仍把张三排到 %d 个早班", algorithm, n)
+		}
+		for _, a := range result.Assignments {
+			if a.ID == uuid.Nil || a.StartTime.Format("2006-01-02") != a.Date {
+				t.Errorf("%s 分配未规整: %+v", algorithm, a)
+			}
+		}
+	}
+}
+
+// TestConstraintEvaluatorRejectsInvalidAssignments 评估适配器将不对应需求或重复排班的分配计为无效
+func TestConstraintEvaluatorRejectsInvalidAssignments(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+	ctx := newPreferenceContext()
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("贪心排班执行失败: %v", err)
+	}
+
+	evaluator := solver.NewConstraintEvaluator(cm, ctx, nil)
+	base, violations := evaluator.Evaluate(result.Assignments, ctx.Employees, ctx.Shifts)
+	if len(violations) != 0 {
+		t.Fatalf("贪心解不应有硬约束违反: %v", violations)
+	}
+
+	// 同一员工当天排两个班，另一个需求因此空缺
+	duplicated := make([]*model.Assignment, len(result.Assignments))
+	for i, a := range result.Assignments {
+		clone := *a
+		duplicated[i] = &clone
+	}
+	for _, a := range duplicated {
+		if a.Date == duplicated[0].Date && a != duplicated[0] {
+			a.EmployeeID = duplicated[0].EmployeeID
+		}
+	}
+	score, violations := evaluator.Evaluate(duplicated, ctx.Employees, ctx.Shifts)
+	if len(violations) != 1 || score <= base+1000 {
+		t.Errorf("重复排班评分 = %.0f（基准 %.0f），violations = %v", score, base, violations)
+	}
+
+	// 优化器插入的分配没有日期，不对应任何需求
+	inserted := append(result.Assignments[:len(result.Assignments):len(result.Assignments)],
+		&model.Assignment{EmployeeID: ctx.Employees[0].ID, ShiftID: ctx.Shifts[0].ID})
+	if _, violations := evaluator.Evaluate(inserted, ctx.Employees, ctx.Shifts); len(violations) != 1 {
+		t.Errorf("无日期分配应视为无效: %v", violations)
+	}
+}
+
+// TestConstraintEvaluatorRepair 修复算子为被移除的分配补位，并优先换一名员工
+func TestConstraintEvaluatorRepair(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+	ctx := newPreferenceContext()
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("贪心排班执行失败: %v", err)
+	}
+
+	// 移除第一天的两个分配
+	var kept, removed []*model.Assignment
+	for _, a := range result.Assignments {
+		if a.Date == "2024-01-15" {
+			removed = append(removed, a)
+		} else {
+			kept = append(kept, a)
+		}
+	}
+	evaluator := solver.NewConstraintEvaluator(cm, ctx, nil)
+	repaired := evaluator.Repair(kept, removed, ctx.Employees, ctx.Shifts)
+	if len(repaired) != len(result.Assignments) {
+		t.Fatalf("修复后分配数 = %d, want %d", len(repaired), len(result.Assignments))
+	}
+	for _, a := range repaired {
+		if a.Date != "2024-01-15" {
+			continue
+		}
+		for _, r := range removed {
+			if r.ShiftID == a.ShiftID && r.EmployeeID == a.EmployeeID {
+				t.Errorf("有其他人选时不应原样排回: %s", ctx.GetEmployee(a.EmployeeID).Name)
+			}
+		}
+	}
+	if _, violations := evaluator.Evaluate(repaired, ctx.Employees, ctx.Shifts); len(violations) != 0 {
+		t.Errorf("修复后的方案不应有违反: %v", violations)
+	}
+}
@@ -0,0 +1,123 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// newLargeContext 100 名员工、30 天三班倒，每班 20 人
+func newLargeContext() *constraint.Context {
+	ctx := constraint.NewContext(uuid.New(), "2024-01-01", "2024-01-30")
+	employees := make([]*model.Employee, 0, 100)
+	for i := 0; i < 100; i++ {
+		employees = append(employees, createEmployee(fmt.Sprintf("员工%d", i), "", nil))
+	}
+	ctx.SetEmployees(employees)
+	shifts := []*model.Shift{
+		createShift("早班", "M", "06:00", "14:00", 480, "morning"),
+		createShift("中班", "A", "14:00", "22:00", 480, "afternoon"),
+		createShift("夜班", "N", "22:00", "06:00", 480, "night"),
+	}
+	ctx.SetShifts(shifts)
+	start, _ := time.Parse("2006-01-02", ctx.StartDate)
+	for d := 0; d < 30; d++ {
+		for _, s := range shifts {
+			ctx.Requirements = append(ctx.Requirements, createRequirement(s.ID, start.AddDate(0, 0, d).Format("2006-01-02"), 20, 1))
+		}
+	}
+	return ctx
+}
+
+// solveLarge 使用默认约束贪心求解 newLargeContext，configure 可为空
+func solveLarge(tb testing.TB, configure func(*solver.GreedySolver)) (*constraint.Context, *solver.Result) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+	s := solver.NewGreedySolver(cm)
+	s.SetMaxIterations(100000)
+	if configure != nil {
+		configure(s)
+	}
+	ctx := newLargeContext()
+	result, err := s.Solve(context.Background(), ctx)
+	if err != nil {
+		tb.Fatalf("排班执行失败: %v", err)
+	}
+	return ctx, result
+}
+
+// TestLargeScheduleHardConstraints 大规模排班：全部需求满足且没有硬约束违反
+func TestLargeScheduleHardConstraints(t *testing.T) {
+	ctx, result := solveLarge(t, nil)
+	if result.Statistics.FillRate != 100 || len(result.Assignments) != 1800 {
+		t.Fatalf("满足率 = %.1f%%, 分配数 = %d", result.Statistics.FillRate, len(result.Assignments))
+	}
+	if n := len(result.ConstraintResult.HardViolations); n != 0 {
+		t.Errorf("硬约束违反 %d 个: %v", n, result.ConstraintResult.HardViolations[0])
+	}
+	for _, emp := range ctx.Employees {
+		for _, a := range ctx.GetEmployeeAssignments(emp.ID) {
+			if ctx.GetEmployeeDayStats(emp.ID, a.Date).Shifts != 1 {
+				t.Fatalf("员工 %s 在 %s 排了多个班次", emp.Name, a.Date)
+			}
+		}
+	}
+}
+
+// BenchmarkGreedyLargeSchedule 100 名员工、30 天的贪心排班
+func BenchmarkGreedyLargeSchedule(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		solveLarge(b, nil)
+	}
+}
+
+// BenchmarkGreedyLargeSchedule 在基线版本（75a60f2）上约 74ms/op。
+// 允许 2 倍以内的机器差异和测量噪声，超出时视为性能回退（曾因逐个候选人计算疲劳指数退化到 700ms/op 以上）
+const (
+	largeScheduleBaseline  = 74 * time.Millisecond
+	largeScheduleTolerance = 2.0
+)
+
+// TestLargeSchedulePerformance 大规模贪心排班的耗时不超过基线的 largeScheduleTolerance 倍，-short 时跳过
+func TestLargeSchedulePerformance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("-short 模式跳过性能检查")
+	}
+	perOp := time.Duration(testing.Benchmark(BenchmarkGreedyLargeSchedule).NsPerOp())
+	if limit := time.Duration(float64(largeScheduleBaseline) * largeScheduleTolerance); perOp > limit {
+		t.Errorf("大规模排班 %v/op，超过基线 %v 的 %.1f 倍（%v）", perOp, largeScheduleBaseline, largeScheduleTolerance, limit)
+	}
+	t.Logf("大规模排班 %v/op，基线 %v", perOp, largeScheduleBaseline)
+}
+
+// TestParallelCandidateEvaluation 并行评估候选人与逐个评估选中相同的员工，设置种子时分配ID也相同
+func TestParallelCandidateEvaluation(t *testing.T) {
+	seqCtx, sequential := solveLarge(t, func(s *solver.GreedySolver) { s.SetSeed(7) })
+	parCtx, parallel := solveLarge(t, func(s *solver.GreedySolver) {
+		s.SetSeed(7)
+		s.SetWorkers(4)
+	})
+	if len(parallel.Assignments) != len(sequential.Assignments) {
+		t.Fatalf("分配数 = %d, want %d", len(parallel.Assignments), len(sequential.Assignments))
+	}
+	// 两次求解的上下文各自生成员工和班次ID，按姓名和班次编码比较
+	describe := func(ctx *constraint.Context, a *model.Assignment) string {
+		return fmt.Sprintf("%s %s %s %s", a.ID, a.Date, ctx.GetShift(a.ShiftID).Code, ctx.GetEmployee(a.EmployeeID).Name)
+	}
+	for i, a := range parallel.Assignments {
+		if got, want := describe(parCtx, a), describe(seqCtx, sequential.Assignments[i]); got != want {
+			t.Fatalf("第 %d 个分配不同: %s vs %s", i, got, want)
+		}
+	}
+	if parallel.Statistics.CandidatesConsidered < sequential.Statistics.CandidatesConsidered {
+		t.Errorf("并行模式应评估全部候选人: %d < %d",
+			parallel.Statistics.CandidatesConsidered, sequential.Statistics.CandidatesConsidered)
+	}
+}
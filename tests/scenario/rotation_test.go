@@ -0,0 +1,49 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestGreedyShiftRotationNoHardViolations 9 人三班倒 14 天、每 3 天轮换：贪心排班不产生倒班模式的硬约束违规
+func TestGreedyShiftRotationNoHardViolations(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterFactoryConstraints(cm, map[string]interface{}{"rotation_days": 3})
+
+	ctx := constraint.NewContext(uuid.New(), "2024-03-04", "2024-03-17")
+	var employees []*model.Employee
+	for i := 1; i <= 9; i++ {
+		employees = append(employees, createEmployee(fmt.Sprintf("工人%d", i), "", nil))
+	}
+	ctx.SetEmployees(employees)
+	shifts := []*model.Shift{
+		createShift("早班", "A", "08:00", "16:00", 480, "morning"),
+		createShift("中班", "B", "16:00", "24:00", 480, "afternoon"),
+		createShift("夜班", "C", "00:00", "08:00", 480, "night"),
+	}
+	ctx.SetShifts(shifts)
+	start := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 14; day++ {
+		date := start.AddDate(0, 0, day).Format("2006-01-02")
+		for _, shift := range shifts {
+			ctx.Requirements = append(ctx.Requirements, createRequirement(shift.ID, date, 2, 5))
+		}
+	}
+
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("排班执行失败: %v", err)
+	}
+	for _, v := range result.ConstraintResult.HardViolations {
+		t.Errorf("硬约束违规: %s %s %s", v.ConstraintType, v.Date, v.Message)
+	}
+	t.Logf("满足率: %.1f%%", result.Statistics.FillRate)
+}
@@ -0,0 +1,78 @@
+package scenario
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestNewScoringProfile 未给出的目标使用默认权重，拒绝未知目标和负数权重
+func TestNewScoringProfile(t *testing.T) {
+	p, err := solver.NewScoringProfile(map[string]float64{"fairness": 3, "cost": 0})
+	if err != nil {
+		t.Fatalf("评分方案应有效: %v", err)
+	}
+	want := solver.DefaultScoringProfile()
+	want.Fairness, want.Cost = 3, 0
+	if p != want {
+		t.Errorf("profile = %+v, want %+v", p, want)
+	}
+	for _, weights := range []map[string]float64{
+		{"speed": 1},
+		{"coverage": -1},
+		{"preference": math.NaN()},
+	} {
+		if _, err := solver.NewScoringProfile(weights); err == nil {
+			t.Errorf("%v 应返回错误", weights)
+		}
+	}
+}
+
+// TestScoringProfileObjective 评分方案报告各目标子得分，并作为优化阶段的目标函数
+func TestScoringProfileObjective(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+
+	greedy, err := solver.NewGreedySolver(cm).Solve(context.Background(), newPreferenceContext())
+	if err != nil {
+		t.Fatalf("贪心排班执行失败: %v", err)
+	}
+	scoring := greedy.Statistics.Scoring
+	if scoring == nil || scoring.Profile != solver.DefaultScoringProfile() {
+		t.Fatalf("应报告默认评分方案: %+v", scoring)
+	}
+	sub := scoring.SubScores
+	if sub.Preference <= 0 || sub.Coverage != 0 {
+		t.Errorf("贪心解违反偏好且无需求缺口: %+v", sub)
+	}
+	if sum := sub.Coverage + sub.Fairness + sub.Cost + sub.Preference + sub.Continuity + sub.Other; scoring.Weighted != sum {
+		t.Errorf("默认权重下加权得分 = %v, want %v", scoring.Weighted, sum)
+	}
+	if p := greedy.ConstraintResult.SoftPenalties[constraint.TypeEmployeePreference]; float64(p) != sub.Preference {
+		t.Errorf("偏好子得分应等于员工偏好约束惩罚 %d: %v", p, sub.Preference)
+	}
+
+	// 偏好权重为 0 时贪心解的目标值不含偏好惩罚
+	initial := func(profile solver.ScoringProfile) *solver.Result {
+		s := solver.NewOptimizingSolver(cm, solver.DefaultOptimizationConfig())
+		s.SetScoringProfile(profile)
+		result, err := s.Solve(context.Background(), newPreferenceContext())
+		if err != nil {
+			t.Fatalf("优化排班执行失败: %v", err)
+		}
+		return result
+	}
+	noPreference := solver.DefaultScoringProfile()
+	noPreference.Preference = 0
+	base, weighted := initial(solver.DefaultScoringProfile()), initial(noPreference)
+	if diff := base.Statistics.Optimization.InitialScore - weighted.Statistics.Optimization.InitialScore; diff != sub.Preference {
+		t.Errorf("偏好权重为 0 时目标值应减少 %v，实际减少 %v", sub.Preference, diff)
+	}
+	if s := weighted.Statistics.Scoring; s == nil || s.Profile != noPreference {
+		t.Errorf("应报告生效的评分方案: %+v", s)
+	}
+}
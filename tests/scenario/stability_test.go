@@ -0,0 +1,69 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestStabilityKeepsWeekdayPattern 周间排班稳定：员工优先安排到上周同一天上过的班次
+func TestStabilityKeepsWeekdayPattern(t *testing.T) {
+	run := func(config map[string]interface{}, swap bool) (map[uuid.UUID]string, map[uuid.UUID]string, *solver.Result) {
+		cm := constraint.NewManager()
+		builtin.RegisterDefaultConstraints(cm, config)
+
+		ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-15")
+		first := createEmployee("张三", "服务员", nil)
+		second := createEmployee("李四", "服务员", nil)
+		ctx.SetEmployees([]*model.Employee{first, second})
+
+		morning := createShift("早班", "M", "07:00", "15:00", 480, "morning")
+		evening := createShift("晚班", "E", "14:00", "22:00", 480, "evening")
+		ctx.SetShifts([]*model.Shift{morning, evening})
+		ctx.Requirements = []*model.ShiftRequirement{
+			createRequirement(morning.ID, "2024-01-15", 1, 5),
+			createRequirement(evening.ID, "2024-01-15", 1, 5),
+		}
+
+		// 上周一（1 月 8 日）的班次作为固定历史
+		last := map[uuid.UUID]*model.Shift{first.ID: morning, second.ID: evening}
+		if swap {
+			last = map[uuid.UUID]*model.Shift{first.ID: evening, second.ID: morning}
+		}
+		var history []*model.Assignment
+		want := make(map[uuid.UUID]string)
+		for empID, shift := range last {
+			history = append(history, createAssignment(empID, shift.ID, "2024-01-08", shift.StartTime, shift.EndTime))
+			want[empID] = shift.Code
+		}
+		ctx.SetHistory(history, nil)
+
+		result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+		if err != nil {
+			t.Fatalf("排班执行失败: %v", err)
+		}
+		got := make(map[uuid.UUID]string)
+		for _, a := range result.Assignments {
+			got[a.EmployeeID] = ctx.GetShift(a.ShiftID).Code
+		}
+		return want, got, result
+	}
+
+	for _, swap := range []bool{false, true} {
+		want, got, result := run(map[string]interface{}{"stability_weight": 60}, swap)
+		for empID, code := range want {
+			if got[empID] != code {
+				t.Errorf("swap=%v: 员工应安排到上周同一天的班次 %s, got %s", swap, code, got[empID])
+			}
+		}
+		stats := result.Statistics.Stability
+		if stats == nil || stats.Comparable != 2 || stats.Matched != 2 || stats.Score != 100 {
+			t.Errorf("swap=%v: 稳定性统计不正确: %+v", swap, stats)
+		}
+	}
+}
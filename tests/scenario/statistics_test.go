@@ -0,0 +1,57 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestSolverStatisticsInstrumentation 求解统计：阶段耗时与候选淘汰计数
+func TestSolverStatisticsInstrumentation(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, map[string]interface{}{
+		"max_hours_per_week": 16,
+	})
+
+	ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-21")
+	active := createEmployee("张三", "服务员", nil)
+	inactive := createEmployee("李四", "服务员", nil)
+	inactive.Status = "inactive"
+	ctx.SetEmployees([]*model.Employee{active, inactive})
+
+	shift := createShift("早班", "M", "08:00", "16:00", 480, "morning")
+	ctx.SetShifts([]*model.Shift{shift})
+	for day := 15; day <= 21; day++ {
+		ctx.Requirements = append(ctx.Requirements,
+			createRequirement(shift.ID, fmt.Sprintf("2024-01-%d", day), 1, 5))
+	}
+
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("排班执行失败: %v", err)
+	}
+	stats := result.Statistics
+
+	if stats.Timings == nil || stats.Timings.TotalMs <= 0 {
+		t.Fatalf("应记录总耗时: %+v", stats.Timings)
+	}
+	if got := stats.CandidatesFiltered[solver.FilterInactive]; got != 7 {
+		t.Errorf("非在职淘汰次数 = %d, want 7", got)
+	}
+	// 周从周日开始：15-20日最多排2个班，21日（周日）属于下一周
+	if stats.TotalAssignments != 3 {
+		t.Errorf("分配数 = %d, want 3", stats.TotalAssignments)
+	}
+	if got := stats.RejectedByConstraint[string(constraint.TypeMaxHoursPerWeek)]; got != 4 {
+		t.Errorf("周工时约束拒绝次数 = %d, want 4 (%v)", got, stats.RejectedByConstraint)
+	}
+	if stats.CandidatesConsidered != 7 {
+		t.Errorf("约束检查候选次数 = %d, want 7", stats.CandidatesConsidered)
+	}
+}
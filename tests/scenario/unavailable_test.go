@@ -0,0 +1,57 @@
+package scenario
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/paiban/paiban/pkg/model"
+	"github.com/paiban/paiban/pkg/scheduler/constraint"
+	"github.com/paiban/paiban/pkg/scheduler/constraint/builtin"
+	"github.com/paiban/paiban/pkg/scheduler/solver"
+)
+
+// TestLeaveAndWeeklyUnavailability 请假和每周不可用时段内不排班
+func TestLeaveAndWeeklyUnavailability(t *testing.T) {
+	cm := constraint.NewManager()
+	builtin.RegisterDefaultConstraints(cm, nil)
+
+	// 2024-01-15 为周一，2024-01-17 为周三
+	ctx := constraint.NewContext(uuid.New(), "2024-01-15", "2024-01-17")
+	onLeave := createEmployee("张三", "服务员", nil)
+	onLeave.Leaves = []model.EmployeeLeave{{StartDate: "2024-01-15", EndDate: "2024-01-16", Type: "annual"}}
+	student := createEmployee("李四", "服务员", nil)
+	student.UnavailableWindows = []model.AvailabilityWindow{{Weekdays: []time.Weekday{time.Wednesday}, Start: "13:00", End: "18:00"}}
+	ctx.SetEmployees([]*model.Employee{onLeave, student})
+
+	day := createShift("白班", "D", "09:00", "17:00", 480, "morning")
+	ctx.SetShifts([]*model.Shift{day})
+	ctx.Requirements = []*model.ShiftRequirement{
+		createRequirement(day.ID, "2024-01-15", 1, 5),
+		createRequirement(day.ID, "2024-01-16", 1, 5),
+		createRequirement(day.ID, "2024-01-17", 1, 5),
+	}
+
+	result, err := solver.NewGreedySolver(cm).Solve(context.Background(), ctx)
+	if err != nil {
+		t.Fatalf("排班执行失败: %v", err)
+	}
+
+	byDate := make(map[string]uuid.UUID)
+	for _, a := range result.Assignments {
+		byDate[a.Date] = a.EmployeeID
+	}
+	if byDate["2024-01-15"] != student.ID || byDate["2024-01-16"] != student.ID {
+		t.Errorf("请假期间应由其他员工顶班: %v", byDate)
+	}
+	if byDate["2024-01-17"] != onLeave.ID {
+		t.Errorf("周三下午不可用的员工不应排白班: %v", byDate)
+	}
+	if result.Statistics.CandidatesFiltered[solver.FilterLeave] != 2 {
+		t.Errorf("请假淘汰次数 = %v", result.Statistics.CandidatesFiltered)
+	}
+	if len(result.ConstraintResult.HardViolations) != 0 {
+		t.Errorf("不应有硬约束违规: %v", result.ConstraintResult.HardViolations)
+	}
+}